@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blockchain
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/payments/bindings"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+)
+
+// Blockchain reads payment related state from blockchain,
+// either directly through ethereum RPC or through the transactor in light client mode.
+type Blockchain interface {
+	IsRegistered(registryAddress, addressToCheck common.Address) (bool, error)
+	IsRegisteredAsProvider(accountantAddress, registryAddress, addressToCheck common.Address) (bool, error)
+	GetMystBalance(mystSCAddress, channel common.Address) (*big.Int, error)
+	GetConsumerChannel(addr common.Address, mystSCAddress common.Address) (paymentClient.ConsumerChannel, error)
+	GetProviderChannel(accountantAddress common.Address, addressToCheck common.Address, pending bool) (paymentClient.ProviderChannel, error)
+	GetAccountantFee(accountantAddress common.Address) (uint16, error)
+	SubscribeToConsumerBalanceEvent(channel, mystSCAddress common.Address, timeout time.Duration) (chan *bindings.MystTokenTransfer, func(), error)
+	SubscribeToPromiseSettledEvent(providerID, accountantID common.Address) (sink chan *bindings.AccountantImplementationPromiseSettled, cancel func(), err error)
+}
+
+// ChainReader reads transactions and blocks from blockchain.
+type ChainReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blockchain
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+)
+
+type hotReader interface {
+	IsRegistered(registryAddress, addressToCheck common.Address) (bool, error)
+	IsRegisteredAsProvider(accountantAddress, registryAddress, addressToCheck common.Address) (bool, error)
+	GetMystBalance(mystSCAddress, channel common.Address) (*big.Int, error)
+	GetConsumerChannel(addr common.Address, mystSCAddress common.Address) (paymentClient.ConsumerChannel, error)
+	GetAccountantFee(accountantAddress common.Address) (uint16, error)
+}
+
+type failureReporter interface {
+	ReportFailure(err error)
+}
+
+type cacheEntry struct {
+	value   interface{}
+	expires time.Time
+}
+
+// CachedBlockchain caches hot blockchain reads, such as registration status and balances, for a short while
+// and reports failed reads so that the ethereum client can fail over to another endpoint.
+type CachedBlockchain struct {
+	*paymentClient.BlockchainWithRetries
+
+	reader   hotReader
+	ttl      time.Duration
+	failures failureReporter
+	now      func() time.Time
+
+	lock  sync.Mutex
+	cache map[string]cacheEntry
+}
+
+// NewCachedBlockchain wraps the given blockchain with a cache of the given TTL.
+func NewCachedBlockchain(bc *paymentClient.BlockchainWithRetries, ttl time.Duration, failures failureReporter) *CachedBlockchain {
+	return newCachedBlockchain(bc, bc, ttl, failures)
+}
+
+func newCachedBlockchain(bc *paymentClient.BlockchainWithRetries, reader hotReader, ttl time.Duration, failures failureReporter) *CachedBlockchain {
+	return &CachedBlockchain{
+		BlockchainWithRetries: bc,
+		reader:                reader,
+		ttl:                   ttl,
+		failures:              failures,
+		now:                   time.Now,
+		cache:                 make(map[string]cacheEntry),
+	}
+}
+
+// IsRegistered checks whether the given identity is registered, positive results are cached permanently.
+func (cb *CachedBlockchain) IsRegistered(registryAddress, addressToCheck common.Address) (bool, error) {
+	key := "registered:" + registryAddress.Hex() + addressToCheck.Hex()
+	res, err := cb.get(key, func() (interface{}, error) {
+		return cb.reader.IsRegistered(registryAddress, addressToCheck)
+	})
+	if err != nil {
+		return false, err
+	}
+	registered := res.(bool)
+	if registered {
+		cb.setPermanent(key, registered)
+	}
+	return registered, nil
+}
+
+// IsRegisteredAsProvider checks whether the given identity is registered as provider, positive results are cached permanently.
+func (cb *CachedBlockchain) IsRegisteredAsProvider(accountantAddress, registryAddress, addressToCheck common.Address) (bool, error) {
+	key := "provider:" + accountantAddress.Hex() + registryAddress.Hex() + addressToCheck.Hex()
+	res, err := cb.get(key, func() (interface{}, error) {
+		return cb.reader.IsRegisteredAsProvider(accountantAddress, registryAddress, addressToCheck)
+	})
+	if err != nil {
+		return false, err
+	}
+	registered := res.(bool)
+	if registered {
+		cb.setPermanent(key, registered)
+	}
+	return registered, nil
+}
+
+// GetMystBalance returns the MYST balance of the given address.
+func (cb *CachedBlockchain) GetMystBalance(mystSCAddress, channel common.Address) (*big.Int, error) {
+	res, err := cb.get("balance:"+mystSCAddress.Hex()+channel.Hex(), func() (interface{}, error) {
+		return cb.reader.GetMystBalance(mystSCAddress, channel)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Set(res.(*big.Int)), nil
+}
+
+// GetConsumerChannel returns the consumer channel.
+func (cb *CachedBlockchain) GetConsumerChannel(addr common.Address, mystSCAddress common.Address) (paymentClient.ConsumerChannel, error) {
+	res, err := cb.get("channel:"+addr.Hex()+mystSCAddress.Hex(), func() (interface{}, error) {
+		return cb.reader.GetConsumerChannel(addr, mystSCAddress)
+	})
+	if err != nil {
+		return paymentClient.ConsumerChannel{}, err
+	}
+	return res.(paymentClient.ConsumerChannel), nil
+}
+
+// GetAccountantFee returns the fee of the given accountant.
+func (cb *CachedBlockchain) GetAccountantFee(accountantAddress common.Address) (uint16, error) {
+	res, err := cb.get("fee:"+accountantAddress.Hex(), func() (interface{}, error) {
+		return cb.reader.GetAccountantFee(accountantAddress)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return res.(uint16), nil
+}
+
+func (cb *CachedBlockchain) get(key string, fetch func() (interface{}, error)) (interface{}, error) {
+	cb.lock.Lock()
+	entry, ok := cb.cache[key]
+	cb.lock.Unlock()
+	if ok && (entry.expires.IsZero() || cb.now().Before(entry.expires)) {
+		return entry.value, nil
+	}
+
+	value, err := fetch()
+	if err != nil {
+		cb.failures.ReportFailure(err)
+		return nil, err
+	}
+
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	cb.cache[key] = cacheEntry{value: value, expires: cb.now().Add(cb.ttl)}
+	return value, nil
+}
+
+func (cb *CachedBlockchain) setPermanent(key string, value interface{}) {
+	cb.lock.Lock()
+	defer cb.lock.Unlock()
+	cb.cache[key] = cacheEntry{value: value}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blockchain
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHotReader struct {
+	calls      int
+	registered bool
+	balance    *big.Int
+	err        error
+}
+
+func (mhr *mockHotReader) IsRegistered(_, _ common.Address) (bool, error) {
+	mhr.calls++
+	return mhr.registered, mhr.err
+}
+
+func (mhr *mockHotReader) IsRegisteredAsProvider(_, _, _ common.Address) (bool, error) {
+	mhr.calls++
+	return mhr.registered, mhr.err
+}
+
+func (mhr *mockHotReader) GetMystBalance(_, _ common.Address) (*big.Int, error) {
+	mhr.calls++
+	return mhr.balance, mhr.err
+}
+
+func (mhr *mockHotReader) GetConsumerChannel(_ common.Address, _ common.Address) (paymentClient.ConsumerChannel, error) {
+	mhr.calls++
+	return paymentClient.ConsumerChannel{Balance: mhr.balance}, mhr.err
+}
+
+func (mhr *mockHotReader) GetAccountantFee(_ common.Address) (uint16, error) {
+	mhr.calls++
+	return 100, mhr.err
+}
+
+type mockFailureReporter struct {
+	failures int
+}
+
+func (mfr *mockFailureReporter) ReportFailure(_ error) {
+	mfr.failures++
+}
+
+func TestCachedBlockchain_CachesBalanceForTTL(t *testing.T) {
+	reader := &mockHotReader{balance: big.NewInt(10)}
+	now := time.Unix(0, 0)
+	cb := newCachedBlockchain(nil, reader, time.Minute, &mockFailureReporter{})
+	cb.now = func() time.Time { return now }
+
+	balance, err := cb.GetMystBalance(common.Address{}, common.Address{})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), balance)
+
+	reader.balance = big.NewInt(20)
+	balance, err = cb.GetMystBalance(common.Address{}, common.Address{})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(10), balance)
+	assert.Equal(t, 1, reader.calls)
+
+	now = now.Add(2 * time.Minute)
+	balance, err = cb.GetMystBalance(common.Address{}, common.Address{})
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(20), balance)
+	assert.Equal(t, 2, reader.calls)
+}
+
+func TestCachedBlockchain_CachesRegistrationPermanently(t *testing.T) {
+	reader := &mockHotReader{}
+	now := time.Unix(0, 0)
+	cb := newCachedBlockchain(nil, reader, time.Minute, &mockFailureReporter{})
+	cb.now = func() time.Time { return now }
+
+	registered, err := cb.IsRegistered(common.Address{}, common.Address{})
+	assert.NoError(t, err)
+	assert.False(t, registered)
+
+	// negative result expires
+	reader.registered = true
+	now = now.Add(2 * time.Minute)
+	registered, err = cb.IsRegistered(common.Address{}, common.Address{})
+	assert.NoError(t, err)
+	assert.True(t, registered)
+
+	// positive result does not
+	reader.registered = false
+	now = now.Add(time.Hour)
+	registered, err = cb.IsRegistered(common.Address{}, common.Address{})
+	assert.NoError(t, err)
+	assert.True(t, registered)
+	assert.Equal(t, 2, reader.calls)
+}
+
+func TestCachedBlockchain_ReportsFailures(t *testing.T) {
+	reader := &mockHotReader{err: errors.New("connection reset")}
+	failures := &mockFailureReporter{}
+	cb := newCachedBlockchain(nil, reader, time.Minute, failures)
+
+	_, err := cb.GetAccountantFee(common.Address{})
+	assert.Error(t, err)
+	assert.Equal(t, 1, failures.failures)
+
+	// failures are not cached
+	reader.err = nil
+	fee, err := cb.GetAccountantFee(common.Address{})
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(100), fee)
+}
@@ -0,0 +1,293 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blockchain
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/payments/bindings"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+	"github.com/rs/zerolog/log"
+)
+
+const lightPollInterval = 5 * time.Second
+
+// LightBlockchain reads blockchain state through the transactor API instead of a direct ethereum RPC connection.
+// Contract events are not available in this mode, so subscriptions are emulated by polling.
+type LightBlockchain struct {
+	transport         *requests.HTTPClient
+	transactorAddress string
+	pollInterval      time.Duration
+}
+
+// NewLightBlockchain returns a new light blockchain reader backed by the given transactor.
+func NewLightBlockchain(transport *requests.HTTPClient, transactorAddress string) *LightBlockchain {
+	return &LightBlockchain{
+		transport:         transport,
+		transactorAddress: transactorAddress,
+		pollInterval:      lightPollInterval,
+	}
+}
+
+type registeredResponse struct {
+	Registered bool `json:"registered"`
+}
+
+type balanceResponse struct {
+	Balance *big.Int `json:"balance"`
+}
+
+type feeResponse struct {
+	Fee uint16 `json:"fee"`
+}
+
+type consumerChannelResponse struct {
+	Balance *big.Int `json:"balance"`
+	Settled *big.Int `json:"settled"`
+}
+
+type providerChannelResponse struct {
+	Beneficiary   common.Address `json:"beneficiary"`
+	Balance       *big.Int       `json:"balance"`
+	Settled       *big.Int       `json:"settled"`
+	Loan          *big.Int       `json:"loan"`
+	LastUsedNonce *big.Int       `json:"last_used_nonce"`
+	Timelock      *big.Int       `json:"timelock"`
+}
+
+// IsRegistered checks whether the given identity is registered in the registry.
+func (lb *LightBlockchain) IsRegistered(registryAddress, addressToCheck common.Address) (bool, error) {
+	var resp registeredResponse
+	path := fmt.Sprintf("chain/registry/%v/identity/%v", registryAddress.Hex(), addressToCheck.Hex())
+	if err := lb.get(path, nil, &resp); err != nil {
+		return false, fmt.Errorf("could not check registration status: %w", err)
+	}
+	return resp.Registered, nil
+}
+
+// IsRegisteredAsProvider checks whether the given identity is registered as provider with the accountant.
+func (lb *LightBlockchain) IsRegisteredAsProvider(accountantAddress, registryAddress, addressToCheck common.Address) (bool, error) {
+	var resp registeredResponse
+	path := fmt.Sprintf("chain/accountant/%v/provider/%v", accountantAddress.Hex(), addressToCheck.Hex())
+	params := url.Values{"registry": []string{registryAddress.Hex()}}
+	if err := lb.get(path, params, &resp); err != nil {
+		return false, fmt.Errorf("could not check provider registration status: %w", err)
+	}
+	return resp.Registered, nil
+}
+
+// GetMystBalance returns the MYST balance of the given address.
+func (lb *LightBlockchain) GetMystBalance(mystSCAddress, channel common.Address) (*big.Int, error) {
+	var resp balanceResponse
+	path := fmt.Sprintf("chain/token/%v/balance/%v", mystSCAddress.Hex(), channel.Hex())
+	if err := lb.get(path, nil, &resp); err != nil {
+		return nil, fmt.Errorf("could not get balance: %w", err)
+	}
+	return orZero(resp.Balance), nil
+}
+
+// GetConsumerChannel returns the consumer channel.
+func (lb *LightBlockchain) GetConsumerChannel(addr common.Address, mystSCAddress common.Address) (paymentClient.ConsumerChannel, error) {
+	var resp consumerChannelResponse
+	path := fmt.Sprintf("chain/consumer/%v", addr.Hex())
+	params := url.Values{"token": []string{mystSCAddress.Hex()}}
+	if err := lb.get(path, params, &resp); err != nil {
+		return paymentClient.ConsumerChannel{}, fmt.Errorf("could not get consumer channel: %w", err)
+	}
+	return paymentClient.ConsumerChannel{
+		Balance: orZero(resp.Balance),
+		Settled: orZero(resp.Settled),
+	}, nil
+}
+
+// GetProviderChannel returns the provider channel with the given accountant.
+func (lb *LightBlockchain) GetProviderChannel(accountantAddress common.Address, addressToCheck common.Address, pending bool) (paymentClient.ProviderChannel, error) {
+	var resp providerChannelResponse
+	path := fmt.Sprintf("chain/accountant/%v/channel/%v", accountantAddress.Hex(), addressToCheck.Hex())
+	params := url.Values{"pending": []string{strconv.FormatBool(pending)}}
+	if err := lb.get(path, params, &resp); err != nil {
+		return paymentClient.ProviderChannel{}, fmt.Errorf("could not get provider channel: %w", err)
+	}
+	return paymentClient.ProviderChannel{
+		Beneficiary:   resp.Beneficiary,
+		Balance:       orZero(resp.Balance),
+		Settled:       orZero(resp.Settled),
+		Loan:          orZero(resp.Loan),
+		LastUsedNonce: orZero(resp.LastUsedNonce),
+		Timelock:      orZero(resp.Timelock),
+	}, nil
+}
+
+// GetAccountantFee returns the fee of the given accountant.
+func (lb *LightBlockchain) GetAccountantFee(accountantAddress common.Address) (uint16, error) {
+	var resp feeResponse
+	path := fmt.Sprintf("chain/accountant/%v/fee", accountantAddress.Hex())
+	if err := lb.get(path, nil, &resp); err != nil {
+		return 0, fmt.Errorf("could not get accountant fee: %w", err)
+	}
+	return resp.Fee, nil
+}
+
+// TransactionReceipt returns the receipt of a mined transaction.
+func (lb *LightBlockchain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	var receipt types.Receipt
+	if err := lb.getWithContext(ctx, fmt.Sprintf("chain/tx/%v/receipt", txHash.Hex()), nil, &receipt); err != nil {
+		return nil, fmt.Errorf("could not get transaction receipt: %w", err)
+	}
+	return &receipt, nil
+}
+
+// HeaderByNumber returns a block header, the latest one if number is nil.
+func (lb *LightBlockchain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	block := "latest"
+	if number != nil {
+		block = number.String()
+	}
+
+	var header types.Header
+	if err := lb.getWithContext(ctx, "chain/header/"+block, nil, &header); err != nil {
+		return nil, fmt.Errorf("could not get block header: %w", err)
+	}
+	return &header, nil
+}
+
+// SubscribeToConsumerBalanceEvent polls the balance of the given channel and emits a transfer event once it increases.
+// The returned channel is closed once the timeout passes.
+func (lb *LightBlockchain) SubscribeToConsumerBalanceEvent(channel, mystSCAddress common.Address, timeout time.Duration) (chan *bindings.MystTokenTransfer, func(), error) {
+	initial, err := lb.GetMystBalance(mystSCAddress, channel)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sink := make(chan *bindings.MystTokenTransfer)
+	stop, cancel := newCancel()
+	go func() {
+		defer close(sink)
+		deadline := time.After(timeout)
+		for {
+			select {
+			case <-stop:
+				return
+			case <-deadline:
+				return
+			case <-time.After(lb.pollInterval):
+			}
+
+			balance, err := lb.GetMystBalance(mystSCAddress, channel)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Could not poll balance of %v", channel.Hex())
+				continue
+			}
+			if balance.Cmp(initial) <= 0 {
+				continue
+			}
+
+			ev := &bindings.MystTokenTransfer{
+				To:    channel,
+				Value: new(big.Int).Sub(balance, initial),
+			}
+			initial = balance
+			select {
+			case sink <- ev:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return sink, cancel, nil
+}
+
+// SubscribeToPromiseSettledEvent polls the provider channel and emits a settlement event once the settled amount increases.
+func (lb *LightBlockchain) SubscribeToPromiseSettledEvent(providerID, accountantID common.Address) (chan *bindings.AccountantImplementationPromiseSettled, func(), error) {
+	initial, err := lb.GetProviderChannel(accountantID, providerID, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sink := make(chan *bindings.AccountantImplementationPromiseSettled)
+	stop, cancel := newCancel()
+	go func() {
+		defer close(sink)
+		settled := initial.Settled
+		for {
+			select {
+			case <-stop:
+				return
+			case <-time.After(lb.pollInterval):
+			}
+
+			ch, err := lb.GetProviderChannel(accountantID, providerID, false)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Could not poll provider channel of %v", providerID.Hex())
+				continue
+			}
+			if ch.Settled.Cmp(settled) <= 0 {
+				continue
+			}
+
+			ev := &bindings.AccountantImplementationPromiseSettled{
+				Beneficiary:  ch.Beneficiary,
+				Amount:       new(big.Int).Sub(ch.Settled, settled),
+				TotalSettled: ch.Settled,
+			}
+			settled = ch.Settled
+			select {
+			case sink <- ev:
+			case <-stop:
+				return
+			}
+		}
+	}()
+	return sink, cancel, nil
+}
+
+func (lb *LightBlockchain) get(path string, params url.Values, to interface{}) error {
+	return lb.getWithContext(context.Background(), path, params, to)
+}
+
+func (lb *LightBlockchain) getWithContext(ctx context.Context, path string, params url.Values, to interface{}) error {
+	req, err := requests.NewGetRequest(lb.transactorAddress, path, params)
+	if err != nil {
+		return err
+	}
+	return lb.transport.DoRequestAndParseResponse(req.WithContext(ctx), to)
+}
+
+func newCancel() (<-chan struct{}, func()) {
+	stop := make(chan struct{})
+	var once sync.Once
+	return stop, func() {
+		once.Do(func() { close(stop) })
+	}
+}
+
+func orZero(v *big.Int) *big.Int {
+	if v == nil {
+		return big.NewInt(0)
+	}
+	return v
+}
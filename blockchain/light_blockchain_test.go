@@ -0,0 +1,167 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blockchain
+
+import (
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	lightTestAccountant = common.HexToAddress("0x1")
+	lightTestRegistry   = common.HexToAddress("0x2")
+	lightTestIdentity   = common.HexToAddress("0x3")
+	lightTestToken      = common.HexToAddress("0x4")
+)
+
+type mockTransactor struct {
+	lock      sync.Mutex
+	responses map[string]string
+}
+
+func (mt *mockTransactor) set(path, response string) {
+	mt.lock.Lock()
+	defer mt.lock.Unlock()
+	mt.responses[path] = response
+}
+
+func (mt *mockTransactor) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	mt.lock.Lock()
+	defer mt.lock.Unlock()
+	resp, ok := mt.responses[r.URL.Path]
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	fmt.Fprint(w, resp)
+}
+
+func newTestLightBlockchain() (*LightBlockchain, *mockTransactor, func()) {
+	transactor := &mockTransactor{responses: make(map[string]string)}
+	server := httptest.NewServer(transactor)
+	lb := NewLightBlockchain(requests.NewHTTPClient("0.0.0.0", time.Second), server.URL)
+	lb.pollInterval = time.Millisecond
+	return lb, transactor, server.Close
+}
+
+func TestLightBlockchain_Reads(t *testing.T) {
+	lb, transactor, stop := newTestLightBlockchain()
+	defer stop()
+
+	transactor.set("/chain/registry/"+lightTestRegistry.Hex()+"/identity/"+lightTestIdentity.Hex(), `{"registered": true}`)
+	transactor.set("/chain/token/"+lightTestToken.Hex()+"/balance/"+lightTestIdentity.Hex(), `{"balance": 100}`)
+	transactor.set("/chain/consumer/"+lightTestIdentity.Hex(), `{"balance": 100, "settled": 10}`)
+	transactor.set("/chain/accountant/"+lightTestAccountant.Hex()+"/fee", `{"fee": 250}`)
+	transactor.set("/chain/accountant/"+lightTestAccountant.Hex()+"/channel/"+lightTestIdentity.Hex(), `{"beneficiary": "`+lightTestToken.Hex()+`", "loan": 5}`)
+
+	registered, err := lb.IsRegistered(lightTestRegistry, lightTestIdentity)
+	assert.NoError(t, err)
+	assert.True(t, registered)
+
+	balance, err := lb.GetMystBalance(lightTestToken, lightTestIdentity)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), balance)
+
+	consumerChannel, err := lb.GetConsumerChannel(lightTestIdentity, lightTestToken)
+	assert.NoError(t, err)
+	assert.Equal(t, big.NewInt(100), consumerChannel.Balance)
+	assert.Equal(t, big.NewInt(10), consumerChannel.Settled)
+
+	fee, err := lb.GetAccountantFee(lightTestAccountant)
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(250), fee)
+
+	providerChannel, err := lb.GetProviderChannel(lightTestAccountant, lightTestIdentity, false)
+	assert.NoError(t, err)
+	assert.Equal(t, lightTestToken, providerChannel.Beneficiary)
+	assert.Equal(t, big.NewInt(5), providerChannel.Loan)
+	assert.Equal(t, big.NewInt(0), providerChannel.Settled)
+
+	_, err = lb.IsRegisteredAsProvider(lightTestAccountant, lightTestRegistry, lightTestIdentity)
+	assert.Error(t, err)
+}
+
+func TestLightBlockchain_SubscribeToConsumerBalanceEvent(t *testing.T) {
+	lb, transactor, stop := newTestLightBlockchain()
+	defer stop()
+
+	path := "/chain/token/" + lightTestToken.Hex() + "/balance/" + lightTestIdentity.Hex()
+	transactor.set(path, `{"balance": 100}`)
+
+	sink, cancel, err := lb.SubscribeToConsumerBalanceEvent(lightTestIdentity, lightTestToken, time.Minute)
+	assert.NoError(t, err)
+	defer cancel()
+
+	transactor.set(path, `{"balance": 150}`)
+	select {
+	case ev := <-sink:
+		assert.Equal(t, lightTestIdentity, ev.To)
+		assert.Equal(t, big.NewInt(50), ev.Value)
+	case <-time.After(time.Second):
+		t.Fatal("balance event not received")
+	}
+}
+
+func TestLightBlockchain_SubscribeToConsumerBalanceEventTimesOut(t *testing.T) {
+	lb, transactor, stop := newTestLightBlockchain()
+	defer stop()
+
+	transactor.set("/chain/token/"+lightTestToken.Hex()+"/balance/"+lightTestIdentity.Hex(), `{"balance": 100}`)
+
+	sink, cancel, err := lb.SubscribeToConsumerBalanceEvent(lightTestIdentity, lightTestToken, 10*time.Millisecond)
+	assert.NoError(t, err)
+	defer cancel()
+
+	select {
+	case _, more := <-sink:
+		assert.False(t, more)
+	case <-time.After(time.Second):
+		t.Fatal("subscription did not time out")
+	}
+}
+
+func TestLightBlockchain_SubscribeToPromiseSettledEvent(t *testing.T) {
+	lb, transactor, stop := newTestLightBlockchain()
+	defer stop()
+
+	path := "/chain/accountant/" + lightTestAccountant.Hex() + "/channel/" + lightTestIdentity.Hex()
+	transactor.set(path, `{"settled": 10}`)
+
+	sink, cancel, err := lb.SubscribeToPromiseSettledEvent(lightTestIdentity, lightTestAccountant)
+	assert.NoError(t, err)
+	defer cancel()
+
+	transactor.set(path, `{"settled": 25, "beneficiary": "`+lightTestToken.Hex()+`"}`)
+	select {
+	case ev := <-sink:
+		assert.Equal(t, big.NewInt(15), ev.Amount)
+		assert.Equal(t, big.NewInt(25), ev.TotalSettled)
+		assert.Equal(t, lightTestToken, ev.Beneficiary)
+	case <-time.After(time.Second):
+		t.Fatal("settlement event not received")
+	}
+}
@@ -0,0 +1,284 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoHealthyEndpoint indicates that none of the RPC endpoints is reachable.
+var ErrNoHealthyEndpoint = errors.New("no healthy ethereum RPC endpoint")
+
+const probeTimeout = 10 * time.Second
+
+// EndpointStatus describes the health of an ethereum RPC endpoint.
+type EndpointStatus struct {
+	URL       string
+	Active    bool
+	Healthy   bool
+	Latency   time.Duration
+	Error     string
+	CheckedAt time.Time
+}
+
+type endpoint struct {
+	url       string
+	client    *ethclient.Client
+	healthy   bool
+	latency   time.Duration
+	err       error
+	checkedAt time.Time
+}
+
+type dialer func(url string) (*ethclient.Client, error)
+
+type prober func(ctx context.Context, url string, client *ethclient.Client) error
+
+// MultiClient is an ethereum client backed by several RPC endpoints.
+// It uses the healthy endpoint with the lowest latency and fails over to the next one once it stops responding.
+type MultiClient struct {
+	lock      sync.RWMutex
+	endpoints []*endpoint
+	active    int
+
+	dial  dialer
+	probe prober
+	now   func() time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMultiClient connects to the given RPC endpoints, the first one is used until their latencies are measured.
+func NewMultiClient(urls []string) (*MultiClient, error) {
+	return newMultiClient(urls, ethclient.Dial, probeLatestHeader)
+}
+
+func newMultiClient(urls []string, dial dialer, probe prober) (*MultiClient, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("no ethereum RPC endpoints given")
+	}
+
+	mc := &MultiClient{
+		dial:  dial,
+		probe: probe,
+		now:   time.Now,
+		stop:  make(chan struct{}),
+	}
+
+	active := -1
+	for i, url := range urls {
+		e := &endpoint{url: url}
+		e.client, e.err = dial(url)
+		if e.err != nil {
+			log.Warn().Err(e.err).Msgf("Ethereum client failed to connect to %s", url)
+		} else {
+			e.healthy = true
+			if active < 0 {
+				active = i
+			}
+		}
+		mc.endpoints = append(mc.endpoints, e)
+	}
+	if active < 0 {
+		return nil, fmt.Errorf("ethereum client failed to connect: %w", mc.endpoints[0].err)
+	}
+	mc.active = active
+
+	return mc, nil
+}
+
+func probeLatestHeader(ctx context.Context, _ string, client *ethclient.Client) error {
+	_, err := client.HeaderByNumber(ctx, nil)
+	return err
+}
+
+// Client returns the client of the currently active endpoint.
+func (mc *MultiClient) Client() *ethclient.Client {
+	mc.lock.RLock()
+	defer mc.lock.RUnlock()
+
+	return mc.endpoints[mc.active].client
+}
+
+// Reconnect redials all endpoints and selects the active one anew.
+func (mc *MultiClient) Reconnect() error {
+	mc.lock.Lock()
+	var errs []error
+	for _, e := range mc.endpoints {
+		client, err := mc.dial(e.url)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("ethereum client failed to dial %s: %w", e.url, err))
+			continue
+		}
+		if e.client != nil {
+			e.client.Close()
+		}
+		e.client = client
+	}
+	mc.lock.Unlock()
+
+	if len(errs) == len(mc.endpoints) {
+		return errs[0]
+	}
+	return mc.Check()
+}
+
+// Check measures latency of all endpoints and switches to the best healthy one.
+func (mc *MultiClient) Check() error {
+	mc.lock.RLock()
+	endpoints := make([]endpoint, len(mc.endpoints))
+	for i, e := range mc.endpoints {
+		endpoints[i] = *e
+	}
+	mc.lock.RUnlock()
+
+	var wg sync.WaitGroup
+	for i := range endpoints {
+		wg.Add(1)
+		go func(e *endpoint) {
+			defer wg.Done()
+			mc.measure(e)
+		}(&endpoints[i])
+	}
+	wg.Wait()
+
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+
+	for i, e := range endpoints {
+		current := mc.endpoints[i]
+		if current.client != e.client {
+			// reconnected meanwhile, the measurement is stale
+			continue
+		}
+		current.healthy, current.latency, current.err, current.checkedAt = e.healthy, e.latency, e.err, e.checkedAt
+	}
+	return mc.selectActive()
+}
+
+func (mc *MultiClient) measure(e *endpoint) {
+	e.checkedAt = mc.now()
+	if e.client == nil {
+		e.healthy = false
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	e.err = mc.probe(ctx, e.url, e.client)
+	e.latency = time.Since(start)
+	e.healthy = e.err == nil
+}
+
+// ReportFailure marks the active endpoint unhealthy and fails over to the next healthy one.
+func (mc *MultiClient) ReportFailure(err error) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+
+	active := mc.endpoints[mc.active]
+	active.healthy = false
+	active.err = err
+	active.checkedAt = mc.now()
+	if err := mc.selectActive(); err != nil {
+		log.Warn().Err(err).Msg("Ethereum client could not fail over")
+	}
+}
+
+// selectActive switches to the healthy endpoint with the lowest latency,
+// the active endpoint is kept while it is healthy and not twice as slow as the best one to avoid flapping.
+func (mc *MultiClient) selectActive() error {
+	best := -1
+	for i, e := range mc.endpoints {
+		if !e.healthy {
+			continue
+		}
+		if best < 0 || e.latency < mc.endpoints[best].latency {
+			best = i
+		}
+	}
+	if best < 0 {
+		return ErrNoHealthyEndpoint
+	}
+
+	active := mc.endpoints[mc.active]
+	if active.healthy && active.latency <= 2*mc.endpoints[best].latency {
+		return nil
+	}
+
+	log.Info().Msgf("Switching ethereum RPC endpoint from %s to %s", active.url, mc.endpoints[best].url)
+	mc.active = best
+	return nil
+}
+
+// Status returns health of all endpoints.
+func (mc *MultiClient) Status() []EndpointStatus {
+	mc.lock.RLock()
+	defer mc.lock.RUnlock()
+
+	status := make([]EndpointStatus, len(mc.endpoints))
+	for i, e := range mc.endpoints {
+		status[i] = EndpointStatus{
+			URL:       e.url,
+			Active:    i == mc.active,
+			Healthy:   e.healthy,
+			Latency:   e.latency,
+			CheckedAt: e.checkedAt,
+		}
+		if e.err != nil {
+			status[i].Error = e.err.Error()
+		}
+	}
+	return status
+}
+
+// Start checks endpoints periodically until stopped.
+func (mc *MultiClient) Start(interval time.Duration) {
+	if err := mc.Check(); err != nil {
+		log.Warn().Err(err).Msg("Ethereum RPC health check failed")
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-mc.stop:
+			return
+		case <-ticker.C:
+			if err := mc.Check(); err != nil {
+				log.Warn().Err(err).Msg("Ethereum RPC health check failed")
+			}
+		}
+	}
+}
+
+// Stop stops periodic checks.
+func (mc *MultiClient) Stop() {
+	mc.stopOnce.Do(func() {
+		close(mc.stop)
+	})
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package blockchain
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProbe struct {
+	lock    sync.Mutex
+	errs    map[string]error
+	delays  map[string]time.Duration
+	dialErr map[string]error
+}
+
+func newMockProbe() *mockProbe {
+	return &mockProbe{
+		errs:    make(map[string]error),
+		delays:  make(map[string]time.Duration),
+		dialErr: make(map[string]error),
+	}
+}
+
+func (mp *mockProbe) set(url string, delay time.Duration, err error) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	mp.delays[url] = delay
+	mp.errs[url] = err
+}
+
+func (mp *mockProbe) dial(url string) (*ethclient.Client, error) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	if err := mp.dialErr[url]; err != nil {
+		return nil, err
+	}
+	return ethclient.Dial(url)
+}
+
+func (mp *mockProbe) probe(_ context.Context, url string, _ *ethclient.Client) error {
+	mp.lock.Lock()
+	delay, err := mp.delays[url], mp.errs[url]
+	mp.lock.Unlock()
+
+	time.Sleep(delay)
+	return err
+}
+
+func activeURL(mc *MultiClient) string {
+	for _, status := range mc.Status() {
+		if status.Active {
+			return status.URL
+		}
+	}
+	return ""
+}
+
+func TestMultiClient_SelectsFastestHealthyEndpoint(t *testing.T) {
+	mp := newMockProbe()
+	mp.set("http://slow", 60*time.Millisecond, nil)
+	mp.set("http://fast", time.Millisecond, nil)
+
+	mc, err := newMultiClient([]string{"http://slow", "http://fast"}, mp.dial, mp.probe)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://slow", activeURL(mc))
+	assert.NotNil(t, mc.Client())
+
+	assert.NoError(t, mc.Check())
+	assert.Equal(t, "http://fast", activeURL(mc))
+
+	mp.set("http://fast", time.Millisecond, errors.New("timeout"))
+	assert.NoError(t, mc.Check())
+	assert.Equal(t, "http://slow", activeURL(mc))
+
+	mp.set("http://slow", time.Millisecond, errors.New("timeout"))
+	assert.Equal(t, ErrNoHealthyEndpoint, mc.Check())
+
+	for _, status := range mc.Status() {
+		assert.False(t, status.Healthy)
+		assert.Equal(t, "timeout", status.Error)
+	}
+}
+
+func TestMultiClient_ReportFailureFailsOver(t *testing.T) {
+	mp := newMockProbe()
+	mc, err := newMultiClient([]string{"http://first", "http://second"}, mp.dial, mp.probe)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://first", activeURL(mc))
+
+	mc.ReportFailure(errors.New("connection reset"))
+	assert.Equal(t, "http://second", activeURL(mc))
+}
+
+func TestMultiClient_SkipsEndpointsFailingToDial(t *testing.T) {
+	mp := newMockProbe()
+	mp.dialErr["http://broken"] = errors.New("dial failed")
+
+	mc, err := newMultiClient([]string{"http://broken", "http://working"}, mp.dial, mp.probe)
+	assert.NoError(t, err)
+	assert.Equal(t, "http://working", activeURL(mc))
+
+	mp.dialErr["http://working"] = errors.New("dial failed")
+	_, err = newMultiClient([]string{"http://broken", "http://working"}, mp.dial, mp.probe)
+	assert.Error(t, err)
+}
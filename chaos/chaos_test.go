@@ -0,0 +1,168 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chaos
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadScenario(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chaos")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "scenario.json")
+	err = ioutil.WriteFile(path, []byte(`{
+		"seed": 42,
+		"p2p": [{"topic": "p2p-session-connectivity-status", "drop_rate": 0.5, "delay": "1s", "jitter": "250ms"}],
+		"payments": {"fail_rate": 0.1},
+		"session_config": {"corrupt_rate": 1}
+	}`), 0600)
+	require.NoError(t, err)
+
+	scenario, err := LoadScenario(path)
+	assert.NoError(t, err)
+	assert.Equal(t, Scenario{
+		Seed: 42,
+		P2P: []MessageFault{
+			{Topic: p2p.TopicSessionStatus, DropRate: 0.5, Delay: Duration(time.Second), Jitter: Duration(250 * time.Millisecond)},
+		},
+		Payments:      PaymentFault{FailRate: 0.1},
+		SessionConfig: SessionConfigFault{CorruptRate: 1},
+	}, scenario)
+
+	err = ioutil.WriteFile(path, []byte(`{"payments": {"fail_rate": 2}}`), 0600)
+	require.NoError(t, err)
+	_, err = LoadScenario(path)
+	assert.EqualError(t, err, "payments.fail_rate must be in range [0, 1], got 2")
+}
+
+func TestInjector_IsReproducible(t *testing.T) {
+	scenario := Scenario{Seed: 7, P2P: []MessageFault{{DropRate: 0.5, Jitter: Duration(time.Second)}}}
+	first, second := NewInjector(scenario), NewInjector(scenario)
+
+	for i := 0; i < 100; i++ {
+		assert.Equal(t, first.dropMessage(p2p.TopicKeepAlive), second.dropMessage(p2p.TopicKeepAlive))
+		assert.Equal(t, first.messageDelay(p2p.TopicKeepAlive), second.messageDelay(p2p.TopicKeepAlive))
+	}
+}
+
+func TestFaultyChannel_DropsMessagesOfTopic(t *testing.T) {
+	ch := &mockChannel{}
+	faulty := &faultyChannel{Channel: ch, injector: NewInjector(Scenario{
+		P2P: []MessageFault{{Topic: p2p.TopicSessionStatus, DropRate: 1}},
+	})}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := faulty.Send(ctx, p2p.TopicSessionStatus, &p2p.Message{})
+	assert.Equal(t, ErrMessageDropped, err)
+
+	_, err = faulty.Send(context.Background(), p2p.TopicKeepAlive, &p2p.Message{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{p2p.TopicKeepAlive}, ch.sent)
+}
+
+func TestFaultyChannel_DelaysMessages(t *testing.T) {
+	ch := &mockChannel{}
+	faulty := &faultyChannel{Channel: ch, injector: NewInjector(Scenario{
+		P2P: []MessageFault{{Delay: Duration(50 * time.Millisecond)}},
+	})}
+
+	started := time.Now()
+	_, err := faulty.Send(context.Background(), p2p.TopicKeepAlive, &p2p.Message{})
+	assert.NoError(t, err)
+	assert.True(t, time.Since(started) >= 50*time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = faulty.Send(ctx, p2p.TopicKeepAlive, &p2p.Message{})
+	assert.Equal(t, context.DeadlineExceeded, err)
+	assert.Len(t, ch.sent, 1)
+}
+
+func TestFaultyChannel_FailsPayments(t *testing.T) {
+	ch := &mockChannel{}
+	faulty := &faultyChannel{Channel: ch, injector: NewInjector(Scenario{Payments: PaymentFault{FailRate: 1}})}
+
+	_, err := faulty.Send(context.Background(), p2p.TopicPaymentMessage, &p2p.Message{})
+	assert.Equal(t, ErrPaymentFailed, err)
+	assert.Empty(t, ch.sent)
+}
+
+func TestFaultyChannel_CorruptsSessionConfig(t *testing.T) {
+	config := []byte(`{"Ports":[1,2,3]}`)
+	ch := &mockChannel{reply: p2p.ProtoMessage(&pb.SessionResponse{ID: "session", Config: config})}
+	faulty := &faultyChannel{Channel: ch, injector: NewInjector(Scenario{SessionConfig: SessionConfigFault{CorruptRate: 1}})}
+
+	reply, err := faulty.Send(context.Background(), p2p.TopicSessionCreate, &p2p.Message{})
+	assert.NoError(t, err)
+
+	var response pb.SessionResponse
+	assert.NoError(t, reply.UnmarshalProto(&response))
+	assert.Equal(t, "session", response.ID)
+	assert.NotEqual(t, config, response.Config)
+}
+
+func TestWrapListener_InjectsFaultsIntoAcceptedChannels(t *testing.T) {
+	listener := WrapListener(&mockListener{ch: &mockChannel{}}, NewInjector(Scenario{}))
+
+	var accepted p2p.Channel
+	_, err := listener.Listen(identity.FromAddress("0x1"), "noop", func(ch p2p.Channel) {
+		accepted = ch
+	})
+	assert.NoError(t, err)
+	assert.IsType(t, &faultyChannel{}, accepted)
+}
+
+type mockChannel struct {
+	p2p.Channel
+	reply *p2p.Message
+	sent  []string
+}
+
+func (m *mockChannel) Send(_ context.Context, topic string, _ *p2p.Message) (*p2p.Message, error) {
+	m.sent = append(m.sent, topic)
+	return m.reply, nil
+}
+
+func (m *mockChannel) RemoteAddr() *net.UDPAddr {
+	return &net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 1234}
+}
+
+type mockListener struct {
+	p2p.Listener
+	ch p2p.Channel
+}
+
+func (m *mockListener) Listen(_ identity.Identity, _ string, channelHandler func(ch p2p.Channel)) (func(), error) {
+	channelHandler(m.ch)
+	return func() {}, nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package chaos injects faults into payments and P2P communication of the node, so that its resilience
+// paths can be tested with reproducible scenarios. It is wired only into nodes built with the chaos tag.
+package chaos
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// Injector decides which faults to inject according to the scenario.
+type Injector struct {
+	scenario Scenario
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// NewInjector creates fault injector for the scenario.
+func NewInjector(scenario Scenario) *Injector {
+	return &Injector{
+		scenario: scenario,
+		rand:     rand.New(rand.NewSource(scenario.Seed)),
+	}
+}
+
+// dropMessage decides whether the message of the topic is lost.
+func (i *Injector) dropMessage(topic string) bool {
+	for _, fault := range i.messageFaults(topic) {
+		if i.happens(fault.DropRate) {
+			return true
+		}
+	}
+	return false
+}
+
+// messageDelay returns how long the message of the topic is delayed.
+func (i *Injector) messageDelay(topic string) time.Duration {
+	var delay time.Duration
+	for _, fault := range i.messageFaults(topic) {
+		delay += time.Duration(fault.Delay)
+		if fault.Jitter > 0 {
+			delay += time.Duration(i.int63n(int64(fault.Jitter)))
+		}
+	}
+	return delay
+}
+
+// failPayment decides whether the invoice payment fails.
+func (i *Injector) failPayment() bool {
+	return i.happens(i.scenario.Payments.FailRate)
+}
+
+// corruptSessionConfig decides whether the session config is corrupted.
+func (i *Injector) corruptSessionConfig() bool {
+	return i.happens(i.scenario.SessionConfig.CorruptRate)
+}
+
+func (i *Injector) messageFaults(topic string) []MessageFault {
+	var faults []MessageFault
+	for _, fault := range i.scenario.P2P {
+		if fault.Topic == "" || fault.Topic == topic {
+			faults = append(faults, fault)
+		}
+	}
+	return faults
+}
+
+func (i *Injector) happens(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.rand.Float64() < rate
+}
+
+func (i *Injector) int63n(n int64) int64 {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return i.rand.Int63n(n)
+}
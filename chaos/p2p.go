@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// droppedMessageTimeout limits how long sender waits for the reply to the dropped message
+// when its context has no deadline.
+const droppedMessageTimeout = 20 * time.Second
+
+var (
+	// ErrMessageDropped is returned when injected fault drops the message.
+	ErrMessageDropped = errors.New("chaos: message dropped")
+	// ErrPaymentFailed is returned when injected fault fails the invoice payment.
+	ErrPaymentFailed = errors.New("chaos: payment failed")
+)
+
+// WrapDialer injects faults into channels established by the dialer.
+func WrapDialer(dialer p2p.Dialer, injector *Injector) p2p.Dialer {
+	return &faultyDialer{Dialer: dialer, injector: injector}
+}
+
+type faultyDialer struct {
+	p2p.Dialer
+	injector *Injector
+}
+
+// Dial establishes channel with faults injected.
+func (d *faultyDialer) Dial(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition) (p2p.Channel, error) {
+	ch, err := d.Dialer.Dial(ctx, consumerID, providerID, serviceType, contactDef)
+	if err != nil {
+		return nil, err
+	}
+	return &faultyChannel{Channel: ch, injector: d.injector}, nil
+}
+
+// WrapListener injects faults into channels accepted by the listener.
+func WrapListener(listener p2p.Listener, injector *Injector) p2p.Listener {
+	return &faultyListener{Listener: listener, injector: injector}
+}
+
+type faultyListener struct {
+	p2p.Listener
+	injector *Injector
+}
+
+// Listen accepts channels with faults injected.
+func (l *faultyListener) Listen(providerID identity.Identity, serviceType string, channelHandler func(ch p2p.Channel)) (func(), error) {
+	return l.Listener.Listen(providerID, serviceType, func(ch p2p.Channel) {
+		channelHandler(&faultyChannel{Channel: ch, injector: l.injector})
+	})
+}
+
+type faultyChannel struct {
+	p2p.Channel
+	injector *Injector
+}
+
+// Send sends message to peer unless injected fault drops, delays or fails it.
+func (c *faultyChannel) Send(ctx context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
+	if topic == p2p.TopicPaymentMessage && c.injector.failPayment() {
+		log.Warn().Msgf("Chaos: failing payment sent to %s", c.RemoteAddr())
+		return nil, ErrPaymentFailed
+	}
+
+	if c.injector.dropMessage(topic) {
+		log.Warn().Msgf("Chaos: dropping %q message sent to %s", topic, c.RemoteAddr())
+		return nil, waitDropped(ctx)
+	}
+
+	if delay := c.injector.messageDelay(topic); delay > 0 {
+		log.Warn().Msgf("Chaos: delaying %q message sent to %s by %s", topic, c.RemoteAddr(), delay)
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	reply, err := c.Channel.Send(ctx, topic, msg)
+	if err != nil || topic != p2p.TopicSessionCreate || !c.injector.corruptSessionConfig() {
+		return reply, err
+	}
+
+	log.Warn().Msgf("Chaos: corrupting session config received from %s", c.RemoteAddr())
+	return c.injector.corruptSessionResponse(reply)
+}
+
+// waitDropped behaves as the peer never received the message: sender gets no reply until it gives up.
+func waitDropped(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+	case <-time.After(droppedMessageTimeout):
+	}
+	return ErrMessageDropped
+}
+
+func (i *Injector) corruptSessionResponse(reply *p2p.Message) (*p2p.Message, error) {
+	var response pb.SessionResponse
+	if err := reply.UnmarshalProto(&response); err != nil {
+		return reply, nil
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if len(response.Config) == 0 {
+		response.Config = make([]byte, 16)
+	}
+	response.Config = response.Config[:i.rand.Intn(len(response.Config))+1]
+	i.rand.Read(response.Config)
+
+	return p2p.ProtoMessage(&response), nil
+}
@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package chaos
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Scenario describes faults injected into the node. The same seed reproduces the same sequence of faults.
+type Scenario struct {
+	Seed int64 `json:"seed"`
+	// P2P faults apply to messages node sends to its peers.
+	P2P []MessageFault `json:"p2p"`
+	// Payments faults apply to invoice payments consumer sends to provider.
+	Payments PaymentFault `json:"payments"`
+	// SessionConfig faults apply to session config consumer receives from provider.
+	SessionConfig SessionConfigFault `json:"session_config"`
+}
+
+// MessageFault drops or delays P2P messages of the topic, empty topic matches every message.
+type MessageFault struct {
+	Topic    string   `json:"topic"`
+	DropRate float64  `json:"drop_rate"`
+	Delay    Duration `json:"delay"`
+	Jitter   Duration `json:"jitter"`
+}
+
+// PaymentFault fails invoice payments.
+type PaymentFault struct {
+	FailRate float64 `json:"fail_rate"`
+}
+
+// SessionConfigFault corrupts session config.
+type SessionConfigFault struct {
+	CorruptRate float64 `json:"corrupt_rate"`
+}
+
+// Duration is time.Duration given as a string, e.g. "1.5s", in scenario file.
+type Duration time.Duration
+
+// UnmarshalJSON parses duration string.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// LoadScenario reads scenario from JSON file.
+func LoadScenario(path string) (Scenario, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Scenario{}, errors.Wrap(err, "could not read chaos scenario")
+	}
+
+	var scenario Scenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return Scenario{}, errors.Wrap(err, "could not parse chaos scenario")
+	}
+	return scenario, scenario.Validate()
+}
+
+// Validate checks that fault rates are probabilities.
+func (s Scenario) Validate() error {
+	rates := map[string]float64{
+		"payments.fail_rate":          s.Payments.FailRate,
+		"session_config.corrupt_rate": s.SessionConfig.CorruptRate,
+	}
+	for _, fault := range s.P2P {
+		rates["p2p."+fault.Topic+".drop_rate"] = fault.DropRate
+	}
+	for name, rate := range rates {
+		if rate < 0 || rate > 1 {
+			return errors.Errorf("%s must be in range [0, 1], got %v", name, rate)
+		}
+	}
+	return nil
+}
@@ -18,15 +18,18 @@
 package cli
 
 import (
+	"encoding/json"
 	"flag"
 	"fmt"
 	"io"
 	stdlog "log"
+	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
 	"github.com/chzyer/readline"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 	"github.com/urfave/cli/v2"
 
@@ -60,6 +63,7 @@ func NewCommand() *cli.Command {
 	return &cli.Command{
 		Name:   cliCommandName,
 		Usage:  "Starts a CLI client with a Tequilapi",
+		Flags:  []cli.Flag{&config.FlagCLICommand, &config.FlagCLIJSON, &config.FlagCLICompletion},
 		Before: clicontext.LoadUserConfigQuietly,
 		Action: func(ctx *cli.Context) error {
 			config.ParseFlagsNode(ctx)
@@ -69,7 +73,14 @@ func NewCommand() *cli.Command {
 				tequilapi:   tequilapi_client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort),
 			}
 			cmd.RegisterSignalCallback(utils.SoftKiller(cmdCLI.Kill))
+			out.json = ctx.Bool(config.FlagCLIJSON.Name)
 
+			if ctx.IsSet(config.FlagCLICompletion.Name) {
+				return cmdCLI.Complete(ctx.String(config.FlagCLICompletion.Name))
+			}
+			if ctx.IsSet(config.FlagCLICommand.Name) {
+				return describeQuit(cmdCLI.RunScript(ctx.String(config.FlagCLICommand.Name)))
+			}
 			return describeQuit(cmdCLI.Run(ctx.Args()))
 		},
 	}
@@ -99,6 +110,8 @@ const redColor = "\033[31m%s\033[0m"
 const identityDefaultPassphrase = ""
 const statusConnected = "Connected"
 
+var errScriptFailed = errors.New("CLI command failed")
+
 var versionSummary = metadata.VersionAsSummary(metadata.LicenseCopyright(
 	"type 'license --warranty'",
 	"type 'license --conditions'",
@@ -142,8 +155,66 @@ func (c *cliApp) Run(args cli.Args) (err error) {
 	}
 }
 
+// RunScript runs the given commands one by one without user interaction.
+// Commands are separated by semicolons or new lines, lines starting with '#' are skipped.
+// Returns an error if any of the commands has failed.
+func (c *cliApp) RunScript(script string) error {
+	c.completer = newAutocompleter(c.tequilapi, c.fetchedProposals)
+
+	for _, line := range splitScript(script) {
+		c.handleActions(line)
+	}
+
+	if out.failed {
+		return errScriptFailed
+	}
+	return nil
+}
+
+// Complete prints completion candidates for the partial command line, so shells could complete CLI commands.
+func (c *cliApp) Complete(line string) error {
+	// Messages of dynamic completions must not be mixed with candidates.
+	out.writer = os.Stderr
+	candidates := completionCandidates(newAutocompleter(c.tequilapi, c.fetchedProposals), line)
+
+	if out.json {
+		return json.NewEncoder(os.Stdout).Encode(candidates)
+	}
+	for _, candidate := range candidates {
+		fmt.Println(candidate)
+	}
+	return nil
+}
+
+func splitScript(script string) []string {
+	var lines []string
+	for _, line := range strings.FieldsFunc(script, func(r rune) bool { return r == ';' || r == '\n' }) {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func completionCandidates(completer *readline.PrefixCompleter, line string) []string {
+	runes := []rune(line)
+	suffixes, offset := completer.Do(runes, len(runes))
+	prefix := string(runes[len(runes)-offset:])
+
+	candidates := make([]string, 0, len(suffixes))
+	for _, suffix := range suffixes {
+		candidates = append(candidates, strings.TrimSpace(prefix+string(suffix)))
+	}
+	return candidates
+}
+
 // Kill stops cli
 func (c *cliApp) Kill() error {
+	if c.reader == nil {
+		return nil
+	}
 	c.reader.Clean()
 	return c.reader.Close()
 }
@@ -178,6 +249,8 @@ func (c *cliApp) handleActions(line string) {
 		{"proposals", c.proposals},
 		{"service", c.service},
 		{"mmn", c.mmnApiKey},
+		{"backup", c.backups},
+		{"promises", c.promises},
 	}
 
 	for _, cmd := range staticCmds {
@@ -203,7 +276,7 @@ func (c *cliApp) handleActions(line string) {
 func (c *cliApp) service(argsString string) {
 	args := strings.Fields(argsString)
 	if len(args) == 0 {
-		fmt.Println(serviceHelp)
+		text(serviceHelp)
 		return
 	}
 
@@ -211,19 +284,19 @@ func (c *cliApp) service(argsString string) {
 	switch action {
 	case "start":
 		if len(args) < 3 {
-			fmt.Println(serviceHelp)
+			text(serviceHelp)
 			return
 		}
 		c.serviceStart(args[1], args[2], args[3:]...)
 	case "stop":
 		if len(args) < 2 {
-			fmt.Println(serviceHelp)
+			text(serviceHelp)
 			return
 		}
 		c.serviceStop(args[1])
 	case "status":
 		if len(args) < 2 {
-			fmt.Println(serviceHelp)
+			text(serviceHelp)
 			return
 		}
 		c.serviceGet(args[1])
@@ -233,14 +306,14 @@ func (c *cliApp) service(argsString string) {
 		c.serviceSessions()
 	default:
 		info(fmt.Sprintf("Unknown action provided: %s", action))
-		fmt.Println(serviceHelp)
+		text(serviceHelp)
 	}
 }
 
 func (c *cliApp) serviceStart(providerID, serviceType string, args ...string) {
 	serviceOpts, err := parseStartFlags(serviceType, args...)
 	if err != nil {
-		info("Failed to parse service options:", err)
+		warn("Failed to parse service options:", err)
 		return
 	}
 
@@ -250,12 +323,21 @@ func (c *cliApp) serviceStart(providerID, serviceType string, args ...string) {
 		PaymentMethod: contract.ServicePaymentMethod{
 			PriceGB:     serviceOpts.PaymentPricePerGB,
 			PriceMinute: serviceOpts.PaymentPricePerMinute,
+			FreeMinutes: serviceOpts.FreeTrialMinutes,
+			FreeMB:      serviceOpts.FreeTrialMB,
 		},
 		AccessPolicies: contract.ServiceAccessPolicies{IDs: serviceOpts.AccessPolicyList},
-		Options:        serviceOpts.TypeOptions,
+		Unlisted:       serviceOpts.Unlisted,
+		ConsumerPolicy: contract.ServiceConsumerPolicy{
+			AllowedCountries: serviceOpts.ConsumerPolicy.AllowedCountries,
+			DeniedCountries:  serviceOpts.ConsumerPolicy.DeniedCountries,
+			AllowedASNs:      serviceOpts.ConsumerPolicy.AllowedASNs,
+			DeniedASNs:       serviceOpts.ConsumerPolicy.DeniedASNs,
+		},
+		Options: serviceOpts.TypeOptions,
 	})
 	if err != nil {
-		info("Failed to start service: ", err)
+		warn("Failed to start service: ", err)
 		return
 	}
 
@@ -263,11 +345,14 @@ func (c *cliApp) serviceStart(providerID, serviceType string, args ...string) {
 		"ID: "+service.ID,
 		"ProviderID: "+service.Proposal.ProviderID,
 		"Type: "+service.Proposal.ServiceType)
+	if service.InviteCode != "" {
+		info("Invite code: " + service.InviteCode)
+	}
 }
 
 func (c *cliApp) serviceStop(id string) {
 	if err := c.tequilapi.ServiceStop(id); err != nil {
-		info("Failed to stop service: ", err)
+		warn("Failed to stop service: ", err)
 		return
 	}
 
@@ -277,7 +362,10 @@ func (c *cliApp) serviceStop(id string) {
 func (c *cliApp) serviceList() {
 	services, err := c.tequilapi.Services()
 	if err != nil {
-		info("Failed to get a list of services: ", err)
+		warn("Failed to get a list of services: ", err)
+		return
+	}
+	if result(services) {
 		return
 	}
 
@@ -292,7 +380,10 @@ func (c *cliApp) serviceList() {
 func (c *cliApp) serviceSessions() {
 	sessions, err := c.tequilapi.Sessions()
 	if err != nil {
-		info("Failed to get a list of sessions: ", err)
+		warn("Failed to get a list of sessions: ", err)
+		return
+	}
+	if result(sessions) {
 		return
 	}
 
@@ -302,7 +393,7 @@ func (c *cliApp) serviceSessions() {
 			"ID: "+session.ID,
 			"ConsumerID: "+session.ConsumerID,
 			fmt.Sprintf("Data: %s/%s", datasize.FromBytes(session.BytesReceived).String(), datasize.FromBytes(session.BytesSent).String()),
-			fmt.Sprintf("Tokens: %s", money.NewMoney(session.Tokens, money.CurrencyMyst)),
+			fmt.Sprintf("Tokens: %s%s", session.Tokens.Myst, money.CurrencyMyst),
 		)
 	}
 }
@@ -310,7 +401,10 @@ func (c *cliApp) serviceSessions() {
 func (c *cliApp) serviceGet(id string) {
 	service, err := c.tequilapi.Service(id)
 	if err != nil {
-		info("Failed to get service info: ", err)
+		warn("Failed to get service info: ", err)
+		return
+	}
+	if result(service) {
 		return
 	}
 
@@ -323,7 +417,7 @@ func (c *cliApp) serviceGet(id string) {
 func (c *cliApp) connect(argsString string) {
 	args := strings.Fields(argsString)
 
-	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [dns=auto|provider|system|1.1.1.1] [disable-kill-switch]"
+	helpMsg := "Please type in the provider identity. connect <consumer-identity> <provider-identity> <service-type> [dns=auto|provider|system|1.1.1.1] [disable-kill-switch] [ad-block] [traffic-breakdown]"
 	if len(args) < 3 {
 		info(helpMsg)
 		return
@@ -331,7 +425,7 @@ func (c *cliApp) connect(argsString string) {
 
 	consumerID, providerID, serviceType := args[0], args[1], args[2]
 
-	var disableKillSwitch bool
+	var disableKillSwitch, adBlock, trafficBreakdown bool
 	var dns connection.DNSOption
 	var err error
 	for _, arg := range args[3:] {
@@ -348,6 +442,10 @@ func (c *cliApp) connect(argsString string) {
 		switch arg {
 		case "disable-kill-switch":
 			disableKillSwitch = true
+		case "ad-block":
+			adBlock = true
+		case "traffic-breakdown":
+			trafficBreakdown = true
 		default:
 			warn("Unexpected arg:", arg)
 			info(helpMsg)
@@ -358,6 +456,8 @@ func (c *cliApp) connect(argsString string) {
 	connectOptions := contract.ConnectOptions{
 		DNS:               dns,
 		DisableKillSwitch: disableKillSwitch,
+		AdBlock:           adBlock,
+		TrafficBreakdown:  trafficBreakdown,
 	}
 
 	if consumerID == "new" {
@@ -419,7 +519,7 @@ func (c *cliApp) payout(argsString string) {
 		success(fmt.Sprintf("Payout address %s registered.", ethAddress))
 	default:
 		warnf("Unknown sub-command '%s'\n", action)
-		fmt.Println(usage)
+		text(usage)
 		return
 	}
 }
@@ -492,7 +592,10 @@ func (c *cliApp) status() {
 			info(fmt.Sprintf("Connection duration: %s", time.Duration(statistics.Duration)*time.Second))
 			info(fmt.Sprintf("Data: %s/%s", datasize.FromBytes(statistics.BytesReceived), datasize.FromBytes(statistics.BytesSent)))
 			info(fmt.Sprintf("Throughput: %s/%s", datasize.BitSpeed(statistics.ThroughputReceived), datasize.BitSpeed(statistics.ThroughputSent)))
-			info(fmt.Sprintf("Spent: %s", money.NewMoney(statistics.TokensSpent, money.CurrencyMyst)))
+			info(fmt.Sprintf("Spent: %s%s", statistics.TokensSpent.Myst, money.CurrencyMyst))
+			if statistics.DNSQueries > 0 {
+				info(fmt.Sprintf("DNS queries blocked: %d/%d", statistics.DNSBlocked, statistics.DNSQueries))
+			}
 		}
 	}
 }
@@ -503,6 +606,9 @@ func (c *cliApp) healthcheck() {
 		warn(err)
 		return
 	}
+	if result(healthcheck) {
+		return
+	}
 
 	info(fmt.Sprintf("Uptime: %v", healthcheck.Uptime))
 	info(fmt.Sprintf("Process: %v", healthcheck.Process))
@@ -517,6 +623,9 @@ func (c *cliApp) natStatus() {
 		warn("Failed to retrieve NAT traversal status:", err)
 		return
 	}
+	if result(status) {
+		return
+	}
 
 	if status.Error == "" {
 		infof("NAT traversal status: %q\n", status.Status)
@@ -533,8 +642,11 @@ func (c *cliApp) proposals(filter string) {
 	if filter != "" {
 		filterMsg = fmt.Sprintf("(filter: '%s')", filter)
 	}
-	info(fmt.Sprintf("Found %v proposals %s", len(proposals), filterMsg))
+	if !out.json {
+		info(fmt.Sprintf("Found %v proposals %s", len(proposals), filterMsg))
+	}
 
+	matched := []contract.ProposalDTO{}
 	for _, proposal := range proposals {
 		country := proposal.ServiceDefinition.LocationOriginate.Country
 		if country == "" {
@@ -554,9 +666,13 @@ func (c *cliApp) proposals(filter string) {
 			strings.Contains(proposal.ProviderID, filter) ||
 			strings.Contains(country, filter) {
 
-			info(msg)
+			matched = append(matched, proposal)
+			if !out.json {
+				info(msg)
+			}
 		}
 	}
+	result(matched)
 }
 
 func (c *cliApp) fetchProposals() []contract.ProposalDTO {
@@ -578,13 +694,16 @@ func (c *cliApp) location() {
 		warn(err)
 		return
 	}
+	if result(location) {
+		return
+	}
 
 	info(fmt.Sprintf("Location: %s, %s (%s - %s)", location.City, location.Country, location.UserType, location.ISP))
 }
 
 func (c *cliApp) help() {
 	info("Mysterium CLI commands:")
-	fmt.Println(c.completer.Tree("  "))
+	text(c.completer.Tree("  "))
 }
 
 // quit stops cli and client commands and exits application
@@ -602,14 +721,14 @@ func (c *cliApp) stopClient() {
 }
 
 func (c *cliApp) version(argsString string) {
-	fmt.Println(versionSummary)
+	text(versionSummary)
 }
 
 func (c *cliApp) license(argsString string) {
 	if argsString == "warranty" {
-		fmt.Print(metadata.LicenseWarranty)
+		text(metadata.LicenseWarranty)
 	} else if argsString == "conditions" {
-		fmt.Print(metadata.LicenseConditions)
+		text(metadata.LicenseConditions)
 	} else {
 		info("identities command:\n    warranty\n    conditions")
 	}
@@ -684,6 +803,19 @@ func newAutocompleter(tequilapi *tequilapi_client.Client, proposals []contract.P
 			readline.PcItem("topup", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
 			readline.PcItem("beneficiary", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
 			readline.PcItem("settle", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
+			readline.PcItem("withdraw", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
+			readline.PcItem("faucet", readline.PcItemDynamic(getIdentityOptionList(tequilapi))),
+		),
+		readline.PcItem(
+			"backup",
+			readline.PcItem("list"),
+			readline.PcItem("create"),
+			readline.PcItem("restore", readline.PcItemDynamic(getBackupOptionList(tequilapi))),
+		),
+		readline.PcItem(
+			"promises",
+			readline.PcItem("check"),
+			readline.PcItem("repair"),
 		),
 		readline.PcItem("status"),
 		readline.PcItem("healthcheck"),
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+)
+
+func (c *cliApp) backups(argsString string) {
+	var usage = strings.Join([]string{
+		"Usage: backup <action> [args]",
+		"Available actions:",
+		"  " + usageListBackups,
+		"  " + usageCreateBackup,
+		"  " + usageRestoreBackup,
+	}, "\n")
+
+	if len(argsString) == 0 {
+		info(usage)
+		return
+	}
+
+	args := strings.Fields(argsString)
+	action := args[0]
+	actionArgs := args[1:]
+
+	switch action {
+	case "list":
+		c.listBackups(actionArgs)
+	case "create":
+		c.createBackup(actionArgs)
+	case "restore":
+		c.restoreBackup(actionArgs)
+	default:
+		warnf("Unknown sub-command '%s'\n", argsString)
+		text(usage)
+	}
+}
+
+const usageListBackups = "list"
+
+func (c *cliApp) listBackups(args []string) {
+	if len(args) > 0 {
+		info("Usage: " + usageListBackups)
+		return
+	}
+	backups, err := c.tequilapi.Backups()
+	if err != nil {
+		warn(err)
+		return
+	}
+	if result(backups) {
+		return
+	}
+
+	for _, backup := range backups.Backups {
+		status("+", backup.Name, datasize.FromBytes(uint64(backup.Size)).String())
+	}
+}
+
+const usageCreateBackup = "create"
+
+func (c *cliApp) createBackup(args []string) {
+	if len(args) > 0 {
+		info("Usage: " + usageCreateBackup)
+		return
+	}
+	backup, err := c.tequilapi.BackupCreate()
+	if err != nil {
+		warn(err)
+		return
+	}
+	if result(backup) {
+		return
+	}
+
+	success("Backup created:", backup.Name)
+}
+
+const usageRestoreBackup = "restore <name>"
+
+func (c *cliApp) restoreBackup(args []string) {
+	if len(args) != 1 {
+		info("Usage: " + usageRestoreBackup)
+		return
+	}
+	if err := c.tequilapi.BackupRestore(args[0]); err != nil {
+		warn(err)
+		return
+	}
+
+	success("Backup will be restored on the next node start, restart the node to complete it")
+}
+
+func getBackupOptionList(tequilapi *tequilapi_client.Client) func(string) []string {
+	return func(line string) []string {
+		var names []string
+		backups, err := tequilapi.Backups()
+		if err != nil {
+			warn(err)
+			return names
+		}
+		for _, backup := range backups.Backups {
+			names = append(names, backup.Name)
+		}
+
+		return names
+	}
+}
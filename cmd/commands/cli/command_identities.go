@@ -24,8 +24,10 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/faucet"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/withdrawal"
 	"github.com/pkg/errors"
 )
 
@@ -40,6 +42,8 @@ func (c *cliApp) identities(argsString string) {
 		"  " + usageRegisterIdentity,
 		"  " + usageTopupIdentity,
 		"  " + usageSettle,
+		"  " + usageWithdraw,
+		"  " + usageFaucet,
 	}, "\n")
 
 	if len(argsString) == 0 {
@@ -68,9 +72,13 @@ func (c *cliApp) identities(argsString string) {
 		c.topupIdentity(actionArgs)
 	case "settle":
 		c.settle(actionArgs)
+	case "withdraw":
+		c.withdraw(actionArgs)
+	case "faucet":
+		c.faucet(actionArgs)
 	default:
 		warnf("Unknown sub-command '%s'\n", argsString)
-		fmt.Println(usage)
+		text(usage)
 	}
 }
 
@@ -83,7 +91,10 @@ func (c *cliApp) listIdentities(args []string) {
 	}
 	ids, err := c.tequilapi.GetIdentities()
 	if err != nil {
-		fmt.Println("Error occurred:", err)
+		warn("Error occurred:", err)
+		return
+	}
+	if result(ids) {
 		return
 	}
 
@@ -106,11 +117,14 @@ func (c *cliApp) getIdentity(actionArgs []string) {
 		warn(err)
 		return
 	}
+	if result(identityStatus) {
+		return
+	}
 	info("Registration status:", identityStatus.RegistrationStatus)
 	info("Channel address:", identityStatus.ChannelAddress)
-	info(fmt.Sprintf("Balance: %s", money.NewMoney(identityStatus.Balance, money.CurrencyMyst)))
-	info(fmt.Sprintf("Earnings: %s", money.NewMoney(identityStatus.Earnings, money.CurrencyMyst)))
-	info(fmt.Sprintf("Earnings total: %s", money.NewMoney(identityStatus.EarningsTotal, money.CurrencyMyst)))
+	info(fmt.Sprintf("Balance: %s%s", identityStatus.Balance.Myst, money.CurrencyMyst))
+	info(fmt.Sprintf("Earnings: %s%s", identityStatus.Earnings.Myst, money.CurrencyMyst))
+	info(fmt.Sprintf("Earnings total: %s%s", identityStatus.EarningsTotal.Myst, money.CurrencyMyst))
 }
 
 const usageNewIdentity = "new [passphrase]"
@@ -236,13 +250,13 @@ func (c *cliApp) settle(args []string) {
 	for {
 		select {
 		case <-timeout:
-			fmt.Println()
+			progress("\n")
 			warn("Settlement timed out")
 			return
 		case <-time.After(time.Millisecond * 500):
-			fmt.Print(".")
+			progress(".")
 		case err := <-errChan:
-			fmt.Println()
+			progress("\n")
 			if err != nil {
 				warn("settlement failed: ", err.Error())
 				return
@@ -290,7 +304,95 @@ func (c *cliApp) setBeneficiary(actionArgs []string) {
 				return
 			}
 
-			fmt.Print(".")
+			progress(".")
+		}
+	}
+}
+
+const usageWithdraw = "withdraw <providerIdentity>"
+
+func (c *cliApp) withdraw(args []string) {
+	if len(args) != 1 {
+		info("Usage: " + usageWithdraw)
+		return
+	}
+
+	w, err := c.tequilapi.Withdraw(args[0], config.GetString(config.FlagAccountantID))
+	if err != nil {
+		warn(errors.Wrap(err, "could not withdraw"))
+		return
+	}
+	info("Withdrawing earnings to " + w.Beneficiary)
+
+	timeout := time.After(5 * time.Minute)
+	for {
+		select {
+		case <-timeout:
+			progress("\n")
+			warn("Withdrawal is still in progress, check its status later")
+			return
+		case <-time.After(time.Second):
+			w, err = c.tequilapi.WithdrawalStatus(args[0])
+			if err != nil {
+				warn(err)
+				continue
+			}
+
+			switch w.Status {
+			case string(withdrawal.StatusConfirmed):
+				progress("\n")
+				success("Withdrawal confirmed in transaction " + w.TxHash)
+				return
+			case string(withdrawal.StatusFailed):
+				progress("\n")
+				warn("Withdrawal failed: " + w.Error)
+				return
+			}
+			progress(".")
+		}
+	}
+}
+
+const usageFaucet = "faucet <identity>"
+
+func (c *cliApp) faucet(args []string) {
+	if len(args) != 1 {
+		info("Usage: " + usageFaucet)
+		return
+	}
+
+	g, err := c.tequilapi.FaucetRequest(args[0])
+	if err != nil {
+		warn(errors.Wrap(err, "could not request test tokens"))
+		return
+	}
+	info("Requesting test tokens for " + g.Identity + ", MYST will be sent to channel " + g.Channel)
+
+	timeout := time.After(5 * time.Minute)
+	for {
+		select {
+		case <-timeout:
+			progress("\n")
+			warn("Test tokens are still being granted, check faucet status later")
+			return
+		case <-time.After(time.Second):
+			g, err = c.tequilapi.FaucetStatus(args[0])
+			if err != nil {
+				warn(err)
+				continue
+			}
+
+			switch g.Status {
+			case string(faucet.StatusGranted):
+				progress("\n")
+				success("Test tokens granted in transaction " + g.TxHash)
+				return
+			case string(faucet.StatusFailed):
+				progress("\n")
+				warn("Faucet grant failed: " + g.Error)
+				return
+			}
+			progress(".")
 		}
 	}
 }
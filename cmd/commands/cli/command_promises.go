@@ -0,0 +1,91 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"strings"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+func (c *cliApp) promises(argsString string) {
+	var usage = strings.Join([]string{
+		"Usage: promises <action>",
+		"Available actions:",
+		"  " + usageCheckPromises,
+		"  " + usageRepairPromises,
+	}, "\n")
+
+	if len(argsString) == 0 {
+		info(usage)
+		return
+	}
+
+	switch argsString {
+	case "check":
+		c.checkPromises()
+	case "repair":
+		c.repairPromises()
+	default:
+		warnf("Unknown sub-command '%s'\n", argsString)
+		text(usage)
+	}
+}
+
+const usageCheckPromises = "check"
+
+func (c *cliApp) checkPromises() {
+	journal, err := c.tequilapi.PromiseJournal()
+	if err != nil {
+		warn(err)
+		return
+	}
+	if result(journal) {
+		return
+	}
+
+	printPromiseJournal(journal)
+	if len(journal.Corrupted) > 0 {
+		warn("Promise journal is corrupted, run 'promises repair' to recover it")
+	}
+}
+
+const usageRepairPromises = "repair"
+
+func (c *cliApp) repairPromises() {
+	journal, err := c.tequilapi.PromiseJournalRepair()
+	if err != nil {
+		warn(err)
+		return
+	}
+	if result(journal) {
+		return
+	}
+
+	success("Promise journal repaired")
+	printPromiseJournal(journal)
+}
+
+func printPromiseJournal(journal contract.PromiseJournalDTO) {
+	status("Entries:", journal.Entries)
+	status("Pending:", journal.Pending)
+	status("Abandoned:", journal.Abandoned)
+	for _, channel := range journal.Corrupted {
+		status("Corrupted:", channel)
+	}
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cli
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/chzyer/readline"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitScript(t *testing.T) {
+	script := `
+# connect to the provider
+connect 0x1 0x2 wireguard; status
+;  nat  `
+	assert.Equal(t, []string{"connect 0x1 0x2 wireguard", "status", "nat"}, splitScript(script))
+}
+
+func TestCompletionCandidates(t *testing.T) {
+	completer := readline.NewPrefixCompleter(
+		readline.PcItem("identities",
+			readline.PcItem("list"),
+			readline.PcItem("unlock"),
+		),
+		readline.PcItem("status"),
+	)
+
+	assert.Equal(t, []string{"identities", "status"}, completionCandidates(completer, ""))
+	assert.Equal(t, []string{"identities"}, completionCandidates(completer, "id"))
+	assert.Equal(t, []string{"list", "unlock"}, completionCandidates(completer, "identities "))
+	assert.Equal(t, []string{"unlock"}, completionCandidates(completer, "identities u"))
+}
+
+func TestJSONOutput(t *testing.T) {
+	defer func(original *printer) { out = original }(out)
+	var buf bytes.Buffer
+	out = &printer{writer: &buf, json: true}
+
+	status("Connected", "ID:", "1")
+	info("Usage:", "list")
+	assert.True(t, result(map[string]string{"status": "Connected"}))
+	assert.False(t, out.failed)
+	warn("Failed")
+
+	assert.Equal(t, `{"level":"status","label":"Connected","message":"ID: 1"}
+{"level":"info","label":"INFO","message":"Usage: list"}
+{"level":"result","data":{"status":"Connected"}}
+{"level":"warning","label":"WARNING","message":"Failed"}
+`, buf.String())
+	assert.True(t, out.failed)
+}
+
+func TestTextOutput(t *testing.T) {
+	defer func(original *printer) { out = original }(out)
+	var buf bytes.Buffer
+	out = &printer{writer: &buf}
+
+	success("Connected.")
+	assert.False(t, result("ignored"))
+	progress(".")
+
+	assert.Equal(t, successColor+"[SUCCESS] \033[0mConnected.\n.", buf.String())
+}
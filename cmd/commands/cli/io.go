@@ -14,11 +14,14 @@
  * You should have received a copy of the GNU General Public License
  * along with this program.  If not, see <http://www.gnu.org/licenses/>.
  */
-
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 )
 
 const statusColor = "\033[33m"
@@ -26,32 +29,86 @@ const warningColor = "\033[31m"
 const successColor = "\033[32m"
 const infoColor = "\033[93m"
 
+// printer writes CLI messages either as colored text for humans or as JSON lines for scripts.
+type printer struct {
+	writer io.Writer
+	json   bool
+	failed bool
+}
+
+var out = &printer{writer: os.Stdout}
+
+// jsonMessage is a single line of JSON output.
+type jsonMessage struct {
+	Level   string      `json:"level"`
+	Label   string      `json:"label,omitempty"`
+	Message string      `json:"message,omitempty"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (p *printer) print(color, level, label, message string) {
+	if p.json {
+		p.encode(jsonMessage{Level: level, Label: label, Message: strings.TrimRight(message, "\n")})
+		return
+	}
+	fmt.Fprintf(p.writer, color+"[%s] \033[0m", label)
+	fmt.Fprint(p.writer, message)
+}
+
+func (p *printer) encode(msg jsonMessage) {
+	if err := json.NewEncoder(p.writer).Encode(msg); err != nil {
+		fmt.Fprintln(os.Stderr, "Failed to encode output:", err)
+	}
+}
+
 func status(label string, items ...interface{}) {
-	fmt.Printf(statusColor+"[%s] \033[0m", label)
-	fmt.Println(items...)
+	out.print(statusColor, "status", label, fmt.Sprintln(items...))
 }
 
 func warn(items ...interface{}) {
-	fmt.Printf(warningColor + "[WARNING] \033[0m")
-	fmt.Println(items...)
+	out.failed = true
+	out.print(warningColor, "warning", "WARNING", fmt.Sprintln(items...))
 }
 
 func warnf(format string, items ...interface{}) {
-	fmt.Printf(warningColor + "[WARNING] \033[0m")
-	fmt.Printf(format, items...)
+	out.failed = true
+	out.print(warningColor, "warning", "WARNING", fmt.Sprintf(format, items...))
 }
 
 func success(items ...interface{}) {
-	fmt.Printf(successColor + "[SUCCESS] \033[0m")
-	fmt.Println(items...)
+	out.print(successColor, "success", "SUCCESS", fmt.Sprintln(items...))
 }
 
 func info(items ...interface{}) {
-	fmt.Printf(infoColor + "[INFO] \033[0m")
-	fmt.Println(items...)
+	out.print(infoColor, "info", "INFO", fmt.Sprintln(items...))
 }
 
 func infof(format string, items ...interface{}) {
-	fmt.Printf(infoColor + "[INFO] \033[0m")
-	fmt.Printf(format, items...)
+	out.print(infoColor, "info", "INFO", fmt.Sprintf(format, items...))
+}
+
+// text prints a block of plain text, like usage or license.
+func text(s string) {
+	if out.json {
+		out.encode(jsonMessage{Level: "info", Message: strings.TrimRight(s, "\n")})
+		return
+	}
+	fmt.Fprintln(out.writer, s)
+}
+
+// progress prints a progress mark while waiting for long running actions, it is omitted from JSON output.
+func progress(mark string) {
+	if !out.json {
+		fmt.Fprint(out.writer, mark)
+	}
+}
+
+// result prints the data returned by a command when JSON output is enabled.
+// Returns false if the command should print human readable output instead.
+func result(data interface{}) bool {
+	if !out.json {
+		return false
+	}
+	out.encode(jsonMessage{Level: "result", Data: data})
+	return true
 }
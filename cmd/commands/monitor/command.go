@@ -0,0 +1,197 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/mysteriumnetwork/node/cmd"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
+	"github.com/mysteriumnetwork/node/core/node"
+	tequilapi_client "github.com/mysteriumnetwork/node/tequilapi/client"
+)
+
+const (
+	refreshInterval   = time.Second
+	reconnectInterval = 3 * time.Second
+
+	enterAltScreen = "\033[?1049h\033[?25l"
+	exitAltScreen  = "\033[?25h\033[?1049l"
+	clearScreen    = "\033[H\033[2J"
+)
+
+// NewCommand creates a command which shows live node dashboard in the terminal
+func NewCommand() *cli.Command {
+	return &cli.Command{
+		Name:   "monitor",
+		Usage:  "Shows live dashboard of the running node in the terminal",
+		Before: clicontext.LoadUserConfigQuietly,
+		Action: func(ctx *cli.Context) error {
+			config.ParseFlagsNode(ctx)
+			nodeOptions := node.GetOptions()
+
+			m := newMonitor(tequilapi_client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort))
+			cmd.RegisterSignalCallback(m.Stop)
+			return m.Run()
+		},
+	}
+}
+
+type stateSource interface {
+	StateEvents(states chan<- tequilapi_client.StateDTO, stop <-chan struct{}) error
+}
+
+type monitor struct {
+	source    stateSource
+	dashboard dashboard
+	states    chan tequilapi_client.StateDTO
+	errors    chan error
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+func newMonitor(source stateSource) *monitor {
+	return &monitor{
+		source: source,
+		states: make(chan tequilapi_client.StateDTO),
+		errors: make(chan error),
+		stop:   make(chan struct{}),
+	}
+}
+
+// Run shows the dashboard until it is stopped or user presses 'q'.
+func (m *monitor) Run() error {
+	fd := int(os.Stdin.Fd())
+	if terminal.IsTerminal(fd) {
+		oldState, err := terminal.MakeRaw(fd)
+		if err != nil {
+			return err
+		}
+		defer terminal.Restore(fd, oldState)
+		go m.readKeys()
+	}
+
+	fmt.Print(enterAltScreen)
+	defer fmt.Print(exitAltScreen)
+
+	go m.subscribe()
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		if err := m.draw(); err != nil {
+			return err
+		}
+
+		select {
+		case state := <-m.states:
+			m.dashboard.update(state, time.Now())
+		case err := <-m.errors:
+			m.dashboard.unreachable(err, time.Now())
+		case <-ticker.C:
+		case <-m.stop:
+			return nil
+		}
+	}
+}
+
+// Stop stops the dashboard.
+func (m *monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// subscribe keeps node state stream open, reconnecting when it is closed.
+func (m *monitor) subscribe() {
+	for {
+		received := make(chan tequilapi_client.StateDTO)
+		done := make(chan error, 1)
+		go func() {
+			done <- m.source.StateEvents(received, m.stop)
+		}()
+
+		// Node closes long running requests, so errors are reported only if no state was received since reconnecting.
+		delivered := false
+	stream:
+		for {
+			select {
+			case state := <-received:
+				delivered = true
+				select {
+				case m.states <- state:
+				case <-m.stop:
+					return
+				}
+			case err := <-done:
+				if err != nil && !delivered {
+					select {
+					case m.errors <- err:
+					case <-m.stop:
+						return
+					}
+				}
+				break stream
+			}
+		}
+
+		select {
+		case <-time.After(reconnectInterval):
+		case <-m.stop:
+			return
+		}
+	}
+}
+
+func (m *monitor) readKeys() {
+	key := make([]byte, 1)
+	for {
+		if _, err := os.Stdin.Read(key); err != nil {
+			return
+		}
+		switch key[0] {
+		case 'q', 'Q', 3: // 3 is Ctrl+C in raw mode
+			m.Stop()
+			return
+		}
+	}
+}
+
+func (m *monitor) draw() error {
+	width, height, err := terminal.GetSize(int(os.Stdout.Fd()))
+	if err != nil {
+		width, height = 0, 0
+	}
+
+	var buf bytes.Buffer
+	if err := m.dashboard.render(&buf, width, height, time.Now()); err != nil {
+		return err
+	}
+	// Terminal is in raw mode, so new lines have to return the carriage explicitly.
+	_, err = fmt.Print(clearScreen + strings.TrimSuffix(strings.Replace(buf.String(), "\n", "\r\n", -1), "\r\n"))
+	return err
+}
@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package monitor
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStateSource struct {
+	states []client.StateDTO
+	err    error
+}
+
+func (s *mockStateSource) StateEvents(states chan<- client.StateDTO, stop <-chan struct{}) error {
+	for _, state := range s.states {
+		states <- state
+	}
+	return s.err
+}
+
+func TestMonitor_SubscribeDeliversStates(t *testing.T) {
+	m := newMonitor(&mockStateSource{states: []client.StateDTO{stateWithSessions()}, err: errors.New("timeout")})
+	defer m.Stop()
+	go m.subscribe()
+
+	select {
+	case state := <-m.states:
+		assert.Equal(t, "successful", state.NATStatus.Status)
+	case <-time.After(time.Second):
+		t.Fatal("state was not delivered")
+	}
+
+	select {
+	case err := <-m.errors:
+		t.Fatalf("unexpected error after state was delivered: %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestMonitor_SubscribeReportsUnreachableNode(t *testing.T) {
+	m := newMonitor(&mockStateSource{err: errors.New("connection refused")})
+	defer m.Stop()
+	go m.subscribe()
+
+	select {
+	case err := <-m.errors:
+		assert.EqualError(t, err, "connection refused")
+	case <-time.After(time.Second):
+		t.Fatal("error was not reported")
+	}
+}
@@ -0,0 +1,222 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package monitor
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/tequilapi/client"
+)
+
+const maxEvents = 10
+
+// event is a notable node state change shown in the dashboard.
+type event struct {
+	time    time.Time
+	message string
+}
+
+// dashboard keeps the latest node state and renders it as a terminal screen.
+type dashboard struct {
+	state     client.StateDTO
+	received  bool
+	updatedAt time.Time
+
+	bytesSent      uint64
+	bytesReceived  uint64
+	throughputUp   datasize.BitSpeed
+	throughputDown datasize.BitSpeed
+
+	events []event
+}
+
+// update applies the new node state, records notable changes as events and calculates bandwidth.
+func (d *dashboard) update(state client.StateDTO, now time.Time) {
+	var sent, received uint64
+	for _, s := range state.Sessions {
+		sent += s.BytesSent
+		received += s.BytesReceived
+	}
+
+	if d.received {
+		d.recordChanges(state, now)
+
+		elapsed := now.Sub(d.updatedAt).Seconds()
+		if elapsed > 0 {
+			d.throughputUp = bitSpeed(sent, d.bytesSent, elapsed)
+			d.throughputDown = bitSpeed(received, d.bytesReceived, elapsed)
+		}
+	}
+
+	d.state = state
+	d.received = true
+	d.updatedAt = now
+	d.bytesSent = sent
+	d.bytesReceived = received
+}
+
+// unreachable records that the node state stream is not available.
+func (d *dashboard) unreachable(err error, now time.Time) {
+	d.record(now, fmt.Sprintf("Node is not reachable: %v", err))
+}
+
+func (d *dashboard) recordChanges(state client.StateDTO, now time.Time) {
+	if state.NATStatus.Status != d.state.NATStatus.Status {
+		d.record(now, fmt.Sprintf("NAT status changed to %s", state.NATStatus.Status))
+	}
+	if state.BrokerStatus.Status != d.state.BrokerStatus.Status {
+		d.record(now, fmt.Sprintf("Broker %s", state.BrokerStatus.Status))
+	}
+	if state.Consumer.Connection.Status != d.state.Consumer.Connection.Status {
+		d.record(now, fmt.Sprintf("Connection status changed to %s", state.Consumer.Connection.Status))
+	}
+
+	previous := make(map[string]bool, len(d.state.Sessions))
+	for _, s := range d.state.Sessions {
+		previous[s.ID] = true
+	}
+	current := make(map[string]bool, len(state.Sessions))
+	for _, s := range state.Sessions {
+		current[s.ID] = true
+		if !previous[s.ID] {
+			d.record(now, fmt.Sprintf("Session %s started by %s (%s)", s.ID, s.ConsumerID, s.ServiceType))
+		}
+	}
+	for _, s := range d.state.Sessions {
+		if !current[s.ID] {
+			d.record(now, fmt.Sprintf("Session %s ended", s.ID))
+		}
+	}
+}
+
+func (d *dashboard) record(now time.Time, message string) {
+	d.events = append(d.events, event{time: now, message: message})
+	if len(d.events) > maxEvents {
+		d.events = d.events[len(d.events)-maxEvents:]
+	}
+}
+
+// render writes the dashboard screen fitted into the given terminal size.
+func (d *dashboard) render(w io.Writer, width, height int, now time.Time) error {
+	var lines []string
+	add := func(format string, args ...interface{}) {
+		lines = append(lines, fmt.Sprintf(format, args...))
+	}
+
+	add("Mysterium node monitor - %s (press q to quit)", now.Format("2006-01-02 15:04:05"))
+	add("")
+	if !d.received {
+		add("Waiting for node state...")
+	} else {
+		connection := d.state.Consumer.Connection.Status
+		add("NAT: %s   Broker: %s   Connection: %s", orNone(d.state.NATStatus.Status), orNone(d.state.BrokerStatus.Status), orNone(connection))
+		add("Bandwidth: down %s   up %s", d.throughputDown, d.throughputUp)
+		add("Data: down %s   up %s", datasize.FromBytes(d.bytesReceived), datasize.FromBytes(d.bytesSent))
+
+		for _, id := range d.state.Identities {
+			add("Identity %s: earnings %s, total %s, balance %s", id.Address,
+				id.Earnings.Myst+string(money.CurrencyMyst),
+				id.EarningsTotal.Myst+string(money.CurrencyMyst),
+				id.Balance.Myst+string(money.CurrencyMyst),
+			)
+		}
+
+		add("")
+		add("Services (%d)", len(d.state.Services))
+		for _, s := range d.state.Services {
+			add("  %-10s %-12s %s", s.Type, s.Status, s.ID)
+		}
+
+		add("")
+		add("Sessions (%d)", len(d.state.Sessions))
+		if len(d.state.Sessions) > 0 {
+			lines = append(lines, d.sessionsTable()...)
+		}
+	}
+
+	var footer []string
+	if len(d.events) > 0 {
+		footer = append(footer, "", "Recent events")
+		for i := len(d.events) - 1; i >= 0; i-- {
+			footer = append(footer, fmt.Sprintf("  %s %s", d.events[i].time.Format("15:04:05"), d.events[i].message))
+		}
+	}
+
+	// Sessions table is the longest part, so it gets cut first when the screen is too small.
+	if height > 0 && len(lines)+len(footer) > height {
+		if len(footer) > height/2 {
+			footer = footer[:height/2]
+		}
+		if len(lines) > height-len(footer) {
+			lines = lines[:height-len(footer)]
+		}
+	}
+	lines = append(lines, footer...)
+
+	var buf bytes.Buffer
+	for _, line := range lines {
+		if width > 0 && len(line) > width {
+			line = line[:width]
+		}
+		buf.WriteString(line)
+		buf.WriteString("\n")
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+func (d *dashboard) sessionsTable() []string {
+	var buf bytes.Buffer
+	table := tabwriter.NewWriter(&buf, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(table, "  ID\tCONSUMER\tSERVICE\tDURATION\tDOWN\tUP\tTOKENS")
+	for _, s := range d.state.Sessions {
+		fmt.Fprintf(table, "  %s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			s.ID,
+			s.ConsumerID,
+			s.ServiceType,
+			time.Duration(s.Duration)*time.Second,
+			datasize.FromBytes(s.BytesReceived),
+			datasize.FromBytes(s.BytesSent),
+			s.Tokens.Myst+string(money.CurrencyMyst),
+		)
+	}
+	table.Flush()
+	return strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+}
+
+func bitSpeed(total, previous uint64, seconds float64) datasize.BitSpeed {
+	if total < previous {
+		// Sessions ended since the last update, their traffic is not accounted anymore.
+		return 0
+	}
+	return datasize.BitSpeed(float64(datasize.FromBytes(total-previous)) / seconds)
+}
+
+func orNone(status string) string {
+	if status == "" {
+		return "-"
+	}
+	return status
+}
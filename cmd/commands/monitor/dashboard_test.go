@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package monitor
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+var now = time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func stateWithSessions(sessions ...contract.SessionDTO) client.StateDTO {
+	return client.StateDTO{
+		NATStatus:    contract.NATStatusDTO{Status: "successful"},
+		BrokerStatus: contract.BrokerStatusDTO{Status: "connected"},
+		Sessions:     sessions,
+	}
+}
+
+func TestDashboard_UpdateRecordsEvents(t *testing.T) {
+	var d dashboard
+	d.update(stateWithSessions(), now)
+	assert.Empty(t, d.events)
+
+	d.update(stateWithSessions(contract.SessionDTO{ID: "s1", ConsumerID: "0x1", ServiceType: "wireguard"}), now.Add(time.Second))
+
+	state := stateWithSessions()
+	state.NATStatus.Status = "failure"
+	d.update(state, now.Add(2*time.Second))
+
+	var messages []string
+	for _, e := range d.events {
+		messages = append(messages, e.message)
+	}
+	assert.Equal(t, []string{
+		"Session s1 started by 0x1 (wireguard)",
+		"NAT status changed to failure",
+		"Session s1 ended",
+	}, messages)
+}
+
+func TestDashboard_UpdateCalculatesBandwidth(t *testing.T) {
+	var d dashboard
+	d.update(stateWithSessions(contract.SessionDTO{ID: "s1", BytesSent: 1000, BytesReceived: 4000}), now)
+	d.update(stateWithSessions(contract.SessionDTO{ID: "s1", BytesSent: 3000, BytesReceived: 8000}), now.Add(2*time.Second))
+
+	assert.Equal(t, 8000.0, float64(d.throughputUp))
+	assert.Equal(t, 16000.0, float64(d.throughputDown))
+
+	d.update(stateWithSessions(), now.Add(3*time.Second))
+	assert.Zero(t, float64(d.throughputUp))
+}
+
+func TestDashboard_KeepsLimitedEvents(t *testing.T) {
+	var d dashboard
+	for i := 0; i < maxEvents+5; i++ {
+		d.unreachable(errors.New("connection refused"), now)
+	}
+	assert.Len(t, d.events, maxEvents)
+}
+
+func TestDashboard_RenderFitsScreen(t *testing.T) {
+	var d dashboard
+	var sessions []contract.SessionDTO
+	for i := 0; i < 50; i++ {
+		sessions = append(sessions, contract.SessionDTO{ID: strings.Repeat("a", 100)})
+	}
+	d.update(stateWithSessions(sessions...), now)
+	d.unreachable(errors.New("connection refused"), now)
+
+	var buf bytes.Buffer
+	assert.NoError(t, d.render(&buf, 80, 20, now))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 20)
+	for _, line := range lines {
+		assert.True(t, len(line) <= 80)
+	}
+	assert.Contains(t, buf.String(), "NAT: successful   Broker: connected")
+	assert.Contains(t, buf.String(), "Node is not reachable: connection refused")
+}
+
+func TestDashboard_RenderWaitsForState(t *testing.T) {
+	var d dashboard
+
+	var buf bytes.Buffer
+	assert.NoError(t, d.render(&buf, 0, 0, now))
+	assert.Contains(t, buf.String(), "Waiting for node state...")
+}
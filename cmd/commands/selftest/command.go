@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package selftest
+
+import (
+	"os"
+
+	"github.com/mysteriumnetwork/node/cmd"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/services/noop"
+	"github.com/mysteriumnetwork/node/tequilapi/client"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// NewCommand function creates selftest command
+func NewCommand(licenseCommandName string) *cli.Command {
+	var di cmd.Dependencies
+	command := &cli.Command{
+		Name:      "selftest",
+		Usage:     "Starts a service and connects to it as a consumer to verify the node can serve sessions",
+		ArgsUsage: "service type to test (noop by default, wireguard also verifies traffic)",
+		Before:    clicontext.LoadUserConfigQuietly,
+		Action: func(ctx *cli.Context) error {
+			if !ctx.Bool(config.FlagAgreedTermsConditions.Name) {
+				return errors.Errorf("self-test publishes a service, agree with terms & conditions ('myst %s') by running it with '--%s' flag",
+					licenseCommandName, config.FlagAgreedTermsConditions.Name)
+			}
+
+			config.ParseFlagsServiceStart(ctx)
+			config.ParseFlagsServiceOpenvpn(ctx)
+			config.ParseFlagsServiceWireguard(ctx)
+			config.ParseFlagsServiceNoop(ctx)
+			config.ParseFlagsNode(ctx)
+
+			nodeOptions := node.GetOptions()
+			if err := di.Bootstrap(*nodeOptions); err != nil {
+				return err
+			}
+
+			serviceType := ctx.Args().Get(0)
+			if serviceType == "" {
+				serviceType = noop.ServiceType
+			}
+			test := newSelfTest(client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort), options{
+				ServiceType:  serviceType,
+				Identity:     ctx.String(config.FlagIdentity.Name),
+				Passphrase:   ctx.String(config.FlagIdentityPassphrase.Name),
+				AccountantID: nodeOptions.Accountant.AccountantID,
+				Timeout:      ctx.Duration(config.FlagSelftestTimeout.Name),
+			})
+
+			results := make(chan []stageResult, 1)
+			go func() { results <- test.Run() }()
+
+			interrupted := make(chan struct{})
+			cmd.RegisterSignalCallback(func() { close(interrupted) })
+
+			select {
+			case r := <-results:
+				return printReport(os.Stdout, r)
+			case <-interrupted:
+				return errors.New("self-test interrupted")
+			}
+		},
+		After: func(ctx *cli.Context) error {
+			return di.Shutdown()
+		},
+	}
+
+	config.RegisterFlagsSelftest(&command.Flags)
+	config.RegisterFlagsServiceStart(&command.Flags)
+	config.RegisterFlagsServiceOpenvpn(&command.Flags)
+	config.RegisterFlagsServiceWireguard(&command.Flags)
+	config.RegisterFlagsServiceNoop(&command.Flags)
+
+	return command
+}
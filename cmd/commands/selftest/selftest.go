@@ -0,0 +1,319 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package selftest
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/services"
+	"github.com/mysteriumnetwork/node/services/noop"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	natStatusSuccessful  = "successful"
+	natStatusNotFinished = "not_finished"
+)
+
+// tequilapiClient is the part of Tequilapi client used to drive the node under test.
+type tequilapiClient interface {
+	GetIdentities() ([]contract.IdentityRefDTO, error)
+	NewIdentity(passphrase string) (contract.IdentityRefDTO, error)
+	CurrentIdentity(identity, passphrase string) (contract.IdentityRefDTO, error)
+	Unlock(identity, passphrase string) error
+	ServiceStart(request contract.ServiceStartRequest) (contract.ServiceInfoDTO, error)
+	ServiceStop(id string) error
+	ProposalsByType(serviceType string) ([]contract.ProposalDTO, error)
+	NATStatus() (contract.NATStatusDTO, error)
+	ConnectionCreate(consumerID, providerID, accountantID, serviceType string, options contract.ConnectOptions) (contract.ConnectionStatusDTO, error)
+	ConnectionStatus() (contract.ConnectionStatusDTO, error)
+	ConnectionStatistics() (contract.ConnectionStatisticsDTO, error)
+	ConnectionDestroy() error
+}
+
+// options describes the self-test run.
+type options struct {
+	ServiceType  string
+	Identity     string
+	Passphrase   string
+	AccountantID string
+	// Timeout limits every stage.
+	Timeout time.Duration
+}
+
+// skipped marks a stage which could not be verified in the current setup.
+type skipped string
+
+func (s skipped) Error() string {
+	return string(s)
+}
+
+type stage struct {
+	name string
+	run  func() error
+}
+
+type stageResult struct {
+	name     string
+	err      error
+	duration time.Duration
+	run      bool
+}
+
+func (r stageResult) failed() bool {
+	if !r.run || r.err == nil {
+		return false
+	}
+	_, isSkipped := r.err.(skipped)
+	return !isSkipped
+}
+
+// selfTest connects the node to its own service as a consumer and verifies every stage of the session.
+type selfTest struct {
+	client       tequilapiClient
+	options      options
+	pollInterval time.Duration
+
+	providerID string
+	consumerID string
+	serviceID  string
+	connected  bool
+}
+
+func newSelfTest(client tequilapiClient, options options) *selfTest {
+	return &selfTest{
+		client:       client,
+		options:      options,
+		pollInterval: time.Second,
+	}
+}
+
+// Run runs all stages in order, stages after the failed one are not run.
+func (st *selfTest) Run() []stageResult {
+	defer st.cleanup()
+
+	stages := []stage{
+		{name: "identity", run: st.unlockIdentities},
+		{name: "service", run: st.startService},
+		{name: "discovery", run: st.discoverProposal},
+		{name: "connection", run: st.connect},
+		{name: "nat", run: st.checkNAT},
+		{name: "payments", run: st.checkPayments},
+		{name: "traffic", run: st.checkTraffic},
+	}
+
+	results := make([]stageResult, len(stages))
+	failed := false
+	for i, s := range stages {
+		results[i].name = s.name
+		if failed {
+			continue
+		}
+
+		log.Info().Msgf("Self-test stage %q started", s.name)
+		started := time.Now()
+		results[i].err = s.run()
+		results[i].duration = time.Since(started)
+		results[i].run = true
+		failed = results[i].failed()
+	}
+	return results
+}
+
+func (st *selfTest) unlockIdentities() error {
+	provider, err := st.client.CurrentIdentity(st.options.Identity, st.options.Passphrase)
+	if err != nil {
+		return errors.Wrap(err, "could not unlock provider identity")
+	}
+	st.providerID = provider.Address
+
+	ids, err := st.client.GetIdentities()
+	if err != nil {
+		return errors.Wrap(err, "could not list identities")
+	}
+	for _, id := range ids {
+		if strings.EqualFold(id.Address, st.providerID) {
+			continue
+		}
+		if err := st.client.Unlock(id.Address, ""); err == nil {
+			st.consumerID = id.Address
+			return nil
+		}
+	}
+
+	consumer, err := st.client.NewIdentity("")
+	if err != nil {
+		return errors.Wrap(err, "could not create consumer identity")
+	}
+	if err := st.client.Unlock(consumer.Address, ""); err != nil {
+		return errors.Wrap(err, "could not unlock consumer identity")
+	}
+	st.consumerID = consumer.Address
+	return nil
+}
+
+func (st *selfTest) startService() error {
+	serviceOpts, err := services.GetStartOptions(st.options.ServiceType)
+	if err != nil {
+		return err
+	}
+
+	service, err := st.client.ServiceStart(contract.ServiceStartRequest{
+		ProviderID: st.providerID,
+		Type:       st.options.ServiceType,
+		PaymentMethod: contract.ServicePaymentMethod{
+			PriceGB:     serviceOpts.PaymentPricePerGB,
+			PriceMinute: serviceOpts.PaymentPricePerMinute,
+			FreeMinutes: serviceOpts.FreeTrialMinutes,
+			FreeMB:      serviceOpts.FreeTrialMB,
+		},
+		AccessPolicies: contract.ServiceAccessPolicies{IDs: serviceOpts.AccessPolicyList},
+		Options:        serviceOpts,
+	})
+	if err != nil {
+		return errors.Wrapf(err, "could not start %s service", st.options.ServiceType)
+	}
+	st.serviceID = service.ID
+	return nil
+}
+
+func (st *selfTest) discoverProposal() error {
+	return st.waitFor("own proposal was not discovered, check broker connectivity", func() (bool, error) {
+		proposals, err := st.client.ProposalsByType(st.options.ServiceType)
+		if err != nil {
+			return false, err
+		}
+		for _, proposal := range proposals {
+			if strings.EqualFold(proposal.ProviderID, st.providerID) {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+}
+
+func (st *selfTest) connect() error {
+	_, err := st.client.ConnectionCreate(st.consumerID, st.providerID, st.options.AccountantID, st.options.ServiceType, contract.ConnectOptions{DisableKillSwitch: true})
+	if err != nil {
+		return errors.Wrap(err, "could not connect to own service")
+	}
+	st.connected = true
+
+	return st.waitFor("connection was not established", func() (bool, error) {
+		status, err := st.client.ConnectionStatus()
+		return status.Status == string(connection.Connected), err
+	})
+}
+
+func (st *selfTest) checkNAT() error {
+	var status contract.NATStatusDTO
+	err := st.waitFor("NAT traversal did not finish", func() (bool, error) {
+		var err error
+		status, err = st.client.NATStatus()
+		return status.Status != natStatusNotFinished, err
+	})
+	if err != nil {
+		return err
+	}
+	if status.Status != natStatusSuccessful {
+		return errors.Errorf("NAT traversal %s: %s", status.Status, status.Error)
+	}
+	return nil
+}
+
+func (st *selfTest) checkPayments() error {
+	return st.waitFor("no invoice was paid, check accountant connectivity and consumer balance", func() (bool, error) {
+		statistics, err := st.client.ConnectionStatistics()
+		spent, _ := money.ParseTokens(statistics.TokensSpent.Amount)
+		return !spent.IsZero(), err
+	})
+}
+
+func (st *selfTest) checkTraffic() error {
+	if st.options.ServiceType == noop.ServiceType {
+		return skipped("noop service has no data path, run self-test with wireguard service to verify traffic")
+	}
+	return st.waitFor("no traffic flows through the connection", func() (bool, error) {
+		statistics, err := st.client.ConnectionStatistics()
+		return statistics.BytesSent > 0 && statistics.BytesReceived > 0, err
+	})
+}
+
+// waitFor polls the condition until it is met or stage timeout passes.
+func (st *selfTest) waitFor(timeoutMessage string, condition func() (bool, error)) error {
+	deadline := time.Now().Add(st.options.Timeout)
+	for {
+		done, err := condition()
+		if done && err == nil {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return err
+			}
+			return errors.Errorf("%s in %s", timeoutMessage, st.options.Timeout)
+		}
+		time.Sleep(st.pollInterval)
+	}
+}
+
+func (st *selfTest) cleanup() {
+	if st.connected {
+		if err := st.client.ConnectionDestroy(); err != nil {
+			log.Warn().Err(err).Msg("Failed to disconnect self-test connection")
+		}
+	}
+	if st.serviceID != "" {
+		if err := st.client.ServiceStop(st.serviceID); err != nil {
+			log.Warn().Err(err).Msg("Failed to stop self-test service")
+		}
+	}
+}
+
+// printReport prints stage results and returns an error if any stage failed.
+func printReport(w io.Writer, results []stageResult) error {
+	fmt.Fprintln(w, "Self-test report:")
+
+	var failed []string
+	for _, result := range results {
+		switch {
+		case !result.run:
+			fmt.Fprintf(w, "  [ -- ] %-10s not run\n", result.name)
+		case result.failed():
+			failed = append(failed, result.name)
+			fmt.Fprintf(w, "  [FAIL] %-10s %6s  %v\n", result.name, result.duration.Round(time.Second), result.err)
+		case result.err != nil:
+			fmt.Fprintf(w, "  [SKIP] %-10s %6s  %v\n", result.name, result.duration.Round(time.Second), result.err)
+		default:
+			fmt.Fprintf(w, "  [ OK ] %-10s %6s\n", result.name, result.duration.Round(time.Second))
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.Errorf("self-test failed at stage: %s", strings.Join(failed, ", "))
+	}
+	fmt.Fprintln(w, "All stages passed, the node is ready to serve consumers.")
+	return nil
+}
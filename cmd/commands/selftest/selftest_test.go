@@ -0,0 +1,209 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package selftest
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeClient struct {
+	identities  []contract.IdentityRefDTO
+	unlockErr   error
+	proposals   []contract.ProposalDTO
+	nat         contract.NATStatusDTO
+	connectErr  error
+	status      contract.ConnectionStatusDTO
+	statistics  contract.ConnectionStatisticsDTO
+	started     bool
+	stopped     bool
+	created     []string
+	connected   bool
+	destroyed   bool
+	newIdentity contract.IdentityRefDTO
+}
+
+func (c *fakeClient) GetIdentities() ([]contract.IdentityRefDTO, error) {
+	return c.identities, nil
+}
+
+func (c *fakeClient) NewIdentity(passphrase string) (contract.IdentityRefDTO, error) {
+	c.created = append(c.created, c.newIdentity.Address)
+	return c.newIdentity, nil
+}
+
+func (c *fakeClient) CurrentIdentity(identity, passphrase string) (contract.IdentityRefDTO, error) {
+	return contract.IdentityRefDTO{Address: "0xprovider"}, nil
+}
+
+func (c *fakeClient) Unlock(identity, passphrase string) error {
+	if identity == c.newIdentity.Address {
+		return nil
+	}
+	return c.unlockErr
+}
+
+func (c *fakeClient) ServiceStart(request contract.ServiceStartRequest) (contract.ServiceInfoDTO, error) {
+	c.started = true
+	return contract.ServiceInfoDTO{ID: "service-1", ProviderID: request.ProviderID, Type: request.Type}, nil
+}
+
+func (c *fakeClient) ServiceStop(id string) error {
+	c.stopped = true
+	return nil
+}
+
+func (c *fakeClient) ProposalsByType(serviceType string) ([]contract.ProposalDTO, error) {
+	return c.proposals, nil
+}
+
+func (c *fakeClient) NATStatus() (contract.NATStatusDTO, error) {
+	return c.nat, nil
+}
+
+func (c *fakeClient) ConnectionCreate(consumerID, providerID, accountantID, serviceType string, options contract.ConnectOptions) (contract.ConnectionStatusDTO, error) {
+	if c.connectErr != nil {
+		return contract.ConnectionStatusDTO{}, c.connectErr
+	}
+	c.connected = true
+	return c.status, nil
+}
+
+func (c *fakeClient) ConnectionStatus() (contract.ConnectionStatusDTO, error) {
+	return c.status, nil
+}
+
+func (c *fakeClient) ConnectionStatistics() (contract.ConnectionStatisticsDTO, error) {
+	return c.statistics, nil
+}
+
+func (c *fakeClient) ConnectionDestroy() error {
+	c.destroyed = true
+	return nil
+}
+
+func newHealthyClient() *fakeClient {
+	return &fakeClient{
+		identities: []contract.IdentityRefDTO{{Address: "0xprovider"}, {Address: "0xconsumer"}},
+		proposals:  []contract.ProposalDTO{{ProviderID: "0xProvider", ServiceType: "wireguard"}},
+		nat:        contract.NATStatusDTO{Status: natStatusSuccessful},
+		status:     contract.ConnectionStatusDTO{Status: "Connected"},
+		statistics: contract.ConnectionStatisticsDTO{BytesSent: 1, BytesReceived: 1, TokensSpent: contract.NewTokensDTO(money.NewTokens(1))},
+	}
+}
+
+func newTestSelfTest(client tequilapiClient, serviceType string) *selfTest {
+	test := newSelfTest(client, options{ServiceType: serviceType, Timeout: 10 * time.Millisecond})
+	test.pollInterval = time.Millisecond
+	return test
+}
+
+func Test_selfTest_PassesAllStages(t *testing.T) {
+	client := newHealthyClient()
+
+	test := newTestSelfTest(client, "wireguard")
+	results := test.Run()
+
+	for _, result := range results {
+		assert.True(t, result.run, result.name)
+		assert.NoError(t, result.err, result.name)
+	}
+	assert.Equal(t, "0xconsumer", test.consumerID)
+	assert.True(t, client.destroyed)
+	assert.True(t, client.stopped)
+	assert.NoError(t, printReport(&bytes.Buffer{}, results))
+}
+
+func Test_selfTest_CreatesConsumerIdentityWhenNoneCanBeUnlocked(t *testing.T) {
+	client := newHealthyClient()
+	client.unlockErr = errors.New("wrong passphrase")
+	client.newIdentity = contract.IdentityRefDTO{Address: "0xnew"}
+
+	test := newTestSelfTest(client, "wireguard")
+
+	assert.NoError(t, test.unlockIdentities())
+	assert.Equal(t, "0xprovider", test.providerID)
+	assert.Equal(t, "0xnew", test.consumerID)
+	assert.Equal(t, []string{"0xnew"}, client.created)
+}
+
+func Test_selfTest_StopsAtFailedStageAndCleansUp(t *testing.T) {
+	client := newHealthyClient()
+	client.connectErr = errors.New("insufficient balance")
+
+	results := newTestSelfTest(client, "wireguard").Run()
+
+	var report bytes.Buffer
+	err := printReport(&report, results)
+	assert.EqualError(t, err, "self-test failed at stage: connection")
+	assert.Contains(t, report.String(), "[FAIL] connection")
+	assert.Contains(t, report.String(), "insufficient balance")
+	assert.Contains(t, report.String(), "[ -- ] payments   not run")
+	assert.False(t, client.destroyed)
+	assert.True(t, client.stopped)
+}
+
+func Test_selfTest_ReportsUndiscoveredProposal(t *testing.T) {
+	client := newHealthyClient()
+	client.proposals = nil
+
+	results := newTestSelfTest(client, "wireguard").Run()
+
+	assert.True(t, results[2].failed())
+	assert.EqualError(t, results[2].err, "own proposal was not discovered, check broker connectivity in 10ms")
+	assert.False(t, results[3].run)
+}
+
+func Test_selfTest_SkipsUnverifiableStages(t *testing.T) {
+	client := newHealthyClient()
+	client.statistics = contract.ConnectionStatisticsDTO{TokensSpent: contract.NewTokensDTO(money.NewTokens(1))}
+
+	results := newTestSelfTest(client, "noop").Run()
+
+	var report bytes.Buffer
+	assert.NoError(t, printReport(&report, results))
+	assert.Contains(t, report.String(), "[SKIP] traffic")
+}
+
+func Test_selfTest_FailsOnNATTraversalFailure(t *testing.T) {
+	client := newHealthyClient()
+	client.nat = contract.NATStatusDTO{Status: "failure", Error: "no response"}
+
+	results := newTestSelfTest(client, "wireguard").Run()
+
+	assert.True(t, results[4].failed())
+	assert.EqualError(t, results[4].err, "NAT traversal failure: no response")
+	assert.True(t, client.destroyed)
+}
+
+func Test_selfTest_FailsOnUnfinishedNATTraversal(t *testing.T) {
+	client := newHealthyClient()
+	client.nat = contract.NATStatusDTO{Status: natStatusNotFinished}
+
+	results := newTestSelfTest(client, "wireguard").Run()
+
+	assert.True(t, results[4].failed())
+	assert.EqualError(t, results[4].err, "NAT traversal did not finish in 10ms")
+	assert.True(t, client.destroyed)
+}
@@ -24,9 +24,12 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/cmd"
+	"github.com/mysteriumnetwork/node/cmd/commands/service/osservice"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/config/urfavecli/clicontext"
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/fleet"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/services"
 	"github.com/mysteriumnetwork/node/tequilapi/client"
@@ -51,40 +54,57 @@ func NewCommand(licenseCommandName string) *cli.Command {
 			}
 
 			quit := make(chan error)
-			config.ParseFlagsServiceStart(ctx)
-			config.ParseFlagsServiceOpenvpn(ctx)
-			config.ParseFlagsServiceWireguard(ctx)
-			config.ParseFlagsServiceNoop(ctx)
-			config.ParseFlagsNode(ctx)
-
-			nodeOptions := node.GetOptions()
-			nodeOptions.Discovery.FetchEnabled = false
-			if err := di.Bootstrap(*nodeOptions); err != nil {
-				return err
+			run := func() error {
+				config.ParseFlagsServiceStart(ctx)
+				config.ParseFlagsServiceOpenvpn(ctx)
+				config.ParseFlagsServiceWireguard(ctx)
+				config.ParseFlagsServiceNoop(ctx)
+				config.ParseFlagsNode(ctx)
+				config.ParseFlagsFleet(ctx)
+
+				fleetClient, fleetProfile, err := fetchFleetProfile(config.GetString(config.FlagBindAddress))
+				if err != nil {
+					return err
+				}
+
+				nodeOptions := node.GetOptions()
+				nodeOptions.Discovery.FetchEnabled = false
+				if err := di.Bootstrap(*nodeOptions); err != nil {
+					return err
+				}
+				go func() { quit <- di.Node.Wait() }()
+
+				cmd.RegisterSignalCallback(func() { quit <- nil })
+
+				cmdService := &serviceCommand{
+					tequilapi:     client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort),
+					errorChannel:  quit,
+					fleet:         fleetClient,
+					fleetProfile:  fleetProfile,
+					signerFactory: di.SignerFactory,
+				}
+				go func() {
+					quit <- cmdService.Run(ctx)
+				}()
+
+				return describeQuit(<-quit)
 			}
-			go func() { quit <- di.Node.Wait() }()
+			// Service manager stops the node while it may be still starting, so quit is not blocked.
+			stop := func() { go func() { quit <- nil }() }
 
-			cmd.RegisterSignalCallback(func() { quit <- nil })
-
-			cmdService := &serviceCommand{
-				tequilapi:    client.NewClient(nodeOptions.TequilapiAddress, nodeOptions.TequilapiPort),
-				errorChannel: quit,
-			}
-			go func() {
-				quit <- cmdService.Run(ctx)
-			}()
-
-			return describeQuit(<-quit)
+			return osservice.Run(run, stop)
 		},
 		After: func(ctx *cli.Context) error {
 			return di.Shutdown()
 		},
+		Subcommands: newOSServiceCommands(licenseCommandName),
 	}
 
 	config.RegisterFlagsServiceStart(&command.Flags)
 	config.RegisterFlagsServiceOpenvpn(&command.Flags)
 	config.RegisterFlagsServiceWireguard(&command.Flags)
 	config.RegisterFlagsServiceNoop(&command.Flags)
+	config.RegisterFlagsFleet(&command.Flags)
 
 	return command
 }
@@ -100,8 +120,11 @@ func describeQuit(err error) error {
 
 // serviceCommand represent entrypoint for service command with top level components
 type serviceCommand struct {
-	tequilapi    *client.Client
-	errorChannel chan error
+	tequilapi     *client.Client
+	errorChannel  chan error
+	fleet         *fleet.Client
+	fleetProfile  *fleet.Profile
+	signerFactory identity.SignerFactory
 }
 
 // Run runs a command
@@ -110,6 +133,8 @@ func (sc *serviceCommand) Run(ctx *cli.Context) (err error) {
 	serviceTypes := services.Types()
 	if arg != "" {
 		serviceTypes = strings.Split(arg, ",")
+	} else if sc.fleetProfile != nil && len(sc.fleetProfile.Services) > 0 {
+		serviceTypes = sc.fleetProfile.Services
 	}
 
 	providerID := sc.unlockIdentity(
@@ -118,6 +143,14 @@ func (sc *serviceCommand) Run(ctx *cli.Context) (err error) {
 	)
 	log.Info().Msgf("Unlocked identity: %v", providerID)
 
+	if sc.fleet != nil {
+		id := identity.FromAddress(providerID)
+		if err := sc.fleet.Enroll(id, sc.signerFactory(id)); err != nil {
+			return err
+		}
+		log.Info().Msgf("Identity %v enrolled to the fleet", providerID)
+	}
+
 	for _, serviceType := range serviceTypes {
 		serviceOpts, err := services.GetStartOptions(serviceType)
 		if err != nil {
@@ -129,9 +162,18 @@ func (sc *serviceCommand) Run(ctx *cli.Context) (err error) {
 			PaymentMethod: contract.ServicePaymentMethod{
 				PriceGB:     serviceOpts.PaymentPricePerGB,
 				PriceMinute: serviceOpts.PaymentPricePerMinute,
+				FreeMinutes: serviceOpts.FreeTrialMinutes,
+				FreeMB:      serviceOpts.FreeTrialMB,
 			},
 			AccessPolicies: contract.ServiceAccessPolicies{IDs: serviceOpts.AccessPolicyList},
-			Options:        serviceOpts,
+			Unlisted:       serviceOpts.Unlisted,
+			ConsumerPolicy: contract.ServiceConsumerPolicy{
+				AllowedCountries: serviceOpts.ConsumerPolicy.AllowedCountries,
+				DeniedCountries:  serviceOpts.ConsumerPolicy.DeniedCountries,
+				AllowedASNs:      serviceOpts.ConsumerPolicy.AllowedASNs,
+				DeniedASNs:       serviceOpts.ConsumerPolicy.DeniedASNs,
+			},
+			Options: serviceOpts,
 		}
 
 		go sc.runService(startRequest)
@@ -154,9 +196,13 @@ func (sc *serviceCommand) unlockIdentity(id, passphrase string) string {
 }
 
 func (sc *serviceCommand) runService(request contract.ServiceStartRequest) {
-	_, err := sc.tequilapi.ServiceStart(request)
+	started, err := sc.tequilapi.ServiceStart(request)
 	if err != nil {
 		sc.errorChannel <- errors.Wrapf(err, "failed to run service %s", request.Type)
+		return
+	}
+	if started.InviteCode != "" {
+		log.Info().Msgf("Service %s is unlisted, share the invite code with consumers: %s", request.Type, started.InviteCode)
 	}
 }
 
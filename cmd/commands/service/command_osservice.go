@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/cmd/commands/service/osservice"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/rs/zerolog/log"
+	"github.com/urfave/cli/v2"
+)
+
+// newOSServiceCommands creates commands which manage the node as a service of the OS service manager
+func newOSServiceCommands(licenseCommandName string) []*cli.Command {
+	install := &cli.Command{
+		Name:      "install",
+		Usage:     "Installs the node as a service of the OS service manager and starts it",
+		ArgsUsage: "comma separated list of services to start",
+		Action: func(ctx *cli.Context) error {
+			if !ctx.Bool(config.FlagAgreedTermsConditions.Name) {
+				printTermWarning(licenseCommandName)
+				os.Exit(2)
+			}
+			config.ParseFlagsNode(ctx)
+
+			execPath, err := os.Executable()
+			if err != nil {
+				return err
+			}
+			if execPath, err = filepath.Abs(execPath); err != nil {
+				return err
+			}
+			dirs, err := directoryFlags()
+			if err != nil {
+				return err
+			}
+
+			options := osservice.Options{
+				ExecPath: execPath,
+				Args:     nodeArgs(os.Args[1:], dirs, ctx.Args().Slice()),
+				User:     ctx.String(config.FlagServiceInstallUser.Name),
+				DataDir:  config.GetString(config.FlagDataDir),
+				LogDir:   config.GetString(config.FlagLogDir),
+			}
+			log.Info().Msgf("Installing node service: %s %s", options.ExecPath, strings.Join(options.Args, " "))
+			if err := osservice.Install(options); err != nil {
+				return err
+			}
+			log.Info().Msg("Node service installed")
+			return nil
+		},
+	}
+	config.RegisterFlagsServiceInstall(&install.Flags)
+
+	return []*cli.Command{
+		install,
+		{
+			Name:  "uninstall",
+			Usage: "Stops and removes the node service from the OS service manager",
+			Action: func(ctx *cli.Context) error {
+				return osservice.Uninstall()
+			},
+		},
+		{
+			Name:  "start",
+			Usage: "Starts the installed node service",
+			Action: func(ctx *cli.Context) error {
+				return osservice.Start()
+			},
+		},
+		{
+			Name:  "stop",
+			Usage: "Stops the installed node service",
+			Action: func(ctx *cli.Context) error {
+				return osservice.Stop()
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "Shows status of the installed node service",
+			Action: func(ctx *cli.Context) error {
+				status, err := osservice.GetStatus()
+				if err != nil {
+					return err
+				}
+				_, err = fmt.Fprintln(ctx.App.Writer, status)
+				return err
+			},
+		},
+	}
+}
+
+// directoryFlags returns node directories resolved by the installing user,
+// so the service uses the same data regardless of the user it runs as.
+func directoryFlags() ([]string, error) {
+	var flags []string
+	for _, flag := range []cli.StringFlag{config.FlagConfigDir, config.FlagDataDir, config.FlagLogDir} {
+		dir, err := filepath.Abs(config.GetString(flag))
+		if err != nil {
+			return nil, err
+		}
+		flags = append(flags, fmt.Sprintf("--%s=%s", flag.Name, dir))
+	}
+	return flags, nil
+}
+
+// nodeArgs builds arguments of the installed node from the arguments install command was called with:
+// global and service flags are kept, install command and its flags are replaced by the list of services.
+func nodeArgs(args []string, dirs []string, services []string) []string {
+	serviceIdx, installIdx := -1, -1
+	for i, arg := range args {
+		if serviceIdx < 0 && arg == "service" {
+			serviceIdx = i
+		} else if serviceIdx >= 0 && arg == "install" {
+			installIdx = i
+			break
+		}
+	}
+	if serviceIdx < 0 || installIdx < 0 {
+		serviceIdx, installIdx = len(args), len(args)
+	}
+
+	var result []string
+	for _, dir := range dirs {
+		name := dir[:strings.Index(dir, "=")]
+		if !hasFlag(args[:serviceIdx], name) {
+			result = append(result, dir)
+		}
+	}
+	result = append(result, args[:serviceIdx]...)
+	result = append(result, "service")
+
+	serviceFlags := args[serviceIdx:installIdx]
+	if len(serviceFlags) > 0 {
+		serviceFlags = serviceFlags[1:]
+	}
+	result = append(result, serviceFlags...)
+	if !hasFlag(serviceFlags, "--"+config.FlagAgreedTermsConditions.Name) {
+		result = append(result, "--"+config.FlagAgreedTermsConditions.Name)
+	}
+	return append(result, services...)
+}
+
+func hasFlag(args []string, name string) bool {
+	name = strings.TrimLeft(name, "-")
+	for _, arg := range args {
+		arg = strings.TrimLeft(arg, "-")
+		if arg == name || strings.HasPrefix(arg, name+"=") {
+			return true
+		}
+	}
+	return false
+}
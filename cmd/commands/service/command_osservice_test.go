@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nodeArgs(t *testing.T) {
+	dirs := []string{"--config-dir=/etc/myst", "--data-dir=/var/lib/myst", "--log-dir=/var/log/myst"}
+
+	tests := []struct {
+		name     string
+		args     []string
+		services []string
+		want     []string
+	}{
+		{
+			name:     "adds directories and terms agreement",
+			args:     []string{"service", "install"},
+			services: []string{"wireguard"},
+			want: []string{
+				"--config-dir=/etc/myst", "--data-dir=/var/lib/myst", "--log-dir=/var/log/myst",
+				"service", "--agreed-terms-and-conditions", "wireguard",
+			},
+		},
+		{
+			name: "keeps global and service flags",
+			args: []string{
+				"--testnet", "--data-dir=/data", "service", "--agreed-terms-and-conditions",
+				"--identity", "0x1", "install", "--user", "myst", "openvpn,wireguard",
+			},
+			services: []string{"openvpn,wireguard"},
+			want: []string{
+				"--config-dir=/etc/myst", "--log-dir=/var/log/myst", "--testnet", "--data-dir=/data",
+				"service", "--agreed-terms-and-conditions", "--identity", "0x1", "openvpn,wireguard",
+			},
+		},
+		{
+			name: "keeps directory flags passed with separate values",
+			args: []string{"--log-dir", "/logs", "service", "install"},
+			want: []string{
+				"--config-dir=/etc/myst", "--data-dir=/var/lib/myst", "--log-dir", "/logs",
+				"service", "--agreed-terms-and-conditions",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, nodeArgs(tt.args, dirs, tt.services))
+		})
+	}
+}
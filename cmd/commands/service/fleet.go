@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/fleet"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	fleetProfileAttempts   = 6
+	fleetProfileRetryDelay = 10 * time.Second
+)
+
+// fetchFleetProfile applies the configuration profile of the fleet controller, if the node is configured to enroll to one.
+func fetchFleetProfile(bindAddress string) (*fleet.Client, *fleet.Profile, error) {
+	controller := config.GetString(config.FlagFleetController)
+	if controller == "" {
+		return nil, nil, nil
+	}
+
+	token, err := fleet.ReadToken(config.GetString(config.FlagFleetToken), config.GetString(config.FlagFleetTokenFile))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client := fleet.NewClient(requests.NewHTTPClient(bindAddress, requests.DefaultTimeout), controller, token)
+	for attempt := 1; ; attempt++ {
+		profile, err := client.Profile()
+		if err == nil {
+			log.Info().Msgf("Applying fleet profile, services: %v", profile.Services)
+			profile.Apply(config.Current)
+			return client, &profile, nil
+		}
+		if attempt == fleetProfileAttempts {
+			return nil, nil, errors.Wrapf(err, "could not fetch fleet profile from %s", controller)
+		}
+		log.Warn().Err(err).Msgf("Failed to fetch fleet profile, retrying in %v", fleetProfileRetryDelay)
+		time.Sleep(fleetProfileRetryDelay)
+	}
+}
@@ -0,0 +1,38 @@
+// +build linux darwin
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package osservice
+
+import (
+	"os/exec"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+func runV(c ...string) (string, error) {
+	cmd := exec.Command(c[0], c[1:]...)
+	output, err := cmd.CombinedOutput()
+	log.Debug().Msgf("[%v] out:\n%s", strings.Join(c, " "), output)
+	if err != nil {
+		return string(output), errors.Wrapf(err, "%s failed: %s", c[0], strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+// Package osservice installs and manages the node as a service of the operating system service manager:
+// systemd on Linux, launchd on macOS and Service Control Manager on Windows.
+package osservice
+
+import (
+	"errors"
+)
+
+// Status describes the state of the installed node service.
+type Status string
+
+const (
+	// StatusRunning means the node service is running.
+	StatusRunning = Status("running")
+	// StatusStopped means the node service is installed, but not running.
+	StatusStopped = Status("stopped")
+	// StatusNotInstalled means the node service is not installed.
+	StatusNotInstalled = Status("not installed")
+)
+
+var (
+	// ErrUnsupported is returned when service manager of the OS is not supported.
+	ErrUnsupported = errors.New("service manager of this OS is not supported")
+	// ErrUserUnsupported is returned when service can not be run as a given user on this OS.
+	ErrUserUnsupported = errors.New("running service as a different user is not supported on this OS")
+	// ErrNotInstalled is returned when managing the service which is not installed.
+	ErrNotInstalled = errors.New("node service is not installed")
+)
+
+// Options describes how node service is installed.
+type Options struct {
+	// ExecPath is an absolute path of the node executable.
+	ExecPath string
+	// Args are the arguments the node is started with.
+	Args []string
+	// User to run the node as, the node runs as a privileged user if empty.
+	User string
+	// DataDir is the node data directory, it is handed over to the User.
+	DataDir string
+	// LogDir is the directory the node and service manager write logs to.
+	LogDir string
+}
+
+func (o Options) valid() bool {
+	return o.ExecPath != "" && o.LogDir != ""
+}
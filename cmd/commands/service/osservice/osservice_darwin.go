@@ -0,0 +1,162 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package osservice
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const daemonID = "network.mysterium.myst"
+const plistPath = "/Library/LaunchDaemons/" + daemonID + ".plist"
+const plistTpl = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>{{xml .DaemonID}}</string>
+	<key>ProgramArguments</key>
+	<array>
+		{{- range .ProgramArguments}}
+		<string>{{xml .}}</string>
+		{{- end}}
+	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<true/>
+	<key>StandardOutPath</key>
+	<string>{{xml .LogPath}}</string>
+	<key>StandardErrorPath</key>
+	<string>{{xml .LogPath}}</string>
+</dict>
+</plist>
+`
+
+// Install installs launchd daemon of the node and starts it.
+func Install(options Options) error {
+	if !options.valid() {
+		return errors.New("invalid options")
+	}
+	if options.User != "" {
+		// Network configuration is done by the node itself, so it has to run as root.
+		return ErrUserUnsupported
+	}
+
+	plist, err := plistFile(options)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(options.LogDir, 0700); err != nil {
+		return errors.Wrapf(err, "could not create directory %s", options.LogDir)
+	}
+
+	if installed() {
+		log.Info().Msg("Cleaning up previous installation")
+		if err := Uninstall(); err != nil {
+			return err
+		}
+	}
+
+	log.Info().Msgf("Installing launchd daemon %s", plistPath)
+	if err := ioutil.WriteFile(plistPath, []byte(plist), 0644); err != nil {
+		return errors.Wrapf(err, "could not create file %s", plistPath)
+	}
+	return Start()
+}
+
+// Uninstall stops and removes launchd daemon of the node.
+func Uninstall() error {
+	if !installed() {
+		return ErrNotInstalled
+	}
+
+	log.Info().Msgf("Uninstalling launchd daemon %s", plistPath)
+	if _, err := runV("launchctl", "unload", "-w", plistPath); err != nil {
+		return err
+	}
+	return errors.Wrapf(os.Remove(plistPath), "could not remove file %s", plistPath)
+}
+
+// Start starts the node service.
+func Start() error {
+	if !installed() {
+		return ErrNotInstalled
+	}
+	out, err := runV("launchctl", "load", "-w", plistPath)
+	if err == nil && strings.Contains(out, "Invalid property") {
+		err = errors.New("invalid plist file")
+	}
+	return err
+}
+
+// Stop stops the node service, it is started again on the next boot.
+func Stop() error {
+	if !installed() {
+		return ErrNotInstalled
+	}
+	_, err := runV("launchctl", "unload", plistPath)
+	return err
+}
+
+// GetStatus returns status of the node service.
+func GetStatus() (Status, error) {
+	if !installed() {
+		return StatusNotInstalled, nil
+	}
+	// list exits with non zero code if daemon is not loaded.
+	out, err := runV("launchctl", "list", daemonID)
+	if err != nil || !strings.Contains(out, `"PID"`) {
+		return StatusStopped, nil
+	}
+	return StatusRunning, nil
+}
+
+func installed() bool {
+	_, err := os.Stat(plistPath)
+	return err == nil
+}
+
+func plistFile(options Options) (string, error) {
+	tpl, err := template.New("plistTpl").Funcs(template.FuncMap{"xml": xmlEscape}).Parse(plistTpl)
+	if err != nil {
+		return "", errors.Wrap(err, "could not create template for launchd daemon")
+	}
+
+	var plist strings.Builder
+	err = tpl.Execute(&plist, map[string]interface{}{
+		"DaemonID":         daemonID,
+		"ProgramArguments": append([]string{options.ExecPath}, options.Args...),
+		"LogPath":          filepath.Join(options.LogDir, "launchd.log"),
+	})
+	return plist.String(), errors.Wrap(err, "could not generate launchd daemon")
+}
+
+func xmlEscape(s string) (string, error) {
+	var buf bytes.Buffer
+	err := xml.EscapeText(&buf, []byte(s))
+	return buf.String(), err
+}
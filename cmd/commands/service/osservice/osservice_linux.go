@@ -0,0 +1,193 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package osservice
+
+import (
+	"io/ioutil"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const unitName = "mysterium-node"
+
+// Unit placed into /etc overrides the one installed by the package.
+const unitPath = "/etc/systemd/system/" + unitName + ".service"
+
+const unitTpl = `[Unit]
+Description=Server for Mysterium - decentralised VPN Network
+Documentation=https://mysterium.network/
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+{{- if .User}}
+User={{.User}}
+# Allows to configure TUN interfaces and firewall without running as root.
+AmbientCapabilities=CAP_NET_ADMIN CAP_NET_RAW CAP_NET_BIND_SERVICE
+CapabilityBoundingSet=CAP_NET_ADMIN CAP_NET_RAW CAP_NET_BIND_SERVICE
+{{- end}}
+ExecStart={{.ExecStart}}
+KillMode=process
+TimeoutStopSec=10
+SendSIGKILL=yes
+Restart=on-failure
+RestartSec=5
+StandardOutput=journal
+StandardError=journal
+SyslogIdentifier=myst
+
+[Install]
+WantedBy=multi-user.target
+`
+
+// Install installs systemd unit of the node, enables and starts it.
+func Install(options Options) error {
+	if !options.valid() {
+		return errors.New("invalid options")
+	}
+
+	unit, err := unitFile(options)
+	if err != nil {
+		return err
+	}
+
+	if options.User != "" {
+		if err := ownDirs(options.User, options.DataDir, options.LogDir); err != nil {
+			return err
+		}
+	}
+
+	log.Info().Msgf("Installing systemd unit %s", unitPath)
+	if err := ioutil.WriteFile(unitPath, []byte(unit), 0644); err != nil {
+		return errors.Wrapf(err, "could not create file %s", unitPath)
+	}
+	if _, err := runV("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	_, err = runV("systemctl", "enable", "--now", unitName)
+	return err
+}
+
+// Uninstall stops, disables and removes systemd unit of the node.
+func Uninstall() error {
+	if !installed() {
+		return ErrNotInstalled
+	}
+
+	log.Info().Msgf("Uninstalling systemd unit %s", unitPath)
+	if _, err := runV("systemctl", "disable", "--now", unitName); err != nil {
+		return err
+	}
+	if err := os.Remove(unitPath); err != nil {
+		return errors.Wrapf(err, "could not remove file %s", unitPath)
+	}
+	_, err := runV("systemctl", "daemon-reload")
+	return err
+}
+
+// Start starts the node service.
+func Start() error {
+	if !installed() {
+		return ErrNotInstalled
+	}
+	_, err := runV("systemctl", "start", unitName)
+	return err
+}
+
+// Stop stops the node service.
+func Stop() error {
+	if !installed() {
+		return ErrNotInstalled
+	}
+	_, err := runV("systemctl", "stop", unitName)
+	return err
+}
+
+// GetStatus returns status of the node service.
+func GetStatus() (Status, error) {
+	if !installed() {
+		return StatusNotInstalled, nil
+	}
+	// is-active exits with non zero code if unit is not active.
+	out, _ := runV("systemctl", "is-active", unitName)
+	if strings.TrimSpace(out) == "active" {
+		return StatusRunning, nil
+	}
+	return StatusStopped, nil
+}
+
+func installed() bool {
+	_, err := os.Stat(unitPath)
+	return err == nil
+}
+
+func unitFile(options Options) (string, error) {
+	tpl, err := template.New("unitTpl").Parse(unitTpl)
+	if err != nil {
+		return "", errors.Wrap(err, "could not create template for systemd unit")
+	}
+
+	execStart := []string{systemdQuote(options.ExecPath)}
+	for _, arg := range options.Args {
+		execStart = append(execStart, systemdQuote(arg))
+	}
+
+	var unit strings.Builder
+	err = tpl.Execute(&unit, map[string]string{
+		"User":      options.User,
+		"ExecStart": strings.Join(execStart, " "),
+	})
+	return unit.String(), errors.Wrap(err, "could not generate systemd unit")
+}
+
+// systemdQuote quotes the argument of ExecStart, so systemd does not split or expand it.
+func systemdQuote(arg string) string {
+	arg = strings.NewReplacer("%", "%%", "$", "$$").Replace(arg)
+	if !strings.ContainsAny(arg, " \t\"'\\;") {
+		return arg
+	}
+	return strconv.Quote(arg)
+}
+
+func ownDirs(username string, dirs ...string) error {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return errors.Wrapf(err, "could not find user %s", username)
+	}
+	uid, _ := strconv.Atoi(u.Uid)
+	gid, _ := strconv.Atoi(u.Gid)
+
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return errors.Wrapf(err, "could not create directory %s", dir)
+		}
+		if err := os.Chown(dir, uid, gid); err != nil {
+			return errors.Wrapf(err, "could not hand over directory %s to %s", dir, username)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package osservice
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnitFile(t *testing.T) {
+	unit, err := unitFile(Options{
+		ExecPath: "/usr/bin/myst",
+		Args:     []string{"--data-dir=/var/lib/my node", "service", "--agreed-terms-and-conditions", "wireguard"},
+		User:     "mysterium-node",
+		LogDir:   "/var/log/mysterium-node",
+	})
+	assert.NoError(t, err)
+	assert.Contains(t, unit, "\nUser=mysterium-node\n")
+	assert.Contains(t, unit, "\nAmbientCapabilities=CAP_NET_ADMIN CAP_NET_RAW CAP_NET_BIND_SERVICE\n")
+	assert.Contains(t, unit, "\nExecStart=/usr/bin/myst \"--data-dir=/var/lib/my node\" service --agreed-terms-and-conditions wireguard\n")
+}
+
+func TestUnitFile_RunsAsRootWithoutUser(t *testing.T) {
+	unit, err := unitFile(Options{ExecPath: "/usr/bin/myst", LogDir: "/var/log/mysterium-node"})
+	assert.NoError(t, err)
+	assert.NotContains(t, unit, "User=")
+	assert.NotContains(t, unit, "AmbientCapabilities=")
+	assert.Contains(t, unit, "[Service]\nExecStart=/usr/bin/myst\n")
+}
+
+func TestSystemdQuote(t *testing.T) {
+	assert.Equal(t, "--data-dir=/tmp", systemdQuote("--data-dir=/tmp"))
+	assert.Equal(t, `"a b"`, systemdQuote("a b"))
+	assert.Equal(t, `"a\"b"`, systemdQuote(`a"b`))
+	assert.Equal(t, "$$HOME/100%%", systemdQuote("$HOME/100%"))
+}
@@ -0,0 +1,45 @@
+// +build !linux,!darwin,!windows
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package osservice
+
+// Install is not supported on this OS.
+func Install(options Options) error {
+	return ErrUnsupported
+}
+
+// Uninstall is not supported on this OS.
+func Uninstall() error {
+	return ErrUnsupported
+}
+
+// Start is not supported on this OS.
+func Start() error {
+	return ErrUnsupported
+}
+
+// Stop is not supported on this OS.
+func Stop() error {
+	return ErrUnsupported
+}
+
+// GetStatus is not supported on this OS.
+func GetStatus() (Status, error) {
+	return StatusNotInstalled, ErrUnsupported
+}
@@ -0,0 +1,219 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package osservice
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/mgr"
+)
+
+const serviceName = "MysteriumNode"
+
+// Install installs Windows service of the node and starts it.
+// If there is previous service instance it is uninstalled before installing the new one.
+func Install(options Options) error {
+	if !options.valid() {
+		return errors.New("invalid options")
+	}
+	if options.User != "" {
+		return ErrUserUnsupported
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return errors.Wrap(err, "could not connect to service manager")
+	}
+	defer m.Disconnect()
+
+	if err := uninstallService(m); err == nil {
+		log.Info().Msg("Uninstalled previous service")
+		if err := waitServiceDeleted(m); err != nil {
+			return err
+		}
+	}
+
+	config := mgr.Config{
+		ServiceType:  windows.SERVICE_WIN32_OWN_PROCESS,
+		StartType:    mgr.StartAutomatic,
+		ErrorControl: mgr.ErrorNormal,
+		DisplayName:  "Mysterium Node",
+		Description:  "Server for Mysterium - decentralised VPN Network",
+		Dependencies: []string{"Nsi"},
+	}
+	s, err := m.CreateService(serviceName, options.ExecPath, config, options.Args...)
+	if err != nil {
+		return errors.Wrap(err, "could not create service")
+	}
+	defer s.Close()
+
+	return errors.Wrap(s.Start(), "could not start service")
+}
+
+// Uninstall stops and removes Windows service of the node.
+func Uninstall() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return errors.Wrap(err, "could not connect to service manager")
+	}
+	defer m.Disconnect()
+
+	return uninstallService(m)
+}
+
+// Start starts the node service.
+func Start() error {
+	return withService(func(s *mgr.Service) error {
+		return s.Start()
+	})
+}
+
+// Stop stops the node service.
+func Stop() error {
+	return withService(func(s *mgr.Service) error {
+		_, err := s.Control(svc.Stop)
+		return err
+	})
+}
+
+// GetStatus returns status of the node service.
+func GetStatus() (status Status, err error) {
+	err = withService(func(s *mgr.Service) error {
+		state, err := s.Query()
+		if err != nil {
+			return err
+		}
+		status = StatusStopped
+		if state.State == svc.Running {
+			status = StatusRunning
+		}
+		return nil
+	})
+	if err == ErrNotInstalled {
+		return StatusNotInstalled, nil
+	}
+	return status, err
+}
+
+// Run runs the node as Windows service when started by the service manager, or in the foreground otherwise.
+func Run(run func() error, stop func()) error {
+	interactive, err := svc.IsAnInteractiveSession()
+	if err != nil {
+		return errors.Wrap(err, "could not determine if running as service")
+	}
+	if interactive {
+		return run()
+	}
+
+	handler := &nodeService{run: run, stop: stop}
+	if err := svc.Run(serviceName, handler); err != nil {
+		return err
+	}
+	return handler.err
+}
+
+type nodeService struct {
+	run  func() error
+	stop func()
+	err  error
+}
+
+// Execute is an entrypoint for a windows service.
+func (n *nodeService) Execute(args []string, r <-chan svc.ChangeRequest, s chan<- svc.Status) (svcSpecificEC bool, exitCode uint32) {
+	const cmdsAccepted = svc.AcceptStop | svc.AcceptShutdown
+
+	s <- svc.Status{State: svc.StartPending}
+	done := make(chan error, 1)
+	go func() {
+		done <- n.run()
+	}()
+	s <- svc.Status{State: svc.Running, Accepts: cmdsAccepted}
+
+	for {
+		select {
+		case n.err = <-done:
+			if n.err != nil {
+				return false, 1
+			}
+			return false, 0
+		case c := <-r:
+			switch c.Cmd {
+			case svc.Interrogate:
+				s <- c.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				s <- svc.Status{State: svc.StopPending}
+				n.stop()
+				n.err = <-done
+				return false, 0
+			default:
+				log.Error().Msgf("Unexpected control request #%d", c)
+			}
+		}
+	}
+}
+
+func withService(fn func(s *mgr.Service) error) error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return errors.Wrap(err, "could not connect to service manager")
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return ErrNotInstalled
+	}
+	defer s.Close()
+
+	return fn(s)
+}
+
+func uninstallService(m *mgr.Mgr) error {
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return ErrNotInstalled
+	}
+	defer s.Close()
+
+	// Service may be already stopped, error is ignored as it is deleted anyway.
+	s.Control(svc.Stop)
+
+	return errors.Wrap(s.Delete(), "could not mark service for deletion")
+}
+
+// waitServiceDeleted checks if service is deleted.
+// It is considered as deleted if OpenService fails.
+func waitServiceDeleted(m *mgr.Mgr) error {
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case <-timeout:
+			return errors.New("timeout waiting for service deletion")
+		case <-time.After(100 * time.Millisecond):
+			s, err := m.OpenService(serviceName)
+			if err != nil {
+				return nil
+			}
+			s.Close()
+		}
+	}
+}
@@ -20,16 +20,25 @@ package cmd
 import (
 	"fmt"
 	"net"
+	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/mysteriumnetwork/node/blockchain"
 	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/config"
 	appconfig "github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/consumer/adblock"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/gateway"
+	"github.com/mysteriumnetwork/node/consumer/isolation"
+	"github.com/mysteriumnetwork/node/consumer/ondemand"
+	"github.com/mysteriumnetwork/node/consumer/proxy"
+	"github.com/mysteriumnetwork/node/consumer/schedule"
 	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/consumer/statistics"
 	"github.com/mysteriumnetwork/node/core/auth"
@@ -38,17 +47,23 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/netmon"
 	"github.com/mysteriumnetwork/node/core/node"
 	nodevent "github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/quality"
-	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/state"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/backup"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrations/history"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb/migrator"
+	"github.com/mysteriumnetwork/node/core/storage/sqlite"
+	"github.com/mysteriumnetwork/node/core/telemetry"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/faucet"
 	"github.com/mysteriumnetwork/node/feedback"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
@@ -66,16 +81,14 @@ import (
 	"github.com/mysteriumnetwork/node/nat/upnp"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/requests"
-	"github.com/mysteriumnetwork/node/services"
-	service_noop "github.com/mysteriumnetwork/node/services/noop"
-	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
+	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	"github.com/mysteriumnetwork/node/sleep"
 	"github.com/mysteriumnetwork/node/tequilapi"
-	tequilapi_endpoints "github.com/mysteriumnetwork/node/tequilapi/endpoints"
-	"github.com/mysteriumnetwork/node/utils"
 	"github.com/mysteriumnetwork/node/utils/netutil"
+	"github.com/mysteriumnetwork/node/utils/stringutil"
+	"github.com/mysteriumnetwork/node/withdrawal"
 
 	paymentClient "github.com/mysteriumnetwork/payments/client"
 	"github.com/pkg/errors"
@@ -96,48 +109,58 @@ type Dependencies struct {
 
 	NetworkDefinition metadata.NetworkDefinition
 	MysteriumAPI      *mysterium.MysteriumAPI
-	EtherClient       *paymentClient.ReconnectableEthClient
+	EtherClient       *blockchain.MultiClient
 
 	BrokerConnector  *nats.BrokerConnector
 	BrokerConnection nats.Connection
 
 	NATService       nat.NATService
-	Storage          *boltdb.Bolt
+	Storage          storage.Storage
+	BackupManager    *backup.Manager
 	Keystore         *identity.Keystore
 	IdentityManager  identity.Manager
 	SignerFactory    identity.SignerFactory
 	IdentityRegistry identity_registry.IdentityRegistry
 	IdentitySelector identity_selector.Handler
 
-	DiscoveryFactory   service.DiscoveryFactory
 	ProposalRepository proposal.Repository
 	DiscoveryWorker    brokerdiscovery.Worker
 
 	QualityClient *quality.MysteriumMORQA
-
-	IPResolver       ip.Resolver
-	LocationResolver *location.Cache
+	QualityScores *quality.ScoreCache
+	Telemetry     *telemetry.Collector
+
+	IPResolver        ip.Resolver
+	LocationResolver  *location.Cache
+	LocationDBUpdater *location.DBUpdater
+	LocationWatcher   *location.Watcher
+	NetworkMonitor    *netmon.Monitor
+	Gateway           *gateway.Gateway
+	Scheduler         *schedule.Scheduler
+	OnDemand          *ondemand.OnDemand
+	Proxy             *proxy.Proxy
 
 	PolicyOracle *policy.Oracle
 
 	StatisticsReporter               *statistics.SessionStatisticsReporter
 	SessionStorage                   *consumer_session.Storage
+	ConsumerStatsStorage             *consumer_session.ConsumerStatsStorage
 	SessionConnectivityStatusStorage connectivity.StatusStorage
 
-	EventBus eventbus.EventBus
+	EventBus      eventbus.EventBus
+	EventRecorder *eventbus.Recorder
 
 	ConnectionManager  connection.Manager
+	ConnectionManagers *isolation.Pool
 	ConnectionRegistry *connection.Registry
 
-	ServicesManager *service.Manager
-	ServiceRegistry *service.Registry
-	ServiceSessions *service.SessionPool
 	ServiceFirewall firewall.IncomingTrafficFirewall
 
-	NATPinger  traversal.NATPinger
-	NATTracker *event.Tracker
-	PortPool   *port.Pool
-	PortMapper mapping.PortMapper
+	NATPinger        traversal.NATPinger
+	NATTracker       *event.Tracker
+	PortReservations *port.Reservations
+	PortPool         *port.Pool
+	PortMapper       mapping.PortMapper
 
 	StateKeeper *state.Keeper
 
@@ -148,7 +171,8 @@ type Dependencies struct {
 	JWTAuthenticator  *auth.JWTAuthenticator
 	UIServer          UIServer
 	Transactor        *registry.Transactor
-	BCHelper          *paymentClient.BlockchainWithRetries
+	BCHelper          blockchain.Blockchain
+	ChainReader       blockchain.ChainReader
 	ProviderRegistrar *registry.ProviderRegistrar
 
 	LogCollector *logconfig.Collector
@@ -160,11 +184,18 @@ type Dependencies struct {
 	ConsumerBalanceTracker   *pingpong.ConsumerBalanceTracker
 	AccountantPromiseSettler pingpong.AccountantPromiseSettler
 	AccountantCaller         *pingpong.AccountantCaller
+	Accountants              *pingpong.Accountants
 	ChannelAddressCalculator *pingpong.ChannelAddressCalculator
 	AccountantPromiseHandler *pingpong.AccountantPromiseHandler
+	PromiseJournal           *pingpong.PromiseJournal
 	SettlementHistoryStorage *pingpong.SettlementHistoryStorage
+	EarningsSeries           *pingpong.EarningsSeries
+	Withdrawals              *withdrawal.Manager
+	Faucet                   *faucet.Manager
 
 	MMN *mmn.MMN
+
+	desktopDependencies
 }
 
 // Bootstrap initiates all container dependencies
@@ -190,13 +221,15 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
-	if err := di.bootstrapFirewall(nodeOptions.Firewall); err != nil {
+	if err := di.bootstrapFirewall(nodeOptions.Firewall, nodeOptions.Directories.Data); err != nil {
 		return err
 	}
 
-	di.bootstrapEventBus()
+	if err := di.bootstrapEventBus(nodeOptions.EventRecord); err != nil {
+		return err
+	}
 
-	if err := di.bootstrapStorage(nodeOptions.Directories.Storage); err != nil {
+	if err := di.bootstrapStorage(nodeOptions.Directories.Storage, nodeOptions.Directories.Keystore, nodeOptions.Storage); err != nil {
 		return err
 	}
 
@@ -226,7 +259,7 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 		return err
 	}
 
-	di.PortPool = port.NewPool()
+	di.PortPool = port.NewPool(di.PortReservations)
 	if config.GetBool(config.FlagPortMapping) {
 		portmapConfig := mapping.DefaultConfig()
 		di.PortMapper = mapping.NewPortMapper(portmapConfig, di.EventBus)
@@ -235,13 +268,23 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 	}
 
 	di.bootstrapP2P(nodeOptions.P2PPorts)
+	if err := di.bootstrapChaos(nodeOptions.Chaos); err != nil {
+		return err
+	}
 	di.SessionConnectivityStatusStorage = connectivity.NewStatusStorage()
 
 	if err := di.bootstrapServices(nodeOptions); err != nil {
 		return err
 	}
+	if err := di.bootstrapManagement(nodeOptions); err != nil {
+		return err
+	}
 
-	if err := di.bootstrapQualityComponents(nodeOptions.BindAddress, nodeOptions.Quality); err != nil {
+	if err := di.bootstrapQualityComponents(nodeOptions.BindAddress, nodeOptions.Quality, nodeOptions.LowResource); err != nil {
+		return err
+	}
+
+	if err := di.bootstrapTelemetry(nodeOptions.Telemetry); err != nil {
 		return err
 	}
 
@@ -259,6 +302,9 @@ func (di *Dependencies) Bootstrap(nodeOptions node.Options) error {
 
 	appconfig.Current.EnableEventPublishing(di.EventBus)
 
+	di.Scheduler.Start()
+	di.startDesktopComponents()
+
 	log.Info().Msg("Mysterium node started!")
 	return nil
 }
@@ -269,7 +315,7 @@ func (di *Dependencies) bootstrapP2P(p2pPorts *port.Range) {
 	identityVerifier := identity.NewVerifierSigned()
 	if p2pPorts.IsSpecified() {
 		log.Info().Msgf("Fixed p2p service port range (%s) configured, using custom port pool", p2pPorts)
-		portPool = port.NewFixedRangePool(*p2pPorts)
+		portPool = port.NewFixedRangePool(*p2pPorts, di.PortReservations)
 		natPinger = traversal.NewNoopPinger()
 	}
 
@@ -300,35 +346,29 @@ func (di *Dependencies) bootstrapStateKeeper(options node.Options) error {
 	deps := state.KeeperDeps{
 		NATStatusProvider:         nat.NewStatusTracker(lastStageName),
 		Publisher:                 di.EventBus,
-		ServiceLister:             di.ServicesManager,
+		ServiceLister:             di.serviceLister(),
 		IdentityProvider:          di.IdentityManager,
 		IdentityRegistry:          di.IdentityRegistry,
 		IdentityChannelCalculator: di.ChannelAddressCalculator,
 		BalanceProvider:           di.ConsumerBalanceTracker,
 		EarningsProvider:          di.AccountantPromiseSettler,
+		SessionStorage:            di.SessionStorage,
 	}
-	di.StateKeeper = state.NewKeeper(deps, state.DefaultDebounceDuration)
-	return di.StateKeeper.Subscribe(di.EventBus)
-}
-
-func (di *Dependencies) registerOpenvpnConnection(nodeOptions node.Options) {
-	service_openvpn.Bootstrap()
-	connectionFactory := func() (connection.Connection, error) {
-		return service_openvpn.NewClient(
-			// TODO instead of passing binary path here, Openvpn from node options could represent abstract vpn factory itself
-			nodeOptions.Openvpn.BinaryPath(),
-			nodeOptions.Directories.Script,
-			nodeOptions.Directories.Runtime,
-			di.SignerFactory,
-			di.IPResolver,
-		)
+	debouncing := state.DefaultDebounceConfig()
+	if options.LowResource {
+		debouncing = state.LowResourceDebounceConfig()
 	}
-	di.ConnectionRegistry.Register(service_openvpn.ServiceType, connectionFactory)
-}
-
-func (di *Dependencies) registerNoopConnection() {
-	service_noop.Bootstrap()
-	di.ConnectionRegistry.Register(service_noop.ServiceType, service_noop.NewConnection)
+	if options.StateDebounce.Statistics > 0 {
+		debouncing.Statistics = options.StateDebounce.Statistics
+	}
+	if options.StateDebounce.Sessions > 0 {
+		debouncing.Sessions = options.StateDebounce.Sessions
+	}
+	if options.StateDebounce.NAT > 0 {
+		debouncing.NAT = options.StateDebounce.NAT
+	}
+	di.StateKeeper = state.NewKeeper(deps, debouncing)
+	return di.StateKeeper.Subscribe(di.EventBus)
 }
 
 // Shutdown stops container
@@ -350,14 +390,58 @@ func (di *Dependencies) Shutdown() (err error) {
 		}
 	}
 
-	if di.ServicesManager != nil {
-		if err := di.ServicesManager.Kill(); err != nil {
+	errs = append(errs, di.stopDesktopComponents()...)
+
+	if di.PolicyOracle != nil {
+		di.PolicyOracle.Stop()
+	}
+
+	if di.Accountants != nil {
+		di.Accountants.Stop()
+	}
+
+	if di.EtherClient != nil {
+		di.EtherClient.Stop()
+	}
+
+	if di.LocationDBUpdater != nil {
+		di.LocationDBUpdater.Stop()
+	}
+
+	if di.LocationWatcher != nil {
+		di.LocationWatcher.Stop()
+	}
+
+	if di.NetworkMonitor != nil {
+		di.NetworkMonitor.Stop()
+	}
+
+	if di.Scheduler != nil {
+		di.Scheduler.Stop()
+	}
+
+	if di.OnDemand != nil {
+		if err := di.OnDemand.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.Gateway != nil {
+		if err := di.Gateway.Stop(); err != nil {
 			errs = append(errs, err)
 		}
 	}
 
-	if di.PolicyOracle != nil {
-		di.PolicyOracle.Stop()
+	if di.Proxy != nil {
+		if err := di.Proxy.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.ConnectionManagers != nil {
+		if err := di.ConnectionManagers.Stop(); err != nil {
+			errs = append(errs, err)
+		}
 	}
 
 	if di.NATService != nil {
@@ -375,48 +459,148 @@ func (di *Dependencies) Shutdown() (err error) {
 	if di.QualityClient != nil {
 		di.QualityClient.Stop()
 	}
+	if di.Telemetry != nil {
+		di.Telemetry.Stop()
+	}
 
 	if di.ServiceFirewall != nil {
 		di.ServiceFirewall.Teardown()
 	}
 	firewall.Reset()
 
+	if di.SessionStorage != nil {
+		di.SessionStorage.Stop()
+	}
+	if di.EarningsSeries != nil {
+		di.EarningsSeries.Stop()
+	}
+	if di.BackupManager != nil {
+		di.BackupManager.Stop()
+	}
 	if di.Storage != nil {
 		if err := di.Storage.Close(); err != nil {
 			errs = append(errs, err)
 		}
 	}
+	if di.EventRecorder != nil {
+		if err := di.EventRecorder.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := di.restartIfUpdated(); err != nil {
+		errs = append(errs, err)
+	}
 
 	return nil
 }
 
-func (di *Dependencies) bootstrapStorage(path string) error {
-	localStorage, err := boltdb.NewStorage(path)
-	if err != nil {
-		return err
+func (di *Dependencies) bootstrapStorage(path, keystoreDir string, options node.OptionsStorage) error {
+	retention := consumer_session.RetentionPolicy{
+		MaxAge:     config.GetDuration(config.FlagSessionHistoryMaxAge),
+		MaxRows:    config.GetInt(config.FlagSessionHistoryMaxRows),
+		CompactAge: config.GetDuration(config.FlagSessionHistoryCompactAge),
 	}
 
-	migrator := migrator.NewMigrator(localStorage)
-	err = migrator.RunMigrations(history.Sequence)
-	if err != nil {
-		return err
-	}
+	var database backup.Database
+	switch options.Backend {
+	case node.StorageBackendSQLite:
+		if options.Encryption != node.StorageEncryptionNone && options.Encryption != "" {
+			return errors.New("storage encryption is not supported by SQLite backend")
+		}
+		if err := restorePendingBackup(filepath.Join(path, "myst.sqlite")); err != nil {
+			return err
+		}
+		localStorage, err := sqlite.NewStorage(path)
+		if err != nil {
+			return err
+		}
+		di.Storage = localStorage
+		database = localStorage
+
+		if di.SessionStorage, err = consumer_session.NewSQLSessionStorage(localStorage.DB(), retention); err != nil {
+			return err
+		}
+	case node.StorageBackendBolt, "":
+		if err := restorePendingBackup(filepath.Join(path, "myst.db")); err != nil {
+			return err
+		}
+		localStorage, err := openStorage(path, options)
+		if err != nil {
+			return err
+		}
+		di.Storage = localStorage
+		database = localStorage
 
-	di.Storage = localStorage
+		migrator := migrator.NewMigrator(localStorage)
+		if err := migrator.RunMigrations(history.Sequence); err != nil {
+			return err
+		}
+
+		di.SessionStorage = consumer_session.NewSessionStorage(localStorage, retention)
+	default:
+		return fmt.Errorf("unknown storage backend: %s", options.Backend)
+	}
 
 	if !config.GetBool(config.FlagUserMode) {
 		netutil.SetRouteManagerStorage(di.Storage)
 	}
+	di.PortReservations = port.NewReservations(di.Storage)
 
 	invoiceStorage := pingpong.NewInvoiceStorage(di.Storage)
 	di.ProviderInvoiceStorage = pingpong.NewProviderInvoiceStorage(invoiceStorage)
 	di.ConsumerTotalsStorage = pingpong.NewConsumerTotalsStorage(di.Storage, di.EventBus)
 	di.AccountantPromiseStorage = pingpong.NewAccountantPromiseStorage(di.Storage)
-	di.SessionStorage = consumer_session.NewSessionStorage(di.Storage)
+	di.SessionStorage.StartMaintenance(time.Hour)
 	di.SettlementHistoryStorage = pingpong.NewSettlementHistoryStorage(di.Storage, pingpong.DefaultMaxEntriesPerChannel)
+	di.ConsumerStatsStorage = consumer_session.NewConsumerStatsStorage(di.Storage)
+	di.EarningsSeries = pingpong.NewEarningsSeries(di.Storage, pingpong.DefaultEarningsSeriesConfig())
+	di.EarningsSeries.Start()
+
+	di.BackupManager = backup.NewManager(database, keystoreDir, filepath.Join(path, "backups"), options.BackupKeep)
+	if options.BackupInterval > 0 {
+		di.BackupManager.Start(options.BackupInterval)
+	}
+	if err := di.ConsumerStatsStorage.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	if err := di.EarningsSeries.Subscribe(di.EventBus); err != nil {
+		return err
+	}
 	return di.SessionStorage.Subscribe(di.EventBus)
 }
 
+func restorePendingBackup(dbPath string) error {
+	restored, err := backup.ApplyPendingRestore(dbPath)
+	if err != nil {
+		return err
+	}
+	if restored {
+		log.Info().Msgf("Restored database %s from backup", dbPath)
+	}
+	return nil
+}
+
+func openStorage(path string, options node.OptionsStorage) (*boltdb.Bolt, error) {
+	switch options.Encryption {
+	case node.StorageEncryptionPassphrase:
+		if options.Passphrase == "" {
+			return nil, errors.New("storage passphrase is required for passphrase encryption")
+		}
+		return boltdb.NewEncryptedStorage(path, options.Passphrase)
+	case node.StorageEncryptionKeychain:
+		passphrase, err := boltdb.KeychainPassphrase()
+		if err != nil {
+			return nil, err
+		}
+		return boltdb.NewEncryptedStorage(path, passphrase)
+	case node.StorageEncryptionNone, "":
+		return boltdb.NewStorage(path)
+	default:
+		return nil, fmt.Errorf("unknown storage encryption: %s", options.Encryption)
+	}
+}
+
 func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequilaListener net.Listener) error {
 	// Consumer current session bandwidth
 	bandwidthTracker := bandwidth.NewTracker(di.EventBus)
@@ -446,9 +630,30 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		di.BCHelper,
 	)
 
+	accountantEndpoints := []pingpong.AccountantEndpoint{{
+		ID:      common.HexToAddress(nodeOptions.Accountant.AccountantID),
+		Address: nodeOptions.Accountant.AccountantEndpointAddress,
+	}}
+	for _, fallback := range nodeOptions.Accountant.Fallbacks {
+		accountantEndpoints = append(accountantEndpoints, pingpong.AccountantEndpoint{
+			ID:      common.HexToAddress(fallback.AccountantID),
+			Address: fallback.AccountantEndpointAddress,
+		})
+	}
+	di.Accountants = pingpong.NewAccountants(di.HTTPClient, accountantEndpoints)
+	go di.Accountants.Start(nodeOptions.Accountant.HealthCheckInterval)
+
 	if err := di.bootstrapAccountantPromiseSettler(nodeOptions); err != nil {
 		return err
 	}
+	di.Withdrawals = withdrawal.NewManager(
+		di.AccountantPromiseSettler,
+		di.SettlementHistoryStorage,
+		di.BCHelper,
+		di.ChainReader,
+		di.EventBus,
+		withdrawal.DefaultOptions(),
+	)
 
 	if err := di.bootstrapProviderRegistrar(nodeOptions); err != nil {
 		return err
@@ -460,7 +665,20 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		nodeOptions.Transactor.RegistryAddress,
 	)
 
-	di.AccountantCaller = pingpong.NewAccountantCaller(di.HTTPClient, nodeOptions.Accountant.AccountantEndpointAddress)
+	if nodeOptions.Faucet.Address != "" {
+		di.Faucet = faucet.NewManager(
+			faucet.NewClient(di.HTTPClient, nodeOptions.Faucet.Address),
+			faucet.NewStorage(di.Storage),
+			di.ChannelAddressCalculator,
+			di.EventBus,
+			faucet.DefaultOptions(),
+		)
+		if err := di.Faucet.Subscribe(di.EventBus); err != nil {
+			return err
+		}
+	}
+
+	di.AccountantCaller, _ = di.Accountants.Caller(di.Accountants.Main())
 	di.ConsumerBalanceTracker = pingpong.NewConsumerBalanceTracker(
 		di.EventBus,
 		common.HexToAddress(nodeOptions.Payments.MystSCAddress),
@@ -478,6 +696,13 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		return errors.Wrap(err, "could not subscribe consumer balance tracker to relevant events")
 	}
 
+	di.PromiseJournal = pingpong.NewPromiseJournal(di.Storage, di.Accountants)
+	go func() {
+		if err := di.PromiseJournal.Reconcile(); err != nil {
+			log.Warn().Err(err).Msg("Failed to reconcile promise journal with accountants")
+		}
+	}()
+
 	di.AccountantPromiseHandler = pingpong.NewAccountantPromiseHandler(pingpong.AccountantPromiseHandlerDeps{
 		AccountantPromiseStorage: di.AccountantPromiseStorage,
 		AccountantCaller:         di.AccountantCaller,
@@ -485,6 +710,8 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		FeeProvider:              di.Transactor,
 		Encryption:               di.Keystore,
 		EventBus:                 di.EventBus,
+		PromiseJournal:           di.PromiseJournal,
+		AdditionalAccountants:    di.Accountants.FallbackCallers(),
 	})
 
 	if err := di.AccountantPromiseHandler.Subscribe(di.EventBus); err != nil {
@@ -492,27 +719,102 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 	}
 
 	di.ConnectionRegistry = connection.NewRegistry()
-	di.ConnectionManager = connection.NewManager(
-		pingpong.ExchangeFactoryFunc(
-			di.Keystore,
-			di.SignerFactory,
-			di.ConsumerTotalsStorage,
-			nodeOptions.Transactor.ChannelImplementation,
-			nodeOptions.Transactor.RegistryAddress,
+	connectionConfig := connection.DefaultConfig()
+	if nodeOptions.KeepAliveInterval > 0 {
+		connectionConfig.KeepAlive.SendInterval = nodeOptions.KeepAliveInterval
+	}
+	if nodeOptions.KeepAliveTimeout > 0 {
+		connectionConfig.KeepAlive.DeadPeerTimeout = nodeOptions.KeepAliveTimeout
+	}
+	connectionConfig.Idle = session.IdleConfig{
+		Timeout:  nodeOptions.ConsumerIdle.Timeout,
+		MinBytes: nodeOptions.ConsumerIdle.MinBytes,
+	}
+	connectionConfig.Reconciliation = reconciliationConfig(nodeOptions.Reconciliation)
+	connectionConfig.QoSClass = nodeOptions.QoS.Request
+	connectionConfig.Handshake = session.HandshakeBudget{Total: nodeOptions.HandshakeTimeout}
+	if nodeOptions.SpeedTest.DownloadURL != "" {
+		connectionConfig.SpeedTest.DownloadURL = nodeOptions.SpeedTest.DownloadURL
+	}
+	if nodeOptions.SpeedTest.UploadURL != "" {
+		connectionConfig.SpeedTest.UploadURL = nodeOptions.SpeedTest.UploadURL
+	}
+	if nodeOptions.SpeedTest.Size > 0 {
+		connectionConfig.SpeedTest.Size = datasize.FromBytes(nodeOptions.SpeedTest.Size)
+	}
+	adBlocker := adblock.NewBlocker(adblock.Config{
+		Lists:          nodeOptions.AdBlock.Lists,
+		UpdateInterval: nodeOptions.AdBlock.UpdateInterval,
+		Address:        nodeOptions.AdBlock.Address,
+	}, di.HTTPClient)
+	newConnectionManager := func(id string) connection.Manager {
+		config := connectionConfig
+		config.ID = id
+		return connection.NewManager(
+			pingpong.ExchangeFactoryFunc(
+				di.Keystore,
+				di.SignerFactory,
+				di.ConsumerTotalsStorage,
+				nodeOptions.Transactor.ChannelImplementation,
+				nodeOptions.Transactor.RegistryAddress,
+				di.EventBus,
+				nodeOptions.Payments.ConsumerDataLeewayMegabytes,
+				nodeOptions.Reconciliation.PausePayments,
+			),
+			di.ConnectionRegistry.CreateConnection,
 			di.EventBus,
-			nodeOptions.Payments.ConsumerDataLeewayMegabytes,
-		),
-		di.ConnectionRegistry.CreateConnection,
-		di.EventBus,
-		di.IPResolver,
-		connection.DefaultConfig(),
-		connection.DefaultStatsReportInterval,
-		connection.NewValidator(
-			di.ConsumerBalanceTracker,
-			di.IdentityManager,
-		),
-		di.P2PDialer,
-	)
+			di.IPResolver,
+			config,
+			connection.DefaultStatsReportInterval,
+			connection.NewValidator(
+				di.ConsumerBalanceTracker,
+				di.IdentityManager,
+			),
+			di.P2PDialer,
+			connection.NewFailureDiagnostics(p2p.NewProviderPinger(di.BrokerConnector), connection.DefaultDiagnosticsTimeout),
+			adBlocker,
+		)
+	}
+	di.ConnectionManager = newConnectionManager(connection.DefaultConnectionID)
+	di.ConnectionManagers = isolation.NewPool(isolation.Config{
+		ProxyHost:         nodeOptions.Isolation.ProxyHost,
+		FirstProxyPort:    nodeOptions.Isolation.ProxyPort,
+		FirstRoutingTable: nodeOptions.Isolation.RoutingTable,
+		MaxConnections:    nodeOptions.Isolation.MaxConnections,
+	}, di.ConnectionManager, newConnectionManager)
+
+	if nodeOptions.OptionsNetwork.WatchInterval > 0 {
+		di.NetworkMonitor = netmon.NewMonitor(di.EventBus, nodeOptions.OptionsNetwork.WatchInterval)
+		di.NetworkMonitor.Start()
+	}
+
+	if err := di.bootstrapGateway(nodeOptions.Gateway); err != nil {
+		return err
+	}
+
+	if nodeOptions.ProxyAddress != "" {
+		if err := di.bootstrapProxy(nodeOptions.ProxyAddress); err != nil {
+			return err
+		}
+	}
+
+	if err := di.bootstrapScheduler(nodeOptions.Schedule); err != nil {
+		return err
+	}
+
+	if err := di.bootstrapOnDemand(nodeOptions.OnDemand); err != nil {
+		return err
+	}
+
+	if nodeOptions.Location.Verify {
+		if err := di.bootstrapConnectionVerifier(nodeOptions); err != nil {
+			return err
+		}
+	}
+
+	if err := di.bootstrapLoadTest(nodeOptions, newConnectionManager); err != nil {
+		return err
+	}
 
 	di.LogCollector = logconfig.NewCollector(&logconfig.CurrentLogOptions)
 	reporter, err := feedback.NewReporter(di.LogCollector, di.IdentityManager, nodeOptions.FeedbackURL)
@@ -525,6 +827,10 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 		return err
 	}
 
+	if err := di.bootstrapUpdater(nodeOptions); err != nil {
+		return err
+	}
+
 	tequilapiHTTPServer, err := di.bootstrapTequilapi(nodeOptions, tequilaListener)
 	if err != nil {
 		return err
@@ -536,46 +842,27 @@ func (di *Dependencies) bootstrapNodeComponents(nodeOptions node.Options, tequil
 	return nil
 }
 
-func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener net.Listener) (tequilapi.APIServer, error) {
-	if !nodeOptions.TequilapiEnabled {
-		return tequilapi.NewNoopAPIServer(), nil
-	}
-
-	router := tequilapi.NewAPIRouter()
-	tequilapi_endpoints.AddRouteForStop(router, utils.SoftKiller(di.Shutdown))
-	tequilapi_endpoints.AddRoutesForAuthentication(router, di.Authenticator, di.JWTAuthenticator)
-	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.ChannelAddressCalculator, di.AccountantPromiseSettler, di.BCHelper)
-	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry)
-	tequilapi_endpoints.AddRoutesForSessions(router, di.SessionStorage)
-	tequilapi_endpoints.AddRoutesForConnectionLocation(router, di.IPResolver, di.LocationResolver, di.LocationResolver)
-	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityClient)
-	tequilapi_endpoints.AddRoutesForService(router, di.ServicesManager, services.JSONParsersByType)
-	tequilapi_endpoints.AddRoutesForPayout(router, di.IdentityManager, di.SignerFactory, di.MysteriumAPI)
-	tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, router, config.GetString(config.FlagAccessPolicyAddress))
-	tequilapi_endpoints.AddRoutesForNAT(router, di.StateKeeper)
-	tequilapi_endpoints.AddRoutesForTransactor(router, di.Transactor, di.AccountantPromiseSettler, di.SettlementHistoryStorage)
-	tequilapi_endpoints.AddRoutesForConfig(router)
-	tequilapi_endpoints.AddRoutesForMMN(router, di.MMN)
-	tequilapi_endpoints.AddRoutesForFeedback(router, di.Reporter)
-	tequilapi_endpoints.AddRoutesForConnectivityStatus(router, di.SessionConnectivityStatusStorage)
-	if err := tequilapi_endpoints.AddRoutesForSSE(router, di.StateKeeper, di.EventBus); err != nil {
-		return nil, err
-	}
-
-	if config.GetBool(config.FlagPProfEnable) {
-		tequilapi_endpoints.AddRoutesForPProf(router)
+// function decides on network definition combined from chain ID or testnet/localnet flags and possible overrides
+// reconciliationConfig returns session traffic reconciliation configuration shared by consumer and provider.
+func reconciliationConfig(options node.OptionsReconciliation) session.ReconciliationConfig {
+	return session.ReconciliationConfig{
+		Interval:      options.Interval,
+		Tolerance:     options.Tolerance,
+		MinBytes:      options.MinBytes,
+		PausePayments: options.PausePayments,
 	}
-
-	corsPolicy := tequilapi.NewMysteriumCorsPolicy()
-	return tequilapi.NewServer(listener, router, corsPolicy), nil
 }
 
-// function decides on network definition combined from testnet/localnet flags and possible overrides
 func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err error) {
 	optionsNetwork := options.OptionsNetwork
 	network := metadata.DefaultNetwork
 
 	switch {
+	case optionsNetwork.ChainID != 0:
+		network, err = metadata.NetworkByChainID(optionsNetwork.ChainID)
+		if err != nil {
+			return err
+		}
 	case optionsNetwork.Testnet:
 		network = metadata.TestnetDefinition
 	case optionsNetwork.Localnet:
@@ -597,46 +884,69 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 
 	di.NetworkDefinition = network
 
-	if _, err := firewall.AllowURLAccess(
-		network.EtherClientRPC,
+	var etherClientRPCs []string
+	if !optionsNetwork.EtherClientLightMode {
+		for _, rpc := range stringutil.Split(network.EtherClientRPC, ',') {
+			etherClientRPCs = append(etherClientRPCs, strings.TrimSpace(rpc))
+		}
+	}
+	allowedURLs := append([]string{
 		network.MysteriumAPIAddress,
 		options.Transactor.TransactorEndpointAddress,
 		options.Accountant.AccountantEndpointAddress,
-	); err != nil {
+	}, etherClientRPCs...)
+	if options.Faucet.Address != "" {
+		allowedURLs = append(allowedURLs, options.Faucet.Address)
+	}
+	if _, err := firewall.AllowURLAccess(allowedURLs...); err != nil {
 		return err
 	}
-	if _, err := di.ServiceFirewall.AllowURLAccess(
-		network.EtherClientRPC,
-		network.MysteriumAPIAddress,
-		options.Transactor.TransactorEndpointAddress,
-		options.Accountant.AccountantEndpointAddress,
-	); err != nil {
+	if _, err := di.ServiceFirewall.AllowURLAccess(allowedURLs...); err != nil {
 		return err
 	}
 
 	di.MysteriumAPI = mysterium.NewClient(di.HTTPClient, network.MysteriumAPIAddress)
 
-	brokerURL, err := nats.ParseServerURI(di.NetworkDefinition.BrokerAddress)
-	if err != nil {
-		return err
+	var brokerURLs []string
+	for _, brokerAddress := range stringutil.Split(di.NetworkDefinition.BrokerAddress, ',') {
+		brokerURL, err := nats.ParseServerURI(strings.TrimSpace(brokerAddress))
+		if err != nil {
+			return err
+		}
+		brokerURLs = append(brokerURLs, brokerURL.String())
 	}
-	if _, err := di.ServiceFirewall.AllowURLAccess(brokerURL.String()); err != nil {
+	if _, err := di.ServiceFirewall.AllowURLAccess(brokerURLs...); err != nil {
 		return err
 	}
-	if di.BrokerConnection, err = di.BrokerConnector.Connect(brokerURL.String()); err != nil {
+	if di.BrokerConnection, err = di.BrokerConnector.ConnectMonitored(di.EventBus, brokerURLs...); err != nil {
 		return err
 	}
 
-	log.Info().Msg("Using Eth endpoint: " + network.EtherClientRPC)
-	di.EtherClient, err = paymentClient.NewReconnectableEthClient(network.EtherClientRPC)
+	registryStorage := registry.NewRegistrationStatusStorage(di.Storage)
+	if optionsNetwork.EtherClientLightMode {
+		log.Info().Msg("Using light client mode, blockchain is read through transactor: " + options.Transactor.TransactorEndpointAddress)
+		lightBlockchain := blockchain.NewLightBlockchain(di.HTTPClient, options.Transactor.TransactorEndpointAddress)
+		di.BCHelper = lightBlockchain
+		di.ChainReader = lightBlockchain
+		di.IdentityRegistry = identity_registry.NewIdentityRegistryLight(lightBlockchain, common.HexToAddress(options.Transactor.RegistryAddress), common.HexToAddress(options.Accountant.AccountantID), registryStorage, di.EventBus)
+		return di.IdentityRegistry.Subscribe(di.EventBus)
+	}
+
+	log.Info().Msg("Using Eth endpoints: " + network.EtherClientRPC)
+	di.EtherClient, err = blockchain.NewMultiClient(etherClientRPCs)
 	if err != nil {
 		return err
 	}
+	go di.EtherClient.Start(optionsNetwork.EtherClientCheckInterval)
 
 	bc := paymentClient.NewBlockchain(di.EtherClient, options.Payments.BCTimeout)
-	di.BCHelper = paymentClient.NewBlockchainWithRetries(bc, time.Millisecond*300, 3)
+	di.BCHelper = blockchain.NewCachedBlockchain(
+		paymentClient.NewBlockchainWithRetries(bc, time.Millisecond*300, 3),
+		optionsNetwork.EtherClientCacheTTL,
+		di.EtherClient,
+	)
+	di.ChainReader = withdrawal.NewChainReader(di.EtherClient)
 
-	registryStorage := registry.NewRegistrationStatusStorage(di.Storage)
 	if di.IdentityRegistry, err = identity_registry.NewIdentityRegistryContract(di.EtherClient, common.HexToAddress(options.Transactor.RegistryAddress), common.HexToAddress(options.Accountant.AccountantID), registryStorage, di.EventBus); err != nil {
 		return err
 	}
@@ -644,8 +954,18 @@ func (di *Dependencies) bootstrapNetworkComponents(options node.Options) (err er
 	return di.IdentityRegistry.Subscribe(di.EventBus)
 }
 
-func (di *Dependencies) bootstrapEventBus() {
+func (di *Dependencies) bootstrapEventBus(options node.OptionsEventRecord) error {
 	di.EventBus = eventbus.New()
+	if options.File == "" {
+		return nil
+	}
+
+	log.Warn().Msgf("Recording all events to %s, disable it when not debugging", options.File)
+	di.EventRecorder = eventbus.NewRecorder(options.File, eventbus.RecorderOptions{
+		Limit:        options.Limit,
+		RedactFields: eventbus.DefaultRedactedFields,
+	})
+	return di.EventRecorder.Subscribe(di.EventBus)
 }
 
 func (di *Dependencies) bootstrapIdentityComponents(options node.Options) {
@@ -672,16 +992,26 @@ func (di *Dependencies) bootstrapIdentityComponents(options node.Options) {
 
 }
 
-func (di *Dependencies) bootstrapQualityComponents(bindAddress string, options node.OptionsQuality) (err error) {
+func (di *Dependencies) bootstrapQualityComponents(bindAddress string, options node.OptionsQuality, lowResource bool) (err error) {
+	if options.MinQuality < 0 || options.MinQuality > 1 {
+		return errors.Errorf("minimum proposal quality must be in range [0, 1], got %v", options.MinQuality)
+	}
 	if _, err := firewall.AllowURLAccess(options.Address); err != nil {
 		return err
 	}
 	if _, err := di.ServiceFirewall.AllowURLAccess(options.Address); err != nil {
 		return err
 	}
-	di.QualityClient = quality.NewMorqaClient(bindAddress, options.Address, di.SignerFactory, 20*time.Second)
+	batchSize := quality.DefaultBatchSize
+	if lowResource {
+		batchSize = quality.LowResourceBatchSize
+	}
+	di.QualityClient = quality.NewMorqaClient(bindAddress, options.Address, di.SignerFactory, 20*time.Second, batchSize)
 	go di.QualityClient.Start()
 
+	di.QualityScores = quality.NewScoreCache(di.QualityClient, quality.DefaultScoresTTL)
+	di.ProposalRepository = quality.NewProposalRepository(di.ProposalRepository, di.QualityScores, options.MinQuality)
+
 	var transport quality.Transport
 	switch options.Type {
 	case node.QualityTypeElastic:
@@ -699,6 +1029,8 @@ func (di *Dependencies) bootstrapQualityComponents(bindAddress string, options n
 
 	// Quality metrics
 	qualitySender := quality.NewSender(transport, metadata.VersionAsString(), di.ConnectionManager, di.LocationResolver)
+	// Session data is sampled on every statistics update, which is too expensive for constrained devices.
+	qualitySender.SampleSessionData = !lowResource
 	if err := qualitySender.Subscribe(di.EventBus); err != nil {
 		return err
 	}
@@ -714,6 +1046,84 @@ func (di *Dependencies) bootstrapQualityComponents(bindAddress string, options n
 	return nil
 }
 
+func (di *Dependencies) bootstrapTelemetry(options node.OptionsTelemetry) error {
+	telemetryConfig := telemetry.DefaultConfig()
+	telemetryConfig.Enabled = options.Enabled
+	if options.Interval > 0 {
+		telemetryConfig.FlushInterval = options.Interval
+	}
+
+	// Salt is persisted only with user consent, so that hashed identity stays the same between restarts.
+	salt := config.Current.GetString(telemetry.SaltConfigKey)
+	if salt == "" {
+		var err error
+		if salt, err = telemetry.NewSalt(); err != nil {
+			return errors.Wrap(err, "could not generate telemetry salt")
+		}
+		if options.Enabled {
+			config.Current.SetUser(telemetry.SaltConfigKey, salt)
+			if err := config.Current.SaveUserConfig(); err != nil {
+				log.Warn().Err(err).Msg("Failed to save telemetry salt")
+			}
+		}
+	}
+
+	transport := telemetry.NewHTTPTransport(di.HTTPClient, options.Address)
+	di.Telemetry = telemetry.NewCollector(telemetryConfig, transport, di.LocationResolver, salt, metadata.VersionAsString())
+	if err := di.Telemetry.Subscribe(di.EventBus); err != nil {
+		return err
+	}
+	go di.Telemetry.Start()
+
+	return nil
+}
+
+func (di *Dependencies) bootstrapConnectionVerifier(options node.Options) error {
+	// Checkers bypass the caches, location is resolved through the tunnel right after connecting.
+	// Built-in checker takes the IP from another source, so a single faulty service can't confirm itself.
+	ipResolver := ip.NewResolver(di.HTTPClient, options.BindAddress, options.Location.VerifyIPDetectorURL)
+	builtin, err := location.NewBuiltInResolver(ipResolver)
+	if err != nil {
+		return err
+	}
+	checkers := []location.Resolver{
+		location.NewOracleResolver(di.HTTPClient, options.Location.IPDetectorURL),
+		builtin,
+	}
+
+	verifier := location.NewConnectionVerifier(checkers, di.ConnectionManager, di.EventBus, options.Location.VerifyStrict)
+	return verifier.Subscribe(di.EventBus)
+}
+
+func (di *Dependencies) bootstrapLocationDB(options node.Options) (*location.DBResolver, error) {
+	dbPath := options.Location.Address
+	if !filepath.IsAbs(dbPath) {
+		dbPath = filepath.Join(options.Directories.Script, dbPath)
+	}
+	if options.Location.DBUpdateURL == "" {
+		return location.NewExternalDBResolver(dbPath, di.IPResolver)
+	}
+
+	if _, err := firewall.AllowURLAccess(options.Location.DBUpdateURL); err != nil {
+		return nil, errors.Wrap(err, "failed to add firewall exception")
+	}
+	updater := location.NewDBUpdater(di.HTTPClient, options.Location.DBUpdateURL, dbPath, options.Location.DBUpdateInterval)
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		log.Info().Msgf("Downloading location db from %s", options.Location.DBUpdateURL)
+		if err := updater.Update(); err != nil {
+			return nil, err
+		}
+	}
+
+	resolver, err := location.NewExternalDBResolver(dbPath, di.IPResolver)
+	if err != nil {
+		return nil, err
+	}
+	updater.Start(resolver)
+	di.LocationDBUpdater = updater
+	return resolver, nil
+}
+
 func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err error) {
 	if _, err = firewall.AllowURLAccess(options.Location.IPDetectorURL); err != nil {
 		return errors.Wrap(err, "failed to add firewall exception")
@@ -731,7 +1141,7 @@ func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err e
 	case node.LocationTypeBuiltin:
 		resolver, err = location.NewBuiltInResolver(di.IPResolver)
 	case node.LocationTypeMMDB:
-		resolver, err = location.NewExternalDBResolver(filepath.Join(options.Directories.Script, options.Location.Address), di.IPResolver)
+		resolver, err = di.bootstrapLocationDB(options)
 	case node.LocationTypeOracle:
 		if _, err := firewall.AllowURLAccess(options.Location.Address); err != nil {
 			return err
@@ -759,9 +1169,107 @@ func (di *Dependencies) bootstrapLocationComponents(options node.Options) (err e
 		return err
 	}
 
+	if options.Consumer || options.Location.WatchInterval <= 0 {
+		return nil
+	}
+	// location changes are only relevant for announced service proposals
+	di.LocationWatcher = location.NewWatcher(resolver, di.EventBus, options.Location.WatchInterval)
+	if err := di.EventBus.SubscribeAsync(connection.AppTopicConnectionState, di.LocationWatcher.HandleConnectionEvent); err != nil {
+		return err
+	}
+	if err := di.EventBus.SubscribeAsync(location.AppTopicLocationChanged, di.LocationResolver.HandleLocationChangedEvent); err != nil {
+		return err
+	}
+	di.LocationWatcher.Start()
+
+	return nil
+}
+
+func (di *Dependencies) bootstrapGateway(options node.OptionsGateway) error {
+	di.Gateway = gateway.NewGateway(gateway.Config{
+		Enabled:   options.Enabled,
+		Interface: options.Interface,
+		Subnet:    options.Subnet,
+	}, func(c gateway.Config) error {
+		config.Current.SetUser(config.FlagGatewayEnabled.Name, c.Enabled)
+		config.Current.SetUser(config.FlagGatewayInterface.Name, c.Interface)
+		config.Current.SetUser(config.FlagGatewaySubnet.Name, c.Subnet)
+		return config.Current.SaveUserConfig()
+	})
+	if err := di.EventBus.SubscribeAsync(connection.AppTopicConnectionState, di.Gateway.HandleConnectionEvent); err != nil {
+		return err
+	}
+	return errors.Wrap(di.Gateway.Start(), "could not start gateway")
+}
+
+func (di *Dependencies) bootstrapScheduler(options node.OptionsSchedule) error {
+	rules, err := schedule.ParseRules(options.Rules)
+	if err != nil {
+		return errors.Wrap(err, "invalid schedule rules")
+	}
+	connector := connection.NewCountryConnector(
+		di.ConnectionManager,
+		quality.NewProposalRanker(di.ProposalRepository, di.QualityScores),
+		di.EventBus,
+		connection.DefaultCountryConnectConfig(),
+	)
+	di.Scheduler = schedule.NewScheduler(schedule.Config{
+		Enabled:    options.Enabled,
+		ConsumerID: options.ConsumerID,
+		Rules:      rules,
+	}, di.ConnectionManager, connector, di.Accountants, di.EventBus, options.CheckInterval, func(c schedule.Config) error {
+		specs := make([]string, len(c.Rules))
+		for i, rule := range c.Rules {
+			specs[i] = rule.String()
+		}
+		config.Current.SetUser(config.FlagScheduleEnabled.Name, c.Enabled)
+		config.Current.SetUser(config.FlagScheduleConsumerID.Name, c.ConsumerID)
+		config.Current.SetUser(config.FlagScheduleRules.Name, specs)
+		return config.Current.SaveUserConfig()
+	})
 	return nil
 }
 
+func (di *Dependencies) bootstrapOnDemand(options node.OptionsOnDemand) error {
+	connector := connection.NewCountryConnector(
+		di.ConnectionManager,
+		quality.NewProposalRanker(di.ProposalRepository, di.QualityScores),
+		di.EventBus,
+		connection.DefaultCountryConnectConfig(),
+	)
+	onDemandConfig := ondemand.Config{
+		Enabled:      options.Enabled,
+		ConsumerID:   options.ConsumerID,
+		Country:      strings.ToUpper(options.Country),
+		ServiceType:  options.ServiceType,
+		Destinations: options.Destinations,
+		Interface:    options.Interface,
+		IdleTimeout:  options.IdleTimeout,
+	}
+	if err := onDemandConfig.Validate(); err != nil {
+		return errors.Wrap(err, "invalid on-demand configuration")
+	}
+	di.OnDemand = ondemand.NewOnDemand(onDemandConfig, di.ConnectionManager, connector, di.Accountants, options.CheckInterval, func(c ondemand.Config) error {
+		config.Current.SetUser(config.FlagOnDemandEnabled.Name, c.Enabled)
+		config.Current.SetUser(config.FlagOnDemandConsumerID.Name, c.ConsumerID)
+		config.Current.SetUser(config.FlagOnDemandCountry.Name, c.Country)
+		config.Current.SetUser(config.FlagOnDemandServiceType.Name, c.ServiceType)
+		config.Current.SetUser(config.FlagOnDemandDestinations.Name, c.Destinations)
+		config.Current.SetUser(config.FlagOnDemandInterface.Name, c.Interface)
+		config.Current.SetUser(config.FlagOnDemandIdleTimeout.Name, c.IdleTimeout.String())
+		return config.Current.SaveUserConfig()
+	})
+	return errors.Wrap(di.OnDemand.Start(), "could not start on-demand mode")
+}
+
+func (di *Dependencies) bootstrapProxy(address string) error {
+	di.Proxy = proxy.NewProxy(address, di.ConnectionManager)
+	if err := di.EventBus.SubscribeAsync(connection.AppTopicConnectionState, di.Proxy.HandleConnectionEvent); err != nil {
+		return err
+	}
+	return errors.Wrap(di.Proxy.Start(), "could not start proxy")
+}
+
 func (di *Dependencies) bootstrapAuthenticator() error {
 	key, err := auth.NewJWTEncryptionKey(di.Storage)
 	if err != nil {
@@ -791,8 +1299,8 @@ func (di *Dependencies) bootstrapNATComponents(options node.Options) error {
 	return nil
 }
 
-func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall) error {
-	firewall.DefaultOutgoingFirewall = firewall.NewOutgoingTrafficFirewall(config.GetBool(config.FlagOutgoingFirewall))
+func (di *Dependencies) bootstrapFirewall(options node.OptionsFirewall, dataDir string) error {
+	firewall.DefaultOutgoingFirewall = firewall.NewOutgoingTrafficFirewall(config.GetBool(config.FlagOutgoingFirewall), dataDir)
 	if err := firewall.DefaultOutgoingFirewall.Setup(); err != nil {
 		return err
 	}
@@ -837,18 +1345,20 @@ func (di *Dependencies) handleConnStateChange() error {
 			di.HTTPClient.Reconnect()
 			di.QualityClient.Reconnect()
 
-			if err := di.EtherClient.Reconnect(); err != nil {
-				log.Warn().Err(err).Msg("Ethereum client failed to reconnect, will retry one more time")
-				// Default golang DNS resolver does not allow to reload /etc/resolv.conf more than once per 5 seconds.
-				// This could lead to the problem, when right after connect/disconnect new DNS config not applied instantly.
-				// Doing a couple of retries here to make sure we reconnected Ethererum client correctly.
-				// Default DNS timeout is 10 seconds. It's enough to try to reconnect only twice to cover 5 seconds lag for DNS config reload.
-				// https://github.com/mysteriumnetwork/node/issues/2282
+			if di.EtherClient != nil {
 				if err := di.EtherClient.Reconnect(); err != nil {
-					log.Error().Err(err).Msg("Ethereum client failed to reconnect")
+					log.Warn().Err(err).Msg("Ethereum client failed to reconnect, will retry one more time")
+					// Default golang DNS resolver does not allow to reload /etc/resolv.conf more than once per 5 seconds.
+					// This could lead to the problem, when right after connect/disconnect new DNS config not applied instantly.
+					// Doing a couple of retries here to make sure we reconnected Ethererum client correctly.
+					// Default DNS timeout is 10 seconds. It's enough to try to reconnect only twice to cover 5 seconds lag for DNS config reload.
+					// https://github.com/mysteriumnetwork/node/issues/2282
+					if err := di.EtherClient.Reconnect(); err != nil {
+						log.Error().Err(err).Msg("Ethereum client failed to reconnect")
+					}
 				}
+				di.EventBus.Publish(registry.AppTopicEthereumClientReconnected, struct{}{})
 			}
-			di.EventBus.Publish(registry.AppTopicEthereumClientReconnected, struct{}{})
 		}
 		latestState = e.State
 	})
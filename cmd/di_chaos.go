@@ -0,0 +1,43 @@
+// +build chaos
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"github.com/mysteriumnetwork/node/chaos"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/rs/zerolog/log"
+)
+
+func (di *Dependencies) bootstrapChaos(options node.OptionsChaos) error {
+	if options.Scenario == "" {
+		return nil
+	}
+
+	scenario, err := chaos.LoadScenario(options.Scenario)
+	if err != nil {
+		return err
+	}
+
+	log.Warn().Msgf("Injecting faults into payments and P2P communication according to scenario %s", options.Scenario)
+	injector := chaos.NewInjector(scenario)
+	di.P2PListener = chaos.WrapListener(di.P2PListener, injector)
+	di.P2PDialer = chaos.WrapDialer(di.P2PDialer, injector)
+	return nil
+}
@@ -1,3 +1,5 @@
+// +build !mobile_slim
+
 /*
  * Copyright (C) 2018 The "MysteriumNetwork/node" Authors.
  *
@@ -18,22 +20,32 @@
 package cmd
 
 import (
+	"path/filepath"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
+	"github.com/mysteriumnetwork/node/core/loadtest"
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/node"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/qos"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/management"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/mmn"
 	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/requests"
 	service_noop "github.com/mysteriumnetwork/node/services/noop"
 	service_openvpn "github.com/mysteriumnetwork/node/services/openvpn"
 	openvpn_discovery "github.com/mysteriumnetwork/node/services/openvpn/discovery"
@@ -43,16 +55,173 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	wireguard_service "github.com/mysteriumnetwork/node/services/wireguard/service"
+	"github.com/mysteriumnetwork/node/session"
+	session_event "github.com/mysteriumnetwork/node/session/event"
+	"github.com/mysteriumnetwork/node/session/freetier"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpong_noop "github.com/mysteriumnetwork/node/session/pingpong/noop"
 	"github.com/mysteriumnetwork/node/ui"
 	uinoop "github.com/mysteriumnetwork/node/ui/noop"
+	"github.com/mysteriumnetwork/node/updater"
 
 	"github.com/rs/zerolog/log"
 
 	"github.com/pkg/errors"
 )
 
+// desktopDependencies holds provider services, node maintenance and testing components,
+// which are left out of the slim mobile build.
+type desktopDependencies struct {
+	DiscoveryFactory service.DiscoveryFactory
+	LoadTest         *loadtest.Generator
+
+	ServicesManager  *service.Manager
+	ServiceRegistry  *service.Registry
+	PaymentEngines   *service.PaymentEngineRegistry
+	ServiceSessions  *service.SessionPool
+	ServiceDrainer   *service.Drainer
+	ServiceResources *service.ResourceMonitor
+
+	ManagementAgent *management.Agent
+	Updater         *updater.Updater
+}
+
+func (di *Dependencies) startDesktopComponents() {
+	if di.Updater != nil {
+		go di.Updater.Start()
+	}
+
+	if di.LoadTest != nil {
+		go func() {
+			if err := di.LoadTest.Start(); err != nil {
+				log.Error().Err(err).Msg("Load test failed")
+			}
+		}()
+	}
+}
+
+func (di *Dependencies) stopDesktopComponents() (errs []error) {
+	if di.LoadTest != nil {
+		if err := di.LoadTest.Stop(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.Updater != nil {
+		di.Updater.Stop()
+	}
+
+	if di.ServiceResources != nil {
+		di.ServiceResources.Stop()
+	}
+
+	if di.ServiceDrainer != nil {
+		if err := di.ServiceDrainer.Drain(); err != nil {
+			errs = append(errs, err)
+		}
+	} else if di.ServicesManager != nil {
+		if err := di.ServicesManager.Kill(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if di.ManagementAgent != nil {
+		di.ManagementAgent.Stop()
+	}
+	return errs
+}
+
+func (di *Dependencies) restartIfUpdated() error {
+	if di.Updater == nil {
+		return nil
+	}
+	return di.Updater.RestartIfUpdated()
+}
+
+func (di *Dependencies) serviceLister() *service.Manager {
+	return di.ServicesManager
+}
+
+func (di *Dependencies) bootstrapDiscoveryFactory(newDiscovery func() *discovery.Discovery) {
+	di.DiscoveryFactory = func() service.Discovery {
+		return newDiscovery()
+	}
+}
+
+func (di *Dependencies) bootstrapLoadTest(nodeOptions node.Options, newConnectionManager func(id string) connection.Manager) error {
+	if nodeOptions.LoadTest.Sessions > 0 {
+		di.LoadTest = loadtest.NewGenerator(loadtest.Options{
+			Sessions:         nodeOptions.LoadTest.Sessions,
+			ConsumerID:       identity.FromAddress(nodeOptions.LoadTest.ConsumerID),
+			ProviderID:       nodeOptions.LoadTest.ProviderID,
+			AccountantID:     common.HexToAddress(nodeOptions.Accountant.AccountantID),
+			DiscoveryTimeout: nodeOptions.LoadTest.DiscoveryTimeout,
+		}, di.ProposalRepository, newConnectionManager)
+	}
+	return nil
+}
+
+func (di *Dependencies) bootstrapUpdater(options node.Options) error {
+	if options.Update.URL == "" {
+		return nil
+	}
+	if !common.IsHexAddress(options.Update.Signer) {
+		return errors.Errorf("invalid release signer identity: %q", options.Update.Signer)
+	}
+
+	var sessions interface {
+		GetAll() []*service.Session
+	}
+	if di.ServiceSessions != nil {
+		sessions = di.ServiceSessions
+	}
+	var drainer interface {
+		StopAccepting()
+	}
+	if di.ServiceDrainer != nil {
+		drainer = di.ServiceDrainer
+	}
+
+	di.Updater = updater.NewUpdater(
+		requests.NewHTTPClient(options.BindAddress, 10*time.Minute),
+		sessions,
+		drainer,
+		func() error { return di.Node.Kill() },
+		metadata.VersionAsString(),
+		updater.Options{
+			URL:           options.Update.URL,
+			Channel:       options.Update.Channel,
+			Signer:        identity.FromAddress(options.Update.Signer),
+			Auto:          options.Update.Auto,
+			CheckInterval: options.Update.CheckInterval,
+			DrainTimeout:  options.Update.DrainTimeout,
+		},
+	)
+	return di.Updater.SetChannel(options.Update.Channel)
+}
+
+func (di *Dependencies) bootstrapManagement(options node.Options) error {
+	if options.Management.Operator == "" || di.ServicesManager == nil {
+		return nil
+	}
+	if !common.IsHexAddress(options.Management.Operator) {
+		return errors.Errorf("invalid management operator identity: %s", options.Management.Operator)
+	}
+
+	auditLogPath := options.Management.AuditLog
+	if auditLogPath == "" {
+		auditLogPath = filepath.Join(options.Directories.Data, "management-audit.log")
+	}
+
+	di.ManagementAgent = management.NewAgent(
+		di.BrokerConnection,
+		identity.FromAddress(options.Management.Operator),
+		management.NewExecutor(di.ServicesManager, config.Current),
+		management.NewAuditLog(auditLogPath),
+	)
+	return di.ManagementAgent.Subscribe(di.EventBus)
+}
+
 // bootstrapServices loads all the components required for running services
 func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 	if nodeOptions.Consumer {
@@ -60,6 +229,10 @@ func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
 		return nil
 	}
 
+	if err := nodeOptions.Description.Validate(); err != nil {
+		return errors.Wrap(err, "invalid provider description")
+	}
+
 	err := di.bootstrapServiceComponents(nodeOptions)
 	if err != nil {
 		return errors.Wrap(err, "service bootstrap failed")
@@ -82,15 +255,17 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 			}
 
 			wgOptions := serviceOptions.(wireguard_service.Options)
+			wgOptions.Obfuscators = nodeOptions.Obfuscation.Offer
+			wgOptions.DNSFilter = nodeOptions.DNSFilter
 
-			// TODO: Use global port pool once migrated to p2p.
-			var portPool port.ServicePortSupplier
+			portRange := nodeOptions.ServicePortRanges[wireguard.ServiceType]
 			if wgOptions.Ports.IsSpecified() {
-				log.Info().Msgf("Fixed service port range (%s) configured, using custom port pool", wgOptions.Ports)
-				portPool = port.NewFixedRangePool(*wgOptions.Ports)
-			} else {
-				portPool = port.NewPool()
+				portRange = wgOptions.Ports
 			}
+			if portRange != nil && portRange.IsSpecified() {
+				log.Info().Msgf("Fixed service port range (%s) configured, using custom port pool", portRange)
+			}
+			portPool := port.NewServicePool(wireguard.ServiceType, portRange, di.PortReservations)
 
 			svc := wireguard_service.NewManager(
 				di.IPResolver,
@@ -102,7 +277,13 @@ func (di *Dependencies) bootstrapServiceWireguard(nodeOptions node.Options) {
 				portPool,
 				di.ServiceFirewall,
 			)
-			return svc, wireguard_service.GetProposal(loc), nil
+			proposal, err := di.announceNATType(wireguard_service.GetProposal(loc, wgOptions.Obfuscators), loc, nodeOptions.NATType)
+			if err != nil {
+				return nil, market.ServiceProposal{}, err
+			}
+			proposal.QoSClasses = qos.Names(nodeOptions.QoS.Classes)
+			proposal.ContentFilter = wgOptions.DNSFilter.CategoryNames()
+			return svc, describeProposal(proposal, nodeOptions.Description), nil
 		},
 	)
 }
@@ -119,14 +300,17 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 		}
 
 		transportOptions := serviceOptions.(openvpn_service.Options)
-		proposal := openvpn_discovery.NewServiceProposalWithLocation(loc, transportOptions.Protocol)
+		proposal, err := di.announceNATType(openvpn_discovery.NewServiceProposalWithLocation(loc, transportOptions.Protocol, openvpn_service.Obfuscators(nodeOptions, transportOptions)), loc, nodeOptions.NATType)
+		if err != nil {
+			return nil, market.ServiceProposal{}, err
+		}
+		proposal.ContentFilter = nodeOptions.DNSFilter.CategoryNames()
 
-		// TODO: Use global port pool once migrated to p2p.
 		var portPool port.ServicePortSupplier
 		if transportOptions.Port != 0 {
 			portPool = port.NewPoolFixed(port.Port(transportOptions.Port))
 		} else {
-			portPool = port.NewPool()
+			portPool = port.NewServicePool(service_openvpn.ServiceType, nodeOptions.ServicePortRanges[service_openvpn.ServiceType], di.PortReservations)
 		}
 
 		manager := openvpn_service.NewManager(
@@ -141,7 +325,7 @@ func (di *Dependencies) bootstrapServiceOpenvpn(nodeOptions node.Options) {
 			di.EventBus,
 			di.ServiceFirewall,
 		)
-		return manager, proposal, nil
+		return manager, describeProposal(proposal, nodeOptions.Description), nil
 	}
 	di.ServiceRegistry.Register(service_openvpn.ServiceType, createService)
 }
@@ -155,11 +339,49 @@ func (di *Dependencies) bootstrapServiceNoop(nodeOptions node.Options) {
 				return nil, market.ServiceProposal{}, err
 			}
 
-			return service_noop.NewManager(), service_noop.GetProposal(loc), nil
+			proposal := service_noop.GetProposal(loc)
+			return service_noop.NewManager(serviceOptions.(service_noop.Options), di.EventBus), describeProposal(proposal, nodeOptions.Description), nil
 		},
 	)
 }
 
+// describeProposal attaches provider self-description to the proposal if its service definition can carry it.
+// announceNATType adds NAT type of provider to the proposal location, so that consumers behind incompatible NAT
+// skip the provider. Provider is not behind NAT if its public IP is assigned to the local interface, otherwise NAT
+// type stays unknown unless configured, such providers are considered reachable from any NAT.
+func (di *Dependencies) announceNATType(proposal market.ServiceProposal, loc location.Location, natType string) (market.ServiceProposal, error) {
+	if natType != "" && !reducer.IsNATType(natType) {
+		return proposal, errors.Errorf("unknown NAT type: %s", natType)
+	}
+	if natType == "" {
+		outboundIP, err := di.IPResolver.GetOutboundIP()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to detect NAT type")
+			return proposal, nil
+		}
+		if outboundIP != loc.IP {
+			return proposal, nil
+		}
+		natType = reducer.NATTypeNone
+	}
+
+	definition, ok := proposal.ServiceDefinition.(market.RelocatableServiceDefinition)
+	if !ok {
+		return proposal, nil
+	}
+	marketLoc := definition.GetLocation()
+	marketLoc.NATType = natType
+	proposal.ServiceDefinition = definition.WithLocation(marketLoc)
+	return proposal, nil
+}
+
+func describeProposal(proposal market.ServiceProposal, description market.ProviderDescription) market.ServiceProposal {
+	if definition, ok := proposal.ServiceDefinition.(market.DescribedServiceDefinition); ok {
+		proposal.ServiceDefinition = definition.WithDescription(description)
+	}
+	return proposal
+}
+
 func (di *Dependencies) bootstrapProviderRegistrar(nodeOptions node.Options) error {
 	if nodeOptions.Consumer {
 		log.Debug().Msg("Skipping provider registrar for consumer mode")
@@ -184,20 +406,24 @@ func (di *Dependencies) bootstrapAccountantPromiseSettler(nodeOptions node.Optio
 		return nil
 	}
 
-	di.AccountantPromiseSettler = pingpong.NewAccountantPromiseSettler(
-		di.EventBus,
-		di.Transactor,
-		di.AccountantPromiseStorage,
-		di.BCHelper,
-		di.IdentityRegistry,
-		di.Keystore,
-		di.SettlementHistoryStorage,
-		pingpong.AccountantPromiseSettlerConfig{
-			AccountantAddress:    common.HexToAddress(nodeOptions.Accountant.AccountantID),
-			Threshold:            nodeOptions.Payments.AccountantPromiseSettlingThreshold,
-			MaxWaitForSettlement: nodeOptions.Payments.SettlementTimeout,
-		},
-	)
+	settlers := make(map[common.Address]pingpong.AccountantPromiseSettler)
+	for _, accountantID := range di.Accountants.IDs() {
+		settlers[accountantID] = pingpong.NewAccountantPromiseSettler(
+			di.EventBus,
+			di.Transactor,
+			di.AccountantPromiseStorage,
+			di.BCHelper,
+			di.IdentityRegistry,
+			di.Keystore,
+			di.SettlementHistoryStorage,
+			pingpong.AccountantPromiseSettlerConfig{
+				AccountantAddress:    accountantID,
+				Threshold:            nodeOptions.Payments.AccountantPromiseSettlingThreshold,
+				MaxWaitForSettlement: nodeOptions.Payments.SettlementTimeout,
+			},
+		)
+	}
+	di.AccountantPromiseSettler = pingpong.NewMultiAccountantPromiseSettler(di.Accountants.Main(), settlers)
 	return di.AccountantPromiseSettler.Subscribe()
 }
 
@@ -218,8 +444,24 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 	)
 	go di.PolicyOracle.Start()
 
-	newP2PSessionHandler := func(serviceInstance *service.Instance, channel p2p.Channel) *service.SessionManager {
-		paymentEngineFactory := pingpong.InvoiceFactoryCreator(
+	sessionConfig := service.DefaultConfig()
+	if nodeOptions.KeepAliveInterval > 0 {
+		sessionConfig.KeepAlive.SendInterval = nodeOptions.KeepAliveInterval
+	}
+	if nodeOptions.KeepAliveTimeout > 0 {
+		sessionConfig.KeepAlive.DeadPeerTimeout = nodeOptions.KeepAliveTimeout
+	}
+	sessionConfig.Idle = session.IdleConfig{
+		Timeout:  nodeOptions.ProviderIdle.Timeout,
+		MinBytes: nodeOptions.ProviderIdle.MinBytes,
+	}
+	sessionConfig.Reconciliation = reconciliationConfig(nodeOptions.Reconciliation)
+	sessionConfig.Handshake = session.HandshakeBudget{Total: nodeOptions.HandshakeTimeout}
+	sessionConfig.QoSClasses = nodeOptions.QoS.Classes
+	sessionConfig.ServeSpeedTests = nodeOptions.SpeedTest.Serve
+	freeTrials := freetier.NewTrials(di.IdentityRegistry)
+	di.PaymentEngines = service.NewPaymentEngineRegistry(func(serviceInstance *service.Instance, channel p2p.Channel) service.PaymentEngineFactory {
+		return pingpong.InvoiceFactoryCreator(
 			channel, nodeOptions.Payments.ProviderInvoiceFrequency,
 			pingpong.PromiseWaitTimeout, di.ProviderInvoiceStorage,
 			nodeOptions.Transactor.RegistryAddress,
@@ -231,19 +473,43 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 			di.EventBus,
 			serviceInstance.Proposal,
 			di.AccountantPromiseHandler,
-			common.HexToAddress(nodeOptions.Accountant.AccountantID),
+			di.Accountants.IDs(),
+			freeTrials,
 		)
+	})
+	if len(nodeOptions.Payments.FreeTier.Services) > 0 {
+		freeTierUsage := freetier.NewUsageTracker(freetier.Quota{
+			Duration: nodeOptions.Payments.FreeTier.Duration,
+			Bytes:    nodeOptions.Payments.FreeTier.Bytes,
+			Period:   nodeOptions.Payments.FreeTier.Period,
+		})
+		for _, serviceType := range nodeOptions.Payments.FreeTier.Services {
+			di.PaymentEngines.Register(serviceType, freetier.NewEngineCreator(freeTierUsage, di.EventBus))
+		}
+	}
+	sessionStarts := service.NewStartLimiter(nodeOptions.SessionStarts.Limit, nodeOptions.SessionStarts.QueueTimeout)
+	newP2PSessionHandler := func(serviceInstance *service.Instance, channel p2p.Channel) *service.SessionManager {
 		return service.NewSessionManager(
 			serviceInstance,
 			di.ServiceSessions,
-			paymentEngineFactory,
+			sessionStarts,
+			di.PaymentEngines.Factory(serviceInstance, channel),
 			di.NATTracker,
 			di.EventBus,
 			channel,
-			service.DefaultConfig(),
+			sessionConfig,
 		)
 	}
 
+	countryResolver, err := location.NewBuiltInResolver(di.IPResolver)
+	if err != nil {
+		return errors.Wrap(err, "failed to load consumer country database")
+	}
+	consumerLocator, err := location.NewIPLocator(countryResolver, nodeOptions.Location.ASNAddress)
+	if err != nil {
+		return errors.Wrap(err, "failed to load consumer ASN database")
+	}
+
 	di.ServicesManager = service.NewManager(
 		di.ServiceRegistry,
 		di.DiscoveryFactory,
@@ -252,12 +518,41 @@ func (di *Dependencies) bootstrapServiceComponents(nodeOptions node.Options) err
 		di.P2PListener,
 		newP2PSessionHandler,
 		di.SessionConnectivityStatusStorage,
+		consumerLocator,
 	)
 
+	drainOptions := service.DefaultDrainOptions()
+	drainOptions.Timeout = nodeOptions.Shutdown.DrainTimeout
+	drainOptions.PaymentTimeout = nodeOptions.Shutdown.PaymentTimeout
+	di.ServiceDrainer = service.NewDrainer(di.ServicesManager, di.ServiceSessions, drainOptions)
+
+	if nodeOptions.Resources.Interval > 0 {
+		di.ServiceResources = service.NewResourceMonitor(di.ServicesManager, di.EventBus, nodeOptions.Resources.Interval, service.ResourceThresholds{
+			Goroutines:      nodeOptions.Resources.MaxGoroutines,
+			CPUPercent:      nodeOptions.Resources.MaxCPUPercent,
+			MemoryBytes:     nodeOptions.Resources.MaxMemoryBytes,
+			FileDescriptors: nodeOptions.Resources.MaxFileDescriptors,
+		})
+		di.ServiceResources.Start()
+	}
+
+	if err := di.EventBus.SubscribeAsync(location.AppTopicLocationChanged, di.ServicesManager.HandleLocationChangedEvent); err != nil {
+		log.Error().Msg("Failed to subscribe services to location changes")
+	}
+	renewPortMappings := func(location.AppEventLocationChanged) {
+		di.PortMapper.Renew()
+	}
+	if err := di.EventBus.SubscribeAsync(location.AppTopicLocationChanged, renewPortMappings); err != nil {
+		log.Error().Msg("Failed to subscribe port mappings to location changes")
+	}
+
 	serviceCleaner := service.Cleaner{SessionStorage: di.ServiceSessions}
 	if err := di.EventBus.Subscribe(servicestate.AppTopicServiceStatus, serviceCleaner.HandleServiceStatus); err != nil {
 		log.Error().Msg("Failed to subscribe service cleaner")
 	}
+	if err := di.EventBus.SubscribeAsync(session_event.AppTopicDataTransferred, di.ServiceSessions.ConsumeDataTransferredEvent); err != nil {
+		log.Error().Msg("Failed to subscribe service session traffic tracking")
+	}
 
 	return nil
 }
@@ -279,12 +574,34 @@ func (di *Dependencies) registerWireguardConnection(nodeOptions node.Options) {
 		opts := wireguard_connection.Options{
 			DNSScriptDir:     nodeOptions.Directories.Script,
 			HandshakeTimeout: 1 * time.Minute,
+			Obfuscation:      nodeOptions.Obfuscation.Request,
 		}
 		return wireguard_connection.NewConnection(opts, di.IPResolver, endpointFactory, handshakeWaiter)
 	}
 	di.ConnectionRegistry.Register(wireguard.ServiceType, connFactory)
 }
 
+func (di *Dependencies) registerOpenvpnConnection(nodeOptions node.Options) {
+	service_openvpn.Bootstrap()
+	connectionFactory := func() (connection.Connection, error) {
+		return service_openvpn.NewClient(
+			// TODO instead of passing binary path here, Openvpn from node options could represent abstract vpn factory itself
+			nodeOptions.Openvpn.BinaryPath(),
+			nodeOptions.Directories.Script,
+			nodeOptions.Directories.Runtime,
+			di.SignerFactory,
+			di.IPResolver,
+			nodeOptions.Obfuscation.Request,
+		)
+	}
+	di.ConnectionRegistry.Register(service_openvpn.ServiceType, connectionFactory)
+}
+
+func (di *Dependencies) registerNoopConnection() {
+	service_noop.Bootstrap()
+	di.ConnectionRegistry.Register(service_noop.ServiceType, service_noop.NewConnection)
+}
+
 func (di *Dependencies) bootstrapUIServer(options node.Options) (err error) {
 	if !options.UI.UIEnabled {
 		di.UIServer = uinoop.NewServer()
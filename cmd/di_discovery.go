@@ -24,7 +24,6 @@ import (
 	"github.com/mysteriumnetwork/node/core/discovery/apidiscovery"
 	"github.com/mysteriumnetwork/node/core/discovery/brokerdiscovery"
 	"github.com/mysteriumnetwork/node/core/node"
-	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/pkg/errors"
 )
 
@@ -54,8 +53,8 @@ func (di *Dependencies) bootstrapDiscoveryComponents(options node.OptionsDiscove
 	}
 
 	di.ProposalRepository = proposalRepository
-	di.DiscoveryFactory = func() service.Discovery {
+	di.bootstrapDiscoveryFactory(func() *discovery.Discovery {
 		return discovery.NewService(di.IdentityRegistry, discoveryRegistry, options.PingInterval, di.SignerFactory, di.EventBus)
-	}
+	})
 	return nil
 }
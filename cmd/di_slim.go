@@ -0,0 +1,114 @@
+// +build mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"net"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery"
+	"github.com/mysteriumnetwork/node/core/node"
+	pingpong_noop "github.com/mysteriumnetwork/node/session/pingpong/noop"
+	"github.com/mysteriumnetwork/node/tequilapi"
+	uinoop "github.com/mysteriumnetwork/node/ui/noop"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotIncludedInBuild indicates that the requested component was left out of the slim mobile build.
+var ErrNotIncludedInBuild = errors.New("component is not included in this build")
+
+// desktopDependencies is empty, provider services, node maintenance and testing components
+// are left out of the slim mobile build.
+type desktopDependencies struct{}
+
+func (di *Dependencies) startDesktopComponents() {}
+
+func (di *Dependencies) stopDesktopComponents() []error {
+	return nil
+}
+
+func (di *Dependencies) restartIfUpdated() error {
+	return nil
+}
+
+func (di *Dependencies) serviceLister() interface{} {
+	return nil
+}
+
+func (di *Dependencies) bootstrapDiscoveryFactory(func() *discovery.Discovery) {}
+
+func (di *Dependencies) bootstrapLoadTest(nodeOptions node.Options, _ func(id string) connection.Manager) error {
+	if nodeOptions.LoadTest.Sessions > 0 {
+		return errors.Wrap(ErrNotIncludedInBuild, "load test")
+	}
+	return nil
+}
+
+func (di *Dependencies) bootstrapUpdater(options node.Options) error {
+	if options.Update.URL != "" {
+		return errors.Wrap(ErrNotIncludedInBuild, "updater")
+	}
+	return nil
+}
+
+func (di *Dependencies) bootstrapManagement(options node.Options) error {
+	return nil
+}
+
+// bootstrapServices fails for providers, the slim build contains consumer components only.
+func (di *Dependencies) bootstrapServices(nodeOptions node.Options) error {
+	if nodeOptions.Consumer {
+		log.Debug().Msg("Skipping services bootstrap for consumer mode")
+		return nil
+	}
+	return errors.Wrap(ErrNotIncludedInBuild, "provider services")
+}
+
+func (di *Dependencies) bootstrapProviderRegistrar(nodeOptions node.Options) error {
+	if nodeOptions.Consumer {
+		return nil
+	}
+	return errors.Wrap(ErrNotIncludedInBuild, "provider registrar")
+}
+
+func (di *Dependencies) bootstrapAccountantPromiseSettler(nodeOptions node.Options) error {
+	di.AccountantPromiseSettler = &pingpong_noop.NoopAccountantPromiseSettler{}
+	return nil
+}
+
+// registerConnections registers nothing, mobile applications register their own WireGuard connection.
+func (di *Dependencies) registerConnections(nodeOptions node.Options) {}
+
+func (di *Dependencies) bootstrapUIServer(options node.Options) (err error) {
+	di.UIServer = uinoop.NewServer()
+	return nil
+}
+
+func (di *Dependencies) bootstrapMMN(options node.Options) error {
+	return nil
+}
+
+func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener net.Listener) (tequilapi.APIServer, error) {
+	if nodeOptions.TequilapiEnabled {
+		return nil, errors.Wrap(ErrNotIncludedInBuild, "tequilapi")
+	}
+	return tequilapi.NewNoopAPIServer(), nil
+}
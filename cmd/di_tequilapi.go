@@ -0,0 +1,137 @@
+// +build !mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package cmd
+
+import (
+	"net"
+	"os"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/logconfig"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/services"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	tequilapi_endpoints "github.com/mysteriumnetwork/node/tequilapi/endpoints"
+	"github.com/mysteriumnetwork/node/utils"
+	"github.com/pkg/errors"
+)
+
+func (di *Dependencies) bootstrapTequilapi(nodeOptions node.Options, listener net.Listener) (tequilapi.APIServer, error) {
+	if !nodeOptions.TequilapiEnabled {
+		return tequilapi.NewNoopAPIServer(), nil
+	}
+
+	if err := contract.SetTokensPrecision(nodeOptions.TequilapiTokensPrecision); err != nil {
+		return nil, errors.Wrap(err, "invalid tequilapi tokens precision")
+	}
+
+	router := tequilapi.NewAPIRouter()
+	tequilapi_endpoints.AddRouteForStop(router, utils.SoftKiller(di.Shutdown))
+	tequilapi_endpoints.AddRoutesForAuthentication(router, di.Authenticator, di.JWTAuthenticator)
+	tequilapi_endpoints.AddRoutesForIdentities(router, di.IdentityManager, di.IdentitySelector, di.IdentityRegistry, di.ConsumerBalanceTracker, di.ChannelAddressCalculator, di.AccountantPromiseSettler, di.BCHelper)
+	countryConnector := connection.NewCountryConnector(
+		di.ConnectionManager,
+		quality.NewProposalRanker(di.ProposalRepository, di.QualityScores),
+		di.EventBus,
+		connection.DefaultCountryConnectConfig(),
+	)
+	tequilapi_endpoints.AddRoutesForConnection(router, di.ConnectionManager, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.Accountants, countryConnector)
+	tequilapi_endpoints.AddRoutesForNamedConnection(router, di.ConnectionManagers, di.StateKeeper, di.ProposalRepository, di.IdentityRegistry, di.Accountants)
+	tequilapi_endpoints.AddRoutesForConnectionPreflight(router, connection.NewPreflight(
+		connection.NewValidator(di.ConsumerBalanceTracker, di.IdentityManager),
+		di.IdentityRegistry,
+		p2p.NewProviderPinger(di.BrokerConnector),
+		connection.DefaultPreflightPingTimeout,
+	), di.ProposalRepository)
+	tequilapi_endpoints.AddRoutesForSessions(router, di.SessionStorage, logconfig.SessionLogs)
+	tequilapi_endpoints.AddRoutesForBackups(router, di.BackupManager)
+	tequilapi_endpoints.AddRoutesForPromiseJournal(router, di.PromiseJournal)
+	if di.EtherClient != nil {
+		tequilapi_endpoints.AddRoutesForDetailedHealthCheck(router, time.Now, os.Getpid, di.EtherClient)
+	}
+	tequilapi_endpoints.AddRoutesForConnectionLocation(router, di.IPResolver, di.LocationResolver, di.LocationResolver)
+	tequilapi_endpoints.AddRoutesForProposals(router, di.ProposalRepository, di.QualityScores, pingpong.NewCostEstimator(di.Transactor))
+	tequilapi_endpoints.AddRoutesForService(router, di.ServicesManager, di.ServiceSessions, services.JSONParsersByType)
+	tequilapi_endpoints.AddRoutesForPayout(router, di.IdentityManager, di.SignerFactory, di.MysteriumAPI)
+	tequilapi_endpoints.AddRoutesForAccessPolicies(di.HTTPClient, router, config.GetString(config.FlagAccessPolicyAddress))
+	tequilapi_endpoints.AddRoutesForNAT(router, di.StateKeeper)
+	tequilapi_endpoints.AddRoutesForProvider(router, di.StateKeeper, di.ConsumerStatsStorage)
+	tequilapi_endpoints.AddRoutesForEarnings(router, di.EarningsSeries)
+	tequilapi_endpoints.AddRoutesForTransactor(router, di.Transactor, di.AccountantPromiseSettler, di.SettlementHistoryStorage)
+	tequilapi_endpoints.AddRoutesForWithdrawal(router, di.Withdrawals)
+	if di.Faucet != nil {
+		tequilapi_endpoints.AddRoutesForFaucet(router, di.Faucet)
+	}
+	tequilapi_endpoints.AddRoutesForConfig(router)
+	tequilapi_endpoints.AddRoutesForMMN(router, di.MMN)
+	tequilapi_endpoints.AddRoutesForFeedback(router, di.Reporter)
+	tequilapi_endpoints.AddRoutesForGateway(router, di.Gateway)
+	tequilapi_endpoints.AddRoutesForSchedule(router, di.Scheduler)
+	tequilapi_endpoints.AddRoutesForOnDemand(router, di.OnDemand)
+	tequilapi_endpoints.AddRoutesForConnectivityStatus(router, di.SessionConnectivityStatusStorage)
+	tequilapi_endpoints.AddRoutesForTelemetry(router, di.Telemetry)
+	if di.Updater != nil {
+		tequilapi_endpoints.AddRoutesForUpdate(router, di.Updater)
+	}
+	if di.ServiceDrainer != nil {
+		tequilapi_endpoints.AddRoutesForDrain(router, di.ServiceDrainer)
+	}
+	if err := tequilapi_endpoints.AddRoutesForSSE(router, di.StateKeeper, di.EventBus); err != nil {
+		return nil, err
+	}
+
+	if config.GetBool(config.FlagPProfEnable) {
+		tequilapi_endpoints.AddRoutesForPProf(router)
+	}
+
+	corsPolicy, err := tequilapi.NewUIOriginPolicy(tequilapi.NewMysteriumCorsPolicy(), nodeOptions.TequilapiAllowedOrigins, func(entries []string) error {
+		config.Current.SetUser(config.FlagTequilapiAllowedOrigins.Name, entries)
+		return config.Current.SaveUserConfig()
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid tequilapi allowed origins")
+	}
+	tequilapi_endpoints.AddRoutesForUIOrigins(router, corsPolicy)
+
+	requestMetrics := tequilapi.NewRequestMetrics()
+	eventMetrics, _ := di.EventBus.(eventbus.MetricsProvider)
+	tequilapi_endpoints.AddRoutesForMetrics(router, requestMetrics, eventMetrics)
+
+	rateLimit := tequilapi.RateLimitConfig{
+		Reads: tequilapi.RateLimit{
+			Rate:  nodeOptions.TequilapiRateLimit.ReadRate,
+			Burst: nodeOptions.TequilapiRateLimit.ReadBurst,
+		},
+		Writes: tequilapi.RateLimit{
+			Rate:  nodeOptions.TequilapiRateLimit.WriteRate,
+			Burst: nodeOptions.TequilapiRateLimit.WriteBurst,
+		},
+	}
+	handler := tequilapi.ApplyAccessLog(tequilapi.ApplyRateLimit(router, rateLimit), router, requestMetrics)
+
+	return tequilapi.NewServer(listener, handler, corsPolicy), nil
+}
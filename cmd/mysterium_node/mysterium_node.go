@@ -23,6 +23,8 @@ import (
 	command_cli "github.com/mysteriumnetwork/node/cmd/commands/cli"
 	"github.com/mysteriumnetwork/node/cmd/commands/daemon"
 	"github.com/mysteriumnetwork/node/cmd/commands/license"
+	"github.com/mysteriumnetwork/node/cmd/commands/monitor"
+	"github.com/mysteriumnetwork/node/cmd/commands/selftest"
 	"github.com/mysteriumnetwork/node/cmd/commands/service"
 	"github.com/mysteriumnetwork/node/cmd/commands/version"
 	"github.com/mysteriumnetwork/node/config"
@@ -37,12 +39,14 @@ var (
 		"run command 'license --warranty'",
 		"run command 'license --conditions'",
 	)
-	versionSummary = metadata.VersionAsSummary(licenseCopyright)
-	daemonCommand  = daemon.NewCommand()
-	versionCommand = version.NewCommand(versionSummary)
-	licenseCommand = license.NewCommand(licenseCopyright)
-	serviceCommand = service.NewCommand(licenseCommand.Name)
-	cliCommand     = command_cli.NewCommand()
+	versionSummary  = metadata.VersionAsSummary(licenseCopyright)
+	daemonCommand   = daemon.NewCommand()
+	versionCommand  = version.NewCommand(versionSummary)
+	licenseCommand  = license.NewCommand(licenseCopyright)
+	serviceCommand  = service.NewCommand(licenseCommand.Name)
+	cliCommand      = command_cli.NewCommand()
+	monitorCommand  = monitor.NewCommand()
+	selftestCommand = selftest.NewCommand(licenseCommand.Name)
 )
 
 func main() {
@@ -82,6 +86,8 @@ func NewCommand() (*cli.App, error) {
 		serviceCommand,
 		daemonCommand,
 		cliCommand,
+		monitorCommand,
+		selftestCommand,
 	}
 
 	return app, nil
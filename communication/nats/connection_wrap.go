@@ -71,8 +71,9 @@ func newConnection(serverURIs ...string) (*ConnectionWrap, error) {
 // ConnectionWrap defines wrapped connection to NATS server(s).
 type ConnectionWrap struct {
 	*nats_lib.Conn
-	servers []string
-	onClose func()
+	servers         []string
+	onClose         func()
+	statusPublisher StatusPublisher
 }
 
 func (c *ConnectionWrap) connectOptions() nats_lib.Options {
@@ -82,12 +83,33 @@ func (c *ConnectionWrap) connectOptions() nats_lib.Options {
 	options.ReconnectWait = 1 * time.Second
 	options.Timeout = 5 * time.Second
 	options.PingInterval = 10 * time.Second
-	options.ClosedCB = func(conn *nats_lib.Conn) { log.Warn().Msg("NATS: connection closed") }
-	options.DisconnectedCB = func(nc *nats_lib.Conn) { log.Warn().Msg("NATS: disconnected") }
-	options.ReconnectedCB = func(nc *nats_lib.Conn) { log.Warn().Msg("NATS: reconnected") }
+	options.ClosedCB = func(conn *nats_lib.Conn) {
+		log.Warn().Msg("NATS: connection closed")
+		c.publishStatus(conn, BrokerStatusClosed)
+	}
+	options.DisconnectedCB = func(nc *nats_lib.Conn) {
+		log.Warn().Msg("NATS: disconnected")
+		c.publishStatus(nc, BrokerStatusDisconnected)
+	}
+	options.ReconnectedCB = func(nc *nats_lib.Conn) {
+		log.Warn().Msgf("NATS: reconnected to %s", nc.ConnectedUrl())
+		c.publishStatus(nc, BrokerStatusReconnected)
+	}
 	return options
 }
 
+func (c *ConnectionWrap) publishStatus(conn *nats_lib.Conn, status BrokerStatus) {
+	if c.statusPublisher == nil {
+		return
+	}
+
+	var server string
+	if conn != nil && status != BrokerStatusDisconnected && status != BrokerStatusClosed {
+		server = conn.ConnectedUrl()
+	}
+	c.statusPublisher.Publish(AppTopicBrokerStatus, AppEventBrokerStatus{Status: status, Server: server})
+}
+
 // Open starts the connection: left for test compatibility.
 // Deprecated: Use nats.BrokerConnector#Connect() instead.
 func (c *ConnectionWrap) Open() (err error) {
@@ -96,6 +118,7 @@ func (c *ConnectionWrap) Open() (err error) {
 		return fmt.Errorf("failed to connect to NATS servers %v: %w", c.connectOptions().Servers, err)
 	}
 
+	c.publishStatus(c.Conn, BrokerStatusConnected)
 	return nil
 }
 
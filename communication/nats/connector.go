@@ -36,12 +36,23 @@ func NewBrokerConnector() *BrokerConnector {
 
 // Connect establishes a new connection to the broker(s).
 func (b *BrokerConnector) Connect(serverURIs ...string) (Connection, error) {
+	return b.connect(nil, serverURIs...)
+}
+
+// ConnectMonitored establishes a new connection to the broker(s) and publishes connection status changes.
+// When multiple servers are given, connection fails over between them until it is closed.
+func (b *BrokerConnector) ConnectMonitored(publisher StatusPublisher, serverURIs ...string) (Connection, error) {
+	return b.connect(publisher, serverURIs...)
+}
+
+func (b *BrokerConnector) connect(publisher StatusPublisher, serverURIs ...string) (Connection, error) {
 	log.Debug().Msg("Connecting to NATS servers: " + strings.Join(serverURIs, ","))
 
 	conn, err := newConnection(serverURIs...)
 	if err != nil {
 		return nil, err
 	}
+	conn.statusPublisher = publisher
 
 	removeFirewallRule, err := firewall.AllowURLAccess(conn.servers...)
 	if err != nil {
@@ -21,6 +21,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/nats-io/nats-server/v2/server"
 	"github.com/nats-io/nats.go"
 	"github.com/stretchr/testify/assert"
@@ -63,3 +64,27 @@ func TestBrokerConnector(t *testing.T) {
 		}
 	}, 5*time.Second, 200*time.Millisecond)
 }
+
+func TestBrokerConnector_ConnectMonitored(t *testing.T) {
+	// given
+	srv := server.New(&server.Options{Port: 44225})
+	go srv.Start()
+	assert.True(t, srv.ReadyForConnections(2*time.Second))
+	publisher := mocks.NewEventBus()
+
+	// when
+	conn, err := NewBrokerConnector().ConnectMonitored(publisher, srv.Addr().String())
+
+	// then
+	assert.NoError(t, err)
+	defer conn.Close()
+	assert.Equal(t, AppEventBrokerStatus{Status: BrokerStatusConnected, Server: "nats://" + srv.Addr().String()}, publisher.Pop())
+
+	// when
+	srv.Shutdown()
+
+	// then
+	assert.Eventually(t, func() bool {
+		return publisher.Pop() == AppEventBrokerStatus{Status: BrokerStatusDisconnected}
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nats
+
+// AppTopicBrokerStatus is the topic to which broker connection status changes are published.
+const AppTopicBrokerStatus = "broker.status"
+
+// BrokerStatus represents the state of connection to the broker.
+type BrokerStatus string
+
+const (
+	// BrokerStatusConnected means connection to one of the brokers is established.
+	BrokerStatusConnected = BrokerStatus("connected")
+	// BrokerStatusDisconnected means connection is lost and failover to the other brokers is in progress.
+	BrokerStatusDisconnected = BrokerStatus("disconnected")
+	// BrokerStatusReconnected means connection was re-established after a failure, possibly to a different broker.
+	BrokerStatusReconnected = BrokerStatus("reconnected")
+	// BrokerStatusClosed means connection was closed and will not be re-established.
+	BrokerStatusClosed = BrokerStatus("closed")
+)
+
+// AppEventBrokerStatus represents a change of broker connection status.
+type AppEventBrokerStatus struct {
+	Status BrokerStatus
+	Server string
+}
+
+// StatusPublisher publishes broker status events.
+type StatusPublisher interface {
+	Publish(topic string, data interface{})
+}
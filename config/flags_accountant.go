@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/urfave/cli/v2"
 )
@@ -35,6 +37,17 @@ var (
 		Usage: "accountant contract address used to register identity",
 		Value: metadata.DefaultNetwork.AccountantID,
 	}
+	// FlagAccountantFallbacks lists accountants used when the main one is unavailable
+	FlagAccountantFallbacks = cli.StringSliceFlag{
+		Name:  "accountant.fallbacks",
+		Usage: `Fallback accountants separated by comma, each given as "<accountant-id>@<accountant URL address>"`,
+	}
+	// FlagAccountantHealthCheckInterval determines how often accountants are checked for health
+	FlagAccountantHealthCheckInterval = cli.DurationFlag{
+		Name:  "accountant.health-check-interval",
+		Usage: "how often accountants are checked for health",
+		Value: time.Minute,
+	}
 )
 
 // RegisterFlagsAccountant function register network flags to flag list
@@ -43,6 +56,8 @@ func RegisterFlagsAccountant(flags *[]cli.Flag) {
 		*flags,
 		&FlagAccountantAddress,
 		&FlagAccountantID,
+		&FlagAccountantFallbacks,
+		&FlagAccountantHealthCheckInterval,
 	)
 }
 
@@ -50,4 +65,6 @@ func RegisterFlagsAccountant(flags *[]cli.Flag) {
 func ParseFlagsAccountant(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagAccountantAddress)
 	Current.ParseStringFlag(ctx, FlagAccountantID)
+	Current.ParseStringSliceFlag(ctx, FlagAccountantFallbacks)
+	Current.ParseDurationFlag(ctx, FlagAccountantHealthCheckInterval)
 }
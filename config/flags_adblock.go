@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagAdBlockLists blocklists of ad and tracker domains.
+	FlagAdBlockLists = cli.StringSliceFlag{
+		Name:  "adblock.lists",
+		Usage: "Addresses of ad and tracker domain blocklists, given in hosts file format or one domain per line",
+		Value: cli.NewStringSlice("https://raw.githubusercontent.com/StevenBlack/hosts/master/hosts"),
+	}
+	// FlagAdBlockUpdateInterval how often blocklists are downloaded.
+	FlagAdBlockUpdateInterval = cli.DurationFlag{
+		Name:  "adblock.update-interval",
+		Usage: "How often to download ad and tracker domain blocklists while blocking is enabled",
+		Value: 24 * time.Hour,
+	}
+	// FlagAdBlockAddress local address of blocking DNS proxy.
+	FlagAdBlockAddress = cli.StringFlag{
+		Name:  "adblock.address",
+		Usage: "Local IP the blocking DNS proxy listens on, tunnel DNS is pointed to it when blocking is enabled for connection",
+		Value: "127.0.0.1",
+	}
+)
+
+// RegisterFlagsAdBlock function register ad blocking flags to flag list
+func RegisterFlagsAdBlock(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagAdBlockLists,
+		&FlagAdBlockUpdateInterval,
+		&FlagAdBlockAddress,
+	)
+}
+
+// ParseFlagsAdBlock function fills in ad blocking options from CLI context
+func ParseFlagsAdBlock(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagAdBlockLists)
+	Current.ParseDurationFlag(ctx, FlagAdBlockUpdateInterval)
+	Current.ParseStringFlag(ctx, FlagAdBlockAddress)
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagChaosScenario sets the fault injection scenario file.
+	FlagChaosScenario = cli.StringFlag{
+		Name:   "chaos.scenario",
+		Usage:  "Path to the JSON scenario of faults injected into payments and P2P communication. Requires node built with the chaos tag",
+		Hidden: true,
+	}
+)
+
+// RegisterFlagsChaos function register fault injection flags to flag list
+func RegisterFlagsChaos(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagChaosScenario,
+	)
+}
+
+// ParseFlagsChaos function fills in fault injection options from CLI context
+func ParseFlagsChaos(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagChaosScenario)
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import "github.com/urfave/cli/v2"
+
+var (
+	// FlagCLICommand runs CLI commands non-interactively.
+	FlagCLICommand = cli.StringFlag{
+		Name:    "command",
+		Aliases: []string{"c"},
+		Usage:   `Runs the given CLI commands and exits, multiple commands can be separated by ";" e.g. "connect <consumer> <provider> wireguard; status"`,
+	}
+	// FlagCLIJSON prints CLI output as JSON.
+	FlagCLIJSON = cli.BoolFlag{
+		Name:  "json",
+		Usage: "Prints CLI output as JSON lines, one object per message",
+	}
+	// FlagCLICompletion prints completion candidates of the CLI command line.
+	FlagCLICompletion = cli.StringFlag{
+		Name:  "completion",
+		Usage: `Prints completion candidates for the given partial CLI command line, one per line e.g. "identities u"`,
+	}
+)
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagDNSFilterCategories content categories filtered from consumer DNS queries.
+	FlagDNSFilterCategories = cli.StringSliceFlag{
+		Name:  "dns-filter.categories",
+		Usage: `Content categories blocked in DNS queries of consumers, advertised as family-safe exit in proposals. Options: { "malware", "adult" }`,
+	}
+	// FlagDNSFilterResolvers custom filtering DNS resolvers.
+	FlagDNSFilterResolvers = cli.StringSliceFlag{
+		Name:  "dns-filter.resolvers",
+		Usage: `Filtering DNS resolvers used instead of the default ones of given categories, each given as "<host>[:<port>]"`,
+	}
+)
+
+// RegisterFlagsDNSFilter function register DNS filter flags to flag list
+func RegisterFlagsDNSFilter(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagDNSFilterCategories,
+		&FlagDNSFilterResolvers,
+	)
+}
+
+// ParseFlagsDNSFilter function fills in DNS filter options from CLI context
+func ParseFlagsDNSFilter(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagDNSFilterCategories)
+	Current.ParseStringSliceFlag(ctx, FlagDNSFilterResolvers)
+}
@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagFleetController fleet controller URL which provides configuration profile for the node.
+	FlagFleetController = cli.StringFlag{
+		Name:  "fleet.controller",
+		Usage: "URL of the fleet controller to enroll the node to. Node configuration and services to start are fetched from it",
+		Value: "",
+	}
+	// FlagFleetToken bootstrap token for enrolling to the fleet.
+	FlagFleetToken = cli.StringFlag{
+		Name:    "fleet.token",
+		Usage:   "Bootstrap token used to enroll the node to the fleet",
+		EnvVars: []string{"MYST_FLEET_TOKEN"},
+		Value:   "",
+	}
+	// FlagFleetTokenFile file containing bootstrap token for enrolling to the fleet.
+	FlagFleetTokenFile = cli.StringFlag{
+		Name:    "fleet.token-file",
+		Usage:   "File containing bootstrap token used to enroll the node to the fleet",
+		EnvVars: []string{"MYST_FLEET_TOKEN_FILE"},
+		Value:   "",
+	}
+)
+
+// RegisterFlagsFleet registers fleet enrollment flags.
+func RegisterFlagsFleet(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagFleetController,
+		&FlagFleetToken,
+		&FlagFleetTokenFile,
+	)
+}
+
+// ParseFlagsFleet parses fleet enrollment flags and registers values to the configuration.
+func ParseFlagsFleet(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagFleetController)
+	Current.ParseStringFlag(ctx, FlagFleetToken)
+	Current.ParseStringFlag(ctx, FlagFleetTokenFile)
+}
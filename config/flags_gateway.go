@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagGatewayEnabled enables gateway mode.
+	FlagGatewayEnabled = cli.BoolFlag{
+		Name:  "gateway.enabled",
+		Usage: "Forward traffic of LAN clients through the active session, LAN traffic is dropped while there is no session (Linux only)",
+		Value: false,
+	}
+	// FlagGatewayInterface LAN interface of gateway.
+	FlagGatewayInterface = cli.StringFlag{
+		Name:  "gateway.lan-interface",
+		Usage: "Network interface LAN clients are connected to, e.g. eth0",
+	}
+	// FlagGatewaySubnet LAN subnet of gateway.
+	FlagGatewaySubnet = cli.StringFlag{
+		Name:  "gateway.lan-subnet",
+		Usage: "LAN network forwarded through the session in CIDR notation, e.g. 192.168.8.0/24",
+	}
+)
+
+// RegisterFlagsGateway function register gateway mode flags to flag list
+func RegisterFlagsGateway(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagGatewayEnabled,
+		&FlagGatewayInterface,
+		&FlagGatewaySubnet,
+	)
+}
+
+// ParseFlagsGateway function fills in gateway mode options from CLI context
+func ParseFlagsGateway(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagGatewayEnabled)
+	Current.ParseStringFlag(ctx, FlagGatewayInterface)
+	Current.ParseStringFlag(ctx, FlagGatewaySubnet)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagConsumerIdleTimeout period after which consumer closes the connection if it stays idle.
+	FlagConsumerIdleTimeout = cli.DurationFlag{
+		Name:  "consumer.idle-timeout",
+		Usage: `Disconnect if less than consumer.idle-min-bytes are transferred during this period { "30m", "1h" }. Zero value keeps idle connections`,
+		Value: 0,
+	}
+	// FlagConsumerIdleMinBytes least amount of data transferred for consumer connection to not be considered idle.
+	FlagConsumerIdleMinBytes = cli.Uint64Flag{
+		Name:  "consumer.idle-min-bytes",
+		Usage: "Least amount of bytes the connection has to transfer during consumer.idle-timeout",
+		Value: datasize.MiB.Bytes(),
+	}
+	// FlagProviderIdleTimeout period after which provider closes the session if it stays idle.
+	FlagProviderIdleTimeout = cli.DurationFlag{
+		Name:  "provider.idle-timeout",
+		Usage: `Close sessions transferring less than provider.idle-min-bytes during this period { "30m", "1h" }. Zero value keeps idle sessions`,
+		Value: 0,
+	}
+	// FlagProviderIdleMinBytes least amount of data transferred for provider session to not be considered idle.
+	FlagProviderIdleMinBytes = cli.Uint64Flag{
+		Name:  "provider.idle-min-bytes",
+		Usage: "Least amount of bytes the session has to transfer during provider.idle-timeout",
+		Value: datasize.MiB.Bytes(),
+	}
+)
+
+// RegisterFlagsIdle function register idle session flags to flag list
+func RegisterFlagsIdle(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagConsumerIdleTimeout,
+		&FlagConsumerIdleMinBytes,
+		&FlagProviderIdleTimeout,
+		&FlagProviderIdleMinBytes,
+	)
+}
+
+// ParseFlagsIdle function fills in idle session options from CLI context
+func ParseFlagsIdle(ctx *cli.Context) {
+	Current.ParseDurationFlag(ctx, FlagConsumerIdleTimeout)
+	Current.ParseUInt64Flag(ctx, FlagConsumerIdleMinBytes)
+	Current.ParseDurationFlag(ctx, FlagProviderIdleTimeout)
+	Current.ParseUInt64Flag(ctx, FlagProviderIdleMinBytes)
+}
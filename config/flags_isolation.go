@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagIsolationMaxConnections limits simultaneous named connections.
+	FlagIsolationMaxConnections = cli.IntFlag{
+		Name:  "isolation.max-connections",
+		Usage: "Maximum number of named connections served by local proxies next to the main one, named connections are disabled if zero (Linux only)",
+		Value: 4,
+	}
+	// FlagIsolationProxyHost local address of named connection proxies.
+	FlagIsolationProxyHost = cli.StringFlag{
+		Name:  "isolation.proxy-host",
+		Usage: "Loopback address SOCKS5/HTTP proxies of named connections listen on",
+		Value: "127.0.0.1",
+	}
+	// FlagIsolationProxyPort proxy port of the first named connection.
+	FlagIsolationProxyPort = cli.IntFlag{
+		Name:  "isolation.proxy-port",
+		Usage: "Proxy port of the first named connection, following connections use the next ports",
+		Value: 41080,
+	}
+	// FlagIsolationRoutingTable routing table of the first named connection.
+	FlagIsolationRoutingTable = cli.IntFlag{
+		Name:  "isolation.routing-table",
+		Usage: "Routing table and firewall mark of the first named connection, following connections use the next ones",
+		Value: 7300,
+	}
+)
+
+// RegisterFlagsIsolation function register connection isolation flags to flag list
+func RegisterFlagsIsolation(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagIsolationMaxConnections,
+		&FlagIsolationProxyHost,
+		&FlagIsolationProxyPort,
+		&FlagIsolationRoutingTable,
+	)
+}
+
+// ParseFlagsIsolation function fills in connection isolation options from CLI context
+func ParseFlagsIsolation(ctx *cli.Context) {
+	Current.ParseIntFlag(ctx, FlagIsolationMaxConnections)
+	Current.ParseStringFlag(ctx, FlagIsolationProxyHost)
+	Current.ParseIntFlag(ctx, FlagIsolationProxyPort)
+	Current.ParseIntFlag(ctx, FlagIsolationRoutingTable)
+}
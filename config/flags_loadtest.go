@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagLoadTestSessions sets the number of concurrent synthetic consumer sessions.
+	FlagLoadTestSessions = cli.IntFlag{
+		Name:   "loadtest.sessions",
+		Usage:  "Number of concurrent consumer sessions started against the noop service of the load test provider. Zero disables load testing.",
+		Hidden: true,
+	}
+	// FlagLoadTestConsumer sets the consumer identity used by synthetic sessions.
+	FlagLoadTestConsumer = cli.StringFlag{
+		Name:   "loadtest.consumer",
+		Usage:  "Unlocked consumer identity used by load test sessions",
+		Hidden: true,
+	}
+	// FlagLoadTestProvider sets the provider which load test sessions connect to.
+	FlagLoadTestProvider = cli.StringFlag{
+		Name:   "loadtest.provider",
+		Usage:  "Provider identity running the noop service which load test sessions connect to",
+		Hidden: true,
+	}
+	// FlagLoadTestDiscoveryTimeout sets how long to wait for the provider's proposal.
+	FlagLoadTestDiscoveryTimeout = cli.DurationFlag{
+		Name:   "loadtest.discovery-timeout",
+		Usage:  "How long to wait for the noop proposal of the load test provider to be discovered",
+		Value:  time.Minute,
+		Hidden: true,
+	}
+)
+
+// RegisterFlagsLoadTest function register load test flags to flag list
+func RegisterFlagsLoadTest(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagLoadTestSessions,
+		&FlagLoadTestConsumer,
+		&FlagLoadTestProvider,
+		&FlagLoadTestDiscoveryTimeout,
+	)
+}
+
+// ParseFlagsLoadTest function fills in load test options from CLI context
+func ParseFlagsLoadTest(ctx *cli.Context) {
+	Current.ParseIntFlag(ctx, FlagLoadTestSessions)
+	Current.ParseStringFlag(ctx, FlagLoadTestConsumer)
+	Current.ParseStringFlag(ctx, FlagLoadTestProvider)
+	Current.ParseDurationFlag(ctx, FlagLoadTestDiscoveryTimeout)
+}
@@ -19,6 +19,7 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/urfave/cli/v2"
 )
@@ -45,6 +46,55 @@ var (
 		),
 		Value: "https://testnet-location.mysterium.network/api/v1/location",
 	}
+	// FlagLocationDBUpdateURL URL to download fresh location database from.
+	FlagLocationDBUpdateURL = cli.StringFlag{
+		Name: "location.mmdb-update-url",
+		Usage: fmt.Sprintf(
+			"URL of MaxMind-format database (optionally gzipped) to keep '--%s' file updated from, when '--%s=mmdb'",
+			FlagLocationAddress.Name,
+			FlagLocationType.Name,
+		),
+		Value: "",
+	}
+	// FlagLocationDBUpdateInterval how often location database is updated.
+	FlagLocationDBUpdateInterval = cli.DurationFlag{
+		Name:  "location.mmdb-update-interval",
+		Usage: "How often to check for location database updates",
+		Value: 7 * 24 * time.Hour,
+	}
+	// FlagLocationWatchInterval how often public IP and location of the node are checked for changes.
+	FlagLocationWatchInterval = cli.DurationFlag{
+		Name:  "location.watch-interval",
+		Usage: "How often to check whether public IP or location of the node changed, to update service proposals. Zero disables the checks",
+		Value: 5 * time.Minute,
+	}
+	// FlagLocationASNAddress path to the ASN database used to resolve consumer networks.
+	FlagLocationASNAddress = cli.StringFlag{
+		Name:  "location.asn-address",
+		Usage: "Path to MaxMind-format ASN database, required to apply ASN restrictions of consumer policy",
+		Value: "",
+	}
+	// FlagLocationVerify enables location verification of established connections.
+	FlagLocationVerify = cli.BoolFlag{
+		Name:  "location.verify",
+		Usage: "Verify that connection exits in the country advertised by the provider",
+		Value: true,
+	}
+	// FlagLocationVerifyStrict disconnects when connection location does not match.
+	FlagLocationVerifyStrict = cli.BoolFlag{
+		Name:  "location.verify-strict",
+		Usage: "Disconnect when connection exits in a different country than advertised by the provider",
+		Value: false,
+	}
+	// FlagLocationVerifyIPDetectorURL URL of IP detection service used to verify connection location.
+	FlagLocationVerifyIPDetectorURL = cli.StringFlag{
+		Name: "location.verify-ip-detector",
+		Usage: fmt.Sprintf(
+			"Address (URL form) of IP detection service used to verify connection location, it should be independent from '--%s'",
+			FlagIPDetectorURL.Name,
+		),
+		Value: "https://api.ipify.org/?format=json",
+	}
 	// FlagLocationCountry service location country.
 	FlagLocationCountry = cli.StringFlag{
 		Name:  "location.country",
@@ -68,6 +118,13 @@ func RegisterFlagsLocation(flags *[]cli.Flag) {
 		&FlagIPDetectorURL,
 		&FlagLocationType,
 		&FlagLocationAddress,
+		&FlagLocationDBUpdateURL,
+		&FlagLocationDBUpdateInterval,
+		&FlagLocationWatchInterval,
+		&FlagLocationASNAddress,
+		&FlagLocationVerify,
+		&FlagLocationVerifyStrict,
+		&FlagLocationVerifyIPDetectorURL,
 		&FlagLocationCountry,
 		&FlagLocationCity,
 		&FlagLocationNodeType,
@@ -79,6 +136,13 @@ func ParseFlagsLocation(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagIPDetectorURL)
 	Current.ParseStringFlag(ctx, FlagLocationType)
 	Current.ParseStringFlag(ctx, FlagLocationAddress)
+	Current.ParseStringFlag(ctx, FlagLocationDBUpdateURL)
+	Current.ParseDurationFlag(ctx, FlagLocationDBUpdateInterval)
+	Current.ParseDurationFlag(ctx, FlagLocationWatchInterval)
+	Current.ParseStringFlag(ctx, FlagLocationASNAddress)
+	Current.ParseBoolFlag(ctx, FlagLocationVerify)
+	Current.ParseBoolFlag(ctx, FlagLocationVerifyStrict)
+	Current.ParseStringFlag(ctx, FlagLocationVerifyIPDetectorURL)
 	Current.ParseStringFlag(ctx, FlagLocationCountry)
 	Current.ParseStringFlag(ctx, FlagLocationCity)
 	Current.ParseStringFlag(ctx, FlagLocationNodeType)
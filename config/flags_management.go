@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagManagementOperator identity allowed to manage the node remotely.
+	FlagManagementOperator = cli.StringFlag{
+		Name:  "management.operator",
+		Usage: "Identity of the operator allowed to send signed management commands (restart services, update config, fetch diagnostics) over the broker. Remote management is disabled if empty",
+		Value: "",
+	}
+	// FlagManagementAuditLog file where received management commands are recorded.
+	FlagManagementAuditLog = cli.StringFlag{
+		Name:  "management.audit-log",
+		Usage: "File to record received management commands in, defaults to management-audit.log in the data directory",
+		Value: "",
+	}
+)
+
+// RegisterFlagsManagement function register remote management flags to flag list
+func RegisterFlagsManagement(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagManagementOperator,
+		&FlagManagementAuditLog,
+	)
+}
+
+// ParseFlagsManagement function fills in remote management options from CLI context
+func ParseFlagsManagement(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagManagementOperator)
+	Current.ParseStringFlag(ctx, FlagManagementAuditLog)
+}
@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/urfave/cli/v2"
 )
@@ -33,6 +35,11 @@ var (
 		Name:  "localnet",
 		Usage: "Defines network configuration which expects locally deployed broker and discovery services",
 	}
+	// FlagChainID selects network definition by chain ID.
+	FlagChainID = cli.IntFlag{
+		Name:  "chain-id",
+		Usage: "Chain ID of the network to use, contract addresses and service URLs default to the ones of this network. Chosen by --testnet or --localnet if not set",
+	}
 	// FlagAPIAddress Mysterium API URL
 	FlagAPIAddress = cli.StringFlag{
 		Name:  "api.address",
@@ -42,15 +49,33 @@ var (
 	// FlagBrokerAddress message broker URI.
 	FlagBrokerAddress = cli.StringFlag{
 		Name:  "broker-address",
-		Usage: "URI of message broker",
+		Usage: "URI of message broker. Multiple brokers for failover can be separated by comma",
 		Value: metadata.DefaultNetwork.BrokerAddress,
 	}
 	// FlagEtherRPC URL or IPC socket to connect to Ethereum node.
 	FlagEtherRPC = cli.StringFlag{
 		Name:  "ether.client.rpc",
-		Usage: "URL or IPC socket to connect to ethereum node, anything what ethereum client accepts - works",
+		Usage: "URL or IPC socket to connect to ethereum node, anything what ethereum client accepts - works. Multiple endpoints for failover can be separated by comma",
 		Value: metadata.DefaultNetwork.EtherClientRPC,
 	}
+	// FlagEtherRPCCheckInterval ethereum RPC endpoints health check interval.
+	FlagEtherRPCCheckInterval = cli.DurationFlag{
+		Name:  "ether.client.check-interval",
+		Usage: "How often latency of ethereum RPC endpoints is measured to select the fastest healthy one",
+		Value: 30 * time.Second,
+	}
+	// FlagEtherRPCCacheTTL ethereum RPC hot reads cache TTL.
+	FlagEtherRPCCacheTTL = cli.DurationFlag{
+		Name:  "ether.client.cache-ttl",
+		Usage: "How long registration status and balances read from blockchain are cached",
+		Value: 15 * time.Second,
+	}
+	// FlagEtherClientLightMode reads blockchain state through transactor instead of ethereum RPC.
+	FlagEtherClientLightMode = cli.BoolFlag{
+		Name:  "ether.client.light",
+		Usage: "Reads blockchain state through transactor instead of connecting to ethereum RPC directly, for environments where outbound RPC is blocked",
+		Value: false,
+	}
 	// FlagNATPunching enables NAT hole punching.
 	FlagNATPunching = cli.BoolFlag{
 		Name:  "experiment-natpunching",
@@ -63,6 +88,12 @@ var (
 		Usage: "Enables NAT port mapping",
 		Value: true,
 	}
+	// FlagNATType NAT type of provider announced in proposals.
+	FlagNATType = cli.StringFlag{
+		Name:  "nat.type",
+		Usage: `NAT type of provider announced in proposals { "none", "fullcone", "rcone", "prcone", "symmetric" }. Detected if empty, "none" for a public IP of the local interface`,
+		Value: "",
+	}
 	// FlagIncomingFirewall enables incoming traffic filtering.
 	FlagIncomingFirewall = cli.BoolFlag{
 		Name:  "incoming-firewall",
@@ -75,6 +106,27 @@ var (
 		Usage: "Enables outgoing traffic filtering",
 		Value: false,
 	}
+	// FlagKeepAliveInterval p2p keepalive ping interval.
+	FlagKeepAliveInterval = cli.DurationFlag{
+		Name:  "keepalive.interval",
+		Usage: `P2P keepalive ping interval { "10s", "1m" }. Zero value uses the default`,
+	}
+	// FlagKeepAliveTimeout p2p dead peer timeout.
+	FlagKeepAliveTimeout = cli.DurationFlag{
+		Name:  "keepalive.timeout",
+		Usage: `Duration without any traffic from the peer after which the session is considered lost { "30s", "1m" }. Zero value uses the default`,
+	}
+	// FlagNetworkWatchInterval how often local network interfaces are checked for changes.
+	FlagNetworkWatchInterval = cli.DurationFlag{
+		Name:  "network.watch-interval",
+		Usage: "How often to check whether local network changed, e.g. from WiFi to LTE, to move the established connection to it. Zero disables the checks",
+		Value: 2 * time.Second,
+	}
+	// FlagHandshakeTimeout total session handshake timeout.
+	FlagHandshakeTimeout = cli.DurationFlag{
+		Name:  "handshake.timeout",
+		Usage: `Total time allowed for session config exchange, first invoice payment and tunnel handshake, split between them { "1m", "90s" }. Zero value uses the default`,
+	}
 )
 
 // RegisterFlagsNetwork function register network flags to flag list
@@ -83,13 +135,22 @@ func RegisterFlagsNetwork(flags *[]cli.Flag) {
 		*flags,
 		&FlagTestnet,
 		&FlagLocalnet,
+		&FlagChainID,
 		&FlagPortMapping,
 		&FlagNATPunching,
+		&FlagNATType,
 		&FlagAPIAddress,
 		&FlagBrokerAddress,
 		&FlagEtherRPC,
+		&FlagEtherRPCCheckInterval,
+		&FlagEtherRPCCacheTTL,
+		&FlagEtherClientLightMode,
 		&FlagIncomingFirewall,
 		&FlagOutgoingFirewall,
+		&FlagKeepAliveInterval,
+		&FlagKeepAliveTimeout,
+		&FlagNetworkWatchInterval,
+		&FlagHandshakeTimeout,
 	)
 }
 
@@ -97,11 +158,59 @@ func RegisterFlagsNetwork(flags *[]cli.Flag) {
 func ParseFlagsNetwork(ctx *cli.Context) {
 	Current.ParseBoolFlag(ctx, FlagTestnet)
 	Current.ParseBoolFlag(ctx, FlagLocalnet)
+	Current.ParseIntFlag(ctx, FlagChainID)
 	Current.ParseStringFlag(ctx, FlagAPIAddress)
 	Current.ParseStringFlag(ctx, FlagBrokerAddress)
 	Current.ParseStringFlag(ctx, FlagEtherRPC)
+	Current.ParseDurationFlag(ctx, FlagEtherRPCCheckInterval)
+	Current.ParseDurationFlag(ctx, FlagEtherRPCCacheTTL)
+	Current.ParseBoolFlag(ctx, FlagEtherClientLightMode)
 	Current.ParseBoolFlag(ctx, FlagPortMapping)
 	Current.ParseBoolFlag(ctx, FlagNATPunching)
+	Current.ParseStringFlag(ctx, FlagNATType)
 	Current.ParseBoolFlag(ctx, FlagIncomingFirewall)
 	Current.ParseBoolFlag(ctx, FlagOutgoingFirewall)
+	Current.ParseDurationFlag(ctx, FlagKeepAliveInterval)
+	Current.ParseDurationFlag(ctx, FlagKeepAliveTimeout)
+	Current.ParseDurationFlag(ctx, FlagNetworkWatchInterval)
+	Current.ParseDurationFlag(ctx, FlagHandshakeTimeout)
+}
+
+// ChainID returns chain ID of the selected network.
+func ChainID() int64 {
+	switch {
+	case GetInt(FlagChainID) != 0:
+		return int64(GetInt(FlagChainID))
+	case GetBool(FlagTestnet):
+		return metadata.TestnetDefinition.ChainID
+	case GetBool(FlagLocalnet):
+		return metadata.LocalnetDefinition.ChainID
+	default:
+		return metadata.DefaultNetwork.ChainID
+	}
+}
+
+// ApplyNetworkDefaults sets defaults of network dependent flags to the values of the selected network,
+// values given in user configuration or CLI take precedence.
+func ApplyNetworkDefaults() error {
+	network, err := metadata.NetworkByChainID(ChainID())
+	if err != nil {
+		return err
+	}
+
+	for flag, value := range map[*cli.StringFlag]string{
+		&FlagTransactorAddress:               network.TransactorAddress,
+		&FlagTransactorRegistryAddress:       network.RegistryAddress,
+		&FlagTransactorChannelImplementation: network.ChannelImplAddress,
+		&FlagAccountantAddress:               network.AccountantAddress,
+		&FlagAccountantID:                    network.AccountantID,
+		&FlagPaymentsMystSCAddress:           network.MystSCAddress,
+		&FlagMMNAddress:                      network.MMNAddress,
+		&FlagFaucetAddress:                   network.FaucetAddress,
+	} {
+		if value != "" {
+			Current.SetDefault(flag.Name, value)
+		}
+	}
+	return nil
 }
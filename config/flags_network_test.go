@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChainID(t *testing.T) {
+	defer func(cfg *Config) { Current = cfg }(Current)
+
+	Current = NewConfig()
+	assert.Equal(t, metadata.DefaultNetwork.ChainID, ChainID())
+
+	Current.SetCLI(FlagLocalnet.Name, true)
+	assert.Equal(t, metadata.LocalnetDefinition.ChainID, ChainID())
+
+	Current.SetCLI(FlagChainID.Name, 42)
+	assert.Equal(t, int64(42), ChainID())
+}
+
+func TestApplyNetworkDefaults(t *testing.T) {
+	defer func(cfg *Config) { Current = cfg }(Current)
+
+	Current = NewConfig()
+	Current.SetCLI(FlagChainID.Name, int(metadata.TestnetDefinition.ChainID))
+	Current.SetUser(FlagAccountantID.Name, "0xuser")
+	Current.SetCLI(FlagTransactorAddress.Name, "http://transactor.cli")
+
+	assert.NoError(t, ApplyNetworkDefaults())
+	assert.Equal(t, metadata.TestnetDefinition.MystSCAddress, GetString(FlagPaymentsMystSCAddress))
+	assert.Equal(t, metadata.TestnetDefinition.RegistryAddress, GetString(FlagTransactorRegistryAddress))
+	assert.Equal(t, "0xuser", GetString(FlagAccountantID))
+	assert.Equal(t, "http://transactor.cli", GetString(FlagTransactorAddress))
+}
+
+func TestApplyNetworkDefaults_UnknownChain(t *testing.T) {
+	defer func(cfg *Config) { Current = cfg }(Current)
+
+	Current = NewConfig()
+	Current.SetCLI(FlagChainID.Name, 999999)
+
+	assert.EqualError(t, ApplyNetworkDefaults(), "unknown network with chain ID 999999")
+}
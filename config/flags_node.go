@@ -74,6 +74,12 @@ var (
 		Usage: "List of comma separated (no spaces) subnets to be protected from access via VPN",
 		Value: "10.0.0.0/8,172.16.0.0/12,192.168.0.0/16,127.0.0.0/8",
 	}
+	// FlagFirewallBlockedPorts blocks destination ports commonly abused via VPN
+	FlagFirewallBlockedPorts = cli.StringFlag{
+		Name:  "firewall.blocked-ports",
+		Usage: "List of comma separated (no spaces) destination ports consumers are not allowed to reach via VPN",
+		Value: "25",
+	}
 	// FlagShaperEnabled enables bandwidth limitation.
 	FlagShaperEnabled = cli.BoolFlag{
 		Name:  "shaper.enabled",
@@ -113,6 +119,12 @@ var (
 		Usage: "URL of my.mysterium.network API",
 		Value: metadata.DefaultNetwork.MMNAddress,
 	}
+	// FlagFaucetAddress URL of test network faucet API.
+	FlagFaucetAddress = cli.StringFlag{
+		Name:  "faucet.address",
+		Usage: "URL of test network faucet API granting test tokens to new identities, faucet is disabled if empty",
+		Value: metadata.DefaultNetwork.FaucetAddress,
+	}
 	// FlagOpenvpnBinary openvpn binary to use for OpenVPN connections.
 	FlagOpenvpnBinary = cli.StringFlag{
 		Name:  "openvpn.binary",
@@ -134,6 +146,12 @@ var (
 		),
 		Value: "https://quality.mysterium.network/api/v1",
 	}
+	// FlagConsumerMinQuality least quality score of proposals listed and connected to by consumer.
+	FlagConsumerMinQuality = cli.Float64Flag{
+		Name:  "consumer.min-quality",
+		Usage: "Hide proposals with Quality Oracle score below the given value in range [0, 1]. Zero value shows all proposals",
+		Value: 0,
+	}
 	// FlagTequilapiAddress IP address of interface to listen for incoming connections.
 	FlagTequilapiAddress = cli.StringFlag{
 		Name:  "tequilapi.address",
@@ -146,6 +164,12 @@ var (
 		Usage: "Port for listening incoming api requests",
 		Value: 4050,
 	}
+	// FlagTequilapiTokensPrecision number of decimal places of human readable MYST amounts returned by API.
+	FlagTequilapiTokensPrecision = cli.IntFlag{
+		Name:  "tequilapi.tokens-precision",
+		Usage: "Number of decimal places of human readable MYST amounts returned by Tequilapi, up to 8",
+		Value: 6,
+	}
 	// FlagPProfEnable enables pprof via TequilAPI.
 	FlagPProfEnable = cli.BoolFlag{
 		Name:  "pprof.enable",
@@ -176,6 +200,12 @@ var (
 		Usage: "Run as a regular user. Delegate elevated commands to the supervisor.",
 		Value: false,
 	}
+	// FlagWireguardBackend selects implementation of WireGuard tunnel device.
+	FlagWireguardBackend = cli.StringFlag{
+		Name:  "wireguard.backend",
+		Usage: `WireGuard tunnel backend, the next supported one is used if it is not available. Options: { "auto", "kernel", "userspace", "wintun" }`,
+		Value: "auto",
+	}
 	// FlagVendorID identifies 3rd party vendor (distributor) of Mysterium node.
 	FlagVendorID = cli.StringFlag{
 		Name: "vendor.id",
@@ -189,12 +219,67 @@ var (
 		Value: "0:0",
 	}
 
+	// FlagServicePortRanges sets port ranges per service type.
+	FlagServicePortRanges = cli.StringFlag{
+		Name:  "service.port-ranges",
+		Usage: "Comma separated port ranges per service type (e.g. wireguard=52820:53075,openvpn=1194:1294)",
+	}
+	// FlagServiceMaxSessionStarts limits how many sessions provider starts at once.
+	FlagServiceMaxSessionStarts = cli.IntFlag{
+		Name:  "service.max-session-starts",
+		Usage: "Maximum number of session handshakes handled at once, others wait in queue. Zero value means no limit",
+		Value: 10,
+	}
+	// FlagServiceSessionStartQueueTimeout sets how long session start waits in queue.
+	FlagServiceSessionStartQueueTimeout = cli.DurationFlag{
+		Name:  "service.session-start-queue-timeout",
+		Usage: `Time session start waits for other session handshakes to finish before it is rejected { "5s", "30s" }`,
+		Value: 10 * time.Second,
+	}
+
+	// FlagSessionHistoryMaxAge sets how long session history is kept.
+	FlagSessionHistoryMaxAge = cli.DurationFlag{
+		Name:  "session-history.max-age",
+		Usage: `Period after which session history is deleted { "720h", "8760h" }. Zero value keeps history forever`,
+		Value: 365 * 24 * time.Hour,
+	}
+	// FlagSessionHistoryMaxRows sets how many sessions are kept in session history.
+	FlagSessionHistoryMaxRows = cli.IntFlag{
+		Name:  "session-history.max-rows",
+		Usage: "Maximum number of sessions kept in session history, older ones are deleted. Zero value means no limit",
+		Value: 100000,
+	}
+	// FlagSessionHistoryCompactAge sets after how long sessions are folded into daily aggregates.
+	FlagSessionHistoryCompactAge = cli.DurationFlag{
+		Name:  "session-history.compact-age",
+		Usage: `Period after which completed sessions are kept only as daily aggregates { "720h" }. Zero value keeps individual sessions`,
+		Value: 0,
+	}
+
 	//FlagConsumer sets to run as consumer only which allows to skip bootstrap for some of the dependencies.
 	FlagConsumer = cli.BoolFlag{
 		Name:  "consumer",
 		Usage: "Run in consumer mode only.",
 		Value: false,
 	}
+	// FlagLowResource reduces node resource usage for constrained devices.
+	FlagLowResource = cli.BoolFlag{
+		Name:  "low-resource",
+		Usage: "Reduce memory and CPU usage on resource-constrained devices, e.g. Raspberry Pi",
+		Value: false,
+	}
+	// FlagEventRecordFile records event bus traffic for debugging.
+	FlagEventRecordFile = cli.StringFlag{
+		Name:  "event-record.file",
+		Usage: "Record all internal events to the given file for debugging, sensitive fields are redacted",
+		Value: "",
+	}
+	// FlagEventRecordLimit limits the size of event recording.
+	FlagEventRecordLimit = cli.IntFlag{
+		Name:  "event-record.limit",
+		Usage: "Number of events kept in the event recording file before it is rotated",
+		Value: 10000,
+	}
 )
 
 // RegisterFlagsNode function register node flags to flag list
@@ -209,6 +294,30 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 	RegisterFlagsAccountant(flags)
 	RegisterFlagsPayments(flags)
 	RegisterFlagsPolicy(flags)
+	RegisterFlagsLoadTest(flags)
+	RegisterFlagsStorage(flags)
+	RegisterFlagsObfuscation(flags)
+	RegisterFlagsQoS(flags)
+	RegisterFlagsDNSFilter(flags)
+	RegisterFlagsProviderDescription(flags)
+	RegisterFlagsAdBlock(flags)
+	RegisterFlagsGateway(flags)
+	RegisterFlagsProxy(flags)
+	RegisterFlagsIsolation(flags)
+	RegisterFlagsSchedule(flags)
+	RegisterFlagsOnDemand(flags)
+	RegisterFlagsManagement(flags)
+	RegisterFlagsUpdate(flags)
+	RegisterFlagsShutdown(flags)
+	RegisterFlagsResources(flags)
+	RegisterFlagsState(flags)
+	RegisterFlagsIdle(flags)
+	RegisterFlagsReconciliation(flags)
+	RegisterFlagsSpeedTest(flags)
+	RegisterFlagsTelemetry(flags)
+	RegisterFlagsTequilapiRateLimit(flags)
+	RegisterFlagsTequilapiCors(flags)
+	RegisterFlagsChaos(flags)
 
 	*flags = append(*flags,
 		&FlagBindAddress,
@@ -218,24 +327,38 @@ func RegisterFlagsNode(flags *[]cli.Flag) error {
 		&FlagFeedbackURL,
 		&FlagFirewallKillSwitch,
 		&FlagFirewallProtectedNetworks,
+		&FlagFirewallBlockedPorts,
 		&FlagShaperEnabled,
 		&FlagKeystoreLightweight,
 		&FlagLogHTTP,
 		&FlagLogLevel,
 		&FlagMMNAddress,
+		&FlagFaucetAddress,
 		&FlagOpenvpnBinary,
 		&FlagQualityType,
 		&FlagQualityAddress,
+		&FlagConsumerMinQuality,
 		&FlagTequilapiAddress,
 		&FlagTequilapiPort,
+		&FlagTequilapiTokensPrecision,
 		&FlagPProfEnable,
 		&FlagUIEnable,
 		&FlagUIAddress,
 		&FlagUIPort,
 		&FlagUserMode,
+		&FlagWireguardBackend,
 		&FlagVendorID,
 		&FlagP2PListenPorts,
+		&FlagServicePortRanges,
+		&FlagServiceMaxSessionStarts,
+		&FlagServiceSessionStartQueueTimeout,
+		&FlagSessionHistoryMaxAge,
+		&FlagSessionHistoryMaxRows,
+		&FlagSessionHistoryCompactAge,
 		&FlagConsumer,
+		&FlagLowResource,
+		&FlagEventRecordFile,
+		&FlagEventRecordLimit,
 	)
 
 	return nil
@@ -251,6 +374,30 @@ func ParseFlagsNode(ctx *cli.Context) {
 	ParseFlagsAccountant(ctx)
 	ParseFlagsPayments(ctx)
 	ParseFlagsPolicy(ctx)
+	ParseFlagsLoadTest(ctx)
+	ParseFlagsStorage(ctx)
+	ParseFlagsObfuscation(ctx)
+	ParseFlagsQoS(ctx)
+	ParseFlagsDNSFilter(ctx)
+	ParseFlagsProviderDescription(ctx)
+	ParseFlagsAdBlock(ctx)
+	ParseFlagsGateway(ctx)
+	ParseFlagsProxy(ctx)
+	ParseFlagsIsolation(ctx)
+	ParseFlagsSchedule(ctx)
+	ParseFlagsOnDemand(ctx)
+	ParseFlagsManagement(ctx)
+	ParseFlagsUpdate(ctx)
+	ParseFlagsShutdown(ctx)
+	ParseFlagsResources(ctx)
+	ParseFlagsState(ctx)
+	ParseFlagsIdle(ctx)
+	ParseFlagsReconciliation(ctx)
+	ParseFlagsSpeedTest(ctx)
+	ParseFlagsTelemetry(ctx)
+	ParseFlagsTequilapiRateLimit(ctx)
+	ParseFlagsTequilapiCors(ctx)
+	ParseFlagsChaos(ctx)
 
 	Current.ParseStringFlag(ctx, FlagBindAddress)
 	Current.ParseStringSliceFlag(ctx, FlagDiscoveryType)
@@ -259,24 +406,42 @@ func ParseFlagsNode(ctx *cli.Context) {
 	Current.ParseStringFlag(ctx, FlagFeedbackURL)
 	Current.ParseBoolFlag(ctx, FlagFirewallKillSwitch)
 	Current.ParseStringFlag(ctx, FlagFirewallProtectedNetworks)
+	Current.ParseStringFlag(ctx, FlagFirewallBlockedPorts)
 	Current.ParseBoolFlag(ctx, FlagShaperEnabled)
 	Current.ParseBoolFlag(ctx, FlagKeystoreLightweight)
 	Current.ParseBoolFlag(ctx, FlagLogHTTP)
 	Current.ParseStringFlag(ctx, FlagLogLevel)
 	Current.ParseStringFlag(ctx, FlagMMNAddress)
+	Current.ParseStringFlag(ctx, FlagFaucetAddress)
 	Current.ParseStringFlag(ctx, FlagOpenvpnBinary)
 	Current.ParseStringFlag(ctx, FlagQualityAddress)
 	Current.ParseStringFlag(ctx, FlagQualityType)
+	Current.ParseFloat64Flag(ctx, FlagConsumerMinQuality)
 	Current.ParseStringFlag(ctx, FlagTequilapiAddress)
 	Current.ParseIntFlag(ctx, FlagTequilapiPort)
+	Current.ParseIntFlag(ctx, FlagTequilapiTokensPrecision)
 	Current.ParseBoolFlag(ctx, FlagPProfEnable)
 	Current.ParseBoolFlag(ctx, FlagUIEnable)
 	Current.ParseStringFlag(ctx, FlagUIAddress)
 	Current.ParseIntFlag(ctx, FlagUIPort)
 	Current.ParseBoolFlag(ctx, FlagUserMode)
+	Current.ParseStringFlag(ctx, FlagWireguardBackend)
 	Current.ParseStringFlag(ctx, FlagVendorID)
 	Current.ParseStringFlag(ctx, FlagP2PListenPorts)
+	Current.ParseStringFlag(ctx, FlagServicePortRanges)
+	Current.ParseIntFlag(ctx, FlagServiceMaxSessionStarts)
+	Current.ParseDurationFlag(ctx, FlagServiceSessionStartQueueTimeout)
+	Current.ParseDurationFlag(ctx, FlagSessionHistoryMaxAge)
+	Current.ParseIntFlag(ctx, FlagSessionHistoryMaxRows)
+	Current.ParseDurationFlag(ctx, FlagSessionHistoryCompactAge)
 	Current.ParseBoolFlag(ctx, FlagConsumer)
+	Current.ParseBoolFlag(ctx, FlagLowResource)
+	Current.ParseStringFlag(ctx, FlagEventRecordFile)
+	Current.ParseIntFlag(ctx, FlagEventRecordLimit)
+
+	if err := ApplyNetworkDefaults(); err != nil {
+		log.Error().Err(err).Msg("Could not apply network defaults")
+	}
 
 	ValidateAddressFlags(FlagTequilapiAddress)
 }
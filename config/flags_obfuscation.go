@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagObfuscationOffer obfuscators offered by provided services.
+	FlagObfuscationOffer = cli.StringSliceFlag{
+		Name:  "obfuscation.offer",
+		Usage: `Obfuscators of service traffic offered to consumers in DPI-heavy networks. Options: { "scramble" }`,
+	}
+	// FlagObfuscationRequest obfuscator requested by consumer.
+	FlagObfuscationRequest = cli.StringFlag{
+		Name:  "obfuscation.request",
+		Usage: `Obfuscator of service traffic requested from providers offering it. Options: { "", "scramble" }`,
+		Value: "",
+	}
+)
+
+// RegisterFlagsObfuscation function register obfuscation flags to flag list
+func RegisterFlagsObfuscation(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagObfuscationOffer,
+		&FlagObfuscationRequest,
+	)
+}
+
+// ParseFlagsObfuscation function fills in obfuscation options from CLI context
+func ParseFlagsObfuscation(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagObfuscationOffer)
+	Current.ParseStringFlag(ctx, FlagObfuscationRequest)
+}
@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagOnDemandEnabled enables on-demand connections.
+	FlagOnDemandEnabled = cli.BoolFlag{
+		Name:  "ondemand.enabled",
+		Usage: "Connect automatically once traffic to the watched destinations or from the watched interface appears",
+		Value: false,
+	}
+	// FlagOnDemandConsumerID identity of on-demand connections.
+	FlagOnDemandConsumerID = cli.StringFlag{
+		Name:  "ondemand.consumer-id",
+		Usage: "Identity on-demand connections are made with",
+	}
+	// FlagOnDemandCountry country of on-demand connections.
+	FlagOnDemandCountry = cli.StringFlag{
+		Name:  "ondemand.country",
+		Usage: "Country code of providers on-demand connections are made to, e.g. DE",
+	}
+	// FlagOnDemandServiceType service type of on-demand connections.
+	FlagOnDemandServiceType = cli.StringFlag{
+		Name:  "ondemand.service-type",
+		Usage: "Service type on-demand connections are made to, any if empty",
+	}
+	// FlagOnDemandDestinations destinations triggering on-demand connections.
+	FlagOnDemandDestinations = cli.StringSliceFlag{
+		Name:  "ondemand.destinations",
+		Usage: "IPv4 addresses or networks in CIDR notation, outbound traffic to them triggers the connection",
+	}
+	// FlagOnDemandInterface interface triggering on-demand connections.
+	FlagOnDemandInterface = cli.StringFlag{
+		Name:  "ondemand.interface",
+		Usage: "Virtual network interface, any traffic forwarded from it triggers the connection, e.g. virbr0",
+	}
+	// FlagOnDemandIdleTimeout idle period after which on-demand connection is disconnected.
+	FlagOnDemandIdleTimeout = cli.DurationFlag{
+		Name:  "ondemand.idle-timeout",
+		Usage: "Disconnect on-demand connection once the watched traffic stays idle this long, 0 to keep it",
+		Value: 10 * time.Minute,
+	}
+	// FlagOnDemandCheckInterval how often the watched traffic is checked.
+	FlagOnDemandCheckInterval = cli.DurationFlag{
+		Name:  "ondemand.check-interval",
+		Usage: "How often the watched traffic is checked",
+		Value: 2 * time.Second,
+	}
+)
+
+// RegisterFlagsOnDemand function register on-demand connection flags to flag list
+func RegisterFlagsOnDemand(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagOnDemandEnabled,
+		&FlagOnDemandConsumerID,
+		&FlagOnDemandCountry,
+		&FlagOnDemandServiceType,
+		&FlagOnDemandDestinations,
+		&FlagOnDemandInterface,
+		&FlagOnDemandIdleTimeout,
+		&FlagOnDemandCheckInterval,
+	)
+}
+
+// ParseFlagsOnDemand function fills in on-demand connection options from CLI context
+func ParseFlagsOnDemand(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagOnDemandEnabled)
+	Current.ParseStringFlag(ctx, FlagOnDemandConsumerID)
+	Current.ParseStringFlag(ctx, FlagOnDemandCountry)
+	Current.ParseStringFlag(ctx, FlagOnDemandServiceType)
+	Current.ParseStringSliceFlag(ctx, FlagOnDemandDestinations)
+	Current.ParseStringFlag(ctx, FlagOnDemandInterface)
+	Current.ParseDurationFlag(ctx, FlagOnDemandIdleTimeout)
+	Current.ParseDurationFlag(ctx, FlagOnDemandCheckInterval)
+}
@@ -20,6 +20,7 @@ package config
 import (
 	"time"
 
+	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/urfave/cli/v2"
 )
 
@@ -51,7 +52,7 @@ var (
 	// FlagPaymentsMystSCAddress represents the myst smart contract address
 	FlagPaymentsMystSCAddress = cli.StringFlag{
 		Name:  "payments.mystscaddress",
-		Value: "0x7753cfAD258eFbC52A9A1452e42fFbce9bE486cb",
+		Value: metadata.DefaultNetwork.MystSCAddress,
 		Usage: "The address of myst token smart contract",
 	}
 	// FlagPaymentsProviderInvoiceFrequency determines how often the provider sends invoices.
@@ -96,6 +97,29 @@ var (
 		Usage: "sets the upper limit of session payment value before forcing an invoice. If this value is exceeded before a payment interval is reached, an invoice is sent.",
 		Value: 3000000,
 	}
+	// FlagPaymentsProviderFreeTierServices sets the service types offered for free within the free tier quota.
+	FlagPaymentsProviderFreeTierServices = cli.StringSliceFlag{
+		Name:  "payments.provider.free-tier.services",
+		Usage: "Service types separated by comma, which are offered for free within the free tier quota",
+	}
+	// FlagPaymentsProviderFreeTierDuration sets the free tier session time quota of a consumer.
+	FlagPaymentsProviderFreeTierDuration = cli.DurationFlag{
+		Name:  "payments.provider.free-tier.duration",
+		Usage: "Session time a consumer can use for free per free tier period. 0 means unlimited",
+		Value: 30 * time.Minute,
+	}
+	// FlagPaymentsProviderFreeTierBytes sets the free tier traffic quota of a consumer.
+	FlagPaymentsProviderFreeTierBytes = cli.Uint64Flag{
+		Name:  "payments.provider.free-tier.bytes",
+		Usage: "Traffic in bytes a consumer can use for free per free tier period. 0 means unlimited",
+		Value: 1024 * 1024 * 1024,
+	}
+	// FlagPaymentsProviderFreeTierPeriod sets how often the free tier quota of a consumer is renewed.
+	FlagPaymentsProviderFreeTierPeriod = cli.DurationFlag{
+		Name:  "payments.provider.free-tier.period",
+		Usage: "Determines how often the free tier quota of a consumer is renewed. 0 means never",
+		Value: 24 * time.Hour,
+	}
 )
 
 // RegisterFlagsPayments function register payments flags to flag list.
@@ -114,6 +138,10 @@ func RegisterFlagsPayments(flags *[]cli.Flag) {
 		&FlagPaymentsConsumerPricePerGBLowerBound,
 		&FlagPaymentsConsumerDataLeewayMegabytes,
 		&FlagPaymentsMaxUnpaidInvoiceValue,
+		&FlagPaymentsProviderFreeTierServices,
+		&FlagPaymentsProviderFreeTierDuration,
+		&FlagPaymentsProviderFreeTierBytes,
+		&FlagPaymentsProviderFreeTierPeriod,
 	)
 }
 
@@ -131,4 +159,8 @@ func ParseFlagsPayments(ctx *cli.Context) {
 	Current.ParseUInt64Flag(ctx, FlagPaymentsConsumerPricePerGBLowerBound)
 	Current.ParseUInt64Flag(ctx, FlagPaymentsConsumerDataLeewayMegabytes)
 	Current.ParseUInt64Flag(ctx, FlagPaymentsMaxUnpaidInvoiceValue)
+	Current.ParseStringSliceFlag(ctx, FlagPaymentsProviderFreeTierServices)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderFreeTierDuration)
+	Current.ParseUInt64Flag(ctx, FlagPaymentsProviderFreeTierBytes)
+	Current.ParseDurationFlag(ctx, FlagPaymentsProviderFreeTierPeriod)
 }
@@ -0,0 +1,57 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagProviderNickname nickname of the provider.
+	FlagProviderNickname = cli.StringFlag{
+		Name:  "provider.nickname",
+		Usage: "Public nickname of the provider shown to consumers in proposals, up to 32 characters",
+	}
+	// FlagProviderContactURL contact URL of the provider.
+	FlagProviderContactURL = cli.StringFlag{
+		Name:  "provider.contact-url",
+		Usage: "Public http(s) or mailto URL the provider can be reached at, shown to consumers in proposals",
+	}
+	// FlagProviderTags capabilities declared by the provider.
+	FlagProviderTags = cli.StringSliceFlag{
+		Name:  "provider.tags",
+		Usage: `Up to 8 capabilities declared by the provider in proposals, consumers can filter proposals by them, e.g. "streaming-friendly"`,
+	}
+)
+
+// RegisterFlagsProviderDescription function register provider self-description flags to flag list
+func RegisterFlagsProviderDescription(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagProviderNickname,
+		&FlagProviderContactURL,
+		&FlagProviderTags,
+	)
+}
+
+// ParseFlagsProviderDescription function fills in provider self-description options from CLI context
+func ParseFlagsProviderDescription(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagProviderNickname)
+	Current.ParseStringFlag(ctx, FlagProviderContactURL)
+	Current.ParseStringSliceFlag(ctx, FlagProviderTags)
+}
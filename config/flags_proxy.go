@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagProxyAddress local address of SOCKS5/HTTP proxy frontend.
+	FlagProxyAddress = cli.StringFlag{
+		Name:  "proxy.address",
+		Usage: "Loopback address to serve SOCKS5 and HTTP proxy routed through the active session on, e.g. 127.0.0.1:1080. Proxy is disabled if empty",
+		Value: "",
+	}
+)
+
+// RegisterFlagsProxy function register proxy frontend flags to flag list
+func RegisterFlagsProxy(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagProxyAddress,
+	)
+}
+
+// ParseFlagsProxy function fills in proxy frontend options from CLI context
+func ParseFlagsProxy(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagProxyAddress)
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagQoSClasses QoS classes offered by provided services.
+	FlagQoSClasses = cli.StringSliceFlag{
+		Name:  "qos.classes",
+		Usage: `QoS classes of sessions offered to consumers, each given as "<name>:<bandwidth limit kbps>", the first one is the default. e.g. best-effort:5000,priority`,
+	}
+	// FlagQoSRequest QoS class requested by consumer.
+	FlagQoSRequest = cli.StringFlag{
+		Name:  "qos.request",
+		Usage: "QoS class of session requested from providers offering it, default class of provider is used if empty",
+		Value: "",
+	}
+)
+
+// RegisterFlagsQoS function register QoS flags to flag list
+func RegisterFlagsQoS(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagQoSClasses,
+		&FlagQoSRequest,
+	)
+}
+
+// ParseFlagsQoS function fills in QoS options from CLI context
+func ParseFlagsQoS(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagQoSClasses)
+	Current.ParseStringFlag(ctx, FlagQoSRequest)
+}
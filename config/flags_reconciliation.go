@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagReconciliationInterval how often consumer compares session traffic with provider.
+	FlagReconciliationInterval = cli.DurationFlag{
+		Name:  "reconciliation.interval",
+		Usage: `How often consumer and provider compare traffic counted for the session { "5m", "1h" }. Zero value disables reconciliation`,
+		Value: 5 * time.Minute,
+	}
+	// FlagReconciliationTolerance largest relative difference of session traffic counters not considered a divergence.
+	FlagReconciliationTolerance = cli.Float64Flag{
+		Name:  "reconciliation.tolerance",
+		Usage: "Largest relative difference of session traffic counted by consumer and provider, e.g. 0.1 for 10%",
+		Value: 0.1,
+	}
+	// FlagReconciliationMinBytes difference of session traffic counters ignored regardless of tolerance.
+	FlagReconciliationMinBytes = cli.Uint64Flag{
+		Name:  "reconciliation.min-bytes",
+		Usage: "Difference of session traffic counters always ignored, it covers counters sampled at different moments",
+		Value: 10 * datasize.MiB.Bytes(),
+	}
+	// FlagReconciliationPausePayments stops paying for the session while traffic counters diverge.
+	FlagReconciliationPausePayments = cli.BoolFlag{
+		Name:  "reconciliation.pause-payments",
+		Usage: "Stop paying invoices while traffic counted by consumer and provider diverges",
+		Value: false,
+	}
+)
+
+// RegisterFlagsReconciliation function register traffic reconciliation flags to flag list
+func RegisterFlagsReconciliation(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagReconciliationInterval,
+		&FlagReconciliationTolerance,
+		&FlagReconciliationMinBytes,
+		&FlagReconciliationPausePayments,
+	)
+}
+
+// ParseFlagsReconciliation function fills in traffic reconciliation options from CLI context
+func ParseFlagsReconciliation(ctx *cli.Context) {
+	Current.ParseDurationFlag(ctx, FlagReconciliationInterval)
+	Current.ParseFloat64Flag(ctx, FlagReconciliationTolerance)
+	Current.ParseUInt64Flag(ctx, FlagReconciliationMinBytes)
+	Current.ParseBoolFlag(ctx, FlagReconciliationPausePayments)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagResourcesInterval how often resource usage of services is sampled.
+	FlagResourcesInterval = cli.DurationFlag{
+		Name:  "resources.interval",
+		Usage: "How often resource usage of provided services is sampled, sampling is disabled if 0",
+		Value: time.Minute,
+	}
+	// FlagResourcesMaxGoroutines goroutine count of a service which is reported as a warning.
+	FlagResourcesMaxGoroutines = cli.IntFlag{
+		Name:  "resources.max-goroutines",
+		Usage: "Goroutine count of a service to report as a warning, disabled if 0",
+		Value: 5000,
+	}
+	// FlagResourcesMaxCPU CPU usage of service processes which is reported as a warning.
+	FlagResourcesMaxCPU = cli.Float64Flag{
+		Name:  "resources.max-cpu",
+		Usage: "CPU usage percent of service processes to report as a warning, disabled if 0",
+		Value: 90,
+	}
+	// FlagResourcesMaxMemory memory usage of service processes which is reported as a warning.
+	FlagResourcesMaxMemory = cli.Uint64Flag{
+		Name:  "resources.max-memory-mb",
+		Usage: "Memory usage of service processes in MiB to report as a warning, disabled if 0",
+		Value: 512,
+	}
+	// FlagResourcesMaxFileDescriptors open file descriptor count of service processes which is reported as a warning.
+	FlagResourcesMaxFileDescriptors = cli.IntFlag{
+		Name:  "resources.max-fds",
+		Usage: "Open file descriptor count of service processes to report as a warning, disabled if 0",
+		Value: 1000,
+	}
+)
+
+// RegisterFlagsResources function register service resource usage flags to flag list
+func RegisterFlagsResources(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagResourcesInterval,
+		&FlagResourcesMaxGoroutines,
+		&FlagResourcesMaxCPU,
+		&FlagResourcesMaxMemory,
+		&FlagResourcesMaxFileDescriptors,
+	)
+}
+
+// ParseFlagsResources function fills in service resource usage options from CLI context
+func ParseFlagsResources(ctx *cli.Context) {
+	Current.ParseDurationFlag(ctx, FlagResourcesInterval)
+	Current.ParseIntFlag(ctx, FlagResourcesMaxGoroutines)
+	Current.ParseFloat64Flag(ctx, FlagResourcesMaxCPU)
+	Current.ParseUInt64Flag(ctx, FlagResourcesMaxMemory)
+	Current.ParseIntFlag(ctx, FlagResourcesMaxFileDescriptors)
+}
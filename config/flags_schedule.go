@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagScheduleEnabled enables scheduled connections.
+	FlagScheduleEnabled = cli.BoolFlag{
+		Name:  "schedule.enabled",
+		Usage: "Connect and disconnect automatically by the schedule rules",
+		Value: false,
+	}
+	// FlagScheduleConsumerID identity of scheduled connections.
+	FlagScheduleConsumerID = cli.StringFlag{
+		Name:  "schedule.consumer-id",
+		Usage: "Identity scheduled connections are made with",
+	}
+	// FlagScheduleRules recurring connection rules.
+	FlagScheduleRules = cli.StringSliceFlag{
+		Name:  "schedule.rules",
+		Usage: `Rules to keep the connection in, given as "<days> <from>-<to> <country> [service type]", e.g. "mon-fri 09:00-17:00 DE wireguard"`,
+	}
+	// FlagScheduleCheckInterval how often schedule rules are checked.
+	FlagScheduleCheckInterval = cli.DurationFlag{
+		Name:  "schedule.check-interval",
+		Usage: "How often schedule rules are checked",
+		Value: 30 * time.Second,
+	}
+)
+
+// RegisterFlagsSchedule function register scheduled connection flags to flag list
+func RegisterFlagsSchedule(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagScheduleEnabled,
+		&FlagScheduleConsumerID,
+		&FlagScheduleRules,
+		&FlagScheduleCheckInterval,
+	)
+}
+
+// ParseFlagsSchedule function fills in scheduled connection options from CLI context
+func ParseFlagsSchedule(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagScheduleEnabled)
+	Current.ParseStringFlag(ctx, FlagScheduleConsumerID)
+	Current.ParseStringSliceFlag(ctx, FlagScheduleRules)
+	Current.ParseDurationFlag(ctx, FlagScheduleCheckInterval)
+}
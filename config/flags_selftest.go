@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagSelftestTimeout limits how long a single self-test stage may take.
+	FlagSelftestTimeout = cli.DurationFlag{
+		Name:  "selftest.timeout",
+		Usage: "Maximum duration of a single self-test stage, payments stage needs at least one invoice period",
+		Value: 3 * time.Minute,
+	}
+)
+
+// RegisterFlagsSelftest registers CLI flags used by the self-test command.
+func RegisterFlagsSelftest(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagSelftestTimeout,
+	)
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagServiceInstallUser user to run the installed node service as.
+	FlagServiceInstallUser = cli.StringFlag{
+		Name:  "user",
+		Usage: "Unprivileged user to run the installed node service as, network capabilities are granted to it (Linux only)",
+	}
+)
+
+// RegisterFlagsServiceInstall registers CLI flags used to install node as OS service.
+func RegisterFlagsServiceInstall(flags *[]cli.Flag) {
+	*flags = append(*flags,
+		&FlagServiceInstallUser,
+	)
+}
@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -40,6 +42,19 @@ var (
 		Usage:  "Comma separated list that determines the access policies of the noop service.",
 		Hidden: true,
 	}
+	// FlagNoopTrafficRate sets the rate of fake traffic reported for every noop service session.
+	FlagNoopTrafficRate = cli.Uint64Flag{
+		Name:   "noop.traffic-rate",
+		Usage:  "Rate in bytes per second of fake traffic reported for every noop service session, used for load testing. Zero disables it.",
+		Hidden: true,
+	}
+	// FlagNoopStatsInterval sets how often fake traffic statistics are reported.
+	FlagNoopStatsInterval = cli.DurationFlag{
+		Name:   "noop.stats-interval",
+		Usage:  "How often fake traffic statistics of the noop service sessions are reported.",
+		Value:  time.Second,
+		Hidden: true,
+	}
 )
 
 // RegisterFlagsServiceNoop function register Wireguard flags to flag list
@@ -48,6 +63,8 @@ func RegisterFlagsServiceNoop(flags *[]cli.Flag) {
 		&FlagNoopPriceMinute,
 		&FlagNoopPriceGB,
 		&FlagNoopAccessPolicies,
+		&FlagNoopTrafficRate,
+		&FlagNoopStatsInterval,
 	)
 }
 
@@ -56,4 +73,6 @@ func ParseFlagsServiceNoop(ctx *cli.Context) {
 	Current.ParseFloat64Flag(ctx, FlagNoopPriceMinute)
 	Current.ParseFloat64Flag(ctx, FlagNoopPriceGB)
 	Current.ParseStringFlag(ctx, FlagNoopAccessPolicies)
+	Current.ParseUInt64Flag(ctx, FlagNoopTrafficRate)
+	Current.ParseDurationFlag(ctx, FlagNoopStatsInterval)
 }
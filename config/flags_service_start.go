@@ -59,6 +59,50 @@ var (
 		Usage: "Sets the price per minute applied to provider service.",
 		Value: 0.0001,
 	}
+	// FlagPaymentFreeTrialMinutes sets the minutes of service a registered consumer gets for free every day.
+	FlagPaymentFreeTrialMinutes = cli.Uint64Flag{
+		Name:  "payment.free-trial-minutes",
+		Usage: "Sets the minutes of service a registered consumer gets for free every day",
+		Value: 0,
+	}
+	// FlagPaymentFreeTrialMB sets the traffic in MiB a registered consumer gets for free every day.
+	FlagPaymentFreeTrialMB = cli.Uint64Flag{
+		Name:  "payment.free-trial-mb",
+		Usage: "Sets the traffic in MiB a registered consumer gets for free every day",
+		Value: 0,
+	}
+
+	// FlagServiceUnlisted keeps the service proposal out of discovery, consumers connect using the invite code.
+	FlagServiceUnlisted = cli.BoolFlag{
+		Name:  "unlisted",
+		Usage: "Do not announce service proposal to discovery, share it with consumers using the invite code instead",
+		Value: false,
+	}
+
+	// FlagConsumerPolicyAllowedCountries a comma-separated list of country codes consumers are allowed to connect from.
+	FlagConsumerPolicyAllowedCountries = cli.StringFlag{
+		Name:  "consumer-policy.allowed-countries",
+		Usage: "Comma separated list of country codes (e.g. LT,DE) consumers are allowed to connect from",
+		Value: "",
+	}
+	// FlagConsumerPolicyDeniedCountries a comma-separated list of country codes consumers are not allowed to connect from.
+	FlagConsumerPolicyDeniedCountries = cli.StringFlag{
+		Name:  "consumer-policy.denied-countries",
+		Usage: "Comma separated list of country codes (e.g. US,CN) consumers are not allowed to connect from",
+		Value: "",
+	}
+	// FlagConsumerPolicyAllowedASNs a comma-separated list of ASNs consumers are allowed to connect from.
+	FlagConsumerPolicyAllowedASNs = cli.StringFlag{
+		Name:  "consumer-policy.allowed-asns",
+		Usage: "Comma separated list of autonomous system numbers (e.g. 8764,AS15169) consumers are allowed to connect from",
+		Value: "",
+	}
+	// FlagConsumerPolicyDeniedASNs a comma-separated list of ASNs consumers are not allowed to connect from.
+	FlagConsumerPolicyDeniedASNs = cli.StringFlag{
+		Name:  "consumer-policy.denied-asns",
+		Usage: "Comma separated list of autonomous system numbers (e.g. 8764,AS15169) consumers are not allowed to connect from",
+		Value: "",
+	}
 )
 
 // RegisterFlagsServiceStart registers CLI flags used to start a service.
@@ -69,7 +113,14 @@ func RegisterFlagsServiceStart(flags *[]cli.Flag) {
 		&FlagAgreedTermsConditions,
 		&FlagPaymentPricePerGB,
 		&FlagPaymentPricePerMinute,
+		&FlagPaymentFreeTrialMinutes,
+		&FlagPaymentFreeTrialMB,
 		&FlagAccessPolicyList,
+		&FlagServiceUnlisted,
+		&FlagConsumerPolicyAllowedCountries,
+		&FlagConsumerPolicyDeniedCountries,
+		&FlagConsumerPolicyAllowedASNs,
+		&FlagConsumerPolicyDeniedASNs,
 	)
 }
 
@@ -80,5 +131,12 @@ func ParseFlagsServiceStart(ctx *cli.Context) {
 	Current.ParseBoolFlag(ctx, FlagAgreedTermsConditions)
 	Current.ParseFloat64Flag(ctx, FlagPaymentPricePerGB)
 	Current.ParseFloat64Flag(ctx, FlagPaymentPricePerMinute)
+	Current.ParseUInt64Flag(ctx, FlagPaymentFreeTrialMinutes)
+	Current.ParseUInt64Flag(ctx, FlagPaymentFreeTrialMB)
 	Current.ParseStringFlag(ctx, FlagAccessPolicyList)
+	Current.ParseBoolFlag(ctx, FlagServiceUnlisted)
+	Current.ParseStringFlag(ctx, FlagConsumerPolicyAllowedCountries)
+	Current.ParseStringFlag(ctx, FlagConsumerPolicyDeniedCountries)
+	Current.ParseStringFlag(ctx, FlagConsumerPolicyAllowedASNs)
+	Current.ParseStringFlag(ctx, FlagConsumerPolicyDeniedASNs)
 }
@@ -18,6 +18,8 @@
 package config
 
 import (
+	"time"
+
 	"github.com/urfave/cli/v2"
 )
 
@@ -49,6 +51,18 @@ var (
 		Name:  "wireguard.access-policies",
 		Usage: "Comma separated list that determines the access policies of the wireguard service.",
 	}
+	// FlagWireguardKeyRotationInterval sets how often the tunnel keys of long-lived sessions are rotated.
+	FlagWireguardKeyRotationInterval = cli.DurationFlag{
+		Name:  "wireguard.key-rotation.interval",
+		Usage: `Interval of tunnel key rotation for long-lived sessions { "1h", "30m" }. Zero value disables the rotation`,
+		Value: time.Hour,
+	}
+	// FlagWireguardKeyRotationThreshold sets the session duration after which the tunnel keys are rotated.
+	FlagWireguardKeyRotationThreshold = cli.DurationFlag{
+		Name:  "wireguard.key-rotation.threshold",
+		Usage: `Session duration after which tunnel key rotation starts { "2h", "90m" }`,
+		Value: 2 * time.Hour,
+	}
 )
 
 // RegisterFlagsServiceWireguard function register Wireguard flags to flag list
@@ -59,6 +73,8 @@ func RegisterFlagsServiceWireguard(flags *[]cli.Flag) {
 		&FlagWireguardPriceMinute,
 		&FlagWireguardPriceGB,
 		&FlagWireguardAccessPolicies,
+		&FlagWireguardKeyRotationInterval,
+		&FlagWireguardKeyRotationThreshold,
 	)
 }
 
@@ -69,4 +85,6 @@ func ParseFlagsServiceWireguard(ctx *cli.Context) {
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceMinute)
 	Current.ParseFloat64Flag(ctx, FlagWireguardPriceGB)
 	Current.ParseStringFlag(ctx, FlagWireguardAccessPolicies)
+	Current.ParseDurationFlag(ctx, FlagWireguardKeyRotationInterval)
+	Current.ParseDurationFlag(ctx, FlagWireguardKeyRotationThreshold)
 }
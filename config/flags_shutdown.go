@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagShutdownDrainTimeout how long consumers are given to end their sessions when provider shuts down.
+	FlagShutdownDrainTimeout = cli.DurationFlag{
+		Name:  "shutdown.drain-timeout",
+		Usage: "How long consumers are given to end their sessions after provider starts shutting down, sessions are killed immediately if 0",
+		Value: 30 * time.Second,
+	}
+	// FlagShutdownPaymentTimeout how long to wait for final payment of drained sessions.
+	FlagShutdownPaymentTimeout = cli.DurationFlag{
+		Name:  "shutdown.payment-timeout",
+		Usage: "How long to wait for the final payment of sessions still running at the drain deadline",
+		Value: 10 * time.Second,
+	}
+)
+
+// RegisterFlagsShutdown function register shutdown flags to flag list
+func RegisterFlagsShutdown(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagShutdownDrainTimeout,
+		&FlagShutdownPaymentTimeout,
+	)
+}
+
+// ParseFlagsShutdown function fills in shutdown options from CLI context
+func ParseFlagsShutdown(ctx *cli.Context) {
+	Current.ParseDurationFlag(ctx, FlagShutdownDrainTimeout)
+	Current.ParseDurationFlag(ctx, FlagShutdownPaymentTimeout)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagSpeedTestDownloadURL public speed test download endpoint.
+	FlagSpeedTestDownloadURL = cli.StringFlag{
+		Name:  "speedtest.download-url",
+		Usage: "URL of public speed test download endpoint, amount of bytes to download is appended to it",
+		Value: "https://speed.cloudflare.com/__down?bytes=",
+	}
+	// FlagSpeedTestUploadURL public speed test upload endpoint.
+	FlagSpeedTestUploadURL = cli.StringFlag{
+		Name:  "speedtest.upload-url",
+		Usage: "URL of public speed test upload endpoint, payload is sent in POST request body",
+		Value: "https://speed.cloudflare.com/__up",
+	}
+	// FlagSpeedTestSize amount of data transferred in each direction by speed test.
+	FlagSpeedTestSize = cli.Uint64Flag{
+		Name:  "speedtest.size",
+		Usage: "Amount of bytes downloaded and uploaded when measuring connection speed",
+		Value: 10 * datasize.MiB.Bytes(),
+	}
+	// FlagSpeedTestServe lets consumers measure connection speed to this provider.
+	FlagSpeedTestServe = cli.BoolFlag{
+		Name:  "speedtest.serve",
+		Usage: "Allow consumers to measure connection speed to this provider, it transfers extra data over the sessions",
+		Value: false,
+	}
+)
+
+// RegisterFlagsSpeedTest function register connection speed test flags to flag list
+func RegisterFlagsSpeedTest(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagSpeedTestDownloadURL,
+		&FlagSpeedTestUploadURL,
+		&FlagSpeedTestSize,
+		&FlagSpeedTestServe,
+	)
+}
+
+// ParseFlagsSpeedTest function fills in connection speed test options from CLI context
+func ParseFlagsSpeedTest(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagSpeedTestDownloadURL)
+	Current.ParseStringFlag(ctx, FlagSpeedTestUploadURL)
+	Current.ParseUInt64Flag(ctx, FlagSpeedTestSize)
+	Current.ParseBoolFlag(ctx, FlagSpeedTestServe)
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagStateDebounceStatistics debounces traffic statistics updates of node state.
+	FlagStateDebounceStatistics = cli.DurationFlag{
+		Name:  "state.debounce-statistics",
+		Usage: "How long to collect traffic statistics and earnings updates before recalculating node state, defaults are used if 0",
+		Value: 0,
+	}
+	// FlagStateDebounceSessions debounces service state updates of node state.
+	FlagStateDebounceSessions = cli.DurationFlag{
+		Name:  "state.debounce-sessions",
+		Usage: "How long to collect service state updates before recalculating node state, defaults are used if 0",
+		Value: 0,
+	}
+	// FlagStateDebounceNAT debounces NAT status updates of node state.
+	FlagStateDebounceNAT = cli.DurationFlag{
+		Name:  "state.debounce-nat",
+		Usage: "How long to collect NAT status updates before recalculating node state, defaults are used if 0",
+		Value: 0,
+	}
+)
+
+// RegisterFlagsState function register node state flags to flag list
+func RegisterFlagsState(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagStateDebounceStatistics,
+		&FlagStateDebounceSessions,
+		&FlagStateDebounceNAT,
+	)
+}
+
+// ParseFlagsState function fills in node state options from CLI context
+func ParseFlagsState(ctx *cli.Context) {
+	Current.ParseDurationFlag(ctx, FlagStateDebounceStatistics)
+	Current.ParseDurationFlag(ctx, FlagStateDebounceSessions)
+	Current.ParseDurationFlag(ctx, FlagStateDebounceNAT)
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagStorageBackend database used to persist node data.
+	FlagStorageBackend = cli.StringFlag{
+		Name:  "storage.backend",
+		Usage: `Database used to persist node data. Options: { "bolt", "sqlite" }`,
+		Value: "bolt",
+	}
+	// FlagStorageEncryption encrypts node database at rest.
+	FlagStorageEncryption = cli.StringFlag{
+		Name:  "storage.encryption",
+		Usage: `Encrypts node database at rest. Options: { "none", "passphrase", "keychain" }`,
+		Value: "none",
+	}
+	// FlagStoragePassphrase passphrase for node database encryption.
+	FlagStoragePassphrase = cli.StringFlag{
+		Name:    "storage.passphrase",
+		Usage:   "Passphrase to derive node database encryption key from, used with --storage.encryption=passphrase",
+		EnvVars: []string{"MYST_STORAGE_PASSPHRASE"},
+	}
+	// FlagStorageBackupInterval interval of automatic node database backups.
+	FlagStorageBackupInterval = cli.DurationFlag{
+		Name:  "storage.backup.interval",
+		Usage: `Interval of automatic node database backups { "12h", "24h" }. Zero value disables automatic backups`,
+		Value: 24 * time.Hour,
+	}
+	// FlagStorageBackupKeep number of node database backups to keep.
+	FlagStorageBackupKeep = cli.IntFlag{
+		Name:  "storage.backup.keep",
+		Usage: "Number of latest node database backups to keep, older ones are removed",
+		Value: 7,
+	}
+)
+
+// RegisterFlagsStorage function register storage flags to flag list
+func RegisterFlagsStorage(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagStorageBackend,
+		&FlagStorageEncryption,
+		&FlagStoragePassphrase,
+		&FlagStorageBackupInterval,
+		&FlagStorageBackupKeep,
+	)
+}
+
+// ParseFlagsStorage function fills in storage options from CLI context
+func ParseFlagsStorage(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagStorageBackend)
+	Current.ParseStringFlag(ctx, FlagStorageEncryption)
+	Current.ParseStringFlag(ctx, FlagStoragePassphrase)
+	Current.ParseDurationFlag(ctx, FlagStorageBackupInterval)
+	Current.ParseIntFlag(ctx, FlagStorageBackupKeep)
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagTelemetryEnabled explicit consent of user to send anonymized telemetry.
+	FlagTelemetryEnabled = cli.BoolFlag{
+		Name:  "telemetry.enabled",
+		Usage: "Send anonymized telemetry: hashed identity, country, session counts and error categories. Use 'GET /telemetry/preview' to see what is sent",
+		Value: false,
+	}
+	// FlagTelemetryAddress telemetry collector URL.
+	FlagTelemetryAddress = cli.StringFlag{
+		Name:  "telemetry.address",
+		Usage: "URL of telemetry collector",
+		Value: "https://quality.mysterium.network/api/v1/telemetry",
+	}
+	// FlagTelemetryInterval period covered by a single telemetry report.
+	FlagTelemetryInterval = cli.DurationFlag{
+		Name:  "telemetry.interval",
+		Usage: "Period covered by a single telemetry report, reports are sent at the end of it",
+		Value: time.Hour,
+	}
+)
+
+// RegisterFlagsTelemetry function register telemetry flags to flag list
+func RegisterFlagsTelemetry(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagTelemetryEnabled,
+		&FlagTelemetryAddress,
+		&FlagTelemetryInterval,
+	)
+}
+
+// ParseFlagsTelemetry function fills in telemetry options from CLI context
+func ParseFlagsTelemetry(ctx *cli.Context) {
+	Current.ParseBoolFlag(ctx, FlagTelemetryEnabled)
+	Current.ParseStringFlag(ctx, FlagTelemetryAddress)
+	Current.ParseDurationFlag(ctx, FlagTelemetryInterval)
+}
@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+// FlagTequilapiAllowedOrigins UI origins allowed to talk to tequilapi in addition to Mysterium ones.
+var FlagTequilapiAllowedOrigins = cli.StringSliceFlag{
+	Name:  "tequilapi.allowed-origins",
+	Usage: "UI origins allowed to talk to Tequilapi, e.g. http://192.168.1.10:3000 or http://192.168.1.10:3000=read for read-only access",
+	Value: cli.NewStringSlice(),
+}
+
+// RegisterFlagsTequilapiCors function register tequilapi CORS flags to flag list
+func RegisterFlagsTequilapiCors(flags *[]cli.Flag) {
+	*flags = append(*flags, &FlagTequilapiAllowedOrigins)
+}
+
+// ParseFlagsTequilapiCors function fills in tequilapi CORS options from CLI context
+func ParseFlagsTequilapiCors(ctx *cli.Context) {
+	Current.ParseStringSliceFlag(ctx, FlagTequilapiAllowedOrigins)
+}
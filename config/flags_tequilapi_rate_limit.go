@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagTequilapiRateLimitReads rate of cheap tequilapi requests allowed per caller.
+	FlagTequilapiRateLimitReads = cli.Float64Flag{
+		Name:  "tequilapi.rate-limit.reads",
+		Usage: "Tequilapi requests, except connect and identity registration, per second allowed for every caller token and IP. Zero value disables the limit",
+		Value: 20,
+	}
+	// FlagTequilapiRateLimitReadsBurst burst of cheap tequilapi requests allowed per caller.
+	FlagTequilapiRateLimitReadsBurst = cli.IntFlag{
+		Name:  "tequilapi.rate-limit.reads-burst",
+		Usage: "Tequilapi requests, except connect and identity registration, allowed at once for every caller token and IP",
+		Value: 100,
+	}
+	// FlagTequilapiRateLimitWrites rate of expensive tequilapi operations allowed per caller.
+	FlagTequilapiRateLimitWrites = cli.Float64Flag{
+		Name:  "tequilapi.rate-limit.writes",
+		Usage: "Tequilapi operations, i.e. connect and identity registration, per second allowed for every caller token and IP. Zero value disables the limit",
+		Value: 1,
+	}
+	// FlagTequilapiRateLimitWritesBurst burst of expensive tequilapi operations allowed per caller.
+	FlagTequilapiRateLimitWritesBurst = cli.IntFlag{
+		Name:  "tequilapi.rate-limit.writes-burst",
+		Usage: "Tequilapi operations, i.e. connect and identity registration, allowed at once for every caller token and IP",
+		Value: 10,
+	}
+)
+
+// RegisterFlagsTequilapiRateLimit function register tequilapi rate limit flags to flag list
+func RegisterFlagsTequilapiRateLimit(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagTequilapiRateLimitReads,
+		&FlagTequilapiRateLimitReadsBurst,
+		&FlagTequilapiRateLimitWrites,
+		&FlagTequilapiRateLimitWritesBurst,
+	)
+}
+
+// ParseFlagsTequilapiRateLimit function fills in tequilapi rate limit options from CLI context
+func ParseFlagsTequilapiRateLimit(ctx *cli.Context) {
+	Current.ParseFloat64Flag(ctx, FlagTequilapiRateLimitReads)
+	Current.ParseIntFlag(ctx, FlagTequilapiRateLimitReadsBurst)
+	Current.ParseFloat64Flag(ctx, FlagTequilapiRateLimitWrites)
+	Current.ParseIntFlag(ctx, FlagTequilapiRateLimitWritesBurst)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"time"
+
+	"github.com/urfave/cli/v2"
+)
+
+var (
+	// FlagUpdateURL address of node release channels.
+	FlagUpdateURL = cli.StringFlag{
+		Name:  "update.url",
+		Usage: "Address of node release channels, self-update is disabled if empty",
+		Value: "",
+	}
+	// FlagUpdateChannel release channel to follow.
+	FlagUpdateChannel = cli.StringFlag{
+		Name:  "update.channel",
+		Usage: `Release channel to follow. Options: { "stable", "beta" }`,
+		Value: "stable",
+	}
+	// FlagUpdateSigner identity signing released binaries.
+	FlagUpdateSigner = cli.StringFlag{
+		Name:  "update.signer",
+		Usage: "Identity signing released node binaries, releases signed by anyone else are rejected",
+		Value: "",
+	}
+	// FlagUpdateAuto enables automatic installation of updates.
+	FlagUpdateAuto = cli.BoolFlag{
+		Name:  "update.auto",
+		Usage: "Install node updates as soon as they are released",
+		Value: false,
+	}
+	// FlagUpdateCheckInterval how often release channel is checked.
+	FlagUpdateCheckInterval = cli.DurationFlag{
+		Name:  "update.check-interval",
+		Usage: "How often to check for node updates",
+		Value: 6 * time.Hour,
+	}
+	// FlagUpdateDrainTimeout how long to wait for provider sessions to finish before restarting.
+	FlagUpdateDrainTimeout = cli.DurationFlag{
+		Name:  "update.drain-timeout",
+		Usage: "How long to wait for active provider sessions to finish before restarting updated node",
+		Value: 30 * time.Minute,
+	}
+)
+
+// RegisterFlagsUpdate function register self-update flags to flag list
+func RegisterFlagsUpdate(flags *[]cli.Flag) {
+	*flags = append(
+		*flags,
+		&FlagUpdateURL,
+		&FlagUpdateChannel,
+		&FlagUpdateSigner,
+		&FlagUpdateAuto,
+		&FlagUpdateCheckInterval,
+		&FlagUpdateDrainTimeout,
+	)
+}
+
+// ParseFlagsUpdate function fills in self-update options from CLI context
+func ParseFlagsUpdate(ctx *cli.Context) {
+	Current.ParseStringFlag(ctx, FlagUpdateURL)
+	Current.ParseStringFlag(ctx, FlagUpdateChannel)
+	Current.ParseStringFlag(ctx, FlagUpdateSigner)
+	Current.ParseBoolFlag(ctx, FlagUpdateAuto)
+	Current.ParseDurationFlag(ctx, FlagUpdateCheckInterval)
+	Current.ParseDurationFlag(ctx, FlagUpdateDrainTimeout)
+}
@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package adblock
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// dnsPort is a port tunnel DNS queries are sent to, it can not be configured for the tunnel.
+const dnsPort = 53
+
+type httpClient interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// Config describes ad and tracker blocking.
+type Config struct {
+	// Lists are addresses of blocklists of ad and tracker domains.
+	Lists []string
+	// UpdateInterval is how often blocklists are downloaded while blocking is enabled.
+	UpdateInterval time.Duration
+	// Address is local IP the blocking DNS proxy listens on.
+	Address string
+}
+
+// Blocker serves tunnel DNS queries of consumer through a local proxy, blocking ad and tracker domains.
+type Blocker struct {
+	config     Config
+	httpClient httpClient
+	list       *dns.Blocklist
+
+	mu        sync.Mutex
+	proxy     *dns.Proxy
+	handler   *dns.BlockingHandler
+	done      chan struct{}
+	updatedAt time.Time
+}
+
+// NewBlocker creates ad and tracker blocker, blocklists are downloaded once blocking is enabled.
+func NewBlocker(config Config, httpClient httpClient) *Blocker {
+	return &Blocker{
+		config:     config,
+		httpClient: httpClient,
+		list:       dns.NewBlocklist(),
+	}
+}
+
+// Start starts serving queries resolved via given servers, or system ones if none given, and returns IP to point tunnel DNS to.
+func (b *Blocker) Start(servers []string) (string, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.stop(); err != nil {
+		log.Warn().Err(err).Msg("Failed to stop previous blocking DNS proxy")
+	}
+
+	upstream := dns.ResolveVia(servers...)
+	if len(servers) == 0 {
+		var err error
+		if upstream, err = dns.ResolveViaSystem(); err != nil {
+			return "", err
+		}
+	}
+
+	handler := dns.BlockDomains(upstream, b.list)
+	proxy := dns.NewProxy(b.config.Address, dnsPort, handler)
+	if err := proxy.Run(); err != nil {
+		return "", err
+	}
+
+	b.proxy = proxy
+	b.handler = handler
+	b.done = make(chan struct{})
+	go b.updateLoop(b.done)
+	return b.config.Address, nil
+}
+
+// Stop stops serving queries.
+func (b *Blocker) Stop() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.stop()
+}
+
+func (b *Blocker) stop() error {
+	if b.proxy == nil {
+		return nil
+	}
+
+	close(b.done)
+	err := b.proxy.Stop()
+	b.proxy = nil
+	return err
+}
+
+// Counters returns how many queries were served and how many of them were blocked since the proxy was started.
+func (b *Blocker) Counters() (queries, blocked uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.handler == nil {
+		return 0, 0
+	}
+	return b.handler.Counters()
+}
+
+// updateLoop keeps blocklists up to date while the proxy is running.
+func (b *Blocker) updateLoop(done <-chan struct{}) {
+	next := time.Until(b.lastUpdate().Add(b.config.UpdateInterval))
+	for {
+		select {
+		case <-done:
+			return
+		case <-time.After(next):
+			if err := b.update(); err != nil {
+				log.Warn().Err(err).Msg("Failed to update ad and tracker blocklists")
+			}
+			next = b.config.UpdateInterval
+		}
+	}
+}
+
+func (b *Blocker) lastUpdate() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.updatedAt
+}
+
+// update downloads all blocklists and replaces blocked domains with them.
+func (b *Blocker) update() error {
+	var domains []string
+	for _, url := range b.config.Lists {
+		list, err := b.download(url)
+		if err != nil {
+			return errors.Wrapf(err, "could not download blocklist %s", url)
+		}
+		domains = append(domains, list...)
+	}
+
+	b.list.Replace(domains)
+	b.mu.Lock()
+	b.updatedAt = time.Now()
+	b.mu.Unlock()
+
+	log.Info().Msgf("Ad and tracker blocklists updated, %d domains blocked", b.list.Len())
+	return nil
+}
+
+func (b *Blocker) download(url string) ([]string, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected response status: %s", resp.Status)
+	}
+	return dns.ParseBlocklist(resp.Body)
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package adblock
+
+import (
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockHTTPClient struct {
+	lists map[string]string
+}
+
+func (m *mockHTTPClient) Do(req *http.Request) (*http.Response, error) {
+	list, ok := m.lists[req.URL.String()]
+	if !ok {
+		return nil, errors.New("no such blocklist")
+	}
+	return &http.Response{
+		Status:     "200 OK",
+		StatusCode: http.StatusOK,
+		Body:       ioutil.NopCloser(strings.NewReader(list)),
+	}, nil
+}
+
+func TestBlocker_UpdateReplacesBlockedDomains(t *testing.T) {
+	// given
+	client := &mockHTTPClient{lists: map[string]string{
+		"http://lists/hosts":   "0.0.0.0 ads.example.com\n0.0.0.0 tracker.example.com\n",
+		"http://lists/domains": "tracker.net\n",
+	}}
+	blocker := NewBlocker(Config{Lists: []string{"http://lists/hosts", "http://lists/domains"}}, client)
+
+	// when
+	err := blocker.update()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 3, blocker.list.Len())
+	assert.True(t, blocker.list.Blocks("cdn.tracker.net."))
+	assert.False(t, blocker.lastUpdate().IsZero())
+}
+
+func TestBlocker_UpdateKeepsBlockedDomainsOnFailure(t *testing.T) {
+	// given
+	client := &mockHTTPClient{lists: map[string]string{
+		"http://lists/hosts": "0.0.0.0 ads.example.com\n",
+	}}
+	blocker := NewBlocker(Config{Lists: []string{"http://lists/hosts"}}, client)
+	assert.NoError(t, blocker.update())
+
+	// when
+	blocker.config.Lists = append(blocker.config.Lists, "http://lists/missing")
+	err := blocker.update()
+
+	// then
+	assert.Error(t, err)
+	assert.True(t, blocker.list.Blocks("ads.example.com."))
+}
+
+func TestBlocker_CountersAreZeroWhenNotStarted(t *testing.T) {
+	blocker := NewBlocker(Config{}, &mockHTTPClient{})
+
+	queries, blocked := blocker.Counters()
+	assert.Zero(t, queries)
+	assert.Zero(t, blocked)
+	assert.NoError(t, blocker.Stop())
+}
@@ -23,7 +23,7 @@ import (
 )
 
 // AppTopicConnectionThroughput represents the session throughput topic.
-const AppTopicConnectionThroughput = "Throughput"
+const AppTopicConnectionThroughput = "connection.throughput"
 
 // AppEventConnectionThroughput represents a session throughput event.
 type AppEventConnectionThroughput struct {
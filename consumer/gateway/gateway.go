@@ -0,0 +1,313 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gateway
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotSupported indicates that gateway mode can not be enabled on this OS.
+var ErrNotSupported = errors.New("gateway mode is supported on Linux only")
+
+// errNoTunnelInterface indicates that connection does not report its tunnel interface, so LAN traffic can not be pinned to it.
+var errNoTunnelInterface = errors.New("tunnel interface of the session is unknown")
+
+const (
+	chainForward     = "FORWARD"
+	chainPostRouting = "POSTROUTING"
+
+	ruleComment = "myst-gateway"
+)
+
+// Config describes LAN traffic forwarded through the active session.
+type Config struct {
+	Enabled bool
+	// Interface is network interface LAN clients are connected to.
+	Interface string
+	// Subnet is LAN network in CIDR notation, its traffic is forwarded through the session.
+	Subnet string
+}
+
+// Validate checks that enabled gateway has LAN interface and subnet set.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.Interface == "" {
+		return errors.New("LAN interface is required")
+	}
+	ip, _, err := net.ParseCIDR(c.Subnet)
+	if err != nil {
+		return fmt.Errorf("invalid LAN subnet %q: %w", c.Subnet, err)
+	}
+	if ip.To4() == nil {
+		return fmt.Errorf("LAN subnet %q is not IPv4", c.Subnet)
+	}
+	return nil
+}
+
+// Status describes gateway configuration and whether LAN traffic is being forwarded.
+type Status struct {
+	Config
+	// Forwarding is set while LAN traffic is forwarded through the active session.
+	Forwarding bool
+}
+
+// Gateway NATs and forwards traffic of LAN clients through the tunnel interface of the main session. LAN traffic
+// is dropped while gateway is enabled and there is no session, as well as forwarded to any other interface,
+// so that it does not leak outside of the tunnel even while tunnel routes are missing or being replaced.
+type Gateway struct {
+	supported bool
+	exec      func(args ...string) error
+	output    func(args ...string) (string, error)
+	save      func(Config) error
+
+	mu             sync.Mutex
+	config         Config
+	connected      bool
+	tunnel         string
+	blockRules     []iptables.Rule
+	forwardRules   []iptables.Rule
+	restoreForward bool
+}
+
+// NewGateway creates gateway with given configuration, save persists configuration changed via Configure.
+func NewGateway(config Config, save func(Config) error) *Gateway {
+	return &Gateway{
+		supported: runtime.GOOS == "linux",
+		exec:      cmdutil.SudoExec,
+		output:    cmdutil.ExecOutput,
+		save:      save,
+		config:    config,
+	}
+}
+
+// Start sets up the gateway if it is enabled.
+func (g *Gateway) Start() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.setup()
+}
+
+// Stop removes all gateway rules, LAN traffic is no longer forwarded nor blocked.
+func (g *Gateway) Stop() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.teardown()
+}
+
+// Status returns current gateway configuration and forwarding status.
+func (g *Gateway) Status() Status {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return Status{Config: g.config, Forwarding: len(g.forwardRules) > 0}
+}
+
+// Configure replaces gateway configuration, rules are reapplied and configuration is persisted.
+func (g *Gateway) Configure(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if config.Enabled && !g.supported {
+		return ErrNotSupported
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if err := g.teardown(); err != nil {
+		log.Warn().Err(err).Msg("Failed to remove previous gateway rules")
+	}
+	g.config = config
+	if err := g.setup(); err != nil {
+		return err
+	}
+	return g.save(config)
+}
+
+// HandleConnectionEvent forwards LAN traffic while the main session is connected, other connections are ignored.
+func (g *Gateway) HandleConnectionEvent(e connection.AppEventConnectionState) {
+	if id := e.SessionInfo.ConnectionID; id != "" && id != connection.DefaultConnectionID {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.connected = e.State == connection.Connected
+	if g.connected && len(g.forwardRules) > 0 && e.SessionInfo.Interface == g.tunnel {
+		return
+	}
+	g.tunnel = e.SessionInfo.Interface
+	if !g.config.Enabled {
+		return
+	}
+
+	if len(g.forwardRules) > 0 {
+		g.removeRules(g.forwardRules)
+		g.forwardRules = nil
+		log.Info().Msgf("Stopped forwarding LAN %s traffic", g.config.Subnet)
+	}
+	if g.connected {
+		if err := g.forward(); err != nil {
+			log.Error().Err(err).Msg("Failed to forward LAN traffic through the session")
+		}
+	}
+}
+
+func (g *Gateway) setup() error {
+	if !g.config.Enabled {
+		return nil
+	}
+	if !g.supported {
+		return ErrNotSupported
+	}
+
+	if err := g.enableIPForward(); err != nil {
+		return err
+	}
+
+	// LAN traffic is blocked first, rules forwarding it during session are inserted before the blocking one.
+	rules, err := g.applyRules(blockRules(g.config))
+	if err != nil {
+		return err
+	}
+	g.blockRules = rules
+
+	if g.connected {
+		return g.forward()
+	}
+	return nil
+}
+
+func (g *Gateway) forward() error {
+	if g.tunnel == "" {
+		return errNoTunnelInterface
+	}
+
+	rules, err := g.applyRules(forwardRules(g.config, g.tunnel))
+	if err != nil {
+		return err
+	}
+	g.forwardRules = rules
+	log.Info().Msgf("Forwarding LAN %s traffic through the session interface %s", g.config.Subnet, g.tunnel)
+	return nil
+}
+
+func (g *Gateway) teardown() error {
+	g.removeRules(g.forwardRules)
+	g.removeRules(g.blockRules)
+	g.forwardRules, g.blockRules = nil, nil
+	return g.restoreIPForward()
+}
+
+func (g *Gateway) applyRules(rules []iptables.Rule) ([]iptables.Rule, error) {
+	var applied []iptables.Rule
+	for _, rule := range rules {
+		if err := g.iptables(rule.ApplyArgs()...); err != nil {
+			g.removeRules(applied)
+			return nil, err
+		}
+		applied = append(applied, rule)
+	}
+	return applied, nil
+}
+
+func (g *Gateway) removeRules(rules []iptables.Rule) {
+	for _, rule := range rules {
+		if err := g.iptables(rule.RemoveArgs()...); err != nil {
+			log.Warn().Err(err).Msgf("Error removing rule: %v you might wanna do it yourself", rule.RemoveArgs())
+		}
+	}
+}
+
+func (g *Gateway) iptables(args ...string) error {
+	return g.exec(append([]string{"/usr/sbin/iptables"}, args...)...)
+}
+
+func (g *Gateway) enableIPForward() error {
+	output, err := g.output("/sbin/sysctl", "-n", "net.ipv4.ip_forward")
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(output) == "1" {
+		return nil
+	}
+
+	if err := g.exec("/sbin/sysctl", "-w", "net.ipv4.ip_forward=1"); err != nil {
+		return err
+	}
+	g.restoreForward = true
+	return nil
+}
+
+func (g *Gateway) restoreIPForward() error {
+	if !g.restoreForward {
+		return nil
+	}
+
+	g.restoreForward = false
+	return g.exec("/sbin/sysctl", "-w", "net.ipv4.ip_forward=0")
+}
+
+func blockRules(config Config) []iptables.Rule {
+	return []iptables.Rule{
+		iptables.InsertAt(chainForward, 1).RuleSpec(
+			"--in-interface", config.Interface, "--source", config.Subnet,
+			"--match", "comment", "--comment", ruleComment,
+			"--jump", "DROP"),
+	}
+}
+
+// forwardRules are inserted on top of the blocking rule, the last one ends up first and drops LAN traffic
+// which is about to leave through any other interface than the tunnel.
+func forwardRules(config Config, tunnel string) []iptables.Rule {
+	return []iptables.Rule{
+		iptables.InsertAt(chainForward, 1).RuleSpec(
+			"--in-interface", config.Interface, "--source", config.Subnet, "--out-interface", tunnel,
+			"--match", "comment", "--comment", ruleComment,
+			"--jump", "ACCEPT"),
+		iptables.InsertAt(chainForward, 1).RuleSpec(
+			"--in-interface", tunnel, "--out-interface", config.Interface, "--destination", config.Subnet,
+			"--match", "conntrack", "--ctstate", "RELATED,ESTABLISHED",
+			"--match", "comment", "--comment", ruleComment,
+			"--jump", "ACCEPT"),
+		iptables.InsertAt(chainForward, 1).RuleSpec(
+			"--in-interface", config.Interface, "--source", config.Subnet, "!", "--out-interface", tunnel,
+			"--match", "comment", "--comment", ruleComment,
+			"--jump", "DROP"),
+		iptables.AppendTo(chainPostRouting).RuleSpec(
+			"--source", config.Subnet, "--out-interface", tunnel,
+			"--match", "comment", "--comment", ruleComment,
+			"--jump", "MASQUERADE",
+			"--table", "nat"),
+	}
+}
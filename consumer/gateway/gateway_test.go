@@ -0,0 +1,259 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package gateway
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockExec struct {
+	commands   []string
+	ipForward  string
+	failOnArgs string
+}
+
+func (m *mockExec) exec(args ...string) error {
+	command := strings.Join(args, " ")
+	if m.failOnArgs != "" && strings.Contains(command, m.failOnArgs) {
+		return errors.New("command failed")
+	}
+	m.commands = append(m.commands, command)
+	return nil
+}
+
+func (m *mockExec) output(args ...string) (string, error) {
+	return m.ipForward + "\n", nil
+}
+
+func newTestGateway(config Config, cmd *mockExec) (*Gateway, *[]Config) {
+	var saved []Config
+	gateway := NewGateway(config, func(config Config) error {
+		saved = append(saved, config)
+		return nil
+	})
+	gateway.supported = true
+	gateway.exec = cmd.exec
+	gateway.output = cmd.output
+	return gateway, &saved
+}
+
+var lanConfig = Config{Enabled: true, Interface: "eth0", Subnet: "192.168.8.0/24"}
+
+var connectedEvent = connection.AppEventConnectionState{
+	State:       connection.Connected,
+	SessionInfo: connection.Status{ConnectionID: connection.DefaultConnectionID, Interface: "myst0"},
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, lanConfig.Validate())
+	assert.Error(t, Config{Enabled: true, Subnet: "192.168.8.0/24"}.Validate())
+	assert.Error(t, Config{Enabled: true, Interface: "eth0", Subnet: "192.168.8.1"}.Validate())
+	assert.Error(t, Config{Enabled: true, Interface: "eth0", Subnet: "fd00::/64"}.Validate())
+}
+
+func TestGateway_StartBlocksLANTrafficUntilConnected(t *testing.T) {
+	// given
+	cmd := &mockExec{ipForward: "0"}
+	gateway, _ := newTestGateway(lanConfig, cmd)
+
+	// when
+	err := gateway.Start()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/sbin/sysctl -w net.ipv4.ip_forward=1",
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface eth0 --source 192.168.8.0/24 --match comment --comment myst-gateway --jump DROP",
+	}, cmd.commands)
+	assert.False(t, gateway.Status().Forwarding)
+}
+
+func TestGateway_ForwardsLANTrafficWhileConnected(t *testing.T) {
+	// given
+	cmd := &mockExec{ipForward: "1"}
+	gateway, _ := newTestGateway(lanConfig, cmd)
+	assert.NoError(t, gateway.Start())
+	cmd.commands = nil
+
+	// when
+	gateway.HandleConnectionEvent(connectedEvent)
+
+	// then
+	assert.Equal(t, []string{
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface eth0 --source 192.168.8.0/24 --out-interface myst0 --match comment --comment myst-gateway --jump ACCEPT",
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface myst0 --out-interface eth0 --destination 192.168.8.0/24 --match conntrack --ctstate RELATED,ESTABLISHED --match comment --comment myst-gateway --jump ACCEPT",
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface eth0 --source 192.168.8.0/24 ! --out-interface myst0 --match comment --comment myst-gateway --jump DROP",
+		"/usr/sbin/iptables -A POSTROUTING --source 192.168.8.0/24 --out-interface myst0 --match comment --comment myst-gateway --jump MASQUERADE --table nat",
+	}, cmd.commands)
+	assert.True(t, gateway.Status().Forwarding)
+
+	// when
+	cmd.commands = nil
+	gateway.HandleConnectionEvent(connectedEvent)
+
+	// then
+	assert.Empty(t, cmd.commands, "repeated event of the same tunnel changes nothing")
+
+	// when
+	gateway.HandleConnectionEvent(connection.AppEventConnectionState{State: connection.Reconnecting})
+
+	// then
+	assert.Len(t, cmd.commands, 4)
+	for _, command := range cmd.commands {
+		assert.True(t, strings.HasPrefix(command, "/usr/sbin/iptables -D "), command)
+	}
+	assert.False(t, gateway.Status().Forwarding)
+}
+
+func TestGateway_DisabledGatewayIgnoresConnection(t *testing.T) {
+	// given
+	cmd := &mockExec{ipForward: "0"}
+	gateway, _ := newTestGateway(Config{}, cmd)
+
+	// when
+	assert.NoError(t, gateway.Start())
+	gateway.HandleConnectionEvent(connectedEvent)
+
+	// then
+	assert.Empty(t, cmd.commands)
+	assert.False(t, gateway.Status().Forwarding)
+}
+
+func TestGateway_ConfigureReappliesRulesAndSavesConfig(t *testing.T) {
+	// given
+	cmd := &mockExec{ipForward: "0"}
+	gateway, saved := newTestGateway(Config{}, cmd)
+	gateway.HandleConnectionEvent(connectedEvent)
+
+	// when
+	err := gateway.Configure(lanConfig)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []Config{lanConfig}, *saved)
+	assert.Equal(t, Status{Config: lanConfig, Forwarding: true}, gateway.Status())
+
+	// when
+	cmd.commands = nil
+	err = gateway.Configure(Config{})
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, cmd.commands, 6)
+	assert.Equal(t, "/sbin/sysctl -w net.ipv4.ip_forward=0", cmd.commands[5])
+	assert.Equal(t, Status{}, gateway.Status())
+}
+
+func TestGateway_ConfigureRejectsInvalidConfig(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	gateway, saved := newTestGateway(Config{}, cmd)
+
+	// when
+	err := gateway.Configure(Config{Enabled: true, Interface: "eth0", Subnet: "lan"})
+
+	// then
+	assert.Error(t, err)
+	assert.Empty(t, *saved)
+	assert.Empty(t, cmd.commands)
+}
+
+func TestGateway_FailedRuleIsRolledBack(t *testing.T) {
+	// given
+	cmd := &mockExec{ipForward: "1", failOnArgs: "MASQUERADE"}
+	gateway, _ := newTestGateway(lanConfig, cmd)
+	assert.NoError(t, gateway.Start())
+	cmd.commands = nil
+
+	// when
+	gateway.HandleConnectionEvent(connectedEvent)
+
+	// then
+	assert.Equal(t, []string{
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface eth0 --source 192.168.8.0/24 --out-interface myst0 --match comment --comment myst-gateway --jump ACCEPT",
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface myst0 --out-interface eth0 --destination 192.168.8.0/24 --match conntrack --ctstate RELATED,ESTABLISHED --match comment --comment myst-gateway --jump ACCEPT",
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface eth0 --source 192.168.8.0/24 ! --out-interface myst0 --match comment --comment myst-gateway --jump DROP",
+		"/usr/sbin/iptables -D FORWARD --in-interface eth0 --source 192.168.8.0/24 --out-interface myst0 --match comment --comment myst-gateway --jump ACCEPT",
+		"/usr/sbin/iptables -D FORWARD --in-interface myst0 --out-interface eth0 --destination 192.168.8.0/24 --match conntrack --ctstate RELATED,ESTABLISHED --match comment --comment myst-gateway --jump ACCEPT",
+		"/usr/sbin/iptables -D FORWARD --in-interface eth0 --source 192.168.8.0/24 ! --out-interface myst0 --match comment --comment myst-gateway --jump DROP",
+	}, cmd.commands)
+	assert.False(t, gateway.Status().Forwarding)
+}
+
+func TestGateway_IgnoresOtherConnections(t *testing.T) {
+	// given
+	cmd := &mockExec{ipForward: "1"}
+	gateway, _ := newTestGateway(lanConfig, cmd)
+	assert.NoError(t, gateway.Start())
+	cmd.commands = nil
+
+	// when
+	gateway.HandleConnectionEvent(connection.AppEventConnectionState{
+		State:       connection.Connected,
+		SessionInfo: connection.Status{ConnectionID: "isolated-0", Interface: "myst1"},
+	})
+
+	// then
+	assert.Empty(t, cmd.commands)
+	assert.False(t, gateway.Status().Forwarding)
+}
+
+func TestGateway_DoesNotForwardWithoutTunnelInterface(t *testing.T) {
+	// given
+	cmd := &mockExec{ipForward: "1"}
+	gateway, _ := newTestGateway(lanConfig, cmd)
+	assert.NoError(t, gateway.Start())
+	cmd.commands = nil
+
+	// when
+	gateway.HandleConnectionEvent(connection.AppEventConnectionState{State: connection.Connected})
+
+	// then
+	assert.Empty(t, cmd.commands)
+	assert.False(t, gateway.Status().Forwarding)
+}
+
+func TestGateway_RepinsRulesWhenTunnelInterfaceChanges(t *testing.T) {
+	// given
+	cmd := &mockExec{ipForward: "1"}
+	gateway, _ := newTestGateway(lanConfig, cmd)
+	assert.NoError(t, gateway.Start())
+	gateway.HandleConnectionEvent(connectedEvent)
+	cmd.commands = nil
+
+	// when
+	event := connectedEvent
+	event.SessionInfo.Interface = "myst1"
+	gateway.HandleConnectionEvent(event)
+
+	// then
+	assert.Len(t, cmd.commands, 8)
+	for _, command := range cmd.commands[:4] {
+		assert.Contains(t, command, "myst0")
+	}
+	for _, command := range cmd.commands[4:] {
+		assert.Contains(t, command, "myst1")
+	}
+	assert.True(t, gateway.Status().Forwarding)
+}
@@ -0,0 +1,300 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package isolation
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/consumer/proxy"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/utils"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrNotSupported indicates that named connections can not be established on this OS.
+	ErrNotSupported = errors.New("named connections are supported on Linux only")
+	// ErrTooManyConnections indicates that all routing tables and proxy ports of named connections are taken.
+	ErrTooManyConnections = errors.New("maximum number of named connections reached")
+	// ErrInvalidConnectionID indicates that connection ID has characters other than letters, digits, dashes and underscores or is too long.
+	ErrInvalidConnectionID = errors.New("connection ID must be 1-32 letters, digits, dashes or underscores")
+)
+
+var connectionIDPattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,32}$`)
+
+// Config describes resources allocated for named connections, N-th slot gets N-th proxy port and routing table.
+type Config struct {
+	// ProxyHost is local address proxies of named connections listen on.
+	ProxyHost string
+	// FirstProxyPort is SOCKS5/HTTP proxy port of the first slot.
+	FirstProxyPort int
+	// FirstRoutingTable is routing table and firewall mark of the first slot.
+	FirstRoutingTable int
+	// MaxConnections limits the number of simultaneous named connections, they are disabled if zero.
+	MaxConnections int
+}
+
+// ManagerFactory creates an independent connection manager for every named connection, named by the given ID.
+type ManagerFactory func(id string) connection.Manager
+
+type proxyServer interface {
+	Start() error
+	Stop() error
+}
+
+type slot struct {
+	id           int
+	proxy        proxyServer
+	routingTable int
+	proxyAddress string
+}
+
+// Pool keeps multiple named connections simultaneously next to the main one, each of them has its own manager
+// with separate state, statistics and payments. Every named connection routes traffic in its own routing table
+// selected by firewall mark, which only its local proxy tags upstream connections with, so named connections
+// never take over the routes of the host.
+type Pool struct {
+	config     Config
+	supported  bool
+	main       connection.Manager
+	newManager ManagerFactory
+	newProxy   func(address string, mark int, active func() bool) proxyServer
+
+	mu       sync.Mutex
+	managers map[string]*namedManager
+	slots    map[int]*namedManager
+}
+
+// NewPool creates a new pool of named connections, `main` manages the default connection.
+func NewPool(config Config, main connection.Manager, newManager ManagerFactory) *Pool {
+	return &Pool{
+		config:     config,
+		supported:  runtime.GOOS == "linux",
+		main:       main,
+		newManager: newManager,
+		newProxy: func(address string, mark int, active func() bool) proxyServer {
+			return proxy.NewMarkedProxy(address, mark, active)
+		},
+		managers: make(map[string]*namedManager),
+		slots:    make(map[int]*namedManager),
+	}
+}
+
+// Manager returns manager of the connection with given ID, it is created on the first use.
+// Empty ID stands for the default connection.
+func (p *Pool) Manager(id string) (connection.Manager, error) {
+	if id == "" || id == connection.DefaultConnectionID {
+		return p.main, nil
+	}
+	if !connectionIDPattern.MatchString(id) {
+		return nil, ErrInvalidConnectionID
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	manager, ok := p.managers[id]
+	if !ok {
+		manager = &namedManager{Manager: p.newManager(id), pool: p}
+		p.managers[id] = manager
+	}
+	return manager, nil
+}
+
+// Lookup returns manager of the connection with given ID if it was ever used.
+// Empty ID stands for the default connection.
+func (p *Pool) Lookup(id string) (connection.Manager, bool) {
+	if id == "" || id == connection.DefaultConnectionID {
+		return p.main, true
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	manager, ok := p.managers[id]
+	return manager, ok
+}
+
+// Statuses returns statuses of the default connection and all named connections which are not idle, ordered by ID.
+func (p *Pool) Statuses() []connection.Status {
+	status := p.main.Status()
+	status.ConnectionID = connection.DefaultConnectionID
+	statuses := []connection.Status{status}
+
+	for id, manager := range p.namedManagers() {
+		status := manager.Status()
+		if status.State == connection.NotConnected {
+			continue
+		}
+		status.ConnectionID = id
+		statuses = append(statuses, status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].ConnectionID < statuses[j].ConnectionID
+	})
+	return statuses
+}
+
+// Stop disconnects all named connections, the default one is left to its owner.
+func (p *Pool) Stop() error {
+	errs := utils.ErrorCollection{}
+	for _, manager := range p.namedManagers() {
+		if err := manager.Disconnect(); err != nil && err != connection.ErrNoConnection {
+			errs.Add(err)
+		}
+	}
+	return errs.Errorf("some named connections did not stop: %s", ", ")
+}
+
+// namedManagers copies managers of named connections, so that they are not called under the lock of the pool.
+func (p *Pool) namedManagers() map[string]*namedManager {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	managers := make(map[string]*namedManager, len(p.managers))
+	for id, manager := range p.managers {
+		managers[id] = manager
+	}
+	return managers
+}
+
+// allocate reserves the first free slot for the manager and starts its proxy, so that the slot
+// can be reached while the session is being connected.
+func (p *Pool) allocate(manager *namedManager) (*slot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for id := 0; id < p.config.MaxConnections; id++ {
+		if _, taken := p.slots[id]; taken {
+			continue
+		}
+		s := &slot{
+			id:           id,
+			routingTable: p.config.FirstRoutingTable + id,
+			proxyAddress: net.JoinHostPort(p.config.ProxyHost, strconv.Itoa(p.config.FirstProxyPort+id)),
+		}
+		s.proxy = p.newProxy(s.proxyAddress, s.routingTable, func() bool {
+			return manager.Manager.Status().State == connection.Connected
+		})
+		if err := s.proxy.Start(); err != nil {
+			return nil, fmt.Errorf("could not start proxy of named connection: %w", err)
+		}
+		p.slots[id] = manager
+		return s, nil
+	}
+	return nil, ErrTooManyConnections
+}
+
+// release frees the slot and stops its proxy.
+func (p *Pool) release(s *slot) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.slots, s.id)
+	if err := s.proxy.Stop(); err != nil {
+		log.Warn().Err(err).Msgf("Could not stop proxy on %s", s.proxyAddress)
+	}
+}
+
+// namedManager holds a slot of the pool while its connection is up, the slot is released on disconnect.
+type namedManager struct {
+	connection.Manager
+	pool *Pool
+
+	mu   sync.Mutex
+	slot *slot
+}
+
+// Connect establishes named connection routed in the routing table of its slot.
+// It blocks until the session is connected or failed.
+func (m *namedManager) Connect(consumerID identity.Identity, accountantID common.Address, proposal market.ServiceProposal, params connection.ConnectParams) error {
+	if !m.pool.supported {
+		return ErrNotSupported
+	}
+
+	m.mu.Lock()
+	if m.slot != nil {
+		m.mu.Unlock()
+		return connection.ErrAlreadyExists
+	}
+	s, err := m.pool.allocate(m)
+	if err != nil {
+		m.mu.Unlock()
+		return err
+	}
+	m.slot = s
+	m.mu.Unlock()
+
+	params.RoutingTable = s.routingTable
+	if err := m.Manager.Connect(consumerID, accountantID, proposal, params); err != nil {
+		m.releaseSlot(s)
+		return err
+	}
+
+	log.Info().Msgf("Named connection is served by proxy on %s", s.proxyAddress)
+	return nil
+}
+
+// Disconnect disconnects named connection and releases its slot.
+func (m *namedManager) Disconnect() error {
+	m.mu.Lock()
+	s := m.slot
+	m.mu.Unlock()
+
+	err := m.Manager.Disconnect()
+	if s != nil {
+		m.releaseSlot(s)
+	}
+	return err
+}
+
+// Status returns status of named connection along with the routing table and proxy serving it.
+func (m *namedManager) Status() connection.Status {
+	status := m.Manager.Status()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.slot != nil {
+		status.RoutingTable = m.slot.routingTable
+		status.ProxyAddress = m.slot.proxyAddress
+	}
+	return status
+}
+
+// releaseSlot releases the slot unless it was already released by concurrent disconnect.
+func (m *namedManager) releaseSlot(s *slot) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.slot != s {
+		return
+	}
+	m.slot = nil
+	m.pool.release(s)
+}
@@ -0,0 +1,290 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package isolation
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConnectionManager struct {
+	id           string
+	connectErr   error
+	params       connection.ConnectParams
+	state        connection.State
+	disconnected bool
+}
+
+func (m *mockConnectionManager) Connect(_ identity.Identity, _ common.Address, _ market.ServiceProposal, params connection.ConnectParams) error {
+	m.params = params
+	if m.connectErr != nil {
+		return m.connectErr
+	}
+	m.state = connection.Connected
+	return nil
+}
+
+func (m *mockConnectionManager) Status() connection.Status {
+	return connection.Status{ConnectionID: m.id, State: m.state}
+}
+
+func (m *mockConnectionManager) Disconnect() error {
+	m.disconnected = true
+	m.state = connection.NotConnected
+	return nil
+}
+
+func (m *mockConnectionManager) CheckChannel(context.Context) error {
+	return nil
+}
+
+func (m *mockConnectionManager) SpeedTest(context.Context, connection.SpeedTestTarget) (connection.SpeedTestResult, error) {
+	return connection.SpeedTestResult{}, nil
+}
+
+func (m *mockConnectionManager) ExportProfile() ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockConnectionManager) Dial(network, address string) (net.Conn, error) {
+	return nil, nil
+}
+
+type mockProxy struct {
+	address string
+	mark    int
+	active  func() bool
+	started bool
+	stopped bool
+}
+
+func (m *mockProxy) Start() error {
+	m.started = true
+	return nil
+}
+
+func (m *mockProxy) Stop() error {
+	m.stopped = true
+	return nil
+}
+
+type poolFixture struct {
+	pool     *Pool
+	main     *mockConnectionManager
+	managers map[string]*mockConnectionManager
+	proxies  []*mockProxy
+}
+
+func newPoolFixture(connectErr error) *poolFixture {
+	f := &poolFixture{
+		main:     &mockConnectionManager{id: connection.DefaultConnectionID, state: connection.NotConnected},
+		managers: make(map[string]*mockConnectionManager),
+	}
+	config := Config{ProxyHost: "127.0.0.1", FirstProxyPort: 40000, FirstRoutingTable: 100, MaxConnections: 2}
+	f.pool = NewPool(config, f.main, func(id string) connection.Manager {
+		manager := &mockConnectionManager{id: id, connectErr: connectErr, state: connection.NotConnected}
+		f.managers[id] = manager
+		return manager
+	})
+	f.pool.supported = true
+	f.pool.newProxy = func(address string, mark int, active func() bool) proxyServer {
+		proxy := &mockProxy{address: address, mark: mark, active: active}
+		f.proxies = append(f.proxies, proxy)
+		return proxy
+	}
+	return f
+}
+
+func (f *poolFixture) connect(id string) (connection.Manager, error) {
+	manager, err := f.pool.Manager(id)
+	if err != nil {
+		return nil, err
+	}
+	return manager, manager.Connect(identity.FromAddress("0x1"), common.Address{}, market.ServiceProposal{}, connection.ConnectParams{})
+}
+
+func TestPool_ManagerReturnsMainForDefaultID(t *testing.T) {
+	f := newPoolFixture(nil)
+
+	for _, id := range []string{"", connection.DefaultConnectionID} {
+		manager, err := f.pool.Manager(id)
+		assert.NoError(t, err)
+		assert.Equal(t, f.main, manager)
+
+		manager, ok := f.pool.Lookup(id)
+		assert.True(t, ok)
+		assert.Equal(t, f.main, manager)
+	}
+}
+
+func TestPool_ManagerCreatesNamedManagerOnce(t *testing.T) {
+	f := newPoolFixture(nil)
+
+	_, ok := f.pool.Lookup("work")
+	assert.False(t, ok)
+
+	first, err := f.pool.Manager("work")
+	assert.NoError(t, err)
+	second, err := f.pool.Manager("work")
+	assert.NoError(t, err)
+	assert.Same(t, first, second)
+	assert.Len(t, f.managers, 1)
+	assert.Equal(t, "work", first.Status().ConnectionID)
+
+	found, ok := f.pool.Lookup("work")
+	assert.True(t, ok)
+	assert.Same(t, first, found)
+}
+
+func TestPool_ManagerRejectsInvalidID(t *testing.T) {
+	f := newPoolFixture(nil)
+
+	for _, id := range []string{"with space", "../etc", "averyveryveryveryveryverylongname1"} {
+		_, err := f.pool.Manager(id)
+		assert.Equal(t, ErrInvalidConnectionID, err, id)
+	}
+	assert.Empty(t, f.managers)
+}
+
+func TestPool_ConnectAllocatesSeparateRoutingTableAndProxy(t *testing.T) {
+	// given
+	f := newPoolFixture(nil)
+
+	// when
+	first, err := f.connect("first")
+	assert.NoError(t, err)
+	second, err := f.connect("second")
+	assert.NoError(t, err)
+
+	// then
+	assert.Equal(t, connection.Status{ConnectionID: "first", State: connection.Connected, RoutingTable: 100, ProxyAddress: "127.0.0.1:40000"}, first.Status())
+	assert.Equal(t, connection.Status{ConnectionID: "second", State: connection.Connected, RoutingTable: 101, ProxyAddress: "127.0.0.1:40001"}, second.Status())
+	assert.Equal(t, 100, f.managers["first"].params.RoutingTable)
+	assert.Equal(t, 101, f.managers["second"].params.RoutingTable)
+	assert.Equal(t, 101, f.proxies[1].mark)
+	assert.True(t, f.proxies[1].started)
+	assert.True(t, f.proxies[1].active())
+	assert.Equal(t, []connection.Status{
+		{ConnectionID: connection.DefaultConnectionID, State: connection.NotConnected},
+		first.Status(),
+		second.Status(),
+	}, f.pool.Statuses())
+}
+
+func TestPool_ConnectFailsWhenAllSlotsAreTaken(t *testing.T) {
+	// given
+	f := newPoolFixture(nil)
+	for _, id := range []string{"first", "second"} {
+		_, err := f.connect(id)
+		assert.NoError(t, err)
+	}
+
+	// when
+	_, err := f.connect("third")
+
+	// then
+	assert.Equal(t, ErrTooManyConnections, err)
+	assert.Len(t, f.proxies, 2)
+}
+
+func TestPool_ConnectFailsWhenAlreadyConnected(t *testing.T) {
+	// given
+	f := newPoolFixture(nil)
+	_, err := f.connect("work")
+	assert.NoError(t, err)
+
+	// when
+	_, err = f.connect("work")
+
+	// then
+	assert.Equal(t, connection.ErrAlreadyExists, err)
+	assert.Len(t, f.proxies, 1)
+	assert.False(t, f.proxies[0].stopped)
+}
+
+func TestPool_ConnectFailureReleasesSlot(t *testing.T) {
+	// given
+	f := newPoolFixture(errors.New("boom"))
+
+	// when
+	manager, err := f.connect("work")
+
+	// then
+	assert.EqualError(t, err, "boom")
+	assert.True(t, f.proxies[0].stopped)
+	assert.Equal(t, connection.Status{ConnectionID: "work", State: connection.NotConnected}, manager.Status())
+	assert.Equal(t, []connection.Status{{ConnectionID: connection.DefaultConnectionID, State: connection.NotConnected}}, f.pool.Statuses())
+}
+
+func TestPool_DisconnectReleasesSlot(t *testing.T) {
+	// given
+	f := newPoolFixture(nil)
+	manager, err := f.connect("first")
+	assert.NoError(t, err)
+
+	// when
+	err = manager.Disconnect()
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, f.managers["first"].disconnected)
+	assert.True(t, f.proxies[0].stopped)
+	assert.False(t, f.proxies[0].active())
+
+	_, err = f.connect("second")
+	assert.NoError(t, err)
+	assert.Equal(t, 100, f.managers["second"].params.RoutingTable)
+}
+
+func TestPool_StopDisconnectsNamedConnectionsOnly(t *testing.T) {
+	// given
+	f := newPoolFixture(nil)
+	f.main.state = connection.Connected
+	_, err := f.connect("work")
+	assert.NoError(t, err)
+
+	// when
+	err = f.pool.Stop()
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, f.managers["work"].disconnected)
+	assert.True(t, f.proxies[0].stopped)
+	assert.False(t, f.main.disconnected)
+}
+
+func TestPool_ConnectIsNotSupported(t *testing.T) {
+	// given
+	f := newPoolFixture(nil)
+	f.pool.supported = false
+
+	// when
+	_, err := f.connect("work")
+
+	// then
+	assert.Equal(t, ErrNotSupported, err)
+	assert.Empty(t, f.proxies)
+}
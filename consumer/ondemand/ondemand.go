@@ -0,0 +1,384 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ondemand
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNotSupported indicates that on-demand mode can not be enabled on this OS.
+var ErrNotSupported = errors.New("on-demand mode is supported on Linux only")
+
+const (
+	chainOutput  = "OUTPUT"
+	chainForward = "FORWARD"
+
+	ruleComment = "myst-ondemand"
+)
+
+// Config describes traffic triggering the connection and the connection made for it.
+type Config struct {
+	Enabled bool
+	// ConsumerID is the identity connections are made with.
+	ConsumerID string
+	// Country is the code of a country to connect to.
+	Country string
+	// ServiceType is the type of service to connect to, any type if empty.
+	ServiceType string
+	// Destinations are IPs or networks in CIDR notation, outbound traffic to them triggers the connection.
+	Destinations []string
+	// Interface is virtual network interface, e.g. of VMs or containers, any traffic forwarded from it triggers the connection.
+	Interface string
+	// IdleTimeout disconnects the connection once the watched traffic stops for this period, zero keeps the connection.
+	IdleTimeout time.Duration
+}
+
+// Validate checks that enabled on-demand mode has the connection and the traffic to watch described.
+func (c Config) Validate() error {
+	for _, destination := range c.Destinations {
+		if _, err := parseDestination(destination); err != nil {
+			return err
+		}
+	}
+	if c.IdleTimeout < 0 {
+		return errors.New("idle timeout can not be negative")
+	}
+	if !c.Enabled {
+		return nil
+	}
+	if c.ConsumerID == "" {
+		return errors.New("consumer identity is required")
+	}
+	if len(c.Country) != 2 {
+		return fmt.Errorf("invalid country code %q", c.Country)
+	}
+	if len(c.Destinations) == 0 && c.Interface == "" {
+		return errors.New("destinations or interface to watch are required")
+	}
+	return nil
+}
+
+// Status describes on-demand configuration and the state of connection made for the watched traffic.
+type Status struct {
+	Config
+	// Connected is set while connection made on demand is up.
+	Connected bool
+	// LastActivity is the last time watched traffic was seen.
+	LastActivity time.Time
+	// LastError is the reason the last on-demand connection attempt failed, empty if it succeeded.
+	LastError string
+}
+
+type connectionManager interface {
+	Status() connection.Status
+	Disconnect() error
+}
+
+type countryConnector interface {
+	Connect(consumerID identity.Identity, accountantID common.Address, country, serviceType string, params connection.ConnectParams) (market.ServiceProposal, error)
+}
+
+type accountantPicker interface {
+	Pick() (common.Address, error)
+}
+
+// OnDemand watches outbound traffic with packet counting firewall rules and connects the main connection
+// once the watched traffic appears while there is no connection. Connection made on demand is disconnected
+// after the watched traffic stays idle, connections made by the user are never interrupted.
+type OnDemand struct {
+	supported   bool
+	exec        func(args ...string) error
+	output      func(args ...string) (string, error)
+	manager     connectionManager
+	connector   countryConnector
+	accountants accountantPicker
+	save        func(Config) error
+	interval    time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	config Config
+	status Status
+	rules  []iptables.Rule
+	// packets is the last count of watched packets, sessionID is of the connection made on demand.
+	packets   uint64
+	sessionID session.ID
+
+	done chan struct{}
+	once sync.Once
+}
+
+// NewOnDemand creates on-demand mode checking watched traffic every interval, save persists configuration changed via Configure.
+func NewOnDemand(config Config, manager connectionManager, connector countryConnector, accountants accountantPicker,
+	interval time.Duration, save func(Config) error) *OnDemand {
+	return &OnDemand{
+		supported:   runtime.GOOS == "linux",
+		exec:        cmdutil.SudoExec,
+		output:      cmdutil.ExecOutput,
+		manager:     manager,
+		connector:   connector,
+		accountants: accountants,
+		save:        save,
+		interval:    interval,
+		now:         time.Now,
+		config:      config,
+		status:      Status{Config: config},
+		done:        make(chan struct{}),
+	}
+}
+
+// Start sets up watching of the traffic if on-demand mode is enabled and starts checking it in background.
+func (o *OnDemand) Start() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if err := o.setup(); err != nil {
+		return err
+	}
+	go o.run()
+	return nil
+}
+
+// Stop stops watching the traffic, the connection is left as it is.
+func (o *OnDemand) Stop() error {
+	o.once.Do(func() {
+		close(o.done)
+	})
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.teardown()
+	return nil
+}
+
+// Status returns on-demand configuration and the state of connection made on demand.
+func (o *OnDemand) Status() Status {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	return o.status
+}
+
+// Configure replaces on-demand configuration, watching rules are reapplied and configuration is persisted.
+func (o *OnDemand) Configure(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+	if config.Enabled && !o.supported {
+		return ErrNotSupported
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.teardown()
+	o.config = config
+	o.status.Config = config
+	if err := o.setup(); err != nil {
+		return err
+	}
+	return o.save(config)
+}
+
+func (o *OnDemand) run() {
+	ticker := time.NewTicker(o.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			o.check()
+		case <-o.done:
+			return
+		}
+	}
+}
+
+// check connects once the watched traffic appears and disconnects connection made on demand once it stays idle.
+func (o *OnDemand) check() {
+	o.mu.Lock()
+	config := o.config
+	active := false
+	if config.Enabled {
+		packets, err := o.countPackets()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to count on-demand traffic")
+		}
+		active = err == nil && packets > o.packets
+		o.packets = packets
+	}
+	if active {
+		o.status.LastActivity = o.now()
+	}
+	lastActivity := o.status.LastActivity
+	o.mu.Unlock()
+
+	current := o.manager.Status()
+	owned := o.sessionID != "" && current.SessionID == o.sessionID && current.State != connection.NotConnected
+	if !owned {
+		o.sessionID = ""
+	}
+
+	if owned && config.IdleTimeout > 0 && o.now().Sub(lastActivity) >= config.IdleTimeout {
+		log.Info().Msgf("No on-demand traffic for %s, disconnecting", config.IdleTimeout)
+		if err := o.manager.Disconnect(); err != nil {
+			log.Warn().Err(err).Msg("Failed to disconnect on-demand connection")
+		}
+		o.sessionID = ""
+	}
+
+	lastError := ""
+	if active && !owned && current.State == connection.NotConnected {
+		log.Info().Msgf("On-demand traffic detected, connecting to %s", config.Country)
+		if err := o.connect(config); err != nil {
+			log.Warn().Err(err).Msg("Failed to connect on demand")
+			lastError = err.Error()
+		} else {
+			o.sessionID = o.manager.Status().SessionID
+		}
+	}
+
+	o.mu.Lock()
+	o.status.Connected = o.sessionID != ""
+	if active || lastError != "" {
+		o.status.LastError = lastError
+	}
+	o.mu.Unlock()
+}
+
+func (o *OnDemand) connect(config Config) error {
+	accountant, err := o.accountants.Pick()
+	if err != nil {
+		return err
+	}
+	_, err = o.connector.Connect(identity.FromAddress(config.ConsumerID), accountant, config.Country, config.ServiceType, connection.ConnectParams{
+		DNS: connection.DNSOptionAuto,
+	})
+	return err
+}
+
+func (o *OnDemand) setup() error {
+	if !o.config.Enabled {
+		return nil
+	}
+	if !o.supported {
+		return ErrNotSupported
+	}
+
+	for _, rule := range watchRules(o.config) {
+		if err := o.iptables(rule.ApplyArgs()...); err != nil {
+			o.teardown()
+			return err
+		}
+		o.rules = append(o.rules, rule)
+	}
+
+	packets, err := o.countPackets()
+	if err != nil {
+		o.teardown()
+		return err
+	}
+	o.packets = packets
+	log.Info().Msgf("Watching on-demand traffic of %d rules", len(o.rules))
+	return nil
+}
+
+func (o *OnDemand) teardown() {
+	for _, rule := range o.rules {
+		if err := o.iptables(rule.RemoveArgs()...); err != nil {
+			log.Warn().Err(err).Msgf("Error removing rule: %v you might wanna do it yourself", rule.RemoveArgs())
+		}
+	}
+	o.rules = nil
+	o.packets = 0
+}
+
+// countPackets sums packet counters of the watching rules.
+func (o *OnDemand) countPackets() (uint64, error) {
+	var total uint64
+	for _, chain := range []string{chainOutput, chainForward} {
+		output, err := o.output("sudo", "/usr/sbin/iptables", "--list", chain, "--verbose", "--exact", "--numeric")
+		if err != nil {
+			return 0, err
+		}
+		for _, line := range strings.Split(output, "\n") {
+			if !strings.Contains(line, "/* "+ruleComment+" */") {
+				continue
+			}
+			fields := strings.Fields(line)
+			if len(fields) == 0 {
+				continue
+			}
+			packets, err := strconv.ParseUint(fields[0], 10, 64)
+			if err != nil {
+				return 0, fmt.Errorf("could not parse packet counter of %q: %w", line, err)
+			}
+			total += packets
+		}
+	}
+	return total, nil
+}
+
+func (o *OnDemand) iptables(args ...string) error {
+	return o.exec(append([]string{"/usr/sbin/iptables"}, args...)...)
+}
+
+// watchRules only count packets, the traffic keeps passing to the following rules.
+func watchRules(config Config) []iptables.Rule {
+	var rules []iptables.Rule
+	for _, destination := range config.Destinations {
+		network, _ := parseDestination(destination)
+		rules = append(rules, iptables.InsertAt(chainOutput, 1).RuleSpec(
+			"--destination", network,
+			"--match", "comment", "--comment", ruleComment))
+	}
+	if config.Interface != "" {
+		rules = append(rules, iptables.InsertAt(chainForward, 1).RuleSpec(
+			"--in-interface", config.Interface,
+			"--match", "comment", "--comment", ruleComment))
+	}
+	return rules
+}
+
+// parseDestination parses IPv4 address or network, address is returned as a single host network.
+func parseDestination(destination string) (string, error) {
+	if ip := net.ParseIP(destination); ip != nil && ip.To4() != nil {
+		return destination + "/32", nil
+	}
+	if ip, network, err := net.ParseCIDR(destination); err == nil && ip.To4() != nil {
+		return network.String(), nil
+	}
+	return "", fmt.Errorf("invalid destination %q, IPv4 address or network in CIDR notation expected", destination)
+}
@@ -0,0 +1,339 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package ondemand
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockExec struct {
+	commands   []string
+	packets    int
+	failOnArgs string
+}
+
+func (m *mockExec) exec(args ...string) error {
+	command := strings.Join(args, " ")
+	if m.failOnArgs != "" && strings.Contains(command, m.failOnArgs) {
+		return errors.New("command failed")
+	}
+	m.commands = append(m.commands, command)
+	return nil
+}
+
+func (m *mockExec) output(args ...string) (string, error) {
+	if strings.Contains(strings.Join(args, " "), chainForward) {
+		return "Chain FORWARD (policy ACCEPT 0 packets, 0 bytes)\n", nil
+	}
+	return fmt.Sprintf(`Chain OUTPUT (policy ACCEPT 0 packets, 0 bytes)
+    pkts      bytes target     prot opt in     out     source               destination
+%8d     1000            all  --  *      *       0.0.0.0/0            10.0.0.0/8           /* myst-ondemand */
+      20     2000 ACCEPT     all  --  *      lo      0.0.0.0/0            0.0.0.0/0
+`, m.packets), nil
+}
+
+type mockManager struct {
+	status       connection.Status
+	disconnected int
+}
+
+func (m *mockManager) Status() connection.Status {
+	return m.status
+}
+
+func (m *mockManager) Disconnect() error {
+	m.disconnected++
+	m.status = connection.Status{State: connection.NotConnected}
+	return nil
+}
+
+type mockConnector struct {
+	manager   *mockManager
+	err       error
+	countries []string
+}
+
+func (c *mockConnector) Connect(_ identity.Identity, _ common.Address, country, _ string, _ connection.ConnectParams) (market.ServiceProposal, error) {
+	c.countries = append(c.countries, country)
+	if c.err != nil {
+		return market.ServiceProposal{}, c.err
+	}
+	c.manager.status = connection.Status{State: connection.Connected, SessionID: session.ID("session-" + country)}
+	return market.ServiceProposal{}, nil
+}
+
+type mockAccountantPicker struct{}
+
+func (mockAccountantPicker) Pick() (common.Address, error) {
+	return common.HexToAddress("0x1"), nil
+}
+
+var (
+	networkConfig = Config{Enabled: true, ConsumerID: "0xc", Country: "DE", Destinations: []string{"10.0.0.0/8"}, IdleTimeout: time.Minute}
+	started       = time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC)
+)
+
+func newTestOnDemand(config Config, cmd *mockExec) (*OnDemand, *mockManager, *mockConnector, *[]Config) {
+	var saved []Config
+	manager := &mockManager{status: connection.Status{State: connection.NotConnected}}
+	connector := &mockConnector{manager: manager}
+	onDemand := NewOnDemand(config, manager, connector, mockAccountantPicker{}, time.Second, func(config Config) error {
+		saved = append(saved, config)
+		return nil
+	})
+	onDemand.supported = true
+	onDemand.exec = cmd.exec
+	onDemand.output = cmd.output
+	onDemand.now = func() time.Time { return started }
+	return onDemand, manager, connector, &saved
+}
+
+func TestConfig_Validate(t *testing.T) {
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, networkConfig.Validate())
+	assert.NoError(t, Config{Enabled: true, ConsumerID: "0xc", Country: "DE", Destinations: []string{"1.1.1.1"}}.Validate())
+	assert.NoError(t, Config{Enabled: true, ConsumerID: "0xc", Country: "DE", Interface: "virbr0"}.Validate())
+	assert.Error(t, Config{Enabled: true, ConsumerID: "0xc", Country: "DE"}.Validate())
+	assert.Error(t, Config{Enabled: true, Country: "DE", Interface: "virbr0"}.Validate())
+	assert.Error(t, Config{Enabled: true, ConsumerID: "0xc", Country: "Germany", Interface: "virbr0"}.Validate())
+	assert.Error(t, Config{Enabled: true, ConsumerID: "0xc", Country: "DE", Destinations: []string{"example.com"}}.Validate())
+	assert.Error(t, Config{Enabled: true, ConsumerID: "0xc", Country: "DE", Destinations: []string{"fd00::/64"}}.Validate())
+}
+
+func TestOnDemand_StartAppliesWatchRules(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, _, _, _ := newTestOnDemand(Config{
+		Enabled: true, ConsumerID: "0xc", Country: "DE",
+		Destinations: []string{"10.0.0.0/8", "1.1.1.1"}, Interface: "virbr0",
+	}, cmd)
+
+	// when
+	err := onDemand.Start()
+	defer onDemand.Stop()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/usr/sbin/iptables -I OUTPUT 1 --destination 10.0.0.0/8 --match comment --comment myst-ondemand",
+		"/usr/sbin/iptables -I OUTPUT 1 --destination 1.1.1.1/32 --match comment --comment myst-ondemand",
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface virbr0 --match comment --comment myst-ondemand",
+	}, cmd.commands)
+}
+
+func TestOnDemand_StopRemovesWatchRules(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, _, _, _ := newTestOnDemand(networkConfig, cmd)
+	assert.NoError(t, onDemand.Start())
+	cmd.commands = nil
+
+	// when
+	err := onDemand.Stop()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/usr/sbin/iptables -D OUTPUT --destination 10.0.0.0/8 --match comment --comment myst-ondemand",
+	}, cmd.commands)
+}
+
+func TestOnDemand_StartFailsWhenNotSupported(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, _, _, _ := newTestOnDemand(networkConfig, cmd)
+	onDemand.supported = false
+
+	// when
+	err := onDemand.Start()
+
+	// then
+	assert.Equal(t, ErrNotSupported, err)
+	assert.Empty(t, cmd.commands)
+}
+
+func TestOnDemand_StartRollsBackAppliedRulesOnFailure(t *testing.T) {
+	// given
+	cmd := &mockExec{failOnArgs: "--in-interface virbr0"}
+	onDemand, _, _, _ := newTestOnDemand(Config{
+		Enabled: true, ConsumerID: "0xc", Country: "DE",
+		Destinations: []string{"10.0.0.0/8"}, Interface: "virbr0",
+	}, cmd)
+
+	// when
+	err := onDemand.Start()
+
+	// then
+	assert.Error(t, err)
+	assert.Equal(t, []string{
+		"/usr/sbin/iptables -I OUTPUT 1 --destination 10.0.0.0/8 --match comment --comment myst-ondemand",
+		"/usr/sbin/iptables -D OUTPUT --destination 10.0.0.0/8 --match comment --comment myst-ondemand",
+	}, cmd.commands)
+}
+
+func TestOnDemand_ConnectsOnWatchedTraffic(t *testing.T) {
+	// given
+	cmd := &mockExec{packets: 5}
+	onDemand, manager, connector, _ := newTestOnDemand(networkConfig, cmd)
+	assert.NoError(t, onDemand.setup())
+
+	// when
+	onDemand.check()
+
+	// then
+	assert.Empty(t, connector.countries)
+
+	// when
+	cmd.packets = 7
+	onDemand.check()
+
+	// then
+	assert.Equal(t, []string{"DE"}, connector.countries)
+	assert.Equal(t, connection.Connected, manager.status.State)
+	status := onDemand.Status()
+	assert.True(t, status.Connected)
+	assert.Equal(t, started, status.LastActivity)
+	assert.Empty(t, status.LastError)
+}
+
+func TestOnDemand_DisconnectsAfterIdleTimeout(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, manager, _, _ := newTestOnDemand(networkConfig, cmd)
+	assert.NoError(t, onDemand.setup())
+	cmd.packets = 1
+	onDemand.check()
+
+	// when
+	onDemand.now = func() time.Time { return started.Add(30 * time.Second) }
+	onDemand.check()
+
+	// then
+	assert.Equal(t, 0, manager.disconnected)
+
+	// when
+	onDemand.now = func() time.Time { return started.Add(time.Minute) }
+	onDemand.check()
+
+	// then
+	assert.Equal(t, 1, manager.disconnected)
+	assert.False(t, onDemand.Status().Connected)
+}
+
+func TestOnDemand_KeepsConnectionWhileTrafficFlows(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, manager, _, _ := newTestOnDemand(networkConfig, cmd)
+	assert.NoError(t, onDemand.setup())
+	cmd.packets = 1
+	onDemand.check()
+
+	// when
+	onDemand.now = func() time.Time { return started.Add(50 * time.Second) }
+	cmd.packets = 2
+	onDemand.check()
+	onDemand.now = func() time.Time { return started.Add(time.Minute + 30*time.Second) }
+	onDemand.check()
+
+	// then
+	assert.Equal(t, 0, manager.disconnected)
+	assert.True(t, onDemand.Status().Connected)
+}
+
+func TestOnDemand_LeavesUserConnectionUntouched(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, manager, connector, _ := newTestOnDemand(networkConfig, cmd)
+	assert.NoError(t, onDemand.setup())
+	manager.status = connection.Status{State: connection.Connected, SessionID: "user-session"}
+
+	// when
+	cmd.packets = 1
+	onDemand.check()
+	onDemand.now = func() time.Time { return started.Add(time.Hour) }
+	onDemand.check()
+
+	// then
+	assert.Empty(t, connector.countries)
+	assert.Equal(t, 0, manager.disconnected)
+	assert.False(t, onDemand.Status().Connected)
+}
+
+func TestOnDemand_ReportsConnectError(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, _, connector, _ := newTestOnDemand(networkConfig, cmd)
+	connector.err = errors.New("no proposals")
+	assert.NoError(t, onDemand.setup())
+
+	// when
+	cmd.packets = 1
+	onDemand.check()
+
+	// then
+	status := onDemand.Status()
+	assert.False(t, status.Connected)
+	assert.Equal(t, "no proposals", status.LastError)
+}
+
+func TestOnDemand_ConfigureReappliesRulesAndSaves(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, _, _, saved := newTestOnDemand(networkConfig, cmd)
+	assert.NoError(t, onDemand.Start())
+	defer onDemand.Stop()
+	cmd.commands = nil
+	config := Config{Enabled: true, ConsumerID: "0xc", Country: "US", Interface: "docker0"}
+
+	// when
+	err := onDemand.Configure(config)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"/usr/sbin/iptables -D OUTPUT --destination 10.0.0.0/8 --match comment --comment myst-ondemand",
+		"/usr/sbin/iptables -I FORWARD 1 --in-interface docker0 --match comment --comment myst-ondemand",
+	}, cmd.commands)
+	assert.Equal(t, []Config{config}, *saved)
+	assert.Equal(t, config, onDemand.Status().Config)
+}
+
+func TestOnDemand_ConfigureRejectsInvalidConfig(t *testing.T) {
+	// given
+	cmd := &mockExec{}
+	onDemand, _, _, saved := newTestOnDemand(Config{}, cmd)
+
+	// when
+	err := onDemand.Configure(Config{Enabled: true, ConsumerID: "0xc", Country: "DE"})
+
+	// then
+	assert.Error(t, err)
+	assert.Empty(t, cmd.commands)
+	assert.Empty(t, *saved)
+}
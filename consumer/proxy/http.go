@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+)
+
+// hopHeaders are meaningful for the proxy hop only and are not passed upstream.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Authenticate",
+	"Proxy-Authorization",
+	"Proxy-Connection",
+	"Te",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+}
+
+func (p *Proxy) serveHTTP(conn net.Conn, reader *bufio.Reader) error {
+	req, err := http.ReadRequest(reader)
+	if err != nil {
+		httpReply(conn, http.StatusBadRequest)
+		return err
+	}
+
+	address := req.Host
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "80")
+	}
+
+	upstream, err := p.dial(address)
+	if err == errNotConnected {
+		httpReply(conn, http.StatusServiceUnavailable)
+		return err
+	} else if err != nil {
+		httpReply(conn, http.StatusBadGateway)
+		return err
+	}
+	defer upstream.Close()
+
+	if req.Method == http.MethodConnect {
+		if _, err := io.WriteString(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+			return err
+		}
+		pipe(struct {
+			io.Reader
+			io.Writer
+		}{reader, conn}, upstream)
+		return nil
+	}
+
+	// Plain HTTP request is forwarded as is, connection is not reused for further requests.
+	for _, header := range hopHeaders {
+		req.Header.Del(header)
+	}
+	req.Close = true
+	if err := req.Write(upstream); err != nil {
+		httpReply(conn, http.StatusBadGateway)
+		return err
+	}
+	_, err = io.Copy(conn, upstream)
+	return err
+}
+
+func httpReply(conn net.Conn, status int) {
+	fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nConnection: close\r\nContent-Length: 0\r\n\r\n", status, http.StatusText(status))
+}
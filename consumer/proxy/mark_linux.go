@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"net"
+	"syscall"
+)
+
+// markedDialer creates dialer tagging upstream connections with given firewall mark,
+// so that policy routing sends them via the tunnel selected by the mark.
+func markedDialer(mark int) Dialer {
+	return &net.Dialer{
+		Timeout: dialTimeout,
+		Control: func(network, address string, conn syscall.RawConn) error {
+			var err error
+			if controlErr := conn.Control(func(fd uintptr) {
+				err = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_MARK, mark)
+			}); controlErr != nil {
+				return controlErr
+			}
+			return err
+		},
+	}
+}
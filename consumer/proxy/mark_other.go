@@ -0,0 +1,36 @@
+// +build !linux
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"errors"
+	"net"
+)
+
+type unsupportedDialer struct{}
+
+func (unsupportedDialer) Dial(network, address string) (net.Conn, error) {
+	return nil, errors.New("firewall marks are supported on Linux only")
+}
+
+// markedDialer creates dialer which always fails, firewall marks are not available on this OS.
+func markedDialer(mark int) Dialer {
+	return unsupportedDialer{}
+}
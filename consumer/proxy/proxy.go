@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// errNotConnected indicates that there is no active session to route proxied traffic through.
+	errNotConnected = errors.New("no active session")
+	// errNotLoopback indicates that proxy is asked to listen on non-local address, clients are not authenticated.
+	errNotLoopback = errors.New("proxy address must be a loopback address, clients are not authenticated")
+)
+
+const dialTimeout = 30 * time.Second
+
+// Dialer opens upstream connections of proxy clients.
+type Dialer interface {
+	Dial(network, address string) (net.Conn, error)
+}
+
+// Proxy serves SOCKS5 and HTTP proxy on a single local address, proxied traffic is routed through the active session.
+// Clients are refused while there is no session, so that application traffic never leaks outside of the tunnel.
+type Proxy struct {
+	address   string
+	dialer    Dialer
+	connected int32
+	active    func() bool
+
+	mu       sync.Mutex
+	listener net.Listener
+}
+
+// NewProxy creates proxy listening on given address once started, upstream connections are opened by `tunnel`
+// through the transport of the session, so that they do not depend on the routing table of the host.
+func NewProxy(address string, tunnel Dialer) *Proxy {
+	p := &Proxy{
+		address: address,
+		dialer:  tunnel,
+	}
+	p.active = p.sessionConnected
+	return p
+}
+
+// NewMarkedProxy creates proxy tagging upstream connections with given firewall mark, so that they are routed
+// through the isolated session using the same routing table. Clients are let through while `active` reports true.
+func NewMarkedProxy(address string, mark int, active func() bool) *Proxy {
+	return &Proxy{
+		address: address,
+		dialer:  markedDialer(mark),
+		active:  active,
+	}
+}
+
+// Start starts accepting proxy clients.
+func (p *Proxy) Start() error {
+	if err := validateAddress(p.address); err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	listener, err := net.Listen("tcp", p.address)
+	if err != nil {
+		return err
+	}
+	p.listener = listener
+	log.Info().Msgf("Serving SOCKS5 and HTTP proxy on %s", listener.Addr())
+
+	go p.serve(listener)
+	return nil
+}
+
+// Stop stops accepting proxy clients.
+func (p *Proxy) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener == nil {
+		return nil
+	}
+	err := p.listener.Close()
+	p.listener = nil
+	return err
+}
+
+// Addr returns address proxy is listening on, nil if it is not started.
+func (p *Proxy) Addr() net.Addr {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.listener == nil {
+		return nil
+	}
+	return p.listener.Addr()
+}
+
+// HandleConnectionEvent lets proxy clients through while session is connected.
+func (p *Proxy) HandleConnectionEvent(e connection.AppEventConnectionState) {
+	if e.State == connection.Connected {
+		atomic.StoreInt32(&p.connected, 1)
+	} else {
+		atomic.StoreInt32(&p.connected, 0)
+	}
+}
+
+// validateAddress makes sure proxy is reachable locally only, as it does not authenticate clients.
+func validateAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip == nil || !ip.IsLoopback() {
+		return errNotLoopback
+	}
+	return nil
+}
+
+func (p *Proxy) sessionConnected() bool {
+	return atomic.LoadInt32(&p.connected) == 1
+}
+
+func (p *Proxy) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			log.Debug().Err(err).Msg("Proxy stopped accepting clients")
+			return
+		}
+		go p.handle(conn)
+	}
+}
+
+func (p *Proxy) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	version, err := reader.Peek(1)
+	if err != nil {
+		return
+	}
+
+	if version[0] == socks5Version {
+		err = p.serveSOCKS5(conn, reader)
+	} else {
+		err = p.serveHTTP(conn, reader)
+	}
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to proxy client %s", conn.RemoteAddr())
+	}
+}
+
+// dial opens upstream connection through the tunnel of the connected session.
+func (p *Proxy) dial(address string) (net.Conn, error) {
+	if !p.active() {
+		return nil, errNotConnected
+	}
+	return p.dialer.Dial("tcp", address)
+}
+
+// pipe copies data both ways until either side closes the connection.
+func pipe(client io.ReadWriter, upstream net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, client)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+}
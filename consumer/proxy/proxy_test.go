@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/stretchr/testify/assert"
+)
+
+func startProxy(t *testing.T, state connection.State) *Proxy {
+	proxy := NewProxy("127.0.0.1:0", &net.Dialer{})
+	assert.NoError(t, proxy.Start())
+	proxy.HandleConnectionEvent(connection.AppEventConnectionState{State: state})
+	return proxy
+}
+
+type mockDialer struct {
+	address string
+	err     error
+}
+
+func (d *mockDialer) Dial(network, address string) (net.Conn, error) {
+	d.address = address
+	return nil, d.err
+}
+
+func get(proxy *Proxy, scheme, target string) (*http.Response, error) {
+	proxyURL, _ := url.Parse(fmt.Sprintf("%s://%s", scheme, proxy.Addr()))
+	client := &http.Client{Transport: &http.Transport{
+		Proxy:           http.ProxyURL(proxyURL),
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	}}
+	return client.Get(target)
+}
+
+func TestProxy_ServesClientsWhileConnected(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello")
+	}))
+	defer upstream.Close()
+	upstreamTLS := httptest.NewTLSServer(upstream.Config.Handler)
+	defer upstreamTLS.Close()
+
+	proxy := startProxy(t, connection.Connected)
+	defer proxy.Stop()
+
+	for _, test := range []struct {
+		scheme string
+		target string
+	}{
+		{"socks5", upstream.URL},
+		{"http", upstream.URL},
+		{"http", upstreamTLS.URL},
+	} {
+		resp, err := get(proxy, test.scheme, test.target)
+		assert.NoError(t, err, test.scheme)
+		if err != nil {
+			continue
+		}
+		body, err := ioutil.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, "hello", string(body), test.scheme+" "+test.target)
+	}
+}
+
+func TestProxy_RefusesClientsWithoutSession(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "hello")
+	}))
+	defer upstream.Close()
+
+	proxy := startProxy(t, connection.Reconnecting)
+	defer proxy.Stop()
+
+	_, err := get(proxy, "socks5", upstream.URL)
+	assert.Error(t, err)
+
+	resp, err := get(proxy, "http", upstream.URL)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+	resp.Body.Close()
+}
+
+func TestProxy_DialsThroughTunnel(t *testing.T) {
+	tunnel := &mockDialer{err: errors.New("tunnel is down")}
+	proxy := NewProxy("127.0.0.1:0", tunnel)
+	assert.NoError(t, proxy.Start())
+	defer proxy.Stop()
+	proxy.HandleConnectionEvent(connection.AppEventConnectionState{State: connection.Connected})
+
+	_, err := get(proxy, "socks5", "http://example.com")
+	assert.Error(t, err)
+	assert.Equal(t, "example.com:80", tunnel.address)
+}
+
+func TestProxy_RefusesNonLoopbackAddress(t *testing.T) {
+	for _, address := range []string{"0.0.0.0:0", ":0", "192.168.1.1:1080", "example.com:1080"} {
+		assert.Equal(t, errNotLoopback, NewProxy(address, &net.Dialer{}).Start(), address)
+	}
+	for _, address := range []string{"127.0.0.1:0", "localhost:0", "[::1]:0"} {
+		proxy := NewProxy(address, &net.Dialer{})
+		if err := proxy.Start(); err == nil {
+			proxy.Stop()
+		} else {
+			assert.NotEqual(t, errNotLoopback, err, address)
+		}
+	}
+}
+
+func TestProxy_StopClosesListener(t *testing.T) {
+	proxy := startProxy(t, connection.Connected)
+	assert.NotNil(t, proxy.Addr())
+
+	assert.NoError(t, proxy.Stop())
+	assert.Nil(t, proxy.Addr())
+	assert.NoError(t, proxy.Stop())
+}
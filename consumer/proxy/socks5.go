@@ -0,0 +1,165 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package proxy
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+)
+
+// SOCKS5 protocol constants, see RFC 1928.
+const (
+	socks5Version = 0x05
+
+	socks5AuthNone         = 0x00
+	socks5AuthNoAcceptable = 0xff
+
+	socks5CmdConnect = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5ReplySucceeded            = 0x00
+	socks5ReplyGeneralFailure       = 0x01
+	socks5ReplyNotAllowed           = 0x02
+	socks5ReplyHostUnreachable      = 0x04
+	socks5ReplyCmdNotSupported      = 0x07
+	socks5ReplyAddrTypeNotSupported = 0x08
+)
+
+func (p *Proxy) serveSOCKS5(conn net.Conn, reader *bufio.Reader) error {
+	if err := socks5Negotiate(conn, reader); err != nil {
+		return err
+	}
+
+	header := make([]byte, 3)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return err
+	}
+	if header[1] != socks5CmdConnect {
+		socks5Reply(conn, socks5ReplyCmdNotSupported, nil)
+		return fmt.Errorf("unsupported SOCKS5 command %d", header[1])
+	}
+
+	address, err := socks5ReadAddress(reader)
+	if err != nil {
+		socks5Reply(conn, socks5ReplyAddrTypeNotSupported, nil)
+		return err
+	}
+
+	upstream, err := p.dial(address)
+	if err == errNotConnected {
+		socks5Reply(conn, socks5ReplyNotAllowed, nil)
+		return err
+	} else if err != nil {
+		socks5Reply(conn, socks5ReplyHostUnreachable, nil)
+		return err
+	}
+	defer upstream.Close()
+
+	if err := socks5Reply(conn, socks5ReplySucceeded, upstream.LocalAddr()); err != nil {
+		return err
+	}
+	pipe(struct {
+		io.Reader
+		io.Writer
+	}{reader, conn}, upstream)
+	return nil
+}
+
+// socks5Negotiate accepts clients which do not require authentication, proxy is only reachable locally.
+func socks5Negotiate(conn net.Conn, reader *bufio.Reader) error {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(reader, header); err != nil {
+		return err
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(reader, methods); err != nil {
+		return err
+	}
+
+	for _, method := range methods {
+		if method == socks5AuthNone {
+			_, err := conn.Write([]byte{socks5Version, socks5AuthNone})
+			return err
+		}
+	}
+	conn.Write([]byte{socks5Version, socks5AuthNoAcceptable})
+	return fmt.Errorf("no acceptable SOCKS5 authentication method in %v", methods)
+}
+
+func socks5ReadAddress(reader *bufio.Reader) (string, error) {
+	addrType, err := reader.ReadByte()
+	if err != nil {
+		return "", err
+	}
+
+	var host string
+	switch addrType {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		size := net.IPv4len
+		if addrType == socks5AddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make(net.IP, size)
+		if _, err := io.ReadFull(reader, ip); err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case socks5AddrDomain:
+		size, err := reader.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		domain := make([]byte, size)
+		if _, err := io.ReadFull(reader, domain); err != nil {
+			return "", err
+		}
+		host = string(domain)
+	default:
+		return "", fmt.Errorf("unsupported SOCKS5 address type %d", addrType)
+	}
+
+	port := make([]byte, 2)
+	if _, err := io.ReadFull(reader, port); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(port)))), nil
+}
+
+func socks5Reply(conn net.Conn, reply byte, bound net.Addr) error {
+	addrType, ip, port := byte(socks5AddrIPv4), net.IPv4zero.To4(), 0
+	if tcpAddr, ok := bound.(*net.TCPAddr); ok {
+		port = tcpAddr.Port
+		if ip4 := tcpAddr.IP.To4(); ip4 != nil {
+			ip = ip4
+		} else {
+			addrType, ip = socks5AddrIPv6, tcpAddr.IP.To16()
+		}
+	}
+
+	msg := append([]byte{socks5Version, reply, 0x00, addrType}, ip...)
+	msg = append(msg, byte(port>>8), byte(port))
+	_, err := conn.Write(msg)
+	return err
+}
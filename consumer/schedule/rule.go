@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+var weekdayNames = [...]string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// Rule keeps the connection to a country during a daily time window on given weekdays.
+// Window ending before it starts spans midnight and ends on the next day.
+type Rule struct {
+	// Days the window starts on.
+	Days [7]bool
+	// From and To are the start and the end of the window since midnight of local time, equal ones mean the whole day.
+	From, To time.Duration
+	// Country is the code of a country to connect to.
+	Country string
+	// ServiceType is the type of service to connect to, any type if empty.
+	ServiceType string
+}
+
+// ParseRule parses rule given as "<days> <from>-<to> <country> [service type]",
+// e.g. "mon-fri 09:00-17:00 DE wireguard". Days are listed by comma separated names or ranges, or "daily".
+func ParseRule(s string) (Rule, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 3 || len(fields) > 4 {
+		return Rule{}, fmt.Errorf("invalid rule %q, expected \"<days> <from>-<to> <country> [service type]\"", s)
+	}
+
+	var rule Rule
+	if err := rule.parseDays(fields[0]); err != nil {
+		return Rule{}, err
+	}
+
+	window := strings.Split(fields[1], "-")
+	if len(window) != 2 {
+		return Rule{}, fmt.Errorf("invalid time window %q, expected \"<from>-<to>\"", fields[1])
+	}
+	var err error
+	if rule.From, err = parseTimeOfDay(window[0]); err != nil {
+		return Rule{}, err
+	}
+	if rule.To, err = parseTimeOfDay(window[1]); err != nil {
+		return Rule{}, err
+	}
+
+	rule.Country = strings.ToUpper(fields[2])
+	if len(rule.Country) != 2 {
+		return Rule{}, fmt.Errorf("invalid country code %q", fields[2])
+	}
+	if len(fields) == 4 {
+		rule.ServiceType = fields[3]
+	}
+	return rule, nil
+}
+
+// ParseRules parses all given rules.
+func ParseRules(specs []string) ([]Rule, error) {
+	rules := make([]Rule, 0, len(specs))
+	for _, spec := range specs {
+		rule, err := ParseRule(spec)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// Active checks whether the time falls into the rule window.
+func (r Rule) Active(t time.Time) bool {
+	sinceMidnight := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+
+	switch {
+	case r.From == r.To:
+		return r.Days[today]
+	case r.From < r.To:
+		return r.Days[today] && sinceMidnight >= r.From && sinceMidnight < r.To
+	default:
+		return (r.Days[today] && sinceMidnight >= r.From) || (r.Days[yesterday] && sinceMidnight < r.To)
+	}
+}
+
+// String returns the rule in the format accepted by ParseRule.
+func (r Rule) String() string {
+	s := fmt.Sprintf("%s %s-%s %s", r.daysString(), formatTimeOfDay(r.From), formatTimeOfDay(r.To), r.Country)
+	if r.ServiceType != "" {
+		s += " " + r.ServiceType
+	}
+	return s
+}
+
+func (r *Rule) parseDays(s string) error {
+	if s == "daily" {
+		for i := range r.Days {
+			r.Days[i] = true
+		}
+		return nil
+	}
+
+	for _, part := range strings.Split(strings.ToLower(s), ",") {
+		bounds := strings.Split(part, "-")
+		if len(bounds) > 2 {
+			return fmt.Errorf("invalid days %q", s)
+		}
+		first, ok := weekdays[bounds[0]]
+		if !ok {
+			return fmt.Errorf("invalid day %q", bounds[0])
+		}
+		last := first
+		if len(bounds) == 2 {
+			if last, ok = weekdays[bounds[1]]; !ok {
+				return fmt.Errorf("invalid day %q", bounds[1])
+			}
+		}
+		for day := first; ; day = (day + 1) % 7 {
+			r.Days[day] = true
+			if day == last {
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (r Rule) daysString() string {
+	var days []string
+	for day, ok := range r.Days {
+		if ok {
+			days = append(days, weekdayNames[day])
+		}
+	}
+	if len(days) == len(r.Days) {
+		return "daily"
+	}
+	return strings.Join(days, ",")
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 2 {
+		return 0, fmt.Errorf("invalid time %q, expected \"HH:MM\"", s)
+	}
+	hours, err := strconv.Atoi(parts[0])
+	if err != nil || hours < 0 || hours > 24 {
+		return 0, fmt.Errorf("invalid time %q, expected \"HH:MM\"", s)
+	}
+	minutes, err := strconv.Atoi(parts[1])
+	if err != nil || minutes < 0 || minutes > 59 || (hours == 24 && minutes > 0) {
+		return 0, fmt.Errorf("invalid time %q, expected \"HH:MM\"", s)
+	}
+	return time.Duration(hours)*time.Hour + time.Duration(minutes)*time.Minute, nil
+}
+
+func formatTimeOfDay(d time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", int(d/time.Hour), int(d%time.Hour/time.Minute))
+}
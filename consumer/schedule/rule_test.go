@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRule(t *testing.T) {
+	rule, err := ParseRule("mon-fri 09:00-17:30 de wireguard")
+	assert.NoError(t, err)
+	assert.Equal(t, Rule{
+		Days:        [7]bool{false, true, true, true, true, true, false},
+		From:        9 * time.Hour,
+		To:          17*time.Hour + 30*time.Minute,
+		Country:     "DE",
+		ServiceType: "wireguard",
+	}, rule)
+	assert.Equal(t, "mon,tue,wed,thu,fri 09:00-17:30 DE wireguard", rule.String())
+
+	rule, err = ParseRule("fri-sun,wed 22:00-02:00 US")
+	assert.NoError(t, err)
+	assert.Equal(t, [7]bool{true, false, false, true, false, true, true}, rule.Days)
+	assert.Equal(t, "sun,wed,fri,sat 22:00-02:00 US", rule.String())
+
+	rule, err = ParseRule("daily 00:00-00:00 LT")
+	assert.NoError(t, err)
+	assert.Equal(t, "daily 00:00-00:00 LT", rule.String())
+}
+
+func TestParseRule_RejectsInvalidRules(t *testing.T) {
+	for _, spec := range []string{
+		"",
+		"mon-fri 09:00-17:00",
+		"mon-fri 09:00-17:00 DE wireguard extra",
+		"funday 09:00-17:00 DE",
+		"mon-fri-sat 09:00-17:00 DE",
+		"mon-fri 09:00 DE",
+		"mon-fri 9-17 DE",
+		"mon-fri 09:60-17:00 DE",
+		"mon-fri 09:00-25:00 DE",
+		"mon-fri 09:00-17:00 DEU",
+	} {
+		_, err := ParseRule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestRule_Active(t *testing.T) {
+	// 2020-06-01 is Monday.
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2020, 6, day, hour, minute, 0, 0, time.Local)
+	}
+
+	workHours, _ := ParseRule("mon-fri 09:00-17:00 DE")
+	assert.True(t, workHours.Active(at(1, 9, 0)))
+	assert.True(t, workHours.Active(at(5, 16, 59)))
+	assert.False(t, workHours.Active(at(1, 8, 59)))
+	assert.False(t, workHours.Active(at(1, 17, 0)))
+	assert.False(t, workHours.Active(at(6, 12, 0)))
+
+	overnight, _ := ParseRule("fri 22:00-06:00 DE")
+	assert.True(t, overnight.Active(at(5, 23, 0)))
+	assert.True(t, overnight.Active(at(6, 5, 59)))
+	assert.False(t, overnight.Active(at(6, 23, 0)))
+	assert.False(t, overnight.Active(at(5, 5, 0)))
+
+	wholeDay, _ := ParseRule("sun 00:00-00:00 DE")
+	assert.True(t, wholeDay.Active(at(7, 0, 0)))
+	assert.True(t, wholeDay.Active(at(7, 23, 59)))
+	assert.False(t, wholeDay.Active(at(8, 0, 0)))
+}
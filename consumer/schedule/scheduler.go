@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/rs/zerolog/log"
+)
+
+// AppTopicScheduleStatus represents the topic of scheduled connection status changes.
+const AppTopicScheduleStatus = "schedule.status"
+
+// AppEventScheduleStatus is published when scheduler configuration or the state of scheduled connection changes.
+type AppEventScheduleStatus struct {
+	Status Status
+}
+
+// Config describes recurring connections.
+type Config struct {
+	Enabled bool
+	// ConsumerID is the identity scheduled connections are made with.
+	ConsumerID string
+	// Rules are the windows to keep the connection in, the first active one is used if several overlap.
+	Rules []Rule
+}
+
+// Validate checks that enabled scheduler has consumer identity and rules set.
+func (c Config) Validate() error {
+	if !c.Enabled {
+		return nil
+	}
+	if c.ConsumerID == "" {
+		return errors.New("consumer identity is required")
+	}
+	if len(c.Rules) == 0 {
+		return errors.New("at least one rule is required")
+	}
+	return nil
+}
+
+// Status describes scheduler configuration and the state of scheduled connection.
+type Status struct {
+	Config
+	// ActiveRule is the rule active right now, empty if none is.
+	ActiveRule string
+	// Connected is set while connection made by the scheduler is up.
+	Connected bool
+	// LastError is the reason the last scheduled connection attempt failed, empty if it succeeded.
+	LastError string
+}
+
+type connectionManager interface {
+	Status() connection.Status
+	Disconnect() error
+}
+
+type countryConnector interface {
+	Connect(consumerID identity.Identity, accountantID common.Address, country, serviceType string, params connection.ConnectParams) (market.ServiceProposal, error)
+}
+
+type accountantPicker interface {
+	Pick() (common.Address, error)
+}
+
+// Scheduler connects and disconnects the main connection by the configured rules. Connections made by
+// the user are never interrupted, only the ones made by the scheduler are disconnected once their rule ends.
+type Scheduler struct {
+	manager     connectionManager
+	connector   countryConnector
+	accountants accountantPicker
+	publisher   eventbus.Publisher
+	save        func(Config) error
+	interval    time.Duration
+	now         func() time.Time
+
+	mu     sync.Mutex
+	config Config
+	status Status
+	// sessionID and rule are of the connection made by the scheduler.
+	sessionID session.ID
+	rule      string
+
+	check chan struct{}
+	done  chan struct{}
+	once  sync.Once
+}
+
+// NewScheduler creates scheduler checking rules every interval, save persists configuration changed via Configure.
+func NewScheduler(config Config, manager connectionManager, connector countryConnector, accountants accountantPicker,
+	publisher eventbus.Publisher, interval time.Duration, save func(Config) error) *Scheduler {
+	return &Scheduler{
+		manager:     manager,
+		connector:   connector,
+		accountants: accountants,
+		publisher:   publisher,
+		save:        save,
+		interval:    interval,
+		now:         time.Now,
+		config:      config,
+		status:      Status{Config: config},
+		check:       make(chan struct{}, 1),
+		done:        make(chan struct{}),
+	}
+}
+
+// Start starts checking the rules in background.
+func (s *Scheduler) Start() {
+	s.publish()
+	go s.run()
+}
+
+// Stop stops checking the rules, the connection is left as it is.
+func (s *Scheduler) Stop() {
+	s.once.Do(func() {
+		close(s.done)
+	})
+}
+
+// Status returns scheduler configuration and the state of scheduled connection.
+func (s *Scheduler) Status() Status {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.status
+}
+
+// Configure replaces the rules, persists them and checks them right away.
+func (s *Scheduler) Configure(config Config) error {
+	if err := config.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.config = config
+	s.status.Config = config
+	s.mu.Unlock()
+
+	if err := s.save(config); err != nil {
+		return err
+	}
+	s.publish()
+
+	select {
+	case s.check <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (s *Scheduler) run() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	s.apply()
+	for {
+		select {
+		case <-ticker.C:
+			s.apply()
+		case <-s.check:
+			s.apply()
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// apply connects or disconnects the scheduled connection by the rule active now.
+func (s *Scheduler) apply() {
+	s.mu.Lock()
+	config := s.config
+	s.mu.Unlock()
+
+	var active *Rule
+	if config.Enabled {
+		now := s.now()
+		for i := range config.Rules {
+			if config.Rules[i].Active(now) {
+				active = &config.Rules[i]
+				break
+			}
+		}
+	}
+
+	current := s.manager.Status()
+	owned := s.sessionID != "" && current.SessionID == s.sessionID && current.State != connection.NotConnected
+	if !owned {
+		s.sessionID, s.rule = "", ""
+	}
+
+	if owned && (active == nil || active.String() != s.rule) {
+		log.Info().Msgf("Disconnecting scheduled connection of rule %q", s.rule)
+		if err := s.manager.Disconnect(); err != nil {
+			log.Warn().Err(err).Msg("Failed to disconnect scheduled connection")
+		}
+		s.sessionID, s.rule = "", ""
+		current = s.manager.Status()
+	}
+
+	var lastError string
+	if active != nil && s.sessionID == "" && current.State == connection.NotConnected {
+		log.Info().Msgf("Connecting by schedule rule %q", active.String())
+		if err := s.connect(config.ConsumerID, *active); err != nil {
+			log.Warn().Err(err).Msgf("Failed to connect by schedule rule %q", active.String())
+			lastError = err.Error()
+		} else {
+			s.sessionID, s.rule = s.manager.Status().SessionID, active.String()
+		}
+	}
+
+	s.mu.Lock()
+	status := s.status
+	status.ActiveRule = ""
+	if active != nil {
+		status.ActiveRule = active.String()
+	}
+	status.Connected = s.sessionID != ""
+	status.LastError = lastError
+	changed := !reflect.DeepEqual(status, s.status)
+	s.status = status
+	s.mu.Unlock()
+
+	if changed {
+		s.publish()
+	}
+}
+
+func (s *Scheduler) connect(consumerID string, rule Rule) error {
+	accountant, err := s.accountants.Pick()
+	if err != nil {
+		return err
+	}
+	_, err = s.connector.Connect(identity.FromAddress(consumerID), accountant, rule.Country, rule.ServiceType, connection.ConnectParams{
+		DNS: connection.DNSOptionAuto,
+	})
+	return err
+}
+
+func (s *Scheduler) publish() {
+	s.publisher.Publish(AppTopicScheduleStatus, AppEventScheduleStatus{Status: s.Status()})
+}
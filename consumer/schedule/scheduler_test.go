@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package schedule
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockManager struct {
+	status       connection.Status
+	disconnected int
+}
+
+func (m *mockManager) Status() connection.Status {
+	return m.status
+}
+
+func (m *mockManager) Disconnect() error {
+	m.disconnected++
+	m.status = connection.Status{State: connection.NotConnected}
+	return nil
+}
+
+type mockConnector struct {
+	manager   *mockManager
+	err       error
+	countries []string
+}
+
+func (c *mockConnector) Connect(_ identity.Identity, _ common.Address, country, _ string, _ connection.ConnectParams) (market.ServiceProposal, error) {
+	c.countries = append(c.countries, country)
+	if c.err != nil {
+		return market.ServiceProposal{}, c.err
+	}
+	c.manager.status = connection.Status{State: connection.Connected, SessionID: session.ID("session-" + country)}
+	return market.ServiceProposal{}, nil
+}
+
+type mockAccountantPicker struct{}
+
+func (mockAccountantPicker) Pick() (common.Address, error) {
+	return common.HexToAddress("0x1"), nil
+}
+
+// 2020-06-01 is Monday.
+var monday = time.Date(2020, 6, 1, 12, 0, 0, 0, time.Local)
+
+func newTestScheduler(now time.Time, rules ...string) (*Scheduler, *mockManager, *mockConnector, *mocks.EventBus) {
+	parsed, _ := ParseRules(rules)
+	manager := &mockManager{status: connection.Status{State: connection.NotConnected}}
+	connector := &mockConnector{manager: manager}
+	bus := mocks.NewEventBus()
+	scheduler := NewScheduler(Config{Enabled: true, ConsumerID: "0xc", Rules: parsed}, manager, connector, mockAccountantPicker{}, bus, time.Minute, func(Config) error {
+		return nil
+	})
+	scheduler.now = func() time.Time { return now }
+	return scheduler, manager, connector, bus
+}
+
+func TestConfig_Validate(t *testing.T) {
+	rules, _ := ParseRules([]string{"daily 09:00-17:00 DE"})
+
+	assert.NoError(t, Config{}.Validate())
+	assert.NoError(t, Config{Enabled: true, ConsumerID: "0xc", Rules: rules}.Validate())
+	assert.Error(t, Config{Enabled: true, Rules: rules}.Validate())
+	assert.Error(t, Config{Enabled: true, ConsumerID: "0xc"}.Validate())
+}
+
+func TestScheduler_ConnectsWhileRuleIsActive(t *testing.T) {
+	// given
+	scheduler, manager, connector, bus := newTestScheduler(monday, "sat,sun 09:00-17:00 US", "mon-fri 09:00-17:00 DE")
+
+	// when
+	scheduler.apply()
+	scheduler.apply()
+
+	// then
+	assert.Equal(t, []string{"DE"}, connector.countries)
+	assert.Equal(t, connection.Connected, manager.status.State)
+	status := scheduler.Status()
+	assert.Equal(t, "mon,tue,wed,thu,fri 09:00-17:00 DE", status.ActiveRule)
+	assert.True(t, status.Connected)
+	assert.Equal(t, AppEventScheduleStatus{Status: status}, bus.Pop())
+}
+
+func TestScheduler_DisconnectsOnceRuleEnds(t *testing.T) {
+	// given
+	scheduler, manager, _, _ := newTestScheduler(monday, "mon-fri 09:00-17:00 DE")
+	scheduler.apply()
+
+	// when
+	scheduler.now = func() time.Time { return monday.Add(5 * time.Hour) }
+	scheduler.apply()
+
+	// then
+	assert.Equal(t, 1, manager.disconnected)
+	assert.Equal(t, connection.NotConnected, manager.status.State)
+	assert.Equal(t, Status{Config: scheduler.config}, scheduler.Status())
+}
+
+func TestScheduler_LeavesUserConnectionAlone(t *testing.T) {
+	// given
+	scheduler, manager, connector, _ := newTestScheduler(monday, "mon-fri 09:00-17:00 DE")
+	manager.status = connection.Status{State: connection.Connected, SessionID: "user-session"}
+
+	// when
+	scheduler.apply()
+	scheduler.now = func() time.Time { return monday.Add(5 * time.Hour) }
+	scheduler.apply()
+
+	// then
+	assert.Empty(t, connector.countries)
+	assert.Zero(t, manager.disconnected)
+	assert.False(t, scheduler.Status().Connected)
+}
+
+func TestScheduler_ReconnectsWhenActiveRuleChanges(t *testing.T) {
+	// given
+	scheduler, manager, connector, _ := newTestScheduler(monday, "mon 09:00-13:00 DE", "mon 13:00-17:00 FR")
+	scheduler.apply()
+
+	// when
+	scheduler.now = func() time.Time { return monday.Add(2 * time.Hour) }
+	scheduler.apply()
+
+	// then
+	assert.Equal(t, []string{"DE", "FR"}, connector.countries)
+	assert.Equal(t, 1, manager.disconnected)
+	assert.Equal(t, "mon 13:00-17:00 FR", scheduler.Status().ActiveRule)
+}
+
+func TestScheduler_ReportsFailedConnection(t *testing.T) {
+	// given
+	scheduler, _, connector, _ := newTestScheduler(monday, "mon-fri 09:00-17:00 DE")
+	connector.err = errors.New("no proposals found")
+
+	// when
+	scheduler.apply()
+
+	// then
+	status := scheduler.Status()
+	assert.False(t, status.Connected)
+	assert.Equal(t, "no proposals found", status.LastError)
+}
+
+func TestScheduler_DisabledSchedulerDoesNotConnect(t *testing.T) {
+	// given
+	scheduler, _, connector, _ := newTestScheduler(monday, "mon-fri 09:00-17:00 DE")
+	scheduler.config.Enabled = false
+
+	// when
+	scheduler.apply()
+
+	// then
+	assert.Empty(t, connector.countries)
+	assert.Empty(t, scheduler.Status().ActiveRule)
+}
+
+func TestScheduler_ConfigureSavesConfig(t *testing.T) {
+	// given
+	scheduler, _, _, _ := newTestScheduler(monday)
+	var saved Config
+	scheduler.save = func(c Config) error {
+		saved = c
+		return nil
+	}
+	rules, _ := ParseRules([]string{"daily 09:00-17:00 DE"})
+
+	// when
+	err := scheduler.Configure(Config{Enabled: true, ConsumerID: "0xc", Rules: rules})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, Config{Enabled: true, ConsumerID: "0xc", Rules: rules}, saved)
+	assert.Equal(t, saved, scheduler.Status().Config)
+	assert.Error(t, scheduler.Configure(Config{Enabled: true}))
+}
@@ -0,0 +1,206 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	session_node "github.com/mysteriumnetwork/node/session"
+	session_event "github.com/mysteriumnetwork/node/session/event"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const consumerStatsBucket = "provider_consumer_stats"
+
+// ConsumerStats holds lifetime statistics of a consumer served by the provider.
+// Consumer is identified by a hash of its identity, so consumer addresses are not stored.
+type ConsumerStats struct {
+	ConsumerHash string `storm:"id"`
+	FirstSeen    time.Time
+	LastSeen     time.Time
+	Sessions     int
+	DataSent     uint64
+	DataReceived uint64
+	Tokens       money.Tokens
+}
+
+// Returning checks if the consumer came back for another session.
+func (cs ConsumerStats) Returning() bool {
+	return cs.Sessions > 1
+}
+
+// HashConsumer returns the hash by which consumer statistics are stored.
+func HashConsumer(consumerID identity.Identity) string {
+	sum := sha256.Sum256([]byte(strings.ToLower(consumerID.Address)))
+	return hex.EncodeToString(sum[:])
+}
+
+type consumerStatsStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+}
+
+// consumerSession holds totals of an active session which are not yet added to consumer statistics.
+type consumerSession struct {
+	consumerHash string
+	dataSent     uint64
+	dataReceived uint64
+	tokens       money.Tokens
+}
+
+// ConsumerStatsStorage keeps lifetime statistics of consumers served by the provider.
+// Unlike session history it is never compacted or pruned.
+type ConsumerStatsStorage struct {
+	storage    consumerStatsStorage
+	timeGetter timeGetter
+
+	mu             sync.Mutex
+	sessionsActive map[session_node.ID]consumerSession
+}
+
+// NewConsumerStatsStorage creates consumer statistics storage.
+func NewConsumerStatsStorage(storage consumerStatsStorage) *ConsumerStatsStorage {
+	return &ConsumerStatsStorage{
+		storage:        storage,
+		timeGetter:     time.Now,
+		sessionsActive: make(map[session_node.ID]consumerSession),
+	}
+}
+
+// Subscribe subscribes to provided session events of event bus.
+func (css *ConsumerStatsStorage) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.Subscribe(session_event.AppTopicSession, css.consumeServiceSessionEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(session_event.AppTopicDataTransferred, css.consumeServiceSessionStatisticsEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(session_event.AppTopicTokensEarned, css.consumeServiceSessionEarningsEvent)
+}
+
+// List returns statistics of all consumers, the most recently seen first.
+func (css *ConsumerStatsStorage) List() ([]ConsumerStats, error) {
+	css.mu.Lock()
+	defer css.mu.Unlock()
+
+	var list []ConsumerStats
+	if err := css.storage.GetAllFrom(consumerStatsBucket, &list); err != nil {
+		return nil, errors.Wrap(err, "could not get consumer stats")
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].LastSeen.After(list[j].LastSeen)
+	})
+	return list, nil
+}
+
+func (css *ConsumerStatsStorage) consumeServiceSessionEvent(e session_event.AppEventSession) {
+	sessionID := session_node.ID(e.Session.ID)
+
+	switch e.Status {
+	case session_event.CreatedStatus:
+		consumerHash := HashConsumer(e.Session.ConsumerID)
+
+		css.mu.Lock()
+		defer css.mu.Unlock()
+
+		css.sessionsActive[sessionID] = consumerSession{consumerHash: consumerHash}
+		err := css.update(consumerHash, func(stats *ConsumerStats) {
+			stats.Sessions++
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("Could not count consumer session %s", sessionID)
+		}
+	case session_event.RemovedStatus:
+		css.mu.Lock()
+		defer css.mu.Unlock()
+
+		sess, ok := css.sessionsActive[sessionID]
+		if !ok {
+			return
+		}
+		delete(css.sessionsActive, sessionID)
+
+		err := css.update(sess.consumerHash, func(stats *ConsumerStats) {
+			stats.DataSent += sess.dataSent
+			stats.DataReceived += sess.dataReceived
+			stats.Tokens = stats.Tokens.Add(sess.tokens)
+		})
+		if err != nil {
+			log.Error().Err(err).Msgf("Could not add totals of consumer session %s", sessionID)
+		}
+	}
+}
+
+func (css *ConsumerStatsStorage) consumeServiceSessionStatisticsEvent(e session_event.AppEventDataTransferred) {
+	css.mu.Lock()
+	defer css.mu.Unlock()
+
+	sessionID := session_node.ID(e.ID)
+	sess, ok := css.sessionsActive[sessionID]
+	if !ok {
+		return
+	}
+
+	sess.dataSent = e.Down
+	sess.dataReceived = e.Up
+	css.sessionsActive[sessionID] = sess
+}
+
+func (css *ConsumerStatsStorage) consumeServiceSessionEarningsEvent(e session_event.AppEventTokensEarned) {
+	css.mu.Lock()
+	defer css.mu.Unlock()
+
+	sessionID := session_node.ID(e.SessionID)
+	sess, ok := css.sessionsActive[sessionID]
+	if !ok {
+		return
+	}
+
+	sess.tokens = e.Total
+	css.sessionsActive[sessionID] = sess
+}
+
+// update applies changes to the stored consumer statistics, it must be called with the lock held.
+func (css *ConsumerStatsStorage) update(consumerHash string, change func(stats *ConsumerStats)) error {
+	now := css.timeGetter().UTC()
+
+	var stats ConsumerStats
+	err := css.storage.GetOneByField(consumerStatsBucket, "ConsumerHash", consumerHash, &stats)
+	if err != nil && err != storage.ErrNotFound {
+		return errors.Wrap(err, "could not get consumer stats")
+	}
+	if stats.ConsumerHash == "" {
+		stats = ConsumerStats{ConsumerHash: consumerHash, FirstSeen: now}
+	}
+
+	change(&stats)
+	stats.LastSeen = now
+
+	return errors.Wrap(css.storage.Store(consumerStatsBucket, &stats), "could not store consumer stats")
+}
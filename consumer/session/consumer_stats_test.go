@@ -0,0 +1,103 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	session_event "github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConsumerStatsStorage_CountsNewAndReturningConsumers(t *testing.T) {
+	// given
+	dir, err := ioutil.TempDir("", "consumerStatsTest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	db, err := boltdb.NewStorage(dir)
+	require.NoError(t, err)
+	defer db.Close()
+
+	now := time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC)
+	stats := NewConsumerStatsStorage(db)
+	stats.timeGetter = func() time.Time { return now }
+
+	returning := identity.FromAddress("0x000000000000000000000000000000000000000a")
+	once := identity.FromAddress("0x000000000000000000000000000000000000000b")
+
+	// when
+	provideSession(stats, "session1", returning, 100, 200, 10)
+	now = now.Add(time.Hour)
+	provideSession(stats, "session2", once, 1, 2, 3)
+	now = now.Add(time.Hour)
+	provideSession(stats, "session3", returning, 100, 200, 10)
+
+	// then
+	list, err := stats.List()
+	require.NoError(t, err)
+	assert.Equal(t, []ConsumerStats{
+		{
+			ConsumerHash: HashConsumer(returning),
+			FirstSeen:    time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC),
+			LastSeen:     time.Date(2020, 6, 17, 12, 0, 0, 0, time.UTC),
+			Sessions:     2,
+			DataSent:     400,
+			DataReceived: 200,
+			Tokens:       money.NewTokens(20),
+		},
+		{
+			ConsumerHash: HashConsumer(once),
+			FirstSeen:    time.Date(2020, 6, 17, 11, 0, 0, 0, time.UTC),
+			LastSeen:     time.Date(2020, 6, 17, 11, 0, 0, 0, time.UTC),
+			Sessions:     1,
+			DataSent:     2,
+			DataReceived: 1,
+			Tokens:       money.NewTokens(3),
+		},
+	}, list)
+	assert.True(t, list[0].Returning())
+	assert.False(t, list[1].Returning())
+}
+
+func TestHashConsumer_IgnoresAddressCase(t *testing.T) {
+	assert.Equal(t,
+		HashConsumer(identity.FromAddress("0x000000000000000000000000000000000000000A")),
+		HashConsumer(identity.FromAddress("0x000000000000000000000000000000000000000a")),
+	)
+	assert.NotContains(t, HashConsumer(identity.FromAddress("0x000000000000000000000000000000000000000a")), "000000000a")
+}
+
+func provideSession(stats *ConsumerStatsStorage, sessionID string, consumerID identity.Identity, up, down, tokens uint64) {
+	stats.consumeServiceSessionEvent(session_event.AppEventSession{
+		Status:  session_event.CreatedStatus,
+		Session: session_event.SessionContext{ID: sessionID, ConsumerID: consumerID},
+	})
+	stats.consumeServiceSessionStatisticsEvent(session_event.AppEventDataTransferred{ID: sessionID, Up: up, Down: down})
+	stats.consumeServiceSessionEarningsEvent(session_event.AppEventTokensEarned{SessionID: sessionID, Total: money.NewTokens(tokens)})
+	stats.consumeServiceSessionEvent(session_event.AppEventSession{
+		Status:  session_event.RemovedStatus,
+		Session: session_event.SessionContext{ID: sessionID, ConsumerID: consumerID},
+	})
+}
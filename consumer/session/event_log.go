@@ -0,0 +1,574 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"bytes"
+	"encoding/binary"
+	"sort"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	"github.com/asdine/storm/v3/codec"
+	"github.com/mysteriumnetwork/node/money"
+	session_node "github.com/mysteriumnetwork/node/session"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Session history is kept as an append-only log of session snapshots. Every snapshot
+// is stored under a key composed of session start time, session ID and a sequence number,
+// so that all snapshots of a session are adjacent and the log can be streamed ordered
+// by session start without loading it into memory. Compaction drops superseded snapshots
+// and, when enabled, folds old sessions into daily aggregates. Search index keeps search terms of sessions
+// followed by the key prefix of their snapshots, so that matching sessions are found without a log scan.
+const (
+	sessionLogBucketName    = "session-log"
+	sessionDailyBucketName  = "session-daily"
+	sessionSearchBucketName = "session-search"
+
+	timeKeyLen = 12
+	seqKeyLen  = 8
+	signBit    = uint64(1) << 63
+)
+
+// eventLog persists session snapshots and daily aggregates of compacted sessions.
+type eventLog interface {
+	append(session History) error
+	// read streams the latest snapshots of sessions started within the given period newest first,
+	// followed by daily aggregates of that period when fnDaily is given, within a single transaction.
+	read(from, to *time.Time, fn func(History) bool, fnDaily func(dailyStats)) error
+	// search streams the latest snapshots of sessions matching all the given search terms newest first,
+	// sessions are looked up in the search index. Compacted sessions are not matched.
+	search(terms []string, fn func(History) bool) error
+	compact(before time.Time) (int, error)
+	prune(before time.Time, maxRows int) (int, error)
+}
+
+// boltEventLog keeps session log in BoltDB.
+type boltEventLog struct {
+	db *storm.DB
+}
+
+func (l *boltEventLog) append(session History) error {
+	return l.db.Bolt.Update(func(tx *bolt.Tx) error {
+		return WriteEventLog(tx, l.db.Codec(), session)
+	})
+}
+
+func (l *boltEventLog) read(from, to *time.Time, fn func(History) bool, fnDaily func(dailyStats)) error {
+	return l.db.Bolt.View(func(tx *bolt.Tx) error {
+		err := iterateLog(tx, l.db.Codec(), from, to, fn)
+		if err != nil || fnDaily == nil {
+			return err
+		}
+		return iterateDailyStats(tx, l.db.Codec(), from, to, fnDaily)
+	})
+}
+
+func (l *boltEventLog) search(terms []string, fn func(History) bool) error {
+	return l.db.Bolt.View(func(tx *bolt.Tx) error {
+		return searchLog(tx, l.db.Codec(), terms, fn)
+	})
+}
+
+func (l *boltEventLog) compact(before time.Time) (removed int, err error) {
+	err = l.db.Bolt.Update(func(tx *bolt.Tx) error {
+		removed, err = compactLog(tx, l.db.Codec(), before)
+		return err
+	})
+	return removed, err
+}
+
+func (l *boltEventLog) prune(before time.Time, maxRows int) (removed int, err error) {
+	err = l.db.Bolt.Update(func(tx *bolt.Tx) error {
+		removed, err = pruneLog(tx, l.db.Codec(), before, maxRows)
+		return err
+	})
+	return removed, err
+}
+
+// WriteEventLog appends given session snapshots to the session log, encoded with the given codec.
+func WriteEventLog(tx *bolt.Tx, codec codec.MarshalUnmarshaler, sessions ...History) error {
+	bucket, err := tx.CreateBucketIfNotExists([]byte(sessionLogBucketName))
+	if err != nil {
+		return err
+	}
+	index, err := tx.CreateBucketIfNotExists([]byte(sessionSearchBucketName))
+	if err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+		value, err := codec.Marshal(session)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(logKey(session.Started, session.SessionID, seq), value); err != nil {
+			return err
+		}
+		if err := indexSession(index, session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IndexEventLog adds sessions of the session log to the search index.
+func IndexEventLog(tx *bolt.Tx, codec codec.MarshalUnmarshaler) error {
+	bucket := tx.Bucket([]byte(sessionLogBucketName))
+	if bucket == nil {
+		return nil
+	}
+	index, err := tx.CreateBucketIfNotExists([]byte(sessionSearchBucketName))
+	if err != nil {
+		return err
+	}
+
+	c := bucket.Cursor()
+	var lastPrefix []byte
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		prefix := k[:len(k)-seqKeyLen]
+		if bytes.Equal(prefix, lastPrefix) {
+			continue
+		}
+		lastPrefix = append(lastPrefix[:0], prefix...)
+
+		var session History
+		if err := codec.Unmarshal(v, &session); err != nil {
+			return err
+		}
+		if err := indexSession(index, session); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RecodeEventLog decodes session snapshots and daily aggregates of the session log
+// and stores them encoded again, so that they are kept in the current encoding.
+func RecodeEventLog(tx *bolt.Tx, codec codec.MarshalUnmarshaler) error {
+	if err := recodeBucket(tx.Bucket([]byte(sessionLogBucketName)), codec, func() interface{} { return &History{} }); err != nil {
+		return err
+	}
+	return recodeBucket(tx.Bucket([]byte(sessionDailyBucketName)), codec, func() interface{} { return &dailyStats{} })
+}
+
+func recodeBucket(bucket *bolt.Bucket, codec codec.MarshalUnmarshaler, newValue func() interface{}) error {
+	if bucket == nil {
+		return nil
+	}
+
+	var keys, values [][]byte
+	c := bucket.Cursor()
+	for k, v := c.First(); k != nil; k, v = c.Next() {
+		value := newValue()
+		if err := codec.Unmarshal(v, value); err != nil {
+			return err
+		}
+		encoded, err := codec.Marshal(value)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, append([]byte(nil), k...))
+		values = append(values, encoded)
+	}
+
+	for i := range keys {
+		if err := bucket.Put(keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func indexSession(index *bolt.Bucket, session History) error {
+	for _, k := range searchKeys(session) {
+		if err := index.Put(k, []byte{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// searchKeys returns search index keys of the given session.
+func searchKeys(session History) [][]byte {
+	prefix := logPrefix(session.Started, session.SessionID)
+	terms := searchIndexTerms(session)
+	keys := make([][]byte, len(terms))
+	for i, term := range terms {
+		keys[i] = searchKey(term, prefix)
+	}
+	return keys
+}
+
+// searchLog looks up sessions matching the first term in the search index
+// and streams the ones matching the rest of the terms, newest first.
+func searchLog(tx *bolt.Tx, codec codec.MarshalUnmarshaler, terms []string, fn func(History) bool) error {
+	bucket := tx.Bucket([]byte(sessionLogBucketName))
+	index := tx.Bucket([]byte(sessionSearchBucketName))
+	if bucket == nil || index == nil || len(terms) == 0 {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var prefixes [][]byte
+	lookup := func(seek []byte) {
+		c := index.Cursor()
+		for k, _ := c.Seek(seek); k != nil && bytes.HasPrefix(k, seek); k, _ = c.Next() {
+			prefix := k[bytes.IndexByte(k, 0)+1:]
+			if !seen[string(prefix)] {
+				seen[string(prefix)] = true
+				prefixes = append(prefixes, append([]byte(nil), prefix...))
+			}
+		}
+	}
+	lookup([]byte(searchKindID + terms[0]))
+	lookup(append([]byte(searchKindValue+terms[0]), 0))
+	sort.Slice(prefixes, func(i, j int) bool {
+		return bytes.Compare(prefixes[i], prefixes[j]) > 0
+	})
+
+	c := bucket.Cursor()
+	for _, prefix := range prefixes {
+		var value []byte
+		for k, v := c.Seek(prefix); k != nil && len(k) == len(prefix)+seqKeyLen && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			value = v
+		}
+		if value == nil {
+			continue
+		}
+
+		var session History
+		if err := codec.Unmarshal(value, &session); err != nil {
+			return err
+		}
+		if !searchMatches(session, terms[1:]) {
+			continue
+		}
+		if !fn(session) {
+			return nil
+		}
+	}
+	return nil
+}
+
+func deleteKeys(bucket *bolt.Bucket, keys [][]byte) error {
+	if bucket == nil {
+		return nil
+	}
+	for _, k := range keys {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterateLog streams the latest snapshots of sessions started within the given period, newest first.
+// Iteration stops when fn returns false.
+func iterateLog(tx *bolt.Tx, codec codec.MarshalUnmarshaler, from, to *time.Time, fn func(History) bool) error {
+	bucket := tx.Bucket([]byte(sessionLogBucketName))
+	if bucket == nil {
+		return nil
+	}
+
+	c := bucket.Cursor()
+	k, v := c.Last()
+	if to != nil {
+		if k, _ = c.Seek(timeKey(to.Add(time.Nanosecond))); k == nil {
+			k, v = c.Last()
+		} else {
+			k, v = c.Prev()
+		}
+	}
+
+	var lastPrefix []byte
+	for ; k != nil; k, v = c.Prev() {
+		if from != nil && keyTime(k).Before(*from) {
+			break
+		}
+
+		prefix := k[:len(k)-seqKeyLen]
+		if bytes.Equal(prefix, lastPrefix) {
+			continue
+		}
+		lastPrefix = append(lastPrefix[:0], prefix...)
+
+		var session History
+		if err := codec.Unmarshal(v, &session); err != nil {
+			return err
+		}
+		if !fn(session) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// compactLog drops superseded session snapshots and folds completed sessions
+// started before the given time into daily aggregates.
+func compactLog(tx *bolt.Tx, codec codec.MarshalUnmarshaler, before time.Time) (int, error) {
+	bucket := tx.Bucket([]byte(sessionLogBucketName))
+	if bucket == nil {
+		return 0, nil
+	}
+
+	var obsolete, obsoleteSearch [][]byte
+	aggregates := make(map[string]*dailyStats)
+
+	c := bucket.Cursor()
+	var lastPrefix []byte
+	for k, v := c.Last(); k != nil; k, v = c.Prev() {
+		prefix := k[:len(k)-seqKeyLen]
+		if bytes.Equal(prefix, lastPrefix) {
+			obsolete = append(obsolete, append([]byte(nil), k...))
+			continue
+		}
+		lastPrefix = append(lastPrefix[:0], prefix...)
+
+		if before.IsZero() || !keyTime(k).Before(before) {
+			continue
+		}
+		var session History
+		if err := codec.Unmarshal(v, &session); err != nil {
+			return 0, err
+		}
+		if session.Status != StatusCompleted {
+			continue
+		}
+
+		day := newDailyStats(session)
+		key := string(day.key())
+		if _, ok := aggregates[key]; !ok {
+			aggregates[key] = &day
+		}
+		aggregates[key].add(session)
+		obsolete = append(obsolete, append([]byte(nil), k...))
+		obsoleteSearch = append(obsoleteSearch, searchKeys(session)...)
+	}
+
+	if err := writeDailyStats(tx, codec, aggregates); err != nil {
+		return 0, err
+	}
+	if err := deleteKeys(bucket, obsolete); err != nil {
+		return 0, err
+	}
+	if err := deleteKeys(tx.Bucket([]byte(sessionSearchBucketName)), obsoleteSearch); err != nil {
+		return 0, err
+	}
+	return len(obsolete), nil
+}
+
+// pruneLog deletes sessions started before the given time, daily aggregates of the days ended
+// before it and the oldest sessions exceeding maxRows. Zero maxRows means no row limit.
+func pruneLog(tx *bolt.Tx, codec codec.MarshalUnmarshaler, before time.Time, maxRows int) (int, error) {
+	var obsolete, obsoleteSearch [][]byte
+	if bucket := tx.Bucket([]byte(sessionLogBucketName)); bucket != nil {
+		c := bucket.Cursor()
+		var rows int
+		var lastPrefix []byte
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			prefix := k[:len(k)-seqKeyLen]
+			latest := !bytes.Equal(prefix, lastPrefix)
+			if latest {
+				rows++
+				lastPrefix = append(lastPrefix[:0], prefix...)
+			}
+			if (maxRows > 0 && rows > maxRows) || keyTime(k).Before(before) {
+				obsolete = append(obsolete, append([]byte(nil), k...))
+				if !latest {
+					continue
+				}
+				var session History
+				if err := codec.Unmarshal(v, &session); err != nil {
+					return 0, err
+				}
+				obsoleteSearch = append(obsoleteSearch, searchKeys(session)...)
+			}
+		}
+		if err := deleteKeys(bucket, obsolete); err != nil {
+			return 0, err
+		}
+		if err := deleteKeys(tx.Bucket([]byte(sessionSearchBucketName)), obsoleteSearch); err != nil {
+			return 0, err
+		}
+	}
+	removed := len(obsolete)
+
+	if bucket := tx.Bucket([]byte(sessionDailyBucketName)); bucket != nil {
+		obsolete = obsolete[:0]
+		c := bucket.Cursor()
+		for k, _ := c.First(); k != nil && !keyTime(k).Add(stepDay).After(before); k, _ = c.Next() {
+			obsolete = append(obsolete, append([]byte(nil), k...))
+		}
+		for _, k := range obsolete {
+			if err := bucket.Delete(k); err != nil {
+				return 0, err
+			}
+		}
+		removed += len(obsolete)
+	}
+	return removed, nil
+}
+
+// dailyStats holds aggregated statistics of compacted sessions started during the same day.
+type dailyStats struct {
+	Day             time.Time
+	Direction       string
+	ServiceType     string
+	Status          string
+	Count           int
+	ConsumerCounts  map[string]int
+	SumDataSent     uint64
+	SumDataReceived uint64
+	SumDuration     time.Duration
+	SumTokens       money.Tokens
+}
+
+func newDailyStats(session History) dailyStats {
+	return dailyStats{
+		Day:            session.Started.Truncate(stepDay),
+		Direction:      session.Direction,
+		ServiceType:    session.ServiceType,
+		Status:         session.Status,
+		ConsumerCounts: make(map[string]int),
+	}
+}
+
+func (d *dailyStats) add(session History) {
+	d.Count++
+	d.ConsumerCounts[session.ConsumerID.Address]++
+	d.SumDataSent += session.DataSent
+	d.SumDataReceived += session.DataReceived
+	d.SumDuration += session.GetDuration()
+	d.SumTokens = d.SumTokens.Add(session.Tokens)
+}
+
+func (d *dailyStats) merge(other dailyStats) {
+	d.Count += other.Count
+	for consumer, count := range other.ConsumerCounts {
+		d.ConsumerCounts[consumer] += count
+	}
+	d.SumDataSent += other.SumDataSent
+	d.SumDataReceived += other.SumDataReceived
+	d.SumDuration += other.SumDuration
+	d.SumTokens = d.SumTokens.Add(other.SumTokens)
+}
+
+func (d *dailyStats) key() []byte {
+	key := timeKey(d.Day)
+	for _, dimension := range []string{d.Direction, d.ServiceType, d.Status} {
+		key = append(key, dimension...)
+		key = append(key, 0)
+	}
+	return key
+}
+
+func writeDailyStats(tx *bolt.Tx, codec codec.MarshalUnmarshaler, aggregates map[string]*dailyStats) error {
+	if len(aggregates) == 0 {
+		return nil
+	}
+
+	bucket, err := tx.CreateBucketIfNotExists([]byte(sessionDailyBucketName))
+	if err != nil {
+		return err
+	}
+	for key, aggregate := range aggregates {
+		if existing := bucket.Get([]byte(key)); existing != nil {
+			var stored dailyStats
+			if err := codec.Unmarshal(existing, &stored); err != nil {
+				return err
+			}
+			aggregate.merge(stored)
+		}
+
+		value, err := codec.Marshal(aggregate)
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put([]byte(key), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterateDailyStats streams daily aggregates of the days overlapping the given period.
+func iterateDailyStats(tx *bolt.Tx, codec codec.MarshalUnmarshaler, from, to *time.Time, fn func(dailyStats)) error {
+	bucket := tx.Bucket([]byte(sessionDailyBucketName))
+	if bucket == nil {
+		return nil
+	}
+
+	c := bucket.Cursor()
+	k, v := c.First()
+	if from != nil {
+		k, v = c.Seek(timeKey(from.Truncate(stepDay)))
+	}
+	for ; k != nil; k, v = c.Next() {
+		if to != nil && keyTime(k).After(*to) {
+			break
+		}
+
+		var aggregate dailyStats
+		if err := codec.Unmarshal(v, &aggregate); err != nil {
+			return err
+		}
+		fn(aggregate)
+	}
+	return nil
+}
+
+func logKey(started time.Time, sessionID session_node.ID, seq uint64) []byte {
+	var seqKey [seqKeyLen]byte
+	binary.BigEndian.PutUint64(seqKey[:], seq)
+	return append(logPrefix(started, sessionID), seqKey[:]...)
+}
+
+// logPrefix returns the key prefix shared by all snapshots of a session.
+func logPrefix(started time.Time, sessionID session_node.ID) []byte {
+	key := make([]byte, 0, timeKeyLen+2+len(sessionID)+seqKeyLen)
+	key = append(key, timeKey(started)...)
+	key = append(key, byte(len(sessionID)>>8), byte(len(sessionID)))
+	return append(key, sessionID...)
+}
+
+func searchKey(term string, prefix []byte) []byte {
+	key := make([]byte, 0, len(term)+1+len(prefix))
+	key = append(key, term...)
+	key = append(key, 0)
+	return append(key, prefix...)
+}
+
+// timeKey encodes time so that byte order of the keys matches chronological order.
+func timeKey(t time.Time) []byte {
+	key := make([]byte, timeKeyLen)
+	binary.BigEndian.PutUint64(key, uint64(t.Unix())^signBit)
+	binary.BigEndian.PutUint32(key[8:], uint32(t.Nanosecond()))
+	return key
+}
+
+func keyTime(key []byte) time.Time {
+	sec := int64(binary.BigEndian.Uint64(key) ^ signBit)
+	nsec := int64(binary.BigEndian.Uint32(key[8:timeKeyLen]))
+	return time.Unix(sec, nsec).UTC()
+}
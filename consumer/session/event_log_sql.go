@@ -0,0 +1,385 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Session log in SQL database keeps only the latest snapshot of every session,
+// compacted sessions are folded into daily aggregates the same way as in BoltDB.
+const sqlEventLogSchema = `
+CREATE TABLE IF NOT EXISTS session_log (
+	session_id   TEXT    NOT NULL PRIMARY KEY,
+	started      INTEGER NOT NULL,
+	direction    TEXT    NOT NULL,
+	service_type TEXT    NOT NULL,
+	status       TEXT    NOT NULL,
+	value        BLOB    NOT NULL
+);
+CREATE INDEX IF NOT EXISTS session_log_started ON session_log (started);
+CREATE TABLE IF NOT EXISTS session_daily (
+	day          INTEGER NOT NULL,
+	direction    TEXT    NOT NULL,
+	service_type TEXT    NOT NULL,
+	status       TEXT    NOT NULL,
+	value        BLOB    NOT NULL,
+	PRIMARY KEY (day, direction, service_type, status)
+);
+CREATE TABLE IF NOT EXISTS session_search (
+	term       TEXT NOT NULL,
+	session_id TEXT NOT NULL,
+	PRIMARY KEY (term, session_id)
+);`
+
+// sqlEventLog keeps session log in SQL database.
+type sqlEventLog struct {
+	db *sql.DB
+}
+
+func newSQLEventLog(db *sql.DB) (*sqlEventLog, error) {
+	if _, err := db.Exec(sqlEventLogSchema); err != nil {
+		return nil, err
+	}
+	l := &sqlEventLog{db: db}
+	if err := l.indexSessions(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// indexSessions adds the logged sessions to the search index, unless it is filled already.
+func (l *sqlEventLog) indexSessions() error {
+	var indexed bool
+	if err := l.db.QueryRow("SELECT EXISTS (SELECT 1 FROM session_search)").Scan(&indexed); err != nil || indexed {
+		return err
+	}
+
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT value FROM session_log")
+	if err != nil {
+		return err
+	}
+	var sessions []History
+	for rows.Next() {
+		var session History
+		if err := scanJSON(rows, &session); err != nil {
+			rows.Close()
+			return err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := closeRows(rows); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := indexSQLSession(tx, session); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (l *sqlEventLog) append(session History) error {
+	value, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"INSERT OR REPLACE INTO session_log (session_id, started, direction, service_type, status, value) VALUES (?, ?, ?, ?, ?, ?)",
+		string(session.SessionID), session.Started.UnixNano(), session.Direction, session.ServiceType, session.Status, value,
+	)
+	if err != nil {
+		return err
+	}
+	if err := indexSQLSession(tx, session); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func indexSQLSession(tx *sql.Tx, session History) error {
+	for _, term := range searchIndexTerms(session) {
+		_, err := tx.Exec("INSERT OR IGNORE INTO session_search (term, session_id) VALUES (?, ?)", term, string(session.SessionID))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *sqlEventLog) search(terms []string, fn func(History) bool) error {
+	if len(terms) == 0 {
+		return nil
+	}
+
+	idFrom := searchKindID + terms[0]
+	idCondition, args := "s.term >= ?", []interface{}{searchKindValue + terms[0], idFrom}
+	if idTo := prefixEnd(idFrom); idTo != "" {
+		idCondition += " AND s.term < ?"
+		args = append(args, idTo)
+	}
+	rows, err := l.db.Query(
+		"SELECT l.value FROM session_log l WHERE l.session_id IN ("+
+			"SELECT s.session_id FROM session_search s WHERE s.term = ? OR ("+idCondition+")"+
+			") ORDER BY l.started DESC, l.session_id DESC",
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var session History
+		if err := scanJSON(rows, &session); err != nil {
+			rows.Close()
+			return err
+		}
+		if !searchMatches(session, terms[1:]) {
+			continue
+		}
+		if !fn(session) {
+			break
+		}
+	}
+	return closeRows(rows)
+}
+
+func (l *sqlEventLog) read(from, to *time.Time, fn func(History) bool, fnDaily func(dailyStats)) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	where, args := periodCondition("started", from, to)
+	rows, err := tx.Query("SELECT value FROM session_log"+where+" ORDER BY started DESC, session_id DESC", args...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var session History
+		if err := scanJSON(rows, &session); err != nil {
+			rows.Close()
+			return err
+		}
+		if !fn(session) {
+			break
+		}
+	}
+	if err := closeRows(rows); err != nil || fnDaily == nil {
+		return err
+	}
+
+	if from != nil {
+		dayFrom := from.Truncate(stepDay)
+		from = &dayFrom
+	}
+	where, args = periodCondition("day", from, to)
+	rows, err = tx.Query("SELECT value FROM session_daily"+where+" ORDER BY day", args...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var aggregate dailyStats
+		if err := scanJSON(rows, &aggregate); err != nil {
+			rows.Close()
+			return err
+		}
+		fnDaily(aggregate)
+	}
+	return closeRows(rows)
+}
+
+func (l *sqlEventLog) compact(before time.Time) (int, error) {
+	if before.IsZero() {
+		return 0, nil
+	}
+
+	tx, err := l.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT value FROM session_log WHERE started < ? AND status = ?", before.UnixNano(), StatusCompleted)
+	if err != nil {
+		return 0, err
+	}
+	aggregates := make(map[string]*dailyStats)
+	for rows.Next() {
+		var session History
+		if err := scanJSON(rows, &session); err != nil {
+			rows.Close()
+			return 0, err
+		}
+
+		day := newDailyStats(session)
+		key := string(day.key())
+		if _, ok := aggregates[key]; !ok {
+			aggregates[key] = &day
+		}
+		aggregates[key].add(session)
+	}
+	if err := closeRows(rows); err != nil {
+		return 0, err
+	}
+
+	for _, aggregate := range aggregates {
+		var stored dailyStats
+		err := tx.QueryRow(
+			"SELECT value FROM session_daily WHERE day = ? AND direction = ? AND service_type = ? AND status = ?",
+			aggregate.Day.UnixNano(), aggregate.Direction, aggregate.ServiceType, aggregate.Status,
+		).Scan(jsonValue{&stored})
+		if err == nil {
+			aggregate.merge(stored)
+		} else if err != sql.ErrNoRows {
+			return 0, err
+		}
+
+		value, err := json.Marshal(aggregate)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.Exec(
+			"INSERT OR REPLACE INTO session_daily (day, direction, service_type, status, value) VALUES (?, ?, ?, ?, ?)",
+			aggregate.Day.UnixNano(), aggregate.Direction, aggregate.ServiceType, aggregate.Status, value,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	res, err := tx.Exec("DELETE FROM session_log WHERE started < ? AND status = ?", before.UnixNano(), StatusCompleted)
+	if err != nil {
+		return 0, err
+	}
+	removed, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if err := deleteUnloggedSearchTerms(tx); err != nil {
+		return 0, err
+	}
+	return int(removed), tx.Commit()
+}
+
+func (l *sqlEventLog) prune(before time.Time, maxRows int) (int, error) {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var removed int64
+	exec := func(query string, args ...interface{}) error {
+		res, err := tx.Exec(query, args...)
+		if err != nil {
+			return err
+		}
+		count, err := res.RowsAffected()
+		removed += count
+		return err
+	}
+
+	if !before.IsZero() {
+		if err := exec("DELETE FROM session_log WHERE started < ?", before.UnixNano()); err != nil {
+			return 0, err
+		}
+		if err := exec("DELETE FROM session_daily WHERE day <= ?", before.Add(-stepDay).UnixNano()); err != nil {
+			return 0, err
+		}
+	}
+	if maxRows > 0 {
+		err := exec(
+			"DELETE FROM session_log WHERE session_id IN (SELECT session_id FROM session_log ORDER BY started DESC, session_id DESC LIMIT -1 OFFSET ?)",
+			maxRows,
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+	if err := deleteUnloggedSearchTerms(tx); err != nil {
+		return 0, err
+	}
+	return int(removed), tx.Commit()
+}
+
+// deleteUnloggedSearchTerms removes search terms of the sessions deleted from the session log.
+func deleteUnloggedSearchTerms(tx *sql.Tx) error {
+	_, err := tx.Exec("DELETE FROM session_search WHERE session_id NOT IN (SELECT session_id FROM session_log)")
+	return err
+}
+
+func periodCondition(column string, from, to *time.Time) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+	if from != nil {
+		conditions = append(conditions, column+" >= ?")
+		args = append(args, from.UnixNano())
+	}
+	if to != nil {
+		conditions = append(conditions, column+" <= ?")
+		args = append(args, to.UnixNano())
+	}
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// jsonValue scans JSON encoded column into the given value.
+type jsonValue struct {
+	to interface{}
+}
+
+func (v jsonValue) Scan(src interface{}) error {
+	raw, ok := src.([]byte)
+	if !ok {
+		return fmt.Errorf("unsupported JSON column type %T", src)
+	}
+	return json.Unmarshal(raw, v.to)
+}
+
+func scanJSON(rows *sql.Rows, to interface{}) error {
+	return rows.Scan(jsonValue{to})
+}
+
+func closeRows(rows *sql.Rows) error {
+	if err := rows.Err(); err != nil {
+		rows.Close()
+		return err
+	}
+	return rows.Close()
+}
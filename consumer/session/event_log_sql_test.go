@@ -0,0 +1,221 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/sqlite"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	session_node "github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func newSQLStorageWithSessions(t *testing.T, sessions ...History) (*Storage, func()) {
+	dir, err := ioutil.TempDir("", "sessionStorageTest")
+	assert.NoError(t, err)
+
+	db, err := sqlite.NewStorage(dir)
+	assert.NoError(t, err)
+
+	storage, err := NewSQLSessionStorage(db.DB(), RetentionPolicy{})
+	assert.NoError(t, err)
+	for _, session := range sessions {
+		assert.NoError(t, storage.append(session))
+	}
+
+	return storage, func() {
+		db.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func TestSQLSessionStorage_Iterate(t *testing.T) {
+	// given
+	storage, storageCleanup := newSQLStorageWithSessions(
+		t,
+		History{SessionID: "session1", Started: time.Date(2020, 6, 17, 0, 0, 1, 0, time.UTC), Direction: DirectionConsumed},
+		History{SessionID: "session2", Started: time.Date(2020, 6, 17, 0, 0, 2, 0, time.UTC), Direction: DirectionProvided},
+		History{SessionID: "session3", Started: time.Date(2020, 6, 17, 0, 0, 3, 0, time.UTC), Direction: DirectionConsumed},
+		History{SessionID: "session3", Started: time.Date(2020, 6, 17, 0, 0, 3, 0, time.UTC), Direction: DirectionConsumed, Status: StatusCompleted},
+		History{SessionID: "session4", Started: time.Date(2020, 6, 17, 0, 0, 4, 0, time.UTC), Direction: DirectionConsumed},
+	)
+	defer storageCleanup()
+
+	// when
+	var sessions []History
+	query := NewQuery().
+		FilterDirection(DirectionConsumed).
+		FilterTo(time.Date(2020, 6, 17, 0, 0, 3, 0, time.UTC))
+	err := storage.Iterate(query, func(session History) bool {
+		sessions = append(sessions, session)
+		return true
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, session_node.ID("session3"), sessions[0].SessionID)
+	assert.Equal(t, StatusCompleted, sessions[0].Status)
+	assert.Equal(t, session_node.ID("session1"), sessions[1].SessionID)
+
+	// when
+	sessions = nil
+	err = storage.Iterate(NewQuery(), func(session History) bool {
+		sessions = append(sessions, session)
+		return false
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, session_node.ID("session4"), sessions[0].SessionID)
+}
+
+func TestSQLSessionStorage_CompactAndPrune(t *testing.T) {
+	// given
+	consumer := identity.FromAddress("consumer1")
+	storage, storageCleanup := newSQLStorageWithSessions(
+		t,
+		History{
+			SessionID:  "session1",
+			ConsumerID: consumer,
+			Tokens:     money.NewTokens(10),
+			Status:     StatusCompleted,
+			Started:    time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC),
+			Updated:    time.Date(2020, 5, 1, 10, 1, 0, 0, time.UTC),
+		},
+		History{
+			SessionID:  "session2",
+			ConsumerID: consumer,
+			Tokens:     money.NewTokens(5),
+			Status:     StatusCompleted,
+			Started:    time.Date(2020, 5, 2, 10, 0, 0, 0, time.UTC),
+			Updated:    time.Date(2020, 5, 2, 10, 1, 0, 0, time.UTC),
+		},
+		History{
+			SessionID:  "session3",
+			ConsumerID: consumer,
+			Status:     StatusCompleted,
+			Started:    time.Date(2020, 6, 16, 10, 0, 0, 0, time.UTC),
+			Updated:    time.Date(2020, 6, 16, 10, 1, 0, 0, time.UTC),
+		},
+	)
+	defer storageCleanup()
+	storage.timeGetter = func() time.Time {
+		return time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC)
+	}
+	storage.retention = RetentionPolicy{CompactAge: 30 * 24 * time.Hour}
+
+	// when
+	err := storage.Compact()
+
+	// then
+	assert.NoError(t, err)
+	query := NewQuery().FetchSessions().FetchStats()
+	assert.NoError(t, storage.Query(query))
+	assert.Len(t, query.Sessions, 1)
+	assert.Equal(t, 3, query.Stats.Count)
+	assert.Equal(t, money.NewTokens(15), query.Stats.SumTokens)
+	assert.Equal(t, 3*time.Minute, query.Stats.SumDuration)
+
+	query = NewQuery().
+		FilterFrom(time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)).
+		FilterTo(time.Date(2020, 5, 1, 23, 59, 59, 0, time.UTC)).
+		FetchStatsByDay()
+	assert.NoError(t, storage.Query(query))
+	assert.Equal(t, 1, query.StatsByDay[time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)].Count)
+
+	// when
+	removed, err := storage.DeleteBefore(time.Date(2020, 5, 2, 12, 0, 0, 0, time.UTC))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	// when
+	storage.retention = RetentionPolicy{MaxAge: 10 * 24 * time.Hour}
+	err = storage.Prune()
+
+	// then
+	assert.NoError(t, err)
+	query = NewQuery().FetchSessions().FetchStats()
+	assert.NoError(t, storage.Query(query))
+	assert.Len(t, query.Sessions, 1)
+	assert.Equal(t, 1, query.Stats.Count)
+
+	// when
+	assert.NoError(t, storage.append(History{SessionID: "session4", Started: time.Date(2020, 6, 16, 11, 0, 0, 0, time.UTC)}))
+	storage.retention = RetentionPolicy{MaxRows: 1}
+	err = storage.Prune()
+
+	// then
+	assert.NoError(t, err)
+	sessions, err := storage.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, session_node.ID("session4"), sessions[0].SessionID)
+}
+
+func TestSQLSessionStorage_Search(t *testing.T) {
+	// given
+	session1 := History{
+		SessionID:   "a1b2-session1",
+		ConsumerID:  identity.FromAddress("0xconsumer1"),
+		ServiceType: "wireguard",
+		Status:      StatusCompleted,
+		Started:     time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC),
+	}
+	session2 := History{
+		SessionID:   "a1c3-session2",
+		ConsumerID:  identity.FromAddress("0xconsumer1"),
+		ServiceType: "openvpn",
+		Started:     time.Date(2020, 6, 16, 10, 0, 0, 0, time.UTC),
+	}
+	storage, storageCleanup := newSQLStorageWithSessions(t, session1, session2)
+	defer storageCleanup()
+
+	// when
+	query := NewQuery().FilterText("A1").FetchSessions()
+	err := storage.Query(query)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{session2, session1}, query.Sessions)
+
+	// when
+	query = NewQuery().FilterText("0xconsumer1 wireguard").FetchSessions()
+	err = storage.Query(query)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{session1}, query.Sessions)
+
+	// when
+	_, err = storage.DeleteBefore(time.Date(2020, 6, 1, 0, 0, 0, 0, time.UTC))
+
+	// then
+	assert.NoError(t, err)
+	var terms int
+	assert.NoError(t, storage.events.(*sqlEventLog).db.QueryRow("SELECT COUNT(*) FROM session_search").Scan(&terms))
+	assert.Equal(t, 3, terms)
+}
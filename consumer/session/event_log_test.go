@@ -0,0 +1,351 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	session_node "github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestSessionStorage_LatestSnapshotWins(t *testing.T) {
+	// given
+	started := time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC)
+	storage, storageCleanup := newStorageWithSessions(
+		History{SessionID: "session1", Started: started, Status: StatusNew},
+		History{SessionID: "session10", Started: started, Status: StatusNew},
+		History{SessionID: "session1", Started: started, Status: StatusCompleted, Tokens: money.NewTokens(10)},
+	)
+	defer storageCleanup()
+
+	// when
+	sessions, err := storage.GetAll()
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	for _, session := range sessions {
+		if session.SessionID == "session1" {
+			assert.Equal(t, StatusCompleted, session.Status)
+			assert.Equal(t, money.NewTokens(10), session.Tokens)
+		} else {
+			assert.Equal(t, StatusNew, session.Status)
+		}
+	}
+}
+
+func TestSessionStorage_Iterate(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorageWithSessions(
+		History{SessionID: "session1", Started: time.Date(2020, 6, 17, 0, 0, 1, 0, time.UTC), Direction: DirectionConsumed},
+		History{SessionID: "session2", Started: time.Date(2020, 6, 17, 0, 0, 2, 0, time.UTC), Direction: DirectionProvided},
+		History{SessionID: "session3", Started: time.Date(2020, 6, 17, 0, 0, 3, 0, time.UTC), Direction: DirectionConsumed},
+		History{SessionID: "session4", Started: time.Date(2020, 6, 17, 0, 0, 4, 0, time.UTC), Direction: DirectionConsumed},
+	)
+	defer storageCleanup()
+
+	// when
+	var ids []session_node.ID
+	query := NewQuery().
+		FilterDirection(DirectionConsumed).
+		FilterTo(time.Date(2020, 6, 17, 0, 0, 3, 0, time.UTC))
+	err := storage.Iterate(query, func(session History) bool {
+		ids = append(ids, session.SessionID)
+		return true
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []session_node.ID{"session3", "session1"}, ids)
+
+	// when
+	ids = nil
+	err = storage.Iterate(NewQuery(), func(session History) bool {
+		ids = append(ids, session.SessionID)
+		return len(ids) < 2
+	})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []session_node.ID{"session4", "session3"}, ids)
+}
+
+func TestSessionStorage_Compact(t *testing.T) {
+	// given
+	consumer := identity.FromAddress("consumer1")
+	oldCompleted := History{
+		SessionID:    "session1",
+		Direction:    DirectionProvided,
+		ConsumerID:   consumer,
+		DataSent:     1000,
+		DataReceived: 100,
+		Tokens:       money.NewTokens(10),
+		Status:       StatusCompleted,
+		Started:      time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC),
+		Updated:      time.Date(2020, 5, 1, 10, 1, 0, 0, time.UTC),
+	}
+	oldActive := History{
+		SessionID:  "session2",
+		Direction:  DirectionProvided,
+		ConsumerID: consumer,
+		Status:     StatusNew,
+		Started:    time.Date(2020, 5, 1, 11, 0, 0, 0, time.UTC),
+		Updated:    time.Date(2020, 5, 1, 11, 0, 10, 0, time.UTC),
+	}
+	recent := History{
+		SessionID:  "session3",
+		Direction:  DirectionProvided,
+		ConsumerID: consumer,
+		Status:     StatusCompleted,
+		Started:    time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC),
+		Updated:    time.Date(2020, 6, 17, 10, 0, 30, 0, time.UTC),
+	}
+	oldCompletedFirst := oldCompleted
+	oldCompletedFirst.Status = StatusNew
+	oldCompletedFirst.Tokens = money.Tokens{}
+
+	storage, storageCleanup := newStorageWithSessions(oldCompletedFirst, oldActive, recent, oldCompleted)
+	defer storageCleanup()
+	storage.timeGetter = func() time.Time {
+		return time.Date(2020, 6, 18, 0, 0, 0, 0, time.UTC)
+	}
+	storage.retention = RetentionPolicy{CompactAge: 30 * 24 * time.Hour}
+
+	// when
+	statsBefore := NewQuery().FetchStats()
+	assert.NoError(t, storage.Query(statsBefore))
+	err := storage.Compact()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countLogRecords(t, storage))
+
+	query := NewQuery().FetchSessions().FetchStats()
+	assert.NoError(t, storage.Query(query))
+	assert.Equal(t, []History{recent, oldActive}, query.Sessions)
+	assert.Equal(t, statsBefore.Stats, query.Stats)
+
+	query = NewQuery().
+		FilterFrom(time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)).
+		FilterTo(time.Date(2020, 5, 1, 23, 59, 59, 0, time.UTC)).
+		FilterStatus(StatusCompleted).
+		FetchStatsByDay()
+	assert.NoError(t, storage.Query(query))
+	assert.Equal(
+		t,
+		map[time.Time]Stats{
+			time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC): {
+				Count:           1,
+				ConsumerCounts:  map[identity.Identity]int{consumer: 1},
+				SumDataSent:     1000,
+				SumDataReceived: 100,
+				SumTokens:       money.NewTokens(10),
+				SumDuration:     time.Minute,
+			},
+		},
+		query.StatsByDay,
+	)
+
+	// when compacted repeatedly aggregates are not duplicated
+	assert.NoError(t, storage.Compact())
+	query = NewQuery().FetchStats()
+	assert.NoError(t, storage.Query(query))
+	assert.Equal(t, statsBefore.Stats, query.Stats)
+}
+
+func TestSessionStorage_CompactKeepsSessionsByDefault(t *testing.T) {
+	// given
+	completed := History{SessionID: "session1", Started: time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC), Status: StatusCompleted}
+	completedFirst := completed
+	completedFirst.Status = StatusNew
+
+	storage, storageCleanup := newStorageWithSessions(completedFirst, completed)
+	defer storageCleanup()
+	storage.timeGetter = func() time.Time {
+		return time.Date(2020, 6, 18, 0, 0, 0, 0, time.UTC)
+	}
+
+	// when
+	err := storage.Compact()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, countLogRecords(t, storage))
+	sessions, err := storage.GetAll()
+	assert.NoError(t, err)
+	assert.Equal(t, []History{completed}, sessions)
+}
+
+func countLogRecords(t *testing.T, storage *Storage) int {
+	var count int
+	err := storage.events.(*boltEventLog).db.Bolt.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte(sessionLogBucketName))
+		if bucket == nil {
+			return nil
+		}
+		count = bucket.Stats().KeyN
+		return nil
+	})
+	assert.NoError(t, err)
+	return count
+}
+
+func TestSessionStorage_Prune(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorageWithSessions(
+		History{SessionID: "session1", Started: time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+		History{SessionID: "session2", Started: time.Date(2020, 6, 10, 10, 0, 0, 0, time.UTC), Status: StatusNew},
+		History{SessionID: "session2", Started: time.Date(2020, 6, 10, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+		History{SessionID: "session3", Started: time.Date(2020, 6, 15, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+		History{SessionID: "session4", Started: time.Date(2020, 6, 16, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+	)
+	defer storageCleanup()
+	storage.timeGetter = func() time.Time {
+		return time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC)
+	}
+
+	// when
+	storage.retention = RetentionPolicy{MaxAge: 10 * 24 * time.Hour}
+	err := storage.Prune()
+
+	// then
+	assert.NoError(t, err)
+	sessions, err := storage.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 3)
+
+	// when
+	storage.retention = RetentionPolicy{MaxRows: 2}
+	err = storage.Prune()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, countLogRecords(t, storage))
+	sessions, err = storage.GetAll()
+	assert.NoError(t, err)
+	assert.Len(t, sessions, 2)
+	assert.Equal(t, session_node.ID("session4"), sessions[0].SessionID)
+	assert.Equal(t, session_node.ID("session3"), sessions[1].SessionID)
+}
+
+func TestSessionStorage_DeleteBefore(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorageWithSessions(
+		History{SessionID: "session1", Started: time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+		History{SessionID: "session2", Started: time.Date(2020, 5, 2, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+		History{SessionID: "session3", Started: time.Date(2020, 6, 16, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+	)
+	defer storageCleanup()
+	storage.timeGetter = func() time.Time {
+		return time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC)
+	}
+	storage.retention = RetentionPolicy{CompactAge: 30 * 24 * time.Hour}
+	assert.NoError(t, storage.Compact())
+
+	// when
+	removed, err := storage.DeleteBefore(time.Date(2020, 5, 2, 12, 0, 0, 0, time.UTC))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 1, removed)
+
+	query := NewQuery().FetchSessions().FetchStats()
+	assert.NoError(t, storage.Query(query))
+	assert.Len(t, query.Sessions, 1)
+	assert.Equal(t, 2, query.Stats.Count)
+
+	// when
+	removed, err = storage.DeleteBefore(time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC))
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	query = NewQuery().FetchSessions().FetchStats()
+	assert.NoError(t, storage.Query(query))
+	assert.Len(t, query.Sessions, 0)
+	assert.Equal(t, 0, query.Stats.Count)
+}
+
+func TestSessionStorage_Encrypted(t *testing.T) {
+	// given
+	dir, err := ioutil.TempDir("", "sessionStorageTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	db, err := boltdb.NewEncryptedStorage(dir, "passphrase")
+	assert.NoError(t, err)
+	defer db.Close()
+
+	started := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	storage := NewSessionStorage(db, RetentionPolicy{CompactAge: 30 * 24 * time.Hour})
+	storage.timeGetter = func() time.Time {
+		return time.Date(2020, 6, 17, 0, 0, 0, 0, time.UTC)
+	}
+	assert.NoError(t, storage.append(History{SessionID: "session1", Started: started, Status: StatusCompleted, Tokens: money.NewTokens(10)}))
+	assert.NoError(t, storage.append(History{SessionID: "session2", Started: started.AddDate(0, 1, 0), Status: StatusCompleted}))
+
+	// when
+	err = storage.Compact()
+
+	// then
+	assert.NoError(t, err)
+	query := NewQuery().FetchSessions().FetchStats()
+	assert.NoError(t, storage.Query(query))
+	assert.Len(t, query.Sessions, 1)
+	assert.Equal(t, 2, query.Stats.Count)
+	assert.Equal(t, money.NewTokens(10), query.Stats.SumTokens)
+}
+
+func TestSessionStorage_SearchIndexIsPruned(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorageWithSessions(
+		History{SessionID: "session1", ServiceType: "wireguard", Started: time.Date(2020, 6, 1, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+		History{SessionID: "session2", ServiceType: "wireguard", Started: time.Date(2020, 6, 16, 10, 0, 0, 0, time.UTC), Status: StatusCompleted},
+	)
+	defer storageCleanup()
+
+	// when
+	_, err := storage.DeleteBefore(time.Date(2020, 6, 10, 0, 0, 0, 0, time.UTC))
+
+	// then
+	assert.NoError(t, err)
+	query := NewQuery().FilterText("wireguard").FetchSessions()
+	assert.NoError(t, storage.Query(query))
+	assert.Len(t, query.Sessions, 1)
+	assert.Equal(t, session_node.ID("session2"), query.Sessions[0].SessionID)
+	assert.Equal(t, 2, countSearchRecords(t, storage))
+}
+
+func countSearchRecords(t *testing.T, storage *Storage) int {
+	var count int
+	err := storage.events.(*boltEventLog).db.Bolt.View(func(tx *bolt.Tx) error {
+		count = tx.Bucket([]byte(sessionSearchBucketName)).Stats().KeyN
+		return nil
+	})
+	assert.NoError(t, err)
+	return count
+}
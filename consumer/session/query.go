@@ -19,16 +19,11 @@ package session
 
 import (
 	"time"
-
-	"github.com/asdine/storm/v3"
-	"github.com/asdine/storm/v3/q"
 )
 
 // NewQuery creates instance of new query.
 func NewQuery() *Query {
-	return &Query{
-		fetch: make([]q.Matcher, 0),
-	}
+	return &Query{}
 }
 
 // Query defines all flags for session filtering in session storage.
@@ -42,8 +37,10 @@ type Query struct {
 	filterDirection   *string
 	filterServiceType *string
 	filterStatus      *string
+	filterText        []string
 
-	fetch []q.Matcher
+	fetch      []func(History)
+	fetchDaily []func(dailyStats)
 }
 
 // FilterFrom filters fetched sessions from given time.
@@ -78,8 +75,23 @@ func (qr *Query) FilterStatus(status string) *Query {
 	return qr
 }
 
+// FilterText filters fetched sessions matching every word of the given text by session ID prefix,
+// consumer or provider address, provider country or service type, ignoring case.
+// Sessions are looked up in the search index, compacted sessions and daily aggregates are not matched.
+func (qr *Query) FilterText(text string) *Query {
+	qr.filterText = searchTerms(text)
+	return qr
+}
+
 // FetchSessions fetches list of sessions to Query.Sessions.
+// Sessions which were already compacted into daily aggregates are not listed.
 func (qr *Query) FetchSessions() *Query {
+	qr.Sessions = []History{}
+
+	qr.fetch = append(qr.fetch, func(session History) {
+		qr.Sessions = append(qr.Sessions, session)
+	})
+
 	return qr
 }
 
@@ -87,13 +99,12 @@ func (qr *Query) FetchSessions() *Query {
 func (qr *Query) FetchStats() *Query {
 	qr.Stats = NewStats()
 
-	qr.fetch = append(
-		qr.fetch,
-		matcher(func(session History) bool {
-			qr.Stats.Add(session)
-			return true
-		}),
-	)
+	qr.fetch = append(qr.fetch, func(session History) {
+		qr.Stats.Add(session)
+	})
+	qr.fetchDaily = append(qr.fetchDaily, func(day dailyStats) {
+		qr.Stats.addDaily(day)
+	})
 
 	return qr
 }
@@ -110,57 +121,86 @@ func (qr *Query) FetchStatsByDay() *Query {
 		}
 	}
 
-	qr.fetch = append(
-		qr.fetch,
-		matcher(func(session History) bool {
-			i := session.Started.Truncate(stepDay)
-
-			stats := qr.StatsByDay[i]
-			stats.Add(session)
-			qr.StatsByDay[i] = stats
-			return true
-		}),
-	)
+	qr.fetch = append(qr.fetch, func(session History) {
+		qr.addToDay(session.Started, func(stats *Stats) { stats.Add(session) })
+	})
+	qr.fetchDaily = append(qr.fetchDaily, func(day dailyStats) {
+		qr.addToDay(day.Day, func(stats *Stats) { stats.addDaily(day) })
+	})
 
 	return qr
 }
 
-func (qr *Query) run(node storm.Node) error {
-	where := make([]q.Matcher, 0)
-	if qr.filterFrom != nil {
-		where = append(where, q.Gte("Started", qr.filterFrom))
-	}
-	if qr.filterTo != nil {
-		where = append(where, q.Lte("Started", qr.filterTo))
-	}
-	if qr.filterDirection != nil {
-		where = append(where, q.Eq("Direction", qr.filterDirection))
-	}
-	if qr.filterServiceType != nil {
-		where = append(where, q.Eq("ServiceType", qr.filterServiceType))
+func (qr *Query) addToDay(t time.Time, add func(stats *Stats)) {
+	i := t.Truncate(stepDay)
+
+	stats, ok := qr.StatsByDay[i]
+	if !ok {
+		stats = NewStats()
 	}
-	if qr.filterStatus != nil {
-		where = append(where, q.Eq("Status", qr.filterStatus))
+	add(&stats)
+	qr.StatsByDay[i] = stats
+}
+
+func (qr *Query) run(events eventLog) error {
+	fetch := func(session History) bool {
+		if qr.matches(session.Direction, session.ServiceType, session.Status) {
+			for _, fetch := range qr.fetch {
+				fetch(session)
+			}
+		}
+		return true
 	}
 
-	sq := node.
-		Select(
-			q.And(where...),
-			q.And(qr.fetch...),
-		).
-		OrderBy("Started").
-		Reverse()
-
-	err := sq.Find(&qr.Sessions)
-	if err == storm.ErrNotFound {
-		qr.Sessions = []History{}
-		return nil
+	var fetchDaily func(dailyStats)
+	if len(qr.fetchDaily) > 0 && len(qr.filterText) == 0 {
+		fetchDaily = func(day dailyStats) {
+			if !qr.matches(day.Direction, day.ServiceType, day.Status) {
+				return
+			}
+			for _, fetch := range qr.fetchDaily {
+				fetch(day)
+			}
+		}
 	}
-	return err
+
+	return qr.read(events, fetch, fetchDaily)
+}
+
+func (qr *Query) iterate(events eventLog, fn func(History) bool) error {
+	return qr.read(events, func(session History) bool {
+		if !qr.matches(session.Direction, session.ServiceType, session.Status) {
+			return true
+		}
+		return fn(session)
+	}, nil)
 }
 
-type matcher func(History) bool
+func (qr *Query) read(events eventLog, fn func(History) bool, fnDaily func(dailyStats)) error {
+	if len(qr.filterText) == 0 {
+		return events.read(qr.filterFrom, qr.filterTo, fn, fnDaily)
+	}
+
+	return events.search(qr.filterText, func(session History) bool {
+		if qr.filterFrom != nil && session.Started.Before(*qr.filterFrom) {
+			return true
+		}
+		if qr.filterTo != nil && session.Started.After(*qr.filterTo) {
+			return true
+		}
+		return fn(session)
+	})
+}
 
-func (m matcher) Match(i interface{}) (bool, error) {
-	return m(i.(History)), nil
+func (qr *Query) matches(direction, serviceType, status string) bool {
+	if qr.filterDirection != nil && *qr.filterDirection != direction {
+		return false
+	}
+	if qr.filterServiceType != nil && *qr.filterServiceType != serviceType {
+		return false
+	}
+	if qr.filterStatus != nil && *qr.filterStatus != status {
+		return false
+	}
+	return true
 }
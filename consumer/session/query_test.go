@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
 	session_node "github.com/mysteriumnetwork/node/session"
 	"github.com/stretchr/testify/assert"
 )
@@ -79,7 +80,7 @@ func TestSessionQuery_FetchStats(t *testing.T) {
 		ConsumerID:   identity.FromAddress("consumer1"),
 		DataSent:     1234,
 		DataReceived: 123,
-		Tokens:       12,
+		Tokens:       money.NewTokens(12),
 		Started:      time.Date(2020, 6, 17, 10, 11, 12, 0, time.UTC),
 		Updated:      time.Date(2020, 6, 17, 10, 11, 32, 0, time.UTC),
 		Status:       "New",
@@ -101,7 +102,7 @@ func TestSessionQuery_FetchStats(t *testing.T) {
 			},
 			SumDataSent:     1234,
 			SumDataReceived: 123,
-			SumTokens:       12,
+			SumTokens:       money.NewTokens(12),
 			SumDuration:     20 * time.Second,
 		},
 		query.Stats,
@@ -123,7 +124,7 @@ func TestSessionQuery_FetchStatsByDay(t *testing.T) {
 		ConsumerID:   identity.FromAddress("consumer1"),
 		DataSent:     1234,
 		DataReceived: 123,
-		Tokens:       12,
+		Tokens:       money.NewTokens(12),
 		Started:      time.Date(2020, 6, 17, 10, 11, 12, 0, time.UTC),
 		Updated:      time.Date(2020, 6, 17, 10, 11, 32, 0, time.UTC),
 		Status:       "New",
@@ -165,7 +166,7 @@ func TestSessionQuery_FetchStatsByDay(t *testing.T) {
 				},
 				SumDataSent:     1234,
 				SumDataReceived: 123,
-				SumTokens:       12,
+				SumTokens:       money.NewTokens(12),
 				SumDuration:     20 * time.Second,
 			},
 			time.Date(2020, 6, 18, 0, 0, 0, 0, time.UTC): NewStats(),
@@ -192,3 +193,52 @@ func TestSessionQuery_FetchStatsByDay(t *testing.T) {
 	)
 	return
 }
+
+func TestSessionQuery_FilterText(t *testing.T) {
+	// given
+	session1 := History{
+		SessionID:       session_node.ID("a1b2-session1"),
+		ConsumerID:      identity.FromAddress("0xConsumer1"),
+		ProviderID:      identity.FromAddress("0xProvider1"),
+		ProviderCountry: "LT",
+		ServiceType:     "wireguard",
+		Started:         time.Date(2020, 6, 17, 0, 0, 1, 0, time.UTC),
+	}
+	session2 := History{
+		SessionID:       session_node.ID("c3d4-session2"),
+		ConsumerID:      identity.FromAddress("0xConsumer2"),
+		ProviderID:      identity.FromAddress("0xProvider1"),
+		ProviderCountry: "US",
+		ServiceType:     "openvpn",
+		Started:         time.Date(2020, 6, 17, 0, 0, 2, 0, time.UTC),
+	}
+	session2Completed := session2
+	session2Completed.Status = StatusCompleted
+	storage, storageCleanup := newStorageWithSessions(session1, session2, session2Completed)
+	defer storageCleanup()
+
+	for text, expected := range map[string][]History{
+		"0xprovider1":           {session2Completed, session1},
+		"a1b2":                  {session1},
+		"0xPROVIDER1 us":        {session2Completed},
+		"wireguard 0xconsumer1": {session1},
+		"wireguard us":          {},
+		"0xprov":                {},
+	} {
+		// when
+		query := NewQuery().FilterText(text).FetchSessions()
+		err := storage.Query(query)
+
+		// then
+		assert.NoError(t, err)
+		assert.Equal(t, expected, query.Sessions, text)
+	}
+
+	// when
+	query := NewQuery().FilterText("0xprovider1").FilterTo(time.Date(2020, 6, 17, 0, 0, 1, 0, time.UTC)).FetchSessions()
+	err := storage.Query(query)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []History{session1}, query.Sessions)
+}
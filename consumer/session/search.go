@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"strings"
+)
+
+// Sessions are looked up by search terms of two kinds: session ID terms matched by prefix
+// and value terms (consumer and provider addresses, provider country, service type) matched exactly.
+// Both are lowercased and prefixed by their kind, so that a single ordered index serves both lookups.
+const (
+	searchKindID    = "#"
+	searchKindValue = "="
+)
+
+// searchTerms splits free-text search into lowercased words, every one of which must match a session.
+func searchTerms(text string) []string {
+	return strings.Fields(strings.ToLower(text))
+}
+
+// searchIndexTerms returns index terms of the given session.
+func searchIndexTerms(session History) []string {
+	terms := []string{searchKindID + strings.ToLower(string(session.SessionID))}
+	seen := make(map[string]bool)
+	for _, value := range []string{
+		session.ConsumerID.Address,
+		session.ProviderID.Address,
+		session.ProviderCountry,
+		session.ServiceType,
+	} {
+		value = strings.ToLower(value)
+		if value == "" || seen[value] {
+			continue
+		}
+		seen[value] = true
+		terms = append(terms, searchKindValue+value)
+	}
+	return terms
+}
+
+// searchMatches checks if the session matches every given search term.
+func searchMatches(session History, terms []string) bool {
+	indexTerms := searchIndexTerms(session)
+	for _, term := range terms {
+		if !strings.HasPrefix(indexTerms[0], searchKindID+term) && !containsString(indexTerms[1:], searchKindValue+term) {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixEnd returns the smallest string greater than every string having the given prefix,
+// empty string means there is no upper bound.
+func prefixEnd(prefix string) string {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return string(end[:i+1])
+		}
+	}
+	return ""
+}
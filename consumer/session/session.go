@@ -20,7 +20,10 @@ package session
 import (
 	"time"
 
+	"github.com/mysteriumnetwork/node/consumer/traffic"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
 	node_session "github.com/mysteriumnetwork/node/session"
 )
 
@@ -49,7 +52,29 @@ type History struct {
 	ProviderCountry string
 	DataSent        uint64
 	DataReceived    uint64
-	Tokens          uint64
+	Tokens          money.Tokens
+	KeyRotations    int
+	KeyRotated      time.Time
+	// DetectedCountry is set when the connection was found exiting in a different country than ProviderCountry.
+	DetectedCountry  string
+	LocationMismatch bool
+	// TrafficDiverged is set when traffic counted by the other party diverged, PeerDataTransferred is the total it reported.
+	TrafficDiverged     bool
+	PeerDataTransferred uint64
+	// TerminationReason tells why the session ended, see session.Termination* constants.
+	TerminationReason string
+	// NATTraversal is the method used to reach the peer, see p2p.Traversal* constants.
+	NATTraversal   string
+	PaymentVersion string
+	// QoSClass is the QoS class negotiated with provider.
+	QoSClass string
+	// SpeedTest* hold the result of the last connection speed test, see connection.SpeedTestTarget.
+	SpeedTestTarget   string
+	SpeedTestLatency  time.Duration
+	SpeedTestDownload datasize.BitSpeed
+	SpeedTestUpload   datasize.BitSpeed
+	// Traffic is tunneled traffic split per destination, set for sessions made with traffic breakdown enabled.
+	Traffic traffic.Breakdown
 
 	Status  string
 	Started time.Time
@@ -64,3 +89,13 @@ func (se *History) GetDuration() time.Duration {
 	}
 	return ended.Sub(se.Started)
 }
+
+// GetThroughput returns average speed of data transferred in both directions during the session.
+func (se *History) GetThroughput() datasize.BitSpeed {
+	seconds := se.GetDuration().Seconds()
+	if seconds <= 0 {
+		return 0
+	}
+	transferred := datasize.FromBytes(se.DataSent + se.DataReceived)
+	return datasize.BitSpeed(float64(transferred) / seconds)
+}
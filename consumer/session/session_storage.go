@@ -18,6 +18,7 @@
 package session
 
 import (
+	"database/sql"
 	"sync"
 	"time"
 
@@ -25,32 +26,62 @@ import (
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
 	session_node "github.com/mysteriumnetwork/node/session"
 	session_event "github.com/mysteriumnetwork/node/session/event"
 	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/rs/zerolog/log"
 )
 
-const sessionStorageBucketName = "session-history"
+// RetentionPolicy limits the size of session history.
+// Zero values disable the corresponding limit.
+type RetentionPolicy struct {
+	// MaxAge is a period after which sessions and their daily aggregates are deleted.
+	MaxAge time.Duration
+	// MaxRows is a maximum number of individual sessions kept in the log.
+	MaxRows int
+	// CompactAge is a period after which completed sessions are folded into daily aggregates
+	// and are no longer kept individually.
+	CompactAge time.Duration
+}
 
 type timeGetter func() time.Time
 
 // Storage contains functions for storing, getting session objects.
 type Storage struct {
-	storage    *boltdb.Bolt
+	events     eventLog
 	timeGetter timeGetter
+	retention  RetentionPolicy
 
 	mu             sync.RWMutex
 	sessionsActive map[session_node.ID]History
+
+	stop     chan struct{}
+	stopOnce sync.Once
 }
 
 // NewSessionStorage creates session repository with given dependencies.
-func NewSessionStorage(storage *boltdb.Bolt) *Storage {
+func NewSessionStorage(storage *boltdb.Bolt, retention RetentionPolicy) *Storage {
+	return newSessionStorage(&boltEventLog{db: storage.DB()}, retention)
+}
+
+// NewSQLSessionStorage creates session repository keeping session history in SQL database.
+func NewSQLSessionStorage(db *sql.DB, retention RetentionPolicy) (*Storage, error) {
+	events, err := newSQLEventLog(db)
+	if err != nil {
+		return nil, err
+	}
+	return newSessionStorage(events, retention), nil
+}
+
+func newSessionStorage(events eventLog, retention RetentionPolicy) *Storage {
 	return &Storage{
-		storage:    storage,
+		events:     events,
 		timeGetter: time.Now,
+		retention:  retention,
 
 		sessionsActive: make(map[session_node.ID]History),
+		stop:           make(chan struct{}),
 	}
 }
 
@@ -65,18 +96,107 @@ func (repo *Storage) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(session_event.AppTopicTokensEarned, repo.consumeServiceSessionEarningsEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(session_event.AppTopicKeyRotated, repo.consumeServiceSessionKeyRotatedEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(session_event.AppTopicTrafficDivergence, repo.consumeTrafficDivergenceEvent); err != nil {
+		return err
+	}
 	if err := bus.Subscribe(connection.AppTopicConnectionSession, repo.consumeConnectionSessionEvent); err != nil {
 		return err
 	}
 	if err := bus.Subscribe(connection.AppTopicConnectionStatistics, repo.consumeConnectionStatisticsEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(connection.AppTopicConnectionLocationMismatch, repo.consumeConnectionLocationMismatchEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(connection.AppTopicConnectionSpeedTest, repo.consumeConnectionSpeedTestEvent); err != nil {
+		return err
+	}
+	if err := bus.Subscribe(connection.AppTopicConnectionTraffic, repo.consumeConnectionTrafficEvent); err != nil {
+		return err
+	}
 	return bus.Subscribe(pingpong_event.AppTopicInvoicePaid, repo.consumeConnectionSpendingEvent)
 }
 
 // Query executes given query.
 func (repo *Storage) Query(query *Query) (err error) {
-	return query.run(repo.storage.DB().From(sessionStorageBucketName))
+	return query.run(repo.events)
+}
+
+// Iterate streams sessions matching the query filters newest first, without loading them into memory.
+// Iteration stops when fn returns false.
+func (repo *Storage) Iterate(query *Query, fn func(History) bool) error {
+	return query.iterate(repo.events, fn)
+}
+
+// Compact drops superseded session snapshots from the log and folds completed sessions
+// older than the retention compaction age into daily aggregates. Sessions are never folded
+// when compaction age is not set.
+func (repo *Storage) Compact() error {
+	var before time.Time
+	if repo.retention.CompactAge > 0 {
+		before = repo.timeGetter().UTC().Add(-repo.retention.CompactAge)
+	}
+	compacted, err := repo.events.compact(before)
+	if err == nil {
+		log.Debug().Msgf("Session log compacted, %d records removed", compacted)
+	}
+	return err
+}
+
+// Prune deletes sessions exceeding the retention policy.
+func (repo *Storage) Prune() error {
+	var before time.Time
+	if repo.retention.MaxAge > 0 {
+		before = repo.timeGetter().UTC().Add(-repo.retention.MaxAge)
+	}
+	if before.IsZero() && repo.retention.MaxRows == 0 {
+		return nil
+	}
+
+	removed, err := repo.events.prune(before, repo.retention.MaxRows)
+	if err == nil {
+		log.Debug().Msgf("Session log pruned, %d records removed", removed)
+	}
+	return err
+}
+
+// DeleteBefore deletes sessions started before the given time together with
+// daily aggregates of the days ended before it. Returns count of removed records.
+func (repo *Storage) DeleteBefore(before time.Time) (int, error) {
+	return repo.events.prune(before.UTC(), 0)
+}
+
+// StartMaintenance compacts and prunes session log periodically until storage is stopped.
+func (repo *Storage) StartMaintenance(interval time.Duration) {
+	go func() {
+		for {
+			select {
+			case <-time.After(interval):
+				if err := repo.Compact(); err != nil {
+					log.Error().Err(err).Msg("Session log compaction failed")
+				}
+				if err := repo.Prune(); err != nil {
+					log.Error().Err(err).Msg("Session log pruning failed")
+				}
+			case <-repo.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops the session log maintenance.
+func (repo *Storage) Stop() {
+	repo.stopOnce.Do(func() {
+		close(repo.stop)
+	})
+}
+
+func (repo *Storage) append(row History) error {
+	return repo.events.append(row)
 }
 
 // GetAll returns array of all sessions.
@@ -91,7 +211,7 @@ func (repo *Storage) consumeServiceSessionEvent(e session_event.AppEventSession)
 
 	switch e.Status {
 	case session_event.RemovedStatus:
-		repo.handleEndedEvent(sessionID)
+		repo.handleEndedEvent(sessionID, e.Session.TerminationReason)
 	case session_event.CreatedStatus:
 		repo.mu.Lock()
 		repo.sessionsActive[sessionID] = History{
@@ -102,6 +222,9 @@ func (repo *Storage) consumeServiceSessionEvent(e session_event.AppEventSession)
 			ProviderID:      identity.FromAddress(e.Session.Proposal.ProviderID),
 			ServiceType:     e.Session.Proposal.ServiceType,
 			ProviderCountry: e.Session.Proposal.ServiceDefinition.GetLocation().Country,
+			NATTraversal:    e.Session.NATTraversal,
+			PaymentVersion:  e.Session.PaymentVersion,
+			QoSClass:        e.Session.QoSClass,
 			Started:         e.Session.StartedAt.UTC(),
 		}
 		repo.mu.Unlock()
@@ -141,13 +264,56 @@ func (repo *Storage) consumeServiceSessionEarningsEvent(e session_event.AppEvent
 	repo.sessionsActive[sessionID] = row
 }
 
+func (repo *Storage) consumeServiceSessionKeyRotatedEvent(e session_event.AppEventKeyRotated) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sessionID := session_node.ID(e.SessionID)
+	row, ok := repo.sessionsActive[sessionID]
+	if !ok {
+		log.Warn().Msg("Received a unknown session update")
+		return
+	}
+
+	row.KeyRotations++
+	row.KeyRotated = e.RotatedAt.UTC()
+	repo.sessionsActive[sessionID] = row
+}
+
+func (repo *Storage) consumeTrafficDivergenceEvent(e session_event.AppEventTrafficDivergence) {
+	if !e.Diverged {
+		return
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sessionID := session_node.ID(e.SessionID)
+	row, ok := repo.sessionsActive[sessionID]
+	if !ok {
+		log.Warn().Msg("Received a unknown session update")
+		return
+	}
+	row.TrafficDiverged = true
+	row.PeerDataTransferred = e.Remote
+
+	err := repo.append(row)
+	if err != nil {
+		log.Error().Err(err).Msgf("Session %v update failed", sessionID)
+		return
+	}
+
+	repo.sessionsActive[sessionID] = row
+	log.Debug().Msgf("Session %v marked with traffic divergence", sessionID)
+}
+
 // consumeConnectionSessionEvent consumes the session state change events
 func (repo *Storage) consumeConnectionSessionEvent(e connection.AppEventConnectionSession) {
 	sessionID := e.SessionInfo.SessionID
 
 	switch e.Status {
 	case connection.SessionEndedStatus:
-		repo.handleEndedEvent(sessionID)
+		repo.handleEndedEvent(sessionID, e.SessionInfo.TerminationReason)
 	case connection.SessionCreatedStatus:
 		repo.mu.Lock()
 		repo.sessionsActive[sessionID] = History{
@@ -158,6 +324,9 @@ func (repo *Storage) consumeConnectionSessionEvent(e connection.AppEventConnecti
 			ProviderID:      identity.FromAddress(e.SessionInfo.Proposal.ProviderID),
 			ServiceType:     e.SessionInfo.Proposal.ServiceType,
 			ProviderCountry: e.SessionInfo.Proposal.ServiceDefinition.GetLocation().Country,
+			NATTraversal:    e.SessionInfo.NATTraversal,
+			PaymentVersion:  e.SessionInfo.PaymentVersion,
+			QoSClass:        e.SessionInfo.QoSClass,
 			Started:         e.SessionInfo.StartedAt.UTC(),
 		}
 		repo.mu.Unlock()
@@ -181,6 +350,68 @@ func (repo *Storage) consumeConnectionStatisticsEvent(e connection.AppEventConne
 	repo.sessionsActive[e.SessionInfo.SessionID] = row
 }
 
+func (repo *Storage) consumeConnectionTrafficEvent(e connection.AppEventConnectionTraffic) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	row, ok := repo.sessionsActive[e.SessionInfo.SessionID]
+	if !ok {
+		log.Warn().Msg("Received a unknown session update")
+		return
+	}
+
+	row.Traffic = e.Breakdown
+	repo.sessionsActive[e.SessionInfo.SessionID] = row
+}
+
+func (repo *Storage) consumeConnectionLocationMismatchEvent(e connection.AppEventConnectionLocationMismatch) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sessionID := e.SessionInfo.SessionID
+	row, ok := repo.sessionsActive[sessionID]
+	if !ok {
+		log.Warn().Msg("Received a unknown session update")
+		return
+	}
+	row.DetectedCountry = e.DetectedCountry
+	row.LocationMismatch = true
+
+	err := repo.append(row)
+	if err != nil {
+		log.Error().Err(err).Msgf("Session %v update failed", sessionID)
+		return
+	}
+
+	repo.sessionsActive[sessionID] = row
+	log.Debug().Msgf("Session %v marked with location mismatch", sessionID)
+}
+
+func (repo *Storage) consumeConnectionSpeedTestEvent(e connection.AppEventConnectionSpeedTest) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	sessionID := e.SessionInfo.SessionID
+	row, ok := repo.sessionsActive[sessionID]
+	if !ok {
+		log.Warn().Msg("Received a unknown session update")
+		return
+	}
+	row.SpeedTestTarget = string(e.Result.Target)
+	row.SpeedTestLatency = e.Result.Latency
+	row.SpeedTestDownload = e.Result.Download
+	row.SpeedTestUpload = e.Result.Upload
+
+	err := repo.append(row)
+	if err != nil {
+		log.Error().Err(err).Msgf("Session %v update failed", sessionID)
+		return
+	}
+
+	repo.sessionsActive[sessionID] = row
+	log.Debug().Msgf("Session %v speed test recorded", sessionID)
+}
+
 func (repo *Storage) consumeConnectionSpendingEvent(e pingpong_event.AppEventInvoicePaid) {
 	repo.mu.Lock()
 	defer repo.mu.Unlock()
@@ -192,9 +423,9 @@ func (repo *Storage) consumeConnectionSpendingEvent(e pingpong_event.AppEventInv
 		return
 	}
 	row.Updated = repo.timeGetter().UTC()
-	row.Tokens = e.Invoice.AgreementTotal
+	row.Tokens = money.NewTokens(e.Invoice.AgreementTotal)
 
-	err := repo.storage.Update(sessionStorageBucketName, &row)
+	err := repo.append(row)
 	if err != nil {
 		log.Error().Err(err).Msgf("Session %v update failed", sessionID)
 		return
@@ -204,7 +435,7 @@ func (repo *Storage) consumeConnectionSpendingEvent(e pingpong_event.AppEventInv
 	log.Debug().Msgf("Session %v updated", sessionID)
 }
 
-func (repo *Storage) handleEndedEvent(sessionID session_node.ID) {
+func (repo *Storage) handleEndedEvent(sessionID session_node.ID, terminationReason string) {
 	repo.mu.RLock()
 	defer repo.mu.RUnlock()
 
@@ -215,8 +446,9 @@ func (repo *Storage) handleEndedEvent(sessionID session_node.ID) {
 	}
 	row.Updated = repo.timeGetter().UTC()
 	row.Status = StatusCompleted
+	row.TerminationReason = terminationReason
 
-	err := repo.storage.Update(sessionStorageBucketName, &row)
+	err := repo.append(row)
 	if err != nil {
 		log.Error().Err(err).Msgf("Session %v update failed", sessionID)
 		return
@@ -237,7 +469,7 @@ func (repo *Storage) handleCreatedEvent(sessionID session_node.ID) {
 	}
 	row.Status = StatusNew
 
-	err := repo.storage.Store(sessionStorageBucketName, &row)
+	err := repo.append(row)
 	if err != nil {
 		log.Error().Err(err).Msgf("Session %v insert failed", row.SessionID)
 		return
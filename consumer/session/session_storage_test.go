@@ -24,10 +24,13 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/consumer/traffic"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
 	session_node "github.com/mysteriumnetwork/node/session"
 	session_event "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
@@ -127,7 +130,11 @@ func TestSessionStorage_consumeServiceSessionsEvent(t *testing.T) {
 	})
 	storage.consumeServiceSessionEarningsEvent(session_event.AppEventTokensEarned{
 		SessionID: serviceSessionMock.ID,
-		Total:     12,
+		Total:     money.NewTokens(12),
+	})
+	storage.consumeServiceSessionKeyRotatedEvent(session_event.AppEventKeyRotated{
+		SessionID: serviceSessionMock.ID,
+		RotatedAt: time.Date(2020, 4, 1, 11, 0, 0, 0, time.UTC),
 	})
 	storage.consumeServiceSessionEvent(session_event.AppEventSession{
 		Status:  session_event.RemovedStatus,
@@ -152,7 +159,9 @@ func TestSessionStorage_consumeServiceSessionsEvent(t *testing.T) {
 				Updated:         time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC),
 				DataSent:        1234,
 				DataReceived:    123,
-				Tokens:          12,
+				Tokens:          money.NewTokens(12),
+				KeyRotations:    1,
+				KeyRotated:      time.Date(2020, 4, 1, 11, 0, 0, 0, time.UTC),
 			},
 		},
 		sessions,
@@ -166,19 +175,24 @@ func TestSessionStorage_consumeEventEndedOK(t *testing.T) {
 		return time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC)
 	}
 	defer storageCleanup()
+	sessionInfo := connectionSessionMock
+	sessionInfo.NATTraversal = "hole_punching"
+	sessionInfo.PaymentVersion = "v3"
+	sessionInfo.QoSClass = "premium"
 
 	// when
 	storage.consumeConnectionSessionEvent(connection.AppEventConnectionSession{
 		Status:      connection.SessionCreatedStatus,
-		SessionInfo: connectionSessionMock,
+		SessionInfo: sessionInfo,
 	})
 	storage.consumeConnectionStatisticsEvent(connection.AppEventConnectionStatistics{
 		Stats:       connectionStatsMock,
-		SessionInfo: connectionSessionMock,
+		SessionInfo: sessionInfo,
 	})
+	sessionInfo.TerminationReason = session_node.TerminationPeerLost
 	storage.consumeConnectionSessionEvent(connection.AppEventConnectionSession{
 		Status:      connection.SessionEndedStatus,
-		SessionInfo: connectionSessionMock,
+		SessionInfo: sessionInfo,
 	})
 
 	// then
@@ -200,6 +214,11 @@ func TestSessionStorage_consumeEventEndedOK(t *testing.T) {
 				Updated:         time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC),
 				DataSent:        connectionStatsMock.BytesSent,
 				DataReceived:    connectionStatsMock.BytesReceived,
+
+				TerminationReason: session_node.TerminationPeerLost,
+				NATTraversal:      "hole_punching",
+				PaymentVersion:    "v3",
+				QoSClass:          "premium",
 			},
 		},
 		sessions,
@@ -303,7 +322,171 @@ func TestSessionStorage_consumeSessionSpendingEvent(t *testing.T) {
 				Started:         time.Date(2020, 4, 1, 10, 11, 12, 0, time.UTC),
 				Status:          "New",
 				Updated:         time.Date(2020, 4, 1, 12, 0, 0, 0, time.UTC),
-				Tokens:          connectionInvoiceMock.AgreementTotal,
+				Tokens:          money.NewTokens(connectionInvoiceMock.AgreementTotal),
+			},
+		},
+		sessions,
+	)
+}
+
+func TestSessionStorage_consumeConnectionLocationMismatchEvent(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	defer storageCleanup()
+
+	// when
+	storage.consumeConnectionSessionEvent(connection.AppEventConnectionSession{
+		Status:      connection.SessionCreatedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+	storage.consumeConnectionLocationMismatchEvent(connection.AppEventConnectionLocationMismatch{
+		SessionInfo:     connectionSessionMock,
+		IP:              "1.2.3.4",
+		ExpectedCountry: "MU",
+		DetectedCountry: "DE",
+	})
+
+	// then
+	sessions, err := storage.GetAll()
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]History{
+			{
+				SessionID:        session_node.ID("sessionID"),
+				Direction:        "Consumed",
+				ConsumerID:       identity.FromAddress("consumerID"),
+				AccountantID:     "0x00000000000000000000000000000000000000AC",
+				ProviderID:       identity.FromAddress("providerID"),
+				ServiceType:      "serviceType",
+				ProviderCountry:  "MU",
+				DetectedCountry:  "DE",
+				LocationMismatch: true,
+				Started:          time.Date(2020, 4, 1, 10, 11, 12, 0, time.UTC),
+				Status:           "New",
+			},
+		},
+		sessions,
+	)
+}
+
+func TestSessionStorage_consumeConnectionSpeedTestEvent(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	defer storageCleanup()
+
+	// when
+	storage.consumeConnectionSessionEvent(connection.AppEventConnectionSession{
+		Status:      connection.SessionCreatedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+	storage.consumeConnectionSpeedTestEvent(connection.AppEventConnectionSpeedTest{
+		SessionInfo: connectionSessionMock,
+		Result: connection.SpeedTestResult{
+			Target:   connection.SpeedTestTargetProvider,
+			Latency:  20 * time.Millisecond,
+			Download: datasize.BitSpeed(100 * datasize.MiB),
+			Upload:   datasize.BitSpeed(10 * datasize.MiB),
+		},
+	})
+
+	// then
+	sessions, err := storage.GetAll()
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]History{
+			{
+				SessionID:         session_node.ID("sessionID"),
+				Direction:         "Consumed",
+				ConsumerID:        identity.FromAddress("consumerID"),
+				AccountantID:      "0x00000000000000000000000000000000000000AC",
+				ProviderID:        identity.FromAddress("providerID"),
+				ServiceType:       "serviceType",
+				ProviderCountry:   "MU",
+				SpeedTestTarget:   "provider",
+				SpeedTestLatency:  20 * time.Millisecond,
+				SpeedTestDownload: datasize.BitSpeed(100 * datasize.MiB),
+				SpeedTestUpload:   datasize.BitSpeed(10 * datasize.MiB),
+				Started:           time.Date(2020, 4, 1, 10, 11, 12, 0, time.UTC),
+				Status:            "New",
+			},
+		},
+		sessions,
+	)
+}
+
+func TestSessionStorage_consumeConnectionTrafficEvent(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	defer storageCleanup()
+	breakdown := traffic.Breakdown{
+		Categories: []traffic.Usage{{Name: "video", BytesSent: 10, BytesReceived: 1000}},
+		Hosts:      []traffic.Usage{{Name: "rr1.googlevideo.com", BytesSent: 10, BytesReceived: 1000}},
+	}
+
+	// when
+	storage.consumeConnectionSessionEvent(connection.AppEventConnectionSession{
+		Status:      connection.SessionCreatedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+	storage.consumeConnectionTrafficEvent(connection.AppEventConnectionTraffic{
+		SessionInfo: connectionSessionMock,
+		Breakdown:   breakdown,
+	})
+	storage.consumeConnectionSessionEvent(connection.AppEventConnectionSession{
+		Status:      connection.SessionEndedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+
+	// then
+	sessions, err := storage.GetAll()
+	assert.Nil(t, err)
+	assert.Len(t, sessions, 1)
+	assert.Equal(t, breakdown, sessions[0].Traffic)
+}
+
+func TestSessionStorage_consumeTrafficDivergenceEvent(t *testing.T) {
+	// given
+	storage, storageCleanup := newStorage()
+	defer storageCleanup()
+
+	// when
+	storage.consumeConnectionSessionEvent(connection.AppEventConnectionSession{
+		Status:      connection.SessionCreatedStatus,
+		SessionInfo: connectionSessionMock,
+	})
+	storage.consumeTrafficDivergenceEvent(session_event.AppEventTrafficDivergence{
+		SessionID: "sessionID",
+		Local:     1000,
+		Remote:    5000,
+		Diverged:  true,
+	})
+	storage.consumeTrafficDivergenceEvent(session_event.AppEventTrafficDivergence{
+		SessionID: "sessionID",
+		Local:     6000,
+		Remote:    6000,
+		Diverged:  false,
+	})
+
+	// then
+	sessions, err := storage.GetAll()
+	assert.Nil(t, err)
+	assert.Equal(
+		t,
+		[]History{
+			{
+				SessionID:           session_node.ID("sessionID"),
+				Direction:           "Consumed",
+				ConsumerID:          identity.FromAddress("consumerID"),
+				AccountantID:        "0x00000000000000000000000000000000000000AC",
+				ProviderID:          identity.FromAddress("providerID"),
+				ServiceType:         "serviceType",
+				ProviderCountry:     "MU",
+				TrafficDiverged:     true,
+				PeerDataTransferred: 5000,
+				Started:             time.Date(2020, 4, 1, 10, 11, 12, 0, time.UTC),
+				Status:              "New",
 			},
 		},
 		sessions,
@@ -321,7 +504,7 @@ func newStorage() (*Storage, func()) {
 		panic(err)
 	}
 
-	return NewSessionStorage(db), func() {
+	return NewSessionStorage(db, RetentionPolicy{}), func() {
 		err := db.Close()
 		if err != nil {
 			panic(err)
@@ -337,8 +520,7 @@ func newStorage() (*Storage, func()) {
 func newStorageWithSessions(sessions ...History) (*Storage, func()) {
 	storage, storageCleanup := newStorage()
 	for _, session := range sessions {
-		err := storage.storage.Store(sessionStorageBucketName, &session)
-		if err != nil {
+		if err := storage.append(session); err != nil {
 			panic(err)
 		}
 	}
@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistory_GetThroughput(t *testing.T) {
+	started := time.Date(2020, 4, 1, 10, 0, 0, 0, time.UTC)
+	history := History{
+		DataSent:     datasize.MiB.Bytes(),
+		DataReceived: 3 * datasize.MiB.Bytes(),
+		Started:      started,
+		Updated:      started.Add(4 * time.Second),
+	}
+	assert.Equal(t, datasize.BitSpeed(datasize.MiB), history.GetThroughput())
+
+	history.Updated = started
+	assert.Equal(t, datasize.BitSpeed(0), history.GetThroughput())
+}
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
 )
 
 // NewStats initiates zero Stats instance.
@@ -37,7 +38,7 @@ type Stats struct {
 	SumDataSent     uint64
 	SumDataReceived uint64
 	SumDuration     time.Duration
-	SumTokens       uint64
+	SumTokens       money.Tokens
 }
 
 // Add accumulates given session to statistics.
@@ -53,5 +54,18 @@ func (s *Stats) Add(session History) {
 	s.SumDataReceived += session.DataReceived
 	s.SumDataSent += session.DataSent
 	s.SumDuration += session.GetDuration()
-	s.SumTokens += session.Tokens
+	s.SumTokens = s.SumTokens.Add(session.Tokens)
+}
+
+// addDaily accumulates given daily aggregate of compacted sessions to statistics.
+func (s *Stats) addDaily(d dailyStats) {
+	s.Count += d.Count
+	for consumer, count := range d.ConsumerCounts {
+		s.ConsumerCounts[identity.FromAddress(consumer)] += count
+	}
+
+	s.SumDataReceived += d.SumDataReceived
+	s.SumDataSent += d.SumDataSent
+	s.SumDuration += d.SumDuration
+	s.SumTokens = s.SumTokens.Add(d.SumTokens)
 }
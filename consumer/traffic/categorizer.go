@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traffic
+
+import "strings"
+
+// Category names a kind of destinations, e.g. video streaming.
+type Category string
+
+const (
+	// CategoryVideo is video streaming.
+	CategoryVideo = Category("video")
+	// CategoryMusic is music streaming.
+	CategoryMusic = Category("music")
+	// CategorySocial is social networks.
+	CategorySocial = Category("social")
+	// CategoryMessaging is messengers and voice calls.
+	CategoryMessaging = Category("messaging")
+	// CategoryGaming is game stores and online games.
+	CategoryGaming = Category("gaming")
+	// CategorySoftware is software downloads and updates.
+	CategorySoftware = Category("software")
+	// CategoryOther is everything not matching any other category, including destinations with unknown names.
+	CategoryOther = Category("other")
+)
+
+var defaultDomains = map[string]Category{
+	"youtube.com":     CategoryVideo,
+	"googlevideo.com": CategoryVideo,
+	"ytimg.com":       CategoryVideo,
+	"netflix.com":     CategoryVideo,
+	"nflxvideo.net":   CategoryVideo,
+	"twitch.tv":       CategoryVideo,
+	"ttvnw.net":       CategoryVideo,
+	"vimeo.com":       CategoryVideo,
+	"hulu.com":        CategoryVideo,
+	"disneyplus.com":  CategoryVideo,
+	"primevideo.com":  CategoryVideo,
+
+	"spotify.com":    CategoryMusic,
+	"scdn.co":        CategoryMusic,
+	"soundcloud.com": CategoryMusic,
+	"deezer.com":     CategoryMusic,
+
+	"facebook.com":     CategorySocial,
+	"fbcdn.net":        CategorySocial,
+	"instagram.com":    CategorySocial,
+	"cdninstagram.com": CategorySocial,
+	"twitter.com":      CategorySocial,
+	"twimg.com":        CategorySocial,
+	"tiktok.com":       CategorySocial,
+	"tiktokcdn.com":    CategorySocial,
+	"reddit.com":       CategorySocial,
+	"redditmedia.com":  CategorySocial,
+	"linkedin.com":     CategorySocial,
+	"pinterest.com":    CategorySocial,
+	"snapchat.com":     CategorySocial,
+	"vk.com":           CategorySocial,
+	"tumblr.com":       CategorySocial,
+
+	"whatsapp.com":   CategoryMessaging,
+	"whatsapp.net":   CategoryMessaging,
+	"telegram.org":   CategoryMessaging,
+	"signal.org":     CategoryMessaging,
+	"discord.com":    CategoryMessaging,
+	"discordapp.com": CategoryMessaging,
+	"slack.com":      CategoryMessaging,
+	"skype.com":      CategoryMessaging,
+	"zoom.us":        CategoryMessaging,
+
+	"steampowered.com": CategoryGaming,
+	"steamcontent.com": CategoryGaming,
+	"epicgames.com":    CategoryGaming,
+	"xboxlive.com":     CategoryGaming,
+	"playstation.net":  CategoryGaming,
+
+	"windowsupdate.com":     CategorySoftware,
+	"update.microsoft.com":  CategorySoftware,
+	"swcdn.apple.com":       CategorySoftware,
+	"github.com":            CategorySoftware,
+	"githubusercontent.com": CategorySoftware,
+	"docker.io":             CategorySoftware,
+	"dl.google.com":         CategorySoftware,
+	"download.mozilla.org":  CategorySoftware,
+}
+
+// Categorizer resolves categories of host names by their domains.
+type Categorizer struct {
+	domains map[string]Category
+}
+
+// NewCategorizer creates categorizer matching host names to the given domains and their subdomains.
+func NewCategorizer(domains map[string]Category) *Categorizer {
+	normalized := make(map[string]Category, len(domains))
+	for domain, category := range domains {
+		normalized[normalizeHost(domain)] = category
+	}
+	return &Categorizer{domains: normalized}
+}
+
+// DefaultCategorizer creates categorizer of well known domains.
+func DefaultCategorizer() *Categorizer {
+	return NewCategorizer(defaultDomains)
+}
+
+// Categorize returns category of the longest domain matching the host, CategoryOther if none matches.
+func (c *Categorizer) Categorize(host string) Category {
+	host = normalizeHost(host)
+	for host != "" {
+		if category, ok := c.domains[host]; ok {
+			return category
+		}
+		i := strings.IndexByte(host, '.')
+		if i < 0 {
+			break
+		}
+		host = host[i+1:]
+	}
+	return CategoryOther
+}
+
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(host), ".")
+}
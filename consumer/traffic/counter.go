@@ -0,0 +1,197 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traffic
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/miekg/dns"
+)
+
+const (
+	// maxNames limits how many learned IP to host name mappings are kept.
+	maxNames = 4096
+	// maxHosts limits how many hosts are accounted separately, traffic of the rest is accounted in categories only.
+	maxHosts = 256
+)
+
+// Usage is traffic transferred with a destination.
+type Usage struct {
+	Name          string
+	BytesSent     uint64
+	BytesReceived uint64
+}
+
+// Total returns bytes transferred in both directions.
+func (u Usage) Total() uint64 {
+	return u.BytesSent + u.BytesReceived
+}
+
+// Breakdown is tunneled traffic split per destination categories and hosts, sorted by the total usage.
+type Breakdown struct {
+	Categories []Usage
+	Hosts      []Usage
+}
+
+// Counter accounts tunneled IP packets per destination.
+// Host names of destinations are learned from DNS answers and TLS server names seen in the tunnel,
+// destinations without known name are accounted by their IP in CategoryOther.
+type Counter struct {
+	categorizer *Categorizer
+
+	mu         sync.Mutex
+	names      map[string]string
+	categories map[Category]*Usage
+	hosts      map[string]*Usage
+}
+
+// NewCounter creates packet counter using given categorizer.
+func NewCounter(categorizer *Categorizer) *Counter {
+	return &Counter{
+		categorizer: categorizer,
+		names:       make(map[string]string),
+		categories:  make(map[Category]*Usage),
+		hosts:       make(map[string]*Usage),
+	}
+}
+
+// Outbound accounts IP packet sent into the tunnel.
+func (c *Counter) Outbound(b []byte) {
+	p, ok := parsePacket(b)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if p.protocol == protocolTCP {
+		if name, ok := serverName(p.payload); ok {
+			c.learn(p.dst.String(), name)
+		}
+	}
+	c.usage(p.dst.String(), func(u *Usage) {
+		u.BytesSent += uint64(len(b))
+	})
+}
+
+// Inbound accounts IP packet received from the tunnel.
+func (c *Counter) Inbound(b []byte) {
+	p, ok := parsePacket(b)
+	if !ok {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if p.protocol == protocolUDP && p.srcPort == dnsPort {
+		c.learnDNS(p.payload)
+	}
+	c.usage(p.src.String(), func(u *Usage) {
+		u.BytesReceived += uint64(len(b))
+	})
+}
+
+// Breakdown returns traffic accounted so far.
+func (c *Counter) Breakdown() Breakdown {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	breakdown := Breakdown{
+		Categories: make([]Usage, 0, len(c.categories)),
+		Hosts:      make([]Usage, 0, len(c.hosts)),
+	}
+	for _, u := range c.categories {
+		breakdown.Categories = append(breakdown.Categories, *u)
+	}
+	for _, u := range c.hosts {
+		breakdown.Hosts = append(breakdown.Hosts, *u)
+	}
+	sortUsage(breakdown.Categories)
+	sortUsage(breakdown.Hosts)
+	return breakdown
+}
+
+func (c *Counter) usage(ip string, add func(u *Usage)) {
+	host, ok := c.names[ip]
+	if !ok {
+		host = ip
+	}
+
+	category := CategoryOther
+	if ok {
+		category = c.categorizer.Categorize(host)
+	}
+	u, ok := c.categories[category]
+	if !ok {
+		u = &Usage{Name: string(category)}
+		c.categories[category] = u
+	}
+	add(u)
+
+	u, ok = c.hosts[host]
+	if !ok {
+		if len(c.hosts) >= maxHosts {
+			return
+		}
+		u = &Usage{Name: host}
+		c.hosts[host] = u
+	}
+	add(u)
+}
+
+func (c *Counter) learnDNS(payload []byte) {
+	msg := new(dns.Msg)
+	if err := msg.Unpack(payload); err != nil || len(msg.Question) == 0 {
+		return
+	}
+
+	// Answers are accounted to the name asked for, not to the names of CNAME chain.
+	name := msg.Question[0].Name
+	for _, rr := range msg.Answer {
+		switch record := rr.(type) {
+		case *dns.A:
+			c.learn(record.A.String(), name)
+		case *dns.AAAA:
+			c.learn(record.AAAA.String(), name)
+		}
+	}
+}
+
+func (c *Counter) learn(ip, name string) {
+	name = strings.TrimSuffix(strings.ToLower(name), ".")
+	if name == "" {
+		return
+	}
+	if _, ok := c.names[ip]; !ok && len(c.names) >= maxNames {
+		c.names = make(map[string]string)
+	}
+	c.names[ip] = name
+}
+
+func sortUsage(usage []Usage) {
+	sort.Slice(usage, func(i, j int) bool {
+		if usage[i].Total() != usage[j].Total() {
+			return usage[i].Total() > usage[j].Total()
+		}
+		return usage[i].Name < usage[j].Name
+	})
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traffic
+
+import (
+	"crypto/tls"
+	"encoding/binary"
+	"net"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategorizer_Categorize(t *testing.T) {
+	categorizer := NewCategorizer(map[string]Category{
+		"example.com":     CategorySocial,
+		"cdn.example.com": CategoryVideo,
+	})
+
+	assert.Equal(t, CategorySocial, categorizer.Categorize("example.com"))
+	assert.Equal(t, CategorySocial, categorizer.Categorize("www.Example.com."))
+	assert.Equal(t, CategoryVideo, categorizer.Categorize("eu.cdn.example.com"))
+	assert.Equal(t, CategoryOther, categorizer.Categorize("notexample.com"))
+	assert.Equal(t, CategoryOther, categorizer.Categorize(""))
+}
+
+func TestCounter_AccountsDestinationsLearnedFromDNS(t *testing.T) {
+	// given
+	counter := NewCounter(DefaultCategorizer())
+	resolver, client := net.ParseIP("10.182.0.1"), net.ParseIP("10.182.0.2")
+	video := net.ParseIP("142.250.0.10")
+
+	// when
+	counter.Inbound(udpPacket(resolver, client, dnsPort, 40000, dnsAnswer(t, "rr1.googlevideo.com.", video)))
+	counter.Outbound(tcpPacket(client, video, 40001, 443, make([]byte, 100)))
+	counter.Inbound(tcpPacket(video, client, 443, 40001, make([]byte, 1000)))
+
+	// then
+	breakdown := counter.Breakdown()
+	require.Len(t, breakdown.Categories, 2)
+	assert.Equal(t, Usage{Name: "video", BytesSent: 140, BytesReceived: 1040}, breakdown.Categories[0])
+	assert.Equal(t, "other", breakdown.Categories[1].Name)
+	require.Len(t, breakdown.Hosts, 2)
+	assert.Equal(t, Usage{Name: "rr1.googlevideo.com", BytesSent: 140, BytesReceived: 1040}, breakdown.Hosts[0])
+	assert.Equal(t, "10.182.0.1", breakdown.Hosts[1].Name)
+}
+
+func TestCounter_AccountsDestinationsLearnedFromTLS(t *testing.T) {
+	// given
+	counter := NewCounter(DefaultCategorizer())
+	client, social := net.ParseIP("10.182.0.2"), net.ParseIP("157.240.0.35")
+
+	// when
+	counter.Outbound(tcpPacket(client, social, 40001, 443, clientHello(t, "www.instagram.com")))
+	counter.Inbound(tcpPacket(social, client, 443, 40001, make([]byte, 500)))
+
+	// then
+	breakdown := counter.Breakdown()
+	require.Len(t, breakdown.Categories, 1)
+	assert.Equal(t, "social", breakdown.Categories[0].Name)
+	assert.Equal(t, uint64(540), breakdown.Categories[0].BytesReceived)
+	require.Len(t, breakdown.Hosts, 1)
+	assert.Equal(t, "www.instagram.com", breakdown.Hosts[0].Name)
+}
+
+func TestCounter_AccountsUnknownDestinationsByIP(t *testing.T) {
+	// given
+	counter := NewCounter(DefaultCategorizer())
+	client, unknown := net.ParseIP("fd00::2"), net.ParseIP("2001:db8::1")
+
+	// when
+	counter.Outbound(udpPacket(client, unknown, 40000, 443, make([]byte, 52)))
+	counter.Outbound([]byte{0x00, 0x01})
+
+	// then
+	assert.Equal(
+		t,
+		Breakdown{
+			Categories: []Usage{{Name: "other", BytesSent: 100}},
+			Hosts:      []Usage{{Name: "2001:db8::1", BytesSent: 100}},
+		},
+		counter.Breakdown(),
+	)
+}
+
+func TestCounter_LimitsAccountedHosts(t *testing.T) {
+	// given
+	counter := NewCounter(DefaultCategorizer())
+	client := net.ParseIP("10.182.0.2")
+
+	// when
+	for i := 0; i < maxHosts+10; i++ {
+		counter.Outbound(udpPacket(client, net.IPv4(198, 51, byte(i>>8), byte(i)), 40000, 443, nil))
+	}
+
+	// then
+	breakdown := counter.Breakdown()
+	assert.Len(t, breakdown.Hosts, maxHosts)
+	assert.Equal(t, uint64(28*(maxHosts+10)), breakdown.Categories[0].BytesSent)
+}
+
+func udpPacket(src, dst net.IP, srcPort, dstPort uint16, payload []byte) []byte {
+	transport := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint16(transport[0:], srcPort)
+	binary.BigEndian.PutUint16(transport[2:], dstPort)
+	binary.BigEndian.PutUint16(transport[4:], uint16(8+len(payload)))
+	return ipPacket(src, dst, protocolUDP, append(transport, payload...))
+}
+
+func tcpPacket(src, dst net.IP, srcPort, dstPort uint16, payload []byte) []byte {
+	transport := make([]byte, 20, 20+len(payload))
+	binary.BigEndian.PutUint16(transport[0:], srcPort)
+	binary.BigEndian.PutUint16(transport[2:], dstPort)
+	transport[12] = 5 << 4
+	return ipPacket(src, dst, protocolTCP, append(transport, payload...))
+}
+
+func ipPacket(src, dst net.IP, protocol byte, transport []byte) []byte {
+	if src.To4() == nil {
+		header := make([]byte, 40)
+		header[0] = 6 << 4
+		binary.BigEndian.PutUint16(header[4:], uint16(len(transport)))
+		header[6] = protocol
+		copy(header[8:], src.To16())
+		copy(header[24:], dst.To16())
+		return append(header, transport...)
+	}
+
+	header := make([]byte, 20)
+	header[0] = 4<<4 | 5
+	binary.BigEndian.PutUint16(header[2:], uint16(20+len(transport)))
+	header[9] = protocol
+	copy(header[12:], src.To4())
+	copy(header[16:], dst.To4())
+	return append(header, transport...)
+}
+
+func dnsAnswer(t *testing.T, name string, ip net.IP) []byte {
+	msg := new(dns.Msg).SetQuestion(name, dns.TypeA)
+	msg.Response = true
+	msg.Answer = []dns.RR{&dns.A{
+		Hdr: dns.RR_Header{Name: name, Rrtype: dns.TypeA, Class: dns.ClassINET, Ttl: 60},
+		A:   ip,
+	}}
+	b, err := msg.Pack()
+	require.NoError(t, err)
+	return b
+}
+
+// clientHello captures the first TLS record sent by client connecting to the given server.
+func clientHello(t *testing.T, serverName string) []byte {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	go func() {
+		defer client.Close()
+		tls.Client(client, &tls.Config{ServerName: serverName}).Handshake()
+	}()
+
+	header := make([]byte, 5)
+	_, err := server.Read(header)
+	require.NoError(t, err)
+	record := make([]byte, 5+int(binary.BigEndian.Uint16(header[3:])))
+	copy(record, header)
+	for n := 5; n < len(record); {
+		read, err := server.Read(record[n:])
+		require.NoError(t, err)
+		n += read
+	}
+	return record
+}
@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package traffic
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+const (
+	protocolTCP = 6
+	protocolUDP = 17
+
+	dnsPort = 53
+)
+
+// packet holds the parts of IP packet needed to account it.
+type packet struct {
+	src, dst         net.IP
+	protocol         byte
+	srcPort, dstPort uint16
+	payload          []byte
+}
+
+// parsePacket parses IPv4 or IPv6 packet, ports and payload are left empty
+// for non-first fragments and packets with IPv6 extension headers.
+func parsePacket(b []byte) (p packet, ok bool) {
+	if len(b) == 0 {
+		return p, false
+	}
+
+	var transport []byte
+	switch b[0] >> 4 {
+	case 4:
+		if len(b) < 20 {
+			return p, false
+		}
+		headerLen := int(b[0]&0x0f) * 4
+		if headerLen < 20 || len(b) < headerLen {
+			return p, false
+		}
+		p.protocol = b[9]
+		p.src, p.dst = net.IP(b[12:16]), net.IP(b[16:20])
+		if fragmentOffset := binary.BigEndian.Uint16(b[6:8]) & 0x1fff; fragmentOffset == 0 {
+			transport = b[headerLen:]
+		}
+	case 6:
+		if len(b) < 40 {
+			return p, false
+		}
+		p.protocol = b[6]
+		p.src, p.dst = net.IP(b[8:24]), net.IP(b[24:40])
+		transport = b[40:]
+	default:
+		return p, false
+	}
+
+	switch p.protocol {
+	case protocolTCP:
+		if len(transport) < 20 {
+			return p, true
+		}
+		dataOffset := int(transport[12]>>4) * 4
+		if dataOffset < 20 || len(transport) < dataOffset {
+			return p, true
+		}
+		p.payload = transport[dataOffset:]
+	case protocolUDP:
+		if len(transport) < 8 {
+			return p, true
+		}
+		p.payload = transport[8:]
+	default:
+		return p, true
+	}
+	p.srcPort = binary.BigEndian.Uint16(transport[0:2])
+	p.dstPort = binary.BigEndian.Uint16(transport[2:4])
+	return p, true
+}
+
+// serverName extracts server name indication of TLS ClientHello message,
+// the message is expected to fit into the given TCP segment.
+func serverName(b []byte) (string, bool) {
+	// TLS record: type, version, length.
+	if len(b) < 5 || b[0] != 0x16 {
+		return "", false
+	}
+	b = b[5:]
+	// Handshake: type, length, client version, random.
+	if len(b) < 38 || b[0] != 0x01 {
+		return "", false
+	}
+	b = b[38:]
+
+	var ok bool
+	if b, ok = skipVector(b, 1); !ok { // session id
+		return "", false
+	}
+	if b, ok = skipVector(b, 2); !ok { // cipher suites
+		return "", false
+	}
+	if b, ok = skipVector(b, 1); !ok { // compression methods
+		return "", false
+	}
+	if len(b) < 2 {
+		return "", false
+	}
+	extensions := b[2:]
+	if n := int(binary.BigEndian.Uint16(b)); n < len(extensions) {
+		extensions = extensions[:n]
+	}
+
+	for len(extensions) >= 4 {
+		extType := binary.BigEndian.Uint16(extensions)
+		extLen := int(binary.BigEndian.Uint16(extensions[2:]))
+		if len(extensions) < 4+extLen {
+			return "", false
+		}
+		ext := extensions[4 : 4+extLen]
+		extensions = extensions[4+extLen:]
+		if extType != 0 {
+			continue
+		}
+
+		// Server name list: length, then entries of type and name.
+		if len(ext) < 2 {
+			return "", false
+		}
+		list := ext[2:]
+		for len(list) >= 3 {
+			nameType := list[0]
+			nameLen := int(binary.BigEndian.Uint16(list[1:]))
+			if len(list) < 3+nameLen {
+				return "", false
+			}
+			if nameType == 0 {
+				return string(list[3 : 3+nameLen]), true
+			}
+			list = list[3+nameLen:]
+		}
+		return "", false
+	}
+	return "", false
+}
+
+func skipVector(b []byte, lengthSize int) ([]byte, bool) {
+	if len(b) < lengthSize {
+		return nil, false
+	}
+	n := int(b[0])
+	if lengthSize == 2 {
+		n = int(binary.BigEndian.Uint16(b))
+	}
+	if len(b) < lengthSize+n {
+		return nil, false
+	}
+	return b[lengthSize+n:], true
+}
@@ -19,12 +19,14 @@ package connection
 
 import (
 	"net"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/session"
+	"github.com/pkg/errors"
 )
 
 // ConnectParams holds plugin specific params
@@ -33,6 +35,19 @@ type ConnectParams struct {
 	DisableKillSwitch bool
 	// DNS servers to use
 	DNS DNSOption
+	// MaxDuration disconnects the session after the given time, zero means no limit
+	MaxDuration time.Duration
+	// MaxCost disconnects the session once the total paid for it reaches the given amount, zero means no limit
+	MaxCost uint64
+	// MaxTraffic disconnects the session once the given amount of bytes is transferred in both directions, zero means no limit
+	MaxTraffic uint64
+	// AdBlock blocks ad and tracker domains in tunnel DNS queries
+	AdBlock bool
+	// RoutingTable isolates the tunnel routes in the given policy routing table selected by the equal fwmark,
+	// system DNS and the kill switch are left untouched for such connections, zero means the main table
+	RoutingTable int
+	// TrafficBreakdown accounts tunneled traffic per destination category locally
+	TrafficBreakdown bool
 }
 
 // ConnectOptions represents the params we need to ensure a successful connection
@@ -46,4 +61,23 @@ type ConnectOptions struct {
 	ProviderNATConn *net.UDPConn
 	ChannelConn     *net.UDPConn
 	AccountantID    common.Address
+	// DNSProxy serves tunnel DNS queries locally if set
+	DNSProxy DNSProxy
+	// PacketObserver observes tunneled IP packets if set, connections not able to do it ignore the observer
+	PacketObserver PacketObserver
+}
+
+// ResolveDNS resolves DNS servers of the tunnel using `providerDNS` as received from the provider,
+// the servers are replaced with local DNS proxy resolving via them if one is set.
+func (o ConnectOptions) ResolveDNS(providerDNS string) ([]string, error) {
+	servers, err := o.Params.DNS.ResolveIPs(providerDNS)
+	if err != nil || o.DNSProxy == nil {
+		return servers, err
+	}
+
+	proxyIP, err := o.DNSProxy.Start(servers)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not start DNS proxy")
+	}
+	return []string{proxyIP}, nil
 }
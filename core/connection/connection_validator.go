@@ -20,10 +20,11 @@ package connection
 import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
 )
 
 type consumerBalanceGetter interface {
-	GetBalance(ID identity.Identity) uint64
+	GetBalance(ID identity.Identity) money.Tokens
 }
 
 type unlockChecker interface {
@@ -52,7 +53,7 @@ func (v *Validator) validateBalance(consumerID identity.Identity, proposal marke
 
 	proposalPrice := proposal.PaymentMethod.GetPrice()
 	balance := v.consumerBalanceGetter.GetBalance(consumerID)
-	return balance >= proposalPrice.Amount
+	return balance.Cmp(money.NewTokens(proposalPrice.Amount)) >= 0
 }
 
 // isUnlocked checks if the identity is unlocked or not.
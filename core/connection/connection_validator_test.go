@@ -134,6 +134,6 @@ type mockConsumerBalanceGetter struct {
 	toReturn uint64
 }
 
-func (mcbg *mockConsumerBalanceGetter) GetBalance(id identity.Identity) uint64 {
-	return mcbg.toReturn
+func (mcbg *mockConsumerBalanceGetter) GetBalance(id identity.Identity) money.Tokens {
+	return money.NewTokens(mcbg.toReturn)
 }
@@ -0,0 +1,147 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+var (
+	// ErrNoProposals indicates that there are no proposals to connect to in the requested country
+	ErrNoProposals = errors.New("no proposals found")
+	// ErrAttemptTimeout indicates that connection to a proposal was not established in time
+	ErrAttemptTimeout = errors.New("connection attempt timed out")
+)
+
+// ProposalRanker returns proposals of a country ordered from the best one.
+type ProposalRanker interface {
+	RankedProposals(country, serviceType string) ([]market.ServiceProposal, error)
+}
+
+// CountryConnectConfig contains options of connecting to a country.
+type CountryConnectConfig struct {
+	// MaxAttempts is the max number of proposals tried before giving up.
+	MaxAttempts int
+	// AttemptTimeout is the max duration of connecting to a single proposal.
+	AttemptTimeout time.Duration
+}
+
+// DefaultCountryConnectConfig returns default params.
+func DefaultCountryConnectConfig() CountryConnectConfig {
+	return CountryConnectConfig{
+		MaxAttempts:    5,
+		AttemptTimeout: 30 * time.Second,
+	}
+}
+
+// CountryConnector connects to the first proposal of a country accepting the connection.
+type CountryConnector struct {
+	manager   Manager
+	ranker    ProposalRanker
+	publisher eventbus.Publisher
+	config    CountryConnectConfig
+}
+
+// NewCountryConnector returns new instance of CountryConnector.
+func NewCountryConnector(manager Manager, ranker ProposalRanker, publisher eventbus.Publisher, config CountryConnectConfig) *CountryConnector {
+	return &CountryConnector{
+		manager:   manager,
+		ranker:    ranker,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+// Connect tries ranked proposals of the given country one by one until connection succeeds,
+// returns the proposal connected to.
+func (c *CountryConnector) Connect(consumerID identity.Identity, accountantID common.Address, country, serviceType string, params ConnectParams) (market.ServiceProposal, error) {
+	proposals, err := c.ranker.RankedProposals(country, serviceType)
+	if err != nil {
+		return market.ServiceProposal{}, errors.Wrap(err, "could not get proposals")
+	}
+	if len(proposals) == 0 {
+		return market.ServiceProposal{}, ErrNoProposals
+	}
+	if len(proposals) > c.config.MaxAttempts {
+		proposals = proposals[:c.config.MaxAttempts]
+	}
+
+	for i, proposal := range proposals {
+		err = c.attempt(consumerID, accountantID, proposal, params)
+
+		event := AppEventConnectionAttempt{
+			Attempt:     i + 1,
+			Country:     country,
+			ServiceType: serviceType,
+			ProviderID:  proposal.ProviderID,
+			Connected:   err == nil,
+		}
+		if err != nil {
+			event.Error = err.Error()
+			event.Cause = FailureCauseOf(err)
+		}
+		c.publisher.Publish(AppTopicConnectionAttempt, event)
+
+		if err == nil {
+			return proposal, nil
+		}
+		if !retryable(err) {
+			return market.ServiceProposal{}, err
+		}
+		log.Warn().Err(err).Msgf("Connection attempt %d to provider %s failed", i+1, proposal.ProviderID)
+	}
+
+	return market.ServiceProposal{}, err
+}
+
+func (c *CountryConnector) attempt(consumerID identity.Identity, accountantID common.Address, proposal market.ServiceProposal, params ConnectParams) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- c.manager.Connect(consumerID, accountantID, proposal, params)
+	}()
+
+	timer := time.NewTimer(c.config.AttemptTimeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		if err := c.manager.Disconnect(); err != nil && err != ErrNoConnection {
+			log.Warn().Err(err).Msg("Could not cancel timed out connection attempt")
+		}
+		<-done
+		return ErrAttemptTimeout
+	}
+}
+
+// retryable checks if connecting to another proposal can succeed after the given error.
+func retryable(err error) bool {
+	switch errors.Cause(err) {
+	case ErrAlreadyExists, ErrConnectionCancelled, ErrInsufficientBalance, ErrUnlockRequired:
+		return false
+	}
+	return true
+}
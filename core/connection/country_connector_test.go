@@ -0,0 +1,181 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRanker struct {
+	proposals []market.ServiceProposal
+}
+
+func (r *mockRanker) RankedProposals(country, serviceType string) ([]market.ServiceProposal, error) {
+	return r.proposals, nil
+}
+
+type mockAttemptManager struct {
+	lock        sync.Mutex
+	results     map[string]error
+	hang        map[string]bool
+	cancel      chan struct{}
+	attempted   []string
+	disconnects int
+}
+
+func (m *mockAttemptManager) Connect(_ identity.Identity, _ common.Address, proposal market.ServiceProposal, _ ConnectParams) error {
+	m.lock.Lock()
+	m.attempted = append(m.attempted, proposal.ProviderID)
+	hang := m.hang[proposal.ProviderID]
+	m.lock.Unlock()
+
+	if hang {
+		<-m.cancel
+		return ErrConnectionCancelled
+	}
+	return m.results[proposal.ProviderID]
+}
+
+func (m *mockAttemptManager) Status() Status {
+	return Status{}
+}
+
+func (m *mockAttemptManager) Disconnect() error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.disconnects++
+	close(m.cancel)
+	return nil
+}
+
+func (m *mockAttemptManager) CheckChannel(context.Context) error {
+	return nil
+}
+
+func (m *mockAttemptManager) SpeedTest(context.Context, SpeedTestTarget) (SpeedTestResult, error) {
+	return SpeedTestResult{}, nil
+}
+
+func (m *mockAttemptManager) ExportProfile() ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockAttemptManager) Dial(network, address string) (net.Conn, error) {
+	return nil, nil
+}
+
+func countryProposals(providers ...string) []market.ServiceProposal {
+	proposals := make([]market.ServiceProposal, 0, len(providers))
+	for _, p := range providers {
+		proposals = append(proposals, market.ServiceProposal{ProviderID: p, ServiceType: "wireguard"})
+	}
+	return proposals
+}
+
+func TestCountryConnector_ConnectsToFirstAvailableProposal(t *testing.T) {
+	// given
+	manager := &mockAttemptManager{
+		results: map[string]error{"0x1": errors.New("provider unreachable")},
+		hang:    map[string]bool{"0x2": true},
+		cancel:  make(chan struct{}),
+	}
+	bus := mocks.NewEventBus()
+	connector := NewCountryConnector(manager, &mockRanker{proposals: countryProposals("0x1", "0x2", "0x3", "0x4")}, bus, CountryConnectConfig{
+		MaxAttempts:    3,
+		AttemptTimeout: 10 * time.Millisecond,
+	})
+
+	// when
+	proposal, err := connector.Connect(identity.FromAddress("0xc"), common.Address{}, "LT", "wireguard", ConnectParams{})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "0x3", proposal.ProviderID)
+	assert.Equal(t, []string{"0x1", "0x2", "0x3"}, manager.attempted)
+	assert.Equal(t, 1, manager.disconnects)
+
+	history := bus.GetEventHistory()
+	assert.Len(t, history, 3)
+	assert.Equal(t, AppEventConnectionAttempt{
+		Attempt:     1,
+		Country:     "LT",
+		ServiceType: "wireguard",
+		ProviderID:  "0x1",
+		Error:       "provider unreachable",
+	}, history[0].Event)
+	assert.Equal(t, ErrAttemptTimeout.Error(), history[1].Event.(AppEventConnectionAttempt).Error)
+	assert.True(t, history[2].Event.(AppEventConnectionAttempt).Connected)
+}
+
+func TestCountryConnector_StopsAfterMaxAttempts(t *testing.T) {
+	// given
+	failure := errors.New("provider unreachable")
+	manager := &mockAttemptManager{
+		results: map[string]error{"0x1": failure, "0x2": failure, "0x3": nil},
+	}
+	connector := NewCountryConnector(manager, &mockRanker{proposals: countryProposals("0x1", "0x2", "0x3")}, mocks.NewEventBus(), CountryConnectConfig{
+		MaxAttempts:    2,
+		AttemptTimeout: time.Second,
+	})
+
+	// when
+	_, err := connector.Connect(identity.FromAddress("0xc"), common.Address{}, "LT", "wireguard", ConnectParams{})
+
+	// then
+	assert.Equal(t, failure, err)
+	assert.Equal(t, []string{"0x1", "0x2"}, manager.attempted)
+}
+
+func TestCountryConnector_StopsOnNotRetryableError(t *testing.T) {
+	// given
+	manager := &mockAttemptManager{
+		results: map[string]error{"0x1": ErrInsufficientBalance},
+	}
+	connector := NewCountryConnector(manager, &mockRanker{proposals: countryProposals("0x1", "0x2")}, mocks.NewEventBus(), DefaultCountryConnectConfig())
+
+	// when
+	_, err := connector.Connect(identity.FromAddress("0xc"), common.Address{}, "LT", "wireguard", ConnectParams{})
+
+	// then
+	assert.Equal(t, ErrInsufficientBalance, err)
+	assert.Equal(t, []string{"0x1"}, manager.attempted)
+}
+
+func TestCountryConnector_NoProposals(t *testing.T) {
+	// given
+	manager := &mockAttemptManager{}
+	connector := NewCountryConnector(manager, &mockRanker{}, mocks.NewEventBus(), DefaultCountryConnectConfig())
+
+	// when
+	_, err := connector.Connect(identity.FromAddress("0xc"), common.Address{}, "LT", "wireguard", ConnectParams{})
+
+	// then
+	assert.Equal(t, ErrNoProposals, err)
+	assert.Empty(t, manager.attempted)
+}
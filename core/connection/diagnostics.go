@@ -0,0 +1,130 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+// FailureCause is a category of the reason why connect attempt has failed.
+type FailureCause string
+
+const (
+	// FailureCauseUnknown means that diagnostics could not tell why connecting has failed.
+	FailureCauseUnknown = FailureCause("unknown")
+	// FailureCauseBrokerUnreachable means that none of the provider brokers could be reached.
+	FailureCauseBrokerUnreachable = FailureCause("broker_unreachable")
+	// FailureCauseProviderUnreachable means that provider did not reply to ping sent via broker.
+	FailureCauseProviderUnreachable = FailureCause("provider_unreachable")
+	// FailureCauseNATBlocked means that provider is reachable via broker, but the direct p2p channel could not be opened.
+	FailureCauseNATBlocked = FailureCause("nat_blocked")
+	// FailureCausePaymentRejected means that connecting was aborted because of payment errors.
+	FailureCausePaymentRejected = FailureCause("payment_rejected")
+	// FailureCauseHandshakeTimeout means that provider is reachable, but session handshake did not complete in time.
+	FailureCauseHandshakeTimeout = FailureCause("handshake_timeout")
+)
+
+// DefaultDiagnosticsTimeout limits how long the failure diagnostics may delay the connect error.
+const DefaultDiagnosticsTimeout = 5 * time.Second
+
+// DiagnosedError is a connect error with the cause found by failure diagnostics attached.
+type DiagnosedError struct {
+	Cause FailureCause
+	Err   error
+}
+
+func (e *DiagnosedError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the original connect error.
+func (e *DiagnosedError) Unwrap() error {
+	return e.Err
+}
+
+// FailureCauseOf returns the cause attached to connect error, empty if the error was not diagnosed.
+func FailureCauseOf(err error) FailureCause {
+	var diagnosed *DiagnosedError
+	if errors.As(err, &diagnosed) {
+		return diagnosed.Cause
+	}
+	return ""
+}
+
+// ConnectFailure describes failed connect attempt.
+type ConnectFailure struct {
+	Proposal market.ServiceProposal
+	// ChannelCreated is true when p2p channel with provider was opened before the failure.
+	ChannelCreated bool
+	// TerminationReason is the reason recorded for the session, see session.Termination* constants.
+	TerminationReason string
+	Err               error
+}
+
+type failureDiagnoser interface {
+	Diagnose(failure ConnectFailure) FailureCause
+}
+
+// FailureDiagnostics runs short checks to categorize why connect attempt has failed.
+type FailureDiagnostics struct {
+	pinger  p2p.ProviderPinger
+	timeout time.Duration
+}
+
+// NewFailureDiagnostics returns a new instance of connect failure diagnostics.
+func NewFailureDiagnostics(pinger p2p.ProviderPinger, timeout time.Duration) *FailureDiagnostics {
+	return &FailureDiagnostics{
+		pinger:  pinger,
+		timeout: timeout,
+	}
+}
+
+// Diagnose returns the cause of the failed connect attempt, provider is pinged via broker unless the cause is known without it.
+func (d *FailureDiagnostics) Diagnose(failure ConnectFailure) FailureCause {
+	if failure.TerminationReason == session.TerminationPaymentFailed {
+		return FailureCausePaymentRejected
+	}
+
+	contact, err := p2p.ParseContact(failure.Proposal.ProviderContacts)
+	if err != nil {
+		return FailureCauseProviderUnreachable
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), d.timeout)
+	defer cancel()
+	_, err = d.pinger.PingProvider(ctx, identity.FromAddress(failure.Proposal.ProviderID), failure.Proposal.UniqueID().ServiceKey(), contact)
+	switch {
+	case errors.Is(err, p2p.ErrBrokerUnreachable):
+		return FailureCauseBrokerUnreachable
+	case err != nil:
+		return FailureCauseProviderUnreachable
+	case !failure.ChannelCreated:
+		return FailureCauseNATBlocked
+	case session.TimedOutStage(failure.Err) != "":
+		return FailureCauseHandshakeTimeout
+	default:
+		return FailureCauseUnknown
+	}
+}
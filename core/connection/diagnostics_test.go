@@ -0,0 +1,88 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFailureDiagnostics_Diagnose(t *testing.T) {
+	tests := []struct {
+		name    string
+		pingErr error
+		failure ConnectFailure
+		want    FailureCause
+	}{
+		{
+			name:    "payment failed",
+			failure: ConnectFailure{TerminationReason: session.TerminationPaymentFailed, Err: ErrConnectionCancelled},
+			want:    FailureCausePaymentRejected,
+		},
+		{
+			name:    "broker unreachable",
+			pingErr: fmt.Errorf("%w: no servers available", p2p.ErrBrokerUnreachable),
+			failure: ConnectFailure{Err: errors.New("could not create p2p channel")},
+			want:    FailureCauseBrokerUnreachable,
+		},
+		{
+			name:    "provider does not reply",
+			pingErr: errors.New("provider did not reply to ping"),
+			failure: ConnectFailure{Err: errors.New("could not create p2p channel")},
+			want:    FailureCauseProviderUnreachable,
+		},
+		{
+			name:    "provider replies but channel is not opened",
+			failure: ConnectFailure{Err: errors.New("could not create p2p channel")},
+			want:    FailureCauseNATBlocked,
+		},
+		{
+			name:    "handshake stage timed out",
+			failure: ConnectFailure{ChannelCreated: true, Err: &session.HandshakeTimeoutError{Stage: session.HandshakeTunnel}},
+			want:    FailureCauseHandshakeTimeout,
+		},
+		{
+			name:    "unknown",
+			failure: ConnectFailure{ChannelCreated: true, Err: ErrConnectionFailed},
+			want:    FailureCauseUnknown,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := NewFailureDiagnostics(&mockProviderPinger{err: tt.pingErr}, time.Second)
+			tt.failure.Proposal = activeProposal
+
+			assert.Equal(t, tt.want, diagnostics.Diagnose(tt.failure))
+		})
+	}
+}
+
+func TestFailureCauseOf(t *testing.T) {
+	err := fmt.Errorf("connect failed: %w", &DiagnosedError{Cause: FailureCauseNATBlocked, Err: ErrConnectionFailed})
+
+	assert.Equal(t, FailureCauseNATBlocked, FailureCauseOf(err))
+	assert.True(t, errors.Is(err, ErrConnectionFailed))
+	assert.EqualError(t, err, "connect failed: connection has failed")
+	assert.Equal(t, FailureCause(""), FailureCauseOf(ErrConnectionFailed))
+}
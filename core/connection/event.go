@@ -21,6 +21,7 @@ import (
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/consumer/traffic"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/session"
@@ -29,11 +30,25 @@ import (
 // Topic represents the different topics a consumer can subscribe to
 const (
 	// AppTopicConnectionState represents the session state change topic
-	AppTopicConnectionState = "State"
+	AppTopicConnectionState = "connection.state"
 	// AppTopicConnectionStatistics represents the session stats topic
-	AppTopicConnectionStatistics = "Statistics"
+	AppTopicConnectionStatistics = "connection.statistics"
+	// AppTopicConnectionStatisticsLive represents the session stats topic for user facing subscribers,
+	// statistics are sampled at the full rate only while this topic has subscribers
+	AppTopicConnectionStatisticsLive = "connection.statistics.live"
 	// AppTopicConnectionSession represents the session lifetime changes
-	AppTopicConnectionSession = "Session"
+	AppTopicConnectionSession = "connection.session"
+	// AppTopicConnectionLocationMismatch represents the topic of connections exiting in a different country than advertised
+	AppTopicConnectionLocationMismatch = "connection.location.mismatch"
+	// AppTopicConnectionGoingAway represents the topic of provider notices about ending the session
+	AppTopicConnectionGoingAway = "connection.going-away"
+	// AppTopicConnectionAttempt represents the topic of connection attempts made while connecting to a country
+	AppTopicConnectionAttempt = "connection.attempt"
+	// AppTopicConnectionSpeedTest represents the topic of connection speed test results
+	AppTopicConnectionSpeedTest = "connection.speed-test"
+	// AppTopicConnectionTraffic represents the topic of session traffic breakdown per destination,
+	// published along with statistics for connections made with traffic breakdown enabled
+	AppTopicConnectionTraffic = "connection.traffic"
 )
 
 // AppEventConnectionState is the struct we'll emit on a AppEventConnectionState topic event
@@ -66,14 +81,41 @@ const (
 	StateConnectionFailed = State("ConnectionFailed")
 )
 
+// DefaultConnectionID is ID of the main connection, it is used when connection ID is not given.
+const DefaultConnectionID = "default"
+
 // Status holds connection state, session id and proposal of the connection
 type Status struct {
+	// ConnectionID names the connection among simultaneous ones, see DefaultConnectionID.
+	ConnectionID string
 	StartedAt    time.Time
 	ConsumerID   identity.Identity
 	AccountantID common.Address
 	State        State
 	SessionID    session.ID
 	Proposal     market.ServiceProposal
+	// ProtocolVersion and Capabilities hold session protocol features negotiated with provider.
+	ProtocolVersion uint32
+	Capabilities    []string
+	PaymentVersion  string
+	// QoSClass is the QoS class of session granted by provider.
+	QoSClass string
+	// NATTraversal is the method used to reach provider, see p2p.Traversal* constants.
+	NATTraversal string
+	// Backend is the backend running the tunnel, set by connections having a choice of them.
+	Backend string
+	// Interface is network interface of the tunnel, set by connections running it on the host.
+	Interface string
+	// RoutingTable and ProxyAddress are set for named connections, only traffic of their local proxy is routed
+	// through the tunnel in this routing table.
+	RoutingTable int
+	ProxyAddress string
+	// TerminationReason is set once the session is ending, see session.Termination* constants.
+	TerminationReason string
+	// TimedOutStage is set when connecting failed because a handshake stage did not complete in time.
+	TimedOutStage session.HandshakeStage
+	// FailureCause is set when connecting failed, it tells what diagnostics found to be the cause.
+	FailureCause FailureCause
 }
 
 // Duration returns elapsed time from marked session start
@@ -102,3 +144,47 @@ type AppEventConnectionStatistics struct {
 	Stats       Statistics
 	SessionInfo Status
 }
+
+// AppEventConnectionLocationMismatch is emitted when the location detected after connecting
+// does not match the one advertised by the provider's proposal
+type AppEventConnectionLocationMismatch struct {
+	SessionInfo     Status
+	IP              string
+	ExpectedCountry string
+	DetectedCountry string
+	// Disconnected is true when connection was terminated because of the mismatch
+	Disconnected bool
+}
+
+// AppEventConnectionSpeedTest is emitted after the speed of the connection was measured
+type AppEventConnectionSpeedTest struct {
+	SessionInfo Status
+	Result      SpeedTestResult
+}
+
+// AppEventConnectionGoingAway is emitted when provider notifies that it is shutting down
+// and the session will be ended by the deadline
+type AppEventConnectionGoingAway struct {
+	SessionInfo Status
+	Deadline    time.Time
+	Reason      string
+}
+
+// AppEventConnectionAttempt is emitted after each proposal tried while connecting to a country
+type AppEventConnectionAttempt struct {
+	Attempt     int
+	Country     string
+	ServiceType string
+	ProviderID  string
+	Connected   bool
+	// Error holds the reason of failed attempt
+	Error string
+	// Cause is the failure cause found by diagnostics, empty if the attempt was not diagnosed
+	Cause FailureCause
+}
+
+// AppEventConnectionTraffic represents tunneled traffic of the session split per destination
+type AppEventConnectionTraffic struct {
+	SessionInfo Status
+	Breakdown   traffic.Breakdown
+}
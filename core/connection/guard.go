@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/session"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// costGuard signals once the total paid for the session reaches the maximum cost.
+type costGuard struct {
+	sessionID session.ID
+	maxCost   uint64
+	exceeded  chan struct{}
+	once      sync.Once
+}
+
+func newCostGuard(sessionID session.ID, maxCost uint64) *costGuard {
+	return &costGuard{
+		sessionID: sessionID,
+		maxCost:   maxCost,
+		exceeded:  make(chan struct{}),
+	}
+}
+
+func (g *costGuard) consumeInvoicePaid(e pingpongEvent.AppEventInvoicePaid) {
+	if e.SessionID != string(g.sessionID) || e.Invoice.AgreementTotal < g.maxCost {
+		return
+	}
+	g.once.Do(func() {
+		close(g.exceeded)
+	})
+}
+
+// trafficGuard signals once the session transfers the maximum amount of data.
+type trafficGuard struct {
+	sessionID  session.ID
+	maxTraffic uint64
+	exceeded   chan struct{}
+	once       sync.Once
+}
+
+func newTrafficGuard(sessionID session.ID, maxTraffic uint64) *trafficGuard {
+	return &trafficGuard{
+		sessionID:  sessionID,
+		maxTraffic: maxTraffic,
+		exceeded:   make(chan struct{}),
+	}
+}
+
+func (g *trafficGuard) consumeStatistics(e AppEventConnectionStatistics) {
+	if e.SessionInfo.SessionID != g.sessionID || e.Stats.BytesSent+e.Stats.BytesReceived < g.maxTraffic {
+		return
+	}
+	g.once.Do(func() {
+		close(g.exceeded)
+	})
+}
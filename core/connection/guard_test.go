@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/session"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostGuard_SignalsWhenMaxCostReached(t *testing.T) {
+	guard := newCostGuard("session-id", 1000)
+	paid := func(sessionID string, total uint64) {
+		guard.consumeInvoicePaid(pingpongEvent.AppEventInvoicePaid{
+			SessionID: sessionID,
+			Invoice:   crypto.Invoice{AgreementTotal: total},
+		})
+	}
+
+	triggered := func() bool {
+		select {
+		case <-guard.exceeded:
+			return true
+		default:
+			return false
+		}
+	}
+
+	paid("session-id", 999)
+	paid("other-session-id", 5000)
+	assert.False(t, triggered())
+
+	paid("session-id", 1000)
+	paid("session-id", 1001)
+	assert.True(t, triggered())
+}
+
+func TestTrafficGuard_SignalsWhenMaxTrafficReached(t *testing.T) {
+	guard := newTrafficGuard("session-id", 1000)
+	transferred := func(sessionID session.ID, sent, received uint64) {
+		guard.consumeStatistics(AppEventConnectionStatistics{
+			SessionInfo: Status{SessionID: sessionID},
+			Stats:       Statistics{BytesSent: sent, BytesReceived: received},
+		})
+	}
+
+	triggered := func() bool {
+		select {
+		case <-guard.exceeded:
+			return true
+		default:
+			return false
+		}
+	}
+
+	transferred("session-id", 100, 899)
+	transferred("other-session-id", 5000, 5000)
+	assert.False(t, triggered())
+
+	transferred("session-id", 100, 900)
+	transferred("session-id", 100, 901)
+	assert.True(t, triggered())
+}
@@ -19,6 +19,7 @@ package connection
 
 import (
 	"context"
+	"net"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
@@ -38,6 +39,58 @@ type Connection interface {
 	Statistics() (Statistics, error)
 }
 
+// ConfigApplier is implemented by connections which are able to apply
+// updated provider session config without reconnecting.
+type ConfigApplier interface {
+	ApplyConfig(sessionConfig []byte) error
+}
+
+// NetworkRebinder is implemented by connections which are able to move
+// the tunnel to a changed local network without reconnecting.
+type NetworkRebinder interface {
+	Rebind() error
+}
+
+// BackendReporter is implemented by connections which run the tunnel on one of several backends.
+type BackendReporter interface {
+	Backend() string
+}
+
+// InterfaceReporter is implemented by connections which tunnel traffic through a network interface of the host.
+type InterfaceReporter interface {
+	InterfaceName() string
+}
+
+// ProfileExporter is implemented by connections which are able to export
+// the session as a standalone profile for external clients.
+type ProfileExporter interface {
+	Profile() ([]byte, error)
+}
+
+// TunnelDialer is implemented by connections which are able to open connections through their tunnel
+// regardless of the routing table of the host.
+type TunnelDialer interface {
+	Dial(network, address string) (net.Conn, error)
+}
+
+// DNSProxy serves tunnel DNS queries of consumer locally, e.g. to block ads and trackers.
+type DNSProxy interface {
+	// Start starts serving queries resolved via given servers, or system ones if none given, and returns IP to point tunnel DNS to.
+	Start(servers []string) (string, error)
+	// Stop stops serving queries.
+	Stop() error
+	// Counters returns how many queries were served and how many of them were blocked.
+	Counters() (queries, blocked uint64)
+}
+
+// PacketObserver observes IP packets passing through the tunnel of consumer.
+type PacketObserver interface {
+	// Outbound observes packet sent into the tunnel.
+	Outbound(packet []byte)
+	// Inbound observes packet received from the tunnel.
+	Inbound(packet []byte)
+}
+
 // StateChannel is the channel we receive state change events on
 type StateChannel chan State
 
@@ -51,4 +104,10 @@ type Manager interface {
 	Disconnect() error
 	// CheckChannel checks if current session channel is alive, returns error on failed keep-alive ping
 	CheckChannel(context.Context) error
+	// SpeedTest measures speed of the active connection to the given target
+	SpeedTest(ctx context.Context, target SpeedTestTarget) (SpeedTestResult, error)
+	// ExportProfile exports the active session as a standalone profile for external clients
+	ExportProfile() ([]byte, error)
+	// Dial opens connection to the address through the tunnel of the active connection
+	Dial(network, address string) (net.Conn, error)
 }
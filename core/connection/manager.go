@@ -20,11 +20,16 @@ package connection
 import (
 	"context"
 	"encoding/json"
+	stdErr "errors"
 	"fmt"
+	"net"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/consumer/traffic"
+	"github.com/mysteriumnetwork/node/core/netmon"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 
@@ -37,6 +42,8 @@ import (
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/connectivity"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/sleep"
 	"github.com/mysteriumnetwork/node/trace"
 )
@@ -60,6 +67,14 @@ var (
 	ErrInsufficientBalance = errors.New("insufficient balance")
 	// ErrUnlockRequired indicates that the consumer identity has not been unlocked yet
 	ErrUnlockRequired = errors.New("unlock required")
+	// ErrSessionPolicyDenied indicates that provider refused the session because its service policy restricts consumer location
+	ErrSessionPolicyDenied = errors.New("provider service policy does not allow consumer location")
+	// ErrReconfigureNotSupported indicates that current connection is not able to apply updated session config
+	ErrReconfigureNotSupported = errors.New("session reconfigure is not supported by connection")
+	// ErrProfileExportNotSupported indicates that current connection is not able to export its session profile
+	ErrProfileExportNotSupported = errors.New("profile export is not supported by connection")
+	// ErrTunnelDialNotSupported indicates that current connection is not able to open connections through its tunnel
+	ErrTunnelDialNotSupported = errors.New("dialing through the tunnel is not supported by connection")
 )
 
 // IPCheckConfig contains common params for connection ip check.
@@ -73,12 +88,24 @@ type KeepAliveConfig struct {
 	SendInterval    time.Duration
 	SendTimeout     time.Duration
 	MaxSendErrCount int
+	// DeadPeerTimeout is the max duration without any traffic from provider
+	// after which the provider is considered lost and connection is closed.
+	DeadPeerTimeout time.Duration
 }
 
 // Config contains common configuration options for connection manager.
 type Config struct {
-	IPCheck   IPCheckConfig
-	KeepAlive KeepAliveConfig
+	IPCheck        IPCheckConfig
+	KeepAlive      KeepAliveConfig
+	Idle           session.IdleConfig
+	Reconciliation session.ReconciliationConfig
+	// QoSClass is the QoS class of session requested from provider, provider picks its default when empty.
+	QoSClass string
+	// Handshake bounds the time of session config exchange and tunnel handshake.
+	Handshake session.HandshakeBudget
+	SpeedTest SpeedTestConfig
+	// ID names connections of the manager, see DefaultConnectionID.
+	ID string
 }
 
 // DefaultConfig returns default params.
@@ -92,7 +119,16 @@ func DefaultConfig() Config {
 			SendInterval:    20 * time.Second,
 			SendTimeout:     5 * time.Second,
 			MaxSendErrCount: 5,
+			DeadPeerTimeout: 60 * time.Second,
 		},
+		SpeedTest: SpeedTestConfig{
+			DownloadURL:    "https://speed.cloudflare.com/__down?bytes=",
+			UploadURL:      "https://speed.cloudflare.com/__up",
+			Size:           10 * datasize.MiB,
+			LatencySamples: 5,
+			Timeout:        30 * time.Second,
+		},
+		ID: DefaultConnectionID,
 	}
 }
 
@@ -126,6 +162,8 @@ type connectionManager struct {
 	statsReportInterval  time.Duration
 	validator            validator
 	p2pDialer            p2p.Dialer
+	diagnostics          failureDiagnoser
+	dnsProxy             DNSProxy
 	timeGetter           TimeGetter
 
 	// These are populated by Connect at runtime.
@@ -141,9 +179,12 @@ type connectionManager struct {
 	acknowledge            func()
 	cancel                 func()
 	channel                p2p.Channel
+	exporter               ProfileExporter
+	tunnelDialer           TunnelDialer
 
 	discoLock      sync.Mutex
 	connectOptions ConnectOptions
+	migrateLock    sync.Mutex
 }
 
 // NewManager creates connection manager with given dependencies
@@ -156,10 +197,12 @@ func NewManager(
 	statsReportInterval time.Duration,
 	validator validator,
 	p2pDialer p2p.Dialer,
+	diagnostics failureDiagnoser,
+	dnsProxy DNSProxy,
 ) *connectionManager {
 	return &connectionManager{
 		newConnection:        connectionCreator,
-		status:               Status{State: NotConnected},
+		status:               Status{State: NotConnected, ConnectionID: config.ID},
 		eventBus:             eventBus,
 		paymentEngineFactory: paymentEngineFactory,
 		cleanup:              make([]func() error, 0),
@@ -169,6 +212,8 @@ func NewManager(
 		statsReportInterval:  statsReportInterval,
 		validator:            validator,
 		p2pDialer:            p2pDialer,
+		diagnostics:          diagnostics,
+		dnsProxy:             dnsProxy,
 		timeGetter:           time.Now,
 	}
 }
@@ -205,10 +250,14 @@ func (m *connectionManager) Connect(consumerID identity.Identity, accountantID c
 	m.ctx, m.cancel = context.WithCancel(context.Background())
 	m.ctxLock.Unlock()
 
+	var channel p2p.Channel
 	m.statusConnecting(consumerID, accountantID, proposal)
 	defer func() {
 		if err != nil {
-			log.Err(err).Msg("Connect failed, disconnecting")
+			err = m.diagnoseFailure(proposal, channel, err)
+			log.Err(err).Msgf("Connect failed (cause: %s), disconnecting", FailureCauseOf(err))
+			m.setTimedOutStage(session.TimedOutStage(err))
+			m.setTerminationReason(session.TerminationSetupFailed)
 			m.disconnect()
 		}
 	}()
@@ -216,12 +265,12 @@ func (m *connectionManager) Connect(consumerID identity.Identity, accountantID c
 	providerID := identity.FromAddress(proposal.ProviderID)
 
 	p2pChannelTrace := tracer.StartStage("Consumer P2P channel creation")
-	contact, err := p2p.ParseContact(proposal.ProviderContacts)
+	contacts, err := p2p.ParseContacts(proposal.ProviderContacts)
 	if err != nil {
 		return fmt.Errorf("provider does not support p2p communication: %w", err)
 	}
 
-	channel, err := m.createP2PChannel(m.currentCtx(), consumerID, providerID, proposal.ServiceType, contact)
+	channel, err = m.createP2PChannel(m.currentCtx(), consumerID, providerID, proposal.UniqueID().ServiceKey(), contacts)
 	if err != nil {
 		return fmt.Errorf("could not create p2p channel: %w", err)
 	}
@@ -248,8 +297,15 @@ func (m *connectionManager) Connect(consumerID identity.Identity, accountantID c
 
 	m.setStatus(func(status *Status) {
 		status.SessionID = sessionID
+		status.ProtocolVersion = session.NegotiateVersion(sessionDTO.GetProtocolVersion())
+		status.Capabilities = session.NegotiateCapabilities(sessionDTO.GetCapabilities())
+		status.PaymentVersion = sessionDTO.GetPaymentInfo()
+		status.QoSClass = sessionDTO.GetQosClass()
+		status.NATTraversal = channel.TraversalMethod()
 	})
 	m.publishSessionCreate(sessionID)
+	m.handleReconfigure(channel, connection, sessionID)
+	m.handleGoingAway(channel, sessionID)
 	paymentSession.SetSessionID(string(sessionID))
 	tracer.EndStage(sessionCreateTrace)
 
@@ -267,6 +323,16 @@ func (m *connectionManager) Connect(consumerID identity.Identity, accountantID c
 		ChannelConn:     channel.Conn(),
 		AccountantID:    accountantID,
 	}
+	if params.AdBlock {
+		if m.dnsProxy != nil {
+			m.connectOptions.DNSProxy = m.dnsProxy
+		} else {
+			log.Warn().Msg("Ad blocking is not available, connecting without it")
+		}
+	}
+	if params.TrafficBreakdown {
+		m.connectOptions.PacketObserver = traffic.NewCounter(traffic.DefaultCategorizer())
+	}
 	err = m.startConnection(m.currentCtx(), connection, m.connectOptions)
 	tracer.EndStage(connectionTrace)
 
@@ -274,12 +340,15 @@ func (m *connectionManager) Connect(consumerID identity.Identity, accountantID c
 		if err == context.Canceled {
 			return ErrConnectionCancelled
 		}
+		err = m.diagnoseFailure(proposal, channel, err)
 		m.addCleanupAfterDisconnect(func() error {
 			return m.sendSessionStatus(channel, consumerID, sessionID, connectivity.StatusConnectionFailed, err)
 		})
+		m.setTimedOutStage(session.TimedOutStage(err))
 		m.publishStateEvent(StateConnectionFailed)
 
 		log.Info().Err(err).Msg("Cancelling connection initiation: ")
+		m.setTerminationReason(session.TerminationSetupFailed)
 		m.Cancel()
 		return err
 	}
@@ -288,7 +357,10 @@ func (m *connectionManager) Connect(consumerID identity.Identity, accountantID c
 	m.clearIPCache()
 
 	go m.keepAliveLoop(channel, sessionID)
-	go m.checkSessionIP(channel, consumerID, sessionID, originalPublicIP)
+	// Isolated connections leave the default route untouched, the public IP is not expected to change.
+	if params.RoutingTable == 0 {
+		go m.checkSessionIP(channel, consumerID, sessionID, originalPublicIP)
+	}
 
 	return nil
 }
@@ -404,11 +476,11 @@ func (m *connectionManager) cleanAfterDisconnect() {
 	m.cleanupAfterDisconnect = nil
 }
 
-func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition) (p2p.Channel, error) {
+func (m *connectionManager) createP2PChannel(ctx context.Context, consumerID, providerID identity.Identity, serviceType string, contacts []p2p.ContactDefinition) (p2p.Channel, error) {
 	timeoutCtx, cancel := context.WithTimeout(ctx, p2pDialTimeout)
 	defer cancel()
 
-	channel, err := m.p2pDialer.Dial(timeoutCtx, consumerID, providerID, serviceType, contactDef)
+	channel, err := p2p.DialCandidates(timeoutCtx, m.p2pDialer, consumerID, providerID, serviceType, contacts, p2p.DefaultDialStagger)
 	if err != nil {
 		return nil, err
 	}
@@ -450,14 +522,22 @@ func (m *connectionManager) createP2PSession(ctx context.Context, c Connection,
 			AccountantID:   accountantID.Hex(),
 			PaymentVersion: "v3",
 		},
-		ProposalID: int64(proposal.ID),
-		Config:     config,
+		ProposalID:      int64(proposal.ID),
+		Config:          config,
+		ProtocolVersion: session.ProtocolVersion,
+		Capabilities:    session.Capabilities(),
+		QosClass:        m.config.QoSClass,
 	}
 	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionCreate, sessionRequest.String())
-	ctx, cancel := context.WithTimeout(ctx, 20*time.Second)
+	ctx, cancel := m.config.Handshake.StageContext(ctx, session.HandshakeConfigExchange)
 	defer cancel()
 	res, err := p2pChannel.Send(ctx, p2p.TopicSessionCreate, p2p.ProtoMessage(sessionRequest))
 	if err != nil {
+		var peerErr *p2p.PublicError
+		if stdErr.As(err, &peerErr) && peerErr.Code == uint64(connectivity.StatusSessionPolicyDenied) {
+			return nil, fmt.Errorf("%s: %w", peerErr.Message, ErrSessionPolicyDenied)
+		}
+		err = m.config.Handshake.StageError(ctx, session.HandshakeConfigExchange, err)
 		return nil, fmt.Errorf("could not send p2p session create request: %w", err)
 	}
 
@@ -504,6 +584,58 @@ func (m *connectionManager) createP2PSession(ctx context.Context, c Connection,
 	return &sessionResponse, nil
 }
 
+// handleReconfigure registers handler for session config updates pushed by provider mid-session.
+func (m *connectionManager) handleReconfigure(channel p2p.ChannelHandler, c Connection, sessionID session.ID) {
+	channel.Handle(p2p.TopicSessionReconfigure, func(ctx p2p.Context) error {
+		var req pb.SessionReconfigure
+		if err := ctx.Request().UnmarshalProto(&req); err != nil {
+			return err
+		}
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionReconfigure, req.String())
+
+		if req.GetSessionID() != string(sessionID) {
+			return ctx.Error(fmt.Errorf("unknown session %s", req.GetSessionID()))
+		}
+
+		applier, ok := c.(ConfigApplier)
+		if !ok {
+			return ctx.Error(ErrReconfigureNotSupported)
+		}
+
+		if err := applier.ApplyConfig(req.GetConfig()); err != nil {
+			return fmt.Errorf("could not apply session config: %w", err)
+		}
+		log.Info().Msgf("Applied updated provider session config. SessionID=%s", sessionID)
+
+		return ctx.OK()
+	})
+}
+
+// handleGoingAway registers handler for provider notices about ending the session because provider is shutting down.
+func (m *connectionManager) handleGoingAway(channel p2p.ChannelHandler, sessionID session.ID) {
+	channel.Handle(p2p.TopicSessionGoingAway, func(ctx p2p.Context) error {
+		var req pb.SessionGoingAway
+		if err := ctx.Request().UnmarshalProto(&req); err != nil {
+			return err
+		}
+		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionGoingAway, req.String())
+
+		if req.GetSessionID() != string(sessionID) {
+			return ctx.Error(fmt.Errorf("unknown session %s", req.GetSessionID()))
+		}
+
+		deadline := time.Unix(req.GetDeadline(), 0)
+		log.Warn().Msgf("Provider is going away (%s), session %s ends by %s", req.GetReason(), sessionID, deadline.Format(time.RFC3339))
+		m.eventBus.Publish(AppTopicConnectionGoingAway, AppEventConnectionGoingAway{
+			SessionInfo: m.Status(),
+			Deadline:    deadline,
+			Reason:      req.GetReason(),
+		})
+
+		return ctx.OK()
+	})
+}
+
 func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
 	m.eventBus.Publish(AppTopicConnectionSession, AppEventConnectionSession{
 		Status:      SessionCreatedStatus,
@@ -522,8 +654,49 @@ func (m *connectionManager) publishSessionCreate(sessionID session.ID) {
 }
 
 func (m *connectionManager) startConnection(ctx context.Context, conn Connection, connectOptions ConnectOptions) (err error) {
-	if err = conn.Start(ctx, connectOptions); err != nil {
-		return err
+	tunnelCtx, cancel := m.config.Handshake.StageContext(ctx, session.HandshakeTunnel)
+	defer cancel()
+
+	var stats statsSupplier = conn
+	var breakdown trafficSupplier
+	if counter, ok := connectOptions.PacketObserver.(trafficSupplier); ok {
+		breakdown = counter
+	}
+	if dnsProxy := connectOptions.DNSProxy; dnsProxy != nil {
+		m.addCleanup(func() error {
+			log.Trace().Msg("Cleaning: stopping DNS proxy")
+			defer log.Trace().Msg("Cleaning: stopping DNS proxy DONE")
+			return dnsProxy.Stop()
+		})
+		stats = dnsStatsSupplier{statsSupplier: conn, proxy: dnsProxy}
+	}
+
+	if err = conn.Start(tunnelCtx, connectOptions); err != nil {
+		return m.config.Handshake.StageError(tunnelCtx, session.HandshakeTunnel, err)
+	}
+	if reporter, ok := conn.(BackendReporter); ok {
+		m.setStatus(func(status *Status) {
+			status.Backend = reporter.Backend()
+		})
+	}
+	if reporter, ok := conn.(InterfaceReporter); ok {
+		m.setStatus(func(status *Status) {
+			status.Interface = reporter.InterfaceName()
+		})
+	}
+	if exporter, ok := conn.(ProfileExporter); ok {
+		m.setExporter(exporter)
+		m.addCleanup(func() error {
+			m.setExporter(nil)
+			return nil
+		})
+	}
+	if dialer, ok := conn.(TunnelDialer); ok {
+		m.setTunnelDialer(dialer)
+		m.addCleanup(func() error {
+			m.setTunnelDialer(nil)
+			return nil
+		})
 	}
 	m.addCleanup(func() error {
 		log.Trace().Msg("Cleaning: stopping connection")
@@ -532,25 +705,35 @@ func (m *connectionManager) startConnection(ctx context.Context, conn Connection
 		return nil
 	})
 
-	err = m.setupTrafficBlock(connectOptions.Params.DisableKillSwitch)
+	err = m.setupTrafficBlock(connectOptions.Params.DisableKillSwitch || connectOptions.Params.RoutingTable > 0)
 	if err != nil {
 		return err
 	}
 
-	err = m.waitForConnectedState(conn.State())
+	err = m.waitForConnectedState(tunnelCtx, conn.State())
 	if err != nil {
+		return m.config.Handshake.StageError(tunnelCtx, session.HandshakeTunnel, err)
+	}
+
+	if err := m.watchNetworkChanges(conn); err != nil {
 		return err
 	}
 
 	statsPublisher := newStatsPublisher(m.eventBus, m.statsReportInterval)
-	go statsPublisher.start(m, conn)
+	statsPublisher.traffic = breakdown
+	go statsPublisher.start(m, stats)
 	m.addCleanup(func() error {
 		log.Trace().Msg("Cleaning: stopping statistics publisher")
 		defer log.Trace().Msg("Cleaning: stopping statistics publisher DONE")
 		statsPublisher.stop()
+		// The final breakdown is published before the session ends, so it is kept with the session.
+		statsPublisher.publishTraffic(m)
 		return nil
 	})
 
+	go m.idleLoop(m.currentCtx(), conn)
+	go m.reconcileLoop(m.currentCtx(), m.channel, m.Status().SessionID, conn)
+	go m.guardLoop(m.currentCtx(), m.Status().SessionID, connectOptions.Params)
 	go m.consumeConnectionStates(conn.State())
 	go m.connectionWaiter(conn)
 	return nil
@@ -563,6 +746,50 @@ func (m *connectionManager) Status() Status {
 	return m.status
 }
 
+// ExportProfile exports the active session as a standalone profile for external clients.
+func (m *connectionManager) ExportProfile() ([]byte, error) {
+	if m.Status().State != Connected {
+		return nil, ErrNoConnection
+	}
+
+	m.statusLock.RLock()
+	exporter := m.exporter
+	m.statusLock.RUnlock()
+	if exporter == nil {
+		return nil, ErrProfileExportNotSupported
+	}
+	return exporter.Profile()
+}
+
+func (m *connectionManager) setExporter(exporter ProfileExporter) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+
+	m.exporter = exporter
+}
+
+// Dial opens connection to the address through the tunnel of the active connection.
+func (m *connectionManager) Dial(network, address string) (net.Conn, error) {
+	if m.Status().State != Connected {
+		return nil, ErrNoConnection
+	}
+
+	m.statusLock.RLock()
+	dialer := m.tunnelDialer
+	m.statusLock.RUnlock()
+	if dialer == nil {
+		return nil, ErrTunnelDialNotSupported
+	}
+	return dialer.Dial(network, address)
+}
+
+func (m *connectionManager) setTunnelDialer(dialer TunnelDialer) {
+	m.statusLock.Lock()
+	defer m.statusLock.Unlock()
+
+	m.tunnelDialer = dialer
+}
+
 func (m *connectionManager) setStatus(delta func(status *Status)) {
 	m.statusLock.Lock()
 	stateWas := m.status.State
@@ -581,6 +808,7 @@ func (m *connectionManager) setStatus(delta func(status *Status)) {
 func (m *connectionManager) statusConnecting(consumerID identity.Identity, accountantID common.Address, proposal market.ServiceProposal) {
 	m.setStatus(func(status *Status) {
 		*status = Status{
+			ConnectionID: m.config.ID,
 			StartedAt:    m.timeGetter(),
 			ConsumerID:   consumerID,
 			AccountantID: accountantID,
@@ -620,7 +848,56 @@ func (m *connectionManager) statusCanceled() {
 	})
 }
 
+// setTerminationReason records why the current session is ending, the first recorded reason is kept.
+func (m *connectionManager) setTerminationReason(reason string) {
+	m.setStatus(func(status *Status) {
+		if status.State != NotConnected && status.TerminationReason == "" {
+			status.TerminationReason = reason
+		}
+	})
+}
+
+// setTimedOutStage records the handshake stage which did not complete in time, the first recorded stage is kept.
+func (m *connectionManager) setTimedOutStage(stage session.HandshakeStage) {
+	m.setStatus(func(status *Status) {
+		if status.State != NotConnected && status.TimedOutStage == "" {
+			status.TimedOutStage = stage
+		}
+	})
+}
+
+// setFailureCause records the cause of failed connect attempt.
+func (m *connectionManager) setFailureCause(cause FailureCause) {
+	m.setStatus(func(status *Status) {
+		status.FailureCause = cause
+	})
+}
+
+// diagnoseFailure finds the cause of failed connect attempt and attaches it to the error,
+// cancelled attempts are not diagnosed unless they were aborted because of payment errors.
+func (m *connectionManager) diagnoseFailure(proposal market.ServiceProposal, channel p2p.Channel, err error) error {
+	if FailureCauseOf(err) != "" {
+		return err
+	}
+
+	status := m.Status()
+	cancelled := err == ErrConnectionCancelled || stdErr.Is(err, context.Canceled)
+	if cancelled && status.TerminationReason != session.TerminationPaymentFailed {
+		return err
+	}
+
+	cause := m.diagnostics.Diagnose(ConnectFailure{
+		Proposal:          proposal,
+		ChannelCreated:    channel != nil,
+		TerminationReason: status.TerminationReason,
+		Err:               err,
+	})
+	m.setFailureCause(cause)
+	return &DiagnosedError{Cause: cause, Err: err}
+}
+
 func (m *connectionManager) Cancel() {
+	m.setTerminationReason(session.TerminationCanceled)
 	m.statusCanceled()
 	logDisconnectError(m.Disconnect())
 }
@@ -630,6 +907,7 @@ func (m *connectionManager) Disconnect() error {
 		return ErrNoConnection
 	}
 
+	m.setTerminationReason(session.TerminationRequested)
 	m.statusDisconnecting()
 	m.disconnect()
 
@@ -666,6 +944,7 @@ func (m *connectionManager) payForService(payments PaymentIssuer) {
 	err := payments.Start()
 	if err != nil {
 		log.Error().Err(err).Msg("Payment error")
+		m.setTerminationReason(session.TerminationPaymentFailed)
 		err = m.Disconnect()
 		if err != nil {
 			log.Error().Err(err).Msg("Could not disconnect gracefully")
@@ -681,10 +960,11 @@ func (m *connectionManager) connectionWaiter(connection Connection) {
 		log.Info().Msg("Connection exited")
 	}
 
+	m.setTerminationReason(session.TerminationConnectionLost)
 	logDisconnectError(m.Disconnect())
 }
 
-func (m *connectionManager) waitForConnectedState(stateChannel <-chan State) error {
+func (m *connectionManager) waitForConnectedState(ctx context.Context, stateChannel <-chan State) error {
 	log.Debug().Msg("waiting for connected state")
 	for {
 		select {
@@ -705,8 +985,8 @@ func (m *connectionManager) waitForConnectedState(stateChannel <-chan State) err
 			default:
 				m.onStateChanged(state)
 			}
-		case <-m.currentCtx().Done():
-			return m.currentCtx().Err()
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 }
@@ -729,12 +1009,67 @@ func (m *connectionManager) handleSleepEvent(e sleep.Event) {
 	}
 }
 
+// watchNetworkChanges migrates the connection to the new local network when it changes mid-session.
+func (m *connectionManager) watchNetworkChanges(conn Connection) error {
+	handler := func(e netmon.AppEventNetworkChanged) {
+		m.migrate(conn)
+	}
+	if err := m.eventBus.SubscribeAsync(netmon.AppTopicNetworkChanged, handler); err != nil {
+		return fmt.Errorf("could not watch local network changes: %w", err)
+	}
+	m.addCleanup(func() error {
+		log.Trace().Msg("Cleaning: unsubscribing from local network changes")
+		defer log.Trace().Msg("Cleaning: unsubscribing from local network changes DONE")
+		return m.eventBus.Unsubscribe(netmon.AppTopicNetworkChanged, handler)
+	})
+	return nil
+}
+
+// migrate moves the connection to the new local network. The tunnel is rebound in place if the connection
+// supports it and the session survives the change, otherwise the session is reconnected.
+func (m *connectionManager) migrate(conn Connection) {
+	m.migrateLock.Lock()
+	defer m.migrateLock.Unlock()
+
+	status := m.Status()
+	if status.State != Connected {
+		return
+	}
+
+	log.Info().Msgf("Local network changed, migrating connection. SessionID=%s", status.SessionID)
+	m.statusReconnecting()
+
+	if rebinder, ok := conn.(NetworkRebinder); ok {
+		err := m.rebind(rebinder)
+		if err == nil {
+			log.Info().Msgf("Connection migrated to the new network. SessionID=%s", status.SessionID)
+			m.statusConnected()
+			return
+		}
+		log.Warn().Err(err).Msgf("Could not migrate connection to the new network. SessionID=%s", status.SessionID)
+	}
+
+	log.Info().Msgf("Reconnecting on the new network. SessionID=%s", status.SessionID)
+	m.reconnect()
+}
+
+func (m *connectionManager) rebind(rebinder NetworkRebinder) error {
+	if err := rebinder.Rebind(); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(m.currentCtx(), m.config.KeepAlive.SendTimeout)
+	defer cancel()
+	return m.CheckChannel(ctx)
+}
+
 func (m *connectionManager) consumeConnectionStates(stateChannel <-chan State) {
 	for state := range stateChannel {
 		m.onStateChanged(state)
 	}
 
 	log.Debug().Msg("State updater stopCalled")
+	m.setTerminationReason(session.TerminationConnectionLost)
 	logDisconnectError(m.Disconnect())
 }
 
@@ -805,12 +1140,18 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 			log.Debug().Msgf("Stopping p2p keepalive: %v", m.currentCtx().Err())
 			return
 		case <-time.After(m.config.KeepAlive.SendInterval):
+			if m.peerLost(channel, sessionID) {
+				m.setTerminationReason(session.TerminationPeerLost)
+				m.Disconnect()
+				return
+			}
 			ctx, cancel := context.WithTimeout(context.Background(), m.config.KeepAlive.SendTimeout)
 			if err := m.sendKeepAlivePing(ctx, channel, sessionID); err != nil {
 				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sessionID)
 				errCount++
 				if errCount == m.config.KeepAlive.MaxSendErrCount {
 					log.Error().Msgf("Max p2p keepalive err count reached, disconnecting. SessionID=%s", sessionID)
+					m.setTerminationReason(session.TerminationPeerLost)
 					m.Disconnect()
 					cancel()
 					return
@@ -823,6 +1164,178 @@ func (m *connectionManager) keepAliveLoop(channel p2p.Channel, sessionID session
 	}
 }
 
+// idleLoop disconnects when the connection transfers too little data during the idle timeout.
+func (m *connectionManager) idleLoop(ctx context.Context, statsSupplier statsSupplier) {
+	if !m.config.Idle.Enabled() {
+		return
+	}
+
+	var transferred uint64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(m.config.Idle.Timeout):
+			stats, err := statsSupplier.Statistics()
+			if err != nil {
+				log.Warn().Err(err).Msg("Could not get connection statistics for idle check")
+				continue
+			}
+
+			total := stats.BytesSent + stats.BytesReceived
+			if m.config.Idle.IsIdle(transferred, total) {
+				log.Info().Msgf("Less than %d bytes transferred in %s, disconnecting idle session. SessionID=%s", m.config.Idle.MinBytes, m.config.Idle.Timeout, m.Status().SessionID)
+				m.setTerminationReason(session.TerminationIdle)
+				logDisconnectError(m.Disconnect())
+				return
+			}
+			transferred = total
+		}
+	}
+}
+
+// reconcileLoop periodically compares connection traffic with the traffic counted by provider.
+func (m *connectionManager) reconcileLoop(ctx context.Context, channel p2p.ChannelSender, sessionID session.ID, statsSupplier statsSupplier) {
+	if channel == nil || !m.config.Reconciliation.Enabled() {
+		return
+	}
+
+	reconciler := session.NewTrafficReconciler(m.config.Reconciliation)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(m.config.Reconciliation.Interval):
+			stats, err := statsSupplier.Statistics()
+			if err != nil {
+				log.Warn().Err(err).Msg("Could not get connection statistics for traffic reconciliation")
+				continue
+			}
+
+			report, err := m.exchangeTrafficReport(ctx, channel, sessionID, stats)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Could not reconcile session traffic with provider. SessionID=%s", sessionID)
+				continue
+			}
+
+			local := stats.BytesSent + stats.BytesReceived
+			remote := report.GetBytesSent() + report.GetBytesReceived()
+			diverged, changed := reconciler.Reconcile(local, remote)
+			if !changed {
+				continue
+			}
+			if diverged {
+				log.Warn().Msgf("Session traffic diverges from provider: counted %d bytes, provider reported %d. SessionID=%s", local, remote, sessionID)
+			} else {
+				log.Info().Msgf("Session traffic matches provider again. SessionID=%s", sessionID)
+			}
+			m.eventBus.Publish(sessionEvent.AppTopicTrafficDivergence, sessionEvent.AppEventTrafficDivergence{
+				SessionID: string(sessionID),
+				Local:     local,
+				Remote:    remote,
+				Diverged:  diverged,
+			})
+		}
+	}
+}
+
+func (m *connectionManager) exchangeTrafficReport(ctx context.Context, channel p2p.ChannelSender, sessionID session.ID, stats Statistics) (*pb.SessionTrafficReport, error) {
+	msg := &pb.SessionTrafficReport{
+		SessionID:     string(sessionID),
+		BytesSent:     stats.BytesSent,
+		BytesReceived: stats.BytesReceived,
+	}
+	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionTrafficReport, msg.String())
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.KeepAlive.SendTimeout)
+	defer cancel()
+	res, err := channel.Send(ctx, p2p.TopicSessionTrafficReport, p2p.ProtoMessage(msg))
+	if err != nil {
+		return nil, fmt.Errorf("could not send traffic report: %w", err)
+	}
+
+	var report pb.SessionTrafficReport
+	if err := res.UnmarshalProto(&report); err != nil {
+		return nil, fmt.Errorf("could not unmarshal traffic report: %w", err)
+	}
+	return &report, nil
+}
+
+// guardLoop disconnects when the session exceeds the maximum duration or cost requested on connect.
+func (m *connectionManager) guardLoop(ctx context.Context, sessionID session.ID, params ConnectParams) {
+	var durationExceeded <-chan time.Time
+	if params.MaxDuration > 0 {
+		timer := time.NewTimer(params.MaxDuration)
+		defer timer.Stop()
+		durationExceeded = timer.C
+	}
+
+	var costExceeded <-chan struct{}
+	if params.MaxCost > 0 {
+		guard := newCostGuard(sessionID, params.MaxCost)
+		if err := m.eventBus.SubscribeAsync(pingpongEvent.AppTopicInvoicePaid, guard.consumeInvoicePaid); err != nil {
+			log.Error().Err(err).Msgf("Could not guard session cost, disconnecting. SessionID=%s", sessionID)
+			m.setTerminationReason(session.TerminationMaxCost)
+			logDisconnectError(m.Disconnect())
+			return
+		}
+		defer m.eventBus.Unsubscribe(pingpongEvent.AppTopicInvoicePaid, guard.consumeInvoicePaid)
+		costExceeded = guard.exceeded
+	}
+
+	var trafficExceeded <-chan struct{}
+	if params.MaxTraffic > 0 {
+		guard := newTrafficGuard(sessionID, params.MaxTraffic)
+		if err := m.eventBus.SubscribeAsync(AppTopicConnectionStatistics, guard.consumeStatistics); err != nil {
+			log.Error().Err(err).Msgf("Could not guard session traffic, disconnecting. SessionID=%s", sessionID)
+			m.setTerminationReason(session.TerminationMaxTraffic)
+			logDisconnectError(m.Disconnect())
+			return
+		}
+		defer m.eventBus.Unsubscribe(AppTopicConnectionStatistics, guard.consumeStatistics)
+		trafficExceeded = guard.exceeded
+	}
+
+	if durationExceeded == nil && costExceeded == nil && trafficExceeded == nil {
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+		return
+	case <-durationExceeded:
+		log.Info().Msgf("Session reached maximum duration of %s, disconnecting. SessionID=%s", params.MaxDuration, sessionID)
+		m.setTerminationReason(session.TerminationMaxDuration)
+	case <-costExceeded:
+		log.Info().Msgf("Session reached maximum cost of %d, disconnecting. SessionID=%s", params.MaxCost, sessionID)
+		m.setTerminationReason(session.TerminationMaxCost)
+	case <-trafficExceeded:
+		log.Info().Msgf("Session reached maximum traffic of %s, disconnecting. SessionID=%s", datasize.FromBytes(params.MaxTraffic), sessionID)
+		m.setTerminationReason(session.TerminationMaxTraffic)
+	}
+	logDisconnectError(m.Disconnect())
+}
+
+// peerLost checks whether provider was silent for longer than dead peer timeout
+// and publishes connectivity lost event if so.
+func (m *connectionManager) peerLost(channel p2p.Channel, sessionID session.ID) bool {
+	if m.config.KeepAlive.DeadPeerTimeout <= 0 {
+		return false
+	}
+
+	lastActivity := channel.LastActivity()
+	if time.Since(lastActivity) < m.config.KeepAlive.DeadPeerTimeout {
+		return false
+	}
+
+	log.Error().Msgf("No p2p traffic from provider since %s, disconnecting. SessionID=%s", lastActivity, sessionID)
+	m.eventBus.Publish(p2p.AppTopicConnectivityLost, p2p.AppEventConnectivityLost{
+		SessionID:    string(sessionID),
+		LastActivity: lastActivity,
+	})
+	return true
+}
+
 func (m *connectionManager) sendKeepAlivePing(ctx context.Context, channel p2p.Channel, sessionID session.ID) error {
 	msg := &pb.P2PKeepAlivePing{
 		SessionID: string(sessionID),
@@ -839,6 +1352,7 @@ func (m *connectionManager) currentCtx() context.Context {
 }
 
 func (m *connectionManager) reconnect() {
+	m.setTerminationReason(session.TerminationReconnect)
 	err := m.Disconnect()
 	if err != nil {
 		log.Error().Msgf("Failed to disconnect stale session: %w", err)
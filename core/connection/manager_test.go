@@ -28,6 +28,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 
+	"github.com/mysteriumnetwork/node/core/netmon"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
@@ -58,6 +59,9 @@ type testContext struct {
 	config                Config
 	statsReportInterval   time.Duration
 	mockP2P               *mockP2PDialer
+	mockPinger            *mockProviderPinger
+	mockPaymentErr        error
+	mockDNSProxy          *mockDNSProxy
 	mockTime              time.Time
 	sync.RWMutex
 }
@@ -125,7 +129,10 @@ func (tc *testContext) SetupTest() {
 	brokerConn := nats.StartConnectionMock()
 	brokerConn.MockResponse("fake-node-1.p2p-config-exchange", []byte("123"))
 
-	tc.mockP2P = &mockP2PDialer{&mockP2PChannel{}}
+	tc.mockP2P = &mockP2PDialer{ch: &mockP2PChannel{}}
+	tc.mockPinger = &mockProviderPinger{}
+	tc.mockPaymentErr = nil
+	tc.mockDNSProxy = &mockDNSProxy{queries: 10, blocked: 3}
 	tc.mockTime = time.Date(2000, time.January, 0, 10, 12, 3, 0, time.UTC)
 
 	tc.connManager = NewManager(
@@ -133,6 +140,7 @@ func (tc *testContext) SetupTest() {
 			consumer, provider identity.Identity, accountant common.Address, proposal market.ServiceProposal) (PaymentIssuer, error) {
 			tc.MockPaymentIssuer = &MockPaymentIssuer{
 				paymentDefinition: market.PaymentRate{},
+				MockError:         tc.mockPaymentErr,
 				stopChan:          make(chan struct{}),
 			}
 			return tc.MockPaymentIssuer, nil
@@ -144,6 +152,8 @@ func (tc *testContext) SetupTest() {
 		tc.statsReportInterval,
 		&mockValidator{},
 		tc.mockP2P,
+		NewFailureDiagnostics(tc.mockPinger, time.Second),
+		tc.mockDNSProxy,
 	)
 	tc.connManager.timeGetter = func() time.Time {
 		return tc.mockTime
@@ -166,6 +176,9 @@ func (tc *testContext) TestOnConnectErrorStatusIsNotConnected() {
 			AccountantID: accountantID,
 			State:        NotConnected,
 			Proposal:     activeProposal,
+
+			TerminationReason: session.TerminationSetupFailed,
+			FailureCause:      FailureCauseUnknown,
 		},
 		tc.connManager.Status(),
 	)
@@ -183,6 +196,7 @@ func (tc *testContext) TestWhenManagerMadeConnectionStatusReturnsConnectedStateA
 			State:        Connected,
 			SessionID:    establishedSessionID,
 			Proposal:     activeProposal,
+			NATTraversal: p2p.TraversalDirect,
 		},
 		tc.connManager.Status(),
 	)
@@ -202,6 +216,7 @@ func (tc *testContext) TestSessionDoesFullReconnectOnWakeupEvent() {
 			State:        Connected,
 			SessionID:    establishedSessionID,
 			Proposal:     activeProposal,
+			NATTraversal: p2p.TraversalDirect,
 		},
 		tc.connManager.Status(),
 	)
@@ -227,6 +242,56 @@ func (tc *testContext) TestSessionDoesFullReconnectOnWakeupEvent() {
 	assert.Equal(tc.T(), <-stateCh, Connected)
 }
 
+func (tc *testContext) TestSessionMigratesToChangedNetwork() {
+	tc.connManager.eventBus = eventbus.New()
+	rebound := make(chan struct{}, 1)
+	tc.fakeConnectionFactory.mockConnection.onRebind = func() error {
+		rebound <- struct{}{}
+		return nil
+	}
+	tc.mockP2P.ch.lock.Lock()
+	tc.mockP2P.ch.keepAlive = true
+	tc.mockP2P.ch.lock.Unlock()
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+	waitABit()
+
+	stateCh := make(chan State, 2)
+	tc.connManager.eventBus.Subscribe(AppTopicConnectionState, func(e AppEventConnectionState) {
+		stateCh <- e.State
+	})
+
+	tc.connManager.eventBus.Publish(netmon.AppTopicNetworkChanged, netmon.AppEventNetworkChanged{})
+
+	<-rebound
+	assert.ElementsMatch(tc.T(), []State{Reconnecting, Connected}, []State{<-stateCh, <-stateCh})
+	assert.Equal(tc.T(), establishedSessionID, tc.connManager.Status().SessionID)
+}
+
+func (tc *testContext) TestSessionDoesFullReconnectWhenNetworkMigrationFails() {
+	tc.connManager.eventBus = eventbus.New()
+	tc.fakeConnectionFactory.mockConnection.onRebind = func() error {
+		return errors.New("tunnel is gone")
+	}
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+	waitABit()
+
+	stateCh := make(chan State, 10)
+	tc.connManager.eventBus.Subscribe(AppTopicConnectionState, func(e AppEventConnectionState) {
+		stateCh <- e.State
+	})
+
+	tc.connManager.eventBus.Publish(netmon.AppTopicNetworkChanged, netmon.AppEventNetworkChanged{})
+
+	seen := make(map[State]bool)
+	for !seen[Reconnecting] || !seen[Connecting] || !seen[Connected] {
+		seen[<-stateCh] = true
+	}
+}
+
 func (tc *testContext) TestStatusReportsConnectingWhenConnectionIsInProgress() {
 	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{}
 
@@ -245,6 +310,7 @@ func (tc *testContext) TestStatusReportsConnectingWhenConnectionIsInProgress() {
 			State:        Connecting,
 			SessionID:    establishedSessionID,
 			Proposal:     activeProposal,
+			NATTraversal: p2p.TraversalDirect,
 		},
 		tc.connManager.Status(),
 	)
@@ -276,6 +342,9 @@ func (tc *testContext) TestStatusReportsNotConnected() {
 			State:        Disconnecting,
 			SessionID:    establishedSessionID,
 			Proposal:     activeProposal,
+			NATTraversal: p2p.TraversalDirect,
+
+			TerminationReason: session.TerminationRequested,
 		},
 		tc.connManager.Status(),
 	)
@@ -295,6 +364,9 @@ func (tc *testContext) TestStatusReportsNotConnected() {
 			State:        NotConnected,
 			SessionID:    establishedSessionID,
 			Proposal:     activeProposal,
+			NATTraversal: p2p.TraversalDirect,
+
+			TerminationReason: session.TerminationRequested,
 		},
 		tc.connManager.Status(),
 	)
@@ -322,6 +394,7 @@ func (tc *testContext) TestReconnectingStatusIsReportedWhenOpenVpnGoesIntoReconn
 			State:        Reconnecting,
 			SessionID:    establishedSessionID,
 			Proposal:     activeProposal,
+			NATTraversal: p2p.TraversalDirect,
 		},
 		tc.connManager.Status(),
 	)
@@ -366,6 +439,7 @@ func (tc *testContext) TestStatusIsConnectedWhenConnectCommandReturnsWithoutErro
 			State:        Connected,
 			SessionID:    establishedSessionID,
 			Proposal:     activeProposal,
+			NATTraversal: p2p.TraversalDirect,
 		},
 		tc.connManager.Status(),
 	)
@@ -406,7 +480,135 @@ func (tc *testContext) TestConnectMethodReturnsErrorIfConnectionExitsDuringConne
 	waitABit()
 	tc.fakeConnectionFactory.mockConnection.reportState(processExited)
 	connectWaiter.Wait()
-	assert.Equal(tc.T(), ErrConnectionFailed, err)
+	assert.True(tc.T(), errors.Is(err, ErrConnectionFailed))
+	assert.Equal(tc.T(), FailureCauseUnknown, FailureCauseOf(err))
+}
+
+func (tc *testContext) TestConnectReportsTimedOutTunnelHandshake() {
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{}
+	tc.fakeConnectionFactory.mockConnection.onStopReportStates = []fakeState{}
+	tc.connManager.config.Handshake = session.HandshakeBudget{Total: 50 * time.Millisecond}
+	tc.stubPublisher.Clear()
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.EqualError(tc.T(), err, "session handshake stage tunnel_handshake timed out after 20ms")
+	assert.Equal(tc.T(), session.HandshakeTunnel, session.TimedOutStage(err))
+	assert.Equal(tc.T(), FailureCauseHandshakeTimeout, FailureCauseOf(err))
+
+	assert.Eventually(tc.T(), func() bool {
+		for _, v := range tc.stubPublisher.GetEventHistory() {
+			if v.Topic != AppTopicConnectionState {
+				continue
+			}
+			if event := v.Event.(AppEventConnectionState); event.State == StateConnectionFailed {
+				return event.SessionInfo.TimedOutStage == session.HandshakeTunnel &&
+					event.SessionInfo.FailureCause == FailureCauseHandshakeTimeout
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func (tc *testContext) TestConnectReportsUnreachableProvider() {
+	tc.mockP2P.err = errors.New("no response from provider")
+	tc.mockPinger.err = errors.New("provider did not reply to ping")
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.Error(tc.T(), err)
+	assert.Equal(tc.T(), FailureCauseProviderUnreachable, FailureCauseOf(err))
+	assert.Equal(tc.T(), FailureCauseProviderUnreachable, tc.connManager.Status().FailureCause)
+}
+
+func (tc *testContext) TestConnectReportsBlockedNAT() {
+	tc.mockP2P.err = errors.New("no response from provider")
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.Error(tc.T(), err)
+	assert.Equal(tc.T(), FailureCauseNATBlocked, FailureCauseOf(err))
+}
+
+func (tc *testContext) TestConnectReportsRejectedPayment() {
+	tc.fakeConnectionFactory.mockConnection.onStartReportStates = []fakeState{}
+	tc.fakeConnectionFactory.mockConnection.onStopReportStates = []fakeState{}
+	tc.mockPaymentErr = errors.New("invoice rejected")
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.Error(tc.T(), err)
+	assert.Equal(tc.T(), FailureCausePaymentRejected, FailureCauseOf(err))
+}
+
+func (tc *testContext) TestConnectWithAdBlockServesDNSViaProxy() {
+	tc.stubPublisher.Clear()
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{AdBlock: true})
+	assert.NoError(tc.T(), err)
+
+	started, _ := tc.mockDNSProxy.state()
+	assert.True(tc.T(), started)
+	assert.Eventually(tc.T(), func() bool {
+		for _, v := range tc.stubPublisher.GetEventHistory() {
+			if v.Topic == AppTopicConnectionStatistics {
+				event := v.Event.(AppEventConnectionStatistics)
+				return event.Stats.DNSQueries == 10 && event.Stats.DNSBlocked == 3
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+
+	assert.NoError(tc.T(), tc.connManager.Disconnect())
+	_, stopped := tc.mockDNSProxy.state()
+	assert.True(tc.T(), stopped)
+}
+
+func (tc *testContext) TestConnectReportsTunnelBackend() {
+	tc.fakeConnectionFactory.mockConnection.backend = "kernel"
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), "kernel", tc.connManager.Status().Backend)
+}
+
+func (tc *testContext) TestExportProfileOfActiveConnection() {
+	tc.fakeConnectionFactory.mockConnection.profile = []byte("client")
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	profile, err := tc.connManager.ExportProfile()
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), []byte("client"), profile)
+}
+
+func (tc *testContext) TestExportProfileWithoutConnection() {
+	_, err := tc.connManager.ExportProfile()
+	assert.Equal(tc.T(), ErrNoConnection, err)
+}
+
+func (tc *testContext) TestDialThroughActiveConnection() {
+	var dialed string
+	tc.fakeConnectionFactory.mockConnection.onDial = func(address string) {
+		dialed = address
+	}
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	_, err = tc.connManager.Dial("tcp", "example.com:443")
+	assert.NoError(tc.T(), err)
+	assert.Equal(tc.T(), "example.com:443", dialed)
+}
+
+func (tc *testContext) TestDialWithoutConnection() {
+	_, err := tc.connManager.Dial("tcp", "example.com:443")
+	assert.Equal(tc.T(), ErrNoConnection, err)
+}
+
+func (tc *testContext) TestConnectWithoutAdBlockDoesNotStartDNSProxy() {
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	started, _ := tc.mockDNSProxy.state()
+	assert.False(tc.T(), started)
 }
 
 func (tc *testContext) Test_PaymentManager_WhenManagerMadeConnectionIsStarted() {
@@ -587,6 +789,149 @@ func (tc *testContext) Test_ManagerNotifiesAboutSuccessfulConnection() {
 	)
 }
 
+func (tc *testContext) Test_ManagerReportsSessionPolicyDenial() {
+	tc.mockP2P.ch.lock.Lock()
+	tc.mockP2P.ch.sessionErr = fmt.Errorf("public peer error: %w", &p2p.PublicError{
+		Code:    uint64(connectivity.StatusSessionPolicyDenied),
+		Message: "consumer location is not allowed by service policy",
+	})
+	tc.mockP2P.ch.lock.Unlock()
+	defer func() {
+		tc.mockP2P.ch.lock.Lock()
+		tc.mockP2P.ch.sessionErr = nil
+		tc.mockP2P.ch.lock.Unlock()
+	}()
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.True(tc.T(), errors.Is(err, ErrSessionPolicyDenied), err)
+	assert.EqualError(tc.T(), err, "consumer location is not allowed by service policy: provider service policy does not allow consumer location")
+}
+
+func (tc *testContext) Test_ManagerNegotiatesProtocol() {
+	tc.mockP2P.ch.lock.Lock()
+	tc.mockP2P.ch.sessionResponse = &pb.SessionResponse{
+		ID:              string(establishedSessionID),
+		ProtocolVersion: session.ProtocolVersion,
+		Capabilities:    []string{session.CapabilityPaymentsV3, "unknown"},
+	}
+	tc.mockP2P.ch.lock.Unlock()
+	defer func() {
+		tc.mockP2P.ch.lock.Lock()
+		tc.mockP2P.ch.sessionResponse = nil
+		tc.mockP2P.ch.lock.Unlock()
+	}()
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	status := tc.connManager.Status()
+	assert.Equal(tc.T(), session.ProtocolVersion, status.ProtocolVersion)
+	assert.Equal(tc.T(), []string{session.CapabilityPaymentsV3}, status.Capabilities)
+}
+
+func (tc *testContext) Test_ManagerAppliesReconfiguredSessionConfig() {
+	applied := make(chan []byte, 1)
+	tc.fakeConnectionFactory.mockConnection.onApplyConfig = func(sessionConfig []byte) {
+		applied <- sessionConfig
+	}
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	handler := tc.mockP2P.ch.handler(p2p.TopicSessionReconfigure)
+	tc.Require().NotNil(handler)
+
+	ctx := &mockP2PContext{req: p2p.ProtoMessage(&pb.SessionReconfigure{SessionID: "unknown", Config: []byte("{}")})}
+	assert.NoError(tc.T(), handler(ctx))
+	assert.Error(tc.T(), ctx.publicError)
+
+	ctx = &mockP2PContext{req: p2p.ProtoMessage(&pb.SessionReconfigure{SessionID: string(establishedSessionID), Config: []byte(`{"key":"new"}`)})}
+	assert.NoError(tc.T(), handler(ctx))
+	assert.NoError(tc.T(), ctx.publicError)
+	assert.Equal(tc.T(), []byte(`{"key":"new"}`), <-applied)
+}
+
+func (tc *testContext) Test_ManagerPublishesProviderGoingAway() {
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	handler := tc.mockP2P.ch.handler(p2p.TopicSessionGoingAway)
+	tc.Require().NotNil(handler)
+
+	deadline := time.Now().Add(time.Minute).Truncate(time.Second)
+	ctx := &mockP2PContext{req: p2p.ProtoMessage(&pb.SessionGoingAway{SessionID: string(establishedSessionID), Deadline: deadline.Unix(), Reason: "maintenance"})}
+	assert.NoError(tc.T(), handler(ctx))
+	assert.NoError(tc.T(), ctx.publicError)
+
+	var goingAway *AppEventConnectionGoingAway
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic == AppTopicConnectionGoingAway {
+			e := v.Event.(AppEventConnectionGoingAway)
+			goingAway = &e
+		}
+	}
+	if assert.NotNil(tc.T(), goingAway) {
+		assert.Equal(tc.T(), establishedSessionID, goingAway.SessionInfo.SessionID)
+		assert.True(tc.T(), deadline.Equal(goingAway.Deadline))
+		assert.Equal(tc.T(), "maintenance", goingAway.Reason)
+	}
+}
+
+func (tc *testContext) Test_ManagerDisconnectsOnLostPeer() {
+	tc.connManager.config.KeepAlive.DeadPeerTimeout = time.Second
+	tc.mockP2P.ch.lock.Lock()
+	tc.mockP2P.ch.lastActivity = time.Now().Add(-time.Minute)
+	tc.mockP2P.ch.lock.Unlock()
+	defer func() {
+		tc.mockP2P.ch.lock.Lock()
+		tc.mockP2P.ch.lastActivity = time.Time{}
+		tc.mockP2P.ch.lock.Unlock()
+	}()
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().State == NotConnected
+	}, 2*time.Second, 10*time.Millisecond)
+
+	var lostEvent *p2p.AppEventConnectivityLost
+	for _, v := range tc.stubPublisher.GetEventHistory() {
+		if v.Topic == p2p.AppTopicConnectivityLost {
+			e := v.Event.(p2p.AppEventConnectivityLost)
+			lostEvent = &e
+		}
+	}
+	if assert.NotNil(tc.T(), lostEvent) {
+		assert.Equal(tc.T(), string(establishedSessionID), lostEvent.SessionID)
+	}
+}
+
+func (tc *testContext) Test_ManagerDisconnectsIdleConnection() {
+	tc.connManager.config.Idle = session.IdleConfig{
+		Timeout:  10 * time.Millisecond,
+		MinBytes: tc.mockStatistics.BytesSent + tc.mockStatistics.BytesReceived + 1,
+	}
+
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{})
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().State == NotConnected
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(tc.T(), session.TerminationIdle, tc.connManager.Status().TerminationReason)
+}
+
+func (tc *testContext) Test_ManagerDisconnectsOnMaxDuration() {
+	err := tc.connManager.Connect(consumerID, accountantID, activeProposal, ConnectParams{MaxDuration: 10 * time.Millisecond})
+	assert.NoError(tc.T(), err)
+
+	assert.Eventually(tc.T(), func() bool {
+		return tc.connManager.Status().State == NotConnected
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(tc.T(), session.TerminationMaxDuration, tc.connManager.Status().TerminationReason)
+}
+
 func TestConnectionManagerSuite(t *testing.T) {
 	suite.Run(t, new(testContext))
 }
@@ -614,6 +959,9 @@ func (mpm *MockPaymentIssuer) Start() error {
 	mpm.Lock()
 	mpm.startCalled = true
 	mpm.Unlock()
+	if mpm.MockError != nil {
+		return mpm.MockError
+	}
 	<-mpm.stopChan
 	return mpm.MockError
 }
@@ -659,22 +1007,36 @@ func (mpm *mockPaymentMethod) GetRate() market.PaymentRate {
 }
 
 type mockP2PDialer struct {
-	ch *mockP2PChannel
+	ch  *mockP2PChannel
+	err error
 }
 
 func (m mockP2PDialer) Dial(ctx context.Context, consumerID identity.Identity, providerID identity.Identity, serviceType string, contactDef p2p.ContactDefinition) (p2p.Channel, error) {
+	if m.err != nil {
+		return nil, m.err
+	}
 	return m.ch, nil
 }
 
 type mockP2PChannel struct {
-	status proto.Message
-	lock   sync.Mutex
+	status          proto.Message
+	lock            sync.Mutex
+	lastActivity    time.Time
+	sessionResponse *pb.SessionResponse
+	sessionErr      error
+	handlers        map[string]p2p.HandlerFunc
+	keepAlive       bool
+	uncompressed    []string
 }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn {
 	return &net.UDPConn{}
 }
 
+func (m *mockP2PChannel) RemoteAddr() *net.UDPAddr {
+	return &net.UDPAddr{}
+}
+
 func (m *mockP2PChannel) getSentMsg() proto.Message {
 	m.lock.Lock()
 	defer m.lock.Unlock()
@@ -684,6 +1046,14 @@ func (m *mockP2PChannel) getSentMsg() proto.Message {
 func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message) (*p2p.Message, error) {
 	switch topic {
 	case p2p.TopicSessionCreate:
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if m.sessionErr != nil {
+			return nil, m.sessionErr
+		}
+		if m.sessionResponse != nil {
+			return p2p.ProtoMessage(m.sessionResponse), nil
+		}
 		res := &pb.SessionResponse{
 			ID: string(establishedSessionID),
 		}
@@ -697,20 +1067,75 @@ func (m *mockP2PChannel) Send(_ context.Context, topic string, msg *p2p.Message)
 		return nil, nil
 	case p2p.TopicSessionAcknowledge:
 		return nil, nil
+	case p2p.TopicKeepAlive:
+		m.lock.Lock()
+		defer m.lock.Unlock()
+		if m.keepAlive {
+			return nil, nil
+		}
 	}
 
 	return nil, errors.New("unexpected error")
 }
 
 func (m *mockP2PChannel) Handle(topic string, handler p2p.HandlerFunc) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.handlers == nil {
+		m.handlers = make(map[string]p2p.HandlerFunc)
+	}
+	m.handlers[topic] = handler
+}
+
+func (m *mockP2PChannel) handler(topic string) p2p.HandlerFunc {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.handlers[topic]
+}
+
+type mockP2PContext struct {
+	req         *p2p.Message
+	publicError error
 }
 
+func (m *mockP2PContext) Request() *p2p.Message { return m.req }
+
+func (m *mockP2PContext) Error(err error) error {
+	m.publicError = err
+	return nil
+}
+
+func (m *mockP2PContext) OkWithReply(_ *p2p.Message) error { return nil }
+
+func (m *mockP2PContext) OK() error { return nil }
+
 func (m *mockP2PChannel) ServiceConn() *net.UDPConn {
 	raddr, _ := net.ResolveUDPAddr("udp", "127.0.0.1:12345")
 	conn, _ := net.DialUDP("udp", nil, raddr)
 	return conn
 }
 
+func (m *mockP2PChannel) LastActivity() time.Time {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if m.lastActivity.IsZero() {
+		return time.Now()
+	}
+	return m.lastActivity
+}
+
+func (m *mockP2PChannel) Stalled() <-chan struct{} { return nil }
+
+func (m *mockP2PChannel) ActiveHandlers() int { return 0 }
+
+func (m *mockP2PChannel) TraversalMethod() string { return p2p.TraversalDirect }
+
+func (m *mockP2PChannel) DisableCompression(topic string) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.uncompressed = append(m.uncompressed, topic)
+}
+
 func (m *mockP2PChannel) Close() error {
 	return nil
 }
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/reducer"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+// PreflightCheck is a name of a single pre-connection check.
+type PreflightCheck string
+
+const (
+	// PreflightIdentityUnlocked checks that the consumer identity is unlocked.
+	PreflightIdentityUnlocked = PreflightCheck("identity_unlocked")
+	// PreflightRegistration checks that the consumer identity is registered.
+	PreflightRegistration = PreflightCheck("registration")
+	// PreflightBalance checks that the consumer balance covers the proposal price.
+	PreflightBalance = PreflightCheck("balance")
+	// PreflightServiceType checks that the proposal service type is supported by the consumer.
+	PreflightServiceType = PreflightCheck("service_type")
+	// PreflightNATCompatibility checks that the provider NAT can be traversed from the consumer NAT.
+	PreflightNATCompatibility = PreflightCheck("nat_compatibility")
+	// PreflightProviderReachable checks that the provider replies to ping.
+	PreflightProviderReachable = PreflightCheck("provider_reachable")
+)
+
+// DefaultPreflightPingTimeout limits how long the provider ping is waited for.
+const DefaultPreflightPingTimeout = 10 * time.Second
+
+// PreflightResult is a result of a single pre-connection check.
+type PreflightResult struct {
+	Check  PreflightCheck
+	Passed bool
+	// Reason explains why the check has not passed, or adds a note to the passed one.
+	Reason string
+}
+
+type registrationStatusProvider interface {
+	GetRegistrationStatus(identity.Identity) (registry.RegistrationStatus, error)
+}
+
+// Preflight checks whether a connection to the proposal is likely to succeed, without establishing a session.
+type Preflight struct {
+	validator   *Validator
+	registry    registrationStatusProvider
+	pinger      p2p.ProviderPinger
+	pingTimeout time.Duration
+}
+
+// NewPreflight returns a new instance of connection pre-flight checker.
+func NewPreflight(validator *Validator, registry registrationStatusProvider, pinger p2p.ProviderPinger, pingTimeout time.Duration) *Preflight {
+	return &Preflight{
+		validator:   validator,
+		registry:    registry,
+		pinger:      pinger,
+		pingTimeout: pingTimeout,
+	}
+}
+
+// Check runs all pre-connection checks. Consumer NAT type is optional, NAT compatibility is not checked when it is unknown.
+func (p *Preflight) Check(consumerID identity.Identity, proposal market.ServiceProposal, natType string) []PreflightResult {
+	return []PreflightResult{
+		p.checkUnlocked(consumerID),
+		p.checkRegistration(consumerID),
+		p.checkBalance(consumerID, proposal),
+		p.checkServiceType(proposal),
+		p.checkNATCompatibility(proposal, natType),
+		p.checkProviderReachable(proposal),
+	}
+}
+
+func (p *Preflight) checkUnlocked(consumerID identity.Identity) PreflightResult {
+	if !p.validator.isUnlocked(consumerID) {
+		return PreflightResult{Check: PreflightIdentityUnlocked, Reason: "consumer identity is locked, unlock it first"}
+	}
+	return PreflightResult{Check: PreflightIdentityUnlocked, Passed: true}
+}
+
+func (p *Preflight) checkRegistration(consumerID identity.Identity) PreflightResult {
+	status, err := p.registry.GetRegistrationStatus(consumerID)
+	if err != nil {
+		return PreflightResult{Check: PreflightRegistration, Reason: fmt.Sprintf("could not check registration status: %v", err)}
+	}
+
+	switch status {
+	case registry.Unregistered, registry.RegistrationError:
+		return PreflightResult{Check: PreflightRegistration, Reason: fmt.Sprintf("consumer identity is not registered (%s)", status)}
+	case registry.InProgress:
+		return PreflightResult{Check: PreflightRegistration, Passed: true, Reason: "registration is in progress"}
+	default:
+		return PreflightResult{Check: PreflightRegistration, Passed: true}
+	}
+}
+
+func (p *Preflight) checkBalance(consumerID identity.Identity, proposal market.ServiceProposal) PreflightResult {
+	if !p.validator.validateBalance(consumerID, proposal) {
+		return PreflightResult{
+			Check: PreflightBalance,
+			Reason: fmt.Sprintf("balance %v is lower than proposal price %d",
+				p.validator.consumerBalanceGetter.GetBalance(consumerID), proposal.PaymentMethod.GetPrice().Amount),
+		}
+	}
+	return PreflightResult{Check: PreflightBalance, Passed: true}
+}
+
+func (p *Preflight) checkServiceType(proposal market.ServiceProposal) PreflightResult {
+	if !proposal.IsSupported() {
+		return PreflightResult{Check: PreflightServiceType, Reason: fmt.Sprintf("service type %q is not supported", proposal.ServiceType)}
+	}
+	return PreflightResult{Check: PreflightServiceType, Passed: true}
+}
+
+func (p *Preflight) checkNATCompatibility(proposal market.ServiceProposal, natType string) PreflightResult {
+	if natType == "" {
+		return PreflightResult{Check: PreflightNATCompatibility, Passed: true, Reason: "consumer NAT type is unknown"}
+	}
+	if !reducer.NATCompatibility(natType)(proposal) {
+		providerNATType, _ := reducer.LocationNATType(proposal).(string)
+		return PreflightResult{
+			Check:  PreflightNATCompatibility,
+			Reason: fmt.Sprintf("provider behind %s NAT can not be reached from %s NAT", providerNATType, natType),
+		}
+	}
+	return PreflightResult{Check: PreflightNATCompatibility, Passed: true}
+}
+
+func (p *Preflight) checkProviderReachable(proposal market.ServiceProposal) PreflightResult {
+	contact, err := p2p.ParseContact(proposal.ProviderContacts)
+	if err != nil {
+		return PreflightResult{Check: PreflightProviderReachable, Reason: fmt.Sprintf("provider does not support p2p communication: %v", err)}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), p.pingTimeout)
+	defer cancel()
+	rtt, err := p.pinger.PingProvider(ctx, identity.FromAddress(proposal.ProviderID), proposal.UniqueID().ServiceKey(), contact)
+	if err != nil {
+		return PreflightResult{Check: PreflightProviderReachable, Reason: err.Error()}
+	}
+	return PreflightResult{Check: PreflightProviderReachable, Passed: true, Reason: fmt.Sprintf("replied in %s", rtt.Round(time.Millisecond))}
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRegistrationStatusProvider struct {
+	status registry.RegistrationStatus
+	err    error
+}
+
+func (m *mockRegistrationStatusProvider) GetRegistrationStatus(identity.Identity) (registry.RegistrationStatus, error) {
+	return m.status, m.err
+}
+
+type mockProviderPinger struct {
+	err         error
+	serviceType string
+}
+
+func (m *mockProviderPinger) PingProvider(_ context.Context, _ identity.Identity, serviceType string, _ p2p.ContactDefinition) (time.Duration, error) {
+	m.serviceType = serviceType
+	return 15 * time.Millisecond, m.err
+}
+
+type natServiceDefinition struct {
+	natType string
+}
+
+func (d natServiceDefinition) GetLocation() market.Location {
+	return market.Location{NATType: d.natType}
+}
+
+func preflightProposal() market.ServiceProposal {
+	return market.ServiceProposal{
+		ProviderID:        activeProviderID.Address,
+		ProviderContacts:  []market.Contact{activeProviderContact},
+		ServiceType:       activeServiceType,
+		ServiceDefinition: natServiceDefinition{natType: "prcone"},
+		PaymentMethod:     &mockPaymentMethod{price: money.Money{Amount: 100, Currency: "MYSTT"}},
+		PaymentMethodType: "PER_MINUTE",
+	}
+}
+
+func TestPreflight_Check_AllPassed(t *testing.T) {
+	pinger := &mockProviderPinger{}
+	preflight := NewPreflight(
+		NewValidator(&mockConsumerBalanceGetter{toReturn: 100}, &mockUnlockChecker{toReturn: true}),
+		&mockRegistrationStatusProvider{status: registry.InProgress},
+		pinger,
+		time.Second,
+	)
+
+	results := preflight.Check(consumerID, preflightProposal(), "fullcone")
+
+	assert.Equal(t, []PreflightResult{
+		{Check: PreflightIdentityUnlocked, Passed: true},
+		{Check: PreflightRegistration, Passed: true, Reason: "registration is in progress"},
+		{Check: PreflightBalance, Passed: true},
+		{Check: PreflightServiceType, Passed: true},
+		{Check: PreflightNATCompatibility, Passed: true},
+		{Check: PreflightProviderReachable, Passed: true, Reason: "replied in 15ms"},
+	}, results)
+	assert.Equal(t, activeServiceType, pinger.serviceType)
+}
+
+func TestPreflight_Check_ReportsReasons(t *testing.T) {
+	preflight := NewPreflight(
+		NewValidator(&mockConsumerBalanceGetter{toReturn: 99}, &mockUnlockChecker{toReturn: false}),
+		&mockRegistrationStatusProvider{status: registry.Unregistered},
+		&mockProviderPinger{err: errors.New("provider did not reply to ping")},
+		time.Second,
+	)
+	proposal := preflightProposal()
+	proposal.ServiceDefinition = market.UnsupportedServiceDefinition{}
+
+	results := preflight.Check(consumerID, proposal, "symmetric")
+
+	assert.Equal(t, []PreflightResult{
+		{Check: PreflightIdentityUnlocked, Reason: "consumer identity is locked, unlock it first"},
+		{Check: PreflightRegistration, Reason: "consumer identity is not registered (Unregistered)"},
+		{Check: PreflightBalance, Reason: "balance 99 is lower than proposal price 100"},
+		{Check: PreflightServiceType, Reason: `service type "fake-service" is not supported`},
+		{Check: PreflightNATCompatibility, Passed: true},
+		{Check: PreflightProviderReachable, Reason: "provider did not reply to ping"},
+	}, results)
+}
+
+func TestPreflight_Check_NATCompatibility(t *testing.T) {
+	preflight := NewPreflight(
+		NewValidator(&mockConsumerBalanceGetter{toReturn: 100}, &mockUnlockChecker{toReturn: true}),
+		&mockRegistrationStatusProvider{status: registry.RegisteredConsumer},
+		&mockProviderPinger{},
+		time.Second,
+	)
+
+	result := preflight.checkNATCompatibility(preflightProposal(), "symmetric")
+	assert.Equal(t, PreflightResult{Check: PreflightNATCompatibility, Reason: "provider behind prcone NAT can not be reached from symmetric NAT"}, result)
+
+	result = preflight.checkNATCompatibility(preflightProposal(), "")
+	assert.Equal(t, PreflightResult{Check: PreflightNATCompatibility, Passed: true, Reason: "consumer NAT type is unknown"}, result)
+}
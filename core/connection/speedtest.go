@@ -0,0 +1,250 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"time"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
+)
+
+// SpeedTestTarget is the endpoint which connection speed is measured against.
+type SpeedTestTarget string
+
+const (
+	// SpeedTestTargetPublic measures the speed to public speed test servers through the tunnel.
+	SpeedTestTargetPublic = SpeedTestTarget("public")
+	// SpeedTestTargetProvider measures the speed to provider itself over the session channel.
+	SpeedTestTargetProvider = SpeedTestTarget("provider")
+)
+
+var (
+	// ErrSpeedTestNotAllowed indicates that provider does not serve speed tests.
+	ErrSpeedTestNotAllowed = errors.New("provider does not allow speed tests")
+	// ErrSpeedTestUnknownTarget indicates that requested speed test target is not known.
+	ErrSpeedTestUnknownTarget = errors.New("unknown speed test target")
+)
+
+// SpeedTestConfig contains speed test options.
+type SpeedTestConfig struct {
+	// DownloadURL is requested with the amount of bytes to download appended.
+	DownloadURL string
+	// UploadURL receives the uploaded payload in POST request body.
+	UploadURL      string
+	Size           datasize.BitSize
+	LatencySamples int
+	Timeout        time.Duration
+}
+
+// SpeedTestResult holds the measured speed of connection.
+type SpeedTestResult struct {
+	Target    SpeedTestTarget
+	StartedAt time.Time
+	Latency   time.Duration
+	Download  datasize.BitSpeed
+	Upload    datasize.BitSpeed
+}
+
+// speedProber does the round trips measured by speed test.
+type speedProber interface {
+	ping(ctx context.Context) error
+	download(ctx context.Context, size uint64) (uint64, error)
+	upload(ctx context.Context, payload []byte) error
+}
+
+// SpeedTest measures latency, download and upload speed of the active connection.
+func (m *connectionManager) SpeedTest(ctx context.Context, target SpeedTestTarget) (SpeedTestResult, error) {
+	status := m.Status()
+	if status.State != Connected {
+		return SpeedTestResult{}, ErrNoConnection
+	}
+
+	var prober speedProber
+	switch target {
+	case SpeedTestTargetPublic:
+		prober = &httpProber{config: m.config.SpeedTest, client: &http.Client{}}
+	case SpeedTestTargetProvider:
+		if !session.HasCapability(status.Capabilities, session.CapabilitySpeedTest) {
+			return SpeedTestResult{}, ErrSpeedTestNotAllowed
+		}
+		// Probes are zero filled, compressing them would defeat the measurement.
+		m.channel.DisableCompression(p2p.TopicSessionSpeedTest)
+		prober = &channelProber{channel: m.channel, sessionID: status.SessionID}
+	default:
+		return SpeedTestResult{}, ErrSpeedTestUnknownTarget
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, m.config.SpeedTest.Timeout)
+	defer cancel()
+
+	result, err := runSpeedTest(ctx, prober, m.config.SpeedTest)
+	if err != nil {
+		return SpeedTestResult{}, err
+	}
+	result.Target = target
+	result.StartedAt = m.timeGetter()
+
+	m.eventBus.Publish(AppTopicConnectionSpeedTest, AppEventConnectionSpeedTest{
+		SessionInfo: status,
+		Result:      result,
+	})
+	return result, nil
+}
+
+func runSpeedTest(ctx context.Context, prober speedProber, config SpeedTestConfig) (SpeedTestResult, error) {
+	var result SpeedTestResult
+
+	samples := make([]time.Duration, 0, config.LatencySamples)
+	for i := 0; i < config.LatencySamples; i++ {
+		start := time.Now()
+		if err := prober.ping(ctx); err != nil {
+			return result, fmt.Errorf("could not measure latency: %w", err)
+		}
+		samples = append(samples, time.Since(start))
+	}
+	result.Latency = median(samples)
+
+	start := time.Now()
+	received, err := prober.download(ctx, config.Size.Bytes())
+	if err != nil {
+		return result, fmt.Errorf("could not measure download speed: %w", err)
+	}
+	result.Download = bitSpeed(received, time.Since(start))
+
+	payload := make([]byte, config.Size.Bytes())
+	start = time.Now()
+	if err := prober.upload(ctx, payload); err != nil {
+		return result, fmt.Errorf("could not measure upload speed: %w", err)
+	}
+	result.Upload = bitSpeed(uint64(len(payload)), time.Since(start))
+
+	return result, nil
+}
+
+func median(samples []time.Duration) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	return samples[len(samples)/2]
+}
+
+func bitSpeed(bytes uint64, took time.Duration) datasize.BitSpeed {
+	if took <= 0 {
+		return 0
+	}
+	return datasize.BitSpeed(float64(datasize.FromBytes(bytes)) / took.Seconds())
+}
+
+// httpProber measures the speed to public speed test servers.
+type httpProber struct {
+	config SpeedTestConfig
+	client *http.Client
+}
+
+func (p *httpProber) ping(ctx context.Context) error {
+	_, err := p.download(ctx, 0)
+	return err
+}
+
+func (p *httpProber) download(ctx context.Context, size uint64) (uint64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s%d", p.config.DownloadURL, size), nil)
+	if err != nil {
+		return 0, err
+	}
+	res, err := p.do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer res.Body.Close()
+
+	received, err := io.Copy(ioutil.Discard, res.Body)
+	return uint64(received), err
+}
+
+func (p *httpProber) upload(ctx context.Context, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.UploadURL, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	res, err := p.do(req)
+	if err != nil {
+		return err
+	}
+	return res.Body.Close()
+}
+
+func (p *httpProber) do(req *http.Request) (*http.Response, error) {
+	res, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode != http.StatusOK {
+		res.Body.Close()
+		return nil, fmt.Errorf("unexpected response status %s", res.Status)
+	}
+	return res, nil
+}
+
+// channelProber measures the speed to provider over the session p2p channel.
+type channelProber struct {
+	channel   p2p.ChannelSender
+	sessionID session.ID
+}
+
+func (p *channelProber) ping(ctx context.Context) error {
+	_, err := p.send(ctx, &pb.SessionSpeedTest{SessionID: string(p.sessionID)})
+	return err
+}
+
+func (p *channelProber) download(ctx context.Context, size uint64) (uint64, error) {
+	res, err := p.send(ctx, &pb.SessionSpeedTest{SessionID: string(p.sessionID), Size: size})
+	if err != nil {
+		return 0, err
+	}
+	return uint64(len(res.GetPayload())), nil
+}
+
+func (p *channelProber) upload(ctx context.Context, payload []byte) error {
+	_, err := p.send(ctx, &pb.SessionSpeedTest{SessionID: string(p.sessionID), Payload: payload})
+	return err
+}
+
+func (p *channelProber) send(ctx context.Context, msg *pb.SessionSpeedTest) (*pb.SessionSpeedTest, error) {
+	res, err := p.channel.Send(ctx, p2p.TopicSessionSpeedTest, p2p.ProtoMessage(msg))
+	if err != nil {
+		return nil, err
+	}
+
+	var reply pb.SessionSpeedTest
+	if err := res.UnmarshalProto(&reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
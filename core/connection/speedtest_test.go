@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+)
+
+type stubProber struct {
+	pings      []time.Duration
+	downloaded uint64
+	uploaded   int
+	err        error
+}
+
+func (p *stubProber) ping(context.Context) error {
+	time.Sleep(p.pings[0])
+	p.pings = p.pings[1:]
+	return p.err
+}
+
+func (p *stubProber) download(_ context.Context, size uint64) (uint64, error) {
+	p.downloaded = size
+	return size, p.err
+}
+
+func (p *stubProber) upload(_ context.Context, payload []byte) error {
+	p.uploaded = len(payload)
+	return p.err
+}
+
+func TestRunSpeedTest(t *testing.T) {
+	// given
+	prober := &stubProber{pings: []time.Duration{30 * time.Millisecond, time.Millisecond, 10 * time.Millisecond}}
+	config := SpeedTestConfig{Size: datasize.KiB, LatencySamples: 3}
+
+	// when
+	result, err := runSpeedTest(context.Background(), prober, config)
+
+	// then
+	assert.NoError(t, err)
+	assert.True(t, result.Latency >= 10*time.Millisecond && result.Latency < 30*time.Millisecond, "median latency %s", result.Latency)
+	assert.Equal(t, uint64(1024), prober.downloaded)
+	assert.Equal(t, 1024, prober.uploaded)
+	assert.NotZero(t, result.Download)
+	assert.NotZero(t, result.Upload)
+}
+
+func TestRunSpeedTestFailsOnProbeError(t *testing.T) {
+	prober := &stubProber{pings: []time.Duration{0}, err: errors.New("unreachable")}
+
+	_, err := runSpeedTest(context.Background(), prober, SpeedTestConfig{LatencySamples: 1})
+
+	assert.EqualError(t, err, "could not measure latency: unreachable")
+}
+
+func TestHTTPProber(t *testing.T) {
+	var uploaded int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/down":
+			size, _ := strconv.Atoi(r.URL.Query().Get("bytes"))
+			w.Write(make([]byte, size))
+		case "/up":
+			body, _ := ioutil.ReadAll(r.Body)
+			uploaded = len(body)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	prober := &httpProber{
+		config: SpeedTestConfig{DownloadURL: server.URL + "/down?bytes=", UploadURL: server.URL + "/up"},
+		client: server.Client(),
+	}
+
+	assert.NoError(t, prober.ping(context.Background()))
+	received, err := prober.download(context.Background(), 2048)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(2048), received)
+	assert.NoError(t, prober.upload(context.Background(), make([]byte, 512)))
+	assert.Equal(t, 512, uploaded)
+
+	prober.config.UploadURL = server.URL + "/missing"
+	assert.EqualError(t, prober.upload(context.Background(), nil), "unexpected response status 404 Not Found")
+}
+
+func TestSpeedTestValidatesConnection(t *testing.T) {
+	manager := &connectionManager{config: DefaultConfig(), status: Status{State: NotConnected}}
+
+	_, err := manager.SpeedTest(context.Background(), SpeedTestTargetPublic)
+	assert.Equal(t, ErrNoConnection, err)
+
+	manager.status = Status{State: Connected, Capabilities: []string{session.CapabilityPaymentsV3}}
+	_, err = manager.SpeedTest(context.Background(), SpeedTestTargetProvider)
+	assert.Equal(t, ErrSpeedTestNotAllowed, err)
+
+	_, err = manager.SpeedTest(context.Background(), SpeedTestTarget("somewhere"))
+	assert.Equal(t, ErrSpeedTestUnknownTarget, err)
+}
+
+func TestSpeedTestDisablesCompressionTowardsProvider(t *testing.T) {
+	channel := &mockP2PChannel{}
+	manager := &connectionManager{
+		config:  DefaultConfig(),
+		channel: channel,
+		status:  Status{State: Connected, Capabilities: []string{session.CapabilitySpeedTest}},
+	}
+
+	_, err := manager.SpeedTest(context.Background(), SpeedTestTargetProvider)
+	assert.Error(t, err)
+	assert.Equal(t, []string{p2p.TopicSessionSpeedTest}, channel.uncompressed)
+}
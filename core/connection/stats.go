@@ -29,6 +29,10 @@ type Statistics struct {
 	At            time.Time
 	BytesSent     uint64
 	BytesReceived uint64
+	// DNSQueries counts tunnel DNS queries served by local DNS proxy
+	DNSQueries uint64
+	// DNSBlocked counts tunnel DNS queries blocked by local DNS proxy
+	DNSBlocked uint64
 }
 
 // Diff calculates the difference in bytes between the old stats and new.
@@ -37,6 +41,8 @@ func (stats Statistics) Diff(new Statistics) Statistics {
 		At:            new.At,
 		BytesSent:     diff(stats.BytesSent, new.BytesSent),
 		BytesReceived: diff(stats.BytesReceived, new.BytesReceived),
+		DNSQueries:    diff(stats.DNSQueries, new.DNSQueries),
+		DNSBlocked:    diff(stats.DNSBlocked, new.DNSBlocked),
 	}
 }
 
@@ -54,6 +60,8 @@ func (stats Statistics) Plus(diff Statistics) Statistics {
 		At:            stats.At,
 		BytesReceived: stats.BytesReceived + diff.BytesReceived,
 		BytesSent:     stats.BytesSent + diff.BytesSent,
+		DNSQueries:    stats.DNSQueries + diff.DNSQueries,
+		DNSBlocked:    stats.DNSBlocked + diff.DNSBlocked,
 	}
 }
 
@@ -20,6 +20,7 @@ package connection
 import (
 	"time"
 
+	"github.com/mysteriumnetwork/node/consumer/traffic"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/rs/zerolog/log"
 )
@@ -27,37 +28,79 @@ import (
 // DefaultStatsReportInterval is interval for consumer connection statistics reporting.
 const DefaultStatsReportInterval = 1 * time.Second
 
+// idleStatsReportIntervalFactor slows down statistics sampling while no user facing subscriber is listening,
+// so an idle connection wakes the node up rarely.
+const idleStatsReportIntervalFactor = 10
+
 type statsSupplier interface {
 	Statistics() (Statistics, error)
 }
 
+// dnsStatsSupplier adds counters of local DNS proxy to the connection statistics.
+type dnsStatsSupplier struct {
+	statsSupplier
+	proxy DNSProxy
+}
+
+func (s dnsStatsSupplier) Statistics() (Statistics, error) {
+	stats, err := s.statsSupplier.Statistics()
+	if err != nil {
+		return stats, err
+	}
+	stats.DNSQueries, stats.DNSBlocked = s.proxy.Counters()
+	return stats, nil
+}
+
+// trafficSupplier supplies traffic of the session split per destination.
+type trafficSupplier interface {
+	Breakdown() traffic.Breakdown
+}
+
 type statsPublisher struct {
-	done     chan struct{}
-	bus      eventbus.Publisher
-	interval time.Duration
+	done         chan struct{}
+	bus          eventbus.Publisher
+	counter      eventbus.SubscriptionCounter
+	interval     time.Duration
+	idleInterval time.Duration
+	// traffic is published along with statistics if set.
+	traffic trafficSupplier
 }
 
 func newStatsPublisher(bus eventbus.Publisher, interval time.Duration) statsPublisher {
+	// Buses which do not count subscribers are treated as always having live subscribers.
+	counter, _ := bus.(eventbus.SubscriptionCounter)
 	return statsPublisher{
-		done:     make(chan struct{}),
-		bus:      bus,
-		interval: interval,
+		done:         make(chan struct{}),
+		bus:          bus,
+		counter:      counter,
+		interval:     interval,
+		idleInterval: interval * idleStatsReportIntervalFactor,
+	}
+}
+
+// live checks whether user facing subscribers are listening, subscription changes are picked up on the next sample.
+func (s statsPublisher) live() bool {
+	return s.counter == nil || s.counter.Subscribers(AppTopicConnectionStatisticsLive) > 0
+}
+
+func (s statsPublisher) nextInterval(live bool) time.Duration {
+	if live {
+		return s.interval
 	}
+	return s.idleInterval
 }
 
 func (s statsPublisher) start(sessionSupplier *connectionManager, statsSupplier statsSupplier) {
+	live := s.live()
+	timer := time.NewTimer(s.nextInterval(live))
+	defer timer.Stop()
+
 	for {
 		select {
-		case <-time.After(s.interval):
-			stats, err := statsSupplier.Statistics()
-			if err != nil {
-				log.Warn().Err(err).Msg("Could not get connection statistics")
-				continue
-			}
-			s.bus.Publish(AppTopicConnectionStatistics, AppEventConnectionStatistics{
-				Stats:       stats,
-				SessionInfo: sessionSupplier.Status(),
-			})
+		case <-timer.C:
+			live = s.live()
+			timer.Reset(s.nextInterval(live))
+			s.publish(sessionSupplier, statsSupplier, live)
 		case <-s.done:
 			log.Info().Msg("Stopped publishing connection statistics")
 			return
@@ -65,6 +108,35 @@ func (s statsPublisher) start(sessionSupplier *connectionManager, statsSupplier
 	}
 }
 
+// publish takes a single statistics sample and publishes it.
+func (s statsPublisher) publish(sessionSupplier *connectionManager, statsSupplier statsSupplier, live bool) {
+	stats, err := statsSupplier.Statistics()
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not get connection statistics")
+		return
+	}
+	e := AppEventConnectionStatistics{
+		Stats:       stats,
+		SessionInfo: sessionSupplier.Status(),
+	}
+	s.bus.Publish(AppTopicConnectionStatistics, e)
+	if live {
+		s.bus.Publish(AppTopicConnectionStatisticsLive, e)
+	}
+	s.publishTraffic(sessionSupplier)
+}
+
+// publishTraffic publishes traffic breakdown of the session if it is accounted.
+func (s statsPublisher) publishTraffic(sessionSupplier *connectionManager) {
+	if s.traffic == nil {
+		return
+	}
+	s.bus.Publish(AppTopicConnectionTraffic, AppEventConnectionTraffic{
+		SessionInfo: sessionSupplier.Status(),
+		Breakdown:   s.traffic.Breakdown(),
+	})
+}
+
 func (s statsPublisher) stop() {
 	s.done <- struct{}{}
 }
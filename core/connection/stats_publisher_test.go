@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/traffic"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingBus struct {
+	mu          sync.Mutex
+	subscribers int
+	published   map[string]int
+}
+
+func (b *countingBus) Publish(topic string, data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.published[topic]++
+}
+
+func (b *countingBus) Subscribers(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if topic != AppTopicConnectionStatisticsLive {
+		return 0
+	}
+	return b.subscribers
+}
+
+func (b *countingBus) count(topic string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.published[topic]
+}
+
+type fakeStatsSupplier struct{}
+
+func (fakeStatsSupplier) Statistics() (Statistics, error) {
+	return Statistics{BytesReceived: 1, BytesSent: 2}, nil
+}
+
+func TestStatsPublisher_SamplesSlowlyWithoutLiveSubscribers(t *testing.T) {
+	bus := &countingBus{published: make(map[string]int)}
+	publisher := newStatsPublisher(bus, time.Millisecond)
+	publisher.idleInterval = time.Hour
+
+	go publisher.start(&connectionManager{}, fakeStatsSupplier{})
+	defer publisher.stop()
+
+	time.Sleep(20 * time.Millisecond)
+	assert.Zero(t, bus.count(AppTopicConnectionStatistics))
+	assert.Zero(t, bus.count(AppTopicConnectionStatisticsLive))
+}
+
+func TestStatsPublisher_SamplesFastWithLiveSubscribers(t *testing.T) {
+	bus := &countingBus{published: make(map[string]int), subscribers: 1}
+	publisher := newStatsPublisher(bus, time.Millisecond)
+	publisher.idleInterval = time.Hour
+
+	go publisher.start(&connectionManager{}, fakeStatsSupplier{})
+	defer publisher.stop()
+
+	assert.Eventually(t, func() bool {
+		return bus.count(AppTopicConnectionStatistics) > 1 && bus.count(AppTopicConnectionStatisticsLive) > 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestStatsPublisher_SlowsDownWhenLiveSubscribersLeave(t *testing.T) {
+	bus := &countingBus{published: make(map[string]int), subscribers: 1}
+	publisher := newStatsPublisher(bus, time.Millisecond)
+	publisher.idleInterval = time.Hour
+
+	go publisher.start(&connectionManager{}, fakeStatsSupplier{})
+	defer publisher.stop()
+
+	assert.Eventually(t, func() bool {
+		return bus.count(AppTopicConnectionStatisticsLive) > 0
+	}, time.Second, time.Millisecond)
+
+	bus.mu.Lock()
+	bus.subscribers = 0
+	bus.mu.Unlock()
+
+	// Sample taken while live subscribers were leaving is the last one.
+	time.Sleep(10 * time.Millisecond)
+	published := bus.count(AppTopicConnectionStatistics)
+	time.Sleep(20 * time.Millisecond)
+	assert.Equal(t, published, bus.count(AppTopicConnectionStatistics))
+}
+
+type fakeTrafficSupplier struct{}
+
+func (fakeTrafficSupplier) Breakdown() traffic.Breakdown {
+	return traffic.Breakdown{Categories: []traffic.Usage{{Name: "video", BytesReceived: 1}}}
+}
+
+func TestStatsPublisher_PublishesTrafficBreakdown(t *testing.T) {
+	bus := &countingBus{published: make(map[string]int), subscribers: 1}
+	publisher := newStatsPublisher(bus, time.Millisecond)
+	publisher.traffic = fakeTrafficSupplier{}
+
+	go publisher.start(&connectionManager{}, fakeStatsSupplier{})
+
+	assert.Eventually(t, func() bool {
+		return bus.count(AppTopicConnectionTraffic) > 0
+	}, time.Second, time.Millisecond)
+
+	publisher.stop()
+
+	published := bus.count(AppTopicConnectionTraffic)
+	publisher.publishTraffic(&connectionManager{})
+	assert.Equal(t, published+1, bus.count(AppTopicConnectionTraffic))
+}
+
+func TestStatsPublisher_SkipsTrafficBreakdownWhenNotEnabled(t *testing.T) {
+	bus := &countingBus{published: make(map[string]int), subscribers: 1}
+	publisher := newStatsPublisher(bus, time.Millisecond)
+
+	go publisher.start(&connectionManager{}, fakeStatsSupplier{})
+
+	assert.Eventually(t, func() bool {
+		return bus.count(AppTopicConnectionStatistics) > 1
+	}, time.Second, time.Millisecond)
+	publisher.stop()
+	assert.Zero(t, bus.count(AppTopicConnectionTraffic))
+}
+
+func BenchmarkStatsPublisher_publish(b *testing.B) {
+	bus := &countingBus{published: make(map[string]int), subscribers: 1}
+	publisher := newStatsPublisher(bus, time.Second)
+	publisher.traffic = fakeTrafficSupplier{}
+	manager := &connectionManager{}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		publisher.publish(manager, fakeStatsSupplier{}, i%2 == 0)
+	}
+}
@@ -19,7 +19,10 @@ package connection
 
 import (
 	"context"
+	"net"
 	"sync"
+
+	"errors"
 )
 
 type fakeState string
@@ -77,6 +80,11 @@ func (c *connectionFactoryFake) CreateConnection(serviceType string) (Connection
 		onStartReportStats:  c.mockConnection.onStartReportStats,
 		fakeProcess:         sync.WaitGroup{},
 		stopBlock:           c.mockConnection.stopBlock,
+		onApplyConfig:       c.mockConnection.onApplyConfig,
+		onRebind:            c.mockConnection.onRebind,
+		backend:             c.mockConnection.backend,
+		profile:             c.mockConnection.profile,
+		onDial:              c.mockConnection.onDial,
 	}
 
 	return &copy, nil
@@ -91,6 +99,11 @@ type connectionMock struct {
 	onStartReportStats  Statistics
 	fakeProcess         sync.WaitGroup
 	stopBlock           chan struct{}
+	onApplyConfig       func(sessionConfig []byte)
+	onRebind            func() error
+	backend             string
+	profile             []byte
+	onDial              func(address string)
 	sync.RWMutex
 }
 
@@ -102,10 +115,43 @@ func (foc *connectionMock) Statistics() (Statistics, error) {
 	return foc.onStartReportStats, nil
 }
 
+func (foc *connectionMock) Backend() string {
+	return foc.backend
+}
+
+func (foc *connectionMock) Profile() ([]byte, error) {
+	return foc.profile, nil
+}
+
+func (foc *connectionMock) Dial(network, address string) (net.Conn, error) {
+	if foc.onDial != nil {
+		foc.onDial(address)
+	}
+	return nil, nil
+}
+
 func (foc *connectionMock) GetConfig() (ConsumerConfig, error) {
 	return nil, nil
 }
 
+func (foc *connectionMock) ApplyConfig(sessionConfig []byte) error {
+	foc.RLock()
+	defer foc.RUnlock()
+	if foc.onApplyConfig != nil {
+		foc.onApplyConfig(sessionConfig)
+	}
+	return nil
+}
+
+func (foc *connectionMock) Rebind() error {
+	foc.RLock()
+	defer foc.RUnlock()
+	if foc.onRebind == nil {
+		return errors.New("rebind is not supported")
+	}
+	return foc.onRebind()
+}
+
 func (foc *connectionMock) Start(ctx context.Context, connectionParams ConnectOptions) error {
 	foc.RLock()
 	defer foc.RUnlock()
@@ -113,6 +159,9 @@ func (foc *connectionMock) Start(ctx context.Context, connectionParams ConnectOp
 	if foc.onStartReturnError != nil {
 		return foc.onStartReturnError
 	}
+	if _, err := connectionParams.ResolveDNS(""); err != nil {
+		return err
+	}
 
 	foc.fakeProcess.Add(1)
 	for _, fakeState := range foc.onStartReportStates {
@@ -149,3 +198,38 @@ func (foc *connectionMock) StateCallback(callback func(state fakeState)) {
 
 	foc.stateCallback = callback
 }
+
+type mockDNSProxy struct {
+	mu       sync.Mutex
+	servers  []string
+	started  bool
+	stopped  bool
+	queries  uint64
+	blocked  uint64
+	startErr error
+}
+
+func (m *mockDNSProxy) Start(servers []string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.servers = servers
+	m.started = m.startErr == nil
+	return "127.0.0.1", m.startErr
+}
+
+func (m *mockDNSProxy) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+	return nil
+}
+
+func (m *mockDNSProxy) Counters() (uint64, uint64) {
+	return m.queries, m.blocked
+}
+
+func (m *mockDNSProxy) state() (started, stopped bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.started, m.stopped
+}
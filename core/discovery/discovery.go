@@ -21,6 +21,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
@@ -105,6 +106,10 @@ func (d *Discovery) Start(ownIdentity identity.Identity, proposal market.Service
 
 	d.proposalAnnouncementStopped.Add(1)
 
+	if err := d.eventBus.SubscribeAsync(nats.AppTopicBrokerStatus, d.handleBrokerStatusEvent); err != nil {
+		log.Error().Err(err).Msg("Failed to subscribe to broker status events")
+	}
+
 	go d.checkRegistration()
 
 	go d.mainDiscoveryLoop()
@@ -182,6 +187,32 @@ func (d *Discovery) handleRegistrationEvent(rep registry.AppEventIdentityRegistr
 	}
 }
 
+// handleBrokerStatusEvent re-registers the announced proposal once broker connection fails over,
+// so that the proposal reappears without waiting for the next ping.
+func (d *Discovery) handleBrokerStatusEvent(e nats.AppEventBrokerStatus) {
+	if e.Status != nats.BrokerStatusReconnected {
+		return
+	}
+
+	select {
+	case <-d.stop:
+		return
+	default:
+	}
+
+	d.mu.RLock()
+	status := d.status
+	d.mu.RUnlock()
+	if status != PingProposal {
+		return
+	}
+
+	log.Info().Msgf("Broker reconnected to %s, re-registering proposal", e.Server)
+	if err := d.proposalRegistry.RegisterProposal(d.proposal, d.signer); err != nil {
+		log.Error().Err(err).Msg("Failed to re-register proposal")
+	}
+}
+
 func (d *Discovery) registerIdentity() {
 	log.Info().Msg("Waiting for registration success event")
 	d.eventBus.Subscribe(registry.AppTopicIdentityRegistration, d.handleRegistrationEvent)
@@ -19,9 +19,11 @@ package discovery
 
 import (
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	identityregistry "github.com/mysteriumnetwork/node/identity/registry"
@@ -113,6 +115,25 @@ func TestStartStopUnregisterProposal(t *testing.T) {
 	assert.Equal(t, ProposalUnregistered, actualStatus)
 }
 
+func TestBrokerReconnectReregistersProposal(t *testing.T) {
+	d := discoveryWithMockedDependencies()
+	d.identityRegistry = &identityregistry.FakeRegistry{RegistrationStatus: identityregistry.RegisteredProvider}
+	registry := d.proposalRegistry.(*mockedProposalRegistry)
+
+	d.Start(providerID, serviceProposal)
+	defer d.Stop()
+
+	observeStatus(d, PingProposal)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&registry.registrations))
+
+	d.eventBus.Publish(nats.AppTopicBrokerStatus, nats.AppEventBrokerStatus{Status: nats.BrokerStatusDisconnected})
+	d.eventBus.Publish(nats.AppTopicBrokerStatus, nats.AppEventBrokerStatus{Status: nats.BrokerStatusReconnected, Server: "nats://broker2:4222"})
+
+	assert.Eventually(t, func() bool {
+		return atomic.LoadInt32(&registry.registrations) == 2
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 func observeStatus(d *Discovery, status Status) Status {
 	for {
 		d.mu.RLock()
@@ -126,9 +147,11 @@ func observeStatus(d *Discovery, status Status) Status {
 }
 
 type mockedProposalRegistry struct {
+	registrations int32
 }
 
-func (mockedProposalRegistry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+func (m *mockedProposalRegistry) RegisterProposal(proposal market.ServiceProposal, signer identity.Signer) error {
+	atomic.AddInt32(&m.registrations, 1)
 	return nil
 }
 
@@ -20,11 +20,11 @@ package discovery
 // Topic represents the different topics a consumer can subscribe to
 const (
 	// AppTopicProposalAdded represents newly announced proposal
-	AppTopicProposalAdded = "ProposalAdded"
+	AppTopicProposalAdded = "proposal.added"
 	// AppTopicProposalUpdated represents re-announced proposal
-	AppTopicProposalUpdated = "ProposalUpdated"
+	AppTopicProposalUpdated = "proposal.updated"
 	// AppTopicProposalRemoved represents newly de-announced proposal
-	AppTopicProposalRemoved = "ProposalRemoved"
+	AppTopicProposalRemoved = "proposal.removed"
 	// AppTopicProposalAnnounce represent proposal events topic.
-	AppTopicProposalAnnounce = "proposalEvent"
+	AppTopicProposalAnnounce = "proposal.announced"
 )
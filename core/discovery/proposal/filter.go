@@ -18,6 +18,8 @@
 package proposal
 
 import (
+	"math"
+
 	"github.com/mysteriumnetwork/node/core/discovery/reducer"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/market/mysterium"
@@ -34,8 +36,17 @@ type Filter struct {
 	LowerTimePriceBound *uint64
 	UpperGBPriceBound   *uint64
 	LowerGBPriceBound   *uint64
-	ExcludeUnsupported  bool
-	IncludeFailed       bool
+	IncludeCountries    []string
+	ExcludeCountries    []string
+	IncludeISPs         []string
+	ExcludeISPs         []string
+	NATCompatibility    string
+	ContentFilter       []string
+	Tags                []string
+	// QualityMin is minimum quality score of provider in range [0, 1], it is applied by the quality aware repository.
+	QualityMin         *float64
+	ExcludeUnsupported bool
+	IncludeFailed      bool
 }
 
 // Matches return flag if filter matches given proposal
@@ -59,12 +70,36 @@ func (filter *Filter) Matches(proposal market.ServiceProposal) bool {
 		conditions = append(conditions, reducer.AccessPolicy(filter.AccessPolicyID, filter.AccessPolicySource))
 	}
 
-	if filter.UpperTimePriceBound != nil && filter.LowerTimePriceBound != nil {
-		conditions = append(conditions, reducer.PriceMinute(*filter.LowerTimePriceBound, *filter.UpperTimePriceBound))
+	if len(filter.IncludeCountries) > 0 {
+		conditions = append(conditions, reducer.InString(reducer.LocationCountry, filter.IncludeCountries...))
+	}
+	if len(filter.ExcludeCountries) > 0 {
+		conditions = append(conditions, reducer.Not(reducer.InString(reducer.LocationCountry, filter.ExcludeCountries...)))
+	}
+	if len(filter.IncludeISPs) > 0 {
+		conditions = append(conditions, reducer.InString(reducer.LocationISP, filter.IncludeISPs...))
+	}
+	if len(filter.ExcludeISPs) > 0 {
+		conditions = append(conditions, reducer.Not(reducer.InString(reducer.LocationISP, filter.ExcludeISPs...)))
+	}
+	if filter.NATCompatibility != "" {
+		conditions = append(conditions, reducer.NATCompatibility(filter.NATCompatibility))
+	}
+	if len(filter.ContentFilter) > 0 {
+		conditions = append(conditions, reducer.ContentFilter(filter.ContentFilter...))
+	}
+	if len(filter.Tags) > 0 {
+		conditions = append(conditions, reducer.Tags(filter.Tags...))
 	}
 
-	if filter.UpperGBPriceBound != nil && filter.LowerGBPriceBound != nil {
-		conditions = append(conditions, reducer.PriceGiB(*filter.LowerGBPriceBound, *filter.UpperGBPriceBound))
+	if filter.UpperTimePriceBound != nil || filter.LowerTimePriceBound != nil {
+		lower, upper := priceBounds(filter.LowerTimePriceBound, filter.UpperTimePriceBound)
+		conditions = append(conditions, reducer.PriceMinute(lower, upper))
+	}
+
+	if filter.UpperGBPriceBound != nil || filter.LowerGBPriceBound != nil {
+		lower, upper := priceBounds(filter.LowerGBPriceBound, filter.UpperGBPriceBound)
+		conditions = append(conditions, reducer.PriceGiB(lower, upper))
 	}
 
 	if len(conditions) > 0 {
@@ -73,6 +108,18 @@ func (filter *Filter) Matches(proposal market.ServiceProposal) bool {
 	return true
 }
 
+// priceBounds fills in missing price bounds, so that only one of them can be given.
+func priceBounds(lower, upper *uint64) (uint64, uint64) {
+	l, u := uint64(0), uint64(math.MaxUint64)
+	if lower != nil {
+		l = *lower
+	}
+	if upper != nil {
+		u = *upper
+	}
+	return l, u
+}
+
 // ToAPIQuery serialises filter to query of Mysterium API
 func (filter *Filter) ToAPIQuery() mysterium.ProposalsQuery {
 	query := mysterium.ProposalsQuery{
@@ -43,7 +43,9 @@ var (
 		Source: "blacklist.txt",
 	}
 	locationDatacenter  = market.Location{ASN: 1000, Country: "DE", City: "Berlin", NodeType: "datacenter"}
-	locationResidential = market.Location{ASN: 124, Country: "LT", City: "Vilnius", NodeType: "residential"}
+	locationResidential = market.Location{ASN: 124, Country: "LT", City: "Vilnius", ISP: "Telia", NodeType: "residential", NATType: "symmetric"}
+
+	descriptionStreaming = market.ProviderDescription{Nickname: "Streamer", Tags: []string{"streaming-friendly", "no-logs"}}
 
 	proposalEmpty              = market.ServiceProposal{}
 	proposalProvider1Streaming = market.ServiceProposal{
@@ -60,8 +62,9 @@ var (
 	proposalProvider2Streaming = market.ServiceProposal{
 		ProviderID:        provider2,
 		ServiceType:       serviceTypeStreaming,
-		ServiceDefinition: mockService{Location: locationResidential},
+		ServiceDefinition: mockService{Location: locationResidential, Description: descriptionStreaming},
 		AccessPolicies:    &[]market.AccessPolicy{accessRuleWhitelist, accessRuleBlacklist},
+		ContentFilter:     []string{"malware"},
 	}
 	proposalTimeExpensive = market.ServiceProposal{
 		PaymentMethod: &mockPaymentMethod{
@@ -132,13 +135,23 @@ var (
 )
 
 type mockService struct {
-	Location market.Location
+	Location    market.Location
+	Description market.ProviderDescription
 }
 
 func (service mockService) GetLocation() market.Location {
 	return service.Location
 }
 
+func (service mockService) GetDescription() market.ProviderDescription {
+	return service.Description
+}
+
+func (service mockService) WithDescription(description market.ProviderDescription) market.ServiceDefinition {
+	service.Description = description
+	return service
+}
+
 func Test_ProposalFilter_FiltersAll(t *testing.T) {
 	filter := &Filter{}
 	assert.True(t, filter.Matches(proposalEmpty))
@@ -245,6 +258,95 @@ func Test_ProposalFilter_Filters_ByTimeBounds(t *testing.T) {
 	assert.True(t, filter.Matches(proposalTimeExact))
 }
 
+func Test_ProposalFilter_Filters_ByUpperTimeBoundOnly(t *testing.T) {
+	var upper uint64 = 1000000
+	filter := &Filter{
+		UpperTimePriceBound: &upper,
+	}
+
+	assert.True(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(proposalTimeExpensive))
+	assert.True(t, filter.Matches(proposalTimeCheap))
+	assert.True(t, filter.Matches(proposalTimeExact))
+}
+
+func Test_ProposalFilter_FiltersByCountry(t *testing.T) {
+	filter := &Filter{
+		IncludeCountries: []string{"DE", "US"},
+	}
+	assert.False(t, filter.Matches(proposalEmpty))
+	assert.True(t, filter.Matches(proposalProvider1Streaming))
+	assert.False(t, filter.Matches(proposalProvider1Noop))
+	assert.False(t, filter.Matches(proposalProvider2Streaming))
+
+	filter = &Filter{
+		ExcludeCountries: []string{"DE"},
+	}
+	assert.True(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(proposalProvider1Streaming))
+	assert.True(t, filter.Matches(proposalProvider1Noop))
+	assert.True(t, filter.Matches(proposalProvider2Streaming))
+}
+
+func Test_ProposalFilter_FiltersByISP(t *testing.T) {
+	filter := &Filter{
+		IncludeISPs: []string{"Telia"},
+	}
+	assert.False(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(proposalProvider1Streaming))
+	assert.True(t, filter.Matches(proposalProvider2Streaming))
+
+	filter = &Filter{
+		ExcludeISPs: []string{"Telia"},
+	}
+	assert.True(t, filter.Matches(proposalEmpty))
+	assert.True(t, filter.Matches(proposalProvider1Streaming))
+	assert.False(t, filter.Matches(proposalProvider2Streaming))
+}
+
+func Test_ProposalFilter_FiltersByNATCompatibility(t *testing.T) {
+	filter := &Filter{
+		NATCompatibility: "symmetric",
+	}
+	assert.True(t, filter.Matches(proposalEmpty))
+	assert.True(t, filter.Matches(proposalProvider1Streaming))
+	assert.False(t, filter.Matches(proposalProvider2Streaming))
+
+	filter = &Filter{
+		NATCompatibility: "none",
+	}
+	assert.True(t, filter.Matches(proposalProvider1Streaming))
+	assert.True(t, filter.Matches(proposalProvider2Streaming))
+}
+
+func Test_ProposalFilter_FiltersByContentFilter(t *testing.T) {
+	filter := &Filter{
+		ContentFilter: []string{"malware"},
+	}
+	assert.False(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(proposalProvider1Streaming))
+	assert.True(t, filter.Matches(proposalProvider2Streaming))
+
+	filter = &Filter{
+		ContentFilter: []string{"malware", "adult"},
+	}
+	assert.False(t, filter.Matches(proposalProvider2Streaming))
+}
+
+func Test_ProposalFilter_FiltersByTags(t *testing.T) {
+	filter := &Filter{
+		Tags: []string{"streaming-friendly"},
+	}
+	assert.False(t, filter.Matches(proposalEmpty))
+	assert.False(t, filter.Matches(proposalProvider1Streaming))
+	assert.True(t, filter.Matches(proposalProvider2Streaming))
+
+	filter = &Filter{
+		Tags: []string{"streaming-friendly", "torrent-friendly"},
+	}
+	assert.False(t, filter.Matches(proposalProvider2Streaming))
+}
+
 func Test_ProposalFilter_Filters_Unsupported(t *testing.T) {
 	filter := &Filter{
 		ExcludeUnsupported: true,
@@ -39,7 +39,9 @@ var (
 		Source: "blacklist.txt",
 	}
 	locationDatacenter  = market.Location{ASN: 1000, Country: "DE", City: "Berlin", NodeType: "datacenter"}
-	locationResidential = market.Location{ASN: 124, Country: "LT", City: "Vilnius", NodeType: "residential"}
+	locationResidential = market.Location{ASN: 124, Country: "LT", City: "Vilnius", ISP: "Telia", NodeType: "residential", NATType: "symmetric"}
+
+	descriptionStreaming = market.ProviderDescription{Nickname: "Streamer", Tags: []string{"streaming-friendly", "no-logs"}}
 
 	proposalEmpty              = market.ServiceProposal{}
 	proposalProvider1Streaming = market.ServiceProposal{
@@ -56,8 +58,9 @@ var (
 	proposalProvider2Streaming = market.ServiceProposal{
 		ProviderID:        provider2,
 		ServiceType:       serviceTypeStreaming,
-		ServiceDefinition: mockService{Location: locationResidential},
+		ServiceDefinition: mockService{Location: locationResidential, Description: descriptionStreaming},
 		AccessPolicies:    &[]market.AccessPolicy{accessRuleWhitelist, accessRuleBlacklist},
+		ContentFilter:     []string{"malware"},
 	}
 	proposalTimeExpensive = market.ServiceProposal{
 		PaymentMethod: &mockPaymentMethod{
@@ -152,13 +155,23 @@ func (mpm *mockPaymentMethod) GetRate() market.PaymentRate {
 }
 
 type mockService struct {
-	Location market.Location
+	Location    market.Location
+	Description market.ProviderDescription
 }
 
 func (service mockService) GetLocation() market.Location {
 	return service.Location
 }
 
+func (service mockService) GetDescription() market.ProviderDescription {
+	return service.Description
+}
+
+func (service mockService) WithDescription(description market.ProviderDescription) market.ServiceDefinition {
+	service.Description = description
+	return service
+}
+
 func conditionAlwaysMatch(_ market.ServiceProposal) bool {
 	return true
 }
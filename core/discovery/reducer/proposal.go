@@ -67,6 +67,24 @@ func LocationType(proposal market.ServiceProposal) interface{} {
 	return service.GetLocation().NodeType
 }
 
+// LocationISP selects location ISP from proposal
+func LocationISP(proposal market.ServiceProposal) interface{} {
+	service := proposal.ServiceDefinition
+	if service == nil {
+		return nil
+	}
+	return service.GetLocation().ISP
+}
+
+// LocationNATType selects provider NAT type from proposal
+func LocationNATType(proposal market.ServiceProposal) interface{} {
+	service := proposal.ServiceDefinition
+	if service == nil {
+		return nil
+	}
+	return service.GetLocation().NATType
+}
+
 // PriceMinute checks if the price per minute is below the given value
 func PriceMinute(lowerBound, upperBound uint64) func(market.ServiceProposal) bool {
 	return pricePerTime(lowerBound, upperBound, time.Minute)
@@ -132,6 +150,86 @@ func AccessPolicy(id, source string) func(market.ServiceProposal) bool {
 	}
 }
 
+// natCompatibility lists provider NAT types a consumer behind the given NAT type can traverse.
+var natCompatibility = map[string][]string{
+	NATTypeNone:               {NATTypeNone, NATTypeFullCone, NATTypeRestrictedCone, NATTypePortRestrictedCone, NATTypeSymmetric},
+	NATTypeFullCone:           {NATTypeNone, NATTypeFullCone, NATTypeRestrictedCone, NATTypePortRestrictedCone, NATTypeSymmetric},
+	NATTypeRestrictedCone:     {NATTypeNone, NATTypeFullCone, NATTypeRestrictedCone, NATTypePortRestrictedCone, NATTypeSymmetric},
+	NATTypePortRestrictedCone: {NATTypeNone, NATTypeFullCone, NATTypeRestrictedCone, NATTypePortRestrictedCone},
+	NATTypeSymmetric:          {NATTypeNone, NATTypeFullCone, NATTypeRestrictedCone},
+}
+
+// NAT types which can be announced by providers and consumers.
+const (
+	NATTypeNone               = "none"
+	NATTypeFullCone           = "fullcone"
+	NATTypeRestrictedCone     = "rcone"
+	NATTypePortRestrictedCone = "prcone"
+	NATTypeSymmetric          = "symmetric"
+)
+
+// IsNATType checks if given NAT type is one of the known ones.
+func IsNATType(natType string) bool {
+	_, ok := natCompatibility[natType]
+	return ok
+}
+
+// NATCompatibility returns a matcher for checking if proposal's provider can be reached from the given local NAT type.
+// Proposals without announced NAT type and unknown local NAT types are considered compatible.
+func NATCompatibility(natType string) func(market.ServiceProposal) bool {
+	compatible, ok := natCompatibility[natType]
+	if !ok {
+		return func(market.ServiceProposal) bool {
+			return true
+		}
+	}
+
+	return func(proposal market.ServiceProposal) bool {
+		providerNATType, _ := LocationNATType(proposal).(string)
+		if providerNATType == "" {
+			return true
+		}
+		for _, t := range compatible {
+			if t == providerNATType {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ContentFilter returns a matcher for checking if proposal's provider blocks all given content categories in DNS queries.
+func ContentFilter(categories ...string) func(market.ServiceProposal) bool {
+	return func(proposal market.ServiceProposal) bool {
+		for _, category := range categories {
+			var filtered bool
+			for _, c := range proposal.ContentFilter {
+				if c == category {
+					filtered = true
+					break
+				}
+			}
+			if !filtered {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Tags returns a matcher for checking if proposal's provider has declared all given tags in its self-description.
+func Tags(tags ...string) func(market.ServiceProposal) bool {
+	return func(proposal market.ServiceProposal) bool {
+		description := market.DescriptionOf(proposal.ServiceDefinition)
+		for _, tag := range tags {
+			if !description.HasTag(tag) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
 // Unsupported filters out unsupported proposals
 func Unsupported() func(market.ServiceProposal) bool {
 	return func(proposal market.ServiceProposal) bool {
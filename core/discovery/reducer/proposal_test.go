@@ -20,6 +20,7 @@ package reducer
 import (
 	"testing"
 
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,6 +60,59 @@ func Test_Location_FiltersByNodeType(t *testing.T) {
 	assert.True(t, match(proposalProvider2Streaming))
 }
 
+func Test_Location_FiltersByISP(t *testing.T) {
+	match := EqualString(LocationISP, "Telia")
+
+	assert.False(t, match(proposalEmpty))
+	assert.False(t, match(proposalProvider1Streaming))
+	assert.True(t, match(proposalProvider2Streaming))
+}
+
+func Test_NATCompatibility(t *testing.T) {
+	match := NATCompatibility(NATTypeSymmetric)
+	assert.True(t, match(proposalEmpty))
+	assert.True(t, match(proposalProvider1Streaming))
+	assert.False(t, match(proposalProvider2Streaming))
+
+	match = NATCompatibility(NATTypeFullCone)
+	assert.True(t, match(proposalProvider2Streaming))
+
+	match = NATCompatibility("unknown")
+	assert.True(t, match(proposalProvider2Streaming))
+}
+
+func Test_ContentFilter(t *testing.T) {
+	match := ContentFilter("malware")
+	assert.False(t, match(proposalEmpty))
+	assert.False(t, match(proposalProvider1Streaming))
+	assert.True(t, match(proposalProvider2Streaming))
+
+	match = ContentFilter("malware", "adult")
+	assert.False(t, match(proposalProvider2Streaming))
+}
+
+func Test_Tags(t *testing.T) {
+	match := Tags("streaming-friendly")
+	assert.False(t, match(proposalEmpty))
+	assert.False(t, match(proposalProvider1Streaming))
+	assert.True(t, match(proposalProvider2Streaming))
+
+	match = Tags("streaming-friendly", "no-logs")
+	assert.True(t, match(proposalProvider2Streaming))
+
+	match = Tags("streaming-friendly", "torrent-friendly")
+	assert.False(t, match(proposalProvider2Streaming))
+}
+
+func Test_Tags_IgnoresInvalidDescription(t *testing.T) {
+	proposal := market.ServiceProposal{
+		ServiceDefinition: mockService{Description: market.ProviderDescription{
+			Tags: []string{"streaming-friendly", "Not A Tag"},
+		}},
+	}
+	assert.False(t, Tags("streaming-friendly")(proposal))
+}
+
 func Test_AccessPolicy_FiltersByID(t *testing.T) {
 	match := AccessPolicy(accessRuleWhitelist.ID, "")
 
@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package loadtest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/services/noop"
+	"github.com/mysteriumnetwork/node/utils"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrProposalNotFound indicates that provider's noop proposal was not discovered in time.
+var ErrProposalNotFound = errors.New("noop proposal of the provider not found")
+
+type proposalFinder interface {
+	Proposal(id market.ProposalID) (*market.ServiceProposal, error)
+}
+
+// ConnectionManagerFactory creates an independent connection manager for every synthetic session, named by the given ID.
+type ConnectionManagerFactory func(id string) connection.Manager
+
+// Options describes synthetic consumer load.
+type Options struct {
+	// Sessions is a number of concurrent consumer sessions.
+	Sessions     int
+	ConsumerID   identity.Identity
+	ProviderID   string
+	AccountantID common.Address
+	// DiscoveryTimeout limits how long generator waits for the provider's proposal to be discovered.
+	DiscoveryTimeout time.Duration
+}
+
+// Generator spins up concurrent consumer sessions against the noop service of a single provider,
+// so that payment and state subsystems can be benchmarked.
+type Generator struct {
+	options      Options
+	proposals    proposalFinder
+	newManager   ConnectionManagerFactory
+	pollInterval time.Duration
+
+	mu       sync.Mutex
+	managers []connection.Manager
+}
+
+// NewGenerator creates a new synthetic consumer load generator.
+func NewGenerator(options Options, proposals proposalFinder, newManager ConnectionManagerFactory) *Generator {
+	return &Generator{
+		options:      options,
+		proposals:    proposals,
+		newManager:   newManager,
+		pollInterval: 2 * time.Second,
+	}
+}
+
+// Start connects all sessions concurrently. It blocks until every session is either established or failed.
+func (g *Generator) Start() error {
+	proposal, err := g.waitForProposal()
+	if err != nil {
+		return err
+	}
+
+	log.Info().Msgf("Starting %d load test sessions against provider %s", g.options.Sessions, g.options.ProviderID)
+
+	var wg sync.WaitGroup
+	var errsMu sync.Mutex
+	errs := utils.ErrorCollection{}
+	for i := 0; i < g.options.Sessions; i++ {
+		manager := g.newManager(fmt.Sprintf("loadtest-%d", i))
+		g.mu.Lock()
+		g.managers = append(g.managers, manager)
+		g.mu.Unlock()
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := manager.Connect(g.options.ConsumerID, g.options.AccountantID, *proposal, connection.ConnectParams{DisableKillSwitch: true})
+
+			errsMu.Lock()
+			defer errsMu.Unlock()
+			errs.Add(err)
+		}()
+	}
+	wg.Wait()
+
+	log.Info().Msgf("Load test sessions established: %d/%d", g.options.Sessions-len(errs), g.options.Sessions)
+	return errs.Errorf("some load test sessions failed: %s", ", ")
+}
+
+// Stop disconnects all established sessions.
+func (g *Generator) Stop() error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	errs := utils.ErrorCollection{}
+	for _, manager := range g.managers {
+		if err := manager.Disconnect(); err != nil && err != connection.ErrNoConnection {
+			errs.Add(err)
+		}
+	}
+	g.managers = nil
+	return errs.Errorf("some load test sessions did not stop: %s", ", ")
+}
+
+func (g *Generator) waitForProposal() (*market.ServiceProposal, error) {
+	id := market.ProposalID{ProviderID: g.options.ProviderID, ServiceType: noop.ServiceType}
+	deadline := time.Now().Add(g.options.DiscoveryTimeout)
+	for {
+		proposal, err := g.proposals.Proposal(id)
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not get load test proposal")
+		} else if proposal != nil {
+			return proposal, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, ErrProposalNotFound
+		}
+		time.Sleep(g.pollInterval)
+	}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProposalFinder struct {
+	mu       sync.Mutex
+	proposal *market.ServiceProposal
+	lastID   market.ProposalID
+}
+
+func (m *mockProposalFinder) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastID = id
+	return m.proposal, nil
+}
+
+type mockConnectionManager struct {
+	connectErr   error
+	connected    bool
+	disconnected bool
+}
+
+func (m *mockConnectionManager) Connect(_ identity.Identity, _ common.Address, _ market.ServiceProposal, _ connection.ConnectParams) error {
+	if m.connectErr != nil {
+		return m.connectErr
+	}
+	m.connected = true
+	return nil
+}
+
+func (m *mockConnectionManager) Status() connection.Status {
+	return connection.Status{}
+}
+
+func (m *mockConnectionManager) Disconnect() error {
+	if !m.connected {
+		return connection.ErrNoConnection
+	}
+	m.disconnected = true
+	return nil
+}
+
+func (m *mockConnectionManager) CheckChannel(context.Context) error {
+	return nil
+}
+
+func (m *mockConnectionManager) SpeedTest(context.Context, connection.SpeedTestTarget) (connection.SpeedTestResult, error) {
+	return connection.SpeedTestResult{}, nil
+}
+
+func (m *mockConnectionManager) ExportProfile() ([]byte, error) {
+	return nil, nil
+}
+
+func (m *mockConnectionManager) Dial(network, address string) (net.Conn, error) {
+	return nil, nil
+}
+
+func TestGenerator_StartsAndStopsSessions(t *testing.T) {
+	proposals := &mockProposalFinder{proposal: &market.ServiceProposal{ProviderID: "0x1", ServiceType: "noop"}}
+	var managers []*mockConnectionManager
+	generator := NewGenerator(Options{Sessions: 3, ProviderID: "0x1"}, proposals, func(string) connection.Manager {
+		manager := &mockConnectionManager{}
+		managers = append(managers, manager)
+		return manager
+	})
+
+	assert.NoError(t, generator.Start())
+	assert.Equal(t, market.ProposalID{ProviderID: "0x1", ServiceType: "noop"}, proposals.lastID)
+	assert.Len(t, managers, 3)
+	for _, manager := range managers {
+		assert.True(t, manager.connected)
+	}
+
+	assert.NoError(t, generator.Stop())
+	for _, manager := range managers {
+		assert.True(t, manager.disconnected)
+	}
+}
+
+func TestGenerator_ReportsFailedSessions(t *testing.T) {
+	proposals := &mockProposalFinder{proposal: &market.ServiceProposal{ProviderID: "0x1", ServiceType: "noop"}}
+	generator := NewGenerator(Options{Sessions: 2, ProviderID: "0x1"}, proposals, func(string) connection.Manager {
+		return &mockConnectionManager{connectErr: errors.New("boom")}
+	})
+
+	assert.Error(t, generator.Start())
+	assert.NoError(t, generator.Stop())
+}
+
+func TestGenerator_FailsWhenProposalIsNotDiscovered(t *testing.T) {
+	generator := NewGenerator(Options{Sessions: 1, ProviderID: "0x1", DiscoveryTimeout: 10 * time.Millisecond}, &mockProposalFinder{}, nil)
+	generator.pollInterval = time.Millisecond
+
+	assert.Equal(t, ErrProposalNotFound, generator.Start())
+}
@@ -112,3 +112,14 @@ func (c *Cache) HandleNodeEvent(se nodevent.Payload) {
 		log.Debug().Msgf("original location detected: %s (%s)", c.origin.Country, c.origin.NodeType)
 	}
 }
+
+// HandleLocationChangedEvent replaces the cached location with the newly detected one.
+// Origin is replaced too, as location changes are only detected while consumer is not connected.
+func (c *Cache) HandleLocationChangedEvent(e AppEventLocationChanged) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.location = e.Current
+	c.origin = e.Current
+	c.lastFetched = time.Now()
+}
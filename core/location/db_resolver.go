@@ -19,6 +19,8 @@ package location
 
 import (
 	"net"
+	"strings"
+	"sync"
 
 	"github.com/mysteriumnetwork/node/core/ip"
 	"github.com/oschwald/geoip2-golang"
@@ -28,6 +30,7 @@ import (
 
 // DBResolver struct represents ip -> country resolver which uses geoip2 data reader
 type DBResolver struct {
+	mu         sync.RWMutex
 	dbReader   *geoip2.Reader
 	ipResolver ip.Resolver
 }
@@ -45,6 +48,48 @@ func NewExternalDBResolver(databasePath string, ipResolver ip.Resolver) (*DBReso
 	}, nil
 }
 
+// Load replaces the database with the one from the given file, e.g. after it was updated.
+func (r *DBResolver) Load(databasePath string) error {
+	db, err := geoip2.Open(databasePath)
+	if err != nil {
+		return errors.Wrap(err, "failed to open external db")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dbReader != nil {
+		if err := r.dbReader.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close previous location db")
+		}
+	}
+	r.dbReader = db
+	return nil
+}
+
+// Swap closes the database while replace swaps its file and opens the file at path again,
+// as open database file can not be replaced on some platforms, e.g. Windows.
+func (r *DBResolver) Swap(path string, replace func() error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.dbReader != nil {
+		if err := r.dbReader.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close previous location db")
+		}
+	}
+	replaceErr := replace()
+
+	// Previous file is still in place if replace failed.
+	db, err := geoip2.Open(path)
+	if err != nil {
+		r.dbReader = nil
+		return errors.Wrap(err, "failed to open external db")
+	}
+	r.dbReader = db
+	return replaceErr
+}
+
 // DetectLocation detects current IP-address provides location information for the IP.
 func (r *DBResolver) DetectLocation() (loc Location, err error) {
 	log.Debug().Msg("Detecting with DB resolver")
@@ -53,21 +98,63 @@ func (r *DBResolver) DetectLocation() (loc Location, err error) {
 		return Location{}, errors.Wrap(err, "failed to get public IP")
 	}
 
-	ip := net.ParseIP(ipAddress)
+	return r.LocateIP(net.ParseIP(ipAddress))
+}
+
+// LocateIP returns location information of the given IP address.
+func (r *DBResolver) LocateIP(ip net.IP) (loc Location, err error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.dbReader == nil {
+		return Location{}, errors.New("external db is not open")
+	}
+
+	// City databases include country records, so they give more details for the same lookup.
+	if strings.Contains(r.dbReader.Metadata().DatabaseType, "City") {
+		loc, err = r.lookupCity(ip)
+	} else {
+		loc, err = r.lookupCountry(ip)
+	}
+	if err != nil {
+		return Location{}, err
+	}
+
+	loc.IP = ip.String()
+	return loc, nil
+}
+
+func (r *DBResolver) lookupCountry(ip net.IP) (loc Location, err error) {
 	countryRecord, err := r.dbReader.Country(ip)
 	if err != nil {
 		return loc, errors.Wrap(err, "failed to get a country")
 	}
 
-	country := countryRecord.Country.IsoCode
-	if country == "" {
-		country = countryRecord.RegisteredCountry.IsoCode
-		if country == "" {
+	loc.Continent = countryRecord.Continent.Code
+	loc.Country = countryRecord.Country.IsoCode
+	if loc.Country == "" {
+		loc.Country = countryRecord.RegisteredCountry.IsoCode
+		if loc.Country == "" {
 			return loc, errors.New("failed to resolve country")
 		}
 	}
+	return loc, nil
+}
 
-	loc.IP = ip.String()
-	loc.Country = country
+func (r *DBResolver) lookupCity(ip net.IP) (loc Location, err error) {
+	cityRecord, err := r.dbReader.City(ip)
+	if err != nil {
+		return loc, errors.Wrap(err, "failed to get a city")
+	}
+
+	loc.Continent = cityRecord.Continent.Code
+	loc.City = cityRecord.City.Names["en"]
+	loc.Country = cityRecord.Country.IsoCode
+	if loc.Country == "" {
+		loc.Country = cityRecord.RegisteredCountry.IsoCode
+		if loc.Country == "" {
+			return loc, errors.New("failed to resolve country")
+		}
+	}
 	return loc, nil
 }
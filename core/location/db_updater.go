@@ -0,0 +1,191 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrDBNotModified is returned when the remote database did not change since the last update.
+var ErrDBNotModified = errors.New("location db not modified")
+
+type httpDoer interface {
+	Do(req *http.Request) (*http.Response, error)
+}
+
+// dbSwapper keeps the database open, it lets the file to be replaced while the database is closed,
+// as open database file can not be replaced on some platforms, e.g. Windows.
+type dbSwapper interface {
+	Swap(path string, replace func() error) error
+}
+
+// DBUpdater periodically downloads a fresh copy of MaxMind-format location database.
+type DBUpdater struct {
+	client   httpDoer
+	url      string
+	path     string
+	interval time.Duration
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewDBUpdater returns updater which keeps database file at path in sync with the one served at url.
+func NewDBUpdater(client httpDoer, url, path string, interval time.Duration) *DBUpdater {
+	return &DBUpdater{
+		client:   client,
+		url:      url,
+		path:     path,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+}
+
+// Update downloads the database and replaces the local file with it, the file must not be open.
+// The local file is only replaced once the downloaded database is verified to be readable.
+func (u *DBUpdater) Update() error {
+	return u.update(func(_ string, replace func() error) error {
+		return replace()
+	})
+}
+
+func (u *DBUpdater) update(swap func(path string, replace func() error) error) error {
+	req, err := http.NewRequest(http.MethodGet, u.url, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to create location db request")
+	}
+	if info, err := os.Stat(u.path); err == nil {
+		req.Header.Set("If-Modified-Since", info.ModTime().UTC().Format(http.TimeFormat))
+	}
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to download location db")
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		// Local file keeps the time of the last check, so that checks are scheduled from it after restart.
+		now := time.Now()
+		if err := os.Chtimes(u.path, now, now); err != nil {
+			log.Warn().Err(err).Msg("Failed to touch location db")
+		}
+		return ErrDBNotModified
+	default:
+		return errors.Errorf("failed to download location db: unexpected status %d", resp.StatusCode)
+	}
+
+	var body io.Reader = resp.Body
+	if strings.HasSuffix(req.URL.Path, ".gz") {
+		gz, err := gzip.NewReader(resp.Body)
+		if err != nil {
+			return errors.Wrap(err, "failed to decompress location db")
+		}
+		defer gz.Close()
+		body = gz
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(u.path), filepath.Base(u.path)+".*.tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create temporary location db file")
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, body)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to write location db")
+	}
+
+	db, err := geoip2.Open(tmp.Name())
+	if err != nil {
+		return errors.Wrap(err, "downloaded location db is invalid")
+	}
+	if err := db.Close(); err != nil {
+		return errors.Wrap(err, "failed to close downloaded location db")
+	}
+
+	err = swap(u.path, func() error {
+		return os.Rename(tmp.Name(), u.path)
+	})
+	return errors.Wrap(err, "failed to replace location db")
+}
+
+// Start periodically updates the database in the background, the file is replaced via `db` keeping it open.
+// The first update is due an interval after the file was last updated or checked, so that the database
+// which got outdated while node was not running is updated right away.
+func (u *DBUpdater) Start(db dbSwapper) {
+	go func() {
+		timer := time.NewTimer(u.firstUpdateIn())
+		defer timer.Stop()
+
+		for {
+			select {
+			case <-u.stop:
+				return
+			case <-timer.C:
+			}
+			timer.Reset(u.interval)
+
+			err := u.update(db.Swap)
+			if err == ErrDBNotModified {
+				log.Debug().Msg("Location db is up to date")
+				continue
+			}
+			if err != nil {
+				log.Warn().Err(err).Msg("Failed to update location db")
+				continue
+			}
+			log.Info().Msgf("Location db updated from %s", u.url)
+		}
+	}()
+}
+
+func (u *DBUpdater) firstUpdateIn() time.Duration {
+	info, err := os.Stat(u.path)
+	if err != nil {
+		return 0
+	}
+	if due := time.Until(info.ModTime().Add(u.interval)); due > 0 {
+		return due
+	}
+	return 0
+}
+
+// Stop stops periodic updates.
+func (u *DBUpdater) Stop() {
+	u.stopOnce.Do(func() {
+		close(u.stop)
+	})
+}
@@ -0,0 +1,158 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBUpdater_Update(t *testing.T) {
+	db, err := ioutil.ReadFile("db/GeoLite2-Country.mmdb")
+	require.NoError(t, err)
+	var gzipped bytes.Buffer
+	gz := gzip.NewWriter(&gzipped)
+	_, err = gz.Write(db)
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+
+	modified := time.Now().Add(-time.Hour)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/db.mmdb":
+			http.ServeContent(w, r, "db.mmdb", modified, bytes.NewReader(db))
+		case "/db.mmdb.gz":
+			w.Write(gzipped.Bytes())
+		default:
+			w.Write([]byte("not a database"))
+		}
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "location-db")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("downloads database", func(t *testing.T) {
+		path := filepath.Join(dir, "plain.mmdb")
+		updater := NewDBUpdater(http.DefaultClient, server.URL+"/db.mmdb", path, time.Hour)
+
+		assert.NoError(t, updater.Update())
+
+		resolver, err := NewExternalDBResolver(path, ip.NewResolverMock("8.8.8.8"))
+		require.NoError(t, err)
+		loc, err := resolver.DetectLocation()
+		assert.NoError(t, err)
+		assert.Equal(t, "US", loc.Country)
+
+		assert.Equal(t, ErrDBNotModified, updater.Update())
+	})
+
+	t.Run("decompresses gzipped database", func(t *testing.T) {
+		path := filepath.Join(dir, "gzipped.mmdb")
+		updater := NewDBUpdater(http.DefaultClient, server.URL+"/db.mmdb.gz", path, time.Hour)
+
+		assert.NoError(t, updater.Update())
+		_, err := NewExternalDBResolver(path, ip.NewResolverMock("8.8.8.8"))
+		assert.NoError(t, err)
+	})
+
+	t.Run("keeps existing database when download is invalid", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.mmdb")
+		require.NoError(t, ioutil.WriteFile(path, db, 0644))
+		require.NoError(t, os.Chtimes(path, time.Time{}, time.Time{}))
+		updater := NewDBUpdater(http.DefaultClient, server.URL+"/broken", path, time.Hour)
+
+		assert.Error(t, updater.Update())
+		_, err := NewExternalDBResolver(path, ip.NewResolverMock("8.8.8.8"))
+		assert.NoError(t, err)
+
+		files, err := filepath.Glob(filepath.Join(dir, "*.tmp"))
+		assert.NoError(t, err)
+		assert.Empty(t, files)
+	})
+}
+
+func TestDBUpdater_StartUpdatesOutdatedDatabaseRightAway(t *testing.T) {
+	db, err := ioutil.ReadFile("db/GeoLite2-Country.mmdb")
+	require.NoError(t, err)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(db)
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "location-db")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "outdated.mmdb")
+	require.NoError(t, ioutil.WriteFile(path, db, 0644))
+	outdated := time.Now().Add(-2 * time.Hour)
+	require.NoError(t, os.Chtimes(path, outdated, outdated))
+
+	resolver, err := NewExternalDBResolver(path, ip.NewResolverMock("8.8.8.8"))
+	require.NoError(t, err)
+	updater := NewDBUpdater(http.DefaultClient, server.URL, path, time.Hour)
+	updater.Start(resolver)
+	defer updater.Stop()
+
+	assert.Eventually(t, func() bool {
+		info, err := os.Stat(path)
+		return err == nil && info.ModTime().After(outdated.Add(time.Hour))
+	}, 2*time.Second, 10*time.Millisecond)
+	loc, err := resolver.DetectLocation()
+	assert.NoError(t, err)
+	assert.Equal(t, "US", loc.Country)
+}
+
+func TestDBResolver_SwapReopensDatabase(t *testing.T) {
+	resolver, err := NewExternalDBResolver("db/GeoLite2-Country.mmdb", ip.NewResolverMock("95.85.39.36"))
+	require.NoError(t, err)
+
+	err = resolver.Swap("db/GeoLite2-Country.mmdb", func() error {
+		return errors.New("replace failed")
+	})
+	assert.EqualError(t, err, "replace failed")
+
+	loc, err := resolver.DetectLocation()
+	assert.NoError(t, err)
+	assert.Equal(t, "NL", loc.Country)
+}
+
+func TestDBResolver_Load(t *testing.T) {
+	resolver, err := NewExternalDBResolver("db/GeoLite2-Country.mmdb", ip.NewResolverMock("95.85.39.36"))
+	require.NoError(t, err)
+
+	assert.Error(t, resolver.Load("db/missing.mmdb"))
+	assert.NoError(t, resolver.Load("db/GeoLite2-Country.mmdb"))
+
+	loc, err := resolver.DetectLocation()
+	assert.NoError(t, err)
+	assert.Equal(t, "NL", loc.Country)
+}
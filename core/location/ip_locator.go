@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"net"
+
+	"github.com/oschwald/geoip2-golang"
+	"github.com/pkg/errors"
+)
+
+// IPLocator resolves location of any IP address, e.g. of a remote peer.
+// ASN is resolved only if ASN database is given.
+type IPLocator struct {
+	countries *DBResolver
+	asns      *geoip2.Reader
+}
+
+// NewIPLocator returns IPLocator using the given country resolver and the optional MaxMind ASN database.
+func NewIPLocator(countries *DBResolver, asnDatabasePath string) (*IPLocator, error) {
+	locator := &IPLocator{countries: countries}
+	if asnDatabasePath != "" {
+		db, err := geoip2.Open(asnDatabasePath)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open ASN db")
+		}
+		locator.asns = db
+	}
+	return locator, nil
+}
+
+// ResolvesASN tells whether ASN database is available to resolve ASNs of located IPs.
+func (l *IPLocator) ResolvesASN() bool {
+	return l.asns != nil
+}
+
+// LocateIP returns location information of the given IP address.
+func (l *IPLocator) LocateIP(ip net.IP) (Location, error) {
+	loc, err := l.countries.LocateIP(ip)
+	if err != nil {
+		return Location{}, err
+	}
+
+	if l.asns != nil {
+		record, err := l.asns.ASN(ip)
+		if err != nil {
+			return Location{}, errors.Wrap(err, "failed to get an ASN")
+		}
+		loc.ASN = int(record.AutonomousSystemNumber)
+		loc.ISP = record.AutonomousSystemOrganization
+	}
+	return loc, nil
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIPLocator_LocateIP(t *testing.T) {
+	countries, err := NewExternalDBResolver("db/GeoLite2-Country.mmdb", ip.NewResolverMock("127.0.0.1"))
+	assert.NoError(t, err)
+	locator, err := NewIPLocator(countries, "")
+	assert.NoError(t, err)
+
+	loc, err := locator.LocateIP(net.ParseIP("95.85.39.36"))
+	assert.NoError(t, err)
+	assert.Equal(t, "NL", loc.Country)
+	assert.Equal(t, "95.85.39.36", loc.IP)
+	assert.Zero(t, loc.ASN)
+
+	_, err = locator.LocateIP(net.ParseIP("127.0.0.1"))
+	assert.EqualError(t, err, "failed to resolve country")
+}
+
+func TestIPLocator_FailsWithMissingASNDatabase(t *testing.T) {
+	countries, err := NewExternalDBResolver("db/GeoLite2-Country.mmdb", ip.NewResolverMock("127.0.0.1"))
+	assert.NoError(t, err)
+
+	_, err = NewIPLocator(countries, "db/missing-ASN.mmdb")
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/rs/zerolog/log"
+)
+
+type connectionManager interface {
+	Status() connection.Status
+	Disconnect() error
+}
+
+// ConnectionVerifier checks whether established connection exits in the country advertised by the provider.
+type ConnectionVerifier struct {
+	checkers    []Resolver
+	connections connectionManager
+	publisher   eventbus.Publisher
+	strict      bool
+}
+
+// NewConnectionVerifier returns verifier which detects location of the connection using all given checkers.
+// In strict mode connection is terminated once the mismatch is detected.
+func NewConnectionVerifier(checkers []Resolver, connections connectionManager, publisher eventbus.Publisher, strict bool) *ConnectionVerifier {
+	return &ConnectionVerifier{
+		checkers:    checkers,
+		connections: connections,
+		publisher:   publisher,
+		strict:      strict,
+	}
+}
+
+// Subscribe subscribes to connection state changes.
+func (v *ConnectionVerifier) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(connection.AppTopicConnectionState, v.HandleConnectionEvent)
+}
+
+// HandleConnectionEvent verifies location of every established connection.
+func (v *ConnectionVerifier) HandleConnectionEvent(e connection.AppEventConnectionState) {
+	if e.State != connection.Connected {
+		return
+	}
+	v.verify(e.SessionInfo)
+}
+
+func (v *ConnectionVerifier) verify(status connection.Status) {
+	expected := status.Proposal.ServiceDefinition.GetLocation().Country
+	if expected == "" {
+		log.Debug().Msgf("Proposal of session %s has no country, skipping location verification", status.SessionID)
+		return
+	}
+
+	var detected []Location
+	for _, checker := range v.checkers {
+		loc, err := checker.DetectLocation()
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to detect connection location")
+			continue
+		}
+		detected = append(detected, loc)
+	}
+	if len(detected) == 0 {
+		log.Warn().Msgf("Location of session %s could not be verified", status.SessionID)
+		return
+	}
+
+	for _, loc := range detected {
+		if loc.IP != detected[0].IP {
+			log.Warn().Msgf("Location checkers disagree on the IP of session %s: %s, %s", status.SessionID, detected[0].IP, loc.IP)
+		}
+		// A single confirmation is enough, geo databases are known to lag behind each other.
+		if strings.EqualFold(loc.Country, expected) {
+			log.Debug().Msgf("Location of session %s verified: %s", status.SessionID, loc.Country)
+			return
+		}
+	}
+
+	mismatch := connection.AppEventConnectionLocationMismatch{
+		SessionInfo:     status,
+		IP:              detected[0].IP,
+		ExpectedCountry: expected,
+		DetectedCountry: detected[0].Country,
+	}
+	log.Warn().Msgf("Session %s exits in %s (%s) instead of advertised %s", status.SessionID, mismatch.DetectedCountry, mismatch.IP, expected)
+
+	if v.strict && v.connections.Status().SessionID == status.SessionID {
+		if err := v.connections.Disconnect(); err != nil {
+			log.Error().Err(err).Msg("Failed to disconnect after location mismatch")
+		} else {
+			mismatch.Disconnected = true
+		}
+	}
+
+	v.publisher.Publish(connection.AppTopicConnectionLocationMismatch, mismatch)
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+type staticChecker struct {
+	location Location
+	err      error
+}
+
+func (c staticChecker) DetectLocation() (Location, error) {
+	return c.location, c.err
+}
+
+type mockConnectionManager struct {
+	status       connection.Status
+	disconnected bool
+}
+
+func (m *mockConnectionManager) Status() connection.Status {
+	return m.status
+}
+
+func (m *mockConnectionManager) Disconnect() error {
+	m.disconnected = true
+	return nil
+}
+
+type countryServiceDefinition string
+
+func (c countryServiceDefinition) GetLocation() market.Location {
+	return market.Location{Country: string(c)}
+}
+
+func connectedEvent(country string) connection.AppEventConnectionState {
+	return connection.AppEventConnectionState{
+		State: connection.Connected,
+		SessionInfo: connection.Status{
+			SessionID: "session1",
+			Proposal:  market.ServiceProposal{ServiceDefinition: countryServiceDefinition(country)},
+		},
+	}
+}
+
+func TestConnectionVerifier_HandleConnectionEvent(t *testing.T) {
+	tests := []struct {
+		name             string
+		checkers         []Resolver
+		strict           bool
+		wantMismatch     bool
+		wantDisconnected bool
+	}{
+		{
+			name:     "country matches",
+			checkers: []Resolver{staticChecker{location: Location{IP: "1.1.1.1", Country: "LT"}}},
+		},
+		{
+			name: "confirmed by one of checkers",
+			checkers: []Resolver{
+				staticChecker{location: Location{IP: "1.1.1.1", Country: "DE"}},
+				staticChecker{location: Location{IP: "1.1.1.1", Country: "lt"}},
+			},
+		},
+		{
+			name: "checkers failed",
+			checkers: []Resolver{
+				staticChecker{err: errors.New("boom")},
+			},
+		},
+		{
+			name: "country mismatch",
+			checkers: []Resolver{
+				staticChecker{err: errors.New("boom")},
+				staticChecker{location: Location{IP: "1.1.1.1", Country: "DE"}},
+			},
+			wantMismatch: true,
+		},
+		{
+			name:             "country mismatch in strict mode",
+			checkers:         []Resolver{staticChecker{location: Location{IP: "1.1.1.1", Country: "DE"}}},
+			strict:           true,
+			wantMismatch:     true,
+			wantDisconnected: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bus := mocks.NewEventBus()
+			event := connectedEvent("LT")
+			connections := &mockConnectionManager{status: event.SessionInfo}
+			verifier := NewConnectionVerifier(tt.checkers, connections, bus, tt.strict)
+
+			verifier.HandleConnectionEvent(event)
+
+			assert.Equal(t, tt.wantDisconnected, connections.disconnected)
+			if !tt.wantMismatch {
+				assert.Empty(t, bus.GetEventHistory())
+				return
+			}
+			assert.Equal(t, []mocks.EventBusEntry{{
+				Topic: connection.AppTopicConnectionLocationMismatch,
+				Event: connection.AppEventConnectionLocationMismatch{
+					SessionInfo:     event.SessionInfo,
+					IP:              "1.1.1.1",
+					ExpectedCountry: "LT",
+					DetectedCountry: "DE",
+					Disconnected:    tt.wantDisconnected,
+				},
+			}}, bus.GetEventHistory())
+		})
+	}
+}
+
+func TestConnectionVerifier_IgnoresOtherStates(t *testing.T) {
+	bus := mocks.NewEventBus()
+	checker := &mockResolver{}
+	verifier := NewConnectionVerifier([]Resolver{checker}, &mockConnectionManager{}, bus, true)
+
+	event := connectedEvent("LT")
+	event.State = connection.Reconnecting
+	verifier.HandleConnectionEvent(event)
+
+	verifier.HandleConnectionEvent(connectedEvent(""))
+
+	assert.False(t, checker.called)
+	assert.Empty(t, bus.GetEventHistory())
+}
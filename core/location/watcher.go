@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/rs/zerolog/log"
+)
+
+// AppTopicLocationChanged is a topic for publishing changes of the node public IP or location.
+const AppTopicLocationChanged = "location-changed"
+
+// AppEventLocationChanged represents a change of the node public IP or location,
+// e.g. after ISP reassigned the IP or the node failed over to another uplink.
+type AppEventLocationChanged struct {
+	Previous Location
+	Current  Location
+}
+
+// Watcher periodically detects the location of the node and publishes its changes,
+// so that stale location does not stay announced until the node is restarted.
+type Watcher struct {
+	resolver  Resolver
+	publisher eventbus.Publisher
+	interval  time.Duration
+
+	lock      sync.Mutex
+	last      Location
+	connected bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewWatcher returns watcher detecting the location by the given resolver every interval.
+// Resolver should not be cached for longer than the interval, otherwise changes are noticed late.
+func NewWatcher(resolver Resolver, publisher eventbus.Publisher, interval time.Duration) *Watcher {
+	return &Watcher{
+		resolver:  resolver,
+		publisher: publisher,
+		interval:  interval,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Check detects the location and publishes it if it differs from the previously detected one.
+// Location is not checked while consumer connection is established, as it is the location of the provider then.
+func (w *Watcher) Check() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.connected {
+		return
+	}
+
+	loc, err := w.resolver.DetectLocation()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to detect location changes")
+		return
+	}
+
+	previous := w.last
+	w.last = loc
+	if previous == (Location{}) || previous == loc {
+		return
+	}
+
+	log.Info().Msgf("Location changed from %s (%s, %s) to %s (%s, %s)", previous.IP, previous.Country, previous.ISP, loc.IP, loc.Country, loc.ISP)
+	w.publisher.Publish(AppTopicLocationChanged, AppEventLocationChanged{Previous: previous, Current: loc})
+}
+
+// HandleConnectionEvent suspends location checks while consumer connection is established.
+func (w *Watcher) HandleConnectionEvent(e connection.AppEventConnectionState) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	w.connected = e.State != connection.NotConnected
+}
+
+// Start checks the location periodically in the background.
+func (w *Watcher) Start() {
+	go func() {
+		w.Check()
+
+		ticker := time.NewTicker(w.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-w.stop:
+				return
+			case <-ticker.C:
+				w.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops periodic location checks.
+func (w *Watcher) Stop() {
+	w.stopOnce.Do(func() {
+		close(w.stop)
+	})
+}
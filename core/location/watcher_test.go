@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package location
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+type sequenceResolver struct {
+	lock      sync.Mutex
+	locations []Location
+	err       error
+	calls     int
+}
+
+func (r *sequenceResolver) DetectLocation() (Location, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.calls++
+	if r.err != nil {
+		return Location{}, r.err
+	}
+	loc := r.locations[0]
+	if len(r.locations) > 1 {
+		r.locations = r.locations[1:]
+	}
+	return loc, nil
+}
+
+func locationChanges(bus *mocks.EventBus) []AppEventLocationChanged {
+	var changes []AppEventLocationChanged
+	for _, e := range bus.GetEventHistory() {
+		if e.Topic == AppTopicLocationChanged {
+			changes = append(changes, e.Event.(AppEventLocationChanged))
+		}
+	}
+	return changes
+}
+
+func TestWatcher_PublishesLocationChanges(t *testing.T) {
+	home := Location{IP: "1.1.1.1", Country: "LT", ISP: "Telia"}
+	lte := Location{IP: "2.2.2.2", Country: "LT", ISP: "Bite"}
+	resolver := &sequenceResolver{locations: []Location{home, home, lte, lte}}
+	bus := mocks.NewEventBus()
+	watcher := NewWatcher(resolver, bus, 0)
+
+	// when
+	watcher.Check()
+	watcher.Check()
+
+	// then
+	assert.Empty(t, locationChanges(bus))
+
+	// when
+	watcher.Check()
+	watcher.Check()
+
+	// then
+	assert.Equal(t, []AppEventLocationChanged{{Previous: home, Current: lte}}, locationChanges(bus))
+}
+
+func TestWatcher_IgnoresFailedDetection(t *testing.T) {
+	home := Location{IP: "1.1.1.1", Country: "LT"}
+	resolver := &sequenceResolver{locations: []Location{home}}
+	bus := mocks.NewEventBus()
+	watcher := NewWatcher(resolver, bus, 0)
+	watcher.Check()
+
+	// when
+	resolver.err = errors.New("no network")
+	watcher.Check()
+	resolver.err = nil
+	watcher.Check()
+
+	// then
+	assert.Empty(t, locationChanges(bus))
+}
+
+func TestWatcher_SkipsChecksWhileConnected(t *testing.T) {
+	home := Location{IP: "1.1.1.1", Country: "LT"}
+	vpn := Location{IP: "3.3.3.3", Country: "DE"}
+	resolver := &sequenceResolver{locations: []Location{home, vpn}}
+	bus := mocks.NewEventBus()
+	watcher := NewWatcher(resolver, bus, 0)
+	watcher.Check()
+
+	// when
+	watcher.HandleConnectionEvent(connection.AppEventConnectionState{State: connection.Connected})
+	watcher.Check()
+
+	// then
+	assert.Empty(t, locationChanges(bus))
+	assert.Equal(t, 1, resolver.calls)
+
+	// when
+	watcher.HandleConnectionEvent(connection.AppEventConnectionState{State: connection.NotConnected})
+	watcher.Check()
+
+	// then
+	assert.Equal(t, 2, resolver.calls)
+}
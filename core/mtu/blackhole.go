@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mtu
+
+const (
+	// blackholeStep is how much MTU is lowered each time the path is found dropping big packets.
+	blackholeStep = 80
+	// blackholeObservations is how many consecutive observations without received traffic make the path suspicious.
+	blackholeObservations = 3
+	// minSentBytes is the traffic sent during a single observation which has to get an answer.
+	minSentBytes = 4 * 1024
+	// minReceivedBytes is the traffic received during a single observation which counts as an answer,
+	// it is bigger than keepalive packets still passing the blackholed path.
+	minReceivedBytes = 1024
+)
+
+// BlackholeDetector notices paths silently dropping packets bigger than their MTU. It happens when
+// routers "fragmentation needed" messages are filtered on the way: small packets, like keepalives,
+// still pass, while bulk traffic sent through the tunnel gets no answer.
+type BlackholeDetector struct {
+	mtu    int
+	min    int
+	silent int
+
+	sent, received uint64
+}
+
+// NewBlackholeDetector creates detector of the tunnel currently using the given MTU,
+// which is never lowered below the given minimum.
+func NewBlackholeDetector(mtu, min int) *BlackholeDetector {
+	return &BlackholeDetector{mtu: mtu, min: min}
+}
+
+// Observe takes traffic counters of the tunnel and returns lowered MTU when the path looks like dropping big packets.
+func (d *BlackholeDetector) Observe(sent, received uint64) (mtu int, lowered bool) {
+	sentDelta, receivedDelta := sent-d.sent, received-d.received
+	d.sent, d.received = sent, received
+
+	switch {
+	case receivedDelta >= minReceivedBytes:
+		d.silent = 0
+	case sentDelta >= minSentBytes:
+		d.silent++
+	}
+
+	if d.silent < blackholeObservations || d.mtu <= d.min {
+		return d.mtu, false
+	}
+
+	d.silent = 0
+	d.mtu -= blackholeStep
+	if d.mtu < d.min {
+		d.mtu = d.min
+	}
+	return d.mtu, true
+}
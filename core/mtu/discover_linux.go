@@ -0,0 +1,97 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mtu
+
+import (
+	stdErr "errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// probeWait is how long to wait for routers to report the probe being too big.
+	probeWait = 100 * time.Millisecond
+	// maxProbes limits probes, each of them discovers the next smaller link on the path.
+	maxProbes = 5
+)
+
+// Discover probes the path to the remote peer for the largest packet passing it unfragmented.
+// Probes are sent with "don't fragment" bit set, so routers having smaller links on the path
+// report them back and kernel lowers the path MTU until the probe passes.
+func Discover(remote *net.UDPAddr) (int, error) {
+	conn, err := net.DialUDP("udp4", nil, remote)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not open probe socket")
+	}
+	defer conn.Close()
+
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not access probe socket")
+	}
+	if err := setsockopt(raw, syscall.IP_MTU_DISCOVER, syscall.IP_PMTUDISC_DO); err != nil {
+		return 0, errors.Wrap(err, "could not forbid fragmentation of probes")
+	}
+
+	pathMTU, err := getsockopt(raw, syscall.IP_MTU)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not read path MTU")
+	}
+	for i := 0; i < maxProbes; i++ {
+		// Probe bigger than the known path MTU is refused by kernel, it is the size to try next.
+		if _, err := conn.Write(make([]byte, Clamp(pathMTU)-UDPOverhead)); err != nil && !stdErr.Is(err, syscall.EMSGSIZE) {
+			return 0, errors.Wrap(err, "could not send probe")
+		}
+		time.Sleep(probeWait)
+
+		probed, err := getsockopt(raw, syscall.IP_MTU)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not read path MTU")
+		}
+		if probed == pathMTU {
+			break
+		}
+		pathMTU = probed
+	}
+
+	return Clamp(pathMTU), nil
+}
+
+func setsockopt(raw syscall.RawConn, option, value int) error {
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		sockErr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_IP, option, value)
+	}); err != nil {
+		return err
+	}
+	return sockErr
+}
+
+func getsockopt(raw syscall.RawConn, option int) (int, error) {
+	var value int
+	var sockErr error
+	if err := raw.Control(func(fd uintptr) {
+		value, sockErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_IP, option)
+	}); err != nil {
+		return 0, err
+	}
+	return value, sockErr
+}
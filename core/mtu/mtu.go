@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package mtu discovers the largest packets the network path between provider and consumer carries unfragmented
+// and notices paths silently dropping bigger ones, so that tunnels are sized to the path instead of being tuned by hand.
+package mtu
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ErrNotSupported is returned when path MTU can not be probed on this OS.
+var ErrNotSupported = errors.New("path MTU discovery is not supported on this OS")
+
+const (
+	// Min is the smallest MTU every IPv6 capable path has to carry.
+	Min = 1280
+	// Max is the Ethernet MTU, the largest one expected on the Internet paths.
+	Max = 1500
+
+	// UDPOverhead is the size of IPv4 and UDP headers in front of the tunnel datagrams.
+	UDPOverhead = 28
+	// WireguardOverhead is the size of IPv6, UDP and WireGuard headers in front of the tunnelled packets.
+	WireguardOverhead = 80
+)
+
+// Clamp limits the path MTU to the range expected on the Internet paths.
+func Clamp(pathMTU int) int {
+	if pathMTU < Min {
+		return Min
+	}
+	if pathMTU > Max {
+		return Max
+	}
+	return pathMTU
+}
+
+// Wireguard returns MTU of WireGuard tunnel packets fitting the path unfragmented.
+func Wireguard(pathMTU int) int {
+	return Clamp(pathMTU) - WireguardOverhead
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mtu
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWireguard(t *testing.T) {
+	assert.Equal(t, 1420, Wireguard(1500))
+	assert.Equal(t, 1420, Wireguard(9000))
+	assert.Equal(t, 1372, Wireguard(1452))
+	assert.Equal(t, 1200, Wireguard(576))
+}
+
+func TestBlackholeDetector_LowersMTUWhenTrafficGetsNoAnswer(t *testing.T) {
+	detector := NewBlackholeDetector(1420, 1200)
+
+	var sent, received uint64
+	observe := func(sentDelta, receivedDelta uint64) (int, bool) {
+		sent += sentDelta
+		received += receivedDelta
+		return detector.Observe(sent, received)
+	}
+
+	// Traffic answered, path is fine.
+	for i := 0; i < 5; i++ {
+		mtu, lowered := observe(64*1024, 512*1024)
+		assert.False(t, lowered)
+		assert.Equal(t, 1420, mtu)
+	}
+
+	// Only keepalives come back.
+	for i := 0; i < blackholeObservations-1; i++ {
+		_, lowered := observe(16*1024, 32)
+		assert.False(t, lowered)
+	}
+	mtu, lowered := observe(16*1024, 32)
+	assert.True(t, lowered)
+	assert.Equal(t, 1340, mtu)
+
+	// Idle tunnel is not suspicious.
+	for i := 0; i < 5; i++ {
+		_, lowered := observe(32, 32)
+		assert.False(t, lowered)
+	}
+
+	// Never lowered below the minimum.
+	for i := 0; i < 10*blackholeObservations; i++ {
+		mtu, _ = observe(16*1024, 0)
+	}
+	assert.Equal(t, 1200, mtu)
+}
+
+func TestDiscover_Loopback(t *testing.T) {
+	pathMTU, err := Discover(&net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 9})
+	if err == ErrNotSupported {
+		t.Skip(err)
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, Max, pathMTU)
+}
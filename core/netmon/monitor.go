@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netmon
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/rs/zerolog/log"
+)
+
+// AppTopicNetworkChanged is a topic for publishing changes of the local network.
+const AppTopicNetworkChanged = "network-changed"
+
+// AppEventNetworkChanged represents a change of the local network of the node,
+// e.g. after switching from WiFi to LTE or plugging in a cable.
+type AppEventNetworkChanged struct {
+	Previous Addresses
+	Current  Addresses
+}
+
+// Addresses are the unicast addresses of active network interfaces keyed by interface name.
+type Addresses map[string][]string
+
+func (a Addresses) String() string {
+	names := make([]string, 0, len(a))
+	for name := range a {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ifaces := make([]string, 0, len(names))
+	for _, name := range names {
+		ifaces = append(ifaces, fmt.Sprintf("%s %s", name, strings.Join(a[name], ",")))
+	}
+	return "[" + strings.Join(ifaces, "; ") + "]"
+}
+
+// Monitor periodically inspects local network interfaces and publishes their changes,
+// so that established connections can be moved to the new network instead of silently dying.
+type Monitor struct {
+	publisher eventbus.Publisher
+	interval  time.Duration
+	addresses func() (Addresses, error)
+
+	lock sync.Mutex
+	last Addresses
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewMonitor returns monitor inspecting local network interfaces every interval.
+func NewMonitor(publisher eventbus.Publisher, interval time.Duration) *Monitor {
+	return &Monitor{
+		publisher: publisher,
+		interval:  interval,
+		addresses: interfaceAddresses,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Check inspects local network interfaces and publishes them if they differ from the previously inspected ones.
+func (m *Monitor) Check() {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	addresses, err := m.addresses()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to inspect local network interfaces")
+		return
+	}
+
+	previous := m.last
+	m.last = addresses
+	if previous == nil || reflect.DeepEqual(previous, addresses) {
+		return
+	}
+
+	log.Info().Msgf("Local network changed from %s to %s", previous, addresses)
+	m.publisher.Publish(AppTopicNetworkChanged, AppEventNetworkChanged{Previous: previous, Current: addresses})
+}
+
+// Start inspects local network interfaces periodically in the background.
+func (m *Monitor) Start() {
+	go func() {
+		m.Check()
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.Check()
+			}
+		}
+	}()
+}
+
+// Stop stops periodic inspection of local network interfaces.
+func (m *Monitor) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+// interfaceAddresses returns addresses of the interfaces which are up, skipping loopback
+// and point-to-point ones, as VPN tunnels come and go with the connections themselves.
+func interfaceAddresses() (Addresses, error) {
+	ifaces, err := net.Interfaces()
+	if err != nil {
+		return nil, err
+	}
+
+	addresses := make(Addresses)
+	for _, iface := range ifaces {
+		if iface.Flags&net.FlagUp == 0 || iface.Flags&(net.FlagLoopback|net.FlagPointToPoint) != 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			return nil, fmt.Errorf("could not get addresses of %s: %w", iface.Name, err)
+		}
+
+		var ips []string
+		for _, addr := range addrs {
+			ipNet, ok := addr.(*net.IPNet)
+			if !ok || !ipNet.IP.IsGlobalUnicast() {
+				continue
+			}
+			ips = append(ips, ipNet.IP.String())
+		}
+		if len(ips) > 0 {
+			sort.Strings(ips)
+			addresses[iface.Name] = ips
+		}
+	}
+	return addresses, nil
+}
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netmon
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func networkChanges(bus *mocks.EventBus) []AppEventNetworkChanged {
+	var changes []AppEventNetworkChanged
+	for _, e := range bus.GetEventHistory() {
+		if e.Topic == AppTopicNetworkChanged {
+			changes = append(changes, e.Event.(AppEventNetworkChanged))
+		}
+	}
+	return changes
+}
+
+func TestMonitor_PublishesNetworkChanges(t *testing.T) {
+	wifi := Addresses{"wlan0": {"192.168.1.10"}}
+	lte := Addresses{"wwan0": {"10.64.1.2"}}
+	sequence := []Addresses{wifi, wifi, lte, lte}
+
+	bus := mocks.NewEventBus()
+	monitor := NewMonitor(bus, 0)
+	monitor.addresses = func() (Addresses, error) {
+		addresses := sequence[0]
+		sequence = sequence[1:]
+		return addresses, nil
+	}
+
+	// when
+	monitor.Check()
+	monitor.Check()
+
+	// then
+	assert.Empty(t, networkChanges(bus))
+
+	// when
+	monitor.Check()
+	monitor.Check()
+
+	// then
+	assert.Equal(t, []AppEventNetworkChanged{{Previous: wifi, Current: lte}}, networkChanges(bus))
+}
+
+func TestMonitor_IgnoresFailedInspection(t *testing.T) {
+	wifi := Addresses{"wlan0": {"192.168.1.10"}}
+	var err error
+
+	bus := mocks.NewEventBus()
+	monitor := NewMonitor(bus, 0)
+	monitor.addresses = func() (Addresses, error) {
+		return wifi, err
+	}
+	monitor.Check()
+
+	// when
+	err = errors.New("netlink unavailable")
+	monitor.Check()
+	err = nil
+	monitor.Check()
+
+	// then
+	assert.Empty(t, networkChanges(bus))
+}
+
+func TestAddresses_String(t *testing.T) {
+	addresses := Addresses{
+		"wlan0": {"192.168.1.10", "fd00::10"},
+		"eth0":  {"10.0.0.2"},
+	}
+
+	assert.Equal(t, "[eth0 10.0.0.2; wlan0 192.168.1.10,fd00::10]", addresses.String())
+}
@@ -19,7 +19,7 @@ package event
 
 const (
 	// AppTopicNode represents the topic we're gonna be publishing and subscribing on
-	AppTopicNode = "Node"
+	AppTopicNode = "node"
 	// StatusStarted is published once node is started
 	StatusStarted Status = "Started"
 	// StatusStopped is published once node is stopped
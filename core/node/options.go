@@ -22,8 +22,10 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/logconfig"
-	openvpn_core "github.com/mysteriumnetwork/node/services/openvpn/core"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -36,24 +38,10 @@ type Openvpn interface {
 	BinaryPath() string
 }
 
-// TODO this struct will disappear when we unify go-openvpn embedded lib and external process based session creation/handling
-type wrapper struct {
-	nodeOptions openvpn_core.NodeOptions
-}
-
-func (w wrapper) Check() error {
-	return w.nodeOptions.Check()
-}
-
-func (w wrapper) BinaryPath() string {
-	return w.nodeOptions.BinaryPath
-}
-
-var _ Openvpn = wrapper{}
-
 // Options describes options which are required to start Node
 type Options struct {
 	Directories OptionsDirectory
+	Storage     OptionsStorage
 
 	TequilapiAddress string
 	TequilapiPort    int
@@ -61,6 +49,12 @@ type Options struct {
 	BindAddress      string
 	UI               OptionsUI
 	FeedbackURL      string
+	// TequilapiRateLimit limits requests of every tequilapi caller.
+	TequilapiRateLimit OptionsRateLimit
+	// TequilapiAllowedOrigins UI origins allowed by user in "<origin>[=<allowance>]" format.
+	TequilapiAllowedOrigins []string
+	// TequilapiTokensPrecision is the number of decimal places of human readable MYST amounts.
+	TequilapiTokensPrecision int
 
 	Keystore OptionsKeystore
 
@@ -68,6 +62,7 @@ type Options struct {
 	OptionsNetwork
 	Discovery  OptionsDiscovery
 	MMN        OptionsMMN
+	Faucet     OptionsFaucet
 	Quality    OptionsQuality
 	Location   OptionsLocation
 	Transactor OptionsTransactor
@@ -78,9 +73,47 @@ type Options struct {
 
 	Payments OptionsPayments
 
+	LoadTest    OptionsLoadTest
+	EventRecord OptionsEventRecord
+	Obfuscation OptionsObfuscation
+	QoS         OptionsQoS
+	DNSFilter   dns.Filter
+	// Description is public self-description provider attaches to its proposals
+	Description market.ProviderDescription
+	AdBlock     OptionsAdBlock
+	Gateway     OptionsGateway
+	// ProxyAddress is local address of SOCKS5/HTTP proxy routed through the active session, empty if disabled
+	ProxyAddress string
+	Isolation    OptionsIsolation
+	Schedule     OptionsSchedule
+	OnDemand     OptionsOnDemand
+	Management   OptionsManagement
+	Update       OptionsUpdate
+	Shutdown     OptionsShutdown
+	Resources    OptionsResources
+	// StateDebounce overrides debouncing intervals of node state updates, zero values keep the defaults.
+	StateDebounce OptionsStateDebounce
+	// ConsumerIdle and ProviderIdle close forgotten sessions slowly draining consumer balance.
+	ConsumerIdle OptionsIdle
+	ProviderIdle OptionsIdle
+	// Reconciliation compares session traffic counted by consumer and provider.
+	Reconciliation OptionsReconciliation
+	// SpeedTest measures connection speed through the active session.
+	SpeedTest OptionsSpeedTest
+	// Telemetry sends anonymized usage reports if user consented.
+	Telemetry OptionsTelemetry
+	// SessionStarts limits session handshakes provider handles at once.
+	SessionStarts OptionsSessionStarts
+	// Chaos injects faults into payments and P2P communication in nodes built with the chaos tag.
+	Chaos OptionsChaos
+
 	Consumer bool
+	// LowResource trades responsiveness of state updates and quality metrics for lower memory and CPU usage.
+	LowResource bool
 
 	P2PPorts *port.Range
+	// ServicePortRanges holds port ranges per service type.
+	ServicePortRanges map[string]*port.Range
 }
 
 // GetOptions retrieves node options from the app configuration.
@@ -97,25 +130,54 @@ func GetOptions() *Options {
 			UIPort:        config.GetInt(config.FlagUIPort),
 		},
 		FeedbackURL: config.GetString(config.FlagFeedbackURL),
+		TequilapiRateLimit: OptionsRateLimit{
+			ReadRate:   config.GetFloat64(config.FlagTequilapiRateLimitReads),
+			ReadBurst:  config.GetInt(config.FlagTequilapiRateLimitReadsBurst),
+			WriteRate:  config.GetFloat64(config.FlagTequilapiRateLimitWrites),
+			WriteBurst: config.GetInt(config.FlagTequilapiRateLimitWritesBurst),
+		},
+		TequilapiAllowedOrigins:  config.GetStringSlice(config.FlagTequilapiAllowedOrigins),
+		TequilapiTokensPrecision: config.GetInt(config.FlagTequilapiTokensPrecision),
 		Keystore: OptionsKeystore{
 			UseLightweight: config.GetBool(config.FlagKeystoreLightweight),
 		},
+		Storage: OptionsStorage{
+			Backend:    StorageBackend(config.GetString(config.FlagStorageBackend)),
+			Encryption: StorageEncryption(config.GetString(config.FlagStorageEncryption)),
+			Passphrase: config.GetString(config.FlagStoragePassphrase),
+
+			BackupInterval: config.GetDuration(config.FlagStorageBackupInterval),
+			BackupKeep:     config.GetInt(config.FlagStorageBackupKeep),
+		},
 		LogOptions: *GetLogOptions(),
 		OptionsNetwork: OptionsNetwork{
-			Testnet:               config.GetBool(config.FlagTestnet),
-			Localnet:              config.GetBool(config.FlagLocalnet),
-			ExperimentNATPunching: config.GetBool(config.FlagNATPunching),
-			MysteriumAPIAddress:   config.GetString(config.FlagAPIAddress),
-			BrokerAddress:         config.GetString(config.FlagBrokerAddress),
-			EtherClientRPC:        config.GetString(config.FlagEtherRPC),
+			Testnet:                  config.GetBool(config.FlagTestnet),
+			Localnet:                 config.GetBool(config.FlagLocalnet),
+			ChainID:                  config.ChainID(),
+			ExperimentNATPunching:    config.GetBool(config.FlagNATPunching),
+			NATType:                  config.GetString(config.FlagNATType),
+			MysteriumAPIAddress:      config.GetString(config.FlagAPIAddress),
+			BrokerAddress:            config.GetString(config.FlagBrokerAddress),
+			EtherClientRPC:           config.GetString(config.FlagEtherRPC),
+			EtherClientCheckInterval: config.GetDuration(config.FlagEtherRPCCheckInterval),
+			EtherClientCacheTTL:      config.GetDuration(config.FlagEtherRPCCacheTTL),
+			EtherClientLightMode:     config.GetBool(config.FlagEtherClientLightMode),
+			KeepAliveInterval:        config.GetDuration(config.FlagKeepAliveInterval),
+			KeepAliveTimeout:         config.GetDuration(config.FlagKeepAliveTimeout),
+			WatchInterval:            config.GetDuration(config.FlagNetworkWatchInterval),
+			HandshakeTimeout:         config.GetDuration(config.FlagHandshakeTimeout),
 		},
 		Discovery: *GetDiscoveryOptions(),
 		MMN: OptionsMMN{
 			Address: config.GetString(config.FlagMMNAddress),
 		},
+		Faucet: OptionsFaucet{
+			Address: config.GetString(config.FlagFaucetAddress),
+		},
 		Quality: OptionsQuality{
-			Type:    QualityType(config.GetString(config.FlagQualityType)),
-			Address: config.GetString(config.FlagQualityAddress),
+			Type:       QualityType(config.GetString(config.FlagQualityType)),
+			Address:    config.GetString(config.FlagQualityAddress),
+			MinQuality: config.GetFloat64(config.FlagConsumerMinQuality),
 		},
 		Location: OptionsLocation{
 			IPDetectorURL: config.GetString(config.FlagIPDetectorURL),
@@ -124,6 +186,16 @@ func GetOptions() *Options {
 			Country:       config.GetString(config.FlagLocationCountry),
 			City:          config.GetString(config.FlagLocationCity),
 			NodeType:      config.GetString(config.FlagLocationNodeType),
+
+			DBUpdateURL:      config.GetString(config.FlagLocationDBUpdateURL),
+			DBUpdateInterval: config.GetDuration(config.FlagLocationDBUpdateInterval),
+			WatchInterval:    config.GetDuration(config.FlagLocationWatchInterval),
+			ASNAddress:       config.GetString(config.FlagLocationASNAddress),
+
+			Verify:       config.GetBool(config.FlagLocationVerify),
+			VerifyStrict: config.GetBool(config.FlagLocationVerifyStrict),
+
+			VerifyIPDetectorURL: config.GetString(config.FlagLocationVerifyIPDetectorURL),
 		},
 		Transactor: OptionsTransactor{
 			TransactorEndpointAddress:       config.GetString(config.FlagTransactorAddress),
@@ -146,19 +218,144 @@ func GetOptions() *Options {
 			ConsumerDataLeewayMegabytes:        config.GetUInt64(config.FlagPaymentsConsumerDataLeewayMegabytes),
 			ProviderInvoiceFrequency:           config.GetDuration(config.FlagPaymentsProviderInvoiceFrequency),
 			MaxUnpaidInvoiceValue:              config.GetUInt64(config.FlagPaymentsMaxUnpaidInvoiceValue),
+			FreeTier: OptionsFreeTier{
+				Services: config.GetStringSlice(config.FlagPaymentsProviderFreeTierServices),
+				Duration: config.GetDuration(config.FlagPaymentsProviderFreeTierDuration),
+				Bytes:    config.GetUInt64(config.FlagPaymentsProviderFreeTierBytes),
+				Period:   config.GetDuration(config.FlagPaymentsProviderFreeTierPeriod),
+			},
 		},
 		Accountant: OptionsAccountant{
 			AccountantID:              config.GetString(config.FlagAccountantID),
 			AccountantEndpointAddress: config.GetString(config.FlagAccountantAddress),
+			Fallbacks:                 getAccountantFallbacks(),
+			HealthCheckInterval:       config.GetDuration(config.FlagAccountantHealthCheckInterval),
+		},
+		EventRecord: OptionsEventRecord{
+			File:  config.GetString(config.FlagEventRecordFile),
+			Limit: config.GetInt(config.FlagEventRecordLimit),
+		},
+		Obfuscation: OptionsObfuscation{
+			Offer:   config.GetStringSlice(config.FlagObfuscationOffer),
+			Request: config.GetString(config.FlagObfuscationRequest),
+		},
+		QoS: OptionsQoS{
+			Classes: getQoSClasses(),
+			Request: config.GetString(config.FlagQoSRequest),
+		},
+		DNSFilter: getDNSFilter(),
+		Description: market.ProviderDescription{
+			Nickname:   config.GetString(config.FlagProviderNickname),
+			ContactURL: config.GetString(config.FlagProviderContactURL),
+			Tags:       config.GetStringSlice(config.FlagProviderTags),
+		},
+		AdBlock: OptionsAdBlock{
+			Lists:          config.GetStringSlice(config.FlagAdBlockLists),
+			UpdateInterval: config.GetDuration(config.FlagAdBlockUpdateInterval),
+			Address:        config.GetString(config.FlagAdBlockAddress),
+		},
+		Gateway: OptionsGateway{
+			Enabled:   config.GetBool(config.FlagGatewayEnabled),
+			Interface: config.GetString(config.FlagGatewayInterface),
+			Subnet:    config.GetString(config.FlagGatewaySubnet),
 		},
-		Openvpn: wrapper{nodeOptions: openvpn_core.NodeOptions{
-			BinaryPath: config.GetString(config.FlagOpenvpnBinary),
-		}},
+		ProxyAddress: config.GetString(config.FlagProxyAddress),
+		Isolation: OptionsIsolation{
+			MaxConnections: config.GetInt(config.FlagIsolationMaxConnections),
+			ProxyHost:      config.GetString(config.FlagIsolationProxyHost),
+			ProxyPort:      config.GetInt(config.FlagIsolationProxyPort),
+			RoutingTable:   config.GetInt(config.FlagIsolationRoutingTable),
+		},
+		Schedule: OptionsSchedule{
+			Enabled:       config.GetBool(config.FlagScheduleEnabled),
+			ConsumerID:    config.GetString(config.FlagScheduleConsumerID),
+			Rules:         config.GetStringSlice(config.FlagScheduleRules),
+			CheckInterval: config.GetDuration(config.FlagScheduleCheckInterval),
+		},
+		OnDemand: OptionsOnDemand{
+			Enabled:       config.GetBool(config.FlagOnDemandEnabled),
+			ConsumerID:    config.GetString(config.FlagOnDemandConsumerID),
+			Country:       config.GetString(config.FlagOnDemandCountry),
+			ServiceType:   config.GetString(config.FlagOnDemandServiceType),
+			Destinations:  config.GetStringSlice(config.FlagOnDemandDestinations),
+			Interface:     config.GetString(config.FlagOnDemandInterface),
+			IdleTimeout:   config.GetDuration(config.FlagOnDemandIdleTimeout),
+			CheckInterval: config.GetDuration(config.FlagOnDemandCheckInterval),
+		},
+		Management: OptionsManagement{
+			Operator: config.GetString(config.FlagManagementOperator),
+			AuditLog: config.GetString(config.FlagManagementAuditLog),
+		},
+		Update: OptionsUpdate{
+			URL:           config.GetString(config.FlagUpdateURL),
+			Channel:       config.GetString(config.FlagUpdateChannel),
+			Signer:        config.GetString(config.FlagUpdateSigner),
+			Auto:          config.GetBool(config.FlagUpdateAuto),
+			CheckInterval: config.GetDuration(config.FlagUpdateCheckInterval),
+			DrainTimeout:  config.GetDuration(config.FlagUpdateDrainTimeout),
+		},
+		Shutdown: OptionsShutdown{
+			DrainTimeout:   config.GetDuration(config.FlagShutdownDrainTimeout),
+			PaymentTimeout: config.GetDuration(config.FlagShutdownPaymentTimeout),
+		},
+		Resources: OptionsResources{
+			Interval:           config.GetDuration(config.FlagResourcesInterval),
+			MaxGoroutines:      config.GetInt(config.FlagResourcesMaxGoroutines),
+			MaxCPUPercent:      config.GetFloat64(config.FlagResourcesMaxCPU),
+			MaxMemoryBytes:     config.GetUInt64(config.FlagResourcesMaxMemory) * datasize.MiB.Bytes(),
+			MaxFileDescriptors: config.GetInt(config.FlagResourcesMaxFileDescriptors),
+		},
+		StateDebounce: OptionsStateDebounce{
+			Statistics: config.GetDuration(config.FlagStateDebounceStatistics),
+			Sessions:   config.GetDuration(config.FlagStateDebounceSessions),
+			NAT:        config.GetDuration(config.FlagStateDebounceNAT),
+		},
+		ConsumerIdle: OptionsIdle{
+			Timeout:  config.GetDuration(config.FlagConsumerIdleTimeout),
+			MinBytes: config.GetUInt64(config.FlagConsumerIdleMinBytes),
+		},
+		ProviderIdle: OptionsIdle{
+			Timeout:  config.GetDuration(config.FlagProviderIdleTimeout),
+			MinBytes: config.GetUInt64(config.FlagProviderIdleMinBytes),
+		},
+		Reconciliation: OptionsReconciliation{
+			Interval:      config.GetDuration(config.FlagReconciliationInterval),
+			Tolerance:     config.GetFloat64(config.FlagReconciliationTolerance),
+			MinBytes:      config.GetUInt64(config.FlagReconciliationMinBytes),
+			PausePayments: config.GetBool(config.FlagReconciliationPausePayments),
+		},
+		SpeedTest: OptionsSpeedTest{
+			DownloadURL: config.GetString(config.FlagSpeedTestDownloadURL),
+			UploadURL:   config.GetString(config.FlagSpeedTestUploadURL),
+			Size:        config.GetUInt64(config.FlagSpeedTestSize),
+			Serve:       config.GetBool(config.FlagSpeedTestServe),
+		},
+		Telemetry: OptionsTelemetry{
+			Enabled:  config.GetBool(config.FlagTelemetryEnabled),
+			Address:  config.GetString(config.FlagTelemetryAddress),
+			Interval: config.GetDuration(config.FlagTelemetryInterval),
+		},
+		Chaos: OptionsChaos{
+			Scenario: config.GetString(config.FlagChaosScenario),
+		},
+		LoadTest: OptionsLoadTest{
+			Sessions:         config.GetInt(config.FlagLoadTestSessions),
+			ConsumerID:       config.GetString(config.FlagLoadTestConsumer),
+			ProviderID:       config.GetString(config.FlagLoadTestProvider),
+			DiscoveryTimeout: config.GetDuration(config.FlagLoadTestDiscoveryTimeout),
+		},
+		Openvpn: newOpenvpnOptions(config.GetString(config.FlagOpenvpnBinary)),
 		Firewall: OptionsFirewall{
 			BlockAlways: config.GetBool(config.FlagFirewallKillSwitch),
 		},
-		P2PPorts: getP2PListenPorts(),
-		Consumer: config.GetBool(config.FlagConsumer),
+		SessionStarts: OptionsSessionStarts{
+			Limit:        config.GetInt(config.FlagServiceMaxSessionStarts),
+			QueueTimeout: config.GetDuration(config.FlagServiceSessionStartQueueTimeout),
+		},
+		P2PPorts:          getP2PListenPorts(),
+		ServicePortRanges: getServicePortRanges(),
+		Consumer:          config.GetBool(config.FlagConsumer),
+		LowResource:       config.GetBool(config.FlagLowResource),
 	}
 }
 
@@ -214,3 +411,12 @@ func getP2PListenPorts() *port.Range {
 	}
 	return p2pPortRange
 }
+
+func getServicePortRanges() map[string]*port.Range {
+	ranges, err := port.ParseServiceRanges(config.GetString(config.FlagServicePortRanges))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to parse service port ranges, using default value")
+		return map[string]*port.Range{}
+	}
+	return ranges
+}
@@ -17,8 +17,40 @@
 
 package node
 
+import (
+	"strings"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/rs/zerolog/log"
+)
+
 // OptionsAccountant describes possible parameters for interaction with Accountant
 type OptionsAccountant struct {
 	AccountantEndpointAddress string
 	AccountantID              string
+	Fallbacks                 []OptionsAccountantEndpoint
+	HealthCheckInterval       time.Duration
+}
+
+// OptionsAccountantEndpoint describes an accountant used when the main one is unavailable.
+type OptionsAccountantEndpoint struct {
+	AccountantID              string
+	AccountantEndpointAddress string
+}
+
+func getAccountantFallbacks() []OptionsAccountantEndpoint {
+	var fallbacks []OptionsAccountantEndpoint
+	for _, fallback := range config.GetStringSlice(config.FlagAccountantFallbacks) {
+		parts := strings.SplitN(fallback, "@", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			log.Warn().Msgf("Failed to parse fallback accountant %q, skipping it", fallback)
+			continue
+		}
+		fallbacks = append(fallbacks, OptionsAccountantEndpoint{
+			AccountantID:              parts[0],
+			AccountantEndpointAddress: parts[1],
+		})
+	}
+	return fallbacks
 }
@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "time"
+
+// OptionsAdBlock describes ad and tracker blocking of consumer DNS queries
+type OptionsAdBlock struct {
+	// Lists are addresses of blocklists of ad and tracker domains
+	Lists []string
+	// UpdateInterval is how often blocklists are downloaded while blocking is enabled
+	UpdateInterval time.Duration
+	// Address is local IP the blocking DNS proxy listens on
+	Address string
+}
@@ -18,10 +18,12 @@
 package node
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
 
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -45,13 +47,23 @@ func GetOptionsDirectory() *OptionsDirectory {
 	dataDir := config.GetString(config.FlagDataDir)
 	return &OptionsDirectory{
 		Data:     dataDir,
-		Storage:  filepath.Join(dataDir, "db"),
+		Storage:  StorageDir(dataDir, config.ChainID()),
 		Keystore: filepath.Join(dataDir, "keystore"),
 		Script:   config.GetString(config.FlagScriptDir),
 		Runtime:  config.GetString(config.FlagRuntimeDir),
 	}
 }
 
+// StorageDir returns database directory of the given chain. State of the default network
+// stays in the top level directory, other networks are isolated in their own subdirectories.
+func StorageDir(dataDir string, chainID int64) string {
+	storageDir := filepath.Join(dataDir, "db")
+	if chainID == 0 || chainID == metadata.DefaultNetwork.ChainID {
+		return storageDir
+	}
+	return filepath.Join(storageDir, fmt.Sprintf("chain-%d", chainID))
+}
+
 // Check checks that configured dirs exist (which should contain info) and runtime dirs are created (if not exist)
 func (options *OptionsDirectory) Check() error {
 	if err := ensureOrCreateDir(options.Runtime); err != nil {
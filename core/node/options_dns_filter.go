@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/rs/zerolog/log"
+)
+
+func getDNSFilter() dns.Filter {
+	var categories []string
+	for _, category := range config.GetStringSlice(config.FlagDNSFilterCategories) {
+		if !dns.IsCategory(category) {
+			log.Warn().Msgf("Unknown DNS filter category %q, skipping it", category)
+			continue
+		}
+		categories = append(categories, category)
+	}
+	return dns.Filter{
+		Categories: categories,
+		Resolvers:  config.GetStringSlice(config.FlagDNSFilterResolvers),
+	}
+}
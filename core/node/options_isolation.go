@@ -0,0 +1,30 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+// OptionsIsolation describes named connections served by local proxies next to the main one
+type OptionsIsolation struct {
+	// MaxConnections limits simultaneous named connections, zero disables them
+	MaxConnections int
+	// ProxyHost is local address proxies of named connections listen on
+	ProxyHost string
+	// ProxyPort is proxy port of the first named connection
+	ProxyPort int
+	// RoutingTable is routing table and firewall mark of the first named connection
+	RoutingTable int
+}
@@ -17,6 +17,8 @@
 
 package node
 
+import "time"
+
 // LocationType identifies location type
 type LocationType string
 
@@ -40,4 +42,20 @@ type OptionsLocation struct {
 	Country  string
 	City     string
 	NodeType string
+
+	// DBUpdateURL is used to keep MMDB file at Address updated, if set.
+	DBUpdateURL      string
+	DBUpdateInterval time.Duration
+
+	// WatchInterval is how often location is detected again to notice public IP or location changes, zero disables it.
+	WatchInterval time.Duration
+
+	// ASNAddress is a path to ASN database, used to resolve networks of consumers.
+	ASNAddress string
+
+	// Verify enables checking the location of established connections, VerifyStrict disconnects on mismatch.
+	Verify       bool
+	VerifyStrict bool
+	// VerifyIPDetectorURL is a source of public IP for verification, independent from IPDetectorURL.
+	VerifyIPDetectorURL string
 }
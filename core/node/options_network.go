@@ -17,15 +17,37 @@
 
 package node
 
+import "time"
+
 // OptionsNetwork describes possible parameters of network configuration
 type OptionsNetwork struct {
 	Testnet  bool
 	Localnet bool
+	// ChainID selects network definition, zero falls back to Testnet/Localnet selection.
+	ChainID int64
 
 	ExperimentNATPunching bool
+	// NATType is NAT type of provider announced in proposals, empty if it should be detected.
+	NATType string
 
 	MysteriumAPIAddress string
 	BrokerAddress       string
 
+	// EtherClientRPC holds ethereum RPC endpoints separated by comma.
 	EtherClientRPC string
+	// EtherClientCheckInterval is how often latency of ethereum RPC endpoints is measured.
+	EtherClientCheckInterval time.Duration
+	// EtherClientCacheTTL is how long hot blockchain reads are cached.
+	EtherClientCacheTTL time.Duration
+	// EtherClientLightMode reads blockchain state through transactor instead of ethereum RPC.
+	EtherClientLightMode bool
+
+	// KeepAliveInterval and KeepAliveTimeout override p2p keepalive ping interval
+	// and dead peer timeout. Zero values keep the defaults.
+	KeepAliveInterval time.Duration
+	KeepAliveTimeout  time.Duration
+	// WatchInterval is how often local network interfaces are checked for changes, zero disables the checks.
+	WatchInterval time.Duration
+	// HandshakeTimeout overrides the total time allowed for establishing a session. Zero value keeps the default.
+	HandshakeTimeout time.Duration
 }
@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "time"
+
+// OptionsOnDemand describes connections made once the watched traffic appears
+type OptionsOnDemand struct {
+	Enabled bool
+	// ConsumerID is the identity on-demand connections are made with
+	ConsumerID string
+	// Country is the code of a country on-demand connections are made to
+	Country string
+	// ServiceType is the type of service on-demand connections are made to
+	ServiceType string
+	// Destinations are IPv4 addresses or networks outbound traffic to which triggers the connection
+	Destinations []string
+	// Interface is the virtual network interface traffic from which triggers the connection
+	Interface string
+	// IdleTimeout is how long the watched traffic stays idle before the connection is disconnected
+	IdleTimeout time.Duration
+	// CheckInterval is how often the watched traffic is checked
+	CheckInterval time.Duration
+}
@@ -0,0 +1,45 @@
+// +build !mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	openvpn_core "github.com/mysteriumnetwork/node/services/openvpn/core"
+)
+
+// TODO this struct will disappear when we unify go-openvpn embedded lib and external process based session creation/handling
+type wrapper struct {
+	nodeOptions openvpn_core.NodeOptions
+}
+
+func (w wrapper) Check() error {
+	return w.nodeOptions.Check()
+}
+
+func (w wrapper) BinaryPath() string {
+	return w.nodeOptions.BinaryPath
+}
+
+var _ Openvpn = wrapper{}
+
+func newOpenvpnOptions(binaryPath string) Openvpn {
+	return wrapper{nodeOptions: openvpn_core.NodeOptions{
+		BinaryPath: binaryPath,
+	}}
+}
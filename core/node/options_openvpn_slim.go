@@ -0,0 +1,39 @@
+// +build mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "github.com/pkg/errors"
+
+// errOpenvpnNotIncluded indicates that OpenVPN was left out of the slim mobile build.
+var errOpenvpnNotIncluded = errors.New("openvpn is not included in this build")
+
+type noOpenvpn struct{}
+
+func (noOpenvpn) Check() error {
+	return errOpenvpnNotIncluded
+}
+
+func (noOpenvpn) BinaryPath() string {
+	return ""
+}
+
+func newOpenvpnOptions(string) Openvpn {
+	return noOpenvpn{}
+}
@@ -33,4 +33,13 @@ type OptionsPayments struct {
 	ConsumerDataLeewayMegabytes        uint64
 	ProviderInvoiceFrequency           time.Duration
 	MaxUnpaidInvoiceValue              uint64
+	FreeTier                           OptionsFreeTier
+}
+
+// OptionsFreeTier describes services offered for free within a quota
+type OptionsFreeTier struct {
+	Services []string
+	Duration time.Duration
+	Bytes    uint64
+	Period   time.Duration
 }
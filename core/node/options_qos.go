@@ -0,0 +1,45 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import (
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/qos"
+	"github.com/rs/zerolog/log"
+)
+
+// OptionsQoS describes QoS classes of sessions
+type OptionsQoS struct {
+	// Classes lists QoS classes advertised in proposals of provided services, the first one is the default
+	Classes []qos.Class
+	// Request is QoS class requested by consumer from providers offering it
+	Request string
+}
+
+func getQoSClasses() []qos.Class {
+	var classes []qos.Class
+	for _, value := range config.GetStringSlice(config.FlagQoSClasses) {
+		class, err := qos.ParseClass(value)
+		if err != nil {
+			log.Warn().Err(err).Msg("Failed to parse QoS class, skipping it")
+			continue
+		}
+		classes = append(classes, class)
+	}
+	return classes
+}
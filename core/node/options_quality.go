@@ -33,4 +33,6 @@ const (
 type OptionsQuality struct {
 	Type    QualityType
 	Address string
+	// MinQuality is the least quality score of proposals consumer lists and connects to, zero disables filtering.
+	MinQuality float64
 }
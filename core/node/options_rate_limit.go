@@ -0,0 +1,28 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+// OptionsRateLimit describes how many tequilapi requests every caller token and IP is allowed to make
+type OptionsRateLimit struct {
+	// ReadRate and ReadBurst limit cheap read requests, the limit is disabled if ReadRate is 0
+	ReadRate  float64
+	ReadBurst int
+	// WriteRate and WriteBurst limit expensive operations, the limit is disabled if WriteRate is 0
+	WriteRate  float64
+	WriteBurst int
+}
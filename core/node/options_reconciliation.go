@@ -0,0 +1,32 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "time"
+
+// OptionsReconciliation describes how session traffic counted by consumer and provider is compared
+type OptionsReconciliation struct {
+	// Interval is the period of exchanging traffic counters, counters are not reconciled if 0
+	Interval time.Duration
+	// Tolerance is the largest relative difference of traffic counters not considered a divergence
+	Tolerance float64
+	// MinBytes is the difference of traffic counters ignored regardless of Tolerance
+	MinBytes uint64
+	// PausePayments stops paying invoices while traffic counters diverge
+	PausePayments bool
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "time"
+
+// OptionsResources describes sampling of resources used by provided services
+type OptionsResources struct {
+	// Interval is how often resource usage is sampled, sampling is disabled if 0
+	Interval time.Duration
+	// Thresholds of usage reported as a warning, zero disables the threshold
+	MaxGoroutines      int
+	MaxCPUPercent      float64
+	MaxMemoryBytes     uint64
+	MaxFileDescriptors int
+}
@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "time"
+
+// OptionsSchedule describes recurring connections made by the schedule rules
+type OptionsSchedule struct {
+	Enabled bool
+	// ConsumerID is the identity scheduled connections are made with
+	ConsumerID string
+	// Rules are the schedule rules as given by the user
+	Rules []string
+	// CheckInterval is how often the rules are checked
+	CheckInterval time.Duration
+}
@@ -0,0 +1,52 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "time"
+
+// StorageBackend identifies database used to persist node data
+type StorageBackend string
+
+const (
+	// StorageBackendBolt keeps node data in BoltDB
+	StorageBackendBolt = StorageBackend("bolt")
+	// StorageBackendSQLite keeps node data in SQLite
+	StorageBackendSQLite = StorageBackend("sqlite")
+)
+
+// StorageEncryption identifies source of node database encryption key
+type StorageEncryption string
+
+const (
+	// StorageEncryptionNone keeps node database unencrypted
+	StorageEncryptionNone = StorageEncryption("none")
+	// StorageEncryptionPassphrase derives database encryption key from user passphrase
+	StorageEncryptionPassphrase = StorageEncryption("passphrase")
+	// StorageEncryptionKeychain derives database encryption key from passphrase kept in OS keychain
+	StorageEncryptionKeychain = StorageEncryption("keychain")
+)
+
+// OptionsStorage describes possible parameters of node database
+type OptionsStorage struct {
+	Backend    StorageBackend
+	Encryption StorageEncryption
+	Passphrase string
+
+	BackupInterval time.Duration
+	BackupKeep     int
+}
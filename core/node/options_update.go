@@ -0,0 +1,36 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package node
+
+import "time"
+
+// OptionsUpdate describes node self-update
+type OptionsUpdate struct {
+	// URL is address of release channels, self-update is disabled when empty
+	URL string
+	// Channel is release channel to follow
+	Channel string
+	// Signer is identity signing released binaries
+	Signer string
+	// Auto enables installing updates as soon as they are released
+	Auto bool
+	// CheckInterval is how often release channel is checked
+	CheckInterval time.Duration
+	// DrainTimeout limits waiting for provider sessions to finish before restart
+	DrainTimeout time.Duration
+}
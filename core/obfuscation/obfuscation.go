@@ -0,0 +1,125 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfuscation
+
+import (
+	"crypto/rand"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// KeySize is the size of the key shared by the consumer and the provider for the session.
+const KeySize = 32
+
+// ErrUnknownObfuscator is returned when the requested obfuscator is not registered.
+var ErrUnknownObfuscator = errors.New("unknown obfuscator")
+
+// PacketObfuscator encapsulates datagrams of the service data plane,
+// so they can't be recognised by deep packet inspection.
+type PacketObfuscator interface {
+	Obfuscate(packet []byte) ([]byte, error)
+	Deobfuscate(packet []byte) ([]byte, error)
+	// Overhead returns the maximum number of bytes obfuscation adds to a packet,
+	// tunnel MTU has to be lowered by it.
+	Overhead() int
+}
+
+// Factory creates obfuscator for the given session key.
+type Factory func(key []byte) (PacketObfuscator, error)
+
+// Config describes the obfuscation of a session, it is negotiated as a part of session config.
+type Config struct {
+	Name string `json:"name"`
+	Key  []byte `json:"key"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{
+		NameScramble: newScrambler,
+	}
+)
+
+// Register registers obfuscator under the given name, replacing the previous one.
+func Register(name string, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	registry[name] = factory
+}
+
+// Supported returns names of all registered obfuscators.
+func Supported() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Negotiate returns the config of requested obfuscator if it is among the offered ones.
+func Negotiate(requested string, offered []string) (*Config, error) {
+	if requested == "" {
+		return nil, nil
+	}
+	for _, name := range offered {
+		if name == requested {
+			return NewConfig(name)
+		}
+	}
+	return nil, errors.Errorf("obfuscator %q is not offered", requested)
+}
+
+// NewConfig generates a session config for the given obfuscator.
+func NewConfig(name string) (*Config, error) {
+	if _, err := factory(name); err != nil {
+		return nil, err
+	}
+
+	key := make([]byte, KeySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, errors.Wrap(err, "failed to generate obfuscation key")
+	}
+	return &Config{Name: name, Key: key}, nil
+}
+
+// New creates obfuscator described by the session config.
+func New(config Config) (PacketObfuscator, error) {
+	factory, err := factory(config.Name)
+	if err != nil {
+		return nil, err
+	}
+	return factory(config.Key)
+}
+
+func factory(name string) (Factory, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	factory, ok := registry[name]
+	if !ok {
+		return nil, errors.Wrap(ErrUnknownObfuscator, name)
+	}
+	return factory, nil
+}
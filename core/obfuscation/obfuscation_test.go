@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfuscation
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiate(t *testing.T) {
+	config, err := Negotiate("", []string{NameScramble})
+	assert.NoError(t, err)
+	assert.Nil(t, config)
+
+	config, err = Negotiate(NameScramble, []string{NameScramble})
+	assert.NoError(t, err)
+	assert.Equal(t, NameScramble, config.Name)
+	assert.Len(t, config.Key, KeySize)
+
+	_, err = Negotiate(NameScramble, nil)
+	assert.EqualError(t, err, `obfuscator "scramble" is not offered`)
+
+	_, err = Negotiate("tls", []string{"tls"})
+	assert.Equal(t, ErrUnknownObfuscator, errors.Cause(err))
+}
+
+type reverseObfuscator struct{}
+
+func (reverseObfuscator) Obfuscate(packet []byte) ([]byte, error) {
+	return reverse(packet), nil
+}
+
+func (reverseObfuscator) Deobfuscate(packet []byte) ([]byte, error) {
+	return reverse(packet), nil
+}
+
+func (reverseObfuscator) Overhead() int {
+	return 0
+}
+
+func reverse(packet []byte) []byte {
+	out := make([]byte, len(packet))
+	for i := range packet {
+		out[len(packet)-1-i] = packet[i]
+	}
+	return out
+}
+
+func TestRegister(t *testing.T) {
+	Register("reverse", func(key []byte) (PacketObfuscator, error) {
+		return reverseObfuscator{}, nil
+	})
+	defer func() {
+		registryMu.Lock()
+		delete(registry, "reverse")
+		registryMu.Unlock()
+	}()
+
+	assert.Equal(t, []string{"reverse", NameScramble}, Supported())
+
+	obfuscator, err := New(Config{Name: "reverse"})
+	assert.NoError(t, err)
+	packet, err := obfuscator.Obfuscate([]byte("abc"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("cba"), packet)
+}
+
+func TestScrambler(t *testing.T) {
+	config, err := NewConfig(NameScramble)
+	require.NoError(t, err)
+	obfuscator, err := New(*config)
+	require.NoError(t, err)
+
+	packet := bytes.Repeat([]byte{1}, 148)
+	first, err := obfuscator.Obfuscate(packet)
+	assert.NoError(t, err)
+	second, err := obfuscator.Obfuscate(packet)
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+	assert.False(t, bytes.Contains(first, packet[:16]))
+
+	restored, err := obfuscator.Deobfuscate(first)
+	assert.NoError(t, err)
+	assert.Equal(t, packet, restored)
+
+	_, err = obfuscator.Deobfuscate(first[:5])
+	assert.Error(t, err)
+
+	_, err = New(Config{Name: NameScramble, Key: []byte("short")})
+	assert.Error(t, err)
+}
@@ -0,0 +1,209 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfuscation
+
+import (
+	stderrors "errors"
+	"net"
+	"sync"
+	"syscall"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const maxDatagramSize = 64 * 1024
+
+// Proxy relays datagrams between the tunnel and the network, obfuscating everything sent to the network.
+type Proxy struct {
+	obfuscator PacketObfuscator
+	// obfuscated is connected to the peer over the network.
+	obfuscated *net.UDPConn
+	// plain is connected to the local tunnel endpoint at a known target,
+	// otherwise local tunnel sends its traffic to it and the sender becomes the plain peer.
+	plain *net.UDPConn
+
+	plainPeerMu sync.RWMutex
+	plainPeer   *net.UDPAddr
+
+	closeOnce sync.Once
+}
+
+// NewProviderProxy relays obfuscated traffic received over conn to the local tunnel endpoint at target.
+func NewProviderProxy(config Config, conn *net.UDPConn, target *net.UDPAddr) (*Proxy, error) {
+	obfuscator, err := New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := net.DialUDP("udp4", nil, target)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to tunnel endpoint")
+	}
+	return newProxy(obfuscator, conn, plain), nil
+}
+
+// NewProviderPeerProxy relays obfuscated traffic received over conn to the local tunnel endpoint,
+// which has to use PlainAddr() as its peer. Traffic reaches the tunnel only after it sends
+// the first packet to the proxy, so the tunnel should keep the proxy alive from its side.
+func NewProviderPeerProxy(config Config, conn *net.UDPConn) (*Proxy, error) {
+	obfuscator, err := New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := listenPlain()
+	if err != nil {
+		return nil, err
+	}
+	return newProxy(obfuscator, conn, plain), nil
+}
+
+// NewConsumerProxy relays traffic of local tunnel to the provider at remote, sending it from localPort.
+// Tunnel should use PlainAddr() as the provider endpoint.
+func NewConsumerProxy(config Config, localPort int, remote *net.UDPAddr) (*Proxy, error) {
+	obfuscator, err := New(config)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialUDP("udp4", &net.UDPAddr{Port: localPort}, remote)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to provider")
+	}
+	plain, err := listenPlain()
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return newProxy(obfuscator, conn, plain), nil
+}
+
+func listenPlain() (*net.UDPConn, error) {
+	plain, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to listen for tunnel traffic")
+	}
+	return plain, nil
+}
+
+func newProxy(obfuscator PacketObfuscator, obfuscated, plain *net.UDPConn) *Proxy {
+	p := &Proxy{
+		obfuscator: obfuscator,
+		obfuscated: obfuscated,
+		plain:      plain,
+	}
+	go p.relayToNetwork()
+	go p.relayToTunnel()
+	return p
+}
+
+// PlainAddr returns the address of tunnel facing side of the proxy.
+func (p *Proxy) PlainAddr() *net.UDPAddr {
+	return p.plain.LocalAddr().(*net.UDPAddr)
+}
+
+// Overhead returns the number of bytes the proxy may add to a tunnel packet.
+func (p *Proxy) Overhead() int {
+	return p.obfuscator.Overhead()
+}
+
+// Close stops relaying.
+func (p *Proxy) Close() error {
+	var err error
+	p.closeOnce.Do(func() {
+		err = p.obfuscated.Close()
+		if plainErr := p.plain.Close(); err == nil {
+			err = plainErr
+		}
+	})
+	return err
+}
+
+func (p *Proxy) relayToNetwork() {
+	defer p.Close()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, addr, err := p.plain.ReadFromUDP(buf)
+		if isRefused(err) {
+			continue
+		}
+		if err != nil {
+			log.Debug().Err(err).Msg("Obfuscation proxy stopped reading tunnel traffic")
+			return
+		}
+		if p.plain.RemoteAddr() == nil {
+			p.plainPeerMu.Lock()
+			p.plainPeer = addr
+			p.plainPeerMu.Unlock()
+		}
+
+		packet, err := p.obfuscator.Obfuscate(buf[:n])
+		if err != nil {
+			log.Error().Err(err).Msg("Failed to obfuscate packet")
+			continue
+		}
+		if _, err := p.obfuscated.Write(packet); err != nil {
+			log.Debug().Err(err).Msg("Failed to send obfuscated packet")
+		}
+	}
+}
+
+func (p *Proxy) relayToTunnel() {
+	defer p.Close()
+
+	buf := make([]byte, maxDatagramSize)
+	for {
+		n, err := p.obfuscated.Read(buf)
+		if isRefused(err) {
+			continue
+		}
+		if err != nil {
+			log.Debug().Err(err).Msg("Obfuscation proxy stopped reading network traffic")
+			return
+		}
+
+		packet, err := p.obfuscator.Deobfuscate(buf[:n])
+		if err != nil {
+			log.Trace().Err(err).Msg("Dropping packet which failed to deobfuscate")
+			continue
+		}
+
+		if p.plain.RemoteAddr() != nil {
+			_, err = p.plain.Write(packet)
+		} else {
+			p.plainPeerMu.RLock()
+			peer := p.plainPeer
+			p.plainPeerMu.RUnlock()
+			if peer == nil {
+				continue
+			}
+			_, err = p.plain.WriteToUDP(packet, peer)
+		}
+		if err != nil {
+			log.Debug().Err(err).Msg("Failed to pass packet to the tunnel")
+		}
+	}
+}
+
+// isRefused reports errors caused by ICMP messages received on connected sockets,
+// these mean that peer is not listening yet and must not stop the proxy.
+func isRefused(err error) bool {
+	return err != nil && stderrors.Is(err, syscall.ECONNREFUSED)
+}
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfuscation
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxy_RelaysTrafficBetweenTunnels(t *testing.T) {
+	config, err := NewConfig(NameScramble)
+	require.NoError(t, err)
+
+	// Provider's tunnel endpoint echoes everything back.
+	tunnel, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer tunnel.Close()
+	received := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, 1024)
+		n, addr, err := tunnel.ReadFromUDP(buf)
+		if err != nil {
+			return
+		}
+		received <- append([]byte(nil), buf[:n]...)
+		tunnel.WriteToUDP(buf[:n], addr)
+	}()
+
+	// Network in the middle sees only obfuscated datagrams.
+	providerPort, consumerPort := freePort(t), freePort(t)
+	providerConn, err := net.DialUDP("udp4", &net.UDPAddr{Port: providerPort}, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: consumerPort})
+	require.NoError(t, err)
+	provider, err := NewProviderProxy(*config, providerConn, tunnel.LocalAddr().(*net.UDPAddr))
+	require.NoError(t, err)
+	defer provider.Close()
+
+	consumer, err := NewConsumerProxy(*config, consumerPort, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: providerPort})
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	client, err := net.DialUDP("udp4", nil, consumer.PlainAddr())
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write([]byte("handshake"))
+	require.NoError(t, err)
+
+	select {
+	case packet := <-received:
+		assert.Equal(t, []byte("handshake"), packet)
+	case <-time.After(2 * time.Second):
+		t.Fatal("packet was not relayed to provider tunnel")
+	}
+
+	client.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := client.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("handshake"), buf[:n])
+}
+
+func TestProxy_RelaysTrafficToTunnelPeer(t *testing.T) {
+	config, err := NewConfig(NameScramble)
+	require.NoError(t, err)
+
+	providerPort, consumerPort := freePort(t), freePort(t)
+	providerConn, err := net.DialUDP("udp4", &net.UDPAddr{Port: providerPort}, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: consumerPort})
+	require.NoError(t, err)
+	provider, err := NewProviderPeerProxy(*config, providerConn)
+	require.NoError(t, err)
+	defer provider.Close()
+	assert.Equal(t, scrambleNonceSize+1+scrambleMaxPadding, provider.Overhead())
+
+	// Provider's tunnel uses proxy as its peer and makes itself known with a keepalive.
+	tunnel, err := net.DialUDP("udp4", nil, provider.PlainAddr())
+	require.NoError(t, err)
+	defer tunnel.Close()
+	_, err = tunnel.Write([]byte("keepalive"))
+	require.NoError(t, err)
+
+	consumer, err := NewConsumerProxy(*config, consumerPort, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: providerPort})
+	require.NoError(t, err)
+	defer consumer.Close()
+
+	client, err := net.DialUDP("udp4", nil, consumer.PlainAddr())
+	require.NoError(t, err)
+	defer client.Close()
+	_, err = client.Write([]byte("handshake"))
+	require.NoError(t, err)
+
+	tunnel.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 1024)
+	n, err := tunnel.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("handshake"), buf[:n])
+}
+
+func freePort(t *testing.T) int {
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	require.NoError(t, err)
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package obfuscation
+
+import (
+	"crypto/rand"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/chacha20"
+)
+
+// NameScramble is the name of obfuscator which makes every datagram look like random noise of random length.
+const NameScramble = "scramble"
+
+const (
+	scrambleNonceSize  = chacha20.NonceSize
+	scrambleMaxPadding = 32
+)
+
+// scrambler encrypts every datagram with a fresh nonce and appends random padding, hiding
+// both the well known handshake patterns and the packet sizes of the tunnel protocols.
+// It provides no integrity protection, the tunnel protocols already authenticate their traffic.
+type scrambler struct {
+	key []byte
+}
+
+func newScrambler(key []byte) (PacketObfuscator, error) {
+	if len(key) != chacha20.KeySize {
+		return nil, errors.Errorf("invalid scramble key size %d", len(key))
+	}
+	return &scrambler{key: key}, nil
+}
+
+// Obfuscate turns packet into: nonce | encrypted(padding length | packet | padding).
+func (s *scrambler) Obfuscate(packet []byte) ([]byte, error) {
+	var random [scrambleNonceSize + 1]byte
+	if _, err := rand.Read(random[:]); err != nil {
+		return nil, errors.Wrap(err, "failed to generate nonce")
+	}
+	padding := int(random[scrambleNonceSize]) % (scrambleMaxPadding + 1)
+
+	out := make([]byte, scrambleNonceSize+1+len(packet)+padding)
+	copy(out, random[:scrambleNonceSize])
+	out[scrambleNonceSize] = byte(padding)
+	copy(out[scrambleNonceSize+1:], packet)
+
+	cipher, err := chacha20.NewUnauthenticatedCipher(s.key, out[:scrambleNonceSize])
+	if err != nil {
+		return nil, err
+	}
+	cipher.XORKeyStream(out[scrambleNonceSize:], out[scrambleNonceSize:])
+	return out, nil
+}
+
+// Overhead returns the size of nonce, padding length and the largest padding.
+func (s *scrambler) Overhead() int {
+	return scrambleNonceSize + 1 + scrambleMaxPadding
+}
+
+// Deobfuscate restores the original packet.
+func (s *scrambler) Deobfuscate(packet []byte) ([]byte, error) {
+	if len(packet) < scrambleNonceSize+1 {
+		return nil, errors.New("scrambled packet too short")
+	}
+
+	cipher, err := chacha20.NewUnauthenticatedCipher(s.key, packet[:scrambleNonceSize])
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(packet)-scrambleNonceSize)
+	cipher.XORKeyStream(out, packet[scrambleNonceSize:])
+
+	padding := int(out[0])
+	if padding > scrambleMaxPadding || 1+padding > len(out) {
+		return nil, errors.New("invalid scrambled packet padding")
+	}
+	return out[1 : len(out)-padding], nil
+}
@@ -25,7 +25,7 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// Tests port by opening a UDP listener on given port number
+// Tests port by opening UDP and TCP listeners on given port number
 func available(port int) (bool, error) {
 	addr, err := net.ResolveUDPAddr("udp", ":"+strconv.Itoa(port))
 	if err != nil {
@@ -39,5 +39,12 @@ func available(port int) (bool, error) {
 	}
 	defer conn.Close()
 
+	listener, err := net.Listen("tcp", ":"+strconv.Itoa(port))
+	if err != nil {
+		log.Info().Err(err).Msgf("Cannot listen on TCP port %d", port)
+		return false, nil
+	}
+	defer listener.Close()
+
 	return true, nil
 }
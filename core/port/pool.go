@@ -29,46 +29,95 @@ import (
 type Pool struct {
 	start, capacity int
 	rand            *rand.Rand
+	serviceType     string
+	reservations    *Reservations
 }
 
 // ServicePortSupplier provides port needed to run a service on
 type ServicePortSupplier interface {
 	Acquire() (Port, error)
 	AcquireMultiple(n int) (ports []Port, err error)
+	Release(port Port) error
 }
 
-// NewPool creates a port pool that will provide ports from range 40000-50000
-func NewPool() *Pool {
+// NewPool creates a port pool that will provide ports from range 40000-50000.
+// Pools sharing the reservations do not hand out the same port.
+func NewPool(reservations *Reservations) *Pool {
 	return &Pool{
-		start:    40000,
-		capacity: 10000,
-		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		start:        40000,
+		capacity:     10000,
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		reservations: reservations,
 	}
 }
 
 // NewFixedRangePool creates a fixed size pool from port.Range
-func NewFixedRangePool(r Range) *Pool {
+func NewFixedRangePool(r Range, reservations *Reservations) *Pool {
 	return &Pool{
-		start:    r.Start,
-		capacity: r.Capacity(),
-		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		start:        r.Start,
+		capacity:     r.Capacity(),
+		rand:         rand.New(rand.NewSource(time.Now().UnixNano())),
+		reservations: reservations,
 	}
 }
 
+// NewServicePool creates a pool for the given service type. Acquired ports are reserved for the
+// service type and are handed out to the same service type first, also after node restart.
+// If the range is not specified, ports are provided from range 40000-50000.
+func NewServicePool(serviceType string, r *Range, reservations *Reservations) *Pool {
+	pool := NewPool(reservations)
+	if r != nil && r.IsSpecified() {
+		pool = NewFixedRangePool(*r, reservations)
+	}
+	pool.serviceType = serviceType
+	return pool
+}
+
 // Acquire returns an unused port in pool's range
 func (pool *Pool) Acquire() (port Port, err error) {
+	for _, p := range pool.reservations.ReservedFor(pool.serviceType) {
+		if !pool.inRange(p) {
+			continue
+		}
+		acquired, err := pool.tryAcquire(p)
+		if err != nil {
+			return 0, errors.Wrap(err, "could not acquire port")
+		}
+		if acquired {
+			log.Info().Msgf("Supplying reserved port %d", p)
+			return Port(p), nil
+		}
+	}
+
 	p := pool.randomPort()
-	available, err := available(p)
+	acquired, err := pool.tryAcquire(p)
 	if err != nil {
 		return 0, errors.Wrap(err, "could not acquire port")
 	}
-	if !available {
+	if !acquired {
 		p, err = pool.seekAvailablePort()
 	}
 	log.Info().Err(err).Msgf("Supplying port %d", p)
 	return Port(p), errors.Wrap(err, "could not acquire port")
 }
 
+// tryAcquire checks that the port is not reserved by other pools and is not used by
+// other processes before reserving it.
+func (pool *Pool) tryAcquire(p int) (bool, error) {
+	if !pool.reservations.free(p, pool.serviceType) {
+		return false, nil
+	}
+	available, err := available(p)
+	if err != nil || !available {
+		return false, err
+	}
+	return pool.reservations.reserve(p, pool.serviceType), nil
+}
+
+func (pool *Pool) inRange(p int) bool {
+	return p >= pool.start && p < pool.start+pool.capacity
+}
+
 func (pool *Pool) randomPort() int {
 	return pool.start + pool.rand.Intn(pool.capacity)
 }
@@ -76,14 +125,19 @@ func (pool *Pool) randomPort() int {
 func (pool *Pool) seekAvailablePort() (int, error) {
 	for i := 0; i < pool.capacity; i++ {
 		p := pool.start + i
-		available, err := available(p)
-		if available || err != nil {
+		acquired, err := pool.tryAcquire(p)
+		if acquired || err != nil {
 			return p, err
 		}
 	}
 	return 0, errors.New("port pool is exhausted")
 }
 
+// Release gives the port back, so that it is not reserved for the service type anymore.
+func (pool *Pool) Release(port Port) error {
+	return pool.reservations.Release(port.Num())
+}
+
 // AcquireMultiple returns n unused ports from pool's range.
 func (pool *Pool) AcquireMultiple(n int) (ports []Port, err error) {
 	for i := 0; i < n; i++ {
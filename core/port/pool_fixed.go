@@ -21,7 +21,7 @@ package port
 func NewPoolFixed(port Port) *PoolFixed {
 	return &PoolFixed{
 		port:       port,
-		randomPool: NewPool(),
+		randomPool: NewPool(NewReservations(nil)),
 	}
 }
 
@@ -42,6 +42,11 @@ func (pool *PoolFixed) Acquire() (port Port, err error) {
 	return
 }
 
+// Release does nothing, fixed port is never reserved.
+func (pool *PoolFixed) Release(port Port) error {
+	return nil
+}
+
 // AcquireMultiple returns n unused ports from pool's range.
 func (pool *PoolFixed) AcquireMultiple(n int) (ports []Port, err error) {
 	for i := 0; i < n; i++ {
@@ -26,7 +26,7 @@ import (
 )
 
 func TestAcquiredPortsAreUsable(t *testing.T) {
-	pool := NewPool()
+	pool := NewPool(NewReservations(nil))
 
 	port, _ := pool.Acquire()
 	err := listenUDP(port.Num())
@@ -34,6 +34,66 @@ func TestAcquiredPortsAreUsable(t *testing.T) {
 	assert.NoError(t, err)
 }
 
+func TestPoolSkipsPortsBoundByOtherProcesses(t *testing.T) {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{})
+	assert.NoError(t, err)
+	defer conn.Close()
+	bound := conn.LocalAddr().(*net.UDPAddr).Port
+
+	pool := NewFixedRangePool(Range{Start: bound, End: bound + 2}, NewReservations(nil))
+
+	port, err := pool.Acquire()
+	assert.NoError(t, err)
+	assert.NotEqual(t, bound, port.Num())
+}
+
+func TestServicePoolsDoNotShareReservedPorts(t *testing.T) {
+	reservations := NewReservations(nil)
+	wgPool := NewServicePool("wireguard", &Range{Start: 40000, End: 40010}, reservations)
+	ovpnPool := NewServicePool("openvpn", &Range{Start: 40000, End: 40010}, reservations)
+
+	wgPorts, err := wgPool.AcquireMultiple(5)
+	assert.NoError(t, err)
+	ovpnPorts, err := ovpnPool.AcquireMultiple(5)
+	assert.NoError(t, err)
+
+	for _, p := range ovpnPorts {
+		assert.NotContains(t, wgPorts, p)
+	}
+}
+
+func TestServicePoolPrefersReservedPorts(t *testing.T) {
+	storage := &mockReservationStorage{
+		reservations: []Reservation{{Port: 40005, ServiceType: "wireguard"}},
+	}
+	reservations := NewReservations(storage)
+	pool := NewServicePool("wireguard", &Range{Start: 40000, End: 40010}, reservations)
+
+	port, err := pool.Acquire()
+
+	assert.NoError(t, err)
+	assert.Equal(t, 40005, port.Num())
+	assert.Equal(t, []int{40005}, reservations.ReservedFor("wireguard"))
+	assert.Empty(t, reservations.ReservedFor("openvpn"))
+}
+
+func TestServicePoolReleasesPorts(t *testing.T) {
+	reservations := NewReservations(nil)
+	wgPool := NewServicePool("wireguard", &Range{Start: 40000, End: 40001}, reservations)
+	ovpnPool := NewServicePool("openvpn", &Range{Start: 40000, End: 40001}, reservations)
+
+	port, err := wgPool.Acquire()
+	assert.NoError(t, err)
+	_, err = ovpnPool.Acquire()
+	assert.Error(t, err)
+
+	assert.NoError(t, wgPool.Release(port))
+	assert.Empty(t, reservations.ReservedFor("wireguard"))
+	reused, err := ovpnPool.Acquire()
+	assert.NoError(t, err)
+	assert.Equal(t, port, reused)
+}
+
 func listenUDP(port int) error {
 	udpAddr, err := net.ResolveUDPAddr("udp", ":"+strconv.Itoa(port))
 	if err != nil {
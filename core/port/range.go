@@ -58,6 +58,26 @@ func ParseRange(rangeExpr string) (*Range, error) {
 	return &Range{start, end}, nil
 }
 
+// ParseServiceRanges parses port ranges per service type expression, e.g. "wireguard=52820:53075,openvpn=1194:1294"
+func ParseServiceRanges(expr string) (map[string]*Range, error) {
+	ranges := make(map[string]*Range)
+	if expr == "" {
+		return ranges, nil
+	}
+	for _, serviceExpr := range strings.Split(expr, ",") {
+		parts := strings.Split(strings.TrimSpace(serviceExpr), "=")
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, errors.New("invalid service port range expression: " + serviceExpr)
+		}
+		r, err := ParseRange(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		ranges[parts[0]] = r
+	}
+	return ranges, nil
+}
+
 // IsSpecified returns true if the range is specific, i.e. has start and end bounds
 func (r *Range) IsSpecified() bool {
 	return r.Start != 0 && r.End != 0
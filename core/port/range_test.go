@@ -0,0 +1,43 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package port
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseServiceRanges(t *testing.T) {
+	ranges, err := ParseServiceRanges("wireguard=52820:53075, openvpn=1194:1294")
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]*Range{
+		"wireguard": {Start: 52820, End: 53075},
+		"openvpn":   {Start: 1194, End: 1294},
+	}, ranges)
+
+	ranges, err = ParseServiceRanges("")
+	assert.NoError(t, err)
+	assert.Empty(t, ranges)
+
+	_, err = ParseServiceRanges("wireguard")
+	assert.Error(t, err)
+	_, err = ParseServiceRanges("wireguard=2:1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,164 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package port
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	reservationBucket = "port-reservations"
+	// reservationHold is a time for which handed out port is kept away from other pools,
+	// so that the caller has time to bind it.
+	reservationHold = time.Minute
+)
+
+type reservationStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+// Reservation represents a port reserved for the service type.
+type Reservation struct {
+	Port        int `storm:"id"`
+	ServiceType string
+	Updated     time.Time
+}
+
+// Reservations keeps track of ports handed out by all port pools of the node, so that
+// pools of different services do not race for the same port. Ports acquired by service
+// pools are reserved for the service type and persisted, so that the service gets the
+// same ports back after node restart.
+type Reservations struct {
+	mu       sync.Mutex
+	storage  reservationStorage
+	reserved map[int]Reservation
+	held     map[int]time.Time
+	now      func() time.Time
+}
+
+// NewReservations creates port reservations restoring persisted ones of the node from a provided storage and persisting
+// new ones there. Reservations are kept in memory only if storage is nil.
+func NewReservations(storage reservationStorage) *Reservations {
+	r := &Reservations{
+		reserved: make(map[int]Reservation),
+		held:     make(map[int]time.Time),
+		now:      time.Now,
+	}
+	if storage != nil {
+		r.restore(storage)
+	}
+	return r
+}
+
+func (r *Reservations) restore(storage reservationStorage) {
+	r.storage = storage
+
+	var reservations []Reservation
+	if err := storage.GetAllFrom(reservationBucket, &reservations); err != nil {
+		log.Warn().Err(err).Msg("Could not restore port reservations")
+		return
+	}
+	for _, res := range reservations {
+		r.reserved[res.Port] = res
+	}
+}
+
+// ReservedFor returns ports reserved for the service type.
+func (r *Reservations) ReservedFor(serviceType string) []int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var ports []int
+	if serviceType == "" {
+		return ports
+	}
+	for port, res := range r.reserved {
+		if res.ServiceType == serviceType {
+			ports = append(ports, port)
+		}
+	}
+	sort.Ints(ports)
+	return ports
+}
+
+// Release removes the reservation of a port, e.g. once the service or session using it is stopped.
+func (r *Reservations) Release(port int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.held, port)
+	res, ok := r.reserved[port]
+	if !ok {
+		return nil
+	}
+	delete(r.reserved, port)
+	if r.storage == nil {
+		return nil
+	}
+	return r.storage.Delete(reservationBucket, &res)
+}
+
+// free checks if port is neither held nor reserved for another service type.
+func (r *Reservations) free(port int, serviceType string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.isFree(port, serviceType)
+}
+
+// reserve holds the port and reserves it for the service type if it is still free.
+func (r *Reservations) reserve(port int, serviceType string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.isFree(port, serviceType) {
+		return false
+	}
+
+	r.held[port] = r.now().Add(reservationHold)
+	if serviceType == "" {
+		return true
+	}
+
+	res := Reservation{Port: port, ServiceType: serviceType, Updated: r.now().UTC()}
+	r.reserved[port] = res
+	if r.storage != nil {
+		if err := r.storage.Store(reservationBucket, &res); err != nil {
+			log.Warn().Err(err).Msgf("Could not persist reservation of port %d", port)
+		}
+	}
+	return true
+}
+
+func (r *Reservations) isFree(port int, serviceType string) bool {
+	if until, ok := r.held[port]; ok {
+		if r.now().Before(until) {
+			return false
+		}
+		delete(r.held, port)
+	}
+
+	res, ok := r.reserved[port]
+	return !ok || res.ServiceType == serviceType
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package port
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReservations_HeldPortIsReleasedAfterHold(t *testing.T) {
+	now := time.Now()
+	reservations := NewReservations(nil)
+	reservations.now = func() time.Time { return now }
+
+	assert.True(t, reservations.reserve(40000, ""))
+	assert.False(t, reservations.free(40000, ""))
+	assert.False(t, reservations.reserve(40000, "wireguard"))
+
+	now = now.Add(reservationHold)
+	assert.True(t, reservations.free(40000, ""))
+	assert.True(t, reservations.free(40000, "wireguard"))
+}
+
+func TestReservations_PersistsServiceReservations(t *testing.T) {
+	storage := &mockReservationStorage{}
+	reservations := NewReservations(storage)
+
+	assert.True(t, reservations.reserve(40000, "wireguard"))
+	assert.True(t, reservations.reserve(40001, ""))
+	if assert.Len(t, storage.reservations, 1) {
+		assert.Equal(t, 40000, storage.reservations[0].Port)
+		assert.Equal(t, "wireguard", storage.reservations[0].ServiceType)
+	}
+
+	restored := NewReservations(storage)
+	assert.False(t, restored.free(40000, "openvpn"))
+	assert.True(t, restored.free(40000, "wireguard"))
+
+	assert.NoError(t, restored.Release(40000))
+	assert.Empty(t, storage.reservations)
+	assert.True(t, restored.free(40000, "openvpn"))
+}
+
+type mockReservationStorage struct {
+	reservations []Reservation
+}
+
+func (m *mockReservationStorage) Store(_ string, data interface{}) error {
+	res := *data.(*Reservation)
+	m.reservations = append(m.reservations, res)
+	return nil
+}
+
+func (m *mockReservationStorage) GetAllFrom(_ string, data interface{}) error {
+	reflect.ValueOf(data).Elem().Set(reflect.ValueOf(append([]Reservation{}, m.reservations...)))
+	return nil
+}
+
+func (m *mockReservationStorage) Delete(_ string, data interface{}) error {
+	res := *data.(*Reservation)
+	for i := range m.reservations {
+		if m.reservations[i].Port == res.Port {
+			m.reservations = append(m.reservations[:i], m.reservations[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package qos
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Class is a QoS class of sessions offered by provider.
+type Class struct {
+	Name string
+	// LimitKbps is bandwidth limit of session traffic in each direction, zero means unlimited.
+	LimitKbps int
+}
+
+// ParseClass parses QoS class given as "<name>:<limit kbps>", limit can be omitted for unlimited classes.
+func ParseClass(value string) (Class, error) {
+	parts := strings.SplitN(value, ":", 2)
+	class := Class{Name: strings.TrimSpace(parts[0])}
+	if class.Name == "" {
+		return Class{}, errors.Errorf("QoS class %q has no name", value)
+	}
+	if len(parts) == 2 {
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || limit < 0 {
+			return Class{}, errors.Errorf("QoS class %q has invalid bandwidth limit", value)
+		}
+		class.LimitKbps = limit
+	}
+	return class, nil
+}
+
+// Names returns names of the given classes.
+func Names(classes []Class) []string {
+	names := make([]string, 0, len(classes))
+	for _, class := range classes {
+		names = append(names, class.Name)
+	}
+	return names
+}
+
+// Negotiate returns the requested class if it is offered or the first offered class otherwise,
+// which is the default one. Sessions are not classified if no classes are offered.
+func Negotiate(requested string, offered []Class) Class {
+	if len(offered) == 0 {
+		return Class{}
+	}
+	for _, class := range offered {
+		if class.Name == requested {
+			return class
+		}
+	}
+	return offered[0]
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package qos
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseClass(t *testing.T) {
+	class, err := ParseClass("best-effort:5000")
+	assert.NoError(t, err)
+	assert.Equal(t, Class{Name: "best-effort", LimitKbps: 5000}, class)
+
+	class, err = ParseClass("priority")
+	assert.NoError(t, err)
+	assert.Equal(t, Class{Name: "priority"}, class)
+
+	_, err = ParseClass(":100")
+	assert.Error(t, err)
+
+	_, err = ParseClass("slow:fast")
+	assert.Error(t, err)
+
+	_, err = ParseClass("slow:-1")
+	assert.Error(t, err)
+}
+
+func TestNegotiate(t *testing.T) {
+	offered := []Class{{Name: "best-effort", LimitKbps: 5000}, {Name: "priority"}}
+
+	assert.Equal(t, offered[1], Negotiate("priority", offered))
+	assert.Equal(t, offered[0], Negotiate("", offered))
+	assert.Equal(t, offered[0], Negotiate("unknown", offered))
+	assert.Equal(t, Class{}, Negotiate("priority", nil))
+}
+
+func TestNames(t *testing.T) {
+	assert.Equal(t, []string{"best-effort", "priority"}, Names([]Class{{Name: "best-effort"}, {Name: "priority"}}))
+}
@@ -41,3 +41,12 @@ type ConnectCount struct {
 	Fail    int `json:"fail" example:"50" format:"int64"`
 	Timeout int `json:"timeout" example:"10" format:"int64"`
 }
+
+// SuccessRate returns the ratio of successful connects to all connect attempts, in range [0, 1].
+func (c ConnectCount) SuccessRate() float64 {
+	total := c.Success + c.Fail + c.Timeout
+	if total == 0 {
+		return 0
+	}
+	return float64(c.Success) / float64(total)
+}
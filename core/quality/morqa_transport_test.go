@@ -51,7 +51,7 @@ func TestMORQATransport_SendEvent_HandlesSuccess(t *testing.T) {
 		response.WriteHeader(http.StatusAccepted)
 	}))
 
-	morqa := NewMorqaClient(bindAllAddress, server.URL, signerFactory, 1*time.Second)
+	morqa := NewMorqaClient(bindAllAddress, server.URL, signerFactory, 1*time.Second, DefaultBatchSize)
 	go morqa.Start()
 	defer morqa.Stop()
 
@@ -89,7 +89,7 @@ func TestMORQAT_sendMetrics_HandlesErrorsWithMessages(t *testing.T) {
 		}`))
 	}))
 
-	morqa := NewMorqaClient(bindAllAddress, server.URL, signerFactory, 1*time.Second)
+	morqa := NewMorqaClient(bindAllAddress, server.URL, signerFactory, 1*time.Second, DefaultBatchSize)
 	morqa.addMetric(&metrics.Event{})
 	err := morqa.sendMetrics()
 
@@ -110,7 +110,7 @@ func TestMORQATransport_SendEvent_HandlesValidationErrors(t *testing.T) {
 		}`))
 	}))
 
-	morqa := NewMorqaClient(bindAllAddress, server.URL, signerFactory, 1*time.Second)
+	morqa := NewMorqaClient(bindAllAddress, server.URL, signerFactory, 1*time.Second, DefaultBatchSize)
 	morqa.addMetric(&metrics.Event{})
 	err := morqa.sendMetrics()
 
@@ -134,7 +134,7 @@ func TestMORQA_ProposalsMetrics(t *testing.T) {
 		}] }`))
 	}))
 
-	morqa := NewMorqaClient(bindAllAddress, server.URL, signerFactory, 1*time.Second)
+	morqa := NewMorqaClient(bindAllAddress, server.URL, signerFactory, 1*time.Second, DefaultBatchSize)
 	metrics := morqa.ProposalsMetrics()
 
 	assert.Equal(t,
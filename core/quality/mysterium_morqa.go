@@ -36,8 +36,12 @@ import (
 const (
 	mysteriumMorqaAgentName = "goclient-v0.1"
 
-	maxBatchMetricsToKeep = 100
 	maxBatchMetricsToWait = 30 * time.Second
+
+	// DefaultBatchSize is the number of metrics buffered before they are sent to the Morqa server.
+	DefaultBatchSize = 100
+	// LowResourceBatchSize is the metrics batch size for resource-constrained devices.
+	LowResourceBatchSize = 10
 )
 
 type metric struct {
@@ -56,19 +60,21 @@ type MysteriumMORQA struct {
 	clientMu      sync.Mutex
 	clientFactory func() *http.Client
 
-	batch    metrics.Batch
-	eventsMu sync.Mutex
-	metrics  chan metric
-	stop     chan struct{}
+	batch     metrics.Batch
+	batchSize int
+	eventsMu  sync.Mutex
+	metrics   chan metric
+	stop      chan struct{}
 }
 
 // NewMorqaClient creates Mysterium Morqa client with a real communication
-func NewMorqaClient(srcIP, baseURL string, signer identity.SignerFactory, timeout time.Duration) *MysteriumMORQA {
+func NewMorqaClient(srcIP, baseURL string, signer identity.SignerFactory, timeout time.Duration, batchSize int) *MysteriumMORQA {
 	morqa := &MysteriumMORQA{
-		baseURL: baseURL,
-		signer:  signer,
-		metrics: make(chan metric, maxBatchMetricsToKeep),
-		stop:    make(chan struct{}),
+		baseURL:   baseURL,
+		signer:    signer,
+		batchSize: batchSize,
+		metrics:   make(chan metric, batchSize),
+		stop:      make(chan struct{}),
 		clientFactory: func() *http.Client {
 			return &http.Client{
 				Timeout:   timeout,
@@ -100,7 +106,7 @@ func (m *MysteriumMORQA) Start() {
 			size := len(m.batch.Events)
 			m.eventsMu.Unlock()
 
-			if size < maxBatchMetricsToKeep {
+			if size < m.batchSize {
 				continue
 			}
 		case <-trigger:
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"sort"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+)
+
+type metricsProvider interface {
+	ProposalsMetrics() []ConnectMetric
+}
+
+// ProposalRanker orders proposals by their connection quality.
+type ProposalRanker struct {
+	repository proposal.Repository
+	metrics    metricsProvider
+}
+
+// NewProposalRanker returns new instance of ProposalRanker.
+func NewProposalRanker(repository proposal.Repository, metrics metricsProvider) *ProposalRanker {
+	return &ProposalRanker{
+		repository: repository,
+		metrics:    metrics,
+	}
+}
+
+// RankedProposals returns supported proposals of the given country and service type,
+// the ones with the best connect success rate first.
+func (r *ProposalRanker) RankedProposals(country, serviceType string) ([]market.ServiceProposal, error) {
+	proposals, err := r.repository.Proposals(&proposal.Filter{
+		ServiceType:        serviceType,
+		IncludeCountries:   []string{country},
+		ExcludeUnsupported: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	metrics := make(map[ProposalID]ConnectMetric)
+	for _, m := range r.metrics.ProposalsMetrics() {
+		metrics[m.ProposalID] = m
+	}
+
+	rank := func(p market.ServiceProposal) float64 {
+		m, ok := metrics[ProposalID{ProviderID: p.ProviderID, ServiceType: p.ServiceType}]
+		if !ok || m.MonitoringFailed {
+			return -1
+		}
+		return m.ConnectCount.SuccessRate()
+	}
+	sort.SliceStable(proposals, func(i, j int) bool {
+		return rank(proposals[i]) > rank(proposals[j])
+	})
+
+	return proposals, nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockProposalRepository struct {
+	proposals      []market.ServiceProposal
+	recordedFilter *proposal.Filter
+}
+
+func (m *mockProposalRepository) Proposal(id market.ProposalID) (*market.ServiceProposal, error) {
+	return nil, nil
+}
+
+func (m *mockProposalRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	m.recordedFilter = filter
+	return m.proposals, nil
+}
+
+type mockMetricsProvider struct {
+	metrics []ConnectMetric
+}
+
+func (m *mockMetricsProvider) ProposalsMetrics() []ConnectMetric {
+	return m.metrics
+}
+
+func TestProposalRanker_RankedProposals(t *testing.T) {
+	// given
+	repository := &mockProposalRepository{
+		proposals: []market.ServiceProposal{
+			{ProviderID: "0x1", ServiceType: "wireguard"},
+			{ProviderID: "0x2", ServiceType: "wireguard"},
+			{ProviderID: "0x3", ServiceType: "wireguard"},
+			{ProviderID: "0x4", ServiceType: "wireguard"},
+		},
+	}
+	metrics := &mockMetricsProvider{
+		metrics: []ConnectMetric{
+			{
+				ProposalID:   ProposalID{ProviderID: "0x2", ServiceType: "wireguard"},
+				ConnectCount: ConnectCount{Success: 1, Fail: 1},
+			},
+			{
+				ProposalID:   ProposalID{ProviderID: "0x3", ServiceType: "wireguard"},
+				ConnectCount: ConnectCount{Success: 9, Fail: 1},
+			},
+			{
+				ProposalID:       ProposalID{ProviderID: "0x4", ServiceType: "wireguard"},
+				ConnectCount:     ConnectCount{Success: 10},
+				MonitoringFailed: true,
+			},
+		},
+	}
+	ranker := NewProposalRanker(repository, metrics)
+
+	// when
+	proposals, err := ranker.RankedProposals("LT", "wireguard")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, &proposal.Filter{
+		ServiceType:        "wireguard",
+		IncludeCountries:   []string{"LT"},
+		ExcludeUnsupported: true,
+	}, repository.recordedFilter)
+
+	var providers []string
+	for _, p := range proposals {
+		providers = append(providers, p.ProviderID)
+	}
+	assert.Equal(t, []string{"0x3", "0x2", "0x1", "0x4"}, providers)
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"math"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/rs/zerolog/log"
+)
+
+type scoreProvider interface {
+	Score(providerID, serviceType string) (float64, bool)
+	Known() bool
+}
+
+// ProposalRepository filters out proposals having quality score below the minimum.
+// Proposals of unknown quality are filtered out too, unless quality of all proposals is unknown,
+// e.g. Quality Oracle is unreachable, in which case proposals are not filtered at all.
+// Minimum quality requested by the filter is always applied, as it can not be guaranteed for proposals of unknown quality.
+type ProposalRepository struct {
+	proposal.Repository
+	scores     scoreProvider
+	minQuality float64
+}
+
+// NewProposalRepository returns new instance of ProposalRepository.
+func NewProposalRepository(repository proposal.Repository, scores scoreProvider, minQuality float64) *ProposalRepository {
+	return &ProposalRepository{
+		Repository: repository,
+		scores:     scores,
+		minQuality: minQuality,
+	}
+}
+
+// Proposals returns proposals matching the filter and having sufficient quality.
+func (r *ProposalRepository) Proposals(filter *proposal.Filter) ([]market.ServiceProposal, error) {
+	proposals, err := r.Repository.Proposals(filter)
+	if len(proposals) == 0 {
+		return proposals, err
+	}
+	if filter != nil && filter.QualityMin != nil {
+		return r.filterByQuality(proposals, math.Max(*filter.QualityMin, r.minQuality)), err
+	}
+	if r.minQuality <= 0 {
+		return proposals, err
+	}
+	if !r.scores.Known() {
+		log.Warn().Msg("Proposal quality is unknown, proposals are not filtered by quality")
+		return proposals, err
+	}
+	return r.filterByQuality(proposals, r.minQuality), err
+}
+
+func (r *ProposalRepository) filterByQuality(proposals []market.ServiceProposal, minQuality float64) []market.ServiceProposal {
+	filtered := make([]market.ServiceProposal, 0, len(proposals))
+	for _, p := range proposals {
+		if score, ok := r.scores.Score(p.ProviderID, p.ServiceType); ok && score >= minQuality {
+			filtered = append(filtered, p)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProposalRepository_Proposals(t *testing.T) {
+	// given
+	repository := &mockProposalRepository{
+		proposals: []market.ServiceProposal{
+			{ProviderID: "0x1", ServiceType: "wireguard"},
+			{ProviderID: "0x2", ServiceType: "wireguard"},
+			{ProviderID: "0x3", ServiceType: "wireguard"},
+		},
+	}
+	scores := NewScoreCache(&mockMetricsProvider{
+		metrics: []ConnectMetric{
+			{
+				ProposalID:   ProposalID{ProviderID: "0x1", ServiceType: "wireguard"},
+				ConnectCount: ConnectCount{Success: 1, Fail: 1},
+			},
+			{
+				ProposalID:   ProposalID{ProviderID: "0x2", ServiceType: "wireguard"},
+				ConnectCount: ConnectCount{Success: 9, Fail: 1},
+			},
+		},
+	}, time.Minute)
+
+	// when
+	proposals, err := NewProposalRepository(repository, scores, 0.6).Proposals(nil)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{{ProviderID: "0x2", ServiceType: "wireguard"}}, proposals)
+}
+
+func TestProposalRepository_ProposalsOfUnknownQuality(t *testing.T) {
+	// given
+	repository := &mockProposalRepository{
+		proposals: []market.ServiceProposal{
+			{ProviderID: "0x1", ServiceType: "wireguard"},
+		},
+	}
+	scores := NewScoreCache(&mockMetricsProvider{}, time.Minute)
+
+	// when
+	proposals, err := NewProposalRepository(repository, scores, 0.6).Proposals(nil)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, repository.proposals, proposals)
+}
+
+func TestProposalRepository_ProposalsOfRequestedQuality(t *testing.T) {
+	// given
+	repository := &mockProposalRepository{
+		proposals: []market.ServiceProposal{
+			{ProviderID: "0x1", ServiceType: "wireguard"},
+			{ProviderID: "0x2", ServiceType: "wireguard"},
+			{ProviderID: "0x3", ServiceType: "wireguard"},
+		},
+	}
+	scores := NewScoreCache(&mockMetricsProvider{
+		metrics: []ConnectMetric{
+			{
+				ProposalID:   ProposalID{ProviderID: "0x1", ServiceType: "wireguard"},
+				ConnectCount: ConnectCount{Success: 1, Fail: 1},
+			},
+			{
+				ProposalID:   ProposalID{ProviderID: "0x2", ServiceType: "wireguard"},
+				ConnectCount: ConnectCount{Success: 9, Fail: 1},
+			},
+		},
+	}, time.Minute)
+	qualityMin := 0.5
+
+	// when
+	proposals, err := NewProposalRepository(repository, scores, 0).Proposals(&proposal.Filter{QualityMin: &qualityMin})
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []market.ServiceProposal{
+		{ProviderID: "0x1", ServiceType: "wireguard"},
+		{ProviderID: "0x2", ServiceType: "wireguard"},
+	}, proposals)
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"sync"
+	"time"
+)
+
+// DefaultScoresTTL is a period during which cached proposal quality metrics are served without refetching.
+const DefaultScoresTTL = 5 * time.Minute
+
+// ScoreCache caches proposal quality metrics fetched from the Quality Oracle,
+// so that proposal listing and connecting don't wait for the oracle on every request.
+type ScoreCache struct {
+	metrics metricsProvider
+	ttl     time.Duration
+	now     func() time.Time
+
+	mu        sync.Mutex
+	fetchedAt time.Time
+	cached    []ConnectMetric
+	scores    map[ProposalID]float64
+}
+
+// NewScoreCache returns new instance of ScoreCache.
+func NewScoreCache(metrics metricsProvider, ttl time.Duration) *ScoreCache {
+	return &ScoreCache{
+		metrics: metrics,
+		ttl:     ttl,
+		now:     time.Now,
+	}
+}
+
+// ProposalsMetrics returns cached quality metrics of proposals, refetching them once they expire.
+// Metrics fetched previously are kept when the Quality Oracle is unreachable.
+func (c *ScoreCache) ProposalsMetrics() []ConnectMetric {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.refresh()
+	return c.cached
+}
+
+// Score returns quality score of the proposal in range [0, 1], which is its connect success rate.
+// Proposals failing monitoring score zero. Returns false if quality of the proposal is unknown.
+func (c *ScoreCache) Score(providerID, serviceType string) (float64, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.refresh()
+	score, ok := c.scores[ProposalID{ProviderID: providerID, ServiceType: serviceType}]
+	return score, ok
+}
+
+// Known checks if quality of any proposal is known.
+func (c *ScoreCache) Known() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.refresh()
+	return len(c.scores) > 0
+}
+
+func (c *ScoreCache) refresh() {
+	now := c.now()
+	if !c.fetchedAt.IsZero() && now.Sub(c.fetchedAt) < c.ttl {
+		return
+	}
+	c.fetchedAt = now
+
+	metrics := c.metrics.ProposalsMetrics()
+	if metrics == nil {
+		return
+	}
+
+	scores := make(map[ProposalID]float64, len(metrics))
+	for _, m := range metrics {
+		if m.MonitoringFailed {
+			scores[m.ProposalID] = 0
+		} else {
+			scores[m.ProposalID] = m.ConnectCount.SuccessRate()
+		}
+	}
+	c.cached = metrics
+	c.scores = scores
+}
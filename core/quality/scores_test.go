@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package quality
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingMetricsProvider struct {
+	metrics []ConnectMetric
+	calls   int
+}
+
+func (m *countingMetricsProvider) ProposalsMetrics() []ConnectMetric {
+	m.calls++
+	return m.metrics
+}
+
+func TestScoreCache_Score(t *testing.T) {
+	// given
+	provider := &countingMetricsProvider{
+		metrics: []ConnectMetric{
+			{
+				ProposalID:   ProposalID{ProviderID: "0x1", ServiceType: "wireguard"},
+				ConnectCount: ConnectCount{Success: 3, Fail: 1},
+			},
+			{
+				ProposalID:       ProposalID{ProviderID: "0x2", ServiceType: "wireguard"},
+				ConnectCount:     ConnectCount{Success: 10},
+				MonitoringFailed: true,
+			},
+		},
+	}
+	cache := NewScoreCache(provider, time.Minute)
+
+	// when
+	score1, known1 := cache.Score("0x1", "wireguard")
+	score2, known2 := cache.Score("0x2", "wireguard")
+	_, known3 := cache.Score("0x3", "wireguard")
+
+	// then
+	assert.True(t, known1)
+	assert.Equal(t, 0.75, score1)
+	assert.True(t, known2)
+	assert.Zero(t, score2)
+	assert.False(t, known3)
+	assert.True(t, cache.Known())
+	assert.Equal(t, 1, provider.calls)
+}
+
+func TestScoreCache_RefetchesExpiredMetrics(t *testing.T) {
+	// given
+	now := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+	metrics := []ConnectMetric{{
+		ProposalID:   ProposalID{ProviderID: "0x1", ServiceType: "wireguard"},
+		ConnectCount: ConnectCount{Success: 1},
+	}}
+	provider := &countingMetricsProvider{metrics: metrics}
+	cache := NewScoreCache(provider, time.Minute)
+	cache.now = func() time.Time { return now }
+
+	// when
+	assert.Equal(t, metrics, cache.ProposalsMetrics())
+	now = now.Add(30 * time.Second)
+	assert.Equal(t, metrics, cache.ProposalsMetrics())
+
+	// then
+	assert.Equal(t, 1, provider.calls)
+
+	// when Quality Oracle is unreachable after expiration
+	provider.metrics = nil
+	now = now.Add(time.Minute)
+
+	// then
+	assert.Equal(t, metrics, cache.ProposalsMetrics())
+	assert.Equal(t, 2, provider.calls)
+}
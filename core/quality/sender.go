@@ -39,6 +39,7 @@ const (
 	appName             = "myst"
 	sessionDataName     = "session_data"
 	sessionTokensName   = "session_tokens"
+	sessionSpeedName    = "session_speedtest"
 	sessionEventName    = "session_event"
 	traceEventName      = "trace_event"
 	unlockEventName     = "unlock"
@@ -54,10 +55,11 @@ type Transport interface {
 // NewSender creates metrics sender with appropriate transport
 func NewSender(transport Transport, appVersion string, manager connection.Manager, locationResolver location.OriginResolver) *Sender {
 	return &Sender{
-		Transport:  transport,
-		AppVersion: appVersion,
-		connection: manager,
-		location:   locationResolver,
+		Transport:         transport,
+		AppVersion:        appVersion,
+		SampleSessionData: true,
+		connection:        manager,
+		location:          locationResolver,
 
 		sessionsActive: make(map[string]sessionContext),
 	}
@@ -67,8 +69,10 @@ func NewSender(transport Transport, appVersion string, manager connection.Manage
 type Sender struct {
 	Transport  Transport
 	AppVersion string
-	connection connection.Manager
-	location   location.OriginResolver
+	// SampleSessionData enables sending transferred data of every connection statistics update.
+	SampleSessionData bool
+	connection        connection.Manager
+	location          location.OriginResolver
 
 	sessionsMu     sync.RWMutex
 	sessionsActive map[string]sessionContext
@@ -111,6 +115,14 @@ type sessionTokensContext struct {
 	sessionContext
 }
 
+type sessionSpeedContext struct {
+	Target   string
+	Latency  time.Duration
+	Download uint64
+	Upload   uint64
+	sessionContext
+}
+
 type sessionTraceContext struct {
 	Duration time.Duration
 	Stage    string
@@ -138,7 +150,12 @@ func (sender *Sender) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(sessionEvent.AppTopicSession, sender.sendServiceSessionEvent); err != nil {
 		return err
 	}
-	if err := bus.SubscribeAsync(connection.AppTopicConnectionStatistics, sender.sendSessionData); err != nil {
+	if sender.SampleSessionData {
+		if err := bus.SubscribeAsync(connection.AppTopicConnectionStatistics, sender.sendSessionData); err != nil {
+			return err
+		}
+	}
+	if err := bus.SubscribeAsync(connection.AppTopicConnectionSpeedTest, sender.sendSessionSpeed); err != nil {
 		return err
 	}
 	if err := bus.SubscribeAsync(pingpongEvent.AppTopicInvoicePaid, sender.sendSessionEarning); err != nil {
@@ -167,6 +184,17 @@ func (sender *Sender) sendSessionData(e connection.AppEventConnectionStatistics)
 	})
 }
 
+// sendSessionSpeed sends the measured speed of session connection.
+func (sender *Sender) sendSessionSpeed(e connection.AppEventConnectionSpeedTest) {
+	sender.sendEvent(sessionSpeedName, sessionSpeedContext{
+		Target:         string(e.Result.Target),
+		Latency:        e.Result.Latency,
+		Download:       uint64(e.Result.Download),
+		Upload:         uint64(e.Result.Upload),
+		sessionContext: sender.toSessionContext(e.SessionInfo),
+	})
+}
+
 func (sender *Sender) sendSessionEarning(e pingpongEvent.AppEventInvoicePaid) {
 	session, err := sender.recoverSessionContext(e.SessionID)
 	if err != nil {
@@ -22,6 +22,8 @@ import (
 	"runtime"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -85,3 +87,40 @@ func TestSender_SendNATMappingFailEvent_SendsToTransport(t *testing.T) {
 	assert.Equal(t, "hole_punching", c.Stage)
 	assert.Equal(t, mockGateways, c.Gateways)
 }
+
+type mockSubscriber struct {
+	topics []string
+}
+
+func (s *mockSubscriber) Subscribe(topic string, fn interface{}) error {
+	s.topics = append(s.topics, topic)
+	return nil
+}
+
+func (s *mockSubscriber) SubscribeAsync(topic string, fn interface{}) error {
+	s.topics = append(s.topics, topic)
+	return nil
+}
+
+func (s *mockSubscriber) SubscribeBuffered(topic string, fn interface{}, options eventbus.BufferOptions) error {
+	s.topics = append(s.topics, topic)
+	return nil
+}
+
+func (s *mockSubscriber) Unsubscribe(topic string, fn interface{}) error {
+	return nil
+}
+
+func TestSender_Subscribe_SkipsSessionDataWhenSamplingDisabled(t *testing.T) {
+	sender := NewSender(buildMockEventsTransport(nil), "test version", nil, nil)
+	bus := &mockSubscriber{}
+	assert.NoError(t, sender.Subscribe(bus))
+	assert.Contains(t, bus.topics, connection.AppTopicConnectionStatistics)
+	assert.Contains(t, bus.topics, connection.AppTopicConnectionSpeedTest)
+
+	sender.SampleSessionData = false
+	bus = &mockSubscriber{}
+	assert.NoError(t, sender.Subscribe(bus))
+	assert.NotContains(t, bus.topics, connection.AppTopicConnectionStatistics)
+	assert.Contains(t, bus.topics, connection.AppTopicConnectionState)
+}
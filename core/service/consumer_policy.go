@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"net"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrConsumerNotAllowed indicates that consumer connects from the location restricted by the service consumer policy.
+var ErrConsumerNotAllowed = errors.New("consumer location is not allowed by service policy")
+
+// ErrASNDatabaseRequired indicates that consumer policy restricts ASNs, but there is no ASN database to resolve them.
+var ErrASNDatabaseRequired = errors.New("consumer ASN policy requires ASN database")
+
+// ConsumerLocator resolves location of the consumer IP address.
+type ConsumerLocator interface {
+	LocateIP(ip net.IP) (location.Location, error)
+	ResolvesASN() bool
+}
+
+// ConsumerPolicy restricts countries and autonomous systems consumers of the service may connect from.
+// Empty allow list allows all countries or ASNs which are not denied.
+type ConsumerPolicy struct {
+	AllowedCountries []string
+	DeniedCountries  []string
+	AllowedASNs      []int
+	DeniedASNs       []int
+}
+
+// Empty tells whether the policy does not restrict any consumers.
+func (p ConsumerPolicy) Empty() bool {
+	return len(p.AllowedCountries) == 0 && len(p.DeniedCountries) == 0 &&
+		len(p.AllowedASNs) == 0 && len(p.DeniedASNs) == 0
+}
+
+// RestrictsASNs tells whether the policy allows or denies any ASNs.
+func (p ConsumerPolicy) RestrictsASNs() bool {
+	return len(p.AllowedASNs) > 0 || len(p.DeniedASNs) > 0
+}
+
+// Allows checks whether consumer from the given location may connect.
+// Unknown country or ASN is allowed only if it does not have to be in allow list.
+func (p ConsumerPolicy) Allows(loc location.Location) bool {
+	if len(p.AllowedCountries) > 0 && !containsCountry(p.AllowedCountries, loc.Country) {
+		return false
+	}
+	if containsCountry(p.DeniedCountries, loc.Country) {
+		return false
+	}
+	if len(p.AllowedASNs) > 0 && !containsASN(p.AllowedASNs, loc.ASN) {
+		return false
+	}
+	return !containsASN(p.DeniedASNs, loc.ASN)
+}
+
+func containsCountry(countries []string, country string) bool {
+	for _, c := range countries {
+		if country != "" && strings.EqualFold(c, country) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsASN(asns []int, asn int) bool {
+	for _, a := range asns {
+		if asn != 0 && a == asn {
+			return true
+		}
+	}
+	return false
+}
+
+// validate checks that the policy can be applied with the given locator,
+// ASN rules would otherwise deny or allow everybody silently.
+func (p ConsumerPolicy) validate(locator ConsumerLocator) error {
+	if p.RestrictsASNs() && (locator == nil || !locator.ResolvesASN()) {
+		return ErrASNDatabaseRequired
+	}
+	return nil
+}
+
+// allowsConsumer checks the consumer reaching the instance from the given address against its consumer policy.
+func (i *Instance) allowsConsumer(addr *net.UDPAddr) error {
+	if i.ConsumerPolicy.Empty() {
+		return nil
+	}
+	if i.consumerLocator == nil || addr == nil {
+		return ErrConsumerNotAllowed
+	}
+
+	loc, err := i.consumerLocator.LocateIP(addr.IP)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not resolve location of consumer %s", addr.IP)
+		return ErrConsumerNotAllowed
+	}
+	if !i.ConsumerPolicy.Allows(loc) {
+		log.Info().Msgf("Consumer %s from country %q, ASN %d is not allowed by service %s policy", addr.IP, loc.Country, loc.ASN, i.ID)
+		return ErrConsumerNotAllowed
+	}
+	return nil
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConsumerLocator struct {
+	locations map[string]location.Location
+	asns      bool
+}
+
+func (m *mockConsumerLocator) ResolvesASN() bool {
+	return m.asns
+}
+
+func (m *mockConsumerLocator) LocateIP(ip net.IP) (location.Location, error) {
+	loc, ok := m.locations[ip.String()]
+	if !ok {
+		return location.Location{}, errors.New("failed to resolve country")
+	}
+	return loc, nil
+}
+
+func TestConsumerPolicy_Allows(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ConsumerPolicy
+		loc    location.Location
+		want   bool
+	}{
+		{"empty policy", ConsumerPolicy{}, location.Location{}, true},
+		{"allowed country", ConsumerPolicy{AllowedCountries: []string{"lt", "LV"}}, location.Location{Country: "LT"}, true},
+		{"not allowed country", ConsumerPolicy{AllowedCountries: []string{"LT"}}, location.Location{Country: "US"}, false},
+		{"unknown country with allow list", ConsumerPolicy{AllowedCountries: []string{"LT"}}, location.Location{}, false},
+		{"denied country", ConsumerPolicy{DeniedCountries: []string{"US"}}, location.Location{Country: "US"}, false},
+		{"unknown country with deny list", ConsumerPolicy{DeniedCountries: []string{"US"}}, location.Location{}, true},
+		{"allowed ASN", ConsumerPolicy{AllowedASNs: []int{8764}}, location.Location{ASN: 8764}, true},
+		{"not allowed ASN", ConsumerPolicy{AllowedASNs: []int{8764}}, location.Location{ASN: 15169}, false},
+		{"denied ASN", ConsumerPolicy{DeniedASNs: []int{15169}}, location.Location{Country: "US", ASN: 15169}, false},
+		{"allowed country of denied ASN", ConsumerPolicy{AllowedCountries: []string{"US"}, DeniedASNs: []int{15169}}, location.Location{Country: "US", ASN: 15169}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.policy.Allows(tt.loc))
+		})
+	}
+}
+
+func TestInstance_AllowsConsumer(t *testing.T) {
+	locator := &mockConsumerLocator{
+		locations: map[string]location.Location{
+			"95.85.39.36": {Country: "NL"},
+			"8.8.8.8":     {Country: "US"},
+		},
+	}
+	instance := &Instance{
+		ConsumerPolicy:  ConsumerPolicy{AllowedCountries: []string{"NL"}},
+		consumerLocator: locator,
+	}
+
+	assert.NoError(t, instance.allowsConsumer(&net.UDPAddr{IP: net.ParseIP("95.85.39.36")}))
+	assert.Equal(t, ErrConsumerNotAllowed, instance.allowsConsumer(&net.UDPAddr{IP: net.ParseIP("8.8.8.8")}))
+	assert.Equal(t, ErrConsumerNotAllowed, instance.allowsConsumer(&net.UDPAddr{IP: net.ParseIP("127.0.0.1")}))
+	assert.Equal(t, ErrConsumerNotAllowed, instance.allowsConsumer(nil))
+
+	unrestricted := &Instance{}
+	assert.NoError(t, unrestricted.allowsConsumer(nil))
+}
+
+func TestConsumerPolicy_ValidateRequiresASNDatabase(t *testing.T) {
+	countriesOnly := &mockConsumerLocator{}
+	withASNs := &mockConsumerLocator{asns: true}
+
+	assert.NoError(t, ConsumerPolicy{}.validate(nil))
+	assert.NoError(t, ConsumerPolicy{DeniedCountries: []string{"US"}}.validate(countriesOnly))
+	assert.Equal(t, ErrASNDatabaseRequired, ConsumerPolicy{DeniedASNs: []int{15169}}.validate(countriesOnly))
+	assert.Equal(t, ErrASNDatabaseRequired, ConsumerPolicy{AllowedASNs: []int{8764}}.validate(nil))
+	assert.NoError(t, ConsumerPolicy{AllowedASNs: []int{8764}}.validate(withASNs))
+}
@@ -0,0 +1,178 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrDraining indicates that provider is draining and does not accept new sessions.
+var ErrDraining = errors.New("provider is draining, new sessions are not accepted")
+
+// DrainOptions describes how provider sessions are drained.
+type DrainOptions struct {
+	// Timeout is how long consumers are given to end their sessions after going-away notice, sessions are killed immediately if 0.
+	Timeout time.Duration
+	// PaymentTimeout limits waiting for the final payment of sessions still running at the deadline.
+	PaymentTimeout time.Duration
+	// Reason is passed to consumers along with going-away notice.
+	Reason string
+}
+
+// DefaultDrainOptions returns default options of draining on node shutdown.
+func DefaultDrainOptions() DrainOptions {
+	return DrainOptions{
+		Timeout:        30 * time.Second,
+		PaymentTimeout: 10 * time.Second,
+		Reason:         "provider is shutting down",
+	}
+}
+
+// paymentSettler is implemented by payment engines which can collect payment for the traffic not yet paid for.
+type paymentSettler interface {
+	Settle(timeout time.Duration) error
+}
+
+// Drainer winds provided services down without abruptly killing sessions:
+// it stops accepting new sessions, notifies consumers, waits for sessions to end, collects final payments and stops services.
+type Drainer struct {
+	services *Manager
+	sessions *SessionPool
+	options  DrainOptions
+
+	lock     sync.Mutex
+	draining bool
+	done     chan struct{}
+	err      error
+}
+
+// NewDrainer creates provider session drainer.
+func NewDrainer(services *Manager, sessions *SessionPool, options DrainOptions) *Drainer {
+	return &Drainer{
+		services: services,
+		sessions: sessions,
+		options:  options,
+		done:     make(chan struct{}),
+	}
+}
+
+// Draining tells whether drain was started.
+func (d *Drainer) Draining() bool {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.draining
+}
+
+// Drain drains provider sessions and stops all services afterwards, it blocks until services are stopped.
+// Calling it while drain is in progress waits for the running drain to finish.
+func (d *Drainer) Drain() error {
+	d.lock.Lock()
+	if d.draining {
+		d.lock.Unlock()
+		<-d.done
+		return d.err
+	}
+	d.draining = true
+	d.lock.Unlock()
+
+	d.err = d.drain()
+	close(d.done)
+	return d.err
+}
+
+// StopAccepting stops accepting new sessions on all services, running sessions are left intact.
+func (d *Drainer) StopAccepting() {
+	for _, instance := range d.services.List() {
+		instance.startDraining()
+	}
+}
+
+func (d *Drainer) drain() error {
+	d.StopAccepting()
+
+	sessions := d.sessions.GetAll()
+	if len(sessions) > 0 && d.options.Timeout > 0 {
+		deadline := time.Now().Add(d.options.Timeout)
+		log.Info().Msgf("Draining %d sessions until %s", len(sessions), deadline.Format(time.RFC3339))
+
+		for _, s := range sessions {
+			go notifyGoingAway(s, deadline, d.options.Reason)
+		}
+
+		var wg sync.WaitGroup
+		for _, s := range sessions {
+			wg.Add(1)
+			go func(s *Session) {
+				defer wg.Done()
+				endSession(s, deadline, d.options.PaymentTimeout)
+			}(s)
+		}
+		wg.Wait()
+	}
+
+	return d.services.Kill()
+}
+
+func notifyGoingAway(s *Session, deadline time.Time, reason string) {
+	if s.channel == nil {
+		return
+	}
+
+	msg := &pb.SessionGoingAway{
+		SessionID: string(s.ID),
+		Deadline:  deadline.Unix(),
+		Reason:    reason,
+	}
+	log.Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionGoingAway, msg.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if _, err := s.channel.Send(ctx, p2p.TopicSessionGoingAway, p2p.ProtoMessage(msg)); err != nil {
+		log.Warn().Err(err).Msgf("Could not notify consumer about session %s going away", s.ID)
+	}
+}
+
+// endSession waits for consumer to end the session until the deadline,
+// after that collects final payment and closes the session.
+func endSession(s *Session, deadline time.Time, paymentTimeout time.Duration) {
+	select {
+	case <-s.Done():
+		return
+	case <-time.After(time.Until(deadline)):
+	}
+
+	if settler, ok := s.paymentEngine().(paymentSettler); ok {
+		if err := settler.Settle(paymentTimeout); err != nil {
+			log.Warn().Err(err).Msgf("Could not collect final payment for session %s", s.ID)
+		}
+	}
+
+	select {
+	case <-s.Done():
+	default:
+		log.Info().Msgf("Drain deadline reached, closing session %s", s.ID)
+		s.Close()
+	}
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/pb"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSettlingEngine struct {
+	mockBalanceTracker
+	lock    sync.Mutex
+	settled bool
+}
+
+func (m *mockSettlingEngine) Settle(time.Duration) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.settled = true
+	return nil
+}
+
+func (m *mockSettlingEngine) wasSettled() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.settled
+}
+
+func TestDrainer_Drain(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return &serviceFake{mockProcess: make(chan struct{})}, proposalMock, nil
+	})
+
+	discovery := mockDiscovery{}
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&discovery),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil, nil,
+	)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{})
+	assert.NoError(t, err)
+	instance := manager.Service(id)
+
+	sessions := NewSessionPool(mocks.NewEventBus())
+
+	// consumer ends this session after going-away notice
+	leaving, err := NewSession(instance, &pb.SessionRequest{})
+	assert.NoError(t, err)
+	leavingChannel := &mockP2PChannel{}
+	leaving.channel = leavingChannel
+	leavingEngine := &mockSettlingEngine{}
+	leaving.setPaymentEngine(leavingEngine)
+	sessions.Add(leaving)
+
+	// consumer keeps this session running until the deadline
+	staying, err := NewSession(instance, &pb.SessionRequest{})
+	assert.NoError(t, err)
+	stayingChannel := &mockP2PChannel{}
+	staying.channel = stayingChannel
+	stayingEngine := &mockSettlingEngine{}
+	staying.setPaymentEngine(stayingEngine)
+	sessions.Add(staying)
+
+	drainer := NewDrainer(manager, sessions, DrainOptions{Timeout: 200 * time.Millisecond, PaymentTimeout: time.Second, Reason: "test"})
+	go func() {
+		assert.Eventually(t, func() bool { return len(leavingChannel.sentTopics()) == 1 }, time.Second, 5*time.Millisecond)
+		leaving.Close()
+	}()
+
+	err = drainer.Drain()
+	assert.NoError(t, err)
+
+	assert.True(t, drainer.Draining())
+	assert.True(t, instance.Draining())
+	assert.Equal(t, []string{p2p.TopicSessionGoingAway}, leavingChannel.sentTopics())
+	assert.Equal(t, []string{p2p.TopicSessionGoingAway}, stayingChannel.sentTopics())
+	assert.False(t, leavingEngine.wasSettled())
+	assert.True(t, stayingEngine.wasSettled())
+	assert.Len(t, manager.List(), 0)
+
+	select {
+	case <-staying.Done():
+	default:
+		t.Error("expected session to be closed at the drain deadline")
+	}
+
+	// repeated drain waits for the finished one
+	assert.NoError(t, drainer.Drain())
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 
 	"github.com/gofrs/uuid"
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/identity"
@@ -39,6 +40,14 @@ var (
 	ErrUnsupportedServiceType = errors.New("unsupported service type")
 	// ErrUnsupportedAccessPolicy indicates that manager tried to create service with unsupported access policy
 	ErrUnsupportedAccessPolicy = errors.New("unsupported access policy")
+	// ErrAlreadyPaused indicates that manager tried to pause the service which is already paused
+	ErrAlreadyPaused = errors.New("service is already paused")
+	// ErrNotPaused indicates that manager tried to resume the service which is not paused
+	ErrNotPaused = errors.New("service is not paused")
+	// ErrPaused indicates that service is paused and does not accept new sessions
+	ErrPaused = errors.New("service is paused, new sessions are not accepted")
+	// ErrProfileExportNotSupported indicates that service is not able to export a profile for external clients
+	ErrProfileExportNotSupported = errors.New("profile export is not supported by service")
 )
 
 // Service interface represents pluggable Mysterium service
@@ -70,6 +79,7 @@ func NewManager(
 	p2pListener p2p.Listener,
 	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager,
 	statusStorage connectivity.StatusStorage,
+	consumerLocator ConsumerLocator,
 ) *Manager {
 	return &Manager{
 		serviceRegistry:  serviceRegistry,
@@ -80,6 +90,8 @@ func NewManager(
 		p2pListener:      p2pListener,
 		sessionManager:   sessionManager,
 		statusStorage:    statusStorage,
+		consumerLocator:  consumerLocator,
+		restartPolicy:    DefaultRestartPolicy(),
 	}
 }
 
@@ -92,15 +104,23 @@ type Manager struct {
 	eventPublisher   Publisher
 	policyOracle     *policy.Oracle
 
-	p2pListener    p2p.Listener
-	sessionManager func(service *Instance, channel p2p.Channel) *SessionManager
-	statusStorage  connectivity.StatusStorage
+	p2pListener     p2p.Listener
+	sessionManager  func(service *Instance, channel p2p.Channel) *SessionManager
+	statusStorage   connectivity.StatusStorage
+	consumerLocator ConsumerLocator
+	restartPolicy   RestartPolicy
 }
 
 // Start starts an instance of the given service type if knows one in service registry.
 // It passes the options to the start method of the service.
+// Unlisted instances are not announced to discovery, consumers reach them using the invite code.
+// Consumer policy restricts locations consumers may connect from.
 // If an error occurs in the underlying service, the error is then returned.
-func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options, pm market.PaymentMethod) (id ID, err error) {
+func (manager *Manager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options Options, pm market.PaymentMethod, unlisted bool, consumerPolicy ConsumerPolicy) (id ID, err error) {
+	if err := consumerPolicy.validate(manager.consumerLocator); err != nil {
+		return id, err
+	}
+
 	service, proposal, err := manager.serviceRegistry.Create(serviceType, options)
 	if err != nil {
 		return id, err
@@ -119,37 +139,44 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 	}
 
 	proposal.SetProviderContacts(providerID, market.ContactList{manager.p2pListener.GetContact()})
+	proposal.ID = manager.servicePool.ProposalID(providerID, serviceType)
 
-	id, err = generateID()
+	id, err = manager.generateUniqueID()
 	if err != nil {
 		return id, err
 	}
 
-	discovery := manager.discoveryFactory()
+	var discovery Discovery = &unlistedDiscovery{}
+	if !unlisted {
+		discovery = manager.discoveryFactory()
+	}
 	discovery.Start(providerID, proposal)
 
 	instance := &Instance{
-		ID:             id,
-		ProviderID:     providerID,
-		Type:           serviceType,
-		state:          servicestate.Starting,
-		Options:        options,
-		service:        service,
-		Proposal:       proposal,
-		policies:       policyRules,
-		discovery:      discovery,
-		eventPublisher: manager.eventPublisher,
+		ID:              id,
+		ProviderID:      providerID,
+		Type:            serviceType,
+		Unlisted:        unlisted,
+		ConsumerPolicy:  consumerPolicy,
+		state:           servicestate.Starting,
+		Options:         options,
+		service:         service,
+		Proposal:        proposal,
+		policies:        policyRules,
+		discovery:       discovery,
+		consumerLocator: manager.consumerLocator,
+		eventPublisher:  manager.eventPublisher,
 	}
 
 	channelHandlers := func(ch p2p.Channel) {
 		instance.addP2PChannel(ch)
 		mng := manager.sessionManager(instance, ch)
-		subscribeSessionCreate(mng, ch)
+		subscribeSessionCreate(mng, ch, manager.statusStorage)
 		subscribeSessionStatus(ch, manager.statusStorage)
 		subscribeSessionAcknowledge(mng, ch)
 		subscribeSessionDestroy(mng, ch)
 	}
-	stopP2PListener, err := manager.p2pListener.Listen(providerID, serviceType, channelHandlers)
+	stopP2PListener, err := manager.p2pListener.Listen(providerID, proposal.UniqueID().ServiceKey(), channelHandlers)
 	if err != nil {
 		return id, fmt.Errorf("could not subscribe to p2p channels: %w", err)
 	}
@@ -157,12 +184,7 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 	manager.servicePool.Add(instance)
 
 	go func() {
-		instance.setState(servicestate.Running)
-
-		serveErr := service.Serve(instance)
-		if serveErr != nil {
-			log.Error().Err(serveErr).Msg("Service serve failed")
-		}
+		manager.supervise(instance)
 
 		stopP2PListener()
 
@@ -171,7 +193,7 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 			log.Error().Err(stopErr).Msg("Service stop failed")
 		}
 
-		discovery.Wait()
+		instance.currentDiscovery().Wait()
 	}()
 
 	netutil.LogNetworkStats()
@@ -179,6 +201,19 @@ func (manager *Manager) Start(providerID identity.Identity, serviceType string,
 	return id, nil
 }
 
+// generateUniqueID generates ID which is not taken by any running service instance.
+func (manager *Manager) generateUniqueID() (ID, error) {
+	for {
+		id, err := generateID()
+		if err != nil {
+			return id, err
+		}
+		if manager.servicePool.Instance(id) == nil {
+			return id, nil
+		}
+	}
+}
+
 func generateID() (ID, error) {
 	uid, err := uuid.NewV4()
 	if err != nil {
@@ -207,7 +242,65 @@ func (manager *Manager) Stop(id ID) error {
 	return nil
 }
 
+// Pause stops accepting new sessions of the service and withdraws its proposal from discovery.
+// The service keeps running, so its ports and proposal ID stay reserved until it is resumed.
+func (manager *Manager) Pause(id ID) error {
+	instance := manager.servicePool.Instance(id)
+	if instance == nil {
+		return ErrNoSuchInstance
+	}
+	return instance.pause()
+}
+
+// Resume starts accepting new sessions of the paused service and announces its proposal again.
+func (manager *Manager) Resume(id ID) error {
+	instance := manager.servicePool.Instance(id)
+	if instance == nil {
+		return ErrNoSuchInstance
+	}
+
+	return instance.resume(manager.instanceDiscoveryFactory(instance))
+}
+
+// HandleLocationChangedEvent announces proposals of the running services from the changed location,
+// instead of keeping the stale location announced until the services are restarted.
+func (manager *Manager) HandleLocationChangedEvent(e location.AppEventLocationChanged) {
+	loc := market.Location{
+		Continent: e.Current.Continent,
+		Country:   e.Current.Country,
+		City:      e.Current.City,
+		ASN:       e.Current.ASN,
+		ISP:       e.Current.ISP,
+		NodeType:  e.Current.NodeType,
+	}
+
+	for id, instance := range manager.servicePool.List() {
+		if instance.relocate(loc, manager.instanceDiscoveryFactory(instance)) {
+			log.Info().Msgf("Proposal of service %s updated to location %s (%s)", id, loc.Country, loc.ISP)
+		}
+	}
+}
+
+// instanceDiscoveryFactory returns factory of discovery announcing the proposal of the given instance.
+func (manager *Manager) instanceDiscoveryFactory(instance *Instance) DiscoveryFactory {
+	return func() Discovery {
+		if instance.Unlisted {
+			return &unlistedDiscovery{}
+		}
+		return manager.discoveryFactory()
+	}
+}
+
 // Service returns a service instance by requested id.
 func (manager *Manager) Service(id ID) *Instance {
 	return manager.servicePool.Instance(id)
 }
+
+// unlistedDiscovery keeps the proposal of unlisted service away from discovery.
+type unlistedDiscovery struct{}
+
+func (d *unlistedDiscovery) Start(_ identity.Identity, _ market.ServiceProposal) {}
+
+func (d *unlistedDiscovery) Stop() {}
+
+func (d *unlistedDiscovery) Wait() {}
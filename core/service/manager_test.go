@@ -22,6 +22,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/policy"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/identity"
@@ -57,15 +58,93 @@ func TestManager_StartRemovesServiceFromPoolIfServiceCrashes(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		&mockP2PListener{}, nil, nil, nil,
 	)
-	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
+	manager.restartPolicy = RestartPolicy{}
+	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{})
 	assert.Nil(t, err)
 
 	discovery.Wait()
 	assert.Len(t, manager.servicePool.List(), 0)
 }
 
+func TestManager_StartRestartsCrashedService(t *testing.T) {
+	registry := NewRegistry()
+	var created int
+	healthy := &serviceFake{mockProcess: make(chan struct{})}
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		created++
+		if created <= 2 {
+			return &serviceFake{onStartReturnError: errors.New("some error")}, proposalMock, nil
+		}
+		return healthy, proposalMock, nil
+	})
+
+	discovery := mockDiscovery{}
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&discovery),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil, nil,
+	)
+	manager.restartPolicy = RestartPolicy{MaxRestarts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{})
+	assert.NoError(t, err)
+
+	instance := manager.Service(id)
+	assert.Eventually(t, func() bool {
+		return instance.Service() == healthy && instance.State() == servicestate.Running
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, instance.Restarts())
+
+	assert.NoError(t, manager.Stop(id))
+	discovery.Wait()
+	assert.Len(t, manager.servicePool.List(), 0)
+}
+
+func TestManager_StartGivesUpRestartingAfterMaxRestarts(t *testing.T) {
+	registry := NewRegistry()
+	var created int
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		created++
+		return &serviceFake{onStartReturnError: errors.New("some error")}, proposalMock, nil
+	})
+
+	discovery := mockDiscovery{}
+	eventBus := mocks.NewEventBus()
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&discovery),
+		eventBus,
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil, nil,
+	)
+	manager.restartPolicy = RestartPolicy{MaxRestarts: 2, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{})
+	assert.NoError(t, err)
+
+	discovery.Wait()
+	assert.Len(t, manager.servicePool.List(), 0)
+	assert.Equal(t, 3, created)
+
+	var statuses []string
+	for _, entry := range eventBus.GetEventHistory() {
+		if entry.Topic == servicestate.AppTopicServiceStatus {
+			statuses = append(statuses, entry.Event.(servicestate.AppEventServiceStatus).Status)
+		}
+	}
+	assert.Equal(t, []string{"Running", "Restarting", "Running", "Restarting", "Running", "Failed", "NotRunning"}, statuses)
+}
+
+func TestRestartPolicy_Backoff(t *testing.T) {
+	policy := RestartPolicy{InitialBackoff: time.Second, MaxBackoff: 5 * time.Second}
+	assert.Equal(t, time.Second, policy.backoff(1))
+	assert.Equal(t, 2*time.Second, policy.backoff(2))
+	assert.Equal(t, 4*time.Second, policy.backoff(3))
+	assert.Equal(t, 5*time.Second, policy.backoff(4))
+}
+
 func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 	registry := NewRegistry()
 	mockCopy := *serviceMock
@@ -81,9 +160,9 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 		discoveryFactory,
 		mocks.NewEventBus(),
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		&mockP2PListener{}, nil, nil, nil,
 	)
-	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{})
 	assert.Nil(t, err)
 	err = manager.Stop(id)
 	assert.Nil(t, err)
@@ -91,6 +170,130 @@ func TestManager_StartDoesNotCrashIfStoppedByUser(t *testing.T) {
 	assert.Len(t, manager.servicePool.List(), 0)
 }
 
+func TestManager_StartUnlistedSkipsDiscovery(t *testing.T) {
+	registry := NewRegistry()
+	mockCopy := *serviceMock
+	mockCopy.mockProcess = make(chan struct{})
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return &mockCopy, proposalMock, nil
+	})
+
+	var discoveryCreated bool
+	manager := NewManager(
+		registry,
+		func() Discovery {
+			discoveryCreated = true
+			return &mockDiscovery{}
+		},
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil, nil,
+	)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, true, ConsumerPolicy{})
+	assert.NoError(t, err)
+	assert.False(t, discoveryCreated)
+	assert.True(t, manager.Service(id).Unlisted)
+
+	assert.NoError(t, manager.Stop(id))
+	assert.Len(t, manager.servicePool.List(), 0)
+}
+
+func TestManager_StartRejectsASNPolicyWithoutASNDatabase(t *testing.T) {
+	registry := NewRegistry()
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return serviceMock, proposalMock, nil
+	})
+
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(&mockDiscovery{}),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil, &mockConsumerLocator{},
+	)
+	_, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{DeniedASNs: []int{15169}})
+	assert.Equal(t, ErrASNDatabaseRequired, err)
+	assert.Len(t, manager.servicePool.List(), 0)
+}
+
+func TestManager_PauseAndResume(t *testing.T) {
+	registry := NewRegistry()
+	mockCopy := *serviceMock
+	mockCopy.mockProcess = make(chan struct{})
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return &mockCopy, proposalMock, nil
+	})
+
+	discovery := mockDiscovery{}
+	var discoveriesCreated int
+	manager := NewManager(
+		registry,
+		func() Discovery {
+			discoveriesCreated++
+			return &discovery
+		},
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil, nil,
+	)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{})
+	assert.NoError(t, err)
+	instance := manager.Service(id)
+	assert.Eventually(t, func() bool { return instance.State() == servicestate.Running }, time.Second, 5*time.Millisecond)
+
+	assert.NoError(t, manager.Pause(id))
+	assert.True(t, instance.Paused())
+	assert.Equal(t, servicestate.Paused, instance.State())
+	assert.Equal(t, ErrAlreadyPaused, manager.Pause(id))
+
+	assert.NoError(t, manager.Resume(id))
+	assert.False(t, instance.Paused())
+	assert.Equal(t, servicestate.Running, instance.State())
+	assert.Equal(t, ErrNotPaused, manager.Resume(id))
+	assert.Equal(t, 2, discoveriesCreated)
+
+	assert.Equal(t, ErrNoSuchInstance, manager.Pause("unknown"))
+	assert.Equal(t, ErrNoSuchInstance, manager.Resume("unknown"))
+
+	assert.NoError(t, manager.Stop(id))
+	discovery.Wait()
+}
+
+func TestManager_HandleLocationChangedEvent_AnnouncesRelocatedProposal(t *testing.T) {
+	registry := NewRegistry()
+	mockCopy := *serviceMock
+	mockCopy.mockProcess = make(chan struct{})
+	proposal := market.ServiceProposal{ServiceDefinition: mockServiceDefinition{Location: market.Location{Country: "LT", NATType: "none"}}}
+	registry.Register(serviceType, func(options Options) (Service, market.ServiceProposal, error) {
+		return &mockCopy, proposal, nil
+	})
+
+	discovery := &proposalsDiscovery{}
+	manager := NewManager(
+		registry,
+		MockDiscoveryFactoryFunc(discovery),
+		mocks.NewEventBus(),
+		mockPolicyOracle,
+		&mockP2PListener{}, nil, nil, nil,
+	)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{})
+	assert.NoError(t, err)
+
+	// when
+	manager.HandleLocationChangedEvent(location.AppEventLocationChanged{
+		Previous: location.Location{IP: "1.1.1.1", Country: "LT"},
+		Current:  location.Location{IP: "2.2.2.2", Country: "LV", ISP: "LTE"},
+	})
+
+	// then
+	started := discovery.started()
+	assert.Len(t, started, 2)
+	assert.Equal(t, market.Location{Country: "LV", ISP: "LTE", NATType: "none"}, started[1].ServiceDefinition.GetLocation())
+	assert.Equal(t, market.Location{Country: "LV", ISP: "LTE", NATType: "none"}, manager.Service(id).Proposal.ServiceDefinition.GetLocation())
+
+	assert.NoError(t, manager.Stop(id))
+}
+
 func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 	registry := NewRegistry()
 	mockCopy := *serviceMock
@@ -107,10 +310,10 @@ func TestManager_StopSendsEvent_SucceedsAndPublishesEvent(t *testing.T) {
 		discoveryFactory,
 		eventBus,
 		mockPolicyOracle,
-		&mockP2PListener{}, nil, nil,
+		&mockP2PListener{}, nil, nil, nil,
 	)
 
-	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil)
+	id, err := manager.Start(identity.FromAddress(proposalMock.ProviderID), serviceType, nil, struct{}{}, nil, false, ConsumerPolicy{})
 	assert.NoError(t, err)
 
 	services := manager.servicePool.List()
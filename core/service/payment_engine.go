@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+// PaymentEngineFactory creates a new instance of payment engine
+type PaymentEngineFactory func(providerID, consumerID identity.Identity, accountantID common.Address, sessionID string) (PaymentEngine, error)
+
+// PaymentEngine is responsible for interacting with the consumer in regard to payments.
+type PaymentEngine interface {
+	Start() error
+	WaitFirstInvoice(time.Duration) error
+	Stop()
+}
+
+// PaymentEngineCreator prepares payment engine factory for sessions of the given service instance.
+type PaymentEngineCreator func(service *Instance, channel p2p.Channel) PaymentEngineFactory
+
+// PaymentEngineRegistry holds payment engines pluggable per service type.
+type PaymentEngineRegistry struct {
+	defaultCreator PaymentEngineCreator
+	creators       map[string]PaymentEngineCreator
+	lock           sync.RWMutex
+}
+
+// NewPaymentEngineRegistry creates a registry of payment engines,
+// services without a registered engine use the given default one.
+func NewPaymentEngineRegistry(defaultCreator PaymentEngineCreator) *PaymentEngineRegistry {
+	return &PaymentEngineRegistry{
+		defaultCreator: defaultCreator,
+		creators:       make(map[string]PaymentEngineCreator),
+	}
+}
+
+// Register registers a payment engine for the given service type.
+func (registry *PaymentEngineRegistry) Register(serviceType string, creator PaymentEngineCreator) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	registry.creators[serviceType] = creator
+}
+
+// Factory returns payment engine factory for sessions of the given service instance.
+func (registry *PaymentEngineRegistry) Factory(service *Instance, channel p2p.Channel) PaymentEngineFactory {
+	registry.lock.RLock()
+	creator, exists := registry.creators[service.Type]
+	registry.lock.RUnlock()
+
+	if !exists {
+		creator = registry.defaultCreator
+	}
+
+	return creator(service, channel)
+}
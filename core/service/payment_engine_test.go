@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/stretchr/testify/assert"
+)
+
+func engineCreator(engine PaymentEngine) PaymentEngineCreator {
+	return func(_ *Instance, _ p2p.Channel) PaymentEngineFactory {
+		return func(_, _ identity.Identity, _ common.Address, _ string) (PaymentEngine, error) {
+			return engine, nil
+		}
+	}
+}
+
+func TestPaymentEngineRegistry_Factory_UsesDefault(t *testing.T) {
+	defaultEngine := &mockBalanceTracker{}
+	registry := NewPaymentEngineRegistry(engineCreator(defaultEngine))
+	registry.Register("free", engineCreator(&mockBalanceTracker{}))
+
+	engine, err := registry.Factory(&Instance{Type: "paid"}, nil)(identity.Identity{}, identity.Identity{}, common.Address{}, "")
+	assert.NoError(t, err)
+	assert.Same(t, defaultEngine, engine)
+}
+
+func TestPaymentEngineRegistry_Factory_UsesRegistered(t *testing.T) {
+	freeEngine := &mockBalanceTracker{}
+	registry := NewPaymentEngineRegistry(engineCreator(&mockBalanceTracker{}))
+	registry.Register("free", engineCreator(freeEngine))
+
+	engine, err := registry.Factory(&Instance{Type: "free"}, nil)(identity.Identity{}, identity.Identity{}, common.Address{}, "")
+	assert.NoError(t, err)
+	assert.Same(t, freeEngine, engine)
+}
@@ -117,6 +117,26 @@ func (p *Pool) Instance(id ID) *Instance {
 	return p.instances[id]
 }
 
+// ProposalID returns the lowest proposal ID which is not used by the running services
+// of the given provider and service type.
+func (p *Pool) ProposalID(providerID identity.Identity, serviceType string) int {
+	p.Lock()
+	defer p.Unlock()
+
+	used := make(map[int]bool)
+	for _, instance := range p.instances {
+		if instance.ProviderID == providerID && instance.Type == serviceType {
+			used[instance.Proposal.ID] = true
+		}
+	}
+
+	id := 0
+	for used[id] {
+		id++
+	}
+	return id
+}
+
 // NewInstance creates new instance of the service.
 func NewInstance(
 	providerID identity.Identity,
@@ -147,21 +167,193 @@ type Instance struct {
 	stateLock       sync.RWMutex
 	ProviderID      identity.Identity
 	Type            string
+	Unlisted        bool
+	ConsumerPolicy  ConsumerPolicy
 	Options         Options
 	service         Service
 	Proposal        market.ServiceProposal
 	policies        *policy.Repository
 	discovery       Discovery
+	consumerLocator ConsumerLocator
 	eventPublisher  Publisher
 	p2pChannelsLock sync.Mutex
 	p2pChannels     []p2p.Channel
+	restarts        int
+	resourceUsage   ResourceUsage
+	stopped         bool
+	draining        bool
+	paused          bool
+	stopCh          chan struct{}
 }
 
 // Service returns the running service implementation.
 func (i *Instance) Service() Service {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
 	return i.service
 }
 
+// BlockedEgressReporter is implemented by services which block consumer traffic to some destination ports.
+type BlockedEgressReporter interface {
+	BlockedEgressAttempts() uint64
+}
+
+// BlockedEgressAttempts returns how many packets to blocked destination ports were dropped by the service.
+func (i *Instance) BlockedEgressAttempts() uint64 {
+	if reporter, ok := i.Service().(BlockedEgressReporter); ok {
+		return reporter.BlockedEgressAttempts()
+	}
+	return 0
+}
+
+// BackendReporter is implemented by services which run tunnels on one of several backends.
+type BackendReporter interface {
+	Backend() string
+}
+
+// Backend returns name of the backend running tunnels of the service, empty if service has no such choice.
+func (i *Instance) Backend() string {
+	if reporter, ok := i.Service().(BackendReporter); ok {
+		return reporter.Backend()
+	}
+	return ""
+}
+
+// ProfileExporter is implemented by services which are able to export
+// a standalone profile for external clients.
+type ProfileExporter interface {
+	Profile() ([]byte, error)
+}
+
+// Profile exports a standalone profile of the service for external clients.
+func (i *Instance) Profile() ([]byte, error) {
+	if exporter, ok := i.Service().(ProfileExporter); ok {
+		return exporter.Profile()
+	}
+	return nil, ErrProfileExportNotSupported
+}
+
+// ResourceUsage returns the latest sample of resources used by the instance.
+func (i *Instance) ResourceUsage() ResourceUsage {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	return i.resourceUsage
+}
+
+func (i *Instance) setResourceUsage(usage ResourceUsage) {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	i.resourceUsage = usage
+}
+
+// Restarts returns how many times the crashed service was restarted.
+func (i *Instance) Restarts() int {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	return i.restarts
+}
+
+// Draining tells whether the instance stopped accepting new sessions.
+func (i *Instance) Draining() bool {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	return i.draining
+}
+
+// startDraining stops accepting new sessions and withdraws the proposal from discovery.
+func (i *Instance) startDraining() {
+	i.stateLock.Lock()
+	if i.draining {
+		i.stateLock.Unlock()
+		return
+	}
+	i.draining = true
+	discovery := i.discovery
+	i.stateLock.Unlock()
+
+	if discovery != nil {
+		discovery.Stop()
+	}
+}
+
+// Paused tells whether the instance was paused by the operator.
+func (i *Instance) Paused() bool {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	return i.paused
+}
+
+// pause stops accepting new sessions and withdraws the proposal from discovery.
+func (i *Instance) pause() error {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	if i.paused {
+		return ErrAlreadyPaused
+	}
+	i.paused = true
+	// discovery of draining instance is already stopped
+	if i.discovery != nil && !i.draining {
+		i.discovery.Stop()
+	}
+
+	i.eventPublisher.Publish(servicestate.AppTopicServiceStatus, i.toEvent())
+	return nil
+}
+
+// resume starts accepting new sessions again and announces the proposal,
+// stopped discovery can not be restarted so a fresh one is created by the given factory.
+func (i *Instance) resume(discoveryFactory DiscoveryFactory) error {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	if !i.paused {
+		return ErrNotPaused
+	}
+	if i.draining || i.stopped {
+		return ErrDraining
+	}
+	i.paused = false
+	i.discovery = discoveryFactory()
+	i.discovery.Start(i.ProviderID, i.Proposal)
+
+	i.eventPublisher.Publish(servicestate.AppTopicServiceStatus, i.toEvent())
+	return nil
+}
+
+// relocate updates the instance proposal to be provided from the given location.
+// Announced proposal is registered again by a fresh discovery, once the previous one unregisters it.
+// Proposal of paused or draining instance is only updated, it is announced when resumed.
+func (i *Instance) relocate(loc market.Location, discoveryFactory DiscoveryFactory) bool {
+	i.stateLock.Lock()
+	definition, ok := i.Proposal.ServiceDefinition.(market.RelocatableServiceDefinition)
+	if !ok || i.stopped {
+		i.stateLock.Unlock()
+		return false
+	}
+	// NAT type does not depend on geographic location, it is kept as announced.
+	loc.NATType = definition.GetLocation().NATType
+	i.Proposal.ServiceDefinition = definition.WithLocation(loc)
+	if i.paused || i.draining || i.discovery == nil {
+		i.stateLock.Unlock()
+		return true
+	}
+	previous := i.discovery
+	i.discovery = discoveryFactory()
+	current, proposal := i.discovery, i.Proposal
+	i.stateLock.Unlock()
+
+	previous.Stop()
+	previous.Wait()
+	current.Start(i.ProviderID, proposal)
+	return true
+}
+
+// currentDiscovery returns discovery announcing the instance proposal.
+func (i *Instance) currentDiscovery() Discovery {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	return i.discovery
+}
+
 // Policies returns service policies of the running service instance.
 func (i *Instance) Policies() *policy.Repository {
 	return i.policies
@@ -171,6 +363,14 @@ func (i *Instance) Policies() *policy.Repository {
 func (i *Instance) State() servicestate.State {
 	i.stateLock.RLock()
 	defer i.stateLock.RUnlock()
+	return i.reportedState()
+}
+
+// reportedState returns Paused instead of Running while the instance is paused.
+func (i *Instance) reportedState() servicestate.State {
+	if i.paused && i.state == servicestate.Running {
+		return servicestate.Paused
+	}
 	return i.state
 }
 
@@ -182,6 +382,57 @@ func (i *Instance) setState(newState servicestate.State) {
 	i.eventPublisher.Publish(servicestate.AppTopicServiceStatus, i.toEvent())
 }
 
+func (i *Instance) setRestarting() {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	i.state = servicestate.Restarting
+	i.restarts++
+
+	i.eventPublisher.Publish(servicestate.AppTopicServiceStatus, i.toEvent())
+}
+
+// replaceService swaps the crashed service implementation with a new one.
+// It refuses to do so once the instance was stopped.
+func (i *Instance) replaceService(service Service) bool {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	if i.stopped {
+		return false
+	}
+	i.service = service
+	return true
+}
+
+func (i *Instance) stopChan() <-chan struct{} {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	if i.stopCh == nil {
+		i.stopCh = make(chan struct{})
+		if i.stopped {
+			close(i.stopCh)
+		}
+	}
+	return i.stopCh
+}
+
+func (i *Instance) isStopped() bool {
+	i.stateLock.RLock()
+	defer i.stateLock.RUnlock()
+	return i.stopped
+}
+
+func (i *Instance) markStopped() Service {
+	i.stateLock.Lock()
+	defer i.stateLock.Unlock()
+	if !i.stopped {
+		i.stopped = true
+		if i.stopCh != nil {
+			close(i.stopCh)
+		}
+	}
+	return i.service
+}
+
 func (i *Instance) addP2PChannel(ch p2p.Channel) {
 	i.p2pChannelsLock.Lock()
 	defer i.p2pChannelsLock.Unlock()
@@ -207,11 +458,13 @@ func (i *Instance) closeP2PChannel(ch p2p.Channel) {
 
 func (i *Instance) stop() error {
 	errStop := utils.ErrorCollection{}
-	if i.discovery != nil {
-		i.discovery.Stop()
+	service := i.markStopped()
+	// discovery of draining or paused instance is already stopped
+	if discovery := i.currentDiscovery(); discovery != nil && !i.Draining() && !i.Paused() {
+		discovery.Stop()
 	}
-	if i.service != nil {
-		errStop.Add(i.service.Stop())
+	if service != nil {
+		errStop.Add(service.Stop())
 	}
 
 	i.p2pChannelsLock.Lock()
@@ -230,6 +483,7 @@ func (i *Instance) toEvent() servicestate.AppEventServiceStatus {
 		ID:         string(i.ID),
 		ProviderID: i.Proposal.ProviderID,
 		Type:       i.Proposal.ServiceType,
-		Status:     string(i.state),
+		Status:     string(i.reportedState()),
+		Restarts:   i.restarts,
 	}
 }
@@ -24,6 +24,8 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/stretchr/testify/assert"
 )
@@ -71,6 +73,18 @@ func Test_Pool_Add(t *testing.T) {
 	assert.Len(t, pool.instances, 1)
 }
 
+func Test_Pool_ProposalID(t *testing.T) {
+	provider := identity.FromAddress("0x1")
+	pool := NewPool(mocks.NewEventBus())
+	assert.Equal(t, 0, pool.ProposalID(provider, "wireguard"))
+
+	pool.Add(&Instance{ID: "1", ProviderID: provider, Type: "wireguard", Proposal: market.ServiceProposal{ID: 0}})
+	pool.Add(&Instance{ID: "2", ProviderID: provider, Type: "wireguard", Proposal: market.ServiceProposal{ID: 2}})
+	pool.Add(&Instance{ID: "3", ProviderID: provider, Type: "openvpn", Proposal: market.ServiceProposal{ID: 1}})
+	assert.Equal(t, 1, pool.ProposalID(provider, "wireguard"))
+	assert.Equal(t, 0, pool.ProposalID(identity.FromAddress("0x2"), "wireguard"))
+}
+
 func Test_Pool_StopAllSuccess(t *testing.T) {
 	instance := &Instance{
 		service:        &mockService{},
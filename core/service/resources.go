@@ -0,0 +1,260 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/rs/zerolog/log"
+)
+
+// serviceLabel is a profiler label of goroutines started while serving the service instance.
+const serviceLabel = "service"
+
+// ResourceUsage is a sample of resources used by the service instance.
+type ResourceUsage struct {
+	// Goroutines counts goroutines started while serving the instance.
+	Goroutines int
+	// CPUPercent, MemoryBytes and FileDescriptors are sampled from processes run by the service, if any.
+	CPUPercent      float64
+	MemoryBytes     uint64
+	FileDescriptors int
+	SampledAt       time.Time
+}
+
+// ResourceThresholds defines usage which is reported as a warning, zero disables the threshold.
+type ResourceThresholds struct {
+	Goroutines      int
+	CPUPercent      float64
+	MemoryBytes     uint64
+	FileDescriptors int
+}
+
+// ProcessOwner is implemented by services which run separate processes, e.g. openvpn server.
+type ProcessOwner interface {
+	PIDs() []int
+}
+
+// processUsage is a sample of resources used by a single process.
+type processUsage struct {
+	cpuTime         time.Duration
+	memoryBytes     uint64
+	fileDescriptors int
+}
+
+type serviceLister interface {
+	List() map[ID]*Instance
+}
+
+// ResourceMonitor periodically samples resources used by service instances, so leaking instances can be spotted.
+type ResourceMonitor struct {
+	services   serviceLister
+	publisher  Publisher
+	interval   time.Duration
+	thresholds ResourceThresholds
+
+	goroutines   func() map[string]int
+	processUsage func(pid int) (processUsage, error)
+
+	cpuTime  map[ID]cpuSample
+	exceeded map[ID]map[string]bool
+	stop     chan struct{}
+	once     sync.Once
+}
+
+type cpuSample struct {
+	total time.Duration
+	at    time.Time
+}
+
+// NewResourceMonitor creates resource monitor of service instances.
+func NewResourceMonitor(services serviceLister, publisher Publisher, interval time.Duration, thresholds ResourceThresholds) *ResourceMonitor {
+	return &ResourceMonitor{
+		services:     services,
+		publisher:    publisher,
+		interval:     interval,
+		thresholds:   thresholds,
+		goroutines:   goroutinesByService,
+		processUsage: sampleProcess,
+		cpuTime:      make(map[ID]cpuSample),
+		exceeded:     make(map[ID]map[string]bool),
+		stop:         make(chan struct{}),
+	}
+}
+
+// Start starts sampling in the background.
+func (m *ResourceMonitor) Start() {
+	go func() {
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-m.stop:
+				return
+			case <-ticker.C:
+				m.sample()
+			}
+		}
+	}()
+}
+
+// Stop stops sampling.
+func (m *ResourceMonitor) Stop() {
+	m.once.Do(func() {
+		close(m.stop)
+	})
+}
+
+func (m *ResourceMonitor) sample() {
+	instances := m.services.List()
+	goroutines := m.goroutines()
+	now := time.Now()
+
+	for id, instance := range instances {
+		usage := ResourceUsage{
+			Goroutines: goroutines[string(id)],
+			SampledAt:  now,
+		}
+		if owner, ok := instance.Service().(ProcessOwner); ok {
+			m.sampleProcesses(id, owner.PIDs(), &usage)
+		}
+		instance.setResourceUsage(usage)
+		m.checkThresholds(instance, usage)
+	}
+
+	// Forget instances which are gone.
+	for id := range m.cpuTime {
+		if _, ok := instances[id]; !ok {
+			delete(m.cpuTime, id)
+		}
+	}
+	for id := range m.exceeded {
+		if _, ok := instances[id]; !ok {
+			delete(m.exceeded, id)
+		}
+	}
+
+	m.publisher.Publish(servicestate.AppTopicServiceResources, struct{}{})
+}
+
+func (m *ResourceMonitor) sampleProcesses(id ID, pids []int, usage *ResourceUsage) {
+	var cpuTime time.Duration
+	for _, pid := range pids {
+		process, err := m.processUsage(pid)
+		if err != nil {
+			log.Debug().Err(err).Msgf("Could not sample process %d of service %s", pid, id)
+			continue
+		}
+		cpuTime += process.cpuTime
+		usage.MemoryBytes += process.memoryBytes
+		usage.FileDescriptors += process.fileDescriptors
+	}
+
+	if previous, ok := m.cpuTime[id]; ok && cpuTime >= previous.total {
+		if elapsed := usage.SampledAt.Sub(previous.at); elapsed > 0 {
+			usage.CPUPercent = float64(cpuTime-previous.total) / float64(elapsed) * 100
+		}
+	}
+	m.cpuTime[id] = cpuSample{total: cpuTime, at: usage.SampledAt}
+}
+
+// checkThresholds publishes a warning once the usage exceeds a threshold, it is published again only after usage drops below it.
+func (m *ResourceMonitor) checkThresholds(instance *Instance, usage ResourceUsage) {
+	check := func(resource string, value, threshold float64) {
+		exceeded := threshold > 0 && value > threshold
+		if m.exceeded[instance.ID] == nil {
+			m.exceeded[instance.ID] = make(map[string]bool)
+		}
+		if !exceeded || m.exceeded[instance.ID][resource] {
+			m.exceeded[instance.ID][resource] = exceeded
+			return
+		}
+		m.exceeded[instance.ID][resource] = true
+
+		log.Warn().Msgf("Service %s %s usage %.0f exceeds threshold %.0f", instance.ID, resource, value, threshold)
+		m.publisher.Publish(servicestate.AppTopicServiceResourceWarning, servicestate.AppEventServiceResourceWarning{
+			ID:        string(instance.ID),
+			Type:      instance.Type,
+			Resource:  resource,
+			Value:     value,
+			Threshold: threshold,
+		})
+	}
+
+	check(servicestate.ResourceGoroutines, float64(usage.Goroutines), float64(m.thresholds.Goroutines))
+	check(servicestate.ResourceCPU, usage.CPUPercent, m.thresholds.CPUPercent)
+	check(servicestate.ResourceMemory, float64(usage.MemoryBytes), float64(m.thresholds.MemoryBytes))
+	check(servicestate.ResourceFileDescriptors, float64(usage.FileDescriptors), float64(m.thresholds.FileDescriptors))
+}
+
+// serveLabeled calls serve with goroutines labeled by the instance ID, goroutines started by serve inherit the label.
+func serveLabeled(instance *Instance, serve func() error) (err error) {
+	pprof.Do(context.Background(), pprof.Labels(serviceLabel, string(instance.ID)), func(context.Context) {
+		err = serve()
+	})
+	return err
+}
+
+// goroutinesByService counts goroutines labeled by the service instance ID.
+func goroutinesByService() map[string]int {
+	var buf bytes.Buffer
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		log.Warn().Err(err).Msg("Could not get goroutine profile")
+		return nil
+	}
+	return parseGoroutineProfile(&buf)
+}
+
+// parseGoroutineProfile parses goroutine counts by service label from the profile in legacy text format:
+//
+//	3 @ 0x43a1c5 0x4069ef
+//	# labels: {"service":"6ba7b810-9dad-11d1-80b4-00c04fd430c8"}
+func parseGoroutineProfile(profile *bytes.Buffer) map[string]int {
+	counts := make(map[string]int)
+	scanner := bufio.NewScanner(profile)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var stackCount int
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "# labels: ") {
+			var labels map[string]string
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "# labels: ")), &labels); err == nil {
+				if id, ok := labels[serviceLabel]; ok {
+					counts[id] += stackCount
+				}
+			}
+			continue
+		}
+
+		stackCount = 0
+		if fields := strings.SplitN(line, " @ ", 2); len(fields) == 2 {
+			stackCount, _ = strconv.Atoi(fields[0])
+		}
+	}
+	return counts
+}
@@ -0,0 +1,37 @@
+//+build linux
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_sampleProcess(t *testing.T) {
+	usage, err := sampleProcess(os.Getpid())
+	assert.NoError(t, err)
+	assert.NotZero(t, usage.memoryBytes)
+	assert.NotZero(t, usage.fileDescriptors)
+
+	_, err = sampleProcess(-1)
+	assert.Error(t, err)
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"bytes"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockServiceLister map[ID]*Instance
+
+func (l mockServiceLister) List() map[ID]*Instance {
+	return l
+}
+
+type mockProcessService struct {
+	mockService
+	pids []int
+}
+
+func (s *mockProcessService) PIDs() []int {
+	return s.pids
+}
+
+func Test_parseGoroutineProfile(t *testing.T) {
+	profile := bytes.NewBufferString(`goroutine profile: total 7
+3 @ 0x43a1c5 0x4069ef 0x46e0a1
+# labels: {"service":"svc-1"}
+#	0x46e0a0	main.loop+0x0	/src/main.go:10
+
+2 @ 0x43a1c5 0x4069ef
+# labels: {"other":"value", "service":"svc-2"}
+#	0x4069ee	main.wait+0x0	/src/main.go:20
+
+1 @ 0x43a1c5 0x4069ef
+# labels: {"service":"svc-1"}
+
+1 @ 0x43a1c5
+#	0x43a1c4	runtime.gopark+0x0	/src/proc.go:1
+`)
+
+	assert.Equal(t, map[string]int{"svc-1": 4, "svc-2": 2}, parseGoroutineProfile(profile))
+}
+
+func Test_serveLabeled_LabelsGoroutinesOfService(t *testing.T) {
+	instance := &Instance{ID: "labeled-service"}
+	release := make(chan struct{})
+	var started sync.WaitGroup
+
+	err := serveLabeled(instance, func() error {
+		for i := 0; i < 3; i++ {
+			started.Add(1)
+			go func() {
+				started.Done()
+				<-release
+			}()
+		}
+		return nil
+	})
+	assert.NoError(t, err)
+	started.Wait()
+
+	assert.Equal(t, 3, goroutinesByService()["labeled-service"])
+	close(release)
+}
+
+func Test_ResourceMonitor_SamplesProcessesAndWarnsOnce(t *testing.T) {
+	instance := &Instance{
+		ID:             "svc-1",
+		Type:           "openvpn",
+		service:        &mockProcessService{pids: []int{10, 11}},
+		eventPublisher: mocks.NewEventBus(),
+	}
+	publisher := mocks.NewEventBus()
+	monitor := NewResourceMonitor(mockServiceLister{"svc-1": instance}, publisher, time.Minute, ResourceThresholds{
+		Goroutines:  10,
+		MemoryBytes: 1000,
+	})
+	monitor.goroutines = func() map[string]int {
+		return map[string]int{"svc-1": 5}
+	}
+	cpuTime := time.Second
+	monitor.processUsage = func(pid int) (processUsage, error) {
+		if pid == 11 {
+			return processUsage{}, errors.New("process is gone")
+		}
+		return processUsage{cpuTime: cpuTime, memoryBytes: 2048, fileDescriptors: 7}, nil
+	}
+
+	monitor.sample()
+	usage := instance.ResourceUsage()
+	assert.Equal(t, 5, usage.Goroutines)
+	assert.Equal(t, uint64(2048), usage.MemoryBytes)
+	assert.Equal(t, 7, usage.FileDescriptors)
+	assert.Zero(t, usage.CPUPercent)
+
+	history := publisher.GetEventHistory()
+	assert.Len(t, history, 2)
+	assert.Equal(t, servicestate.AppTopicServiceResourceWarning, history[0].Topic)
+	assert.Equal(t, servicestate.AppEventServiceResourceWarning{
+		ID:        "svc-1",
+		Type:      "openvpn",
+		Resource:  servicestate.ResourceMemory,
+		Value:     2048,
+		Threshold: 1000,
+	}, history[0].Event)
+	assert.Equal(t, servicestate.AppTopicServiceResources, history[1].Topic)
+
+	// CPU usage is calculated from the time spent since the previous sample.
+	previous := monitor.cpuTime["svc-1"]
+	monitor.cpuTime["svc-1"] = cpuSample{total: previous.total - 500*time.Millisecond, at: previous.at.Add(-time.Second)}
+	publisher.Clear()
+	monitor.sample()
+	assert.InDelta(t, 50, instance.ResourceUsage().CPUPercent, 5)
+
+	history = publisher.GetEventHistory()
+	assert.Len(t, history, 1, "warning is not repeated while usage stays above threshold")
+	assert.Equal(t, servicestate.AppTopicServiceResources, history[0].Topic)
+}
+
+func Test_ResourceMonitor_ForgetsStoppedInstances(t *testing.T) {
+	lister := mockServiceLister{"svc-1": &Instance{ID: "svc-1", service: &mockProcessService{pids: []int{10}}}}
+	monitor := NewResourceMonitor(lister, mocks.NewEventBus(), time.Minute, ResourceThresholds{})
+	monitor.goroutines = func() map[string]int { return nil }
+	monitor.processUsage = func(pid int) (processUsage, error) { return processUsage{}, nil }
+
+	monitor.sample()
+	assert.Len(t, monitor.cpuTime, 1)
+
+	delete(lister, "svc-1")
+	monitor.sample()
+	assert.Len(t, monitor.cpuTime, 0)
+	assert.Len(t, monitor.exceeded, 0)
+}
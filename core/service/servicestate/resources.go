@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package servicestate
+
+const (
+	// AppTopicServiceResources is used in event bus to announce that resource usage of services was sampled.
+	AppTopicServiceResources = "service.resources"
+	// AppTopicServiceResourceWarning is used in event bus to announce that service exceeded resource usage threshold.
+	AppTopicServiceResourceWarning = "service.resource-warning"
+)
+
+// Resources which usage is sampled.
+const (
+	ResourceGoroutines      = "goroutines"
+	ResourceCPU             = "cpu_percent"
+	ResourceMemory          = "memory_bytes"
+	ResourceFileDescriptors = "file_descriptors"
+)
+
+// AppEventServiceResourceWarning represents the service exceeding resource usage threshold.
+type AppEventServiceResourceWarning struct {
+	ID        string  `json:"id"`
+	Type      string  `json:"type"`
+	Resource  string  `json:"resource"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+}
@@ -19,7 +19,7 @@ package servicestate
 
 const (
 	// AppTopicServiceStatus is used in event bus to announce the service status.
-	AppTopicServiceStatus = "Service status"
+	AppTopicServiceStatus = "service.status"
 )
 
 // AppEventServiceStatus represents the service event related information
@@ -28,6 +28,7 @@ type AppEventServiceStatus struct {
 	ProviderID string `json:"provider_id"`
 	Type       string `json:"type"`
 	Status     string `json:"status"`
+	Restarts   int    `json:"restarts"`
 }
 
 // State represents list of possible service states
@@ -40,4 +41,10 @@ const (
 	Starting = State("Starting")
 	// Running means that fully established service exists
 	Running = State("Running")
+	// Restarting means that service crashed and is waiting to be started again
+	Restarting = State("Restarting")
+	// Paused means that service is running but does not accept new sessions
+	Paused = State("Paused")
+	// Failed means that service crashed and restart attempts were exhausted
+	Failed = State("Failed")
 )
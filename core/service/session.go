@@ -19,17 +19,20 @@ package service
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/gofrs/uuid"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/logconfig"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
 	"github.com/mysteriumnetwork/node/session"
 	"github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/trace"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
 // Session structure holds all required information about current session between service consumer and provider.
@@ -40,11 +43,27 @@ type Session struct {
 	Proposal     market.ServiceProposal
 	ServiceID    string
 	CreatedAt    time.Time
-	request      *pb.SessionRequest
-	done         chan struct{}
-	cleanupLock  sync.Mutex
-	cleanup      []func() error
-	tracer       *trace.Tracer
+	// ProtocolVersion and Capabilities hold session protocol features negotiated with consumer.
+	ProtocolVersion uint32
+	Capabilities    []string
+	PaymentVersion  string
+	NATTraversal    string
+	QoSClass        string
+	request         *pb.SessionRequest
+	channel         p2p.ChannelSender
+	payments        PaymentEngine
+	done            chan struct{}
+	cleanupLock     sync.Mutex
+	cleanup         []func() error
+	tracer          *trace.Tracer
+
+	terminationLock   sync.Mutex
+	terminationReason string
+	timedOutStage     session.HandshakeStage
+
+	// dataSent and dataReceived count session traffic of provider, accessed atomically.
+	dataSent     uint64
+	dataReceived uint64
 }
 
 // Close ends session.
@@ -55,20 +74,88 @@ func (s *Session) Close() {
 	defer s.cleanupLock.Unlock()
 
 	for i := len(s.cleanup) - 1; i >= 0; i-- {
-		log.Trace().Msgf("Session cleaning up: (%v/%v)", i+1, len(s.cleanup))
+		s.logger().Trace().Msgf("Session cleaning up: (%v/%v)", i+1, len(s.cleanup))
 		err := s.cleanup[i]()
 		if err != nil {
-			log.Warn().Err(err).Msg("Cleanup error")
+			s.logger().Warn().Err(err).Msg("Cleanup error")
 		}
 	}
 	s.cleanup = nil
 }
 
+// setTerminationReason records why the session is ending, the first recorded reason is kept.
+func (s *Session) setTerminationReason(reason string) {
+	s.terminationLock.Lock()
+	defer s.terminationLock.Unlock()
+
+	if s.terminationReason == "" {
+		s.terminationReason = reason
+	}
+}
+
+func (s *Session) getTerminationReason() string {
+	s.terminationLock.Lock()
+	defer s.terminationLock.Unlock()
+
+	return s.terminationReason
+}
+
+// setTimedOutStage records the handshake stage which did not complete in time, the first recorded stage is kept.
+func (s *Session) setTimedOutStage(stage session.HandshakeStage) {
+	s.terminationLock.Lock()
+	defer s.terminationLock.Unlock()
+
+	if s.timedOutStage == "" {
+		s.timedOutStage = stage
+	}
+}
+
+func (s *Session) getTimedOutStage() session.HandshakeStage {
+	s.terminationLock.Lock()
+	defer s.terminationLock.Unlock()
+
+	return s.timedOutStage
+}
+
+func (s *Session) setDataTransferred(up, down uint64) {
+	atomic.StoreUint64(&s.dataSent, up)
+	atomic.StoreUint64(&s.dataReceived, down)
+}
+
+func (s *Session) getDataTransferred() uint64 {
+	sent, received := s.getTraffic()
+	return sent + received
+}
+
+func (s *Session) getTraffic() (sent, received uint64) {
+	return atomic.LoadUint64(&s.dataSent), atomic.LoadUint64(&s.dataReceived)
+}
+
 // Done returns readonly done channel.
 func (s *Session) Done() <-chan struct{} {
 	return s.done
 }
 
+func (s *Session) setPaymentEngine(engine PaymentEngine) {
+	s.cleanupLock.Lock()
+	defer s.cleanupLock.Unlock()
+
+	s.payments = engine
+}
+
+func (s *Session) paymentEngine() PaymentEngine {
+	s.cleanupLock.Lock()
+	defer s.cleanupLock.Unlock()
+
+	return s.payments
+}
+
+// logger returns logger correlating log lines with the session.
+func (s *Session) logger() *zerolog.Logger {
+	logger := logconfig.SessionLogger(string(s.ID), s.ConsumerID.Address)
+	return &logger
+}
+
 func (s *Session) addCleanup(fn func() error) {
 	s.cleanupLock.Lock()
 	defer s.cleanupLock.Unlock()
@@ -83,11 +170,18 @@ func (s *Session) toEvent(status event.Status) event.AppEventSession {
 			ID: s.ServiceID,
 		},
 		Session: event.SessionContext{
-			ID:           string(s.ID),
-			StartedAt:    s.CreatedAt,
-			ConsumerID:   s.ConsumerID,
-			AccountantID: s.AccountantID,
-			Proposal:     s.Proposal,
+			ID:                string(s.ID),
+			StartedAt:         s.CreatedAt,
+			ConsumerID:        s.ConsumerID,
+			AccountantID:      s.AccountantID,
+			Proposal:          s.Proposal,
+			ProtocolVersion:   s.ProtocolVersion,
+			Capabilities:      s.Capabilities,
+			PaymentVersion:    s.PaymentVersion,
+			NATTraversal:      s.NATTraversal,
+			QoSClass:          s.QoSClass,
+			TerminationReason: s.getTerminationReason(),
+			TimedOutStage:     string(s.getTimedOutStage()),
 		},
 	}
 }
@@ -100,15 +194,18 @@ func NewSession(service *Instance, request *pb.SessionRequest) (*Session, error)
 	}
 
 	return &Session{
-		ID:           session.ID(uid.String()),
-		ConsumerID:   identity.FromAddress(request.GetConsumer().GetId()),
-		AccountantID: common.HexToAddress(request.GetConsumer().GetAccountantID()),
-		Proposal:     service.Proposal,
-		ServiceID:    string(service.ID),
-		CreatedAt:    time.Now().UTC(),
-		request:      request,
-		done:         make(chan struct{}),
-		cleanup:      make([]func() error, 0),
-		tracer:       trace.NewTracer(),
+		ID:              session.ID(uid.String()),
+		ConsumerID:      identity.FromAddress(request.GetConsumer().GetId()),
+		AccountantID:    common.HexToAddress(request.GetConsumer().GetAccountantID()),
+		Proposal:        service.Proposal,
+		ServiceID:       string(service.ID),
+		CreatedAt:       time.Now().UTC(),
+		ProtocolVersion: session.NegotiateVersion(request.GetProtocolVersion()),
+		Capabilities:    session.NegotiateCapabilities(request.GetCapabilities()),
+		PaymentVersion:  request.GetConsumer().GetPaymentVersion(),
+		request:         request,
+		done:            make(chan struct{}),
+		cleanup:         make([]func() error, 0),
+		tracer:          trace.NewTracer(),
 	}, nil
 }
@@ -24,7 +24,8 @@ import (
 	"net"
 	"time"
 
-	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/qos"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat/event"
@@ -43,6 +44,14 @@ var (
 	ErrorSessionNotExists = errors.New("session does not exists")
 	// ErrorWrongSessionOwner returned when consumer tries to destroy session that does not belongs to him
 	ErrorWrongSessionOwner = errors.New("wrong session owner")
+	// ErrorReconfigureNotSupported returned when consumer does not support session config updates
+	ErrorReconfigureNotSupported = errors.New("session reconfigure is not supported by consumer")
+)
+
+const (
+	reconfigureTimeout = 20 * time.Second
+	// maxSpeedTestSize limits the payload provider sends to consumer in a single speed test probe.
+	maxSpeedTestSize = 64 * datasize.MiB
 )
 
 // IDGenerator defines method for session id generation
@@ -52,6 +61,19 @@ type IDGenerator func() (session.ID, error)
 type ConfigParams struct {
 	SessionServiceConfig   ServiceConfiguration
 	SessionDestroyCallback DestroyCallback
+	// SessionConfigUpdates is an optional channel on which service sends updated session config
+	// which should be pushed to the consumer without tearing the session down.
+	SessionConfigUpdates <-chan ConfigUpdate
+	// ShapeTraffic is an optional callback which limits session traffic according to its QoS class.
+	ShapeTraffic func(class qos.Class) error
+}
+
+// ConfigUpdate holds updated session config which is pushed to the consumer.
+type ConfigUpdate struct {
+	Config ServiceConfiguration
+	// Result is an optional callback which receives the outcome of the push,
+	// it is nil error only if consumer applied the config.
+	Result func(err error)
 }
 
 // ServiceConfiguration defines service configuration from underlying transport mechanism to be passed to remote party
@@ -67,11 +89,22 @@ type KeepAliveConfig struct {
 	SendInterval    time.Duration
 	SendTimeout     time.Duration
 	MaxSendErrCount int
+	// DeadPeerTimeout is the max duration without any traffic from consumer
+	// after which the consumer is considered lost and session is destroyed.
+	DeadPeerTimeout time.Duration
 }
 
 // Config contains common configuration options for session manager.
 type Config struct {
-	KeepAlive KeepAliveConfig
+	KeepAlive      KeepAliveConfig
+	Idle           session.IdleConfig
+	Reconciliation session.ReconciliationConfig
+	// QoSClasses lists QoS classes of sessions, the ones advertised in service proposal are offered to consumers.
+	QoSClasses []qos.Class
+	// Handshake bounds the time consumer has to pay the first invoice and receive the session config.
+	Handshake session.HandshakeBudget
+	// ServeSpeedTests lets consumers measure the connection speed to this provider.
+	ServeSpeedTests bool
 }
 
 // DefaultConfig returns default params.
@@ -81,6 +114,7 @@ func DefaultConfig() Config {
 			SendInterval:    14 * time.Second,
 			SendTimeout:     5 * time.Second,
 			MaxSendErrCount: 5,
+			DeadPeerTimeout: 45 * time.Second,
 		},
 	}
 }
@@ -101,16 +135,6 @@ type PromiseProcessor interface {
 	Stop() error
 }
 
-// PaymentEngineFactory creates a new instance of payment engine
-type PaymentEngineFactory func(providerID, consumerID identity.Identity, accountantID common.Address, sessionID string) (PaymentEngine, error)
-
-// PaymentEngine is responsible for interacting with the consumer in regard to payments.
-type PaymentEngine interface {
-	Start() error
-	WaitFirstInvoice(time.Duration) error
-	Stop()
-}
-
 // NATEventGetter lets us access the last known traversal event
 type NATEventGetter interface {
 	LastEvent() *event.Event
@@ -120,6 +144,7 @@ type NATEventGetter interface {
 func NewSessionManager(
 	service *Instance,
 	sessionStorage *SessionPool,
+	startLimiter *StartLimiter,
 	paymentEngineFactory PaymentEngineFactory,
 	natEventGetter NATEventGetter,
 	publisher publisher,
@@ -129,6 +154,7 @@ func NewSessionManager(
 	return &SessionManager{
 		service:              service,
 		sessionStorage:       sessionStorage,
+		startLimiter:         startLimiter,
 		natEventGetter:       natEventGetter,
 		publisher:            publisher,
 		paymentEngineFactory: paymentEngineFactory,
@@ -141,6 +167,7 @@ func NewSessionManager(
 type SessionManager struct {
 	service              *Instance
 	sessionStorage       *SessionPool
+	startLimiter         *StartLimiter
 	paymentEngineFactory PaymentEngineFactory
 	natEventGetter       NATEventGetter
 	publisher            publisher
@@ -151,32 +178,45 @@ type SessionManager struct {
 // Start starts a session on the provider side for the given consumer.
 // Multiple sessions per peerID is possible in case different services are used
 func (manager *SessionManager) Start(request *pb.SessionRequest) (_ pb.SessionResponse, err error) {
-	session, err := NewSession(manager.service, request)
+	// Consumer waits for the session config no longer than the config exchange deadline,
+	// so there is no point in answering after it passes.
+	deadline := time.Now().Add(manager.config.Handshake.Timeout(session.HandshakeConfigExchange))
+
+	release, err := manager.startLimiter.Acquire()
+	if err != nil {
+		log.Warn().Err(err).Msgf("Rejecting session of consumer %s", request.GetConsumer().GetId())
+		return pb.SessionResponse{}, err
+	}
+	defer release()
+
+	sess, err := NewSession(manager.service, request)
 	if err != nil {
 		return pb.SessionResponse{}, errors.Wrap(err, "cannot create new session")
 	}
 	defer func() {
 		if err != nil {
-			log.Err(err).Msg("Session failed, disconnecting")
-			session.Close()
+			sess.logger().Err(err).Msg("Session failed, disconnecting")
+			sess.setTimedOutStage(session.TimedOutStage(err))
+			sess.setTerminationReason(session.TerminationSetupFailed)
+			sess.Close()
 		}
 	}()
 
-	trace := session.tracer.StartStage("Provider whole session create")
+	trace := sess.tracer.StartStage("Provider whole session create")
 	defer func() {
-		session.tracer.EndStage(trace)
-		traceResult := session.tracer.Finish(manager.publisher, string(session.ID))
-		log.Debug().Msgf("Provider connection trace: %s", traceResult)
+		sess.tracer.EndStage(trace)
+		traceResult := sess.tracer.Finish(manager.publisher, string(sess.ID))
+		sess.logger().Debug().Msgf("Provider connection trace: %s", traceResult)
 	}()
 
-	if err = manager.startSession(session); err != nil {
+	if err = manager.startSession(sess); err != nil {
 		return pb.SessionResponse{}, err
 	}
-	if err = manager.paymentLoop(session); err != nil {
+	if err = manager.paymentLoop(sess, deadline); err != nil {
 		return pb.SessionResponse{}, err
 	}
 
-	return manager.providerService(session, manager.channel)
+	return manager.providerService(sess, manager.channel, deadline)
 }
 
 // Acknowledge marks the session as successfully established as far as the consumer is concerned.
@@ -203,6 +243,10 @@ func (manager *SessionManager) startSession(session *Session) error {
 
 	manager.clearStaleSession(session.ConsumerID, manager.service.Type)
 
+	session.channel = manager.channel
+	session.NATTraversal = manager.channel.TraversalMethod()
+	session.QoSClass = manager.negotiateQoSClass(session).Name
+	session.Capabilities = manager.offeredCapabilities(session.Capabilities)
 	manager.sessionStorage.Add(session)
 	session.addCleanup(func() error {
 		manager.sessionStorage.Remove(session.ID)
@@ -210,10 +254,47 @@ func (manager *SessionManager) startSession(session *Session) error {
 	})
 
 	go manager.keepAliveLoop(session, manager.channel)
+	go manager.idleLoop(session)
+	go manager.stallLoop(session, manager.channel)
+	manager.handleTrafficReport(session, manager.channel)
+	if manager.config.ServeSpeedTests {
+		manager.handleSpeedTest(session, manager.channel)
+	}
 
 	return nil
 }
 
+// offeredCapabilities drops the negotiated session features which provider is not willing to serve.
+func (manager *SessionManager) offeredCapabilities(capabilities []string) []string {
+	if manager.config.ServeSpeedTests {
+		return capabilities
+	}
+	return session.WithoutCapability(capabilities, session.CapabilitySpeedTest)
+}
+
+func (manager *SessionManager) negotiateQoSClass(session *Session) qos.Class {
+	requested := session.request.GetQosClass()
+	class := qos.Negotiate(requested, manager.offeredQoSClasses())
+	if requested != "" && requested != class.Name {
+		session.logger().Warn().Msgf("QoS class %q is not offered, using %q", requested, class.Name)
+	}
+	return class
+}
+
+// offeredQoSClasses returns QoS classes advertised in the service proposal.
+func (manager *SessionManager) offeredQoSClasses() []qos.Class {
+	var offered []qos.Class
+	for _, class := range manager.config.QoSClasses {
+		for _, name := range manager.service.Proposal.QoSClasses {
+			if class.Name == name {
+				offered = append(offered, class)
+				break
+			}
+		}
+	}
+	return offered
+}
+
 func (manager *SessionManager) validateSession(session *Session) error {
 	if manager.service.Proposal.ID != int(session.request.GetProposalID()) {
 		return ErrorInvalidProposal
@@ -229,15 +310,16 @@ func (manager *SessionManager) validateSession(session *Session) error {
 func (manager *SessionManager) clearStaleSession(consumerID identity.Identity, serviceType string) {
 	// Reading stale session before starting the clean up in goroutine.
 	// This is required to make sure we are not cleaning the newly created session.
-	for _, session := range manager.sessionStorage.GetAll() {
-		if consumerID != session.ConsumerID {
+	for _, sess := range manager.sessionStorage.GetAll() {
+		if consumerID != sess.ConsumerID {
 			continue
 		}
-		if serviceType != session.Proposal.ServiceType {
+		if serviceType != sess.Proposal.ServiceType {
 			continue
 		}
-		log.Info().Msgf("Cleaning stale session %s for %s consumer", session.ID, consumerID.Address)
-		go session.Close()
+		sess.logger().Info().Msg("Cleaning stale session")
+		sess.setTerminationReason(session.TerminationReconnect)
+		go sess.Close()
 	}
 }
 
@@ -255,18 +337,19 @@ func (manager *SessionManager) Destroy(consumerID identity.Identity, sessionID s
 	return nil
 }
 
-func (manager *SessionManager) paymentLoop(session *Session) error {
-	trace := session.tracer.StartStage("Provider payments")
-	defer session.tracer.EndStage(trace)
+func (manager *SessionManager) paymentLoop(sess *Session, deadline time.Time) error {
+	trace := sess.tracer.StartStage("Provider payments")
+	defer sess.tracer.EndStage(trace)
 
-	log.Info().Msg("Using new payments")
-	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, session.ConsumerID, session.AccountantID, string(session.ID))
+	sess.logger().Info().Msg("Using new payments")
+	engine, err := manager.paymentEngineFactory(manager.service.ProviderID, sess.ConsumerID, sess.AccountantID, string(sess.ID))
 	if err != nil {
 		return err
 	}
+	sess.setPaymentEngine(engine)
 
 	// stop the balance tracker once the session is finished
-	session.addCleanup(func() error {
+	sess.addCleanup(func() error {
 		engine.Stop()
 		return nil
 	})
@@ -274,47 +357,129 @@ func (manager *SessionManager) paymentLoop(session *Session) error {
 	go func() {
 		err := engine.Start()
 		if err != nil {
-			log.Error().Err(err).Msg("Payment engine error")
-			session.Close()
+			sess.logger().Error().Err(err).Msg("Payment engine error")
+			sess.setTerminationReason(session.TerminationPaymentFailed)
+			sess.Close()
 		}
 	}()
 
-	log.Info().Msg("Waiting for a first invoice to be paid")
-	if err := engine.WaitFirstInvoice(30 * time.Second); err != nil {
+	timeout := manager.config.Handshake.Timeout(session.HandshakeFirstInvoice)
+	if remaining := time.Until(deadline); remaining < timeout {
+		timeout = remaining
+	}
+
+	sess.logger().Info().Msg("Waiting for a first invoice to be paid")
+	waitStarted := time.Now()
+	if err := engine.WaitFirstInvoice(timeout); err != nil {
+		sess.setTerminationReason(session.TerminationPaymentFailed)
+		if time.Since(waitStarted) >= timeout {
+			err = &session.HandshakeTimeoutError{Stage: session.HandshakeFirstInvoice, Timeout: timeout}
+		}
 		return fmt.Errorf("first invoice was not paid: %w", err)
 	}
 
 	return nil
 }
 
-func (manager *SessionManager) providerService(session *Session, channel p2p.Channel) (pb.SessionResponse, error) {
-	trace := session.tracer.StartStage("Provider config")
-	defer session.tracer.EndStage(trace)
+func (manager *SessionManager) providerService(sess *Session, channel p2p.Channel, deadline time.Time) (pb.SessionResponse, error) {
+	trace := sess.tracer.StartStage("Provider config")
+	defer sess.tracer.EndStage(trace)
 
-	config, err := manager.service.Service().ProvideConfig(string(session.ID), session.request.GetConfig(), channel.ServiceConn())
+	config, err := manager.service.Service().ProvideConfig(string(sess.ID), sess.request.GetConfig(), channel.ServiceConn())
 	if err != nil {
-		return pb.SessionResponse{}, fmt.Errorf("cannot get provider config for session %s: %w", string(session.ID), err)
+		return pb.SessionResponse{}, fmt.Errorf("cannot get provider config for session %s: %w", string(sess.ID), err)
 	}
 
 	if config.SessionDestroyCallback != nil {
-		session.addCleanup(func() error {
+		sess.addCleanup(func() error {
 			config.SessionDestroyCallback()
 			return nil
 		})
 	}
 
+	if time.Now().After(deadline) {
+		return pb.SessionResponse{}, &session.HandshakeTimeoutError{
+			Stage:   session.HandshakeConfigExchange,
+			Timeout: manager.config.Handshake.Timeout(session.HandshakeConfigExchange),
+		}
+	}
+
 	data, err := json.Marshal(config.SessionServiceConfig)
 	if err != nil {
-		return pb.SessionResponse{}, fmt.Errorf("cannot pack session %s service config: %w", string(session.ID), err)
+		return pb.SessionResponse{}, fmt.Errorf("cannot pack session %s service config: %w", string(sess.ID), err)
 	}
 
+	if config.SessionConfigUpdates != nil {
+		go manager.reconfigureLoop(sess, channel, config.SessionConfigUpdates)
+	}
+
+	manager.shapeTraffic(sess, config.ShapeTraffic)
+
 	return pb.SessionResponse{
-		ID:          string(session.ID),
-		PaymentInfo: "v3",
-		Config:      data,
+		ID:              string(sess.ID),
+		PaymentInfo:     "v3",
+		Config:          data,
+		ProtocolVersion: sess.ProtocolVersion,
+		Capabilities:    sess.Capabilities,
+		QosClass:        sess.QoSClass,
 	}, nil
 }
 
+func (manager *SessionManager) shapeTraffic(session *Session, shape func(class qos.Class) error) {
+	class := qos.Negotiate(session.QoSClass, manager.offeredQoSClasses())
+	if class.LimitKbps == 0 {
+		return
+	}
+	if shape == nil {
+		session.logger().Warn().Msgf("Service %s does not shape traffic, session is not limited by QoS class %q", manager.service.Type, class.Name)
+		return
+	}
+	if err := shape(class); err != nil {
+		session.logger().Error().Err(err).Msgf("Could not shape traffic of session by QoS class %q", class.Name)
+	}
+}
+
+func (manager *SessionManager) reconfigureLoop(sess *Session, channel p2p.ChannelSender, updates <-chan ConfigUpdate) {
+	for {
+		select {
+		case <-sess.Done():
+			return
+		case update, more := <-updates:
+			if !more {
+				return
+			}
+			err := manager.reconfigure(sess, channel, update.Config)
+			if err != nil {
+				sess.logger().Err(err).Msg("Failed to push updated session config")
+			}
+			if update.Result != nil {
+				update.Result(err)
+			}
+		}
+	}
+}
+
+func (manager *SessionManager) reconfigure(sess *Session, channel p2p.ChannelSender, config ServiceConfiguration) error {
+	if !session.HasCapability(sess.Capabilities, session.CapabilityReconfigure) {
+		return ErrorReconfigureNotSupported
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("cannot pack session service config: %w", err)
+	}
+
+	msg := &pb.SessionReconfigure{
+		SessionID: string(sess.ID),
+		Config:    data,
+	}
+	sess.logger().Debug().Msgf("Sending P2P message to %q: %s", p2p.TopicSessionReconfigure, msg.String())
+	ctx, cancel := context.WithTimeout(context.Background(), reconfigureTimeout)
+	defer cancel()
+	_, err = channel.Send(ctx, p2p.TopicSessionReconfigure, p2p.ProtoMessage(msg))
+	return err
+}
+
 func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel) {
 	// Register handler for handling p2p keep alive pings from consumer.
 	channel.Handle(p2p.TopicKeepAlive, func(c p2p.Context) error {
@@ -323,7 +488,7 @@ func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel)
 			return err
 		}
 
-		log.Debug().Msgf("Received p2p keepalive ping with SessionID=%s", ping.SessionID)
+		sess.logger().Debug().Msg("Received p2p keepalive ping")
 		return c.OK()
 	})
 
@@ -337,11 +502,17 @@ func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel)
 			channel.Close()
 			return
 		case <-time.After(manager.config.KeepAlive.SendInterval):
+			if manager.peerLost(channel, sess) {
+				channel.Close()
+				sess.setTerminationReason(session.TerminationPeerLost)
+				sess.Close()
+				return
+			}
 			if err := manager.sendKeepAlivePing(channel, sess.ID); err != nil {
-				log.Err(err).Msgf("Failed to send p2p keepalive ping. SessionID=%s", sess.ID)
+				sess.logger().Err(err).Msg("Failed to send p2p keepalive ping")
 				errCount++
 				if errCount == manager.config.KeepAlive.MaxSendErrCount {
-					log.Error().Msgf("Max p2p keepalive err count reached, closing p2p channel. SessionID=%s", sess.ID)
+					sess.logger().Error().Msg("Max p2p keepalive err count reached, closing p2p channel")
 					channel.Close()
 					return
 				}
@@ -352,6 +523,130 @@ func (manager *SessionManager) keepAliveLoop(sess *Session, channel p2p.Channel)
 	}
 }
 
+// handleTrafficReport answers consumer traffic reports with the traffic counted by provider
+// and publishes an event when both counters start or stop diverging.
+func (manager *SessionManager) handleTrafficReport(sess *Session, channel p2p.ChannelHandler) {
+	reconciler := session.NewTrafficReconciler(manager.config.Reconciliation)
+	channel.Handle(p2p.TopicSessionTrafficReport, func(c p2p.Context) error {
+		var report pb.SessionTrafficReport
+		if err := c.Request().UnmarshalProto(&report); err != nil {
+			return err
+		}
+		sess.logger().Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionTrafficReport, report.String())
+
+		if report.GetSessionID() != string(sess.ID) {
+			return c.Error(fmt.Errorf("unknown session %s", report.GetSessionID()))
+		}
+
+		sent, received := sess.getTraffic()
+		local := sent + received
+		remote := report.GetBytesSent() + report.GetBytesReceived()
+		if diverged, changed := reconciler.Reconcile(local, remote); changed {
+			if diverged {
+				sess.logger().Warn().Msgf("Session traffic diverges from consumer: counted %d bytes, consumer reported %d", local, remote)
+			} else {
+				sess.logger().Info().Msg("Session traffic matches consumer again")
+			}
+			manager.publisher.Publish(sevent.AppTopicTrafficDivergence, sevent.AppEventTrafficDivergence{
+				SessionID: string(sess.ID),
+				Local:     local,
+				Remote:    remote,
+				Diverged:  diverged,
+			})
+		}
+
+		return c.OkWithReply(p2p.ProtoMessage(&pb.SessionTrafficReport{
+			SessionID:     string(sess.ID),
+			BytesSent:     sent,
+			BytesReceived: received,
+		}))
+	})
+}
+
+// handleSpeedTest answers consumer speed test probes, replying with a payload of requested size.
+func (manager *SessionManager) handleSpeedTest(sess *Session, channel p2p.Channel) {
+	// Replies are zero filled, compressing them would defeat the measurement.
+	channel.DisableCompression(p2p.TopicSessionSpeedTest)
+	channel.Handle(p2p.TopicSessionSpeedTest, func(c p2p.Context) error {
+		var probe pb.SessionSpeedTest
+		if err := c.Request().UnmarshalProto(&probe); err != nil {
+			return err
+		}
+
+		if probe.GetSessionID() != string(sess.ID) {
+			return c.Error(fmt.Errorf("unknown session %s", probe.GetSessionID()))
+		}
+		if probe.GetSize() > maxSpeedTestSize.Bytes() {
+			return c.Error(fmt.Errorf("speed test size %d exceeds the limit of %s", probe.GetSize(), maxSpeedTestSize))
+		}
+
+		return c.OkWithReply(p2p.ProtoMessage(&pb.SessionSpeedTest{
+			SessionID: string(sess.ID),
+			Size:      probe.GetSize(),
+			Payload:   make([]byte, probe.GetSize()),
+		}))
+	})
+}
+
+// idleLoop closes the session when it transfers too little data during the idle timeout.
+func (manager *SessionManager) idleLoop(sess *Session) {
+	if !manager.config.Idle.Enabled() {
+		return
+	}
+
+	var transferred uint64
+	for {
+		select {
+		case <-sess.Done():
+			return
+		case <-time.After(manager.config.Idle.Timeout):
+			total := sess.getDataTransferred()
+			if manager.config.Idle.IsIdle(transferred, total) {
+				sess.logger().Info().Msgf("Less than %d bytes transferred in %s, closing idle session", manager.config.Idle.MinBytes, manager.config.Idle.Timeout)
+				sess.setTerminationReason(session.TerminationIdle)
+				sess.Close()
+				return
+			}
+			transferred = total
+		}
+	}
+}
+
+// stallLoop closes the session when consumer stops receiving messages, so it does not hold provider buffers.
+func (manager *SessionManager) stallLoop(sess *Session, channel p2p.Channel) {
+	select {
+	case <-sess.Done():
+	case <-channel.Stalled():
+		sess.logger().Warn().Msgf("Consumer stopped receiving messages with %d requests being handled, closing session", channel.ActiveHandlers())
+		manager.publisher.Publish(p2p.AppTopicPeerStalled, p2p.AppEventPeerStalled{
+			SessionID:      string(sess.ID),
+			ActiveHandlers: channel.ActiveHandlers(),
+		})
+		sess.setTerminationReason(session.TerminationSlowConsumer)
+		sess.Close()
+	}
+}
+
+// peerLost checks whether consumer was silent for longer than dead peer timeout
+// and publishes connectivity lost event if so.
+func (manager *SessionManager) peerLost(channel p2p.Channel, sess *Session) bool {
+	if manager.config.KeepAlive.DeadPeerTimeout <= 0 {
+		return false
+	}
+
+	lastActivity := channel.LastActivity()
+	if time.Since(lastActivity) < manager.config.KeepAlive.DeadPeerTimeout {
+		return false
+	}
+
+	sess.logger().Error().Msgf("No p2p traffic from consumer since %s, destroying session", lastActivity)
+	manager.publisher.Publish(p2p.AppTopicConnectivityLost, p2p.AppEventConnectivityLost{
+		SessionID:    string(sess.ID),
+		LastActivity: lastActivity,
+	})
+	return true
+}
+
 func (manager *SessionManager) sendKeepAlivePing(channel p2p.Channel, sessionID session.ID) error {
 	ctx, cancel := context.WithTimeout(context.Background(), manager.config.KeepAlive.SendTimeout)
 	defer cancel()
@@ -21,11 +21,13 @@ import (
 	"context"
 	"errors"
 	"net"
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/policy"
+	"github.com/mysteriumnetwork/node/core/qos"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
@@ -33,6 +35,7 @@ import (
 	"github.com/mysteriumnetwork/node/nat/event"
 	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/pb"
+	"github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/trace"
 	"github.com/stretchr/testify/assert"
@@ -61,6 +64,8 @@ var (
 type mockBalanceTracker struct {
 	paymentError      error
 	firstPaymentError error
+	// firstPaymentLate makes waiting for the first invoice time out.
+	firstPaymentLate bool
 }
 
 func (m mockBalanceTracker) Start() error {
@@ -71,16 +76,34 @@ func (m mockBalanceTracker) Stop() {
 
 }
 
-func (m mockBalanceTracker) WaitFirstInvoice(time.Duration) error {
+func (m mockBalanceTracker) WaitFirstInvoice(wait time.Duration) error {
+	if m.firstPaymentLate {
+		time.Sleep(wait)
+		return errors.New("failed waiting for first invoice")
+	}
 	return m.firstPaymentError
 }
 
-type mockP2PChannel struct{}
+type mockP2PChannel struct {
+	lastActivity time.Time
+	stalled      chan struct{}
+	sent         []string
+	lock         sync.Mutex
+}
 
-func (m *mockP2PChannel) Send(_ context.Context, _ string, _ *p2p.Message) (*p2p.Message, error) {
+func (m *mockP2PChannel) Send(_ context.Context, topic string, _ *p2p.Message) (*p2p.Message, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	m.sent = append(m.sent, topic)
 	return nil, nil
 }
 
+func (m *mockP2PChannel) sentTopics() []string {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.sent
+}
+
 func (m *mockP2PChannel) Handle(topic string, handler p2p.HandlerFunc) {
 }
 
@@ -88,6 +111,23 @@ func (m *mockP2PChannel) ServiceConn() *net.UDPConn { return nil }
 
 func (m *mockP2PChannel) Conn() *net.UDPConn { return nil }
 
+func (m *mockP2PChannel) RemoteAddr() *net.UDPAddr { return nil }
+
+func (m *mockP2PChannel) LastActivity() time.Time {
+	if m.lastActivity.IsZero() {
+		return time.Now()
+	}
+	return m.lastActivity
+}
+
+func (m *mockP2PChannel) Stalled() <-chan struct{} { return m.stalled }
+
+func (m *mockP2PChannel) ActiveHandlers() int { return 0 }
+
+func (m *mockP2PChannel) TraversalMethod() string { return p2p.TraversalDirect }
+
+func (m *mockP2PChannel) DisableCompression(topic string) {}
+
 func (m *mockP2PChannel) Close() error { return nil }
 
 func TestManager_Start_StoresSession(t *testing.T) {
@@ -191,6 +231,93 @@ func TestManager_Start_DisconnectsOnPaymentError(t *testing.T) {
 	}, time.Second, 10*time.Millisecond)
 }
 
+func TestManager_Start_ReportsTimedOutHandshakeStage(t *testing.T) {
+	// given
+	config := DefaultConfig()
+	config.Handshake = session.HandshakeBudget{Total: 50 * time.Millisecond}
+
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := NewSessionManager(
+		currentService,
+		sessionStore,
+		nil,
+		func(_, _ identity.Identity, _ common.Address, _ string) (PaymentEngine, error) {
+			return &mockBalanceTracker{firstPaymentLate: true}, nil
+		},
+		&MockNatEventTracker{},
+		publisher,
+		&mockP2PChannel{},
+		config,
+	)
+
+	// when
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:           consumerID.Address,
+			AccountantID: accountantID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+
+	// then
+	assert.EqualError(t, err, "first invoice was not paid: session handshake stage first_invoice timed out after 20ms")
+	assert.Equal(t, session.HandshakeFirstInvoice, session.TimedOutStage(err))
+	assert.Eventually(t, func() bool {
+		for _, e := range publisher.GetEventHistory() {
+			if closeEvent, ok := e.Event.(sessionEvent.AppEventSession); ok && closeEvent.Status == sessionEvent.RemovedStatus {
+				return closeEvent.Session.TimedOutStage == string(session.HandshakeFirstInvoice) &&
+					closeEvent.Session.TerminationReason == session.TerminationPaymentFailed
+			}
+		}
+		return false
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestManager_Start_RejectsSessionsOverStartLimit(t *testing.T) {
+	// given
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	limiter := NewStartLimiter(1, 10*time.Millisecond)
+	release, err := limiter.Acquire()
+	assert.NoError(t, err)
+
+	manager := NewSessionManager(
+		currentService,
+		sessionStore,
+		limiter,
+		func(_, _ identity.Identity, _ common.Address, _ string) (PaymentEngine, error) {
+			return &mockBalanceTracker{}, nil
+		},
+		&MockNatEventTracker{},
+		publisher,
+		&mockP2PChannel{},
+		DefaultConfig(),
+	)
+	request := &pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:           consumerID.Address,
+			AccountantID: accountantID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	}
+
+	// when
+	_, err = manager.Start(request)
+
+	// then
+	assert.Equal(t, ErrorTooManySessionStarts, err)
+	assert.Empty(t, sessionStore.GetAll())
+
+	// when
+	release()
+	_, err = manager.Start(request)
+
+	// then
+	assert.NoError(t, err)
+	assert.Len(t, sessionStore.GetAll(), 1)
+}
+
 func TestManager_Start_Second_Session_Destroy_Stale_Session(t *testing.T) {
 	sessionRequest := &pb.SessionRequest{
 		Consumer: &pb.ConsumerInfo{
@@ -220,6 +347,258 @@ func TestManager_Start_Second_Session_Destroy_Stale_Session(t *testing.T) {
 	}, time.Second, 10*time.Millisecond, "Waiting for session destroy")
 }
 
+func TestManager_Start_NegotiatesProtocol(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{})
+
+	res, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:           consumerID.Address,
+			AccountantID: accountantID.String(),
+		},
+		ProposalID:      int64(currentProposalID),
+		ProtocolVersion: session.ProtocolVersion + 1,
+		Capabilities:    []string{"unknown", session.CapabilityPaymentsV3},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, session.ProtocolVersion, res.ProtocolVersion)
+	assert.Equal(t, []string{session.CapabilityPaymentsV3}, res.Capabilities)
+
+	sess := sessionStore.GetAll()[0]
+	assert.Equal(t, session.ProtocolVersion, sess.ProtocolVersion)
+	assert.Equal(t, []string{session.CapabilityPaymentsV3}, sess.Capabilities)
+}
+
+func TestManager_Start_OffersSpeedTestOnlyWhenServed(t *testing.T) {
+	for serve, expected := range map[bool][]string{
+		false: {session.CapabilityPaymentsV3},
+		true:  {session.CapabilityPaymentsV3, session.CapabilitySpeedTest},
+	} {
+		publisher := mocks.NewEventBus()
+		sessionStore := NewSessionPool(publisher)
+		manager := newManager(currentService, sessionStore, publisher, &mockBalanceTracker{})
+		manager.config.ServeSpeedTests = serve
+
+		res, err := manager.Start(&pb.SessionRequest{
+			Consumer: &pb.ConsumerInfo{
+				Id:           consumerID.Address,
+				AccountantID: accountantID.String(),
+			},
+			ProposalID:   int64(currentProposalID),
+			Capabilities: []string{session.CapabilityPaymentsV3, session.CapabilitySpeedTest},
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, res.Capabilities, "serve %v", serve)
+	}
+}
+
+func TestManager_Start_NegotiatesQoSClass(t *testing.T) {
+	proposal := currentProposal
+	proposal.QoSClasses = []string{"standard", "premium"}
+	service := NewInstance(
+		identity.FromAddress(proposal.ProviderID),
+		proposal.ServiceType,
+		struct{}{},
+		proposal,
+		servicestate.Running,
+		&mockService{},
+		policy.NewRepository(),
+		&mockDiscovery{},
+	)
+	config := DefaultConfig()
+	config.QoSClasses = []qos.Class{{Name: "standard", LimitKbps: 5000}, {Name: "premium"}, {Name: "unadvertised"}}
+
+	for requested, expected := range map[string]string{
+		"":             "standard",
+		"premium":      "premium",
+		"unadvertised": "standard",
+	} {
+		publisher := mocks.NewEventBus()
+		sessionStore := NewSessionPool(publisher)
+		manager := NewSessionManager(
+			service,
+			sessionStore,
+			nil,
+			func(_, _ identity.Identity, _ common.Address, _ string) (PaymentEngine, error) {
+				return &mockBalanceTracker{}, nil
+			},
+			&MockNatEventTracker{},
+			publisher,
+			&mockP2PChannel{},
+			config,
+		)
+
+		_, err := manager.Start(&pb.SessionRequest{
+			Consumer: &pb.ConsumerInfo{
+				Id:           consumerID.Address,
+				AccountantID: accountantID.String(),
+			},
+			ProposalID: int64(currentProposalID),
+			QosClass:   requested,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, expected, sessionStore.GetAll()[0].QoSClass, "requested %q", requested)
+	}
+}
+
+func TestManager_Reconfigure(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	manager := newManager(currentService, NewSessionPool(publisher), publisher, &mockBalanceTracker{})
+	channel := &mockP2PChannel{}
+
+	err := manager.reconfigure(&Session{ID: "1"}, channel, "config")
+	assert.Equal(t, ErrorReconfigureNotSupported, err)
+	assert.Empty(t, channel.sentTopics())
+
+	err = manager.reconfigure(&Session{ID: "1", Capabilities: session.Capabilities()}, channel, "config")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{p2p.TopicSessionReconfigure}, channel.sentTopics())
+}
+
+func TestManager_ReconfigureLoop_ReportsResult(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	manager := newManager(currentService, NewSessionPool(publisher), publisher, &mockBalanceTracker{})
+	sess := &Session{ID: "1", done: make(chan struct{})}
+	defer sess.Close()
+
+	updates := make(chan ConfigUpdate)
+	go manager.reconfigureLoop(sess, &mockP2PChannel{}, updates)
+
+	result := make(chan error, 1)
+	updates <- ConfigUpdate{Config: "config", Result: func(err error) { result <- err }}
+	assert.Equal(t, ErrorReconfigureNotSupported, <-result)
+}
+
+func TestManager_Start_DestroysSessionOnLostPeer(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	config := DefaultConfig()
+	config.KeepAlive.SendInterval = 10 * time.Millisecond
+	config.KeepAlive.DeadPeerTimeout = time.Second
+	manager := NewSessionManager(
+		currentService,
+		sessionStore,
+		nil,
+		func(_, _ identity.Identity, _ common.Address, _ string) (PaymentEngine, error) {
+			return &mockBalanceTracker{}, nil
+		},
+		&MockNatEventTracker{},
+		publisher,
+		&mockP2PChannel{lastActivity: time.Now().Add(-time.Minute)},
+		config,
+	)
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:           consumerID.Address,
+			AccountantID: accountantID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.NoError(t, err)
+	session := sessionStore.GetAll()[0]
+
+	assert.Eventually(t, func() bool {
+		_, found := sessionStore.Find(session.ID)
+		return !found
+	}, time.Second, 10*time.Millisecond)
+
+	var lostEvent *p2p.AppEventConnectivityLost
+	for _, entry := range publisher.GetEventHistory() {
+		if entry.Topic == p2p.AppTopicConnectivityLost {
+			e := entry.Event.(p2p.AppEventConnectivityLost)
+			lostEvent = &e
+		}
+	}
+	if assert.NotNil(t, lostEvent) {
+		assert.Equal(t, string(session.ID), lostEvent.SessionID)
+	}
+}
+
+func TestManager_Start_ClosesIdleSession(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	config := DefaultConfig()
+	config.Idle = session.IdleConfig{Timeout: 10 * time.Millisecond, MinBytes: 100}
+	manager := NewSessionManager(
+		currentService,
+		sessionStore,
+		nil,
+		func(_, _ identity.Identity, _ common.Address, _ string) (PaymentEngine, error) {
+			return &mockBalanceTracker{}, nil
+		},
+		&MockNatEventTracker{},
+		publisher,
+		&mockP2PChannel{},
+		config,
+	)
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:           consumerID.Address,
+			AccountantID: accountantID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.NoError(t, err)
+	sess := sessionStore.GetAll()[0]
+	sessionStore.ConsumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: string(sess.ID), Up: 10, Down: 20})
+
+	assert.Eventually(t, func() bool {
+		_, found := sessionStore.Find(sess.ID)
+		return !found
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, session.TerminationIdle, sess.getTerminationReason())
+}
+
+func TestManager_Start_ClosesSessionOfStalledConsumer(t *testing.T) {
+	publisher := mocks.NewEventBus()
+	sessionStore := NewSessionPool(publisher)
+	channel := &mockP2PChannel{stalled: make(chan struct{})}
+	manager := NewSessionManager(
+		currentService,
+		sessionStore,
+		nil,
+		func(_, _ identity.Identity, _ common.Address, _ string) (PaymentEngine, error) {
+			return &mockBalanceTracker{}, nil
+		},
+		&MockNatEventTracker{},
+		publisher,
+		channel,
+		DefaultConfig(),
+	)
+
+	_, err := manager.Start(&pb.SessionRequest{
+		Consumer: &pb.ConsumerInfo{
+			Id:           consumerID.Address,
+			AccountantID: accountantID.String(),
+		},
+		ProposalID: int64(currentProposalID),
+	})
+	assert.NoError(t, err)
+	sess := sessionStore.GetAll()[0]
+
+	close(channel.stalled)
+
+	assert.Eventually(t, func() bool {
+		_, found := sessionStore.Find(sess.ID)
+		return !found
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, session.TerminationSlowConsumer, sess.getTerminationReason())
+
+	var stalledEvent *p2p.AppEventPeerStalled
+	for _, e := range publisher.GetEventHistory() {
+		if e.Topic == p2p.AppTopicPeerStalled {
+			event := e.Event.(p2p.AppEventPeerStalled)
+			stalledEvent = &event
+		}
+	}
+	if assert.NotNil(t, stalledEvent) {
+		assert.Equal(t, string(sess.ID), stalledEvent.SessionID)
+	}
+}
+
 func TestManager_Start_RejectsUnknownProposal(t *testing.T) {
 	publisher := mocks.NewEventBus()
 	sessionStore := NewSessionPool(mocks.NewEventBus())
@@ -314,6 +693,7 @@ func newManager(service *Instance, sessions *SessionPool, publisher publisher, p
 	return NewSessionManager(
 		service,
 		sessions,
+		nil,
 		func(_, _ identity.Identity, _ common.Address, _ string) (PaymentEngine, error) {
 			return paymentEngine, nil
 		},
@@ -19,6 +19,7 @@ package service
 
 import (
 	"sync"
+	"time"
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session"
@@ -108,16 +109,43 @@ func (sp *SessionPool) Remove(id session.ID) {
 
 	if instance, found := sp.sessions[id]; found {
 		delete(sp.sessions, id)
+		instance.setTerminationReason(session.TerminationRequested)
 		go sp.publisher.Publish(event.AppTopicSession, instance.toEvent(event.RemovedStatus))
 	}
 }
 
+// ConsumeDataTransferredEvent records session traffic, it is used to detect idle sessions.
+func (sp *SessionPool) ConsumeDataTransferredEvent(e event.AppEventDataTransferred) {
+	if instance, found := sp.Find(session.ID(e.ID)); found {
+		instance.setDataTransferred(e.Up, e.Down)
+	}
+}
+
 // RemoveForService removes all sessions which belong to given service
 func (sp *SessionPool) RemoveForService(serviceID string) {
 	sessions := sp.GetAll()
-	for _, session := range sessions {
-		if session.ServiceID == serviceID {
-			sp.Remove(session.ID)
+	for _, s := range sessions {
+		if s.ServiceID == serviceID {
+			s.setTerminationReason(session.TerminationServiceStopped)
+			sp.Remove(s.ID)
 		}
 	}
 }
+
+// SuspendForService notifies consumers and closes active sessions of the given service, the service itself keeps running.
+func (sp *SessionPool) SuspendForService(serviceID string) {
+	for _, s := range sp.GetAll() {
+		if s.ServiceID != serviceID {
+			continue
+		}
+		select {
+		case <-s.Done():
+			continue
+		default:
+		}
+
+		notifyGoingAway(s, time.Now(), "service is paused")
+		s.setTerminationReason(session.TerminationServicePaused)
+		s.Close()
+	}
+}
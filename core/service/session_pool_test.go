@@ -24,6 +24,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/p2p"
 	"github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/stretchr/testify/assert"
@@ -118,6 +119,16 @@ func TestSessionPool_Remove(t *testing.T) {
 	assert.Len(t, pool.sessions, 0)
 }
 
+func TestSessionPool_ConsumeDataTransferredEvent(t *testing.T) {
+	instance := &Session{ID: session.ID("traffic-id")}
+	pool := mockPool(mocks.NewEventBus(), instance)
+
+	pool.ConsumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: "traffic-id", Up: 10, Down: 20})
+	pool.ConsumeDataTransferredEvent(sessionEvent.AppEventDataTransferred{ID: "unknown-id", Up: 100, Down: 200})
+
+	assert.Equal(t, uint64(30), instance.getDataTransferred())
+}
+
 func TestSessionPool_RemoveNonExisting(t *testing.T) {
 	pool := &SessionPool{
 		sessions:  map[session.ID]*Session{},
@@ -163,6 +174,32 @@ func TestSessionPool_RemoveForService_PublishesEvents(t *testing.T) {
 	assert.Eventually(t, lastEventMatches(mp, sessionExisting.ID, sessionEvent.RemovedStatus), 2*time.Second, 10*time.Millisecond)
 }
 
+func TestSessionPool_SuspendForService(t *testing.T) {
+	// given
+	channel := &mockP2PChannel{}
+	suspended := &Session{ID: "suspended-id", ServiceID: "paused-service", channel: channel, done: make(chan struct{})}
+	other := &Session{ID: "other-id", ServiceID: "other-service", done: make(chan struct{})}
+	pool := mockPool(mocks.NewEventBus(), suspended)
+	pool.Add(other)
+
+	// when
+	pool.SuspendForService("paused-service")
+
+	// then
+	assert.Equal(t, []string{p2p.TopicSessionGoingAway}, channel.sentTopics())
+	assert.Equal(t, session.TerminationServicePaused, suspended.getTerminationReason())
+	select {
+	case <-suspended.Done():
+	default:
+		t.Error("expected session of paused service to be closed")
+	}
+	select {
+	case <-other.Done():
+		t.Error("expected session of other service to keep running")
+	default:
+	}
+}
+
 func mockPool(publisher publisher, sessionInstance *Session) *SessionPool {
 	return &SessionPool{
 		sessions:  map[session.ID]*Session{sessionInstance.ID: sessionInstance},
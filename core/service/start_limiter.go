@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrorTooManySessionStarts returned when session start waited in queue for too long
+// because provider was busy handling other session starts.
+var ErrorTooManySessionStarts = errors.New("too many sessions are being started")
+
+// StartLimiter limits the number of session handshakes provider handles at once.
+// Session starts over the limit are queued until some start finishes or the queue timeout passes.
+// Established sessions do not hold the limit.
+type StartLimiter struct {
+	slots        chan struct{}
+	queueTimeout time.Duration
+}
+
+// NewStartLimiter returns limiter of concurrent session starts, starts are not limited if limit is 0.
+func NewStartLimiter(limit int, queueTimeout time.Duration) *StartLimiter {
+	limiter := &StartLimiter{queueTimeout: queueTimeout}
+	if limit > 0 {
+		limiter.slots = make(chan struct{}, limit)
+	}
+	return limiter
+}
+
+// Acquire waits for a free slot to start the session, the returned func frees the slot.
+func (l *StartLimiter) Acquire() (release func(), err error) {
+	if l == nil || l.slots == nil {
+		return func() {}, nil
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	default:
+	}
+
+	timer := time.NewTimer(l.queueTimeout)
+	defer timer.Stop()
+
+	select {
+	case l.slots <- struct{}{}:
+		return l.release, nil
+	case <-timer.C:
+		return nil, ErrorTooManySessionStarts
+	}
+}
+
+func (l *StartLimiter) release() {
+	<-l.slots
+}
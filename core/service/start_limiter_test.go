@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStartLimiter_QueuesStartsOverLimit(t *testing.T) {
+	// given
+	limiter := NewStartLimiter(1, time.Second)
+	release, err := limiter.Acquire()
+	assert.NoError(t, err)
+
+	// when
+	acquired := make(chan error)
+	go func() {
+		_, err := limiter.Acquire()
+		acquired <- err
+	}()
+
+	// then
+	select {
+	case <-acquired:
+		t.Fatal("start over the limit should wait in queue")
+	case <-time.After(20 * time.Millisecond):
+	}
+	release()
+	assert.NoError(t, <-acquired)
+}
+
+func TestStartLimiter_RejectsAfterQueueTimeout(t *testing.T) {
+	// given
+	limiter := NewStartLimiter(1, 10*time.Millisecond)
+	_, err := limiter.Acquire()
+	assert.NoError(t, err)
+
+	// when
+	_, err = limiter.Acquire()
+
+	// then
+	assert.Equal(t, ErrorTooManySessionStarts, err)
+}
+
+func TestStartLimiter_Unlimited(t *testing.T) {
+	for _, limiter := range []*StartLimiter{nil, NewStartLimiter(0, 0)} {
+		for i := 0; i < 100; i++ {
+			_, err := limiter.Acquire()
+			assert.NoError(t, err)
+		}
+	}
+}
@@ -71,6 +71,40 @@ func (mds *mockDiscovery) Wait() {
 	mds.wg.Wait()
 }
 
+type mockServiceDefinition struct {
+	Location market.Location
+}
+
+func (d mockServiceDefinition) GetLocation() market.Location {
+	return d.Location
+}
+
+func (d mockServiceDefinition) WithLocation(location market.Location) market.ServiceDefinition {
+	d.Location = location
+	return d
+}
+
+// proposalsDiscovery records proposals it was started with.
+type proposalsDiscovery struct {
+	mockDiscovery
+	lock      sync.Mutex
+	proposals []market.ServiceProposal
+}
+
+func (d *proposalsDiscovery) Start(ownIdentity identity.Identity, proposal market.ServiceProposal) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	d.proposals = append(d.proposals, proposal)
+}
+
+func (d *proposalsDiscovery) Stop() {}
+
+func (d *proposalsDiscovery) started() []market.ServiceProposal {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return append([]market.ServiceProposal(nil), d.proposals...)
+}
+
 // MockDiscoveryFactoryFunc returns a discovery factory which in turn returns the discovery service.
 func MockDiscoveryFactoryFunc(ds Discovery) DiscoveryFactory {
 	return func() Discovery {
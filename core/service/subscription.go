@@ -28,7 +28,7 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-func subscribeSessionCreate(mng *SessionManager, ch p2p.Channel) {
+func subscribeSessionCreate(mng *SessionManager, ch p2p.Channel, statusStorage connectivity.StatusStorage) {
 	ch.Handle(p2p.TopicSessionCreate, func(c p2p.Context) error {
 		var request pb.SessionRequest
 		if err := c.Request().UnmarshalProto(&request); err != nil {
@@ -36,6 +36,22 @@ func subscribeSessionCreate(mng *SessionManager, ch p2p.Channel) {
 		}
 		log.Debug().Msgf("Received P2P message for %q: %s", p2p.TopicSessionCreate, request.String())
 
+		if mng.service.Draining() {
+			return c.Error(ErrDraining)
+		}
+		if mng.service.Paused() {
+			return c.Error(ErrPaused)
+		}
+		if err := mng.service.allowsConsumer(ch.RemoteAddr()); err != nil {
+			statusStorage.AddStatusEntry(connectivity.StatusEntry{
+				PeerID:       identity.FromAddress(request.GetConsumer().GetId()),
+				StatusCode:   connectivity.StatusSessionPolicyDenied,
+				Message:      err.Error(),
+				CreatedAtUTC: time.Now().UTC(),
+			})
+			return c.Error(&p2p.PublicError{Code: uint64(connectivity.StatusSessionPolicyDenied), Message: err.Error()})
+		}
+
 		response, err := mng.Start(&request)
 		if err != nil {
 			return fmt.Errorf("cannot start session: %s: %w", response.ID, err)
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/rs/zerolog/log"
+)
+
+// RestartPolicy defines how crashed services are restarted.
+type RestartPolicy struct {
+	// MaxRestarts caps consecutive restart attempts, zero disables restarts.
+	MaxRestarts int
+	// InitialBackoff is a delay before the first restart, doubled for every next attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the delay between restarts.
+	MaxBackoff time.Duration
+	// StablePeriod is how long service has to serve to reset consecutive restart attempts, zero never resets them.
+	StablePeriod time.Duration
+}
+
+// DefaultRestartPolicy returns restart policy used by the service manager.
+func DefaultRestartPolicy() RestartPolicy {
+	return RestartPolicy{
+		MaxRestarts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		StablePeriod:   5 * time.Minute,
+	}
+}
+
+func (p RestartPolicy) backoff(attempt int) time.Duration {
+	delay := p.InitialBackoff
+	for i := 1; i < attempt && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 && delay > p.MaxBackoff {
+		delay = p.MaxBackoff
+	}
+	return delay
+}
+
+// supervise serves the instance and restarts the service with exponential backoff when it crashes.
+// It returns once the instance is stopped, service finishes gracefully or restart attempts are exhausted,
+// in the latter case instance is left in the Failed state.
+func (manager *Manager) supervise(instance *Instance) {
+	attempts := 0
+	for {
+		instance.setState(servicestate.Running)
+
+		started := time.Now()
+		serveErr := serveLabeled(instance, func() error {
+			return instance.Service().Serve(instance)
+		})
+		if instance.isStopped() {
+			return
+		}
+		if serveErr == nil {
+			log.Info().Msgf("Service finished: %s", instance.ID)
+			return
+		}
+		log.Error().Err(serveErr).Msgf("Service serve failed: %s", instance.ID)
+
+		if stable := manager.restartPolicy.StablePeriod; stable > 0 && time.Since(started) >= stable {
+			attempts = 0
+		}
+		if attempts >= manager.restartPolicy.MaxRestarts {
+			log.Error().Msgf("Service %s crashed %d times in a row, giving up", instance.ID, attempts+1)
+			instance.setState(servicestate.Failed)
+			return
+		}
+		attempts++
+
+		instance.setRestarting()
+		delay := manager.restartPolicy.backoff(attempts)
+		log.Info().Msgf("Restarting service %s in %s (attempt %d/%d)", instance.ID, delay, attempts, manager.restartPolicy.MaxRestarts)
+		select {
+		case <-time.After(delay):
+		case <-instance.stopChan():
+			return
+		}
+
+		if err := manager.restart(instance); err != nil {
+			log.Error().Err(err).Msgf("Could not restart service: %s", instance.ID)
+			instance.setState(servicestate.Failed)
+			return
+		}
+	}
+}
+
+func (manager *Manager) restart(instance *Instance) error {
+	if err := instance.Service().Stop(); err != nil {
+		log.Warn().Err(err).Msgf("Could not clean up crashed service: %s", instance.ID)
+	}
+
+	service, _, err := manager.serviceRegistry.Create(instance.Type, instance.Options)
+	if err != nil {
+		return err
+	}
+	if !instance.replaceService(service) {
+		return service.Stop()
+	}
+	return nil
+}
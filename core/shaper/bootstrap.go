@@ -21,6 +21,8 @@ package shaper
 type Shaper interface {
 	// Start applies shaping configuration on the specified interface and then continuously ensures it.
 	Start(interfaceName string) error
+	// Limit limits bandwidth of the specified interface, it takes precedence over the node wide configuration.
+	Limit(interfaceName string, limitKbps int) error
 	// Clear clears shaping rules.
 	Clear(interfaceName string)
 }
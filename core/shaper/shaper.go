@@ -21,6 +21,7 @@ package shaper
 
 import (
 	"github.com/mysteriumnetwork/node/config"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
@@ -40,6 +41,11 @@ func (noopShaper) Start(_ string) error {
 	return nil
 }
 
+// Limit noop
+func (noopShaper) Limit(_ string, _ int) error {
+	return errors.New("traffic shaping is only supported under linux")
+}
+
 // Clear noop
 func (noopShaper) Clear(_ string) {
 }
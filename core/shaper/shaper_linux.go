@@ -18,6 +18,8 @@
 package shaper
 
 import (
+	"sync"
+
 	"github.com/mysteriumnetwork/go-wondershaper/wondershaper"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/pkg/errors"
@@ -30,6 +32,9 @@ type linuxShaper struct {
 	ws          *wondershaper.Shaper
 	listener    eventListener
 	listenTopic string
+
+	limitLock        sync.Mutex
+	sessionLimitKbps int
 }
 
 func create(listener eventListener) *linuxShaper {
@@ -46,21 +51,7 @@ func create(listener eventListener) *linuxShaper {
 // Start applies shaping configuration on the specified interface and then continuously ensures it.
 func (s *linuxShaper) Start(interfaceName string) error {
 	applyLimits := func() error {
-		s.ws.Clear(interfaceName)
-
-		if config.GetBool(config.FlagShaperEnabled) {
-			err := s.ws.LimitDownlink(interfaceName, limitKbps)
-			if err != nil {
-				log.Error().Err(err).Msg("Could not limit download speed")
-				return err
-			}
-			err = s.ws.LimitUplink(interfaceName, limitKbps)
-			if err != nil {
-				log.Error().Err(err).Msg("Could not limit upload speed")
-				return err
-			}
-		}
-		return nil
+		return s.applyLimits(interfaceName)
 	}
 
 	err := s.listener.SubscribeAsync(s.listenTopic, applyLimits)
@@ -71,7 +62,50 @@ func (s *linuxShaper) Start(interfaceName string) error {
 	return applyLimits()
 }
 
+// Limit limits bandwidth of the specified interface, it takes precedence over the node wide configuration.
+func (s *linuxShaper) Limit(interfaceName string, limitKbps int) error {
+	s.limitLock.Lock()
+	s.sessionLimitKbps = limitKbps
+	s.limitLock.Unlock()
+
+	return s.applyLimits(interfaceName)
+}
+
 // Clear clears shaping rules.
 func (s *linuxShaper) Clear(interfaceName string) {
 	s.ws.Clear(interfaceName)
 }
+
+func (s *linuxShaper) applyLimits(interfaceName string) error {
+	s.ws.Clear(interfaceName)
+
+	limit := s.limit()
+	if limit == 0 {
+		return nil
+	}
+
+	err := s.ws.LimitDownlink(interfaceName, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Could not limit download speed")
+		return err
+	}
+	err = s.ws.LimitUplink(interfaceName, limit)
+	if err != nil {
+		log.Error().Err(err).Msg("Could not limit upload speed")
+		return err
+	}
+	return nil
+}
+
+func (s *linuxShaper) limit() int {
+	s.limitLock.Lock()
+	defer s.limitLock.Unlock()
+
+	if s.sessionLimitKbps > 0 {
+		return s.sessionLimitKbps
+	}
+	if config.GetBool(config.FlagShaperEnabled) {
+		return limitKbps
+	}
+	return 0
+}
@@ -22,24 +22,32 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/schedule"
 	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/consumer/traffic"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/money"
+	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
 // AppTopicState is the topic that we use to announce state changes to via the event bus
-const AppTopicState = "State change"
+const AppTopicState = "state.changed"
 
 // State represents the node state at the current moment. It's a read only object, used only to display data.
 type State struct {
 	NATStatus  contract.NATStatusDTO
+	Broker     contract.BrokerStatusDTO
 	Services   []contract.ServiceInfoDTO
 	Sessions   []session.History
 	Connection Connection
+	// Connections holds simultaneous consumer connections by ID, Connection is the default one of them.
+	Connections map[string]Connection
+	// Schedule holds schedule rules of the main connection and the state of connection made by them.
+	Schedule   schedule.Status
 	Identities []Identity
 }
 
@@ -48,9 +56,11 @@ type Identity struct {
 	Address            string
 	RegistrationStatus registry.RegistrationStatus
 	ChannelAddress     common.Address
-	Balance            uint64
-	Earnings           uint64
-	EarningsTotal      uint64
+	Balance            money.Tokens
+	Earnings           money.Tokens
+	EarningsTotal      money.Tokens
+	// EarningsPerAccountant holds earnings of each accountant identity works with, Earnings and EarningsTotal are their sums.
+	EarningsPerAccountant map[common.Address]pingpongEvent.Earnings
 }
 
 // Connection represents consumer connection state.
@@ -59,6 +69,8 @@ type Connection struct {
 	Statistics connection.Statistics
 	Throughput bandwidth.Throughput
 	Invoice    crypto.Invoice
+	// Traffic is set for connections made with traffic breakdown enabled.
+	Traffic traffic.Breakdown
 }
 
 func (c Connection) String() string {
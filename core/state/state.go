@@ -19,30 +19,70 @@ package state
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/communication/nats"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/schedule"
 	"github.com/mysteriumnetwork/node/consumer/session"
 	"github.com/mysteriumnetwork/node/core/connection"
-	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/core/state/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/nat"
 	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	nodeSession "github.com/mysteriumnetwork/node/session"
 	sevent "github.com/mysteriumnetwork/node/session/event"
 	pingpongEvent "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
-// DefaultDebounceDuration is the default time interval suggested for debouncing
-const DefaultDebounceDuration = time.Millisecond * 200
+// DebounceConfig holds debouncing intervals of state updates per event class.
+type DebounceConfig struct {
+	// Statistics debounces high frequency traffic, throughput, spending and earnings updates.
+	Statistics time.Duration
+	// Sessions debounces service state and resource usage updates.
+	Sessions time.Duration
+	// NAT debounces NAT status updates.
+	NAT time.Duration
+	// Announce debounces publishing of the changed state.
+	Announce time.Duration
+}
+
+// DefaultDebounceConfig returns the default debouncing intervals.
+func DefaultDebounceConfig() DebounceConfig {
+	return DebounceConfig{
+		Statistics: time.Second,
+		Sessions:   time.Millisecond * 200,
+		NAT:        time.Millisecond * 200,
+		Announce:   time.Millisecond * 200,
+	}
+}
+
+// LowResourceDebounceConfig returns the debouncing intervals for resource-constrained devices,
+// it trades state freshness for fewer state recalculations and announcements.
+func LowResourceDebounceConfig() DebounceConfig {
+	return DebounceConfig{
+		Statistics: time.Second * 5,
+		Sessions:   time.Second * 2,
+		NAT:        time.Second * 2,
+		Announce:   time.Second * 2,
+	}
+}
+
+// UniformDebounceConfig returns config debouncing all event classes by the same interval.
+func UniformDebounceConfig(d time.Duration) DebounceConfig {
+	return DebounceConfig{Statistics: d, Sessions: d, NAT: d, Announce: d}
+}
 
 type natStatusProvider interface {
 	Status() nat.Status
@@ -53,10 +93,6 @@ type publisher interface {
 	Publish(topic string, data interface{})
 }
 
-type serviceLister interface {
-	List() map[service.ID]*service.Instance
-}
-
 type identityProvider interface {
 	GetIdentities() []identity.Identity
 }
@@ -66,19 +102,31 @@ type channelAddressCalculator interface {
 }
 
 type balanceProvider interface {
-	GetBalance(id identity.Identity) uint64
+	GetBalance(id identity.Identity) money.Tokens
 }
 
 type earningsProvider interface {
-	GetEarnings(id identity.Identity) pingpongEvent.Earnings
+	GetEarningsPerAccountant(id identity.Identity) map[common.Address]pingpongEvent.Earnings
+}
+
+type sessionStorage interface {
+	Query(query *session.Query) error
 }
 
 // Keeper keeps track of state through eventual consistency.
 // This should become the de-facto place to get your info about node.
+//
+// Reducers modify the working state under the lock and publish an immutable snapshot of it afterwards,
+// so readers never block on reducers. Sections of the state are copied on write, hence a snapshot
+// shares unchanged sections with the working state and must never be modified in place.
 type Keeper struct {
-	state *stateEvent.State
-	lock  sync.RWMutex
-	deps  KeeperDeps
+	state    *stateEvent.State
+	snapshot atomic.Value
+	revision uint64
+	lock     sync.Mutex
+	deps     KeeperDeps
+
+	statisticsHistory *statisticsHistory
 
 	// provider
 	consumeServiceStateEvent             func(e interface{})
@@ -89,6 +137,7 @@ type Keeper struct {
 	consumeConnectionStatisticsEvent func(interface{})
 	consumeConnectionThroughputEvent func(interface{})
 	consumeConnectionSpendingEvent   func(interface{})
+	consumeConnectionTrafficEvent    func(interface{})
 
 	announceStateChanges func(e interface{})
 }
@@ -103,37 +152,47 @@ type KeeperDeps struct {
 	IdentityChannelCalculator channelAddressCalculator
 	BalanceProvider           balanceProvider
 	EarningsProvider          earningsProvider
+	SessionStorage            sessionStorage
 }
 
 // NewKeeper returns a new instance of the keeper.
-func NewKeeper(deps KeeperDeps, debounceDuration time.Duration) *Keeper {
+func NewKeeper(deps KeeperDeps, debouncing DebounceConfig) *Keeper {
 	k := &Keeper{
 		state: &stateEvent.State{
 			NATStatus: contract.NATStatusDTO{
 				Status: "not_finished",
 			},
+			// Node bootstrap fails without the broker connection, so it is established by now.
+			Broker: contract.BrokerStatusDTO{
+				Status: string(nats.BrokerStatusConnected),
+			},
 			Sessions: make([]session.History, 0),
 			Connection: stateEvent.Connection{
 				Session: connection.Status{
-					State: connection.NotConnected,
+					ConnectionID: connection.DefaultConnectionID,
+					State:        connection.NotConnected,
 				},
 			},
 		},
-		deps: deps,
+		deps:              deps,
+		statisticsHistory: newStatisticsHistory(connectionStatisticsHistorySize),
 	}
+	k.state.Connections = map[string]stateEvent.Connection{connection.DefaultConnectionID: k.state.Connection}
 	k.state.Identities = k.fetchIdentities()
+	k.publishState()
 
 	// provider
-	k.consumeServiceStateEvent = debounce(k.updateServiceState, debounceDuration)
-	k.consumeNATEvent = debounce(k.updateNatStatus, debounceDuration)
-	k.consumeServiceSessionStatisticsEvent = debounce(k.updateSessionStats, debounceDuration)
-	k.consumeServiceSessionEarningsEvent = debounce(k.updateSessionEarnings, debounceDuration)
+	k.consumeServiceStateEvent = debounce(k.updateServiceState, debouncing.Sessions)
+	k.consumeNATEvent = debounce(k.updateNatStatus, debouncing.NAT)
+	k.consumeServiceSessionStatisticsEvent = debounce(k.updateSessionStats, debouncing.Statistics)
+	k.consumeServiceSessionEarningsEvent = debounce(k.updateSessionEarnings, debouncing.Statistics)
 
 	// consumer
-	k.consumeConnectionStatisticsEvent = debounce(k.updateConnectionStats, debounceDuration)
-	k.consumeConnectionThroughputEvent = debounce(k.updateConnectionThroughput, debounceDuration)
-	k.consumeConnectionSpendingEvent = debounce(k.updateConnectionSpending, debounceDuration)
-	k.announceStateChanges = debounce(k.announceState, debounceDuration)
+	k.consumeConnectionStatisticsEvent = debouncePerConnection(k.updateConnectionStats, debouncing.Statistics, statisticsConnectionID)
+	k.consumeConnectionThroughputEvent = debouncePerConnection(k.updateConnectionThroughput, debouncing.Statistics, throughputConnectionID)
+	k.consumeConnectionSpendingEvent = debouncePerConnection(k.updateConnectionSpending, debouncing.Statistics, k.spendingConnectionID)
+	k.consumeConnectionTrafficEvent = debouncePerConnection(k.updateConnectionTraffic, debouncing.Statistics, trafficConnectionID)
+	k.announceStateChanges = debounce(k.announceState, debouncing.Announce)
 
 	return k
 }
@@ -153,14 +212,16 @@ func (k *Keeper) fetchIdentities() []stateEvent.Identity {
 			log.Warn().Err(err).Msgf("Could not calculate channel address for %s", id.Address)
 		}
 
-		earnings := k.deps.EarningsProvider.GetEarnings(id)
+		earningsPerAccountant := k.deps.EarningsProvider.GetEarningsPerAccountant(id)
+		earnings := totalEarnings(earningsPerAccountant)
 		stateIdentity := event.Identity{
-			Address:            id.Address,
-			RegistrationStatus: status,
-			ChannelAddress:     channelAddress,
-			Balance:            k.deps.BalanceProvider.GetBalance(id),
-			Earnings:           earnings.UnsettledBalance,
-			EarningsTotal:      earnings.LifetimeBalance,
+			Address:               id.Address,
+			RegistrationStatus:    status,
+			ChannelAddress:        channelAddress,
+			Balance:               k.deps.BalanceProvider.GetBalance(id),
+			Earnings:              earnings.UnsettledBalance,
+			EarningsTotal:         earnings.LifetimeBalance,
+			EarningsPerAccountant: earningsPerAccountant,
 		}
 		identities[idx] = stateIdentity
 	}
@@ -172,6 +233,9 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(servicestate.AppTopicServiceStatus, k.consumeServiceStateEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(servicestate.AppTopicServiceResources, k.consumeServiceStateEvent); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(sevent.AppTopicSession, k.consumeServiceSessionEvent); err != nil {
 		return err
 	}
@@ -184,15 +248,30 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 	if err := bus.SubscribeAsync(natEvent.AppTopicTraversal, k.consumeNATEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(mapping.AppTopicPortMappingHealth, k.consumePortMappingHealthEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(nats.AppTopicBrokerStatus, k.consumeBrokerStatusEvent); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(schedule.AppTopicScheduleStatus, k.consumeScheduleStatusEvent); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(connection.AppTopicConnectionState, k.consumeConnectionStateEvent); err != nil {
 		return err
 	}
 	if err := bus.SubscribeAsync(connection.AppTopicConnectionStatistics, k.consumeConnectionStatisticsEvent); err != nil {
 		return err
 	}
+	if err := bus.Subscribe(connection.AppTopicConnectionStatistics, k.recordConnectionStatistics); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(bandwidth.AppTopicConnectionThroughput, k.consumeConnectionThroughputEvent); err != nil {
 		return err
 	}
+	if err := bus.SubscribeAsync(connection.AppTopicConnectionTraffic, k.consumeConnectionTrafficEvent); err != nil {
+		return err
+	}
 	if err := bus.SubscribeAsync(pingpongEvent.AppTopicInvoicePaid, k.consumeConnectionSpendingEvent); err != nil {
 		return err
 	}
@@ -212,42 +291,25 @@ func (k *Keeper) Subscribe(bus eventbus.Subscriber) error {
 }
 
 func (k *Keeper) announceState(_ interface{}) {
-	k.lock.Lock()
-	defer k.lock.Unlock()
-	k.deps.Publisher.Publish(stateEvent.AppTopicState, *k.state)
+	k.deps.Publisher.Publish(stateEvent.AppTopicState, k.GetState())
+}
+
+// publishState swaps the snapshot returned to readers with a copy of the working state.
+// It must be called with the lock held after every change of the working state.
+func (k *Keeper) publishState() {
+	snapshot := *k.state
+	k.snapshot.Store(&snapshot)
+	atomic.AddUint64(&k.revision, 1)
 }
 
 func (k *Keeper) updateServiceState(_ interface{}) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
 	k.updateServices()
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
-func (k *Keeper) updateServices() {
-	services := k.deps.ServiceLister.List()
-	result := make([]contract.ServiceInfoDTO, len(services))
-
-	i := 0
-	for key, v := range services {
-		// merge in the connection statistics
-		match, _ := k.getServiceByID(string(key))
-
-		result[i] = contract.ServiceInfoDTO{
-			ID:                   string(key),
-			ProviderID:           v.ProviderID.Address,
-			Type:                 v.Type,
-			Options:              v.Options,
-			Status:               string(v.State()),
-			Proposal:             contract.NewProposalDTO(v.Proposal),
-			ConnectionStatistics: match.ConnectionStatistics,
-		}
-		i++
-	}
-
-	k.state.Services = result
-}
-
 func (k *Keeper) getServiceByID(id string) (se contract.ServiceInfoDTO, found bool) {
 	for i := range k.state.Services {
 		if k.state.Services[i].ID == id {
@@ -271,11 +333,62 @@ func (k *Keeper) updateNatStatus(e interface{}) {
 
 	k.deps.NATStatusProvider.ConsumeNATEvent(event)
 	status := k.deps.NATStatusProvider.Status()
-	k.state.NATStatus = contract.NATStatusDTO{Status: status.Status}
+	k.state.NATStatus = contract.NATStatusDTO{Status: status.Status, PortMappings: k.state.NATStatus.PortMappings}
 	if status.Error != nil {
 		k.state.NATStatus.Error = status.Error.Error()
 	}
 
+	k.publishState()
+	go k.announceStateChanges(nil)
+}
+
+func (k *Keeper) consumePortMappingHealthEvent(e mapping.AppEventPortMappingHealth) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	mappings := make([]contract.PortMappingDTO, 0, len(k.state.NATStatus.PortMappings)+1)
+	for _, m := range k.state.NATStatus.PortMappings {
+		if m.Protocol != e.Protocol || m.Port != e.Port {
+			mappings = append(mappings, m)
+		}
+	}
+	if e.Status != mapping.LeaseReleased {
+		m := contract.PortMappingDTO{
+			Protocol: e.Protocol,
+			Port:     e.Port,
+			Status:   string(e.Status),
+		}
+		if e.Error != nil {
+			m.Error = e.Error.Error()
+		}
+		mappings = append(mappings, m)
+	}
+	k.state.NATStatus.PortMappings = mappings
+
+	k.publishState()
+	go k.announceStateChanges(nil)
+}
+
+func (k *Keeper) consumeBrokerStatusEvent(e nats.AppEventBrokerStatus) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.state.Broker = contract.BrokerStatusDTO{
+		Status: string(e.Status),
+		Server: e.Server,
+	}
+
+	k.publishState()
+	go k.announceStateChanges(nil)
+}
+
+func (k *Keeper) consumeScheduleStatusEvent(e schedule.AppEventScheduleStatus) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	k.state.Schedule = e.Status
+
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
@@ -294,11 +407,14 @@ func (k *Keeper) consumeServiceSessionEvent(e sevent.AppEventSession) {
 		k.incrementConnectCount(e.Service.ID, true)
 	}
 
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
 func (k *Keeper) addSession(e sevent.AppEventSession) {
-	k.state.Sessions = append(k.state.Sessions, session.History{
+	sessions := make([]session.History, len(k.state.Sessions), len(k.state.Sessions)+1)
+	copy(sessions, k.state.Sessions)
+	k.state.Sessions = append(sessions, session.History{
 		SessionID:       nodeSession.ID(e.Session.ID),
 		Direction:       session.DirectionProvided,
 		ConsumerID:      e.Session.ConsumerID,
@@ -312,17 +428,25 @@ func (k *Keeper) addSession(e sevent.AppEventSession) {
 }
 
 func (k *Keeper) removeSession(e sevent.AppEventSession) {
-	found := false
+	i := k.sessionIndex(e.Session.ID)
+	if i < 0 {
+		log.Warn().Msgf("Couldn't find a matching session for session remove: %s", e.Session.ID)
+		return
+	}
+
+	sessions := make([]session.History, 0, len(k.state.Sessions)-1)
+	sessions = append(sessions, k.state.Sessions[:i]...)
+	k.state.Sessions = append(sessions, k.state.Sessions[i+1:]...)
+}
+
+func (k *Keeper) sessionIndex(id string) int {
+	idx := -1
 	for i := range k.state.Sessions {
-		if string(k.state.Sessions[i].SessionID) == e.Session.ID {
-			k.state.Sessions = append(k.state.Sessions[:i], k.state.Sessions[i+1:]...)
-			found = true
-			break
+		if string(k.state.Sessions[i].SessionID) == id {
+			idx = i
 		}
 	}
-	if !found {
-		log.Warn().Msgf("Couldn't find a matching session for session remove: %s", e.Session.ID)
-	}
+	return idx
 }
 
 // updates the data transfer info on the session
@@ -336,22 +460,20 @@ func (k *Keeper) updateSessionStats(e interface{}) {
 		return
 	}
 
-	var session *session.History
-	for i := range k.state.Sessions {
-		if string(k.state.Sessions[i].SessionID) == evt.ID {
-			session = &k.state.Sessions[i]
-		}
-	}
-	if session == nil {
+	i := k.sessionIndex(evt.ID)
+	if i < 0 {
 		log.Warn().Msgf("Couldn't find a matching session for earnings change: %s", evt.ID)
 		return
 	}
+	k.state.Sessions = append([]session.History(nil), k.state.Sessions...)
+	session := &k.state.Sessions[i]
 
 	// From a server perspective, bytes up are the actual bytes the client downloaded(aka the bytes we pushed to the consumer)
 	// To lessen the confusion, I suggest having the bytes reversed on the session instance.
 	// This way, the session will show that it downloaded the bytes in a manner that is easier to comprehend.
 	session.DataReceived = evt.Up
 	session.DataSent = evt.Down
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
@@ -366,18 +488,16 @@ func (k *Keeper) updateSessionEarnings(e interface{}) {
 		return
 	}
 
-	var session *session.History
-	for i := range k.state.Sessions {
-		if string(k.state.Sessions[i].SessionID) == evt.SessionID {
-			session = &k.state.Sessions[i]
-		}
-	}
-	if session == nil {
+	i := k.sessionIndex(evt.SessionID)
+	if i < 0 {
 		log.Warn().Msgf("Couldn't find a matching session for earnings change: %s", evt.SessionID)
 		return
 	}
+	k.state.Sessions = append([]session.History(nil), k.state.Sessions...)
+	session := &k.state.Sessions[i]
 
 	session.Tokens = evt.Total
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
@@ -390,12 +510,19 @@ func (k *Keeper) consumeConnectionStateEvent(e interface{}) {
 		return
 	}
 
-	if evt.State == connection.NotConnected {
-		k.state.Connection = stateEvent.Connection{}
+	id := connectionID(evt.SessionInfo)
+	if evt.State == connection.NotConnected && id == connection.DefaultConnectionID {
+		k.statisticsHistory.clear()
 	}
-	k.state.Connection.Session = evt.SessionInfo
-	log.Info().Msgf("Session %s", k.state.Connection.String())
+	k.updateConnection(id, func(c *stateEvent.Connection) {
+		if evt.State == connection.NotConnected {
+			*c = stateEvent.Connection{}
+		}
+		c.Session = evt.SessionInfo
+		log.Info().Msgf("Session %s", c.String())
+	})
 
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
@@ -408,11 +535,24 @@ func (k *Keeper) updateConnectionStats(e interface{}) {
 		return
 	}
 
-	k.state.Connection.Statistics = evt.Stats
+	k.updateConnection(connectionID(evt.SessionInfo), func(c *stateEvent.Connection) {
+		c.Statistics = evt.Stats
+	})
 
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
+// recordConnectionStatistics keeps every statistics sample, unlike the debounced state update.
+func (k *Keeper) recordConnectionStatistics(e connection.AppEventConnectionStatistics) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+
+	if connectionID(e.SessionInfo) == connection.DefaultConnectionID {
+		k.statisticsHistory.add(e.Stats)
+	}
+}
+
 func (k *Keeper) updateConnectionThroughput(e interface{}) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
@@ -422,8 +562,28 @@ func (k *Keeper) updateConnectionThroughput(e interface{}) {
 		return
 	}
 
-	k.state.Connection.Throughput = evt.Throughput
+	k.updateConnection(connectionID(evt.SessionInfo), func(c *stateEvent.Connection) {
+		c.Throughput = evt.Throughput
+	})
 
+	k.publishState()
+	go k.announceStateChanges(nil)
+}
+
+func (k *Keeper) updateConnectionTraffic(e interface{}) {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	evt, ok := e.(connection.AppEventConnectionTraffic)
+	if !ok {
+		log.Warn().Msg("Received a wrong kind of event for connection state update")
+		return
+	}
+
+	k.updateConnection(connectionID(evt.SessionInfo), func(c *stateEvent.Connection) {
+		c.Traffic = evt.Breakdown
+	})
+
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
@@ -436,12 +596,83 @@ func (k *Keeper) updateConnectionSpending(e interface{}) {
 		return
 	}
 
-	k.state.Connection.Invoice = evt.Invoice
-	log.Info().Msgf("Session %s", k.state.Connection.String())
+	k.updateConnection(k.sessionConnectionID(evt.SessionID), func(c *stateEvent.Connection) {
+		c.Invoice = evt.Invoice
+		log.Info().Msgf("Session %s", c.String())
+	})
 
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
+// updateConnection applies the change to the connection of given ID, connections which are not connected
+// anymore are dropped except the default one. The default connection is mirrored to State.Connection.
+func (k *Keeper) updateConnection(id string, change func(c *stateEvent.Connection)) {
+	connections := make(map[string]stateEvent.Connection, len(k.state.Connections)+1)
+	for cid, c := range k.state.Connections {
+		connections[cid] = c
+	}
+
+	c, ok := connections[id]
+	if !ok {
+		c.Session.ConnectionID = id
+	}
+	change(&c)
+	if id != connection.DefaultConnectionID && c.Session.State == connection.NotConnected {
+		delete(connections, id)
+	} else {
+		connections[id] = c
+	}
+
+	k.state.Connections = connections
+	if id == connection.DefaultConnectionID {
+		k.state.Connection = c
+	}
+}
+
+// sessionConnectionID finds the connection session belongs to, the default one is assumed for unknown sessions.
+func (k *Keeper) sessionConnectionID(sessionID string) string {
+	for id, c := range k.state.Connections {
+		if sessionID != "" && string(c.Session.SessionID) == sessionID {
+			return id
+		}
+	}
+	return connection.DefaultConnectionID
+}
+
+func (k *Keeper) spendingConnectionID(e interface{}) string {
+	evt, ok := e.(pingpongEvent.AppEventInvoicePaid)
+	if !ok {
+		return connection.DefaultConnectionID
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	return k.sessionConnectionID(evt.SessionID)
+}
+
+func connectionID(status connection.Status) string {
+	if status.ConnectionID == "" {
+		return connection.DefaultConnectionID
+	}
+	return status.ConnectionID
+}
+
+func statisticsConnectionID(e interface{}) string {
+	evt, _ := e.(connection.AppEventConnectionStatistics)
+	return connectionID(evt.SessionInfo)
+}
+
+func throughputConnectionID(e interface{}) string {
+	evt, _ := e.(bandwidth.AppEventConnectionThroughput)
+	return connectionID(evt.SessionInfo)
+}
+
+func trafficConnectionID(e interface{}) string {
+	evt, _ := e.(connection.AppEventConnectionTraffic)
+	return connectionID(evt.SessionInfo)
+}
+
 func (k *Keeper) consumeBalanceChangedEvent(e interface{}) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
@@ -450,18 +681,13 @@ func (k *Keeper) consumeBalanceChangedEvent(e interface{}) {
 		log.Warn().Msg("Received a wrong kind of event for balance change")
 		return
 	}
-	var id *stateEvent.Identity
-	for i := range k.state.Identities {
-		if k.state.Identities[i].Address == evt.Identity.Address {
-			id = &k.state.Identities[i]
-			break
-		}
-	}
+	id := k.mutableIdentity(evt.Identity.Address)
 	if id == nil {
 		log.Warn().Msgf("Couldn't find a matching identity for balance change: %s", evt.Identity.Address)
 		return
 	}
 	id.Balance = evt.Current
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
@@ -473,26 +699,52 @@ func (k *Keeper) consumeEarningsChangedEvent(e interface{}) {
 		log.Warn().Msg("Received a wrong kind of event for earnings change")
 		return
 	}
-	var id *stateEvent.Identity
-	for i := range k.state.Identities {
-		if k.state.Identities[i].Address == evt.Identity.Address {
-			id = &k.state.Identities[i]
-			break
-		}
-	}
+	id := k.mutableIdentity(evt.Identity.Address)
 	if id == nil {
 		log.Warn().Msgf("Couldn't find a matching identity for earnings change: %s", evt.Identity.Address)
 		return
 	}
-	id.Earnings = evt.Current.UnsettledBalance
-	id.EarningsTotal = evt.Current.LifetimeBalance
+	// copy earnings so that previously announced states are not affected
+	earningsPerAccountant := make(map[common.Address]pingpongEvent.Earnings, len(id.EarningsPerAccountant)+1)
+	for accountantID, earnings := range id.EarningsPerAccountant {
+		earningsPerAccountant[accountantID] = earnings
+	}
+	earningsPerAccountant[evt.AccountantID] = evt.Current
+	earnings := totalEarnings(earningsPerAccountant)
+
+	id.EarningsPerAccountant = earningsPerAccountant
+	id.Earnings = earnings.UnsettledBalance
+	id.EarningsTotal = earnings.LifetimeBalance
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
+// mutableIdentity returns the identity of the working state which is safe to modify,
+// leaving the identities of the published snapshot intact.
+func (k *Keeper) mutableIdentity(address string) *stateEvent.Identity {
+	for i := range k.state.Identities {
+		if k.state.Identities[i].Address == address {
+			k.state.Identities = append([]stateEvent.Identity(nil), k.state.Identities...)
+			return &k.state.Identities[i]
+		}
+	}
+	return nil
+}
+
+func totalEarnings(earningsPerAccountant map[common.Address]pingpongEvent.Earnings) pingpongEvent.Earnings {
+	var total pingpongEvent.Earnings
+	for _, earnings := range earningsPerAccountant {
+		total.LifetimeBalance = total.LifetimeBalance.Add(earnings.LifetimeBalance)
+		total.UnsettledBalance = total.UnsettledBalance.Add(earnings.UnsettledBalance)
+	}
+	return total
+}
+
 func (k *Keeper) consumeIdentityCreatedEvent(_ interface{}) {
 	k.lock.Lock()
 	defer k.lock.Unlock()
 	k.state.Identities = k.fetchIdentities()
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
@@ -503,24 +755,20 @@ func (k *Keeper) consumeIdentityRegistrationEvent(e interface{}) {
 	if !ok {
 		log.Warn().Msg("Received a wrong kind of event for identity registration")
 	}
-	var id *stateEvent.Identity
-	for i := range k.state.Identities {
-		if k.state.Identities[i].Address == evt.ID.Address {
-			id = &k.state.Identities[i]
-			break
-		}
-	}
+	id := k.mutableIdentity(evt.ID.Address)
 	if id == nil {
 		log.Warn().Msgf("Couldn't find a matching identity for balance change: %s", evt.ID.Address)
 		return
 	}
 	id.RegistrationStatus = evt.Status
+	k.publishState()
 	go k.announceStateChanges(nil)
 }
 
 func (k *Keeper) incrementConnectCount(serviceID string, isSuccess bool) {
 	for i := range k.state.Services {
 		if k.state.Services[i].ID == serviceID {
+			k.state.Services = append([]contract.ServiceInfoDTO(nil), k.state.Services...)
 			if isSuccess {
 				k.state.Services[i].ConnectionStatistics.Successful++
 			} else {
@@ -531,16 +779,115 @@ func (k *Keeper) incrementConnectCount(serviceID string, isSuccess bool) {
 	}
 }
 
-// GetState returns the current state
+// GetState returns the latest snapshot of the state without blocking on state updates.
+// Returned state is shared with other readers and must not be modified.
 func (k *Keeper) GetState() event.State {
+	return *k.snapshot.Load().(*stateEvent.State)
+}
+
+// Revision returns the revision of the latest state snapshot, it changes whenever the state does.
+// Pollers can use it to cheaply detect state changes before calling GetState.
+func (k *Keeper) Revision() uint64 {
+	return atomic.LoadUint64(&k.revision)
+}
+
+// ConnectionStatisticsHistory returns recent connection statistics samples ordered from the oldest.
+func (k *Keeper) ConnectionStatisticsHistory() []connection.Statistics {
 	k.lock.Lock()
 	defer k.lock.Unlock()
 
-	return *k.state
+	return k.statisticsHistory.within(connectionStatisticsHistoryWindow)
+}
+
+// ProviderOverview aggregates active sessions, provided session statistics, NAT status and service health.
+func (k *Keeper) ProviderOverview() (contract.ProviderOverviewDTO, error) {
+	now := time.Now().UTC()
+	today, err := k.providedSessionStats(time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return contract.ProviderOverviewDTO{}, err
+	}
+	month, err := k.providedSessionStats(time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		return contract.ProviderOverviewDTO{}, err
+	}
+
+	state := k.GetState()
+	consumers := make(map[identity.Identity]struct{})
+	for _, se := range state.Sessions {
+		consumers[se.ConsumerID] = struct{}{}
+	}
+
+	services := make([]contract.ProviderServiceOverviewDTO, len(state.Services))
+	for i, se := range state.Services {
+		services[i] = contract.ProviderServiceOverviewDTO{
+			ID:                   se.ID,
+			Type:                 se.Type,
+			Status:               se.Status,
+			Restarts:             se.Restarts,
+			ProposalStatus:       proposalStatus(se),
+			ConnectionStatistics: se.ConnectionStatistics,
+		}
+	}
+
+	return contract.ProviderOverviewDTO{
+		ActiveSessions:  len(state.Sessions),
+		ActiveConsumers: len(consumers),
+		Today:           contract.NewSessionStatsDTO(today),
+		Month:           contract.NewSessionStatsDTO(month),
+		NATStatus:       state.NATStatus,
+		Services:        services,
+	}, nil
+}
+
+func (k *Keeper) providedSessionStats(from time.Time) (session.Stats, error) {
+	if k.deps.SessionStorage == nil {
+		return session.NewStats(), nil
+	}
+
+	query := session.NewQuery().
+		FilterFrom(from).
+		FilterDirection(session.DirectionProvided).
+		FetchStats()
+	if err := k.deps.SessionStorage.Query(query); err != nil {
+		return session.Stats{}, errors.Wrap(err, "could not query provided sessions")
+	}
+	return query.Stats, nil
+}
+
+func proposalStatus(se contract.ServiceInfoDTO) string {
+	switch {
+	case se.Status != string(servicestate.Running):
+		return "not_published"
+	case se.Unlisted:
+		return "unlisted"
+	default:
+		return "published"
+	}
 }
 
 // Debounce takes in the f and makes sure that it only gets called once if multiple calls are executed in the given interval d.
 // It returns the debounced instance of the function.
+// debouncePerConnection debounces events of every connection separately, so that frequent events
+// of one connection do not suppress events of the others.
+func debouncePerConnection(f func(interface{}), d time.Duration, connectionID func(interface{}) string) func(interface{}) {
+	var lock sync.Mutex
+	debounced := make(map[string]func(interface{}))
+
+	return func(e interface{}) {
+		id := connectionID(e)
+
+		lock.Lock()
+		consume, ok := debounced[id]
+		if !ok {
+			consume = debounce(f, d)
+			debounced[id] = consume
+		}
+		lock.Unlock()
+
+		consume(e)
+	}
+}
+
 func debounce(f func(interface{}), d time.Duration) func(interface{}) {
 	incoming := make(chan interface{})
 
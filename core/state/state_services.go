@@ -0,0 +1,64 @@
+// +build !mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+type serviceLister interface {
+	List() map[service.ID]*service.Instance
+}
+
+func (k *Keeper) updateServices() {
+	services := k.deps.ServiceLister.List()
+	result := make([]contract.ServiceInfoDTO, len(services))
+
+	i := 0
+	for key, v := range services {
+		// merge in the connection statistics
+		match, _ := k.getServiceByID(string(key))
+
+		result[i] = contract.ServiceInfoDTO{
+			ID:                   string(key),
+			ProviderID:           v.ProviderID.Address,
+			Type:                 v.Type,
+			Options:              v.Options,
+			Status:               string(v.State()),
+			Restarts:             v.Restarts(),
+			Proposal:             contract.NewProposalDTO(v.Proposal),
+			Unlisted:             v.Unlisted,
+			ConnectionStatistics: match.ConnectionStatistics,
+		}
+		if usage := v.ResourceUsage(); !usage.SampledAt.IsZero() {
+			result[i].ResourceUsage = &contract.ServiceResourceUsageDTO{
+				Goroutines:      usage.Goroutines,
+				CPUPercent:      usage.CPUPercent,
+				MemoryBytes:     usage.MemoryBytes,
+				FileDescriptors: usage.FileDescriptors,
+				SampledAt:       usage.SampledAt,
+			}
+		}
+		i++
+	}
+
+	k.state.Services = result
+}
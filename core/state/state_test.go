@@ -19,12 +19,18 @@ package state
 
 import (
 	"errors"
+	"io/ioutil"
+	"os"
+	"reflect"
 	"sync"
 	"testing"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/consumer/schedule"
 	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/consumer/traffic"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
@@ -34,8 +40,10 @@ import (
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/nat"
 	natEvent "github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/mapping"
 	nodeSession "github.com/mysteriumnetwork/node/session"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong"
@@ -148,7 +156,7 @@ func Test_ConsumesNATEvents(t *testing.T) {
 		ServiceLister:     sl,
 		IdentityProvider:  &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, duration)
+	keeper := NewKeeper(deps, UniformDebounceConfig(duration))
 
 	for i := 0; i < 5; i++ {
 		// shoot a few events to see if we'll debounce
@@ -165,6 +173,91 @@ func Test_ConsumesNATEvents(t *testing.T) {
 	assert.Equal(t, natProvider.statusToReturn.Status, keeper.GetState().NATStatus.Status)
 }
 
+func Test_DebouncesEventClassesSeparately(t *testing.T) {
+	natProvider := &natStatusProviderMock{
+		statusToReturn: mockNATStatus,
+	}
+	deps := KeeperDeps{
+		NATStatusProvider: natProvider,
+		Publisher:         &mockPublisher{},
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+	}
+	keeper := NewKeeper(deps, DebounceConfig{
+		Statistics: time.Hour,
+		Sessions:   time.Hour,
+		NAT:        time.Millisecond,
+		Announce:   time.Millisecond,
+	})
+
+	// NAT status is not held back by slow debouncing of other event classes
+	keeper.consumeServiceSessionStatisticsEvent(sessionEvent.AppEventDataTransferred{ID: "1", Up: 1, Down: 2})
+	keeper.consumeNATEvent(natEvent.Event{Stage: "hole_punching", Successful: true})
+
+	assert.Eventually(t, interacted(natProvider, 1), 2*time.Second, 10*time.Millisecond)
+}
+
+func Test_ConsumesPortMappingHealthEvents(t *testing.T) {
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{},
+		Publisher:         &mockPublisher{},
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+	}
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
+
+	keeper.consumePortMappingHealthEvent(mapping.AppEventPortMappingHealth{Protocol: "UDP", Port: 1, Status: mapping.LeaseActive})
+	keeper.consumePortMappingHealthEvent(mapping.AppEventPortMappingHealth{Protocol: "UDP", Port: 2, Status: mapping.LeaseActive})
+	keeper.consumePortMappingHealthEvent(mapping.AppEventPortMappingHealth{Protocol: "UDP", Port: 1, Status: mapping.LeaseLost, Error: errors.New("gateway is not reachable")})
+	assert.Equal(t, []contract.PortMappingDTO{
+		{Protocol: "UDP", Port: 2, Status: "active"},
+		{Protocol: "UDP", Port: 1, Status: "lost", Error: "gateway is not reachable"},
+	}, keeper.GetState().NATStatus.PortMappings)
+
+	keeper.consumePortMappingHealthEvent(mapping.AppEventPortMappingHealth{Protocol: "UDP", Port: 2, Status: mapping.LeaseReleased})
+	assert.Equal(t, []contract.PortMappingDTO{
+		{Protocol: "UDP", Port: 1, Status: "lost", Error: "gateway is not reachable"},
+	}, keeper.GetState().NATStatus.PortMappings)
+}
+
+func Test_ConsumesBrokerStatusEvents(t *testing.T) {
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{},
+		Publisher:         &mockPublisher{},
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+	}
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
+	assert.Equal(t, "connected", keeper.GetState().Broker.Status)
+
+	keeper.consumeBrokerStatusEvent(nats.AppEventBrokerStatus{Status: nats.BrokerStatusDisconnected})
+	assert.Equal(t, contract.BrokerStatusDTO{Status: "disconnected"}, keeper.GetState().Broker)
+
+	keeper.consumeBrokerStatusEvent(nats.AppEventBrokerStatus{Status: nats.BrokerStatusReconnected, Server: "nats://broker2:4222"})
+	assert.Equal(t, contract.BrokerStatusDTO{Status: "reconnected", Server: "nats://broker2:4222"}, keeper.GetState().Broker)
+}
+
+func Test_ConsumesScheduleStatusEvents(t *testing.T) {
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{},
+		Publisher:         &mockPublisher{},
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+	}
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
+	rules, err := schedule.ParseRules([]string{"mon-fri 09:00-17:00 DE"})
+	assert.NoError(t, err)
+	status := schedule.Status{
+		Config:     schedule.Config{Enabled: true, ConsumerID: "0xc", Rules: rules},
+		ActiveRule: "mon,tue,wed,thu,fri 09:00-17:00 DE",
+		Connected:  true,
+	}
+
+	keeper.consumeScheduleStatusEvent(schedule.AppEventScheduleStatus{Status: status})
+
+	assert.Equal(t, status, keeper.GetState().Schedule)
+}
+
 func Test_ConsumesSessionEvents(t *testing.T) {
 	// given
 	expected := sessionEvent.SessionContext{
@@ -182,7 +275,7 @@ func Test_ConsumesSessionEvents(t *testing.T) {
 		Publisher:        eventBus,
 		IdentityProvider: &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	keeper.Subscribe(eventBus)
 
 	// when
@@ -235,7 +328,7 @@ func Test_ConsumesSessionAcknowledgeEvents(t *testing.T) {
 		Publisher:        eventBus,
 		IdentityProvider: &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	keeper.Subscribe(eventBus)
 	keeper.state.Services = []contract.ServiceInfoDTO{
 		{ID: myID},
@@ -243,6 +336,7 @@ func Test_ConsumesSessionAcknowledgeEvents(t *testing.T) {
 	keeper.state.Sessions = []session.History{
 		expected,
 	}
+	keeper.publishState()
 
 	// when
 	eventBus.Publish(sessionEvent.AppTopicSession, sessionEvent.AppEventSession{
@@ -268,26 +362,27 @@ func Test_consumeServiceSessionEarningsEvent(t *testing.T) {
 		Publisher:        eventBus,
 		IdentityProvider: &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	keeper.Subscribe(eventBus)
 	keeper.state.Sessions = []session.History{
 		{SessionID: nodeSession.ID("1")},
 	}
+	keeper.publishState()
 
 	// when
 	eventBus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{
 		SessionID: "1",
-		Total:     500,
+		Total:     money.NewTokens(500),
 	})
 
 	// then
 	assert.Eventually(t, func() bool {
-		return keeper.GetState().Sessions[0].Tokens != 0
+		return !keeper.GetState().Sessions[0].Tokens.IsZero()
 	}, 2*time.Second, 10*time.Millisecond)
 	assert.Equal(
 		t,
 		[]session.History{
-			{SessionID: nodeSession.ID("1"), Tokens: 500},
+			{SessionID: nodeSession.ID("1"), Tokens: money.NewTokens(500)},
 		},
 		keeper.GetState().Sessions,
 	)
@@ -300,11 +395,12 @@ func Test_consumeServiceSessionStatisticsEvent(t *testing.T) {
 		Publisher:        eventBus,
 		IdentityProvider: &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	keeper.Subscribe(eventBus)
 	keeper.state.Sessions = []session.History{
 		{SessionID: nodeSession.ID("1")},
 	}
+	keeper.publishState()
 
 	// when
 	eventBus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{
@@ -326,6 +422,41 @@ func Test_consumeServiceSessionStatisticsEvent(t *testing.T) {
 	)
 }
 
+func Test_GetStateReturnsImmutableSnapshots(t *testing.T) {
+	// given
+	deps := KeeperDeps{
+		Publisher:        &mockPublisher{},
+		IdentityProvider: &mocks.IdentityProvider{},
+	}
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
+	keeper.state.Sessions = []session.History{
+		{SessionID: nodeSession.ID("1")},
+		{SessionID: nodeSession.ID("2")},
+	}
+	keeper.publishState()
+	revision := keeper.Revision()
+	before := keeper.GetState()
+
+	// when
+	keeper.updateSessionStats(sessionEvent.AppEventDataTransferred{ID: "1", Up: 1, Down: 2})
+	keeper.consumeServiceSessionEvent(sessionEvent.AppEventSession{
+		Status:  sessionEvent.RemovedStatus,
+		Session: sessionEvent.SessionContext{ID: "1"},
+	})
+
+	// then
+	assert.Equal(
+		t,
+		[]session.History{
+			{SessionID: nodeSession.ID("1")},
+			{SessionID: nodeSession.ID("2")},
+		},
+		before.Sessions,
+	)
+	assert.Equal(t, []session.History{{SessionID: nodeSession.ID("2")}}, keeper.GetState().Sessions)
+	assert.Equal(t, revision+2, keeper.Revision())
+}
+
 func Test_ConsumesServiceEvents(t *testing.T) {
 	expected := service.Instance{}
 	var id service.ID
@@ -347,7 +478,7 @@ func Test_ConsumesServiceEvents(t *testing.T) {
 		ServiceLister:     sl,
 		IdentityProvider:  &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, duration)
+	keeper := NewKeeper(deps, UniformDebounceConfig(duration))
 
 	for i := 0; i < 5; i++ {
 		// shoot a few events to see if we'll debounce
@@ -375,7 +506,7 @@ func Test_ConsumesConnectionStateEvents(t *testing.T) {
 		ServiceLister:     &serviceListerMock{},
 		IdentityProvider:  &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	err := keeper.Subscribe(eventBus)
 	assert.NoError(t, err)
 	assert.Equal(t, connection.NotConnected, keeper.GetState().Connection.Session.State)
@@ -407,7 +538,7 @@ func Test_ConsumesConnectionStatisticsEvents(t *testing.T) {
 		ServiceLister:     &serviceListerMock{},
 		IdentityProvider:  &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	err := keeper.Subscribe(eventBus)
 	assert.NoError(t, err)
 	assert.True(t, keeper.GetState().Connection.Statistics.At.IsZero())
@@ -423,6 +554,106 @@ func Test_ConsumesConnectionStatisticsEvents(t *testing.T) {
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
+func Test_ConsumesConnectionTrafficEvents(t *testing.T) {
+	// given
+	expected := traffic.Breakdown{
+		Categories: []traffic.Usage{{Name: "video", BytesSent: 10, BytesReceived: 1000}},
+		Hosts:      []traffic.Usage{{Name: "rr1.googlevideo.com", BytesSent: 10, BytesReceived: 1000}},
+	}
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{statusToReturn: mockNATStatus},
+		Publisher:         eventBus,
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+	}
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+
+	// when
+	eventBus.Publish(connection.AppTopicConnectionTraffic, connection.AppEventConnectionTraffic{
+		SessionInfo: connection.Status{State: connection.Connected},
+		Breakdown:   expected,
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return reflect.DeepEqual(expected, keeper.GetState().Connection.Traffic)
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
+func Test_KeepsNamedConnectionsSeparately(t *testing.T) {
+	// given
+	named := connection.Status{ConnectionID: "work", State: connection.Connected, SessionID: "2"}
+	stats := connection.Statistics{At: time.Now(), BytesReceived: 10, BytesSent: 5}
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{statusToReturn: mockNATStatus},
+		Publisher:         eventBus,
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+	}
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+
+	// when
+	eventBus.Publish(connection.AppTopicConnectionState, connection.AppEventConnectionState{State: named.State, SessionInfo: named})
+	eventBus.Publish(connection.AppTopicConnectionStatistics, connection.AppEventConnectionStatistics{Stats: stats, SessionInfo: named})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return keeper.GetState().Connections["work"].Statistics == stats
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, named, keeper.GetState().Connections["work"].Session)
+	assert.Equal(t, connection.NotConnected, keeper.GetState().Connection.Session.State)
+	assert.True(t, keeper.GetState().Connection.Statistics.At.IsZero())
+	assert.Empty(t, keeper.ConnectionStatisticsHistory())
+
+	// when
+	named.State = connection.NotConnected
+	eventBus.Publish(connection.AppTopicConnectionState, connection.AppEventConnectionState{State: named.State, SessionInfo: named})
+
+	// then
+	assert.Eventually(t, func() bool {
+		_, ok := keeper.GetState().Connections["work"]
+		return !ok
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Contains(t, keeper.GetState().Connections, connection.DefaultConnectionID)
+}
+
+func Test_RecordsConnectionStatisticsHistory(t *testing.T) {
+	// given
+	first := connection.Statistics{At: time.Now(), BytesReceived: 10, BytesSent: 5}
+	second := connection.Statistics{At: first.At.Add(time.Second), BytesReceived: 20, BytesSent: 10}
+	eventBus := eventbus.New()
+	deps := KeeperDeps{
+		NATStatusProvider: &natStatusProviderMock{statusToReturn: mockNATStatus},
+		Publisher:         eventBus,
+		ServiceLister:     &serviceListerMock{},
+		IdentityProvider:  &mocks.IdentityProvider{},
+	}
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
+	err := keeper.Subscribe(eventBus)
+	assert.NoError(t, err)
+
+	// when
+	eventBus.Publish(connection.AppTopicConnectionStatistics, connection.AppEventConnectionStatistics{Stats: first})
+	eventBus.Publish(connection.AppTopicConnectionStatistics, connection.AppEventConnectionStatistics{Stats: second})
+
+	// then
+	assert.Equal(t, []connection.Statistics{first, second}, keeper.ConnectionStatisticsHistory())
+
+	// when
+	eventBus.Publish(connection.AppTopicConnectionState, connection.AppEventConnectionState{State: connection.NotConnected})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return len(keeper.ConnectionStatisticsHistory()) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+}
+
 func Test_ConsumesConnectionInvoiceEvents(t *testing.T) {
 	// given
 	expected := crypto.Invoice{
@@ -437,7 +668,7 @@ func Test_ConsumesConnectionInvoiceEvents(t *testing.T) {
 		ServiceLister:     &serviceListerMock{},
 		IdentityProvider:  &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	err := keeper.Subscribe(eventBus)
 	assert.NoError(t, err)
 	assert.True(t, keeper.GetState().Connection.Statistics.At.IsZero())
@@ -470,7 +701,7 @@ func Test_ConsumesBalanceChangeEvent(t *testing.T) {
 		BalanceProvider:           &mockBalanceProvider{Balance: 0},
 		EarningsProvider:          &mockEarningsProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	err := keeper.Subscribe(eventBus)
 	assert.NoError(t, err)
 	assert.Zero(t, keeper.GetState().Identities[0].Balance)
@@ -478,13 +709,13 @@ func Test_ConsumesBalanceChangeEvent(t *testing.T) {
 	// when
 	eventBus.Publish(pingpongEvent.AppTopicBalanceChanged, pingpongEvent.AppEventBalanceChanged{
 		Identity: identity.Identity{Address: "0x000000000000000000000000000000000000000a"},
-		Previous: 0,
-		Current:  999,
+		Previous: money.NewTokens(0),
+		Current:  money.NewTokens(999),
 	})
 
 	// then
 	assert.Eventually(t, func() bool {
-		return keeper.GetState().Identities[0].Balance == 999
+		return keeper.GetState().Identities[0].Balance.Cmp(money.NewTokens(999)) == 0
 	}, 2*time.Second, 10*time.Millisecond)
 }
 
@@ -505,7 +736,7 @@ func Test_ConsumesEarningsChangeEvent(t *testing.T) {
 		BalanceProvider:           &mockBalanceProvider{Balance: 0},
 		EarningsProvider:          &mockEarningsProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	err := keeper.Subscribe(eventBus)
 	assert.NoError(t, err)
 	assert.Zero(t, keeper.GetState().Identities[0].Balance)
@@ -514,13 +745,28 @@ func Test_ConsumesEarningsChangeEvent(t *testing.T) {
 	eventBus.Publish(pingpongEvent.AppTopicEarningsChanged, pingpongEvent.AppEventEarningsChanged{
 		Identity: identity.Identity{Address: "0x000000000000000000000000000000000000000a"},
 		Previous: pingpongEvent.Earnings{},
-		Current:  pingpongEvent.Earnings{LifetimeBalance: 100, UnsettledBalance: 10},
+		Current:  pingpongEvent.Earnings{LifetimeBalance: money.NewTokens(100), UnsettledBalance: money.NewTokens(10)},
+	})
+
+	// then
+	assert.Eventually(t, func() bool {
+		return keeper.GetState().Identities[0].Earnings.Cmp(money.NewTokens(10)) == 0 && keeper.GetState().Identities[0].EarningsTotal.Cmp(money.NewTokens(100)) == 0
+	}, 2*time.Second, 10*time.Millisecond)
+
+	// when
+	secondAccountant := common.HexToAddress("0x000000000000000000000000000000000000000b")
+	eventBus.Publish(pingpongEvent.AppTopicEarningsChanged, pingpongEvent.AppEventEarningsChanged{
+		Identity:     identity.Identity{Address: "0x000000000000000000000000000000000000000a"},
+		AccountantID: secondAccountant,
+		Previous:     pingpongEvent.Earnings{},
+		Current:      pingpongEvent.Earnings{LifetimeBalance: money.NewTokens(50), UnsettledBalance: money.NewTokens(5)},
 	})
 
 	// then
 	assert.Eventually(t, func() bool {
-		return keeper.GetState().Identities[0].Earnings == 10 && keeper.GetState().Identities[0].EarningsTotal == 100
+		return keeper.GetState().Identities[0].Earnings.Cmp(money.NewTokens(15)) == 0 && keeper.GetState().Identities[0].EarningsTotal.Cmp(money.NewTokens(150)) == 0
 	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, pingpongEvent.Earnings{LifetimeBalance: money.NewTokens(50), UnsettledBalance: money.NewTokens(5)}, keeper.GetState().Identities[0].EarningsPerAccountant[secondAccountant])
 }
 
 func Test_ConsumesIdentityRegistrationEvent(t *testing.T) {
@@ -540,7 +786,7 @@ func Test_ConsumesIdentityRegistrationEvent(t *testing.T) {
 		BalanceProvider:           &mockBalanceProvider{Balance: 0},
 		EarningsProvider:          &mockEarningsProvider{},
 	}
-	keeper := NewKeeper(deps, time.Millisecond)
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
 	err := keeper.Subscribe(eventBus)
 	assert.NoError(t, err)
 	assert.Equal(t, registry.Unregistered, keeper.GetState().Identities[0].RegistrationStatus)
@@ -574,7 +820,7 @@ func Test_getServiceByID(t *testing.T) {
 		ServiceLister:     sl,
 		IdentityProvider:  &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, duration)
+	keeper := NewKeeper(deps, UniformDebounceConfig(duration))
 	myID := "test"
 	keeper.state.Services = []contract.ServiceInfoDTO{
 		{ID: myID},
@@ -611,14 +857,16 @@ func Test_incrementConnectionCount(t *testing.T) {
 		ServiceLister:     sl,
 		IdentityProvider:  &mocks.IdentityProvider{},
 	}
-	keeper := NewKeeper(deps, duration)
+	keeper := NewKeeper(deps, UniformDebounceConfig(duration))
 	myID := "test"
 	keeper.state.Services = []contract.ServiceInfoDTO{
 		{ID: myID},
 		{ID: "mock"},
 	}
+	keeper.publishState()
 
 	keeper.incrementConnectCount(myID, false)
+	keeper.publishState()
 	s, found := serviceByID(keeper.GetState().Services, myID)
 	assert.True(t, found)
 
@@ -626,6 +874,7 @@ func Test_incrementConnectionCount(t *testing.T) {
 	assert.Equal(t, 0, s.ConnectionStatistics.Successful)
 
 	keeper.incrementConnectCount(myID, true)
+	keeper.publishState()
 	s, found = serviceByID(keeper.GetState().Services, myID)
 	assert.True(t, found)
 
@@ -644,16 +893,16 @@ type mockBalanceProvider struct {
 }
 
 // GetBalance returns a pre-defined balance.
-func (mbp *mockBalanceProvider) GetBalance(_ identity.Identity) uint64 {
-	return mbp.Balance
+func (mbp *mockBalanceProvider) GetBalance(_ identity.Identity) money.Tokens {
+	return money.NewTokens(mbp.Balance)
 }
 
 type mockEarningsProvider struct {
-	Earnings pingpongEvent.Earnings
+	Earnings map[common.Address]pingpongEvent.Earnings
 }
 
-// GetEarnings returns a pre-defined settlement state.
-func (mep *mockEarningsProvider) GetEarnings(_ identity.Identity) pingpongEvent.Earnings {
+// GetEarningsPerAccountant returns a pre-defined settlement state.
+func (mep *mockEarningsProvider) GetEarningsPerAccountant(_ identity.Identity) map[common.Address]pingpongEvent.Earnings {
 	return mep.Earnings
 }
 
@@ -673,3 +922,183 @@ type StubServiceDefinition struct{}
 func (fs *StubServiceDefinition) GetLocation() market.Location {
 	return market.Location{Country: "MU"}
 }
+
+func Test_ReplayedEventsRestoreKeeperState(t *testing.T) {
+	// given
+	recording, err := ioutil.TempFile("", "keeper-events")
+	assert.NoError(t, err)
+	recording.Close()
+	defer os.Remove(recording.Name())
+	defer os.Remove(recording.Name() + eventbus.RecordBackupSuffix)
+
+	newKeeper := func(bus eventbus.EventBus) *Keeper {
+		keeper := NewKeeper(KeeperDeps{
+			Publisher:        bus,
+			IdentityProvider: &mocks.IdentityProvider{},
+		}, UniformDebounceConfig(time.Millisecond))
+		keeper.Subscribe(bus)
+		keeper.state.Sessions = []session.History{
+			{SessionID: nodeSession.ID("1")},
+		}
+		keeper.publishState()
+		return keeper
+	}
+
+	recordedBus := eventbus.New()
+	recorded := newKeeper(recordedBus)
+	recorder := eventbus.NewRecorder(recording.Name(), eventbus.RecorderOptions{})
+	assert.NoError(t, recorder.Subscribe(recordedBus))
+
+	recordedBus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "1", Up: 1, Down: 2})
+	recordedBus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{SessionID: "1", Total: money.NewTokens(500)})
+	assert.Eventually(t, func() bool {
+		s := recorded.GetState().Sessions[0]
+		return !s.Tokens.IsZero() && s.DataReceived != 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		file, err := os.Open(recording.Name())
+		assert.NoError(t, err)
+		defer file.Close()
+		events, err := eventbus.ReadRecording(file)
+		return err == nil && len(events) >= 2
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.NoError(t, recorder.Close())
+
+	// when
+	file, err := os.Open(recording.Name())
+	assert.NoError(t, err)
+	defer file.Close()
+	events, err := eventbus.ReadRecording(file)
+	assert.NoError(t, err)
+
+	replayedBus := eventbus.New()
+	replayed := newKeeper(replayedBus)
+	replayer := eventbus.NewReplayer()
+	replayer.Register(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{})
+	replayer.Register(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{})
+	_, err = replayer.Replay(events, replayedBus)
+	assert.NoError(t, err)
+
+	// then
+	assert.Eventually(t, func() bool {
+		s := replayed.GetState().Sessions[0]
+		return !s.Tokens.IsZero() && s.DataReceived != 0
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.Equal(t, recorded.GetState().Sessions, replayed.GetState().Sessions)
+}
+
+type mockSessionStorage struct {
+	stats   []session.Stats
+	queries int
+}
+
+func (m *mockSessionStorage) Query(query *session.Query) error {
+	query.Stats = m.stats[m.queries]
+	m.queries++
+	return nil
+}
+
+func Test_ProviderOverview(t *testing.T) {
+	// given
+	consumer1 := identity.FromAddress("0x0000000000000000000000000000000000000001")
+	consumer2 := identity.FromAddress("0x0000000000000000000000000000000000000002")
+	today := session.Stats{
+		Count:           1,
+		ConsumerCounts:  map[identity.Identity]int{consumer1: 1},
+		SumDataSent:     10,
+		SumDataReceived: 20,
+		SumDuration:     time.Minute,
+		SumTokens:       money.NewTokens(30),
+	}
+	month := session.Stats{
+		Count:           2,
+		ConsumerCounts:  map[identity.Identity]int{consumer1: 1, consumer2: 1},
+		SumDataSent:     11,
+		SumDataReceived: 22,
+		SumDuration:     2 * time.Minute,
+		SumTokens:       money.NewTokens(33),
+	}
+	storage := &mockSessionStorage{stats: []session.Stats{today, month}}
+
+	deps := KeeperDeps{
+		IdentityProvider: &mocks.IdentityProvider{},
+		SessionStorage:   storage,
+	}
+	keeper := NewKeeper(deps, UniformDebounceConfig(time.Millisecond))
+	keeper.state.NATStatus = contract.NATStatusDTO{Status: "successful"}
+	keeper.state.Sessions = []session.History{
+		{SessionID: "1", ConsumerID: consumer1},
+		{SessionID: "2", ConsumerID: consumer1},
+	}
+	keeper.state.Services = []contract.ServiceInfoDTO{
+		{ID: "a", Type: "wireguard", Status: string(servicestate.Running), ConnectionStatistics: contract.ServiceStatisticsDTO{Attempted: 2, Successful: 1}},
+		{ID: "b", Type: "openvpn", Status: string(servicestate.Running), Unlisted: true},
+		{ID: "c", Type: "noop", Status: string(servicestate.Restarting), Restarts: 3},
+	}
+	keeper.publishState()
+
+	// when
+	overview, err := keeper.ProviderOverview()
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, 2, storage.queries)
+	assert.Equal(t, contract.ProviderOverviewDTO{
+		ActiveSessions:  2,
+		ActiveConsumers: 1,
+		Today: contract.SessionStatsDTO{
+			Count:            1,
+			CountConsumers:   1,
+			SumBytesReceived: 20,
+			SumBytesSent:     10,
+			SumDuration:      60,
+			SumTokens:        contract.NewTokensDTO(money.NewTokens(30)),
+		},
+		Month: contract.SessionStatsDTO{
+			Count:            2,
+			CountConsumers:   2,
+			SumBytesReceived: 22,
+			SumBytesSent:     11,
+			SumDuration:      120,
+			SumTokens:        contract.NewTokensDTO(money.NewTokens(33)),
+		},
+		NATStatus: contract.NATStatusDTO{Status: "successful"},
+		Services: []contract.ProviderServiceOverviewDTO{
+			{ID: "a", Type: "wireguard", Status: "Running", ProposalStatus: "published", ConnectionStatistics: contract.ServiceStatisticsDTO{Attempted: 2, Successful: 1}},
+			{ID: "b", Type: "openvpn", Status: "Running", ProposalStatus: "unlisted"},
+			{ID: "c", Type: "noop", Status: "Restarting", Restarts: 3, ProposalStatus: "not_published"},
+		},
+	}, overview)
+}
+
+func BenchmarkKeeper_updateSessionStats(b *testing.B) {
+	keeper := NewKeeper(KeeperDeps{
+		Publisher:        &mockPublisher{},
+		IdentityProvider: &mocks.IdentityProvider{},
+	}, UniformDebounceConfig(time.Hour))
+	keeper.state.Sessions = []session.History{
+		{SessionID: nodeSession.ID("1")},
+		{SessionID: nodeSession.ID("2")},
+	}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		keeper.updateSessionStats(sessionEvent.AppEventDataTransferred{ID: "2", Up: uint64(i), Down: uint64(i)})
+	}
+}
+
+func BenchmarkKeeper_updateConnectionStats(b *testing.B) {
+	keeper := NewKeeper(KeeperDeps{
+		Publisher:        &mockPublisher{},
+		IdentityProvider: &mocks.IdentityProvider{},
+	}, UniformDebounceConfig(time.Hour))
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		evt := connection.AppEventConnectionStatistics{
+			Stats: connection.Statistics{BytesReceived: uint64(i), BytesSent: uint64(i)},
+		}
+		keeper.updateConnectionStats(evt)
+		keeper.recordConnectionStatistics(evt)
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+)
+
+const (
+	// connectionStatisticsHistorySize fits the history window at the default statistics report interval.
+	connectionStatisticsHistorySize = 600
+	// connectionStatisticsHistoryWindow limits the age of kept samples when statistics are reported less frequently.
+	connectionStatisticsHistoryWindow = 10 * time.Minute
+)
+
+// statisticsHistory is a fixed size ring buffer of connection statistics samples.
+type statisticsHistory struct {
+	samples []connection.Statistics
+	next    int
+	full    bool
+}
+
+func newStatisticsHistory(size int) *statisticsHistory {
+	return &statisticsHistory{samples: make([]connection.Statistics, size)}
+}
+
+func (h *statisticsHistory) add(stats connection.Statistics) {
+	h.samples[h.next] = stats
+	h.next = (h.next + 1) % len(h.samples)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+func (h *statisticsHistory) clear() {
+	h.next = 0
+	h.full = false
+}
+
+// within returns samples not older than the given window from the latest one, ordered from the oldest.
+func (h *statisticsHistory) within(window time.Duration) []connection.Statistics {
+	ordered := h.samples[:h.next]
+	if h.full {
+		ordered = append(append([]connection.Statistics{}, h.samples[h.next:]...), h.samples[:h.next]...)
+	}
+	if len(ordered) == 0 {
+		return []connection.Statistics{}
+	}
+
+	since := ordered[len(ordered)-1].At.Add(-window)
+	result := make([]connection.Statistics, 0, len(ordered))
+	for _, stats := range ordered {
+		if !stats.At.Before(since) {
+			result = append(result, stats)
+		}
+	}
+	return result
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StatisticsHistory_KeepsLatestSamples(t *testing.T) {
+	start := time.Date(2020, 6, 25, 13, 0, 0, 0, time.UTC)
+	sample := func(i int) connection.Statistics {
+		return connection.Statistics{At: start.Add(time.Duration(i) * time.Second), BytesSent: uint64(i)}
+	}
+
+	history := newStatisticsHistory(3)
+	assert.Equal(t, []connection.Statistics{}, history.within(time.Minute))
+
+	history.add(sample(1))
+	history.add(sample(2))
+	assert.Equal(t, []connection.Statistics{sample(1), sample(2)}, history.within(time.Minute))
+
+	history.add(sample(3))
+	history.add(sample(4))
+	history.add(sample(5))
+	assert.Equal(t, []connection.Statistics{sample(3), sample(4), sample(5)}, history.within(time.Minute))
+	assert.Equal(t, []connection.Statistics{sample(4), sample(5)}, history.within(time.Second))
+
+	history.clear()
+	assert.Equal(t, []connection.Statistics{}, history.within(time.Minute))
+}
@@ -0,0 +1,352 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package backup
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	timeLayout     = "20060102T150405Z"
+	restoreSuffix  = ".restore"
+	partialSuffix  = ".partial"
+	keystoreSuffix = ".keystore"
+)
+
+var (
+	// ErrBackupNotFound is returned when requested backup does not exist.
+	ErrBackupNotFound = errors.New("backup not found")
+	// ErrInvalidBackupName is returned when backup name points outside of backups directory.
+	ErrInvalidBackupName = errors.New("invalid backup name")
+)
+
+// Database is a node database which can be copied while in use.
+type Database interface {
+	Path() string
+	Snapshot(path string) error
+}
+
+// Backup describes a single database snapshot.
+type Backup struct {
+	Name      string
+	CreatedAt time.Time
+	Size      int64
+}
+
+// Manager takes database snapshots into the backups directory and keeps the configured amount of latest ones.
+// Identity keys are backed up together with the database, as database is useless without them.
+type Manager struct {
+	db          Database
+	keystoreDir string
+	dir         string
+	keep        int
+
+	lock     sync.Mutex
+	stop     chan struct{}
+	stopOnce sync.Once
+	now      func() time.Time
+}
+
+// NewManager returns a new backup manager. Keystore is not backed up if keystoreDir is empty.
+func NewManager(db Database, keystoreDir, dir string, keep int) *Manager {
+	return &Manager{
+		db:          db,
+		keystoreDir: keystoreDir,
+		dir:         dir,
+		keep:        keep,
+		stop:        make(chan struct{}),
+		now:         time.Now,
+	}
+}
+
+// Backup takes a consistent snapshot of the database and removes the backups exceeding the rotation limit.
+func (m *Manager) Backup() (Backup, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	if err := os.MkdirAll(m.dir, 0700); err != nil {
+		return Backup{}, errors.Wrap(err, "failed to create backups directory")
+	}
+
+	createdAt := m.now().UTC()
+	name := m.prefix() + createdAt.Format(timeLayout) + m.ext()
+	path := filepath.Join(m.dir, name)
+
+	partial := path + partialSuffix
+	if err := m.db.Snapshot(partial); err != nil {
+		os.Remove(partial)
+		return Backup{}, errors.Wrap(err, "failed to snapshot database")
+	}
+	if err := m.backupKeystore(path + keystoreSuffix); err != nil {
+		os.Remove(partial)
+		return Backup{}, err
+	}
+	if err := os.Rename(partial, path); err != nil {
+		os.Remove(partial)
+		os.RemoveAll(path + keystoreSuffix)
+		return Backup{}, errors.Wrap(err, "failed to save backup")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return Backup{}, errors.Wrap(err, "failed to save backup")
+	}
+
+	if err := m.rotate(); err != nil {
+		return Backup{}, err
+	}
+
+	return Backup{Name: name, CreatedAt: createdAt, Size: info.Size()}, nil
+}
+
+// List returns existing backups starting from the newest one.
+func (m *Manager) List() ([]Backup, error) {
+	files, err := ioutil.ReadDir(m.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list backups")
+	}
+
+	var backups []Backup
+	for _, file := range files {
+		if file.IsDir() {
+			continue
+		}
+		createdAt, ok := m.parseName(file.Name())
+		if !ok {
+			continue
+		}
+		backups = append(backups, Backup{Name: file.Name(), CreatedAt: createdAt, Size: file.Size()})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		return backups[i].CreatedAt.After(backups[j].CreatedAt)
+	})
+	return backups, nil
+}
+
+// Restore stages the given backup to replace the database on the next node start.
+// The running database can not be swapped safely, so the node has to be restarted to complete the restore.
+// Identity keys missing from the keystore are restored right away, existing keys are never overwritten.
+func (m *Manager) Restore(name string) error {
+	if name != filepath.Base(name) {
+		return ErrInvalidBackupName
+	}
+	if _, ok := m.parseName(name); !ok {
+		return ErrInvalidBackupName
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	src, err := os.Open(filepath.Join(m.dir, name))
+	if os.IsNotExist(err) {
+		return ErrBackupNotFound
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to open backup")
+	}
+	defer src.Close()
+
+	if err := m.restoreKeystore(filepath.Join(m.dir, name+keystoreSuffix)); err != nil {
+		return err
+	}
+	return copyFile(m.db.Path()+restoreSuffix, src)
+}
+
+// Start takes database backups periodically until manager is stopped.
+func (m *Manager) Start(interval time.Duration) {
+	go func() {
+		for {
+			select {
+			case <-time.After(interval):
+				if _, err := m.Backup(); err != nil {
+					log.Error().Err(err).Msg("Database backup failed")
+				}
+			case <-m.stop:
+				return
+			}
+		}
+	}()
+}
+
+// Stop stops periodic backups.
+func (m *Manager) Stop() {
+	m.stopOnce.Do(func() {
+		close(m.stop)
+	})
+}
+
+func (m *Manager) rotate() error {
+	if m.keep <= 0 {
+		return nil
+	}
+
+	backups, err := m.List()
+	if err != nil {
+		return err
+	}
+	for i := m.keep; i < len(backups); i++ {
+		if err := os.Remove(filepath.Join(m.dir, backups[i].Name)); err != nil {
+			return errors.Wrap(err, "failed to remove old backup")
+		}
+		if err := os.RemoveAll(filepath.Join(m.dir, backups[i].Name+keystoreSuffix)); err != nil {
+			return errors.Wrap(err, "failed to remove old backup")
+		}
+	}
+	return nil
+}
+
+// backupKeystore copies key files of the keystore into the given directory.
+func (m *Manager) backupKeystore(dst string) error {
+	if m.keystoreDir == "" {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(m.keystoreDir)
+	if err != nil && !os.IsNotExist(err) {
+		return errors.Wrap(err, "failed to list keystore")
+	}
+
+	partial := dst + partialSuffix
+	if err := os.MkdirAll(partial, 0700); err != nil {
+		return errors.Wrap(err, "failed to back up keystore")
+	}
+	for _, file := range files {
+		if !file.Mode().IsRegular() {
+			continue
+		}
+		if err := copyFileFrom(filepath.Join(m.keystoreDir, file.Name()), filepath.Join(partial, file.Name())); err != nil {
+			os.RemoveAll(partial)
+			return errors.Wrap(err, "failed to back up keystore")
+		}
+	}
+	if err := os.Rename(partial, dst); err != nil {
+		os.RemoveAll(partial)
+		return errors.Wrap(err, "failed to back up keystore")
+	}
+	return nil
+}
+
+// restoreKeystore copies key files missing from the keystore out of the given backup directory.
+func (m *Manager) restoreKeystore(src string) error {
+	if m.keystoreDir == "" {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(src)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to list keystore backup")
+	}
+
+	if err := os.MkdirAll(m.keystoreDir, 0700); err != nil {
+		return errors.Wrap(err, "failed to restore keystore")
+	}
+	for _, file := range files {
+		dst := filepath.Join(m.keystoreDir, file.Name())
+		if _, err := os.Stat(dst); err == nil {
+			continue
+		}
+		if err := copyFileFrom(filepath.Join(src, file.Name()), dst); err != nil {
+			return errors.Wrap(err, "failed to restore keystore")
+		}
+	}
+	return nil
+}
+
+func (m *Manager) prefix() string {
+	return strings.TrimSuffix(filepath.Base(m.db.Path()), m.ext()) + "-"
+}
+
+func (m *Manager) ext() string {
+	return filepath.Ext(m.db.Path())
+}
+
+func (m *Manager) parseName(name string) (time.Time, bool) {
+	if !strings.HasPrefix(name, m.prefix()) || !strings.HasSuffix(name, m.ext()) {
+		return time.Time{}, false
+	}
+	stamp := strings.TrimSuffix(strings.TrimPrefix(name, m.prefix()), m.ext())
+	createdAt, err := time.Parse(timeLayout, stamp)
+	return createdAt, err == nil
+}
+
+// ApplyPendingRestore replaces the database at the given path with the backup staged by Manager.Restore.
+// It must be called before the database is opened. Returns true if database was restored.
+func ApplyPendingRestore(dbPath string) (bool, error) {
+	staged := dbPath + restoreSuffix
+	if _, err := os.Stat(staged); os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to check staged restore")
+	}
+
+	// SQLite keeps uncommitted pages next to the database, they would corrupt the restored copy.
+	for _, suffix := range []string{"-wal", "-shm"} {
+		if err := os.Remove(dbPath + suffix); err != nil && !os.IsNotExist(err) {
+			return false, errors.Wrap(err, "failed to remove database journal")
+		}
+	}
+	if err := os.Rename(staged, dbPath); err != nil {
+		return false, errors.Wrap(err, "failed to restore database")
+	}
+	return true, nil
+}
+
+func copyFileFrom(srcPath, path string) error {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return errors.Wrap(err, "failed to open file")
+	}
+	defer src.Close()
+
+	return copyFile(path, src)
+}
+
+func copyFile(path string, src io.Reader) error {
+	partial := path + partialSuffix
+	dst, err := os.OpenFile(partial, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "failed to create file")
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(partial)
+		return errors.Wrap(err, "failed to copy file")
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(partial)
+		return errors.Wrap(err, "failed to copy file")
+	}
+	return errors.Wrap(os.Rename(partial, path), "failed to copy file")
+}
@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package backup
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDatabase struct {
+	path    string
+	content string
+}
+
+func (db *mockDatabase) Path() string {
+	return db.path
+}
+
+func (db *mockDatabase) Snapshot(path string) error {
+	return ioutil.WriteFile(path, []byte(db.content), 0600)
+}
+
+func newTestManager(t *testing.T, keep int) (*Manager, *mockDatabase, func()) {
+	dir := boltdbtest.CreateTempDir(t)
+	db := &mockDatabase{path: filepath.Join(dir, "myst.db"), content: "data"}
+	assert.NoError(t, ioutil.WriteFile(db.path, []byte(db.content), 0600))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "keystore"), 0700))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(dir, "keystore", "key1"), []byte("key1"), 0600))
+
+	manager := NewManager(db, filepath.Join(dir, "keystore"), filepath.Join(dir, "backups"), keep)
+	now := time.Date(2020, 6, 18, 10, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+	return manager, db, func() {
+		boltdbtest.RemoveTempDir(t, dir)
+	}
+}
+
+func TestManager_BackupRotatesOldBackups(t *testing.T) {
+	manager, _, cleanup := newTestManager(t, 2)
+	defer cleanup()
+
+	for i := 0; i < 3; i++ {
+		_, err := manager.Backup()
+		assert.NoError(t, err)
+	}
+
+	backups, err := manager.List()
+	assert.NoError(t, err)
+	assert.Equal(t, []Backup{
+		{Name: "myst-20200618T130000Z.db", CreatedAt: time.Date(2020, 6, 18, 13, 0, 0, 0, time.UTC), Size: 4},
+		{Name: "myst-20200618T120000Z.db", CreatedAt: time.Date(2020, 6, 18, 12, 0, 0, 0, time.UTC), Size: 4},
+	}, backups)
+}
+
+func TestManager_ListIgnoresForeignFiles(t *testing.T) {
+	manager, _, cleanup := newTestManager(t, 0)
+	defer cleanup()
+
+	_, err := manager.Backup()
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(manager.dir, "notes.txt"), nil, 0600))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(manager.dir, "myst-20200618T120000Z.db.partial"), nil, 0600))
+
+	backups, err := manager.List()
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
+
+func TestManager_ListWithoutBackups(t *testing.T) {
+	manager, _, cleanup := newTestManager(t, 0)
+	defer cleanup()
+
+	backups, err := manager.List()
+	assert.NoError(t, err)
+	assert.Empty(t, backups)
+}
+
+func TestManager_RestoreIsAppliedOnNextStart(t *testing.T) {
+	manager, db, cleanup := newTestManager(t, 0)
+	defer cleanup()
+
+	backup, err := manager.Backup()
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(db.path, []byte("corrupted"), 0600))
+	assert.NoError(t, ioutil.WriteFile(db.path+"-wal", []byte("journal"), 0600))
+
+	assert.NoError(t, manager.Restore(backup.Name))
+
+	restored, err := ApplyPendingRestore(db.path)
+	assert.NoError(t, err)
+	assert.True(t, restored)
+
+	content, err := ioutil.ReadFile(db.path)
+	assert.NoError(t, err)
+	assert.Equal(t, "data", string(content))
+	_, err = os.Stat(db.path + "-wal")
+	assert.True(t, os.IsNotExist(err))
+
+	restored, err = ApplyPendingRestore(db.path)
+	assert.NoError(t, err)
+	assert.False(t, restored)
+}
+
+func TestManager_BackupIncludesKeystore(t *testing.T) {
+	manager, _, cleanup := newTestManager(t, 1)
+	defer cleanup()
+
+	first, err := manager.Backup()
+	assert.NoError(t, err)
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(manager.keystoreDir, "key2"), []byte("key2"), 0600))
+	second, err := manager.Backup()
+	assert.NoError(t, err)
+
+	_, err = os.Stat(filepath.Join(manager.dir, first.Name+keystoreSuffix))
+	assert.True(t, os.IsNotExist(err))
+	assert.NoError(t, os.Remove(filepath.Join(manager.keystoreDir, "key1")))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(manager.keystoreDir, "key2"), []byte("replaced"), 0600))
+
+	assert.NoError(t, manager.Restore(second.Name))
+
+	content, err := ioutil.ReadFile(filepath.Join(manager.keystoreDir, "key1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", string(content))
+	content, err = ioutil.ReadFile(filepath.Join(manager.keystoreDir, "key2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "replaced", string(content))
+}
+
+func TestManager_RestoreValidatesName(t *testing.T) {
+	manager, _, cleanup := newTestManager(t, 0)
+	defer cleanup()
+
+	assert.Equal(t, ErrInvalidBackupName, manager.Restore("../myst.db"))
+	assert.Equal(t, ErrInvalidBackupName, manager.Restore("notes.txt"))
+	assert.Equal(t, ErrBackupNotFound, manager.Restore("myst-20200618T120000Z.db"))
+}
@@ -0,0 +1,300 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/asdine/storm/v3"
+	storm_json "github.com/asdine/storm/v3/codec/json"
+	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
+	"golang.org/x/crypto/scrypt"
+)
+
+// Encrypted database keeps its key derivation salt and a passphrase check value in a plaintext bucket.
+// Values (and keys encoded by storm codec) are encrypted with AES-GCM using a nonce derived from
+// the plaintext, so that encrypted keys stay stable and storm lookups keep working.
+// Bucket names and raw keys (strings, numbers, time based log keys) are not encrypted.
+const (
+	encryptionBucketName = "__encryption"
+	encryptionCodecName  = "aes-gcm-json"
+	encryptionCheck      = "mysterium"
+
+	saltLen = 32
+	keyLen  = 32
+)
+
+var (
+	saltKey  = []byte("salt")
+	checkKey = []byte("check")
+)
+
+// ErrWrongPassphrase is returned when database is encrypted with a different passphrase.
+var ErrWrongPassphrase = errors.New("wrong database passphrase")
+
+// ErrEncryptedValueCorrupted is returned when encrypted value can not be decrypted.
+var ErrEncryptedValueCorrupted = errors.New("encrypted value corrupted")
+
+// NewEncryptedStorage creates a new BoltDB storage which encrypts data at rest with a key derived
+// from the given passphrase. Existing plaintext database is encrypted on the first run.
+func NewEncryptedStorage(path, passphrase string) (*Bolt, error) {
+	db, err := openBolt(filepath.Join(path, "myst.db"))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open boltDB")
+	}
+
+	codec, salt, encrypted, err := loadEncryption(db, passphrase)
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to load boltDB encryption")
+	}
+	if !encrypted {
+		if db, err = encryptDB(db, codec, salt); err != nil {
+			return nil, errors.Wrap(err, "failed to encrypt boltDB")
+		}
+	}
+
+	stormDB, err := storm.Open(db.Path(), storm.UseDB(db), storm.Codec(codec))
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to open boltDB")
+	}
+	return &Bolt{stormDB}, nil
+}
+
+func openBolt(name string) (*bolt.DB, error) {
+	return bolt.Open(name, 0600, &bolt.Options{Timeout: 1 * time.Second})
+}
+
+// loadEncryption derives encryption key and verifies it against the stored check value.
+func loadEncryption(db *bolt.DB, passphrase string) (codec *encryptedCodec, salt []byte, encrypted bool, err error) {
+	var check []byte
+	err = db.View(func(tx *bolt.Tx) error {
+		if bucket := tx.Bucket([]byte(encryptionBucketName)); bucket != nil {
+			salt = append([]byte(nil), bucket.Get(saltKey)...)
+			check = append([]byte(nil), bucket.Get(checkKey)...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, false, err
+	}
+
+	if len(salt) == 0 {
+		salt = make([]byte, saltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, nil, false, err
+		}
+	}
+	if codec, err = newEncryptedCodec(passphrase, salt); err != nil {
+		return nil, nil, false, err
+	}
+
+	if len(check) == 0 {
+		return codec, salt, false, nil
+	}
+	if !bytes.Equal(check, codec.seal([]byte(encryptionCheck))) {
+		return nil, nil, false, ErrWrongPassphrase
+	}
+	return codec, salt, true, nil
+}
+
+// encryptDB rewrites plaintext database into a new file with all codec encoded keys and values
+// encrypted, so that no plaintext is left in the free pages, and replaces the original file.
+func encryptDB(db *bolt.DB, codec *encryptedCodec, salt []byte) (*bolt.DB, error) {
+	name := db.Path()
+	tmpName := name + ".encrypting"
+	if err := os.Remove(tmpName); err != nil && !os.IsNotExist(err) {
+		db.Close()
+		return nil, err
+	}
+
+	dst, err := openBolt(tmpName)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	err = dst.Update(func(dstTx *bolt.Tx) error {
+		err := db.View(func(srcTx *bolt.Tx) error {
+			return srcTx.ForEach(func(name []byte, src *bolt.Bucket) error {
+				if string(name) == encryptionBucketName {
+					return nil
+				}
+				bucket, err := dstTx.CreateBucket(name)
+				if err != nil {
+					return err
+				}
+				return encryptBucket(bucket, src, codec)
+			})
+		})
+		if err != nil {
+			return err
+		}
+
+		bucket, err := dstTx.CreateBucket([]byte(encryptionBucketName))
+		if err != nil {
+			return err
+		}
+		if err := bucket.Put(saltKey, salt); err != nil {
+			return err
+		}
+		return bucket.Put(checkKey, codec.seal([]byte(encryptionCheck)))
+	})
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := db.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(tmpName)
+		return nil, err
+	}
+
+	if err := os.Rename(tmpName, name); err != nil {
+		return nil, err
+	}
+	return openBolt(name)
+}
+
+// encryptBucket copies source bucket encrypting all codec encoded keys and values.
+func encryptBucket(dst, src *bolt.Bucket, codec *encryptedCodec) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(key, value []byte) error {
+		if value == nil {
+			bucket, err := dst.CreateBucket(key)
+			if err != nil {
+				return err
+			}
+			if string(key) == "__storm_metadata" {
+				if err := copyBucket(bucket, src.Bucket(key)); err != nil {
+					return err
+				}
+				return bucket.Put([]byte("codec"), []byte(codec.Name()))
+			}
+			return encryptBucket(bucket, src.Bucket(key), codec)
+		}
+
+		// Source data is only valid until the source transaction is closed.
+		key, value = append([]byte(nil), key...), append([]byte(nil), value...)
+
+		// Storm codec output is JSON, raw keys (strings, numbers, binary) are left as is.
+		if json.Valid(key) {
+			key = codec.seal(key)
+		}
+		if json.Valid(value) {
+			value = codec.seal(value)
+		}
+		return dst.Put(key, value)
+	})
+}
+
+func copyBucket(dst, src *bolt.Bucket) error {
+	if err := dst.SetSequence(src.Sequence()); err != nil {
+		return err
+	}
+
+	return src.ForEach(func(key, value []byte) error {
+		if value == nil {
+			bucket, err := dst.CreateBucket(key)
+			if err != nil {
+				return err
+			}
+			return copyBucket(bucket, src.Bucket(key))
+		}
+		return dst.Put(append([]byte(nil), key...), append([]byte(nil), value...))
+	})
+}
+
+// encryptedCodec encodes values as JSON encrypted with AES-GCM.
+type encryptedCodec struct {
+	aead   cipher.AEAD
+	macKey []byte
+}
+
+func newEncryptedCodec(passphrase string, salt []byte) (*encryptedCodec, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 2*keyLen)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key[:keyLen])
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &encryptedCodec{aead: aead, macKey: key[keyLen:]}, nil
+}
+
+// Marshal encodes given value to encrypted JSON.
+func (c *encryptedCodec) Marshal(v interface{}) ([]byte, error) {
+	plaintext, err := storm_json.Codec.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	return c.seal(plaintext), nil
+}
+
+// Unmarshal decodes given encrypted JSON to value.
+func (c *encryptedCodec) Unmarshal(b []byte, v interface{}) error {
+	plaintext, err := c.open(b)
+	if err != nil {
+		return err
+	}
+	return storm_json.Codec.Unmarshal(plaintext, v)
+}
+
+// Name returns codec name stored in storm metadata.
+func (c *encryptedCodec) Name() string {
+	return encryptionCodecName
+}
+
+func (c *encryptedCodec) seal(plaintext []byte) []byte {
+	mac := hmac.New(sha256.New, c.macKey)
+	mac.Write(plaintext)
+	nonce := mac.Sum(nil)[:c.aead.NonceSize()]
+	return c.aead.Seal(nonce, nonce, plaintext, nil)
+}
+
+func (c *encryptedCodec) open(ciphertext []byte) ([]byte, error) {
+	nonceSize := c.aead.NonceSize()
+	if len(ciphertext) < nonceSize {
+		return nil, ErrEncryptedValueCorrupted
+	}
+	plaintext, err := c.aead.Open(nil, ciphertext[:nonceSize], ciphertext[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrEncryptedValueCorrupted
+	}
+	return plaintext, nil
+}
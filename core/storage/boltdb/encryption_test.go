@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+)
+
+type secretKey string
+
+type secretTestType struct {
+	ID     secretKey `storm:"id"`
+	Secret string
+}
+
+func Test_EncryptedStorage(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+
+	storage, err := NewEncryptedStorage(dir, "passphrase")
+	assert.Nil(t, err)
+
+	data := secretTestType{ID: "key", Secret: "very-secret-value"}
+	assert.Nil(t, storage.Store(bucket, &data))
+	assert.Nil(t, storage.SetValue(bucket, secretKey("value-key"), "another-secret-value"))
+	assert.Nil(t, storage.Close())
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "myst.db"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(raw), "very-secret-value")
+	assert.NotContains(t, string(raw), "another-secret-value")
+
+	storage, err = NewEncryptedStorage(dir, "passphrase")
+	assert.Nil(t, err)
+	defer storage.Close()
+
+	var result secretTestType
+	assert.Nil(t, storage.GetOneByField(bucket, "ID", data.ID, &result))
+	assert.Equal(t, data, result)
+
+	var value string
+	assert.Nil(t, storage.GetValue(bucket, secretKey("value-key"), &value))
+	assert.Equal(t, "another-secret-value", value)
+}
+
+func Test_EncryptedStorage_WrongPassphrase(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+
+	storage, err := NewEncryptedStorage(dir, "passphrase")
+	assert.Nil(t, err)
+	assert.Nil(t, storage.Close())
+
+	_, err = NewEncryptedStorage(dir, "wrong")
+	assert.Equal(t, ErrWrongPassphrase, errors.Cause(err))
+
+	_, err = NewStorage(dir)
+	assert.Error(t, err)
+}
+
+func Test_EncryptedStorage_EncryptsPlaintextDatabase(t *testing.T) {
+	dir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, dir)
+
+	storage, err := NewStorage(dir)
+	assert.Nil(t, err)
+	data := secretTestType{ID: "key", Secret: "very-secret-value"}
+	assert.Nil(t, storage.Store(bucket, &data))
+	assert.Nil(t, storage.Store(bucket, &myTestType{ID: 1}))
+	assert.Nil(t, storage.SetValue("values", "value-key", "another-secret-value"))
+	assert.Nil(t, storage.Close())
+
+	storage, err = NewEncryptedStorage(dir, "passphrase")
+	assert.Nil(t, err)
+	assert.Nil(t, storage.Close())
+
+	raw, err := ioutil.ReadFile(filepath.Join(dir, "myst.db"))
+	assert.Nil(t, err)
+	assert.NotContains(t, string(raw), "very-secret-value")
+	assert.NotContains(t, string(raw), "another-secret-value")
+
+	storage, err = NewEncryptedStorage(dir, "passphrase")
+	assert.Nil(t, err)
+	defer storage.Close()
+
+	var result secretTestType
+	assert.Nil(t, storage.GetOneByField(bucket, "ID", data.ID, &result))
+	assert.Equal(t, data, result)
+
+	var numbered myTestType
+	assert.Nil(t, storage.GetOneByField(bucket, "ID", int64(1), &numbered))
+
+	var value string
+	assert.Nil(t, storage.GetValue("values", "value-key", &value))
+	assert.Equal(t, "another-secret-value", value)
+
+	data.Secret = "updated-secret-value"
+	assert.Nil(t, storage.Store(bucket, &data))
+	assert.Nil(t, storage.GetOneByField(bucket, "ID", data.ID, &result))
+	assert.Equal(t, data, result)
+}
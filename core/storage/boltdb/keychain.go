@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	keychainService = "mysterium-node"
+	keychainAccount = "storage"
+	keychainLabel   = "Mysterium node storage passphrase"
+)
+
+var (
+	// ErrKeychainUnsupported is returned when OS keychain is not supported on the current platform.
+	ErrKeychainUnsupported = errors.New("keychain is not supported on this platform")
+
+	errKeychainEntryNotFound = errors.New("keychain entry not found")
+)
+
+// KeychainPassphrase returns database passphrase kept in the OS keychain.
+// Random passphrase is generated and stored in the keychain on the first run.
+func KeychainPassphrase() (string, error) {
+	passphrase, err := keychainGet()
+	if err == nil {
+		return passphrase, nil
+	}
+	if err != errKeychainEntryNotFound {
+		return "", errors.Wrap(err, "failed to read passphrase from keychain")
+	}
+
+	secret := make([]byte, keyLen)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	passphrase = hex.EncodeToString(secret)
+	if err := keychainSet(passphrase); err != nil {
+		return "", errors.Wrap(err, "failed to store passphrase to keychain")
+	}
+	return passphrase, nil
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package boltdb
+
+import (
+	"os/exec"
+	"strings"
+)
+
+func keychainGet() (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", keychainService, "account", keychainAccount).Output()
+	if _, ok := err.(*exec.ExitError); ok && len(out) == 0 {
+		return "", errKeychainEntryNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+func keychainSet(secret string) error {
+	cmd := exec.Command("secret-tool", "store", "--label", keychainLabel, "service", keychainService, "account", keychainAccount)
+	cmd.Stdin = strings.NewReader(secret)
+	return cmd.Run()
+}
@@ -31,4 +31,22 @@ var Sequence = []migrations.Migration{
 			2018, 12, 04, 12, 00, 00, 0, time.UTC),
 		Migrate: migrations.MigrateSessionToHistory,
 	},
+	{
+		Name: "session-history-to-session-log",
+		Date: time.Date(
+			2020, 06, 17, 12, 00, 00, 0, time.UTC),
+		Migrate: migrations.MigrateSessionHistoryToLog,
+	},
+	{
+		Name: "session-log-search-index",
+		Date: time.Date(
+			2020, 07, 21, 12, 00, 00, 0, time.UTC),
+		Migrate: migrations.MigrateSessionLogSearchIndex,
+	},
+	{
+		Name: "tokens-to-strings",
+		Date: time.Date(
+			2020, 07, 28, 12, 00, 00, 0, time.UTC),
+		Migrate: migrations.MigrateTokensToStrings,
+	},
 }
@@ -0,0 +1,40 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"github.com/asdine/storm/v3"
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	bolt "go.etcd.io/bbolt"
+)
+
+const sessionHistoryBucketName = "session-history"
+
+// MigrateSessionHistoryToLog copies session history records into the append-only session event log.
+// Session history bucket is left intact, so that it can still be read by older node versions.
+func MigrateSessionHistoryToLog(db *storm.DB) error {
+	sessions := []consumer_session.History{}
+	err := db.From(sessionHistoryBucketName).All(&sessions)
+	if err != nil && err != storm.ErrNotFound {
+		return err
+	}
+
+	return db.Bolt.Update(func(tx *bolt.Tx) error {
+		return consumer_session.WriteEventLog(tx, db.Codec(), sessions...)
+	})
+}
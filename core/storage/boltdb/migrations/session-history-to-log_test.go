@@ -0,0 +1,81 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"testing"
+	"time"
+
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	node_session "github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestSessionHistoryToLogMigrationWithNoData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	err := MigrateSessionHistoryToLog(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, countSessionLogRecords(t, db.Bolt))
+}
+
+func TestSessionHistoryToLogMigrationWithData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	historyBucket := db.From(sessionHistoryBucketName)
+	err := historyBucket.Save(&consumer_session.History{
+		SessionID: node_session.ID("sessionID1"),
+		Status:    consumer_session.StatusCompleted,
+		Started:   time.Now().UTC(),
+	})
+	assert.Nil(t, err)
+	err = historyBucket.Save(&consumer_session.History{
+		SessionID: node_session.ID("sessionID2"),
+		Status:    consumer_session.StatusNew,
+		Started:   time.Now().UTC(),
+	})
+	assert.Nil(t, err)
+
+	err = MigrateSessionHistoryToLog(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, countSessionLogRecords(t, db.Bolt))
+
+	err = db.Bolt.View(func(tx *bolt.Tx) error {
+		assert.NotNil(t, tx.Bucket([]byte(sessionHistoryBucketName)))
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func countSessionLogRecords(t *testing.T, db *bolt.DB) int {
+	var count int
+	err := db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("session-log"))
+		if bucket == nil {
+			return nil
+		}
+		count = bucket.Stats().KeyN
+		return nil
+	})
+	assert.Nil(t, err)
+	return count
+}
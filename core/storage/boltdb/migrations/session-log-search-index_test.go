@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"testing"
+	"time"
+
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/identity"
+	node_session "github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestSessionLogSearchIndexMigrationWithNoData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	err := MigrateSessionLogSearchIndex(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, countSessionSearchRecords(t, db.Bolt))
+}
+
+func TestSessionLogSearchIndexMigrationWithData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	session := consumer_session.History{
+		SessionID:       node_session.ID("sessionID1"),
+		ConsumerID:      identity.FromAddress("0x1"),
+		ProviderID:      identity.FromAddress("0x2"),
+		ProviderCountry: "LT",
+		ServiceType:     "wireguard",
+		Status:          consumer_session.StatusCompleted,
+		Started:         time.Now().UTC(),
+	}
+	err := db.Bolt.Update(func(tx *bolt.Tx) error {
+		if err := consumer_session.WriteEventLog(tx, db.Codec(), session); err != nil {
+			return err
+		}
+		return tx.DeleteBucket([]byte("session-search"))
+	})
+	assert.Nil(t, err)
+
+	err = MigrateSessionLogSearchIndex(db)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, countSessionSearchRecords(t, db.Bolt))
+}
+
+func countSessionSearchRecords(t *testing.T, db *bolt.DB) int {
+	var count int
+	err := db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket([]byte("session-search"))
+		if bucket == nil {
+			return nil
+		}
+		count = bucket.Stats().KeyN
+		return nil
+	})
+	assert.Nil(t, err)
+	return count
+}
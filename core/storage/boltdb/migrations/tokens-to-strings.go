@@ -0,0 +1,61 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"github.com/asdine/storm/v3"
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	consumerStatsBucketName  = "provider_consumer_stats"
+	earningsSeriesBucketName = "provider_earnings_series"
+)
+
+// MigrateTokensToStrings stores amounts of tokens, which used to be stored as JSON numbers, as decimal strings
+func MigrateTokensToStrings(db *storm.DB) error {
+	return db.Bolt.Update(func(tx *bolt.Tx) error {
+		if err := consumer_session.RecodeEventLog(tx, db.Codec()); err != nil {
+			return err
+		}
+
+		node := db.WithTransaction(tx)
+		stats := []consumer_session.ConsumerStats{}
+		if err := node.From(consumerStatsBucketName).All(&stats); err != nil {
+			return err
+		}
+		for i := range stats {
+			if err := node.From(consumerStatsBucketName).Save(&stats[i]); err != nil {
+				return err
+			}
+		}
+
+		points := []pingpong.EarningsPoint{}
+		if err := node.From(earningsSeriesBucketName).All(&points); err != nil {
+			return err
+		}
+		for i := range points {
+			if err := node.From(earningsSeriesBucketName).Save(&points[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package migrations
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	consumer_session "github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	node_session "github.com/mysteriumnetwork/node/session"
+	"github.com/stretchr/testify/assert"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestTokensToStringsMigrationWithNoData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	err := MigrateTokensToStrings(db)
+	assert.Nil(t, err)
+}
+
+func TestTokensToStringsMigrationWithData(t *testing.T) {
+	file, db := boltdbtest.CreateDB(t)
+	defer boltdbtest.CleanupDB(t, file, db)
+
+	session := consumer_session.History{
+		SessionID:  node_session.ID("sessionID1"),
+		ConsumerID: identity.FromAddress("0x1"),
+		Status:     consumer_session.StatusCompleted,
+		Started:    time.Now().UTC(),
+		Tokens:     money.NewTokens(500),
+	}
+	err := db.Bolt.Update(func(tx *bolt.Tx) error {
+		return consumer_session.WriteEventLog(tx, db.Codec(), session)
+	})
+	assert.Nil(t, err)
+	err = db.From(consumerStatsBucketName).Save(&consumer_session.ConsumerStats{ConsumerHash: "hash1", Tokens: money.NewTokens(30)})
+	assert.Nil(t, err)
+
+	// amounts used to be stored as JSON numbers
+	replaceStoredValues(t, db.Bolt, `"Tokens":"500"`, `"Tokens":500`, "session-log")
+	replaceStoredValues(t, db.Bolt, `"Tokens":"30"`, `"Tokens":30`, consumerStatsBucketName, "ConsumerStats")
+
+	err = MigrateTokensToStrings(db)
+	assert.Nil(t, err)
+
+	assert.Contains(t, storedValues(t, db.Bolt, "session-log"), `"Tokens":"500"`)
+	assert.Contains(t, storedValues(t, db.Bolt, consumerStatsBucketName, "ConsumerStats"), `"Tokens":"30"`)
+
+	var stats consumer_session.ConsumerStats
+	err = db.From(consumerStatsBucketName).One("ConsumerHash", "hash1", &stats)
+	assert.Nil(t, err)
+	assert.Equal(t, money.NewTokens(30), stats.Tokens)
+}
+
+func nestedBucket(tx *bolt.Tx, path ...string) *bolt.Bucket {
+	bucket := tx.Bucket([]byte(path[0]))
+	for _, name := range path[1:] {
+		bucket = bucket.Bucket([]byte(name))
+	}
+	return bucket
+}
+
+func replaceStoredValues(t *testing.T, db *bolt.DB, old, new string, path ...string) {
+	err := db.Update(func(tx *bolt.Tx) error {
+		bucket := nestedBucket(tx, path...)
+		values := make(map[string][]byte)
+		err := bucket.ForEach(func(k, v []byte) error {
+			if v != nil {
+				values[string(k)] = bytes.Replace(v, []byte(old), []byte(new), -1)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		for k, v := range values {
+			if err := bucket.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	assert.Nil(t, err)
+}
+
+func storedValues(t *testing.T, db *bolt.DB, path ...string) string {
+	var values string
+	err := db.View(func(tx *bolt.Tx) error {
+		return nestedBucket(tx, path...).ForEach(func(k, v []byte) error {
+			values += string(v)
+			return nil
+		})
+	})
+	assert.Nil(t, err)
+	return values
+}
@@ -21,7 +21,9 @@ import (
 	"path/filepath"
 
 	"github.com/asdine/storm/v3"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/pkg/errors"
+	bolt "go.etcd.io/bbolt"
 )
 
 // Bolt is a wrapper around boltdb
@@ -90,7 +92,21 @@ func (b *Bolt) DB() *storm.DB {
 	return b.db
 }
 
+// Path returns the path of the database file.
+func (b *Bolt) Path() string {
+	return b.db.Bolt.Path()
+}
+
+// Snapshot writes a consistent copy of the database to the given file while it stays open for writes.
+func (b *Bolt) Snapshot(path string) error {
+	return b.db.Bolt.View(func(tx *bolt.Tx) error {
+		return tx.CopyFile(path, 0600)
+	})
+}
+
 // Close closes database
 func (b *Bolt) Close() error {
 	return b.db.Close()
 }
+
+var _ storage.Storage = &Bolt{}
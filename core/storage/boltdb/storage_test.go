@@ -18,6 +18,7 @@
 package boltdb
 
 import (
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -113,3 +114,26 @@ func Test_StorageGetLast_ErrsOnEmpty(t *testing.T) {
 	err = storage.GetLast(bucket, &result)
 	assert.Equal(t, "not found", err.Error())
 }
+
+func Test_StorageSnapshot(t *testing.T) {
+	storage, close, err := createMockStorage(t)
+	assert.Nil(t, err)
+	defer close()
+
+	err = storage.Store(bucket, &myTestType{ID: 1})
+	assert.Nil(t, err)
+
+	snapshotDir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, snapshotDir)
+	err = storage.Snapshot(filepath.Join(snapshotDir, "myst.db"))
+	assert.Nil(t, err)
+
+	snapshot, err := NewStorage(snapshotDir)
+	assert.Nil(t, err)
+	defer snapshot.Close()
+
+	var res myTestType
+	err = snapshot.GetOneByField(bucket, "ID", int64(1), &res)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1), res.ID)
+}
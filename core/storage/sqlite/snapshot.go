@@ -0,0 +1,69 @@
+// +build cgo
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+// Snapshot writes a consistent copy of the database to the given file using the SQLite online backup API.
+func (s *Storage) Snapshot(path string) error {
+	ctx := context.Background()
+
+	dst, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return errors.Wrap(err, "failed to open snapshot file")
+	}
+	defer dst.Close()
+
+	dstConn, err := dst.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to open snapshot file")
+	}
+	defer dstConn.Close()
+
+	srcConn, err := s.db.Conn(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to acquire database connection")
+	}
+	defer srcConn.Close()
+
+	return dstConn.Raw(func(dstDriverConn interface{}) error {
+		return srcConn.Raw(func(srcDriverConn interface{}) error {
+			return copyDB(dstDriverConn.(*sqlite3.SQLiteConn), srcDriverConn.(*sqlite3.SQLiteConn))
+		})
+	})
+}
+
+func copyDB(dst, src *sqlite3.SQLiteConn) error {
+	backup, err := dst.Backup("main", src, "main")
+	if err != nil {
+		return errors.Wrap(err, "failed to start SQLite backup")
+	}
+	if _, err := backup.Step(-1); err != nil {
+		backup.Close()
+		return errors.Wrap(err, "failed to copy SQLite database")
+	}
+	return backup.Finish()
+}
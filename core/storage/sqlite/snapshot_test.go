@@ -0,0 +1,46 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_StorageSnapshot(t *testing.T) {
+	s, cleanup := createMockStorage(t)
+	defer cleanup()
+
+	data := myTestType{ID: 1, Name: "name"}
+	assert.NoError(t, s.Store(bucket, &data))
+
+	snapshotDir := boltdbtest.CreateTempDir(t)
+	defer boltdbtest.RemoveTempDir(t, snapshotDir)
+	assert.NoError(t, s.Snapshot(filepath.Join(snapshotDir, "myst.sqlite")))
+
+	snapshot, err := NewStorage(snapshotDir)
+	assert.NoError(t, err)
+	defer snapshot.Close()
+
+	var result myTestType
+	assert.NoError(t, snapshot.GetOneByField(bucket, "ID", int64(1), &result))
+	assert.Equal(t, data, result)
+}
@@ -0,0 +1,386 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	// Registers sqlite3 driver.
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/pkg/errors"
+)
+
+// Objects are kept in a single table as JSON documents identified by bucket and ID.
+// IDs are encoded the same way as storm does, so entries are ordered identically to BoltDB.
+const schema = `
+CREATE TABLE IF NOT EXISTS objects (
+	bucket TEXT NOT NULL,
+	id     BLOB NOT NULL,
+	value  BLOB NOT NULL,
+	PRIMARY KEY (bucket, id)
+)`
+
+var (
+	// ErrNoID is returned when struct has no ID field.
+	ErrNoID = errors.New("missing struct tag id or ID field")
+	// ErrZeroID is returned when struct ID field has a zero value.
+	ErrZeroID = errors.New("id field must not be a zero value")
+	// ErrStructPtrNeeded is returned when argument is not a pointer to struct.
+	ErrStructPtrNeeded = errors.New("provided target must be a pointer to struct")
+	// ErrSlicePtrNeeded is returned when argument is not a pointer to slice.
+	ErrSlicePtrNeeded = errors.New("provided target must be a pointer to slice")
+)
+
+// Storage is a SQLite based storage of the node.
+type Storage struct {
+	db   *sql.DB
+	path string
+}
+
+var _ storage.Storage = &Storage{}
+
+// NewStorage creates a new SQLite storage in the given directory.
+func NewStorage(path string) (*Storage, error) {
+	return openDB(filepath.Join(path, "myst.sqlite"))
+}
+
+func openDB(name string) (*Storage, error) {
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?_busy_timeout=5000&_journal_mode=WAL", name))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open SQLite")
+	}
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "failed to create SQLite schema")
+	}
+	return &Storage{db: db, path: name}, nil
+}
+
+// GetValue gets key value
+func (s *Storage) GetValue(bucket string, key interface{}, to interface{}) error {
+	id, err := toBytes(key)
+	if err != nil {
+		return err
+	}
+	return s.get(bucket, id, to)
+}
+
+// SetValue sets key value
+func (s *Storage) SetValue(bucket string, key interface{}, value interface{}) error {
+	id, err := toBytes(key)
+	if err != nil {
+		return err
+	}
+	return s.put(bucket, id, value)
+}
+
+// Store allows to keep struct grouped by the bucket
+func (s *Storage) Store(bucket string, data interface{}) error {
+	id, err := structID(data)
+	if err != nil {
+		return err
+	}
+	return s.put(bucket, id, data)
+}
+
+// GetAllFrom allows to get all structs from the bucket
+func (s *Storage) GetAllFrom(bucket string, data interface{}) error {
+	ref := reflect.ValueOf(data)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Slice {
+		return ErrSlicePtrNeeded
+	}
+
+	rows, err := s.db.Query("SELECT value FROM objects WHERE bucket = ? ORDER BY id", bucket)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	results := reflect.MakeSlice(ref.Elem().Type(), 0, 0)
+	elemType := ref.Elem().Type().Elem()
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return err
+		}
+
+		elem := newElem(elemType)
+		if err := json.Unmarshal(raw, elem.Interface()); err != nil {
+			return err
+		}
+		if elemType.Kind() == reflect.Ptr {
+			results = reflect.Append(results, elem)
+		} else {
+			results = reflect.Append(results, elem.Elem())
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	ref.Elem().Set(results)
+	return nil
+}
+
+// Delete removes the given struct from the given bucket
+func (s *Storage) Delete(bucket string, data interface{}) error {
+	id, err := structID(data)
+	if err != nil {
+		return err
+	}
+
+	res, err := s.db.Exec("DELETE FROM objects WHERE bucket = ? AND id = ?", bucket, id)
+	if err != nil {
+		return err
+	}
+	if count, err := res.RowsAffected(); err != nil {
+		return err
+	} else if count == 0 {
+		return storage.ErrNotFound
+	}
+	return nil
+}
+
+// Update allows to update the struct in the given bucket, only non-zero fields are updated
+func (s *Storage) Update(bucket string, object interface{}) error {
+	id, err := structID(object)
+	if err != nil {
+		return err
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var raw []byte
+	err = tx.QueryRow("SELECT value FROM objects WHERE bucket = ? AND id = ?", bucket, id).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	} else if err != nil {
+		return err
+	}
+
+	updated := reflect.ValueOf(object).Elem()
+	current := reflect.New(updated.Type())
+	if err := json.Unmarshal(raw, current.Interface()); err != nil {
+		return err
+	}
+	for i := 0; i < updated.NumField(); i++ {
+		if field := updated.Field(i); current.Elem().Field(i).CanSet() && !field.IsZero() {
+			current.Elem().Field(i).Set(field)
+		}
+	}
+
+	value, err := json.Marshal(current.Interface())
+	if err != nil {
+		return err
+	}
+	if _, err := tx.Exec("UPDATE objects SET value = ? WHERE bucket = ? AND id = ?", value, bucket, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// GetOneByField returns an object from the given bucket by the given field
+func (s *Storage) GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error {
+	ref := reflect.ValueOf(to)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return ErrStructPtrNeeded
+	}
+
+	value, err := toBytes(key)
+	if err != nil {
+		return err
+	}
+	if idField, ok := idFieldOf(ref.Elem().Type()); ok && idField.Name == fieldName {
+		return s.get(bucket, value, to)
+	}
+
+	rows, err := s.db.Query("SELECT value FROM objects WHERE bucket = ? ORDER BY id", bucket)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var raw []byte
+		if err := rows.Scan(&raw); err != nil {
+			return err
+		}
+
+		elem := reflect.New(ref.Elem().Type())
+		if err := json.Unmarshal(raw, elem.Interface()); err != nil {
+			return err
+		}
+		field := elem.Elem().FieldByName(fieldName)
+		if !field.IsValid() {
+			return fmt.Errorf("field %s not found", fieldName)
+		}
+		fieldValue, err := toBytes(field.Interface())
+		if err != nil {
+			return err
+		}
+		if bytes.Equal(fieldValue, value) {
+			ref.Elem().Set(elem.Elem())
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	return storage.ErrNotFound
+}
+
+// GetLast returns the last entry in the bucket
+func (s *Storage) GetLast(bucket string, to interface{}) error {
+	var raw []byte
+	err := s.db.QueryRow("SELECT value FROM objects WHERE bucket = ? ORDER BY id DESC LIMIT 1", bucket).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, to)
+}
+
+// GetBuckets returns a list of buckets
+func (s *Storage) GetBuckets() []string {
+	rows, err := s.db.Query("SELECT DISTINCT bucket FROM objects ORDER BY bucket")
+	if err != nil {
+		return nil
+	}
+	defer rows.Close()
+
+	var buckets []string
+	for rows.Next() {
+		var bucket string
+		if err := rows.Scan(&bucket); err != nil {
+			return buckets
+		}
+		buckets = append(buckets, bucket)
+	}
+	return buckets
+}
+
+// DB returns raw SQL database.
+func (s *Storage) DB() *sql.DB {
+	return s.db
+}
+
+// Path returns the path of the database file.
+func (s *Storage) Path() string {
+	return s.path
+}
+
+// Close closes database
+func (s *Storage) Close() error {
+	return s.db.Close()
+}
+
+func (s *Storage) get(bucket string, id []byte, to interface{}) error {
+	var raw []byte
+	err := s.db.QueryRow("SELECT value FROM objects WHERE bucket = ? AND id = ?", bucket, id).Scan(&raw)
+	if err == sql.ErrNoRows {
+		return storage.ErrNotFound
+	} else if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, to)
+}
+
+func (s *Storage) put(bucket string, id []byte, data interface{}) error {
+	value, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec("INSERT OR REPLACE INTO objects (bucket, id, value) VALUES (?, ?, ?)", bucket, id, value)
+	return err
+}
+
+func newElem(elemType reflect.Type) reflect.Value {
+	if elemType.Kind() == reflect.Ptr {
+		return reflect.New(elemType.Elem())
+	}
+	return reflect.New(elemType)
+}
+
+// idFieldOf finds ID field of the struct the same way storm does: tagged with `storm:"id"` or named ID.
+func idFieldOf(structType reflect.Type) (reflect.StructField, bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if tag := field.Tag.Get("storm"); tag == "id" || strings.HasPrefix(tag, "id,") {
+			return field, true
+		}
+	}
+	return structType.FieldByName("ID")
+}
+
+func structID(data interface{}) ([]byte, error) {
+	ref := reflect.ValueOf(data)
+	if ref.Kind() != reflect.Ptr || ref.Elem().Kind() != reflect.Struct {
+		return nil, ErrStructPtrNeeded
+	}
+
+	field, ok := idFieldOf(ref.Elem().Type())
+	if !ok {
+		return nil, ErrNoID
+	}
+	value := ref.Elem().FieldByIndex(field.Index)
+	if value.IsZero() {
+		return nil, ErrZeroID
+	}
+	return toBytes(value.Interface())
+}
+
+// toBytes encodes key the same way as storm does.
+func toBytes(key interface{}) ([]byte, error) {
+	switch t := key.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return t, nil
+	case string:
+		return []byte(t), nil
+	case int:
+		return numberToBytes(int64(t))
+	case uint:
+		return numberToBytes(uint64(t))
+	case int8, int16, int32, int64, uint8, uint16, uint32, uint64:
+		return numberToBytes(t)
+	default:
+		return json.Marshal(key)
+	}
+}
+
+func numberToBytes(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.BigEndian, v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sqlite
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/core/storage"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb/boltdbtest"
+	"github.com/stretchr/testify/assert"
+)
+
+type myTestType struct {
+	ID   int64 `storm:"id"`
+	Name string
+	Size int
+}
+
+type myKey string
+
+type myKeyedType struct {
+	Key  myKey `storm:"id"`
+	Name string
+}
+
+var bucket = "test"
+
+func createMockStorage(t *testing.T) (*Storage, func()) {
+	dir := boltdbtest.CreateTempDir(t)
+	s, err := NewStorage(dir)
+	assert.NoError(t, err)
+	return s, func() {
+		s.Close()
+		boltdbtest.RemoveTempDir(t, dir)
+	}
+}
+
+func Test_StorageGetByID(t *testing.T) {
+	s, cleanup := createMockStorage(t)
+	defer cleanup()
+
+	data := myKeyedType{Key: "key", Name: "name"}
+	assert.NoError(t, s.Store(bucket, &data))
+
+	var result myKeyedType
+	assert.NoError(t, s.GetOneByField(bucket, "Key", data.Key, &result))
+	assert.Equal(t, data, result)
+
+	err := s.GetOneByField(bucket, "Key", myKey("missing"), &result)
+	assert.Equal(t, storage.ErrNotFound, err)
+}
+
+func Test_StorageGetByField(t *testing.T) {
+	s, cleanup := createMockStorage(t)
+	defer cleanup()
+
+	assert.NoError(t, s.Store(bucket, &myTestType{ID: 1, Name: "first"}))
+	assert.NoError(t, s.Store(bucket, &myTestType{ID: 2, Name: "second"}))
+
+	var result myTestType
+	assert.NoError(t, s.GetOneByField(bucket, "Name", "second", &result))
+	assert.Equal(t, int64(2), result.ID)
+
+	err := s.GetOneByField(bucket, "Name", "third", &result)
+	assert.Equal(t, "not found", err.Error())
+}
+
+func Test_StorageGetAllAndLast(t *testing.T) {
+	s, cleanup := createMockStorage(t)
+	defer cleanup()
+
+	var results []myTestType
+	assert.NoError(t, s.GetAllFrom(bucket, &results))
+	assert.Len(t, results, 0)
+	assert.Equal(t, storage.ErrNotFound, s.GetLast(bucket, &myTestType{}))
+
+	assert.NoError(t, s.Store(bucket, &myTestType{ID: 2}))
+	assert.NoError(t, s.Store(bucket, &myTestType{ID: 1}))
+	assert.NoError(t, s.Store("other", &myTestType{ID: 3}))
+
+	assert.NoError(t, s.GetAllFrom(bucket, &results))
+	assert.Equal(t, []myTestType{{ID: 1}, {ID: 2}}, results)
+
+	var pointers []*myTestType
+	assert.NoError(t, s.GetAllFrom(bucket, &pointers))
+	assert.Len(t, pointers, 2)
+
+	var last myTestType
+	assert.NoError(t, s.GetLast(bucket, &last))
+	assert.Equal(t, int64(2), last.ID)
+
+	assert.Equal(t, []string{"other", bucket}, s.GetBuckets())
+}
+
+func Test_StorageUpdateAndDelete(t *testing.T) {
+	s, cleanup := createMockStorage(t)
+	defer cleanup()
+
+	assert.Equal(t, storage.ErrNotFound, s.Update(bucket, &myTestType{ID: 1, Name: "name"}))
+	assert.Equal(t, ErrZeroID, s.Store(bucket, &myTestType{}))
+
+	assert.NoError(t, s.Store(bucket, &myTestType{ID: 1, Name: "name", Size: 10}))
+	assert.NoError(t, s.Update(bucket, &myTestType{ID: 1, Name: "updated"}))
+
+	var result myTestType
+	assert.NoError(t, s.GetOneByField(bucket, "ID", int64(1), &result))
+	assert.Equal(t, myTestType{ID: 1, Name: "updated", Size: 10}, result)
+
+	assert.NoError(t, s.Delete(bucket, &result))
+	assert.Equal(t, storage.ErrNotFound, s.Delete(bucket, &result))
+}
+
+func Test_StorageKeyValues(t *testing.T) {
+	s, cleanup := createMockStorage(t)
+	defer cleanup()
+
+	var value string
+	assert.Equal(t, storage.ErrNotFound, s.GetValue(bucket, "key", &value))
+
+	assert.NoError(t, s.SetValue(bucket, "key", "value"))
+	assert.NoError(t, s.SetValue(bucket, "key", "updated"))
+	assert.NoError(t, s.GetValue(bucket, "key", &value))
+	assert.Equal(t, "updated", value)
+}
@@ -0,0 +1,347 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telemetry
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"runtime"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/rs/zerolog/log"
+)
+
+// SaltConfigKey is the user config key of salt used to hash identities.
+const SaltConfigKey = "telemetry.salt"
+
+// Config configures collection and sending of telemetry.
+type Config struct {
+	// Enabled is explicit consent of user, nothing leaves the node without it.
+	Enabled bool
+	// FlushInterval is the period covered by a single report.
+	FlushInterval time.Duration
+	// MaxBatch limits reports kept locally until they are sent.
+	MaxBatch int
+}
+
+// DefaultConfig returns default telemetry configuration with telemetry disabled.
+func DefaultConfig() Config {
+	return Config{
+		Enabled:       false,
+		FlushInterval: time.Hour,
+		MaxBatch:      24,
+	}
+}
+
+// Transport sends marshalled telemetry payload.
+type Transport interface {
+	Send(payload []byte) error
+}
+
+// Report is anonymized summary of node usage during a period.
+type Report struct {
+	// Identity is a salted hash of node identity, salt never leaves the node.
+	Identity string `json:"identity"`
+	// Country is the only location information reported.
+	Country     string         `json:"country"`
+	Version     string         `json:"version"`
+	OS          string         `json:"os"`
+	Arch        string         `json:"arch"`
+	PeriodStart int64          `json:"period_start"`
+	PeriodEnd   int64          `json:"period_end"`
+	Sessions    SessionCounts  `json:"sessions"`
+	Errors      map[string]int `json:"errors"`
+}
+
+// SessionCounts holds counts of sessions started by service type.
+type SessionCounts struct {
+	Consumer map[string]int `json:"consumer"`
+	Provider map[string]int `json:"provider"`
+}
+
+type payload struct {
+	Reports []Report `json:"reports"`
+}
+
+// failedTerminations are the session termination reasons counted as errors.
+var failedTerminations = map[string]bool{
+	session.TerminationSetupFailed:    true,
+	session.TerminationPeerLost:       true,
+	session.TerminationConnectionLost: true,
+	session.TerminationPaymentFailed:  true,
+}
+
+// Collector counts node usage events into reports and sends them if user consented.
+type Collector struct {
+	config     Config
+	transport  Transport
+	location   location.OriginResolver
+	salt       []byte
+	appVersion string
+	now        func() time.Time
+
+	mu       sync.Mutex
+	identity string
+	current  Report
+	batch    []Report
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewCollector creates telemetry collector, identities are hashed with the given salt.
+func NewCollector(config Config, transport Transport, locationResolver location.OriginResolver, salt string, appVersion string) *Collector {
+	c := &Collector{
+		config:     config,
+		transport:  transport,
+		location:   locationResolver,
+		salt:       []byte(salt),
+		appVersion: appVersion,
+		now:        time.Now,
+		stop:       make(chan struct{}),
+	}
+	c.current = c.newReport(c.now())
+	return c
+}
+
+// NewSalt generates random salt for hashing identities.
+func NewSalt() (string, error) {
+	salt := make([]byte, 32)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(salt), nil
+}
+
+// Enabled returns true if user consented to send telemetry.
+func (c *Collector) Enabled() bool {
+	return c.config.Enabled
+}
+
+// Subscribe subscribes to relevant events of event bus.
+func (c *Collector) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(identity.AppTopicIdentityUnlock, c.consumeIdentityUnlock); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(connection.AppTopicConnectionSession, c.consumeConnectionSession); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(connection.AppTopicConnectionState, c.consumeConnectionState); err != nil {
+		return err
+	}
+	if err := bus.SubscribeAsync(connection.AppTopicConnectionAttempt, c.consumeConnectionAttempt); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(sessionEvent.AppTopicSession, c.consumeServiceSession)
+}
+
+// Start flushes reports periodically until stopped.
+func (c *Collector) Start() {
+	ticker := time.NewTicker(c.config.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.flush()
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+// Stop stops collector and flushes the last report.
+func (c *Collector) Stop() {
+	c.stopOnce.Do(func() {
+		close(c.stop)
+		c.flush()
+	})
+}
+
+// Preview returns exactly the payload which would be sent on the next flush.
+func (c *Collector) Preview() ([]byte, error) {
+	c.mu.Lock()
+	reports := append([]Report{}, c.batch...)
+	current := copyReport(c.current)
+	c.mu.Unlock()
+
+	if !isEmpty(current) {
+		reports = append(reports, c.closeReport(current, c.now()))
+	}
+	return json.Marshal(payload{Reports: reports})
+}
+
+func (c *Collector) flush() {
+	now := c.now()
+
+	c.mu.Lock()
+	closed := c.current
+	c.current = c.newReport(now)
+	c.mu.Unlock()
+
+	if !isEmpty(closed) {
+		closed = c.closeReport(closed, now)
+	}
+
+	c.mu.Lock()
+	if !isEmpty(closed) {
+		c.batch = append(c.batch, closed)
+	}
+	if len(c.batch) > c.config.MaxBatch {
+		c.batch = c.batch[len(c.batch)-c.config.MaxBatch:]
+	}
+	reports := append([]Report{}, c.batch...)
+	c.mu.Unlock()
+
+	if !c.config.Enabled || len(reports) == 0 {
+		return
+	}
+
+	data, err := json.Marshal(payload{Reports: reports})
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal telemetry reports")
+		return
+	}
+	if err := c.transport.Send(data); err != nil {
+		log.Warn().Err(err).Msg("Failed to send telemetry reports")
+		return
+	}
+
+	c.mu.Lock()
+	c.batch = c.batch[len(reports):]
+	c.mu.Unlock()
+}
+
+func (c *Collector) consumeIdentityUnlock(address string) {
+	mac := hmac.New(sha256.New, c.salt)
+	mac.Write([]byte(address))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.identity = hex.EncodeToString(mac.Sum(nil)[:16])
+	c.current.Identity = c.identity
+}
+
+func (c *Collector) consumeConnectionSession(e connection.AppEventConnectionSession) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch e.Status {
+	case connection.SessionCreatedStatus:
+		c.current.Sessions.Consumer[e.SessionInfo.Proposal.ServiceType]++
+	case connection.SessionEndedStatus:
+		if failedTerminations[e.SessionInfo.TerminationReason] {
+			c.current.Errors["session_"+e.SessionInfo.TerminationReason]++
+		}
+	}
+}
+
+func (c *Collector) consumeConnectionState(e connection.AppEventConnectionState) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch e.State {
+	case connection.StateConnectionFailed:
+		c.current.Errors["connection_failed"]++
+	case connection.StateIPNotChanged:
+		c.current.Errors["ip_not_changed"]++
+	}
+	if e.SessionInfo.TimedOutStage != "" {
+		c.current.Errors["handshake_timeout_"+string(e.SessionInfo.TimedOutStage)]++
+	}
+}
+
+func (c *Collector) consumeConnectionAttempt(e connection.AppEventConnectionAttempt) {
+	if e.Connected {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.current.Errors["connection_attempt_failed"]++
+}
+
+func (c *Collector) consumeServiceSession(e sessionEvent.AppEventSession) {
+	if e.Status != sessionEvent.CreatedStatus {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.current.Sessions.Provider[e.Session.Proposal.ServiceType]++
+}
+
+func (c *Collector) newReport(start time.Time) Report {
+	return Report{
+		Identity:    c.identity,
+		Version:     c.appVersion,
+		OS:          runtime.GOOS,
+		Arch:        runtime.GOARCH,
+		PeriodStart: start.Unix(),
+		Sessions: SessionCounts{
+			Consumer: make(map[string]int),
+			Provider: make(map[string]int),
+		},
+		Errors: make(map[string]int),
+	}
+}
+
+// closeReport completes report with the coarse location, report maps must not be modified afterwards.
+func (c *Collector) closeReport(report Report, end time.Time) Report {
+	report.PeriodEnd = end.Unix()
+	origin, err := c.location.GetOrigin()
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to get origin country for telemetry")
+	}
+	report.Country = origin.Country
+	return report
+}
+
+func copyReport(report Report) Report {
+	report.Sessions = SessionCounts{
+		Consumer: copyCounts(report.Sessions.Consumer),
+		Provider: copyCounts(report.Sessions.Provider),
+	}
+	report.Errors = copyCounts(report.Errors)
+	return report
+}
+
+func copyCounts(counts map[string]int) map[string]int {
+	result := make(map[string]int, len(counts))
+	for key, count := range counts {
+		result[key] = count
+	}
+	return result
+}
+
+func isEmpty(report Report) bool {
+	return len(report.Sessions.Consumer) == 0 && len(report.Sessions.Provider) == 0 && len(report.Errors) == 0
+}
@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockOriginResolver struct{}
+
+func (r *mockOriginResolver) GetOrigin() (location.Location, error) {
+	return location.Location{IP: "1.2.3.4", ISP: "Telia", Country: "LT", City: "Vilnius"}, nil
+}
+
+type mockTransport struct {
+	sent [][]byte
+	err  error
+}
+
+func (t *mockTransport) Send(payload []byte) error {
+	if t.err != nil {
+		return t.err
+	}
+	t.sent = append(t.sent, payload)
+	return nil
+}
+
+func newTestCollector(enabled bool, transport Transport) *Collector {
+	config := DefaultConfig()
+	config.Enabled = enabled
+	config.MaxBatch = 2
+	collector := NewCollector(config, transport, &mockOriginResolver{}, "salt", "1.0.0")
+	now := time.Unix(1600000000, 0)
+	collector.now = func() time.Time { return now }
+	collector.current = collector.newReport(now)
+	return collector
+}
+
+func collectEvents(c *Collector) {
+	c.consumeIdentityUnlock("0x0000000000000000000000000000000000000001")
+	c.consumeConnectionSession(connection.AppEventConnectionSession{
+		Status:      connection.SessionCreatedStatus,
+		SessionInfo: connection.Status{Proposal: market.ServiceProposal{ServiceType: "wireguard"}},
+	})
+	c.consumeConnectionSession(connection.AppEventConnectionSession{
+		Status:      connection.SessionEndedStatus,
+		SessionInfo: connection.Status{TerminationReason: session.TerminationPeerLost},
+	})
+	c.consumeConnectionSession(connection.AppEventConnectionSession{
+		Status:      connection.SessionEndedStatus,
+		SessionInfo: connection.Status{TerminationReason: session.TerminationRequested},
+	})
+	c.consumeConnectionState(connection.AppEventConnectionState{State: connection.StateConnectionFailed})
+	c.consumeConnectionAttempt(connection.AppEventConnectionAttempt{Connected: false, Error: "provider 0x123 is unreachable"})
+	c.consumeServiceSession(sessionEvent.AppEventSession{
+		Status:  sessionEvent.CreatedStatus,
+		Session: sessionEvent.SessionContext{Proposal: market.ServiceProposal{ServiceType: "openvpn"}},
+	})
+}
+
+func TestCollector_PreviewShowsAnonymizedReport(t *testing.T) {
+	// given
+	collector := newTestCollector(false, &mockTransport{})
+	collectEvents(collector)
+
+	// when
+	preview, err := collector.Preview()
+
+	// then
+	assert.NoError(t, err)
+	var result payload
+	assert.NoError(t, json.Unmarshal(preview, &result))
+	assert.Len(t, result.Reports, 1)
+	report := result.Reports[0]
+	assert.Len(t, report.Identity, 32)
+	assert.NotContains(t, string(preview), "0x0000000000000000000000000000000000000001")
+	assert.NotContains(t, string(preview), "Vilnius")
+	assert.NotContains(t, string(preview), "1.2.3.4")
+	assert.NotContains(t, string(preview), "unreachable")
+	assert.Equal(t, "LT", report.Country)
+	assert.Equal(t, map[string]int{"wireguard": 1}, report.Sessions.Consumer)
+	assert.Equal(t, map[string]int{"openvpn": 1}, report.Sessions.Provider)
+	assert.Equal(t, map[string]int{
+		"session_peer_lost":         1,
+		"connection_failed":         1,
+		"connection_attempt_failed": 1,
+	}, report.Errors)
+}
+
+func TestCollector_HashesIdentityWithSalt(t *testing.T) {
+	collector := newTestCollector(false, &mockTransport{})
+	other := newTestCollector(false, &mockTransport{})
+	other.salt = []byte("other")
+
+	collector.consumeIdentityUnlock("0x1")
+	other.consumeIdentityUnlock("0x1")
+
+	assert.NotEqual(t, collector.identity, other.identity)
+}
+
+func TestCollector_FlushSendsOnlyWithConsent(t *testing.T) {
+	// given
+	transport := &mockTransport{}
+	collector := newTestCollector(false, transport)
+	collectEvents(collector)
+
+	// when
+	preview, err := collector.Preview()
+	assert.NoError(t, err)
+	collector.flush()
+
+	// then
+	assert.Empty(t, transport.sent)
+
+	// when
+	collector.config.Enabled = true
+	collector.flush()
+
+	// then
+	assert.Equal(t, [][]byte{preview}, transport.sent)
+	assert.Empty(t, collector.batch)
+}
+
+func TestCollector_KeepsLimitedBatchWhenSendingFails(t *testing.T) {
+	// given
+	transport := &mockTransport{err: errors.New("unreachable")}
+	collector := newTestCollector(true, transport)
+
+	// when
+	for i := 0; i < 3; i++ {
+		collectEvents(collector)
+		collector.flush()
+	}
+
+	// then
+	assert.Len(t, collector.batch, 2)
+
+	// when
+	transport.err = nil
+	collector.flush()
+
+	// then
+	assert.Len(t, transport.sent, 1)
+	assert.Empty(t, collector.batch)
+}
+
+func TestCollector_SkipsEmptyReports(t *testing.T) {
+	transport := &mockTransport{}
+	collector := newTestCollector(true, transport)
+
+	collector.consumeIdentityUnlock("0x1")
+	collector.flush()
+
+	assert.Empty(t, transport.sent)
+	preview, err := collector.Preview()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"reports": []}`, string(preview))
+}
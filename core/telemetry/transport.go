@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package telemetry
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/pkg/errors"
+)
+
+// NewHTTPTransport creates transport posting telemetry payload to the given URL.
+func NewHTTPTransport(httpClient *requests.HTTPClient, url string) Transport {
+	return &httpTransport{
+		httpClient: httpClient,
+		url:        url,
+	}
+}
+
+type httpTransport struct {
+	httpClient *requests.HTTPClient
+	url        string
+}
+
+func (t *httpTransport) Send(payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, t.url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	response, err := t.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(response.Body)
+		return errors.Errorf("unexpected response status: %v, body: %s", response.Status, body)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/miekg/dns"
+)
+
+// Blocklist is a set of blocked domains, which can be replaced while queries are served.
+type Blocklist struct {
+	mu      sync.RWMutex
+	domains map[string]struct{}
+}
+
+// NewBlocklist creates blocklist of given domains.
+func NewBlocklist(domains ...string) *Blocklist {
+	list := &Blocklist{}
+	list.Replace(domains)
+	return list
+}
+
+// Replace replaces all blocked domains.
+func (bl *Blocklist) Replace(domains []string) {
+	set := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		set[dns.Fqdn(strings.ToLower(domain))] = struct{}{}
+	}
+
+	bl.mu.Lock()
+	defer bl.mu.Unlock()
+	bl.domains = set
+}
+
+// Len returns count of blocked domains.
+func (bl *Blocklist) Len() int {
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+	return len(bl.domains)
+}
+
+// Blocks checks if given name or any of its parent domains is blocked.
+func (bl *Blocklist) Blocks(name string) bool {
+	name = dns.Fqdn(strings.ToLower(name))
+
+	bl.mu.RLock()
+	defer bl.mu.RUnlock()
+	for offset, end := 0, false; !end; offset, end = dns.NextLabel(name, offset) {
+		if _, ok := bl.domains[name[offset:]]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseBlocklist reads blocked domains given either in hosts file format or one per line, comments are skipped.
+func ParseBlocklist(r io.Reader) ([]string, error) {
+	var domains []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if net.ParseIP(fields[0]) != nil {
+			fields = fields[1:]
+		}
+		for _, domain := range fields {
+			if domain == "localhost" || net.ParseIP(domain) != nil {
+				continue
+			}
+			domains = append(domains, domain)
+		}
+	}
+	return domains, scanner.Err()
+}
+
+// BlockingHandler answers queries of blocked domains with NXDOMAIN and passes the rest to the next handler.
+type BlockingHandler struct {
+	next dns.Handler
+	list *Blocklist
+
+	queries uint64
+	blocked uint64
+}
+
+// BlockDomains wraps handler to block queries of domains in the given blocklist.
+func BlockDomains(next dns.Handler, list *Blocklist) *BlockingHandler {
+	return &BlockingHandler{
+		next: next,
+		list: list,
+	}
+}
+
+// ServeDNS serves DNS query.
+func (bh *BlockingHandler) ServeDNS(writer dns.ResponseWriter, req *dns.Msg) {
+	atomic.AddUint64(&bh.queries, 1)
+	for _, question := range req.Question {
+		if bh.list.Blocks(question.Name) {
+			atomic.AddUint64(&bh.blocked, 1)
+
+			resp := &dns.Msg{}
+			resp.SetRcode(req, dns.RcodeNameError)
+			writer.WriteMsg(resp)
+			return
+		}
+	}
+
+	bh.next.ServeDNS(writer, req)
+}
+
+// Counters returns how many queries were served and how many of them were blocked.
+func (bh *BlockingHandler) Counters() (queries, blocked uint64) {
+	return atomic.LoadUint64(&bh.queries), atomic.LoadUint64(&bh.blocked)
+}
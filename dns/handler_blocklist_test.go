@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/miekg/dns"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Blocklist_BlocksSubdomains(t *testing.T) {
+	list := NewBlocklist("ads.example.com", "Tracker.net")
+
+	assert.True(t, list.Blocks("ads.example.com."))
+	assert.True(t, list.Blocks("cdn.ads.example.com"))
+	assert.True(t, list.Blocks("tracker.NET."))
+	assert.False(t, list.Blocks("example.com."))
+	assert.False(t, list.Blocks("notads.example.com."))
+
+	list.Replace([]string{"example.com"})
+	assert.Equal(t, 1, list.Len())
+	assert.False(t, list.Blocks("tracker.net."))
+	assert.True(t, list.Blocks("notads.example.com."))
+}
+
+func Test_ParseBlocklist(t *testing.T) {
+	domains, err := ParseBlocklist(strings.NewReader(`# ad servers
+127.0.0.1 localhost
+0.0.0.0 ads.example.com tracker.example.com # inline comment
+
+tracker.net
+`))
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ads.example.com", "tracker.example.com", "tracker.net"}, domains)
+}
+
+func Test_BlockingHandler_ServeDNS(t *testing.T) {
+	answer := &dns.Msg{MsgHdr: dns.MsgHdr{Rcode: dns.RcodeSuccess}}
+	handler := BlockDomains(
+		dns.HandlerFunc(func(writer dns.ResponseWriter, req *dns.Msg) {
+			writer.WriteMsg(answer)
+		}),
+		NewBlocklist("ads.example.com"),
+	)
+
+	writer := &recordingWriter{}
+	handler.ServeDNS(writer, new(dns.Msg).SetQuestion("cdn.ads.example.com.", dns.TypeA))
+	assert.Equal(t, dns.RcodeNameError, writer.responseMsg.Rcode)
+
+	writer = &recordingWriter{}
+	handler.ServeDNS(writer, new(dns.Msg).SetQuestion("example.com.", dns.TypeA))
+	assert.Equal(t, answer, writer.responseMsg)
+
+	queries, blocked := handler.Counters()
+	assert.Equal(t, uint64(2), queries)
+	assert.Equal(t, uint64(1), blocked)
+}
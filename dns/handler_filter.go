@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"sort"
+
+	"github.com/miekg/dns"
+	"github.com/pkg/errors"
+)
+
+const (
+	// CategoryMalware blocks domains known to host malware and phishing.
+	CategoryMalware = "malware"
+	// CategoryAdult blocks adult content domains, malware domains are blocked along.
+	CategoryAdult = "adult"
+)
+
+var (
+	// securityResolvers block malware domains only.
+	securityResolvers = []string{"1.1.1.2", "1.0.0.2"}
+	// familyResolvers block both malware and adult content domains.
+	familyResolvers = []string{"1.1.1.3", "1.0.0.3"}
+)
+
+// Filter describes content filtering of DNS queries resolved on behalf of consumers.
+type Filter struct {
+	// Categories lists content categories blocked by the filter.
+	Categories []string
+	// Resolvers overrides the filtering resolvers chosen by the categories, each given as "<host>[:<port>]".
+	Resolvers []string
+}
+
+// Enabled tells if any content category is filtered.
+func (f Filter) Enabled() bool {
+	return len(f.Categories) > 0
+}
+
+// IsCategory tells if content of the given category can be filtered.
+func IsCategory(name string) bool {
+	return name == CategoryMalware || name == CategoryAdult
+}
+
+// CategoryNames returns sorted unique filtered categories, as advertised in service proposals.
+func (f Filter) CategoryNames() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, category := range f.Categories {
+		if seen[category] {
+			continue
+		}
+		seen[category] = true
+		names = append(names, category)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Resolve creates DNS handler of provided services, queries are filtered if filter is enabled.
+func Resolve(filter Filter) (dns.Handler, error) {
+	if filter.Enabled() {
+		return ResolveViaFilter(filter)
+	}
+	return ResolveViaSystem()
+}
+
+// ResolveViaFilter creates DNS handler proxying queries to filtering resolvers.
+func ResolveViaFilter(filter Filter) (dns.Handler, error) {
+	resolvers, err := filter.resolvers()
+	if err != nil {
+		return nil, err
+	}
+
+	return ResolveVia(resolvers...), nil
+}
+
+func (f Filter) resolvers() ([]string, error) {
+	if len(f.Resolvers) > 0 {
+		return f.Resolvers, nil
+	}
+
+	resolvers := securityResolvers
+	for _, category := range f.Categories {
+		switch category {
+		case CategoryMalware:
+		case CategoryAdult:
+			resolvers = familyResolvers
+		default:
+			return nil, errors.Errorf("unknown content filter category: %s", category)
+		}
+	}
+	return resolvers, nil
+}
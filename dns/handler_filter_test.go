@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package dns
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ResolveViaFilter(t *testing.T) {
+	tests := []struct {
+		name      string
+		filter    Filter
+		wantAddrs []string
+		wantErr   bool
+	}{
+		{
+			"should block malware via security resolvers",
+			Filter{Categories: []string{CategoryMalware}},
+			[]string{"1.1.1.2:53", "1.0.0.2:53"},
+			false,
+		},
+		{
+			"should block adult content via family resolvers",
+			Filter{Categories: []string{CategoryMalware, CategoryAdult}},
+			[]string{"1.1.1.3:53", "1.0.0.3:53"},
+			false,
+		},
+		{
+			"should prefer custom resolvers",
+			Filter{Categories: []string{CategoryAdult}, Resolvers: []string{"9.9.9.9", "149.112.112.112:5353"}},
+			[]string{"9.9.9.9:53", "149.112.112.112:5353"},
+			false,
+		},
+		{
+			"should reject unknown category",
+			Filter{Categories: []string{"gambling"}},
+			nil,
+			true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, err := ResolveViaFilter(tt.filter)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantAddrs, handler.(*proxyHandler).proxyAddrs)
+		})
+	}
+}
+
+func Test_Filter_CategoryNames(t *testing.T) {
+	filter := Filter{Categories: []string{CategoryMalware, CategoryAdult, CategoryMalware}}
+
+	assert.True(t, filter.Enabled())
+	assert.Equal(t, []string{CategoryAdult, CategoryMalware}, filter.CategoryNames())
+	assert.False(t, Filter{}.Enabled())
+}
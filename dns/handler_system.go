@@ -25,6 +25,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// defaultPort is a port of DNS servers given without one.
+const defaultPort = "53"
+
 // ResolveViaSystem creates proxying DNS handler.
 func ResolveViaSystem() (dns.Handler, error) {
 	handler := &proxyHandler{
@@ -41,6 +44,24 @@ func ResolveViaSystem() (dns.Handler, error) {
 	return handler, nil
 }
 
+// ResolveVia creates DNS handler proxying queries to given servers, each given as "<host>[:<port>]".
+func ResolveVia(servers ...string) dns.Handler {
+	handler := &proxyHandler{
+		client: &dns.Client{
+			DialTimeout:  dnsTimeout,
+			ReadTimeout:  dnsTimeout,
+			WriteTimeout: dnsTimeout,
+		},
+	}
+	for _, server := range servers {
+		if _, _, err := net.SplitHostPort(server); err != nil {
+			server = net.JoinHostPort(server, defaultPort)
+		}
+		handler.proxyAddrs = append(handler.proxyAddrs, server)
+	}
+	return handler
+}
+
 type proxyHandler struct {
 	proxyAddrs []string
 	client     *dns.Client
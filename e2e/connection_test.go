@@ -20,6 +20,7 @@ package e2e
 import (
 	"fmt"
 	"math"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -124,12 +125,13 @@ func TestConsumerConnectsToProvider(t *testing.T) {
 		fees, err := tequilapiProvider.GetTransactorFees()
 		assert.NoError(t, err)
 
-		accountantFee := math.Round(0.04 * float64(providerStatus.EarningsTotal))
+		earningsTotal := parseTokens(t, providerStatus.EarningsTotal.Amount)
+		accountantFee := math.Round(0.04 * float64(earningsTotal))
 		accountantFeeUint := uint64(math.Trunc(accountantFee))
-		expected := initialBalance + providerStatus.EarningsTotal - fees.Settlement - accountantFeeUint
+		expected := initialBalance + earningsTotal - fees.Settlement - accountantFeeUint
 
 		// To avoid running into rounding errors, assume a delta of 2 micromyst is OK
-		assert.InDelta(t, expected, providerStatus.Balance, 2)
+		assert.InDelta(t, expected, parseTokens(t, providerStatus.Balance.Amount), 2)
 	})
 
 	t.Run("Provider stops services", func(t *testing.T) {
@@ -177,7 +179,7 @@ func recheckBalancesWithAccountant(t *testing.T, consumerID string, consumerSpen
 		accountantCaller := pingpong.NewAccountantCaller(requests.NewHTTPClient("0.0.0.0", time.Second), "http://accountant:8889/api/v2")
 		accountantData, err := accountantCaller.GetConsumerData(consumerID)
 		assert.NoError(t, err)
-		promised := accountantData.LatestPromise.Amount
+		promised := accountantData.LatestPromise.Amount.Uint64()
 		lastAccountant = promised
 		return promised == consumerSpending
 	}, time.Second*10, time.Millisecond*300, fmt.Sprintf("Consumer reported spending %v  accountant says %v. Service type %v", consumerSpending, lastAccountant, serviceType))
@@ -321,12 +323,13 @@ func consumerConnectFlow(t *testing.T, tequilapi *tequilapi_client.Client, consu
 
 	consumerStatus, err := tequilapi.Identity(consumerID)
 	assert.NoError(t, err)
-	assert.True(t, consumerStatus.Balance > uint64(0), "consumer balance should not be empty")
-	assert.True(t, consumerStatus.Balance < uint64(690000000), "balance should decrease but is %sd", consumerStatus.Balance)
-	assert.Zero(t, consumerStatus.Earnings)
-	assert.Zero(t, consumerStatus.EarningsTotal)
+	balance := parseTokens(t, consumerStatus.Balance.Amount)
+	assert.True(t, balance > uint64(0), "consumer balance should not be empty")
+	assert.True(t, balance < uint64(690000000), "balance should decrease but is %d", balance)
+	assert.Zero(t, parseTokens(t, consumerStatus.Earnings.Amount))
+	assert.Zero(t, parseTokens(t, consumerStatus.EarningsTotal.Amount))
 
-	return uint64(690000000) - consumerStatus.Balance
+	return uint64(690000000) - balance
 }
 
 func consumerRejectWhitelistedFlow(t *testing.T, tequilapi *tequilapi_client.Client, consumerID, accountantID, serviceType string, proposal contract.ProposalDTO) {
@@ -353,11 +356,19 @@ func providerEarnedTokens(t *testing.T, tequilapi *tequilapi_client.Client, id s
 	// Before settlement
 	providerStatus, err := tequilapi.Identity(id)
 	assert.NoError(t, err)
-	assert.Equal(t, uint64(690000000), providerStatus.Balance)
-	assert.Equal(t, earningsExpected, providerStatus.Earnings, fmt.Sprintf("consumers reported spend %v, providers earnings %v", earningsExpected, providerStatus.Earnings))
-	assert.Equal(t, earningsExpected, providerStatus.EarningsTotal, fmt.Sprintf("consumers reported spend %v, providers earnings %v", earningsExpected, providerStatus.Earnings))
-	assert.True(t, providerStatus.Earnings > uint64(500), "earnings should be at least 500 but is %d", providerStatus.Earnings)
-	return providerStatus.Earnings
+	earnings := parseTokens(t, providerStatus.Earnings.Amount)
+	assert.Equal(t, uint64(690000000), parseTokens(t, providerStatus.Balance.Amount))
+	assert.Equal(t, earningsExpected, earnings, fmt.Sprintf("consumers reported spend %v, providers earnings %v", earningsExpected, earnings))
+	assert.Equal(t, earningsExpected, parseTokens(t, providerStatus.EarningsTotal.Amount), fmt.Sprintf("consumers reported spend %v, providers earnings %v", earningsExpected, earnings))
+	assert.True(t, earnings > uint64(500), "earnings should be at least 500 but is %d", earnings)
+	return earnings
+}
+
+// parseTokens parses amount of tokens returned by tequilapi, which fit into uint64 in the test network.
+func parseTokens(t *testing.T, amount string) uint64 {
+	tokens, err := strconv.ParseUint(amount, 10, 64)
+	assert.NoError(t, err)
+	return tokens
 }
 
 func sessionStatsReceived(tequilapi *tequilapi_client.Client, serviceType string) func() bool {
@@ -86,7 +86,7 @@ func TestMobileNodeConsumer(t *testing.T) {
 
 		balance, err := node.GetBalance(&mysterium.GetBalanceRequest{IdentityAddress: identity.IdentityAddress})
 		require.NoError(t, err)
-		require.Equal(t, int64(690000000), balance.Balance)
+		require.Equal(t, "690000000", balance.Balance)
 	})
 
 	t.Run("Test shutdown", func(t *testing.T) {
@@ -0,0 +1,155 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy defines what happens to an event published to a subscriber with a full buffer.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock blocks the publisher until the subscriber makes room for the event.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowDropOldest drops the oldest queued event to make room for the new one.
+	OverflowDropOldest
+	// OverflowCoalesce replaces the newest queued event with the new one,
+	// suitable for events carrying the latest state.
+	OverflowCoalesce
+)
+
+func (p OverflowPolicy) String() string {
+	switch p {
+	case OverflowBlock:
+		return "block"
+	case OverflowDropOldest:
+		return "drop-oldest"
+	case OverflowCoalesce:
+		return "coalesce"
+	default:
+		return "unknown"
+	}
+}
+
+// BufferOptions configures buffered delivery of events to a subscriber.
+type BufferOptions struct {
+	// Size is the number of events queued for the subscriber.
+	Size int
+	// Overflow defines what happens to events published while the buffer is full.
+	Overflow OverflowPolicy
+}
+
+func (o BufferOptions) validate() error {
+	if o.Size <= 0 {
+		return errors.New("buffer size must be positive")
+	}
+	if o.Overflow < OverflowBlock || o.Overflow > OverflowCoalesce {
+		return errors.New("unknown buffer overflow policy")
+	}
+	return nil
+}
+
+// queue holds events of a buffered subscriber until they are delivered.
+type queue struct {
+	// Counters are accessed atomically and kept first for 64-bit alignment on 32-bit platforms.
+	dropped   uint64
+	coalesced uint64
+
+	options BufferOptions
+	mu      sync.Mutex
+	cond    *sync.Cond
+	events  []interface{}
+	closed  bool
+}
+
+func newQueue(options BufferOptions) *queue {
+	q := &queue{
+		options: options,
+		events:  make([]interface{}, 0, options.Size),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push queues the event applying the overflow policy, it returns true if the buffer was full.
+func (q *queue) push(data interface{}) (overflow bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && len(q.events) >= q.options.Size {
+		overflow = true
+		switch q.options.Overflow {
+		case OverflowDropOldest:
+			q.events[0] = nil
+			q.events = q.events[1:]
+			atomic.AddUint64(&q.dropped, 1)
+		case OverflowCoalesce:
+			q.events[len(q.events)-1] = data
+			atomic.AddUint64(&q.coalesced, 1)
+			return overflow
+		default:
+			q.cond.Wait()
+		}
+	}
+	if q.closed {
+		atomic.AddUint64(&q.dropped, 1)
+		return overflow
+	}
+
+	q.events = append(q.events, data)
+	q.cond.Broadcast()
+	return overflow
+}
+
+// pop waits for the next event, it returns false once the queue is closed.
+func (q *queue) pop() (interface{}, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for !q.closed && len(q.events) == 0 {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return nil, false
+	}
+
+	data := q.events[0]
+	q.events[0] = nil
+	q.events = q.events[1:]
+	q.cond.Broadcast()
+	return data, true
+}
+
+func (q *queue) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	atomic.AddUint64(&q.dropped, uint64(len(q.events)))
+	q.events = nil
+	q.closed = true
+	q.cond.Broadcast()
+}
+
+func (q *queue) len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return len(q.events)
+}
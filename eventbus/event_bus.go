@@ -18,7 +18,12 @@
 package eventbus
 
 import (
-	asaskevichEventBus "github.com/asaskevich/EventBus"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -34,40 +39,181 @@ type Publisher interface {
 	Publish(topic string, data interface{})
 }
 
-// Subscriber subscribes to events
+// Subscriber subscribes to events. Topics are hierarchical, segments separated by dots,
+// so a subscriber can listen to a whole family of topics with a wildcard pattern:
+// "session.*" matches a single segment, "payments.>" matches one or more trailing segments.
+// Handlers accepting an Envelope receive event metadata together with the event.
+// Events of a type the handler does not accept are logged and dropped instead of panicking,
+// they are counted as Dropped in the delivery metrics.
 type Subscriber interface {
 	Subscribe(topic string, fn interface{}) error
 	SubscribeAsync(topic string, fn interface{}) error
+	SubscribeBuffered(topic string, fn interface{}, options BufferOptions) error
 	Unsubscribe(topic string, fn interface{}) error
 }
 
+// SubscriptionCounter reports how many subscribers listen to a topic,
+// so publishers can produce expensive events only when somebody consumes them.
+// Wildcard subscriptions are not counted, they are not interested in a topic in particular.
+type SubscriptionCounter interface {
+	Subscribers(topic string) int
+}
+
+// MetricsProvider reports event delivery metrics of every subscriber.
+type MetricsProvider interface {
+	Metrics() []DeliveryMetrics
+}
+
+// DeliveryMetrics describes events delivered to a single subscriber.
+type DeliveryMetrics struct {
+	Topic   string
+	Handler string
+	// Delivered is the number of events the handler was called with.
+	Delivered uint64
+	// Dropped is the number of events lost because of a full buffer or a handler of different type.
+	Dropped uint64
+	// Coalesced is the number of queued events replaced by newer ones.
+	Coalesced uint64
+	// Queued is the number of events waiting for delivery.
+	Queued int
+}
+
 type simplifiedEventBus struct {
-	bus asaskevichEventBus.Bus
+	sequence uint64
+
+	mu        sync.RWMutex
+	handlers  map[string][]*subscription
+	wildcards []*subscription
+}
+
+// Subscribe subscribes to the topic, handler is called synchronously by the publisher.
+func (simplifiedBus *simplifiedEventBus) Subscribe(topic string, fn interface{}) error {
+	return simplifiedBus.subscribe(topic, fn, deliverSync, nil)
+}
+
+// SubscribeAsync subscribes to the topic, handler is called in a separate goroutine for every event.
+func (simplifiedBus *simplifiedEventBus) SubscribeAsync(topic string, fn interface{}) error {
+	return simplifiedBus.subscribe(topic, fn, deliverAsync, nil)
+}
+
+// SubscribeBuffered subscribes to the topic, events are queued for the handler and delivered in order,
+// so a slow handler delays neither the publisher nor other subscribers.
+func (simplifiedBus *simplifiedEventBus) SubscribeBuffered(topic string, fn interface{}, options BufferOptions) error {
+	if err := options.validate(); err != nil {
+		return err
+	}
+	return simplifiedBus.subscribe(topic, fn, deliverBuffered, &options)
+}
+
+func (simplifiedBus *simplifiedEventBus) subscribe(topic string, fn interface{}, delivery delivery, options *BufferOptions) error {
+	pattern := isPattern(topic)
+	if pattern {
+		if err := validatePattern(topic); err != nil {
+			return err
+		}
+	}
+
+	sub, err := newSubscription(topic, fn, delivery)
+	if err != nil {
+		return err
+	}
+	if options != nil {
+		sub.queue = newQueue(*options)
+		go sub.deliverQueued()
+	}
+
+	simplifiedBus.mu.Lock()
+	defer simplifiedBus.mu.Unlock()
+
+	// Handlers are copied on write, so publishing does not hold the lock while calling them.
+	if pattern {
+		simplifiedBus.wildcards = appendSubscription(simplifiedBus.wildcards, sub)
+	} else {
+		simplifiedBus.handlers[topic] = appendSubscription(simplifiedBus.handlers[topic], sub)
+	}
+	return nil
+}
+
+func appendSubscription(handlers []*subscription, sub *subscription) []*subscription {
+	updated := make([]*subscription, len(handlers), len(handlers)+1)
+	copy(updated, handlers)
+	return append(updated, sub)
 }
 
-func (simplifiedBus simplifiedEventBus) Unsubscribe(topic string, fn interface{}) error {
-	return simplifiedBus.bus.Unsubscribe(topic, fn)
+func removeSubscription(handlers []*subscription, topic string, fn interface{}) ([]*subscription, *subscription) {
+	callback := reflect.ValueOf(fn)
+	for i, sub := range handlers {
+		if sub.topic != topic || sub.fn != callback {
+			continue
+		}
+		updated := make([]*subscription, 0, len(handlers)-1)
+		updated = append(updated, handlers[:i]...)
+		return append(updated, handlers[i+1:]...), sub
+	}
+	return handlers, nil
+}
+
+// Unsubscribe removes the first subscription of the topic or wildcard pattern with the given handler.
+func (simplifiedBus *simplifiedEventBus) Unsubscribe(topic string, fn interface{}) error {
+	simplifiedBus.mu.Lock()
+	defer simplifiedBus.mu.Unlock()
+
+	var removed *subscription
+	if isPattern(topic) {
+		simplifiedBus.wildcards, removed = removeSubscription(simplifiedBus.wildcards, topic, fn)
+		if removed == nil {
+			return fmt.Errorf("topic pattern %s doesn't exist", topic)
+		}
+	} else {
+		handlers := simplifiedBus.handlers[topic]
+		if len(handlers) == 0 {
+			return fmt.Errorf("topic %s doesn't exist", topic)
+		}
+		simplifiedBus.handlers[topic], removed = removeSubscription(handlers, topic, fn)
+	}
+
+	if removed != nil {
+		removed.close()
+	}
+	return nil
 }
 
-func (simplifiedBus simplifiedEventBus) Subscribe(topic string, fn interface{}) error {
-	return simplifiedBus.bus.Subscribe(topic, fn)
+// Subscribers returns the number of subscribers of the given topic.
+func (simplifiedBus *simplifiedEventBus) Subscribers(topic string) int {
+	simplifiedBus.mu.RLock()
+	defer simplifiedBus.mu.RUnlock()
+	return len(simplifiedBus.handlers[topic])
 }
 
-func (simplifiedBus simplifiedEventBus) SubscribeAsync(topic string, fn interface{}) error {
-	return simplifiedBus.bus.SubscribeAsync(topic, fn, false)
+// Metrics returns delivery metrics of all current subscribers.
+func (simplifiedBus *simplifiedEventBus) Metrics() []DeliveryMetrics {
+	simplifiedBus.mu.RLock()
+	defer simplifiedBus.mu.RUnlock()
+
+	var metrics []DeliveryMetrics
+	for _, handlers := range simplifiedBus.handlers {
+		for _, sub := range handlers {
+			metrics = append(metrics, sub.metrics())
+		}
+	}
+	for _, sub := range simplifiedBus.wildcards {
+		metrics = append(metrics, sub.metrics())
+	}
+	return metrics
 }
 
 var logLevelsByTopic = map[string]zerolog.Level{
-	"ProposalAdded":               zerolog.Disabled,
-	"ProposalUpdated":             zerolog.Disabled,
-	"ProposalRemoved":             zerolog.Disabled,
-	"proposalEvent":               zerolog.Disabled,
-	"Statistics":                  zerolog.Disabled,
-	"Throughput":                  zerolog.Disabled,
-	"State change":                zerolog.TraceLevel,
-	"Session data transferred":    zerolog.TraceLevel,
-	"Session change":              zerolog.TraceLevel,
-	"accountant_promise_received": zerolog.TraceLevel,
+	"proposal.added":                       zerolog.Disabled,
+	"proposal.updated":                     zerolog.Disabled,
+	"proposal.removed":                     zerolog.Disabled,
+	"proposal.announced":                   zerolog.Disabled,
+	"connection.statistics":                zerolog.Disabled,
+	"connection.statistics.live":           zerolog.Disabled,
+	"connection.throughput":                zerolog.Disabled,
+	"state.changed":                        zerolog.TraceLevel,
+	"session.data_transferred":             zerolog.TraceLevel,
+	"session.changed":                      zerolog.TraceLevel,
+	"payments.accountant_promise.received": zerolog.TraceLevel,
 }
 
 func levelFor(topic string) zerolog.Level {
@@ -77,13 +223,48 @@ func levelFor(topic string) zerolog.Level {
 	return zerolog.DebugLevel
 }
 
-func (simplifiedBus simplifiedEventBus) Publish(topic string, data interface{}) {
+func (simplifiedBus *simplifiedEventBus) Publish(topic string, data interface{}) {
 	log.WithLevel(levelFor(topic)).Msgf("Published topic=%q event=%+v", topic, data)
-	simplifiedBus.bus.Publish(topic, data)
+
+	sequence := atomic.AddUint64(&simplifiedBus.sequence, 1)
+
+	simplifiedBus.mu.RLock()
+	handlers := simplifiedBus.handlers[topic]
+	wildcards := simplifiedBus.wildcards
+	simplifiedBus.mu.RUnlock()
+
+	// Envelope is built only when some handler asks for it, as looking up the source is not free.
+	var envelope interface{}
+	deliver := func(sub *subscription) {
+		if !sub.envelope {
+			sub.deliver(data)
+			return
+		}
+		if envelope == nil {
+			envelope = Envelope{
+				Topic:     topic,
+				Sequence:  sequence,
+				Timestamp: time.Now(),
+				Source:    callerPackage(2),
+				Event:     data,
+			}
+		}
+		sub.deliver(envelope)
+	}
+
+	for _, sub := range handlers {
+		deliver(sub)
+	}
+	for _, sub := range wildcards {
+		if matchTopic(sub.topic, topic) {
+			deliver(sub)
+		}
+	}
 }
 
 // New returns implementation of EventBus
 func New() EventBus {
-	bus := asaskevichEventBus.New()
-	return simplifiedEventBus{bus: bus}
+	return &simplifiedEventBus{
+		handlers: make(map[string][]*subscription),
+	}
 }
@@ -19,6 +19,7 @@ package eventbus
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,3 +35,295 @@ func Test_simplifiedEventBus_Publish_InvokesSubscribers(t *testing.T) {
 
 	assert.Equal(t, "test data", received)
 }
+
+func Test_simplifiedEventBus_Subscribers_CountsSubscriptions(t *testing.T) {
+	eventBus := New().(SubscriptionCounter)
+	bus := eventBus.(EventBus)
+	first := func(data string) {}
+	second := func(data string) {}
+
+	assert.Equal(t, 0, eventBus.Subscribers("test topic"))
+
+	assert.NoError(t, bus.Subscribe("test topic", first))
+	assert.NoError(t, bus.SubscribeAsync("test topic", second))
+	assert.Equal(t, 2, eventBus.Subscribers("test topic"))
+	assert.Equal(t, 0, eventBus.Subscribers("other topic"))
+
+	assert.NoError(t, bus.Unsubscribe("test topic", first))
+	assert.Equal(t, 1, eventBus.Subscribers("test topic"))
+
+	assert.NoError(t, bus.Unsubscribe("test topic", first))
+	assert.Equal(t, 1, eventBus.Subscribers("test topic"))
+
+	assert.NoError(t, bus.Unsubscribe("test topic", second))
+	assert.Equal(t, 0, eventBus.Subscribers("test topic"))
+}
+
+func Test_simplifiedEventBus_Subscribe_RejectsInvalidHandlers(t *testing.T) {
+	eventBus := New()
+
+	assert.Error(t, eventBus.Subscribe("test topic", "not a function"))
+	assert.Error(t, eventBus.Subscribe("test topic", func(a, b string) {}))
+	assert.Error(t, eventBus.SubscribeBuffered("test topic", func(string) {}, BufferOptions{}))
+	assert.Equal(t, 0, eventBus.(SubscriptionCounter).Subscribers("test topic"))
+}
+
+func Test_simplifiedEventBus_Publish_NotifiesHandlersWithoutArguments(t *testing.T) {
+	eventBus := New()
+	notified := false
+	assert.NoError(t, eventBus.Subscribe("test topic", func() {
+		notified = true
+	}))
+
+	eventBus.Publish("test topic", "test data")
+
+	assert.True(t, notified)
+}
+
+func Test_simplifiedEventBus_Publish_DropsEventsOfDifferentType(t *testing.T) {
+	eventBus := New()
+	var received []string
+	assert.NoError(t, eventBus.Subscribe("test topic", func(data string) {
+		received = append(received, data)
+	}))
+
+	eventBus.Publish("test topic", 1)
+	eventBus.Publish("test topic", "test data")
+
+	assert.Equal(t, []string{"test data"}, received)
+	metrics := eventBus.(MetricsProvider).Metrics()
+	assert.Len(t, metrics, 1)
+	assert.Equal(t, uint64(1), metrics[0].Delivered)
+	assert.Equal(t, uint64(1), metrics[0].Dropped)
+}
+
+func Test_simplifiedEventBus_Publish_TypeMismatchDoesNotStopOtherSubscribers(t *testing.T) {
+	eventBus := New()
+	var received []int
+	assert.NoError(t, eventBus.Subscribe("test topic", func(data string) {}))
+	assert.NoError(t, eventBus.Subscribe("test topic", func(data int) {
+		received = append(received, data)
+	}))
+
+	assert.NotPanics(t, func() {
+		eventBus.Publish("test topic", 1)
+	})
+
+	assert.Equal(t, []int{1}, received)
+}
+
+func Test_simplifiedEventBus_Publish_AllowsHandlersToUnsubscribe(t *testing.T) {
+	eventBus := New()
+	calls := 0
+	var handler func(string)
+	handler = func(string) {
+		calls++
+		assert.NoError(t, eventBus.Unsubscribe("test topic", handler))
+	}
+	assert.NoError(t, eventBus.Subscribe("test topic", handler))
+
+	eventBus.Publish("test topic", "first")
+	eventBus.Publish("test topic", "second")
+
+	assert.Equal(t, 1, calls)
+}
+
+func Test_simplifiedEventBus_SubscribeBuffered_DeliversInOrderWithoutBlockingPublisher(t *testing.T) {
+	eventBus := New()
+	release := make(chan struct{})
+	received := make(chan int, 10)
+	assert.NoError(t, eventBus.SubscribeBuffered("test topic", func(data int) {
+		<-release
+		received <- data
+	}, BufferOptions{Size: 10, Overflow: OverflowBlock}))
+
+	for i := 0; i < 5; i++ {
+		eventBus.Publish("test topic", i)
+	}
+	close(release)
+
+	for i := 0; i < 5; i++ {
+		assert.Equal(t, i, <-received)
+	}
+}
+
+func Test_simplifiedEventBus_SubscribeBuffered_OverflowPolicies(t *testing.T) {
+	tests := []struct {
+		policy    OverflowPolicy
+		received  []int
+		dropped   uint64
+		coalesced uint64
+	}{
+		{policy: OverflowDropOldest, received: []int{0, 3, 4}, dropped: 2},
+		{policy: OverflowCoalesce, received: []int{0, 1, 4}, coalesced: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			eventBus := New()
+			started := make(chan struct{})
+			release := make(chan struct{})
+			received := make(chan int, 10)
+			assert.NoError(t, eventBus.SubscribeBuffered("test topic", func(data int) {
+				if data == 0 {
+					close(started)
+					<-release
+				}
+				received <- data
+			}, BufferOptions{Size: 2, Overflow: tt.policy}))
+
+			// The first event is being handled, so the following ones overflow the buffer of 2.
+			eventBus.Publish("test topic", 0)
+			<-started
+			for i := 1; i < 5; i++ {
+				eventBus.Publish("test topic", i)
+			}
+
+			metrics := eventBus.(MetricsProvider).Metrics()[0]
+			assert.Equal(t, tt.dropped, metrics.Dropped)
+			assert.Equal(t, tt.coalesced, metrics.Coalesced)
+			assert.Equal(t, 2, metrics.Queued)
+
+			close(release)
+			for _, want := range tt.received {
+				assert.Equal(t, want, <-received)
+			}
+		})
+	}
+}
+
+func Test_simplifiedEventBus_SubscribeBuffered_BlocksPublisherWhenFull(t *testing.T) {
+	eventBus := New()
+	started := make(chan struct{})
+	release := make(chan struct{})
+	assert.NoError(t, eventBus.SubscribeBuffered("test topic", func(data int) {
+		if data == 0 {
+			close(started)
+			<-release
+		}
+	}, BufferOptions{Size: 1, Overflow: OverflowBlock}))
+
+	eventBus.Publish("test topic", 0)
+	<-started
+	eventBus.Publish("test topic", 1)
+
+	published := make(chan struct{})
+	go func() {
+		eventBus.Publish("test topic", 2)
+		close(published)
+	}()
+
+	select {
+	case <-published:
+		t.Fatal("publisher was not blocked by the full buffer")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	close(release)
+	<-published
+}
+
+func Test_simplifiedEventBus_Unsubscribe_StopsBufferedDelivery(t *testing.T) {
+	eventBus := New()
+	received := make(chan string, 1)
+	handler := func(data string) {
+		received <- data
+	}
+	assert.NoError(t, eventBus.SubscribeBuffered("test topic", handler, BufferOptions{Size: 1, Overflow: OverflowBlock}))
+
+	eventBus.Publish("test topic", "first")
+	assert.Equal(t, "first", <-received)
+
+	assert.NoError(t, eventBus.Unsubscribe("test topic", handler))
+	eventBus.Publish("test topic", "second")
+	assert.Empty(t, eventBus.(MetricsProvider).Metrics())
+}
+
+func Test_simplifiedEventBus_Subscribe_WildcardPatterns(t *testing.T) {
+	eventBus := New()
+	var single, tail []string
+	assert.NoError(t, eventBus.Subscribe("session.*", func(data string) {
+		single = append(single, data)
+	}))
+	assert.NoError(t, eventBus.Subscribe("payments.>", func(data string) {
+		tail = append(tail, data)
+	}))
+
+	eventBus.Publish("session.changed", "session changed")
+	eventBus.Publish("session.key.rotated", "too deep for single segment")
+	eventBus.Publish("session", "too shallow")
+	eventBus.Publish("payments.balance.changed", "balance changed")
+	eventBus.Publish("payments.invoice", "invoice")
+	eventBus.Publish("payments", "too shallow for tail")
+
+	assert.Equal(t, []string{"session changed"}, single)
+	assert.Equal(t, []string{"balance changed", "invoice"}, tail)
+	assert.Equal(t, 0, eventBus.(SubscriptionCounter).Subscribers("session.changed"))
+}
+
+func Test_simplifiedEventBus_Subscribe_RejectsInvalidPatterns(t *testing.T) {
+	eventBus := New()
+
+	assert.Error(t, eventBus.Subscribe("payments.>.changed", func(string) {}))
+	assert.Error(t, eventBus.Subscribe("session..*", func(string) {}))
+}
+
+func Test_simplifiedEventBus_Unsubscribe_WildcardPattern(t *testing.T) {
+	eventBus := New()
+	received := 0
+	handler := func(string) { received++ }
+	assert.NoError(t, eventBus.Subscribe("session.*", handler))
+
+	eventBus.Publish("session.changed", "first")
+	assert.NoError(t, eventBus.Unsubscribe("session.*", handler))
+	eventBus.Publish("session.changed", "second")
+
+	assert.Equal(t, 1, received)
+	assert.Error(t, eventBus.Unsubscribe("session.*", handler))
+}
+
+func Test_simplifiedEventBus_Publish_DeliversEnvelopes(t *testing.T) {
+	eventBus := New()
+	var envelopes []Envelope
+	var events []string
+	assert.NoError(t, eventBus.Subscribe("session.>", func(envelope Envelope) {
+		envelopes = append(envelopes, envelope)
+	}))
+	assert.NoError(t, eventBus.Subscribe("session.changed", func(data string) {
+		events = append(events, data)
+	}))
+
+	before := time.Now()
+	eventBus.Publish("session.changed", "first")
+	eventBus.Publish("session.data_transferred", "second")
+
+	assert.Equal(t, []string{"first"}, events)
+	assert.Len(t, envelopes, 2)
+	assert.Equal(t, "session.changed", envelopes[0].Topic)
+	assert.Equal(t, "first", envelopes[0].Event)
+	assert.Equal(t, "eventbus", envelopes[0].Source)
+	assert.False(t, envelopes[0].Timestamp.Before(before))
+	assert.Equal(t, "session.data_transferred", envelopes[1].Topic)
+	assert.Equal(t, envelopes[0].Sequence+1, envelopes[1].Sequence)
+}
+
+func Test_matchTopic(t *testing.T) {
+	tests := []struct {
+		pattern, topic string
+		match          bool
+	}{
+		{"*", "trace", true},
+		{"*", "session.changed", false},
+		{">", "session.changed", true},
+		{"session.*", "session.changed", true},
+		{"session.*", "connection.state", false},
+		{"*.changed", "session.changed", true},
+		{"*.changed", "state.changed", true},
+		{"*.changed", "payments.balance.changed", false},
+		{"payments.*.changed", "payments.balance.changed", true},
+		{"payments.>", "payments", false},
+		{"connection.statistics", "connection.statistics.live", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.match, matchTopic(tt.pattern, tt.topic), "pattern %q topic %q", tt.pattern, tt.topic)
+	}
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// DefaultRecordLimit is the number of events kept in a recording file before it is rotated.
+	DefaultRecordLimit = 10000
+	// RecordBackupSuffix is appended to the rotated recording file name.
+	RecordBackupSuffix = ".1"
+
+	recorderBufferSize = 1000
+	redactedValue      = "[redacted]"
+)
+
+// DefaultRedactedFields lists event fields which are never persisted by the recorder.
+var DefaultRedactedFields = []string{"passphrase", "password", "secret", "privatekey", "private_key", "apikey", "api_key", "authorization"}
+
+// RecordedEvent is an event bus message persisted by the Recorder.
+type RecordedEvent struct {
+	Sequence  uint64          `json:"sequence"`
+	Timestamp time.Time       `json:"timestamp"`
+	Topic     string          `json:"topic"`
+	Source    string          `json:"source,omitempty"`
+	Type      string          `json:"type,omitempty"`
+	Event     json.RawMessage `json:"event"`
+}
+
+// RecorderOptions configures the event recorder.
+type RecorderOptions struct {
+	// Limit is the number of events written to the recording file before it is rotated to a backup,
+	// so at most twice the limit of events is kept on disk.
+	Limit int
+	// RedactFields lists case insensitive names of event fields whose values are not persisted.
+	RedactFields []string
+}
+
+// Recorder persists all event bus traffic to a file as JSON lines, so it can be replayed for debugging.
+type Recorder struct {
+	path    string
+	limit   int
+	redact  map[string]struct{}
+	handler func(Envelope)
+
+	mu      sync.Mutex
+	file    *os.File
+	written int
+	bus     Subscriber
+}
+
+// NewRecorder creates an event recorder writing to the given file.
+func NewRecorder(path string, options RecorderOptions) *Recorder {
+	limit := options.Limit
+	if limit <= 0 {
+		limit = DefaultRecordLimit
+	}
+	redact := make(map[string]struct{}, len(options.RedactFields))
+	for _, field := range options.RedactFields {
+		redact[strings.ToLower(field)] = struct{}{}
+	}
+
+	recorder := &Recorder{
+		path:   path,
+		limit:  limit,
+		redact: redact,
+	}
+	recorder.handler = recorder.record
+	return recorder
+}
+
+// Subscribe starts recording all events published to the bus.
+// Events are buffered, so the recorder never slows down publishers, oldest events are dropped instead.
+func (r *Recorder) Subscribe(bus Subscriber) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.open(); err != nil {
+		return err
+	}
+	if err := bus.SubscribeBuffered(WildcardTail, r.handler, BufferOptions{Size: recorderBufferSize, Overflow: OverflowDropOldest}); err != nil {
+		r.file.Close()
+		r.file = nil
+		return err
+	}
+	r.bus = bus
+	return nil
+}
+
+// Close stops recording and closes the recording file.
+func (r *Recorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.bus != nil {
+		if err := r.bus.Unsubscribe(WildcardTail, r.handler); err != nil {
+			log.Warn().Err(err).Msg("Failed to unsubscribe event recorder")
+		}
+		r.bus = nil
+	}
+	if r.file == nil {
+		return nil
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// open rotates the previous recording to a backup, so the recording of the last run survives a restart.
+func (r *Recorder) open() error {
+	if _, err := os.Stat(r.path); err == nil {
+		if err := os.Rename(r.path, r.path+RecordBackupSuffix); err != nil {
+			return errors.Wrap(err, "could not rotate event recording")
+		}
+	}
+
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600)
+	if err != nil {
+		return errors.Wrap(err, "could not open event recording")
+	}
+	r.file = file
+	r.written = 0
+	return nil
+}
+
+func (r *Recorder) record(envelope Envelope) {
+	line, err := r.encode(envelope)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Failed to record event of topic %q", envelope.Topic)
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.file == nil {
+		return
+	}
+	if r.written >= r.limit {
+		if err := r.file.Close(); err != nil {
+			log.Warn().Err(err).Msg("Failed to close event recording")
+		}
+		r.file = nil
+		if err := r.open(); err != nil {
+			log.Error().Err(err).Msg("Event recording stopped")
+			return
+		}
+	}
+	if _, err := r.file.Write(line); err != nil {
+		log.Warn().Err(err).Msg("Failed to write event recording")
+		return
+	}
+	r.written++
+}
+
+func (r *Recorder) encode(envelope Envelope) ([]byte, error) {
+	event, err := json.Marshal(envelope.Event)
+	if err != nil {
+		return nil, err
+	}
+	if event, err = r.redactFields(event); err != nil {
+		return nil, err
+	}
+
+	line, err := json.Marshal(RecordedEvent{
+		Sequence:  envelope.Sequence,
+		Timestamp: envelope.Timestamp,
+		Topic:     envelope.Topic,
+		Source:    envelope.Source,
+		Type:      fmt.Sprintf("%T", envelope.Event),
+		Event:     event,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append(line, '\n'), nil
+}
+
+func (r *Recorder) redactFields(event []byte) ([]byte, error) {
+	if len(r.redact) == 0 || (!bytes.HasPrefix(event, []byte("{")) && !bytes.HasPrefix(event, []byte("["))) {
+		return event, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(event, &value); err != nil {
+		return nil, err
+	}
+	return json.Marshal(r.redactValue(value))
+}
+
+func (r *Recorder) redactValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if _, ok := r.redact[strings.ToLower(key)]; ok {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = r.redactValue(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = r.redactValue(v[i])
+		}
+	}
+	return value
+}
@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordedTestEvent struct {
+	ID         string
+	Passphrase string
+	Amount     int
+}
+
+func newTestRecording(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "eventbus-recorder")
+	assert.NoError(t, err)
+	return filepath.Join(dir, "events.log"), func() { os.RemoveAll(dir) }
+}
+
+func readTestRecording(t *testing.T, path string) []RecordedEvent {
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+
+	events, err := ReadRecording(file)
+	assert.NoError(t, err)
+	return events
+}
+
+func Test_Recorder_RecordsAndRedactsEvents(t *testing.T) {
+	path, cleanup := newTestRecording(t)
+	defer cleanup()
+
+	bus := New()
+	recorder := NewRecorder(path, RecorderOptions{RedactFields: DefaultRedactedFields})
+	assert.NoError(t, recorder.Subscribe(bus))
+
+	bus.Publish("test.first", recordedTestEvent{ID: "1", Passphrase: "secret", Amount: 10})
+	bus.Publish("test.second", "second")
+
+	assert.Eventually(t, func() bool {
+		return bus.(MetricsProvider).Metrics()[0].Delivered == 2
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.NoError(t, recorder.Close())
+
+	events := readTestRecording(t, path)
+	assert.Len(t, events, 2)
+	assert.Equal(t, "test.first", events[0].Topic)
+	assert.Equal(t, "eventbus.recordedTestEvent", events[0].Type)
+	assert.JSONEq(t, `{"ID":"1","Passphrase":"[redacted]","Amount":10}`, string(events[0].Event))
+	assert.Equal(t, "test.second", events[1].Topic)
+	assert.JSONEq(t, `"second"`, string(events[1].Event))
+	assert.Equal(t, events[0].Sequence+1, events[1].Sequence)
+}
+
+func Test_Recorder_RotatesRecordingWhenLimitIsReached(t *testing.T) {
+	path, cleanup := newTestRecording(t)
+	defer cleanup()
+
+	bus := New()
+	recorder := NewRecorder(path, RecorderOptions{Limit: 2})
+	assert.NoError(t, recorder.Subscribe(bus))
+
+	for i := 0; i < 3; i++ {
+		bus.Publish("test.topic", i)
+	}
+	assert.Eventually(t, func() bool {
+		return bus.(MetricsProvider).Metrics()[0].Delivered == 3
+	}, 2*time.Second, 10*time.Millisecond)
+	assert.NoError(t, recorder.Close())
+
+	assert.Len(t, readTestRecording(t, path+RecordBackupSuffix), 2)
+	assert.Len(t, readTestRecording(t, path), 1)
+}
+
+func Test_Replayer_PublishesRegisteredEventsInOrder(t *testing.T) {
+	recording := bytes.NewBufferString(`{"sequence":2,"topic":"test.event","event":{"ID":"2","Amount":20}}
+{"sequence":1,"topic":"test.event","event":{"ID":"1","Amount":10}}
+{"sequence":3,"topic":"test.pointer","event":{"ID":"3"}}
+{"sequence":4,"topic":"test.unknown","event":"skipped"}
+`)
+	events, err := ReadRecording(recording)
+	assert.NoError(t, err)
+
+	bus := New()
+	var received []recordedTestEvent
+	assert.NoError(t, bus.Subscribe("test.event", func(event recordedTestEvent) {
+		received = append(received, event)
+	}))
+	var pointer *recordedTestEvent
+	assert.NoError(t, bus.Subscribe("test.pointer", func(event *recordedTestEvent) {
+		pointer = event
+	}))
+
+	replayer := NewReplayer()
+	replayer.Register("test.event", recordedTestEvent{})
+	replayer.Register("test.pointer", &recordedTestEvent{})
+	published, err := replayer.Replay(events, bus)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, published)
+	assert.Equal(t, []recordedTestEvent{{ID: "1", Amount: 10}, {ID: "2", Amount: 20}}, received)
+	assert.Equal(t, &recordedTestEvent{ID: "3"}, pointer)
+}
+
+func Test_Replayer_FailsOnEventsOfDifferentType(t *testing.T) {
+	events := []RecordedEvent{{Sequence: 1, Topic: "test.event", Event: []byte(`"not a struct"`)}}
+
+	replayer := NewReplayer()
+	replayer.Register("test.event", recordedTestEvent{})
+	published, err := replayer.Replay(events, New())
+
+	assert.Error(t, err)
+	assert.Equal(t, 0, published)
+}
@@ -0,0 +1,112 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// ReadRecording reads events persisted by the Recorder.
+func ReadRecording(reader io.Reader) ([]RecordedEvent, error) {
+	var events []RecordedEvent
+	decoder := json.NewDecoder(reader)
+	for {
+		var event RecordedEvent
+		err := decoder.Decode(&event)
+		if err == io.EOF {
+			return events, nil
+		}
+		if err != nil {
+			return events, errors.Wrap(err, "could not read event recording")
+		}
+		events = append(events, event)
+	}
+}
+
+// Replayer publishes recorded events back to an event bus, so a fresh set of subscribers
+// can be brought to the state of the recorded node in tests.
+type Replayer struct {
+	types map[string]reflect.Type
+}
+
+// NewReplayer creates a new event replayer.
+func NewReplayer() *Replayer {
+	return &Replayer{
+		types: make(map[string]reflect.Type),
+	}
+}
+
+// Register sets the type recorded events of the topic are decoded to, given as a sample value.
+func (r *Replayer) Register(topic string, prototype interface{}) {
+	r.types[topic] = reflect.TypeOf(prototype)
+}
+
+// Replay publishes events of registered topics in the recorded order and returns the number of published events.
+// Events of other topics are skipped.
+func (r *Replayer) Replay(events []RecordedEvent, publisher Publisher) (int, error) {
+	ordered := make([]RecordedEvent, len(events))
+	copy(ordered, events)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return ordered[i].Sequence < ordered[j].Sequence
+	})
+
+	published := 0
+	for _, recorded := range ordered {
+		eventType, ok := r.types[recorded.Topic]
+		if !ok {
+			continue
+		}
+		event, err := decodeEvent(recorded.Event, eventType)
+		if err != nil {
+			return published, errors.Wrapf(err, "could not decode event %d of topic %q", recorded.Sequence, recorded.Topic)
+		}
+		publisher.Publish(recorded.Topic, event)
+		published++
+	}
+	return published, nil
+}
+
+func decodeEvent(data json.RawMessage, eventType reflect.Type) (interface{}, error) {
+	if eventType == nil {
+		return nil, nil
+	}
+
+	isPtr := eventType.Kind() == reflect.Ptr
+	valueType := eventType
+	if isPtr {
+		valueType = eventType.Elem()
+	}
+	if isPtr && (len(data) == 0 || bytes.Equal(data, []byte("null"))) {
+		return reflect.Zero(eventType).Interface(), nil
+	}
+
+	value := reflect.New(valueType)
+	if err := json.Unmarshal(data, value.Interface()); err != nil {
+		return nil, err
+	}
+	if isPtr {
+		return value.Interface(), nil
+	}
+	return value.Elem().Interface(), nil
+}
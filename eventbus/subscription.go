@@ -0,0 +1,160 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/rs/zerolog/log"
+)
+
+type delivery int
+
+const (
+	deliverSync delivery = iota
+	deliverAsync
+	deliverBuffered
+)
+
+// subscription is a handler of a single topic. Handler type is checked on subscribe
+// and events of a different type are dropped instead of panicking the publisher.
+type subscription struct {
+	// Counters are accessed atomically and kept first for 64-bit alignment on 32-bit platforms.
+	delivered uint64
+	dropped   uint64
+	warned    uint32
+
+	topic     string
+	fn        reflect.Value
+	eventType reflect.Type
+	delivery  delivery
+	queue     *queue
+	// envelope is set for handlers accepting the Envelope instead of the bare event.
+	envelope bool
+}
+
+func newSubscription(topic string, fn interface{}, delivery delivery) (*subscription, error) {
+	fnType := reflect.TypeOf(fn)
+	if fnType == nil || fnType.Kind() != reflect.Func {
+		return nil, fmt.Errorf("%v is not of type reflect.Func", fnType)
+	}
+	if fnType.NumIn() > 1 || fnType.IsVariadic() {
+		return nil, fmt.Errorf("handler of topic %q must accept a single event argument or none", topic)
+	}
+
+	sub := &subscription{
+		topic:    topic,
+		fn:       reflect.ValueOf(fn),
+		delivery: delivery,
+	}
+	// Handlers without arguments are only notified about the event.
+	if fnType.NumIn() == 1 {
+		sub.eventType = fnType.In(0)
+		sub.envelope = sub.eventType == envelopeType
+	}
+	return sub, nil
+}
+
+func (s *subscription) deliver(data interface{}) {
+	switch s.delivery {
+	case deliverAsync:
+		go s.call(data)
+	case deliverBuffered:
+		if s.queue.push(data) && atomic.CompareAndSwapUint32(&s.warned, 0, 1) {
+			log.Warn().Msgf("Subscriber %s of topic %q is too slow, buffer overflow policy %q applied", s.name(), s.topic, s.queue.options.Overflow)
+		}
+	default:
+		s.call(data)
+	}
+}
+
+func (s *subscription) deliverQueued() {
+	for {
+		data, ok := s.queue.pop()
+		if !ok {
+			return
+		}
+		s.call(data)
+	}
+}
+
+func (s *subscription) call(data interface{}) {
+	if s.eventType == nil {
+		s.fn.Call(nil)
+		atomic.AddUint64(&s.delivered, 1)
+		return
+	}
+
+	arg, ok := s.argument(data)
+	if !ok {
+		atomic.AddUint64(&s.dropped, 1)
+		log.Error().Msgf("Event of type %T can not be delivered to subscriber %s of topic %q expecting %s", data, s.name(), s.topic, s.eventType)
+		return
+	}
+
+	s.fn.Call([]reflect.Value{arg})
+	atomic.AddUint64(&s.delivered, 1)
+}
+
+func (s *subscription) argument(data interface{}) (reflect.Value, bool) {
+	if data == nil {
+		switch s.eventType.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			return reflect.Zero(s.eventType), true
+		default:
+			return reflect.Value{}, false
+		}
+	}
+
+	value := reflect.ValueOf(data)
+	if !value.Type().AssignableTo(s.eventType) {
+		return reflect.Value{}, false
+	}
+	return value, true
+}
+
+func (s *subscription) close() {
+	if s.queue != nil {
+		s.queue.close()
+	}
+}
+
+func (s *subscription) name() string {
+	if fn := runtime.FuncForPC(s.fn.Pointer()); fn != nil {
+		return fn.Name()
+	}
+	return s.fn.Type().String()
+}
+
+func (s *subscription) metrics() DeliveryMetrics {
+	metrics := DeliveryMetrics{
+		Topic:     s.topic,
+		Handler:   s.name(),
+		Delivered: atomic.LoadUint64(&s.delivered),
+		Dropped:   atomic.LoadUint64(&s.dropped),
+	}
+	if s.queue != nil {
+		metrics.Dropped += atomic.LoadUint64(&s.queue.dropped)
+		metrics.Coalesced = atomic.LoadUint64(&s.queue.coalesced)
+		metrics.Queued = s.queue.len()
+	}
+	return metrics
+}
@@ -0,0 +1,118 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package eventbus
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const (
+	// TopicSeparator separates hierarchical topic segments, e.g. "session.changed".
+	TopicSeparator = "."
+	// WildcardSegment matches exactly one topic segment, e.g. "session.*".
+	WildcardSegment = "*"
+	// WildcardTail matches one or more trailing topic segments, e.g. "payments.>".
+	WildcardTail = ">"
+)
+
+// Envelope wraps a published event with its delivery metadata.
+// Handlers accepting an Envelope receive it instead of the bare event.
+type Envelope struct {
+	Topic string
+	// Sequence is increasing for every event published on the bus.
+	Sequence  uint64
+	Timestamp time.Time
+	// Source is the package which published the event.
+	Source string
+	Event  interface{}
+}
+
+var envelopeType = reflect.TypeOf(Envelope{})
+
+// isPattern reports whether the topic contains wildcard segments.
+func isPattern(topic string) bool {
+	for _, segment := range strings.Split(topic, TopicSeparator) {
+		if segment == WildcardSegment || segment == WildcardTail {
+			return true
+		}
+	}
+	return false
+}
+
+func validatePattern(pattern string) error {
+	segments := strings.Split(pattern, TopicSeparator)
+	for i, segment := range segments {
+		if segment == "" {
+			return fmt.Errorf("topic pattern %q has an empty segment", pattern)
+		}
+		if segment == WildcardTail && i != len(segments)-1 {
+			return fmt.Errorf("topic pattern %q can only end with %q", pattern, WildcardTail)
+		}
+	}
+	return nil
+}
+
+// matchTopic reports whether the topic matches the wildcard pattern.
+func matchTopic(pattern, topic string) bool {
+	for {
+		patternSegment, patternRest := nextSegment(pattern)
+		topicSegment, topicRest := nextSegment(topic)
+
+		if patternSegment == WildcardTail {
+			return topicSegment != ""
+		}
+		if topicSegment == "" || (patternSegment != WildcardSegment && patternSegment != topicSegment) {
+			return false
+		}
+		if patternRest == "" || topicRest == "" {
+			return patternRest == "" && topicRest == ""
+		}
+		pattern, topic = patternRest, topicRest
+	}
+}
+
+func nextSegment(topic string) (segment, rest string) {
+	if i := strings.Index(topic, TopicSeparator); i >= 0 {
+		return topic[:i], topic[i+1:]
+	}
+	return topic, ""
+}
+
+const modulePrefix = "github.com/mysteriumnetwork/node/"
+
+// callerPackage returns the package of the function skip frames above the caller.
+func callerPackage(skip int) string {
+	pc, _, _, ok := runtime.Caller(skip + 1)
+	if !ok {
+		return ""
+	}
+	fn := runtime.FuncForPC(pc)
+	if fn == nil {
+		return ""
+	}
+	name := fn.Name()
+	pkgStart := strings.LastIndex(name, "/") + 1
+	if i := strings.Index(name[pkgStart:], "."); i >= 0 {
+		name = name[:pkgStart+i]
+	}
+	return strings.TrimPrefix(name, modulePrefix)
+}
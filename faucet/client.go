@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package faucet
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/requests"
+)
+
+// GrantResponse is the faucet representation of a token grant.
+type GrantResponse struct {
+	ID     string   `json:"id"`
+	Status Status   `json:"status"`
+	TxHash string   `json:"tx_hash"`
+	MYST   *big.Int `json:"myst"`
+	ETH    *big.Int `json:"eth"`
+	Error  string   `json:"error"`
+}
+
+type grantRequest struct {
+	Identity string `json:"identity"`
+	Channel  string `json:"channel"`
+}
+
+// Client requests test tokens from the faucet API.
+type Client struct {
+	http    *requests.HTTPClient
+	address string
+}
+
+// NewClient returns a new faucet API client.
+func NewClient(http *requests.HTTPClient, address string) *Client {
+	return &Client{
+		http:    http,
+		address: address,
+	}
+}
+
+// RequestGrant requests test ETH for the identity and test MYST for its channel.
+func (c *Client) RequestGrant(identity, channel common.Address) (GrantResponse, error) {
+	req, err := requests.NewPostRequest(c.address, "grants", grantRequest{
+		Identity: identity.Hex(),
+		Channel:  channel.Hex(),
+	})
+	if err != nil {
+		return GrantResponse{}, fmt.Errorf("could not form grant request: %w", err)
+	}
+
+	var resp GrantResponse
+	if err := c.http.DoRequestAndParseResponse(req, &resp); err != nil {
+		return GrantResponse{}, fmt.Errorf("could not request grant from faucet: %w", err)
+	}
+	return resp, nil
+}
+
+// GrantStatus returns the current status of the grant.
+func (c *Client) GrantStatus(grantID string) (GrantResponse, error) {
+	req, err := requests.NewGetRequest(c.address, "grants/"+grantID, nil)
+	if err != nil {
+		return GrantResponse{}, fmt.Errorf("could not form grant status request: %w", err)
+	}
+
+	var resp GrantResponse
+	if err := c.http.DoRequestAndParseResponse(req, &resp); err != nil {
+		return GrantResponse{}, fmt.Errorf("could not get grant status from faucet: %w", err)
+	}
+	return resp, nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package faucet
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_RequestGrant(t *testing.T) {
+	var received grantRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/grants":
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+			fmt.Fprint(w, `{"id": "grant-1", "status": "pending"}`)
+		case r.Method == http.MethodGet && r.URL.Path == "/grants/grant-1":
+			fmt.Fprint(w, `{"id": "grant-1", "status": "granted", "tx_hash": "0x1", "myst": 10, "eth": 1}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(requests.NewHTTPClient("0.0.0.0", time.Second), server.URL)
+
+	resp, err := client.RequestGrant(consumerID.ToCommonAddress(), channel)
+	assert.NoError(t, err)
+	assert.Equal(t, GrantResponse{ID: "grant-1", Status: StatusPending}, resp)
+	assert.Equal(t, grantRequest{Identity: consumerID.ToCommonAddress().Hex(), Channel: channel.Hex()}, received)
+
+	resp, err = client.GrantStatus("grant-1")
+	assert.NoError(t, err)
+	assert.Equal(t, StatusGranted, resp.Status)
+	assert.Equal(t, "0x1", resp.TxHash)
+	assert.Equal(t, big.NewInt(10), resp.MYST)
+	assert.Equal(t, big.NewInt(1), resp.ETH)
+
+	_, err = client.GrantStatus("unknown")
+	assert.Error(t, err)
+}
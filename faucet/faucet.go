@@ -0,0 +1,265 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package faucet
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Status represents faucet grant progress.
+type Status string
+
+const (
+	// StatusPending means that the faucet accepted the request and is sending tokens.
+	StatusPending Status = "pending"
+	// StatusGranted means that tokens were sent to the identity.
+	StatusGranted Status = "granted"
+	// StatusFailed means that the faucet could not grant tokens.
+	StatusFailed Status = "failed"
+)
+
+var (
+	// ErrGrantNotFound indicates that identity has not requested any tokens.
+	ErrGrantNotFound = errors.New("faucet grant not found")
+	// ErrGrantInProgress indicates that identity already has a pending grant.
+	ErrGrantInProgress = errors.New("faucet grant already in progress")
+	// ErrAlreadyGranted indicates that identity already received tokens from the faucet.
+	ErrAlreadyGranted = errors.New("tokens already granted")
+)
+
+// Grant describes test tokens requested from the faucet for an identity.
+type Grant struct {
+	Identity    identity.Identity `storm:"id"`
+	Channel     common.Address
+	GrantID     string
+	Status      Status
+	TxHash      string
+	MYST        *big.Int
+	ETH         *big.Int
+	Error       string
+	RequestedAt time.Time
+	UpdatedAt   time.Time
+}
+
+type faucetClient interface {
+	RequestGrant(identity, channel common.Address) (GrantResponse, error)
+	GrantStatus(grantID string) (GrantResponse, error)
+}
+
+type grantStorage interface {
+	Store(grant Grant) error
+	Get(id identity.Identity) (Grant, error)
+	GetAll() ([]Grant, error)
+}
+
+type channelAddressCalculator interface {
+	GetChannelAddress(id identity.Identity) (common.Address, error)
+}
+
+type publisher interface {
+	Publish(topic string, data interface{})
+}
+
+// Options describes how faucet grants are tracked.
+type Options struct {
+	// PollInterval is how often grant status is checked.
+	PollInterval time.Duration
+	// Timeout limits waiting for tokens to be granted.
+	Timeout time.Duration
+}
+
+// DefaultOptions returns default faucet grant tracking options.
+func DefaultOptions() Options {
+	return Options{
+		PollInterval: 10 * time.Second,
+		Timeout:      15 * time.Minute,
+	}
+}
+
+// Manager requests test tokens from the faucet for new identities and tracks grants until tokens arrive.
+type Manager struct {
+	client    faucetClient
+	storage   grantStorage
+	channels  channelAddressCalculator
+	publisher publisher
+	options   Options
+
+	lock sync.Mutex
+	stop chan struct{}
+	once sync.Once
+}
+
+// NewManager creates faucet grant manager.
+func NewManager(client faucetClient, storage grantStorage, channels channelAddressCalculator, publisher publisher, options Options) *Manager {
+	return &Manager{
+		client:    client,
+		storage:   storage,
+		channels:  channels,
+		publisher: publisher,
+		options:   options,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Subscribe subscribes the manager to node events, pending grants are resumed once node is started.
+func (m *Manager) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(event.AppTopicNode, m.handleNodeEvent)
+}
+
+// Status returns the last grant of identity.
+func (m *Manager) Status(id identity.Identity) (Grant, error) {
+	return m.storage.Get(id)
+}
+
+// Request requests test tokens for the identity, failed grants can be requested again.
+func (m *Manager) Request(id identity.Identity) (Grant, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	previous, err := m.storage.Get(id)
+	if err != nil && err != ErrGrantNotFound {
+		return Grant{}, err
+	}
+	switch {
+	case err == ErrGrantNotFound:
+	case previous.Status == StatusPending:
+		return Grant{}, ErrGrantInProgress
+	case previous.Status == StatusGranted:
+		return Grant{}, ErrAlreadyGranted
+	}
+
+	channel, err := m.channels.GetChannelAddress(id)
+	if err != nil {
+		return Grant{}, errors.Wrap(err, "could not calculate channel address")
+	}
+
+	resp, err := m.client.RequestGrant(id.ToCommonAddress(), channel)
+	if err != nil {
+		return Grant{}, err
+	}
+
+	log.Info().Msgf("Requested test tokens for %s from faucet, grant %s", id.Address, resp.ID)
+	now := time.Now().UTC()
+	grant := Grant{
+		Identity:    id,
+		Channel:     channel,
+		GrantID:     resp.ID,
+		RequestedAt: now,
+	}
+	grant = m.apply(grant, resp)
+	if err := m.update(grant); err != nil {
+		return Grant{}, err
+	}
+
+	if grant.Status == StatusPending {
+		go m.track(grant)
+	}
+	return grant, nil
+}
+
+func (m *Manager) handleNodeEvent(ev event.Payload) {
+	switch ev.Status {
+	case event.StatusStarted:
+		m.resume()
+	case event.StatusStopped:
+		m.once.Do(func() {
+			close(m.stop)
+		})
+	}
+}
+
+func (m *Manager) resume() {
+	grants, err := m.storage.GetAll()
+	if err != nil {
+		log.Error().Err(err).Msg("Could not resume faucet grants")
+		return
+	}
+	for _, grant := range grants {
+		if grant.Status == StatusPending {
+			go m.track(grant)
+		}
+	}
+}
+
+func (m *Manager) track(grant Grant) {
+	deadline := grant.RequestedAt.Add(m.options.Timeout)
+	ticker := time.NewTicker(m.options.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.stop:
+			return
+		case <-ticker.C:
+		}
+
+		resp, err := m.client.GrantStatus(grant.GrantID)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not check faucet grant %s", grant.GrantID)
+		} else if grant = m.apply(grant, resp); grant.Status != StatusPending {
+			log.Info().Msgf("Faucet grant %s of %s is %s", grant.GrantID, grant.Identity.Address, grant.Status)
+			m.save(grant)
+			return
+		}
+
+		if time.Now().UTC().After(deadline) {
+			grant.Status = StatusFailed
+			grant.Error = "tokens were not granted in " + m.options.Timeout.String()
+			m.save(grant)
+			return
+		}
+	}
+}
+
+func (m *Manager) apply(grant Grant, resp GrantResponse) Grant {
+	grant.Status = resp.Status
+	grant.TxHash = resp.TxHash
+	grant.MYST = resp.MYST
+	grant.ETH = resp.ETH
+	grant.Error = resp.Error
+	if grant.Status == "" {
+		grant.Status = StatusPending
+	}
+	return grant
+}
+
+func (m *Manager) save(grant Grant) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	if err := m.update(grant); err != nil {
+		log.Error().Err(err).Msgf("Could not store faucet grant of %s", grant.Identity.Address)
+	}
+}
+
+func (m *Manager) update(grant Grant) error {
+	grant.UpdatedAt = time.Now().UTC()
+	if err := m.storage.Store(grant); err != nil {
+		return err
+	}
+	m.publisher.Publish(AppTopicFaucetGrant, AppEventFaucetGrant{Grant: grant})
+	return nil
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package faucet
+
+import (
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	consumerID  = identity.FromAddress("0x0000000000000000000000000000000000000001")
+	channel     = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	testOptions = Options{PollInterval: time.Millisecond, Timeout: time.Second}
+)
+
+type mockClient struct {
+	lock      sync.Mutex
+	requested []common.Address
+	status    GrantResponse
+	err       error
+}
+
+func (mc *mockClient) RequestGrant(identity, channel common.Address) (GrantResponse, error) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	mc.requested = append(mc.requested, identity, channel)
+	if mc.err != nil {
+		return GrantResponse{}, mc.err
+	}
+	return GrantResponse{ID: "grant-1", Status: StatusPending}, nil
+}
+
+func (mc *mockClient) GrantStatus(_ string) (GrantResponse, error) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	return mc.status, nil
+}
+
+func (mc *mockClient) setStatus(status GrantResponse) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	mc.status = status
+}
+
+type mockChannels struct{}
+
+func (mockChannels) GetChannelAddress(_ identity.Identity) (common.Address, error) {
+	return channel, nil
+}
+
+func newTestStorage(t *testing.T) (*Storage, func()) {
+	dir, err := ioutil.TempDir("", "faucetTest")
+	assert.NoError(t, err)
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	return NewStorage(bolt), func() {
+		bolt.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+func waitForStatus(t *testing.T, m *Manager, status Status) Grant {
+	var grant Grant
+	assert.Eventually(t, func() bool {
+		var err error
+		grant, err = m.Status(consumerID)
+		return err == nil && grant.Status == status
+	}, time.Second, time.Millisecond)
+	return grant
+}
+
+func TestManager_RequestGrant(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+	client := &mockClient{status: GrantResponse{ID: "grant-1", Status: StatusPending}}
+	publisher := mocks.NewEventBus()
+	m := NewManager(client, storage, mockChannels{}, publisher, testOptions)
+	defer m.handleNodeEvent(event.Payload{Status: event.StatusStopped})
+
+	_, err := m.Status(consumerID)
+	assert.Equal(t, ErrGrantNotFound, err)
+
+	grant, err := m.Request(consumerID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, grant.Status)
+	assert.Equal(t, "grant-1", grant.GrantID)
+	assert.Equal(t, []common.Address{consumerID.ToCommonAddress(), channel}, client.requested)
+
+	_, err = m.Request(consumerID)
+	assert.Equal(t, ErrGrantInProgress, err)
+
+	client.setStatus(GrantResponse{ID: "grant-1", Status: StatusGranted, TxHash: "0x1", MYST: big.NewInt(10), ETH: big.NewInt(1)})
+	grant = waitForStatus(t, m, StatusGranted)
+	assert.Equal(t, "0x1", grant.TxHash)
+	assert.Equal(t, big.NewInt(10), grant.MYST)
+	assert.Equal(t, AppEventFaucetGrant{Grant: grant}, publisher.Pop())
+
+	_, err = m.Request(consumerID)
+	assert.Equal(t, ErrAlreadyGranted, err)
+}
+
+func TestManager_FailedGrantCanBeRequestedAgain(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+	client := &mockClient{status: GrantResponse{ID: "grant-1", Status: StatusFailed, Error: "faucet is empty"}}
+	m := NewManager(client, storage, mockChannels{}, mocks.NewEventBus(), testOptions)
+	defer m.handleNodeEvent(event.Payload{Status: event.StatusStopped})
+
+	_, err := m.Request(consumerID)
+	assert.NoError(t, err)
+	grant := waitForStatus(t, m, StatusFailed)
+	assert.Equal(t, "faucet is empty", grant.Error)
+
+	client.setStatus(GrantResponse{ID: "grant-1", Status: StatusPending})
+	grant, err = m.Request(consumerID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusPending, grant.Status)
+	assert.Empty(t, grant.Error)
+}
+
+func TestManager_RequestGrantFails(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+	client := &mockClient{err: errors.New("rate limited")}
+	m := NewManager(client, storage, mockChannels{}, mocks.NewEventBus(), testOptions)
+
+	_, err := m.Request(consumerID)
+	assert.EqualError(t, err, "rate limited")
+
+	_, err = m.Status(consumerID)
+	assert.Equal(t, ErrGrantNotFound, err)
+}
+
+func TestManager_ResumesPendingGrants(t *testing.T) {
+	storage, cleanup := newTestStorage(t)
+	defer cleanup()
+	assert.NoError(t, storage.Store(Grant{Identity: consumerID, GrantID: "grant-1", Status: StatusPending, RequestedAt: time.Now().UTC()}))
+
+	client := &mockClient{status: GrantResponse{ID: "grant-1", Status: StatusGranted}}
+	m := NewManager(client, storage, mockChannels{}, mocks.NewEventBus(), testOptions)
+	defer m.handleNodeEvent(event.Payload{Status: event.StatusStopped})
+
+	m.handleNodeEvent(event.Payload{Status: event.StatusStarted})
+	waitForStatus(t, m, StatusGranted)
+}
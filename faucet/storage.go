@@ -0,0 +1,84 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package faucet
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/pkg/errors"
+)
+
+const grantBucket = "faucet_grants"
+
+type persistentStorage interface {
+	Store(bucket string, data interface{}) error
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+}
+
+var errBoltNotFound = "not found"
+
+// Storage persists faucet grants of identities.
+type Storage struct {
+	lock sync.Mutex
+	bolt persistentStorage
+}
+
+// NewStorage returns a new faucet grant storage.
+func NewStorage(bolt persistentStorage) *Storage {
+	return &Storage{
+		bolt: bolt,
+	}
+}
+
+// Store stores the given grant, replacing the previous grant of the identity.
+func (s *Storage) Store(grant Grant) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return errors.Wrap(s.bolt.Store(grantBucket, &grant), "could not store faucet grant")
+}
+
+// Get returns the last grant of the identity.
+func (s *Storage) Get(id identity.Identity) (Grant, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var grant Grant
+	err := s.bolt.GetOneByField(grantBucket, "Identity", id, &grant)
+	if err != nil {
+		if err.Error() == errBoltNotFound {
+			return Grant{}, ErrGrantNotFound
+		}
+		return Grant{}, errors.Wrap(err, "could not get faucet grant")
+	}
+	return grant, nil
+}
+
+// GetAll returns grants of all identities.
+func (s *Storage) GetAll() ([]Grant, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	var grants []Grant
+	err := s.bolt.GetAllFrom(grantBucket, &grants)
+	if err != nil && err.Error() != errBoltNotFound {
+		return nil, errors.Wrap(err, "could not get faucet grants")
+	}
+	return grants, nil
+}
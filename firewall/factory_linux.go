@@ -19,8 +19,8 @@
 
 package firewall
 
-// NewOutgoingTrafficFirewall creates firewall instance for outgoing traffic.
-func NewOutgoingTrafficFirewall(enabled bool) OutgoingTrafficFirewall {
+// NewOutgoingTrafficFirewall creates firewall instance for outgoing traffic, dataDir keeps state which has to survive a crash.
+func NewOutgoingTrafficFirewall(enabled bool, dataDir string) OutgoingTrafficFirewall {
 	if enabled {
 		return &outgoingFirewallIptables{
 			referenceTracker: make(map[string]refCount),
@@ -0,0 +1,32 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+// NewOutgoingTrafficFirewall creates firewall instance for outgoing traffic, dataDir keeps state which has to survive a crash.
+func NewOutgoingTrafficFirewall(enabled bool, dataDir string) OutgoingTrafficFirewall {
+	if enabled {
+		return newOutgoingFirewallNetsh(dataDir)
+	}
+
+	return &outgoingFirewallNoop{}
+}
+
+// NewIncomingTrafficFirewall creates firewall instance for incoming traffic.
+func NewIncomingTrafficFirewall(enabled bool) IncomingTrafficFirewall {
+	return &incomingFirewallNoop{}
+}
@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netsh
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/utils/cmdutil"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Exec executes given args in the advfirewall context of netsh.
+var Exec = defaultExec
+
+func defaultExec(args ...string) ([]string, error) {
+	args = append([]string{"netsh", "advfirewall"}, args...)
+	output, err := cmdutil.ExecOutput(args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "netsh cmd error")
+	}
+
+	outputScanner := bufio.NewScanner(bytes.NewBufferString(output))
+	var lines []string
+	for outputScanner.Scan() {
+		lines = append(lines, outputScanner.Text())
+	}
+	return lines, outputScanner.Err()
+}
+
+// AddRuleWithRemoval activates given rule
+func AddRuleWithRemoval(rule Rule) (func(), error) {
+	if _, err := Exec(rule.ApplyArgs()...); err != nil {
+		return nil, err
+	}
+	return func() {
+		_, err := Exec(rule.RemoveArgs()...)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Error executing rule: %v you might wanna do it yourself", rule.RemoveArgs())
+		}
+	}, nil
+}
+
+// Policies returns default firewall policy of every profile, e.g. "BlockInbound,AllowOutbound" for "domainprofile".
+func Policies() (map[string]string, error) {
+	lines, err := Exec("show", "allprofiles", "firewallpolicy")
+	if err != nil {
+		return nil, err
+	}
+
+	policies := make(map[string]string)
+	var profile string
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		switch {
+		case strings.HasSuffix(line, "Profile Settings:"):
+			profile = strings.ToLower(strings.Fields(line)[0]) + "profile"
+		case strings.HasPrefix(line, "Firewall Policy") && profile != "":
+			fields := strings.Fields(line)
+			policies[profile] = fields[len(fields)-1]
+		}
+	}
+	if len(policies) == 0 {
+		return nil, errors.Errorf("no firewall policies found in: %v", lines)
+	}
+	return policies, nil
+}
+
+// SetPolicy sets default firewall policy of the given profile.
+func SetPolicy(profile, policy string) error {
+	_, err := Exec("set", profile, "firewallpolicy", policy)
+	return err
+}
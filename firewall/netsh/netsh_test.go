@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netsh
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRule_Args(t *testing.T) {
+	rule := NewRule("myst-rule").RuleSpec("dir=out", "action=allow", "remoteip=1.2.3.4")
+
+	assert.Equal(t, []string{"firewall", "add", "rule", "name=myst-rule", "dir=out", "action=allow", "remoteip=1.2.3.4"}, rule.ApplyArgs())
+	assert.Equal(t, []string{"firewall", "delete", "rule", "name=myst-rule"}, rule.RemoveArgs())
+}
+
+func TestPolicies(t *testing.T) {
+	Exec = func(args ...string) ([]string, error) {
+		assert.Equal(t, []string{"show", "allprofiles", "firewallpolicy"}, args)
+		return []string{
+			"",
+			"Domain Profile Settings:",
+			"----------------------------------------------------------------------",
+			"Firewall Policy                       BlockInbound,AllowOutbound",
+			"",
+			"Private Profile Settings:",
+			"----------------------------------------------------------------------",
+			"Firewall Policy                       BlockInbound,BlockOutbound",
+			"Ok.",
+		}, nil
+	}
+	defer func() { Exec = defaultExec }()
+
+	policies, err := Policies()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"domainprofile":  "BlockInbound,AllowOutbound",
+		"privateprofile": "BlockInbound,BlockOutbound",
+	}, policies)
+}
+
+func TestPolicies_FailsWithoutProfiles(t *testing.T) {
+	Exec = func(args ...string) ([]string, error) {
+		return []string{"Ok."}, nil
+	}
+	defer func() { Exec = defaultExec }()
+
+	_, err := Policies()
+	assert.Error(t, err)
+}
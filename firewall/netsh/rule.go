@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netsh
+
+// Rule is a Windows Filtering Platform rule managed by netsh.
+type Rule struct {
+	name     string
+	ruleSpec []string
+}
+
+// NewRule creates a new rule with the given name, removing the rule removes every rule with the same name.
+func NewRule(name string) Rule {
+	return Rule{name: name}
+}
+
+// RuleSpec sets the rule specification (see `netsh advfirewall firewall add rule`).
+func (r Rule) RuleSpec(spec ...string) Rule {
+	r.ruleSpec = spec
+	return r
+}
+
+// Name returns name of the rule.
+func (r Rule) Name() string {
+	return r.name
+}
+
+// ApplyArgs returns an argument list to be passed to netsh to APPLY the rule.
+func (r Rule) ApplyArgs() []string {
+	return append([]string{"firewall", "add", "rule", "name=" + r.name}, r.ruleSpec...)
+}
+
+// RemoveArgs returns an argument list to be passed to netsh to REMOVE the rule.
+func (r Rule) RemoveArgs() []string {
+	return []string{"firewall", "delete", "rule", "name=" + r.name}
+}
@@ -0,0 +1,326 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/mysteriumnetwork/node/firewall/netsh"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	killswitchRulePrefix = "myst-kill-switch"
+	blockTrafficRef      = "block-traffic"
+	policiesBackupFile   = "firewall-policies.json"
+)
+
+// outgoingFirewallNetsh is a kill switch built on persistent rules and policies of netsh advfirewall.
+// Block rules of Windows firewall take precedence over allow rules, so instead of blocking traffic from outbound IP
+// outgoing traffic is blocked by default policy and allow rules are added for the tunnel and the exceptions.
+// Nothing is removed when the process dies, so original policies are saved to disk and restored on the next Setup.
+type outgoingFirewallNetsh struct {
+	lock             sync.Mutex
+	trafficLockScope Scope
+	referenceTracker map[string]refCount
+	lookupIP         func(host string) ([]net.IP, error)
+	policiesFile     string
+}
+
+func newOutgoingFirewallNetsh(dataDir string) *outgoingFirewallNetsh {
+	return &outgoingFirewallNetsh{
+		referenceTracker: make(map[string]refCount),
+		trafficLockScope: none,
+		lookupIP:         net.LookupIP,
+		policiesFile:     filepath.Join(dataDir, policiesBackupFile),
+	}
+}
+
+// Setup tries to cleanup rules left by previous run and allows outgoing DNS traffic.
+func (obn *outgoingFirewallNetsh) Setup() error {
+	if err := obn.cleanupStaleRules(); err != nil {
+		return err
+	}
+
+	// TODO for now always allow outgoing DNS traffic, BUT it should be exposed as separate firewall call
+	for _, protocol := range []string{"udp", "tcp"} {
+		rule := netsh.NewRule(killswitchRulePrefix+"-dns-"+protocol).
+			RuleSpec("dir=out", "action=allow", "protocol="+protocol, "remoteport=53")
+		if _, err := netsh.Exec(rule.ApplyArgs()...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Teardown tries to cleanup all changes made by setup and leave system in the state before setup.
+func (obn *outgoingFirewallNetsh) Teardown() {
+	obn.lock.Lock()
+	if ref := obn.referenceTracker[blockTrafficRef]; ref.count > 0 {
+		ref.f()
+		delete(obn.referenceTracker, blockTrafficRef)
+	}
+	obn.lock.Unlock()
+
+	if err := obn.cleanupStaleRules(); err != nil {
+		log.Warn().Err(err).Msg("Error cleaning up firewall rules, you might want to do it yourself")
+	}
+}
+
+// BlockOutgoingTraffic effectively disallows any outgoing traffic from consumer node with specified scope.
+func (obn *outgoingFirewallNetsh) BlockOutgoingTraffic(scope Scope, outboundIP string) (OutgoingRuleRemove, error) {
+	if obn.trafficLockScope == Global {
+		// nothing can override global lock
+		return func() {}, nil
+	}
+	obn.trafficLockScope = scope
+	return obn.trackingReferenceCall(blockTrafficRef, func() (OutgoingRuleRemove, error) {
+		tunnelIPs, err := excludeIP(outboundIP)
+		if err != nil {
+			return nil, err
+		}
+		removeTunnelRule, err := netsh.AddRuleWithRemoval(
+			netsh.NewRule(killswitchRulePrefix+"-tunnel").RuleSpec("dir=out", "action=allow", "localip="+tunnelIPs),
+		)
+		if err != nil {
+			return nil, err
+		}
+		restorePolicies, err := obn.blockOutboundPolicies()
+		if err != nil {
+			removeTunnelRule()
+			return nil, err
+		}
+		return func() {
+			restorePolicies()
+			removeTunnelRule()
+		}, nil
+	})
+}
+
+// AllowIPAccess adds IP based exception, host names are resolved since Windows firewall accepts addresses only.
+func (obn *outgoingFirewallNetsh) AllowIPAccess(ip string) (OutgoingRuleRemove, error) {
+	return obn.trackingReferenceCall("allow:"+ip, func() (OutgoingRuleRemove, error) {
+		remoteIPs, err := obn.resolve(ip)
+		if err != nil {
+			return nil, err
+		}
+		return netsh.AddRuleWithRemoval(
+			netsh.NewRule(killswitchRulePrefix+"-allow-"+ip).RuleSpec("dir=out", "action=allow", "remoteip="+strings.Join(remoteIPs, ",")),
+		)
+	})
+}
+
+// AllowURLAccess adds exception to blocked traffic for specified URL (host part is usually taken).
+func (obn *outgoingFirewallNetsh) AllowURLAccess(rawURLs ...string) (OutgoingRuleRemove, error) {
+	var ruleRemovers []func()
+	removeAll := func() {
+		for _, ruleRemover := range ruleRemovers {
+			ruleRemover()
+		}
+	}
+	for _, rawURL := range rawURLs {
+		parsed, err := url.Parse(rawURL)
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+
+		remover, err := obn.AllowIPAccess(parsed.Hostname())
+		if err != nil {
+			removeAll()
+			return nil, err
+		}
+		ruleRemovers = append(ruleRemovers, remover)
+	}
+	return removeAll, nil
+}
+
+func (obn *outgoingFirewallNetsh) resolve(host string) ([]string, error) {
+	if net.ParseIP(host) != nil {
+		return []string{host}, nil
+	}
+
+	ips, err := obn.lookupIP(host)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not resolve %q", host)
+	}
+	result := make([]string, len(ips))
+	for i, ip := range ips {
+		result[i] = ip.String()
+	}
+	return result, nil
+}
+
+// cleanupStaleRules removes kill switch rules and restores policies saved by the previous run if it left traffic blocked.
+func (obn *outgoingFirewallNetsh) cleanupStaleRules() error {
+	lines, err := netsh.Exec("firewall", "show", "rule", "name=all", "dir=out")
+	if err != nil {
+		return err
+	}
+
+	stale := make(map[string]bool)
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "Rule Name:") {
+			continue
+		}
+		name := strings.TrimSpace(strings.TrimPrefix(line, "Rule Name:"))
+		if strings.HasPrefix(name, killswitchRulePrefix) {
+			stale[name] = true
+		}
+	}
+	for name := range stale {
+		if _, err := netsh.Exec(netsh.NewRule(name).RemoveArgs()...); err != nil {
+			return err
+		}
+	}
+
+	return obn.restoreSavedPolicies()
+}
+
+// restoreSavedPolicies sets policies saved before blocking the traffic and forgets them.
+func (obn *outgoingFirewallNetsh) restoreSavedPolicies() error {
+	data, err := ioutil.ReadFile(obn.policiesFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "could not read saved firewall policies")
+	}
+
+	var policies map[string]string
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return errors.Wrap(err, "could not parse saved firewall policies")
+	}
+	for profile, policy := range policies {
+		if err := netsh.SetPolicy(profile, policy); err != nil {
+			return err
+		}
+	}
+	return os.Remove(obn.policiesFile)
+}
+
+func (obn *outgoingFirewallNetsh) trackingReferenceCall(ref string, actualCall func() (OutgoingRuleRemove, error)) (OutgoingRuleRemove, error) {
+	obn.lock.Lock()
+	defer obn.lock.Unlock()
+
+	refCount := obn.referenceTracker[ref]
+	if refCount.count == 0 {
+		removeRule, err := actualCall()
+		if err != nil {
+			return nil, err
+		}
+		refCount.f = removeRule
+	}
+	refCount.count++
+	obn.referenceTracker[ref] = refCount
+
+	return obn.decreaseRefCall(ref), nil
+}
+
+func (obn *outgoingFirewallNetsh) decreaseRefCall(ref string) OutgoingRuleRemove {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			obn.lock.Lock()
+			defer obn.lock.Unlock()
+
+			refCount := obn.referenceTracker[ref]
+			if refCount.count == 0 {
+				return
+			}
+			refCount.count--
+			if refCount.count == 0 {
+				refCount.f()
+			}
+			obn.referenceTracker[ref] = refCount
+		})
+	}
+}
+
+// blockOutboundPolicies blocks outgoing traffic by default in every firewall profile.
+// Original policies are saved to disk first, so that they survive a crash of the node.
+func (obn *outgoingFirewallNetsh) blockOutboundPolicies() (func(), error) {
+	policies, err := netsh.Policies()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(policies)
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(obn.policiesFile, data, 0600); err != nil {
+		return nil, errors.Wrap(err, "could not save firewall policies")
+	}
+
+	restore := func() {
+		for profile, policy := range policies {
+			if err := netsh.SetPolicy(profile, policy); err != nil {
+				log.Warn().Err(err).Msgf("Error restoring firewall policy %s of %s, you might wanna do it yourself", policy, profile)
+				return
+			}
+		}
+		if err := os.Remove(obn.policiesFile); err != nil {
+			log.Warn().Err(err).Msg("Error removing saved firewall policies")
+		}
+	}
+	for profile, policy := range policies {
+		if err := netsh.SetPolicy(profile, inboundPolicy(policy)+",BlockOutbound"); err != nil {
+			restore()
+			return nil, err
+		}
+	}
+	return restore, nil
+}
+
+func inboundPolicy(policy string) string {
+	return strings.Split(policy, ",")[0]
+}
+
+// excludeIP returns IPv4 ranges covering every address except the given one.
+func excludeIP(ip string) (string, error) {
+	parsed := net.ParseIP(ip).To4()
+	if parsed == nil {
+		return "", errors.Errorf("invalid IPv4 address %q", ip)
+	}
+
+	var ranges []string
+	if !parsed.Equal(net.IPv4zero) {
+		ranges = append(ranges, "0.0.0.0-"+offsetIP(parsed, -1).String())
+	}
+	if !parsed.Equal(net.IPv4bcast) {
+		ranges = append(ranges, offsetIP(parsed, 1).String()+"-255.255.255.255")
+	}
+	return strings.Join(ranges, ","), nil
+}
+
+func offsetIP(ip net.IP, offset int) net.IP {
+	value := uint32(ip[0])<<24 | uint32(ip[1])<<16 | uint32(ip[2])<<8 | uint32(ip[3])
+	value = uint32(int64(value) + int64(offset))
+	return net.IPv4(byte(value>>24), byte(value>>16), byte(value>>8), byte(value))
+}
+
+var _ OutgoingTrafficFirewall = &outgoingFirewallNetsh{}
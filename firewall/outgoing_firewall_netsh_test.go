@@ -0,0 +1,211 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package firewall
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"os"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/firewall/netsh"
+	"github.com/stretchr/testify/assert"
+)
+
+var netshPolicies = []string{
+	"",
+	"Domain Profile Settings:",
+	"----------------------------------------------------------------------",
+	"Firewall Policy                       BlockInbound,AllowOutbound",
+	"",
+	"Public Profile Settings:",
+	"----------------------------------------------------------------------",
+	"Firewall Policy                       AllowInbound,AllowOutbound",
+	"Ok.",
+}
+
+func newNetshExecMock() *netshExecMock {
+	mockedExec := &netshExecMock{
+		mocks: map[string]netshExecResult{
+			"show allprofiles firewallpolicy": {output: netshPolicies},
+		},
+	}
+	netsh.Exec = mockedExec.Exec
+	return mockedExec
+}
+
+func newTestOutgoingFirewallNetsh(t *testing.T) *outgoingFirewallNetsh {
+	dir, err := ioutil.TempDir("", "netshFirewallTest")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return newOutgoingFirewallNetsh(dir)
+}
+
+func Test_outgoingFirewallNetsh_SetupCleansStaleRules(t *testing.T) {
+	mockedExec := newNetshExecMock()
+	mockedExec.mocks["firewall show rule name=all dir=out"] = netshExecResult{
+		output: []string{
+			"Rule Name:                            myst-kill-switch-tunnel",
+			"Rule Name:                            myst-kill-switch-allow-1.1.1.1",
+			"Rule Name:                            myst-kill-switch-allow-1.1.1.1",
+			"Rule Name:                            other-app",
+		},
+	}
+	fw := newTestOutgoingFirewallNetsh(t)
+
+	err := fw.Setup()
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "delete", "rule", "name=myst-kill-switch-tunnel"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "delete", "rule", "name=myst-kill-switch-allow-1.1.1.1"))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("firewall", "delete", "rule", "name=other-app"))
+	assert.False(t, mockedExec.VerifyCalledWithArgs("set", "domainprofile", "firewallpolicy", "BlockInbound,AllowOutbound"), "policies are restored only from the saved ones")
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "add", "rule", "name=myst-kill-switch-dns-udp", "dir=out", "action=allow", "protocol=udp", "remoteport=53"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "add", "rule", "name=myst-kill-switch-dns-tcp", "dir=out", "action=allow", "protocol=tcp", "remoteport=53"))
+}
+
+func Test_outgoingFirewallNetsh_SetupRestoresSavedPolicies(t *testing.T) {
+	mockedExec := newNetshExecMock()
+	fw := newTestOutgoingFirewallNetsh(t)
+	err := ioutil.WriteFile(fw.policiesFile, []byte(`{"domainprofile":"BlockInbound,BlockOutbound","publicprofile":"AllowInbound,AllowOutbound"}`), 0600)
+	assert.NoError(t, err)
+
+	err = fw.Setup()
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("set", "domainprofile", "firewallpolicy", "BlockInbound,BlockOutbound"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("set", "publicprofile", "firewallpolicy", "AllowInbound,AllowOutbound"))
+	assert.NoFileExists(t, fw.policiesFile)
+}
+
+func Test_outgoingFirewallNetsh_SetupFailsOnNetshError(t *testing.T) {
+	mockedExec := newNetshExecMock()
+	mockedExec.mocks["firewall show rule name=all dir=out"] = netshExecResult{err: errors.New("access denied")}
+	fw := newTestOutgoingFirewallNetsh(t)
+
+	err := fw.Setup()
+	assert.EqualError(t, err, "access denied")
+}
+
+func Test_outgoingFirewallNetsh_BlocksAllOutgoingTraffic(t *testing.T) {
+	mockedExec := newNetshExecMock()
+	fw := newTestOutgoingFirewallNetsh(t)
+
+	removeRuleFunc, err := fw.BlockOutgoingTraffic("test-scope", "1.1.1.1")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "add", "rule", "name=myst-kill-switch-tunnel", "dir=out", "action=allow", "localip=0.0.0.0-1.1.1.0,1.1.1.2-255.255.255.255"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("set", "domainprofile", "firewallpolicy", "BlockInbound,BlockOutbound"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("set", "publicprofile", "firewallpolicy", "AllowInbound,BlockOutbound"))
+	assert.FileExists(t, fw.policiesFile)
+
+	removeRuleFunc()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("set", "domainprofile", "firewallpolicy", "BlockInbound,AllowOutbound"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("set", "publicprofile", "firewallpolicy", "AllowInbound,AllowOutbound"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "delete", "rule", "name=myst-kill-switch-tunnel"))
+	assert.NoFileExists(t, fw.policiesFile)
+}
+
+func Test_outgoingFirewallNetsh_BlockRemovesTunnelRuleOnPolicyError(t *testing.T) {
+	mockedExec := newNetshExecMock()
+	mockedExec.mocks["show allprofiles firewallpolicy"] = netshExecResult{err: errors.New("access denied")}
+	fw := newTestOutgoingFirewallNetsh(t)
+
+	_, err := fw.BlockOutgoingTraffic(Session, "1.1.1.1")
+	assert.EqualError(t, err, "access denied")
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "delete", "rule", "name=myst-kill-switch-tunnel"))
+	assert.Equal(t, 0, fw.referenceTracker[blockTrafficRef].count)
+}
+
+func Test_outgoingFirewallNetsh_SessionTrafficBlockIsNoopWhenGlobalBlockWasCalled(t *testing.T) {
+	newNetshExecMock()
+	fw := newTestOutgoingFirewallNetsh(t)
+
+	removeGlobalBlock, err := fw.BlockOutgoingTraffic(Global, "1.1.1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, fw.referenceTracker[blockTrafficRef].count)
+
+	removeSessionRule, _ := fw.BlockOutgoingTraffic(Session, "1.1.1.1")
+	assert.Equal(t, 1, fw.referenceTracker[blockTrafficRef].count)
+
+	removeSessionRule()
+	assert.Equal(t, 1, fw.referenceTracker[blockTrafficRef].count)
+
+	removeGlobalBlock()
+	assert.Equal(t, 0, fw.referenceTracker[blockTrafficRef].count)
+}
+
+func Test_outgoingFirewallNetsh_TeardownRestoresPolicies(t *testing.T) {
+	mockedExec := newNetshExecMock()
+	fw := newTestOutgoingFirewallNetsh(t)
+
+	_, err := fw.BlockOutgoingTraffic(Global, "1.1.1.1")
+	assert.NoError(t, err)
+
+	fw.Teardown()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("set", "domainprofile", "firewallpolicy", "BlockInbound,AllowOutbound"))
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "delete", "rule", "name=myst-kill-switch-tunnel"))
+	assert.Equal(t, 0, fw.referenceTracker[blockTrafficRef].count)
+}
+
+func Test_outgoingFirewallNetsh_AllowIPAccessIsAddedAndRemovedByLastReference(t *testing.T) {
+	mockedExec := newNetshExecMock()
+	fw := newTestOutgoingFirewallNetsh(t)
+
+	removeFirst, err := fw.AllowIPAccess("1.2.3.4")
+	assert.NoError(t, err)
+	removeSecond, err := fw.AllowIPAccess("1.2.3.4")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "add", "rule", "name=myst-kill-switch-allow-1.2.3.4", "dir=out", "action=allow", "remoteip=1.2.3.4"))
+	assert.Equal(t, 2, fw.referenceTracker["allow:1.2.3.4"].count)
+
+	removeFirst()
+	removeFirst()
+	assert.False(t, mockedExec.VerifyCalledWithArgs("firewall", "delete", "rule", "name=myst-kill-switch-allow-1.2.3.4"))
+
+	removeSecond()
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "delete", "rule", "name=myst-kill-switch-allow-1.2.3.4"))
+	assert.Equal(t, 0, fw.referenceTracker["allow:1.2.3.4"].count)
+}
+
+func Test_outgoingFirewallNetsh_AllowURLAccessResolvesHost(t *testing.T) {
+	mockedExec := newNetshExecMock()
+	fw := newTestOutgoingFirewallNetsh(t)
+	fw.lookupIP = func(host string) ([]net.IP, error) {
+		assert.Equal(t, "example.com", host)
+		return []net.IP{net.ParseIP("1.2.3.4"), net.ParseIP("5.6.7.8")}, nil
+	}
+
+	_, err := fw.AllowURLAccess("https://example.com:8080/path")
+	assert.NoError(t, err)
+	assert.True(t, mockedExec.VerifyCalledWithArgs("firewall", "add", "rule", "name=myst-kill-switch-allow-example.com", "dir=out", "action=allow", "remoteip=1.2.3.4,5.6.7.8"))
+}
+
+func Test_excludeIP(t *testing.T) {
+	for ip, expected := range map[string]string{
+		"10.0.0.1":        "0.0.0.0-10.0.0.0,10.0.0.2-255.255.255.255",
+		"10.0.1.0":        "0.0.0.0-10.0.0.255,10.0.1.1-255.255.255.255",
+		"0.0.0.0":         "0.0.0.1-255.255.255.255",
+		"255.255.255.255": "0.0.0.0-255.255.255.254",
+	} {
+		ranges, err := excludeIP(ip)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, ranges, ip)
+	}
+
+	_, err := excludeIP("::1")
+	assert.Error(t, err)
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package fleet
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/pkg/errors"
+)
+
+type enrollmentDTO struct {
+	Token    string `json:"token"`
+	Identity string `json:"identity"`
+}
+
+// Client communicates with the fleet controller using the bootstrap token.
+type Client struct {
+	httpClient    *requests.HTTPClient
+	controllerURL string
+	token         string
+}
+
+// NewClient returns fleet controller client.
+func NewClient(httpClient *requests.HTTPClient, controllerURL, token string) *Client {
+	return &Client{
+		httpClient:    httpClient,
+		controllerURL: strings.TrimSuffix(controllerURL, "/"),
+		token:         token,
+	}
+}
+
+// Profile fetches the configuration profile assigned to the bootstrap token.
+func (c *Client) Profile() (Profile, error) {
+	req, err := requests.NewGetRequest(c.controllerURL, "profile", nil)
+	if err != nil {
+		return Profile{}, err
+	}
+	c.authorize(req)
+
+	var profile Profile
+	if err := c.httpClient.DoRequestAndParseResponse(req, &profile); err != nil {
+		return Profile{}, errors.Wrap(err, "failed to fetch fleet profile")
+	}
+	return profile, nil
+}
+
+// Enroll reports the provider identity to the fleet controller. The request is signed
+// by the identity, so the controller can bind the identity to the bootstrap token.
+func (c *Client) Enroll(id identity.Identity, signer identity.Signer) error {
+	req, err := requests.NewSignedPostRequest(c.controllerURL, "enroll", enrollmentDTO{
+		Token:    c.token,
+		Identity: id.Address,
+	}, signer)
+	if err != nil {
+		return err
+	}
+	c.authorize(req)
+
+	if err := c.httpClient.DoRequest(req); err != nil {
+		return errors.Wrap(err, "failed to enroll identity")
+	}
+	return nil
+}
+
+func (c *Client) authorize(req *http.Request) {
+	req.Header.Set("X-Fleet-Token", c.token)
+}
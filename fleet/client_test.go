@@ -0,0 +1,71 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package fleet
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_Profile(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/profile", r.URL.Path)
+		if r.Header.Get("X-Fleet-Token") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte(`{"services": ["wireguard"], "config": {"payment.price-gb": 0.2}}`))
+	}))
+	defer server.Close()
+
+	profile, err := NewClient(requests.NewHTTPClient("0.0.0.0", requests.DefaultTimeout), server.URL+"/", "token").Profile()
+	assert.NoError(t, err)
+	assert.Equal(t, Profile{
+		Services: []string{"wireguard"},
+		Config:   map[string]interface{}{"payment.price-gb": 0.2},
+	}, profile)
+
+	_, err = NewClient(requests.NewHTTPClient("0.0.0.0", requests.DefaultTimeout), server.URL, "wrong").Profile()
+	assert.Error(t, err)
+}
+
+func TestClient_Enroll(t *testing.T) {
+	var enrollment enrollmentDTO
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/enroll", r.URL.Path)
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "token", r.Header.Get("X-Fleet-Token"))
+		assert.NotEmpty(t, r.Header.Get("Authorization"))
+
+		body, _ := ioutil.ReadAll(r.Body)
+		assert.NoError(t, json.Unmarshal(body, &enrollment))
+	}))
+	defer server.Close()
+
+	client := NewClient(requests.NewHTTPClient("0.0.0.0", requests.DefaultTimeout), server.URL, "token")
+	err := client.Enroll(identity.FromAddress("0x1"), &identity.SignerFake{})
+	assert.NoError(t, err)
+	assert.Equal(t, enrollmentDTO{Token: "token", Identity: "0x1"}, enrollment)
+}
@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package fleet
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/pkg/errors"
+)
+
+// ErrNoToken indicates that neither bootstrap token nor token file were given.
+var ErrNoToken = errors.New("fleet bootstrap token is not provided")
+
+// Profile is a node configuration prepared by the fleet controller.
+type Profile struct {
+	// Services lists service types to start, e.g. "wireguard".
+	Services []string `json:"services"`
+	// Config holds configuration values keyed by flag name, e.g. "payment.price-gb".
+	Config map[string]interface{} `json:"config"`
+}
+
+// Apply stores profile configuration as user configuration, so explicit CLI flags still take precedence.
+func (p Profile) Apply(cfg *config.Config) {
+	for key, value := range p.Config {
+		if list, ok := value.([]interface{}); ok {
+			value = toStringSlice(list)
+		}
+		cfg.SetUser(key, value)
+	}
+}
+
+func toStringSlice(list []interface{}) []string {
+	res := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			res = append(res, s)
+		}
+	}
+	return res
+}
+
+// ReadToken returns bootstrap token given directly or read from the token file.
+func ReadToken(token, tokenFile string) (string, error) {
+	if token != "" {
+		return strings.TrimSpace(token), nil
+	}
+	if tokenFile == "" {
+		return "", ErrNoToken
+	}
+	data, err := ioutil.ReadFile(tokenFile)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read fleet bootstrap token")
+	}
+	token = strings.TrimSpace(string(data))
+	if token == "" {
+		return "", ErrNoToken
+	}
+	return token, nil
+}
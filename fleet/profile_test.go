@@ -0,0 +1,65 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package fleet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfile_Apply(t *testing.T) {
+	cfg := config.NewConfig()
+	cfg.SetCLI("payment.price-gb", 0.5)
+
+	Profile{Config: map[string]interface{}{
+		"payment.price-gb":     0.2,
+		"payment.price-minute": 0.001,
+		"obfuscation.offer":    []interface{}{"scramble"},
+	}}.Apply(cfg)
+
+	assert.Equal(t, 0.5, cfg.GetFloat64("payment.price-gb"))
+	assert.Equal(t, 0.001, cfg.GetFloat64("payment.price-minute"))
+	assert.Equal(t, []string{"scramble"}, cfg.GetStringSlice("obfuscation.offer"))
+}
+
+func TestReadToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fleet")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+	tokenFile := filepath.Join(dir, "token")
+	assert.NoError(t, ioutil.WriteFile(tokenFile, []byte("from-file\n"), 0600))
+
+	token, err := ReadToken(" direct ", tokenFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "direct", token)
+
+	token, err = ReadToken("", tokenFile)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-file", token)
+
+	_, err = ReadToken("", "")
+	assert.Equal(t, ErrNoToken, err)
+
+	_, err = ReadToken("", filepath.Join(dir, "missing"))
+	assert.Error(t, err)
+}
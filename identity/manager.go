@@ -33,8 +33,8 @@ import (
 
 // Identity events
 const (
-	AppTopicIdentityUnlock  = "identity-unlocked"
-	AppTopicIdentityCreated = "identity-created"
+	AppTopicIdentityUnlock  = "identity.unlocked"
+	AppTopicIdentityCreated = "identity.created"
 )
 
 type identityManager struct {
@@ -26,11 +26,11 @@ import (
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/payments/bindings"
-	paymentClient "github.com/mysteriumnetwork/payments/client"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -42,6 +42,10 @@ type registryStorage interface {
 	GetAll() ([]StoredRegistrationStatus, error)
 }
 
+type ethClientGetter interface {
+	Client() *ethclient.Client
+}
+
 type contractRegistry struct {
 	accountantAddress common.Address
 	storage           registryStorage
@@ -49,12 +53,12 @@ type contractRegistry struct {
 	once              sync.Once
 	publisher         eventbus.Publisher
 	lock              sync.Mutex
-	ethC              *paymentClient.ReconnectableEthClient
+	ethC              ethClientGetter
 	registryAddress   common.Address
 }
 
 // NewIdentityRegistryContract creates identity registry service which uses blockchain for information
-func NewIdentityRegistryContract(ethClient *paymentClient.ReconnectableEthClient, registryAddress, accountantAddress common.Address, registryStorage registryStorage, publisher eventbus.Publisher) (*contractRegistry, error) {
+func NewIdentityRegistryContract(ethClient ethClientGetter, registryAddress, accountantAddress common.Address, registryStorage registryStorage, publisher eventbus.Publisher) (*contractRegistry, error) {
 	log.Info().Msgf("Using registryAddress %v accountantAddress %v", registryAddress.Hex(), accountantAddress.Hex())
 	return &contractRegistry{
 		accountantAddress: accountantAddress,
@@ -385,7 +389,7 @@ func (registry *contractRegistry) isRegisteredInBC(id identity.Identity) (Regist
 }
 
 // AppTopicEthereumClientReconnected indicates that the ethereum client has reconnected.
-var AppTopicEthereumClientReconnected = "ether-client-reconnect"
+var AppTopicEthereumClientReconnected = "identity.registry.ether_client_reconnected"
 
 func (registry *contractRegistry) handleEtherClientReconnect(_ interface{}) {
 	err := registry.loadInitialState()
@@ -0,0 +1,251 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/node/event"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const lightRegistryPollInterval = 10 * time.Second
+
+type registrationChecker interface {
+	IsRegistered(registryAddress, addressToCheck common.Address) (bool, error)
+	GetProviderChannel(accountantAddress common.Address, addressToCheck common.Address, pending bool) (client.ProviderChannel, error)
+}
+
+type lightRegistry struct {
+	accountantAddress common.Address
+	registryAddress   common.Address
+	storage           registryStorage
+	publisher         eventbus.Publisher
+	bc                registrationChecker
+	pollInterval      time.Duration
+	stop              chan struct{}
+	once              sync.Once
+	lock              sync.Mutex
+}
+
+// NewIdentityRegistryLight creates identity registry service which reads blockchain through the transactor,
+// registration events are not available in this mode so pending registrations are polled.
+func NewIdentityRegistryLight(bc registrationChecker, registryAddress, accountantAddress common.Address, registryStorage registryStorage, publisher eventbus.Publisher) *lightRegistry {
+	log.Info().Msgf("Using light registry with registryAddress %v accountantAddress %v", registryAddress.Hex(), accountantAddress.Hex())
+	return &lightRegistry{
+		accountantAddress: accountantAddress,
+		registryAddress:   registryAddress,
+		storage:           registryStorage,
+		publisher:         publisher,
+		bc:                bc,
+		pollInterval:      lightRegistryPollInterval,
+		stop:              make(chan struct{}),
+	}
+}
+
+// Subscribe subscribes the light registry to relevant events
+func (registry *lightRegistry) Subscribe(eb eventbus.Subscriber) error {
+	err := eb.SubscribeAsync(event.AppTopicNode, registry.handleNodeEvent)
+	if err != nil {
+		return err
+	}
+	return eb.Subscribe(AppTopicTransactorRegistration, registry.handleRegistrationEvent)
+}
+
+// GetRegistrationStatus returns the registration status of the provided identity
+func (registry *lightRegistry) GetRegistrationStatus(id identity.Identity) (RegistrationStatus, error) {
+	status, err := registry.storage.Get(id)
+	if err == nil {
+		return status.RegistrationStatus, nil
+	}
+
+	if err != ErrNotFound {
+		return Unregistered, errors.Wrap(err, "could not check status in local db")
+	}
+
+	statusBC, err := registry.isRegisteredInBC(id)
+	if err != nil {
+		return Unregistered, errors.Wrap(err, "could not check identity registration status through transactor")
+	}
+	err = registry.storage.Store(StoredRegistrationStatus{
+		Identity:           id,
+		RegistrationStatus: statusBC,
+	})
+	return statusBC, errors.Wrap(err, "could not store registration status")
+}
+
+func (registry *lightRegistry) handleNodeEvent(ev event.Payload) {
+	if ev.Status == event.StatusStarted {
+		registry.handleStart()
+		return
+	}
+	if ev.Status == event.StatusStopped {
+		registry.handleStop()
+		return
+	}
+}
+
+func (registry *lightRegistry) handleStop() {
+	registry.once.Do(func() {
+		log.Info().Msg("Stopping registry...")
+		close(registry.stop)
+	})
+}
+
+func (registry *lightRegistry) handleStart() {
+	log.Info().Msg("Starting registry...")
+	if err := registry.loadInitialState(); err != nil {
+		log.Error().Err(err).Msg("Could not start registry")
+	}
+}
+
+func (registry *lightRegistry) handleRegistrationEvent(ev IdentityRegistrationRequest) {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	ID := identity.FromAddress(ev.Identity)
+	status, err := registry.storage.Get(ID)
+	if err != nil && err != ErrNotFound {
+		log.Error().Err(err).Msg("Could not get status from local db")
+		return
+	}
+
+	if err == nil && status.RegistrationStatus == RegisteredProvider {
+		log.Info().Msgf("Identity %q already fully registered, skipping", ev.Identity)
+		return
+	}
+
+	s := InProgress
+	if ev.Stake > 0 {
+		s = Promoting
+	}
+
+	go registry.publisher.Publish(AppTopicIdentityRegistration, AppEventIdentityRegistration{
+		ID:     ID,
+		Status: s,
+	})
+	err = registry.storage.Store(StoredRegistrationStatus{
+		Identity:           ID,
+		RegistrationStatus: s,
+	})
+	if err != nil {
+		log.Error().Err(err).Stack().Msg("Could not store registration status")
+	}
+
+	go registry.waitForRegistration(ID, s == Promoting)
+}
+
+func (registry *lightRegistry) waitForRegistration(id identity.Identity, promoting bool) {
+	log.Info().Msgf("Polling registration status of %s", id.Address)
+	for {
+		select {
+		case <-registry.stop:
+			return
+		case <-time.After(registry.pollInterval):
+		}
+
+		status, err := registry.isRegisteredInBC(id)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not poll registration status of %s", id.Address)
+			continue
+		}
+		if !status.Registered() || (promoting && status != RegisteredProvider) {
+			continue
+		}
+
+		log.Info().Msgf("Registration of %v confirmed", id.Address)
+		registry.publisher.Publish(AppTopicIdentityRegistration, AppEventIdentityRegistration{
+			ID:     id,
+			Status: status,
+		})
+		err = registry.storage.Store(StoredRegistrationStatus{
+			Identity:           id,
+			RegistrationStatus: status,
+		})
+		if err != nil {
+			log.Error().Err(err).Msg("Could not store registration status")
+		}
+		return
+	}
+}
+
+func (registry *lightRegistry) loadInitialState() error {
+	registry.lock.Lock()
+	defer registry.lock.Unlock()
+
+	entries, err := registry.storage.GetAll()
+	if err != nil {
+		return errors.Wrap(err, "Could not fetch previous registrations")
+	}
+
+	for i := range entries {
+		if time.Now().UTC().Sub(entries[i].UpdatedAt) > month {
+			continue
+		}
+		switch entries[i].RegistrationStatus {
+		case RegistrationError, InProgress, Promoting, RegisteredConsumer:
+			if err := registry.handleUnregisteredIdentityInitialLoad(entries[i]); err != nil {
+				return errors.Wrapf(err, "could not check %q registration status", entries[i].Identity)
+			}
+		}
+	}
+	return nil
+}
+
+func (registry *lightRegistry) handleUnregisteredIdentityInitialLoad(entry StoredRegistrationStatus) error {
+	status, err := registry.isRegisteredInBC(entry.Identity)
+	if err != nil {
+		return err
+	}
+
+	if status.Registered() && (entry.RegistrationStatus != Promoting || status == RegisteredProvider) {
+		return errors.Wrap(registry.storage.Store(StoredRegistrationStatus{
+			Identity:           entry.Identity,
+			RegistrationStatus: status,
+		}), "could not store registration status on local db")
+	}
+
+	go registry.waitForRegistration(entry.Identity, entry.RegistrationStatus == Promoting)
+	return nil
+}
+
+func (registry *lightRegistry) isRegisteredInBC(id identity.Identity) (RegistrationStatus, error) {
+	registered, err := registry.bc.IsRegistered(registry.registryAddress, id.ToCommonAddress())
+	if err != nil {
+		return RegistrationError, errors.Wrap(err, "could not check registration status")
+	}
+	if !registered {
+		return Unregistered, nil
+	}
+
+	providerChannel, err := registry.bc.GetProviderChannel(registry.accountantAddress, id.ToCommonAddress(), false)
+	if err != nil {
+		return RegistrationError, errors.Wrap(err, "could not get provider channel")
+	}
+	if providerChannel.Loan != nil && providerChannel.Loan.Cmp(big.NewInt(0)) == 1 {
+		return RegisteredProvider, nil
+	}
+	return RegisteredConsumer, nil
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package registry
+
+import (
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/payments/client"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockRegistrationChecker struct {
+	lock       sync.Mutex
+	registered bool
+	loan       *big.Int
+}
+
+func (mrc *mockRegistrationChecker) set(registered bool, loan int64) {
+	mrc.lock.Lock()
+	defer mrc.lock.Unlock()
+	mrc.registered = registered
+	mrc.loan = big.NewInt(loan)
+}
+
+func (mrc *mockRegistrationChecker) IsRegistered(_, _ common.Address) (bool, error) {
+	mrc.lock.Lock()
+	defer mrc.lock.Unlock()
+	return mrc.registered, nil
+}
+
+func (mrc *mockRegistrationChecker) GetProviderChannel(_ common.Address, _ common.Address, _ bool) (client.ProviderChannel, error) {
+	mrc.lock.Lock()
+	defer mrc.lock.Unlock()
+	return client.ProviderChannel{Loan: mrc.loan}, nil
+}
+
+type mockRegistryStorage struct {
+	lock     sync.Mutex
+	statuses map[identity.Identity]StoredRegistrationStatus
+}
+
+func (mrs *mockRegistryStorage) Store(status StoredRegistrationStatus) error {
+	mrs.lock.Lock()
+	defer mrs.lock.Unlock()
+	mrs.statuses[status.Identity] = status
+	return nil
+}
+
+func (mrs *mockRegistryStorage) Get(id identity.Identity) (StoredRegistrationStatus, error) {
+	mrs.lock.Lock()
+	defer mrs.lock.Unlock()
+	status, ok := mrs.statuses[id]
+	if !ok {
+		return StoredRegistrationStatus{}, ErrNotFound
+	}
+	return status, nil
+}
+
+func (mrs *mockRegistryStorage) GetAll() ([]StoredRegistrationStatus, error) {
+	mrs.lock.Lock()
+	defer mrs.lock.Unlock()
+	var result []StoredRegistrationStatus
+	for _, status := range mrs.statuses {
+		result = append(result, status)
+	}
+	return result, nil
+}
+
+type mockPublisher struct {
+	events chan AppEventIdentityRegistration
+}
+
+func (mp *mockPublisher) Publish(_ string, data interface{}) {
+	mp.events <- data.(AppEventIdentityRegistration)
+}
+
+func newTestLightRegistry(checker *mockRegistrationChecker) (*lightRegistry, *mockRegistryStorage, *mockPublisher) {
+	storage := &mockRegistryStorage{statuses: make(map[identity.Identity]StoredRegistrationStatus)}
+	publisher := &mockPublisher{events: make(chan AppEventIdentityRegistration, 10)}
+	registry := NewIdentityRegistryLight(checker, common.Address{}, common.Address{}, storage, publisher)
+	registry.pollInterval = time.Millisecond
+	return registry, storage, publisher
+}
+
+func TestLightRegistry_GetRegistrationStatus(t *testing.T) {
+	checker := &mockRegistrationChecker{}
+	registry, storage, _ := newTestLightRegistry(checker)
+	id := identity.FromAddress("0x1")
+
+	status, err := registry.GetRegistrationStatus(id)
+	assert.NoError(t, err)
+	assert.Equal(t, Unregistered, status)
+
+	checker.set(true, 1)
+	id2 := identity.FromAddress("0x2")
+	status, err = registry.GetRegistrationStatus(id2)
+	assert.NoError(t, err)
+	assert.Equal(t, RegisteredProvider, status)
+
+	stored, err := storage.Get(id2)
+	assert.NoError(t, err)
+	assert.Equal(t, RegisteredProvider, stored.RegistrationStatus)
+}
+
+func TestLightRegistry_PollsPendingRegistration(t *testing.T) {
+	checker := &mockRegistrationChecker{}
+	registry, storage, publisher := newTestLightRegistry(checker)
+	defer registry.handleStop()
+	id := identity.FromAddress("0x1")
+
+	registry.handleRegistrationEvent(IdentityRegistrationRequest{Identity: id.Address})
+	assert.Equal(t, AppEventIdentityRegistration{ID: id, Status: InProgress}, <-publisher.events)
+
+	checker.set(true, 0)
+	select {
+	case ev := <-publisher.events:
+		assert.Equal(t, AppEventIdentityRegistration{ID: id, Status: RegisteredConsumer}, ev)
+	case <-time.After(time.Second):
+		t.Fatal("registration was not confirmed")
+	}
+
+	stored, err := storage.Get(id)
+	assert.NoError(t, err)
+	assert.Equal(t, RegisteredConsumer, stored.RegistrationStatus)
+}
@@ -81,7 +81,7 @@ func (srs StoredRegistrationStatus) FromEvent(status RegistrationStatus, ev Iden
 }
 
 // AppTopicIdentityRegistration represents the registration event topic.
-const AppTopicIdentityRegistration = "registration_event_topic"
+const AppTopicIdentityRegistration = "identity.registration"
 
 // AppEventIdentityRegistration represents the registration event payload.
 type AppEventIdentityRegistration struct {
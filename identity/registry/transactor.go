@@ -34,10 +34,10 @@ import (
 )
 
 // AppTopicTransactorRegistration represents the registration topic to which events regarding registration attempts on transactor will occur
-const AppTopicTransactorRegistration = "transactor_identity_registration"
+const AppTopicTransactorRegistration = "identity.transactor.registration"
 
 // AppTopicTransactorTopUp represents the top up topic to which events regarding top up attempts are sent.
-const AppTopicTransactorTopUp = "transactor_top_up"
+const AppTopicTransactorTopUp = "identity.transactor.top_up"
 
 type channelProvider interface {
 	GetProviderChannel(accountantAddress common.Address, addressToCheck common.Address, pending bool) (client.ProviderChannel, error)
@@ -26,7 +26,6 @@ import (
 	"strings"
 	"time"
 
-	"github.com/mysteriumnetwork/go-openvpn/openvpn"
 	"github.com/mysteriumnetwork/node/logconfig/rollingwriter"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
@@ -57,7 +56,7 @@ func Bootstrap() {
 		return fmt.Sprintf("%-41v", file+":"+strconv.Itoa(line))
 	}
 
-	openvpn.UseLogger(zerologOpenvpnLogger{})
+	bootstrapOpenvpnLogger()
 	logger := makeLogger(consoleWriter())
 	setGlobalLogger(&logger)
 }
@@ -99,7 +98,7 @@ func zeroLogger(out io.Writer) io.Writer {
 }
 
 func makeLogger(w io.Writer) zerolog.Logger {
-	return log.Output(w).
+	return log.Output(io.MultiWriter(w, SessionLogs)).
 		Level(zerolog.DebugLevel).
 		With().
 		Caller().
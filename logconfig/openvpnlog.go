@@ -1,3 +1,5 @@
+// +build !mobile_slim
+
 /*
  * Copyright (C) 2019 The "MysteriumNetwork/node" Authors.
  *
@@ -21,9 +23,15 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/mysteriumnetwork/go-openvpn/openvpn"
 	"github.com/rs/zerolog/log"
 )
 
+// bootstrapOpenvpnLogger routes go-openvpn logs to zerolog.
+func bootstrapOpenvpnLogger() {
+	openvpn.UseLogger(zerologOpenvpnLogger{})
+}
+
 type zerologOpenvpnLogger struct {
 }
 
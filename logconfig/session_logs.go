@@ -0,0 +1,105 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logconfig
+
+import (
+	"encoding/json"
+	"sync"
+
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// FieldSessionID is the log field correlating all log lines of a single session.
+	FieldSessionID = "session_id"
+	// FieldConsumerID is the log field holding consumer of the session.
+	FieldConsumerID = "consumer_id"
+
+	sessionLogCapacity = 5000
+)
+
+// SessionLogs keeps the most recent log lines correlated with sessions.
+var SessionLogs = NewSessionLogBuffer(sessionLogCapacity)
+
+// SessionLogger returns logger annotating every log line with session correlation fields.
+func SessionLogger(sessionID, consumerID string) zerolog.Logger {
+	ctx := log.Logger.With().Str(FieldSessionID, sessionID)
+	if consumerID != "" {
+		ctx = ctx.Str(FieldConsumerID, consumerID)
+	}
+	return ctx.Logger()
+}
+
+// SessionLogBuffer is a ring buffer of JSON log lines carrying session correlation ID.
+type SessionLogBuffer struct {
+	lock  sync.Mutex
+	lines []sessionLogLine
+	next  int
+}
+
+type sessionLogLine struct {
+	sessionID string
+	line      json.RawMessage
+}
+
+// NewSessionLogBuffer creates buffer keeping up to capacity log lines.
+func NewSessionLogBuffer(capacity int) *SessionLogBuffer {
+	return &SessionLogBuffer{lines: make([]sessionLogLine, 0, capacity)}
+}
+
+// Write stores JSON log line if it is correlated with a session, other lines are ignored.
+func (b *SessionLogBuffer) Write(p []byte) (int, error) {
+	var fields struct {
+		SessionID string `json:"session_id"`
+	}
+	if err := json.Unmarshal(p, &fields); err != nil || fields.SessionID == "" {
+		return len(p), nil
+	}
+
+	line := sessionLogLine{
+		sessionID: fields.SessionID,
+		line:      append(json.RawMessage{}, p...),
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if len(b.lines) < cap(b.lines) {
+		b.lines = append(b.lines, line)
+	} else {
+		b.lines[b.next] = line
+	}
+	b.next = (b.next + 1) % cap(b.lines)
+	return len(p), nil
+}
+
+// Lines returns buffered log lines of the session, oldest first.
+func (b *SessionLogBuffer) Lines(sessionID string) []json.RawMessage {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	result := make([]json.RawMessage, 0)
+	for i := range b.lines {
+		line := b.lines[(b.next+i)%len(b.lines)]
+		if line.sessionID == sessionID {
+			result = append(result, line.line)
+		}
+	}
+	return result
+}
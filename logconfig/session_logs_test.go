@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package logconfig
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSessionLogBuffer_KeepsLinesOfSessions(t *testing.T) {
+	// given
+	buffer := NewSessionLogBuffer(10)
+	logger := zerolog.New(buffer)
+
+	// when
+	logger.Info().Str(FieldSessionID, "session-1").Msg("first")
+	logger.Info().Msg("unrelated")
+	logger.Info().Str(FieldSessionID, "session-2").Msg("other")
+	logger.Info().Str(FieldSessionID, "session-1").Msg("second")
+
+	// then
+	assert.Equal(t, []string{"first", "second"}, messages(t, buffer.Lines("session-1")))
+	assert.Equal(t, []string{"other"}, messages(t, buffer.Lines("session-2")))
+	assert.Empty(t, buffer.Lines("session-3"))
+}
+
+func TestSessionLogBuffer_DropsOldestLines(t *testing.T) {
+	// given
+	buffer := NewSessionLogBuffer(2)
+	logger := zerolog.New(buffer).With().Str(FieldSessionID, "session").Logger()
+
+	// when
+	logger.Info().Msg("1")
+	logger.Info().Msg("2")
+	logger.Info().Msg("3")
+
+	// then
+	assert.Equal(t, []string{"2", "3"}, messages(t, buffer.Lines("session")))
+}
+
+func messages(t *testing.T, lines []json.RawMessage) []string {
+	var result []string
+	for _, line := range lines {
+		var fields struct {
+			Message string `json:"message"`
+		}
+		assert.NoError(t, json.Unmarshal(line, &fields))
+		result = append(result, fields.Message)
+	}
+	return result
+}
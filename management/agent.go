@@ -0,0 +1,227 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	nats_lib "github.com/nats-io/nats.go"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// MaxCommandAge is how far command timestamp may be from the node clock for command to be accepted.
+const MaxCommandAge = time.Minute
+
+var (
+	// ErrInvalidSignature indicates that command was not signed by the operator.
+	ErrInvalidSignature = errors.New("command signature is invalid")
+	// ErrCommandExpired indicates that command timestamp is too far from the node clock.
+	ErrCommandExpired = errors.New("command is expired")
+	// ErrWrongProvider indicates that command was issued to another provider.
+	ErrWrongProvider = errors.New("command was issued to another provider")
+	// ErrCommandReplayed indicates that command with the same ID was already handled.
+	ErrCommandReplayed = errors.New("command was already handled")
+	// ErrUnknownAction indicates that command action is not supported.
+	ErrUnknownAction = errors.New("unknown management action")
+)
+
+// Executor carries out management commands on the node.
+type Executor interface {
+	RestartService(serviceType string) error
+	UpdateConfig(values map[string]interface{}) error
+	Diagnostics() (interface{}, error)
+}
+
+// Agent accepts signed management commands from the operator identity over the broker.
+type Agent struct {
+	brokerConn nats.Connection
+	operator   identity.Identity
+	verifier   identity.Verifier
+	executor   Executor
+	audit      *AuditLog
+	now        func() time.Time
+
+	lock         sync.Mutex
+	provider     identity.Identity
+	subscription *nats_lib.Subscription
+	seen         map[string]time.Time
+}
+
+// NewAgent creates management agent which accepts commands signed by the given operator identity.
+func NewAgent(brokerConn nats.Connection, operator identity.Identity, executor Executor, audit *AuditLog) *Agent {
+	return &Agent{
+		brokerConn: brokerConn,
+		operator:   operator,
+		verifier:   identity.NewVerifierIdentity(operator),
+		executor:   executor,
+		audit:      audit,
+		now:        time.Now,
+		seen:       make(map[string]time.Time),
+	}
+}
+
+// Subscribe starts listening for management commands once a service of provider identity is running.
+func (a *Agent) Subscribe(bus eventbus.Subscriber) error {
+	return bus.SubscribeAsync(servicestate.AppTopicServiceStatus, a.handleServiceStatus)
+}
+
+func (a *Agent) handleServiceStatus(e servicestate.AppEventServiceStatus) {
+	if e.Status != string(servicestate.Running) {
+		return
+	}
+	if err := a.Start(identity.FromAddress(e.ProviderID)); err != nil {
+		log.Err(err).Msg("Could not start management agent")
+	}
+}
+
+// Start listens for management commands addressed to the given provider.
+func (a *Agent) Start(providerID identity.Identity) error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.subscription != nil {
+		if !strings.EqualFold(a.provider.Address, providerID.Address) {
+			log.Warn().Msgf("Management agent already accepts commands for provider %s, ignoring %s", a.provider.Address, providerID.Address)
+		}
+		return nil
+	}
+
+	subscription, err := a.brokerConn.Subscribe(subject(providerID), func(msg *nats_lib.Msg) {
+		reply, err := json.Marshal(a.handle(msg.Data))
+		if err != nil {
+			log.Err(err).Msg("Could not marshal management reply")
+			return
+		}
+		if msg.Reply == "" {
+			return
+		}
+		if err := a.brokerConn.Publish(msg.Reply, reply); err != nil {
+			log.Err(err).Msg("Could not publish management reply")
+		}
+	})
+	if err != nil {
+		return err
+	}
+	a.subscription = subscription
+	a.provider = providerID
+
+	log.Info().Msgf("Management agent accepting commands from operator %s", a.operator.Address)
+	return nil
+}
+
+// Stop stops listening for management commands.
+func (a *Agent) Stop() {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	if a.subscription == nil {
+		return
+	}
+	if err := a.subscription.Unsubscribe(); err != nil {
+		log.Err(err).Msg("Failed to unsubscribe from management topic")
+	}
+	a.subscription = nil
+}
+
+func (a *Agent) handle(data []byte) Reply {
+	entry := AuditEntry{Time: a.now(), Operator: a.operator.Address}
+
+	cmd, err := a.verify(data)
+	if cmd != nil {
+		entry.CommandID = cmd.ID
+		entry.Action = cmd.Action
+	}
+
+	var result interface{}
+	if err == nil {
+		entry.Accepted = true
+		result, err = a.execute(*cmd)
+	}
+
+	reply := Reply{ID: entry.CommandID, OK: err == nil, Result: result}
+	if err != nil {
+		entry.Error = err.Error()
+		reply.Error = err.Error()
+	}
+	a.audit.Record(entry)
+	return reply
+}
+
+// verify checks that command is signed by the operator, is issued to this provider and was not handled before.
+func (a *Agent) verify(data []byte) (*Command, error) {
+	var signed signedCommand
+	if err := json.Unmarshal(data, &signed); err != nil {
+		return nil, err
+	}
+
+	var cmd Command
+	if err := json.Unmarshal(signed.Command, &cmd); err != nil {
+		return nil, err
+	}
+	if !a.verifier.Verify(signed.Command, identity.SignatureBase64(signed.Signature)) {
+		return &cmd, ErrInvalidSignature
+	}
+
+	a.lock.Lock()
+	provider := a.provider
+	a.lock.Unlock()
+	if provider.Address == "" || !strings.EqualFold(cmd.ProviderID, provider.Address) {
+		return &cmd, ErrWrongProvider
+	}
+
+	now := a.now()
+	issued := time.Unix(cmd.Timestamp, 0)
+	if issued.Before(now.Add(-MaxCommandAge)) || issued.After(now.Add(MaxCommandAge)) {
+		return &cmd, ErrCommandExpired
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for id, seenAt := range a.seen {
+		if now.Sub(seenAt) > 2*MaxCommandAge {
+			delete(a.seen, id)
+		}
+	}
+	if _, ok := a.seen[cmd.ID]; ok {
+		return &cmd, ErrCommandReplayed
+	}
+	a.seen[cmd.ID] = now
+
+	return &cmd, nil
+}
+
+func (a *Agent) execute(cmd Command) (interface{}, error) {
+	switch cmd.Action {
+	case ActionRestartService:
+		return nil, a.executor.RestartService(cmd.ServiceType)
+	case ActionUpdateConfig:
+		return nil, a.executor.UpdateConfig(cmd.Config)
+	case ActionDiagnostics:
+		return a.executor.Diagnostics()
+	default:
+		return nil, ErrUnknownAction
+	}
+}
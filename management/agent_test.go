@@ -0,0 +1,173 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/core/service/servicestate"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	providerID = identity.FromAddress("0x1")
+	operatorID = identity.FromAddress("0x2")
+)
+
+type mockExecutor struct {
+	restarted []string
+	config    map[string]interface{}
+}
+
+func (e *mockExecutor) RestartService(serviceType string) error {
+	e.restarted = append(e.restarted, serviceType)
+	return nil
+}
+
+func (e *mockExecutor) UpdateConfig(values map[string]interface{}) error {
+	e.config = values
+	return nil
+}
+
+func (e *mockExecutor) Diagnostics() (interface{}, error) {
+	return map[string]string{"version": "1.0"}, nil
+}
+
+func newTestAgent(t *testing.T, conn nats.Connection) (*Agent, *mockExecutor, string) {
+	dir, err := ioutil.TempDir("", "management")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	executor := &mockExecutor{}
+	auditPath := filepath.Join(dir, "audit.log")
+	agent := NewAgent(conn, operatorID, executor, NewAuditLog(auditPath))
+	agent.verifier = &identity.VerifierFake{}
+	agent.provider = providerID
+	return agent, executor, auditPath
+}
+
+func pack(t *testing.T, cmd Command) []byte {
+	if cmd.ProviderID == "" {
+		cmd.ProviderID = providerID.Address
+	}
+	data, err := packCommand(&identity.SignerFake{}, cmd)
+	assert.NoError(t, err)
+	return data
+}
+
+func readAudit(t *testing.T, path string) []AuditEntry {
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	var entries []AuditEntry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry AuditEntry
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func TestAgent_RepliesOverBroker(t *testing.T) {
+	conn := nats.StartConnectionMock()
+	defer conn.Close()
+	agent, executor, _ := newTestAgent(t, conn)
+	agent.handleServiceStatus(servicestate.AppEventServiceStatus{ProviderID: providerID.Address, Status: string(servicestate.Running)})
+
+	reply, err := SendCommand(conn, &identity.SignerFake{}, providerID, Command{ID: "1", Action: ActionRestartService, ServiceType: "wireguard"}, time.Second)
+	assert.NoError(t, err)
+	assert.Equal(t, &Reply{ID: "1", OK: true}, reply)
+	assert.Equal(t, []string{"wireguard"}, executor.restarted)
+}
+
+func TestAgent_StartsOnlyForRunningProvider(t *testing.T) {
+	conn := nats.StartConnectionMock()
+	defer conn.Close()
+	agent, _, _ := newTestAgent(t, conn)
+	agent.provider = identity.Identity{}
+
+	agent.handleServiceStatus(servicestate.AppEventServiceStatus{ProviderID: providerID.Address, Status: string(servicestate.Starting)})
+	assert.Nil(t, agent.subscription)
+
+	agent.handleServiceStatus(servicestate.AppEventServiceStatus{ProviderID: providerID.Address, Status: string(servicestate.Running)})
+	agent.handleServiceStatus(servicestate.AppEventServiceStatus{ProviderID: "0x3", Status: string(servicestate.Running)})
+	assert.NotNil(t, agent.subscription)
+	assert.Equal(t, providerID, agent.provider)
+}
+
+func TestAgent_ExecutesSignedCommands(t *testing.T) {
+	agent, executor, auditPath := newTestAgent(t, nil)
+
+	reply := agent.handle(pack(t, Command{ID: "1", Action: ActionUpdateConfig, Config: map[string]interface{}{"payment.price-gb": 0.1}}))
+	assert.Equal(t, Reply{ID: "1", OK: true}, reply)
+	assert.Equal(t, map[string]interface{}{"payment.price-gb": 0.1}, executor.config)
+
+	reply = agent.handle(pack(t, Command{ID: "2", Action: ActionDiagnostics}))
+	assert.Equal(t, Reply{ID: "2", OK: true, Result: map[string]string{"version": "1.0"}}, reply)
+
+	reply = agent.handle(pack(t, Command{ID: "3", Action: "shutdown"}))
+	assert.Equal(t, Reply{ID: "3", Error: ErrUnknownAction.Error()}, reply)
+
+	entries := readAudit(t, auditPath)
+	assert.Len(t, entries, 3)
+	assert.Equal(t, operatorID.Address, entries[0].Operator)
+	assert.Equal(t, ActionUpdateConfig, entries[0].Action)
+	assert.True(t, entries[0].Accepted)
+	assert.Equal(t, "3", entries[2].CommandID)
+	assert.Equal(t, ErrUnknownAction.Error(), entries[2].Error)
+}
+
+func TestAgent_RejectsUnverifiedCommands(t *testing.T) {
+	agent, executor, auditPath := newTestAgent(t, nil)
+	now := time.Now()
+	agent.now = func() time.Time { return now }
+
+	cmdBytes, _ := json.Marshal(Command{ID: "1", Action: ActionRestartService, Timestamp: now.Unix()})
+	forged, _ := json.Marshal(signedCommand{Command: cmdBytes, Signature: "Zm9yZ2Vk"})
+	reply := agent.handle(forged)
+	assert.Equal(t, Reply{ID: "1", Error: ErrInvalidSignature.Error()}, reply)
+
+	reply = agent.handle(pack(t, Command{ID: "2", Action: ActionRestartService, Timestamp: now.Add(-2 * MaxCommandAge).Unix()}))
+	assert.Equal(t, Reply{ID: "2", Error: ErrCommandExpired.Error()}, reply)
+
+	reply = agent.handle(pack(t, Command{ID: "3", Action: ActionRestartService}))
+	assert.True(t, reply.OK)
+	reply = agent.handle(pack(t, Command{ID: "3", Action: ActionRestartService}))
+	assert.Equal(t, Reply{ID: "3", Error: ErrCommandReplayed.Error()}, reply)
+
+	reply = agent.handle(pack(t, Command{ID: "4", ProviderID: "0x3", Action: ActionRestartService}))
+	assert.Equal(t, Reply{ID: "4", Error: ErrWrongProvider.Error()}, reply)
+
+	assert.Equal(t, []string{""}, executor.restarted)
+
+	entries := readAudit(t, auditPath)
+	assert.Len(t, entries, 5)
+	for i, accepted := range []bool{false, false, true, false, false} {
+		assert.Equal(t, accepted, entries[i].Accepted)
+	}
+}
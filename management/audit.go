@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// AuditEntry describes a single management command received by the node.
+type AuditEntry struct {
+	Time      time.Time `json:"time"`
+	Operator  string    `json:"operator"`
+	CommandID string    `json:"command_id"`
+	Action    string    `json:"action"`
+	Accepted  bool      `json:"accepted"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// AuditLog records every management command, including rejected ones.
+type AuditLog struct {
+	path string
+	lock sync.Mutex
+}
+
+// NewAuditLog returns audit log which appends entries to the given file as JSON lines.
+// Entries are only logged when path is empty.
+func NewAuditLog(path string) *AuditLog {
+	return &AuditLog{path: path}
+}
+
+// Record stores the audit entry.
+func (a *AuditLog) Record(entry AuditEntry) {
+	log.Info().
+		Str("operator", entry.Operator).
+		Str("command", entry.CommandID).
+		Str("action", entry.Action).
+		Bool("accepted", entry.Accepted).
+		Str("error", entry.Error).
+		Msg("Management command received")
+
+	if a.path == "" {
+		return
+	}
+	if err := a.append(entry); err != nil {
+		log.Err(err).Msg("Could not write management audit log")
+	}
+}
+
+func (a *AuditLog) append(entry AuditEntry) error {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	f, err := os.OpenFile(a.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// SendCommand signs the command with operator's signer and sends it to the provider, waiting for the reply.
+func SendCommand(brokerConn nats.Connection, signer identity.Signer, providerID identity.Identity, cmd Command, timeout time.Duration) (*Reply, error) {
+	cmd.ProviderID = providerID.Address
+	data, err := packCommand(signer, cmd)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := brokerConn.Request(subject(providerID), data, timeout)
+	if err != nil {
+		return nil, fmt.Errorf("could not send management command: %w", err)
+	}
+	var reply Reply
+	if err := json.Unmarshal(msg.Data, &reply); err != nil {
+		return nil, err
+	}
+	return &reply, nil
+}
+
+// packCommand marshals and signs the command, stamping it with current time if it has none.
+func packCommand(signer identity.Signer, cmd Command) ([]byte, error) {
+	if cmd.Timestamp == 0 {
+		cmd.Timestamp = time.Now().Unix()
+	}
+	cmdBytes, err := json.Marshal(cmd)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := signer.Sign(cmdBytes)
+	if err != nil {
+		return nil, fmt.Errorf("could not sign management command: %w", err)
+	}
+	return json.Marshal(signedCommand{Command: cmdBytes, Signature: signature.Base64()})
+}
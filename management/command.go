@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+const (
+	// ActionRestartService restarts running services of the given type, or all of them when type is empty.
+	ActionRestartService = "restart-service"
+	// ActionUpdateConfig sets the given configuration values as user configuration.
+	ActionUpdateConfig = "update-config"
+	// ActionDiagnostics collects node diagnostics.
+	ActionDiagnostics = "diagnostics"
+)
+
+// Command is a management command issued by the operator.
+type Command struct {
+	ID string `json:"id"`
+	// ProviderID is the address of provider command is issued to, so that it can not be replayed to other providers.
+	ProviderID  string                 `json:"provider_id"`
+	Action      string                 `json:"action"`
+	ServiceType string                 `json:"service_type,omitempty"`
+	Config      map[string]interface{} `json:"config,omitempty"`
+	// Timestamp is unix time of the moment command was issued, used to reject replayed commands.
+	Timestamp int64 `json:"timestamp"`
+}
+
+// Reply is sent back to the operator once command is handled.
+type Reply struct {
+	ID     string      `json:"id"`
+	OK     bool        `json:"ok"`
+	Error  string      `json:"error,omitempty"`
+	Result interface{} `json:"result,omitempty"`
+}
+
+// signedCommand carries command bytes together with operator's signature of them.
+type signedCommand struct {
+	Command   json.RawMessage `json:"command"`
+	Signature string          `json:"signature"`
+}
+
+func subject(providerID identity.Identity) string {
+	return fmt.Sprintf("%s.management", providerID.Address)
+}
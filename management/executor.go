@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"runtime"
+	"time"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/metadata"
+	"github.com/pkg/errors"
+)
+
+// ErrServiceNotRunning indicates that there is no running service to restart.
+var ErrServiceNotRunning = errors.New("service is not running")
+
+type serviceManager interface {
+	Start(providerID identity.Identity, serviceType string, policyIDs []string, options service.Options, pm market.PaymentMethod, unlisted bool, consumerPolicy service.ConsumerPolicy) (service.ID, error)
+	Stop(id service.ID) error
+	List() map[service.ID]*service.Instance
+}
+
+// Diagnostics describes node state reported to the operator.
+type Diagnostics struct {
+	Version    string               `json:"version"`
+	OS         string               `json:"os"`
+	Uptime     string               `json:"uptime"`
+	Goroutines int                  `json:"goroutines"`
+	Services   []ServiceDiagnostics `json:"services"`
+}
+
+// ServiceDiagnostics describes running service instance.
+type ServiceDiagnostics struct {
+	ID       string `json:"id"`
+	Type     string `json:"type"`
+	State    string `json:"state"`
+	Restarts int    `json:"restarts"`
+}
+
+// NodeExecutor executes management commands against node services and configuration.
+type NodeExecutor struct {
+	services serviceManager
+	cfg      *config.Config
+	started  time.Time
+}
+
+// NewExecutor creates management command executor.
+func NewExecutor(services serviceManager, cfg *config.Config) *NodeExecutor {
+	return &NodeExecutor{
+		services: services,
+		cfg:      cfg,
+		started:  time.Now(),
+	}
+}
+
+// RestartService restarts running services of the given type with the same options, or all running services when type is empty.
+func (e *NodeExecutor) RestartService(serviceType string) error {
+	restarted := 0
+	for id, instance := range e.services.List() {
+		if serviceType != "" && instance.Type != serviceType {
+			continue
+		}
+
+		var policyIDs []string
+		if instance.Proposal.AccessPolicies != nil {
+			for _, p := range *instance.Proposal.AccessPolicies {
+				policyIDs = append(policyIDs, p.ID)
+			}
+		}
+
+		if err := e.services.Stop(id); err != nil {
+			return errors.Wrapf(err, "could not stop service %s", id)
+		}
+		_, err := e.services.Start(instance.ProviderID, instance.Type, policyIDs, instance.Options, instance.Proposal.PaymentMethod, instance.Unlisted, instance.ConsumerPolicy)
+		if err != nil {
+			return errors.Wrapf(err, "could not start service %s", instance.Type)
+		}
+		restarted++
+	}
+	if restarted == 0 {
+		return ErrServiceNotRunning
+	}
+	return nil
+}
+
+// UpdateConfig sets given values as user configuration.
+// Values take effect for components reading configuration after the update, i.e. restarted services.
+func (e *NodeExecutor) UpdateConfig(values map[string]interface{}) error {
+	for key, value := range values {
+		e.cfg.SetUser(key, value)
+	}
+	return nil
+}
+
+// Diagnostics collects node diagnostics.
+func (e *NodeExecutor) Diagnostics() (interface{}, error) {
+	diagnostics := Diagnostics{
+		Version:    metadata.VersionAsString(),
+		OS:         runtime.GOOS + "/" + runtime.GOARCH,
+		Uptime:     time.Since(e.started).Round(time.Second).String(),
+		Goroutines: runtime.NumGoroutine(),
+		Services:   []ServiceDiagnostics{},
+	}
+	for id, instance := range e.services.List() {
+		diagnostics.Services = append(diagnostics.Services, ServiceDiagnostics{
+			ID:       string(id),
+			Type:     instance.Type,
+			State:    string(instance.State()),
+			Restarts: instance.Restarts(),
+		})
+	}
+	return diagnostics, nil
+}
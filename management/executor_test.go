@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package management
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type startCall struct {
+	providerID  identity.Identity
+	serviceType string
+	policyIDs   []string
+	unlisted    bool
+}
+
+type mockServiceManager struct {
+	instances map[service.ID]*service.Instance
+	stopped   []service.ID
+	started   []startCall
+}
+
+func (m *mockServiceManager) Start(providerID identity.Identity, serviceType string, policyIDs []string, _ service.Options, _ market.PaymentMethod, unlisted bool, _ service.ConsumerPolicy) (service.ID, error) {
+	m.started = append(m.started, startCall{providerID, serviceType, policyIDs, unlisted})
+	return service.ID("new"), nil
+}
+
+func (m *mockServiceManager) Stop(id service.ID) error {
+	m.stopped = append(m.stopped, id)
+	return nil
+}
+
+func (m *mockServiceManager) List() map[service.ID]*service.Instance {
+	return m.instances
+}
+
+func TestNodeExecutor_RestartService(t *testing.T) {
+	manager := &mockServiceManager{instances: map[service.ID]*service.Instance{
+		"1": {
+			ProviderID: providerID,
+			Type:       "wireguard",
+			Unlisted:   true,
+			Proposal:   market.ServiceProposal{AccessPolicies: &[]market.AccessPolicy{{ID: "allow"}}},
+		},
+		"2": {ProviderID: providerID, Type: "openvpn"},
+	}}
+	executor := NewExecutor(manager, config.NewConfig())
+
+	assert.NoError(t, executor.RestartService("wireguard"))
+	assert.Equal(t, []service.ID{"1"}, manager.stopped)
+	assert.Equal(t, []startCall{{providerID, "wireguard", []string{"allow"}, true}}, manager.started)
+
+	assert.Equal(t, ErrServiceNotRunning, executor.RestartService("noop"))
+}
+
+func TestNodeExecutor_UpdateConfig(t *testing.T) {
+	cfg := config.NewConfig()
+	executor := NewExecutor(&mockServiceManager{}, cfg)
+
+	assert.NoError(t, executor.UpdateConfig(map[string]interface{}{"payment.price-gb": 0.1}))
+	assert.Equal(t, 0.1, cfg.GetFloat64("payment.price-gb"))
+}
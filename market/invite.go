@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// InviteCodePrefix marks invite codes of unlisted service proposals.
+const InviteCodePrefix = "myst-invite:"
+
+// maxInviteSize limits the size of decompressed invite, proposals are well below it.
+const maxInviteSize = 64 * 1024
+
+// ErrInvalidInviteCode is returned when the invite code can't be decoded into a proposal.
+var ErrInvalidInviteCode = errors.New("invalid invite code")
+
+// NewInviteCode encodes the proposal with provider identity and contacts into a compact code,
+// which can be shared directly with consumers, e.g. as a QR code.
+func NewInviteCode(proposal ServiceProposal) (string, error) {
+	data, err := json.Marshal(proposal)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal proposal")
+	}
+
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", err
+	}
+	if _, err := w.Write(data); err != nil {
+		return "", err
+	}
+	if err := w.Close(); err != nil {
+		return "", err
+	}
+	return InviteCodePrefix + base64.RawURLEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// ParseInviteCode decodes the proposal shared by the provider.
+func ParseInviteCode(code string) (ServiceProposal, error) {
+	code = strings.TrimSpace(code)
+	if !strings.HasPrefix(code, InviteCodePrefix) {
+		return ServiceProposal{}, errors.Wrap(ErrInvalidInviteCode, "unknown format")
+	}
+
+	compressed, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(code, InviteCodePrefix))
+	if err != nil {
+		return ServiceProposal{}, errors.Wrap(ErrInvalidInviteCode, err.Error())
+	}
+	data, err := ioutil.ReadAll(io.LimitReader(flate.NewReader(bytes.NewReader(compressed)), maxInviteSize))
+	if err != nil {
+		return ServiceProposal{}, errors.Wrap(ErrInvalidInviteCode, err.Error())
+	}
+
+	var proposal ServiceProposal
+	if err := json.Unmarshal(data, &proposal); err != nil {
+		return ServiceProposal{}, errors.Wrap(ErrInvalidInviteCode, err.Error())
+	}
+	if proposal.ProviderID == "" || proposal.ServiceType == "" {
+		return ServiceProposal{}, errors.Wrap(ErrInvalidInviteCode, "provider is missing")
+	}
+	if !proposal.IsSupported() {
+		return ServiceProposal{}, errors.Wrap(ErrInvalidInviteCode, "proposal is not supported")
+	}
+	return proposal, nil
+}
@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_InviteCode_RoundTrip(t *testing.T) {
+	proposal := ServiceProposal{
+		ID:                1,
+		Format:            "format/X",
+		ServiceType:       "mock_service",
+		ServiceDefinition: serviceDefinition,
+		PaymentMethodType: "mock_payment",
+		PaymentMethod:     paymentMethod,
+		ProviderID:        "0x1",
+		ProviderContacts: ContactList{
+			Contact{Type: "mock_contact", Definition: mockContact{}},
+		},
+	}
+
+	code, err := NewInviteCode(proposal)
+	assert.NoError(t, err)
+	assert.Contains(t, code, InviteCodePrefix)
+
+	actual, err := ParseInviteCode(" " + code + "\n")
+	assert.NoError(t, err)
+	assert.Equal(t, proposal, actual)
+}
+
+func Test_ParseInviteCode_Invalid(t *testing.T) {
+	noProvider, err := NewInviteCode(ServiceProposal{ServiceType: "mock_service", ServiceDefinition: serviceDefinition})
+	assert.NoError(t, err)
+	unsupported, err := NewInviteCode(ServiceProposal{ProviderID: "0x1", ServiceType: "unknown"})
+	assert.NoError(t, err)
+
+	for name, code := range map[string]string{
+		"empty":       "",
+		"no prefix":   "abc",
+		"not base64":  InviteCodePrefix + "!!!",
+		"not deflate": InviteCodePrefix + "YWJj",
+		"no provider": noProvider,
+		"unsupported": unsupported,
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := ParseInviteCode(code)
+			assert.Error(t, err)
+			assert.Equal(t, ErrInvalidInviteCode, errors.Cause(err))
+		})
+	}
+}
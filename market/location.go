@@ -26,4 +26,5 @@ type Location struct {
 	ASN      int    `json:"asn,omitempty"`
 	ISP      string `json:"isp,omitempty"`
 	NodeType string `json:"node_type,omitempty"`
+	NATType  string `json:"nat_type,omitempty"`
 }
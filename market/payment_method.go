@@ -39,6 +39,12 @@ type PaymentRate struct {
 	PerByte uint64
 }
 
+// FreeTrialPaymentMethod is a payment method offering consumers a free trial allowance every day
+type FreeTrialPaymentMethod interface {
+	PaymentMethod
+	GetFreeTrial() (duration time.Duration, bytes uint64)
+}
+
 // UnsupportedPaymentMethod represents payment method which is unknown to node (i.e. not registered)
 type UnsupportedPaymentMethod struct {
 }
@@ -0,0 +1,121 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// MaxNicknameLength is maximal number of characters in provider nickname.
+	MaxNicknameLength = 32
+	// MaxContactURLLength is maximal length of provider contact URL.
+	MaxContactURLLength = 256
+	// MaxTags is maximal number of tags provider can declare.
+	MaxTags = 8
+	// MaxTagLength is maximal length of a single tag.
+	MaxTagLength = 32
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ProviderDescription is public self-description provider attaches to its proposals.
+type ProviderDescription struct {
+	// Nickname is a human readable name of the provider.
+	Nickname string `json:"nickname,omitempty"`
+	// ContactURL is a http(s) or mailto URL the provider can be reached at.
+	ContactURL string `json:"contact_url,omitempty"`
+	// Tags are capabilities declared by provider, e.g. "streaming-friendly".
+	Tags []string `json:"tags,omitempty"`
+}
+
+// IsEmpty checks if provider has described nothing.
+func (d ProviderDescription) IsEmpty() bool {
+	return d.Nickname == "" && d.ContactURL == "" && len(d.Tags) == 0
+}
+
+// HasTag checks if provider has declared the given tag.
+func (d ProviderDescription) HasTag(tag string) bool {
+	for _, t := range d.Tags {
+		if t == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate checks that description is within size limits and its fields are well formed.
+func (d ProviderDescription) Validate() error {
+	if utf8.RuneCountInString(d.Nickname) > MaxNicknameLength {
+		return fmt.Errorf("nickname is longer than %d characters", MaxNicknameLength)
+	}
+	for _, r := range d.Nickname {
+		if !unicode.IsPrint(r) {
+			return fmt.Errorf("nickname contains non printable character %q", r)
+		}
+	}
+
+	if len(d.ContactURL) > MaxContactURLLength {
+		return fmt.Errorf("contact URL is longer than %d characters", MaxContactURLLength)
+	}
+	if d.ContactURL != "" {
+		u, err := url.Parse(d.ContactURL)
+		if err != nil {
+			return fmt.Errorf("invalid contact URL: %w", err)
+		}
+		switch {
+		case u.Scheme == "mailto" && u.Opaque != "":
+		case (u.Scheme == "http" || u.Scheme == "https") && u.Host != "":
+		default:
+			return fmt.Errorf("invalid contact URL %q, http(s) or mailto URL expected", d.ContactURL)
+		}
+	}
+
+	if len(d.Tags) > MaxTags {
+		return fmt.Errorf("more than %d tags", MaxTags)
+	}
+	for i, tag := range d.Tags {
+		if len(tag) > MaxTagLength || !tagPattern.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q, up to %d lowercase letters, digits and dashes expected", tag, MaxTagLength)
+		}
+		for _, t := range d.Tags[:i] {
+			if t == tag {
+				return fmt.Errorf("duplicate tag %q", tag)
+			}
+		}
+	}
+	return nil
+}
+
+// DescriptionOf returns provider self-description carried by the service definition. Description exceeding
+// the limits is ignored as a whole, so consumers never show or filter by unchecked data announced by providers.
+func DescriptionOf(service ServiceDefinition) ProviderDescription {
+	definition, ok := service.(DescribedServiceDefinition)
+	if !ok {
+		return ProviderDescription{}
+	}
+	description := definition.GetDescription()
+	if err := description.Validate(); err != nil {
+		return ProviderDescription{}
+	}
+	return description
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package market
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type describedService struct {
+	Description ProviderDescription
+}
+
+func (service describedService) GetLocation() Location {
+	return Location{}
+}
+
+func (service describedService) GetDescription() ProviderDescription {
+	return service.Description
+}
+
+func (service describedService) WithDescription(description ProviderDescription) ServiceDefinition {
+	service.Description = description
+	return service
+}
+
+func TestProviderDescription_Validate(t *testing.T) {
+	var tests = []struct {
+		description ProviderDescription
+		valid       bool
+	}{
+		{ProviderDescription{}, true},
+		{ProviderDescription{Nickname: "Šiaurės elnias", ContactURL: "https://example.com/contact", Tags: []string{"streaming-friendly", "p2p"}}, true},
+		{ProviderDescription{ContactURL: "mailto:provider@example.com"}, true},
+		{ProviderDescription{Nickname: strings.Repeat("a", MaxNicknameLength+1)}, false},
+		{ProviderDescription{Nickname: "new\nline"}, false},
+		{ProviderDescription{ContactURL: "javascript:alert(1)"}, false},
+		{ProviderDescription{ContactURL: "https://"}, false},
+		{ProviderDescription{ContactURL: "https://example.com/" + strings.Repeat("a", MaxContactURLLength)}, false},
+		{ProviderDescription{Tags: []string{"Streaming"}}, false},
+		{ProviderDescription{Tags: []string{"streaming--friendly"}}, false},
+		{ProviderDescription{Tags: []string{strings.Repeat("a", MaxTagLength+1)}}, false},
+		{ProviderDescription{Tags: []string{"p2p", "p2p"}}, false},
+		{ProviderDescription{Tags: []string{"a", "b", "c", "d", "e", "f", "g", "h", "i"}}, false},
+	}
+
+	for _, test := range tests {
+		err := test.description.Validate()
+		if test.valid {
+			assert.NoError(t, err, "%+v", test.description)
+		} else {
+			assert.Error(t, err, "%+v", test.description)
+		}
+	}
+}
+
+func TestDescriptionOf(t *testing.T) {
+	description := ProviderDescription{Nickname: "Provider", Tags: []string{"streaming-friendly"}}
+
+	assert.Equal(t, description, DescriptionOf(describedService{Description: description}))
+	assert.Equal(t, ProviderDescription{}, DescriptionOf(UnsupportedServiceDefinition{}))
+	assert.Equal(t, ProviderDescription{}, DescriptionOf(nil))
+	assert.Equal(t, ProviderDescription{}, DescriptionOf(describedService{
+		Description: ProviderDescription{Nickname: "Provider", Tags: []string{"<script>"}},
+	}))
+}
@@ -41,6 +41,22 @@ func (UnsupportedServiceDefinition) GetLocation() Location {
 
 var _ ServiceDefinition = UnsupportedServiceDefinition{}
 
+// RelocatableServiceDefinition is implemented by service definitions which can be announced from a changed location.
+type RelocatableServiceDefinition interface {
+	ServiceDefinition
+	// WithLocation returns a copy of the service definition provided from the given location.
+	WithLocation(location Location) ServiceDefinition
+}
+
+// DescribedServiceDefinition is implemented by service definitions which carry provider self-description.
+type DescribedServiceDefinition interface {
+	ServiceDefinition
+	// GetDescription returns provider self-description, empty if provider has not described itself.
+	GetDescription() ProviderDescription
+	// WithDescription returns a copy of the service definition carrying the given description.
+	WithDescription(description ProviderDescription) ServiceDefinition
+}
+
 // ServiceDefinitionUnserializer defines function to register for concrete service definition
 type ServiceDefinitionUnserializer func(*json.RawMessage) (ServiceDefinition, error)
 
@@ -31,7 +31,6 @@ const (
 // service proposal can be marked as unsupported by deserializer, because of unknown service, payment method, or contact type
 type ServiceProposal struct {
 	// Per provider unique serial number of service description provided
-	// TODO Not supported yet
 	ID int `json:"id"`
 
 	// A version number is included in the proposal to allow extensions to the proposal format
@@ -57,6 +56,12 @@ type ServiceProposal struct {
 
 	// AccessPolicies represents the access controls for proposal
 	AccessPolicies *[]AccessPolicy `json:"access_policies,omitempty"`
+
+	// QoSClasses lists QoS classes of sessions consumer can request, the first one is the default
+	QoSClasses []string `json:"qos_classes,omitempty"`
+
+	// ContentFilter lists content categories blocked in DNS queries of consumers, empty if exit is unfiltered
+	ContentFilter []string `json:"content_filter,omitempty"`
 }
 
 // UniqueID returns unique proposal composite ID
@@ -64,6 +69,7 @@ func (proposal *ServiceProposal) UniqueID() ProposalID {
 	return ProposalID{
 		ProviderID:  proposal.ProviderID,
 		ServiceType: proposal.ServiceType,
+		ID:          proposal.ID,
 	}
 }
 
@@ -79,6 +85,8 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 		PaymentMethod     *json.RawMessage `json:"payment_method"`
 		ProviderContacts  *json.RawMessage `json:"provider_contacts"`
 		AccessPolicies    *[]AccessPolicy  `json:"access_policies,omitempty"`
+		QoSClasses        []string         `json:"qos_classes,omitempty"`
+		ContentFilter     []string         `json:"content_filter,omitempty"`
 	}
 	if err := json.Unmarshal(data, &jsonData); err != nil {
 		return err
@@ -106,6 +114,8 @@ func (proposal *ServiceProposal) UnmarshalJSON(data []byte) error {
 	proposal.ProviderContacts = unserializeContacts(jsonData.ProviderContacts)
 
 	proposal.AccessPolicies = jsonData.AccessPolicies
+	proposal.QoSClasses = jsonData.QoSClasses
+	proposal.ContentFilter = jsonData.ContentFilter
 	return nil
 }
 
@@ -17,6 +17,8 @@
 
 package market
 
+import "fmt"
+
 // ProposalID defines composite ID to identify unique proposal discovery of Mysterium Network
 type ProposalID struct {
 	// Type of service type offered
@@ -26,6 +28,14 @@ type ProposalID struct {
 	ProviderID string
 
 	// Per provider unique serial number of service description provided
-	// TODO Not supported yet
 	ID int
 }
+
+// ServiceKey returns the key identifying proposed service among the services of the provider.
+// The first service of each type keeps the plain service type key, so that it stays reachable by older consumers.
+func (id ProposalID) ServiceKey() string {
+	if id.ID == 0 {
+		return id.ServiceType
+	}
+	return fmt.Sprintf("%s-%d", id.ServiceType, id.ID)
+}
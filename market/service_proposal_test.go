@@ -257,3 +257,34 @@ func Test_ServiceProposal_UnserializeAccessPolicy(t *testing.T) {
 	assert.Equal(t, expected, actual)
 	assert.True(t, actual.IsSupported())
 }
+
+func Test_ServiceProposal_UnserializeContentFilter(t *testing.T) {
+	jsonData := []byte(`{
+		"id": 1,
+		"service_type": "mock_service",
+		"provider_id": "node",
+		"content_filter": ["adult", "malware"]
+	}`)
+
+	var actual ServiceProposal
+	err := json.Unmarshal(jsonData, &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"adult", "malware"}, actual.ContentFilter)
+
+	serialized, err := json.Marshal(actual)
+	assert.NoError(t, err)
+	assert.Contains(t, string(serialized), `"content_filter":["adult","malware"]`)
+}
+
+func TestServiceProposal_UniqueID(t *testing.T) {
+	proposal := ServiceProposal{ID: 2, ServiceType: "wireguard", ProviderID: "0x1"}
+
+	id := proposal.UniqueID()
+	assert.Equal(t, ProposalID{ServiceType: "wireguard", ProviderID: "0x1", ID: 2}, id)
+	assert.Equal(t, "wireguard-2", id.ServiceKey())
+}
+
+func TestProposalID_ServiceKey_KeepsServiceTypeForFirstProposal(t *testing.T) {
+	id := ProposalID{ServiceType: "wireguard", ProviderID: "0x1"}
+	assert.Equal(t, "wireguard", id.ServiceKey())
+}
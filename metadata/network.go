@@ -17,8 +17,16 @@
 
 package metadata
 
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
 // NetworkDefinition structure holds all parameters which describe particular network
 type NetworkDefinition struct {
+	// ChainID identifies the blockchain network payments are made on
+	ChainID                   int64
 	MysteriumAPIAddress       string
 	AccessPolicyOracleAddress string
 	BrokerAddress             string
@@ -28,11 +36,14 @@ type NetworkDefinition struct {
 	RegistryAddress           string
 	AccountantID              string
 	ChannelImplAddress        string
+	MystSCAddress             string
 	MMNAddress                string
+	FaucetAddress             string
 }
 
 // TestnetDefinition defines parameters for test network (currently default network)
 var TestnetDefinition = NetworkDefinition{
+	ChainID:                   5,
 	MysteriumAPIAddress:       "https://testnet-api.mysterium.network/v1",
 	AccessPolicyOracleAddress: "https://testnet-trust.mysterium.network/api/v1/access-policies/",
 	BrokerAddress:             "nats://testnet-broker.mysterium.network",
@@ -42,12 +53,15 @@ var TestnetDefinition = NetworkDefinition{
 	ChannelImplAddress:        "0x3026eB9622e2C5bdC157C6b117F7f4aC2C2Db3b5",
 	AccountantID:              "0x0214281cf15C1a66b51990e2E65e1f7b7C363318",
 	AccountantAddress:         "https://testnet-accountant.mysterium.network/api/v2",
+	MystSCAddress:             "0x7753cfAD258eFbC52A9A1452e42fFbce9bE486cb",
 	MMNAddress:                "https://my.mysterium.network/api/v1",
+	FaucetAddress:             "https://testnet-faucet.mysterium.network/api/v1",
 }
 
 // LocalnetDefinition defines parameters for local network
 // Expects discovery, broker and morqa services on localhost
 var LocalnetDefinition = NetworkDefinition{
+	ChainID:                   1337,
 	MysteriumAPIAddress:       "http://localhost:8001/v1",
 	AccessPolicyOracleAddress: "https://localhost:8081/api/v1/access-policies/",
 	BrokerAddress:             "localhost",
@@ -57,3 +71,45 @@ var LocalnetDefinition = NetworkDefinition{
 
 // DefaultNetwork defines default network values when no runtime parameters are given
 var DefaultNetwork = TestnetDefinition
+
+var (
+	networksLock sync.Mutex
+	networks     = map[int64]NetworkDefinition{
+		TestnetDefinition.ChainID:  TestnetDefinition,
+		LocalnetDefinition.ChainID: LocalnetDefinition,
+	}
+)
+
+// RegisterNetwork adds network definition to the registry, replacing the previous definition of the same chain.
+func RegisterNetwork(definition NetworkDefinition) {
+	networksLock.Lock()
+	defer networksLock.Unlock()
+	networks[definition.ChainID] = definition
+}
+
+// NetworkByChainID returns network definition of the given chain.
+func NetworkByChainID(chainID int64) (NetworkDefinition, error) {
+	networksLock.Lock()
+	defer networksLock.Unlock()
+
+	definition, ok := networks[chainID]
+	if !ok {
+		return NetworkDefinition{}, fmt.Errorf("unknown network with chain ID %d", chainID)
+	}
+	return definition, nil
+}
+
+// Networks returns all known network definitions ordered by chain ID.
+func Networks() []NetworkDefinition {
+	networksLock.Lock()
+	defer networksLock.Unlock()
+
+	result := make([]NetworkDefinition, 0, len(networks))
+	for _, definition := range networks {
+		result = append(result, definition)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ChainID < result[j].ChainID
+	})
+	return result
+}
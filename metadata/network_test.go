@@ -0,0 +1,51 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package metadata
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNetworkByChainID(t *testing.T) {
+	network, err := NetworkByChainID(TestnetDefinition.ChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, TestnetDefinition, network)
+
+	_, err = NetworkByChainID(123456)
+	assert.EqualError(t, err, "unknown network with chain ID 123456")
+}
+
+func TestRegisterNetwork(t *testing.T) {
+	sidechain := NetworkDefinition{
+		ChainID:       80001,
+		MystSCAddress: "0x0000000000000000000000000000000000000001",
+	}
+	RegisterNetwork(sidechain)
+
+	network, err := NetworkByChainID(sidechain.ChainID)
+	assert.NoError(t, err)
+	assert.Equal(t, sidechain, network)
+
+	var chainIDs []int64
+	for _, n := range Networks() {
+		chainIDs = append(chainIDs, n.ChainID)
+	}
+	assert.Equal(t, []int64{TestnetDefinition.ChainID, LocalnetDefinition.ChainID, sidechain.ChainID}, chainIDs)
+}
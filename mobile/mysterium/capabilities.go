@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"github.com/mysteriumnetwork/node/services/wireguard"
+)
+
+// openvpnServiceType is spelled out, importing the openvpn package would pull it into the slim build.
+const openvpnServiceType = "openvpn"
+
+// Capabilities describes which features are compiled into the mobile library.
+// Library built with the "mobile_slim" tag leaves out provider services, OpenVPN and Tequilapi.
+type Capabilities struct {
+	WireGuard bool
+	OpenVPN   bool
+}
+
+// GetCapabilities returns service types the library is able to connect to.
+func (mb *MobileNode) GetCapabilities() *Capabilities {
+	return &Capabilities{
+		WireGuard: isServiceTypeSupported(wireguard.ServiceType),
+		OpenVPN:   isServiceTypeSupported(openvpnServiceType),
+	}
+}
+
+// IsServiceTypeSupported checks whether the library is able to connect to the service of the given type.
+func (mb *MobileNode) IsServiceTypeSupported(serviceType string) bool {
+	return isServiceTypeSupported(serviceType)
+}
+
+func isServiceTypeSupported(serviceType string) bool {
+	for _, supported := range supportedServiceTypes {
+		if supported == serviceType {
+			return true
+		}
+	}
+	return false
+}
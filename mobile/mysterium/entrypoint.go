@@ -19,7 +19,9 @@ package mysterium
 
 import (
 	"context"
+	stdErr "errors"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -72,16 +74,24 @@ type MobileNode struct {
 	registryAddress              string
 	channelImplementationAddress string
 	startTime                    time.Time
+	statisticsCallback           func(e connection.AppEventConnectionStatistics)
+	statisticsCallbackLock       sync.Mutex
+	stateSync                    *stateSyncer
+	stateSyncLock                sync.Mutex
+	background                   bool
+	tunnelPreferences            tunnelPreferencesStore
 }
 
 // MobileNodeOptions contains common mobile node options.
 type MobileNodeOptions struct {
 	Testnet                         bool
 	Localnet                        bool
+	ChainID                         int64
 	ExperimentNATPunching           bool
 	MysteriumAPIAddress             string
 	BrokerAddress                   string
 	EtherClientRPC                  string
+	EtherClientLightMode            bool
 	FeedbackURL                     string
 	QualityOracleURL                string
 	IPDetectorURL                   string
@@ -98,6 +108,7 @@ type MobileNodeOptions struct {
 func DefaultNodeOptions() *MobileNodeOptions {
 	return &MobileNodeOptions{
 		Testnet:                         true,
+		ChainID:                         metadata.TestnetDefinition.ChainID,
 		ExperimentNATPunching:           true,
 		MysteriumAPIAddress:             metadata.TestnetDefinition.MysteriumAPIAddress,
 		BrokerAddress:                   metadata.TestnetDefinition.BrokerAddress,
@@ -111,7 +122,7 @@ func DefaultNodeOptions() *MobileNodeOptions {
 		TransactorChannelImplementation: metadata.TestnetDefinition.ChannelImplAddress,
 		AccountantEndpointAddress:       metadata.TestnetDefinition.AccountantAddress,
 		AccountantID:                    metadata.TestnetDefinition.AccountantID,
-		MystSCAddress:                   "0x7753cfAD258eFbC52A9A1452e42fFbce9bE486cb",
+		MystSCAddress:                   metadata.TestnetDefinition.MystSCAddress,
 	}
 }
 
@@ -126,12 +137,16 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 	currentDir := appPath
 
 	network := node.OptionsNetwork{
-		Testnet:               options.Testnet,
-		Localnet:              options.Localnet,
-		ExperimentNATPunching: options.ExperimentNATPunching,
-		MysteriumAPIAddress:   options.MysteriumAPIAddress,
-		BrokerAddress:         options.BrokerAddress,
-		EtherClientRPC:        options.EtherClientRPC,
+		Testnet:                  options.Testnet,
+		Localnet:                 options.Localnet,
+		ChainID:                  options.ChainID,
+		ExperimentNATPunching:    options.ExperimentNATPunching,
+		MysteriumAPIAddress:      options.MysteriumAPIAddress,
+		BrokerAddress:            options.BrokerAddress,
+		EtherClientRPC:           options.EtherClientRPC,
+		EtherClientCheckInterval: 30 * time.Second,
+		EtherClientCacheTTL:      15 * time.Second,
+		EtherClientLightMode:     options.EtherClientLightMode,
 	}
 	logOptions := logconfig.LogOptions{
 		LogLevel: zerolog.DebugLevel,
@@ -143,7 +158,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		LogOptions: logOptions,
 		Directories: node.OptionsDirectory{
 			Data:     dataDir,
-			Storage:  filepath.Join(dataDir, "db"),
+			Storage:  node.StorageDir(dataDir, options.ChainID),
 			Keystore: filepath.Join(dataDir, "keystore"),
 			Runtime:  currentDir,
 		},
@@ -184,6 +199,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		Accountant: node.OptionsAccountant{
 			AccountantEndpointAddress: options.AccountantEndpointAddress,
 			AccountantID:              options.AccountantID,
+			HealthCheckInterval:       time.Minute,
 		},
 		Payments: node.OptionsPayments{
 			MaxAllowedPaymentPercentile:        1500,
@@ -223,7 +239,7 @@ func NewNode(appPath string, options *MobileNodeOptions) (*MobileNode, error) {
 		proposalsManager: newProposalsManager(
 			di.ProposalRepository,
 			di.MysteriumAPI,
-			di.QualityClient,
+			di.QualityScores,
 		),
 		startTime: time.Now(),
 	}
@@ -283,12 +299,30 @@ type StatisticsChangeCallback interface {
 }
 
 // RegisterStatisticsChangeCallback registers callback which is called on active connection
-// statistics change.
+// statistics change. Statistics are sampled at the full rate only while the callback is registered,
+// so it should be unregistered when statistics are not displayed to save battery.
 func (mb *MobileNode) RegisterStatisticsChangeCallback(cb StatisticsChangeCallback) {
-	_ = mb.eventBus.SubscribeAsync(connection.AppTopicConnectionStatistics, func(e connection.AppEventConnectionStatistics) {
+	mb.UnregisterStatisticsChangeCallback()
+
+	mb.statisticsCallbackLock.Lock()
+	defer mb.statisticsCallbackLock.Unlock()
+	mb.statisticsCallback = func(e connection.AppEventConnectionStatistics) {
 		tokensSpent := mb.stateKeeper.GetState().Connection.Invoice.AgreementTotal
 		cb.OnChange(int64(e.SessionInfo.Duration().Seconds()), int64(e.Stats.BytesReceived), int64(e.Stats.BytesSent), int64(tokensSpent))
-	})
+	}
+	_ = mb.eventBus.SubscribeAsync(connection.AppTopicConnectionStatisticsLive, mb.statisticsCallback)
+}
+
+// UnregisterStatisticsChangeCallback unregisters previously registered statistics callback,
+// e.g. when the application goes to background.
+func (mb *MobileNode) UnregisterStatisticsChangeCallback() {
+	mb.statisticsCallbackLock.Lock()
+	defer mb.statisticsCallbackLock.Unlock()
+	if mb.statisticsCallback == nil {
+		return
+	}
+	_ = mb.eventBus.Unsubscribe(connection.AppTopicConnectionStatisticsLive, mb.statisticsCallback)
+	mb.statisticsCallback = nil
 }
 
 // ConnectionStatusChangeCallback represents status callback.
@@ -305,14 +339,15 @@ func (mb *MobileNode) RegisterConnectionStatusChangeCallback(cb ConnectionStatus
 }
 
 // BalanceChangeCallback represents balance change callback.
+// Balance is a decimal string of the smallest token units, it does not fit into int64.
 type BalanceChangeCallback interface {
-	OnChange(identityAddress string, balance int64)
+	OnChange(identityAddress string, balance string)
 }
 
 // RegisterBalanceChangeCallback registers callback which is called on identity balance change.
 func (mb *MobileNode) RegisterBalanceChangeCallback(cb BalanceChangeCallback) {
 	_ = mb.eventBus.SubscribeAsync(event.AppTopicBalanceChanged, func(e event.AppEventBalanceChanged) {
-		cb.OnChange(e.Identity.Address, int64(e.Current))
+		cb.OnChange(e.Identity.Address, e.Current.String())
 	})
 }
 
@@ -346,6 +381,7 @@ type ConnectResponse struct {
 const (
 	connectErrInvalidProposal     = "InvalidProposal"
 	connectErrInsufficientBalance = "InsufficientBalance"
+	connectErrPolicyDenied        = "PolicyDenied"
 	connectErrUnknown             = "Unknown"
 )
 
@@ -372,6 +408,12 @@ func (mb *MobileNode) Connect(req *ConnectRequest) *ConnectResponse {
 				ErrorCode: connectErrInsufficientBalance,
 			}
 		}
+		if stdErr.Is(err, connection.ErrSessionPolicyDenied) {
+			return &ConnectResponse{
+				ErrorCode:    connectErrPolicyDenied,
+				ErrorMessage: err.Error(),
+			}
+		}
 		return &ConnectResponse{
 			ErrorCode:    connectErrUnknown,
 			ErrorMessage: err.Error(),
@@ -507,13 +549,14 @@ type GetBalanceRequest struct {
 
 // GetBalanceResponse represents balance response.
 type GetBalanceResponse struct {
-	Balance int64
+	// Balance is a decimal string of the smallest token units.
+	Balance string
 }
 
 // GetBalance returns current balance.
 func (mb *MobileNode) GetBalance(req *GetBalanceRequest) (*GetBalanceResponse, error) {
 	balance := mb.consumerBalanceTracker.GetBalance(identity.FromAddress(req.IdentityAddress))
-	return &GetBalanceResponse{Balance: int64(balance)}, nil
+	return &GetBalanceResponse{Balance: balance.String()}, nil
 }
 
 // SendFeedbackRequest represents user feedback request.
@@ -541,6 +584,7 @@ func (mb *MobileNode) SendFeedback(req *SendFeedbackRequest) error {
 
 // Shutdown function stops running mobile node
 func (mb *MobileNode) Shutdown() error {
+	mb.UnregisterStateSyncCallback()
 	return mb.shutdown()
 }
 
@@ -559,7 +603,7 @@ func (mb *MobileNode) OverrideWireguardConnection(wgTunnelSetup WireguardTunnelS
 		}
 		return NewWireGuardConnection(
 			opts,
-			newWireguardDevice(wgTunnelSetup),
+			newWireguardDevice(wgTunnelSetup, mb.tunnelPreferences.get),
 			mb.ipResolver,
 			wireguard_connection.NewHandshakeWaiter(),
 		)
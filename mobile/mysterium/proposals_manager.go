@@ -25,8 +25,6 @@ import (
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/market/mysterium"
-	"github.com/mysteriumnetwork/node/services/openvpn"
-	"github.com/mysteriumnetwork/node/services/wireguard"
 )
 
 const (
@@ -159,7 +157,7 @@ func (m *proposalsManager) getFromRepository(filter *proposal.Filter) ([]market.
 	// proposals, but for now just filter in memory.
 	var res []market.ServiceProposal
 	for _, p := range allProposals {
-		if p.ServiceType == openvpn.ServiceType || p.ServiceType == wireguard.ServiceType {
+		if isServiceTypeSupported(p.ServiceType) {
 			res = append(res, p)
 		}
 	}
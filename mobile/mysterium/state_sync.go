@@ -0,0 +1,320 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+const defaultStateSyncInterval = time.Second
+
+// StateSyncCallback represents batched state callback.
+type StateSyncCallback interface {
+	// OnStateChange receives JSON encoded state batch with values changed since the previous delivery.
+	OnStateChange(batch []byte)
+}
+
+// StateSyncOptions configures the cadence of batched state delivery.
+type StateSyncOptions struct {
+	// IntervalMillis is the delivery cadence while the app is in foreground.
+	IntervalMillis int64
+	// BackgroundIntervalMillis is the delivery cadence while the app is in background,
+	// zero holds all updates until the app returns to foreground.
+	BackgroundIntervalMillis int64
+}
+
+// DefaultStateSyncOptions returns default state sync options.
+func DefaultStateSyncOptions() *StateSyncOptions {
+	return &StateSyncOptions{
+		IntervalMillis:           defaultStateSyncInterval.Milliseconds(),
+		BackgroundIntervalMillis: 0,
+	}
+}
+
+// RegisterStateSyncCallback registers callback which receives state changes batched at the configured cadence,
+// instead of a separate callback per event. Only one state sync callback can be registered at a time.
+func (mb *MobileNode) RegisterStateSyncCallback(cb StateSyncCallback, options *StateSyncOptions) {
+	mb.UnregisterStateSyncCallback()
+
+	if options == nil {
+		options = DefaultStateSyncOptions()
+	}
+
+	mb.stateSyncLock.Lock()
+	defer mb.stateSyncLock.Unlock()
+	mb.stateSync = newStateSyncer(mb.eventBus, cb, *options, mb.tokensSpent)
+	mb.stateSync.setBackground(mb.background)
+	mb.stateSync.consumeTunnelPreferences(mb.tunnelPreferences.get())
+	if err := mb.stateSync.start(); err != nil {
+		log.Error().Err(err).Msg("Failed to start state sync")
+	}
+}
+
+// UnregisterStateSyncCallback stops batched state delivery.
+func (mb *MobileNode) UnregisterStateSyncCallback() {
+	mb.stateSyncLock.Lock()
+	defer mb.stateSyncLock.Unlock()
+	if mb.stateSync == nil {
+		return
+	}
+	mb.stateSync.stop()
+	mb.stateSync = nil
+}
+
+// SetAppInBackground notifies the node about application visibility. While in background
+// state updates are coalesced and delivered at the background cadence.
+func (mb *MobileNode) SetAppInBackground(background bool) {
+	mb.stateSyncLock.Lock()
+	defer mb.stateSyncLock.Unlock()
+	mb.background = background
+	if mb.stateSync != nil {
+		mb.stateSync.setBackground(background)
+	}
+}
+
+func (mb *MobileNode) tokensSpent() uint64 {
+	return mb.stateKeeper.GetState().Connection.Invoice.AgreementTotal
+}
+
+type stateBatch struct {
+	Connection    *connectionStateDTO     `json:"connection,omitempty"`
+	Statistics    *statisticsDTO          `json:"statistics,omitempty"`
+	Balances      map[string]money.Tokens `json:"balances,omitempty"`
+	Registrations map[string]string       `json:"registrations,omitempty"`
+	Tunnel        *tunnelPreferencesDTO   `json:"tunnel,omitempty"`
+}
+
+func (b stateBatch) empty() bool {
+	return b.Connection == nil && b.Statistics == nil && len(b.Balances) == 0 && len(b.Registrations) == 0 && b.Tunnel == nil
+}
+
+type connectionStateDTO struct {
+	State       string `json:"state"`
+	ProviderID  string `json:"provider_id,omitempty"`
+	ServiceType string `json:"service_type,omitempty"`
+}
+
+type statisticsDTO struct {
+	Duration      int64 `json:"duration"`
+	BytesReceived int64 `json:"bytes_received"`
+	BytesSent     int64 `json:"bytes_sent"`
+	TokensSpent   int64 `json:"tokens_spent"`
+}
+
+// stateSyncer coalesces state events and delivers them in a single callback at a fixed cadence,
+// so the app is not woken up by every event.
+type stateSyncer struct {
+	bus         eventbus.Subscriber
+	callback    StateSyncCallback
+	options     StateSyncOptions
+	tokensSpent func() uint64
+
+	lock       sync.Mutex
+	batch      stateBatch
+	background bool
+	intervalCh chan time.Duration
+	stopCh     chan struct{}
+	stopOnce   sync.Once
+
+	onState        func(e connection.AppEventConnectionState)
+	onStatistics   func(e connection.AppEventConnectionStatistics)
+	onBalance      func(e event.AppEventBalanceChanged)
+	onRegistration func(e registry.AppEventIdentityRegistration)
+}
+
+func newStateSyncer(bus eventbus.Subscriber, callback StateSyncCallback, options StateSyncOptions, tokensSpent func() uint64) *stateSyncer {
+	s := &stateSyncer{
+		bus:         bus,
+		callback:    callback,
+		options:     options,
+		tokensSpent: tokensSpent,
+		intervalCh:  make(chan time.Duration, 1),
+		stopCh:      make(chan struct{}),
+	}
+	s.onState = s.consumeConnectionState
+	s.onStatistics = s.consumeStatistics
+	s.onBalance = s.consumeBalance
+	s.onRegistration = s.consumeRegistration
+	return s
+}
+
+func (s *stateSyncer) start() error {
+	if err := s.bus.Subscribe(connection.AppTopicConnectionState, s.onState); err != nil {
+		return err
+	}
+	if err := s.bus.Subscribe(connection.AppTopicConnectionStatistics, s.onStatistics); err != nil {
+		return err
+	}
+	if err := s.bus.Subscribe(event.AppTopicBalanceChanged, s.onBalance); err != nil {
+		return err
+	}
+	if err := s.bus.Subscribe(registry.AppTopicIdentityRegistration, s.onRegistration); err != nil {
+		return err
+	}
+
+	go s.deliverLoop(s.currentInterval())
+	return nil
+}
+
+func (s *stateSyncer) stop() {
+	s.stopOnce.Do(func() {
+		close(s.stopCh)
+		_ = s.bus.Unsubscribe(connection.AppTopicConnectionState, s.onState)
+		_ = s.bus.Unsubscribe(connection.AppTopicConnectionStatistics, s.onStatistics)
+		_ = s.bus.Unsubscribe(event.AppTopicBalanceChanged, s.onBalance)
+		_ = s.bus.Unsubscribe(registry.AppTopicIdentityRegistration, s.onRegistration)
+	})
+}
+
+func (s *stateSyncer) setBackground(background bool) {
+	s.lock.Lock()
+	changed := s.background != background
+	s.background = background
+	s.lock.Unlock()
+	if !changed {
+		return
+	}
+
+	// Drop the interval which was not picked up yet, only the latest one matters.
+	select {
+	case <-s.intervalCh:
+	default:
+	}
+	s.intervalCh <- s.currentInterval()
+}
+
+// currentInterval returns the delivery interval for the current app state, zero pauses the delivery.
+func (s *stateSyncer) currentInterval() time.Duration {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.background {
+		return time.Duration(s.options.BackgroundIntervalMillis) * time.Millisecond
+	}
+	if s.options.IntervalMillis <= 0 {
+		return defaultStateSyncInterval
+	}
+	return time.Duration(s.options.IntervalMillis) * time.Millisecond
+}
+
+func (s *stateSyncer) deliverLoop(interval time.Duration) {
+	var ticker *time.Ticker
+	var tickCh <-chan time.Time
+	reset := func(interval time.Duration) {
+		if ticker != nil {
+			ticker.Stop()
+			ticker, tickCh = nil, nil
+		}
+		if interval > 0 {
+			ticker = time.NewTicker(interval)
+			tickCh = ticker.C
+		}
+	}
+	reset(interval)
+	defer reset(0)
+
+	for {
+		select {
+		case <-s.stopCh:
+			return
+		case interval := <-s.intervalCh:
+			// Deliver everything which was held back once the app comes back to foreground.
+			s.flush()
+			reset(interval)
+		case <-tickCh:
+			s.flush()
+		}
+	}
+}
+
+func (s *stateSyncer) flush() {
+	s.lock.Lock()
+	if s.background && s.options.BackgroundIntervalMillis <= 0 {
+		s.lock.Unlock()
+		return
+	}
+	batch := s.batch
+	s.batch = stateBatch{}
+	s.lock.Unlock()
+
+	if batch.empty() {
+		return
+	}
+	data, err := json.Marshal(batch)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to marshal state batch")
+		return
+	}
+	s.callback.OnStateChange(data)
+}
+
+func (s *stateSyncer) consumeConnectionState(e connection.AppEventConnectionState) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.batch.Connection = &connectionStateDTO{
+		State:       string(e.State),
+		ProviderID:  e.SessionInfo.Proposal.ProviderID,
+		ServiceType: e.SessionInfo.Proposal.ServiceType,
+	}
+}
+
+func (s *stateSyncer) consumeStatistics(e connection.AppEventConnectionStatistics) {
+	tokensSpent := s.tokensSpent()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.batch.Statistics = &statisticsDTO{
+		Duration:      int64(e.SessionInfo.Duration().Seconds()),
+		BytesReceived: int64(e.Stats.BytesReceived),
+		BytesSent:     int64(e.Stats.BytesSent),
+		TokensSpent:   int64(tokensSpent),
+	}
+}
+
+func (s *stateSyncer) consumeBalance(e event.AppEventBalanceChanged) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.batch.Balances == nil {
+		s.batch.Balances = make(map[string]money.Tokens)
+	}
+	s.batch.Balances[e.Identity.Address] = e.Current
+}
+
+func (s *stateSyncer) consumeRegistration(e registry.AppEventIdentityRegistration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.batch.Registrations == nil {
+		s.batch.Registrations = make(map[string]string)
+	}
+	s.batch.Registrations[e.ID.Address] = e.Status.String()
+}
+
+func (s *stateSyncer) consumeTunnelPreferences(preferences TunnelPreferences) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.batch.Tunnel = preferences.toDTO()
+}
@@ -0,0 +1,116 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockStateSyncCallback struct {
+	batches chan string
+}
+
+func (cb *mockStateSyncCallback) OnStateChange(batch []byte) {
+	cb.batches <- string(batch)
+}
+
+func newTestStateSyncer(options StateSyncOptions) (*stateSyncer, eventbus.EventBus, *mockStateSyncCallback) {
+	bus := eventbus.New()
+	cb := &mockStateSyncCallback{batches: make(chan string, 10)}
+	return newStateSyncer(bus, cb, options, func() uint64 { return 7 }), bus, cb
+}
+
+func TestStateSyncer_CoalescesEventsIntoSingleBatch(t *testing.T) {
+	syncer, bus, cb := newTestStateSyncer(StateSyncOptions{IntervalMillis: 50})
+	assert.NoError(t, syncer.start())
+	defer syncer.stop()
+
+	bus.Publish(connection.AppTopicConnectionState, connection.AppEventConnectionState{State: connection.Connecting})
+	bus.Publish(connection.AppTopicConnectionState, connection.AppEventConnectionState{State: connection.Connected})
+	bus.Publish(connection.AppTopicConnectionStatistics, connection.AppEventConnectionStatistics{
+		Stats: connection.Statistics{BytesReceived: 1, BytesSent: 2},
+	})
+	bus.Publish(event.AppTopicBalanceChanged, event.AppEventBalanceChanged{Identity: identity.FromAddress("0x1"), Current: money.NewTokens(10)})
+	bus.Publish(event.AppTopicBalanceChanged, event.AppEventBalanceChanged{Identity: identity.FromAddress("0x1"), Current: money.NewTokens(9)})
+	bus.Publish(registry.AppTopicIdentityRegistration, registry.AppEventIdentityRegistration{ID: identity.FromAddress("0x1"), Status: registry.RegisteredConsumer})
+
+	select {
+	case batch := <-cb.batches:
+		assert.JSONEq(t, `{
+			"connection": {"state": "Connected"},
+			"statistics": {"duration": 0, "bytes_received": 1, "bytes_sent": 2, "tokens_spent": 7},
+			"balances": {"0x1": "9"},
+			"registrations": {"0x1": "RegisteredConsumer"}
+		}`, batch)
+	case <-time.After(time.Second):
+		t.Fatal("state batch was not delivered")
+	}
+
+	select {
+	case batch := <-cb.batches:
+		t.Fatalf("unexpected empty batch delivered: %s", batch)
+	case <-time.After(120 * time.Millisecond):
+	}
+}
+
+func TestStateSyncer_HoldsUpdatesWhileInBackground(t *testing.T) {
+	syncer, bus, cb := newTestStateSyncer(StateSyncOptions{IntervalMillis: 20})
+	assert.NoError(t, syncer.start())
+	defer syncer.stop()
+
+	syncer.setBackground(true)
+	bus.Publish(connection.AppTopicConnectionState, connection.AppEventConnectionState{State: connection.Connected})
+	bus.Publish(connection.AppTopicConnectionState, connection.AppEventConnectionState{State: connection.Disconnecting})
+
+	select {
+	case batch := <-cb.batches:
+		t.Fatalf("batch delivered while in background: %s", batch)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	syncer.setBackground(false)
+	select {
+	case batch := <-cb.batches:
+		assert.JSONEq(t, `{"connection": {"state": "Disconnecting"}}`, batch)
+	case <-time.After(time.Second):
+		t.Fatal("held back state was not delivered")
+	}
+}
+
+func TestStateSyncer_StopsDelivery(t *testing.T) {
+	syncer, bus, cb := newTestStateSyncer(StateSyncOptions{IntervalMillis: 20})
+	assert.NoError(t, syncer.start())
+	syncer.stop()
+
+	bus.Publish(connection.AppTopicConnectionState, connection.AppEventConnectionState{State: connection.Connected})
+
+	select {
+	case batch := <-cb.batches:
+		t.Fatalf("batch delivered after stop: %s", batch)
+	case <-time.After(60 * time.Millisecond):
+	}
+}
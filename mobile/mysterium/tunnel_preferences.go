@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// errMixedAppLists is returned when both included and excluded applications are set,
+// platforms allow either routing only the given applications through the tunnel or all except the given ones.
+var errMixedAppLists = errors.New("included and excluded applications can not be set at the same time")
+
+// TunnelPreferences holds preferences of VPN tunnel established by the host app.
+type TunnelPreferences struct {
+	// AlwaysOn asks the host app to keep the tunnel up and to reconnect it after restart
+	// (always-on VpnService on Android, on-demand NEVPNManager on iOS).
+	AlwaysOn bool
+	// Lockdown asks the host app to block traffic while the tunnel is not established
+	// (blocking connections without VPN on Android, includeAllNetworks on iOS).
+	Lockdown bool
+
+	includedApps []string
+	excludedApps []string
+}
+
+// NewTunnelPreferences returns preferences routing all applications through the tunnel.
+func NewTunnelPreferences() *TunnelPreferences {
+	return &TunnelPreferences{}
+}
+
+// IncludeApp routes traffic of the given application through the tunnel, other applications bypass it.
+func (p *TunnelPreferences) IncludeApp(appID string) {
+	p.includedApps = append(p.includedApps, appID)
+}
+
+// ExcludeApp lets traffic of the given application bypass the tunnel.
+func (p *TunnelPreferences) ExcludeApp(appID string) {
+	p.excludedApps = append(p.excludedApps, appID)
+}
+
+func (p TunnelPreferences) validate() error {
+	if len(p.includedApps) > 0 && len(p.excludedApps) > 0 {
+		return errMixedAppLists
+	}
+	return nil
+}
+
+func (p TunnelPreferences) toDTO() *tunnelPreferencesDTO {
+	return &tunnelPreferencesDTO{
+		AlwaysOn:     p.AlwaysOn,
+		Lockdown:     p.Lockdown,
+		IncludedApps: append([]string(nil), p.includedApps...),
+		ExcludedApps: append([]string(nil), p.excludedApps...),
+	}
+}
+
+type tunnelPreferencesDTO struct {
+	AlwaysOn     bool     `json:"always_on"`
+	Lockdown     bool     `json:"lockdown"`
+	IncludedApps []string `json:"included_apps,omitempty"`
+	ExcludedApps []string `json:"excluded_apps,omitempty"`
+}
+
+// tunnelPreferencesStore keeps preferences applied on the next tunnel establishment.
+type tunnelPreferencesStore struct {
+	lock        sync.Mutex
+	preferences TunnelPreferences
+}
+
+func (s *tunnelPreferencesStore) get() TunnelPreferences {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.preferences
+}
+
+func (s *tunnelPreferencesStore) set(preferences TunnelPreferences) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.preferences = TunnelPreferences{
+		AlwaysOn:     preferences.AlwaysOn,
+		Lockdown:     preferences.Lockdown,
+		includedApps: append([]string(nil), preferences.includedApps...),
+		excludedApps: append([]string(nil), preferences.excludedApps...),
+	}
+}
+
+// SetTunnelPreferences sets preferences applied on the next tunnel establishment,
+// they are delivered to the state sync callback so the host app can configure the platform VPN.
+func (mb *MobileNode) SetTunnelPreferences(preferences *TunnelPreferences) error {
+	if preferences == nil {
+		preferences = NewTunnelPreferences()
+	}
+	if err := preferences.validate(); err != nil {
+		return err
+	}
+	mb.tunnelPreferences.set(*preferences)
+
+	mb.stateSyncLock.Lock()
+	defer mb.stateSyncLock.Unlock()
+	if mb.stateSync != nil {
+		mb.stateSync.consumeTunnelPreferences(mb.tunnelPreferences.get())
+	}
+	return nil
+}
+
+// GetTunnelPreferences returns JSON encoded tunnel preferences.
+func (mb *MobileNode) GetTunnelPreferences() ([]byte, error) {
+	return json.Marshal(mb.tunnelPreferences.get().toDTO())
+}
+
+// applyTunnelPreferences passes preferences to the tunnel setup before the tunnel is established.
+func applyTunnelPreferences(tunnelSetup WireguardTunnelSetup, preferences TunnelPreferences) error {
+	if err := preferences.validate(); err != nil {
+		return err
+	}
+	for _, appID := range preferences.includedApps {
+		if err := tunnelSetup.AddAllowedApplication(appID); err != nil {
+			return fmt.Errorf("could not include application %s: %w", appID, err)
+		}
+	}
+	for _, appID := range preferences.excludedApps {
+		if err := tunnelSetup.AddDisallowedApplication(appID); err != nil {
+			return fmt.Errorf("could not exclude application %s: %w", appID, err)
+		}
+	}
+	tunnelSetup.SetAlwaysOn(preferences.AlwaysOn, preferences.Lockdown)
+	return nil
+}
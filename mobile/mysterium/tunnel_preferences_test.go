@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mysterium
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTunnelSetup struct {
+	WireguardTunnelSetup
+	allowed    []string
+	disallowed []string
+	alwaysOn   bool
+	lockdown   bool
+	err        error
+}
+
+func (m *mockTunnelSetup) AddAllowedApplication(appID string) error {
+	m.allowed = append(m.allowed, appID)
+	return m.err
+}
+
+func (m *mockTunnelSetup) AddDisallowedApplication(appID string) error {
+	m.disallowed = append(m.disallowed, appID)
+	return m.err
+}
+
+func (m *mockTunnelSetup) SetAlwaysOn(alwaysOn, lockdown bool) {
+	m.alwaysOn, m.lockdown = alwaysOn, lockdown
+}
+
+func TestMobileNode_SetTunnelPreferences(t *testing.T) {
+	mb := &MobileNode{}
+	preferences := NewTunnelPreferences()
+	preferences.AlwaysOn = true
+	preferences.ExcludeApp("com.example.bank")
+
+	err := mb.SetTunnelPreferences(preferences)
+	assert.NoError(t, err)
+
+	// Preferences are copied, later changes require another call.
+	preferences.ExcludeApp("com.example.game")
+	data, err := mb.GetTunnelPreferences()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"always_on": true, "lockdown": false, "excluded_apps": ["com.example.bank"]}`, string(data))
+}
+
+func TestMobileNode_SetTunnelPreferencesRejectsMixedAppLists(t *testing.T) {
+	mb := &MobileNode{}
+	preferences := NewTunnelPreferences()
+	preferences.IncludeApp("com.example.browser")
+	preferences.ExcludeApp("com.example.bank")
+
+	err := mb.SetTunnelPreferences(preferences)
+	assert.Equal(t, errMixedAppLists, err)
+
+	data, err := mb.GetTunnelPreferences()
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"always_on": false, "lockdown": false}`, string(data))
+}
+
+func TestMobileNode_SetTunnelPreferencesDeliversState(t *testing.T) {
+	syncer, _, cb := newTestStateSyncer(StateSyncOptions{IntervalMillis: 20})
+	assert.NoError(t, syncer.start())
+	defer syncer.stop()
+	mb := &MobileNode{stateSync: syncer}
+
+	preferences := NewTunnelPreferences()
+	preferences.AlwaysOn = true
+	preferences.Lockdown = true
+	preferences.IncludeApp("com.example.browser")
+	assert.NoError(t, mb.SetTunnelPreferences(preferences))
+
+	select {
+	case batch := <-cb.batches:
+		assert.JSONEq(t, `{"tunnel": {"always_on": true, "lockdown": true, "included_apps": ["com.example.browser"]}}`, batch)
+	case <-time.After(time.Second):
+		t.Fatal("tunnel preferences were not delivered")
+	}
+}
+
+func TestApplyTunnelPreferences(t *testing.T) {
+	setup := &mockTunnelSetup{}
+	preferences := TunnelPreferences{AlwaysOn: true, Lockdown: true}
+	preferences.IncludeApp("com.example.browser")
+	preferences.IncludeApp("com.example.mail")
+
+	err := applyTunnelPreferences(setup, preferences)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"com.example.browser", "com.example.mail"}, setup.allowed)
+	assert.Empty(t, setup.disallowed)
+	assert.True(t, setup.alwaysOn)
+	assert.True(t, setup.lockdown)
+}
+
+func TestApplyTunnelPreferences_FailsOnPlatformError(t *testing.T) {
+	setup := &mockTunnelSetup{err: errors.New("package not found")}
+	preferences := TunnelPreferences{}
+	preferences.ExcludeApp("com.example.missing")
+
+	err := applyTunnelPreferences(setup, preferences)
+	assert.EqualError(t, err, "could not exclude application com.example.missing: package not found")
+	assert.False(t, setup.alwaysOn)
+}
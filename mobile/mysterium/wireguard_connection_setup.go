@@ -56,6 +56,11 @@ type WireguardTunnelSetup interface {
 	SetMTU(mtu int)
 	Protect(socket int) error
 	SetSessionName(session string)
+	// AddAllowedApplication and AddDisallowedApplication configure per-app tunneling, only one of them is used per tunnel.
+	AddAllowedApplication(appID string) error
+	AddDisallowedApplication(appID string) error
+	// SetAlwaysOn passes always-on and lockdown preferences of the tunnel to the platform VPN configuration.
+	SetAlwaysOn(alwaysOn, lockdown bool)
 }
 
 type wireGuardOptions struct {
@@ -182,12 +187,13 @@ type wireguardDevice interface {
 	Stats() (*wgcfg.Stats, error)
 }
 
-func newWireguardDevice(tunnelSetup WireguardTunnelSetup) wireguardDevice {
-	return &wireguardDeviceImpl{tunnelSetup: tunnelSetup}
+func newWireguardDevice(tunnelSetup WireguardTunnelSetup, preferences func() TunnelPreferences) wireguardDevice {
+	return &wireguardDeviceImpl{tunnelSetup: tunnelSetup, preferences: preferences}
 }
 
 type wireguardDeviceImpl struct {
 	tunnelSetup WireguardTunnelSetup
+	preferences func() TunnelPreferences
 
 	device *device.Device
 }
@@ -292,6 +298,10 @@ func (w *wireguardDeviceImpl) newTunnDevice(wgTunnSetup WireguardTunnelSetup, co
 	wgTunnSetup.AddRoute("0.0.0.0", 1)
 	wgTunnSetup.AddRoute("128.0.0.0", 1)
 
+	if err := applyTunnelPreferences(wgTunnSetup, w.preferences()); err != nil {
+		return nil, err
+	}
+
 	fd, err := wgTunnSetup.Establish()
 	if err != nil {
 		return nil, err
@@ -19,6 +19,8 @@ package mocks
 
 import (
 	"sync"
+
+	"github.com/mysteriumnetwork/node/eventbus"
 )
 
 // EventBusEntry represents the entry in publisher's history
@@ -63,6 +65,11 @@ func (mp *EventBus) Subscribe(topic string, fn interface{}) error {
 	return nil
 }
 
+// SubscribeBuffered fakes buffered subscribe.
+func (mp *EventBus) SubscribeBuffered(topic string, fn interface{}, options eventbus.BufferOptions) error {
+	return nil
+}
+
 // Unsubscribe fakes unsubscribe.
 func (mp *EventBus) Unsubscribe(topic string, fn interface{}) error {
 	return nil
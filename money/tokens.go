@@ -0,0 +1,146 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// Tokens is an amount of tokens in their smallest units. It is backed by big.Int,
+// so that amounts of 18 decimal tokens do not overflow, and is serialized to JSON as a decimal string.
+// Zero value holds no tokens. Tokens are immutable, arithmetic returns a new value.
+type Tokens struct {
+	value *big.Int
+}
+
+// NewTokens returns the given amount of tokens.
+func NewTokens(amount uint64) Tokens {
+	return newTokens(new(big.Int).SetUint64(amount))
+}
+
+// NewTokensFromBig returns a copy of the given amount of tokens, nil is zero tokens.
+func NewTokensFromBig(amount *big.Int) Tokens {
+	if amount == nil {
+		return Tokens{}
+	}
+	return newTokens(new(big.Int).Set(amount))
+}
+
+// ParseTokens parses decimal amount of tokens.
+func ParseTokens(amount string) (Tokens, error) {
+	value, ok := new(big.Int).SetString(amount, 10)
+	if !ok {
+		return Tokens{}, fmt.Errorf("invalid amount of tokens: %q", amount)
+	}
+	return newTokens(value), nil
+}
+
+// newTokens keeps zero as nil, so that equal amounts have equal representation.
+func newTokens(value *big.Int) Tokens {
+	if value.Sign() == 0 {
+		return Tokens{}
+	}
+	return Tokens{value: value}
+}
+
+// Big returns a copy of the amount.
+func (t Tokens) Big() *big.Int {
+	if t.value == nil {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(t.value)
+}
+
+// Uint64 returns the amount for APIs limited to uint64, amounts not fitting into it are capped.
+func (t Tokens) Uint64() uint64 {
+	switch {
+	case t.value == nil || t.value.Sign() < 0:
+		return 0
+	case !t.value.IsUint64():
+		return ^uint64(0)
+	default:
+		return t.value.Uint64()
+	}
+}
+
+// Add returns the sum of both amounts.
+func (t Tokens) Add(other Tokens) Tokens {
+	return newTokens(new(big.Int).Add(t.Big(), other.Big()))
+}
+
+// Sub returns the difference of both amounts.
+func (t Tokens) Sub(other Tokens) Tokens {
+	return newTokens(new(big.Int).Sub(t.Big(), other.Big()))
+}
+
+// Cmp compares both amounts, returns -1, 0 or +1 like big.Int.
+func (t Tokens) Cmp(other Tokens) int {
+	return t.Big().Cmp(other.Big())
+}
+
+// IsZero checks whether the amount is zero.
+func (t Tokens) IsZero() bool {
+	return t.value == nil
+}
+
+// String returns decimal amount of tokens.
+func (t Tokens) String() string {
+	return t.Big().String()
+}
+
+// Decimal returns the amount in whole tokens rounded to the given number of decimal places.
+// It is calculated exactly, without converting the amount to float.
+func (t Tokens) Decimal(precision int) string {
+	return new(big.Rat).SetFrac(t.Big(), big.NewInt(MystSize)).FloatString(precision)
+}
+
+// Format returns the amount in whole tokens with the currency, the same way as Money does.
+func (t Tokens) Format(currency Currency) string {
+	return t.Decimal(6) + string(currency)
+}
+
+// MarshalJSON serializes the amount as a decimal string.
+func (t Tokens) MarshalJSON() ([]byte, error) {
+	return json.Marshal(t.String())
+}
+
+// UnmarshalJSON parses the amount from a decimal string,
+// plain JSON numbers written before amounts were serialized as strings are accepted too.
+func (t *Tokens) UnmarshalJSON(data []byte) error {
+	if bytes.Equal(data, []byte("null")) {
+		*t = Tokens{}
+		return nil
+	}
+
+	amount := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		if err := json.Unmarshal(data, &amount); err != nil {
+			return err
+		}
+	}
+
+	parsed, err := ParseTokens(amount)
+	if err != nil {
+		return err
+	}
+	*t = parsed
+	return nil
+}
@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package money
+
+import (
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTokens_Arithmetic(t *testing.T) {
+	max := NewTokens(^uint64(0))
+
+	sum := max.Add(NewTokens(1))
+	assert.Equal(t, "18446744073709551616", sum.String())
+	assert.Equal(t, ^uint64(0), sum.Uint64())
+	assert.Equal(t, 1, sum.Cmp(max))
+	assert.Equal(t, max, sum.Sub(NewTokens(1)))
+	assert.Equal(t, Tokens{}, NewTokens(5).Sub(NewTokens(5)))
+	assert.True(t, NewTokens(0).IsZero())
+	assert.Equal(t, "0", Tokens{}.String())
+}
+
+func TestTokens_BigIsCopy(t *testing.T) {
+	tokens := NewTokensFromBig(big.NewInt(10))
+
+	tokens.Big().SetInt64(20)
+
+	assert.Equal(t, uint64(10), tokens.Uint64())
+}
+
+func TestTokens_JSON(t *testing.T) {
+	type holder struct {
+		Tokens Tokens
+	}
+
+	encoded, err := json.Marshal(holder{Tokens: NewTokens(500)})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"Tokens": "500"}`, string(encoded))
+
+	for data, expected := range map[string]Tokens{
+		`{"Tokens": "500"}`: NewTokens(500),
+		`{"Tokens": 500}`:   NewTokens(500),
+		`{"Tokens": null}`:  {},
+		`{}`:                {},
+	} {
+		var decoded holder
+		assert.NoError(t, json.Unmarshal([]byte(data), &decoded), data)
+		assert.Equal(t, expected, decoded.Tokens, data)
+	}
+
+	var decoded holder
+	assert.Error(t, json.Unmarshal([]byte(`{"Tokens": "five"}`), &decoded))
+}
+
+func TestTokens_Format(t *testing.T) {
+	assert.Equal(t, "1.500000MYST", NewTokens(150_000_000).Format(CurrencyMyst))
+	assert.Equal(t, "0.000000MYST", Tokens{}.Format(CurrencyMyst))
+	assert.Equal(t, "1.23", NewTokens(123_456_789).Decimal(2))
+	assert.Equal(t, "184467440737.09551615", NewTokens(^uint64(0)).Decimal(8))
+}
@@ -25,7 +25,7 @@ import (
 )
 
 // AppTopicTraversal the topic that traversal events are published on
-const AppTopicTraversal = "Traversal"
+const AppTopicTraversal = "nat.traversal"
 
 // Tracker is able to track NAT traversal events
 type Tracker struct {
@@ -26,6 +26,8 @@ type NATService interface {
 	Setup(opts Options) (rules []interface{}, err error)
 	Del(rules []interface{}) error
 	Disable() error
+	// BlockedAttempts returns how many packets originating from the given network were dropped by the egress port policy.
+	BlockedAttempts(network net.IPNet) (uint64, error)
 }
 
 // Options params to setup firewall/NAT rules.
@@ -35,4 +37,6 @@ type Options struct {
 	EnableDNSRedirect bool
 	DNSIP             net.IP
 	DNSPort           int
+	// BlockedPorts lists destination ports consumers are not allowed to reach through the provider.
+	BlockedPorts []int
 }
@@ -0,0 +1,216 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+var errGatewayUnreachable = errors.New("gateway is not reachable")
+
+// AppTopicPortMappingHealth is a topic for publish events about port mapping lease health.
+const AppTopicPortMappingHealth = "nat.port_mapping.health"
+
+// LeaseStatus represents port mapping lease status.
+type LeaseStatus string
+
+const (
+	// LeaseActive means that port mapping is granted and renewed in time.
+	LeaseActive LeaseStatus = "active"
+	// LeaseLost means that port mapping is lost, e.g. after gateway reboot, and could not be re-established yet.
+	LeaseLost LeaseStatus = "lost"
+	// LeaseReleased means that port mapping is no longer needed and was deleted.
+	LeaseReleased LeaseStatus = "released"
+)
+
+// AppEventPortMappingHealth represents port mapping lease health change.
+type AppEventPortMappingHealth struct {
+	Protocol  string
+	Port      int
+	Status    LeaseStatus
+	ExpiresAt time.Time
+	Error     error
+}
+
+type lease struct {
+	protocol  string
+	port      int
+	name      string
+	permanent bool
+	expiresAt time.Time
+	gatewayIP net.IP
+	lost      bool
+	renew     chan struct{}
+	stop      chan struct{}
+	stopped   chan struct{}
+	stopOnce  sync.Once
+}
+
+// trackLease starts tracking of the granted port mapping lease. Lease is renewed ahead
+// of its expiry and re-established once gateway loses it, e.g. after a reboot.
+func (p *portMapper) trackLease(protocol string, port int, name string, permanent bool) (release func()) {
+	l := &lease{
+		protocol:  protocol,
+		port:      port,
+		name:      name,
+		permanent: permanent,
+		renew:     make(chan struct{}, 1),
+		stop:      make(chan struct{}),
+		stopped:   make(chan struct{}),
+	}
+	l.expiresAt = p.leaseExpiry(permanent)
+	l.gatewayIP, _ = p.config.MapInterface.ExternalIP()
+
+	p.publishLease(l, nil)
+	go p.maintainLease(l)
+
+	p.leasesLock.Lock()
+	p.leases[l] = struct{}{}
+	p.leasesLock.Unlock()
+
+	return func() {
+		l.stopOnce.Do(func() {
+			p.leasesLock.Lock()
+			delete(p.leases, l)
+			p.leasesLock.Unlock()
+
+			close(l.stop)
+			<-l.stopped
+
+			p.deleteMapping(protocol, port, port)
+			p.publisher.Publish(AppTopicPortMappingHealth, AppEventPortMappingHealth{
+				Protocol: protocol,
+				Port:     port,
+				Status:   LeaseReleased,
+			})
+		})
+	}
+}
+
+func (p *portMapper) maintainLease(l *lease) {
+	defer close(l.stopped)
+
+	// Permanent leases don't need to be renewed in intervals, but still can be lost.
+	var renewC <-chan time.Time
+	if !l.permanent && p.config.MapUpdateInterval > 0 {
+		renew := time.NewTicker(p.config.MapUpdateInterval)
+		defer renew.Stop()
+		renewC = renew.C
+	}
+
+	var checkC <-chan time.Time
+	if p.config.MapCheckInterval > 0 {
+		check := time.NewTicker(p.config.MapCheckInterval)
+		defer check.Stop()
+		checkC = check.C
+	}
+
+	for {
+		select {
+		case <-l.stop:
+			return
+		case <-renewC:
+			p.renewLease(l)
+		case <-l.renew:
+			l.gatewayIP, _ = p.config.MapInterface.ExternalIP()
+			p.renewLease(l)
+		case <-checkC:
+			p.checkLease(l)
+		}
+	}
+}
+
+// Renew re-establishes all the tracked leases without waiting for gateway checks to notice the change.
+func (p *portMapper) Renew() {
+	p.leasesLock.Lock()
+	defer p.leasesLock.Unlock()
+
+	for l := range p.leases {
+		select {
+		case l.renew <- struct{}{}:
+		default:
+			// renewal is already pending
+		}
+	}
+}
+
+// renewLease renews the lease ahead of its expiry.
+func (p *portMapper) renewLease(l *lease) {
+	permanent, err := p.addMapping(l.protocol, l.port, l.port, l.name)
+	p.notify(err)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not renew port mapping lease for port %d", l.port)
+		l.lost = true
+		p.publishLease(l, err)
+		return
+	}
+
+	l.lost = false
+	l.permanent = permanent
+	l.expiresAt = p.leaseExpiry(permanent)
+	p.publishLease(l, nil)
+}
+
+// checkLease detects gateway reboots, which make gateway to lose its port mappings,
+// and re-establishes the lost mapping. Gateway is considered rebooted if it was not
+// reachable for a while or if its external IP has changed.
+func (p *portMapper) checkLease(l *lease) {
+	ip, err := p.config.MapInterface.ExternalIP()
+	if err != nil {
+		log.Debug().Err(err).Msg("Couldn't detect router IP address")
+		if !l.lost {
+			l.lost = true
+			p.publishLease(l, errGatewayUnreachable)
+		}
+		return
+	}
+
+	if !l.lost && ip.Equal(l.gatewayIP) {
+		return
+	}
+
+	log.Info().Msgf("Gateway was restarted or changed, re-establishing port mapping for port %d", l.port)
+	l.gatewayIP = ip
+	p.renewLease(l)
+}
+
+func (p *portMapper) leaseExpiry(permanent bool) time.Time {
+	if permanent {
+		return time.Time{}
+	}
+	return time.Now().Add(p.config.MapLifetime)
+}
+
+func (p *portMapper) publishLease(l *lease, err error) {
+	status := LeaseActive
+	if l.lost {
+		status = LeaseLost
+	}
+	p.publisher.Publish(AppTopicPortMappingHealth, AppEventPortMappingHealth{
+		Protocol:  l.protocol,
+		Port:      l.port,
+		Status:    status,
+		ExpiresAt: l.expiresAt,
+		Error:     err,
+	})
+}
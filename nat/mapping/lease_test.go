@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package mapping
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLease_ReestablishedAfterGatewayReboot(t *testing.T) {
+	router := &mockRouter{uPnPEnabled: true, permanentLease: true}
+	bus := mocks.NewEventBus()
+	config := &Config{
+		MapInterface:     router,
+		MapLifetime:      time.Hour,
+		MapCheckInterval: time.Millisecond,
+	}
+	portMapper := NewPortMapper(config, bus)
+
+	release, ok := portMapper.Map("UDP", 51334, "Test")
+	assert.True(t, ok)
+	assert.Equal(t, LeaseActive, lastLeaseStatus(bus))
+
+	router.setUnreachable(true)
+	assert.Eventually(t, func() bool {
+		return lastLeaseStatus(bus) == LeaseLost
+	}, time.Second, time.Millisecond)
+
+	mappings := router.addedMappings()
+	router.setUnreachable(false)
+	assert.Eventually(t, func() bool {
+		return lastLeaseStatus(bus) == LeaseActive && router.addedMappings() > mappings
+	}, time.Second, time.Millisecond)
+
+	release()
+	assert.Equal(t, LeaseReleased, lastLeaseStatus(bus))
+}
+
+func TestLease_RenewedAheadOfExpiry(t *testing.T) {
+	router := &mockRouter{uPnPEnabled: true}
+	bus := mocks.NewEventBus()
+	config := &Config{
+		MapInterface:      router,
+		MapLifetime:       time.Hour,
+		MapUpdateInterval: time.Millisecond,
+	}
+	portMapper := NewPortMapper(config, bus)
+
+	release, ok := portMapper.Map("UDP", 51334, "Test")
+	defer release()
+
+	assert.True(t, ok)
+	assert.Eventually(t, func() bool {
+		return router.addedMappings() > 2
+	}, time.Second, time.Millisecond)
+}
+
+func TestLease_RenewedOnDemand(t *testing.T) {
+	router := &mockRouter{uPnPEnabled: true, permanentLease: true}
+	bus := mocks.NewEventBus()
+	config := &Config{
+		MapInterface: router,
+		MapLifetime:  time.Hour,
+	}
+	portMapper := NewPortMapper(config, bus)
+
+	release, ok := portMapper.Map("UDP", 51334, "Test")
+	assert.True(t, ok)
+
+	mappings := router.addedMappings()
+	portMapper.Renew()
+	assert.Eventually(t, func() bool {
+		return router.addedMappings() > mappings
+	}, time.Second, time.Millisecond)
+
+	release()
+	mappings = router.addedMappings()
+	portMapper.Renew()
+	time.Sleep(10 * time.Millisecond)
+	assert.Equal(t, mappings, router.addedMappings())
+}
+
+func lastLeaseStatus(bus *mocks.EventBus) LeaseStatus {
+	var status LeaseStatus
+	for _, e := range bus.GetEventHistory() {
+		if e.Topic == AppTopicPortMappingHealth {
+			status = e.Event.(AppEventPortMappingHealth).Status
+		}
+	}
+	return status
+}
@@ -20,6 +20,7 @@ package mapping
 import (
 	"errors"
 	"net"
+	"sync"
 	"time"
 
 	portmap "github.com/ethereum/go-ethereum/p2p/nat"
@@ -37,6 +38,7 @@ func DefaultConfig() *Config {
 		MapInterface:      portmap.Any(),
 		MapLifetime:       20 * time.Minute,
 		MapUpdateInterval: 15 * time.Minute,
+		MapCheckInterval:  time.Minute,
 	}
 }
 
@@ -45,6 +47,8 @@ type Config struct {
 	MapInterface      portmap.Interface
 	MapLifetime       time.Duration
 	MapUpdateInterval time.Duration
+	// MapCheckInterval is an interval of gateway checks used to detect lost port mappings.
+	MapCheckInterval time.Duration
 }
 
 // PortMapper tries to map port using router's uPnP or NAT-PMP depending on given config map interface.
@@ -53,6 +57,8 @@ type PortMapper interface {
 	// must be called when port no longer needed and ok which is true if
 	// port mapping was successful.
 	Map(protocol string, port int, name string) (release func(), ok bool)
+	// Renew re-establishes all the granted port mappings, e.g. after public IP of the node has changed.
+	Renew()
 }
 
 // NewPortMapper returns port mapper instance.
@@ -60,12 +66,16 @@ func NewPortMapper(config *Config, publisher eventbus.Publisher) PortMapper {
 	return &portMapper{
 		config:    config,
 		publisher: publisher,
+		leases:    make(map[*lease]struct{}),
 	}
 }
 
 type portMapper struct {
 	config    *Config
 	publisher eventbus.Publisher
+
+	leasesLock sync.Mutex
+	leases     map[*lease]struct{}
 }
 
 func (p *portMapper) Map(protocol string, port int, name string) (release func(), ok bool) {
@@ -85,28 +95,7 @@ func (p *portMapper) Map(protocol string, port int, name string) (release func()
 		return nil, false
 	}
 
-	// If only permanent lease is supported we don't need to update it in intervals.
-	if permanent {
-		return func() { p.deleteMapping(protocol, port, port) }, true
-	}
-
-	stopUpdate := make(chan struct{})
-	go func() {
-		for {
-			select {
-			case <-stopUpdate:
-				return
-			case <-time.After(p.config.MapUpdateInterval):
-				_, err := p.addMapping(protocol, port, port, name)
-				p.notify(err)
-			}
-		}
-	}()
-
-	return func() {
-		p.deleteMapping(protocol, port, port)
-		close(stopUpdate)
-	}, true
+	return p.trackLease(protocol, port, name, permanent), true
 }
 
 func (p *portMapper) routerIPPublic() bool {
@@ -137,8 +137,10 @@ type mockRouter struct {
 	uPnPEnabled    bool
 	permanentLease bool
 	routerIP       net.IP
+	unreachable    bool
 
-	mapping mapping
+	mapping  mapping
+	mappings int
 }
 
 func (m *mockRouter) AddMapping(protocol string, extport, intport int, name string, lifetime time.Duration) error {
@@ -158,6 +160,7 @@ func (m *mockRouter) AddMapping(protocol string, extport, intport int, name stri
 		name:     name,
 		lifetime: lifetime,
 	}
+	m.mappings++
 	return nil
 }
 
@@ -173,9 +176,29 @@ func (m *mockRouter) DeleteMapping(protocol string, extport, intport int) error
 }
 
 func (m *mockRouter) ExternalIP() (net.IP, error) {
+	m.Lock()
+	defer m.Unlock()
+
+	if m.unreachable {
+		return nil, errors.New("gateway is down")
+	}
 	return m.routerIP, nil
 }
 
+func (m *mockRouter) setUnreachable(unreachable bool) {
+	m.Lock()
+	defer m.Unlock()
+
+	m.unreachable = unreachable
+}
+
+func (m *mockRouter) addedMappings() int {
+	m.Lock()
+	defer m.Unlock()
+
+	return m.mappings
+}
+
 func (m *mockRouter) String() string {
 	return ""
 }
@@ -19,6 +19,7 @@ package nat
 
 import (
 	"net"
+	"strconv"
 	"strings"
 
 	"github.com/mysteriumnetwork/node/config"
@@ -40,3 +41,20 @@ func protectedNetworks() (nets []*net.IPNet) {
 	}
 	return nets
 }
+
+// DefaultBlockedPorts returns destination ports blocked for consumers unless a service overrides them.
+func DefaultBlockedPorts() (ports []int) {
+	cfg := config.GetString(config.FlagFirewallBlockedPorts)
+	if cfg == "" {
+		return nil
+	}
+	for _, s := range strings.Split(cfg, ",") {
+		port, err := strconv.Atoi(s)
+		if err != nil || port <= 0 || port > 65535 {
+			log.Error().Msgf("Could not parse blocked port %q", s)
+			continue
+		}
+		ports = append(ports, port)
+	}
+	return ports
+}
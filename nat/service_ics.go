@@ -175,6 +175,11 @@ func (ics *serviceICS) Del([]interface{}) error {
 	return nil
 }
 
+// BlockedAttempts is not supported by ICS, blocked ports are not enforced.
+func (ics *serviceICS) BlockedAttempts(net.IPNet) (uint64, error) {
+	return 0, nil
+}
+
 // Disable disables internet connection sharing for the public interface.
 func (ics *serviceICS) Disable() error {
 	result := utils.ErrorCollection{}
@@ -18,7 +18,9 @@
 package nat
 
 import (
+	"net"
 	"strconv"
+	"strings"
 	"sync"
 
 	"github.com/mysteriumnetwork/node/firewall/iptables"
@@ -38,6 +40,8 @@ const (
 	chainForward     = "FORWARD"
 	chainPreRouting  = "PREROUTING"
 	chainPostRouting = "POSTROUTING"
+
+	blockedPortComment = "myst-blocked-port"
 )
 
 // Setup sets NAT/Firewall rules for the given NATOptions.
@@ -88,6 +92,38 @@ func (svc *serviceIPTables) Del(rules []interface{}) (err error) {
 	return err
 }
 
+// BlockedAttempts sums packet counters of blocked port rules set up for networks within the given one.
+func (svc *serviceIPTables) BlockedAttempts(network net.IPNet) (uint64, error) {
+	lines, err := iptables.Exec("--list", chainForward, "--verbose", "--exact", "--numeric")
+	if err != nil {
+		return 0, errors.Wrap(err, "could not list forwarding rules")
+	}
+	return countBlockedAttempts(lines, network), nil
+}
+
+func countBlockedAttempts(lines []string, network net.IPNet) (total uint64) {
+	prefix := "/* " + blockedPortComment + " "
+	for _, line := range lines {
+		start := strings.Index(line, prefix)
+		if start < 0 {
+			continue
+		}
+		comment := strings.TrimSuffix(strings.TrimSpace(line[start+len(prefix):]), "*/")
+		source, _, err := net.ParseCIDR(strings.TrimSpace(comment))
+		if err != nil || !network.Contains(source) {
+			continue
+		}
+		fields := strings.Fields(line)
+		packets, err := strconv.ParseUint(fields[0], 10, 64)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not parse blocked port rule counters: %s", line)
+			continue
+		}
+		total += packets
+	}
+	return total
+}
+
 // Enable enables NAT service.
 func (svc *serviceIPTables) Enable() error {
 	err := svc.ipForward.Enable()
@@ -147,6 +183,17 @@ func makeIPTablesRules(opts Options) (rules []iptables.Rule) {
 		rules = append(rules, rule)
 	}
 
+	// Block destination ports abused via VPN, the comment identifies rules when reading their counters
+	for _, port := range opts.BlockedPorts {
+		for _, protocol := range []string{"tcp", "udp"} {
+			rule := iptables.AppendTo(chainForward).RuleSpec(
+				"--source", vpnNetwork, "--protocol", protocol, "--dport", strconv.Itoa(port),
+				"--match", "comment", "--comment", blockedPortComment+" "+vpnNetwork,
+				"--jump", "DROP")
+			rules = append(rules, rule)
+		}
+	}
+
 	// Protect private networks rule
 	for _, ipNet := range protectedNetworks() {
 		rule := iptables.AppendTo(chainForward).RuleSpec(
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nat
+
+import (
+	"net"
+	"testing"
+
+	"github.com/mysteriumnetwork/node/firewall/iptables"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_makeIPTablesRules_BlocksPorts(t *testing.T) {
+	_, vpnNetwork, _ := net.ParseCIDR("10.182.0.0/24")
+	rules := makeIPTablesRules(Options{
+		VPNNetwork:    *vpnNetwork,
+		ProviderExtIP: net.ParseIP("1.2.3.4"),
+		BlockedPorts:  []int{25},
+	})
+
+	assert.Equal(t, []iptables.Rule{
+		iptables.AppendTo(chainForward).RuleSpec(
+			"--source", "10.182.0.0/24", "--protocol", "tcp", "--dport", "25",
+			"--match", "comment", "--comment", "myst-blocked-port 10.182.0.0/24",
+			"--jump", "DROP"),
+		iptables.AppendTo(chainForward).RuleSpec(
+			"--source", "10.182.0.0/24", "--protocol", "udp", "--dport", "25",
+			"--match", "comment", "--comment", "myst-blocked-port 10.182.0.0/24",
+			"--jump", "DROP"),
+		iptables.AppendTo(chainPostRouting).RuleSpec("--source", "10.182.0.0/24", "!", "--destination", "10.182.0.0/24",
+			"--jump", "SNAT", "--to", "1.2.3.4",
+			"--table", "nat"),
+	}, rules)
+}
+
+func Test_countBlockedAttempts(t *testing.T) {
+	lines := []string{
+		"Chain FORWARD (policy ACCEPT 0 packets, 0 bytes)",
+		"    pkts      bytes target     prot opt in     out     source               destination",
+		"       3      180 DROP       tcp  --  *      *       10.182.0.0/24        0.0.0.0/0            tcp dpt:25 /* myst-blocked-port 10.182.0.0/24 */",
+		"       2      120 DROP       udp  --  *      *       10.182.0.0/24        0.0.0.0/0            udp dpt:25 /* myst-blocked-port 10.182.0.0/24 */",
+		"       7      420 DROP       tcp  --  *      *       10.8.0.0/24          0.0.0.0/0            tcp dpt:25 /* myst-blocked-port 10.8.0.0/24 */",
+		"      11      660 DROP       all  --  *      *       10.182.0.0/24        192.168.0.0/16",
+	}
+
+	_, wireguardSubnet, _ := net.ParseCIDR("10.182.0.0/16")
+	assert.Equal(t, uint64(5), countBlockedAttempts(lines, *wireguardSubnet))
+
+	_, openvpnSubnet, _ := net.ParseCIDR("10.8.0.0/24")
+	assert.Equal(t, uint64(7), countBlockedAttempts(lines, *openvpnSubnet))
+
+	_, otherSubnet, _ := net.ParseCIDR("172.16.0.0/12")
+	assert.Equal(t, uint64(0), countBlockedAttempts(lines, *otherSubnet))
+}
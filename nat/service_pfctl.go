@@ -83,6 +83,11 @@ func (service *servicePFCtl) Del(rules []interface{}) error {
 	return nil
 }
 
+// BlockedAttempts is not supported by pfctl, blocked ports are not enforced.
+func (service *servicePFCtl) BlockedAttempts(net.IPNet) (uint64, error) {
+	return 0, nil
+}
+
 func makePfctlRules(opts Options) (rules []string, err error) {
 	externalIface, err := ifaceByAddress(opts.ProviderExtIP)
 	if err != nil {
@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nodetest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+// DiscoveryTimeout limits how long consumer waits for provider to announce its service.
+const DiscoveryTimeout = 5 * time.Second
+
+// Consumer is an in-process consumer node using the service under test.
+type Consumer struct {
+	ID       identity.Identity
+	EventBus eventbus.EventBus
+	// Connection manages connection of the consumer.
+	Connection connection.Manager
+
+	network *Network
+	options ServiceOptions
+}
+
+// NewConsumer creates consumer node with the given identity address.
+// Connection config can be adjusted with configure, public IP of the consumer does not change
+// when connected in-process, so the IP check is disabled by default.
+func (n *Network) NewConsumer(address string, options ServiceOptions, configure ...func(*connection.Config)) *Consumer {
+	bus := eventbus.New()
+	registry := connection.NewRegistry()
+	registry.Register(options.ServiceType, options.Connection)
+
+	config := connection.DefaultConfig()
+	config.IPCheck.MaxAttempts = 0
+	for _, fn := range configure {
+		fn(&config)
+	}
+
+	ipResolver := ip.NewResolverMock(Location.IP)
+	dialer := p2p.NewDialer(n, signerFactory, &identity.VerifierFake{}, ipResolver, traversal.NewNoopPinger(), port.NewPool(port.NewReservations(nil)))
+	diagnostics := connection.NewFailureDiagnostics(p2p.NewProviderPinger(n), connection.DefaultDiagnosticsTimeout)
+
+	return &Consumer{
+		ID:         identity.FromAddress(address),
+		EventBus:   bus,
+		Connection: connection.NewManager(consumerPayments, registry.CreateConnection, bus, ipResolver, config, time.Second, acceptingValidator{}, dialer, diagnostics, nil),
+		network:    n,
+		options:    options,
+	}
+}
+
+// Connect connects consumer to the service of the provider once it is announced.
+func (c *Consumer) Connect(provider *Provider, params connection.ConnectParams) error {
+	proposal, err := c.network.Proposal(provider.ID, c.options.ServiceType, DiscoveryTimeout)
+	if err != nil {
+		return err
+	}
+	return c.Connection.Connect(c.ID, AccountantID, proposal, params)
+}
+
+// Disconnect disconnects consumer from the provider.
+func (c *Consumer) Disconnect() error {
+	return c.Connection.Disconnect()
+}
+
+// WaitForState waits until connection of the consumer reaches the given state.
+func (c *Consumer) WaitForState(state connection.State, timeout time.Duration) error {
+	if !waitFor(timeout, func() bool { return c.Connection.Status().State == state }) {
+		return fmt.Errorf("consumer connection is %s instead of %s after %s", c.Connection.Status().State, state, timeout)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nodetest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+// AccountantID is the accountant consumers pay through, payments are not settled in-process.
+var AccountantID = common.HexToAddress("0x0000000000000000000000000000000000000acc")
+
+// freePayments replaces payments of both provider and consumer, the first invoice is paid immediately.
+type freePayments struct {
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func newFreePayments() *freePayments {
+	return &freePayments{stop: make(chan struct{})}
+}
+
+func (p *freePayments) Start() error {
+	<-p.stop
+	return nil
+}
+
+func (p *freePayments) WaitFirstInvoice(time.Duration) error {
+	return nil
+}
+
+func (p *freePayments) SetSessionID(string) {}
+
+func (p *freePayments) Stop() {
+	p.stopOnce.Do(func() {
+		close(p.stop)
+	})
+}
+
+func providerPayments(_, _ identity.Identity, _ common.Address, _ string) (service.PaymentEngine, error) {
+	return newFreePayments(), nil
+}
+
+func consumerPayments(_ p2p.Channel, _, _ identity.Identity, _ common.Address, _ market.ServiceProposal) (connection.PaymentIssuer, error) {
+	return newFreePayments(), nil
+}
+
+// acceptingValidator lets consumer connect without checking its balance or registration.
+type acceptingValidator struct{}
+
+func (acceptingValidator) Validate(identity.Identity, market.ServiceProposal) error {
+	return nil
+}
+
+func signerFactory(identity.Identity) identity.Signer {
+	return &identity.SignerFake{}
+}
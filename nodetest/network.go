@@ -0,0 +1,169 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+// Package nodetest runs provider and consumer nodes in-process, so that service plugins can be integration
+// tested without the docker e2e environment. Broker, discovery and payments are replaced with in-memory fakes,
+// therefore neither blockchain nor transactor is needed.
+package nodetest
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/location"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/services/noop"
+)
+
+// ServiceFactory creates provider side of the service under test, publisher is the event bus of provider node.
+type ServiceFactory func(publisher eventbus.Publisher, options service.Options) (service.Service, market.ServiceProposal, error)
+
+// ServiceOptions describes the service under test.
+type ServiceOptions struct {
+	ServiceType string
+	// Service creates provider side of the service.
+	Service ServiceFactory
+	// Connection creates consumer side of the service.
+	Connection connection.Factory
+	// Options are passed to the service when provider starts it.
+	Options service.Options
+}
+
+// NoopService returns options of the noop service which does no real tunneling.
+func NoopService() ServiceOptions {
+	return ServiceOptions{
+		ServiceType: noop.ServiceType,
+		Service: func(publisher eventbus.Publisher, options service.Options) (service.Service, market.ServiceProposal, error) {
+			return noop.NewManager(options.(noop.Options), publisher), noop.GetProposal(Location), nil
+		},
+		Connection: noop.NewConnection,
+		Options:    noop.DefaultOptions,
+	}
+}
+
+// Location is reported by every in-process node.
+var Location = location.Location{
+	IP:        "127.0.0.1",
+	Continent: "EU",
+	Country:   "LT",
+	City:      "Vilnius",
+	NodeType:  "residential",
+}
+
+// Network connects in-process nodes through the in-memory broker and discovery.
+type Network struct {
+	broker *nats.ConnectionMock
+
+	proposalsMu sync.Mutex
+	proposals   map[market.ProposalID]market.ServiceProposal
+}
+
+// NewNetwork creates network for in-process nodes.
+func NewNetwork() *Network {
+	return &Network{
+		broker:    nats.StartConnectionMock(),
+		proposals: make(map[market.ProposalID]market.ServiceProposal),
+	}
+}
+
+// Close shuts down the in-memory broker.
+func (n *Network) Close() {
+	n.broker.Close()
+}
+
+// Connect implements broker connector of p2p dialer, every node shares the same in-memory broker.
+func (n *Network) Connect(_ ...string) (nats.Connection, error) {
+	return n.broker, nil
+}
+
+// Proposal waits until the provider announces its service and returns the proposal.
+func (n *Network) Proposal(providerID identity.Identity, serviceType string, timeout time.Duration) (market.ServiceProposal, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		n.proposalsMu.Lock()
+		for id, proposal := range n.proposals {
+			if id.ProviderID == providerID.Address && id.ServiceType == serviceType {
+				n.proposalsMu.Unlock()
+				return proposal, nil
+			}
+		}
+		n.proposalsMu.Unlock()
+
+		if time.Now().After(deadline) {
+			return market.ServiceProposal{}, fmt.Errorf("proposal of %s service by %s was not announced in %s", serviceType, providerID.Address, timeout)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+func (n *Network) announce(proposal market.ServiceProposal) {
+	n.proposalsMu.Lock()
+	defer n.proposalsMu.Unlock()
+
+	n.proposals[proposal.UniqueID()] = proposal
+}
+
+func (n *Network) unannounce(proposal market.ServiceProposal) {
+	n.proposalsMu.Lock()
+	defer n.proposalsMu.Unlock()
+
+	delete(n.proposals, proposal.UniqueID())
+}
+
+// discovery announces proposals to the network instead of the broker.
+type discovery struct {
+	network  *Network
+	proposal market.ServiceProposal
+	stopped  chan struct{}
+	once     sync.Once
+}
+
+func (d *discovery) Start(_ identity.Identity, proposal market.ServiceProposal) {
+	d.proposal = proposal
+	d.network.announce(proposal)
+}
+
+func (d *discovery) Stop() {
+	d.once.Do(func() {
+		d.network.unannounce(d.proposal)
+		close(d.stopped)
+	})
+}
+
+func (d *discovery) Wait() {
+	<-d.stopped
+}
+
+const pollInterval = 10 * time.Millisecond
+
+// waitFor polls the condition until it is met or timeout passes.
+func waitFor(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(pollInterval)
+	}
+	return true
+}
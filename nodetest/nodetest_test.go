@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nodetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/connection"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNoopSession(t *testing.T) {
+	// given
+	network := NewNetwork()
+	defer network.Close()
+
+	provider := network.NewProvider("0x0000000000000000000000000000000000000001", NoopService())
+	defer provider.Close()
+	consumer := network.NewConsumer("0x0000000000000000000000000000000000000002", NoopService())
+
+	providerEvents := make(chan sessionEvent.AppEventSession, 10)
+	assert.NoError(t, provider.EventBus.Subscribe(sessionEvent.AppTopicSession, func(e sessionEvent.AppEventSession) {
+		providerEvents <- e
+	}))
+
+	_, err := provider.StartService()
+	assert.NoError(t, err)
+
+	// when
+	err = consumer.Connect(provider, connection.ConnectParams{DisableKillSwitch: true})
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, consumer.WaitForState(connection.Connected, 10*time.Second))
+	assert.NoError(t, provider.WaitForSessions(1, time.Second))
+	assert.Equal(t, consumer.ID, provider.Sessions.GetAll()[0].ConsumerID)
+	assert.Equal(t, sessionEvent.CreatedStatus, (<-providerEvents).Status)
+
+	// when
+	err = consumer.Disconnect()
+
+	// then
+	assert.NoError(t, err)
+	assert.NoError(t, consumer.WaitForState(connection.NotConnected, 10*time.Second))
+	assert.NoError(t, provider.WaitForSessions(0, 10*time.Second))
+}
+
+func TestConnectFailsWithoutService(t *testing.T) {
+	network := NewNetwork()
+	defer network.Close()
+
+	provider := network.NewProvider("0x0000000000000000000000000000000000000001", NoopService())
+	consumer := network.NewConsumer("0x0000000000000000000000000000000000000002", NoopService())
+
+	_, err := network.Proposal(provider.ID, NoopService().ServiceType, 50*time.Millisecond)
+	assert.Error(t, err)
+	assert.Equal(t, connection.NotConnected, consumer.Connection.Status().State)
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package nodetest
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/nat/event"
+	"github.com/mysteriumnetwork/node/nat/mapping"
+	"github.com/mysteriumnetwork/node/nat/traversal"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session/connectivity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// Provider is an in-process provider node serving the service under test.
+type Provider struct {
+	ID       identity.Identity
+	EventBus eventbus.EventBus
+	// Services manages service instances of the provider.
+	Services *service.Manager
+	// Sessions holds active sessions of the provider.
+	Sessions *service.SessionPool
+
+	options ServiceOptions
+}
+
+// NewProvider creates provider node with the given identity address.
+// Session config, e.g. QoS classes, can be adjusted with configure before the service is started.
+func (n *Network) NewProvider(address string, options ServiceOptions, configure ...func(*service.Config)) *Provider {
+	bus := eventbus.New()
+	registry := service.NewRegistry()
+	registry.Register(options.ServiceType, func(serviceOptions service.Options) (service.Service, market.ServiceProposal, error) {
+		return options.Service(bus, serviceOptions)
+	})
+
+	sessionConfig := service.DefaultConfig()
+	for _, fn := range configure {
+		fn(&sessionConfig)
+	}
+
+	sessions := service.NewSessionPool(bus)
+	sessionStarts := service.NewStartLimiter(0, 0)
+	natTracker := event.NewTracker()
+	newSessionManager := func(instance *service.Instance, channel p2p.Channel) *service.SessionManager {
+		return service.NewSessionManager(instance, sessions, sessionStarts, providerPayments, natTracker, bus, channel, sessionConfig)
+	}
+
+	listener := p2p.NewListener(
+		n.broker,
+		signerFactory,
+		&identity.VerifierFake{},
+		ip.NewResolverMock(Location.IP),
+		traversal.NewNoopPinger(),
+		port.NewPool(port.NewReservations(nil)),
+		mapping.NewNoopPortMapper(bus),
+	)
+
+	return &Provider{
+		ID:       identity.FromAddress(address),
+		EventBus: bus,
+		Services: service.NewManager(
+			registry,
+			func() service.Discovery { return &discovery{network: n, stopped: make(chan struct{})} },
+			bus,
+			nil,
+			listener,
+			newSessionManager,
+			connectivity.NewStatusStorage(),
+			nil,
+		),
+		Sessions: sessions,
+		options:  options,
+	}
+}
+
+// StartService starts the service under test and announces it to the network.
+func (p *Provider) StartService() (service.ID, error) {
+	return p.Services.Start(
+		p.ID,
+		p.options.ServiceType,
+		nil,
+		p.options.Options,
+		pingpong.NewPaymentMethod(0, 0),
+		false,
+		service.ConsumerPolicy{},
+	)
+}
+
+// StopService stops the service instance.
+func (p *Provider) StopService(id service.ID) error {
+	return p.Services.Stop(id)
+}
+
+// Close stops all services of the provider.
+func (p *Provider) Close() error {
+	return p.Services.Kill()
+}
+
+// WaitForSessions waits until provider has the given count of active sessions.
+func (p *Provider) WaitForSessions(count int, timeout time.Duration) error {
+	if !waitFor(timeout, func() bool { return len(p.Sessions.GetAll()) == count }) {
+		return fmt.Errorf("provider has %d sessions instead of %d after %s", len(p.Sessions.GetAll()), count, timeout)
+	}
+	return nil
+}
@@ -27,6 +27,7 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/rs/zerolog/log"
@@ -43,12 +44,24 @@ var (
 
 	// ErrHandlerNotFound indicates that peer is not registered handler yet.
 	ErrHandlerNotFound = errors.New("p2p peer handler not found")
+
+	// ErrPeerStalled indicates that remote peer stopped receiving messages sent to it.
+	ErrPeerStalled = errors.New("p2p peer stopped receiving messages")
+
+	errChannelClosed = errors.New("p2p channel is closed")
+
+	// stallTimeout is how long sending may block before remote peer is considered stalled.
+	stallTimeout = 30 * time.Second
 )
 
 const (
 	kcpMTUSize            = 1280
 	mtuLimit              = 1500
 	initialTrafficTimeout = 10 * time.Second
+
+	// maxConcurrentHandlers limits goroutines handling peer requests. When all of them are busy
+	// reading from peer is paused, so KCP window fills up and peer has to slow down.
+	maxConcurrentHandlers = 64
 )
 
 // ChannelSender is used to send messages.
@@ -74,6 +87,25 @@ type Channel interface {
 	// Conn returns underlying channel's UDP connection.
 	Conn() *net.UDPConn
 
+	// RemoteAddr returns current address of remote peer.
+	RemoteAddr() *net.UDPAddr
+
+	// LastActivity returns time when the last packet was received from remote peer.
+	LastActivity() time.Time
+
+	// Stalled returns channel which is closed when remote peer stops receiving messages sent to it.
+	Stalled() <-chan struct{}
+
+	// ActiveHandlers returns count of goroutines currently handling peer requests.
+	ActiveHandlers() int
+
+	// TraversalMethod returns NAT traversal method used to reach remote peer.
+	TraversalMethod() string
+
+	// DisableCompression disables payload compression for messages of given topic.
+	// It should be used for topics which carry already compressed or encrypted payloads.
+	DisableCompression(topic string)
+
 	// Close closes p2p communication channel.
 	Close() error
 }
@@ -165,6 +197,9 @@ type channel struct {
 	// upnpPortsRelease should be called to close mapped upnp ports when channel is closed.
 	upnpPortsRelease []func()
 
+	// traversalMethod is NAT traversal method used to reach remote peer.
+	traversalMethod string
+
 	// stop is used to stop all running goroutines.
 	stop chan struct{}
 
@@ -173,6 +208,24 @@ type channel struct {
 
 	// terminate remote aliveness checking only once
 	remoteAliveOnce sync.Once
+
+	// lastActivity holds unix nano timestamp of the last packet received from remote peer.
+	lastActivity int64
+
+	// handlerSlots bounds goroutines handling peer requests, activeHandlers counts them.
+	handlerSlots   chan struct{}
+	activeHandlers int32
+
+	// stalled is closed once when remote peer stops receiving messages.
+	stalled     chan struct{}
+	stalledOnce sync.Once
+
+	// compression is payload compression algorithm negotiated with remote peer during config exchange.
+	// Empty value means that messages are sent uncompressed.
+	compression string
+
+	// uncompressedTopics holds topics for which compression is disabled.
+	uncompressedTopics map[string]struct{}
 }
 
 // newChannel creates new p2p channel with initialized crypto primitives for data encryption
@@ -213,16 +266,20 @@ func newChannel(remoteConn *net.UDPConn, privateKey PrivateKey, peerPubKey Publi
 	}
 
 	c := channel{
-		tr:               &tr,
-		topicHandlers:    make(map[string]HandlerFunc),
-		streams:          make(map[uint64]*stream),
-		privateKey:       privateKey,
-		peer:             &peer,
-		localSessionAddr: sessAddr,
-		serviceConn:      nil,
-		stop:             make(chan struct{}, 1),
-		sendQueue:        make(chan *transportMsg, 100),
-		remoteAlive:      make(chan struct{}, 1),
+		tr:                 &tr,
+		topicHandlers:      make(map[string]HandlerFunc),
+		uncompressedTopics: make(map[string]struct{}),
+		streams:            make(map[uint64]*stream),
+		privateKey:         privateKey,
+		peer:               &peer,
+		localSessionAddr:   sessAddr,
+		serviceConn:        nil,
+		stop:               make(chan struct{}, 1),
+		sendQueue:          make(chan *transportMsg, 100),
+		remoteAlive:        make(chan struct{}, 1),
+		lastActivity:       time.Now().UnixNano(),
+		handlerSlots:       make(chan struct{}, maxConcurrentHandlers),
+		stalled:            make(chan struct{}),
 	}
 
 	return &c, nil
@@ -261,6 +318,7 @@ func (c *channel) remoteReadLoop() {
 		c.remoteAliveOnce.Do(func() {
 			close(c.remoteAlive)
 		})
+		atomic.StoreInt64(&c.lastActivity, time.Now().UnixNano())
 
 		// Check if peer address changed.
 		if addr, ok := addr.(*net.UDPAddr); ok {
@@ -331,9 +389,25 @@ func (c *channel) localReadLoop() {
 			fmt.Printf("recv from %s: %+v\n", c.tr.session.RemoteAddr(), msg)
 		}
 
+		if msg.encoding != "" {
+			data, err := decompress(msg.encoding, msg.data)
+			if err != nil {
+				log.Err(err).Msgf("Could not decompress message %d", msg.id)
+				continue
+			}
+			msg.data = data
+		}
+
 		// If message contains topic it means that peer is making a request
 		// and waits for response.
 		if msg.topic != "" {
+			// Wait for a free handler instead of piling up goroutines for a peer which floods us
+			// with requests, but does not read replies.
+			select {
+			case c.handlerSlots <- struct{}{}:
+			case <-c.stop:
+				return
+			}
 			go c.handleRequest(&msg)
 		} else {
 			// In other case we treat it as a reply for peer to our request.
@@ -357,8 +431,13 @@ func (c *channel) localSendLoop() {
 				fmt.Printf("send to %s: %+v\n", c.tr.session.RemoteAddr(), msg)
 			}
 
+			// KCP blocks writes while peer does not acknowledge sent data.
+			deadline := time.Now().Add(stallTimeout)
+			c.tr.session.SetWriteDeadline(deadline)
 			if err := msg.writeTo(c.tr.textWriter); err != nil {
-				if !errPipeClosed(err) && !errNetClose(err) {
+				if !time.Now().Before(deadline) {
+					c.markStalled("write to transport timed out")
+				} else if !errPipeClosed(err) && !errNetClose(err) {
 					log.Err(err).Msg("Write to textproto writer failed")
 				}
 				return
@@ -380,6 +459,12 @@ func (c *channel) handleReply(msg *transportMsg) {
 
 // handleRequest handles incoming request and schedules reply to send queue.
 func (c *channel) handleRequest(msg *transportMsg) {
+	atomic.AddInt32(&c.activeHandlers, 1)
+	defer func() {
+		atomic.AddInt32(&c.activeHandlers, -1)
+		<-c.handlerSlots
+	}()
+
 	c.mu.RLock()
 	handler, ok := c.topicHandlers[msg.topic]
 	c.mu.RUnlock()
@@ -392,7 +477,7 @@ func (c *channel) handleRequest(msg *transportMsg) {
 		errMsg := fmt.Sprintf("handler %q not found", msg.topic)
 		log.Err(errors.New(errMsg))
 		resMsg.data = []byte(errMsg)
-		c.sendQueue <- &resMsg
+		c.enqueueReply(&resMsg)
 		return
 	}
 
@@ -406,13 +491,70 @@ func (c *channel) handleRequest(msg *transportMsg) {
 		log.Err(ctx.publicError).Msgf("Handler %q public error", msg.topic)
 		resMsg.statusCode = statusCodePublicErr
 		resMsg.data = []byte(ctx.publicError.Error())
+		var coded *PublicError
+		if errors.As(ctx.publicError, &coded) {
+			resMsg.errorCode = coded.Code
+		}
 	} else {
 		resMsg.statusCode = statusCodeOK
 		if ctx.res != nil {
 			resMsg.data = ctx.res.Data
+			c.compressMsg(&resMsg, msg.topic)
 		}
 	}
-	c.sendQueue <- &resMsg
+	c.enqueueReply(&resMsg)
+}
+
+// enqueueReply schedules reply to send queue.
+func (c *channel) enqueueReply(msg *transportMsg) {
+	if err := c.enqueue(context.Background(), msg); err != nil {
+		log.Debug().Err(err).Msgf("Dropping reply to message %d", msg.id)
+	}
+}
+
+// enqueue puts message to send queue. It gives up when queue stays full for too long,
+// which means that remote peer stopped receiving messages.
+func (c *channel) enqueue(ctx context.Context, msg *transportMsg) error {
+	select {
+	case c.sendQueue <- msg:
+		return nil
+	default:
+	}
+
+	timer := time.NewTimer(stallTimeout)
+	defer timer.Stop()
+
+	select {
+	case c.sendQueue <- msg:
+		return nil
+	case <-ctx.Done():
+		return ErrSendTimeout
+	case <-c.stop:
+		return errChannelClosed
+	case <-c.stalled:
+		return ErrPeerStalled
+	case <-timer.C:
+		c.markStalled("send queue is full")
+		return ErrPeerStalled
+	}
+}
+
+// markStalled records that remote peer stopped receiving messages.
+func (c *channel) markStalled(reason string) {
+	c.stalledOnce.Do(func() {
+		log.Warn().Msgf("P2P peer stopped receiving messages: %s, %d handlers are running", reason, c.ActiveHandlers())
+		close(c.stalled)
+	})
+}
+
+// Stalled returns channel which is closed when remote peer stops receiving messages sent to it.
+func (c *channel) Stalled() <-chan struct{} {
+	return c.stalled
+}
+
+// ActiveHandlers returns count of goroutines currently handling peer requests.
+func (c *channel) ActiveHandlers() int {
+	return int(atomic.LoadInt32(&c.activeHandlers))
 }
 
 // ServiceConn returns UDP connection which can be used for services.
@@ -461,6 +603,57 @@ func (c *channel) Conn() *net.UDPConn {
 	return c.tr.remoteConn
 }
 
+// RemoteAddr returns current address of remote peer.
+func (c *channel) RemoteAddr() *net.UDPAddr {
+	return c.peer.addr()
+}
+
+// DisableCompression disables payload compression for messages of given topic.
+func (c *channel) DisableCompression(topic string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.uncompressedTopics[topic] = struct{}{}
+}
+
+// compressMsg compresses message payload with negotiated compression if it is worth it.
+func (c *channel) compressMsg(msg *transportMsg, topic string) {
+	if c.compression == "" || len(msg.data) < compressionMinSize {
+		return
+	}
+
+	c.mu.RLock()
+	_, disabled := c.uncompressedTopics[topic]
+	c.mu.RUnlock()
+	if disabled {
+		return
+	}
+
+	data, err := compress(c.compression, msg.data)
+	if err != nil {
+		log.Err(err).Msgf("Could not compress message for topic %q", topic)
+		return
+	}
+	if len(data) >= len(msg.data) {
+		return
+	}
+	msg.data = data
+	msg.encoding = c.compression
+}
+
+// LastActivity returns time when the last packet was received from remote peer.
+func (c *channel) LastActivity() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.lastActivity))
+}
+
+// TraversalMethod returns NAT traversal method used to reach remote peer.
+func (c *channel) TraversalMethod() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.traversalMethod
+}
+
 // Send sends message to given topic. Peer listening to topic will receive message.
 func (c *channel) Send(ctx context.Context, topic string, msg *Message) (*Message, error) {
 	reply, err := c.sendRequest(ctx, topic, msg)
@@ -484,7 +677,11 @@ func (c *channel) sendRequest(ctx context.Context, topic string, m *Message) (*M
 	defer c.deleteStream(s.id)
 
 	// Send request.
-	c.sendQueue <- &transportMsg{id: s.id, topic: topic, data: m.Data}
+	msg := &transportMsg{id: s.id, topic: topic, data: m.Data}
+	c.compressMsg(msg, topic)
+	if err := c.enqueue(ctx, msg); err != nil {
+		return nil, fmt.Errorf("could not send request to %q: %w", topic, err)
+	}
 
 	// Wait for response.
 	select {
@@ -493,7 +690,7 @@ func (c *channel) sendRequest(ctx context.Context, topic string, m *Message) (*M
 	case res := <-s.resCh:
 		if res.statusCode != statusCodeOK {
 			if res.statusCode == statusCodePublicErr {
-				return nil, fmt.Errorf("public peer error: %s", string(res.data))
+				return nil, fmt.Errorf("public peer error: %w", &PublicError{Code: res.errorCode, Message: string(res.data)})
 			}
 			if res.statusCode == statusCodeHandlerNotFoundErr {
 				return nil, fmt.Errorf("%s: %w", string(res.data), ErrHandlerNotFound)
@@ -536,6 +733,22 @@ func (c *channel) setUpnpPortsRelease(release []func()) {
 	c.upnpPortsRelease = release
 }
 
+func (c *channel) setTraversalMethod(method string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.traversalMethod = method
+}
+
+// setCompression sets payload compression negotiated with remote peer.
+// It must be called before starting read and send loops.
+func (c *channel) setCompression(algorithm string) {
+	if algorithm != "" {
+		log.Debug().Msgf("Will use %s p2p payload compression", algorithm)
+	}
+	c.compression = algorithm
+}
+
 func (c *channel) checkIfChannelAlive() {
 	select {
 	case <-c.stop:
@@ -18,6 +18,7 @@
 package p2p
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"net"
@@ -160,6 +161,19 @@ func TestChannelFullCommunicationFlow(t *testing.T) {
 		assert.EqualError(t, err, "public peer error: I don't like you")
 	})
 
+	t.Run("Test peer returns public error with code", func(t *testing.T) {
+		provider.Handle("get-error", func(c Context) error {
+			return c.Error(&PublicError{Code: 42, Message: "I don't like you"})
+		})
+
+		_, err := consumer.Send(context.Background(), "get-error", &Message{Data: []byte("hello")})
+		assert.EqualError(t, err, "public peer error: I don't like you")
+		var publicErr *PublicError
+		if assert.True(t, errors.As(err, &publicErr)) {
+			assert.Equal(t, uint64(42), publicErr.Code)
+		}
+	})
+
 	t.Run("Test peer returns internal error", func(t *testing.T) {
 		provider.Handle("get-error", func(c Context) error {
 			return errors.New("I don't like you")
@@ -258,6 +272,97 @@ func TestChannel_Detect_And_Update_Peer_Addr(t *testing.T) {
 	_, err = consumer.Send(ctx, "ping", &Message{Data: []byte("pingasssas")})
 }
 
+func TestChannel_Send_Compressed(t *testing.T) {
+	provider, consumer, err := createTestChannelsWithCompression(CompressionSnappy)
+	require.NoError(t, err)
+	defer provider.Close()
+	defer consumer.Close()
+
+	payload := bytes.Repeat([]byte("compressible "), 100)
+	provider.Handle("echo", func(c Context) error {
+		return c.OkWithReply(c.Request())
+	})
+	provider.Handle("echo-raw", func(c Context) error {
+		return c.OkWithReply(c.Request())
+	})
+	consumer.DisableCompression("echo-raw")
+
+	for _, topic := range []string{"echo", "echo-raw"} {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		res, err := consumer.Send(ctx, topic, &Message{Data: payload})
+		cancel()
+		require.NoError(t, err)
+		assert.Equal(t, payload, res.Data)
+	}
+}
+
+func TestChannel_LastActivity_Updated_On_Peer_Traffic(t *testing.T) {
+	provider, consumer, err := createTestChannels()
+	require.NoError(t, err)
+	defer consumer.Close()
+	defer provider.Close()
+
+	provider.Handle("ping", func(c Context) error {
+		return c.OK()
+	})
+	before := consumer.LastActivity()
+
+	time.Sleep(10 * time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = consumer.Send(ctx, "ping", &Message{Data: []byte("ping")})
+	require.NoError(t, err)
+
+	assert.True(t, consumer.LastActivity().After(before))
+	assert.True(t, provider.LastActivity().After(before))
+}
+
+func TestChannel_ActiveHandlers_Counts_Running_Requests(t *testing.T) {
+	provider, consumer, err := createTestChannels()
+	require.NoError(t, err)
+	defer consumer.Close()
+	defer provider.Close()
+
+	release := make(chan struct{})
+	provider.Handle("block", func(c Context) error {
+		<-release
+		return c.OK()
+	})
+
+	go consumer.Send(context.Background(), "block", &Message{Data: []byte("ping")})
+	assert.Eventually(t, func() bool {
+		return provider.ActiveHandlers() == 1
+	}, time.Second, 10*time.Millisecond)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		return provider.ActiveHandlers() == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestChannel_Stalled_When_Send_Queue_Stays_Full(t *testing.T) {
+	defer func(timeout time.Duration) { stallTimeout = timeout }(stallTimeout)
+	stallTimeout = 10 * time.Millisecond
+
+	c := &channel{
+		stop:      make(chan struct{}),
+		streams:   make(map[uint64]*stream),
+		sendQueue: make(chan *transportMsg),
+		stalled:   make(chan struct{}),
+	}
+
+	err := c.enqueue(context.Background(), &transportMsg{id: 1})
+	assert.True(t, errors.Is(err, ErrPeerStalled))
+	select {
+	case <-c.Stalled():
+	default:
+		t.Fatal("expected channel to be stalled")
+	}
+
+	_, err = c.sendRequest(context.Background(), "ping", &Message{Data: []byte("ping")})
+	assert.True(t, errors.Is(err, ErrPeerStalled))
+}
+
 func BenchmarkChannel_Send(b *testing.B) {
 	provider, consumer, err := createTestChannels()
 	require.NoError(b, err)
@@ -304,6 +409,10 @@ func reopenChannelWithNewLocalAddr(c *channel) (*channel, error) {
 }
 
 func createTestChannels() (Channel, Channel, error) {
+	return createTestChannelsWithCompression("")
+}
+
+func createTestChannelsWithCompression(compression string) (Channel, Channel, error) {
 	ports, err := acquirePorts(2)
 	if err != nil {
 		return nil, nil, err
@@ -334,19 +443,21 @@ func createTestChannels() (Channel, Channel, error) {
 	if err != nil {
 		return nil, nil, err
 	}
+	provider.setCompression(compression)
 	provider.launchReadSendLoops()
 
 	consumer, err := newChannel(consumerConn, consumerPrivateKey, providerPublicKey)
 	if err != nil {
 		return nil, nil, err
 	}
+	consumer.setCompression(compression)
 	consumer.launchReadSendLoops()
 
 	return provider, consumer, nil
 }
 
 func acquirePorts(n int) ([]int, error) {
-	portPool := port.NewPool()
+	portPool := port.NewPool(port.NewReservations(nil))
 	ports, err := portPool.AcquireMultiple(n)
 	if err != nil {
 		return nil, err
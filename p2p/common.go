@@ -37,6 +37,16 @@ const (
 	consumerInitialTTL = 128
 )
 
+// NAT traversal methods used to establish p2p channel.
+const (
+	// TraversalDirect means that peer ports were reachable without traversal.
+	TraversalDirect = "direct"
+	// TraversalPortMapping means that local ports were mapped on the router with UPnP or NAT-PMP.
+	TraversalPortMapping = "port_mapping"
+	// TraversalHolePunching means that ports were opened by pinging the peer.
+	TraversalHolePunching = "hole_punching"
+)
+
 type brokerConnector interface {
 	Connect(serverURIs ...string) (nats.Connection, error)
 }
@@ -57,6 +67,10 @@ func configExchangeACKSubject(providerID identity.Identity, serviceType string)
 	return fmt.Sprintf("%s.%s.p2p-config-exchange-ack", providerID.Address, serviceType)
 }
 
+func pingSubject(providerID identity.Identity, serviceType string) string {
+	return fmt.Sprintf("%s.%s.p2p-ping", providerID.Address, serviceType)
+}
+
 func channelHandlersReadySubject(providerID identity.Identity, serviceType string) string {
 	return fmt.Sprintf("%s.%s.p2p-channel-handlers-ready", providerID.Address, serviceType)
 }
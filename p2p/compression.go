@@ -0,0 +1,67 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"fmt"
+
+	"github.com/golang/snappy"
+)
+
+const (
+	// CompressionSnappy is snappy block compression of message payload.
+	CompressionSnappy = "snappy"
+
+	// compressionMinSize is the min payload size for which compression is applied.
+	// Smaller payloads usually do not benefit from it.
+	compressionMinSize = 256
+)
+
+// supportedCompression lists compression algorithms supported by this peer ordered by preference.
+var supportedCompression = []string{CompressionSnappy}
+
+// negotiateCompression picks the most preferred compression algorithm supported by both peers.
+// Empty string is returned if peers have no algorithms in common, e.g. remote peer is of older version.
+func negotiateCompression(peerSupported []string) string {
+	for _, local := range supportedCompression {
+		for _, remote := range peerSupported {
+			if local == remote {
+				return local
+			}
+		}
+	}
+	return ""
+}
+
+func compress(algorithm string, data []byte) ([]byte, error) {
+	switch algorithm {
+	case CompressionSnappy:
+		return snappy.Encode(nil, data), nil
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", algorithm)
+	}
+}
+
+func decompress(algorithm string, data []byte) ([]byte, error) {
+	switch algorithm {
+	case CompressionSnappy:
+		return snappy.Decode(nil, data)
+	default:
+		return nil, fmt.Errorf("unsupported compression %q", algorithm)
+	}
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"bufio"
+	"bytes"
+	"net/textproto"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNegotiateCompression(t *testing.T) {
+	assert.Equal(t, CompressionSnappy, negotiateCompression([]string{"zstd", CompressionSnappy}))
+	assert.Equal(t, "", negotiateCompression([]string{"zstd"}))
+	assert.Equal(t, "", negotiateCompression(nil))
+}
+
+func TestCompressDecompress(t *testing.T) {
+	data := bytes.Repeat([]byte("data"), 100)
+
+	compressed, err := compress(CompressionSnappy, data)
+	require.NoError(t, err)
+	assert.Less(t, len(compressed), len(data))
+
+	decompressed, err := decompress(CompressionSnappy, compressed)
+	require.NoError(t, err)
+	assert.Equal(t, data, decompressed)
+
+	_, err = compress("unknown", data)
+	assert.Error(t, err)
+	_, err = decompress("unknown", data)
+	assert.Error(t, err)
+}
+
+func TestTransportMsg_Encoding(t *testing.T) {
+	var buf bytes.Buffer
+	w := bufio.NewWriter(&buf)
+	msg := transportMsg{id: 1, topic: "test", statusCode: statusCodeOK, encoding: CompressionSnappy, data: []byte("data")}
+	require.NoError(t, msg.writeTo(textproto.NewWriter(w)))
+	require.NoError(t, w.Flush())
+
+	var res transportMsg
+	require.NoError(t, res.readFrom(textproto.NewReader(bufio.NewReader(&buf))))
+	assert.Equal(t, msg, res)
+}
@@ -54,6 +54,25 @@ func ParseContact(contacts market.ContactList) (ContactDefinition, error) {
 	return ContactDefinition{}, ErrContactNotFound
 }
 
+// ParseContacts parses all p2p contacts from given contacts list, in order of their appearance.
+func ParseContacts(contacts market.ContactList) ([]ContactDefinition, error) {
+	var defs []ContactDefinition
+	for _, c := range contacts {
+		if c.Type != ContactTypeV1 {
+			continue
+		}
+		def, ok := c.Definition.(ContactDefinition)
+		if !ok {
+			return nil, fmt.Errorf("invalid p2p contact definition: %#v", c.Definition)
+		}
+		defs = append(defs, def)
+	}
+	if len(defs) == 0 {
+		return nil, ErrContactNotFound
+	}
+	return defs, nil
+}
+
 // RegisterContactUnserializer registers global proposal contact unserializer.
 func RegisterContactUnserializer() {
 	market.RegisterContactUnserializer(
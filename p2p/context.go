@@ -32,6 +32,17 @@ type Context interface {
 	OK() error
 }
 
+// PublicError is a handler error reported to the peer along with a code, which lets the peer
+// tell it apart from other failures. Peer receives it wrapped into the error returned by Send.
+type PublicError struct {
+	Code    uint64
+	Message string
+}
+
+func (e *PublicError) Error() string {
+	return e.Message
+}
+
 type defaultContext struct {
 	req         *Message
 	res         *Message
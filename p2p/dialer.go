@@ -144,6 +144,8 @@ func (m *dialer) Dial(ctx context.Context, consumerID, providerID identity.Ident
 		return nil, fmt.Errorf("could not create p2p channel: %w", err)
 	}
 	channel.setServiceConn(conn2)
+	channel.setTraversalMethod(config.traversalMethod())
+	channel.setCompression(negotiateCompression(config.peerCompression))
 	channel.launchReadSendLoops()
 
 	return channel, nil
@@ -198,8 +200,9 @@ func (m *dialer) exchangeConfig(ctx context.Context, brokerConn nats.Connection,
 		return nil, fmt.Errorf("could not acquire local ports: %v", err)
 	}
 	connConfig := &pb.P2PConnectConfig{
-		PublicIP: publicIP,
-		Ports:    intToInt32Slice(localPorts),
+		PublicIP:    publicIP,
+		Ports:       intToInt32Slice(localPorts),
+		Compression: supportedCompression,
 	}
 	connConfigCiphertext, err := encryptConnConfigMsg(connConfig, privateKey, peerPubKey)
 	if err != nil {
@@ -220,12 +223,13 @@ func (m *dialer) exchangeConfig(ctx context.Context, brokerConn nats.Connection,
 	}
 
 	return &p2pConnectConfig{
-		publicIP:     publicIP,
-		privateKey:   privateKey,
-		localPorts:   localPorts,
-		peerPubKey:   peerPubKey,
-		peerPublicIP: peerConnConfig.PublicIP,
-		peerPorts:    int32ToIntSlice(peerConnConfig.Ports),
+		publicIP:        publicIP,
+		privateKey:      privateKey,
+		localPorts:      localPorts,
+		peerPubKey:      peerPubKey,
+		peerPublicIP:    peerConnConfig.PublicIP,
+		peerPorts:       int32ToIntSlice(peerConnConfig.Ports),
+		peerCompression: peerConnConfig.Compression,
 	}, nil
 }
 
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultDialStagger is a delay after which the next contact candidate is dialed
+// while dialing the previous ones is still in progress.
+const DefaultDialStagger = 500 * time.Millisecond
+
+// DialCandidates dials the given contact candidates in parallel with staggered starts, so that a slow
+// or unreachable contact does not delay the connection. The next candidate is dialed once the stagger
+// delay passes or a previous candidate fails. Returns the channel established first, dialing of the rest
+// is cancelled and channels established later are closed.
+func DialCandidates(ctx context.Context, dialer Dialer, consumerID, providerID identity.Identity, serviceType string, contacts []ContactDefinition, stagger time.Duration) (Channel, error) {
+	if len(contacts) == 0 {
+		return nil, ErrContactNotFound
+	}
+	if len(contacts) == 1 {
+		return dialer.Dial(ctx, consumerID, providerID, serviceType, contacts[0])
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan dialResult, len(contacts))
+
+	var next, pending int
+	var staggered <-chan time.Time
+	dialNext := func() {
+		contact := contacts[next]
+		go func() {
+			channel, err := dialer.Dial(ctx, consumerID, providerID, serviceType, contact)
+			results <- dialResult{channel: channel, err: err}
+		}()
+		next++
+		pending++
+
+		staggered = nil
+		if next < len(contacts) {
+			staggered = time.After(stagger)
+		}
+	}
+
+	dialNext()
+	var lastErr error
+	for pending > 0 {
+		select {
+		case <-staggered:
+			dialNext()
+		case res := <-results:
+			pending--
+			if res.err == nil {
+				go closeLateChannels(results, pending)
+				return res.channel, nil
+			}
+
+			log.Debug().Err(res.err).Msgf("Dialing p2p contact candidate of provider %s failed", providerID.Address)
+			lastErr = res.err
+			if next < len(contacts) {
+				dialNext()
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("could not dial any of %d contact candidates: %w", len(contacts), lastErr)
+}
+
+type dialResult struct {
+	channel Channel
+	err     error
+}
+
+// closeLateChannels closes channels of candidates established after the winning one.
+func closeLateChannels(results <-chan dialResult, pending int) {
+	for ; pending > 0; pending-- {
+		res := <-results
+		if res.err != nil {
+			continue
+		}
+		if err := res.channel.Close(); err != nil {
+			log.Warn().Err(err).Msg("Could not close p2p channel of contact candidate")
+		}
+	}
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+type candidateChannel struct {
+	Channel
+	address string
+
+	mu     sync.Mutex
+	closed bool
+}
+
+func (c *candidateChannel) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.closed = true
+	return nil
+}
+
+func (c *candidateChannel) isClosed() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.closed
+}
+
+type candidateAttempt struct {
+	delay time.Duration
+	err   error
+}
+
+type candidateDialer struct {
+	attempts map[string]candidateAttempt
+
+	mu       sync.Mutex
+	dialed   []string
+	channels []*candidateChannel
+}
+
+func (d *candidateDialer) Dial(ctx context.Context, _, _ identity.Identity, _ string, contactDef ContactDefinition) (Channel, error) {
+	address := contactDef.BrokerAddresses[0]
+	d.mu.Lock()
+	d.dialed = append(d.dialed, address)
+	d.mu.Unlock()
+
+	attempt := d.attempts[address]
+	select {
+	case <-time.After(attempt.delay):
+	case <-ctx.Done():
+		if attempt.err == nil {
+			// simulates channel established despite cancellation
+			break
+		}
+		return nil, ctx.Err()
+	}
+	if attempt.err != nil {
+		return nil, attempt.err
+	}
+
+	channel := &candidateChannel{address: address}
+	d.mu.Lock()
+	d.channels = append(d.channels, channel)
+	d.mu.Unlock()
+	return channel, nil
+}
+
+func (d *candidateDialer) dialedAddresses() []string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]string(nil), d.dialed...)
+}
+
+func candidates(addresses ...string) []ContactDefinition {
+	contacts := make([]ContactDefinition, len(addresses))
+	for i, address := range addresses {
+		contacts[i] = ContactDefinition{BrokerAddresses: []string{address}}
+	}
+	return contacts
+}
+
+func TestDialCandidates_FirstEstablishedWins(t *testing.T) {
+	// given
+	dialer := &candidateDialer{attempts: map[string]candidateAttempt{
+		"slow": {delay: 200 * time.Millisecond},
+		"fast": {delay: 10 * time.Millisecond},
+	}}
+
+	// when
+	channel, err := DialCandidates(context.Background(), dialer, identity.Identity{}, identity.Identity{}, "wireguard", candidates("slow", "fast"), 20*time.Millisecond)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "fast", channel.(*candidateChannel).address)
+	assert.Equal(t, []string{"slow", "fast"}, dialer.dialedAddresses())
+	assert.Eventually(t, func() bool {
+		dialer.mu.Lock()
+		defer dialer.mu.Unlock()
+		return len(dialer.channels) == 2 && !dialer.channels[0].isClosed() && dialer.channels[1].isClosed()
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestDialCandidates_FailureStartsNextCandidate(t *testing.T) {
+	// given
+	dialer := &candidateDialer{attempts: map[string]candidateAttempt{
+		"broken": {err: errors.New("unreachable")},
+		"direct": {},
+		"relay":  {},
+	}}
+
+	// when
+	channel, err := DialCandidates(context.Background(), dialer, identity.Identity{}, identity.Identity{}, "wireguard", candidates("broken", "direct", "relay"), time.Minute)
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, "direct", channel.(*candidateChannel).address)
+	assert.Equal(t, []string{"broken", "direct"}, dialer.dialedAddresses())
+}
+
+func TestDialCandidates_AllFail(t *testing.T) {
+	// given
+	dialErr := errors.New("unreachable")
+	dialer := &candidateDialer{attempts: map[string]candidateAttempt{
+		"first":  {err: dialErr},
+		"second": {err: dialErr},
+	}}
+
+	// when
+	channel, err := DialCandidates(context.Background(), dialer, identity.Identity{}, identity.Identity{}, "wireguard", candidates("first", "second"), time.Millisecond)
+
+	// then
+	assert.Nil(t, channel)
+	assert.True(t, errors.Is(err, dialErr))
+	assert.Len(t, dialer.dialedAddresses(), 2)
+}
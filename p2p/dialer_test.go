@@ -81,7 +81,7 @@ func TestDialer_Exchange_And_Communication_With_Provider(t *testing.T) {
 			brokerConn := nats.StartConnectionMock()
 			defer brokerConn.Close()
 			mockBroker := &mockBroker{conn: brokerConn}
-			portPool := port.NewPool()
+			portPool := port.NewPool(port.NewReservations(nil))
 
 			// Provider starts listening.
 			channelListener := NewListener(brokerConn, signerFactory, verifier, test.ipResolver, test.natProviderPinger, portPool, test.portMapper)
@@ -121,6 +121,15 @@ func natTestPingers(t *testing.T) (providerPinger natProviderPinger, consumerPin
 	return
 }
 
+func TestP2PConnectConfig_TraversalMethod(t *testing.T) {
+	assert.Equal(t, TraversalDirect, (&p2pConnectConfig{peerPorts: []int{1, 2}}).traversalMethod())
+	assert.Equal(t, TraversalHolePunching, (&p2pConnectConfig{peerPorts: []int{1, 2, 3}}).traversalMethod())
+	assert.Equal(t, TraversalPortMapping, (&p2pConnectConfig{
+		peerPorts:        []int{1, 2, 3},
+		upnpPortsRelease: []func(){func() {}},
+	}).traversalMethod())
+}
+
 type mockConsumerNATPinger struct {
 	conns []*net.UDPConn
 }
@@ -152,3 +161,5 @@ type mockPortMapper struct {
 func (m mockPortMapper) Map(protocol string, port int, name string) (release func(), ok bool) {
 	return func() {}, m.enabled
 }
+
+func (m mockPortMapper) Renew() {}
@@ -0,0 +1,38 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import "time"
+
+// AppTopicConnectivityLost represents the topic to which events about lost p2p peer connectivity are published.
+const AppTopicConnectivityLost = "p2p.connectivity_lost"
+
+// AppEventConnectivityLost is published when no traffic was received from the remote peer for longer than dead peer timeout.
+type AppEventConnectivityLost struct {
+	SessionID    string
+	LastActivity time.Time
+}
+
+// AppTopicPeerStalled represents the topic to which events about p2p peers which stopped receiving messages are published.
+const AppTopicPeerStalled = "p2p.peer_stalled"
+
+// AppEventPeerStalled is published when the remote peer does not receive messages sent to it for longer than stall timeout.
+type AppEventPeerStalled struct {
+	SessionID      string
+	ActiveHandlers int
+}
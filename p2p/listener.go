@@ -87,6 +87,19 @@ type p2pConnectConfig struct {
 	privateKey       PrivateKey
 	peerPubKey       PublicKey
 	upnpPortsRelease []func()
+	peerCompression  []string
+}
+
+// traversalMethod returns NAT traversal method used to reach the peer with this config.
+func (c *p2pConnectConfig) traversalMethod() string {
+	switch {
+	case len(c.upnpPortsRelease) > 0:
+		return TraversalPortMapping
+	case len(c.peerPorts) == requiredConnCount:
+		return TraversalDirect
+	default:
+		return TraversalHolePunching
+	}
 }
 
 func (c *p2pConnectConfig) peerIP() string {
@@ -177,6 +190,8 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 
 		channel.setServiceConn(conn2)
 		channel.setUpnpPortsRelease(config.upnpPortsRelease)
+		channel.setTraversalMethod(config.traversalMethod())
+		channel.setCompression(negotiateCompression(config.peerCompression))
 
 		channelHandlers(channel)
 
@@ -197,6 +212,22 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		return func() {}, fmt.Errorf("could not get subscribe to config exchange acknowledge topic: %w", err)
 	}
 
+	// Consumers ping provider before connecting to check it is still listening.
+	pingSub, err := m.brokerConn.Subscribe(pingSubject(providerID, serviceType), func(msg *nats_lib.Msg) {
+		if err := m.brokerConn.Publish(msg.Reply, []byte("OK")); err != nil {
+			log.Err(err).Msg("Could not publish ping reply")
+		}
+	})
+	if err != nil {
+		if err := configSub.Unsubscribe(); err != nil {
+			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
+		}
+		if err := ackSub.Unsubscribe(); err != nil {
+			log.Err(err).Msg("Failed to unsubscribe from config exchange acknowledge topic")
+		}
+		return func() {}, fmt.Errorf("could not get subscribe to ping topic: %w", err)
+	}
+
 	return func() {
 		if err := configSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange topic")
@@ -204,6 +235,9 @@ func (m *listener) Listen(providerID identity.Identity, serviceType string, chan
 		if err := ackSub.Unsubscribe(); err != nil {
 			log.Err(err).Msg("Failed to unsubscribe from config exchange acknowledge topic")
 		}
+		if err := pingSub.Unsubscribe(); err != nil {
+			log.Err(err).Msg("Failed to unsubscribe from ping topic")
+		}
 	}, nil
 }
 
@@ -250,8 +284,9 @@ func (m *listener) providerStartConfigExchange(signerID identity.Identity, msg *
 	})
 
 	config := pb.P2PConnectConfig{
-		PublicIP: publicIP,
-		Ports:    intToInt32Slice(localPorts),
+		PublicIP:    publicIP,
+		Ports:       intToInt32Slice(localPorts),
+		Compression: supportedCompression,
 	}
 	configCiphertext, err := encryptConnConfigMsg(&config, privateKey, peerPubKey)
 	if err != nil {
@@ -355,6 +390,7 @@ func (m *listener) providerAckConfigExchange(msg *nats_lib.Msg) (*p2pConnectConf
 		peerPubKey:       config.peerPubKey,
 		publicIP:         config.publicIP,
 		upnpPortsRelease: config.upnpPortsRelease,
+		peerCompression:  peerConfig.Compression,
 	}, nil
 }
 
@@ -46,6 +46,14 @@ const (
 	TopicSessionStatus = "p2p-session-connectivity-status"
 	// TopicSessionDestroy is a session destroy endpoint for p2p communication.
 	TopicSessionDestroy = "p2p-session-destroy"
+	// TopicSessionReconfigure is an endpoint for pushing updated provider session config to consumer.
+	TopicSessionReconfigure = "p2p-session-reconfigure"
+	// TopicSessionGoingAway is a notification for consumer that provider is shutting down and will end the session.
+	TopicSessionGoingAway = "p2p-session-going-away"
+	// TopicSessionTrafficReport is an endpoint for comparing session traffic counters of consumer and provider.
+	TopicSessionTrafficReport = "p2p-session-traffic-report"
+	// TopicSessionSpeedTest is an endpoint for measuring connection speed between consumer and provider.
+	TopicSessionSpeedTest = "p2p-session-speed-test"
 
 	// TopicPaymentMessage is a payment messages endpoint for p2p communication.
 	TopicPaymentMessage = "p2p-payment-message"
@@ -80,6 +88,8 @@ const (
 	headerFieldTopic     = "Topic"
 	headerStatusCode     = "Status-Code"
 	headerMsg            = "Message"
+	headerEncoding       = "Content-Encoding"
+	headerErrorCode      = "Error-Code"
 
 	statusCodeOK                 = 1
 	statusCodePublicErr          = 2
@@ -94,6 +104,8 @@ type transportMsg struct {
 	statusCode uint64
 	topic      string
 	msg        string
+	encoding   string
+	errorCode  uint64
 
 	// Data field.
 	data []byte
@@ -117,6 +129,12 @@ func (m *transportMsg) readFrom(conn *textproto.Reader) error {
 	m.statusCode = statusCode
 	m.topic = header.Get(headerFieldTopic)
 	m.msg = header.Get(headerMsg)
+	m.encoding = header.Get(headerEncoding)
+	if errorCode := header.Get(headerErrorCode); errorCode != "" {
+		if m.errorCode, err = strconv.ParseUint(errorCode, 10, 64); err != nil {
+			return fmt.Errorf("could not parse error code: %w", err)
+		}
+	}
 
 	// Read data.
 	data, err := conn.ReadDotBytes()
@@ -136,6 +154,12 @@ func (m *transportMsg) writeTo(conn *textproto.Writer) error {
 	header.WriteString(fmt.Sprintf("%s:%s\r\n", headerFieldTopic, m.topic))
 	header.WriteString(fmt.Sprintf("%s:%d\r\n", headerStatusCode, m.statusCode))
 	header.WriteString(fmt.Sprintf("%s:%s\r\n", headerMsg, m.msg))
+	if m.encoding != "" {
+		header.WriteString(fmt.Sprintf("%s:%s\r\n", headerEncoding, m.encoding))
+	}
+	if m.errorCode != 0 {
+		header.WriteString(fmt.Sprintf("%s:%d\r\n", headerErrorCode, m.errorCode))
+	}
 	header.WriteByte('\n')
 	w.Write(header.Bytes())
 	w.Write(m.data)
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// ErrBrokerUnreachable indicates that none of the brokers from provider contacts could be connected to.
+var ErrBrokerUnreachable = errors.New("could not open broker conn")
+
+// ProviderPinger checks whether provider listens for p2p connections without establishing a channel.
+type ProviderPinger interface {
+	// PingProvider sends a ping to provider via broker and returns the round trip time.
+	PingProvider(ctx context.Context, providerID identity.Identity, serviceType string, contactDef ContactDefinition) (time.Duration, error)
+}
+
+// NewProviderPinger creates new provider pinger which is used on consumer side.
+func NewProviderPinger(broker brokerConnector) ProviderPinger {
+	return &providerPinger{broker: broker}
+}
+
+type providerPinger struct {
+	broker brokerConnector
+}
+
+// PingProvider sends a ping to provider via broker and returns the round trip time.
+func (p *providerPinger) PingProvider(ctx context.Context, providerID identity.Identity, serviceType string, contactDef ContactDefinition) (time.Duration, error) {
+	brokerConn, err := p.broker.Connect(contactDef.BrokerAddresses...)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrBrokerUnreachable, err)
+	}
+	defer brokerConn.Close()
+
+	started := time.Now()
+	if _, err := brokerConn.RequestWithContext(ctx, pingSubject(providerID, serviceType), nil); err != nil {
+		return 0, fmt.Errorf("provider did not reply to ping: %w", err)
+	}
+	return time.Since(started), nil
+}
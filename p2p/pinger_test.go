@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/communication/nats"
+	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProviderPinger_PingProvider(t *testing.T) {
+	providerID := identity.FromAddress("0x1")
+	signerFactory := func(id identity.Identity) identity.Signer {
+		return &identity.SignerFake{}
+	}
+	brokerConn := nats.StartConnectionMock()
+	defer brokerConn.Close()
+
+	channelListener := NewListener(brokerConn, signerFactory, &identity.VerifierFake{}, ip.NewResolverMock("127.0.0.1"), &mockProviderNATPinger{}, port.NewPool(port.NewReservations(nil)), &mockPortMapper{})
+	stop, err := channelListener.Listen(providerID, "wireguard", func(ch Channel) {})
+	assert.NoError(t, err)
+
+	pinger := NewProviderPinger(&mockBroker{conn: brokerConn})
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	_, err = pinger.PingProvider(ctx, providerID, "wireguard", ContactDefinition{BrokerAddresses: []string{"broker"}})
+	assert.NoError(t, err)
+
+	stop()
+	ctx, cancel = context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = pinger.PingProvider(ctx, providerID, "wireguard", ContactDefinition{BrokerAddresses: []string{"broker"}})
+	assert.Error(t, err)
+	assert.False(t, errors.Is(err, ErrBrokerUnreachable))
+}
+
+func TestProviderPinger_PingProvider_BrokerUnreachable(t *testing.T) {
+	pinger := NewProviderPinger(&unreachableBroker{})
+
+	_, err := pinger.PingProvider(context.Background(), identity.FromAddress("0x1"), "wireguard", ContactDefinition{BrokerAddresses: []string{"broker"}})
+	assert.True(t, errors.Is(err, ErrBrokerUnreachable))
+}
+
+type unreachableBroker struct{}
+
+func (b *unreachableBroker) Connect(serverURIs ...string) (nats.Connection, error) {
+	return nil, errors.New("no servers available for connection")
+}
@@ -34,6 +34,7 @@ func NewConnection() (connection.Connection, error) {
 
 // Connection which does no real tunneling
 type Connection struct {
+	runningMu      sync.Mutex
 	isRunning      bool
 	noopConnection sync.WaitGroup
 	stateCh        chan connection.State
@@ -54,7 +55,7 @@ func (c *Connection) Statistics() (connection.Statistics, error) {
 // Start implements the connection.Connection interface
 func (c *Connection) Start(ctx context.Context, params connection.ConnectOptions) error {
 	c.noopConnection.Add(1)
-	c.isRunning = true
+	c.setRunning(true)
 
 	c.stateCh <- connection.Connecting
 
@@ -65,7 +66,7 @@ func (c *Connection) Start(ctx context.Context, params connection.ConnectOptions
 
 // Wait implements the connection.Connection interface
 func (c *Connection) Wait() error {
-	if c.isRunning {
+	if c.running() {
 		c.noopConnection.Wait()
 	}
 	return nil
@@ -73,11 +74,14 @@ func (c *Connection) Wait() error {
 
 // Stop implements the connection.Connection interface
 func (c *Connection) Stop() {
+	c.runningMu.Lock()
 	if !c.isRunning {
+		c.runningMu.Unlock()
 		return
 	}
-
 	c.isRunning = false
+	c.runningMu.Unlock()
+
 	c.stateCh <- connection.Disconnecting
 	time.Sleep(2 * time.Second)
 	c.stateCh <- connection.NotConnected
@@ -89,3 +93,17 @@ func (c *Connection) Stop() {
 func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
 	return nil, nil
 }
+
+func (c *Connection) running() bool {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+
+	return c.isRunning
+}
+
+func (c *Connection) setRunning(running bool) {
+	c.runningMu.Lock()
+	defer c.runningMu.Unlock()
+
+	c.isRunning = running
+}
@@ -28,9 +28,35 @@ const ServiceType = "noop"
 type ServiceDefinition struct {
 	// Approximate information on location where the service is provided from
 	Location market.Location `json:"location"`
+
+	// Public self-description of the provider
+	Description *market.ProviderDescription `json:"description,omitempty"`
 }
 
 // GetLocation returns geographic location of service definition provider
 func (service ServiceDefinition) GetLocation() market.Location {
 	return service.Location
 }
+
+// WithLocation returns a copy of service definition provided from the given location
+func (service ServiceDefinition) WithLocation(location market.Location) market.ServiceDefinition {
+	service.Location = location
+	return service
+}
+
+// GetDescription returns self-description of service definition provider
+func (service ServiceDefinition) GetDescription() market.ProviderDescription {
+	if service.Description == nil {
+		return market.ProviderDescription{}
+	}
+	return *service.Description
+}
+
+// WithDescription returns a copy of service definition carrying the given provider self-description
+func (service ServiceDefinition) WithDescription(description market.ProviderDescription) market.ServiceDefinition {
+	service.Description = nil
+	if !description.IsEmpty() {
+		service.Description = &description
+	}
+	return service
+}
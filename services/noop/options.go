@@ -19,16 +19,79 @@ package noop
 
 import (
 	"encoding/json"
+	"time"
 
+	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
 )
 
+// Options describes options which are used by Noop service.
+type Options struct {
+	// TrafficRate is a rate in bytes per second of fake traffic reported for every session, zero disables it.
+	TrafficRate uint64
+	// StatsInterval is an interval of fake traffic statistics reporting.
+	StatsInterval time.Duration
+}
+
+// DefaultOptions is a Noop service configuration that will be used if no options provided.
+var DefaultOptions = Options{
+	StatsInterval: time.Second,
+}
+
 // GetOptions returns effective Noop service options from application configuration.
-func GetOptions() service.Options {
-	return nil
+func GetOptions() Options {
+	options := Options{
+		TrafficRate:   config.GetUInt64(config.FlagNoopTrafficRate),
+		StatsInterval: config.GetDuration(config.FlagNoopStatsInterval),
+	}
+	if options.StatsInterval <= 0 {
+		options.StatsInterval = DefaultOptions.StatsInterval
+	}
+	return options
 }
 
 // ParseJSONOptions function fills in Noop options from JSON request
-func ParseJSONOptions(_ *json.RawMessage) (service.Options, error) {
-	return nil, nil
+func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
+	var requestOptions = GetOptions()
+	if request == nil {
+		return requestOptions, nil
+	}
+
+	opts := DefaultOptions
+	err := json.Unmarshal(*request, &opts)
+	return opts, err
+}
+
+// MarshalJSON implements json.Marshaler interface to provide human readable configuration.
+func (o Options) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&struct {
+		TrafficRate   uint64 `json:"traffic_rate"`
+		StatsInterval string `json:"stats_interval"`
+	}{
+		TrafficRate:   o.TrafficRate,
+		StatsInterval: o.StatsInterval.String(),
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
+func (o *Options) UnmarshalJSON(data []byte) error {
+	var options struct {
+		TrafficRate   uint64 `json:"traffic_rate"`
+		StatsInterval string `json:"stats_interval"`
+	}
+
+	if err := json.Unmarshal(data, &options); err != nil {
+		return err
+	}
+
+	o.TrafficRate = options.TrafficRate
+	if options.StatsInterval != "" {
+		d, err := time.ParseDuration(options.StatsInterval)
+		if err != nil {
+			return err
+		}
+		o.StatsInterval = d
+	}
+
+	return nil
 }
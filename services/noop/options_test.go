@@ -20,6 +20,7 @@ package noop
 import (
 	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -28,13 +29,27 @@ func Test_ParseJSONOptions_HandlesNil(t *testing.T) {
 	options, err := ParseJSONOptions(nil)
 
 	assert.NoError(t, err)
-	assert.Equal(t, nil, options)
+	assert.Equal(t, DefaultOptions, options)
 }
 
 func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
-	request := json.RawMessage(`{"port": 1123}`)
+	request := json.RawMessage(`{"traffic_rate": 1024, "stats_interval": "100ms"}`)
 	options, err := ParseJSONOptions(&request)
 
 	assert.NoError(t, err)
-	assert.Equal(t, nil, options)
+	assert.Equal(t, Options{TrafficRate: 1024, StatsInterval: 100 * time.Millisecond}, options)
+}
+
+func Test_ParseJSONOptions_InvalidInterval(t *testing.T) {
+	request := json.RawMessage(`{"stats_interval": "often"}`)
+	_, err := ParseJSONOptions(&request)
+
+	assert.Error(t, err)
+}
+
+func Test_Options_MarshalJSON(t *testing.T) {
+	data, err := json.Marshal(Options{TrafficRate: 1024, StatsInterval: time.Second})
+
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"traffic_rate": 1024, "stats_interval": "1s"}`, string(data))
 }
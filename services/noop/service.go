@@ -24,6 +24,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
@@ -33,18 +34,34 @@ import (
 var ErrAlreadyStarted = errors.New("service already started")
 
 // NewManager creates new instance of Noop service
-func NewManager() *Manager {
-	return &Manager{}
+func NewManager(options Options, bus eventbus.Publisher) *Manager {
+	return &Manager{
+		options: options,
+		bus:     bus,
+	}
 }
 
 // Manager represents entrypoint for Noop service
 type Manager struct {
 	process sync.WaitGroup
+	options Options
+	bus     eventbus.Publisher
 }
 
 // ProvideConfig provides the session configuration
-func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPConn) (*service.ConfigParams, error) {
-	return &service.ConfigParams{}, nil
+func (manager *Manager) ProvideConfig(sessionID string, _ json.RawMessage, _ *net.UDPConn) (*service.ConfigParams, error) {
+	if manager.options.TrafficRate == 0 {
+		return &service.ConfigParams{}, nil
+	}
+
+	interval := manager.options.StatsInterval
+	if interval <= 0 {
+		interval = DefaultOptions.StatsInterval
+	}
+	traffic := newTrafficGenerator(manager.bus, manager.options.TrafficRate, interval)
+	go traffic.start(sessionID)
+
+	return &service.ConfigParams{SessionDestroyCallback: traffic.stop}, nil
 }
 
 // Serve starts service - does block
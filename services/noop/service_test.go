@@ -24,10 +24,12 @@ import (
 	"github.com/mysteriumnetwork/node/core/location"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/event"
 	"github.com/stretchr/testify/assert"
 )
 
-var _ service.Service = NewManager()
+var _ service.Service = NewManager(DefaultOptions, mocks.NewEventBus())
 
 func Test_GetProposal(t *testing.T) {
 	country := "LT"
@@ -44,15 +46,33 @@ func Test_GetProposal(t *testing.T) {
 }
 
 func Test_Manager_ProvideConfig(t *testing.T) {
-	manager := NewManager()
+	manager := NewManager(DefaultOptions, mocks.NewEventBus())
 	sessionConfig, err := manager.ProvideConfig("", nil, nil)
 	assert.NoError(t, err)
 	assert.Nil(t, sessionConfig.SessionServiceConfig)
 	assert.Nil(t, sessionConfig.SessionDestroyCallback)
 }
 
+func Test_Manager_ProvideConfig_GeneratesTraffic(t *testing.T) {
+	bus := mocks.NewEventBus()
+	manager := NewManager(Options{TrafficRate: 1000, StatsInterval: 10 * time.Millisecond}, bus)
+	sessionConfig, err := manager.ProvideConfig("session-id", nil, nil)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		e, ok := bus.Pop().(event.AppEventDataTransferred)
+		return ok && e.ID == "session-id" && e.Up > 0
+	}, time.Second, 10*time.Millisecond)
+
+	sessionConfig.SessionDestroyCallback()
+	time.Sleep(20 * time.Millisecond)
+	bus.Clear()
+	time.Sleep(20 * time.Millisecond)
+	assert.Empty(t, bus.GetEventHistory())
+}
+
 func Test_Manager_Serve_Stop(t *testing.T) {
-	manager := NewManager()
+	manager := NewManager(DefaultOptions, mocks.NewEventBus())
 	go func() {
 		err := manager.Serve(&service.Instance{})
 		assert.NoError(t, err)
@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package noop
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/session/event"
+)
+
+// trafficGenerator reports fake traffic statistics of a session at the configured rate.
+type trafficGenerator struct {
+	done     chan struct{}
+	bus      eventbus.Publisher
+	rate     uint64
+	interval time.Duration
+}
+
+func newTrafficGenerator(bus eventbus.Publisher, rate uint64, interval time.Duration) *trafficGenerator {
+	return &trafficGenerator{
+		done:     make(chan struct{}),
+		bus:      bus,
+		rate:     rate,
+		interval: interval,
+	}
+}
+
+func (g *trafficGenerator) start(sessionID string) {
+	ticker := time.NewTicker(g.interval)
+	defer ticker.Stop()
+
+	started := time.Now()
+	for {
+		select {
+		case <-ticker.C:
+			g.bus.Publish(event.AppTopicDataTransferred, event.AppEventDataTransferred{
+				ID: sessionID,
+				Up: uint64(time.Since(started).Seconds() * float64(g.rate)),
+			})
+		case <-g.done:
+			return
+		}
+	}
+}
+
+func (g *trafficGenerator) stop() {
+	close(g.done)
+}
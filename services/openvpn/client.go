@@ -20,6 +20,7 @@ package openvpn
 import (
 	"context"
 	"encoding/json"
+	"net"
 	"sync"
 	"time"
 
@@ -30,9 +31,11 @@ import (
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/middlewares/state"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/obfuscation"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
@@ -40,6 +43,9 @@ import (
 // ErrProcessNotStarted represents the error we return when the process is not started yet
 var ErrProcessNotStarted = errors.New("process not started yet")
 
+// ErrRoutingTableNotSupported represents error when isolated routing table is requested for openvpn connection
+var ErrRoutingTableNotSupported = errors.New("isolated routing table is not supported by openvpn connection")
+
 // processFactory creates a new openvpn process
 type processFactory func(options connection.ConnectOptions, sessionConfig VPNConfig) (openvpn.Process, *ClientConfig, error)
 
@@ -47,16 +53,18 @@ type processFactory func(options connection.ConnectOptions, sessionConfig VPNCon
 func NewClient(openvpnBinary, scriptDir, runtimeDir string,
 	signerFactory identity.SignerFactory,
 	ipResolver ip.Resolver,
+	requestedObfuscation string,
 ) (connection.Connection, error) {
 
 	stateCh := make(chan connection.State, 100)
 	client := &Client{
-		scriptDir:           scriptDir,
-		runtimeDir:          runtimeDir,
-		signerFactory:       signerFactory,
-		stateCh:             stateCh,
-		ipResolver:          ipResolver,
-		removeAllowedIPRule: func() {},
+		scriptDir:            scriptDir,
+		runtimeDir:           runtimeDir,
+		signerFactory:        signerFactory,
+		stateCh:              stateCh,
+		ipResolver:           ipResolver,
+		requestedObfuscation: requestedObfuscation,
+		removeAllowedIPRule:  func() {},
 	}
 
 	procFactory := func(options connection.ConnectOptions, sessionConfig VPNConfig) (openvpn.Process, *ClientConfig, error) {
@@ -80,17 +88,24 @@ func NewClient(openvpnBinary, scriptDir, runtimeDir string,
 
 // Client takes in the openvpn process and works with it
 type Client struct {
-	scriptDir           string
-	runtimeDir          string
-	signerFactory       identity.SignerFactory
-	stateCh             chan connection.State
-	stats               connection.Statistics
-	statsMu             sync.RWMutex
-	process             openvpn.Process
-	processFactory      processFactory
-	ipResolver          ip.Resolver
-	removeAllowedIPRule func()
-	stopOnce            sync.Once
+	scriptDir            string
+	runtimeDir           string
+	signerFactory        identity.SignerFactory
+	stateCh              chan connection.State
+	stats                connection.Statistics
+	statsMu              sync.RWMutex
+	process              openvpn.Process
+	processFactory       processFactory
+	ipResolver           ip.Resolver
+	requestedObfuscation string
+	obfuscationProxy     *obfuscation.Proxy
+	excludedIP           net.IP
+	removeAllowedIPRule  func()
+	stopOnce             sync.Once
+
+	// sessionConfig and options are kept as negotiated with provider, to export session profile.
+	sessionConfig *VPNConfig
+	options       connection.ConnectOptions
 }
 
 var _ connection.Connection = &Client{}
@@ -111,17 +126,34 @@ func (c *Client) Statistics() (connection.Statistics, error) {
 func (c *Client) Start(ctx context.Context, options connection.ConnectOptions) error {
 	log.Info().Msg("Starting connection")
 
+	if options.Params.RoutingTable > 0 {
+		return ErrRoutingTableNotSupported
+	}
+	if options.PacketObserver != nil {
+		log.Warn().Msg("Traffic breakdown is not supported by openvpn connection, connecting without it")
+	}
+
 	sessionConfig := VPNConfig{}
 	err := json.Unmarshal(options.SessionConfig, &sessionConfig)
 	if err != nil {
 		return errors.Wrap(err, "failed to unmarshal session config")
 	}
+	negotiatedConfig := sessionConfig
+	c.sessionConfig = &negotiatedConfig
+	c.options = options
 
 	c.removeAllowedIPRule, err = firewall.AllowIPAccess(sessionConfig.RemoteIP)
 	if err != nil {
 		return errors.Wrap(err, "failed to add allowed IP address")
 	}
 
+	if sessionConfig.Obfuscation != nil {
+		if err := c.startObfuscation(&sessionConfig, options.ProviderNATConn); err != nil {
+			c.Stop()
+			return errors.Wrap(err, "could not start obfuscation proxy")
+		}
+	}
+
 	proc, clientConfig, err := c.processFactory(options, sessionConfig)
 	if err != nil {
 		log.Info().Err(err).Msg("Client config factory error")
@@ -137,6 +169,48 @@ func (c *Client) Start(ctx context.Context, options connection.ConnectOptions) e
 	return errors.Wrap(err, "failed to start client process")
 }
 
+// startObfuscation points OpenVPN to the local obfuscation proxy, which relays its traffic to the provider.
+func (c *Client) startObfuscation(sessionConfig *VPNConfig, natConn *net.UDPConn) error {
+	log.Info().Msgf("Obfuscating connection traffic with %s", sessionConfig.Obfuscation.Name)
+	localPort := sessionConfig.LocalPort
+	remote := &net.UDPAddr{IP: net.ParseIP(sessionConfig.RemoteIP), Port: sessionConfig.RemotePort}
+	if natConn != nil {
+		natConn.Close()
+		localPort = natConn.LocalAddr().(*net.UDPAddr).Port
+		remote = natConn.RemoteAddr().(*net.UDPAddr)
+	}
+
+	proxy, err := obfuscation.NewConsumerProxy(*sessionConfig.Obfuscation, localPort, remote)
+	if err != nil {
+		return err
+	}
+	c.obfuscationProxy = proxy
+
+	// OpenVPN remote no longer points to the provider, so its route has to be excluded here.
+	if err := netutil.ExcludeRoute(remote.IP); err != nil {
+		return err
+	}
+	c.excludedIP = remote.IP
+	sessionConfig.RemoteIP = proxy.PlainAddr().IP.String()
+	sessionConfig.RemotePort = proxy.PlainAddr().Port
+	sessionConfig.LocalPort = 0
+	return nil
+}
+
+// Profile exports the session as a standalone .ovpn profile, signed with consumer credentials of the session.
+func (c *Client) Profile() ([]byte, error) {
+	if c.sessionConfig == nil {
+		return nil, ErrProcessNotStarted
+	}
+
+	credentials := SignatureCredentialsProvider(c.options.SessionID, c.signerFactory(c.options.ConsumerID))
+	username, password, err := credentials()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign session credentials")
+	}
+	return NewProfile(*c.sessionConfig, username, password)
+}
+
 // Wait waits for the connection to exit
 func (c *Client) Wait() error {
 	if c.process == nil {
@@ -151,6 +225,16 @@ func (c *Client) Stop() {
 		if c.process != nil {
 			c.process.Stop()
 		}
+		if c.obfuscationProxy != nil {
+			if err := c.obfuscationProxy.Close(); err != nil {
+				log.Error().Err(err).Msg("Failed to close obfuscation proxy")
+			}
+		}
+		if c.excludedIP != nil {
+			if err := netutil.DeleteExcludedRoute(c.excludedIP); err != nil {
+				log.Error().Err(err).Msg("Failed to delete excluded route of provider")
+			}
+		}
 		c.removeAllowedIPRule()
 	})
 }
@@ -167,10 +251,10 @@ func (c *Client) OnStats(cnt openvpn_bytescount.Bytecount) error {
 
 // GetConfig returns the consumer-side configuration.
 func (c *Client) GetConfig() (connection.ConsumerConfig, error) {
-	return &ConsumerConfig{}, nil
+	return &ConsumerConfig{Obfuscation: c.requestedObfuscation}, nil
 }
 
-//VPNConfig structure represents VPN configuration options for given session
+// VPNConfig structure represents VPN configuration options for given session
 type VPNConfig struct {
 	DNSIPs          string `json:"dns_ips"`
 	RemoteIP        string `json:"remote"`
@@ -180,6 +264,10 @@ type VPNConfig struct {
 	RemoteProtocol  string `json:"protocol"`
 	TLSPresharedKey string `json:"TLSPresharedKey"`
 	CACertificate   string `json:"CACertificate"`
+	// Obfuscation is set when provider agreed to obfuscate the session traffic.
+	Obfuscation *obfuscation.Config `json:"obfuscation,omitempty"`
+	// MTU of the path between provider and consumer, zero if provider did not discover it.
+	MTU int `json:"mtu,omitempty"`
 }
 
 func newAuthMiddleware(sessionID session.ID, signer identity.Signer) management.Middleware {
@@ -23,6 +23,7 @@ import (
 
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/config"
 	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/mtu"
 )
 
 // ClientConfig represents specific "openvpn as client" configuration
@@ -91,7 +92,7 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, scriptDir string, runtimeDi
 	}
 
 	clientFileConfig := newClientConfig(runtimeDir, scriptDir)
-	dnsIPs, err := options.Params.DNS.ResolveIPs(vpnConfig.DNSIPs)
+	dnsIPs, err := options.ResolveDNS(vpnConfig.DNSIPs)
 	if err != nil {
 		return nil, err
 	}
@@ -115,6 +116,10 @@ func NewClientConfigFromSession(vpnConfig VPNConfig, scriptDir string, runtimeDi
 	clientFileConfig.SetProtocol(vpnConfig.RemoteProtocol)
 	clientFileConfig.SetTLSCACertificate(vpnConfig.CACertificate)
 	clientFileConfig.SetTLSCrypt(vpnConfig.TLSPresharedKey)
+	if vpnConfig.MTU > 0 {
+		// Limits tunnelled TCP segments, so that encapsulated packets fit the path unfragmented.
+		clientFileConfig.SetParam("mssfix", strconv.Itoa(vpnConfig.MTU-mtu.UDPOverhead))
+	}
 
 	return clientFileConfig, nil
 }
@@ -30,7 +30,11 @@ var (
 	locationUS = market.Location{
 		Country: "US",
 	}
-	protocol = "tcp"
+	protocol    = "tcp"
+	description = market.ProviderDescription{
+		Nickname: "Exit",
+		Tags:     []string{"streaming-friendly"},
+	}
 )
 
 func TestServiceDefinitionSerialize(t *testing.T) {
@@ -56,6 +60,25 @@ func TestServiceDefinitionSerialize(t *testing.T) {
 				"protocol": "tcp"
 			}`,
 		},
+		{
+			ServiceDefinition{
+				Location:          locationUS,
+				LocationOriginate: locationUS,
+				Description:       &description,
+			},
+			`{
+				"location": {
+					"country": "US"
+				},
+				"location_originate": {
+					"country": "US"
+				},
+				"description": {
+					"nickname": "Exit",
+					"tags": ["streaming-friendly"]
+				}
+			}`,
+		},
 		{
 			ServiceDefinition{},
 			`{
@@ -98,6 +121,20 @@ func TestServiceDefinitionUnserialize(t *testing.T) {
 			},
 			nil,
 		},
+		{
+			`{
+				"location": {},
+				"location_originate": {},
+				"description": {
+					"nickname": "Exit",
+					"tags": ["streaming-friendly"]
+				}
+			}`,
+			ServiceDefinition{
+				Description: &description,
+			},
+			nil,
+		},
 		{
 			`{
 				"location": {},
@@ -121,3 +158,12 @@ func TestServiceDefinitionUnserialize(t *testing.T) {
 		assert.Equal(t, err, test.expectedError)
 	}
 }
+
+func TestServiceDefinitionWithDescription(t *testing.T) {
+	definition := ServiceDefinition{Location: locationUS}.WithDescription(description)
+	assert.Equal(t, ServiceDefinition{Location: locationUS, Description: &description}, definition)
+	assert.Equal(t, description, definition.(ServiceDefinition).GetDescription())
+
+	definition = definition.(ServiceDefinition).WithDescription(market.ProviderDescription{})
+	assert.Equal(t, ServiceDefinition{Location: locationUS}, definition)
+}
@@ -29,6 +29,7 @@ import (
 func NewServiceProposalWithLocation(
 	loc location.Location,
 	protocol string,
+	obfuscators []string,
 ) market.ServiceProposal {
 	serviceLocation := market.Location{
 		Continent: loc.Continent,
@@ -46,6 +47,7 @@ func NewServiceProposalWithLocation(
 			LocationOriginate: serviceLocation,
 			SessionBandwidth:  dto.Bandwidth(10 * datasize.MiB),
 			Protocol:          protocol,
+			Obfuscators:       obfuscators,
 		},
 	}
 }
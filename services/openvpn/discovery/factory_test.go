@@ -39,7 +39,7 @@ var (
 )
 
 func Test_NewServiceProposalWithLocation(t *testing.T) {
-	proposal := NewServiceProposalWithLocation(locationLTTelia, protocol)
+	proposal := NewServiceProposalWithLocation(locationLTTelia, protocol, nil)
 
 	assert.Exactly(
 		t,
@@ -24,4 +24,6 @@ const AuthSignaturePrefix = "MystVpnSessionId:"
 type ConsumerConfig struct {
 	IP    string `json:"Ip,omitempty"`
 	Ports []int  `json:"Ports,omitempty"`
+	// Obfuscation is the obfuscator requested by consumer.
+	Obfuscation string `json:"Obfuscation,omitempty"`
 }
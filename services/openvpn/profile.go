@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package openvpn
+
+import (
+	"strconv"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/config"
+)
+
+// NewProfile renders a standalone .ovpn profile for the given session config, so that external devices can connect
+// to the provider. Credentials are inlined when given, otherwise client is prompted for them.
+func NewProfile(vpnConfig VPNConfig, username, password string) ([]byte, error) {
+	if err := NewDefaultValidator().IsValid(vpnConfig); err != nil {
+		return nil, err
+	}
+
+	vpnConfig, err := FormatTLSPresharedKey(vpnConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	profile := defaultClientConfig("", "")
+	profile.SetFlag("client")
+	profile.SetParam("remote", vpnConfig.RemoteIP, strconv.Itoa(vpnConfig.RemotePort))
+	profile.SetFlag("nobind")
+	profile.SetParam("remote-cert-ku", "84")
+	profile.SetProtocol(vpnConfig.RemoteProtocol)
+	if vpnConfig.DNSIPs != "" {
+		profile.SetParam("dhcp-option", "DNS", vpnConfig.DNSIPs)
+	}
+	profile.SetTLSCACertificate(vpnConfig.CACertificate)
+	profile.SetTLSCrypt(vpnConfig.TLSPresharedKey)
+	if username != "" {
+		profile.AddOptions(config.OptionFile("auth-user-pass", username+"\n"+password, ""))
+	} else {
+		profile.SetFlag("auth-user-pass")
+	}
+
+	content, err := profile.ToConfigFileContent()
+	if err != nil {
+		return nil, err
+	}
+	return []byte(content), nil
+}
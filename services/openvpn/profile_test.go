@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package openvpn
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProfile(t *testing.T) {
+	vpnConfig := VPNConfig{
+		DNSIPs:          "10.8.0.1",
+		RemoteIP:        "1.2.3.4",
+		RemotePort:      10999,
+		RemoteProtocol:  "udp",
+		TLSPresharedKey: tlsTestKey,
+		CACertificate:   caCertificate,
+	}
+
+	profile, err := NewProfile(vpnConfig, "session-id", "signature")
+	assert.NoError(t, err)
+
+	content := string(profile)
+	assert.Contains(t, content, "client\n")
+	assert.Contains(t, content, "remote 1.2.3.4 10999\n")
+	assert.Contains(t, content, "dhcp-option DNS 10.8.0.1\n")
+	assert.Contains(t, content, "<ca>\n"+caCertificate+"\n</ca>\n")
+	assert.Contains(t, content, "<tls-crypt>\n"+tlsTestKeyPreformatted+"\n</tls-crypt>\n")
+	assert.Contains(t, content, "<auth-user-pass>\nsession-id\nsignature\n</auth-user-pass>\n")
+}
+
+func TestNewProfileWithoutCredentials(t *testing.T) {
+	vpnConfig := VPNConfig{
+		RemoteIP:        "1.2.3.4",
+		RemotePort:      10999,
+		RemoteProtocol:  "tcp",
+		TLSPresharedKey: tlsTestKey,
+		CACertificate:   caCertificate,
+	}
+
+	profile, err := NewProfile(vpnConfig, "", "")
+	assert.NoError(t, err)
+
+	content := string(profile)
+	assert.Contains(t, content, "proto tcp-client\n")
+	assert.Contains(t, content, "auth-user-pass\n")
+	assert.NotContains(t, content, "<auth-user-pass>")
+	assert.NotContains(t, content, "dhcp-option")
+}
+
+func TestNewProfileErrorsOnInvalidConfig(t *testing.T) {
+	_, err := NewProfile(VPNConfig{RemoteIP: "1.2.3.4"}, "", "")
+	assert.Error(t, err)
+}
@@ -57,6 +57,14 @@ func NewManager(nodeOptions node.Options,
 	}
 }
 
+// Obfuscators returns obfuscators offered by the service, only datagram transport can be obfuscated.
+func Obfuscators(nodeOptions node.Options, serviceOptions Options) []string {
+	if serviceOptions.Protocol != "udp" {
+		return nil
+	}
+	return nodeOptions.Obfuscation.Offer
+}
+
 func vpnServerIP(outboundIP, publicIP string, isLocalnet bool) string {
 	if publicIP == outboundIP {
 		return publicIP
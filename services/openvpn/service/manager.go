@@ -29,7 +29,9 @@ import (
 	"github.com/mysteriumnetwork/go-openvpn/openvpn/tls"
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/mtu"
 	"github.com/mysteriumnetwork/node/core/node"
+	"github.com/mysteriumnetwork/node/core/obfuscation"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/shaper"
@@ -37,6 +39,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/logconfig"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/nat"
 	nat_event "github.com/mysteriumnetwork/node/nat/event"
@@ -66,6 +69,7 @@ type Manager struct {
 	vpnNetwork      net.IPNet
 	vpnServerPort   int
 	openvpnProcess  openvpn.Process
+	openvpnPID      pidTracker
 	openvpnClients  *clientMap
 	openvpnAuth     *authHandler
 	ipResolver      ip.Resolver
@@ -87,7 +91,7 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 	}
 
 	var dnsPort = 11153
-	dnsHandler, err := dns.ResolveViaSystem()
+	dnsHandler, err := dns.Resolve(m.nodeOptions.DNSFilter)
 	if err == nil {
 		if instance.Policies().HasDNSRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
@@ -117,6 +121,11 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 	if err != nil {
 		return fmt.Errorf("failed to acquire an unused port: %w", err)
 	}
+	defer func() {
+		if err := m.ports.Release(servicePort); err != nil {
+			log.Warn().Err(err).Msgf("Failed to release OpenVPN port %d", servicePort)
+		}
+	}()
 	m.vpnServerPort = servicePort.Num()
 
 	m.outboundIP, err = m.ipResolver.GetOutboundIP()
@@ -149,6 +158,7 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 		EnableDNSRedirect: m.dnsOK,
 		DNSIP:             m.dnsIP,
 		DNSPort:           dnsPort,
+		BlockedPorts:      m.serviceOptions.BlockedPorts,
 	}); err != nil {
 		return fmt.Errorf("failed to setup NAT/firewall rules: %w", err)
 	}
@@ -164,6 +174,23 @@ func (m *Manager) Serve(instance *service.Instance) (err error) {
 	return m.openvpnProcess.Wait()
 }
 
+// BlockedEgressAttempts returns how many packets to blocked destination ports consumers have sent.
+func (m *Manager) BlockedEgressAttempts() uint64 {
+	blocked, err := m.natService.BlockedAttempts(m.vpnNetwork)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read blocked port counters")
+	}
+	return blocked
+}
+
+// PIDs returns PID of the openvpn server process, so its resource usage can be sampled.
+func (m *Manager) PIDs() []int {
+	if pid := m.openvpnPID.PID(); pid > 0 {
+		return []int{pid}
+	}
+	return nil
+}
+
 // Stop stops service
 func (m *Manager) Stop() error {
 	if m.openvpnProcess != nil {
@@ -179,8 +206,34 @@ func (m *Manager) Stop() error {
 	return nil
 }
 
+// Profile exports a standalone .ovpn profile of the service. It carries no credentials,
+// external client has to log in with session ID and consumer signature of an established session.
+func (m *Manager) Profile() ([]byte, error) {
+	if m.vpnServerPort == 0 {
+		return nil, errors.New("service port not initialized")
+	}
+
+	publicIP, err := m.ipResolver.GetPublicIP()
+	if err != nil {
+		return nil, fmt.Errorf("could not get public IP: %w", err)
+	}
+
+	vpnConfig := openvpn_service.VPNConfig{
+		RemoteIP:        vpnServerIP(m.outboundIP, publicIP, m.nodeOptions.OptionsNetwork.Localnet),
+		RemotePort:      m.vpnServerPort,
+		RemoteProtocol:  m.serviceOptions.Protocol,
+		TLSPresharedKey: m.tlsPrimitives.PresharedKey.ToPEMFormat(),
+		CACertificate:   m.tlsPrimitives.CertificateAuthority.ToPEMFormat(),
+	}
+	if m.dnsOK {
+		vpnConfig.DNSIPs = m.dnsIP.String()
+	}
+	return openvpn_service.NewProfile(vpnConfig, "", "")
+}
+
 // ProvideConfig takes session creation config from end consumer and provides the service configuration to the end consumer
 func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, conn *net.UDPConn) (*service.ConfigParams, error) {
+	logger := logconfig.SessionLogger(sessionID, "")
 	if m.vpnServerPort == 0 {
 		return nil, errors.New("service port not initialized")
 	}
@@ -201,18 +254,46 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	if m.dnsOK {
 		vpnConfig.DNSIPs = m.dnsIP.String()
 	}
+	if remote, ok := conn.RemoteAddr().(*net.UDPAddr); ok {
+		if vpnConfig.MTU, err = mtu.Discover(remote); err != nil {
+			logger.Warn().Err(err).Msg("Could not discover path MTU, using the default one")
+		}
+	}
+
+	var consumerConfig openvpn_service.ConsumerConfig
+	if len(sessionConfig) > 0 {
+		if err := json.Unmarshal(sessionConfig, &consumerConfig); err != nil {
+			return nil, fmt.Errorf("could not unmarshal consumer config: %w", err)
+		}
+	}
+	vpnConfig.Obfuscation, err = obfuscation.Negotiate(consumerConfig.Obfuscation, Obfuscators(m.nodeOptions, m.serviceOptions))
+	if err != nil {
+		logger.Warn().Err(err).Msg("Session traffic will not be obfuscated")
+	}
 
-	if err := proxyOpenVPN(conn, m.vpnServerPort); err != nil {
+	var proxy *obfuscation.Proxy
+	if vpnConfig.Obfuscation != nil {
+		proxy, err = obfuscation.NewProviderProxy(*vpnConfig.Obfuscation, conn, &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: m.vpnServerPort})
+		if err != nil {
+			return nil, fmt.Errorf("could not start obfuscation proxy: %w", err)
+		}
+	} else if err := proxyOpenVPN(conn, m.vpnServerPort); err != nil {
 		return nil, fmt.Errorf("could not proxy connection to OpenVPN server: %w", err)
 	}
 
 	destroy := func() {
-		log.Info().Msgf("Cleaning up session %s", sessionID)
+		logger.Info().Msg("Cleaning up session")
 
 		sessionClients := m.openvpnClients.GetSessionClients(session.ID(sessionID))
 		for clientID := range sessionClients {
 			if err := m.openvpnAuth.ClientKill(clientID); err != nil {
-				log.Error().Err(err).Msgf("Cleaning up session %s failed. Error disconnecting Openvpn client %d", sessionID, clientID)
+				logger.Error().Err(err).Msgf("Cleaning up session failed. Error disconnecting Openvpn client %d", clientID)
+			}
+		}
+
+		if proxy != nil {
+			if err := proxy.Close(); err != nil {
+				logger.Warn().Err(err).Msg("Failed to close obfuscation proxy")
 			}
 		}
 	}
@@ -253,6 +334,7 @@ func (m *Manager) startServer() error {
 			}
 		}),
 		newStatsPublisher(m.openvpnClients, m.bus, 1),
+		&m.openvpnPID,
 	)
 	if err := m.openvpnProcess.Start(); err != nil {
 		return err
@@ -20,6 +20,8 @@ package service
 import (
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/node"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,3 +30,10 @@ func TestManager_StopNotPanic(t *testing.T) {
 	err := m.Stop()
 	assert.NoError(t, err)
 }
+
+func TestObfuscators(t *testing.T) {
+	nodeOptions := node.Options{Obfuscation: node.OptionsObfuscation{Offer: []string{"scramble"}}}
+
+	assert.Equal(t, []string{"scramble"}, Obfuscators(nodeOptions, Options{Protocol: "udp"}))
+	assert.Nil(t, Obfuscators(nodeOptions, Options{Protocol: "tcp"}))
+}
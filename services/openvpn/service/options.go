@@ -22,6 +22,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/nat"
 	"github.com/rs/zerolog/log"
 )
 
@@ -31,6 +32,8 @@ type Options struct {
 	Port     int    `json:"port"`
 	Subnet   string `json:"subnet"`
 	Netmask  string `json:"netmask"`
+	// BlockedPorts lists destination ports consumers are not allowed to reach, defaults to node wide configuration.
+	BlockedPorts []int `json:"blocked_ports"`
 }
 
 // GetOptions returns effective OpenVPN service options from application configuration.
@@ -40,6 +43,8 @@ func GetOptions() Options {
 		Port:     config.GetInt(config.FlagOpenvpnPort),
 		Subnet:   config.GetString(config.FlagOpenvpnSubnet),
 		Netmask:  config.GetString(config.FlagOpenvpnNetmask),
+
+		BlockedPorts: nat.DefaultBlockedPorts(),
 	}
 }
 
@@ -0,0 +1,63 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/management"
+	"github.com/rs/zerolog/log"
+)
+
+// pidTracker is a management middleware which records PID of the openvpn server process.
+type pidTracker struct {
+	pid int64
+}
+
+// Start asks for the process PID, failure is not fatal since PID is used for resource usage sampling only.
+func (pt *pidTracker) Start(commandWriter management.CommandWriter) error {
+	// Management interface replies with "SUCCESS: pid=1234".
+	output, err := commandWriter.SingleLineCommand("pid")
+	if err != nil {
+		log.Warn().Err(err).Msg("Could not get openvpn pid")
+		return nil
+	}
+	pid, err := strconv.ParseInt(strings.TrimPrefix(output, "pid="), 10, 64)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not parse openvpn pid %q", output)
+		return nil
+	}
+	atomic.StoreInt64(&pt.pid, pid)
+	return nil
+}
+
+func (pt *pidTracker) Stop(_ management.CommandWriter) error {
+	atomic.StoreInt64(&pt.pid, 0)
+	return nil
+}
+
+func (pt *pidTracker) ConsumeLine(_ string) (bool, error) {
+	return false, nil
+}
+
+// PID returns PID of the running openvpn process, zero if it is not running.
+func (pt *pidTracker) PID() int {
+	return int(atomic.LoadInt64(&pt.pid))
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"testing"
+
+	"github.com/mysteriumnetwork/go-openvpn/openvpn/management"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPidTracker_RecordsProcessPID(t *testing.T) {
+	tracker := &pidTracker{}
+	conn := &management.MockConnection{CommandResult: "pid=1234"}
+
+	assert.NoError(t, tracker.Start(conn))
+	assert.Equal(t, "pid", conn.LastLine)
+	assert.Equal(t, 1234, tracker.PID())
+
+	assert.NoError(t, tracker.Stop(conn))
+	assert.Equal(t, 0, tracker.PID())
+}
+
+func TestPidTracker_IgnoresUnexpectedReply(t *testing.T) {
+	tracker := &pidTracker{}
+
+	assert.NoError(t, tracker.Start(&management.MockConnection{CommandResult: "unknown"}))
+	assert.Equal(t, 0, tracker.PID())
+}
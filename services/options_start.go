@@ -18,6 +18,7 @@
 package services
 
 import (
+	"strconv"
 	"strings"
 
 	"github.com/mysteriumnetwork/node/config"
@@ -26,6 +27,7 @@ import (
 	"github.com/mysteriumnetwork/node/services/noop"
 	"github.com/mysteriumnetwork/node/services/openvpn"
 	"github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 )
 
@@ -50,7 +52,45 @@ func GetStartOptions(serviceType string) (opts StartOptions, err error) {
 		opts.PaymentPricePerMinute = getPrice(config.FlagNoopPriceMinute, config.FlagPaymentPricePerMinute)
 		opts.AccessPolicyList = getPolicies(config.FlagNoopAccessPolicies, config.FlagAccessPolicyList)
 	}
-	return opts, nil
+	opts.FreeTrialMinutes = config.GetUInt64(config.FlagPaymentFreeTrialMinutes)
+	opts.FreeTrialMB = config.GetUInt64(config.FlagPaymentFreeTrialMB)
+	opts.Unlisted = config.GetBool(config.FlagServiceUnlisted)
+	opts.ConsumerPolicy, err = getConsumerPolicy()
+	return opts, err
+}
+
+func getConsumerPolicy() (policy service.ConsumerPolicy, err error) {
+	policy.AllowedCountries = splitList(config.GetString(config.FlagConsumerPolicyAllowedCountries))
+	policy.DeniedCountries = splitList(config.GetString(config.FlagConsumerPolicyDeniedCountries))
+	if policy.AllowedASNs, err = parseASNs(config.GetString(config.FlagConsumerPolicyAllowedASNs)); err != nil {
+		return policy, errors.Wrapf(err, "invalid %s", config.FlagConsumerPolicyAllowedASNs.Name)
+	}
+	if policy.DeniedASNs, err = parseASNs(config.GetString(config.FlagConsumerPolicyDeniedASNs)); err != nil {
+		return policy, errors.Wrapf(err, "invalid %s", config.FlagConsumerPolicyDeniedASNs.Name)
+	}
+	return policy, nil
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func parseASNs(value string) ([]int, error) {
+	var asns []int
+	for _, item := range splitList(value) {
+		asn, err := strconv.Atoi(strings.TrimPrefix(strings.ToUpper(item), "AS"))
+		if err != nil {
+			return nil, err
+		}
+		asns = append(asns, asn)
+	}
+	return asns, nil
 }
 
 func getPrice(flag cli.Float64Flag, fallback cli.Float64Flag) uint64 {
@@ -80,6 +120,10 @@ func getPolicies(flag cli.StringFlag, fallback cli.StringFlag) []string {
 type StartOptions struct {
 	PaymentPricePerGB     uint64
 	PaymentPricePerMinute uint64
+	FreeTrialMinutes      uint64
+	FreeTrialMB           uint64
 	AccessPolicyList      []string
+	Unlisted              bool
+	ConsumerPolicy        service.ConsumerPolicy
 	TypeOptions           service.Options
 }
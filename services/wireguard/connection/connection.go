@@ -26,6 +26,8 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/mtu"
+	"github.com/mysteriumnetwork/node/core/obfuscation"
 	"github.com/mysteriumnetwork/node/firewall"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
@@ -35,10 +37,15 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// mtuCheckInterval is how often tunnel traffic is checked for the path dropping big packets.
+const mtuCheckInterval = 5 * time.Second
+
 // Options represents connection options.
 type Options struct {
 	DNSScriptDir     string
 	HandshakeTimeout time.Duration
+	// Obfuscation is the obfuscator of session traffic requested from provider, empty disables obfuscation.
+	Obfuscation string
 }
 
 // NewConnection returns new WireGuard connection.
@@ -56,6 +63,8 @@ func NewConnection(opts Options, ipResolver ip.Resolver, endpointFactory wg.Endp
 		ipResolver:          ipResolver,
 		connEndpointFactory: endpointFactory,
 		handshakeWaiter:     handshakeWaiter,
+		refreshRoute:        netutil.RefreshExcludedRoute,
+		mtuCheckInterval:    mtuCheckInterval,
 	}, nil
 }
 
@@ -66,16 +75,24 @@ type Connection struct {
 	stateCh  chan connection.State
 
 	ports               []int
+	providerIP          net.IP
 	privateKey          string
 	ipResolver          ip.Resolver
 	connectionEndpoint  wg.ConnectionEndpoint
+	dialer              *tunnelDialer
+	obfuscationProxy    *obfuscation.Proxy
 	removeAllowedIPRule func()
 	opts                Options
 	connEndpointFactory wg.EndpointFactory
 	handshakeWaiter     HandshakeWaiter
+	refreshRoute        func(ip net.IP) error
+	mtuCheckInterval    time.Duration
 }
 
 var _ connection.Connection = &Connection{}
+var _ connection.ConfigApplier = &Connection{}
+var _ connection.NetworkRebinder = &Connection{}
+var _ connection.TunnelDialer = &Connection{}
 
 // State returns connection state channel.
 func (c *Connection) State() <-chan connection.State {
@@ -107,6 +124,7 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		return errors.Wrap(err, "failed to add firewall exception for wireguard remote IP")
 	}
 	c.removeAllowedIPRule = removeAllowedIPRule
+	c.providerIP = config.Provider.Endpoint.IP
 
 	defer func() {
 		if err != nil {
@@ -122,19 +140,28 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		config.Provider.Endpoint.Port = options.ProviderNATConn.RemoteAddr().(*net.UDPAddr).Port
 	}
 
-	dnsIPs, err := options.Params.DNS.ResolveIPs(config.Consumer.DNSIPs)
+	if config.Obfuscation != nil {
+		if err := c.startObfuscation(&config); err != nil {
+			return errors.Wrap(err, "could not start obfuscation proxy")
+		}
+	}
+
+	dnsIPs, err := options.ResolveDNS(config.Consumer.DNSIPs)
 	if err != nil {
 		return errors.Wrap(err, "could not resolve DNS IPs")
 	}
 
 	log.Info().Msg("Starting new connection")
 	conn, err := c.startConn(wgcfg.DeviceConfig{
-		IfaceName:    "", // Interface name will be generated by connection endpoint.
-		Subnet:       config.Consumer.IPAddress,
-		PrivateKey:   c.privateKey,
-		ListenPort:   config.LocalPort,
-		DNS:          dnsIPs,
-		DNSScriptDir: c.opts.DNSScriptDir,
+		IfaceName:      "", // Interface name will be generated by connection endpoint.
+		Subnet:         config.Consumer.IPAddress,
+		PrivateKey:     c.privateKey,
+		ListenPort:     config.LocalPort,
+		DNS:            dnsIPs,
+		DNSScriptDir:   c.opts.DNSScriptDir,
+		MTU:            config.MTU,
+		RoutingTable:   options.Params.RoutingTable,
+		PacketObserver: options.PacketObserver,
 		Peer: wgcfg.Peer{
 			Endpoint:               &config.Provider.Endpoint,
 			PublicKey:              config.Provider.PublicKey,
@@ -146,6 +173,7 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 		return errors.Wrap(err, "could not start new connection")
 	}
 	c.connectionEndpoint = conn
+	c.dialer = newTunnelDialer(conn.InterfaceName(), config.Consumer.IPAddress.IP, dnsIPs)
 
 	log.Info().Msgf("Adding connection peer %s", config.Provider.Endpoint.String())
 
@@ -155,6 +183,57 @@ func (c *Connection) Start(ctx context.Context, options connection.ConnectOption
 	}
 
 	c.stateCh <- connection.Connected
+
+	tunnelMTU := config.MTU
+	if tunnelMTU == 0 {
+		tunnelMTU = mtu.Wireguard(mtu.Max)
+	}
+	go c.adjustMTU(tunnelMTU)
+	return nil
+}
+
+// adjustMTU lowers tunnel MTU when the path turns out to drop big packets silently,
+// which provider could not discover if routers do not report them.
+func (c *Connection) adjustMTU(tunnelMTU int) {
+	detector := mtu.NewBlackholeDetector(tunnelMTU, mtu.Wireguard(mtu.Min))
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(c.mtuCheckInterval):
+			stats, err := c.connectionEndpoint.PeerStats()
+			if err != nil {
+				log.Warn().Err(err).Msg("Could not get peer stats")
+				continue
+			}
+
+			lowered, ok := detector.Observe(stats.BytesSent, stats.BytesReceived)
+			if !ok {
+				continue
+			}
+			log.Warn().Msgf("Tunnel traffic gets no answer, lowering MTU to %d", lowered)
+			if err := c.connectionEndpoint.SetMTU(lowered); err != nil {
+				log.Error().Err(err).Msg("Failed to lower tunnel MTU")
+			}
+		}
+	}
+}
+
+// startObfuscation points the tunnel to the local obfuscation proxy, which relays its traffic to the provider.
+func (c *Connection) startObfuscation(config *wg.ServiceConfig) error {
+	log.Info().Msgf("Obfuscating connection traffic with %s", config.Obfuscation.Name)
+	proxy, err := obfuscation.NewConsumerProxy(*config.Obfuscation, config.LocalPort, &config.Provider.Endpoint)
+	if err != nil {
+		return err
+	}
+	c.obfuscationProxy = proxy
+
+	// Tunnel endpoint no longer points to the provider, so its route has to be excluded here.
+	if err := netutil.ExcludeRoute(config.Provider.Endpoint.IP); err != nil {
+		return err
+	}
+	config.LocalPort = 0
+	config.Provider.Endpoint = *proxy.PlainAddr()
 	return nil
 }
 
@@ -172,6 +251,87 @@ func (c *Connection) startConn(conf wgcfg.DeviceConfig) (wg.ConnectionEndpoint,
 	return conn, nil
 }
 
+// Backend returns name of wireguard backend running the tunnel, empty until the connection is started.
+func (c *Connection) Backend() string {
+	if c.connectionEndpoint == nil {
+		return ""
+	}
+	return c.connectionEndpoint.Backend()
+}
+
+// InterfaceName returns name of the tunnel network interface, empty until the connection is started.
+func (c *Connection) InterfaceName() string {
+	if c.connectionEndpoint == nil {
+		return ""
+	}
+	return c.connectionEndpoint.InterfaceName()
+}
+
+// ApplyConfig applies session config updated by the provider mid-session.
+// Currently only the provider key rotation is supported.
+func (c *Connection) ApplyConfig(sessionConfig []byte) error {
+	var config wg.ServiceConfig
+	if err := json.Unmarshal(sessionConfig, &config); err != nil {
+		return errors.Wrap(err, "failed to unmarshal connection config")
+	}
+	if c.connectionEndpoint == nil {
+		return errors.New("connection is not started")
+	}
+
+	log.Info().Msg("Applying rotated provider key")
+	if err := c.connectionEndpoint.ReplacePeer(config.Provider.PublicKey); err != nil {
+		return errors.Wrap(err, "could not replace provider peer")
+	}
+	return nil
+}
+
+// Rebind moves the tunnel to the changed local network keeping the session.
+// WireGuard peers roam to the new address of each other on their own, so it is enough
+// to route provider traffic via the new default gateway and make sure the provider replies again.
+func (c *Connection) Rebind() error {
+	if c.connectionEndpoint == nil {
+		return errors.New("connection is not started")
+	}
+
+	stats, err := c.connectionEndpoint.PeerStats()
+	if err != nil {
+		return errors.Wrap(err, "could not get peer stats")
+	}
+
+	log.Info().Msgf("Routing traffic of provider %s via the new network", c.providerIP)
+	if err := c.refreshRoute(c.providerIP); err != nil {
+		return errors.Wrap(err, "could not route provider traffic via the new network")
+	}
+
+	go pokeTunnel()
+	timeout := time.After(c.opts.HandshakeTimeout)
+	for {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			current, err := c.connectionEndpoint.PeerStats()
+			if err != nil {
+				return errors.Wrap(err, "could not get peer stats")
+			}
+			if current.BytesReceived > stats.BytesReceived {
+				return nil
+			}
+		case <-timeout:
+			return errors.New("provider did not reply on the new network")
+		case <-c.done:
+			return errors.New("connection stopped")
+		}
+	}
+}
+
+// Dial opens connection to the address through the tunnel, e.g. for proxy clients to stay inside of the session
+// even if the default route of the host does not point to the tunnel.
+func (c *Connection) Dial(network, address string) (net.Conn, error) {
+	if c.dialer == nil {
+		return nil, errors.New("connection is not started")
+	}
+	return c.dialer.Dial(network, address)
+}
+
 // Wait blocks until wireguard connection not stopped.
 func (c *Connection) Wait() error {
 	<-c.done
@@ -186,8 +346,9 @@ func (c *Connection) GetConfig() (connection.ConsumerConfig, error) {
 	}
 
 	return wg.ConsumerConfig{
-		PublicKey: publicKey,
-		Ports:     c.ports,
+		PublicKey:   publicKey,
+		Ports:       c.ports,
+		Obfuscation: c.opts.Obfuscation,
 	}, nil
 }
 
@@ -207,6 +368,15 @@ func (c *Connection) Stop() {
 			}
 		}
 
+		if c.obfuscationProxy != nil {
+			if err := c.obfuscationProxy.Close(); err != nil {
+				log.Error().Err(err).Msg("Failed to close obfuscation proxy")
+			}
+			if err := netutil.DeleteExcludedRoute(c.providerIP); err != nil {
+				log.Error().Err(err).Msg("Failed to delete excluded route of provider")
+			}
+		}
+
 		c.stateCh <- connection.NotConnected
 
 		close(c.stateCh)
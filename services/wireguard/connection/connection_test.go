@@ -92,6 +92,93 @@ func TestConnectionStopOnceAfterHandshakeErrorAndStopCall(t *testing.T) {
 	assert.Equal(t, connection.NotConnected, <-conn.State())
 }
 
+func TestConnectionApplyConfig(t *testing.T) {
+	conn := newConn(t)
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{
+		Params:        connection.ConnectParams{DNS: "1.2.3.4"},
+		SessionConfig: sessionConfig,
+	})
+	assert.NoError(t, err)
+
+	config := newServiceConfig()
+	config.Provider.PublicKey = "wg2"
+	sessionConfig, _ = json.Marshal(config)
+	err = conn.ApplyConfig(sessionConfig)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "wg2", conn.connectionEndpoint.(*mockConnectionEndpoint).peerPublicKey)
+}
+
+func TestConnectionRebind(t *testing.T) {
+	conn := newConn(t)
+	conn.opts.HandshakeTimeout = time.Second
+	var routed net.IP
+	conn.refreshRoute = func(ip net.IP) error {
+		routed = ip
+		return nil
+	}
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{SessionConfig: sessionConfig})
+	assert.NoError(t, err)
+
+	var received uint64
+	conn.connectionEndpoint.(*mockConnectionEndpoint).peerStats = func() (*wgcfg.Stats, error) {
+		received++
+		return &wgcfg.Stats{LastHandshake: time.Now(), BytesReceived: received}, nil
+	}
+
+	err = conn.Rebind()
+
+	assert.NoError(t, err)
+	assert.Equal(t, "127.0.0.1", routed.String())
+}
+
+func TestConnectionRebindFailsWithoutReply(t *testing.T) {
+	conn := newConn(t)
+	conn.opts.HandshakeTimeout = 200 * time.Millisecond
+	conn.refreshRoute = func(ip net.IP) error {
+		return nil
+	}
+	sessionConfig, _ := json.Marshal(newServiceConfig())
+	err := conn.Start(context.Background(), connection.ConnectOptions{SessionConfig: sessionConfig})
+	assert.NoError(t, err)
+
+	err = conn.Rebind()
+
+	assert.EqualError(t, err, "provider did not reply on the new network")
+}
+
+func TestConnectionLowersMTUWhenTrafficGetsNoAnswer(t *testing.T) {
+	var sent uint64
+	endpoint := &mockConnectionEndpoint{
+		mtu: make(chan int, 1),
+		peerStats: func() (*wgcfg.Stats, error) {
+			sent += 64 * 1024
+			return &wgcfg.Stats{LastHandshake: time.Now(), BytesSent: sent, BytesReceived: 32}, nil
+		},
+	}
+	conn := newConn(t)
+	conn.connEndpointFactory = func() (wg.ConnectionEndpoint, error) {
+		return endpoint, nil
+	}
+	conn.mtuCheckInterval = time.Millisecond
+	defer conn.Stop()
+
+	config := newServiceConfig()
+	config.MTU = 1392
+	sessionConfig, _ := json.Marshal(config)
+	err := conn.Start(context.Background(), connection.ConnectOptions{SessionConfig: sessionConfig})
+	assert.NoError(t, err)
+
+	select {
+	case mtu := <-endpoint.mtu:
+		assert.Equal(t, 1312, mtu)
+	case <-time.After(time.Second):
+		t.Fatal("MTU was not lowered")
+	}
+}
+
 func newConn(t *testing.T) *Connection {
 	endpointFactory := func() (wg.ConnectionEndpoint, error) {
 		return &mockConnectionEndpoint{}, nil
@@ -101,6 +188,7 @@ func newConn(t *testing.T) *Connection {
 	}
 	conn, err := NewConnection(opts, ip.NewResolverMock("172.44.1.12"), endpointFactory, &mockHandshakeWaiter{})
 	assert.NoError(t, err)
+	conn.(*Connection).mtuCheckInterval = time.Hour
 	return conn.(*Connection)
 }
 
@@ -129,19 +217,36 @@ func newServiceConfig() wg.ServiceConfig {
 	}
 }
 
-type mockConnectionEndpoint struct{}
+type mockConnectionEndpoint struct {
+	peerPublicKey string
+	peerStats     func() (*wgcfg.Stats, error)
+	mtu           chan int
+}
 
 func (mce *mockConnectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig) error { return nil }
 func (mce *mockConnectionEndpoint) StartProviderMode(ip string, config wgcfg.DeviceConfig) error {
 	return nil
 }
 func (mce *mockConnectionEndpoint) InterfaceName() string                { return "mce0" }
+func (mce *mockConnectionEndpoint) Backend() string                      { return "userspace" }
 func (mce *mockConnectionEndpoint) Stop() error                          { return nil }
 func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)    { return wg.ServiceConfig{}, nil }
 func (mce *mockConnectionEndpoint) AddPeer(_ string, _ wgcfg.Peer) error { return nil }
 func (mce *mockConnectionEndpoint) RemovePeer(_ string) error            { return nil }
 func (mce *mockConnectionEndpoint) ConfigureRoutes(_ net.IP) error       { return nil }
+func (mce *mockConnectionEndpoint) RotatePrivateKey(_ string) error      { return nil }
+func (mce *mockConnectionEndpoint) ReplacePeer(publicKey string) error {
+	mce.peerPublicKey = publicKey
+	return nil
+}
+func (mce *mockConnectionEndpoint) SetMTU(mtu int) error {
+	mce.mtu <- mtu
+	return nil
+}
 func (mce *mockConnectionEndpoint) PeerStats() (*wgcfg.Stats, error) {
+	if mce.peerStats != nil {
+		return mce.peerStats()
+	}
 	return &wgcfg.Stats{LastHandshake: time.Now(), BytesSent: 10, BytesReceived: 11}, nil
 }
 
@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package connection
+
+import (
+	"net"
+	"time"
+
+	"github.com/miekg/dns"
+	"github.com/mysteriumnetwork/node/utils/netutil"
+	"github.com/pkg/errors"
+)
+
+const (
+	tunnelDialTimeout = 30 * time.Second
+	tunnelDNSTimeout  = 5 * time.Second
+)
+
+// tunnelDialer opens connections through the tunnel interface, hostnames are resolved with the tunnel DNS servers,
+// so that neither connections nor DNS queries follow the routing table of the host.
+type tunnelDialer struct {
+	dialer     *netutil.InterfaceDialer
+	dnsServers []string
+}
+
+func newTunnelDialer(iface string, localIP net.IP, dnsServers []string) *tunnelDialer {
+	return &tunnelDialer{
+		dialer:     &netutil.InterfaceDialer{Iface: iface, LocalIP: localIP, Timeout: tunnelDialTimeout},
+		dnsServers: dnsServers,
+	}
+}
+
+// Dial connects to the address on the named network through the tunnel.
+func (d *tunnelDialer) Dial(network, address string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	if net.ParseIP(host) == nil {
+		ip, err := d.resolve(host)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not resolve %s", host)
+		}
+		address = net.JoinHostPort(ip.String(), port)
+	}
+	return d.dialer.Dial(network, address)
+}
+
+func (d *tunnelDialer) resolve(host string) (net.IP, error) {
+	query := new(dns.Msg)
+	query.SetQuestion(dns.Fqdn(host), dns.TypeA)
+
+	err := errors.New("no DNS servers")
+	for _, server := range d.dnsServers {
+		var ip net.IP
+		if ip, err = d.exchange(query, server); err == nil {
+			return ip, nil
+		}
+	}
+	return nil, err
+}
+
+func (d *tunnelDialer) exchange(query *dns.Msg, server string) (net.IP, error) {
+	address := net.JoinHostPort(server, "53")
+
+	var conn net.Conn
+	var err error
+	if serverIP := net.ParseIP(server); serverIP != nil && (serverIP.IsLoopback() || serverIP.Equal(d.dialer.LocalIP)) {
+		// Local DNS proxy forwards queries through the tunnel itself.
+		conn, err = net.DialTimeout("udp", address, tunnelDNSTimeout)
+	} else {
+		conn, err = d.dialer.Dial("udp", address)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	dnsConn := &dns.Conn{Conn: conn}
+	if err := dnsConn.SetDeadline(time.Now().Add(tunnelDNSTimeout)); err != nil {
+		return nil, err
+	}
+	if err := dnsConn.WriteMsg(query); err != nil {
+		return nil, err
+	}
+	reply, err := dnsConn.ReadMsg()
+	if err != nil {
+		return nil, err
+	}
+	for _, answer := range reply.Answer {
+		if record, ok := answer.(*dns.A); ok {
+			return record.A, nil
+		}
+	}
+	return nil, errors.Errorf("no A record in reply of %s", server)
+}
@@ -40,6 +40,14 @@ func NewHandshakeWaiter() HandshakeWaiter {
 type handshakeWaiter struct {
 }
 
+// pokeTunnel sends a packet through the tunnel, so that WireGuard has something to deliver to the peer.
+func pokeTunnel() {
+	conn, err := net.DialTimeout("tcp", "8.8.8.8:53", 100*time.Millisecond)
+	if err == nil {
+		conn.Close()
+	}
+}
+
 func (h *handshakeWaiter) Wait(statsFetch func() (*wgcfg.Stats, error), timeout time.Duration, stop <-chan struct{}) error {
 	// We need to send any packet to initialize handshake process.
 	handshakePingConn, err := net.DialTimeout("tcp", "8.8.8.8:53", 100*time.Millisecond)
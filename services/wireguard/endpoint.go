@@ -30,7 +30,11 @@ type ConnectionEndpoint interface {
 	StartConsumerMode(config wgcfg.DeviceConfig) error
 	StartProviderMode(publicIP string, config wgcfg.DeviceConfig) error
 	PeerStats() (*wgcfg.Stats, error)
+	RotatePrivateKey(privateKey string) error
+	ReplacePeer(publicKey string) error
+	SetMTU(mtu int) error
 	Config() (ServiceConfig, error)
 	InterfaceName() string
+	Backend() string
 	Stop() error
 }
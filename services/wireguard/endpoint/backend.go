@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoint
+
+import (
+	"runtime"
+
+	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/userspace"
+	"github.com/pkg/errors"
+)
+
+// Backend is an implementation of WireGuard tunnel device.
+type Backend string
+
+const (
+	// BackendAuto picks the first backend supported by the platform.
+	BackendAuto = Backend("auto")
+	// BackendKernel uses WireGuard kernel module, available on Linux only.
+	BackendKernel = Backend("kernel")
+	// BackendUserspace uses wireguard-go with native TUN device of the platform, e.g. utun on macOS.
+	BackendUserspace = Backend("userspace")
+	// BackendWintun uses wireguard-go with Wintun device, available on Windows only.
+	BackendWintun = Backend("wintun")
+	// BackendSupervisor delegates tunnel device to the supervisor when node runs as a regular user.
+	BackendSupervisor = Backend("supervisor")
+)
+
+// autoBackends lists backends in order of preference.
+var autoBackends = []Backend{BackendKernel, BackendWintun, BackendUserspace}
+
+// ParseBackend parses and validates backend name, empty name means BackendAuto.
+func ParseBackend(name string) (Backend, error) {
+	backend := Backend(name)
+	switch backend {
+	case "":
+		return BackendAuto, nil
+	case BackendAuto, BackendKernel, BackendUserspace, BackendWintun:
+		return backend, nil
+	}
+	return "", errors.Errorf("unknown wireguard backend: %s", name)
+}
+
+// candidates lists backends to try, the preferred one goes first followed by the automatic fallbacks.
+func (b Backend) candidates() []Backend {
+	if b == BackendAuto {
+		return autoBackends
+	}
+
+	backends := []Backend{b}
+	for _, backend := range autoBackends {
+		if backend != b {
+			backends = append(backends, backend)
+		}
+	}
+	return backends
+}
+
+// supported checks whether platform is able to run the backend.
+func (b Backend) supported() bool {
+	switch b {
+	case BackendKernel:
+		return isKernelSpaceSupported()
+	case BackendWintun:
+		return runtime.GOOS == "windows" && userspace.WintunSupported()
+	}
+	return true
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseBackend(t *testing.T) {
+	for name, want := range map[string]Backend{
+		"":          BackendAuto,
+		"auto":      BackendAuto,
+		"kernel":    BackendKernel,
+		"userspace": BackendUserspace,
+		"wintun":    BackendWintun,
+	} {
+		backend, err := ParseBackend(name)
+		assert.NoError(t, err, name)
+		assert.Equal(t, want, backend, name)
+	}
+
+	_, err := ParseBackend("supervisor")
+	assert.Error(t, err)
+}
+
+func TestBackendCandidates(t *testing.T) {
+	assert.Equal(t, []Backend{BackendKernel, BackendWintun, BackendUserspace}, BackendAuto.candidates())
+	assert.Equal(t, []Backend{BackendUserspace, BackendKernel, BackendWintun}, BackendUserspace.candidates())
+	assert.Equal(t, []Backend{BackendWintun, BackendKernel, BackendUserspace}, BackendWintun.candidates())
+}
@@ -20,6 +20,7 @@ package endpoint
 import (
 	"net"
 
+	"github.com/mysteriumnetwork/node/config"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
 	"github.com/mysteriumnetwork/node/services/wireguard/key"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
@@ -29,15 +30,23 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
-// NewConnectionEndpoint returns new connection endpoint instance.
+// NewConnectionEndpoint returns new connection endpoint instance using the configured backend.
 func NewConnectionEndpoint(resourceAllocator *resources.Allocator) (wg.ConnectionEndpoint, error) {
-	wgClient, err := newWGClient()
+	preferred, err := ParseBackend(config.GetString(config.FlagWireguardBackend))
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to parse wireguard backend, using automatic selection")
+		preferred = BackendAuto
+	}
+
+	wgClient, backend, err := newWGClient(preferred)
 	if err != nil {
 		return nil, err
 	}
+	log.Debug().Msgf("Using wireguard %s backend", backend)
 
 	return &connectionEndpoint{
 		wgClient:          wgClient,
+		backend:           backend,
 		resourceAllocator: resourceAllocator,
 	}, nil
 }
@@ -47,6 +56,12 @@ type connectionEndpoint struct {
 	endpoint          net.UDPAddr
 	resourceAllocator *resources.Allocator
 	wgClient          WgClient
+	backend           Backend
+}
+
+// Backend returns name of the backend running the tunnel device.
+func (ce *connectionEndpoint) Backend() string {
+	return string(ce.backend)
 }
 
 // StartConsumerMode starts and configure wireguard network interface running in consumer mode.
@@ -62,6 +77,9 @@ func (ce *connectionEndpoint) StartConsumerMode(cfg wgcfg.DeviceConfig) error {
 
 	cfg.IfaceName = iface
 	ce.cfg = cfg
+	if cfg.PacketObserver != nil && ce.backend != BackendUserspace && ce.backend != BackendWintun {
+		log.Warn().Msgf("Traffic breakdown is not supported by wireguard %s backend, connecting without it", ce.backend)
+	}
 
 	if err := ce.wgClient.ConfigureDevice(cfg); err != nil {
 		return errors.Wrap(err, "could not configure device")
@@ -73,8 +91,8 @@ func (ce *connectionEndpoint) StartProviderMode(publicIP string, config wgcfg.De
 	if publicIP == "" {
 		return errors.New("public IP is required")
 	}
-	if config.ListenPort == 0 {
-		return errors.New("listen port is required")
+	if config.ListenPort == 0 && config.Peer.Endpoint == nil {
+		return errors.New("listen port or peer endpoint is required")
 	}
 
 	if err := ce.cleanAbandonedInterfaces(); err != nil {
@@ -107,6 +125,37 @@ func (ce *connectionEndpoint) PeerStats() (*wgcfg.Stats, error) {
 	return ce.wgClient.PeerStats(ce.cfg.IfaceName)
 }
 
+// RotatePrivateKey replaces private key of the running wireguard network interface.
+func (ce *connectionEndpoint) RotatePrivateKey(privateKey string) error {
+	cfg := ce.cfg
+	cfg.PrivateKey = privateKey
+	if err := ce.wgClient.ReconfigureDevice(cfg); err != nil {
+		return errors.Wrap(err, "could not rotate private key")
+	}
+	ce.cfg.PrivateKey = privateKey
+	return nil
+}
+
+// ReplacePeer replaces public key of the connected peer keeping the rest of peer configuration.
+func (ce *connectionEndpoint) ReplacePeer(publicKey string) error {
+	cfg := ce.cfg
+	cfg.Peer.PublicKey = publicKey
+	if err := ce.wgClient.ReconfigureDevice(cfg); err != nil {
+		return errors.Wrap(err, "could not replace peer")
+	}
+	ce.cfg.Peer.PublicKey = publicKey
+	return nil
+}
+
+// SetMTU changes MTU of the running wireguard network interface.
+func (ce *connectionEndpoint) SetMTU(mtu int) error {
+	if err := netutil.SetMTU(ce.cfg.IfaceName, mtu); err != nil {
+		return errors.Wrap(err, "could not set MTU")
+	}
+	ce.cfg.MTU = mtu
+	return nil
+}
+
 // Config provides wireguard service configuration for the current connection endpoint.
 func (ce *connectionEndpoint) Config() (wg.ServiceConfig, error) {
 	publicKey, err := key.PrivateKeyToPublicKey(ce.cfg.PrivateKey)
@@ -34,9 +34,10 @@ import (
 )
 
 type client struct {
-	iface      string
-	wgClient   *wgctrl.Client
-	dnsManager dns.Manager
+	iface        string
+	routingTable int
+	wgClient     *wgctrl.Client
+	dnsManager   dns.Manager
 }
 
 // NewWireguardClient creates new wireguard kernel space client.
@@ -61,11 +62,16 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	deviceConfig.PrivateKey = &privateKey
 	deviceConfig.ListenPort = &port
 
-	if err := c.up(config.IfaceName, config.Subnet); err != nil {
+	if err := c.up(config.IfaceName, config.Subnet, config.MTU); err != nil {
 		return err
 	}
 
-	if config.Peer.Endpoint != nil {
+	if config.RoutingTable > 0 {
+		if err := netutil.AddPolicyRoute(config.IfaceName, config.RoutingTable); err != nil {
+			return err
+		}
+		c.routingTable = config.RoutingTable
+	} else if config.Peer.Endpoint != nil {
 		if err := configureRoutes(config.IfaceName, config.Peer.Endpoint.IP); err != nil {
 			return err
 		}
@@ -79,6 +85,10 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	if err := c.wgClient.ConfigureDevice(c.iface, deviceConfig); err != nil {
 		return fmt.Errorf("could not configure kernel space device: %w", err)
 	}
+	// Isolated connections must not change the system DNS used by the other connections.
+	if config.RoutingTable > 0 {
+		return nil
+	}
 	if err := c.dnsManager.Set(dns.Config{
 		ScriptDir: config.DNSScriptDir,
 		IfaceName: config.IfaceName,
@@ -122,6 +132,40 @@ func addPeerConfig(peer wgcfg.Peer) (wgtypes.PeerConfig, error) {
 	}, nil
 }
 
+func (c *client) ReconfigureDevice(config wgcfg.DeviceConfig) error {
+	privateKey, err := stringToKey(config.PrivateKey)
+	if err != nil {
+		return err
+	}
+	peer, err := addPeerConfig(config.Peer)
+	if err != nil {
+		return err
+	}
+
+	d, err := c.wgClient.Device(c.iface)
+	if err != nil {
+		return err
+	}
+
+	// Keep the existing peer untouched if its key is the same, so that the endpoint
+	// learned from the handshake is not lost. Remove all other peers.
+	peers := []wgtypes.PeerConfig{peer}
+	for _, p := range d.Peers {
+		if p.PublicKey != peer.PublicKey {
+			peers = append(peers, wgtypes.PeerConfig{PublicKey: p.PublicKey, Remove: true})
+		}
+	}
+
+	deviceConfig := wgtypes.Config{
+		PrivateKey: &privateKey,
+		Peers:      peers,
+	}
+	if err := c.wgClient.ConfigureDevice(c.iface, deviceConfig); err != nil {
+		return fmt.Errorf("could not reconfigure kernel space device: %w", err)
+	}
+	return nil
+}
+
 func (c *client) PeerStats(string) (*wgcfg.Stats, error) {
 	d, err := c.wgClient.Device(c.iface)
 	if err != nil {
@@ -143,7 +187,7 @@ func (c *client) DestroyDevice(name string) error {
 	return cmdutil.SudoExec("ip", "link", "del", "dev", name)
 }
 
-func (c *client) up(iface string, ipAddr net.IPNet) error {
+func (c *client) up(iface string, ipAddr net.IPNet, mtu int) error {
 	if d, err := c.wgClient.Device(iface); err != nil || d.Name != iface {
 		if err := cmdutil.SudoExec("ip", "link", "add", "dev", iface, "type", "wireguard"); err != nil {
 			return err
@@ -154,11 +198,22 @@ func (c *client) up(iface string, ipAddr net.IPNet) error {
 		return err
 	}
 
+	if mtu > 0 {
+		if err := netutil.SetMTU(iface, mtu); err != nil {
+			return err
+		}
+	}
+
 	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
 
 func (c *client) Close() (err error) {
 	errs := utils.ErrorCollection{}
+	if c.routingTable > 0 {
+		if err := netutil.DeletePolicyRoute(c.routingTable); err != nil {
+			errs.Add(err)
+		}
+	}
 	if err := c.DestroyDevice(c.iface); err != nil {
 		errs.Add(err)
 	}
@@ -66,6 +66,23 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) error {
 	return nil
 }
 
+func (c *client) ReconfigureDevice(config wgcfg.DeviceConfig) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	config.IfaceName = c.iface
+
+	jsonCfg, err := json.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("could not marshal device config to JSON: %w", err)
+	}
+	jsonb64 := base64.StdEncoding.EncodeToString(jsonCfg)
+
+	if _, err := supervisorclient.Command("wg-reconfigure", "-iface", c.iface, "-config", jsonb64); err != nil {
+		return fmt.Errorf("failed to reconfigure wg interface: %w", err)
+	}
+	return nil
+}
+
 func (c *client) DestroyDevice(iface string) error {
 	_, err := supervisorclient.Command("wg-down", "-iface", iface)
 	if err != nil {
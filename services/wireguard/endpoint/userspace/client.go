@@ -20,6 +20,7 @@ package userspace
 import (
 	"bufio"
 	"fmt"
+	"net"
 	"strings"
 
 	"github.com/mysteriumnetwork/node/services/wireguard/connection/dns"
@@ -31,22 +32,39 @@ import (
 )
 
 type client struct {
-	tun        tun.Device
-	devAPI     *device.Device
-	dnsManager dns.Manager
+	createTUN    func(name string, subnet net.IPNet, mtu int) (tun.Device, error)
+	tun          tun.Device
+	devAPI       *device.Device
+	dnsManager   dns.Manager
+	routingTable int
 }
 
-// NewWireguardClient creates new wireguard user space client.
+// NewWireguardClient creates new wireguard user space client using native TUN device of the platform.
 func NewWireguardClient() (*client, error) {
 	return &client{
+		createTUN:  CreateTUN,
+		dnsManager: dns.NewManager(),
+	}, nil
+}
+
+// NewWintunClient creates new wireguard user space client using Wintun device, available on Windows only.
+func NewWintunClient() (*client, error) {
+	if !WintunSupported() {
+		return nil, errors.New("wintun driver is not available")
+	}
+	return &client{
+		createTUN:  CreateWintunTUN,
 		dnsManager: dns.NewManager(),
 	}, nil
 }
 
 func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
-	if c.tun, err = CreateTUN(config.IfaceName, config.Subnet); err != nil {
+	if c.tun, err = c.createTUN(config.IfaceName, config.Subnet, config.MTU); err != nil {
 		return errors.Wrap(err, "failed to create TUN device")
 	}
+	if config.PacketObserver != nil {
+		c.tun = &observedTUN{Device: c.tun, observer: config.PacketObserver}
+	}
 
 	c.devAPI = device.NewDevice(c.tun, device.NewLogger(device.LogLevelDebug, "[userspace-wg]"))
 	if err := c.setDeviceConfig(config.Encode()); err != nil {
@@ -55,6 +73,16 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 
 	c.devAPI.Up()
 
+	// Isolated consumer connection routes only the marked traffic via VPN tunnel
+	// and leaves system DNS to the other connections.
+	if config.RoutingTable > 0 {
+		if err := netutil.AddPolicyRoute(config.IfaceName, config.RoutingTable); err != nil {
+			return fmt.Errorf("could not add policy route for %s: %w", config.IfaceName, err)
+		}
+		c.routingTable = config.RoutingTable
+		return nil
+	}
+
 	// For consumer mode we need to exclude provider's IP from VPN tunnel
 	// and add default routes to forward all traffic via VPN tunnel.
 	if config.Peer.Endpoint != nil {
@@ -79,6 +107,11 @@ func (c *client) ConfigureDevice(config wgcfg.DeviceConfig) (err error) {
 
 func (c *client) Close() error {
 	c.devAPI.Close() // c.devAPI.Close() closes c.tun too
+	if c.routingTable > 0 {
+		if err := netutil.DeletePolicyRoute(c.routingTable); err != nil {
+			return fmt.Errorf("could not delete policy route: %w", err)
+		}
+	}
 	if err := c.dnsManager.Clean(); err != nil {
 		return fmt.Errorf("could not clean DNS: %w", err)
 	}
@@ -97,6 +130,10 @@ func (c *client) PeerStats(string) (*wgcfg.Stats, error) {
 	return stats, nil
 }
 
+func (c *client) ReconfigureDevice(config wgcfg.DeviceConfig) error {
+	return ReconfigureDevice(c.devAPI, config)
+}
+
 func (c *client) DestroyDevice(name string) error {
 	return destroyDevice(name)
 }
@@ -107,3 +144,25 @@ func (c *client) setDeviceConfig(config string) error {
 	}
 	return nil
 }
+
+// ReconfigureDevice updates private key and peer of already running userspace device.
+// Existing peer is replaced only if its public key differs, so the endpoint learned
+// from the peer handshake is kept otherwise.
+func ReconfigureDevice(dev *device.Device, config wgcfg.DeviceConfig) error {
+	deviceState, err := ParseUserspaceDevice(dev.IpcGetOperation)
+	if err != nil {
+		return fmt.Errorf("could not parse device state: %w", err)
+	}
+
+	replacePeers := true
+	for _, peer := range deviceState.Peers {
+		if peer.PublicKey == config.Peer.PublicKey {
+			replacePeers = false
+		}
+	}
+
+	if err := dev.IpcSetOperation(bufio.NewReader(strings.NewReader(config.EncodeReconfigure(replacePeers)))); err != nil {
+		return errors.Wrap(err, "failed to reconfigure device")
+	}
+	return nil
+}
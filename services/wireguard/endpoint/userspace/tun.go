@@ -28,9 +28,12 @@ import (
 	"golang.zx2c4.com/wireguard/tun"
 )
 
-// CreateTUN creates native TUN device for wireguard.
-func CreateTUN(name string, subnet net.IPNet) (tunDevice tun.Device, err error) {
-	if tunDevice, err = tun.CreateTUN(name, device.DefaultMTU); err != nil {
+// CreateTUN creates native TUN device for wireguard, zero MTU keeps the default one.
+func CreateTUN(name string, subnet net.IPNet, mtu int) (tunDevice tun.Device, err error) {
+	if mtu == 0 {
+		mtu = device.DefaultMTU
+	}
+	if tunDevice, err = tun.CreateTUN(name, mtu); err != nil {
 		return nil, errors.Wrap(err, "failed to create TUN device")
 	}
 	if err = netutil.AssignIP(name, subnet); err != nil {
@@ -38,3 +41,13 @@ func CreateTUN(name string, subnet net.IPNet) (tunDevice tun.Device, err error)
 	}
 	return tunDevice, nil
 }
+
+// WintunSupported checks whether Wintun driver is installed, it is available on Windows only.
+func WintunSupported() bool {
+	return false
+}
+
+// CreateWintunTUN creates Wintun device for wireguard, it is available on Windows only.
+func CreateWintunTUN(name string, subnet net.IPNet, mtu int) (tun.Device, error) {
+	return nil, errors.New("wintun is not supported on this platform")
+}
@@ -0,0 +1,44 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package userspace
+
+import (
+	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
+	"golang.zx2c4.com/wireguard/tun"
+)
+
+// observedTUN passes packets read from and written to TUN device to the observer.
+type observedTUN struct {
+	tun.Device
+	observer wgcfg.PacketObserver
+}
+
+// Read reads a packet sent by the system into the tunnel.
+func (t *observedTUN) Read(buf []byte, offset int) (int, error) {
+	n, err := t.Device.Read(buf, offset)
+	if n > 0 {
+		t.observer.Outbound(buf[offset : offset+n])
+	}
+	return n, err
+}
+
+// Write writes a packet received from the tunnel to the system.
+func (t *observedTUN) Write(buf []byte, offset int) (int, error) {
+	t.observer.Inbound(buf[offset:])
+	return t.Device.Write(buf, offset)
+}
@@ -23,6 +23,7 @@ import (
 	"net"
 	"os"
 	"os/exec"
+	"path/filepath"
 
 	"github.com/mysteriumnetwork/node/utils/netutil"
 	"github.com/pkg/errors"
@@ -34,10 +35,15 @@ import (
 type nativeTun struct {
 	tun    *water.Interface
 	events chan tun.Event
+	mtu    int
 }
 
-// CreateTUN creates native TUN device for wireguard.
-func CreateTUN(name string, subnet net.IPNet) (tun.Device, error) {
+// CreateTUN creates native TUN device for wireguard, zero MTU keeps the default one.
+func CreateTUN(name string, subnet net.IPNet, mtu int) (tun.Device, error) {
+	if mtu == 0 {
+		mtu = device.DefaultMTU
+	}
+
 	tunDevice, err := water.New(water.Config{
 		DeviceType: water.TUN,
 		PlatformSpecificParams: water.PlatformSpecificParams{
@@ -59,12 +65,39 @@ func CreateTUN(name string, subnet net.IPNet) (tun.Device, error) {
 		}
 	}
 
+	if err := netutil.SetMTU(name, mtu); err != nil {
+		return nil, errors.Wrap(err, "failed to set MTU")
+	}
+
 	return &nativeTun{
 		tun:    tunDevice,
 		events: make(chan tun.Event, 10),
+		mtu:    mtu,
 	}, nil
 }
 
+// WintunSupported checks whether Wintun driver is installed.
+func WintunSupported() bool {
+	_, err := os.Stat(filepath.Join(os.Getenv("SystemRoot"), "System32", "drivers", "wintun.sys"))
+	return err == nil
+}
+
+// CreateWintunTUN creates Wintun device for wireguard, zero MTU keeps the default one.
+func CreateWintunTUN(name string, subnet net.IPNet, mtu int) (tun.Device, error) {
+	if mtu == 0 {
+		mtu = device.DefaultMTU
+	}
+	tunDevice, err := tun.CreateTUN(name, mtu)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Wintun device")
+	}
+	if err := netutil.AssignIP(name, subnet); err != nil {
+		tunDevice.Close()
+		return nil, errors.Wrap(err, "failed to assign IP address")
+	}
+	return tunDevice, nil
+}
+
 func (tun *nativeTun) Name() (string, error) {
 	return tun.tun.Name(), nil
 }
@@ -95,7 +128,7 @@ func (tun *nativeTun) Flush() error {
 }
 
 func (tun *nativeTun) MTU() (int, error) {
-	return device.DefaultMTU, nil
+	return tun.mtu, nil
 }
 
 func renameInterface(name, newname string) error {
@@ -26,27 +26,54 @@ import (
 	"github.com/mysteriumnetwork/node/services/wireguard/endpoint/userspace"
 	"github.com/mysteriumnetwork/node/services/wireguard/wgcfg"
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog/log"
 )
 
 // WgClient represents WireGuard client.
 type WgClient interface {
 	ConfigureDevice(config wgcfg.DeviceConfig) error
+	ReconfigureDevice(config wgcfg.DeviceConfig) error
 	DestroyDevice(name string) error
 	PeerStats(iface string) (*wgcfg.Stats, error)
 	Close() error
 }
 
-func newWGClient() (WgClient, error) {
+// newWGClient creates client of the preferred backend, falling back to the next supported one if it is not available.
+func newWGClient(preferred Backend) (WgClient, Backend, error) {
 	if config.GetBool(config.FlagUserMode) {
-		return remoteclient.New()
+		client, err := remoteclient.New()
+		return client, BackendSupervisor, err
 	}
-	if isKernelSpaceSupported() {
-		return kernelspace.NewWireguardClient()
+
+	for _, backend := range preferred.candidates() {
+		if !backend.supported() {
+			log.Info().Msgf("Wireguard %s backend is not supported, trying the next one", backend)
+			continue
+		}
+
+		client, err := newBackendClient(backend)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Failed to create wireguard %s backend client, trying the next one", backend)
+			continue
+		}
+		if backend != preferred && preferred != BackendAuto {
+			log.Warn().Msgf("Wireguard %s backend is not available, falling back to %s", preferred, backend)
+		}
+		return client, backend, nil
 	}
+	return nil, "", errors.New("no wireguard backend is available")
+}
 
-	log.Info().Msg("Wireguard kernel space is not supported. Switching to user space implementation.")
-	return userspace.NewWireguardClient()
+func newBackendClient(backend Backend) (WgClient, error) {
+	switch backend {
+	case BackendKernel:
+		return kernelspace.NewWireguardClient()
+	case BackendWintun:
+		return userspace.NewWintunClient()
+	default:
+		return userspace.NewWireguardClient()
+	}
 }
 
 func isKernelSpaceSupported() bool {
@@ -33,6 +33,17 @@ import (
 // MaxConnections sets the limit to the maximum number of wireguard connections.
 var MaxConnections = 256
 
+// allocated tracks resources handed out by all allocators of the process,
+// so that several service instances neither share nor clean up each other's resources.
+var allocated = struct {
+	sync.Mutex
+	ifaces map[int]struct{}
+	ipNets map[string]struct{}
+}{
+	ifaces: make(map[int]struct{}),
+	ipNets: make(map[string]struct{}),
+}
+
 type portSupplier interface {
 	Acquire() (port.Port, error)
 }
@@ -62,8 +73,8 @@ func NewAllocator(ports portSupplier, subnet net.IPNet) *Allocator {
 // AbandonedInterfaces returns a list of abandoned interfaces that exist in the system,
 // but was not allocated by the Allocator.
 func (a *Allocator) AbandonedInterfaces() ([]net.Interface, error) {
-	a.mu.Lock()
-	defer a.mu.Unlock()
+	allocated.Lock()
+	defer allocated.Unlock()
 
 	ifaces, err := net.Interfaces()
 	if err != nil {
@@ -75,7 +86,7 @@ func (a *Allocator) AbandonedInterfaces() ([]net.Interface, error) {
 		if strings.HasPrefix(iface.Name, interfacePrefix) {
 			ifaceID, err := strconv.Atoi(strings.TrimPrefix(iface.Name, interfacePrefix))
 			if err == nil {
-				if _, ok := a.Ifaces[ifaceID]; !ok {
+				if _, ok := allocated.ifaces[ifaceID]; !ok {
 					list = append(list, iface)
 				}
 			}
@@ -95,9 +106,13 @@ func (a *Allocator) AllocateInterface() (string, error) {
 		return "", err
 	}
 
+	allocated.Lock()
+	defer allocated.Unlock()
+
 	for i := 0; i < MaxConnections; i++ {
-		if _, ok := a.Ifaces[i]; !ok {
+		if _, ok := allocated.ifaces[i]; !ok {
 			a.Ifaces[i] = struct{}{}
+			allocated.ifaces[i] = struct{}{}
 			if interfaceExists(ifaces, fmt.Sprintf("%s%d", interfacePrefix, i)) {
 				continue
 			}
@@ -114,11 +129,20 @@ func (a *Allocator) AllocateIPNet() (net.IPNet, error) {
 	a.mu.Lock()
 	defer a.mu.Unlock()
 
+	allocated.Lock()
+	defer allocated.Unlock()
+
 	for i := 0; i < MaxConnections; i++ {
-		if _, ok := a.IPAddresses[i]; !ok {
-			a.IPAddresses[i] = struct{}{}
-			return calcIPNet(a.subnet, i), nil
+		if _, ok := a.IPAddresses[i]; ok {
+			continue
+		}
+		ipnet := calcIPNet(a.subnet, i)
+		if _, ok := allocated.ipNets[ipnet.String()]; ok {
+			continue
 		}
+		a.IPAddresses[i] = struct{}{}
+		allocated.ipNets[ipnet.String()] = struct{}{}
+		return ipnet, nil
 	}
 	return net.IPNet{}, errors.New("no more unused subnets")
 }
@@ -150,6 +174,9 @@ func (a *Allocator) ReleaseInterface(iface string) error {
 	}
 
 	delete(a.Ifaces, i)
+	allocated.Lock()
+	delete(allocated.ifaces, i)
+	allocated.Unlock()
 	return nil
 }
 
@@ -169,6 +196,10 @@ func (a *Allocator) ReleaseIPNet(ipnet net.IPNet) error {
 	}
 
 	delete(a.IPAddresses, i)
+	released := calcIPNet(a.subnet, i)
+	allocated.Lock()
+	delete(allocated.ipNets, released.String())
+	allocated.Unlock()
 	return nil
 }
 
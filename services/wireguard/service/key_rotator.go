@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/services/wireguard/key"
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/rs/zerolog/log"
+)
+
+type privateKeyRotator interface {
+	RotatePrivateKey(privateKey string) error
+}
+
+// keyRotator periodically rotates provider tunnel key of a long-lived session.
+// New public key is pushed to the consumer first and provider switches to the
+// new private key only after consumer confirmed the update, so the tunnel
+// recovers with a single handshake instead of being torn down.
+type keyRotator struct {
+	done      chan struct{}
+	updates   chan service.ConfigUpdate
+	bus       eventbus.Publisher
+	threshold time.Duration
+	interval  time.Duration
+}
+
+func newKeyRotator(bus eventbus.Publisher, threshold, interval time.Duration) keyRotator {
+	return keyRotator{
+		done:      make(chan struct{}),
+		updates:   make(chan service.ConfigUpdate),
+		bus:       bus,
+		threshold: threshold,
+		interval:  interval,
+	}
+}
+
+func (r keyRotator) start(sessionID string, config wg.ServiceConfig, endpoint privateKeyRotator) {
+	timer := time.NewTimer(r.threshold)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			rotated, err := r.rotate(config, endpoint)
+			if err != nil {
+				log.Warn().Err(err).Msgf("Could not rotate tunnel key for session %s", sessionID)
+			} else {
+				config = rotated
+				log.Info().Msgf("Tunnel key rotated for session %s", sessionID)
+				r.bus.Publish(event.AppTopicKeyRotated, event.AppEventKeyRotated{
+					SessionID: sessionID,
+					RotatedAt: time.Now().UTC(),
+				})
+			}
+			timer.Reset(r.interval)
+		case <-r.done:
+			log.Info().Msgf("Stopped tunnel key rotation for session %s", sessionID)
+			return
+		}
+	}
+}
+
+func (r keyRotator) rotate(config wg.ServiceConfig, endpoint privateKeyRotator) (wg.ServiceConfig, error) {
+	privateKey, err := key.GeneratePrivateKey()
+	if err != nil {
+		return config, fmt.Errorf("could not generate private key: %w", err)
+	}
+	publicKey, err := key.PrivateKeyToPublicKey(privateKey)
+	if err != nil {
+		return config, fmt.Errorf("could not get public key from private key: %w", err)
+	}
+	config.Provider.PublicKey = publicKey
+
+	result := make(chan error, 1)
+	update := service.ConfigUpdate{
+		Config: config,
+		Result: func(err error) { result <- err },
+	}
+	select {
+	case r.updates <- update:
+	case <-r.done:
+		return config, fmt.Errorf("session is closed")
+	}
+
+	select {
+	case err := <-result:
+		if err != nil {
+			return config, fmt.Errorf("consumer did not apply rotated key: %w", err)
+		}
+	case <-r.done:
+		return config, fmt.Errorf("session is closed")
+	}
+
+	if err := endpoint.RotatePrivateKey(privateKey); err != nil {
+		return config, fmt.Errorf("could not apply rotated private key: %w", err)
+	}
+	return config, nil
+}
+
+func (r keyRotator) stop() {
+	close(r.done)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/mocks"
+	wg "github.com/mysteriumnetwork/node/services/wireguard"
+	"github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_keyRotator_RotatesKeyAfterConsumerApplied(t *testing.T) {
+	bus := mocks.NewEventBus()
+	endpoint := &mockConnectionEndpoint{}
+	rotator := newKeyRotator(bus, time.Millisecond, time.Hour)
+	defer rotator.stop()
+
+	config := wg.ServiceConfig{}
+	config.Provider.PublicKey = "old"
+	go rotator.start("kappa", config, endpoint)
+
+	update := <-rotator.updates
+	rotated := update.Config.(wg.ServiceConfig)
+	assert.NotEqual(t, "old", rotated.Provider.PublicKey)
+	assert.Empty(t, endpoint.privateKey)
+
+	update.Result(nil)
+	assert.Eventually(t, func() bool {
+		evt, ok := bus.Pop().(event.AppEventKeyRotated)
+		return ok && evt.SessionID == "kappa"
+	}, time.Second, time.Millisecond)
+	assert.NotEmpty(t, endpoint.privateKey)
+}
+
+func Test_keyRotator_KeepsKeyWhenConsumerFailed(t *testing.T) {
+	bus := mocks.NewEventBus()
+	endpoint := &mockConnectionEndpoint{}
+	rotator := newKeyRotator(bus, time.Millisecond, time.Hour)
+	defer rotator.stop()
+
+	go func() {
+		update := <-rotator.updates
+		update.Result(errors.New("not supported"))
+	}()
+	_, err := rotator.rotate(wg.ServiceConfig{}, endpoint)
+
+	assert.Error(t, err)
+	assert.Empty(t, endpoint.privateKey)
+	assert.Nil(t, bus.Pop())
+}
@@ -20,10 +20,13 @@ package service
 import (
 	"encoding/json"
 	"net"
+	"time"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/port"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/dns"
+	"github.com/mysteriumnetwork/node/nat"
 	"github.com/mysteriumnetwork/node/services/wireguard/resources"
 	"github.com/rs/zerolog/log"
 )
@@ -32,6 +35,16 @@ import (
 type Options struct {
 	Ports  *port.Range
 	Subnet net.IPNet
+	// KeyRotationInterval is an interval of tunnel key rotation, zero value disables the rotation.
+	KeyRotationInterval time.Duration
+	// KeyRotationThreshold is a session duration after which tunnel key rotation starts.
+	KeyRotationThreshold time.Duration
+	// BlockedPorts lists destination ports consumers are not allowed to reach, defaults to node wide configuration.
+	BlockedPorts []int
+	// Obfuscators lists obfuscators of session traffic offered to consumers, configured node wide.
+	Obfuscators []string `json:"-"`
+	// DNSFilter describes content filtering of consumer DNS queries, configured node wide.
+	DNSFilter dns.Filter `json:"-"`
 }
 
 // DefaultOptions is a wireguard service configuration that will be used if no options provided.
@@ -41,6 +54,8 @@ var DefaultOptions = Options{
 		IP:   net.ParseIP("10.182.0.0").To4(),
 		Mask: net.IPv4Mask(255, 255, 0, 0),
 	},
+	KeyRotationInterval:  time.Hour,
+	KeyRotationThreshold: 2 * time.Hour,
 }
 
 // GetOptions returns effective Wireguard service options from application configuration.
@@ -62,8 +77,11 @@ func GetOptions() Options {
 		portRange = port.UnspecifiedRange()
 	}
 	return Options{
-		Ports:  portRange,
-		Subnet: *ipnet,
+		Ports:                portRange,
+		Subnet:               *ipnet,
+		KeyRotationInterval:  config.GetDuration(config.FlagWireguardKeyRotationInterval),
+		KeyRotationThreshold: config.GetDuration(config.FlagWireguardKeyRotationThreshold),
+		BlockedPorts:         nat.DefaultBlockedPorts(),
 	}
 }
 
@@ -75,6 +93,7 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 	}
 
 	opts := DefaultOptions
+	opts.BlockedPorts = nat.DefaultBlockedPorts()
 	err := json.Unmarshal(*request, &opts)
 	return opts, err
 }
@@ -82,19 +101,28 @@ func ParseJSONOptions(request *json.RawMessage) (service.Options, error) {
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (o Options) MarshalJSON() ([]byte, error) {
 	return json.Marshal(&struct {
-		Ports  string `json:"ports"`
-		Subnet string `json:"subnet"`
+		Ports                string `json:"ports"`
+		Subnet               string `json:"subnet"`
+		KeyRotationInterval  string `json:"key_rotation_interval"`
+		KeyRotationThreshold string `json:"key_rotation_threshold"`
+		BlockedPorts         []int  `json:"blocked_ports"`
 	}{
-		Ports:  o.Ports.String(),
-		Subnet: o.Subnet.String(),
+		Ports:                o.Ports.String(),
+		Subnet:               o.Subnet.String(),
+		KeyRotationInterval:  o.KeyRotationInterval.String(),
+		KeyRotationThreshold: o.KeyRotationThreshold.String(),
+		BlockedPorts:         o.BlockedPorts,
 	})
 }
 
 // UnmarshalJSON implements json.Unmarshaler interface to receive human readable configuration.
 func (o *Options) UnmarshalJSON(data []byte) error {
 	var options struct {
-		Ports  string `json:"ports"`
-		Subnet string `json:"subnet"`
+		Ports                string `json:"ports"`
+		Subnet               string `json:"subnet"`
+		KeyRotationInterval  string `json:"key_rotation_interval"`
+		KeyRotationThreshold string `json:"key_rotation_threshold"`
+		BlockedPorts         *[]int `json:"blocked_ports"`
 	}
 
 	if err := json.Unmarshal(data, &options); err != nil {
@@ -115,6 +143,23 @@ func (o *Options) UnmarshalJSON(data []byte) error {
 		}
 		o.Subnet = *ipnet
 	}
+	if options.KeyRotationInterval != "" {
+		d, err := time.ParseDuration(options.KeyRotationInterval)
+		if err != nil {
+			return err
+		}
+		o.KeyRotationInterval = d
+	}
+	if options.KeyRotationThreshold != "" {
+		d, err := time.ParseDuration(options.KeyRotationThreshold)
+		if err != nil {
+			return err
+		}
+		o.KeyRotationThreshold = d
+	}
+	if options.BlockedPorts != nil {
+		o.BlockedPorts = *options.BlockedPorts
+	}
 
 	return nil
 }
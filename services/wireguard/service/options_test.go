@@ -22,6 +22,7 @@ import (
 	"flag"
 	"net"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/config"
 	"github.com/mysteriumnetwork/node/core/port"
@@ -48,7 +49,7 @@ func Test_ParseJSONOptions_HandlesEmptyRequest(t *testing.T) {
 
 func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 	configureDefaults()
-	request := json.RawMessage(`{"ports": "52820:53075", "subnet":"10.10.0.0/16"}`)
+	request := json.RawMessage(`{"ports": "52820:53075", "subnet":"10.10.0.0/16", "key_rotation_interval": "30m"}`)
 	options, err := ParseJSONOptions(&request)
 
 	assert.NoError(t, err)
@@ -58,6 +59,8 @@ func Test_ParseJSONOptions_ValidRequest(t *testing.T) {
 			IP:   net.ParseIP("10.10.0.0").To4(),
 			Mask: net.IPv4Mask(255, 255, 0, 0),
 		},
+		KeyRotationInterval:  30 * time.Minute,
+		KeyRotationThreshold: 2 * time.Hour,
 	}, options)
 }
 
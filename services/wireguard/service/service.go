@@ -24,7 +24,7 @@ import (
 )
 
 // GetProposal returns the proposal for wireguard service
-func GetProposal(location location.Location) market.ServiceProposal {
+func GetProposal(location location.Location, obfuscators []string) market.ServiceProposal {
 	marketLocation := market.Location{
 		Continent: location.Continent,
 		Country:   location.Country,
@@ -40,6 +40,7 @@ func GetProposal(location location.Location) market.ServiceProposal {
 		ServiceDefinition: wg.ServiceDefinition{
 			Location:          marketLocation,
 			LocationOriginate: marketLocation,
+			Obfuscators:       obfuscators,
 		},
 	}
 }
@@ -52,9 +52,10 @@ func Test_GetProposal(t *testing.T) {
 			ServiceDefinition: wg.ServiceDefinition{
 				Location:          market.Location{Country: country},
 				LocationOriginate: market.Location{Country: country},
+				Obfuscators:       []string{"scramble"},
 			},
 		},
-		GetProposal(location.Location{Country: country}),
+		GetProposal(location.Location{Country: country}, []string{"scramble"}),
 	)
 }
 
@@ -90,23 +91,41 @@ func Test_Manager_ProviderConfig_FailsWhenSessionConfigIsInvalid(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_Manager_ProviderConfig_FailsWhenObfuscatorIsNotOffered(t *testing.T) {
+	manager := newManagerStub(pubIP, outIP, country)
+
+	params, err := manager.ProvideConfig("", []byte(`{"Obfuscation":"scramble"}`), nil)
+
+	assert.Nil(t, params)
+	assert.Error(t, err)
+}
+
 // usually time.Sleep call gives a chance for other goroutines to kick in important when testing async code
 func waitABit() {
 	time.Sleep(10 * time.Millisecond)
 }
 
-type mockConnectionEndpoint struct{}
+type mockConnectionEndpoint struct {
+	privateKey string
+}
 
 func (mce *mockConnectionEndpoint) StartConsumerMode(config wgcfg.DeviceConfig) error { return nil }
 func (mce *mockConnectionEndpoint) StartProviderMode(ip string, config wgcfg.DeviceConfig) error {
 	return nil
 }
 func (mce *mockConnectionEndpoint) InterfaceName() string                { return "mce0" }
+func (mce *mockConnectionEndpoint) Backend() string                      { return "userspace" }
 func (mce *mockConnectionEndpoint) Stop() error                          { return nil }
 func (mce *mockConnectionEndpoint) Config() (wg.ServiceConfig, error)    { return wg.ServiceConfig{}, nil }
 func (mce *mockConnectionEndpoint) AddPeer(_ string, _ wgcfg.Peer) error { return nil }
 func (mce *mockConnectionEndpoint) RemovePeer(_ string) error            { return nil }
 func (mce *mockConnectionEndpoint) ConfigureRoutes(_ net.IP) error       { return nil }
+func (mce *mockConnectionEndpoint) RotatePrivateKey(privateKey string) error {
+	mce.privateKey = privateKey
+	return nil
+}
+func (mce *mockConnectionEndpoint) ReplacePeer(_ string) error { return nil }
+func (mce *mockConnectionEndpoint) SetMTU(_ int) error         { return nil }
 func (mce *mockConnectionEndpoint) PeerStats() (*wgcfg.Stats, error) {
 	return &wgcfg.Stats{LastHandshake: time.Now()}, nil
 }
@@ -130,3 +149,6 @@ func (service *serviceFake) Setup(nat.Options) (rules []interface{}, err error)
 func (service *serviceFake) Del([]interface{}) error { return nil }
 func (service *serviceFake) Enable() error           { return nil }
 func (service *serviceFake) Disable() error          { return nil }
+func (service *serviceFake) BlockedAttempts(net.IPNet) (uint64, error) {
+	return 0, nil
+}
@@ -24,15 +24,20 @@ import (
 	"fmt"
 	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/mysteriumnetwork/node/core/ip"
+	"github.com/mysteriumnetwork/node/core/mtu"
+	"github.com/mysteriumnetwork/node/core/obfuscation"
 	"github.com/mysteriumnetwork/node/core/port"
+	"github.com/mysteriumnetwork/node/core/qos"
 	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/core/shaper"
 	"github.com/mysteriumnetwork/node/dns"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/firewall"
+	"github.com/mysteriumnetwork/node/logconfig"
 	"github.com/mysteriumnetwork/node/nat"
 	natevent "github.com/mysteriumnetwork/node/nat/event"
 	wg "github.com/mysteriumnetwork/node/services/wireguard"
@@ -45,6 +50,9 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// obfuscatedKeepAlivePeriod is how often WireGuard reminds the obfuscation proxy of its address, in seconds.
+const obfuscatedKeepAlivePeriod = 18
+
 // NATEventGetter allows us to fetch the last known NAT event
 type NATEventGetter interface {
 	LastEvent() *natevent.Event
@@ -77,6 +85,7 @@ func NewManager(
 		},
 		country:        country,
 		sessionCleanup: map[string]func(){},
+		options:        options,
 	}
 }
 
@@ -104,25 +113,75 @@ type Manager struct {
 	sessionCleanup   map[string]func()
 	sessionCleanupMu sync.Mutex
 
+	// blockedAttempts accumulates blocked port counters of finished sessions, their rules are gone.
+	blockedAttempts uint64
+
+	// backend is a name of wireguard backend running the latest session tunnel.
+	backend atomic.Value
+
 	country    string
 	outboundIP string
+	options    Options
 }
 
 // ProvideConfig provides the config for consumer and handles new WireGuard connection.
 func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage, remoteConn *net.UDPConn) (*service.ConfigParams, error) {
-	log.Info().Msg("Accepting new WireGuard connection")
+	logger := logconfig.SessionLogger(sessionID, "")
+	logger.Info().Msg("Accepting new WireGuard connection")
 	consumerConfig := wg.ConsumerConfig{}
 	err := json.Unmarshal(sessionConfig, &consumerConfig)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not unmarshal wg consumer config")
 	}
 
-	remoteConn.Close()
+	obfuscationConfig, err := obfuscation.Negotiate(consumerConfig.Obfuscation, m.options.Obfuscators)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not negotiate obfuscation")
+	}
+
+	var pathMTU int
+	if remote, ok := remoteConn.RemoteAddr().(*net.UDPAddr); ok {
+		if pathMTU, err = mtu.Discover(remote); err != nil {
+			logger.Warn().Err(err).Msg("Could not discover path MTU, using the default one")
+		}
+	}
+
+	var proxy *obfuscation.Proxy
 	listenPort := remoteConn.LocalAddr().(*net.UDPAddr).Port
+	if obfuscationConfig != nil {
+		// Obfuscation proxy takes over the connection, WireGuard listens on a random port
+		// and exchanges the plain traffic with the proxy as its peer.
+		proxy, err = obfuscation.NewProviderPeerProxy(*obfuscationConfig, remoteConn)
+		if err != nil {
+			return nil, errors.Wrap(err, "could not start obfuscation proxy")
+		}
+		listenPort = 0
+		if pathMTU == 0 {
+			pathMTU = mtu.Max
+		}
+	} else {
+		remoteConn.Close()
+	}
+	provided := false
+	defer func() {
+		if proxy != nil && !provided {
+			proxy.Close()
+		}
+	}()
+
 	providerConfig, err := m.createProviderConfig(listenPort, consumerConfig.PublicKey)
 	if err != nil {
 		return nil, fmt.Errorf("could not create provider mode wg config: %w", err)
 	}
+	if pathMTU > 0 {
+		providerConfig.MTU = mtu.Wireguard(pathMTU)
+	}
+	if proxy != nil {
+		providerConfig.MTU -= proxy.Overhead()
+		providerConfig.Peer.Endpoint = proxy.PlainAddr()
+		// Proxy learns where to pass the consumer traffic only from WireGuard packets.
+		providerConfig.Peer.KeepAlivePeriodSeconds = obfuscatedKeepAlivePeriod
+	}
 
 	publicIP, err := m.ipResolver.GetPublicIP()
 	if err != nil {
@@ -138,6 +197,12 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	if err != nil {
 		return nil, errors.Wrap(err, "could not get peer config")
 	}
+	config.MTU = providerConfig.MTU
+
+	if proxy != nil {
+		config.Provider.Endpoint.Port = remoteConn.LocalAddr().(*net.UDPAddr).Port
+		config.Obfuscation = obfuscationConfig
+	}
 
 	var dnsIP net.IP
 	var releaseTrafficFirewall firewall.IncomingRuleRemove
@@ -159,6 +224,7 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 		ProviderExtIP:     net.ParseIP(m.outboundIP),
 		EnableDNSRedirect: m.dnsOK,
 		DNSPort:           m.dnsPort,
+		BlockedPorts:      m.options.BlockedPorts,
 	})
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to setup NAT/firewall rules")
@@ -167,41 +233,64 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	statsPublisher := newStatsPublisher(m.eventBus, time.Second)
 	go statsPublisher.start(sessionID, conn)
 
+	var rotator *keyRotator
+	if m.options.KeyRotationInterval > 0 {
+		r := newKeyRotator(m.eventBus, m.options.KeyRotationThreshold, m.options.KeyRotationInterval)
+		rotator = &r
+		go rotator.start(sessionID, config, conn)
+	}
+
 	ifaceName := conn.InterfaceName()
 	s := shaper.New(m.eventBus)
 	err = s.Start(ifaceName)
 	if err != nil {
-		log.Error().Err(err).Msg("Could not start traffic shaper")
+		logger.Error().Err(err).Msg("Could not start traffic shaper")
 	}
 
 	destroy := func() {
-		log.Info().Msgf("Cleaning up session %s", sessionID)
+		logger.Info().Msg("Cleaning up session")
 		m.sessionCleanupMu.Lock()
 		delete(m.sessionCleanup, sessionID)
 		m.sessionCleanupMu.Unlock()
 
 		statsPublisher.stop()
 
+		if rotator != nil {
+			rotator.stop()
+		}
+
 		s.Clear(ifaceName)
 
 		if releaseTrafficFirewall != nil {
 			if err := releaseTrafficFirewall(); err != nil {
-				log.Warn().Err(err).Msg("failed to disable traffic blocking")
+				logger.Warn().Err(err).Msg("failed to disable traffic blocking")
 			}
 		}
 
-		log.Trace().Msg("Deleting nat rules")
+		if blocked, err := m.natService.BlockedAttempts(config.Consumer.IPAddress); err != nil {
+			logger.Warn().Err(err).Msg("Failed to read blocked port counters")
+		} else {
+			atomic.AddUint64(&m.blockedAttempts, blocked)
+		}
+
+		logger.Trace().Msg("Deleting nat rules")
 		if err := m.natService.Del(natRules); err != nil {
-			log.Error().Err(err).Msg("Failed to delete NAT rules")
+			logger.Error().Err(err).Msg("Failed to delete NAT rules")
+		}
+
+		if proxy != nil {
+			if err := proxy.Close(); err != nil {
+				logger.Warn().Err(err).Msg("Failed to close obfuscation proxy")
+			}
 		}
 
-		log.Trace().Msg("Stopping connection endpoint")
+		logger.Trace().Msg("Stopping connection endpoint")
 		if err := conn.Stop(); err != nil {
-			log.Error().Err(err).Msg("Failed to stop connection endpoint")
+			logger.Error().Err(err).Msg("Failed to stop connection endpoint")
 		}
 
 		if err := m.resourcesAllocator.ReleaseIPNet(providerConfig.Subnet); err != nil {
-			log.Error().Err(err).Msg("Failed to release IP network")
+			logger.Error().Err(err).Msg("Failed to release IP network")
 		}
 	}
 
@@ -209,7 +298,18 @@ func (m *Manager) ProvideConfig(sessionID string, sessionConfig json.RawMessage,
 	m.sessionCleanup[sessionID] = destroy
 	m.sessionCleanupMu.Unlock()
 
-	return &service.ConfigParams{SessionServiceConfig: config, SessionDestroyCallback: destroy}, nil
+	params := &service.ConfigParams{
+		SessionServiceConfig:   config,
+		SessionDestroyCallback: destroy,
+		ShapeTraffic: func(class qos.Class) error {
+			return s.Limit(ifaceName, class.LimitKbps)
+		},
+	}
+	if rotator != nil {
+		params.SessionConfigUpdates = rotator.updates
+	}
+	provided = true
+	return params, nil
 }
 
 func (m *Manager) createProviderConfig(listenPort int, peerPublicKey string) (wgcfg.DeviceConfig, error) {
@@ -248,9 +348,25 @@ func (m *Manager) startNewConnection(publicIP string, config wgcfg.DeviceConfig)
 	if err := connEndpoint.StartProviderMode(publicIP, config); err != nil {
 		return nil, errors.Wrap(err, "could not start provider wg connection endpoint")
 	}
+	m.backend.Store(connEndpoint.Backend())
 	return connEndpoint, nil
 }
 
+// BlockedEgressAttempts returns how many packets to blocked destination ports consumers have sent.
+func (m *Manager) BlockedEgressAttempts() uint64 {
+	active, err := m.natService.BlockedAttempts(m.options.Subnet)
+	if err != nil {
+		log.Warn().Err(err).Msg("Failed to read blocked port counters")
+	}
+	return atomic.LoadUint64(&m.blockedAttempts) + active
+}
+
+// Backend returns name of wireguard backend running session tunnels, empty until the first session.
+func (m *Manager) Backend() string {
+	backend, _ := m.backend.Load().(string)
+	return backend
+}
+
 // Serve starts service - does block
 func (m *Manager) Serve(instance *service.Instance) error {
 	log.Info().Msg("Wireguard: starting")
@@ -266,7 +382,7 @@ func (m *Manager) Serve(instance *service.Instance) error {
 	// Start DNS proxy.
 	m.dnsPort = 11253
 	m.dnsOK = false
-	dnsHandler, err := dns.ResolveViaSystem()
+	dnsHandler, err := dns.Resolve(m.options.DNSFilter)
 	if err == nil {
 		if m.serviceInstance.Policies().HasDNSRules() {
 			dnsHandler = dns.WhitelistAnswers(dnsHandler, m.trafficFirewall, instance.Policies())
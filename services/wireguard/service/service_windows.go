@@ -58,6 +58,16 @@ func (manager *Manager) ProvideConfig(_ string, _ json.RawMessage, _ *net.UDPCon
 	return nil, errors.New("not implemented")
 }
 
+// BlockedEgressAttempts returns how many packets to blocked destination ports consumers have sent.
+func (manager *Manager) BlockedEgressAttempts() uint64 {
+	return 0
+}
+
+// Backend returns name of wireguard backend running session tunnels.
+func (manager *Manager) Backend() string {
+	return ""
+}
+
 // Serve starts service - does block
 func (manager *Manager) Serve(_ *service.Instance) error {
 	return errors.New("not implemented")
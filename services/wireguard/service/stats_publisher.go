@@ -45,9 +45,12 @@ func newStatsPublisher(bus eventbus.Publisher, frequency time.Duration) statsPub
 }
 
 func (s statsPublisher) start(sessionID string, supplier statsSupplier) {
+	ticker := time.NewTicker(s.frequency)
+	defer ticker.Stop()
+
 	for {
 		select {
-		case <-time.After(s.frequency):
+		case <-ticker.C:
 			stats, err := supplier.PeerStats()
 			if err != nil {
 				log.Warn().Err(err).Msg("Could not get peer statistics")
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"net"
 
+	"github.com/mysteriumnetwork/node/core/obfuscation"
 	"github.com/mysteriumnetwork/node/market"
 )
 
@@ -35,6 +36,12 @@ type ServiceDefinition struct {
 	// Approximate information on location where the actual tunnelled traffic will originate from.
 	// This is used by providers having their own means of setting tunnels to other remote exit points.
 	LocationOriginate market.Location `json:"location_originate"`
+
+	// Obfuscators of the service traffic supported by provider.
+	Obfuscators []string `json:"obfuscators,omitempty"`
+
+	// Public self-description of the provider
+	Description *market.ProviderDescription `json:"description,omitempty"`
 }
 
 // GetLocation returns geographic location of service definition provider
@@ -42,6 +49,30 @@ func (service ServiceDefinition) GetLocation() market.Location {
 	return service.Location
 }
 
+// WithLocation returns a copy of service definition provided from the given location
+func (service ServiceDefinition) WithLocation(location market.Location) market.ServiceDefinition {
+	service.Location = location
+	service.LocationOriginate = location
+	return service
+}
+
+// GetDescription returns self-description of service definition provider
+func (service ServiceDefinition) GetDescription() market.ProviderDescription {
+	if service.Description == nil {
+		return market.ProviderDescription{}
+	}
+	return *service.Description
+}
+
+// WithDescription returns a copy of service definition carrying the given provider self-description
+func (service ServiceDefinition) WithDescription(description market.ProviderDescription) market.ServiceDefinition {
+	service.Description = nil
+	if !description.IsEmpty() {
+		service.Description = &description
+	}
+	return service
+}
+
 // ServiceConfig represent a Wireguard service provider configuration that will be passed to the consumer for establishing a connection.
 type ServiceConfig struct {
 	// LocalPort and RemotePort are needed for NAT hole punching only.
@@ -57,6 +88,10 @@ type ServiceConfig struct {
 		IPAddress net.IPNet
 		DNSIPs    string
 	}
+	// Obfuscation is set when provider agreed to obfuscate the session traffic.
+	Obfuscation *obfuscation.Config
+	// MTU of the tunnel fitting the path between provider and consumer, zero if provider did not discover it.
+	MTU int
 }
 
 // ConsumerConfig is used for sending the public key and IP from consumer to provider.
@@ -65,6 +100,8 @@ type ConsumerConfig struct {
 	// IP is needed when provider is behind NAT. In such case provider parses this IP and tries to ping consumer.
 	IP    string `json:"IP,omitempty"`
 	Ports []int  `json:"Ports"`
+	// Obfuscation is the obfuscator requested by consumer.
+	Obfuscation string `json:"Obfuscation,omitempty"`
 }
 
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
@@ -79,11 +116,13 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 	}
 
 	return json.Marshal(&struct {
-		LocalPort  int      `json:"local_port"`
-		RemotePort int      `json:"remote_port"`
-		Ports      []int    `json:"ports"`
-		Provider   provider `json:"provider"`
-		Consumer   consumer `json:"consumer"`
+		LocalPort   int                 `json:"local_port"`
+		RemotePort  int                 `json:"remote_port"`
+		Ports       []int               `json:"ports"`
+		Provider    provider            `json:"provider"`
+		Consumer    consumer            `json:"consumer"`
+		Obfuscation *obfuscation.Config `json:"obfuscation,omitempty"`
+		MTU         int                 `json:"mtu,omitempty"`
 	}{
 		Ports:      s.Ports,
 		LocalPort:  s.LocalPort,
@@ -96,6 +135,8 @@ func (s ServiceConfig) MarshalJSON() ([]byte, error) {
 			IPAddress: s.Consumer.IPAddress.String(),
 			DNSIPs:    s.Consumer.DNSIPs,
 		},
+		Obfuscation: s.Obfuscation,
+		MTU:         s.MTU,
 	})
 }
 
@@ -110,11 +151,13 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 		DNSIPs    string `json:"dns_ips"`
 	}
 	var config struct {
-		LocalPort  int      `json:"local_port"`
-		RemotePort int      `json:"remote_port"`
-		Ports      []int    `json:"ports"`
-		Provider   provider `json:"provider"`
-		Consumer   consumer `json:"consumer"`
+		LocalPort   int                 `json:"local_port"`
+		RemotePort  int                 `json:"remote_port"`
+		Ports       []int               `json:"ports"`
+		Provider    provider            `json:"provider"`
+		Consumer    consumer            `json:"consumer"`
+		Obfuscation *obfuscation.Config `json:"obfuscation,omitempty"`
+		MTU         int                 `json:"mtu,omitempty"`
 	}
 
 	if err := json.Unmarshal(data, &config); err != nil {
@@ -138,6 +181,8 @@ func (s *ServiceConfig) UnmarshalJSON(data []byte) error {
 	s.Consumer.DNSIPs = config.Consumer.DNSIPs
 	s.Consumer.IPAddress = *ipnet
 	s.Consumer.IPAddress.IP = ip
+	s.Obfuscation = config.Obfuscation
+	s.MTU = config.MTU
 
 	return nil
 }
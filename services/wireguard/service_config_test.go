@@ -22,6 +22,7 @@ import (
 	"net"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/obfuscation"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	"github.com/stretchr/testify/assert"
@@ -180,3 +181,29 @@ func TestServiceConfig_UnmarshalJSON(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expecteConfig, actualConfig)
 }
+
+func TestServiceConfig_ObfuscationRoundTrip(t *testing.T) {
+	configJSON := json.RawMessage(`{"local_port":0,"remote_port":0,"ports":null,"provider":{"public_key":"wg1","endpoint":"127.0.0.1:51001"},"consumer":{"ip_address":"127.0.0.1/25","dns_ips":""},"obfuscation":{"name":"scramble","key":"AQID"}}`)
+
+	var config ServiceConfig
+	err := json.Unmarshal(configJSON, &config)
+	assert.NoError(t, err)
+	assert.Equal(t, &obfuscation.Config{Name: "scramble", Key: []byte{1, 2, 3}}, config.Obfuscation)
+
+	configBytes, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(configJSON), string(configBytes))
+}
+
+func TestServiceConfig_MTURoundTrip(t *testing.T) {
+	configJSON := json.RawMessage(`{"local_port":0,"remote_port":0,"ports":null,"provider":{"public_key":"wg1","endpoint":"127.0.0.1:51001"},"consumer":{"ip_address":"127.0.0.1/25","dns_ips":""},"mtu":1392}`)
+
+	var config ServiceConfig
+	err := json.Unmarshal(configJSON, &config)
+	assert.NoError(t, err)
+	assert.Equal(t, 1392, config.MTU)
+
+	configBytes, err := json.Marshal(config)
+	assert.NoError(t, err)
+	assert.JSONEq(t, string(configJSON), string(configBytes))
+}
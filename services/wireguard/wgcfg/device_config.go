@@ -45,10 +45,22 @@ type DeviceConfig struct {
 	DNS        []string  `json:"dns"`
 	// Used only for unix.
 	DNSScriptDir string `json:"dns_script_dir"`
+	// MTU of the device, zero keeps the default one.
+	MTU int `json:"mtu"`
+	// RoutingTable routes the tunnel traffic in the given policy routing table, zero uses the main table.
+	RoutingTable int `json:"routing_table"`
+	// PacketObserver observes packets passing through the device, only userspace devices support it.
+	PacketObserver PacketObserver `json:"-"`
 
 	Peer Peer `json:"peer"`
 }
 
+// PacketObserver observes IP packets passing through the device.
+type PacketObserver interface {
+	Outbound(packet []byte)
+	Inbound(packet []byte)
+}
+
 // MarshalJSON implements json.Marshaler interface to provide human readable configuration.
 func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 	type peer struct {
@@ -65,6 +77,8 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 		ListenPort   int      `json:"listen_port"`
 		DNS          []string `json:"dns"`
 		DNSScriptDir string   `json:"dns_script_dir"`
+		MTU          int      `json:"mtu,omitempty"`
+		RoutingTable int      `json:"routing_table,omitempty"`
 		Peer         peer     `json:"peer"`
 	}
 
@@ -80,6 +94,8 @@ func (dc DeviceConfig) MarshalJSON() ([]byte, error) {
 		ListenPort:   dc.ListenPort,
 		DNS:          dc.DNS,
 		DNSScriptDir: dc.DNSScriptDir,
+		MTU:          dc.MTU,
+		RoutingTable: dc.RoutingTable,
 		Peer: peer{
 			PublicKey:              dc.Peer.PublicKey,
 			Endpoint:               peerEndpoint,
@@ -105,6 +121,8 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 		ListenPort   int      `json:"listen_port"`
 		DNS          []string `json:"dns"`
 		DNSScriptDir string   `json:"dns_script_dir"`
+		MTU          int      `json:"mtu,omitempty"`
+		RoutingTable int      `json:"routing_table,omitempty"`
 		Peer         peer     `json:"peer"`
 	}
 
@@ -134,6 +152,8 @@ func (dc *DeviceConfig) UnmarshalJSON(data []byte) error {
 	dc.ListenPort = cfg.ListenPort
 	dc.DNS = cfg.DNS
 	dc.DNSScriptDir = cfg.DNSScriptDir
+	dc.MTU = cfg.MTU
+	dc.RoutingTable = cfg.RoutingTable
 	dc.Peer = Peer{
 		PublicKey:              cfg.Peer.PublicKey,
 		Endpoint:               peerEndpoint,
@@ -161,6 +181,26 @@ func (dc *DeviceConfig) Encode() string {
 	return res.String()
 }
 
+// EncodeReconfigure encodes private key and peer of device config into string representation
+// which is used to reconfigure already running userspace wireguard device. If replacePeers is set
+// all existing device peers are removed before the peer is added.
+func (dc *DeviceConfig) EncodeReconfigure(replacePeers bool) string {
+	var res strings.Builder
+	keyBytes, err := base64.StdEncoding.DecodeString(dc.PrivateKey)
+	if err != nil {
+		log.Err(err).Msg("Could not decode device private key. Will use empty config.")
+		return ""
+	}
+	hexKey := hex.EncodeToString(keyBytes)
+
+	res.WriteString(fmt.Sprintf("private_key=%s\n", hexKey))
+	if replacePeers {
+		res.WriteString("replace_peers=true\n")
+	}
+	res.WriteString(dc.Peer.Encode())
+	return res.String()
+}
+
 // Peer represents wireguard peer.
 type Peer struct {
 	PublicKey              string       `json:"public_key"`
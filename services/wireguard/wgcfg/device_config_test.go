@@ -73,6 +73,31 @@ persistent_keepalive_interval=0
 	}
 }
 
+func TestDeviceConfig_EncodeReconfigure(t *testing.T) {
+	config := DeviceConfig{
+		PrivateKey: "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+		ListenPort: 53511,
+		Peer: Peer{
+			PublicKey:              "DyxwLJ++jVO+azusu7rPEnzdgfm+0fiOBQ1GTbkk3QQ=",
+			AllowedIPs:             []string{"192.168.4.10/32"},
+			KeepAlivePeriodSeconds: 20,
+		},
+	}
+
+	assert.Equal(t, `private_key=0f2c702c9fbe8d53be6b3bacbbbacf127cdd81f9bed1f88e050d464db924dd04
+public_key=0f2c702c9fbe8d53be6b3bacbbbacf127cdd81f9bed1f88e050d464db924dd04
+persistent_keepalive_interval=20
+allowed_ip=192.168.4.10/32
+`, config.EncodeReconfigure(false))
+
+	assert.Equal(t, `private_key=0f2c702c9fbe8d53be6b3bacbbbacf127cdd81f9bed1f88e050d464db924dd04
+replace_peers=true
+public_key=0f2c702c9fbe8d53be6b3bacbbbacf127cdd81f9bed1f88e050d464db924dd04
+persistent_keepalive_interval=20
+allowed_ip=192.168.4.10/32
+`, config.EncodeReconfigure(true))
+}
+
 func TestDeviceConfig_MarshalJSON(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -35,4 +35,7 @@ const (
 
 	// StatusConnectionFailed indicates unknown session connection error.
 	StatusConnectionFailed StatusCode = 2003
+
+	// StatusSessionPolicyDenied indicates that session was refused because consumer location is restricted by service policy.
+	StatusSessionPolicyDenied StatusCode = 2004
 )
@@ -23,15 +23,20 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
 )
 
 const (
 	// AppTopicSession represents the session change topic.
-	AppTopicSession = "Session change"
+	AppTopicSession = "session.changed"
 	// AppTopicDataTransferred represents the data transfer topic.
-	AppTopicDataTransferred = "Session data transferred"
+	AppTopicDataTransferred = "session.data_transferred"
 	// AppTopicTokensEarned is a topic for publish events about tokens earned as a provider.
-	AppTopicTokensEarned = "SessionTokensEarned"
+	AppTopicTokensEarned = "session.tokens_earned"
+	// AppTopicKeyRotated is a topic for publish events about session tunnel key rotation.
+	AppTopicKeyRotated = "session.key_rotated"
+	// AppTopicTrafficDivergence is a topic for publish events about session traffic counters of consumer and provider diverging.
+	AppTopicTrafficDivergence = "session.traffic_divergence"
 )
 
 // AppEventDataTransferred represents the data transfer event
@@ -44,7 +49,22 @@ type AppEventDataTransferred struct {
 type AppEventTokensEarned struct {
 	ProviderID identity.Identity
 	SessionID  string
-	Total      uint64
+	Total      money.Tokens
+}
+
+// AppEventKeyRotated represents session tunnel key rotation event.
+type AppEventKeyRotated struct {
+	SessionID string
+	RotatedAt time.Time
+}
+
+// AppEventTrafficDivergence represents a change of session traffic reconciliation state.
+type AppEventTrafficDivergence struct {
+	SessionID string
+	// Local and Remote are the traffic totals counted by this node and reported by the other party.
+	Local, Remote uint64
+	// Diverged is false once the counters match again.
+	Diverged bool
 }
 
 // Status represents the different actions that might happen on a session
@@ -78,4 +98,17 @@ type SessionContext struct {
 	ConsumerID   identity.Identity
 	AccountantID common.Address
 	Proposal     market.ServiceProposal
+	// ProtocolVersion and Capabilities hold session protocol features negotiated with consumer.
+	ProtocolVersion uint32
+	Capabilities    []string
+	PaymentVersion  string
+	// NATTraversal is the method used to reach consumer, see p2p.Traversal* constants.
+	NATTraversal string
+	// QoSClass is the QoS class of session negotiated with consumer.
+	QoSClass string
+	// TerminationReason is set on removed sessions, see session.Termination* constants.
+	TerminationReason string
+	// TimedOutStage is set on sessions which failed because a handshake stage did not complete in time,
+	// see session.Handshake* constants.
+	TimedOutStage string
 }
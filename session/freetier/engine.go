@@ -0,0 +1,131 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package freetier
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrQuotaExceeded indicates that consumer has used up the free tier quota.
+var ErrQuotaExceeded = errors.New("free tier quota exceeded")
+
+// DefaultCheckInterval is how often running sessions are checked against the quota.
+const DefaultCheckInterval = 10 * time.Second
+
+// Engine is a payment engine which does not charge consumer, but enforces free tier quota instead.
+type Engine struct {
+	consumerID    identity.Identity
+	sessionID     string
+	tracker       *UsageTracker
+	bus           eventbus.Subscriber
+	checkInterval time.Duration
+	now           func() time.Time
+
+	lock      sync.Mutex
+	startedAt time.Time
+	bytes     uint64
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewEngine returns a new instance of free tier payment engine.
+func NewEngine(consumerID identity.Identity, sessionID string, tracker *UsageTracker, bus eventbus.Subscriber, checkInterval time.Duration) *Engine {
+	return &Engine{
+		consumerID:    consumerID,
+		sessionID:     sessionID,
+		tracker:       tracker,
+		bus:           bus,
+		checkInterval: checkInterval,
+		now:           time.Now,
+		stop:          make(chan struct{}),
+	}
+}
+
+// Start enforces the quota until the session is stopped.
+func (e *Engine) Start() error {
+	e.lock.Lock()
+	e.startedAt = e.now()
+	e.lock.Unlock()
+
+	if err := e.bus.Subscribe(sessionEvent.AppTopicDataTransferred, e.consumeDataTransferredEvent); err != nil {
+		return err
+	}
+	defer func() {
+		_ = e.bus.Unsubscribe(sessionEvent.AppTopicDataTransferred, e.consumeDataTransferredEvent)
+		e.tracker.Add(e.consumerID, e.sessionUsage())
+	}()
+
+	ticker := time.NewTicker(e.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-e.stop:
+			return nil
+		case <-ticker.C:
+			if e.tracker.Exhausted(e.consumerID, e.sessionUsage()) {
+				log.Info().Msgf("Consumer %s used up free tier quota in session %s", e.consumerID.Address, e.sessionID)
+				return ErrQuotaExceeded
+			}
+		}
+	}
+}
+
+// WaitFirstInvoice rejects sessions of consumers who have no quota left.
+func (e *Engine) WaitFirstInvoice(time.Duration) error {
+	if e.tracker.Exhausted(e.consumerID, Usage{}) {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// Stop stops the engine.
+func (e *Engine) Stop() {
+	e.stopOnce.Do(func() {
+		close(e.stop)
+	})
+}
+
+func (e *Engine) consumeDataTransferredEvent(ev sessionEvent.AppEventDataTransferred) {
+	if ev.ID != e.sessionID {
+		return
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	e.bytes = ev.Up + ev.Down
+}
+
+func (e *Engine) sessionUsage() Usage {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	return Usage{
+		Duration: e.now().Sub(e.startedAt),
+		Bytes:    e.bytes,
+	}
+}
@@ -0,0 +1,37 @@
+// +build !mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package freetier
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/p2p"
+)
+
+// NewEngineCreator returns a payment engine creator for services offered for free within the quota.
+func NewEngineCreator(tracker *UsageTracker, bus eventbus.Subscriber) service.PaymentEngineCreator {
+	return func(_ *service.Instance, _ p2p.Channel) service.PaymentEngineFactory {
+		return func(_, consumerID identity.Identity, _ common.Address, sessionID string) (service.PaymentEngine, error) {
+			return NewEngine(consumerID, sessionID, tracker, bus, DefaultCheckInterval), nil
+		}
+	}
+}
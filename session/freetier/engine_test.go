@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package freetier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+var consumerID = identity.FromAddress("0x1")
+
+func TestEngine_StopsSessionWhenQuotaExceeded(t *testing.T) {
+	bus := eventbus.New()
+	tracker := NewUsageTracker(Quota{Bytes: 100})
+	engine := NewEngine(consumerID, "session", tracker, bus, time.Millisecond)
+	assert.NoError(t, engine.WaitFirstInvoice(time.Second))
+
+	errCh := make(chan error)
+	go func() { errCh <- engine.Start() }()
+
+	assert.Eventually(t, func() bool {
+		bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "other", Up: 1000})
+		bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "session", Up: 30, Down: 20})
+		return engine.sessionUsage().Bytes == 50
+	}, time.Second, time.Millisecond)
+	bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "session", Up: 60, Down: 40})
+
+	select {
+	case err := <-errCh:
+		assert.Equal(t, ErrQuotaExceeded, err)
+	case <-time.After(time.Second):
+		t.Fatal("engine did not stop on exceeded quota")
+	}
+	assert.Equal(t, uint64(100), tracker.Used(consumerID).Bytes)
+
+	next := NewEngine(consumerID, "next-session", tracker, bus, time.Millisecond)
+	assert.Equal(t, ErrQuotaExceeded, next.WaitFirstInvoice(time.Second))
+}
+
+func TestEngine_RecordsUsageOnStop(t *testing.T) {
+	bus := eventbus.New()
+	tracker := NewUsageTracker(Quota{Bytes: 100})
+	engine := NewEngine(consumerID, "session", tracker, bus, time.Hour)
+
+	errCh := make(chan error)
+	go func() { errCh <- engine.Start() }()
+
+	assert.Eventually(t, func() bool {
+		bus.Publish(sessionEvent.AppTopicDataTransferred, sessionEvent.AppEventDataTransferred{ID: "session", Up: 30, Down: 20})
+		return engine.sessionUsage().Bytes == 50
+	}, time.Second, time.Millisecond)
+	engine.Stop()
+	engine.Stop()
+
+	assert.NoError(t, <-errCh)
+	assert.Equal(t, uint64(50), tracker.Used(consumerID).Bytes)
+	assert.NoError(t, NewEngine(consumerID, "next-session", tracker, bus, time.Hour).WaitFirstInvoice(time.Second))
+}
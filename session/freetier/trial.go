@@ -0,0 +1,100 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package freetier
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/rs/zerolog/log"
+)
+
+// TrialPeriod is how often free trial allowance of a consumer is renewed.
+const TrialPeriod = 24 * time.Hour
+
+type registrationStatusChecker interface {
+	GetRegistrationStatus(id identity.Identity) (registry.RegistrationStatus, error)
+}
+
+// Trials hands out free trial allowances of services to consumers, once per trial period for each service type.
+// Only registered consumers are granted allowance, so it can not be claimed again by creating new identities for free.
+type Trials struct {
+	statusChecker registrationStatusChecker
+	trackers      map[string]*UsageTracker
+	lock          sync.Mutex
+}
+
+// NewTrials returns a new instance of free trial allowance keeper.
+func NewTrials(statusChecker registrationStatusChecker) *Trials {
+	return &Trials{
+		statusChecker: statusChecker,
+		trackers:      make(map[string]*UsageTracker),
+	}
+}
+
+// Grant returns the part of the service allowance consumer has not used in the current trial period.
+func (t *Trials) Grant(serviceType string, consumerID identity.Identity, allowance Usage) Usage {
+	status, err := t.statusChecker.GetRegistrationStatus(consumerID)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Could not check registration of consumer %s, free trial is not granted", consumerID.Address)
+		return Usage{}
+	}
+	if !status.Registered() {
+		log.Info().Msgf("Consumer %s is not registered, free trial is not granted", consumerID.Address)
+		return Usage{}
+	}
+
+	used := t.tracker(serviceType).Used(consumerID)
+	return Usage{
+		Duration: subDuration(allowance.Duration, used.Duration),
+		Bytes:    subBytes(allowance.Bytes, used.Bytes),
+	}
+}
+
+// Record marks the allowance as used by the consumer.
+func (t *Trials) Record(serviceType string, consumerID identity.Identity, used Usage) {
+	t.tracker(serviceType).Add(consumerID, used)
+}
+
+func (t *Trials) tracker(serviceType string) *UsageTracker {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	tracker, ok := t.trackers[serviceType]
+	if !ok {
+		tracker = NewUsageTracker(Quota{Period: TrialPeriod})
+		t.trackers[serviceType] = tracker
+	}
+	return tracker
+}
+
+func subDuration(a, b time.Duration) time.Duration {
+	if b >= a {
+		return 0
+	}
+	return a - b
+}
+
+func subBytes(a, b uint64) uint64 {
+	if b >= a {
+		return 0
+	}
+	return a - b
+}
@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package freetier
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrials_Grant(t *testing.T) {
+	allowance := Usage{Duration: 10 * time.Minute, Bytes: 100}
+	trials := NewTrials(&registry.FakeRegistry{RegistrationStatus: registry.RegisteredConsumer})
+
+	assert.Equal(t, allowance, trials.Grant("wireguard", consumerID, allowance))
+
+	trials.Record("wireguard", consumerID, Usage{Duration: 4 * time.Minute, Bytes: 100})
+	assert.Equal(t, Usage{Duration: 6 * time.Minute}, trials.Grant("wireguard", consumerID, allowance))
+	assert.Equal(t, allowance, trials.Grant("openvpn", consumerID, allowance))
+}
+
+func TestTrials_Grant_RequiresRegisteredConsumer(t *testing.T) {
+	allowance := Usage{Duration: 10 * time.Minute, Bytes: 100}
+
+	trials := NewTrials(&registry.FakeRegistry{RegistrationStatus: registry.Unregistered})
+	assert.Equal(t, Usage{}, trials.Grant("wireguard", consumerID, allowance))
+
+	trials = NewTrials(&registry.FakeRegistry{RegistrationCheckError: errors.New("boom")})
+	assert.Equal(t, Usage{}, trials.Grant("wireguard", consumerID, allowance))
+}
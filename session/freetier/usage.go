@@ -0,0 +1,108 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package freetier
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+)
+
+// Quota limits free usage of a service per consumer.
+type Quota struct {
+	// Duration and Bytes cap session time and traffic, zero means unlimited.
+	Duration time.Duration
+	Bytes    uint64
+	// Period is how long consumed quota is remembered, zero means forever.
+	Period time.Duration
+}
+
+// Usage is the amount of quota consumed.
+type Usage struct {
+	Duration time.Duration
+	Bytes    uint64
+}
+
+// Exceeds checks if the usage is over the given quota.
+func (u Usage) Exceeds(quota Quota) bool {
+	if quota.Duration > 0 && u.Duration >= quota.Duration {
+		return true
+	}
+	if quota.Bytes > 0 && u.Bytes >= quota.Bytes {
+		return true
+	}
+	return false
+}
+
+type consumerUsage struct {
+	Usage
+	since time.Time
+}
+
+// UsageTracker keeps free tier usage of consumers across their sessions.
+type UsageTracker struct {
+	quota Quota
+	now   func() time.Time
+	usage map[identity.Identity]consumerUsage
+	lock  sync.Mutex
+}
+
+// NewUsageTracker returns a new instance of free tier usage tracker.
+func NewUsageTracker(quota Quota) *UsageTracker {
+	return &UsageTracker{
+		quota: quota,
+		now:   time.Now,
+		usage: make(map[identity.Identity]consumerUsage),
+	}
+}
+
+// Used returns the quota already consumed by the given consumer in the current period.
+func (t *UsageTracker) Used(consumerID identity.Identity) Usage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.current(consumerID).Usage
+}
+
+// Add records quota consumed by the given consumer.
+func (t *UsageTracker) Add(consumerID identity.Identity, usage Usage) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	cu := t.current(consumerID)
+	cu.Duration += usage.Duration
+	cu.Bytes += usage.Bytes
+	t.usage[consumerID] = cu
+}
+
+// Exhausted checks if the given consumer is out of quota, counting the usage of a running session.
+func (t *UsageTracker) Exhausted(consumerID identity.Identity, session Usage) bool {
+	used := t.Used(consumerID)
+	used.Duration += session.Duration
+	used.Bytes += session.Bytes
+	return used.Exceeds(t.quota)
+}
+
+func (t *UsageTracker) current(consumerID identity.Identity) consumerUsage {
+	cu, ok := t.usage[consumerID]
+	if !ok || (t.quota.Period > 0 && t.now().Sub(cu.since) >= t.quota.Period) {
+		return consumerUsage{since: t.now()}
+	}
+	return cu
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package freetier
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUsage_Exceeds(t *testing.T) {
+	quota := Quota{Duration: time.Minute, Bytes: 100}
+
+	assert.False(t, Usage{Duration: 59 * time.Second, Bytes: 99}.Exceeds(quota))
+	assert.True(t, Usage{Duration: time.Minute}.Exceeds(quota))
+	assert.True(t, Usage{Bytes: 100}.Exceeds(quota))
+	assert.False(t, Usage{Duration: time.Hour, Bytes: 1000}.Exceeds(Quota{}))
+}
+
+func TestUsageTracker_RenewsQuotaAfterPeriod(t *testing.T) {
+	now := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	tracker := NewUsageTracker(Quota{Bytes: 100, Period: time.Hour})
+	tracker.now = func() time.Time { return now }
+	consumer := identity.FromAddress("0x1")
+
+	tracker.Add(consumer, Usage{Bytes: 60})
+	assert.False(t, tracker.Exhausted(consumer, Usage{}))
+	assert.True(t, tracker.Exhausted(consumer, Usage{Bytes: 40}))
+	assert.False(t, tracker.Exhausted(identity.FromAddress("0x2"), Usage{Bytes: 40}))
+
+	tracker.Add(consumer, Usage{Bytes: 40})
+	assert.True(t, tracker.Exhausted(consumer, Usage{}))
+
+	now = now.Add(time.Hour)
+	assert.Equal(t, Usage{}, tracker.Used(consumer))
+	assert.False(t, tracker.Exhausted(consumer, Usage{}))
+}
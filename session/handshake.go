@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultHandshakeTimeout is the total time allowed for establishing a session.
+const DefaultHandshakeTimeout = 75 * time.Second
+
+// HandshakeStage names a step of session establishment which has its own deadline.
+type HandshakeStage string
+
+const (
+	// HandshakeConfigExchange is the session create request, consumer and provider exchange service configs.
+	// Provider answers it only after the first invoice is paid.
+	HandshakeConfigExchange = HandshakeStage("config_exchange")
+	// HandshakeFirstInvoice is provider waiting for consumer to pay the first invoice.
+	HandshakeFirstInvoice = HandshakeStage("first_invoice")
+	// HandshakeTunnel is consumer establishing the service tunnel.
+	HandshakeTunnel = HandshakeStage("tunnel_handshake")
+)
+
+// HandshakeBudget splits the total time allowed for establishing a session between handshake stages.
+type HandshakeBudget struct {
+	// Total is the time allowed for the whole handshake, DefaultHandshakeTimeout is used if 0.
+	Total time.Duration
+}
+
+// Timeout returns the deadline of a handshake stage. Config exchange and tunnel handshake
+// follow each other on consumer side and share the whole budget, first invoice is paid
+// during config exchange, so it gets a part of the config exchange deadline.
+func (b HandshakeBudget) Timeout(stage HandshakeStage) time.Duration {
+	total := b.Total
+	if total <= 0 {
+		total = DefaultHandshakeTimeout
+	}
+
+	switch stage {
+	case HandshakeConfigExchange:
+		return total * 3 / 5
+	case HandshakeFirstInvoice, HandshakeTunnel:
+		return total * 2 / 5
+	default:
+		return total
+	}
+}
+
+// StageContext returns context which expires at the deadline of a handshake stage.
+func (b HandshakeBudget) StageContext(ctx context.Context, stage HandshakeStage) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, b.Timeout(stage))
+}
+
+// StageError replaces error caused by the expired stage context with HandshakeTimeoutError.
+func (b HandshakeBudget) StageError(ctx context.Context, stage HandshakeStage, err error) error {
+	if err != nil && ctx.Err() == context.DeadlineExceeded {
+		return &HandshakeTimeoutError{Stage: stage, Timeout: b.Timeout(stage)}
+	}
+	return err
+}
+
+// HandshakeTimeoutError is returned when a handshake stage did not complete before its deadline.
+type HandshakeTimeoutError struct {
+	Stage   HandshakeStage
+	Timeout time.Duration
+}
+
+func (e *HandshakeTimeoutError) Error() string {
+	return fmt.Sprintf("session handshake stage %s timed out after %s", e.Stage, e.Timeout)
+}
+
+// TimedOutStage returns handshake stage which caused the error by timing out, empty if the error is not a stage timeout.
+func TimedOutStage(err error) HandshakeStage {
+	var timeoutErr *HandshakeTimeoutError
+	if errors.As(err, &timeoutErr) {
+		return timeoutErr.Stage
+	}
+	return ""
+}
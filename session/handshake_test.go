@@ -0,0 +1,55 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandshakeBudget_Timeout(t *testing.T) {
+	budget := HandshakeBudget{Total: 50 * time.Second}
+
+	assert.Equal(t, 30*time.Second, budget.Timeout(HandshakeConfigExchange))
+	assert.Equal(t, 20*time.Second, budget.Timeout(HandshakeFirstInvoice))
+	assert.Equal(t, 20*time.Second, budget.Timeout(HandshakeTunnel))
+	assert.Equal(t, 45*time.Second, HandshakeBudget{}.Timeout(HandshakeConfigExchange), "zero total should use the default")
+}
+
+func TestHandshakeBudget_StageError(t *testing.T) {
+	budget := HandshakeBudget{Total: 5 * time.Millisecond}
+
+	ctx, cancel := budget.StageContext(context.Background(), HandshakeTunnel)
+	defer cancel()
+	<-ctx.Done()
+
+	err := budget.StageError(ctx, HandshakeTunnel, ctx.Err())
+	assert.EqualError(t, err, "session handshake stage tunnel_handshake timed out after 2ms")
+	assert.Equal(t, HandshakeTunnel, TimedOutStage(fmt.Errorf("connect failed: %w", err)))
+	assert.NoError(t, budget.StageError(ctx, HandshakeTunnel, nil))
+
+	ctx, cancel = budget.StageContext(context.Background(), HandshakeTunnel)
+	cancel()
+	assert.Equal(t, context.Canceled, budget.StageError(ctx, HandshakeTunnel, ctx.Err()))
+	assert.Empty(t, TimedOutStage(errors.New("connection refused")))
+}
@@ -0,0 +1,42 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import "time"
+
+// IdleConfig defines when a session is considered idle and closed automatically.
+type IdleConfig struct {
+	// Timeout is the period over which session traffic is measured, idle sessions are kept if 0.
+	Timeout time.Duration
+	// MinBytes is the least amount of data the session has to transfer during Timeout.
+	MinBytes uint64
+}
+
+// Enabled checks whether idle sessions should be closed.
+func (c IdleConfig) Enabled() bool {
+	return c.Timeout > 0
+}
+
+// IsIdle checks whether too little data was transferred between the previous and the current traffic totals.
+func (c IdleConfig) IsIdle(previous, current uint64) bool {
+	if current < previous {
+		// counters were reset, e.g. after reconnect
+		return false
+	}
+	return current-previous < c.MinBytes
+}
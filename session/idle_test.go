@@ -0,0 +1,39 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdleConfig_Enabled(t *testing.T) {
+	assert.False(t, IdleConfig{}.Enabled())
+	assert.True(t, IdleConfig{Timeout: time.Minute}.Enabled())
+}
+
+func TestIdleConfig_IsIdle(t *testing.T) {
+	config := IdleConfig{Timeout: time.Minute, MinBytes: 100}
+
+	assert.True(t, config.IsIdle(0, 0))
+	assert.True(t, config.IsIdle(1000, 1099))
+	assert.False(t, config.IsIdle(1000, 1100))
+	assert.False(t, config.IsIdle(1000, 10), "counters reset should not be considered idle")
+}
@@ -30,6 +30,7 @@ import (
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -202,6 +203,25 @@ func (ac *AccountantCaller) GetConsumerData(id string) (ConsumerData, error) {
 	return resp, nil
 }
 
+// Ping checks whether accountant is reachable and able to serve requests.
+func (ac *AccountantCaller) Ping() error {
+	req, err := requests.NewGetRequest(ac.accountantBaseURI, "status", nil)
+	if err != nil {
+		return fmt.Errorf("could not form status request: %w", err)
+	}
+
+	resp, err := ac.transport.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not reach accountant: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= http.StatusInternalServerError {
+		return fmt.Errorf("accountant responded with status %v", resp.StatusCode)
+	}
+	return nil
+}
+
 func (ac *AccountantCaller) doRequest(req *http.Request, to interface{}) error {
 	resp, err := ac.transport.Do(req)
 	if err != nil {
@@ -237,29 +257,29 @@ type ConsumerData struct {
 	Identity         string        `json:"Identity"`
 	Beneficiary      string        `json:"Beneficiary"`
 	ChannelID        string        `json:"ChannelID"`
-	Balance          uint64        `json:"Balance"`
-	Promised         uint64        `json:"Promised"`
-	Settled          uint64        `json:"Settled"`
-	Stake            uint64        `json:"Stake"`
+	Balance          money.Tokens  `json:"Balance"`
+	Promised         money.Tokens  `json:"Promised"`
+	Settled          money.Tokens  `json:"Settled"`
+	Stake            money.Tokens  `json:"Stake"`
 	LatestPromise    LatestPromise `json:"LatestPromise"`
 	LatestSettlement time.Time     `json:"LatestSettlement"`
 }
 
 // LatestPromise represents the latest promise
 type LatestPromise struct {
-	ChannelID string      `json:"ChannelID"`
-	Amount    uint64      `json:"Amount"`
-	Fee       uint64      `json:"Fee"`
-	Hashlock  string      `json:"Hashlock"`
-	R         interface{} `json:"R"`
-	Signature string      `json:"Signature"`
+	ChannelID string       `json:"ChannelID"`
+	Amount    money.Tokens `json:"Amount"`
+	Fee       money.Tokens `json:"Fee"`
+	Hashlock  string       `json:"Hashlock"`
+	R         interface{}  `json:"R"`
+	Signature string       `json:"Signature"`
 }
 
 // isValid checks if the promise is really issued by the given identity
 func (lp LatestPromise) isValid(id string) error {
 	// if we've not promised anything, that's fine for us.
 	// handles the case when we've just registered the identity.
-	if lp.Amount == 0 {
+	if lp.Amount.IsZero() {
 		return nil
 	}
 
@@ -276,10 +296,11 @@ func (lp LatestPromise) isValid(id string) error {
 		return fmt.Errorf("could not decode hashlock: %w", err)
 	}
 
+	// Signed promises are limited to uint64, larger amounts fail the signature check.
 	p := crypto.Promise{
 		ChannelID: decodedChannelID,
-		Amount:    lp.Amount,
-		Fee:       lp.Fee,
+		Amount:    lp.Amount.Uint64(),
+		Fee:       lp.Fee.Uint64(),
 		Hashlock:  decodedHashlock,
 		Signature: decodedSignature,
 	}
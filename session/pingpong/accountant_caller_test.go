@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/requests"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/stretchr/testify/assert"
@@ -141,10 +142,22 @@ func TestAccountantGetConsumerData_OK(t *testing.T) {
 	caller := NewAccountantCaller(c, server.URL)
 	data, err := caller.GetConsumerData("0x75C2067Ca5B42467FD6CD789d785aafb52a6B95b")
 	assert.Nil(t, err)
-	res, err := json.Marshal(data)
-	assert.Nil(t, err)
 
-	assert.JSONEq(t, mockConsumerData, string(res))
+	assert.Equal(t, "0x6295502615e5dDfd1FC7bD22EA5b78d65751A835", data.ChannelID)
+	assert.Equal(t, money.NewTokens(12185543791), data.Balance)
+	assert.Equal(t, money.NewTokens(217345248), data.Promised)
+	assert.True(t, data.Settled.IsZero())
+	assert.Equal(t, money.NewTokens(461730032), data.LatestPromise.Amount)
+	assert.Equal(t, "0x31c88b635e72755012289cd04bf9b34a11a95f5962f8f1b15dc4b6b80d4af34a", data.LatestPromise.Hashlock)
+}
+
+func TestConsumerData_UnmarshalsAmountsExceedingUint64(t *testing.T) {
+	var data ConsumerData
+	err := json.Unmarshal([]byte(`{"Balance": 123456789012345678901234, "LatestPromise": {"Amount": "123456789012345678901"}}`), &data)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "123456789012345678901234", data.Balance.String())
+	assert.Equal(t, "123456789012345678901", data.LatestPromise.Amount.String())
 }
 
 var mockConsumerData = `
@@ -236,8 +249,8 @@ func TestLatestPromise_isValid(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			lp := LatestPromise{
 				ChannelID: tt.fields.ChannelID,
-				Amount:    tt.fields.Amount,
-				Fee:       tt.fields.Fee,
+				Amount:    money.NewTokens(tt.fields.Amount),
+				Fee:       money.NewTokens(tt.fields.Fee),
 				Hashlock:  tt.fields.Hashlock,
 				R:         tt.fields.R,
 				Signature: tt.fields.Signature,
@@ -250,3 +263,19 @@ func TestLatestPromise_isValid(t *testing.T) {
 		})
 	}
 }
+
+func TestAccountantCaller_Ping(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/status", r.URL.Path)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	c := requests.NewHTTPClient("0.0.0.0", time.Second)
+	caller := NewAccountantCaller(c, server.URL)
+	assert.NoError(t, caller.Ping())
+
+	status = http.StatusServiceUnavailable
+	assert.Error(t, caller.Ping())
+}
@@ -22,6 +22,7 @@ import (
 	"encoding/json"
 	stdErr "errors"
 	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -30,6 +31,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/money"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	pinge "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -51,6 +53,11 @@ type accountantCaller interface {
 	RevealR(r string, provider string, agreementID uint64) error
 }
 
+type promiseJournal interface {
+	Begin(accountantID common.Address, em crypto.ExchangeMessage, sessionID string) error
+	Commit(em crypto.ExchangeMessage) error
+}
+
 type encryption interface {
 	Decrypt(addr common.Address, encrypted []byte) ([]byte, error)
 	Encrypt(addr common.Address, plaintext []byte) ([]byte, error)
@@ -64,6 +71,9 @@ type AccountantPromiseHandlerDeps struct {
 	FeeProvider              feeProvider
 	Encryption               encryption
 	EventBus                 eventbus.Publisher
+	PromiseJournal           promiseJournal
+	// AdditionalAccountants are accountants besides the main one which provider accepts sessions from.
+	AdditionalAccountants map[common.Address]*AccountantCaller
 }
 
 // AccountantPromiseHandler handles the accountant promises for ongoing sessions.
@@ -93,6 +103,20 @@ type enqueuedRequest struct {
 	sessionID  string
 }
 
+// accountantFor returns the accountant exchange message is addressed to, the main one if not specified.
+func (aph *AccountantPromiseHandler) accountantFor(em crypto.ExchangeMessage) (common.Address, accountantCaller, error) {
+	if em.HermesID == "" || strings.EqualFold(em.HermesID, aph.deps.AccountantID.Hex()) {
+		return aph.deps.AccountantID, aph.deps.AccountantCaller, nil
+	}
+
+	accountantID := common.HexToAddress(em.HermesID)
+	caller, ok := aph.deps.AdditionalAccountants[accountantID]
+	if !ok {
+		return common.Address{}, nil, fmt.Errorf("unsupported accountant %v", em.HermesID)
+	}
+	return accountantID, caller, nil
+}
+
 // RequestPromise adds the request to the queue.
 func (aph *AccountantPromiseHandler) RequestPromise(r []byte, em crypto.ExchangeMessage, providerID identity.Identity, sessionID string) <-chan error {
 	er := enqueuedRequest{
@@ -169,6 +193,18 @@ func (aph *AccountantPromiseHandler) handleNodeStopEvents(e event.Payload) {
 func (aph *AccountantPromiseHandler) requestPromise(er enqueuedRequest) {
 	defer func() { close(er.errChan) }()
 
+	accountantID, caller, err := aph.accountantFor(er.em)
+	if err != nil {
+		er.errChan <- err
+		return
+	}
+
+	// journal the consumer promise before exchanging it, so that it is not accepted twice even if we crash in between
+	if err := aph.deps.PromiseJournal.Begin(accountantID, er.em, er.sessionID); err != nil {
+		er.errChan <- fmt.Errorf("could not journal consumer promise: %w", err)
+		return
+	}
+
 	if !aph.transactorFee.IsValid() {
 		aph.updateFee()
 	}
@@ -196,8 +232,8 @@ func (aph *AccountantPromiseHandler) requestPromise(er enqueuedRequest) {
 		RRecoveryData:   hex.EncodeToString(encrypted),
 	}
 
-	promise, err := aph.deps.AccountantCaller.RequestPromise(request)
-	err = aph.handleAccountantError(err, er.providerID)
+	promise, err := caller.RequestPromise(request)
+	err = aph.handleAccountantError(err, er.providerID, caller)
 	if err != nil {
 		er.errChan <- fmt.Errorf("accountant request promise error: %w", err)
 		return
@@ -210,34 +246,38 @@ func (aph *AccountantPromiseHandler) requestPromise(er enqueuedRequest) {
 		AgreementID: er.em.AgreementID,
 	}
 
-	err = aph.deps.AccountantPromiseStorage.Store(er.providerID, aph.deps.AccountantID, ap)
+	err = aph.deps.AccountantPromiseStorage.Store(er.providerID, accountantID, ap)
 	if err != nil && !stdErr.Is(err, ErrAttemptToOverwrite) {
 		er.errChan <- fmt.Errorf("could not store accountant promise: %w", err)
 		return
 	}
 
+	if err := aph.deps.PromiseJournal.Commit(er.em); err != nil {
+		log.Error().Err(err).Msg("Could not commit consumer promise to journal")
+	}
+
 	aph.deps.EventBus.Publish(pinge.AppTopicAccountantPromise, pinge.AppEventAccountantPromise{
 		Promise:      promise,
-		AccountantID: aph.deps.AccountantID,
+		AccountantID: accountantID,
 		ProviderID:   er.providerID,
 	})
 	aph.deps.EventBus.Publish(sessionEvent.AppTopicTokensEarned, sessionEvent.AppEventTokensEarned{
 		ProviderID: er.providerID,
 		SessionID:  er.sessionID,
-		Total:      er.em.AgreementTotal,
+		Total:      money.NewTokens(er.em.AgreementTotal),
 	})
 
-	err = aph.revealR(er.providerID)
-	err = aph.handleAccountantError(err, er.providerID)
+	err = aph.revealR(er.providerID, accountantID, caller)
+	err = aph.handleAccountantError(err, er.providerID, caller)
 	if err != nil {
 		er.errChan <- fmt.Errorf("accountant reveal r error: %w", err)
 		return
 	}
 }
 
-func (aph *AccountantPromiseHandler) revealR(providerID identity.Identity) error {
+func (aph *AccountantPromiseHandler) revealR(providerID identity.Identity, accountantID common.Address, caller accountantCaller) error {
 	needsRevealing := false
-	accountantPromise, err := aph.deps.AccountantPromiseStorage.Get(providerID, aph.deps.AccountantID)
+	accountantPromise, err := aph.deps.AccountantPromiseStorage.Get(providerID, accountantID)
 	switch err {
 	case nil:
 		needsRevealing = !accountantPromise.Revealed
@@ -251,14 +291,14 @@ func (aph *AccountantPromiseHandler) revealR(providerID identity.Identity) error
 		return nil
 	}
 
-	err = aph.deps.AccountantCaller.RevealR(accountantPromise.R, providerID.Address, accountantPromise.AgreementID)
-	handledErr := aph.handleAccountantError(err, providerID)
+	err = caller.RevealR(accountantPromise.R, providerID.Address, accountantPromise.AgreementID)
+	handledErr := aph.handleAccountantError(err, providerID, caller)
 	if handledErr != nil {
 		return fmt.Errorf("could not reveal R: %w", err)
 	}
 
 	accountantPromise.Revealed = true
-	err = aph.deps.AccountantPromiseStorage.Store(providerID, aph.deps.AccountantID, accountantPromise)
+	err = aph.deps.AccountantPromiseStorage.Store(providerID, accountantID, accountantPromise)
 	if err != nil && !stdErr.Is(err, ErrAttemptToOverwrite) {
 		return fmt.Errorf("could not store accountant promise: %w", err)
 	}
@@ -266,7 +306,7 @@ func (aph *AccountantPromiseHandler) revealR(providerID identity.Identity) error
 	return nil
 }
 
-func (aph *AccountantPromiseHandler) handleAccountantError(err error, providerID identity.Identity) error {
+func (aph *AccountantPromiseHandler) handleAccountantError(err error, providerID identity.Identity, caller accountantCaller) error {
 	if err == nil {
 		return nil
 	}
@@ -278,7 +318,7 @@ func (aph *AccountantPromiseHandler) handleAccountantError(err error, providerID
 		if !ok {
 			return errors.New("could not cast errNeedsRecovery to accountantError")
 		}
-		recoveryErr := aph.recoverR(aer, providerID)
+		recoveryErr := aph.recoverR(aer, providerID, caller)
 		if recoveryErr != nil {
 			return recoveryErr
 		}
@@ -291,7 +331,7 @@ func (aph *AccountantPromiseHandler) handleAccountantError(err error, providerID
 	}
 }
 
-func (aph *AccountantPromiseHandler) recoverR(aerr accountantError, providerID identity.Identity) error {
+func (aph *AccountantPromiseHandler) recoverR(aerr accountantError, providerID identity.Identity, caller accountantCaller) error {
 	log.Info().Msg("Recovering R...")
 	decoded, err := hex.DecodeString(aerr.Data())
 	if err != nil {
@@ -310,7 +350,7 @@ func (aph *AccountantPromiseHandler) recoverR(aerr accountantError, providerID i
 	}
 
 	log.Info().Msg("R recovered, will reveal...")
-	err = aph.deps.AccountantCaller.RevealR(res.R, providerID.Address, res.AgreementID)
+	err = caller.RevealR(res.R, providerID.Address, res.AgreementID)
 	if err != nil {
 		return fmt.Errorf("could not reveal R: %w", err)
 	}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/node/event"
 	"github.com/mysteriumnetwork/node/core/service/servicestate"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -40,6 +41,7 @@ func TestAccountantPromiseHandler_RequestPromise(t *testing.T) {
 			EventBus:                 eventbus.New(),
 			AccountantPromiseStorage: &mockAccountantPromiseStorage{},
 			FeeProvider:              &mockFeeProvider{},
+			PromiseJournal:           &mockPromiseJournal{},
 		},
 		queue: make(chan enqueuedRequest),
 		stop:  make(chan struct{}),
@@ -78,6 +80,7 @@ func TestAccountantPromiseHandler_RequestPromise_BubblesErrors(t *testing.T) {
 			EventBus:                 bus,
 			AccountantPromiseStorage: &mockAccountantPromiseStorage{},
 			FeeProvider:              &mockFeeProvider{},
+			PromiseJournal:           &mockPromiseJournal{},
 		},
 		queue: make(chan enqueuedRequest),
 		stop:  make(chan struct{}),
@@ -107,6 +110,40 @@ func TestAccountantPromiseHandler_RequestPromise_BubblesErrors(t *testing.T) {
 	assert.Nil(t, err)
 }
 
+func TestAccountantPromiseHandler_RequestPromise_RejectsJournaledPromise(t *testing.T) {
+	bus := eventbus.New()
+	aph := &AccountantPromiseHandler{
+		deps: AccountantPromiseHandlerDeps{
+			AccountantCaller:         &mockAccountantCaller{},
+			Encryption:               &mockEncryptor{},
+			EventBus:                 bus,
+			AccountantPromiseStorage: &mockAccountantPromiseStorage{},
+			FeeProvider:              &mockFeeProvider{},
+			PromiseJournal:           &mockPromiseJournal{beginErr: ErrConsumerPromiseRollback},
+		},
+		queue: make(chan enqueuedRequest),
+		stop:  make(chan struct{}),
+	}
+	err := aph.Subscribe(bus)
+	assert.NoError(t, err)
+	bus.Publish(servicestate.AppTopicServiceStatus, servicestate.AppEventServiceStatus{
+		Status: string(servicestate.Running),
+	})
+	defer bus.Publish(event.AppTopicNode, event.Payload{
+		Status: event.StatusStopped,
+	})
+
+	ch := aph.RequestPromise([]byte{0x0, 0x1}, crypto.ExchangeMessage{}, identity.FromAddress("asddadadqweqwe"), "session")
+
+	err, more := <-ch
+	assert.True(t, more)
+	assert.True(t, errors.Is(err, ErrConsumerPromiseRollback))
+
+	err, more = <-ch
+	assert.False(t, more)
+	assert.Nil(t, err)
+}
+
 func TestAccountantPromiseHandler_recoverR(t *testing.T) {
 	type fields struct {
 		deps       AccountantPromiseHandlerDeps
@@ -179,7 +216,7 @@ func TestAccountantPromiseHandler_recoverR(t *testing.T) {
 			it := &AccountantPromiseHandler{
 				deps: tt.fields.deps,
 			}
-			if err := it.recoverR(tt.err, tt.fields.providerID); (err != nil) != tt.wantErr {
+			if err := it.recoverR(tt.err, tt.fields.providerID, it.deps.AccountantCaller); (err != nil) != tt.wantErr {
 				t.Errorf("AccountantPromiseHandler.recoverR() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
@@ -230,7 +267,7 @@ func TestAccountantPromiseHandler_handleAccountantError(t *testing.T) {
 			aph := &AccountantPromiseHandler{
 				deps: tt.deps,
 			}
-			err := aph.handleAccountantError(tt.err, tt.providerID)
+			err := aph.handleAccountantError(tt.err, tt.providerID, aph.deps.AccountantCaller)
 			if tt.wantErr == nil {
 				assert.NoError(t, err, tt.name)
 			} else {
@@ -249,3 +286,17 @@ type mockFeeProvider struct {
 func (mfp *mockFeeProvider) FetchSettleFees() (registry.FeesResponse, error) {
 	return mfp.toReturn, mfp.errToReturn
 }
+
+type mockPromiseJournal struct {
+	beginErr  error
+	committed []crypto.ExchangeMessage
+}
+
+func (mpj *mockPromiseJournal) Begin(_ common.Address, _ crypto.ExchangeMessage, _ string) error {
+	return mpj.beginErr
+}
+
+func (mpj *mockPromiseJournal) Commit(em crypto.ExchangeMessage) error {
+	mpj.committed = append(mpj.committed, em)
+	return nil
+}
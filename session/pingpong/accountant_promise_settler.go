@@ -30,6 +30,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/bindings"
 	"github.com/mysteriumnetwork/payments/client"
@@ -74,6 +75,7 @@ type receivedPromise struct {
 // AccountantPromiseSettler is responsible for settling the accountant promises.
 type AccountantPromiseSettler interface {
 	GetEarnings(id identity.Identity) event.Earnings
+	GetEarningsPerAccountant(id identity.Identity) map[common.Address]event.Earnings
 	ForceSettle(providerID identity.Identity, accountantID common.Address) error
 	SettleWithBeneficiary(providerID identity.Identity, beneficiary, accountantID common.Address) error
 	Subscribe() error
@@ -177,9 +179,10 @@ func (aps *accountantPromiseSettler) resyncState(id identity.Identity) error {
 
 func (aps *accountantPromiseSettler) publishChangeEvent(id identity.Identity, before, after settlementState) {
 	aps.eventBus.Publish(event.AppTopicEarningsChanged, event.AppEventEarningsChanged{
-		Identity: id,
-		Previous: before.Earnings(),
-		Current:  after.Earnings(),
+		Identity:     id,
+		AccountantID: aps.config.AccountantAddress,
+		Previous:     before.Earnings(),
+		Current:      after.Earnings(),
 	})
 }
 
@@ -210,6 +213,10 @@ func (aps *accountantPromiseSettler) Subscribe() error {
 }
 
 func (aps *accountantPromiseSettler) handleSettlementEvent(event event.AppEventSettlementRequest) {
+	if event.AccountantID != aps.config.AccountantAddress {
+		return
+	}
+
 	err := aps.ForceSettle(event.ProviderID, event.AccountantID)
 	if err != nil {
 		log.Error().Err(err).Msg("could not settle promise")
@@ -260,6 +267,10 @@ func (aps *accountantPromiseSettler) handleRegistrationEvent(payload registry.Ap
 }
 
 func (aps *accountantPromiseSettler) handleAccountantPromiseReceived(apep event.AppEventAccountantPromise) {
+	if apep.AccountantID != aps.config.AccountantAddress {
+		return
+	}
+
 	id := apep.ProviderID
 	log.Info().Msgf("Received accountant promise for %q", id)
 	aps.lock.Lock()
@@ -333,6 +344,13 @@ func (aps *accountantPromiseSettler) GetEarnings(id identity.Identity) event.Ear
 	return aps.currentState[id].Earnings()
 }
 
+// GetEarningsPerAccountant returns current settlement status for given identity by accountant
+func (aps *accountantPromiseSettler) GetEarningsPerAccountant(id identity.Identity) map[common.Address]event.Earnings {
+	return map[common.Address]event.Earnings{
+		aps.config.AccountantAddress: aps.GetEarnings(id),
+	}
+}
+
 // ErrNothingToSettle indicates that there is nothing to settle.
 var ErrNothingToSettle = errors.New("nothing to settle for the given provider")
 
@@ -574,7 +592,7 @@ func (ss settlementState) needsSettling(threshold float64) bool {
 
 func (ss settlementState) Earnings() event.Earnings {
 	return event.Earnings{
-		LifetimeBalance:  ss.lifetimeBalance(),
-		UnsettledBalance: ss.unsettledBalance(),
+		LifetimeBalance:  money.NewTokens(ss.lifetimeBalance()),
+		UnsettledBalance: money.NewTokens(ss.unsettledBalance()),
 	}
 }
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+)
+
+// multiAccountantPromiseSettler settles promises of every accountant provider works with,
+// delegating to the settler of the accountant in question.
+type multiAccountantPromiseSettler struct {
+	main     common.Address
+	settlers map[common.Address]AccountantPromiseSettler
+}
+
+// NewMultiAccountantPromiseSettler combines settlers of several accountants into one, fees are reported by the main accountant.
+func NewMultiAccountantPromiseSettler(main common.Address, settlers map[common.Address]AccountantPromiseSettler) AccountantPromiseSettler {
+	return &multiAccountantPromiseSettler{
+		main:     main,
+		settlers: settlers,
+	}
+}
+
+// Subscribe subscribes settlers of all accountants to the appropriate events.
+func (m *multiAccountantPromiseSettler) Subscribe() error {
+	for accountantID, settler := range m.settlers {
+		if err := settler.Subscribe(); err != nil {
+			return fmt.Errorf("could not subscribe settler of accountant %v: %w", accountantID.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// GetEarnings returns earnings of given identity summed over all accountants.
+func (m *multiAccountantPromiseSettler) GetEarnings(id identity.Identity) event.Earnings {
+	var total event.Earnings
+	for _, earnings := range m.GetEarningsPerAccountant(id) {
+		total.LifetimeBalance = total.LifetimeBalance.Add(earnings.LifetimeBalance)
+		total.UnsettledBalance = total.UnsettledBalance.Add(earnings.UnsettledBalance)
+	}
+	return total
+}
+
+// GetEarningsPerAccountant returns earnings of given identity by accountant.
+func (m *multiAccountantPromiseSettler) GetEarningsPerAccountant(id identity.Identity) map[common.Address]event.Earnings {
+	earnings := make(map[common.Address]event.Earnings, len(m.settlers))
+	for _, settler := range m.settlers {
+		for accountantID, e := range settler.GetEarningsPerAccountant(id) {
+			earnings[accountantID] = e
+		}
+	}
+	return earnings
+}
+
+// ForceSettle forces the settlement of promises issued by the given accountant.
+func (m *multiAccountantPromiseSettler) ForceSettle(providerID identity.Identity, accountantID common.Address) error {
+	settler, err := m.settler(accountantID)
+	if err != nil {
+		return err
+	}
+	return settler.ForceSettle(providerID, accountantID)
+}
+
+// SettleWithBeneficiary settles promises issued by the given accountant setting a new beneficiary.
+func (m *multiAccountantPromiseSettler) SettleWithBeneficiary(providerID identity.Identity, beneficiary, accountantID common.Address) error {
+	settler, err := m.settler(accountantID)
+	if err != nil {
+		return err
+	}
+	return settler.SettleWithBeneficiary(providerID, beneficiary, accountantID)
+}
+
+// GetAccountantFee fetches the fee of the main accountant.
+func (m *multiAccountantPromiseSettler) GetAccountantFee() (uint16, error) {
+	settler, err := m.settler(m.main)
+	if err != nil {
+		return 0, err
+	}
+	return settler.GetAccountantFee()
+}
+
+func (m *multiAccountantPromiseSettler) settler(accountantID common.Address) (AccountantPromiseSettler, error) {
+	settler, ok := m.settlers[accountantID]
+	if !ok {
+		return nil, fmt.Errorf("unsupported accountant %v", accountantID.Hex())
+	}
+	return settler, nil
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/session/pingpong/event"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockSingleSettler struct {
+	accountantID common.Address
+	earnings     event.Earnings
+	fee          uint16
+	settled      bool
+}
+
+func (mss *mockSingleSettler) Subscribe() error {
+	return nil
+}
+
+func (mss *mockSingleSettler) GetEarnings(_ identity.Identity) event.Earnings {
+	return mss.earnings
+}
+
+func (mss *mockSingleSettler) GetEarningsPerAccountant(_ identity.Identity) map[common.Address]event.Earnings {
+	return map[common.Address]event.Earnings{mss.accountantID: mss.earnings}
+}
+
+func (mss *mockSingleSettler) ForceSettle(_ identity.Identity, _ common.Address) error {
+	mss.settled = true
+	return nil
+}
+
+func (mss *mockSingleSettler) SettleWithBeneficiary(_ identity.Identity, _, _ common.Address) error {
+	mss.settled = true
+	return nil
+}
+
+func (mss *mockSingleSettler) GetAccountantFee() (uint16, error) {
+	return mss.fee, nil
+}
+
+func TestMultiAccountantPromiseSettler(t *testing.T) {
+	main := common.HexToAddress("0x1")
+	fallback := common.HexToAddress("0x2")
+	mainSettler := &mockSingleSettler{accountantID: main, earnings: event.Earnings{LifetimeBalance: money.NewTokens(10), UnsettledBalance: money.NewTokens(1)}, fee: 100}
+	fallbackSettler := &mockSingleSettler{accountantID: fallback, earnings: event.Earnings{LifetimeBalance: money.NewTokens(20), UnsettledBalance: money.NewTokens(2)}, fee: 200}
+	settler := NewMultiAccountantPromiseSettler(main, map[common.Address]AccountantPromiseSettler{
+		main:     mainSettler,
+		fallback: fallbackSettler,
+	})
+	id := identity.FromAddress("0xf")
+
+	assert.Equal(t, event.Earnings{LifetimeBalance: money.NewTokens(30), UnsettledBalance: money.NewTokens(3)}, settler.GetEarnings(id))
+	assert.Equal(t, map[common.Address]event.Earnings{
+		main:     mainSettler.earnings,
+		fallback: fallbackSettler.earnings,
+	}, settler.GetEarningsPerAccountant(id))
+
+	fee, err := settler.GetAccountantFee()
+	assert.NoError(t, err)
+	assert.Equal(t, uint16(100), fee)
+
+	assert.NoError(t, settler.ForceSettle(id, fallback))
+	assert.True(t, fallbackSettler.settled)
+	assert.False(t, mainSettler.settled)
+
+	assert.Error(t, settler.ForceSettle(id, common.HexToAddress("0x3")))
+}
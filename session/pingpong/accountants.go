@@ -0,0 +1,200 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/rs/zerolog/log"
+)
+
+// ErrNoHealthyAccountant indicates that none of the configured accountants is reachable.
+var ErrNoHealthyAccountant = errors.New("no healthy accountant available")
+
+// AccountantEndpoint describes an accountant and the address of its API.
+type AccountantEndpoint struct {
+	ID      common.Address
+	Address string
+}
+
+type accountantPinger interface {
+	Ping() error
+}
+
+// Accountants keeps the accountants node works with: the main one goes first and the rest are fallbacks.
+// Providers accept sessions accounted by any of them, consumers pick the first one that is healthy.
+type Accountants struct {
+	endpoints []AccountantEndpoint
+	callers   map[common.Address]*AccountantCaller
+	pingers   map[common.Address]accountantPinger
+
+	lock    sync.RWMutex
+	healthy map[common.Address]bool
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewAccountants creates accountants from the given endpoints, the first endpoint is the main accountant.
+func NewAccountants(transport *requests.HTTPClient, endpoints []AccountantEndpoint) *Accountants {
+	callers := make(map[common.Address]*AccountantCaller, len(endpoints))
+	pingers := make(map[common.Address]accountantPinger, len(endpoints))
+	for _, endpoint := range endpoints {
+		caller := NewAccountantCaller(transport, endpoint.Address)
+		callers[endpoint.ID] = caller
+		pingers[endpoint.ID] = caller
+	}
+	return newAccountants(endpoints, callers, pingers)
+}
+
+func newAccountants(endpoints []AccountantEndpoint, callers map[common.Address]*AccountantCaller, pingers map[common.Address]accountantPinger) *Accountants {
+	// accountants are assumed to be healthy until checked otherwise
+	healthy := make(map[common.Address]bool, len(endpoints))
+	for _, endpoint := range endpoints {
+		healthy[endpoint.ID] = true
+	}
+
+	return &Accountants{
+		endpoints: endpoints,
+		callers:   callers,
+		pingers:   pingers,
+		healthy:   healthy,
+		stop:      make(chan struct{}),
+	}
+}
+
+// Main returns the main accountant.
+func (a *Accountants) Main() common.Address {
+	return a.endpoints[0].ID
+}
+
+// IDs returns all accountants, the main one goes first.
+func (a *Accountants) IDs() []common.Address {
+	ids := make([]common.Address, len(a.endpoints))
+	for i, endpoint := range a.endpoints {
+		ids[i] = endpoint.ID
+	}
+	return ids
+}
+
+// Allowed tells whether the accountant is one of the configured accountants.
+func (a *Accountants) Allowed(id common.Address) bool {
+	_, ok := a.callers[id]
+	return ok
+}
+
+// Caller returns API caller of the accountant.
+func (a *Accountants) Caller(id common.Address) (*AccountantCaller, bool) {
+	caller, ok := a.callers[id]
+	return caller, ok
+}
+
+// FallbackCallers returns API callers of all accountants except the main one.
+func (a *Accountants) FallbackCallers() map[common.Address]*AccountantCaller {
+	callers := make(map[common.Address]*AccountantCaller, len(a.endpoints)-1)
+	for _, endpoint := range a.endpoints[1:] {
+		callers[endpoint.ID] = a.callers[endpoint.ID]
+	}
+	return callers
+}
+
+// Healthy tells whether the accountant responded to the last health check.
+func (a *Accountants) Healthy(id common.Address) bool {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.healthy[id]
+}
+
+// Pick returns the first healthy accountant, preferring the main one.
+func (a *Accountants) Pick() (common.Address, error) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	for _, endpoint := range a.endpoints {
+		if a.healthy[endpoint.ID] {
+			return endpoint.ID, nil
+		}
+	}
+	return common.Address{}, ErrNoHealthyAccountant
+}
+
+// Check checks health of all accountants.
+func (a *Accountants) Check() {
+	var wg sync.WaitGroup
+	for _, endpoint := range a.endpoints {
+		wg.Add(1)
+		go func(endpoint AccountantEndpoint) {
+			defer wg.Done()
+			err := a.pingers[endpoint.ID].Ping()
+			a.setHealthy(endpoint, err)
+		}(endpoint)
+	}
+	wg.Wait()
+}
+
+func (a *Accountants) setHealthy(endpoint AccountantEndpoint, err error) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+
+	healthy := err == nil
+	if a.healthy[endpoint.ID] != healthy {
+		if healthy {
+			log.Info().Msgf("Accountant %s (%s) is healthy again", endpoint.ID.Hex(), endpoint.Address)
+		} else {
+			log.Warn().Err(err).Msgf("Accountant %s (%s) is unhealthy", endpoint.ID.Hex(), endpoint.Address)
+		}
+	}
+	a.healthy[endpoint.ID] = healthy
+}
+
+// Start checks health of accountants periodically until stopped.
+func (a *Accountants) Start(interval time.Duration) {
+	a.Check()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stop:
+			return
+		case <-ticker.C:
+			a.Check()
+		}
+	}
+}
+
+// Stop stops health checks.
+func (a *Accountants) Stop() {
+	a.stopOnce.Do(func() {
+		close(a.stop)
+	})
+}
+
+// GetConsumerData fetches consumer data from the given accountant.
+func (a *Accountants) GetConsumerData(accountantID common.Address, consumerID string) (ConsumerData, error) {
+	caller, ok := a.callers[accountantID]
+	if !ok {
+		return ConsumerData{}, fmt.Errorf("unsupported accountant %v", accountantID.Hex())
+	}
+	return caller.GetConsumerData(consumerID)
+}
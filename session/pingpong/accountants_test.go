@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockAccountantPinger struct {
+	lock sync.Mutex
+	err  error
+}
+
+func (mp *mockAccountantPinger) Ping() error {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	return mp.err
+}
+
+func (mp *mockAccountantPinger) setErr(err error) {
+	mp.lock.Lock()
+	defer mp.lock.Unlock()
+	mp.err = err
+}
+
+func TestAccountants_Pick(t *testing.T) {
+	main := common.HexToAddress("0x1")
+	fallback := common.HexToAddress("0x2")
+	mainPinger := &mockAccountantPinger{}
+	fallbackPinger := &mockAccountantPinger{}
+
+	accountants := newAccountants(
+		[]AccountantEndpoint{{ID: main, Address: "main"}, {ID: fallback, Address: "fallback"}},
+		map[common.Address]*AccountantCaller{main: {}, fallback: {}},
+		map[common.Address]accountantPinger{main: mainPinger, fallback: fallbackPinger},
+	)
+	assert.Equal(t, main, accountants.Main())
+	assert.Equal(t, []common.Address{main, fallback}, accountants.IDs())
+	assert.True(t, accountants.Allowed(fallback))
+	assert.False(t, accountants.Allowed(common.HexToAddress("0x3")))
+	assert.Len(t, accountants.FallbackCallers(), 1)
+	assert.Contains(t, accountants.FallbackCallers(), fallback)
+
+	picked, err := accountants.Pick()
+	assert.NoError(t, err)
+	assert.Equal(t, main, picked)
+
+	mainPinger.setErr(errors.New("boom"))
+	accountants.Check()
+	assert.False(t, accountants.Healthy(main))
+	picked, err = accountants.Pick()
+	assert.NoError(t, err)
+	assert.Equal(t, fallback, picked)
+
+	fallbackPinger.setErr(errors.New("boom"))
+	accountants.Check()
+	_, err = accountants.Pick()
+	assert.Equal(t, ErrNoHealthyAccountant, err)
+
+	mainPinger.setErr(nil)
+	accountants.Check()
+	picked, err = accountants.Pick()
+	assert.NoError(t, err)
+	assert.Equal(t, main, picked)
+}
+
+func TestSessionAccountant(t *testing.T) {
+	main := common.HexToAddress("0x1")
+	fallback := common.HexToAddress("0x2")
+	providersAccountants := []common.Address{main, fallback}
+
+	assert.Equal(t, main, sessionAccountant(main, providersAccountants))
+	assert.Equal(t, fallback, sessionAccountant(fallback, providersAccountants))
+	assert.Equal(t, main, sessionAccountant(common.HexToAddress("0x3"), providersAccountants))
+}
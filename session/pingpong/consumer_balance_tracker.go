@@ -31,6 +31,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/bindings"
 	"github.com/mysteriumnetwork/payments/client"
@@ -119,15 +120,15 @@ func (cbt *ConsumerBalanceTracker) Subscribe(bus eventbus.Subscriber) error {
 }
 
 // GetBalance gets the current balance for given identity
-func (cbt *ConsumerBalanceTracker) GetBalance(id identity.Identity) uint64 {
+func (cbt *ConsumerBalanceTracker) GetBalance(id identity.Identity) money.Tokens {
 	if v, ok := cbt.getBalance(id); ok {
 		return v.GetBalance()
 	}
-	return 0
+	return money.Tokens{}
 }
 
-func (cbt *ConsumerBalanceTracker) publishChangeEvent(id identity.Identity, before, after uint64) {
-	if before == after {
+func (cbt *ConsumerBalanceTracker) publishChangeEvent(id identity.Identity, before, after money.Tokens) {
+	if before.Cmp(after) == 0 {
 		return
 	}
 
@@ -191,14 +192,14 @@ func (cbt *ConsumerBalanceTracker) handleTopUpEvent(id string) {
 			return
 		}
 		updated = true
-		cbt.increaseBCBalance(identity.FromAddress(id), ev.Value.Uint64())
+		cbt.increaseBCBalance(identity.FromAddress(id), money.NewTokensFromBig(ev.Value))
 	case <-cbt.stop:
 		return
 	}
 }
 
 // ForceBalanceUpdate forces a balance update and returns the updated balance
-func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(id identity.Identity) uint64 {
+func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(id identity.Identity) money.Tokens {
 	fallback := cbt.GetBalance(id)
 
 	addr, err := cbt.channelAddressCalculator.GetChannelAddress(id)
@@ -225,15 +226,15 @@ func (cbt *ConsumerBalanceTracker) ForceBalanceUpdate(id identity.Identity) uint
 		return fallback
 	}
 
-	var before uint64
+	var before money.Tokens
 	if v, ok := cbt.getBalance(id); ok {
 		before = v.GetBalance()
 	}
 
 	cbt.setBalance(id, ConsumerBalance{
-		BCBalance:          cc.Balance.Uint64(),
-		BCSettled:          cc.Settled.Uint64(),
-		GrandTotalPromised: grandTotal,
+		BCBalance:          money.NewTokensFromBig(cc.Balance),
+		BCSettled:          money.NewTokensFromBig(cc.Settled),
+		GrandTotalPromised: money.NewTokens(grandTotal),
 	})
 
 	currentBalance, _ := cbt.getBalance(id)
@@ -258,7 +259,7 @@ func (cbt *ConsumerBalanceTracker) alignWithTransactor(id identity.Identity) {
 	}
 
 	// do not override existing balances with transactor data
-	if balance.BCBalance != 0 {
+	if !balance.BCBalance.IsZero() {
 		return
 	}
 
@@ -297,7 +298,8 @@ func (cbt *ConsumerBalanceTracker) alignWithTransactor(id identity.Identity) {
 		return
 	}
 
-	if data.BountyAmount == 0 {
+	bounty := money.NewTokens(data.BountyAmount)
+	if bounty.IsZero() {
 		// if we've got no bounty, get myst balance from BC and use that as bounty
 		addr, err := cbt.channelAddressCalculator.GetChannelAddress(id)
 		if err != nil {
@@ -310,15 +312,13 @@ func (cbt *ConsumerBalanceTracker) alignWithTransactor(id identity.Identity) {
 			return
 		}
 
-		data.BountyAmount = balance.Uint64()
+		bounty = money.NewTokensFromBig(balance)
 	}
 
 	c := ConsumerBalance{
-		BCBalance:          data.BountyAmount,
-		BCSettled:          0,
-		GrandTotalPromised: 0,
+		BCBalance: bounty,
 	}
-	log.Debug().Msgf("Loaded transactor state, current balance: %v MYST", data.BountyAmount)
+	log.Debug().Msgf("Loaded transactor state, current balance: %v MYST", bounty)
 	cbt.setBalance(id, c)
 	go cbt.publishChangeEvent(id, balance.GetBalance(), c.GetBalance())
 }
@@ -361,7 +361,8 @@ func (cbt *ConsumerBalanceTracker) recoverGrandTotalPromised(identity identity.I
 	}
 
 	log.Debug().Msgf("Loaded accountant state: already promised: %v", data.LatestPromise.Amount)
-	return cbt.consumerGrandTotalsStorage.Store(identity, cbt.accountantAddress, data.LatestPromise.Amount)
+	// Grand total is the amount of signed promises, which are limited to uint64.
+	return cbt.consumerGrandTotalsStorage.Store(identity, cbt.accountantAddress, data.LatestPromise.Amount.Uint64())
 }
 
 func (cbt *ConsumerBalanceTracker) handleStopEvent() {
@@ -370,11 +371,11 @@ func (cbt *ConsumerBalanceTracker) handleStopEvent() {
 	})
 }
 
-func (cbt *ConsumerBalanceTracker) increaseBCBalance(id identity.Identity, diff uint64) {
+func (cbt *ConsumerBalanceTracker) increaseBCBalance(id identity.Identity, diff money.Tokens) {
 	b, ok := cbt.getBalance(id)
 	before := b.BCBalance
 	if ok {
-		b.BCBalance = safeAdd(b.BCBalance, diff)
+		b.BCBalance = b.BCBalance.Add(diff)
 		cbt.setBalance(id, b)
 	} else {
 		cbt.ForceBalanceUpdate(id)
@@ -402,7 +403,7 @@ func (cbt *ConsumerBalanceTracker) setBalance(id identity.Identity, balance Cons
 	cbt.balances[id] = balance
 }
 
-func (cbt *ConsumerBalanceTracker) updateGrandTotal(id identity.Identity, current uint64) {
+func (cbt *ConsumerBalanceTracker) updateGrandTotal(id identity.Identity, current money.Tokens) {
 	b, ok := cbt.getBalance(id)
 	before := b.BCBalance
 	if ok {
@@ -423,23 +424,22 @@ func safeSub(a, b uint64) uint64 {
 	return 0
 }
 
-func safeAdd(a, b uint64) uint64 {
-	c := a + b
-	if (c > a) == (b > 0) {
-		return c
+func safeSubTokens(a, b money.Tokens) money.Tokens {
+	if a.Cmp(b) >= 0 {
+		return a.Sub(b)
 	}
-	return 0
+	return money.Tokens{}
 }
 
 // ConsumerBalance represents the consumer balance
 type ConsumerBalance struct {
-	BCBalance          uint64
-	BCSettled          uint64
-	GrandTotalPromised uint64
+	BCBalance          money.Tokens
+	BCSettled          money.Tokens
+	GrandTotalPromised money.Tokens
 }
 
 // GetBalance returns the current balance
-func (cb ConsumerBalance) GetBalance() uint64 {
+func (cb ConsumerBalance) GetBalance() money.Tokens {
 	// Balance (to spend) = BCBalance - (accountantPromised - BCSettled)
-	return safeSub(cb.BCBalance, safeSub(cb.GrandTotalPromised, cb.BCSettled))
+	return safeSubTokens(cb.BCBalance, safeSubTokens(cb.GrandTotalPromised, cb.BCSettled))
 }
@@ -26,6 +26,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/bindings"
 	"github.com/mysteriumnetwork/payments/client"
@@ -72,27 +73,27 @@ func TestConsumerBalanceTracker_Fresh_Registration(t *testing.T) {
 	})
 
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id1) == initialBalance
+		return cbt.GetBalance(id1).Uint64() == initialBalance
 	}, defaultWaitTime, defaultWaitInterval)
 
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id2) == 0
+		return cbt.GetBalance(id2).Uint64() == 0
 	}, defaultWaitTime, defaultWaitInterval)
 
 	bus.Publish(identity.AppTopicIdentityUnlock, id2.Address)
 
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id2) == initialBalance
+		return cbt.GetBalance(id2).Uint64() == initialBalance
 	}, defaultWaitTime, defaultWaitInterval)
 
 	var promised uint64 = 100
 	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
 		ConsumerID: id1,
-		Current:    promised,
+		Current:    money.NewTokens(promised),
 	})
 
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id1) == initialBalance-promised
+		return cbt.GetBalance(id1).Uint64() == initialBalance-promised
 	}, defaultWaitTime, defaultWaitInterval)
 }
 
@@ -129,7 +130,7 @@ func TestConsumerBalanceTracker_Fast_Registration(t *testing.T) {
 		})
 
 		assert.Eventually(t, func() bool {
-			return cbt.GetBalance(id1) == ba
+			return cbt.GetBalance(id1).Uint64() == ba
 		}, defaultWaitTime, defaultWaitInterval)
 	})
 	t.Run("Falls back to blockchain balance if no bounty is specified on transactor", func(t *testing.T) {
@@ -163,7 +164,7 @@ func TestConsumerBalanceTracker_Fast_Registration(t *testing.T) {
 		})
 
 		assert.Eventually(t, func() bool {
-			return cbt.GetBalance(id1) == ba
+			return cbt.GetBalance(id1).Uint64() == ba
 		}, defaultWaitTime, defaultWaitInterval)
 	})
 }
@@ -190,27 +191,27 @@ func TestConsumerBalanceTracker_Handles_GrandTotalChanges(t *testing.T) {
 	assert.NoError(t, err)
 	bus.Publish(identity.AppTopicIdentityUnlock, id1.Address)
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id1) == initialBalance-grandTotalPromised
+		return cbt.GetBalance(id1).Uint64() == initialBalance-grandTotalPromised
 	}, defaultWaitTime, defaultWaitInterval)
 
 	var diff uint64 = 10
 	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
 		ConsumerID: id1,
-		Current:    grandTotalPromised + diff,
+		Current:    money.NewTokens(grandTotalPromised + diff),
 	})
 
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id1) == initialBalance-grandTotalPromised-diff
+		return cbt.GetBalance(id1).Uint64() == initialBalance-grandTotalPromised-diff
 	}, defaultWaitTime, defaultWaitInterval)
 
 	var diff2 uint64 = 20
 	bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
 		ConsumerID: id1,
-		Current:    grandTotalPromised + diff2,
+		Current:    money.NewTokens(grandTotalPromised + diff2),
 	})
 
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id1) == initialBalance-grandTotalPromised-diff2
+		return cbt.GetBalance(id1).Uint64() == initialBalance-grandTotalPromised-diff2
 	}, defaultWaitTime, defaultWaitInterval)
 }
 
@@ -237,7 +238,7 @@ func TestConsumerBalanceTracker_Handles_TopUp(t *testing.T) {
 	assert.NoError(t, err)
 	bus.Publish(identity.AppTopicIdentityUnlock, id1.Address)
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id1) == initialBalance-grandTotalPromised
+		return cbt.GetBalance(id1).Uint64() == initialBalance-grandTotalPromised
 	}, defaultWaitTime, defaultWaitInterval)
 
 	bus.Publish(registry.AppTopicTransactorTopUp, id1.Address)
@@ -247,7 +248,7 @@ func TestConsumerBalanceTracker_Handles_TopUp(t *testing.T) {
 	}
 
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id1) == initialBalance-grandTotalPromised+topUpAmount
+		return cbt.GetBalance(id1).Uint64() == initialBalance-grandTotalPromised+topUpAmount
 	}, defaultWaitTime, defaultWaitInterval)
 }
 
@@ -285,7 +286,7 @@ func TestConsumerBalanceTracker_FallsBackToTransactorIfInProgress(t *testing.T)
 	assert.NoError(t, err)
 	bus.Publish(identity.AppTopicIdentityUnlock, id1.Address)
 	assert.Eventually(t, func() bool {
-		return cbt.GetBalance(id1) == 100
+		return cbt.GetBalance(id1).Uint64() == 100
 	}, defaultWaitTime, defaultWaitInterval)
 }
 
@@ -326,7 +327,7 @@ type mockconsumerInfoGetter struct {
 func (mcig *mockconsumerInfoGetter) GetConsumerData(_ string) (ConsumerData, error) {
 	return ConsumerData{
 		LatestPromise: LatestPromise{
-			Amount: mcig.amount,
+			Amount: money.NewTokens(mcig.amount),
 		},
 	}, nil
 }
@@ -347,8 +348,8 @@ func TestConsumerBalanceTracker_DoesNotBlockedOnEmptyBalancesList(t *testing.T)
 	cbt := NewConsumerBalanceTracker(bus, mockMystSCaddress, accountantID, &bc, &calc, &mcts, &mockconsumerInfoGetter{}, &mockTransactor{}, &mockRegistrationStatusProvider{})
 
 	// Make sure we are not dead locked here. https://github.com/mysteriumnetwork/node/issues/2181
-	cbt.increaseBCBalance(identity.FromAddress("0x0000"), 1)
-	cbt.updateGrandTotal(identity.FromAddress("0x0000"), 1)
+	cbt.increaseBCBalance(identity.FromAddress("0x0000"), money.NewTokens(1))
+	cbt.updateGrandTotal(identity.FromAddress("0x0000"), money.NewTokens(1))
 }
 
 func TestConsumerBalance_GetBalance(t *testing.T) {
@@ -393,11 +394,11 @@ func TestConsumerBalance_GetBalance(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			cb := ConsumerBalance{
-				BCBalance:          tt.fields.BCBalance,
-				BCSettled:          tt.fields.BCSettled,
-				GrandTotalPromised: tt.fields.GrandTotalPromised,
+				BCBalance:          money.NewTokens(tt.fields.BCBalance),
+				BCSettled:          money.NewTokens(tt.fields.BCSettled),
+				GrandTotalPromised: money.NewTokens(tt.fields.GrandTotalPromised),
 			}
-			if got := cb.GetBalance(); got != tt.want {
+			if got := cb.GetBalance(); got.Uint64() != tt.want {
 				t.Errorf("ConsumerBalance.GetBalance() = %v, want %v", got, tt.want)
 			}
 		})
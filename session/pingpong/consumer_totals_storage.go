@@ -23,6 +23,7 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/pkg/errors"
 )
@@ -55,7 +56,7 @@ func (cts *ConsumerTotalsStorage) Store(id identity.Identity, accountantID commo
 	}
 
 	go cts.bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
-		Current:      amount,
+		Current:      money.NewTokens(amount),
 		AccountantID: accountantID,
 		ConsumerID:   id,
 	})
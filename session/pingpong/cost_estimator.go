@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/pkg/errors"
+)
+
+type settleFeeProvider interface {
+	FetchSettleFees() (registry.FeesResponse, error)
+}
+
+// CostEstimate holds projected cost of a session.
+type CostEstimate struct {
+	// Amount is the price of the service itself.
+	Amount money.Tokens
+	// TransactorFee is the fee of settling the paid amount on blockchain.
+	TransactorFee money.Tokens
+}
+
+// Total returns the amount paid including fees.
+func (ce CostEstimate) Total() money.Tokens {
+	return ce.Amount.Add(ce.TransactorFee)
+}
+
+// CostEstimator projects cost of a session from the expected usage, before connecting.
+type CostEstimator struct {
+	fees settleFeeProvider
+
+	lock       sync.Mutex
+	cachedFees registry.FeesResponse
+}
+
+// NewCostEstimator returns a new instance of cost estimator.
+func NewCostEstimator(fees settleFeeProvider) *CostEstimator {
+	return &CostEstimator{
+		fees: fees,
+	}
+}
+
+// Estimate calculates cost of a session lasting for the given duration and transferring the given amount of data.
+func (ce *CostEstimator) Estimate(method market.PaymentMethod, duration time.Duration, data datasize.BitSize) (CostEstimate, error) {
+	amount := CalculatePaymentAmount(duration, DataTransferred{Down: data.Bytes()}, method)
+	if amount == 0 {
+		return CostEstimate{}, nil
+	}
+
+	fee, err := ce.settleFee()
+	if err != nil {
+		return CostEstimate{}, err
+	}
+
+	return CostEstimate{
+		Amount:        money.NewTokens(amount),
+		TransactorFee: money.NewTokens(fee),
+	}, nil
+}
+
+func (ce *CostEstimator) settleFee() (uint64, error) {
+	ce.lock.Lock()
+	defer ce.lock.Unlock()
+
+	if time.Now().Before(ce.cachedFees.ValidUntil) {
+		return ce.cachedFees.Fee, nil
+	}
+
+	fees, err := ce.fees.FetchSettleFees()
+	if err != nil {
+		return 0, errors.Wrap(err, "could not fetch transactor settlement fees")
+	}
+	ce.cachedFees = fees
+	return fees.Fee, nil
+}
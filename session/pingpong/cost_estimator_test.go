@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCostEstimator_Estimate(t *testing.T) {
+	fees := &mockFeeProvider{
+		toReturn: registry.FeesResponse{Fee: 100, ValidUntil: time.Now().Add(time.Hour)},
+	}
+	estimator := NewCostEstimator(fees)
+	method := &mocks.PaymentMethod{
+		Rate:  market.PaymentRate{PerTime: time.Minute, PerByte: uint64(datasize.GiB.Bytes())},
+		Price: money.NewMoney(1000, money.CurrencyMyst),
+	}
+
+	estimate, err := estimator.Estimate(method, time.Hour, 2*datasize.GiB)
+	assert.NoError(t, err)
+	assert.Equal(t, money.NewTokens(60*1000+2*1000), estimate.Amount)
+	assert.Equal(t, money.NewTokens(100), estimate.TransactorFee)
+	assert.Equal(t, money.NewTokens(62100), estimate.Total())
+
+	// fees are cached until they expire
+	fees.toReturn.Fee = 200
+	estimate, err = estimator.Estimate(method, time.Minute, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, CostEstimate{Amount: money.NewTokens(1000), TransactorFee: money.NewTokens(100)}, estimate)
+}
+
+func TestCostEstimator_Estimate_FreeService(t *testing.T) {
+	estimator := NewCostEstimator(&mockFeeProvider{errToReturn: errors.New("boom")})
+
+	estimate, err := estimator.Estimate(&mocks.PaymentMethod{}, time.Hour, datasize.GiB)
+	assert.NoError(t, err)
+	assert.Equal(t, CostEstimate{}, estimate)
+}
+
+func TestCostEstimator_Estimate_FeeError(t *testing.T) {
+	estimator := NewCostEstimator(&mockFeeProvider{errToReturn: errors.New("boom")})
+
+	_, err := estimator.Estimate(mocks.DefaultPaymentMethod(), time.Hour, datasize.GiB)
+	assert.EqualError(t, err, "could not fetch transactor settlement fees: boom")
+}
@@ -0,0 +1,290 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/money"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const earningsSeriesBucket = "provider_earnings_series"
+
+// EarningsGranularity is a period into which earnings are rolled up.
+type EarningsGranularity string
+
+const (
+	// EarningsHourly rolls up earnings by UTC hours.
+	EarningsHourly EarningsGranularity = "hour"
+	// EarningsDaily rolls up earnings by UTC days.
+	EarningsDaily EarningsGranularity = "day"
+)
+
+// Truncate returns the start of period the given time belongs to.
+func (g EarningsGranularity) Truncate(t time.Time) time.Time {
+	t = t.UTC()
+	if g == EarningsDaily {
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return t.Truncate(time.Hour)
+}
+
+// Next returns the start of the period following the given one.
+func (g EarningsGranularity) Next(t time.Time) time.Time {
+	if g == EarningsDaily {
+		return t.AddDate(0, 0, 1)
+	}
+	return t.Add(time.Hour)
+}
+
+// Valid checks if the granularity is supported.
+func (g EarningsGranularity) Valid() bool {
+	return g == EarningsHourly || g == EarningsDaily
+}
+
+// EarningsPoint holds tokens earned by provider identity during the period starting at Time.
+type EarningsPoint struct {
+	ID          string `storm:"id"`
+	Identity    string
+	Granularity EarningsGranularity
+	Time        time.Time
+	Tokens      money.Tokens
+}
+
+func earningsPointID(id string, granularity EarningsGranularity, t time.Time) string {
+	return fmt.Sprintf("%s|%s|%d", id, granularity, t.Unix())
+}
+
+// EarningsSeriesConfig configures earnings aggregation.
+type EarningsSeriesConfig struct {
+	// FlushInterval is how often aggregated earnings are written to storage.
+	FlushInterval time.Duration
+	// HourlyRetention is a period during which hourly series is kept, daily series is kept forever.
+	HourlyRetention time.Duration
+}
+
+// DefaultEarningsSeriesConfig returns the default earnings aggregation config.
+func DefaultEarningsSeriesConfig() EarningsSeriesConfig {
+	return EarningsSeriesConfig{
+		FlushInterval:   time.Minute,
+		HourlyRetention: 31 * 24 * time.Hour,
+	}
+}
+
+type earningsSeriesStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+type earningsKey struct {
+	identity    string
+	granularity EarningsGranularity
+	time        time.Time
+}
+
+// EarningsSeries rolls up tokens earned in provided sessions into hourly and daily series,
+// so that charting earnings does not require to replay session history.
+type EarningsSeries struct {
+	storage earningsSeriesStorage
+	config  EarningsSeriesConfig
+	now     func() time.Time
+
+	lock          sync.Mutex
+	sessionTotals map[string]money.Tokens
+	pending       map[earningsKey]money.Tokens
+	prunedAt      time.Time
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// NewEarningsSeries returns a new instance of earnings series aggregator.
+func NewEarningsSeries(storage earningsSeriesStorage, config EarningsSeriesConfig) *EarningsSeries {
+	return &EarningsSeries{
+		storage:       storage,
+		config:        config,
+		now:           time.Now,
+		sessionTotals: make(map[string]money.Tokens),
+		pending:       make(map[earningsKey]money.Tokens),
+		stop:          make(chan struct{}),
+	}
+}
+
+// Subscribe subscribes to provided session events of event bus.
+func (es *EarningsSeries) Subscribe(bus eventbus.Subscriber) error {
+	if err := bus.SubscribeAsync(sessionEvent.AppTopicTokensEarned, es.consumeTokensEarnedEvent); err != nil {
+		return err
+	}
+	return bus.SubscribeAsync(sessionEvent.AppTopicSession, es.consumeSessionEvent)
+}
+
+// Start starts writing aggregated earnings to storage periodically.
+func (es *EarningsSeries) Start() {
+	go func() {
+		for {
+			select {
+			case <-es.stop:
+				return
+			case <-time.After(es.config.FlushInterval):
+				if err := es.Flush(); err != nil {
+					log.Error().Err(err).Msg("Failed to flush earnings series")
+				}
+			}
+		}
+	}()
+}
+
+// Stop stops periodic writes and flushes earnings aggregated so far.
+func (es *EarningsSeries) Stop() {
+	es.stopOnce.Do(func() {
+		close(es.stop)
+		if err := es.Flush(); err != nil {
+			log.Error().Err(err).Msg("Failed to flush earnings series")
+		}
+	})
+}
+
+// Flush writes aggregated earnings to storage and prunes expired hourly series.
+func (es *EarningsSeries) Flush() error {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	return es.flush()
+}
+
+// Series returns earnings of the given granularity in the period [from, to), one point per period
+// including periods without earnings. Earnings of all identities are summed if identity is empty.
+func (es *EarningsSeries) Series(id string, granularity EarningsGranularity, from, to time.Time) ([]EarningsPoint, error) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	if err := es.flush(); err != nil {
+		return nil, err
+	}
+
+	var stored []EarningsPoint
+	if err := es.storage.GetAllFrom(earningsSeriesBucket, &stored); err != nil {
+		return nil, errors.Wrap(err, "could not get earnings series")
+	}
+
+	from = granularity.Truncate(from)
+	to = to.UTC()
+	tokens := make(map[time.Time]money.Tokens)
+	for _, p := range stored {
+		if p.Granularity != granularity || (id != "" && p.Identity != id) {
+			continue
+		}
+		tokens[p.Time.UTC()] = tokens[p.Time.UTC()].Add(p.Tokens)
+	}
+
+	series := make([]EarningsPoint, 0)
+	for t := from; t.Before(to); t = granularity.Next(t) {
+		series = append(series, EarningsPoint{
+			Identity:    id,
+			Granularity: granularity,
+			Time:        t,
+			Tokens:      tokens[t],
+		})
+	}
+	return series, nil
+}
+
+func (es *EarningsSeries) consumeTokensEarnedEvent(e sessionEvent.AppEventTokensEarned) {
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	previous := es.sessionTotals[e.SessionID]
+	if e.Total.Cmp(previous) <= 0 {
+		return
+	}
+	es.sessionTotals[e.SessionID] = e.Total
+
+	now := es.now()
+	for _, granularity := range []EarningsGranularity{EarningsHourly, EarningsDaily} {
+		key := earningsKey{identity: e.ProviderID.Address, granularity: granularity, time: granularity.Truncate(now)}
+		es.pending[key] = es.pending[key].Add(e.Total.Sub(previous))
+	}
+}
+
+func (es *EarningsSeries) consumeSessionEvent(e sessionEvent.AppEventSession) {
+	if e.Status != sessionEvent.RemovedStatus {
+		return
+	}
+
+	es.lock.Lock()
+	defer es.lock.Unlock()
+
+	delete(es.sessionTotals, e.Session.ID)
+}
+
+// flush must be called with the lock held.
+func (es *EarningsSeries) flush() error {
+	for key, tokens := range es.pending {
+		id := earningsPointID(key.identity, key.granularity, key.time)
+
+		var point EarningsPoint
+		err := es.storage.GetOneByField(earningsSeriesBucket, "ID", id, &point)
+		if err != nil && err.Error() != errBoltNotFound {
+			return errors.Wrap(err, "could not get earnings point")
+		}
+		if point.ID == "" {
+			point = EarningsPoint{ID: id, Identity: key.identity, Granularity: key.granularity, Time: key.time}
+		}
+		point.Tokens = point.Tokens.Add(tokens)
+
+		if err := es.storage.Store(earningsSeriesBucket, &point); err != nil {
+			return errors.Wrap(err, "could not store earnings point")
+		}
+		delete(es.pending, key)
+	}
+
+	return es.prune()
+}
+
+// prune deletes expired hourly series once a day, it must be called with the lock held.
+func (es *EarningsSeries) prune() error {
+	now := es.now()
+	if es.config.HourlyRetention <= 0 || now.Sub(es.prunedAt) < 24*time.Hour {
+		return nil
+	}
+
+	var stored []EarningsPoint
+	if err := es.storage.GetAllFrom(earningsSeriesBucket, &stored); err != nil {
+		return errors.Wrap(err, "could not get earnings series")
+	}
+
+	before := now.Add(-es.config.HourlyRetention)
+	for i := range stored {
+		if stored[i].Granularity != EarningsHourly || !stored[i].Time.Before(before) {
+			continue
+		}
+		if err := es.storage.Delete(earningsSeriesBucket, &stored[i]); err != nil {
+			return errors.Wrap(err, "could not delete expired earnings point")
+		}
+	}
+	es.prunedAt = now
+	return nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEarningsSeries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "earningsSeriesTest")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+	defer bolt.Close()
+
+	now := time.Date(2020, 6, 17, 10, 30, 0, 0, time.UTC)
+	series := NewEarningsSeries(bolt, DefaultEarningsSeriesConfig())
+	series.now = func() time.Time { return now }
+
+	provider1 := identity.FromAddress("0x1")
+	provider2 := identity.FromAddress("0x2")
+
+	t.Run("Rolls up session earnings increments", func(t *testing.T) {
+		series.consumeTokensEarnedEvent(sessionEvent.AppEventTokensEarned{ProviderID: provider1, SessionID: "1", Total: money.NewTokens(10)})
+		series.consumeTokensEarnedEvent(sessionEvent.AppEventTokensEarned{ProviderID: provider1, SessionID: "1", Total: money.NewTokens(25)})
+		series.consumeTokensEarnedEvent(sessionEvent.AppEventTokensEarned{ProviderID: provider2, SessionID: "2", Total: money.NewTokens(5)})
+		assert.NoError(t, series.Flush())
+
+		now = now.Add(time.Hour)
+		series.consumeTokensEarnedEvent(sessionEvent.AppEventTokensEarned{ProviderID: provider1, SessionID: "1", Total: money.NewTokens(40)})
+		// repeated totals are not counted twice
+		series.consumeTokensEarnedEvent(sessionEvent.AppEventTokensEarned{ProviderID: provider1, SessionID: "1", Total: money.NewTokens(40)})
+
+		hourly, err := series.Series(provider1.Address, EarningsHourly, now.Add(-2*time.Hour), now)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{0, 25, 15}, earningsTokens(hourly))
+		assert.Equal(t, time.Date(2020, 6, 17, 9, 0, 0, 0, time.UTC), hourly[0].Time)
+
+		daily, err := series.Series(provider1.Address, EarningsDaily, now.AddDate(0, 0, -1), now)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{0, 40}, earningsTokens(daily))
+	})
+
+	t.Run("Sums identities when identity is not given", func(t *testing.T) {
+		daily, err := series.Series("", EarningsDaily, now, now)
+		assert.NoError(t, err)
+		assert.Equal(t, []uint64{45}, earningsTokens(daily))
+	})
+
+	t.Run("Prunes expired hourly series", func(t *testing.T) {
+		now = now.AddDate(0, 2, 0)
+		assert.NoError(t, series.Flush())
+
+		var stored []EarningsPoint
+		assert.NoError(t, bolt.GetAllFrom(earningsSeriesBucket, &stored))
+		for _, p := range stored {
+			assert.Equal(t, EarningsDaily, p.Granularity)
+		}
+		assert.Len(t, stored, 2)
+	})
+}
+
+func earningsTokens(series []EarningsPoint) []uint64 {
+	tokens := make([]uint64, len(series))
+	for i, p := range series {
+		tokens[i] = p.Tokens.Uint64()
+	}
+	return tokens
+}
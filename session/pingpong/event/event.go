@@ -20,20 +20,21 @@ package event
 import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
 
 const (
 	// AppTopicAccountantPromise represents a topic to which we send accountant promise events.
-	AppTopicAccountantPromise = "accountant_promise_received"
+	AppTopicAccountantPromise = "payments.accountant_promise.received"
 	// AppTopicBalanceChanged represents the balance change topic
-	AppTopicBalanceChanged = "balance_change"
+	AppTopicBalanceChanged = "payments.balance.changed"
 	// AppTopicEarningsChanged represents the earnings change topic
-	AppTopicEarningsChanged = "earnings_change"
+	AppTopicEarningsChanged = "payments.earnings.changed"
 	// AppTopicInvoicePaid is a topic for publish events exchange message send to provider as a consumer.
-	AppTopicInvoicePaid = "invoice_paid"
+	AppTopicInvoicePaid = "payments.invoice.paid"
 	// AppTopicSettlementRequest forces the settlement of promises for given provider/accountant.
-	AppTopicSettlementRequest = "settlement_request"
+	AppTopicSettlementRequest = "payments.settlement.requested"
 )
 
 // AppEventSettlementRequest represents the payload that is sent on the AppTopicSettlementRequest topic.
@@ -52,21 +53,22 @@ type AppEventAccountantPromise struct {
 // AppEventBalanceChanged represents a balance change event
 type AppEventBalanceChanged struct {
 	Identity identity.Identity
-	Previous uint64
-	Current  uint64
+	Previous money.Tokens
+	Current  money.Tokens
 }
 
 // AppEventEarningsChanged represents a balance change event
 type AppEventEarningsChanged struct {
-	Identity identity.Identity
-	Previous Earnings
-	Current  Earnings
+	Identity     identity.Identity
+	AccountantID common.Address
+	Previous     Earnings
+	Current      Earnings
 }
 
 // Earnings represents current identity earnings
 type Earnings struct {
-	LifetimeBalance  uint64
-	UnsettledBalance uint64
+	LifetimeBalance  money.Tokens
+	UnsettledBalance money.Tokens
 }
 
 // AppEventInvoicePaid is an update on paid invoices during current session
@@ -77,11 +79,11 @@ type AppEventInvoicePaid struct {
 }
 
 // AppTopicGrandTotalChanged represents a topic to which we send grand total change messages.
-const AppTopicGrandTotalChanged = "consumer_grand_total_change"
+const AppTopicGrandTotalChanged = "payments.grand_total.changed"
 
 // AppEventGrandTotalChanged represents the grand total changed event.
 type AppEventGrandTotalChanged struct {
-	Current      uint64
+	Current      money.Tokens
 	AccountantID common.Address
 	ConsumerID   identity.Identity
 }
@@ -22,7 +22,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/connection"
-	"github.com/mysteriumnetwork/node/core/service"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
@@ -76,6 +75,26 @@ type PaymentMethod struct {
 	Duration time.Duration `json:"duration"`
 	Bytes    uint64        `json:"bytes"`
 	Type     string        `json:"type"`
+	// FreeDuration and FreeBytes are the free trial allowance a registered consumer gets every day.
+	FreeDuration time.Duration `json:"free_duration,omitempty"`
+	FreeBytes    uint64        `json:"free_bytes,omitempty"`
+}
+
+// WithFreeTrial returns the payment method offering the given free trial allowance.
+func (pm PaymentMethod) WithFreeTrial(duration time.Duration, bytes uint64) PaymentMethod {
+	pm.FreeDuration = duration
+	pm.FreeBytes = bytes
+	return pm
+}
+
+// HasFreeTrial checks if the payment method offers a free trial allowance.
+func (pm PaymentMethod) HasFreeTrial() bool {
+	return pm.FreeDuration > 0 || pm.FreeBytes > 0
+}
+
+// GetFreeTrial returns the free trial allowance of the payment method.
+func (pm PaymentMethod) GetFreeTrial() (time.Duration, uint64) {
+	return pm.FreeDuration, pm.FreeBytes
 }
 
 // GetPrice returns the payment methods price
@@ -93,56 +112,15 @@ func (pm PaymentMethod) GetRate() market.PaymentRate {
 	return market.PaymentRate{PerByte: pm.Bytes, PerTime: pm.Duration}
 }
 
-// InvoiceFactoryCreator returns a payment engine factory.
-func InvoiceFactoryCreator(
-	channel p2p.Channel,
-	balanceSendPeriod, promiseTimeout time.Duration,
-	invoiceStorage providerInvoiceStorage,
-	registryAddress string,
-	channelImplementationAddress string,
-	maxAccountantFailureCount uint64,
-	maxAllowedAccountantFee uint16,
-	maxUnpaidInvoiceValue uint64,
-	blockchainHelper bcHelper,
-	eventBus eventbus.EventBus,
-	proposal market.ServiceProposal,
-	promiseHandler promiseHandler,
-	providersAccountant common.Address,
-) func(identity.Identity, identity.Identity, common.Address, string) (service.PaymentEngine, error) {
-	return func(providerID, consumerID identity.Identity, accountantID common.Address, sessionID string) (service.PaymentEngine, error) {
-		exchangeChan, err := exchangeMessageReceiver(channel)
-		if err != nil {
-			return nil, err
-		}
-		timeTracker := session.NewTracker(mbtime.Now)
-		deps := InvoiceTrackerDeps{
-			Proposal:                   proposal,
-			Peer:                       consumerID,
-			PeerInvoiceSender:          NewInvoiceSender(channel),
-			InvoiceStorage:             invoiceStorage,
-			TimeTracker:                &timeTracker,
-			ChargePeriod:               balanceSendPeriod,
-			ChargePeriodLeeway:         2 * time.Minute,
-			ExchangeMessageChan:        exchangeChan,
-			ExchangeMessageWaitTimeout: promiseTimeout,
-			FirstInvoiceSendTimeout:    10 * time.Second,
-			FirstInvoiceSendDuration:   1 * time.Second,
-			ProviderID:                 providerID,
-			ConsumersAccountantID:      accountantID,
-			ProvidersAccountantID:      providersAccountant,
-			Registry:                   registryAddress,
-			MaxAccountantFailureCount:  maxAccountantFailureCount,
-			MaxAllowedAccountantFee:    maxAllowedAccountantFee,
-			BlockchainHelper:           blockchainHelper,
-			EventBus:                   eventBus,
-			SessionID:                  sessionID,
-			PromiseHandler:             promiseHandler,
-			ChannelAddressCalculator:   NewChannelAddressCalculator(accountantID.Hex(), channelImplementationAddress, registryAddress),
-			MaxNotPaidInvoice:          maxUnpaidInvoiceValue,
+// sessionAccountant returns the accountant session is accounted by,
+// which is the one consumer asked for if provider works with it or the main accountant of provider otherwise.
+func sessionAccountant(consumersAccountant common.Address, providersAccountants []common.Address) common.Address {
+	for _, accountant := range providersAccountants {
+		if accountant == consumersAccountant {
+			return accountant
 		}
-		paymentEngine := NewInvoiceTracker(deps)
-		return paymentEngine, nil
 	}
+	return providersAccountants[0]
 }
 
 // ExchangeFactoryFunc returns a exchange factory.
@@ -153,7 +131,8 @@ func ExchangeFactoryFunc(
 	channelImplementation string,
 	registryAddress string,
 	eventBus eventbus.EventBus,
-	dataLeewayMegabytes uint64) func(channel p2p.Channel, consumer, provider identity.Identity, accountant common.Address, proposal market.ServiceProposal) (connection.PaymentIssuer, error) {
+	dataLeewayMegabytes uint64,
+	pauseOnTrafficDivergence bool) func(channel p2p.Channel, consumer, provider identity.Identity, accountant common.Address, proposal market.ServiceProposal) (connection.PaymentIssuer, error) {
 	return func(channel p2p.Channel, consumer, provider identity.Identity, accountant common.Address, proposal market.ServiceProposal) (connection.PaymentIssuer, error) {
 		invoices, err := invoiceReceiver(channel)
 		if err != nil {
@@ -173,6 +152,7 @@ func ExchangeFactoryFunc(
 			EventBus:                  eventBus,
 			AccountantAddress:         accountant,
 			DataLeeway:                datasize.MiB * datasize.BitSize(dataLeewayMegabytes),
+			PauseOnTrafficDivergence:  pauseOnTrafficDivergence,
 		}
 		return NewInvoicePayer(deps), nil
 	}
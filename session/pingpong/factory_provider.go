@@ -0,0 +1,89 @@
+// +build !mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/p2p"
+	"github.com/mysteriumnetwork/node/session"
+	"github.com/mysteriumnetwork/node/session/mbtime"
+)
+
+// InvoiceFactoryCreator returns a payment engine factory.
+func InvoiceFactoryCreator(
+	channel p2p.Channel,
+	balanceSendPeriod, promiseTimeout time.Duration,
+	invoiceStorage providerInvoiceStorage,
+	registryAddress string,
+	channelImplementationAddress string,
+	maxAccountantFailureCount uint64,
+	maxAllowedAccountantFee uint16,
+	maxUnpaidInvoiceValue uint64,
+	blockchainHelper bcHelper,
+	eventBus eventbus.EventBus,
+	proposal market.ServiceProposal,
+	promiseHandler promiseHandler,
+	providersAccountants []common.Address,
+	trials freeTrials,
+) func(identity.Identity, identity.Identity, common.Address, string) (service.PaymentEngine, error) {
+	return func(providerID, consumerID identity.Identity, accountantID common.Address, sessionID string) (service.PaymentEngine, error) {
+		exchangeChan, err := exchangeMessageReceiver(channel)
+		if err != nil {
+			return nil, err
+		}
+		timeTracker := session.NewTracker(mbtime.Now)
+		deps := InvoiceTrackerDeps{
+			Proposal:                   proposal,
+			Peer:                       consumerID,
+			PeerInvoiceSender:          NewInvoiceSender(channel),
+			InvoiceStorage:             invoiceStorage,
+			TimeTracker:                &timeTracker,
+			ChargePeriod:               balanceSendPeriod,
+			ChargePeriodLeeway:         2 * time.Minute,
+			ExchangeMessageChan:        exchangeChan,
+			ExchangeMessageWaitTimeout: promiseTimeout,
+			FirstInvoiceSendTimeout:    10 * time.Second,
+			FirstInvoiceSendDuration:   1 * time.Second,
+			ProviderID:                 providerID,
+			ConsumersAccountantID:      accountantID,
+			ProvidersAccountantID:      sessionAccountant(accountantID, providersAccountants),
+			Registry:                   registryAddress,
+			MaxAccountantFailureCount:  maxAccountantFailureCount,
+			MaxAllowedAccountantFee:    maxAllowedAccountantFee,
+			BlockchainHelper:           blockchainHelper,
+			EventBus:                   eventBus,
+			SessionID:                  sessionID,
+			PromiseHandler:             promiseHandler,
+			ChannelAddressCalculator:   NewChannelAddressCalculator(accountantID.Hex(), channelImplementationAddress, registryAddress),
+			MaxNotPaidInvoice:          maxUnpaidInvoiceValue,
+		}
+		if pm, ok := proposal.PaymentMethod.(PaymentMethod); ok && pm.HasFreeTrial() {
+			return newFreeTrialInvoiceTracker(deps, trials), nil
+		}
+		paymentEngine := NewInvoiceTracker(deps)
+		return paymentEngine, nil
+	}
+}
@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"sync"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/freetier"
+)
+
+type freeTrials interface {
+	Grant(serviceType string, consumerID identity.Identity, allowance freetier.Usage) freetier.Usage
+	Record(serviceType string, consumerID identity.Identity, used freetier.Usage)
+}
+
+// freeTrialInvoiceTracker is an invoice tracker which does not charge consumer for the granted free trial allowance
+// and records the used part of it once the session is over.
+type freeTrialInvoiceTracker struct {
+	*InvoiceTracker
+	trials     freeTrials
+	recordOnce sync.Once
+}
+
+func newFreeTrialInvoiceTracker(deps InvoiceTrackerDeps, trials freeTrials) *freeTrialInvoiceTracker {
+	pm, _ := deps.Proposal.PaymentMethod.(PaymentMethod)
+	granted := trials.Grant(deps.Proposal.ServiceType, deps.Peer, freetier.Usage{
+		Duration: pm.FreeDuration,
+		Bytes:    pm.FreeBytes,
+	})
+	deps.FreeDuration = granted.Duration
+	deps.FreeBytes = granted.Bytes
+
+	return &freeTrialInvoiceTracker{
+		InvoiceTracker: NewInvoiceTracker(deps),
+		trials:         trials,
+	}
+}
+
+// Stop stops the invoice tracker and records the free trial allowance used by consumer.
+func (t *freeTrialInvoiceTracker) Stop() {
+	t.InvoiceTracker.Stop()
+	t.recordOnce.Do(func() {
+		t.trials.Record(t.deps.Proposal.ServiceType, t.deps.Peer, t.usedFreeTrial())
+	})
+}
+
+func (t *freeTrialInvoiceTracker) usedFreeTrial() freetier.Usage {
+	used := freetier.Usage{
+		Duration: t.deps.TimeTracker.Elapsed(),
+		Bytes:    t.getDataTransferred().sum(),
+	}
+	if used.Duration > t.deps.FreeDuration {
+		used.Duration = t.deps.FreeDuration
+	}
+	if used.Bytes > t.deps.FreeBytes {
+		used.Bytes = t.deps.FreeBytes
+	}
+	return used
+}
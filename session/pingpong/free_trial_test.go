@@ -0,0 +1,75 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/freetier"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockFreeTrials struct {
+	granted  freetier.Usage
+	recorded []freetier.Usage
+}
+
+func (m *mockFreeTrials) Grant(_ string, _ identity.Identity, _ freetier.Usage) freetier.Usage {
+	return m.granted
+}
+
+func (m *mockFreeTrials) Record(_ string, _ identity.Identity, used freetier.Usage) {
+	m.recorded = append(m.recorded, used)
+}
+
+func Test_InvoiceTracker_calculateOwed_DeductsFreeTrial(t *testing.T) {
+	pm := NewPaymentMethod(1000000, 10000)
+	tracker := NewInvoiceTracker(InvoiceTrackerDeps{
+		Proposal:     market.ServiceProposal{PaymentMethod: pm},
+		FreeDuration: 10 * time.Minute,
+		FreeBytes:    100,
+	})
+	tracker.updateDataTransfer(60, 50)
+
+	assert.Zero(t, tracker.calculateOwed(5*time.Minute))
+	assert.Equal(t,
+		CalculatePaymentAmount(5*time.Minute, DataTransferred{Down: 10}, pm),
+		tracker.calculateOwed(15*time.Minute),
+	)
+}
+
+func Test_freeTrialInvoiceTracker_RecordsUsedAllowance(t *testing.T) {
+	trials := &mockFreeTrials{granted: freetier.Usage{Duration: 10 * time.Minute, Bytes: 100}}
+	tracker := newFreeTrialInvoiceTracker(InvoiceTrackerDeps{
+		Proposal:    market.ServiceProposal{PaymentMethod: NewPaymentMethod(1000000, 10000).WithFreeTrial(time.Hour, 1000)},
+		TimeTracker: &mockTimeTracker{timeToReturn: 5 * time.Minute},
+		EventBus:    mocks.NewEventBus(),
+	}, trials)
+	assert.Equal(t, 10*time.Minute, tracker.deps.FreeDuration)
+	assert.Equal(t, uint64(100), tracker.deps.FreeBytes)
+
+	tracker.updateDataTransfer(100, 100)
+	tracker.Stop()
+	tracker.Stop()
+
+	assert.Equal(t, []freetier.Usage{{Duration: 5 * time.Minute, Bytes: 100}}, trials.recorded)
+}
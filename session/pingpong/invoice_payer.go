@@ -30,6 +30,7 @@ import (
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -81,6 +82,13 @@ type InvoicePayer struct {
 
 	dataTransferred     DataTransferred
 	dataTransferredLock sync.Mutex
+	// Statistics are sampled rarely while nobody watches them, so the data transferred
+	// since the last sample is estimated from the rate of the last sampling interval.
+	lastSampleElapsed time.Duration
+	lastSampleRate    float64
+
+	trafficDiverged     bool
+	trafficDivergedLock sync.Mutex
 }
 
 type hashSigner interface {
@@ -101,6 +109,8 @@ type InvoicePayerDeps struct {
 	EventBus                  eventbus.EventBus
 	AccountantAddress         common.Address
 	DataLeeway                datasize.BitSize
+	// PauseOnTrafficDivergence holds invoices unpaid while session traffic counted by consumer and provider diverges.
+	PauseOnTrafficDivergence bool
 }
 
 // NewInvoicePayer returns a new instance of exchange message tracker.
@@ -130,6 +140,12 @@ func (ip *InvoicePayer) Start() error {
 	if err != nil {
 		return errors.Wrap(err, "could not subscribe to data transfer events")
 	}
+	if ip.deps.PauseOnTrafficDivergence {
+		err = ip.deps.EventBus.SubscribeAsync(sessionEvent.AppTopicTrafficDivergence, ip.consumeTrafficDivergenceEvent)
+		if err != nil {
+			return errors.Wrap(err, "could not subscribe to traffic divergence events")
+		}
+	}
 
 	for {
 		select {
@@ -142,6 +158,12 @@ func (ip *InvoicePayer) Start() error {
 				return errors.Wrap(err, "invoice not valid")
 			}
 
+			// Later invoices carry the agreement total, so the held amount is paid once traffic matches again.
+			if ip.isTrafficDiverged() {
+				log.Warn().Msgf("Session traffic diverges from provider, invoice %v is not paid", invoice.AgreementTotal)
+				continue
+			}
+
 			err = ip.issueExchangeMessage(invoice)
 			if err != nil {
 				return err
@@ -169,7 +191,7 @@ func (ip *InvoicePayer) isInvoiceOK(invoice crypto.Invoice) error {
 		return ErrWrongProvider
 	}
 
-	transferred := ip.getDataTransferred()
+	transferred := ip.estimateDataTransferred()
 	transferred.Up += ip.deps.DataLeeway.Bytes()
 
 	shouldBe := CalculatePaymentAmount(ip.deps.TimeTracker.Elapsed(), transferred, ip.deps.Proposal.PaymentMethod)
@@ -264,6 +286,9 @@ func (ip *InvoicePayer) Stop() {
 	ip.once.Do(func() {
 		log.Debug().Msg("Stopping...")
 		_ = ip.deps.EventBus.Unsubscribe(connection.AppTopicConnectionStatistics, ip.consumeDataTransferredEvent)
+		if ip.deps.PauseOnTrafficDivergence {
+			_ = ip.deps.EventBus.Unsubscribe(sessionEvent.AppTopicTrafficDivergence, ip.consumeTrafficDivergenceEvent)
+		}
 		close(ip.stop)
 	})
 }
@@ -275,6 +300,24 @@ func (ip *InvoicePayer) consumeDataTransferredEvent(e connection.AppEventConnect
 	ip.updateDataTransfer(e.Stats.BytesSent, e.Stats.BytesReceived)
 }
 
+func (ip *InvoicePayer) consumeTrafficDivergenceEvent(e sessionEvent.AppEventTrafficDivergence) {
+	if e.SessionID != ip.deps.SessionID {
+		return
+	}
+
+	ip.trafficDivergedLock.Lock()
+	defer ip.trafficDivergedLock.Unlock()
+
+	ip.trafficDiverged = e.Diverged
+}
+
+func (ip *InvoicePayer) isTrafficDiverged() bool {
+	ip.trafficDivergedLock.Lock()
+	defer ip.trafficDivergedLock.Unlock()
+
+	return ip.trafficDiverged
+}
+
 func (ip *InvoicePayer) updateDataTransfer(up, down uint64) {
 	ip.dataTransferredLock.Lock()
 	defer ip.dataTransferredLock.Unlock()
@@ -289,6 +332,13 @@ func (ip *InvoicePayer) updateDataTransfer(up, down uint64) {
 		newDown = down
 	}
 
+	elapsed := ip.deps.TimeTracker.Elapsed()
+	if sampleInterval := elapsed - ip.lastSampleElapsed; sampleInterval > 0 {
+		transferred := (newUp + newDown) - ip.dataTransferred.sum()
+		ip.lastSampleRate = float64(transferred) / sampleInterval.Seconds()
+	}
+	ip.lastSampleElapsed = elapsed
+
 	ip.dataTransferred = DataTransferred{
 		Up:   newUp,
 		Down: newDown,
@@ -302,6 +352,18 @@ func (ip *InvoicePayer) getDataTransferred() DataTransferred {
 	return ip.dataTransferred
 }
 
+// estimateDataTransferred returns the data transferred including the data transferred since the last statistics sample.
+func (ip *InvoicePayer) estimateDataTransferred() DataTransferred {
+	ip.dataTransferredLock.Lock()
+	defer ip.dataTransferredLock.Unlock()
+
+	transferred := ip.dataTransferred
+	if sinceSample := ip.deps.TimeTracker.Elapsed() - ip.lastSampleElapsed; sinceSample > 0 {
+		transferred.Up += uint64(ip.lastSampleRate * sinceSample.Seconds())
+	}
+	return transferred
+}
+
 // SetSessionID updates invoice payer dependencies to set session ID once session established.
 func (ip *InvoicePayer) SetSessionID(sessionID string) {
 	ip.deps.SessionID = sessionID
@@ -32,6 +32,7 @@ import (
 	"github.com/mysteriumnetwork/node/mocks"
 	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session"
+	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/mbtime"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/crypto"
@@ -172,6 +173,84 @@ func Test_InvoicePayer_SendsMessage(t *testing.T) {
 	<-testDone
 }
 
+func Test_InvoicePayer_HoldsInvoicesWhileTrafficDiverges(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exchange_message_tracker_test")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	assert.Nil(t, err)
+
+	err = ks.Unlock(acc, "")
+	assert.Nil(t, err)
+
+	mockSender := &MockPeerExchangeMessageSender{
+		chanToWriteTo: make(chan crypto.ExchangeMessage, 10),
+	}
+
+	invoiceChan := make(chan crypto.Invoice)
+	bolt, err := boltdb.NewStorage(dir)
+	assert.Nil(t, err)
+	defer bolt.Close()
+
+	tracker := session.NewTracker(mbtime.Now)
+	totalsStorage := NewConsumerTotalsStorage(bolt, eventbus.New())
+	totalsStorage.Store(identity.FromAddress(acc.Address.Hex()), common.Address{}, 10)
+	deps := InvoicePayerDeps{
+		InvoiceChan:               invoiceChan,
+		PeerExchangeMessageSender: mockSender,
+		ConsumerTotalsStorage:     totalsStorage,
+		TimeTracker:               &tracker,
+		EventBus:                  mocks.NewEventBus(),
+		Ks:                        ks,
+		ChannelAddressCalculator:  NewChannelAddressCalculator(acc.Address.Hex(), acc.Address.Hex(), acc.Address.Hex()),
+		Identity:                  identity.FromAddress(acc.Address.Hex()),
+		Peer:                      identity.FromAddress("0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C"),
+		Proposal: market.ServiceProposal{
+			PaymentMethod: &mockPaymentMethod{
+				price: money.NewMoney(10, money.CurrencyMyst),
+				rate:  market.PaymentRate{PerTime: time.Minute},
+			},
+		},
+		SessionID:                "session",
+		PauseOnTrafficDivergence: true,
+	}
+	InvoicePayer := NewInvoicePayer(deps)
+
+	mockInvoice := crypto.Invoice{
+		AgreementID:    1,
+		AgreementTotal: 0,
+		TransactorFee:  0,
+		Hashlock:       "0x441Da57A51e42DAB7Daf55909Af93A9b00eEF23C",
+		Provider:       deps.Peer.Address,
+	}
+
+	testDone := make(chan struct{})
+
+	defer InvoicePayer.Stop()
+	go func() {
+		err := InvoicePayer.Start()
+		assert.Nil(t, err)
+		testDone <- struct{}{}
+	}()
+
+	InvoicePayer.consumeTrafficDivergenceEvent(sessionEvent.AppEventTrafficDivergence{SessionID: "other", Diverged: false})
+	InvoicePayer.consumeTrafficDivergenceEvent(sessionEvent.AppEventTrafficDivergence{SessionID: "session", Diverged: true})
+	invoiceChan <- mockInvoice
+	invoiceChan <- mockInvoice
+	assert.Len(t, mockSender.chanToWriteTo, 0)
+
+	InvoicePayer.consumeTrafficDivergenceEvent(sessionEvent.AppEventTrafficDivergence{SessionID: "session", Diverged: false})
+	invoiceChan <- mockInvoice
+
+	exchangeMessage := <-mockSender.chanToWriteTo
+	InvoicePayer.Stop()
+	<-testDone
+
+	assert.Equal(t, uint64(10), exchangeMessage.Promise.Amount)
+}
+
 func Test_InvoicePayer_SendsMessage_OnFreeService(t *testing.T) {
 	dir, err := ioutil.TempDir("", "exchange_message_tracker_test")
 	assert.Nil(t, err)
@@ -574,7 +653,7 @@ func TestInvoicePayer_issueExchangeMessage_publishesEvents(t *testing.T) {
 	assert.Equal(t, event.AppTopicGrandTotalChanged, ev.name)
 	assert.EqualValues(t, event.AppEventGrandTotalChanged{
 		ConsumerID: emt.deps.Identity,
-		Current:    5,
+		Current:    money.NewTokens(5),
 	}, ev.value)
 }
 
@@ -702,7 +781,7 @@ func (mcts *mockConsumerTotalsStorage) Store(id identity.Identity, accountantID
 	mcts.calledWith = amount
 	if mcts.bus != nil {
 		go mcts.bus.Publish(event.AppTopicGrandTotalChanged, event.AppEventGrandTotalChanged{
-			Current:      amount,
+			Current:      money.NewTokens(amount),
 			AccountantID: accountantID,
 			ConsumerID:   id,
 		})
@@ -877,3 +956,19 @@ func Test_estimateInvoiceTolerance(t *testing.T) {
 		})
 	}
 }
+
+func TestInvoicePayer_estimateDataTransferred(t *testing.T) {
+	tracker := &mockTimeTracker{}
+	ip := NewInvoicePayer(InvoicePayerDeps{TimeTracker: tracker})
+
+	tracker.timeToReturn = 10 * time.Second
+	ip.updateDataTransfer(100, 900)
+	tracker.timeToReturn = 20 * time.Second
+	ip.updateDataTransfer(200, 1800)
+	assert.Equal(t, DataTransferred{Up: 200, Down: 1800}, ip.estimateDataTransferred())
+
+	// No sample arrived for 5s, the rate of the last sampling interval is assumed.
+	tracker.timeToReturn = 25 * time.Second
+	assert.Equal(t, DataTransferred{Up: 700, Down: 1800}, ip.estimateDataTransferred())
+	assert.Equal(t, DataTransferred{Up: 200, Down: 1800}, ip.getDataTransferred())
+}
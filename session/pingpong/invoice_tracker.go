@@ -32,13 +32,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/logconfig"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/p2p"
 	sessionEvent "github.com/mysteriumnetwork/node/session/event"
 	"github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
-	"github.com/rs/zerolog/log"
+	"github.com/rs/zerolog"
 )
 
 // ErrConsumerPromiseValidationFailed represents an error where consumer tries to cheat us with incorrect promises.
@@ -59,6 +60,9 @@ var ErrInvoiceSendMaxFailCountReached = errors.New("did not sent a new exchange
 // ErrFirstInvoiceSendTimeout indicates that first invoice was not sent.
 var ErrFirstInvoiceSendTimeout = errors.New("did not sent first invoice")
 
+// ErrFinalPaymentTimeout indicates that consumer did not pay the final invoice in time.
+var ErrFinalPaymentTimeout = errors.New("did not get paid for the final invoice")
+
 // ErrExchangeValidationFailed indicates that there was an error with the exchange signature.
 var ErrExchangeValidationFailed = errors.New("exchange validation failed")
 
@@ -160,6 +164,9 @@ type InvoiceTrackerDeps struct {
 	SessionID                  string
 	PromiseHandler             promiseHandler
 	MaxNotPaidInvoice          uint64
+	// FreeDuration and FreeBytes is the free trial allowance granted to the consumer, which is not charged for.
+	FreeDuration time.Duration
+	FreeBytes    uint64
 }
 
 // NewInvoiceTracker creates a new instance of invoice tracker.
@@ -187,6 +194,12 @@ func calculateMaxNotSentExchangeMessageCount(chargeLeeway, chargePeriod time.Dur
 	return uint64(math.Round(float64(chargeLeeway) / float64(chargePeriod)))
 }
 
+// logger returns logger correlating log lines with the session being paid for.
+func (it *InvoiceTracker) logger() *zerolog.Logger {
+	logger := logconfig.SessionLogger(it.deps.SessionID, it.deps.Peer.Address)
+	return &logger
+}
+
 func (it *InvoiceTracker) markInvoiceSent(invoice sentInvoice) {
 	it.invoiceLock.Lock()
 	defer it.invoiceLock.Unlock()
@@ -234,7 +247,7 @@ func (it *InvoiceTracker) generateAgreementID() {
 func (it *InvoiceTracker) handleExchangeMessage(em crypto.ExchangeMessage) error {
 	invoice, ok := it.getMarkedInvoice(em.Promise.Hashlock)
 	if !ok {
-		log.Debug().Msgf("consumer sent exchange message with missing expired hashlock %s, skipping", invoice.invoice.Hashlock)
+		it.logger().Debug().Msgf("consumer sent exchange message with missing expired hashlock %s, skipping", invoice.invoice.Hashlock)
 		return ErrInvoiceExpired
 	}
 
@@ -249,7 +262,7 @@ func (it *InvoiceTracker) handleExchangeMessage(em crypto.ExchangeMessage) error
 	it.resetNotSentExchangeMessageCount()
 
 	// incase of zero payment, we'll just skip going to the accountant
-	if isServiceFree(it.deps.Proposal.PaymentMethod) {
+	if isServiceFree(it.deps.Proposal.PaymentMethod) || em.AgreementTotal == 0 {
 		return nil
 	}
 
@@ -265,7 +278,7 @@ func (it *InvoiceTracker) handleExchangeMessage(em crypto.ExchangeMessage) error
 
 // Start stars the invoice tracker
 func (it *InvoiceTracker) Start() error {
-	log.Debug().Msg("Starting...")
+	it.logger().Debug().Msg("Starting...")
 	it.deps.TimeTracker.StartTracking()
 
 	if err := it.deps.EventBus.SubscribeAsync(sessionEvent.AppTopicDataTransferred, it.consumeDataTransferredEvent); err != nil {
@@ -282,7 +295,7 @@ func (it *InvoiceTracker) Start() error {
 	}
 
 	if fee > it.deps.MaxAllowedAccountantFee {
-		log.Error().Msgf("Accountant fee too large, asking for %v where %v is the limit", fee, it.deps.MaxAllowedAccountantFee)
+		it.logger().Error().Msgf("Accountant fee too large, asking for %v where %v is the limit", fee, it.deps.MaxAllowedAccountantFee)
 		return ErrAccountantFeeTooLarge
 	}
 
@@ -307,7 +320,7 @@ func (it *InvoiceTracker) Start() error {
 			err := it.sendInvoice(critical)
 			if err != nil {
 				if stdErr.Is(err, p2p.ErrSendTimeout) {
-					log.Warn().Err(err).Msg("Marking invoice as not sent")
+					it.logger().Warn().Err(err).Msg("Marking invoice as not sent")
 					it.markExchangeMessageNotSent()
 				} else {
 					return fmt.Errorf("sending of invoice failed: %w", err)
@@ -330,33 +343,47 @@ func (it *InvoiceTracker) Start() error {
 
 func (it *InvoiceTracker) sendInvoicesWhenNeeded(interval time.Duration) {
 	it.lastInvoiceSent = it.deps.TimeTracker.Elapsed()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
 	for {
 		select {
 		case <-it.stop:
 			return
-		case <-time.After(interval):
-			currentlyElapsed := it.deps.TimeTracker.Elapsed()
-			shouldBe := CalculatePaymentAmount(currentlyElapsed, it.getDataTransferred(), it.deps.Proposal.PaymentMethod)
-			lastEM := it.getLastExchangeMessage()
-			diff := safeSub(shouldBe, lastEM.AgreementTotal)
-			if diff >= it.deps.MaxNotPaidInvoice && currentlyElapsed-it.lastInvoiceSent > it.invoiceDebounceRate {
-				it.lastInvoiceSent = it.deps.TimeTracker.Elapsed()
-				it.invoiceChannel <- true
-			} else if currentlyElapsed-it.lastInvoiceSent > it.deps.ChargePeriod {
-				it.lastInvoiceSent = it.deps.TimeTracker.Elapsed()
-				it.invoiceChannel <- false
+		case <-ticker.C:
+			if due, critical := it.invoiceDue(); due {
+				it.invoiceChannel <- critical
 			}
 		}
 	}
 }
 
+// invoiceDue checks whether an invoice has to be sent on this tick.
+// Critical invoices are sent as soon as the unpaid amount exceeds MaxNotPaidInvoice.
+func (it *InvoiceTracker) invoiceDue() (due, critical bool) {
+	currentlyElapsed := it.deps.TimeTracker.Elapsed()
+	shouldBe := it.calculateOwed(currentlyElapsed)
+	lastEM := it.getLastExchangeMessage()
+	diff := safeSub(shouldBe, lastEM.AgreementTotal)
+	if diff >= it.deps.MaxNotPaidInvoice && currentlyElapsed-it.lastInvoiceSent > it.invoiceDebounceRate {
+		it.lastInvoiceSent = it.deps.TimeTracker.Elapsed()
+		return true, true
+	} else if currentlyElapsed-it.lastInvoiceSent > it.deps.ChargePeriod {
+		it.lastInvoiceSent = it.deps.TimeTracker.Elapsed()
+		return true, false
+	}
+	return false, false
+}
+
 // WaitFirstInvoice waits for a first invoice to be paid.
 func (it *InvoiceTracker) WaitFirstInvoice(wait time.Duration) error {
 	timeout := time.After(wait)
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
 
 	for {
 		select {
-		case <-time.After(10 * time.Millisecond):
+		case <-ticker.C:
 			it.invoiceLock.Lock()
 			paid := it.firstInvoicePaid
 			it.invoiceLock.Unlock()
@@ -371,6 +398,39 @@ func (it *InvoiceTracker) WaitFirstInvoice(wait time.Duration) error {
 	}
 }
 
+// Settle sends invoice for the traffic not yet paid for and waits until consumer pays it.
+// It is used to collect the final payment before provider ends the session.
+func (it *InvoiceTracker) Settle(wait time.Duration) error {
+	timeout := time.After(wait)
+	owed := it.calculateOwed(it.deps.TimeTracker.Elapsed())
+	if it.getLastExchangeMessage().AgreementTotal >= owed {
+		return nil
+	}
+
+	select {
+	case it.invoiceChannel <- false:
+	case <-it.stop:
+		return nil
+	case <-timeout:
+		return ErrFinalPaymentTimeout
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			if it.getLastExchangeMessage().AgreementTotal >= owed {
+				return nil
+			}
+		case <-it.stop:
+			return nil
+		case <-timeout:
+			return ErrFinalPaymentTimeout
+		}
+	}
+}
+
 func (it *InvoiceTracker) handlePromiseErrors(ch <-chan error) {
 	for err := range ch {
 		it.promiseErrors <- err
@@ -440,13 +500,13 @@ func (it *InvoiceTracker) sendInvoice(isCritical bool) error {
 		return ErrExchangeWaitTimeout
 	}
 
-	shouldBe := CalculatePaymentAmount(it.deps.TimeTracker.Elapsed(), it.getDataTransferred(), it.deps.Proposal.PaymentMethod)
+	shouldBe := it.calculateOwed(it.deps.TimeTracker.Elapsed())
 
 	lastEm := it.getLastExchangeMessage()
 	if lastEm.AgreementTotal == 0 && shouldBe > 0 {
 		// The first invoice should have minimal static value.
 		shouldBe = providerFirstInvoiceValue
-		log.Debug().Msgf("Being lenient for the first payment, asking for %v", shouldBe)
+		it.logger().Debug().Msgf("Being lenient for the first payment, asking for %v", shouldBe)
 	}
 
 	r := it.generateR()
@@ -485,7 +545,7 @@ func (it *InvoiceTracker) sendFirstInvoice() error {
 		case <-time.After(it.deps.FirstInvoiceSendDuration):
 			err := it.sendInvoice(true)
 			if err != nil {
-				log.Warn().Err(err).Msg("Failed to send first invoice")
+				it.logger().Warn().Err(err).Msg("Failed to send first invoice")
 				continue
 			}
 			return nil
@@ -502,12 +562,12 @@ func (it *InvoiceTracker) waitForInvoicePayment(hlock []byte) {
 		}
 
 		if inv.isCritical {
-			log.Info().Msgf("did not get paid for invoice with hashlock %v, invoice is critical. Aborting.", inv.invoice.Hashlock)
+			it.logger().Info().Msgf("did not get paid for invoice with hashlock %v, invoice is critical. Aborting.", inv.invoice.Hashlock)
 			it.criticalInvoiceErrors <- fmt.Errorf("did not get paid for critical invoice with hashlock %v", inv.invoice.Hashlock)
 			return
 		}
 
-		log.Info().Msgf("did not get paid for invoice with hashlock %v, incrementing failure count", inv.invoice.Hashlock)
+		it.logger().Info().Msgf("did not get paid for invoice with hashlock %v, incrementing failure count", inv.invoice.Hashlock)
 		it.markInvoicePaid(hlock)
 		it.markExchangeMessageNotReceived()
 	case <-it.stop:
@@ -533,14 +593,16 @@ func (it *InvoiceTracker) handleAccountantError(err error) error {
 		if it.incrementAccountantFailureCount() > it.deps.MaxAccountantFailureCount {
 			return err
 		}
-		log.Warn().Err(err).Msg("accountant error, will retry")
+		it.logger().Warn().Err(err).Msg("accountant error, will retry")
 		return nil
 	case
 		stdErr.Is(err, ErrAccountantInvalidSignature),
 		stdErr.Is(err, ErrAccountantPaymentValueTooLow),
 		stdErr.Is(err, ErrAccountantPromiseValueTooLow),
 		stdErr.Is(err, ErrAccountantOverspend),
-		stdErr.Is(err, ErrConsumerUnregistered):
+		stdErr.Is(err, ErrConsumerUnregistered),
+		stdErr.Is(err, ErrConsumerPromiseRollback),
+		stdErr.Is(err, ErrConsumerPromiseReused):
 		// these are critical, return and cancel session
 		return err
 	// under normal use, this should not occur. If it does, we should drop sessions until we settle because we're not getting paid.
@@ -554,7 +616,7 @@ func (it *InvoiceTracker) handleAccountantError(err error) error {
 		)
 		return err
 	default:
-		log.Err(err).Msgf("unknown accountant error encountered")
+		it.logger().Err(err).Msgf("unknown accountant error encountered")
 		return err
 	}
 }
@@ -563,7 +625,7 @@ func (it *InvoiceTracker) incrementAccountantFailureCount() uint64 {
 	it.accountantFailureCountLock.Lock()
 	defer it.accountantFailureCountLock.Unlock()
 	it.accountantFailureCount++
-	log.Trace().Msgf("accountant error count %v/%v", it.accountantFailureCount, it.deps.MaxAccountantFailureCount)
+	it.logger().Trace().Msgf("accountant error count %v/%v", it.accountantFailureCount, it.deps.MaxAccountantFailureCount)
 	return it.accountantFailureCount
 }
 
@@ -594,7 +656,7 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 
 	lastEm := it.getLastExchangeMessage()
 	if em.Promise.Amount < lastEm.Promise.Amount {
-		log.Warn().Msgf("Consumer sent an invalid amount. Expected < %v, got %v", lastEm.Promise.Amount, em.Promise.Amount)
+		it.logger().Warn().Msgf("Consumer sent an invalid amount. Expected < %v, got %v", lastEm.Promise.Amount, em.Promise.Amount)
 		return errors.Wrap(ErrConsumerPromiseValidationFailed, "invalid amount")
 	}
 
@@ -609,7 +671,7 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 	}
 
 	if !bytes.Equal(expectedChannel, em.Promise.ChannelID) {
-		log.Warn().Msgf("Consumer sent an invalid channel address. Expected %q, got %q", addr, hex.EncodeToString(em.Promise.ChannelID))
+		it.logger().Warn().Msgf("Consumer sent an invalid channel address. Expected %q, got %q", addr, hex.EncodeToString(em.Promise.ChannelID))
 		return errors.Wrap(ErrConsumerPromiseValidationFailed, "invalid channel address")
 	}
 	return nil
@@ -618,7 +680,7 @@ func (it *InvoiceTracker) validateExchangeMessage(em crypto.ExchangeMessage) err
 // Stop stops the invoice tracker.
 func (it *InvoiceTracker) Stop() {
 	it.once.Do(func() {
-		log.Debug().Msg("Stopping...")
+		it.logger().Debug().Msg("Stopping...")
 		_ = it.deps.EventBus.Unsubscribe(sessionEvent.AppTopicDataTransferred, it.consumeDataTransferredEvent)
 		close(it.stop)
 	})
@@ -656,6 +718,24 @@ func (it *InvoiceTracker) updateDataTransfer(up, down uint64) {
 	}
 }
 
+// calculateOwed returns the amount consumer owes for the session, the free trial allowance is not charged for.
+func (it *InvoiceTracker) calculateOwed(elapsed time.Duration) uint64 {
+	if elapsed > it.deps.FreeDuration {
+		elapsed -= it.deps.FreeDuration
+	} else {
+		elapsed = 0
+	}
+
+	transferred := it.getDataTransferred()
+	freeDown := safeSub(it.deps.FreeBytes, transferred.Up)
+	transferred = DataTransferred{
+		Up:   safeSub(transferred.Up, it.deps.FreeBytes),
+		Down: safeSub(transferred.Down, freeDown),
+	}
+
+	return CalculatePaymentAmount(elapsed, transferred, it.deps.Proposal.PaymentMethod)
+}
+
 func (it *InvoiceTracker) getDataTransferred() DataTransferred {
 	it.dataTransferredLock.Lock()
 	defer it.dataTransferredLock.Unlock()
@@ -29,6 +29,7 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
@@ -896,6 +897,10 @@ func (mp *mockPublisher) SubscribeAsync(topic string, fn interface{}) error {
 	return nil
 }
 
+func (mp *mockPublisher) SubscribeBuffered(topic string, fn interface{}, options eventbus.BufferOptions) error {
+	return nil
+}
+
 func (mp *mockPublisher) Unsubscribe(topic string, fn interface{}) error {
 	return nil
 }
@@ -939,3 +944,61 @@ func TestInvoiceTracker_validateExchangeMessage(t *testing.T) {
 		})
 	}
 }
+
+func TestInvoiceTracker_Settle(t *testing.T) {
+	timeTracker := session.NewTracker(mbtime.Now)
+	tracker := NewInvoiceTracker(InvoiceTrackerDeps{
+		Proposal: market.ServiceProposal{
+			PaymentMethod: &mockPaymentMethod{
+				price: money.NewMoney(10, money.CurrencyMyst),
+				rate:  market.PaymentRate{PerByte: 1},
+			},
+		},
+		TimeTracker: &timeTracker,
+	})
+	assert.NoError(t, tracker.Settle(time.Millisecond), "nothing is owed before any traffic")
+
+	tracker.updateDataTransfer(5, 5)
+	assert.Equal(t, ErrFinalPaymentTimeout, tracker.Settle(10*time.Millisecond))
+
+	go func() {
+		<-tracker.invoiceChannel
+		tracker.saveLastExchangeMessage(crypto.ExchangeMessage{AgreementTotal: 100})
+	}()
+	assert.NoError(t, tracker.Settle(time.Second))
+}
+
+// Invoice tick runs every second of every session, so it must not add GC pressure on low-end providers.
+func TestInvoiceTracker_invoiceDue_DoesNotAllocate(t *testing.T) {
+	defer discardLogs()()
+	tracker := NewInvoiceTracker(InvoiceTrackerDeps{
+		Proposal:          market.ServiceProposal{PaymentMethod: NewPaymentMethod(1000000, 10000)},
+		TimeTracker:       &mockTimeTracker{timeToReturn: time.Hour},
+		ChargePeriod:      time.Minute,
+		MaxNotPaidInvoice: 1000000,
+	})
+	tracker.updateDataTransfer(1024*1024, 10*1024*1024)
+
+	allocs := testing.AllocsPerRun(100, func() {
+		tracker.invoiceDue()
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkInvoiceTracker_invoiceDue(b *testing.B) {
+	defer discardLogs()()
+	timeTracker := &mockTimeTracker{}
+	tracker := NewInvoiceTracker(InvoiceTrackerDeps{
+		Proposal:          market.ServiceProposal{PaymentMethod: NewPaymentMethod(1000000, 10000)},
+		TimeTracker:       timeTracker,
+		ChargePeriod:      time.Minute,
+		MaxNotPaidInvoice: 1000000,
+	})
+	tracker.updateDataTransfer(1024*1024, 10*1024*1024)
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		timeTracker.timeToReturn = time.Duration(i) * time.Second
+		tracker.invoiceDue()
+	}
+}
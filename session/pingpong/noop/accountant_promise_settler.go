@@ -37,6 +37,11 @@ func (n *NoopAccountantPromiseSettler) GetEarnings(_ identity.Identity) event.Ea
 	return event.Earnings{}
 }
 
+// GetEarningsPerAccountant returns an empty state.
+func (n *NoopAccountantPromiseSettler) GetEarningsPerAccountant(_ identity.Identity) map[common.Address]event.Earnings {
+	return map[common.Address]event.Earnings{}
+}
+
 // ForceSettle does nothing.
 func (n *NoopAccountantPromiseSettler) ForceSettle(_ identity.Identity, _ common.Address) error {
 	return nil
@@ -64,6 +64,11 @@ func CalculatePaymentAmount(timePassed time.Duration, bytesTransferred DataTrans
 
 	byteComponent := uint64(math.Round(chunksTransferred * float64(price)))
 	total := timeComponent + byteComponent
-	log.Debug().Msgf("Calculated price %v. Time component: %v, data component: %v ", total, timeComponent, byteComponent)
+	// Structured fields keep price calculation, which happens on every invoice tick, free of allocations.
+	log.Debug().
+		Uint64("total", total).
+		Uint64("time_component", timeComponent).
+		Uint64("data_component", byteComponent).
+		Msg("Calculated price")
 	return total
 }
@@ -18,11 +18,14 @@
 package pingpong
 
 import (
+	"io/ioutil"
 	"testing"
 	"time"
 
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/money"
+	"github.com/rs/zerolog/log"
+	"github.com/stretchr/testify/assert"
 )
 
 func Test_isServiceFree(t *testing.T) {
@@ -178,3 +181,40 @@ func Test_CalculatePaymentAmount(t *testing.T) {
 		})
 	}
 }
+
+// Price is calculated on every invoice tick of every session, so it must not add GC pressure on low-end providers.
+func Test_CalculatePaymentAmount_DoesNotAllocate(t *testing.T) {
+	defer discardLogs()()
+	method := &mockPaymentMethod{
+		price: money.NewMoney(50000, money.CurrencyMyst),
+		rate:  market.PaymentRate{PerTime: time.Minute, PerByte: 7669584},
+	}
+	transferred := DataTransferred{Up: 1024 * 1024, Down: 10 * 1024 * 1024}
+
+	allocs := testing.AllocsPerRun(100, func() {
+		CalculatePaymentAmount(time.Hour, transferred, method)
+	})
+	assert.Zero(t, allocs)
+}
+
+func BenchmarkCalculatePaymentAmount(b *testing.B) {
+	defer discardLogs()()
+	method := &mockPaymentMethod{
+		price: money.NewMoney(50000, money.CurrencyMyst),
+		rate:  market.PaymentRate{PerTime: time.Minute, PerByte: 7669584},
+	}
+	transferred := DataTransferred{Up: 1024 * 1024, Down: 10 * 1024 * 1024}
+
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		CalculatePaymentAmount(time.Duration(i)*time.Second, transferred, method)
+	}
+}
+
+func discardLogs() (restore func()) {
+	logger := log.Logger
+	log.Logger = log.Output(ioutil.Discard)
+	return func() {
+		log.Logger = logger
+	}
+}
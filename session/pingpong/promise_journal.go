@@ -0,0 +1,330 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/rs/zerolog/log"
+)
+
+const promiseJournalBucket = "consumer_promise_journal"
+
+// ErrConsumerPromiseRollback indicates that consumer sent a promise lower than the one it has already promised.
+var ErrConsumerPromiseRollback = errors.New("consumer promise amount is lower than previously promised")
+
+// ErrConsumerPromiseReused indicates that consumer sent a promise which has already been exchanged.
+var ErrConsumerPromiseReused = errors.New("consumer promise has already been used")
+
+// ErrPromiseJournalCorrupted indicates that the journal entry does not match its checksum.
+var ErrPromiseJournalCorrupted = errors.New("promise journal entry is corrupted")
+
+// PromiseJournalState represents the state of a journaled consumer promise.
+type PromiseJournalState string
+
+const (
+	// PromiseJournalPending indicates that the promise was accepted from consumer but not yet exchanged with the accountant.
+	PromiseJournalPending PromiseJournalState = "pending"
+	// PromiseJournalCommitted indicates that the promise was exchanged with the accountant.
+	PromiseJournalCommitted PromiseJournalState = "committed"
+	// PromiseJournalAbandoned indicates that the promise was never exchanged with the accountant, e.g. node crashed in between.
+	PromiseJournalAbandoned PromiseJournalState = "abandoned"
+)
+
+// PromiseJournalEntry is the latest promise accepted from the consumer channel.
+type PromiseJournalEntry struct {
+	ChannelID    string `storm:"id"`
+	Consumer     string
+	AccountantID string
+	Amount       money.Tokens
+	Hashlock     string
+	SessionID    string
+	State        PromiseJournalState
+	UpdatedAt    time.Time
+	Checksum     string
+}
+
+func (e PromiseJournalEntry) checksum() string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf(
+		"%v|%v|%v|%v|%v|%v|%v|%v",
+		e.ChannelID, e.Consumer, e.AccountantID, e.Amount, e.Hashlock, e.SessionID, e.State, e.UpdatedAt.UnixNano(),
+	)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (e PromiseJournalEntry) valid() bool {
+	return e.Checksum == e.checksum()
+}
+
+// PromiseJournalReport summarizes the state of the promise journal.
+type PromiseJournalReport struct {
+	Entries   int
+	Pending   int
+	Abandoned int
+	Corrupted []string
+}
+
+type promiseJournalStorage interface {
+	Store(bucket string, data interface{}) error
+	GetAllFrom(bucket string, data interface{}) error
+	GetOneByField(bucket string, fieldName string, key interface{}, to interface{}) error
+	Delete(bucket string, data interface{}) error
+}
+
+type consumerDataProvider interface {
+	GetConsumerData(accountantID common.Address, consumerID string) (ConsumerData, error)
+}
+
+// PromiseJournal is a write-ahead journal of promises provider accepts from consumers.
+// Promise is journaled before it is exchanged with the accountant and committed afterwards,
+// so that neither a crash nor a reconnecting consumer can make provider accept a stale promise.
+type PromiseJournal struct {
+	lock        sync.Mutex
+	storage     promiseJournalStorage
+	accountants consumerDataProvider
+	now         func() time.Time
+}
+
+// NewPromiseJournal returns a new instance of the promise journal.
+func NewPromiseJournal(storage promiseJournalStorage, accountants consumerDataProvider) *PromiseJournal {
+	return &PromiseJournal{
+		storage:     storage,
+		accountants: accountants,
+		now:         time.Now,
+	}
+}
+
+// Begin validates the promise against the journal and records it as pending.
+func (pj *PromiseJournal) Begin(accountantID common.Address, em crypto.ExchangeMessage, sessionID string) error {
+	consumer, err := em.Promise.RecoverSigner()
+	if err != nil {
+		return fmt.Errorf("could not recover promise signer: %w", err)
+	}
+
+	pj.lock.Lock()
+	defer pj.lock.Unlock()
+
+	channelID := hex.EncodeToString(em.Promise.ChannelID)
+	hashlock := hex.EncodeToString(em.Promise.Hashlock)
+
+	previous, err := pj.get(channelID)
+	switch {
+	case err == nil:
+		if previous.Hashlock == hashlock {
+			log.Warn().Msgf("Consumer %v reused promise with hashlock %v", consumer.Hex(), hashlock)
+			return ErrConsumerPromiseReused
+		}
+		if money.NewTokens(em.Promise.Amount).Cmp(previous.Amount) < 0 {
+			log.Warn().Msgf("Consumer %v rolled back promise. Expected >= %v, got %v", consumer.Hex(), previous.Amount, em.Promise.Amount)
+			return ErrConsumerPromiseRollback
+		}
+	case errors.Is(err, ErrNotFound):
+	case errors.Is(err, ErrPromiseJournalCorrupted):
+		log.Error().Err(err).Msg("Promise journal needs to be repaired")
+		return err
+	default:
+		return err
+	}
+
+	return pj.store(PromiseJournalEntry{
+		ChannelID:    channelID,
+		Consumer:     consumer.Hex(),
+		AccountantID: accountantID.Hex(),
+		Amount:       money.NewTokens(em.Promise.Amount),
+		Hashlock:     hashlock,
+		SessionID:    sessionID,
+		State:        PromiseJournalPending,
+	})
+}
+
+// Commit marks the promise as exchanged with the accountant.
+func (pj *PromiseJournal) Commit(em crypto.ExchangeMessage) error {
+	pj.lock.Lock()
+	defer pj.lock.Unlock()
+
+	entry, err := pj.get(hex.EncodeToString(em.Promise.ChannelID))
+	if err != nil {
+		return err
+	}
+	if entry.Hashlock != hex.EncodeToString(em.Promise.Hashlock) {
+		// a newer promise has already been journaled
+		return nil
+	}
+
+	entry.State = PromiseJournalCommitted
+	return pj.store(entry)
+}
+
+// Check inspects the journal and reports its state.
+func (pj *PromiseJournal) Check() (PromiseJournalReport, error) {
+	pj.lock.Lock()
+	defer pj.lock.Unlock()
+
+	entries, err := pj.list()
+	if err != nil {
+		return PromiseJournalReport{}, err
+	}
+	return report(entries), nil
+}
+
+// Repair removes corrupted entries and reconciles the rest against the accountants.
+func (pj *PromiseJournal) Repair() (PromiseJournalReport, error) {
+	pj.lock.Lock()
+	entries, err := pj.list()
+	if err != nil {
+		pj.lock.Unlock()
+		return PromiseJournalReport{}, err
+	}
+	for _, entry := range entries {
+		if entry.valid() {
+			continue
+		}
+		log.Warn().Msgf("Removing corrupted promise journal entry of channel %v", entry.ChannelID)
+		if err := pj.storage.Delete(promiseJournalBucket, &PromiseJournalEntry{ChannelID: entry.ChannelID}); err != nil {
+			pj.lock.Unlock()
+			return PromiseJournalReport{}, fmt.Errorf("could not remove corrupted promise journal entry: %w", err)
+		}
+	}
+	pj.lock.Unlock()
+
+	if err := pj.Reconcile(); err != nil {
+		return PromiseJournalReport{}, err
+	}
+	return pj.Check()
+}
+
+// Reconcile brings the journal in line with the state accountants have:
+// pending promises accountant has already seen are committed, the rest are abandoned,
+// and promises consumers have issued elsewhere since raise the accepted minimum.
+func (pj *PromiseJournal) Reconcile() error {
+	pj.lock.Lock()
+	entries, err := pj.list()
+	pj.lock.Unlock()
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if !entry.valid() {
+			log.Warn().Msgf("Promise journal entry of channel %v is corrupted, skipping it", entry.ChannelID)
+			continue
+		}
+
+		data, err := pj.accountants.GetConsumerData(common.HexToAddress(entry.AccountantID), entry.Consumer)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not reconcile promise journal entry of channel %v", entry.ChannelID)
+			continue
+		}
+
+		if err := pj.reconcile(entry, data.LatestPromise); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (pj *PromiseJournal) reconcile(entry PromiseJournalEntry, latest LatestPromise) error {
+	pj.lock.Lock()
+	defer pj.lock.Unlock()
+
+	current, err := pj.get(entry.ChannelID)
+	if err != nil {
+		return err
+	}
+	if current.Hashlock != entry.Hashlock {
+		// a newer promise was journaled meanwhile
+		return nil
+	}
+
+	changed := false
+	if current.State == PromiseJournalPending {
+		if latest.Amount.Cmp(current.Amount) >= 0 {
+			current.State = PromiseJournalCommitted
+		} else {
+			current.State = PromiseJournalAbandoned
+		}
+		changed = true
+	}
+	if latest.Amount.Cmp(current.Amount) > 0 {
+		current.Amount = latest.Amount
+		current.Hashlock = latest.Hashlock
+		changed = true
+	}
+	if !changed {
+		return nil
+	}
+	return pj.store(current)
+}
+
+func (pj *PromiseJournal) get(channelID string) (PromiseJournalEntry, error) {
+	var entry PromiseJournalEntry
+	err := pj.storage.GetOneByField(promiseJournalBucket, "ChannelID", channelID, &entry)
+	if err != nil {
+		if err.Error() == errBoltNotFound {
+			return entry, ErrNotFound
+		}
+		return entry, fmt.Errorf("could not get promise journal entry: %w", err)
+	}
+	if !entry.valid() {
+		return entry, fmt.Errorf("channel %v: %w", channelID, ErrPromiseJournalCorrupted)
+	}
+	return entry, nil
+}
+
+func (pj *PromiseJournal) list() ([]PromiseJournalEntry, error) {
+	var entries []PromiseJournalEntry
+	err := pj.storage.GetAllFrom(promiseJournalBucket, &entries)
+	if err != nil && err.Error() != errBoltNotFound {
+		return nil, fmt.Errorf("could not list promise journal entries: %w", err)
+	}
+	return entries, nil
+}
+
+func (pj *PromiseJournal) store(entry PromiseJournalEntry) error {
+	entry.UpdatedAt = pj.now().UTC()
+	entry.Checksum = entry.checksum()
+	if err := pj.storage.Store(promiseJournalBucket, &entry); err != nil {
+		return fmt.Errorf("could not store promise journal entry: %w", err)
+	}
+	return nil
+}
+
+func report(entries []PromiseJournalEntry) PromiseJournalReport {
+	r := PromiseJournalReport{Entries: len(entries)}
+	for _, entry := range entries {
+		if !entry.valid() {
+			r.Corrupted = append(r.Corrupted, entry.ChannelID)
+			continue
+		}
+		switch entry.State {
+		case PromiseJournalPending:
+			r.Pending++
+		case PromiseJournalAbandoned:
+			r.Abandoned++
+		}
+	}
+	return r
+}
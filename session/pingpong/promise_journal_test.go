@@ -0,0 +1,207 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package pingpong
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/payments/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+const journalTestChannel = "0x30960954558C5bFA0D4153B0002B1d1E3E3f5Ff5"
+
+type mockConsumerDataProvider struct {
+	data ConsumerData
+	err  error
+}
+
+func (mcdp *mockConsumerDataProvider) GetConsumerData(_ common.Address, _ string) (ConsumerData, error) {
+	return mcdp.data, mcdp.err
+}
+
+func newTestPromiseJournal(t *testing.T, accountants consumerDataProvider) (*PromiseJournal, *boltdb.Bolt, func()) {
+	dir, err := ioutil.TempDir("", "promiseJournalTest")
+	assert.NoError(t, err)
+
+	bolt, err := boltdb.NewStorage(dir)
+	assert.NoError(t, err)
+
+	return NewPromiseJournal(bolt, accountants), bolt, func() {
+		bolt.Close()
+		os.RemoveAll(dir)
+	}
+}
+
+// newTestConsumer returns a function issuing promises signed by a fresh consumer identity.
+func newTestConsumer(t *testing.T) func(amount uint64, hashlock string) crypto.ExchangeMessage {
+	ks := identity.NewMockKeystore()
+	acc, err := ks.NewAccount("")
+	assert.NoError(t, err)
+	assert.NoError(t, ks.Unlock(acc, ""))
+
+	return func(amount uint64, hashlock string) crypto.ExchangeMessage {
+		promise, err := crypto.CreatePromise(journalTestChannel, amount, 0, hashlock, ks, acc.Address)
+		assert.NoError(t, err)
+		return crypto.ExchangeMessage{Promise: *promise}
+	}
+}
+
+func TestPromiseJournal_Begin(t *testing.T) {
+	journal, _, cleanup := newTestPromiseJournal(t, &mockConsumerDataProvider{})
+	defer cleanup()
+	promise := newTestConsumer(t)
+	accountantID := common.HexToAddress("0x1")
+
+	first := promise(10, "0x01")
+	assert.NoError(t, journal.Begin(accountantID, first, "session"))
+
+	report, err := journal.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseJournalReport{Entries: 1, Pending: 1}, report)
+
+	assert.NoError(t, journal.Commit(first))
+	report, err = journal.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseJournalReport{Entries: 1}, report)
+
+	err = journal.Begin(accountantID, first, "other-session")
+	assert.True(t, errors.Is(err, ErrConsumerPromiseReused))
+
+	rolledBack := promise(5, "0x02")
+	err = journal.Begin(accountantID, rolledBack, "other-session")
+	assert.True(t, errors.Is(err, ErrConsumerPromiseRollback))
+
+	next := promise(15, "0x03")
+	assert.NoError(t, journal.Begin(accountantID, next, "other-session"))
+}
+
+func TestPromiseJournal_Reconcile(t *testing.T) {
+	accountants := &mockConsumerDataProvider{}
+	journal, _, cleanup := newTestPromiseJournal(t, accountants)
+	defer cleanup()
+	promise := newTestConsumer(t)
+	accountantID := common.HexToAddress("0x1")
+
+	// accountant has seen the pending promise before the crash
+	assert.NoError(t, journal.Begin(accountantID, promise(10, "0x01"), "session"))
+	accountants.data.LatestPromise = LatestPromise{Amount: money.NewTokens(10), Hashlock: "0x01"}
+	assert.NoError(t, journal.Reconcile())
+
+	report, err := journal.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseJournalReport{Entries: 1}, report)
+
+	// accountant has never seen the pending promise
+	assert.NoError(t, journal.Begin(accountantID, promise(20, "0x02"), "session"))
+	assert.NoError(t, journal.Reconcile())
+
+	report, err = journal.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseJournalReport{Entries: 1, Abandoned: 1}, report)
+
+	// consumer has promised more elsewhere since
+	accountants.data.LatestPromise = LatestPromise{Amount: money.NewTokens(100), Hashlock: "0x03"}
+	assert.NoError(t, journal.Reconcile())
+
+	err = journal.Begin(accountantID, promise(50, "0x04"), "session")
+	assert.True(t, errors.Is(err, ErrConsumerPromiseRollback))
+}
+
+func TestPromiseJournal_Repair(t *testing.T) {
+	journal, bolt, cleanup := newTestPromiseJournal(t, &mockConsumerDataProvider{err: errors.New("unreachable")})
+	defer cleanup()
+	promise := newTestConsumer(t)
+	accountantID := common.HexToAddress("0x1")
+
+	em := promise(10, "0x01")
+	assert.NoError(t, journal.Begin(accountantID, em, "session"))
+
+	var entries []PromiseJournalEntry
+	assert.NoError(t, bolt.GetAllFrom(promiseJournalBucket, &entries))
+	assert.Len(t, entries, 1)
+	entries[0].Amount = money.NewTokens(1)
+	assert.NoError(t, bolt.Store(promiseJournalBucket, &entries[0]))
+
+	report, err := journal.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{entries[0].ChannelID}, report.Corrupted)
+
+	err = journal.Begin(accountantID, promise(20, "0x02"), "session")
+	assert.True(t, errors.Is(err, ErrPromiseJournalCorrupted))
+
+	report, err = journal.Repair()
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseJournalReport{}, report)
+
+	assert.NoError(t, journal.Begin(accountantID, promise(20, "0x02"), "session"))
+}
+
+func TestPromiseJournal_ReadsEntriesStoredWithNumericAmount(t *testing.T) {
+	journal, bolt, cleanup := newTestPromiseJournal(t, &mockConsumerDataProvider{})
+	defer cleanup()
+	promise := newTestConsumer(t)
+	accountantID := common.HexToAddress("0x1")
+
+	em := promise(10, "0x01")
+	assert.NoError(t, journal.Begin(accountantID, em, "session"))
+	var entries []PromiseJournalEntry
+	assert.NoError(t, bolt.GetAllFrom(promiseJournalBucket, &entries))
+	assert.Len(t, entries, 1)
+
+	// Entries were stored with uint64 amount before, storm names the bucket after the type.
+	type PromiseJournalEntry struct {
+		ChannelID    string `storm:"id"`
+		Consumer     string
+		AccountantID string
+		Amount       uint64
+		Hashlock     string
+		SessionID    string
+		State        PromiseJournalState
+		UpdatedAt    time.Time
+		Checksum     string
+	}
+	stored := entries[0]
+	legacy := PromiseJournalEntry{
+		ChannelID:    stored.ChannelID,
+		Consumer:     stored.Consumer,
+		AccountantID: stored.AccountantID,
+		Amount:       stored.Amount.Uint64(),
+		Hashlock:     stored.Hashlock,
+		SessionID:    stored.SessionID,
+		State:        stored.State,
+		UpdatedAt:    stored.UpdatedAt,
+		Checksum:     stored.Checksum,
+	}
+	assert.NoError(t, bolt.Store(promiseJournalBucket, &legacy))
+
+	report, err := journal.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, PromiseJournalReport{Entries: 1, Pending: 1}, report)
+
+	err = journal.Begin(accountantID, promise(5, "0x02"), "session")
+	assert.True(t, errors.Is(err, ErrConsumerPromiseRollback))
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+// ProtocolVersion is the version of session protocol implemented by this node.
+// Peers which do not send protocol version are treated as version 0.
+const ProtocolVersion uint32 = 1
+
+const (
+	// CapabilityPaymentsV3 indicates support of "v3" payments.
+	CapabilityPaymentsV3 = "payments-v3"
+	// CapabilityCompression indicates support of p2p payload compression.
+	CapabilityCompression = "compression"
+	// CapabilityReconfigure indicates support of session config updates pushed by provider mid-session.
+	CapabilityReconfigure = "reconfigure"
+	// CapabilitySpeedTest indicates that provider lets consumer measure the connection speed to it.
+	CapabilitySpeedTest = "speed-test"
+)
+
+// Capabilities returns optional session features supported by this node.
+func Capabilities() []string {
+	return []string{CapabilityPaymentsV3, CapabilityCompression, CapabilityReconfigure, CapabilitySpeedTest}
+}
+
+// HasCapability checks if given capability is in the list.
+func HasCapability(capabilities []string, capability string) bool {
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// WithoutCapability returns the list with the given capability removed.
+func WithoutCapability(capabilities []string, capability string) []string {
+	var left []string
+	for _, c := range capabilities {
+		if c != capability {
+			left = append(left, c)
+		}
+	}
+	return left
+}
+
+// NegotiateVersion returns protocol version which both peers are able to speak.
+func NegotiateVersion(peerVersion uint32) uint32 {
+	if peerVersion < ProtocolVersion {
+		return peerVersion
+	}
+	return ProtocolVersion
+}
+
+// NegotiateCapabilities returns capabilities supported by both peers keeping the local order.
+func NegotiateCapabilities(peerCapabilities []string) []string {
+	peer := make(map[string]struct{}, len(peerCapabilities))
+	for _, c := range peerCapabilities {
+		peer[c] = struct{}{}
+	}
+
+	var agreed []string
+	for _, c := range Capabilities() {
+		if _, ok := peer[c]; ok {
+			agreed = append(agreed, c)
+		}
+	}
+	return agreed
+}
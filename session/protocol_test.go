@@ -0,0 +1,48 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNegotiateVersion(t *testing.T) {
+	assert.Equal(t, uint32(0), NegotiateVersion(0))
+	assert.Equal(t, ProtocolVersion, NegotiateVersion(ProtocolVersion))
+	assert.Equal(t, ProtocolVersion, NegotiateVersion(ProtocolVersion+1))
+}
+
+func TestNegotiateCapabilities(t *testing.T) {
+	assert.Nil(t, NegotiateCapabilities(nil))
+	assert.Equal(t, []string{CapabilityPaymentsV3}, NegotiateCapabilities([]string{"relay", CapabilityPaymentsV3}))
+	assert.Equal(t, Capabilities(), NegotiateCapabilities([]string{CapabilitySpeedTest, CapabilityReconfigure, CapabilityCompression, CapabilityPaymentsV3}))
+}
+
+func TestWithoutCapability(t *testing.T) {
+	assert.Equal(t, []string{CapabilityPaymentsV3}, WithoutCapability([]string{CapabilityPaymentsV3, CapabilitySpeedTest}, CapabilitySpeedTest))
+	assert.Equal(t, []string{CapabilityPaymentsV3}, WithoutCapability([]string{CapabilityPaymentsV3}, CapabilitySpeedTest))
+	assert.Nil(t, WithoutCapability(nil, CapabilitySpeedTest))
+}
+
+func TestHasCapability(t *testing.T) {
+	assert.True(t, HasCapability(Capabilities(), CapabilityReconfigure))
+	assert.False(t, HasCapability([]string{CapabilityPaymentsV3}, CapabilityReconfigure))
+	assert.False(t, HasCapability(nil, CapabilityReconfigure))
+}
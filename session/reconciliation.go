@@ -0,0 +1,78 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"sync"
+	"time"
+)
+
+// ReconciliationConfig defines how session traffic counted by consumer and provider is compared.
+type ReconciliationConfig struct {
+	// Interval is the period of exchanging traffic counters, counters are not reconciled if 0.
+	Interval time.Duration
+	// Tolerance is the largest relative difference of traffic totals not considered a divergence.
+	Tolerance float64
+	// MinBytes is the difference of traffic totals ignored regardless of tolerance, it covers counters sampled at different moments.
+	MinBytes uint64
+	// PausePayments stops paying invoices of the session while traffic counters diverge.
+	PausePayments bool
+}
+
+// Enabled checks whether traffic counters should be reconciled.
+func (c ReconciliationConfig) Enabled() bool {
+	return c.Interval > 0
+}
+
+// Diverges checks whether traffic totals of both parties differ significantly.
+func (c ReconciliationConfig) Diverges(local, remote uint64) bool {
+	larger, smaller := local, remote
+	if smaller > larger {
+		larger, smaller = smaller, larger
+	}
+	difference := larger - smaller
+	if difference <= c.MinBytes {
+		return false
+	}
+	return float64(difference) > c.Tolerance*float64(larger)
+}
+
+// TrafficReconciler keeps the reconciliation state of a single session.
+type TrafficReconciler struct {
+	config ReconciliationConfig
+
+	mu       sync.Mutex
+	diverged bool
+}
+
+// NewTrafficReconciler returns reconciler of session traffic counters.
+func NewTrafficReconciler(config ReconciliationConfig) *TrafficReconciler {
+	return &TrafficReconciler{config: config}
+}
+
+// Reconcile compares traffic totals of both parties, it tells whether they diverge
+// and whether that changed since the previous comparison.
+func (r *TrafficReconciler) Reconcile(local, remote uint64) (diverged, changed bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	diverged = r.config.Diverges(local, remote)
+	changed = diverged != r.diverged
+	r.diverged = diverged
+	return diverged, changed
+}
@@ -0,0 +1,60 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReconciliationConfig_Enabled(t *testing.T) {
+	assert.False(t, ReconciliationConfig{}.Enabled())
+	assert.True(t, ReconciliationConfig{Interval: time.Minute}.Enabled())
+}
+
+func TestReconciliationConfig_Diverges(t *testing.T) {
+	config := ReconciliationConfig{Tolerance: 0.1, MinBytes: 100}
+
+	assert.False(t, config.Diverges(0, 0))
+	assert.False(t, config.Diverges(0, 100), "differences below min bytes are ignored")
+	assert.False(t, config.Diverges(10000, 9000))
+	assert.True(t, config.Diverges(10000, 8999))
+	assert.True(t, config.Diverges(8999, 10000))
+}
+
+func TestTrafficReconciler_Reconcile(t *testing.T) {
+	reconciler := NewTrafficReconciler(ReconciliationConfig{Tolerance: 0.1})
+
+	diverged, changed := reconciler.Reconcile(1000, 1000)
+	assert.False(t, diverged)
+	assert.False(t, changed)
+
+	diverged, changed = reconciler.Reconcile(2000, 1000)
+	assert.True(t, diverged)
+	assert.True(t, changed)
+
+	diverged, changed = reconciler.Reconcile(3000, 1000)
+	assert.True(t, diverged)
+	assert.False(t, changed)
+
+	diverged, changed = reconciler.Reconcile(3000, 3000)
+	assert.False(t, diverged)
+	assert.True(t, changed)
+}
@@ -0,0 +1,50 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package session
+
+// Reasons of session termination recorded in session history.
+const (
+	// TerminationRequested means that the session was ended on request of the user or peer.
+	TerminationRequested = "requested"
+	// TerminationCanceled means that connecting was canceled before the session was established.
+	TerminationCanceled = "canceled"
+	// TerminationSetupFailed means that the session could not be established.
+	TerminationSetupFailed = "setup_failed"
+	// TerminationPeerLost means that the peer stopped responding to keepalive pings.
+	TerminationPeerLost = "peer_lost"
+	// TerminationConnectionLost means that the service tunnel went down.
+	TerminationConnectionLost = "connection_lost"
+	// TerminationPaymentFailed means that the session was ended because of payment errors.
+	TerminationPaymentFailed = "payment_failed"
+	// TerminationReconnect means that the session was replaced by a new one, e.g. after waking up from sleep.
+	TerminationReconnect = "reconnect"
+	// TerminationServiceStopped means that the provider stopped the service.
+	TerminationServiceStopped = "service_stopped"
+	// TerminationServicePaused means that the provider paused the service suspending its sessions.
+	TerminationServicePaused = "service_paused"
+	// TerminationMaxDuration means that the session reached the maximum duration requested on connect.
+	TerminationMaxDuration = "max_duration"
+	// TerminationMaxCost means that the session reached the maximum cost requested on connect.
+	TerminationMaxCost = "max_cost"
+	// TerminationMaxTraffic means that the session transferred the maximum amount of data requested on connect.
+	TerminationMaxTraffic = "max_traffic"
+	// TerminationIdle means that the session transferred too little data during the idle timeout.
+	TerminationIdle = "idle"
+	// TerminationSlowConsumer means that the consumer stopped receiving messages sent by provider.
+	TerminationSlowConsumer = "slow_consumer"
+)
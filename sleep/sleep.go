@@ -24,7 +24,7 @@ type Event int
 
 const (
 	// AppTopicSleepNotification represents sleep management Event notification
-	AppTopicSleepNotification = "sleep_notification"
+	AppTopicSleepNotification = "sleep.notification"
 
 	// EventWakeup event sent to node after OS wakes up from sleep
 	EventWakeup Event = iota
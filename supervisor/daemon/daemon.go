@@ -102,6 +102,14 @@ func (d *Daemon) dialog(conn io.ReadWriter) {
 			} else {
 				answer.ok(stats)
 			}
+		case commandWgReconfigure:
+			err := d.wgReconfigure(cmd...)
+			if err != nil {
+				log.Err(err).Msgf("%s failed", commandWgReconfigure)
+				answer.err(err)
+			} else {
+				answer.ok()
+			}
 		case commandKill:
 			if err := d.killMyst(); err != nil {
 				log.Err(err).Msgf("%s failed", commandKill)
@@ -162,6 +170,36 @@ func (d *Daemon) wgDown(args ...string) (err error) {
 	return nil
 }
 
+func (d *Daemon) wgReconfigure(args ...string) error {
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	interfaceName := flags.String("iface", "", "")
+	deviceConfigStr := flags.String("config", "", "Device configuration JSON string")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+	if *interfaceName == "" {
+		return errors.New("-iface is required")
+	}
+	if *deviceConfigStr == "" {
+		return errors.New("-config is required")
+	}
+
+	configJSON, err := base64.StdEncoding.DecodeString(*deviceConfigStr)
+	if err != nil {
+		return fmt.Errorf("could not decode config from base64: %w", err)
+	}
+
+	deviceConfig := wgcfg.DeviceConfig{}
+	if err := json.Unmarshal(configJSON, &deviceConfig); err != nil {
+		return fmt.Errorf("could not unmarshal device config: %w", err)
+	}
+
+	if err := d.monitor.Reconfigure(*interfaceName, deviceConfig); err != nil {
+		return fmt.Errorf("failed to reconfigure wg interface %s: %w", *interfaceName, err)
+	}
+	return nil
+}
+
 func (d *Daemon) wgStats(args ...string) (string, error) {
 	flags := flag.NewFlagSet("", flag.ContinueOnError)
 	interfaceName := flags.String("iface", "", "")
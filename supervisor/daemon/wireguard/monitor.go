@@ -70,6 +70,19 @@ func (m *Monitor) Down(interfaceName string) error {
 	return nil
 }
 
+// Reconfigure requests private key and peer update of the running interface.
+func (m *Monitor) Reconfigure(interfaceName string, cfg wgcfg.DeviceConfig) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	iface, ok := m.interfaces[interfaceName]
+	if !ok {
+		return fmt.Errorf("interface %s not found", interfaceName)
+	}
+
+	return userspace.ReconfigureDevice(iface.Device, cfg)
+}
+
 // Stats requests interface statistics.
 func (m *Monitor) Stats(interfaceName string) (*wgcfg.Stats, error) {
 	m.mu.Lock()
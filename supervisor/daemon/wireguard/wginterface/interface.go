@@ -40,7 +40,7 @@ type WgInterface struct {
 
 // New creates new WgInterface instance.
 func New(cfg wgcfg.DeviceConfig, uid string) (*WgInterface, error) {
-	tunnel, interfaceName, err := createTunnel(cfg.IfaceName)
+	tunnel, interfaceName, err := createTunnel(cfg.IfaceName, cfg.MTU)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create TUN device %s: %w", cfg.IfaceName, err)
 	}
@@ -31,8 +31,11 @@ import (
 	"golang.zx2c4.com/wireguard/tun"
 )
 
-func createTunnel(requestedInterfaceName string) (tunnel tun.Device, interfaceName string, err error) {
-	tunnel, err = tun.CreateTUN(requestedInterfaceName, device.DefaultMTU)
+func createTunnel(requestedInterfaceName string, mtu int) (tunnel tun.Device, interfaceName string, err error) {
+	if mtu == 0 {
+		mtu = device.DefaultMTU
+	}
+	tunnel, err = tun.CreateTUN(requestedInterfaceName, mtu)
 	if err == nil {
 		interfaceName = requestedInterfaceName
 		realInterfaceName, err2 := tunnel.Name()
@@ -24,7 +24,7 @@ import (
 	"golang.zx2c4.com/wireguard/tun"
 )
 
-func createTunnel(requestedInterfaceName string) (tunnel tun.Device, interfaceName string, err error) {
+func createTunnel(requestedInterfaceName string, _ int) (tunnel tun.Device, interfaceName string, err error) {
 	return nil, requestedInterfaceName, errors.New("not implemented")
 }
 
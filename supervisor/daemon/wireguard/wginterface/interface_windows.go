@@ -27,9 +27,9 @@ import (
 	"golang.zx2c4.com/wireguard/tun"
 )
 
-func createTunnel(interfaceName string) (tunnel tun.Device, _ string, err error) {
+func createTunnel(interfaceName string, mtu int) (tunnel tun.Device, _ string, err error) {
 	log.Info().Msg("Creating Wintun interface")
-	wintun, err := tun.CreateTUN(interfaceName, 0)
+	wintun, err := tun.CreateTUN(interfaceName, mtu)
 	if err != nil {
 		return nil, interfaceName, fmt.Errorf("could not create Wintun tunnel: %w", err)
 	}
@@ -18,9 +18,13 @@
 package client
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 
@@ -30,6 +34,8 @@ import (
 	"github.com/mysteriumnetwork/node/tequilapi/validation"
 )
 
+const stateChangeEvent = "state-change"
+
 // NewClient returns a new instance of Client
 func NewClient(ip string, port int) *Client {
 	return &Client{
@@ -185,6 +191,76 @@ func (client *Client) ConnectionCreate(consumerID, providerID, accountantID, ser
 	return status, err
 }
 
+// ConnectionProbe initiates a short test-drive connection, which is closed automatically after reaching its limits
+func (client *Client) ConnectionProbe(consumerID, providerID, accountantID, serviceType string, options contract.ConnectOptions) (status contract.ConnectionStatusDTO, err error) {
+	response, err := client.http.Put("connection/probe", contract.ConnectionCreateRequest{
+		ConsumerID:     consumerID,
+		ProviderID:     providerID,
+		AccountantID:   accountantID,
+		ServiceType:    serviceType,
+		ConnectOptions: options,
+	})
+	if err != nil {
+		return contract.ConnectionStatusDTO{}, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &status)
+	return status, err
+}
+
+// ConnectionCreateFromInvite initiates a new connection to the unlisted provider using the shared invite code
+func (client *Client) ConnectionCreateFromInvite(consumerID, accountantID, inviteCode string, options contract.ConnectOptions) (status contract.ConnectionStatusDTO, err error) {
+	response, err := client.http.Post("connection/invite", contract.ConnectionInviteRequest{
+		ConsumerID:     consumerID,
+		AccountantID:   accountantID,
+		InviteCode:     inviteCode,
+		ConnectOptions: options,
+	})
+	if err != nil {
+		return contract.ConnectionStatusDTO{}, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &status)
+	return status, err
+}
+
+// ConnectionCreateForCountry initiates a new connection to the best available provider of the given country
+func (client *Client) ConnectionCreateForCountry(consumerID, accountantID, country, serviceType string, options contract.ConnectOptions) (status contract.ConnectionStatusDTO, err error) {
+	response, err := client.http.Post("connection/country", contract.ConnectionCountryRequest{
+		ConsumerID:     consumerID,
+		AccountantID:   accountantID,
+		Country:        country,
+		ServiceType:    serviceType,
+		ConnectOptions: options,
+	})
+	if err != nil {
+		return contract.ConnectionStatusDTO{}, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &status)
+	return status, err
+}
+
+// ConnectionPreflight checks whether connection to a host identified by providerID is likely to succeed
+func (client *Client) ConnectionPreflight(consumerID, providerID, serviceType, natType string) (preflight contract.ConnectionPreflightDTO, err error) {
+	response, err := client.http.Post("connection/preflight", contract.ConnectionPreflightRequest{
+		ConsumerID:  consumerID,
+		ProviderID:  providerID,
+		ServiceType: serviceType,
+		NATType:     natType,
+	})
+	if err != nil {
+		return contract.ConnectionPreflightDTO{}, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &preflight)
+	return preflight, err
+}
+
 // ConnectionDestroy terminates current connection
 func (client *Client) ConnectionDestroy() (err error) {
 	response, err := client.http.Delete("connection", nil)
@@ -208,6 +284,18 @@ func (client *Client) ConnectionStatistics() (statistics contract.ConnectionStat
 	return statistics, err
 }
 
+// ConnectionStatisticsHistory returns recent statistics samples of current connection
+func (client *Client) ConnectionStatisticsHistory() (history contract.ConnectionStatisticsHistoryDTO, err error) {
+	response, err := client.http.Get("connection/statistics/history", url.Values{})
+	if err != nil {
+		return history, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &history)
+	return history, err
+}
+
 // ConnectionStatus returns connection status
 func (client *Client) ConnectionStatus() (status contract.ConnectionStatusDTO, err error) {
 	response, err := client.http.Get("connection", url.Values{})
@@ -302,6 +390,18 @@ func (client *Client) ProposalsByPrice(lowerTime, upperTime, lowerGB, upperGB ui
 	return client.proposals(values)
 }
 
+// ProposalCostEstimate projects cost of using the proposal with the expected usage
+func (client *Client) ProposalCostEstimate(request contract.ProposalCostEstimateRequest) (cost contract.ProposalCostDTO, err error) {
+	response, err := client.http.Post("proposals/estimate", request)
+	if err != nil {
+		return cost, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &cost)
+	return cost, err
+}
+
 // Unlock allows using identity in following commands
 func (client *Client) Unlock(identity, passphrase string) error {
 	path := fmt.Sprintf("identities/%s/unlock", identity)
@@ -357,6 +457,22 @@ func (client *Client) Sessions() (sessions contract.ListSessionsResponse, err er
 	return sessions, err
 }
 
+// SearchSessions returns sessions from history matching every word of the given search text
+func (client *Client) SearchSessions(text string, page, pageSize int) (sessions contract.SearchSessionsResponse, err error) {
+	params := url.Values{}
+	params.Set("q", text)
+	params.Set("page", strconv.Itoa(page))
+	params.Set("page_size", strconv.Itoa(pageSize))
+	response, err := client.http.Get("sessions/search", params)
+	if err != nil {
+		return sessions, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &sessions)
+	return sessions, err
+}
+
 // SessionsByServiceType returns sessions from history filtered by type
 func (client *Client) SessionsByServiceType(serviceType string) (contract.ListSessionsResponse, error) {
 	sessions, err := client.Sessions()
@@ -419,6 +535,32 @@ func (client *Client) ServiceStop(id string) error {
 	return nil
 }
 
+// ServicePause stops accepting new sessions of the service, active sessions are closed if suspendSessions is set.
+func (client *Client) ServicePause(id string, suspendSessions bool) (service contract.ServiceInfoDTO, err error) {
+	path := fmt.Sprintf("services/%s/pause", id)
+	response, err := client.http.Put(path, contract.ServicePauseRequest{SuspendSessions: suspendSessions})
+	if err != nil {
+		return service, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &service)
+	return service, err
+}
+
+// ServiceResume starts accepting new sessions of the paused service.
+func (client *Client) ServiceResume(id string) (service contract.ServiceInfoDTO, err error) {
+	path := fmt.Sprintf("services/%s/resume", id)
+	response, err := client.http.Put(path, nil)
+	if err != nil {
+		return service, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &service)
+	return service, err
+}
+
 // NATStatus returns status of NAT traversal
 func (client *Client) NATStatus() (status contract.NATStatusDTO, err error) {
 	response, err := client.http.Get("nat/status", nil)
@@ -431,6 +573,164 @@ func (client *Client) NATStatus() (status contract.NATStatusDTO, err error) {
 	return status, err
 }
 
+// ProviderOverview returns aggregated provider dashboard information
+func (client *Client) ProviderOverview() (overview contract.ProviderOverviewDTO, err error) {
+	response, err := client.http.Get("provider/overview", nil)
+	if err != nil {
+		return overview, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &overview)
+	return overview, err
+}
+
+// ProviderConsumers returns a page of consumers served by provider
+func (client *Client) ProviderConsumers(page, pageSize int) (consumers contract.ProviderConsumersResponse, err error) {
+	params := url.Values{}
+	params.Set("page", strconv.Itoa(page))
+	params.Set("page_size", strconv.Itoa(pageSize))
+	response, err := client.http.Get("provider/consumers", params)
+	if err != nil {
+		return consumers, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &consumers)
+	return consumers, err
+}
+
+// EarningsSeries returns provider earnings rolled up by the given granularity, "hour" or "day"
+func (client *Client) EarningsSeries(granularity string) (series contract.EarningsSeriesDTO, err error) {
+	params := url.Values{}
+	params.Set("granularity", granularity)
+	response, err := client.http.Get("earnings/series", params)
+	if err != nil {
+		return series, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &series)
+	return series, err
+}
+
+// Backups returns node database backups
+func (client *Client) Backups() (backups contract.ListBackupsResponse, err error) {
+	response, err := client.http.Get("backups", nil)
+	if err != nil {
+		return backups, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &backups)
+	return backups, err
+}
+
+// BackupCreate takes a snapshot of node database
+func (client *Client) BackupCreate() (backup contract.BackupDTO, err error) {
+	response, err := client.http.Post("backups", struct{}{})
+	if err != nil {
+		return backup, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &backup)
+	return backup, err
+}
+
+// BackupRestore stages node database backup to be restored on the next node start
+func (client *Client) BackupRestore(name string) error {
+	path := fmt.Sprintf("backups/%s/restore", url.PathEscape(name))
+	response, err := client.http.Post(path, struct{}{})
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return nil
+}
+
+// PromiseJournal returns the state of the journal of promises accepted from consumers
+func (client *Client) PromiseJournal() (journal contract.PromiseJournalDTO, err error) {
+	response, err := client.http.Get("promises/journal", nil)
+	if err != nil {
+		return journal, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &journal)
+	return journal, err
+}
+
+// PromiseJournalRepair removes corrupted promise journal entries and reconciles the rest against the accountants
+func (client *Client) PromiseJournalRepair() (journal contract.PromiseJournalDTO, err error) {
+	response, err := client.http.Post("promises/journal/repair", struct{}{})
+	if err != nil {
+		return journal, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &journal)
+	return journal, err
+}
+
+// StateEvents subscribes to node state changes and sends every received state to the given channel.
+// It blocks until the stream is closed by the node or the stop channel is closed.
+func (client *Client) StateEvents(states chan<- StateDTO, stop <-chan struct{}) error {
+	response, err := client.http.Get("events/state", nil)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-stop:
+			response.Body.Close()
+		case <-done:
+		}
+	}()
+
+	scanner := bufio.NewScanner(response.Body)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		data := strings.TrimPrefix(scanner.Text(), "data: ")
+		if data == scanner.Text() {
+			continue
+		}
+
+		var event struct {
+			Type    string          `json:"type"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return errors.Wrap(err, "failed to parse state event")
+		}
+		if event.Type != stateChangeEvent {
+			continue
+		}
+
+		var state StateDTO
+		if err := json.Unmarshal(event.Payload, &state); err != nil {
+			return errors.Wrap(err, "failed to parse state event")
+		}
+		select {
+		case states <- state:
+		case <-stop:
+			return nil
+		}
+	}
+
+	select {
+	case <-stop:
+		return nil
+	default:
+		return scanner.Err()
+	}
+}
+
 // filterSessionsByType removes all sessions of irrelevant types
 func filterSessionsByType(serviceType string, sessions contract.ListSessionsResponse) contract.ListSessionsResponse {
 	matches := 0
@@ -517,6 +817,57 @@ func (client *Client) Beneficiary(address string) (res contract.IdentityBenefici
 	return res, err
 }
 
+// Withdraw settles earnings of the provided identity to its current beneficiary.
+func (client *Client) Withdraw(address, accountantID string) (res contract.WithdrawalDTO, err error) {
+	payload := contract.WithdrawRequest{
+		AccountantID: accountantID,
+	}
+	response, err := client.http.Post("identities/"+address+"/withdraw", payload)
+	if err != nil {
+		return contract.WithdrawalDTO{}, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// WithdrawalStatus returns the last withdrawal status of the provided identity.
+func (client *Client) WithdrawalStatus(address string) (res contract.WithdrawalDTO, err error) {
+	response, err := client.http.Get("identities/"+address+"/withdraw", nil)
+	if err != nil {
+		return contract.WithdrawalDTO{}, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// FaucetRequest requests test tokens from the test network faucet for the provided identity.
+func (client *Client) FaucetRequest(address string) (res contract.FaucetGrantDTO, err error) {
+	response, err := client.http.Post("identities/"+address+"/faucet", nil)
+	if err != nil {
+		return contract.FaucetGrantDTO{}, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
+// FaucetStatus returns the last faucet grant status of the provided identity.
+func (client *Client) FaucetStatus(address string) (res contract.FaucetGrantDTO, err error) {
+	response, err := client.http.Get("identities/"+address+"/faucet", nil)
+	if err != nil {
+		return contract.FaucetGrantDTO{}, err
+	}
+	defer response.Body.Close()
+
+	err = parseResponseJSON(response, &res)
+	return res, err
+}
+
 // SetMMNApiKey sets MMN's API key in config and registers node to MMN
 func (client *Client) SetMMNApiKey(data contract.MMNApiKeyRequest) error {
 	response, err := client.http.Post("mmn/api-key", data)
@@ -536,7 +887,7 @@ func (client *Client) SetMMNApiKey(data contract.MMNApiKeyRequest) error {
 	// TODO this should probably be wrapped and moved into the validation package
 	type validationResponse struct {
 		Message string                              `json:"message"`
-		Errors  map[string][]*validation.FieldError `json:"errors"`
+		Errors  map[string][]*validation.FieldError `json:"fields"`
 	}
 	res := validationResponse{}
 	err = parseResponseJSON(response, &res)
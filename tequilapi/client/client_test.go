@@ -30,6 +30,7 @@ import (
 
 const errorMessage = `
 {
+	"code" : "connection_failed",
 	"message" : "me haz faild"
 }
 `
@@ -65,6 +66,33 @@ func Test_NATStatus_ReturnsError(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func Test_StateEvents_SendsStates(t *testing.T) {
+	httpClient := mockHTTPClient(
+		t,
+		http.MethodGet,
+		"/events/state",
+		http.StatusOK,
+		`data: {"type":"state-change","payload":{"nat_status":{"status":"successful"},"sessions":[{"id":"s1"}]}}
+
+data: {"type":"nat","payload":{}}
+
+data: {"type":"state-change","payload":{"nat_status":{"status":"failure"}}}
+
+`,
+	)
+	client := Client{http: httpClient}
+
+	states := make(chan StateDTO, 2)
+	err := client.StateEvents(states, make(chan struct{}))
+	assert.NoError(t, err)
+
+	state := <-states
+	assert.Equal(t, "successful", state.NATStatus.Status)
+	assert.Equal(t, "s1", state.Sessions[0].ID)
+	state = <-states
+	assert.Equal(t, "failure", state.NATStatus.Status)
+}
+
 func TestConnectionErrorIsReturnedByClientInsteadOfDoubleParsing(t *testing.T) {
 	responseBody := &trackingCloser{
 		Reader: strings.NewReader(errorMessage),
@@ -85,6 +113,7 @@ func TestConnectionErrorIsReturnedByClientInsteadOfDoubleParsing(t *testing.T) {
 	_, err := client.ConnectionCreate("consumer", "provider", "accountant", "service", contract.ConnectOptions{})
 	assert.Error(t, err)
 	assert.EqualError(t, err, "server response invalid: Internal server error (http://test-api-whatever/connection). Possible error: me haz faild")
+	assert.Equal(t, contract.ErrCodeConnectionFailed, ErrorCode(err))
 	//when doing http request, response body should always be closed by client - otherwise persistent connections are leaking
 	assert.True(t, responseBody.Closed)
 }
@@ -17,6 +17,8 @@
 
 package client
 
+import "github.com/mysteriumnetwork/node/tequilapi/contract"
+
 // Fees represents the transactor fee
 type Fees struct {
 	Registration uint64 `json:"registration"`
@@ -41,3 +43,19 @@ type SettleWithBeneficiaryRequest struct {
 	SettleRequest
 	Beneficiary string `json:"beneficiary"`
 }
+
+// StateDTO represents the node state sent by the state events stream
+type StateDTO struct {
+	NATStatus     contract.NATStatusDTO     `json:"nat_status"`
+	BrokerStatus  contract.BrokerStatusDTO  `json:"broker_status"`
+	Services      []contract.ServiceInfoDTO `json:"service_info"`
+	Sessions      []contract.SessionDTO     `json:"sessions"`
+	SessionsStats contract.SessionStatsDTO  `json:"sessions_stats"`
+	Consumer      ConsumerStateDTO          `json:"consumer"`
+	Identities    []contract.IdentityDTO    `json:"identities"`
+}
+
+// ConsumerStateDTO represents the consumer part of the node state
+type ConsumerStateDTO struct {
+	Connection contract.ConnectionDTO `json:"connection"`
+}
@@ -31,6 +31,7 @@ import (
 	"github.com/rs/zerolog/log"
 
 	"github.com/mysteriumnetwork/node/requests"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 )
 
 type httpClientInterface interface {
@@ -119,23 +120,47 @@ func (client *httpClient) executeRequest(method, fullPath string, payloadJSON []
 	return response, nil
 }
 
-type errorBody struct {
-	Message string `json:"message"`
+// APIError is returned when tequilapi responds with an error, Code allows to branch on the kind of the error.
+type APIError struct {
+	StatusCode int
+	Status     string
+	URL        string
+	Code       contract.ErrorCode
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("server response invalid: %s (%s). Possible error: %s", e.Status, e.URL, e.Message)
+}
+
+// ErrorCode returns code of the tequilapi error, empty if the error did not come from tequilapi.
+func ErrorCode(err error) contract.ErrorCode {
+	if apiErr, ok := errors.Cause(err).(*APIError); ok {
+		return apiErr.Code
+	}
+	return ""
 }
 
 func parseResponseError(response *http.Response) error {
 	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		apiErr := &APIError{
+			StatusCode: response.StatusCode,
+			Status:     response.Status,
+			URL:        response.Request.URL.String(),
+		}
 		//sometimes we can get json message with single "message" field which represents error - try to get that
-		var parsedBody errorBody
-		var message string
+		var parsedBody contract.ErrorDTO
 		err := parseResponseJSON(response, &parsedBody)
 		if err != nil {
-			message = err.Error()
+			apiErr.Message = err.Error()
 		} else {
-			message = parsedBody.Message
+			apiErr.Code = parsedBody.Code
+			apiErr.Message = parsedBody.Message
+			if parsedBody.Detail != "" {
+				apiErr.Message = parsedBody.Detail
+			}
 		}
-		// TODO these errors are ugly long and hard to check against - consider return error structs or specific error constants
-		return errors.Errorf("server response invalid: %s (%s). Possible error: %s", response.Status, response.Request.URL, message)
+		return apiErr
 	}
 
 	return nil
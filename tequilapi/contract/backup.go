@@ -0,0 +1,62 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/storage/backup"
+)
+
+// NewBackupDTO maps to API database backup.
+func NewBackupDTO(b backup.Backup) BackupDTO {
+	return BackupDTO{
+		Name:      b.Name,
+		CreatedAt: b.CreatedAt.Format(time.RFC3339),
+		Size:      b.Size,
+	}
+}
+
+// NewListBackupsResponse maps to API database backups list.
+func NewListBackupsResponse(backups []backup.Backup) ListBackupsResponse {
+	result := ListBackupsResponse{Backups: []BackupDTO{}}
+	for _, b := range backups {
+		result.Backups = append(result.Backups, NewBackupDTO(b))
+	}
+	return result
+}
+
+// ListBackupsResponse defines database backups list representable as json.
+// swagger:model ListBackupsResponse
+type ListBackupsResponse struct {
+	Backups []BackupDTO `json:"backups"`
+}
+
+// BackupDTO represents a snapshot of the node database.
+// swagger:model BackupDTO
+type BackupDTO struct {
+	// example: myst-20200701T120000Z.db
+	Name string `json:"name"`
+
+	// example: 2020-07-01T12:00:00Z
+	CreatedAt string `json:"created_at"`
+
+	// size of the backup in bytes
+	// example: 65536
+	Size int64 `json:"size"`
+}
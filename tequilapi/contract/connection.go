@@ -18,10 +18,14 @@
 package contract
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/tequilapi/validation"
 	"github.com/mysteriumnetwork/payments/crypto"
 )
@@ -31,9 +35,15 @@ var emptyAddress = common.Address{}
 // NewConnectionStatusDTO maps to API connection status.
 func NewConnectionStatusDTO(session connection.Status) ConnectionStatusDTO {
 	response := ConnectionStatusDTO{
-		Status:     string(session.State),
-		ConsumerID: session.ConsumerID.Address,
-		SessionID:  string(session.SessionID),
+		ConnectionID: session.ConnectionID,
+		Status:       string(session.State),
+		ConsumerID:   session.ConsumerID.Address,
+		SessionID:    string(session.SessionID),
+
+		FailureCause: string(session.FailureCause),
+		Backend:      session.Backend,
+		RoutingTable: session.RoutingTable,
+		ProxyAddress: session.ProxyAddress,
 	}
 	if session.AccountantID != emptyAddress {
 		response.AccountantAddress = session.AccountantID.Hex()
@@ -49,6 +59,10 @@ func NewConnectionStatusDTO(session connection.Status) ConnectionStatusDTO {
 // ConnectionStatusDTO holds partial consumer connection details.
 // swagger:model ConnectionStatusDTO
 type ConnectionStatusDTO struct {
+	// connection among simultaneous ones, the main one is named "default"
+	// example: default
+	ConnectionID string `json:"connection_id,omitempty"`
+
 	// example: Connected
 	Status string `json:"status"`
 
@@ -63,6 +77,37 @@ type ConnectionStatusDTO struct {
 
 	// example: 4cfb0324-daf6-4ad8-448b-e61fe0a1f918
 	SessionID string `json:"session_id,omitempty"`
+
+	// cause of the last failed connect attempt
+	// example: nat_blocked
+	FailureCause string `json:"failure_cause,omitempty"`
+
+	// backend running the tunnel, omitted if the service type has no choice of them
+	// example: userspace
+	Backend string `json:"backend,omitempty"`
+
+	// routing table and firewall mark of named connection, omitted for the default one
+	// example: 7300
+	RoutingTable int `json:"routing_table,omitempty"`
+
+	// local SOCKS5/HTTP proxy routed through named connection, omitted for the default one
+	// example: 127.0.0.1:41080
+	ProxyAddress string `json:"proxy_address,omitempty"`
+}
+
+// NewConnectionStatusListDTO maps to API connection status list.
+func NewConnectionStatusListDTO(statuses []connection.Status) ConnectionStatusListDTO {
+	response := ConnectionStatusListDTO{Connections: make([]ConnectionStatusDTO, len(statuses))}
+	for i, status := range statuses {
+		response.Connections[i] = NewConnectionStatusDTO(status)
+	}
+	return response
+}
+
+// ConnectionStatusListDTO lists statuses of simultaneous connections.
+// swagger:model ConnectionStatusListDTO
+type ConnectionStatusListDTO struct {
+	Connections []ConnectionStatusDTO `json:"connections"`
 }
 
 // NewConnectionDTO maps to API connection.
@@ -92,7 +137,9 @@ func NewConnectionStatisticsDTO(session connection.Status, statistics connection
 		BytesReceived:      statistics.BytesReceived,
 		ThroughputSent:     datasize.BitSize(throughput.Up).Bits(),
 		ThroughputReceived: datasize.BitSize(throughput.Down).Bits(),
-		TokensSpent:        invoice.AgreementTotal,
+		TokensSpent:        NewTokensDTO(money.NewTokens(invoice.AgreementTotal)),
+		DNSQueries:         statistics.DNSQueries,
+		DNSBlocked:         statistics.DNSBlocked,
 	}
 }
 
@@ -117,8 +164,116 @@ type ConnectionStatisticsDTO struct {
 	// example: 60
 	Duration int `json:"duration"`
 
-	// example: 500000
-	TokensSpent uint64 `json:"tokens_spent"`
+	TokensSpent TokensDTO `json:"tokens_spent"`
+
+	// DNS queries served by local DNS proxy, if ad blocking is enabled
+	// example: 120
+	DNSQueries uint64 `json:"dns_queries,omitempty"`
+
+	// DNS queries of ad and tracker domains blocked by local DNS proxy
+	// example: 30
+	DNSBlocked uint64 `json:"dns_blocked,omitempty"`
+}
+
+// NewConnectionStatisticsHistoryDTO maps connection statistics samples to API, throughput is calculated between adjacent samples.
+func NewConnectionStatisticsHistoryDTO(samples []connection.Statistics) ConnectionStatisticsHistoryDTO {
+	dto := ConnectionStatisticsHistoryDTO{
+		Samples: make([]ConnectionStatisticsSampleDTO, len(samples)),
+	}
+	for i, stats := range samples {
+		sample := ConnectionStatisticsSampleDTO{
+			At:            stats.At.UTC().Format(time.RFC3339),
+			BytesSent:     stats.BytesSent,
+			BytesReceived: stats.BytesReceived,
+		}
+		if i > 0 {
+			previous := samples[i-1]
+			if seconds := stats.At.Sub(previous.At).Seconds(); seconds > 0 {
+				diff := previous.Diff(stats)
+				sample.ThroughputSent = uint64(float64(datasize.FromBytes(diff.BytesSent).Bits()) / seconds)
+				sample.ThroughputReceived = uint64(float64(datasize.FromBytes(diff.BytesReceived).Bits()) / seconds)
+			}
+		}
+		dto.Samples[i] = sample
+	}
+	return dto
+}
+
+// ConnectionStatisticsHistoryDTO holds recent consumer connection statistics samples ordered from the oldest.
+// swagger:model ConnectionStatisticsHistoryDTO
+type ConnectionStatisticsHistoryDTO struct {
+	Samples []ConnectionStatisticsSampleDTO `json:"samples"`
+}
+
+// ConnectionStatisticsSampleDTO holds a single consumer connection statistics sample.
+// swagger:model ConnectionStatisticsSampleDTO
+type ConnectionStatisticsSampleDTO struct {
+	// example: 2020-06-25T13:01:21Z
+	At string `json:"at"`
+
+	// example: 1024
+	BytesSent uint64 `json:"bytes_sent"`
+
+	// example: 1024
+	BytesReceived uint64 `json:"bytes_received"`
+
+	// Upload speed in bits per second since the previous sample
+	// example: 1024
+	ThroughputSent uint64 `json:"throughput_sent"`
+
+	// Download speed in bits per second since the previous sample
+	// example: 1024
+	ThroughputReceived uint64 `json:"throughput_received"`
+}
+
+// ConnectionSpeedTestRequest request used to measure the speed of current connection.
+// swagger:model ConnectionSpeedTestRequestDTO
+type ConnectionSpeedTestRequest struct {
+	// measure speed to public speed test servers or to provider itself, the latter must be allowed by provider
+	// required: false
+	// default: public
+	// example: public
+	Target string `json:"target"`
+}
+
+// Validate validates fields in request
+func (sr ConnectionSpeedTestRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	switch connection.SpeedTestTarget(sr.Target) {
+	case connection.SpeedTestTargetPublic, connection.SpeedTestTargetProvider:
+	default:
+		errs.ForField("target").AddError("invalid", "Unknown speed test target")
+	}
+	return errs
+}
+
+// NewConnectionSpeedTestDTO maps to API connection speed test result.
+func NewConnectionSpeedTestDTO(result connection.SpeedTestResult) ConnectionSpeedTestDTO {
+	return ConnectionSpeedTestDTO{
+		Target:   string(result.Target),
+		Latency:  uint64(result.Latency / time.Millisecond),
+		Download: uint64(result.Download),
+		Upload:   uint64(result.Upload),
+	}
+}
+
+// ConnectionSpeedTestDTO holds the measured speed of current connection.
+// swagger:model ConnectionSpeedTestDTO
+type ConnectionSpeedTestDTO struct {
+	// example: public
+	Target string `json:"target"`
+
+	// round trip time in milliseconds
+	// example: 20
+	Latency uint64 `json:"latency"`
+
+	// download speed in bits per second
+	// example: 104857600
+	Download uint64 `json:"download"`
+
+	// upload speed in bits per second
+	// example: 10485760
+	Upload uint64 `json:"upload"`
 }
 
 // ConnectionCreateRequest request used to start a connection.
@@ -134,8 +289,8 @@ type ConnectionCreateRequest struct {
 	// example: 0x0000000000000000000000000000000000000002
 	ProviderID string `json:"provider_id"`
 
-	// accountant identity
-	// required: true
+	// accountant identity, a healthy one is picked when not given
+	// required: false
 	// example: 0x0000000000000000000000000000000000000003
 	AccountantID string `json:"accountant_id"`
 
@@ -145,6 +300,11 @@ type ConnectionCreateRequest struct {
 	// example: openvpn
 	ServiceType string `json:"service_type"`
 
+	// per provider unique serial number of the proposal, required to choose among several services of the same type
+	// required: false
+	// example: 0
+	ProposalID int `json:"proposal_id,omitempty"`
+
 	// connect options
 	// required: false
 	ConnectOptions ConnectOptions `json:"connect_options,omitempty"`
@@ -159,8 +319,81 @@ func (cr ConnectionCreateRequest) Validate() *validation.FieldErrorMap {
 	if len(cr.ProviderID) == 0 {
 		errs.ForField("provider_id").AddError("required", "Field is required")
 	}
-	if len(cr.AccountantID) == 0 {
-		errs.ForField("accountant_id").AddError("required", "Field is required")
+	return errs
+}
+
+// ConnectionInviteRequest request used to start a connection to the unlisted provider using its invite code.
+// swagger:model ConnectionInviteRequestDTO
+type ConnectionInviteRequest struct {
+	// consumer identity
+	// required: true
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// accountant identity, a healthy one is picked when not given
+	// required: false
+	// example: 0x0000000000000000000000000000000000000003
+	AccountantID string `json:"accountant_id"`
+
+	// invite code shared by the provider
+	// required: true
+	// example: myst-invite:3ZJNb9owEIbv_SvQnPdgQoQ2pxp...
+	InviteCode string `json:"invite_code"`
+
+	// connect options
+	// required: false
+	ConnectOptions ConnectOptions `json:"connect_options,omitempty"`
+}
+
+// Validate validates fields in request
+func (ir ConnectionInviteRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if len(ir.ConsumerID) == 0 {
+		errs.ForField("consumer_id").AddError("required", "Field is required")
+	}
+	if len(ir.InviteCode) == 0 {
+		errs.ForField("invite_code").AddError("required", "Field is required")
+	}
+	return errs
+}
+
+// ConnectionCountryRequest request used to start a connection to the best available provider of the country.
+// swagger:model ConnectionCountryRequestDTO
+type ConnectionCountryRequest struct {
+	// consumer identity
+	// required: true
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// accountant identity, a healthy one is picked when not given
+	// required: false
+	// example: 0x0000000000000000000000000000000000000003
+	AccountantID string `json:"accountant_id"`
+
+	// country code of the provider
+	// required: true
+	// example: LT
+	Country string `json:"country"`
+
+	// service type. Possible values are "openvpn", "wireguard" and "noop"
+	// required: false
+	// default: openvpn
+	// example: openvpn
+	ServiceType string `json:"service_type"`
+
+	// connect options
+	// required: false
+	ConnectOptions ConnectOptions `json:"connect_options,omitempty"`
+}
+
+// Validate validates fields in request
+func (cr ConnectionCountryRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if len(cr.ConsumerID) == 0 {
+		errs.ForField("consumer_id").AddError("required", "Field is required")
+	}
+	if len(cr.Country) == 0 {
+		errs.ForField("country").AddError("required", "Field is required")
 	}
 	return errs
 }
@@ -177,4 +410,129 @@ type ConnectOptions struct {
 	// default: auto
 	// example: auto, provider, system, "1.1.1.1,8.8.8.8"
 	DNS connection.DNSOption `json:"dns"`
+	// disconnect after the given number of seconds, no limit if 0
+	// required: false
+	// example: 3600
+	MaxDuration uint64 `json:"max_duration,omitempty"`
+	// disconnect once the total paid for the session reaches the given amount, no limit if 0
+	// required: false
+	// example: 500000000000000000
+	MaxCost uint64 `json:"max_cost,omitempty"`
+	// disconnect once the given number of bytes is transferred in both directions, no limit if 0
+	// required: false
+	// example: 10485760
+	MaxTraffic uint64 `json:"max_traffic,omitempty"`
+	// block ad and tracker domains in DNS queries of the tunnel using local DNS proxy
+	// required: false
+	// example: true
+	AdBlock bool `json:"ad_block,omitempty"`
+	// account tunneled traffic per destination category locally, see /connection/traffic-breakdown
+	// required: false
+	// example: true
+	TrafficBreakdown bool `json:"traffic_breakdown,omitempty"`
+}
+
+// ConnectionPreflightRequest request used to check whether connection to a proposal is likely to succeed.
+// swagger:model ConnectionPreflightRequestDTO
+type ConnectionPreflightRequest struct {
+	// consumer identity
+	// required: true
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// provider identity
+	// required: true
+	// example: 0x0000000000000000000000000000000000000002
+	ProviderID string `json:"provider_id"`
+
+	// service type. Possible values are "openvpn", "wireguard" and "noop"
+	// required: false
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// per provider unique serial number of the proposal, required to choose among several services of the same type
+	// required: false
+	// example: 0
+	ProposalID int `json:"proposal_id,omitempty"`
+
+	// consumer NAT type, NAT compatibility is not checked when it is empty
+	// required: false
+	// example: prcone
+	NATType string `json:"nat_type,omitempty"`
+}
+
+// Validate validates fields in request
+func (pr ConnectionPreflightRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if len(pr.ConsumerID) == 0 {
+		errs.ForField("consumer_id").AddError("required", "Field is required")
+	}
+	if len(pr.ProviderID) == 0 {
+		errs.ForField("provider_id").AddError("required", "Field is required")
+	}
+	return errs
+}
+
+// NewConnectionPreflightDTO maps to API connection pre-flight check results.
+func NewConnectionPreflightDTO(results []connection.PreflightResult) ConnectionPreflightDTO {
+	response := ConnectionPreflightDTO{
+		Passed: true,
+		Checks: make([]PreflightCheckDTO, len(results)),
+	}
+	for i, result := range results {
+		response.Checks[i] = PreflightCheckDTO{
+			Check:  string(result.Check),
+			Passed: result.Passed,
+			Reason: result.Reason,
+		}
+		response.Passed = response.Passed && result.Passed
+	}
+	return response
+}
+
+// ConnectionPreflightDTO holds results of connection pre-flight checks.
+// swagger:model ConnectionPreflightDTO
+type ConnectionPreflightDTO struct {
+	// true when all checks have passed
+	// example: false
+	Passed bool `json:"passed"`
+
+	Checks []PreflightCheckDTO `json:"checks"`
+}
+
+// PreflightCheckDTO holds result of a single pre-flight check.
+// swagger:model PreflightCheckDTO
+type PreflightCheckDTO struct {
+	// check name. Possible values are "identity_unlocked", "registration", "balance", "service_type", "nat_compatibility" and "provider_reachable"
+	// example: balance
+	Check string `json:"check"`
+
+	// example: false
+	Passed bool `json:"passed"`
+
+	// explanation of the failed check
+	// example: balance 99 is lower than proposal price 100
+	Reason string `json:"reason,omitempty"`
+}
+
+// NewConnectionErrorDTO maps to API connect error.
+func NewConnectionErrorDTO(err error) ConnectionErrorDTO {
+	dto := ConnectionErrorDTO{
+		ErrorDTO: NewErrorDTO(err, http.StatusInternalServerError),
+		Cause:    string(connection.FailureCauseOf(err)),
+	}
+	if dto.Code == ErrCodeInternal {
+		dto.Code = ErrCodeConnectionFailed
+	}
+	return dto
+}
+
+// ConnectionErrorDTO holds error of the failed connect attempt.
+// swagger:model ConnectionErrorDTO
+type ConnectionErrorDTO struct {
+	ErrorDTO
+
+	// failure cause found by diagnostics. Possible values are "unknown", "broker_unreachable", "provider_unreachable", "nat_blocked", "payment_rejected" and "handshake_timeout"
+	// example: nat_blocked
+	Cause string `json:"cause,omitempty"`
 }
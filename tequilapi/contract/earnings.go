@@ -0,0 +1,59 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/session/pingpong"
+)
+
+// NewEarningsSeriesDTO maps to API earnings series.
+func NewEarningsSeriesDTO(granularity pingpong.EarningsGranularity, series []pingpong.EarningsPoint) EarningsSeriesDTO {
+	points := make([]EarningsPointDTO, len(series))
+	for i, p := range series {
+		points[i] = EarningsPointDTO{
+			Time:   p.Time,
+			Tokens: NewTokensDTO(p.Tokens),
+		}
+	}
+	return EarningsSeriesDTO{
+		Granularity: string(granularity),
+		Points:      points,
+	}
+}
+
+// EarningsSeriesDTO represents provider earnings rolled up by hours or days.
+// swagger:model EarningsSeriesDTO
+type EarningsSeriesDTO struct {
+	// period of a single point. Possible values are "hour" and "day"
+	// example: day
+	Granularity string `json:"granularity"`
+
+	Points []EarningsPointDTO `json:"points"`
+}
+
+// EarningsPointDTO represents tokens earned during a single period.
+// swagger:model EarningsPointDTO
+type EarningsPointDTO struct {
+	// start of the period (UTC)
+	// example: 2020-06-17T00:00:00Z
+	Time time.Time `json:"time"`
+
+	Tokens TokensDTO `json:"tokens"`
+}
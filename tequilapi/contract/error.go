@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	stdErr "errors"
+	"net/http"
+
+	"github.com/pkg/errors"
+
+	"github.com/mysteriumnetwork/node/consumer/gateway"
+	"github.com/mysteriumnetwork/node/consumer/isolation"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/storage/backup"
+	"github.com/mysteriumnetwork/node/faucet"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+	"github.com/mysteriumnetwork/node/withdrawal"
+)
+
+// ErrorCode is a stable machine readable code of the error, clients should branch on it instead of the message.
+type ErrorCode string
+
+// Generic error codes, used when the error is not found in the catalog.
+const (
+	ErrCodeBadRequest         = ErrorCode("bad_request")
+	ErrCodeUnauthorized       = ErrorCode("unauthorized")
+	ErrCodeForbidden          = ErrorCode("forbidden")
+	ErrCodeNotFound           = ErrorCode("not_found")
+	ErrCodeConflict           = ErrorCode("conflict")
+	ErrCodePreconditionFailed = ErrorCode("precondition_failed")
+	ErrCodeValidationFailed   = ErrorCode("validation_failed")
+	ErrCodeRateLimited        = ErrorCode("rate_limited")
+	ErrCodeInternal           = ErrorCode("internal")
+	ErrCodeNotImplemented     = ErrorCode("not_implemented")
+	ErrCodeServiceUnavailable = ErrorCode("service_unavailable")
+	ErrCodeGatewayTimeout     = ErrorCode("gateway_timeout")
+	ErrCodeUnknown            = ErrorCode("unknown")
+)
+
+// Error codes of the errors known to the catalog.
+const (
+	ErrCodeConnectionExists         = ErrorCode("connection_exists")
+	ErrCodeConnectionNotFound       = ErrorCode("connection_not_found")
+	ErrCodeConnectionCancelled      = ErrorCode("connection_cancelled")
+	ErrCodeConnectionFailed         = ErrorCode("connection_failed")
+	ErrCodeConnectionPolicyDenied   = ErrorCode("connection_policy_denied")
+	ErrCodeConnectionAttemptTimeout = ErrorCode("connection_attempt_timeout")
+	ErrCodeUnsupportedServiceType   = ErrorCode("unsupported_service_type")
+	ErrCodeInsufficientBalance      = ErrorCode("insufficient_balance")
+	ErrCodeIdentityLocked           = ErrorCode("identity_locked")
+	ErrCodeProposalsNotFound        = ErrorCode("proposals_not_found")
+	ErrCodeProposalNotFound         = ErrorCode("proposal_not_found")
+	ErrCodeSessionNotFound          = ErrorCode("session_not_found")
+	ErrCodeSpeedTestNotAllowed      = ErrorCode("speed_test_not_allowed")
+	ErrCodeServiceNotFound          = ErrorCode("service_not_found")
+	ErrCodeServiceAlreadyPaused     = ErrorCode("service_already_paused")
+	ErrCodeServiceNotPaused         = ErrorCode("service_not_paused")
+	ErrCodeServiceLocationFailed    = ErrorCode("service_location_failed")
+	ErrCodeProviderDraining         = ErrorCode("provider_draining")
+	ErrCodeBackupNotFound           = ErrorCode("backup_not_found")
+	ErrCodeInvalidBackupName        = ErrorCode("invalid_backup_name")
+	ErrCodeFaucetGrantInProgress    = ErrorCode("faucet_grant_in_progress")
+	ErrCodeFaucetAlreadyGranted     = ErrorCode("faucet_already_granted")
+	ErrCodeFaucetGrantNotFound      = ErrorCode("faucet_grant_not_found")
+	ErrCodeNoUpdate                 = ErrorCode("no_update")
+	ErrCodeUpdateInProgress         = ErrorCode("update_in_progress")
+	ErrCodeWithdrawalInProgress     = ErrorCode("withdrawal_in_progress")
+	ErrCodeGatewayNotSupported      = ErrorCode("gateway_not_supported")
+	ErrCodeIsolationNotSupported    = ErrorCode("isolation_not_supported")
+	ErrCodeIsolationLimitReached    = ErrorCode("isolation_limit_reached")
+	ErrCodeInvalidConnectionID      = ErrorCode("invalid_connection_id")
+)
+
+// catalogEntry maps internal error to its code.
+type catalogEntry struct {
+	err  error
+	code ErrorCode
+}
+
+// errorCatalog maps internal errors to their codes.
+var errorCatalog = []catalogEntry{
+	{connection.ErrAlreadyExists, ErrCodeConnectionExists},
+	{connection.ErrNoConnection, ErrCodeConnectionNotFound},
+	{connection.ErrConnectionCancelled, ErrCodeConnectionCancelled},
+	{connection.ErrConnectionFailed, ErrCodeConnectionFailed},
+	{connection.ErrSessionPolicyDenied, ErrCodeConnectionPolicyDenied},
+	{connection.ErrAttemptTimeout, ErrCodeConnectionAttemptTimeout},
+	{connection.ErrUnsupportedServiceType, ErrCodeUnsupportedServiceType},
+	{connection.ErrInsufficientBalance, ErrCodeInsufficientBalance},
+	{connection.ErrUnlockRequired, ErrCodeIdentityLocked},
+	{connection.ErrNoProposals, ErrCodeProposalsNotFound},
+	{connection.ErrSpeedTestNotAllowed, ErrCodeSpeedTestNotAllowed},
+	{backup.ErrBackupNotFound, ErrCodeBackupNotFound},
+	{backup.ErrInvalidBackupName, ErrCodeInvalidBackupName},
+	{faucet.ErrGrantInProgress, ErrCodeFaucetGrantInProgress},
+	{faucet.ErrAlreadyGranted, ErrCodeFaucetAlreadyGranted},
+	{faucet.ErrGrantNotFound, ErrCodeFaucetGrantNotFound},
+	{withdrawal.ErrWithdrawalInProgress, ErrCodeWithdrawalInProgress},
+	{gateway.ErrNotSupported, ErrCodeGatewayNotSupported},
+	{isolation.ErrNotSupported, ErrCodeIsolationNotSupported},
+	{isolation.ErrTooManyConnections, ErrCodeIsolationLimitReached},
+	{isolation.ErrInvalidConnectionID, ErrCodeInvalidConnectionID},
+}
+
+// statusErrorCodes maps HTTP status of the response to generic error code.
+var statusErrorCodes = map[int]ErrorCode{
+	http.StatusBadRequest:          ErrCodeBadRequest,
+	http.StatusUnauthorized:        ErrCodeUnauthorized,
+	http.StatusForbidden:           ErrCodeForbidden,
+	http.StatusNotFound:            ErrCodeNotFound,
+	http.StatusConflict:            ErrCodeConflict,
+	http.StatusExpectationFailed:   ErrCodePreconditionFailed,
+	http.StatusUnprocessableEntity: ErrCodeValidationFailed,
+	http.StatusTooManyRequests:     ErrCodeRateLimited,
+	499:                            ErrCodeConnectionCancelled,
+	http.StatusInternalServerError: ErrCodeInternal,
+	http.StatusNotImplemented:      ErrCodeNotImplemented,
+	http.StatusServiceUnavailable:  ErrCodeServiceUnavailable,
+	http.StatusGatewayTimeout:      ErrCodeGatewayTimeout,
+}
+
+// ErrorDTO is the body of all tequilapi error responses.
+// swagger:model ErrorDTO
+type ErrorDTO struct {
+	// machine readable error code
+	// example: connection_exists
+	Code ErrorCode `json:"code"`
+
+	// human readable error message
+	// example: connection already exists
+	Message string `json:"message"`
+
+	// full error with the context, set when it differs from the message
+	// example: could not connect: connection already exists
+	Detail string `json:"detail,omitempty"`
+
+	// validation errors of the request fields
+	Fields *validation.FieldErrorMap `json:"fields,omitempty"`
+}
+
+// NewErrorDTO maps error to API error. Errors found in the catalog get their own code and message,
+// the rest get generic code of the HTTP status.
+func NewErrorDTO(err error, httpCode int) ErrorDTO {
+	for _, known := range errorCatalog {
+		if stdErr.Is(err, known.err) || errors.Cause(err) == known.err {
+			dto := ErrorDTO{Code: known.code, Message: known.err.Error()}
+			if err.Error() != dto.Message {
+				dto.Detail = err.Error()
+			}
+			return dto
+		}
+	}
+	return NewErrorMessageDTO(err.Error(), httpCode)
+}
+
+// NewErrorMessageDTO maps error message to API error with generic code of the HTTP status.
+func NewErrorMessageDTO(message string, httpCode int) ErrorDTO {
+	code, ok := statusErrorCodes[httpCode]
+	if !ok {
+		code = ErrCodeUnknown
+	}
+	return ErrorDTO{Code: code, Message: message}
+}
+
+// NewValidationErrorDTO maps request validation errors to API error.
+func NewValidationErrorDTO(fields *validation.FieldErrorMap) ErrorDTO {
+	return ErrorDTO{Code: ErrCodeValidationFailed, Message: "validation_error", Fields: fields}
+}
@@ -0,0 +1,40 @@
+// +build !mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/updater"
+)
+
+// Provider services and updater are left out of the slim mobile build, so are their errors.
+func init() {
+	errorCatalog = append(errorCatalog, []catalogEntry{
+		{service.ErrorInvalidProposal, ErrCodeProposalNotFound},
+		{service.ErrorSessionNotExists, ErrCodeSessionNotFound},
+		{service.ErrorLocation, ErrCodeServiceLocationFailed},
+		{service.ErrNoSuchInstance, ErrCodeServiceNotFound},
+		{service.ErrAlreadyPaused, ErrCodeServiceAlreadyPaused},
+		{service.ErrNotPaused, ErrCodeServiceNotPaused},
+		{service.ErrDraining, ErrCodeProviderDraining},
+		{updater.ErrNoUpdate, ErrCodeNoUpdate},
+		{updater.ErrUpdateInProgress, ErrCodeUpdateInProgress},
+	}...)
+}
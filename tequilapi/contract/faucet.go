@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/faucet"
+)
+
+// FaucetGrantDTO describes test tokens requested from the faucet for an identity.
+// swagger:model FaucetGrantDTO
+type FaucetGrantDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	Identity string `json:"identity"`
+	// channel receiving test MYST
+	// example: 0x0000000000000000000000000000000000000002
+	Channel string `json:"channel"`
+	// example: pending
+	Status string `json:"status"`
+	// example: 0x88af51047ff2da1e3626722fe239f70c3ddd668f067b2ac8d67b280d2eff39f7
+	TxHash string `json:"tx_hash,omitempty"`
+	// granted test MYST in wei
+	// example: 10000000000000000000
+	MYST string `json:"myst,omitempty"`
+	// granted test ETH in wei
+	// example: 100000000000000000
+	ETH   string `json:"eth,omitempty"`
+	Error string `json:"error,omitempty"`
+	// example: 2020-09-01T10:00:00Z
+	RequestedAt string `json:"requested_at"`
+	// example: 2020-09-01T10:01:00Z
+	UpdatedAt string `json:"updated_at"`
+}
+
+// NewFaucetGrantDTO maps faucet grant to DTO.
+func NewFaucetGrantDTO(g faucet.Grant) FaucetGrantDTO {
+	dto := FaucetGrantDTO{
+		Identity:    g.Identity.Address,
+		Channel:     g.Channel.Hex(),
+		Status:      string(g.Status),
+		TxHash:      g.TxHash,
+		Error:       g.Error,
+		RequestedAt: g.RequestedAt.Format(time.RFC3339),
+		UpdatedAt:   g.UpdatedAt.Format(time.RFC3339),
+	}
+	if g.MYST != nil {
+		dto.MYST = g.MYST.String()
+	}
+	if g.ETH != nil {
+		dto.ETH = g.ETH.String()
+	}
+	return dto
+}
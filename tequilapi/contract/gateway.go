@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"net"
+
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// GatewayDTO describes forwarding of LAN traffic through the active session.
+// swagger:model GatewayDTO
+type GatewayDTO struct {
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// network interface LAN clients are connected to
+	// example: eth0
+	Interface string `json:"interface"`
+
+	// LAN network forwarded through the session
+	// example: 192.168.8.0/24
+	Subnet string `json:"subnet"`
+
+	// whether LAN traffic is being forwarded through the active session right now
+	// example: true
+	Forwarding bool `json:"forwarding"`
+}
+
+// GatewayRequest request used to configure gateway mode.
+// swagger:model GatewayRequestDTO
+type GatewayRequest struct {
+	// required: true
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// network interface LAN clients are connected to, required when gateway is enabled
+	// example: eth0
+	Interface string `json:"interface"`
+
+	// LAN network in CIDR notation, required when gateway is enabled
+	// example: 192.168.8.0/24
+	Subnet string `json:"subnet"`
+}
+
+// Validate validates fields in request
+func (r GatewayRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if !r.Enabled {
+		return errs
+	}
+	if r.Interface == "" {
+		errs.ForField("interface").AddError("required", "Field is required")
+	}
+	if r.Subnet == "" {
+		errs.ForField("subnet").AddError("required", "Field is required")
+	} else if ip, _, err := net.ParseCIDR(r.Subnet); err != nil || ip.To4() == nil {
+		errs.ForField("subnet").AddError("invalid", "Subnet must be IPv4 network in CIDR notation")
+	}
+	return errs
+}
@@ -17,6 +17,12 @@
 
 package contract
 
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/blockchain"
+)
+
 // HealthCheckDTO holds API healthcheck.
 // swagger:model HealthCheckDTO
 type HealthCheckDTO struct {
@@ -43,3 +49,55 @@ type BuildInfoDTO struct {
 	// example: dev-build
 	BuildNumber string `json:"build_number"`
 }
+
+// HealthCheckDetailedDTO holds API healthcheck along with health of node dependencies.
+// swagger:model HealthCheckDetailedDTO
+type HealthCheckDetailedDTO struct {
+	HealthCheckDTO
+
+	// ethereum RPC endpoints
+	RPC []RPCEndpointDTO `json:"rpc"`
+}
+
+// NewRPCEndpointsDTO maps to API ethereum RPC endpoints health.
+func NewRPCEndpointsDTO(status []blockchain.EndpointStatus) []RPCEndpointDTO {
+	result := []RPCEndpointDTO{}
+	for _, s := range status {
+		dto := RPCEndpointDTO{
+			URL:       s.URL,
+			Active:    s.Active,
+			Healthy:   s.Healthy,
+			LatencyMs: s.Latency.Milliseconds(),
+			Error:     s.Error,
+		}
+		if !s.CheckedAt.IsZero() {
+			dto.CheckedAt = s.CheckedAt.UTC().Format(time.RFC3339)
+		}
+		result = append(result, dto)
+	}
+	return result
+}
+
+// RPCEndpointDTO holds health of an ethereum RPC endpoint.
+// swagger:model RPCEndpointDTO
+type RPCEndpointDTO struct {
+	// example: https://goerli.infura.io/v3/key
+	URL string `json:"url"`
+
+	// whether the endpoint is currently used
+	// example: true
+	Active bool `json:"active"`
+
+	// example: true
+	Healthy bool `json:"healthy"`
+
+	// latency of the last check in milliseconds
+	// example: 120
+	LatencyMs int64 `json:"latency_ms"`
+
+	// error of the last check
+	Error string `json:"error,omitempty"`
+
+	// example: 2020-07-01T12:00:00Z
+	CheckedAt string `json:"checked_at,omitempty"`
+}
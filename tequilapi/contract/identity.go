@@ -37,12 +37,12 @@ type IdentityDTO struct {
 	// identity in Ethereum address format
 	// required: true
 	// example: 0x0000000000000000000000000000000000000001
-	Address            string `json:"id"`
-	RegistrationStatus string `json:"registration_status"`
-	ChannelAddress     string `json:"channel_address"`
-	Balance            uint64 `json:"balance"`
-	Earnings           uint64 `json:"earnings"`
-	EarningsTotal      uint64 `json:"earnings_total"`
+	Address            string    `json:"id"`
+	RegistrationStatus string    `json:"registration_status"`
+	ChannelAddress     string    `json:"channel_address"`
+	Balance            TokensDTO `json:"balance"`
+	Earnings           TokensDTO `json:"earnings"`
+	EarningsTotal      TokensDTO `json:"earnings_total"`
 }
 
 // NewIdentityDTO maps to API identity.
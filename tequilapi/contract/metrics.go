@@ -0,0 +1,90 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+// RequestMetricsDTO holds metrics of tequilapi requests grouped by endpoint.
+// swagger:model RequestMetricsDTO
+type RequestMetricsDTO struct {
+	Endpoints []EndpointMetricsDTO `json:"endpoints"`
+}
+
+// EndpointMetricsDTO holds request count, errors and latency histogram of a single endpoint.
+// swagger:model EndpointMetricsDTO
+type EndpointMetricsDTO struct {
+	// example: GET
+	Method string `json:"method"`
+
+	// route of the endpoint, path parameters are replaced by their names
+	// example: /identities/:id
+	Route string `json:"route"`
+
+	// example: 120
+	Count uint64 `json:"count"`
+
+	// requests answered with 4xx status
+	// example: 2
+	ClientErrors uint64 `json:"client_errors"`
+
+	// requests answered with 5xx status
+	// example: 1
+	ServerErrors uint64 `json:"server_errors"`
+
+	// average latency in milliseconds
+	// example: 12
+	LatencyAvg uint64 `json:"latency_avg"`
+
+	// maximum latency in milliseconds
+	// example: 250
+	LatencyMax uint64 `json:"latency_max"`
+
+	// number of requests by the upper bound of their latency
+	// example: {"10ms": 100, "100ms": 18, "1s": 2, "+Inf": 0}
+	Latency map[string]uint64 `json:"latency"`
+}
+
+// EventMetricsDTO holds delivery metrics of every event bus subscriber.
+// swagger:model EventMetricsDTO
+type EventMetricsDTO struct {
+	Subscribers []EventSubscriberMetricsDTO `json:"subscribers"`
+}
+
+// EventSubscriberMetricsDTO holds delivery metrics of a single event bus subscriber.
+// swagger:model EventSubscriberMetricsDTO
+type EventSubscriberMetricsDTO struct {
+	// topic or wildcard pattern of the subscription
+	// example: State change
+	Topic string `json:"topic"`
+
+	// example: github.com/mysteriumnetwork/node/core/state.(*Keeper).consumeConnectionStateEvent-fm
+	Handler string `json:"handler"`
+
+	// example: 120
+	Delivered uint64 `json:"delivered"`
+
+	// events lost because of a full buffer or an event type not accepted by the handler
+	// example: 2
+	Dropped uint64 `json:"dropped"`
+
+	// queued events replaced by newer ones
+	// example: 0
+	Coalesced uint64 `json:"coalesced"`
+
+	// events waiting for delivery
+	// example: 0
+	Queued int `json:"queued"`
+}
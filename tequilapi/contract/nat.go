@@ -20,6 +20,28 @@ package contract
 // NATStatusDTO gives information about NAT traversal success or failure
 // swagger:model NATStatusDTO
 type NATStatusDTO struct {
+	Status       string           `json:"status"`
+	Error        string           `json:"error"`
+	PortMappings []PortMappingDTO `json:"port_mappings,omitempty"`
+}
+
+// PortMappingDTO gives information about port mapping lease health
+// swagger:model PortMappingDTO
+type PortMappingDTO struct {
+	// example: UDP
+	Protocol string `json:"protocol"`
+	// example: 51820
+	Port int `json:"port"`
+	// example: active
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// BrokerStatusDTO gives information about connection to the message broker
+// swagger:model BrokerStatusDTO
+type BrokerStatusDTO struct {
+	// example: connected
 	Status string `json:"status"`
-	Error  string `json:"error"`
+	// example: nats://testnet-broker.mysterium.network:4222
+	Server string `json:"server,omitempty"`
 }
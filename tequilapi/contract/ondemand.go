@@ -0,0 +1,145 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/ondemand"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// NewOnDemandDTO maps to API on-demand mode.
+func NewOnDemandDTO(status ondemand.Status) OnDemandDTO {
+	dto := OnDemandDTO{
+		Enabled:      status.Enabled,
+		ConsumerID:   status.ConsumerID,
+		Country:      status.Country,
+		ServiceType:  status.ServiceType,
+		Destinations: status.Destinations,
+		Interface:    status.Interface,
+		IdleTimeout:  uint64(status.IdleTimeout.Seconds()),
+		Connected:    status.Connected,
+		LastError:    status.LastError,
+	}
+	if dto.Destinations == nil {
+		dto.Destinations = []string{}
+	}
+	if !status.LastActivity.IsZero() {
+		dto.LastActivity = status.LastActivity.Format(time.RFC3339)
+	}
+	return dto
+}
+
+// OnDemandDTO describes traffic triggering the main connection and the state of connection made for it.
+// swagger:model OnDemandDTO
+type OnDemandDTO struct {
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// identity on-demand connections are made with
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// country code of providers on-demand connections are made to
+	// example: DE
+	Country string `json:"country"`
+
+	// service type on-demand connections are made to, any if empty
+	// example: wireguard
+	ServiceType string `json:"service_type,omitempty"`
+
+	// IPv4 addresses or networks outbound traffic to which triggers the connection
+	// example: ["10.8.0.0/16", "93.184.216.34"]
+	Destinations []string `json:"destinations"`
+
+	// virtual network interface any forwarded traffic from which triggers the connection
+	// example: virbr0
+	Interface string `json:"interface,omitempty"`
+
+	// seconds the watched traffic stays idle before connection made on demand is disconnected, 0 keeps the connection
+	// example: 600
+	IdleTimeout uint64 `json:"idle_timeout"`
+
+	// whether connection made on demand is up, connections made by the user are never interrupted
+	// example: true
+	Connected bool `json:"connected"`
+
+	// last time watched traffic was seen in RFC3339 format
+	// example: 2020-06-01T12:00:00Z
+	LastActivity string `json:"last_activity,omitempty"`
+
+	// reason the last on-demand connection attempt failed
+	// example: no proposals found
+	LastError string `json:"last_error,omitempty"`
+}
+
+// OnDemandRequest request used to configure on-demand mode.
+// swagger:model OnDemandRequestDTO
+type OnDemandRequest struct {
+	// required: true
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// identity on-demand connections are made with, required when on-demand mode is enabled
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// country code of providers on-demand connections are made to, required when on-demand mode is enabled
+	// example: DE
+	Country string `json:"country"`
+
+	// service type on-demand connections are made to, any if empty
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// IPv4 addresses or networks in CIDR notation, destinations or interface are required when on-demand mode is enabled
+	// example: ["10.8.0.0/16", "93.184.216.34"]
+	Destinations []string `json:"destinations"`
+
+	// virtual network interface, e.g. of VMs or containers
+	// example: virbr0
+	Interface string `json:"interface"`
+
+	// seconds the watched traffic stays idle before connection made on demand is disconnected, 0 keeps the connection
+	// example: 600
+	IdleTimeout uint64 `json:"idle_timeout"`
+}
+
+// Validate validates fields in request
+func (r OnDemandRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if err := (ondemand.Config{Destinations: r.Destinations}).Validate(); err != nil {
+		errs.ForField("destinations").AddError("invalid", err.Error())
+	}
+	if !r.Enabled {
+		return errs
+	}
+	if r.ConsumerID == "" {
+		errs.ForField("consumer_id").AddError("required", "Field is required")
+	}
+	if r.Country == "" {
+		errs.ForField("country").AddError("required", "Field is required")
+	} else if len(r.Country) != 2 {
+		errs.ForField("country").AddError("invalid", "Country must be two letter code")
+	}
+	if len(r.Destinations) == 0 && r.Interface == "" {
+		errs.ForField("destinations").AddError("required", "Destinations or interface are required")
+	}
+	return errs
+}
@@ -0,0 +1,53 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import "github.com/mysteriumnetwork/node/session/pingpong"
+
+// NewPromiseJournalDTO maps to API promise journal report.
+func NewPromiseJournalDTO(report pingpong.PromiseJournalReport) PromiseJournalDTO {
+	corrupted := report.Corrupted
+	if corrupted == nil {
+		corrupted = []string{}
+	}
+	return PromiseJournalDTO{
+		Entries:   report.Entries,
+		Pending:   report.Pending,
+		Abandoned: report.Abandoned,
+		Corrupted: corrupted,
+	}
+}
+
+// PromiseJournalDTO represents the state of the journal of promises accepted from consumers.
+// swagger:model PromiseJournalDTO
+type PromiseJournalDTO struct {
+	// number of consumer channels journaled
+	// example: 12
+	Entries int `json:"entries"`
+
+	// number of promises not yet exchanged with the accountant
+	// example: 1
+	Pending int `json:"pending"`
+
+	// number of promises which were never exchanged with the accountant
+	// example: 0
+	Abandoned int `json:"abandoned"`
+
+	// consumer channels which entries are corrupted
+	Corrupted []string `json:"corrupted"`
+}
@@ -22,7 +22,7 @@ import (
 
 	"github.com/mysteriumnetwork/node/core/quality"
 	"github.com/mysteriumnetwork/node/market"
-	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
 )
 
 // NewProposalDTO maps to API service proposal.
@@ -34,6 +34,8 @@ func NewProposalDTO(p market.ServiceProposal) ProposalDTO {
 		ServiceDefinition: NewServiceDefinitionDTO(p.ServiceDefinition),
 		AccessPolicies:    p.AccessPolicies,
 		PaymentMethod:     NewPaymentMethodDTO(p.PaymentMethod),
+		QoSClasses:        p.QoSClasses,
+		ContentFilter:     p.ContentFilter,
 	}
 }
 
@@ -42,14 +44,23 @@ func NewPaymentMethodDTO(m market.PaymentMethod) PaymentMethodDTO {
 	if m == nil {
 		return PaymentMethodDTO{}
 	}
-	return PaymentMethodDTO{
+	dto := PaymentMethodDTO{
 		Type:  m.GetType(),
-		Price: m.GetPrice(),
+		Price: NewMoneyTokensDTO(m.GetPrice()),
 		Rate: PaymentRateDTO{
 			PerSeconds: uint64(m.GetRate().PerTime.Seconds()),
 			PerBytes:   m.GetRate().PerByte,
 		},
 	}
+	if trial, ok := m.(market.FreeTrialPaymentMethod); ok {
+		if duration, bytes := trial.GetFreeTrial(); duration > 0 || bytes > 0 {
+			dto.FreeTrial = &FreeTrialDTO{
+				Seconds: uint64(duration.Seconds()),
+				Bytes:   bytes,
+			}
+		}
+	}
+	return dto
 }
 
 // NewServiceDefinitionDTO maps to API service definition.
@@ -57,9 +68,17 @@ func NewServiceDefinitionDTO(s market.ServiceDefinition) ServiceDefinitionDTO {
 	if s == nil {
 		return ServiceDefinitionDTO{}
 	}
-	return ServiceDefinitionDTO{
+	dto := ServiceDefinitionDTO{
 		LocationOriginate: NewLocationsDTO(s.GetLocation()),
 	}
+	if description := market.DescriptionOf(s); !description.IsEmpty() {
+		dto.Description = &ProviderDescriptionDTO{
+			Nickname:   description.Nickname,
+			ContactURL: description.ContactURL,
+			Tags:       description.Tags,
+		}
+	}
+	return dto
 }
 
 // NewLocationsDTO maps to API service location.
@@ -101,6 +120,17 @@ type ProposalDTO struct {
 
 	// PaymentMethod
 	PaymentMethod PaymentMethodDTO `json:"payment_method"`
+
+	// projected cost of typical usage, for comparing providers
+	TypicalUsageCost *ProposalCostDTO `json:"typical_usage_cost,omitempty"`
+
+	// QoS classes of sessions offered by provider, the first one is the default
+	// example: ["standard","premium"]
+	QoSClasses []string `json:"qos_classes,omitempty"`
+
+	// content categories blocked in DNS queries of consumers, empty if exit is unfiltered
+	// example: ["adult","malware"]
+	ContentFilter []string `json:"content_filter,omitempty"`
 }
 
 func (p ProposalDTO) String() string {
@@ -111,6 +141,21 @@ func (p ProposalDTO) String() string {
 // swagger:model ServiceDefinitionDTO
 type ServiceDefinitionDTO struct {
 	LocationOriginate ServiceLocationDTO `json:"location_originate"`
+
+	// public self-description of the provider, omitted if provider has not described itself
+	Description *ProviderDescriptionDTO `json:"description,omitempty"`
+}
+
+// ProviderDescriptionDTO holds public self-description of the provider.
+// swagger:model ProviderDescriptionDTO
+type ProviderDescriptionDTO struct {
+	// example: Fast Exit
+	Nickname string `json:"nickname,omitempty"`
+	// example: https://example.com/contact
+	ContactURL string `json:"contact_url,omitempty"`
+	// capabilities declared by provider
+	// example: ["streaming-friendly"]
+	Tags []string `json:"tags,omitempty"`
 }
 
 // ServiceLocationDTO holds service location metadata.
@@ -143,8 +188,17 @@ type ProposalMetricsDTO struct {
 // swagger:model PaymentMethodDTO
 type PaymentMethodDTO struct {
 	Type  string         `json:"type"`
-	Price money.Money    `json:"price"`
+	Price TokensDTO      `json:"price"`
 	Rate  PaymentRateDTO `json:"rate"`
+	// free allowance registered consumers get every day before being charged
+	FreeTrial *FreeTrialDTO `json:"free_trial,omitempty"`
+}
+
+// FreeTrialDTO holds free trial allowance of the service.
+// swagger:model FreeTrialDTO
+type FreeTrialDTO struct {
+	Seconds uint64 `json:"seconds"`
+	Bytes   uint64 `json:"bytes"`
 }
 
 // PaymentRateDTO holds payment frequencies.
@@ -167,3 +221,71 @@ type QualityMetricsResponse struct {
 	ServiceType string `json:"service_type"`
 	ProposalMetricsDTO
 }
+
+// ProposalCostEstimateRequest request used to estimate cost of using a proposal.
+// swagger:model ProposalCostEstimateRequestDTO
+type ProposalCostEstimateRequest struct {
+	// provider identity
+	// required: true
+	// example: 0x0000000000000000000000000000000000000002
+	ProviderID string `json:"provider_id"`
+
+	// per provider unique serial number of service description provided
+	// example: 5
+	ProposalID int `json:"proposal_id"`
+
+	// service type
+	// required: true
+	// example: wireguard
+	ServiceType string `json:"service_type"`
+
+	// expected amount of transferred data in GiB
+	// example: 1.5
+	GB float64 `json:"gb"`
+
+	// expected session duration in hours
+	// example: 2
+	Hours float64 `json:"hours"`
+}
+
+// Validate validates fields in request
+func (r ProposalCostEstimateRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if len(r.ProviderID) == 0 {
+		errs.ForField("provider_id").AddError("required", "Field is required")
+	}
+	if len(r.ServiceType) == 0 {
+		errs.ForField("service_type").AddError("required", "Field is required")
+	}
+	if r.GB < 0 {
+		errs.ForField("gb").AddError("invalid", "Field must not be negative")
+	}
+	if r.Hours < 0 {
+		errs.ForField("hours").AddError("invalid", "Field must not be negative")
+	}
+	if r.GB == 0 && r.Hours == 0 {
+		errs.ForField("gb").AddError("required", "Either gb or hours is required")
+	}
+	return errs
+}
+
+// ProposalCostDTO holds projected cost of using a proposal.
+// swagger:model ProposalCostDTO
+type ProposalCostDTO struct {
+	// expected amount of transferred data in GiB
+	// example: 1
+	GB float64 `json:"gb"`
+
+	// expected session duration in hours
+	// example: 1
+	Hours float64 `json:"hours"`
+
+	// price of the service itself
+	Amount TokensDTO `json:"amount"`
+
+	// transactor fee of settling the amount
+	TransactorFee TokensDTO `json:"transactor_fee"`
+
+	// amount including fees
+	Total TokensDTO `json:"total"`
+}
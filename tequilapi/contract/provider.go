@@ -0,0 +1,143 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/vcraescu/go-paginator"
+)
+
+// ProviderOverviewDTO aggregates the information a provider dashboard needs into a single response.
+// swagger:model ProviderOverviewDTO
+type ProviderOverviewDTO struct {
+	// number of currently active sessions
+	// example: 2
+	ActiveSessions int `json:"active_sessions"`
+
+	// number of unique consumers in currently active sessions
+	// example: 1
+	ActiveConsumers int `json:"active_consumers"`
+
+	// statistics of sessions provided since the start of the day (UTC)
+	Today SessionStatsDTO `json:"today"`
+
+	// statistics of sessions provided since the start of the month (UTC)
+	Month SessionStatsDTO `json:"month"`
+
+	NATStatus NATStatusDTO `json:"nat_status"`
+
+	Services []ProviderServiceOverviewDTO `json:"services"`
+}
+
+// ProviderServiceOverviewDTO represents the health and proposal status of a running service.
+// swagger:model ProviderServiceOverviewDTO
+type ProviderServiceOverviewDTO struct {
+	// example: 6ba7b810-9dad-11d1-80b4-00c04fd430c8
+	ID string `json:"id"`
+
+	// example: wireguard
+	Type string `json:"type"`
+
+	// example: Running
+	Status string `json:"status"`
+
+	// how many times the crashed service was restarted
+	// example: 0
+	Restarts int `json:"restarts"`
+
+	// proposal status. Possible values are "published", "unlisted" and "not_published"
+	// example: published
+	ProposalStatus string `json:"proposal_status"`
+
+	ConnectionStatistics ServiceStatisticsDTO `json:"connection_statistics"`
+}
+
+// NewProviderConsumersResponse maps to API provider consumers list.
+func NewProviderConsumersResponse(consumers []session.ConsumerStats, paginator *paginator.Paginator, total, returning int) ProviderConsumersResponse {
+	dtoArray := make([]ProviderConsumerDTO, len(consumers))
+	for i, cs := range consumers {
+		dtoArray[i] = ProviderConsumerDTO{
+			ConsumerHash:     cs.ConsumerHash,
+			FirstSeen:        cs.FirstSeen,
+			LastSeen:         cs.LastSeen,
+			Returning:        cs.Returning(),
+			Sessions:         cs.Sessions,
+			SumBytesReceived: cs.DataReceived,
+			SumBytesSent:     cs.DataSent,
+			SumTokens:        NewTokensDTO(cs.Tokens),
+		}
+	}
+
+	return ProviderConsumersResponse{
+		Consumers:      dtoArray,
+		Paging:         NewPagingDTO(paginator),
+		CountConsumers: total,
+		CountNew:       total - returning,
+		CountReturning: returning,
+	}
+}
+
+// ProviderConsumersResponse defines list of consumers served by provider representable as json.
+// swagger:model ProviderConsumersResponse
+type ProviderConsumersResponse struct {
+	Consumers []ProviderConsumerDTO `json:"consumers"`
+	Paging    PagingDTO             `json:"paging"`
+
+	// number of unique consumers ever served
+	// example: 10
+	CountConsumers int `json:"count_consumers"`
+
+	// number of consumers which had a single session
+	// example: 7
+	CountNew int `json:"count_new"`
+
+	// number of consumers which came back for another session
+	// example: 3
+	CountReturning int `json:"count_returning"`
+}
+
+// ProviderConsumerDTO represents lifetime statistics of a consumer served by provider.
+// swagger:model ProviderConsumerDTO
+type ProviderConsumerDTO struct {
+	// hash of consumer identity, consumer addresses are not stored
+	// example: 9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08
+	ConsumerHash string `json:"consumer_hash"`
+
+	// example: 2020-06-17T10:11:12Z
+	FirstSeen time.Time `json:"first_seen"`
+
+	// example: 2020-06-18T10:11:12Z
+	LastSeen time.Time `json:"last_seen"`
+
+	// whether consumer came back for another session
+	// example: true
+	Returning bool `json:"returning"`
+
+	// example: 2
+	Sessions int `json:"sessions"`
+
+	// example: 1024
+	SumBytesReceived uint64 `json:"sum_bytes_received"`
+
+	// example: 1024
+	SumBytesSent uint64 `json:"sum_bytes_sent"`
+
+	SumTokens TokensDTO `json:"sum_tokens"`
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/consumer/schedule"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// NewScheduleDTO maps to API schedule.
+func NewScheduleDTO(status schedule.Status) ScheduleDTO {
+	rules := make([]string, len(status.Rules))
+	for i, rule := range status.Rules {
+		rules[i] = rule.String()
+	}
+	return ScheduleDTO{
+		Enabled:    status.Enabled,
+		ConsumerID: status.ConsumerID,
+		Rules:      rules,
+		ActiveRule: status.ActiveRule,
+		Connected:  status.Connected,
+		LastError:  status.LastError,
+	}
+}
+
+// ScheduleDTO describes schedule rules of the main connection and the state of connection made by them.
+// swagger:model ScheduleDTO
+type ScheduleDTO struct {
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// identity scheduled connections are made with
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// rules given as "<days> <from>-<to> <country> [service type]" in local time, the first active one is used if several overlap
+	// example: ["mon,tue,wed,thu,fri 09:00-17:00 DE wireguard"]
+	Rules []string `json:"rules"`
+
+	// rule active right now, empty if none is
+	// example: mon,tue,wed,thu,fri 09:00-17:00 DE wireguard
+	ActiveRule string `json:"active_rule,omitempty"`
+
+	// whether connection made by the schedule is up, connections made by the user are never interrupted by the schedule
+	// example: true
+	Connected bool `json:"connected"`
+
+	// reason the last scheduled connection attempt failed
+	// example: no proposals found
+	LastError string `json:"last_error,omitempty"`
+}
+
+// ScheduleRequest request used to configure schedule rules.
+// swagger:model ScheduleRequestDTO
+type ScheduleRequest struct {
+	// required: true
+	// example: true
+	Enabled bool `json:"enabled"`
+
+	// identity scheduled connections are made with, required when schedule is enabled
+	// example: 0x0000000000000000000000000000000000000001
+	ConsumerID string `json:"consumer_id"`
+
+	// rules given as "<days> <from>-<to> <country> [service type]" in local time, days are comma separated names
+	// or ranges of "mon" to "sun", or "daily", window ending before it starts ends on the next day
+	// example: ["mon-fri 09:00-17:00 DE wireguard", "sat,sun 10:00-02:00 US"]
+	Rules []string `json:"rules"`
+}
+
+// Validate validates fields in request
+func (r ScheduleRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if _, err := schedule.ParseRules(r.Rules); err != nil {
+		errs.ForField("rules").AddError("invalid", err.Error())
+	}
+	if !r.Enabled {
+		return errs
+	}
+	if r.ConsumerID == "" {
+		errs.ForField("consumer_id").AddError("required", "Field is required")
+	}
+	if len(r.Rules) == 0 {
+		errs.ForField("rules").AddError("required", "Field is required")
+	}
+	return errs
+}
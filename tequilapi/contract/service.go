@@ -17,6 +17,8 @@
 
 package contract
 
+import "time"
+
 // ServiceStartRequest request used to start a service.
 // swagger:model ServiceStartRequestDTO
 type ServiceStartRequest struct {
@@ -38,17 +40,38 @@ type ServiceStartRequest struct {
 	// required: false
 	AccessPolicies ServiceAccessPolicies `json:"access_policies"`
 
+	// unlisted services are not announced to discovery, consumers connect to them using the invite code
+	// required: false
+	// example: false
+	Unlisted bool `json:"unlisted"`
+
+	// restricts which consumers are able to connect by their country and ASN
+	// required: false
+	ConsumerPolicy ServiceConsumerPolicy `json:"consumer_policy"`
+
 	// service options. Every service has a unique list of allowed options.
 	// required: false
 	// example: {"port": 1123, "protocol": "udp"}
 	Options interface{} `json:"options"`
 }
 
+// ServicePauseRequest request used to pause a service.
+// swagger:model ServicePauseRequestDTO
+type ServicePauseRequest struct {
+	// whether active sessions of the service should be closed, they are kept running if false
+	// required: false
+	// example: false
+	SuspendSessions bool `json:"suspend_sessions"`
+}
+
 // ServicePaymentMethod payment parameters for service start.
 // swagger:model ServicePaymentMethod
 type ServicePaymentMethod struct {
 	PriceGB     uint64 `json:"price_gb"`
 	PriceMinute uint64 `json:"price_minute"`
+	// free trial allowance a registered consumer gets every day
+	FreeMinutes uint64 `json:"free_minutes,omitempty"`
+	FreeMB      uint64 `json:"free_mb,omitempty"`
 }
 
 // ServiceAccessPolicies represents the access controls for service start
@@ -57,6 +80,20 @@ type ServiceAccessPolicies struct {
 	IDs []string `json:"ids"`
 }
 
+// ServiceConsumerPolicy restricts consumers of the service by their location.
+// Allow lists are only applied when they are not empty, deny lists take precedence over them.
+// swagger:model ServiceConsumerPolicy
+type ServiceConsumerPolicy struct {
+	// example: ["LT", "DE"]
+	AllowedCountries []string `json:"allowed_countries,omitempty"`
+	// example: ["US"]
+	DeniedCountries []string `json:"denied_countries,omitempty"`
+	// example: [8764]
+	AllowedASNs []int `json:"allowed_asns,omitempty"`
+	// example: [15169]
+	DeniedASNs []int `json:"denied_asns,omitempty"`
+}
+
 // ListServicesResponse represents a list of running services on the node.
 // swagger:model ListServicesResponse
 type ListServicesResponse []ServiceInfoDTO
@@ -82,9 +119,58 @@ type ServiceInfoDTO struct {
 	// example: Running
 	Status string `json:"status"`
 
+	// how many times the crashed service was restarted
+	// example: 0
+	Restarts int `json:"restarts"`
+
 	Proposal ProposalDTO `json:"proposal"`
 
+	// whether the service proposal is hidden from discovery
+	// example: false
+	Unlisted bool `json:"unlisted"`
+
+	// code which consumers use to connect to the unlisted service
+	// example: myst-invite:3ZJNb9owEIbv_SvQnPdgQoQ2pxp...
+	InviteCode string `json:"invite_code,omitempty"`
+
+	// restrictions applied to consumers of the service, omitted when there are none
+	ConsumerPolicy *ServiceConsumerPolicy `json:"consumer_policy,omitempty"`
+
 	ConnectionStatistics ServiceStatisticsDTO `json:"connection_statistics"`
+
+	// how many packets consumers sent to blocked destination ports
+	// example: 0
+	BlockedEgressAttempts uint64 `json:"blocked_egress_attempts"`
+
+	// backend running tunnels of the service, omitted if the service has no choice of them
+	// example: kernel
+	Backend string `json:"backend,omitempty"`
+
+	// last sample of resources used by the service, omitted until the first sample is taken
+	ResourceUsage *ServiceResourceUsageDTO `json:"resource_usage,omitempty"`
+}
+
+// ServiceResourceUsageDTO shows resources used by the running service
+// swagger:model ServiceResourceUsageDTO
+type ServiceResourceUsageDTO struct {
+	// goroutines started while serving the service
+	// example: 42
+	Goroutines int `json:"goroutines"`
+
+	// CPU usage of processes run by the service
+	// example: 1.5
+	CPUPercent float64 `json:"cpu_percent"`
+
+	// resident memory of processes run by the service
+	// example: 10485760
+	MemoryBytes uint64 `json:"memory_bytes"`
+
+	// open file descriptors of processes run by the service
+	// example: 24
+	FileDescriptors int `json:"file_descriptors"`
+
+	// example: 2020-11-03T08:40:15Z
+	SampledAt time.Time `json:"sampled_at"`
 }
 
 // ServiceStatisticsDTO shows the successful and attempted connection count
@@ -18,6 +18,7 @@
 package contract
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/mysteriumnetwork/node/consumer/session"
@@ -53,6 +54,42 @@ type ListSessionsResponse struct {
 	StatsDaily map[string]SessionStatsDTO `json:"stats_daily"`
 }
 
+// NewSearchSessionsResponse maps to API session search result.
+func NewSearchSessionsResponse(sessions []session.History, paginator *paginator.Paginator) SearchSessionsResponse {
+	dtoArray := make([]SessionDTO, len(sessions))
+	for i, se := range sessions {
+		dtoArray[i] = NewSessionDTO(se)
+	}
+
+	return SearchSessionsResponse{
+		Sessions: dtoArray,
+		Paging:   NewPagingDTO(paginator),
+	}
+}
+
+// SearchSessionsResponse defines session search result representable as json.
+// swagger:model SearchSessionsResponse
+type SearchSessionsResponse struct {
+	Sessions []SessionDTO `json:"sessions"`
+	Paging   PagingDTO    `json:"paging"`
+}
+
+// DeleteSessionsResponse defines sessions deletion result representable as json.
+// swagger:model DeleteSessionsResponse
+type DeleteSessionsResponse struct {
+	// count of deleted session history records
+	// example: 10
+	Deleted int `json:"deleted"`
+}
+
+// SessionLogsDTO holds recent log lines of a single session.
+// swagger:model SessionLogsDTO
+type SessionLogsDTO struct {
+	SessionID string `json:"session_id"`
+	// log lines carrying session correlation ID, oldest first
+	Logs []json.RawMessage `json:"logs"`
+}
+
 // NewSessionStatsDTO maps to API session stats.
 func NewSessionStatsDTO(stats session.Stats) SessionStatsDTO {
 	return SessionStatsDTO{
@@ -61,19 +98,19 @@ func NewSessionStatsDTO(stats session.Stats) SessionStatsDTO {
 		SumBytesReceived: stats.SumDataReceived,
 		SumBytesSent:     stats.SumDataSent,
 		SumDuration:      uint64(stats.SumDuration.Seconds()),
-		SumTokens:        stats.SumTokens,
+		SumTokens:        NewTokensDTO(stats.SumTokens),
 	}
 }
 
 // SessionStatsDTO represents the session aggregated statistics.
 // swagger:model ListSessionsResponse
 type SessionStatsDTO struct {
-	Count            int    `json:"count"`
-	CountConsumers   int    `json:"count_consumers"`
-	SumBytesReceived uint64 `json:"sum_bytes_received"`
-	SumBytesSent     uint64 `json:"sum_bytes_sent"`
-	SumDuration      uint64 `json:"sum_duration"`
-	SumTokens        uint64 `json:"sum_tokens"`
+	Count            int       `json:"count"`
+	CountConsumers   int       `json:"count_consumers"`
+	SumBytesReceived uint64    `json:"sum_bytes_received"`
+	SumBytesSent     uint64    `json:"sum_bytes_sent"`
+	SumDuration      uint64    `json:"sum_duration"`
+	SumTokens        TokensDTO `json:"sum_tokens"`
 }
 
 // NewSessionStatsDailyDTO maps to API session stats grouped by day.
@@ -87,6 +124,12 @@ func NewSessionStatsDailyDTO(statsGrouped map[time.Time]session.Stats) map[strin
 
 // NewSessionDTO maps to API session.
 func NewSessionDTO(se session.History) SessionDTO {
+	var trafficBreakdown *TrafficBreakdownDTO
+	if len(se.Traffic.Categories) > 0 {
+		breakdown := NewTrafficBreakdownDTO(se.Traffic)
+		trafficBreakdown = &breakdown
+	}
+
 	return SessionDTO{
 		ID:              string(se.SessionID),
 		Direction:       se.Direction,
@@ -99,8 +142,28 @@ func NewSessionDTO(se session.History) SessionDTO {
 		BytesReceived:   se.DataReceived,
 		BytesSent:       se.DataSent,
 		Duration:        uint64(se.GetDuration().Seconds()),
-		Tokens:          se.Tokens,
+		Tokens:          NewTokensDTO(se.Tokens),
+		KeyRotations:    se.KeyRotations,
 		Status:          se.Status,
+
+		LocationMismatch: se.LocationMismatch,
+		DetectedCountry:  se.DetectedCountry,
+
+		TrafficDiverged:      se.TrafficDiverged,
+		PeerBytesTransferred: se.PeerDataTransferred,
+
+		TerminationReason: se.TerminationReason,
+		NATTraversal:      se.NATTraversal,
+		PaymentVersion:    se.PaymentVersion,
+		QoSClass:          se.QoSClass,
+		Throughput:        uint64(se.GetThroughput()),
+
+		SpeedTestTarget:   se.SpeedTestTarget,
+		SpeedTestLatency:  uint64(se.SpeedTestLatency / time.Millisecond),
+		SpeedTestDownload: uint64(se.SpeedTestDownload),
+		SpeedTestUpload:   uint64(se.SpeedTestUpload),
+
+		TrafficBreakdown: trafficBreakdown,
 	}
 }
 
@@ -141,9 +204,67 @@ type SessionDTO struct {
 	// example: 1024
 	BytesSent uint64 `json:"bytes_sent"`
 
-	// example: 500000
-	Tokens uint64 `json:"tokens"`
+	Tokens TokensDTO `json:"tokens"`
+
+	// number of tunnel key rotations during the session
+	// example: 2
+	KeyRotations int `json:"key_rotations"`
 
 	// example: Completed
 	Status string `json:"status"`
+
+	// true when the connection exited in a different country than provider advertised
+	// example: false
+	LocationMismatch bool `json:"location_mismatch,omitempty"`
+
+	// country detected after connecting, set only on location mismatch
+	// example: DE
+	DetectedCountry string `json:"detected_country,omitempty"`
+
+	// true when traffic counted by the other party of the session diverged significantly
+	// example: false
+	TrafficDiverged bool `json:"traffic_diverged,omitempty"`
+
+	// total traffic reported by the other party on the last divergence
+	// example: 2048
+	PeerBytesTransferred uint64 `json:"peer_bytes_transferred,omitempty"`
+
+	// why the session ended, set only on completed sessions
+	// example: peer_lost
+	TerminationReason string `json:"termination_reason,omitempty"`
+
+	// NAT traversal method used to reach the peer
+	// example: hole_punching
+	NATTraversal string `json:"nat_traversal,omitempty"`
+
+	// negotiated payment version
+	// example: v3
+	PaymentVersion string `json:"payment_version,omitempty"`
+
+	// QoS class negotiated with provider
+	// example: premium
+	QoSClass string `json:"qos_class,omitempty"`
+
+	// average throughput in both directions, bits per second
+	// example: 2048
+	Throughput uint64 `json:"throughput"`
+
+	// target of the last connection speed test, set only when speed was measured
+	// example: provider
+	SpeedTestTarget string `json:"speed_test_target,omitempty"`
+
+	// latency measured by the last speed test, milliseconds
+	// example: 20
+	SpeedTestLatency uint64 `json:"speed_test_latency,omitempty"`
+
+	// download speed measured by the last speed test, bits per second
+	// example: 104857600
+	SpeedTestDownload uint64 `json:"speed_test_download,omitempty"`
+
+	// upload speed measured by the last speed test, bits per second
+	// example: 10485760
+	SpeedTestUpload uint64 `json:"speed_test_upload,omitempty"`
+
+	// tunneled traffic split per destination, set only for sessions made with traffic breakdown enabled
+	TrafficBreakdown *TrafficBreakdownDTO `json:"traffic_breakdown,omitempty"`
 }
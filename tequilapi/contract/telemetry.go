@@ -0,0 +1,31 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import "encoding/json"
+
+// TelemetryPreviewDTO shows telemetry which would be sent.
+// swagger:model TelemetryPreviewDTO
+type TelemetryPreviewDTO struct {
+	// whether user consented to send telemetry
+	// example: false
+	Enabled bool `json:"enabled"`
+
+	// exact payload sent on the next flush, it is not sent if telemetry is disabled
+	Payload json.RawMessage `json:"payload"`
+}
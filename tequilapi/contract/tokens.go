@@ -0,0 +1,66 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/pkg/errors"
+)
+
+// DefaultTokensPrecision is the default number of decimal places of human readable amounts of tokens.
+const DefaultTokensPrecision = 6
+
+// maxTokensPrecision is the number of decimal places of the smallest token unit, more places would be zeros only.
+const maxTokensPrecision = 8
+
+// tokensPrecision is configured once on startup, before tequilapi starts serving.
+var tokensPrecision = DefaultTokensPrecision
+
+// SetTokensPrecision sets the number of decimal places of human readable amounts of tokens.
+func SetTokensPrecision(precision int) error {
+	if precision < 0 || precision > maxTokensPrecision {
+		return errors.Errorf("tokens precision %d is out of range [0, %d]", precision, maxTokensPrecision)
+	}
+	tokensPrecision = precision
+	return nil
+}
+
+// NewTokensDTO maps amount of tokens to API, human readable amount is rounded to the configured precision.
+func NewTokensDTO(amount money.Tokens) TokensDTO {
+	return TokensDTO{
+		Amount: amount.String(),
+		Myst:   amount.Decimal(tokensPrecision),
+	}
+}
+
+// NewMoneyTokensDTO maps amount of money to API.
+func NewMoneyTokensDTO(amount money.Money) TokensDTO {
+	return NewTokensDTO(money.NewTokens(amount.Amount))
+}
+
+// TokensDTO holds an amount of tokens, both exact and human readable, so that API clients do not need to convert it.
+// swagger:model TokensDTO
+type TokensDTO struct {
+	// amount of tokens in the smallest units, 1 MYST is 100000000 of them
+	// example: 150000000
+	Amount string `json:"amount"`
+
+	// amount of tokens in MYST, rounded to the precision configured by tequilapi.tokens-precision flag
+	// example: 1.500000
+	Myst string `json:"myst"`
+}
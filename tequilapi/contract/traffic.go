@@ -0,0 +1,64 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import "github.com/mysteriumnetwork/node/consumer/traffic"
+
+// TrafficUsageDTO holds traffic transferred with a destination.
+// swagger:model TrafficUsageDTO
+type TrafficUsageDTO struct {
+	// category or host name, hosts with unknown names are named by their IP
+	// example: video
+	Name string `json:"name"`
+
+	// example: 1024
+	BytesSent uint64 `json:"bytes_sent"`
+
+	// example: 1048576
+	BytesReceived uint64 `json:"bytes_received"`
+}
+
+// TrafficBreakdownDTO holds tunneled traffic split per destination, sorted by the total usage.
+// swagger:model TrafficBreakdownDTO
+type TrafficBreakdownDTO struct {
+	// traffic per destination category, possible values are "video", "music", "social", "messaging", "gaming", "software" and "other"
+	Categories []TrafficUsageDTO `json:"categories"`
+
+	// traffic per host, only the first hosts seen in the session are listed separately
+	Hosts []TrafficUsageDTO `json:"hosts"`
+}
+
+// NewTrafficBreakdownDTO maps to API traffic breakdown.
+func NewTrafficBreakdownDTO(breakdown traffic.Breakdown) TrafficBreakdownDTO {
+	return TrafficBreakdownDTO{
+		Categories: newTrafficUsageDTOs(breakdown.Categories),
+		Hosts:      newTrafficUsageDTOs(breakdown.Hosts),
+	}
+}
+
+func newTrafficUsageDTOs(usage []traffic.Usage) []TrafficUsageDTO {
+	dtos := make([]TrafficUsageDTO, len(usage))
+	for i, u := range usage {
+		dtos[i] = TrafficUsageDTO{
+			Name:          u.Name,
+			BytesSent:     u.BytesSent,
+			BytesReceived: u.BytesReceived,
+		}
+	}
+	return dtos
+}
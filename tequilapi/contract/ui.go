@@ -0,0 +1,76 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"net/url"
+
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+)
+
+// UIOriginDTO describes an origin of UI talking to the node.
+// swagger:model UIOriginDTO
+type UIOriginDTO struct {
+	// example: http://192.168.1.10:3000
+	Origin string `json:"origin"`
+
+	// trusted origins are built-in, allowed ones are approved by user and pending ones wait for approval
+	// example: pending
+	Status string `json:"status"`
+
+	// requests origin is allowed to make, "full" or "read"
+	// example: read
+	Allowance string `json:"allowance,omitempty"`
+}
+
+// UIOriginsDTO lists origins approved by user and the ones waiting for approval.
+// swagger:model UIOriginsDTO
+type UIOriginsDTO struct {
+	Allowed []UIOriginDTO `json:"allowed"`
+	Pending []UIOriginDTO `json:"pending"`
+}
+
+// UIOriginRequest request used to allow UI origin.
+// swagger:model UIOriginRequestDTO
+type UIOriginRequest struct {
+	// required: true
+	// example: http://192.168.1.10:3000
+	Origin string `json:"origin"`
+
+	// requests origin is allowed to make, "full" or "read"
+	// required: false
+	// default: full
+	// example: read
+	Allowance string `json:"allowance"`
+}
+
+// Validate validates fields in request
+func (r UIOriginRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if r.Origin == "" {
+		errs.ForField("origin").AddError("required", "Field is required")
+	} else if u, err := url.Parse(r.Origin); err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" {
+		errs.ForField("origin").AddError("invalid", "Origin must consist of scheme, host and optional port")
+	}
+	switch r.Allowance {
+	case "", "full", "read":
+	default:
+		errs.ForField("allowance").AddError("invalid", "Unknown allowance")
+	}
+	return errs
+}
@@ -0,0 +1,76 @@
+// +build !mobile_slim
+
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+	"github.com/mysteriumnetwork/node/updater"
+)
+
+// UpdateStatusDTO describes node self-update state.
+// swagger:model UpdateStatusDTO
+type UpdateStatusDTO struct {
+	// example: 0.39.0
+	CurrentVersion string `json:"current_version"`
+	// example: stable
+	Channel string `json:"channel"`
+	// example: Idle
+	State string `json:"state"`
+	// example: 0.40.0
+	AvailableVersion string `json:"available_version,omitempty"`
+	// example: 2020-09-01T10:00:00Z
+	LastCheck string `json:"last_check,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// NewUpdateStatusDTO maps updater status to DTO.
+func NewUpdateStatusDTO(status updater.Status) UpdateStatusDTO {
+	dto := UpdateStatusDTO{
+		CurrentVersion: status.CurrentVersion,
+		Channel:        status.Channel,
+		State:          string(status.State),
+		Error:          status.Error,
+	}
+	if status.Available != nil {
+		dto.AvailableVersion = status.Available.Version
+	}
+	if !status.LastCheck.IsZero() {
+		dto.LastCheck = status.LastCheck.UTC().Format(time.RFC3339)
+	}
+	return dto
+}
+
+// UpdateChannelRequest request used to switch release channel.
+// swagger:model UpdateChannelRequest
+type UpdateChannelRequest struct {
+	// example: beta
+	Channel string `json:"channel"`
+}
+
+// Validate validates fields in request
+func (r UpdateChannelRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if r.Channel != updater.ChannelStable && r.Channel != updater.ChannelBeta {
+		errs.ForField("channel").AddError("invalid", "Channel must be one of: stable, beta")
+	}
+	return errs
+}
@@ -0,0 +1,107 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package contract
+
+import (
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/mysteriumnetwork/node/tequilapi/validation"
+	"github.com/mysteriumnetwork/node/withdrawal"
+)
+
+// WithdrawalDTO describes withdrawal of identity earnings.
+// swagger:model WithdrawalDTO
+type WithdrawalDTO struct {
+	// example: 0x0000000000000000000000000000000000000001
+	Identity string `json:"identity"`
+	// example: 0x0000000000000000000000000000000000000002
+	AccountantID string `json:"accountant_id"`
+	// example: 0x0000000000000000000000000000000000000003
+	Beneficiary string `json:"beneficiary"`
+	// example: confirming
+	Status string `json:"status"`
+	// example: 0x88af51047ff2da1e3626722fe239f70c3ddd668f067b2ac8d67b280d2eff39f7
+	TxHash string `json:"tx_hash,omitempty"`
+	// example: 2
+	Confirmations uint64 `json:"confirmations"`
+	Error         string `json:"error,omitempty"`
+	// example: 2020-09-01T10:00:00Z
+	StartedAt string `json:"started_at"`
+	// example: 2020-09-01T10:01:00Z
+	UpdatedAt string `json:"updated_at"`
+}
+
+// NewWithdrawalDTO maps withdrawal to DTO.
+func NewWithdrawalDTO(w withdrawal.Withdrawal) WithdrawalDTO {
+	dto := WithdrawalDTO{
+		Identity:      w.Identity.Address,
+		AccountantID:  w.Accountant.Hex(),
+		Beneficiary:   w.Beneficiary.Hex(),
+		Status:        string(w.Status),
+		Confirmations: w.Confirmations,
+		Error:         w.Error,
+		StartedAt:     w.StartedAt.Format(time.RFC3339),
+		UpdatedAt:     w.UpdatedAt.Format(time.RFC3339),
+	}
+	if w.TxHash != (common.Hash{}) {
+		dto.TxHash = w.TxHash.Hex()
+	}
+	return dto
+}
+
+// SetBeneficiaryRequest request used to change identity beneficiary, earnings are settled to the new beneficiary.
+// swagger:model SetBeneficiaryRequest
+type SetBeneficiaryRequest struct {
+	// example: 0x0000000000000000000000000000000000000003
+	Beneficiary string `json:"beneficiary"`
+	// accountant to settle with, configured accountant is used if empty
+	// example: 0x0000000000000000000000000000000000000002
+	AccountantID string `json:"accountant_id,omitempty"`
+}
+
+// Validate validates fields in request
+func (r SetBeneficiaryRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if r.Beneficiary == "" {
+		errs.ForField("beneficiary").AddError("required", "Field is required")
+	} else if !common.IsHexAddress(r.Beneficiary) {
+		errs.ForField("beneficiary").AddError("invalid", "Beneficiary must be an ethereum address")
+	}
+	if r.AccountantID != "" && !common.IsHexAddress(r.AccountantID) {
+		errs.ForField("accountant_id").AddError("invalid", "Accountant ID must be an ethereum address")
+	}
+	return errs
+}
+
+// WithdrawRequest request used to settle earnings to the current beneficiary.
+// swagger:model WithdrawRequest
+type WithdrawRequest struct {
+	// accountant to settle with, configured accountant is used if empty
+	// example: 0x0000000000000000000000000000000000000002
+	AccountantID string `json:"accountant_id,omitempty"`
+}
+
+// Validate validates fields in request
+func (r WithdrawRequest) Validate() *validation.FieldErrorMap {
+	errs := validation.NewErrorMap()
+	if r.AccountantID != "" && !common.IsHexAddress(r.AccountantID) {
+		errs.ForField("accountant_id").AddError("invalid", "Accountant ID must be an ethereum address")
+	}
+	return errs
+}
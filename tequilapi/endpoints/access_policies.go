@@ -67,7 +67,7 @@ func NewAccessPoliciesEndpoint(httpClient *requests.HTTPClient, accessPolicyEndp
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (ape *accessPoliciesEndpoint) List(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
 	req, err := requests.NewGetRequest(ape.accessPolicyEndpointURL, "", nil)
 	if err != nil {
@@ -57,11 +57,11 @@ type authenticator interface {
 //   400:
 //     description: Body parsing error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   401:
 //     description: Unauthorized
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (api *authenticationAPI) Login(httpRes http.ResponseWriter, httpReq *http.Request, _ httprouter.Params) {
 	var req *contract.LoginRequest
 	var err error
@@ -108,11 +108,11 @@ func (api *authenticationAPI) Login(httpRes http.ResponseWriter, httpReq *http.R
 //   400:
 //     description: Body parsing error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   401:
 //     description: Unauthorized
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (api *authenticationAPI) ChangePassword(httpRes http.ResponseWriter, httpReq *http.Request, _ httprouter.Params) {
 	var req *contract.ChangePasswordRequest
 	var err error
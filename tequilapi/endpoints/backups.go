@@ -0,0 +1,138 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/storage/backup"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type backupManager interface {
+	Backup() (backup.Backup, error)
+	List() ([]backup.Backup, error)
+	Restore(name string) error
+}
+
+type backupsEndpoint struct {
+	backups backupManager
+}
+
+// NewBackupsEndpoint creates and returns database backups endpoint
+func NewBackupsEndpoint(backups backupManager) *backupsEndpoint {
+	return &backupsEndpoint{
+		backups: backups,
+	}
+}
+
+// swagger:operation GET /backups Backup listBackups
+// ---
+// summary: Returns database backups
+// description: Returns list of node database backups starting from the newest one
+// responses:
+//   200:
+//     description: List of backups
+//     schema:
+//       "$ref": "#/definitions/ListBackupsResponse"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *backupsEndpoint) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	backups, err := endpoint.backups.List()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewListBackupsResponse(backups), resp)
+}
+
+// swagger:operation POST /backups Backup createBackup
+// ---
+// summary: Creates database backup
+// description: Takes a consistent snapshot of the node database together with identity keys and removes the backups exceeding the rotation limit
+// responses:
+//   201:
+//     description: Backup created
+//     schema:
+//       "$ref": "#/definitions/BackupDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *backupsEndpoint) Create(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	b, err := endpoint.backups.Backup()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	resp.WriteHeader(http.StatusCreated)
+	utils.WriteAsJSON(contract.NewBackupDTO(b), resp)
+}
+
+// swagger:operation POST /backups/{name}/restore Backup restoreBackup
+// ---
+// summary: Restores database backup
+// description: Stages the backup to replace the node database, restore is completed on the next node start. Missing identity keys are restored right away
+// parameters:
+// - in: path
+//   name: name
+//   description: Name of the backup
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Restore accepted, node restart is required
+//   400:
+//     description: Invalid backup name
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   404:
+//     description: Backup not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *backupsEndpoint) Restore(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	err := endpoint.backups.Restore(params.ByName("name"))
+	switch err {
+	case nil:
+		resp.WriteHeader(http.StatusAccepted)
+	case backup.ErrInvalidBackupName:
+		utils.SendError(resp, err, http.StatusBadRequest)
+	case backup.ErrBackupNotFound:
+		utils.SendError(resp, err, http.StatusNotFound)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+// AddRoutesForBackups attaches database backup endpoints to router
+func AddRoutesForBackups(router *httprouter.Router, backups backupManager) {
+	backupsEndpoint := NewBackupsEndpoint(backups)
+	router.GET("/backups", backupsEndpoint.List)
+	router.POST("/backups", backupsEndpoint.Create)
+	router.POST("/backups/:name/restore", backupsEndpoint.Restore)
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/storage/backup"
+	"github.com/stretchr/testify/assert"
+)
+
+type backupManagerMock struct {
+	backups     []backup.Backup
+	restored    string
+	errToReturn error
+}
+
+func (m *backupManagerMock) Backup() (backup.Backup, error) {
+	if m.errToReturn != nil {
+		return backup.Backup{}, m.errToReturn
+	}
+	return m.backups[0], nil
+}
+
+func (m *backupManagerMock) List() ([]backup.Backup, error) {
+	return m.backups, m.errToReturn
+}
+
+func (m *backupManagerMock) Restore(name string) error {
+	m.restored = name
+	return m.errToReturn
+}
+
+var backupMock = backup.Backup{
+	Name:      "myst-20200701T120000Z.db",
+	CreatedAt: time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC),
+	Size:      65536,
+}
+
+func Test_BackupsEndpoint_List(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/backups", nil)
+	resp := httptest.NewRecorder()
+
+	router := httprouter.New()
+	AddRoutesForBackups(router, &backupManagerMock{backups: []backup.Backup{backupMock}})
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t,
+		`{"backups":[{"name":"myst-20200701T120000Z.db","created_at":"2020-07-01T12:00:00Z","size":65536}]}`,
+		resp.Body.String(),
+	)
+}
+
+func Test_BackupsEndpoint_ListEmpty(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/backups", nil)
+	resp := httptest.NewRecorder()
+
+	router := httprouter.New()
+	AddRoutesForBackups(router, &backupManagerMock{})
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"backups":[]}`, resp.Body.String())
+}
+
+func Test_BackupsEndpoint_Create(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/backups", nil)
+	resp := httptest.NewRecorder()
+
+	router := httprouter.New()
+	AddRoutesForBackups(router, &backupManagerMock{backups: []backup.Backup{backupMock}})
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.JSONEq(t,
+		`{"name":"myst-20200701T120000Z.db","created_at":"2020-07-01T12:00:00Z","size":65536}`,
+		resp.Body.String(),
+	)
+}
+
+func Test_BackupsEndpoint_CreateBubblesError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/backups", nil)
+	resp := httptest.NewRecorder()
+
+	router := httprouter.New()
+	AddRoutesForBackups(router, &backupManagerMock{errToReturn: errors.New("disk full")})
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"code":"internal","message":"disk full"}`, resp.Body.String())
+}
+
+func Test_BackupsEndpoint_Restore(t *testing.T) {
+	tests := []struct {
+		err          error
+		expectedCode int
+	}{
+		{nil, http.StatusAccepted},
+		{backup.ErrInvalidBackupName, http.StatusBadRequest},
+		{backup.ErrBackupNotFound, http.StatusNotFound},
+		{errors.New("disk full"), http.StatusInternalServerError},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/backups/myst-20200701T120000Z.db/restore", nil)
+		resp := httptest.NewRecorder()
+
+		manager := &backupManagerMock{errToReturn: test.err}
+		router := httprouter.New()
+		AddRoutesForBackups(router, manager)
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, test.expectedCode, resp.Code)
+		assert.Equal(t, "myst-20200701T120000Z.db", manager.restored)
+	}
+}
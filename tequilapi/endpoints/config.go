@@ -63,7 +63,7 @@ func newConfigAPI(config configProvider) *configAPI {
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (api *configAPI) GetDefaultConfig(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
 	res := configPayload{Data: api.config.GetDefaultConfig()}
 	utils.WriteAsJSON(res, writer)
@@ -82,7 +82,7 @@ func (api *configAPI) GetDefaultConfig(writer http.ResponseWriter, httpReq *http
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (api *configAPI) GetUserConfig(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
 	res := configPayload{Data: api.config.GetUserConfig()}
 	utils.WriteAsJSON(res, writer)
@@ -107,7 +107,7 @@ func (api *configAPI) GetUserConfig(writer http.ResponseWriter, httpReq *http.Re
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (api *configAPI) SetUserConfig(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
 	var req configPayload
 	err := json.NewDecoder(httpReq.Body).Decode(&req)
@@ -19,16 +19,21 @@ package endpoints
 
 import (
 	"encoding/json"
+	stdErr "errors"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/isolation"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session/pingpong"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 	"github.com/pkg/errors"
@@ -39,6 +44,12 @@ import (
 // operations, custom client error code is defined. Maybe in later times a better idea will come how to handle these situations
 const statusConnectCancelled = 499
 
+const (
+	// probeMaxDuration and probeMaxTraffic bound the test-drive session opened to measure provider before committing to it.
+	probeMaxDuration = 60 * time.Second
+	probeMaxTraffic  = 10 * 1024 * 1024
+)
+
 // ProposalGetter defines interface to fetch currently active service proposal by id
 type ProposalGetter interface {
 	GetProposal(id market.ProposalID) (*market.ServiceProposal, error)
@@ -48,22 +59,41 @@ type identityRegistry interface {
 	GetRegistrationStatus(identity.Identity) (registry.RegistrationStatus, error)
 }
 
+type accountantPicker interface {
+	Pick() (common.Address, error)
+}
+
+type countryConnector interface {
+	Connect(consumerID identity.Identity, accountantID common.Address, country, serviceType string, params connection.ConnectParams) (market.ServiceProposal, error)
+}
+
+type connectionStateProvider interface {
+	stateProvider
+	ConnectionStatisticsHistory() []connection.Statistics
+}
+
 // ConnectionEndpoint struct represents /connection resource and it's subresources
 type ConnectionEndpoint struct {
 	manager       connection.Manager
-	stateProvider stateProvider
+	stateProvider connectionStateProvider
 	//TODO connection should use concrete proposal from connection params and avoid going to marketplace
 	proposalRepository proposal.Repository
 	identityRegistry   identityRegistry
+	accountantPicker   accountantPicker
+	countryConnector   countryConnector
+	// connectionID names the connection of manager, empty for the default one
+	connectionID string
 }
 
 // NewConnectionEndpoint creates and returns connection endpoint
-func NewConnectionEndpoint(manager connection.Manager, stateProvider stateProvider, proposalRepository proposal.Repository, identityRegistry identityRegistry) *ConnectionEndpoint {
+func NewConnectionEndpoint(manager connection.Manager, stateProvider connectionStateProvider, proposalRepository proposal.Repository, identityRegistry identityRegistry, accountantPicker accountantPicker, countryConnector countryConnector) *ConnectionEndpoint {
 	return &ConnectionEndpoint{
 		manager:            manager,
 		stateProvider:      stateProvider,
 		proposalRepository: proposalRepository,
 		identityRegistry:   identityRegistry,
+		accountantPicker:   accountantPicker,
+		countryConnector:   countryConnector,
 	}
 }
 
@@ -80,7 +110,7 @@ func NewConnectionEndpoint(manager connection.Manager, stateProvider stateProvid
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (ce *ConnectionEndpoint) Status(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	status := ce.manager.Status()
 	statusResponse := contract.NewConnectionStatusDTO(status)
@@ -106,26 +136,263 @@ func (ce *ConnectionEndpoint) Status(resp http.ResponseWriter, _ *http.Request,
 //   400:
 //     description: Bad request
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
+//   403:
+//     description: Provider service policy does not allow consumer location
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
 //   409:
 //     description: Conflict. Connection already exists
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   422:
 //     description: Parameters validation error
 //     schema:
-//       "$ref": "#/definitions/ValidationErrorDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   499:
 //     description: Connection was cancelled
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
+//   503:
+//     description: No healthy accountant available
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
-//     description: Internal server error
+//     description: Internal server error, cause of the connect failure is attached when it was diagnosed
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ConnectionErrorDTO"
 func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	ce.create(resp, req, params, func(options connection.ConnectParams, _ market.ServiceProposal) connection.ConnectParams {
+		return options
+	})
+}
+
+// Probe starts new test-drive connection
+// swagger:operation PUT /connection/probe Connection connectionProbe
+// ---
+// summary: Starts new test-drive connection
+// description: Consumer opens a short connection to provider to measure it before committing, connection is closed automatically after 60 seconds or 10MiB of traffic and costs no more than these limits are priced
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (consumer_id, provider_id, service_type) required for creating new connection
+//     schema:
+//       $ref: "#/definitions/ConnectionCreateRequestDTO"
+// responses:
+//   201:
+//     description: Connection started
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatusDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   403:
+//     description: Provider service policy does not allow consumer location
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. Connection already exists
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   499:
+//     description: Connection was cancelled
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   503:
+//     description: No healthy accountant available
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error, cause of the connect failure is attached when it was diagnosed
+//     schema:
+//       "$ref": "#/definitions/ConnectionErrorDTO"
+func (ce *ConnectionEndpoint) Probe(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	ce.create(resp, req, params, probeParams)
+}
+
+// create connects to the requested proposal, connect params given in request are adjusted by the given function.
+func (ce *ConnectionEndpoint) create(resp http.ResponseWriter, req *http.Request, params httprouter.Params, adjust func(connection.ConnectParams, market.ServiceProposal) connection.ConnectParams) {
+	cr, proposal, ok := ce.parseCreateRequest(resp, req)
+	if !ok {
+		return
+	}
+
+	ce.connect(resp, req, params, cr.AccountantID, func(accountant common.Address) error {
+		return ce.manager.Connect(identity.FromAddress(cr.ConsumerID), accountant, *proposal, adjust(getConnectOptions(cr.ConnectOptions), *proposal))
+	})
+}
+
+// parseCreateRequest reads connection request and looks up the requested proposal,
+// responds with an error and returns false if the consumer can't connect to it.
+func (ce *ConnectionEndpoint) parseCreateRequest(resp http.ResponseWriter, req *http.Request) (*contract.ConnectionCreateRequest, *market.ServiceProposal, bool) {
 	cr, err := toConnectionRequest(req)
 	if err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return nil, nil, false
+	}
+
+	if errorMap := cr.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return nil, nil, false
+	}
+
+	// TODO Validate for account existence
+	if !ce.checkRegistration(resp, cr.ConsumerID) {
+		return nil, nil, false
+	}
+
+	proposal, err := ce.proposalRepository.Proposal(market.ProposalID{
+		ProviderID:  cr.ProviderID,
+		ServiceType: cr.ServiceType,
+		ID:          cr.ProposalID,
+	})
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return nil, nil, false
+	}
+	if proposal == nil {
+		utils.SendError(resp, errors.New("provider has no service proposals"), http.StatusBadRequest)
+		return nil, nil, false
+	}
+	return cr, proposal, true
+}
+
+// CreateFromInvite starts new connection to the unlisted provider
+// swagger:operation POST /connection/invite Connection connectionCreateFromInvite
+// ---
+// summary: Starts new connection using the invite code
+// description: Consumer opens connection to the provider which shared its proposal using the invite code instead of discovery
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (consumer_id, accountant_id, invite_code) required for creating new connection
+//     schema:
+//       $ref: "#/definitions/ConnectionInviteRequestDTO"
+// responses:
+//   201:
+//     description: Connection started
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatusDTO"
+//   400:
+//     description: Bad request or invalid invite code
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   403:
+//     description: Provider service policy does not allow consumer location
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. Connection already exists
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   499:
+//     description: Connection was cancelled
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   503:
+//     description: No healthy accountant available
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error, cause of the connect failure is attached when it was diagnosed
+//     schema:
+//       "$ref": "#/definitions/ConnectionErrorDTO"
+func (ce *ConnectionEndpoint) CreateFromInvite(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	ir := contract.ConnectionInviteRequest{
+		ConnectOptions: contract.ConnectOptions{
+			DNS: connection.DNSOptionAuto,
+		},
+	}
+	if err := json.NewDecoder(req.Body).Decode(&ir); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := ir.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	proposal, err := market.ParseInviteCode(ir.InviteCode)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	if !ce.checkRegistration(resp, ir.ConsumerID) {
+		return
+	}
+
+	ce.connect(resp, req, params, ir.AccountantID, func(accountant common.Address) error {
+		return ce.manager.Connect(identity.FromAddress(ir.ConsumerID), accountant, proposal, getConnectOptions(ir.ConnectOptions))
+	})
+}
+
+// CreateForCountry starts new connection to the best available provider of the country
+// swagger:operation POST /connection/country Connection connectionCreateForCountry
+// ---
+// summary: Starts new connection to the country
+// description: Consumer tries ranked proposals of the country one by one until connection to one of them succeeds. Each attempt is reported by the connection.attempt event.
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (consumer_id, country, service_type) required for creating new connection
+//     schema:
+//       $ref: "#/definitions/ConnectionCountryRequestDTO"
+// responses:
+//   201:
+//     description: Connection started
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatusDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   404:
+//     description: No proposals found in the country
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   403:
+//     description: Provider service policy does not allow consumer location
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. Connection already exists
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   499:
+//     description: Connection was cancelled
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   503:
+//     description: No healthy accountant available
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error, cause of the connect failure is attached when it was diagnosed
+//     schema:
+//       "$ref": "#/definitions/ConnectionErrorDTO"
+func (ce *ConnectionEndpoint) CreateForCountry(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	cr := contract.ConnectionCountryRequest{
+		ServiceType: "openvpn",
+		ConnectOptions: contract.ConnectOptions{
+			DNS: connection.DNSOptionAuto,
+		},
+	}
+	if err := json.NewDecoder(req.Body).Decode(&cr); err != nil {
 		utils.SendError(resp, err, http.StatusBadRequest)
 		return
 	}
@@ -135,55 +402,82 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 		return
 	}
 
-	// TODO Validate for account existence
-	consumerID := identity.FromAddress(cr.ConsumerID)
-	status, err := ce.identityRegistry.GetRegistrationStatus(consumerID)
+	if !ce.checkRegistration(resp, cr.ConsumerID) {
+		return
+	}
+
+	ce.connect(resp, req, params, cr.AccountantID, func(accountant common.Address) error {
+		_, err := ce.countryConnector.Connect(identity.FromAddress(cr.ConsumerID), accountant, cr.Country, cr.ServiceType, getConnectOptions(cr.ConnectOptions))
+		return err
+	})
+}
+
+// checkRegistration responds with an error and returns false if the consumer can't connect yet.
+func (ce *ConnectionEndpoint) checkRegistration(resp http.ResponseWriter, consumerID string) bool {
+	status, err := ce.identityRegistry.GetRegistrationStatus(identity.FromAddress(consumerID))
 	if err != nil {
 		log.Error().Err(err).Stack().Msg("could not check registration status")
 		utils.SendError(resp, err, http.StatusInternalServerError)
-		return
+		return false
 	}
 	switch status {
 	case registry.Unregistered, registry.RegistrationError:
-		log.Warn().Msgf("identity %q is not registered, aborting...", cr.ConsumerID)
-		utils.SendError(resp, fmt.Errorf("identity %q is not registered. Please register the identity first", cr.ConsumerID), http.StatusExpectationFailed)
-		return
+		log.Warn().Msgf("identity %q is not registered, aborting...", consumerID)
+		utils.SendError(resp, fmt.Errorf("identity %q is not registered. Please register the identity first", consumerID), http.StatusExpectationFailed)
+		return false
 	case registry.InProgress:
-		log.Info().Msgf("identity %q registration is in progress, continuing...", cr.ConsumerID)
+		log.Info().Msgf("identity %q registration is in progress, continuing...", consumerID)
 	default:
-		log.Info().Msgf("identity %q is registered, continuing...", cr.ConsumerID)
+		log.Info().Msgf("identity %q is registered, continuing...", consumerID)
 	}
+	return true
+}
 
-	// TODO Pass proposal ID directly in request
-	proposal, err := ce.proposalRepository.Proposal(market.ProposalID{
-		ProviderID:  cr.ProviderID,
-		ServiceType: cr.ServiceType,
-	})
-	if err != nil {
-		utils.SendError(resp, err, http.StatusInternalServerError)
+// connect picks the accountant if not given and responds with the result of the given connect function.
+func (ce *ConnectionEndpoint) connect(resp http.ResponseWriter, req *http.Request, params httprouter.Params, accountantID string, connect func(accountant common.Address) error) {
+	if !ce.tryConnect(resp, accountantID, connect) {
 		return
 	}
-	if proposal == nil {
-		utils.SendError(resp, errors.New("provider has no service proposals"), http.StatusBadRequest)
-		return
+	resp.WriteHeader(http.StatusCreated)
+	ce.Status(resp, req, params)
+}
+
+// tryConnect picks the accountant if not given and calls the given connect function,
+// responds with an error and returns false if connection failed.
+func (ce *ConnectionEndpoint) tryConnect(resp http.ResponseWriter, accountantID string, connect func(accountant common.Address) error) bool {
+	accountant := common.HexToAddress(accountantID)
+	if accountantID == "" {
+		picked, err := ce.accountantPicker.Pick()
+		if err != nil {
+			utils.SendError(resp, err, http.StatusServiceUnavailable)
+			return false
+		}
+		accountant = picked
 	}
 
-	err = ce.manager.Connect(consumerID, common.HexToAddress(cr.AccountantID), *proposal, getConnectOptions(cr))
+	err := connect(accountant)
 
+	if stdErr.Is(err, connection.ErrSessionPolicyDenied) {
+		utils.SendError(resp, err, http.StatusForbidden)
+		return false
+	}
 	if err != nil {
 		switch err {
-		case connection.ErrAlreadyExists:
+		case connection.ErrNoProposals:
+			utils.SendError(resp, err, http.StatusNotFound)
+		case connection.ErrAlreadyExists, isolation.ErrTooManyConnections:
 			utils.SendError(resp, err, http.StatusConflict)
 		case connection.ErrConnectionCancelled:
 			utils.SendError(resp, err, statusConnectCancelled)
+		case isolation.ErrNotSupported:
+			utils.SendError(resp, err, http.StatusNotImplemented)
 		default:
 			log.Error().Err(err).Msg("")
-			utils.SendError(resp, err, http.StatusInternalServerError)
+			utils.SendErrorBody(resp, contract.NewConnectionErrorDTO(err), http.StatusInternalServerError)
 		}
-		return
+		return false
 	}
-	resp.WriteHeader(http.StatusCreated)
-	ce.Status(resp, req, params)
+	return true
 }
 
 // Kill stops connection
@@ -197,11 +491,11 @@ func (ce *ConnectionEndpoint) Create(resp http.ResponseWriter, req *http.Request
 //   409:
 //     description: Conflict. No connection exists
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (ce *ConnectionEndpoint) Kill(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
 	err := ce.manager.Disconnect()
 	if err != nil {
@@ -229,22 +523,175 @@ func (ce *ConnectionEndpoint) Kill(resp http.ResponseWriter, req *http.Request,
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (ce *ConnectionEndpoint) GetStatistics(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	connection := ce.stateProvider.GetState().Connection
+	connection := ce.connectionState()
 	response := contract.NewConnectionStatisticsDTO(connection.Session, connection.Statistics, connection.Throughput, connection.Invoice)
 
 	utils.WriteAsJSON(response, writer)
 }
 
+// GetTrafficBreakdown returns traffic of current connection split per destination
+// swagger:operation GET /connection/traffic-breakdown Connection connectionTrafficBreakdown
+// ---
+// summary: Returns connection traffic breakdown
+// description: Returns tunneled traffic of current connection split per destination category and host, counted locally. Lists are empty unless connection was made with traffic breakdown enabled.
+// responses:
+//   200:
+//     description: Connection traffic breakdown
+//     schema:
+//       "$ref": "#/definitions/TrafficBreakdownDTO"
+func (ce *ConnectionEndpoint) GetTrafficBreakdown(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	response := contract.NewTrafficBreakdownDTO(ce.connectionState().Traffic)
+	utils.WriteAsJSON(response, writer)
+}
+
+// GetStatisticsHistory returns recent statistics samples of current connection
+// swagger:operation GET /connection/statistics/history Connection connectionStatisticsHistory
+// ---
+// summary: Returns connection statistics history
+// description: Returns statistics samples of current connection for the last 10 minutes, ordered from the oldest
+// responses:
+//   200:
+//     description: Connection statistics history
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatisticsHistoryDTO"
+func (ce *ConnectionEndpoint) GetStatisticsHistory(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	response := contract.NewConnectionStatisticsHistoryDTO(ce.stateProvider.ConnectionStatisticsHistory())
+	utils.WriteAsJSON(response, writer)
+}
+
+// SpeedTest measures speed of current connection
+// swagger:operation POST /connection/speedtest Connection connectionSpeedTest
+// ---
+// summary: Measures connection speed
+// description: Measures latency, download and upload speed of current connection through the active session
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (target) required for measuring connection speed
+//     schema:
+//       $ref: "#/definitions/ConnectionSpeedTestRequestDTO"
+// responses:
+//   200:
+//     description: Connection speed
+//     schema:
+//       "$ref": "#/definitions/ConnectionSpeedTestDTO"
+//   400:
+//     description: Provider does not allow speed tests
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. No connection exists
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (ce *ConnectionEndpoint) SpeedTest(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	sr := contract.ConnectionSpeedTestRequest{Target: string(connection.SpeedTestTargetPublic)}
+	if req.ContentLength > 0 {
+		if err := json.NewDecoder(req.Body).Decode(&sr); err != nil {
+			utils.SendError(resp, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	if errorMap := sr.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	result, err := ce.manager.SpeedTest(req.Context(), connection.SpeedTestTarget(sr.Target))
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection:
+			utils.SendError(resp, err, http.StatusConflict)
+		case connection.ErrSpeedTestNotAllowed:
+			utils.SendError(resp, err, http.StatusBadRequest)
+		default:
+			log.Error().Err(err).Msg("Connection speed test failed")
+			utils.SendError(resp, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewConnectionSpeedTestDTO(result), resp)
+}
+
+// ExportProfile exports current session as a standalone profile
+// swagger:operation GET /connection/profile Connection exportConnectionProfile
+// ---
+// summary: Exports connection profile
+// description: Exports current session as a standalone OpenVPN profile (.ovpn), so that external devices (e.g. routers) can connect to provider using the negotiated session parameters
+// produces:
+//   - application/x-openvpn-profile
+// responses:
+//   200:
+//     description: Session profile
+//   409:
+//     description: Conflict. No connection exists or connection does not support profile export
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (ce *ConnectionEndpoint) ExportProfile(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	profile, err := ce.manager.ExportProfile()
+	if err != nil {
+		switch err {
+		case connection.ErrNoConnection, connection.ErrProfileExportNotSupported:
+			utils.SendError(resp, err, http.StatusConflict)
+		default:
+			log.Error().Err(err).Msg("Connection profile export failed")
+			utils.SendError(resp, err, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	writeProfile(resp, string(ce.manager.Status().SessionID), profile)
+}
+
+// connectionState returns state of the connection endpoint manages.
+func (ce *ConnectionEndpoint) connectionState() stateEvent.Connection {
+	state := ce.stateProvider.GetState()
+	if ce.connectionID == "" {
+		return state.Connection
+	}
+	return state.Connections[ce.connectionID]
+}
+
+// writeProfile sends OpenVPN profile as a file attachment with given name.
+func writeProfile(resp http.ResponseWriter, name string, profile []byte) {
+	resp.Header().Set("Content-Type", "application/x-openvpn-profile")
+	resp.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name+".ovpn"))
+	if _, err := resp.Write(profile); err != nil {
+		log.Error().Err(err).Msg("Failed to write profile")
+	}
+}
+
 // AddRoutesForConnection adds connections routes to given router
 func AddRoutesForConnection(router *httprouter.Router, manager connection.Manager,
-	stateProvider stateProvider, proposalRepository proposal.Repository, identityRegistry identityRegistry) {
-	connectionEndpoint := NewConnectionEndpoint(manager, stateProvider, proposalRepository, identityRegistry)
+	stateProvider connectionStateProvider, proposalRepository proposal.Repository, identityRegistry identityRegistry, accountantPicker accountantPicker,
+	countryConnector countryConnector) {
+	connectionEndpoint := NewConnectionEndpoint(manager, stateProvider, proposalRepository, identityRegistry, accountantPicker, countryConnector)
 	router.GET("/connection", connectionEndpoint.Status)
 	router.PUT("/connection", connectionEndpoint.Create)
+	router.PUT("/connection/probe", connectionEndpoint.Probe)
+	router.POST("/connection/invite", connectionEndpoint.CreateFromInvite)
+	router.POST("/connection/country", connectionEndpoint.CreateForCountry)
 	router.DELETE("/connection", connectionEndpoint.Kill)
 	router.GET("/connection/statistics", connectionEndpoint.GetStatistics)
+	router.GET("/connection/statistics/history", connectionEndpoint.GetStatisticsHistory)
+	router.GET("/connection/traffic-breakdown", connectionEndpoint.GetTrafficBreakdown)
+	router.POST("/connection/speedtest", connectionEndpoint.SpeedTest)
+	router.GET("/connection/profile", connectionEndpoint.ExportProfile)
 }
 
 func toConnectionRequest(req *http.Request) (*contract.ConnectionCreateRequest, error) {
@@ -261,14 +708,35 @@ func toConnectionRequest(req *http.Request) (*contract.ConnectionCreateRequest,
 	return &connectionRequest, nil
 }
 
-func getConnectOptions(cr *contract.ConnectionCreateRequest) connection.ConnectParams {
+func getConnectOptions(options contract.ConnectOptions) connection.ConnectParams {
 	dns := connection.DNSOptionAuto
-	if cr.ConnectOptions.DNS != "" {
-		dns = cr.ConnectOptions.DNS
+	if options.DNS != "" {
+		dns = options.DNS
 	}
 
 	return connection.ConnectParams{
-		DisableKillSwitch: cr.ConnectOptions.DisableKillSwitch,
+		DisableKillSwitch: options.DisableKillSwitch,
 		DNS:               dns,
+		MaxDuration:       time.Duration(options.MaxDuration) * time.Second,
+		MaxCost:           options.MaxCost,
+		MaxTraffic:        options.MaxTraffic,
+		AdBlock:           options.AdBlock,
+		TrafficBreakdown:  options.TrafficBreakdown,
+	}
+}
+
+// probeParams caps the connection to a short test-drive of provider, smaller limits given by the user are kept.
+// The cost guard is set to the most the probe can cost within its duration and traffic limits.
+func probeParams(params connection.ConnectParams, proposal market.ServiceProposal) connection.ConnectParams {
+	if params.MaxDuration <= 0 || params.MaxDuration > probeMaxDuration {
+		params.MaxDuration = probeMaxDuration
+	}
+	if params.MaxTraffic == 0 || params.MaxTraffic > probeMaxTraffic {
+		params.MaxTraffic = probeMaxTraffic
+	}
+	maxCost := pingpong.CalculatePaymentAmount(params.MaxDuration, pingpong.DataTransferred{Down: params.MaxTraffic}, proposal.PaymentMethod)
+	if params.MaxCost == 0 || params.MaxCost > maxCost {
+		params.MaxCost = maxCost
 	}
+	return params
 }
@@ -73,11 +73,11 @@ func NewConnectionLocationEndpoint(
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   503:
 //     description: Service unavailable
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (le *ConnectionLocationEndpoint) GetConnectionIP(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	ipAddress, err := le.ipResolver.GetPublicIP()
 	if err != nil {
@@ -104,7 +104,7 @@ func (le *ConnectionLocationEndpoint) GetConnectionIP(writer http.ResponseWriter
 //   503:
 //     description: Service unavailable
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (le *ConnectionLocationEndpoint) GetConnectionLocation(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	currentLocation, err := le.locationResolver.DetectLocation()
 	if err != nil {
@@ -128,7 +128,7 @@ func (le *ConnectionLocationEndpoint) GetConnectionLocation(writer http.Response
 //   503:
 //     description: Service unavailable
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (le *ConnectionLocationEndpoint) GetOriginLocation(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	originLocation, err := le.locationOriginResolver.GetOrigin()
 	if err != nil {
@@ -156,6 +156,7 @@ func TestGetIPEndpointReturnsErrorWhenIPDetectionFails(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code":"service_unavailable",
 			"message": "fake error"
 		}`,
 		resp.Body.String(),
@@ -0,0 +1,267 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type connectionManagers interface {
+	Manager(id string) (connection.Manager, error)
+	Lookup(id string) (connection.Manager, bool)
+	Statuses() []connection.Status
+}
+
+type namedConnectionAPI struct {
+	managers           connectionManagers
+	stateProvider      connectionStateProvider
+	proposalRepository proposal.Repository
+	identityRegistry   identityRegistry
+	accountantPicker   accountantPicker
+}
+
+// List returns statuses of simultaneous connections
+// swagger:operation GET /connections Connection connectionList
+// ---
+// summary: Returns statuses of simultaneous connections
+// description: Returns status of the default connection and all named connections which are not idle
+// responses:
+//   200:
+//     description: List of connection statuses
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatusListDTO"
+func (api *namedConnectionAPI) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewConnectionStatusListDTO(api.managers.Statuses()), resp)
+}
+
+// Status returns status of named connection
+// swagger:operation GET /connections/{id} Connection namedConnectionStatus
+// ---
+// summary: Returns status of named connection
+// description: Returns status of connection with given ID, "default" stands for the connection managed under /connection
+// parameters:
+//   - name: id
+//     in: path
+//     description: connection ID
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Status
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatusDTO"
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *namedConnectionAPI) Status(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	if ce := api.lookup(resp, params); ce != nil {
+		ce.Status(resp, req, params)
+	}
+}
+
+// Create starts new named connection
+// swagger:operation PUT /connections/{id} Connection namedConnectionCreate
+// ---
+// summary: Starts new named connection
+// description: Consumer opens connection with given ID next to the main one, only traffic of its local SOCKS5/HTTP proxy is routed through it (Linux only)
+// parameters:
+//   - name: id
+//     in: path
+//     description: connection ID, up to 32 letters, digits, dashes or underscores
+//     type: string
+//     required: true
+//   - in: body
+//     name: body
+//     description: Parameters in body (consumer_id, provider_id, service_type) required for creating new connection
+//     schema:
+//       $ref: "#/definitions/ConnectionCreateRequestDTO"
+// responses:
+//   201:
+//     description: Connection started
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatusDTO"
+//   400:
+//     description: Bad request or invalid connection ID
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   403:
+//     description: Provider service policy does not allow consumer location
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. Connection already exists or maximum number of named connections reached
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   499:
+//     description: Connection was cancelled
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   501:
+//     description: Named connections are not supported on this OS
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   503:
+//     description: No healthy accountant available
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error, cause of the connect failure is attached when it was diagnosed
+//     schema:
+//       "$ref": "#/definitions/ConnectionErrorDTO"
+func (api *namedConnectionAPI) Create(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	manager, err := api.managers.Manager(params.ByName("id"))
+	if err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	api.endpoint(manager, params).Create(resp, req, params)
+}
+
+// Kill stops named connection
+// swagger:operation DELETE /connections/{id} Connection namedConnectionCancel
+// ---
+// summary: Stops named connection
+// description: Stops connection with given ID along with its local proxy
+// parameters:
+//   - name: id
+//     in: path
+//     description: connection ID
+//     type: string
+//     required: true
+// responses:
+//   202:
+//     description: Connection Stopped
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. No connection exists
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *namedConnectionAPI) Kill(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	if ce := api.lookup(resp, params); ce != nil {
+		ce.Kill(resp, req, params)
+	}
+}
+
+// GetStatistics returns statistics of named connection
+// swagger:operation GET /connections/{id}/statistics Connection namedConnectionStatistics
+// ---
+// summary: Returns statistics of named connection
+// description: Returns statistics of connection with given ID
+// parameters:
+//   - name: id
+//     in: path
+//     description: connection ID
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Connection statistics
+//     schema:
+//       "$ref": "#/definitions/ConnectionStatisticsDTO"
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *namedConnectionAPI) GetStatistics(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	if ce := api.lookup(resp, params); ce != nil {
+		ce.GetStatistics(resp, req, params)
+	}
+}
+
+// GetTrafficBreakdown returns traffic of named connection split per destination
+// swagger:operation GET /connections/{id}/traffic-breakdown Connection namedConnectionTrafficBreakdown
+// ---
+// summary: Returns traffic breakdown of named connection
+// description: Returns tunneled traffic of connection with given ID split per destination category and host
+// parameters:
+//   - name: id
+//     in: path
+//     description: connection ID
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Connection traffic breakdown
+//     schema:
+//       "$ref": "#/definitions/TrafficBreakdownDTO"
+//   404:
+//     description: Connection not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *namedConnectionAPI) GetTrafficBreakdown(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	if ce := api.lookup(resp, params); ce != nil {
+		ce.GetTrafficBreakdown(resp, req, params)
+	}
+}
+
+// lookup returns endpoint of existing connection given by path, responds with an error and returns nil if it is not found.
+func (api *namedConnectionAPI) lookup(resp http.ResponseWriter, params httprouter.Params) *ConnectionEndpoint {
+	manager, ok := api.managers.Lookup(params.ByName("id"))
+	if !ok {
+		utils.SendError(resp, errors.New("connection not found"), http.StatusNotFound)
+		return nil
+	}
+	return api.endpoint(manager, params)
+}
+
+func (api *namedConnectionAPI) endpoint(manager connection.Manager, params httprouter.Params) *ConnectionEndpoint {
+	ce := NewConnectionEndpoint(manager, api.stateProvider, api.proposalRepository, api.identityRegistry, api.accountantPicker, nil)
+	if id := params.ByName("id"); id != connection.DefaultConnectionID {
+		ce.connectionID = id
+	}
+	return ce
+}
+
+// AddRoutesForNamedConnection adds routes of simultaneous named connections to given router,
+// "default" connection is the same one managed under /connection
+func AddRoutesForNamedConnection(router *httprouter.Router, managers connectionManagers, stateProvider connectionStateProvider,
+	proposalRepository proposal.Repository, identityRegistry identityRegistry, accountantPicker accountantPicker) {
+	api := &namedConnectionAPI{
+		managers:           managers,
+		stateProvider:      stateProvider,
+		proposalRepository: proposalRepository,
+		identityRegistry:   identityRegistry,
+		accountantPicker:   accountantPicker,
+	}
+
+	router.GET("/connections", api.List)
+	router.GET("/connections/:id", api.Status)
+	router.PUT("/connections/:id", api.Create)
+	router.DELETE("/connections/:id", api.Kill)
+	router.GET("/connections/:id/statistics", api.GetStatistics)
+	router.GET("/connections/:id/traffic-breakdown", api.GetTrafficBreakdown)
+}
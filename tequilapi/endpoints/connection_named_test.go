@@ -0,0 +1,197 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/isolation"
+	"github.com/mysteriumnetwork/node/core/connection"
+	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConnectionManagers struct {
+	main     *mockConnectionManager
+	managers map[string]*mockConnectionManager
+}
+
+func (m *mockConnectionManagers) Manager(id string) (connection.Manager, error) {
+	if id == connection.DefaultConnectionID {
+		return m.main, nil
+	}
+	if strings.Contains(id, " ") {
+		return nil, isolation.ErrInvalidConnectionID
+	}
+	manager, ok := m.managers[id]
+	if !ok {
+		manager = &mockConnectionManager{onStatusReturn: connection.Status{ConnectionID: id, State: connection.Connected}}
+		m.managers[id] = manager
+	}
+	return manager, nil
+}
+
+func (m *mockConnectionManagers) Lookup(id string) (connection.Manager, bool) {
+	if id == connection.DefaultConnectionID {
+		return m.main, true
+	}
+	manager, ok := m.managers[id]
+	return manager, ok
+}
+
+func (m *mockConnectionManagers) Statuses() []connection.Status {
+	statuses := []connection.Status{m.main.Status()}
+	for _, manager := range m.managers {
+		statuses = append(statuses, manager.Status())
+	}
+	return statuses
+}
+
+type namedConnectionFixture struct {
+	router   *httprouter.Router
+	main     *mockConnectionManager
+	managers map[string]*mockConnectionManager
+	state    *mockStateProvider
+}
+
+func newNamedConnectionFixture() *namedConnectionFixture {
+	f := &namedConnectionFixture{
+		main:     &mockConnectionManager{onStatusReturn: connection.Status{ConnectionID: connection.DefaultConnectionID, State: connection.NotConnected}},
+		managers: make(map[string]*mockConnectionManager),
+		state:    &mockStateProvider{},
+	}
+	managers := &mockConnectionManagers{main: f.main, managers: f.managers}
+	f.router = httprouter.New()
+	AddRoutesForNamedConnection(f.router, managers, f.state, mockRepositoryWithProposal("required-node", "wireguard"), mockIdentityRegistryInstance, &mockAccountantPicker{})
+	return f
+}
+
+func (f *namedConnectionFixture) serve(method, path, body string) *httptest.ResponseRecorder {
+	resp := httptest.NewRecorder()
+	f.router.ServeHTTP(resp, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return resp
+}
+
+func TestNamedConnectionCreateStartsConnectionOfGivenID(t *testing.T) {
+	f := newNamedConnectionFixture()
+
+	resp := f.serve(http.MethodPut, "/connections/work", `{"consumer_id": "my-identity", "provider_id": "required-node", "service_type": "wireguard"}`)
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.JSONEq(t, `{"connection_id": "work", "status": "Connected"}`, resp.Body.String())
+	assert.Equal(t, identity.FromAddress("my-identity"), f.managers["work"].requestedConsumerID)
+	assert.Equal(t, identity.Identity{}, f.main.requestedConsumerID)
+}
+
+func TestNamedConnectionCreateRejectsInvalidID(t *testing.T) {
+	f := newNamedConnectionFixture()
+
+	resp := f.serve(http.MethodPut, "/connections/with%20space", `{"consumer_id": "my-identity", "provider_id": "required-node"}`)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Empty(t, f.managers)
+}
+
+func TestNamedConnectionDefaultIsTheMainConnection(t *testing.T) {
+	f := newNamedConnectionFixture()
+
+	resp := f.serve(http.MethodGet, "/connections/default", "")
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"connection_id": "default", "status": "NotConnected"}`, resp.Body.String())
+
+	resp = f.serve(http.MethodDelete, "/connections/default", "")
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.Equal(t, 1, f.main.disconnectCount)
+}
+
+func TestNamedConnectionList(t *testing.T) {
+	f := newNamedConnectionFixture()
+	f.serve(http.MethodPut, "/connections/work", `{"consumer_id": "my-identity", "provider_id": "required-node"}`)
+
+	resp := f.serve(http.MethodGet, "/connections", "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"connections": [
+		{"connection_id": "default", "status": "NotConnected"},
+		{"connection_id": "work", "status": "Connected"}
+	]}`, resp.Body.String())
+}
+
+func TestNamedConnectionUnknownIsNotFound(t *testing.T) {
+	f := newNamedConnectionFixture()
+
+	assert.Equal(t, http.StatusNotFound, f.serve(http.MethodGet, "/connections/work", "").Code)
+	assert.Equal(t, http.StatusNotFound, f.serve(http.MethodDelete, "/connections/work", "").Code)
+	assert.Equal(t, http.StatusNotFound, f.serve(http.MethodGet, "/connections/work/statistics", "").Code)
+	assert.Empty(t, f.managers)
+}
+
+func TestNamedConnectionStatisticsAreTakenFromItsState(t *testing.T) {
+	f := newNamedConnectionFixture()
+	f.serve(http.MethodPut, "/connections/work", `{"consumer_id": "my-identity", "provider_id": "required-node"}`)
+	f.state.stateToReturn.Connection.Statistics = connection.Statistics{BytesSent: 100, BytesReceived: 200}
+	f.state.stateToReturn.Connections = map[string]stateEvent.Connection{
+		"work": {Statistics: connection.Statistics{BytesSent: 1, BytesReceived: 2}},
+	}
+
+	resp := f.serve(http.MethodGet, "/connections/work/statistics", "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"bytes_sent": 1,
+		"bytes_received": 2,
+		"throughput_sent": 0,
+		"throughput_received": 0,
+		"duration": 0,
+		"tokens_spent": {"amount": "0", "myst": "0.000000"}
+	}`, resp.Body.String())
+}
+
+func TestNamedConnectionCreateReportsIsolationErrors(t *testing.T) {
+	for err, status := range map[error]int{
+		isolation.ErrTooManyConnections: http.StatusConflict,
+		isolation.ErrNotSupported:       http.StatusNotImplemented,
+	} {
+		f := newNamedConnectionFixture()
+		f.managers["work"] = &mockConnectionManager{onConnectReturn: err}
+
+		resp := f.serve(http.MethodPut, "/connections/work", `{"consumer_id": "my-identity", "provider_id": "required-node"}`)
+
+		assert.Equal(t, status, resp.Code, err.Error())
+	}
+}
+
+func TestNamedConnectionStatusHasItsProxy(t *testing.T) {
+	f := newNamedConnectionFixture()
+	f.managers["work"] = &mockConnectionManager{onStatusReturn: connection.Status{
+		ConnectionID: "work",
+		State:        connection.Connected,
+		RoutingTable: 7300,
+		ProxyAddress: "127.0.0.1:41080",
+	}}
+
+	resp := f.serve(http.MethodGet, "/connections/work", "")
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"connection_id": "work", "status": "Connected", "routing_table": 7300, "proxy_address": "127.0.0.1:41080"}`, resp.Body.String())
+}
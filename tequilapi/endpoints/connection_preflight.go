@@ -0,0 +1,114 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/core/discovery/proposal"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/pkg/errors"
+)
+
+type preflightChecker interface {
+	Check(consumerID identity.Identity, proposal market.ServiceProposal, natType string) []connection.PreflightResult
+}
+
+// ConnectionPreflightEndpoint struct represents /connection/preflight resource.
+type ConnectionPreflightEndpoint struct {
+	preflight          preflightChecker
+	proposalRepository proposal.Repository
+}
+
+// NewConnectionPreflightEndpoint creates and returns connection pre-flight endpoint.
+func NewConnectionPreflightEndpoint(preflight preflightChecker, proposalRepository proposal.Repository) *ConnectionPreflightEndpoint {
+	return &ConnectionPreflightEndpoint{
+		preflight:          preflight,
+		proposalRepository: proposalRepository,
+	}
+}
+
+// Check runs connection pre-flight checks
+// swagger:operation POST /connection/preflight Connection connectionPreflight
+// ---
+// summary: Checks whether connection is likely to succeed
+// description: Checks consumer identity, balance, NAT compatibility and provider reachability without establishing a session
+// parameters:
+//   - in: body
+//     name: body
+//     description: Parameters in body (consumer_id, provider_id, service_type) of the planned connection
+//     schema:
+//       $ref: "#/definitions/ConnectionPreflightRequestDTO"
+// responses:
+//   200:
+//     description: Pre-flight check results
+//     schema:
+//       "$ref": "#/definitions/ConnectionPreflightDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (pe *ConnectionPreflightEndpoint) Check(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var pr contract.ConnectionPreflightRequest
+	if err := json.NewDecoder(req.Body).Decode(&pr); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	if errorMap := pr.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	proposal, err := pe.proposalRepository.Proposal(market.ProposalID{
+		ProviderID:  pr.ProviderID,
+		ServiceType: pr.ServiceType,
+		ID:          pr.ProposalID,
+	})
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	if proposal == nil {
+		utils.SendError(resp, errors.New("provider has no service proposals"), http.StatusBadRequest)
+		return
+	}
+
+	results := pe.preflight.Check(identity.FromAddress(pr.ConsumerID), *proposal, pr.NATType)
+	utils.WriteAsJSON(contract.NewConnectionPreflightDTO(results), resp)
+}
+
+// AddRoutesForConnectionPreflight adds connection pre-flight routes to given router
+func AddRoutesForConnectionPreflight(router *httprouter.Router, preflight preflightChecker, proposalRepository proposal.Repository) {
+	preflightEndpoint := NewConnectionPreflightEndpoint(preflight, proposalRepository)
+	router.POST("/connection/preflight", preflightEndpoint.Check)
+}
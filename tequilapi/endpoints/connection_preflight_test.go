@@ -0,0 +1,99 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/connection"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockPreflightChecker struct {
+	results    []connection.PreflightResult
+	consumerID identity.Identity
+	proposal   market.ServiceProposal
+	natType    string
+}
+
+func (m *mockPreflightChecker) Check(consumerID identity.Identity, proposal market.ServiceProposal, natType string) []connection.PreflightResult {
+	m.consumerID = consumerID
+	m.proposal = proposal
+	m.natType = natType
+	return m.results
+}
+
+func TestConnectionPreflightEndpoint_Check(t *testing.T) {
+	checker := &mockPreflightChecker{results: []connection.PreflightResult{
+		{Check: connection.PreflightIdentityUnlocked, Passed: true},
+		{Check: connection.PreflightBalance, Reason: "balance 99 is lower than proposal price 100"},
+	}}
+	router := httprouter.New()
+	AddRoutesForConnectionPreflight(router, checker, mockRepositoryWithProposal("required-node", "wireguard"))
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/connection/preflight",
+		strings.NewReader(`{"consumer_id": "my-identity", "provider_id": "required-node", "service_type": "wireguard", "nat_type": "symmetric"}`),
+	)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"passed": false,
+		"checks": [
+			{"check": "identity_unlocked", "passed": true},
+			{"check": "balance", "passed": false, "reason": "balance 99 is lower than proposal price 100"}
+		]
+	}`, resp.Body.String())
+	assert.Equal(t, identity.FromAddress("my-identity"), checker.consumerID)
+	assert.Equal(t, "required-node", checker.proposal.ProviderID)
+	assert.Equal(t, "symmetric", checker.natType)
+}
+
+func TestConnectionPreflightEndpoint_Check_ValidatesRequest(t *testing.T) {
+	endpoint := NewConnectionPreflightEndpoint(&mockPreflightChecker{}, mockRepositoryWithProposal("required-node", "wireguard"))
+
+	req := httptest.NewRequest(http.MethodPost, "/connection/preflight", strings.NewReader(`{}`))
+	resp := httptest.NewRecorder()
+	endpoint.Check(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+func TestConnectionPreflightEndpoint_Check_RequiresProposal(t *testing.T) {
+	endpoint := NewConnectionPreflightEndpoint(&mockPreflightChecker{}, &mockProposalRepository{})
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/connection/preflight",
+		strings.NewReader(`{"consumer_id": "my-identity", "provider_id": "required-node"}`),
+	)
+	resp := httptest.NewRecorder()
+	endpoint.Check(resp, req, nil)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.JSONEq(t, `{"code":"bad_request","message": "provider has no service proposals"}`, resp.Body.String())
+}
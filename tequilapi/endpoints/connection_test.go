@@ -19,6 +19,9 @@ package endpoints
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -28,11 +31,14 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/consumer/bandwidth"
+	"github.com/mysteriumnetwork/node/consumer/traffic"
 	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/payments/crypto"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
@@ -42,12 +48,17 @@ type mockConnectionManager struct {
 	onConnectReturn       error
 	onDisconnectReturn    error
 	onCheckChannelReturn  error
+	onSpeedTestReturn     error
+	requestedSpeedTest    connection.SpeedTestTarget
+	onExportProfileReturn error
+	exportedProfile       []byte
 	onStatusReturn        connection.Status
 	disconnectCount       int
 	requestedConsumerID   identity.Identity
 	requestedProvider     identity.Identity
 	requestedAccountantID common.Address
 	requestedServiceType  string
+	requestedParams       connection.ConnectParams
 }
 
 func (cm *mockConnectionManager) Connect(consumerID identity.Identity, accountantID common.Address, proposal market.ServiceProposal, options connection.ConnectParams) error {
@@ -55,9 +66,19 @@ func (cm *mockConnectionManager) Connect(consumerID identity.Identity, accountan
 	cm.requestedAccountantID = accountantID
 	cm.requestedProvider = identity.FromAddress(proposal.ProviderID)
 	cm.requestedServiceType = proposal.ServiceType
+	cm.requestedParams = options
 	return cm.onConnectReturn
 }
 
+type mockAccountantPicker struct {
+	accountantID common.Address
+	err          error
+}
+
+func (mp *mockAccountantPicker) Pick() (common.Address, error) {
+	return mp.accountantID, mp.err
+}
+
 func (cm *mockConnectionManager) Status() connection.Status {
 	return cm.onStatusReturn
 }
@@ -71,6 +92,27 @@ func (cm *mockConnectionManager) CheckChannel(context.Context) error {
 	return cm.onCheckChannelReturn
 }
 
+func (cm *mockConnectionManager) ExportProfile() ([]byte, error) {
+	return cm.exportedProfile, cm.onExportProfileReturn
+}
+
+func (cm *mockConnectionManager) Dial(network, address string) (net.Conn, error) {
+	return nil, nil
+}
+
+func (cm *mockConnectionManager) SpeedTest(_ context.Context, target connection.SpeedTestTarget) (connection.SpeedTestResult, error) {
+	cm.requestedSpeedTest = target
+	if cm.onSpeedTestReturn != nil {
+		return connection.SpeedTestResult{}, cm.onSpeedTestReturn
+	}
+	return connection.SpeedTestResult{
+		Target:   target,
+		Latency:  20 * time.Millisecond,
+		Download: datasize.BitSpeed(100 * datasize.MiB),
+		Upload:   datasize.BitSpeed(10 * datasize.MiB),
+	}, nil
+}
+
 func (cm *mockConnectionManager) Wait() error {
 	return nil
 }
@@ -99,7 +141,7 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 	fakeState.stateToReturn.Connection.Statistics = connection.Statistics{BytesSent: 1, BytesReceived: 2}
 
 	mockedProposalProvider := mockRepositoryWithProposal("node1", "noop")
-	AddRoutesForConnection(router, fakeManager, fakeState, mockedProposalProvider, mockIdentityRegistryInstance)
+	AddRoutesForConnection(router, fakeManager, fakeState, mockedProposalProvider, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 
 	tests := []struct {
 		method         string
@@ -128,7 +170,7 @@ func TestAddRoutesForConnectionAddsRoutes(t *testing.T) {
 				"throughput_received": 0,
 				"throughput_sent": 0,
 				"duration": 0,
-				"tokens_spent": 0
+				"tokens_spent": {"amount": "0", "myst": "0.000000"}
 			}`,
 		},
 	}
@@ -158,7 +200,7 @@ func TestStateIsReturnedFromStore(t *testing.T) {
 		},
 	}
 
-	connEndpoint := NewConnectionEndpoint(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance)
+	connEndpoint := NewConnectionEndpoint(manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -178,7 +220,7 @@ func TestStateIsReturnedFromStore(t *testing.T) {
 func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("a"))
 	resp := httptest.NewRecorder()
 
@@ -189,15 +231,49 @@ func TestPutReturns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "bad_request",
 			"message" : "invalid character 'a' looking for beginning of value"
 		}`,
 		resp.Body.String())
 }
 
+func TestPutWithoutAccountantPicksHealthyAccountant(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	picker := &mockAccountantPicker{accountantID: common.HexToAddress("0x3")}
+
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, mockIdentityRegistryInstance, picker, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, common.HexToAddress("0x3"), fakeManager.requestedAccountantID)
+
+	picker.err = errors.New("no healthy accountant available")
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(`{"consumer_id" : "my-identity", "provider_id" : "required-node"}`))
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusServiceUnavailable, resp.Code)
+}
+
 func TestPutReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("{}"))
 	resp := httptest.NewRecorder()
 
@@ -208,9 +284,9 @@ func TestPutReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "validation_failed",
 			"message" : "validation_error",
-			"errors" : {
-				"accountant_id" : [ {"code" : "required" , "message" : "Field is required" } ],
+			"fields" : {
 				"consumer_id" : [ { "code" : "required" , "message" : "Field is required" } ],
 				"provider_id" : [ {"code" : "required" , "message" : "Field is required" } ]
 			}
@@ -227,7 +303,7 @@ func TestPutWithValidBodyCreatesConnection(t *testing.T) {
 	fakeState.stateToReturn.Connection.Session = state
 
 	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
-	connEndpoint := NewConnectionEndpoint(&fakeManager, fakeState, proposalProvider, mockIdentityRegistryInstance)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, fakeState, proposalProvider, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -264,7 +340,7 @@ func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
 	mir := *mockIdentityRegistryInstance
 	mir.RegistrationStatus = registry.Unregistered
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, &mir)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -281,7 +357,7 @@ func TestPutUnregisteredIdentityReturnsError(t *testing.T) {
 	assert.Equal(t, http.StatusExpectationFailed, resp.Code)
 	assert.JSONEq(
 		t,
-		`{"message":"identity \"my-identity\" is not registered. Please register the identity first"}`,
+		`{"code":"precondition_failed","message":"identity \"my-identity\" is not registered. Please register the identity first"}`,
 		resp.Body.String(),
 	)
 }
@@ -293,7 +369,7 @@ func TestPutFailedRegistrationCheckReturnsError(t *testing.T) {
 	mir := *mockIdentityRegistryInstance
 	mir.RegistrationCheckError = errors.New("explosions everywhere")
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, &mir)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, &mir, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -310,7 +386,35 @@ func TestPutFailedRegistrationCheckReturnsError(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	assert.JSONEq(
 		t,
-		`{"message":"explosions everywhere"}`,
+		`{"code":"internal","message":"explosions everywhere"}`,
+		resp.Body.String(),
+	)
+}
+
+func TestPutWithDiagnosedConnectErrorReturnsCause(t *testing.T) {
+	fakeManager := mockConnectionManager{
+		onConnectReturn: &connection.DiagnosedError{Cause: connection.FailureCauseNATBlocked, Err: errors.New("could not create p2p channel")},
+	}
+
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"accountant_id" : "accountant"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(
+		t,
+		`{"code":"connection_failed","message":"could not create p2p channel","cause":"nat_blocked"}`,
 		resp.Body.String(),
 	)
 }
@@ -319,7 +423,7 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
 	mystAPI := mockRepositoryWithProposal("required-node", "noop")
-	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, mystAPI, mockIdentityRegistryInstance)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, mystAPI, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -345,7 +449,7 @@ func TestPutWithServiceTypeOverridesDefault(t *testing.T) {
 func TestDeleteCallsDisconnect(t *testing.T) {
 	fakeManager := mockConnectionManager{}
 
-	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance)
+	connEndpoint := NewConnectionEndpoint(&fakeManager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(http.MethodDelete, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
 
@@ -356,6 +460,105 @@ func TestDeleteCallsDisconnect(t *testing.T) {
 	assert.Equal(t, fakeManager.disconnectCount, 1)
 }
 
+func TestPutWithGuardOptionsPassesLimitsToManager(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"accountant_id" : "accountant",
+				"connect_options": {
+					"max_duration": 3600,
+					"max_cost": 5000,
+					"ad_block": true
+				}
+			}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.Create(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, connection.ConnectParams{
+		DNS:         connection.DNSOptionAuto,
+		MaxDuration: time.Hour,
+		MaxCost:     5000,
+		AdBlock:     true,
+	}, fakeManager.requestedParams)
+}
+
+func TestPutProbeCapsConnection(t *testing.T) {
+	proposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
+	proposalProvider.proposals[0].PaymentMethod = &mocks.PaymentMethod{
+		Rate:  market.PaymentRate{PerTime: time.Minute, PerByte: 1024 * 1024},
+		Price: money.Money{Amount: 100, Currency: money.CurrencyMyst},
+	}
+
+	for body, expected := range map[string]connection.ConnectParams{
+		`{}`: {
+			DNS:         connection.DNSOptionAuto,
+			MaxDuration: time.Minute,
+			MaxTraffic:  10 * 1024 * 1024,
+			MaxCost:     1100,
+		},
+		`{"max_duration": 30, "max_traffic": 1048576, "max_cost": 5000}`: {
+			DNS:         connection.DNSOptionAuto,
+			MaxDuration: 30 * time.Second,
+			MaxTraffic:  1024 * 1024,
+			MaxCost:     150,
+		},
+		`{"max_duration": 3600, "max_cost": 10}`: {
+			DNS:         connection.DNSOptionAuto,
+			MaxDuration: time.Minute,
+			MaxTraffic:  10 * 1024 * 1024,
+			MaxCost:     10,
+		},
+	} {
+		fakeManager := mockConnectionManager{}
+		connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, proposalProvider, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+		req := httptest.NewRequest(
+			http.MethodPut,
+			"/irrelevant",
+			strings.NewReader(fmt.Sprintf(`{"consumer_id": "my-identity", "provider_id": "required-node", "connect_options": %s}`, body)),
+		)
+		resp := httptest.NewRecorder()
+
+		connEndpoint.Probe(resp, req, httprouter.Params{})
+
+		assert.Equal(t, http.StatusCreated, resp.Code, body)
+		assert.Equal(t, expected, fakeManager.requestedParams, body)
+	}
+}
+
+func TestGetStatisticsHistoryEndpointReturnsSamples(t *testing.T) {
+	at := time.Date(2020, 6, 25, 13, 1, 21, 0, time.UTC)
+	fakeState := &mockStateProvider{
+		statisticsHistoryToReturn: []connection.Statistics{
+			{At: at, BytesSent: 100, BytesReceived: 1000},
+			{At: at.Add(2 * time.Second), BytesSent: 200, BytesReceived: 3000},
+		},
+	}
+
+	connEndpoint := NewConnectionEndpoint(&mockConnectionManager{}, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+
+	resp := httptest.NewRecorder()
+	connEndpoint.GetStatisticsHistory(resp, nil, nil)
+	assert.JSONEq(
+		t,
+		`{
+			"samples": [
+				{"at": "2020-06-25T13:01:21Z", "bytes_sent": 100, "bytes_received": 1000, "throughput_sent": 0, "throughput_received": 0},
+				{"at": "2020-06-25T13:01:23Z", "bytes_sent": 200, "bytes_received": 3000, "throughput_sent": 400, "throughput_received": 8000}
+			]
+		}`,
+		resp.Body.String(),
+	)
+}
+
 func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	fakeState := &mockStateProvider{}
 	fakeState.stateToReturn.Connection.Statistics = connection.Statistics{BytesSent: 1, BytesReceived: 2}
@@ -363,7 +566,7 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 	fakeState.stateToReturn.Connection.Invoice = crypto.Invoice{AgreementTotal: 10001}
 
 	manager := mockConnectionManager{}
-	connEndpoint := NewConnectionEndpoint(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance)
+	connEndpoint := NewConnectionEndpoint(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 
 	resp := httptest.NewRecorder()
 	connEndpoint.GetStatistics(resp, nil, nil)
@@ -375,18 +578,74 @@ func TestGetStatisticsEndpointReturnsStatistics(t *testing.T) {
 			"throughput_sent": 1000,
 			"throughput_received": 2000,
 			"duration": 0,
-			"tokens_spent": 10001
+			"tokens_spent": {"amount": "10001", "myst": "0.000100"}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestGetStatisticsEndpointReturnsDNSBlockCounters(t *testing.T) {
+	fakeState := &mockStateProvider{}
+	fakeState.stateToReturn.Connection.Statistics = connection.Statistics{BytesSent: 1, BytesReceived: 2, DNSQueries: 120, DNSBlocked: 30}
+
+	manager := mockConnectionManager{}
+	connEndpoint := NewConnectionEndpoint(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+
+	resp := httptest.NewRecorder()
+	connEndpoint.GetStatistics(resp, nil, nil)
+	assert.JSONEq(
+		t,
+		`{
+			"bytes_sent": 1,
+			"bytes_received": 2,
+			"throughput_sent": 0,
+			"throughput_received": 0,
+			"duration": 0,
+			"tokens_spent": {"amount": "0", "myst": "0.000000"},
+			"dns_queries": 120,
+			"dns_blocked": 30
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestGetTrafficBreakdownEndpointReturnsBreakdown(t *testing.T) {
+	fakeState := &mockStateProvider{}
+	fakeState.stateToReturn.Connection.Traffic = traffic.Breakdown{
+		Categories: []traffic.Usage{{Name: "video", BytesSent: 10, BytesReceived: 1000}},
+		Hosts:      []traffic.Usage{{Name: "rr1.googlevideo.com", BytesSent: 10, BytesReceived: 1000}},
+	}
+
+	manager := mockConnectionManager{}
+	connEndpoint := NewConnectionEndpoint(&manager, fakeState, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+
+	resp := httptest.NewRecorder()
+	connEndpoint.GetTrafficBreakdown(resp, nil, nil)
+	assert.JSONEq(
+		t,
+		`{
+			"categories": [{"name": "video", "bytes_sent": 10, "bytes_received": 1000}],
+			"hosts": [{"name": "rr1.googlevideo.com", "bytes_sent": 10, "bytes_received": 1000}]
 		}`,
 		resp.Body.String(),
 	)
 }
 
+func TestGetTrafficBreakdownEndpointReturnsEmptyListsWhenNotAccounted(t *testing.T) {
+	manager := mockConnectionManager{}
+	connEndpoint := NewConnectionEndpoint(&manager, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+
+	resp := httptest.NewRecorder()
+	connEndpoint.GetTrafficBreakdown(resp, nil, nil)
+	assert.JSONEq(t, `{"categories": [], "hosts": []}`, resp.Body.String())
+}
+
 func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onConnectReturn = connection.ErrAlreadyExists
 
 	mystAPI := mockRepositoryWithProposal("required-node", "openvpn")
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, mystAPI, mockIdentityRegistryInstance)
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, mystAPI, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 
 	req := httptest.NewRequest(
 		http.MethodPut,
@@ -405,17 +664,50 @@ func TestEndpointReturnsConflictStatusIfConnectionAlreadyExists(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "connection_exists",
 			"message" : "connection already exists"
 		}`,
 		resp.Body.String(),
 	)
 }
 
+func TestEndpointReturnsForbiddenStatusIfProviderPolicyDeniesConsumer(t *testing.T) {
+	manager := mockConnectionManager{}
+	manager.onConnectReturn = fmt.Errorf("consumer location is not allowed by service policy: %w", connection.ErrSessionPolicyDenied)
+
+	mystAPI := mockRepositoryWithProposal("required-node", "openvpn")
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, mystAPI, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+
+	req := httptest.NewRequest(
+		http.MethodPut,
+		"/irrelevant",
+		strings.NewReader(
+			`{
+				"consumer_id" : "my-identity",
+				"provider_id" : "required-node",
+				"accountant_id" : "accountant"
+			}`))
+	resp := httptest.NewRecorder()
+
+	connectionEndpoint.Create(resp, req, nil)
+
+	assert.Equal(t, http.StatusForbidden, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"code" : "connection_policy_denied",
+			"message" : "provider service policy does not allow consumer location",
+			"detail" : "consumer location is not allowed by service policy: provider service policy does not allow consumer location"
+		}`,
+		resp.Body.String(),
+	)
+}
+
 func TestDisconnectReturnsConflictStatusIfConnectionDoesNotExist(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onDisconnectReturn = connection.ErrNoConnection
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance)
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 
 	req := httptest.NewRequest(
 		http.MethodDelete,
@@ -430,6 +722,7 @@ func TestDisconnectReturnsConflictStatusIfConnectionDoesNotExist(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "connection_not_found",
 			"message" : "no connection exists"
 		}`,
 		resp.Body.String(),
@@ -441,7 +734,7 @@ func TestConnectReturnsConnectCancelledStatusWhenErrConnectionCancelledIsEncount
 	manager.onConnectReturn = connection.ErrConnectionCancelled
 
 	mockProposalProvider := mockRepositoryWithProposal("required-node", "openvpn")
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance)
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, mockProposalProvider, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -459,6 +752,7 @@ func TestConnectReturnsConnectCancelledStatusWhenErrConnectionCancelledIsEncount
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "connection_cancelled",
 			"message" : "connection was cancelled"
 		}`,
 		resp.Body.String(),
@@ -469,7 +763,7 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 	manager := mockConnectionManager{}
 	manager.onConnectReturn = connection.ErrConnectionCancelled
 
-	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance)
+	connectionEndpoint := NewConnectionEndpoint(&manager, nil, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
 	req := httptest.NewRequest(
 		http.MethodPut,
 		"/irrelevant",
@@ -487,10 +781,256 @@ func TestConnectReturnsErrorIfNoProposals(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "bad_request",
 			"message" : "provider has no service proposals"
 		}`,
 		resp.Body.String(),
 	)
 }
 
+func TestPostInviteCreatesConnectionToUnlistedProvider(t *testing.T) {
+	market.RegisterServiceDefinitionUnserializer("invite-test", func(*json.RawMessage) (market.ServiceDefinition, error) {
+		return TestServiceDefinition{}, nil
+	})
+	market.RegisterPaymentMethodUnserializer(mocks.DefaultPaymentMethodType, func(*json.RawMessage) (market.PaymentMethod, error) {
+		return mocks.DefaultPaymentMethod(), nil
+	})
+	market.RegisterContactUnserializer("invite-test", func(*json.RawMessage) (market.ContactDefinition, error) {
+		return nil, nil
+	})
+	code, err := market.NewInviteCode(market.ServiceProposal{
+		ServiceType:       "invite-test",
+		ServiceDefinition: TestServiceDefinition{},
+		ProviderID:        "unlisted-node",
+		PaymentMethodType: mocks.DefaultPaymentMethodType,
+		PaymentMethod:     mocks.DefaultPaymentMethod(),
+		ProviderContacts:  market.ContactList{{Type: "invite-test"}},
+	})
+	assert.NoError(t, err)
+
+	state := connection.Status{State: connection.Connected, SessionID: "1"}
+	fakeManager := mockConnectionManager{onStatusReturn: state}
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/irrelevant",
+		strings.NewReader(fmt.Sprintf(`{
+			"consumer_id" : "my-identity",
+			"accountant_id" : "accountant",
+			"invite_code" : %q
+		}`, code)))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.CreateFromInvite(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, identity.FromAddress("my-identity"), fakeManager.requestedConsumerID)
+	assert.Equal(t, common.HexToAddress("accountant"), fakeManager.requestedAccountantID)
+	assert.Equal(t, identity.FromAddress("unlisted-node"), fakeManager.requestedProvider)
+	assert.Equal(t, "invite-test", fakeManager.requestedServiceType)
+}
+
+func TestPostInviteReturnsErrorIfInviteCodeIsInvalid(t *testing.T) {
+	fakeManager := mockConnectionManager{}
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/irrelevant",
+		strings.NewReader(`{
+			"consumer_id" : "my-identity",
+			"accountant_id" : "accountant",
+			"invite_code" : "myst-invite:garbage"
+		}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.CreateFromInvite(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+	assert.Equal(t, identity.Identity{}, fakeManager.requestedConsumerID)
+}
+
+func TestPostInviteReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
+	connEndpoint := NewConnectionEndpoint(&mockConnectionManager{}, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+	req := httptest.NewRequest(http.MethodPost, "/irrelevant", strings.NewReader(`{}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.CreateFromInvite(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"code" : "validation_failed",
+			"message" : "validation_error",
+			"fields" : {
+				"consumer_id" : [ { "code" : "required" , "message" : "Field is required" } ],
+				"invite_code" : [ { "code" : "required" , "message" : "Field is required" } ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+type mockCountryConnector struct {
+	requestedConsumerID   identity.Identity
+	requestedAccountantID common.Address
+	requestedCountry      string
+	requestedServiceType  string
+	onConnectReturn       error
+}
+
+func (c *mockCountryConnector) Connect(consumerID identity.Identity, accountantID common.Address, country, serviceType string, params connection.ConnectParams) (market.ServiceProposal, error) {
+	c.requestedConsumerID = consumerID
+	c.requestedAccountantID = accountantID
+	c.requestedCountry = country
+	c.requestedServiceType = serviceType
+	return market.ServiceProposal{}, c.onConnectReturn
+}
+
+func TestPostCountryCreatesConnection(t *testing.T) {
+	state := connection.Status{State: connection.Connected, SessionID: "1"}
+	fakeManager := mockConnectionManager{onStatusReturn: state}
+	connector := &mockCountryConnector{}
+	connEndpoint := NewConnectionEndpoint(&fakeManager, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, connector)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/irrelevant",
+		strings.NewReader(`{
+			"consumer_id" : "my-identity",
+			"accountant_id" : "accountant",
+			"country" : "LT"
+		}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.CreateForCountry(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(t, identity.FromAddress("my-identity"), connector.requestedConsumerID)
+	assert.Equal(t, common.HexToAddress("accountant"), connector.requestedAccountantID)
+	assert.Equal(t, "LT", connector.requestedCountry)
+	assert.Equal(t, "openvpn", connector.requestedServiceType)
+}
+
+func TestPostCountryReturnsNotFoundWithoutProposals(t *testing.T) {
+	connector := &mockCountryConnector{onConnectReturn: connection.ErrNoProposals}
+	connEndpoint := NewConnectionEndpoint(&mockConnectionManager{}, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, connector)
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/irrelevant",
+		strings.NewReader(`{
+			"consumer_id" : "my-identity",
+			"country" : "LT",
+			"service_type" : "wireguard"
+		}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.CreateForCountry(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+	assert.Equal(t, "wireguard", connector.requestedServiceType)
+}
+
+func TestPostCountryReturns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
+	connEndpoint := NewConnectionEndpoint(&mockConnectionManager{}, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, &mockCountryConnector{})
+	req := httptest.NewRequest(http.MethodPost, "/irrelevant", strings.NewReader(`{}`))
+	resp := httptest.NewRecorder()
+
+	connEndpoint.CreateForCountry(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"code" : "validation_failed",
+			"message" : "validation_error",
+			"fields" : {
+				"consumer_id" : [ { "code" : "required" , "message" : "Field is required" } ],
+				"country" : [ { "code" : "required" , "message" : "Field is required" } ]
+			}
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestPostSpeedTestMeasuresPublicTargetByDefault(t *testing.T) {
+	fakeManager := &mockConnectionManager{}
+	connEndpoint := NewConnectionEndpoint(fakeManager, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+	req := httptest.NewRequest(http.MethodPost, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	connEndpoint.SpeedTest(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, connection.SpeedTestTargetPublic, fakeManager.requestedSpeedTest)
+	assert.JSONEq(
+		t,
+		`{
+			"target": "public",
+			"latency": 20,
+			"download": 838860800,
+			"upload": 83886080
+		}`,
+		resp.Body.String(),
+	)
+}
+
+func TestPostSpeedTestReturnsErrors(t *testing.T) {
+	for _, test := range []struct {
+		body           string
+		managerErr     error
+		expectedStatus int
+	}{
+		{`{"target": "somewhere"}`, nil, http.StatusUnprocessableEntity},
+		{`{"target": "provider"}`, connection.ErrSpeedTestNotAllowed, http.StatusBadRequest},
+		{`{"target": "provider"}`, connection.ErrNoConnection, http.StatusConflict},
+		{`{"target": "public"}`, errors.New("timeout"), http.StatusInternalServerError},
+	} {
+		fakeManager := &mockConnectionManager{onSpeedTestReturn: test.managerErr}
+		connEndpoint := NewConnectionEndpoint(fakeManager, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+		req := httptest.NewRequest(http.MethodPost, "/irrelevant", strings.NewReader(test.body))
+		resp := httptest.NewRecorder()
+
+		connEndpoint.SpeedTest(resp, req, httprouter.Params{})
+
+		assert.Equal(t, test.expectedStatus, resp.Code, test.body)
+	}
+}
+
+func TestGetProfileExportsActiveSession(t *testing.T) {
+	fakeManager := &mockConnectionManager{
+		onStatusReturn:  connection.Status{State: connection.Connected, SessionID: "my-session"},
+		exportedProfile: []byte("client\n"),
+	}
+	connEndpoint := NewConnectionEndpoint(fakeManager, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	connEndpoint.ExportProfile(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "application/x-openvpn-profile", resp.Header().Get("Content-Type"))
+	assert.Equal(t, `attachment; filename="my-session.ovpn"`, resp.Header().Get("Content-Disposition"))
+	assert.Equal(t, "client\n", resp.Body.String())
+}
+
+func TestGetProfileReturnsErrors(t *testing.T) {
+	for _, test := range []struct {
+		managerErr     error
+		expectedStatus int
+	}{
+		{connection.ErrNoConnection, http.StatusConflict},
+		{connection.ErrProfileExportNotSupported, http.StatusConflict},
+		{errors.New("signing failed"), http.StatusInternalServerError},
+	} {
+		fakeManager := &mockConnectionManager{onExportProfileReturn: test.managerErr}
+		connEndpoint := NewConnectionEndpoint(fakeManager, &mockStateProvider{}, &mockProposalRepository{}, mockIdentityRegistryInstance, &mockAccountantPicker{}, nil)
+		req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+		resp := httptest.NewRecorder()
+
+		connEndpoint.ExportProfile(resp, req, httprouter.Params{})
+
+		assert.Equal(t, test.expectedStatus, resp.Code, test.managerErr.Error())
+	}
+}
+
 var mockIdentityRegistryInstance = &registry.FakeRegistry{RegistrationStatus: registry.RegisteredConsumer}
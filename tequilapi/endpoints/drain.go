@@ -0,0 +1,68 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/rs/zerolog/log"
+)
+
+type sessionDrainer interface {
+	Draining() bool
+	Drain() error
+}
+
+type drainEndpoint struct {
+	drainer sessionDrainer
+}
+
+// swagger:operation POST /node/drain Node nodeDrain
+// ---
+// summary: Drains provider sessions
+// description: Stops accepting new sessions, notifies connected consumers, waits for their sessions to end and payments to settle, then stops all services
+// responses:
+//   202:
+//     description: Drain started
+//   409:
+//     description: Drain already in progress
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *drainEndpoint) Drain(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	if endpoint.drainer.Draining() {
+		utils.SendError(resp, service.ErrDraining, http.StatusConflict)
+		return
+	}
+
+	log.Info().Msg("Provider session drain requested")
+	go func() {
+		if err := endpoint.drainer.Drain(); err != nil {
+			log.Error().Err(err).Msg("Provider session drain failed")
+		}
+	}()
+	resp.WriteHeader(http.StatusAccepted)
+}
+
+// AddRoutesForDrain attaches provider session drain endpoint to router
+func AddRoutesForDrain(router *httprouter.Router, drainer sessionDrainer) {
+	endpoint := &drainEndpoint{drainer: drainer}
+	router.POST("/node/drain", endpoint.Drain)
+}
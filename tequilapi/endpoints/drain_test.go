@@ -0,0 +1,70 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockDrainer struct {
+	lock     sync.Mutex
+	draining bool
+	drained  chan struct{}
+}
+
+func (m *mockDrainer) Draining() bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+	return m.draining
+}
+
+func (m *mockDrainer) Drain() error {
+	m.lock.Lock()
+	m.draining = true
+	m.lock.Unlock()
+	m.drained <- struct{}{}
+	return nil
+}
+
+func TestDrainEndpoint_Drain(t *testing.T) {
+	drainer := &mockDrainer{drained: make(chan struct{}, 1)}
+	router := httprouter.New()
+	AddRoutesForDrain(router, drainer)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/node/drain", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	select {
+	case <-drainer.drained:
+	case <-time.After(time.Second):
+		t.Error("Drain was not started")
+	}
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/node/drain", nil))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(t, `{"code":"provider_draining","message":"provider is draining, new sessions are not accepted"}`, resp.Body.String())
+}
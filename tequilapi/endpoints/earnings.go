@@ -0,0 +1,136 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+// maxEarningsPoints limits the size of a single earnings series response.
+const maxEarningsPoints = 1000
+
+type earningsSeriesProvider interface {
+	Series(id string, granularity pingpong.EarningsGranularity, from, to time.Time) ([]pingpong.EarningsPoint, error)
+}
+
+type earningsEndpoint struct {
+	series earningsSeriesProvider
+	now    func() time.Time
+}
+
+// swagger:operation GET /earnings/series Earnings earningsSeries
+// ---
+// summary: Provides earnings series
+// description: Provides tokens earned in provided sessions rolled up by hours or days, one point per period including periods without earnings
+// parameters:
+//   - in: query
+//     name: granularity
+//     description: Period of a single point. Possible values are "hour" and "day", defaults to "day".
+//     type: string
+//   - in: query
+//     name: identity
+//     description: Provider identity to filter the earnings by, earnings of all identities are summed if not given.
+//     type: string
+//   - in: query
+//     name: from
+//     description: Start of the series formatted in RFC3339 e.g. 2020-07-01T00:00:00Z. Defaults to 24 hours ago for hourly and 30 days ago for daily series.
+//     type: string
+//   - in: query
+//     name: to
+//     description: End of the series formatted in RFC3339 e.g. 2020-07-01T00:00:00Z. Defaults to now.
+//     type: string
+// responses:
+//   200:
+//     description: Earnings series
+//     schema:
+//       "$ref": "#/definitions/EarningsSeriesDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *earningsEndpoint) Series(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	granularity := pingpong.EarningsDaily
+	if granularityStr := request.URL.Query().Get("granularity"); granularityStr != "" {
+		granularity = pingpong.EarningsGranularity(granularityStr)
+	}
+	if !granularity.Valid() {
+		utils.SendErrorMessage(resp, fmt.Sprintf("unknown granularity %q", granularity), http.StatusBadRequest)
+		return
+	}
+
+	to := endpoint.now()
+	if toStr := request.URL.Query().Get("to"); toStr != "" {
+		var err error
+		if to, err = time.Parse(time.RFC3339, toStr); err != nil {
+			utils.SendError(resp, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	from := to.Add(-24 * time.Hour)
+	if granularity == pingpong.EarningsDaily {
+		from = to.AddDate(0, 0, -30)
+	}
+	if fromStr := request.URL.Query().Get("from"); fromStr != "" {
+		var err error
+		if from, err = time.Parse(time.RFC3339, fromStr); err != nil {
+			utils.SendError(resp, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	period := time.Hour
+	if granularity == pingpong.EarningsDaily {
+		period = 24 * time.Hour
+	}
+	if to.Before(from) || to.Sub(from)/period > maxEarningsPoints {
+		utils.SendErrorMessage(resp, fmt.Sprintf("series must have from 0 to %d points", maxEarningsPoints), http.StatusBadRequest)
+		return
+	}
+
+	var id string
+	if idStr := request.URL.Query().Get("identity"); idStr != "" {
+		id = identity.FromAddress(idStr).Address
+	}
+
+	series, err := endpoint.series.Series(id, granularity, from, to)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewEarningsSeriesDTO(granularity, series), resp)
+}
+
+// AddRoutesForEarnings attaches earnings endpoints to router
+func AddRoutesForEarnings(router *httprouter.Router, series earningsSeriesProvider) {
+	endpoint := &earningsEndpoint{series: series, now: time.Now}
+	router.GET("/earnings/series", endpoint.Series)
+}
@@ -0,0 +1,120 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockEarningsSeries struct {
+	id          string
+	granularity pingpong.EarningsGranularity
+	from, to    time.Time
+	series      []pingpong.EarningsPoint
+	err         error
+}
+
+func (m *mockEarningsSeries) Series(id string, granularity pingpong.EarningsGranularity, from, to time.Time) ([]pingpong.EarningsPoint, error) {
+	m.id, m.granularity, m.from, m.to = id, granularity, from, to
+	return m.series, m.err
+}
+
+func TestEarningsEndpoint_Series(t *testing.T) {
+	series := &mockEarningsSeries{
+		series: []pingpong.EarningsPoint{
+			{Time: time.Date(2020, 6, 17, 9, 0, 0, 0, time.UTC), Tokens: money.NewTokens(10)},
+			{Time: time.Date(2020, 6, 17, 10, 0, 0, 0, time.UTC), Tokens: money.NewTokens(0)},
+		},
+	}
+	router := httprouter.New()
+	AddRoutesForEarnings(router, series)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(
+		http.MethodGet,
+		"/earnings/series?granularity=hour&identity=0xAB&from=2020-06-17T09:00:00Z&to=2020-06-17T11:00:00Z",
+		nil,
+	))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"granularity": "hour",
+		"points": [
+			{"time": "2020-06-17T09:00:00Z", "tokens": {"amount": "10", "myst": "0.000000"}},
+			{"time": "2020-06-17T10:00:00Z", "tokens": {"amount": "0", "myst": "0.000000"}}
+		]
+	}`, resp.Body.String())
+	assert.Equal(t, "0xab", series.id)
+	assert.Equal(t, pingpong.EarningsHourly, series.granularity)
+	assert.Equal(t, time.Date(2020, 6, 17, 9, 0, 0, 0, time.UTC), series.from)
+	assert.Equal(t, time.Date(2020, 6, 17, 11, 0, 0, 0, time.UTC), series.to)
+}
+
+func TestEarningsEndpoint_SeriesDefaultsToLastMonth(t *testing.T) {
+	series := &mockEarningsSeries{}
+	router := httprouter.New()
+	endpoint := &earningsEndpoint{series: series, now: func() time.Time {
+		return time.Date(2020, 6, 17, 9, 0, 0, 0, time.UTC)
+	}}
+	router.GET("/earnings/series", endpoint.Series)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/earnings/series", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"granularity": "day", "points": []}`, resp.Body.String())
+	assert.Equal(t, "", series.id)
+	assert.Equal(t, pingpong.EarningsDaily, series.granularity)
+	assert.Equal(t, time.Date(2020, 5, 18, 9, 0, 0, 0, time.UTC), series.from)
+}
+
+func TestEarningsEndpoint_SeriesRejectsBadRequests(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForEarnings(router, &mockEarningsSeries{})
+
+	for _, query := range []string{
+		"granularity=minute",
+		"from=yesterday",
+		"from=2020-06-17T00:00:00Z&to=2020-06-16T00:00:00Z",
+		"granularity=hour&from=2020-01-01T00:00:00Z&to=2020-06-16T00:00:00Z",
+	} {
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/earnings/series?"+query, nil))
+		assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+	}
+}
+
+func TestEarningsEndpoint_SeriesFails(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForEarnings(router, &mockEarningsSeries{err: errors.New("storage unavailable")})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/earnings/series", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"code":"internal","message":"storage unavailable"}`, resp.Body.String())
+}
@@ -0,0 +1,115 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/faucet"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type faucetGranter interface {
+	Request(id identity.Identity) (faucet.Grant, error)
+	Status(id identity.Identity) (faucet.Grant, error)
+}
+
+type faucetEndpoint struct {
+	faucet faucetGranter
+}
+
+// swagger:operation POST /identities/{id}/faucet Identity requestFaucetGrant
+// ---
+// summary: Requests test tokens
+// description: Requests test MYST and ETH from the test network faucet for identity. Progress is reported by faucet grant status.
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity address
+//   type: string
+//   required: true
+// responses:
+//   202:
+//     description: Test tokens requested
+//     schema:
+//       "$ref": "#/definitions/FaucetGrantDTO"
+//   409:
+//     description: Grant already in progress or tokens already granted
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *faucetEndpoint) Request(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	g, err := endpoint.faucet.Request(identity.FromAddress(params.ByName("id")))
+	switch err {
+	case nil:
+		resp.WriteHeader(http.StatusAccepted)
+		utils.WriteAsJSON(contract.NewFaucetGrantDTO(g), resp)
+	case faucet.ErrGrantInProgress, faucet.ErrAlreadyGranted:
+		utils.SendError(resp, err, http.StatusConflict)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+// swagger:operation GET /identities/{id}/faucet Identity faucetGrantStatus
+// ---
+// summary: Returns faucet grant status
+// description: Returns status of the last test tokens request of identity
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity address
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Faucet grant status
+//     schema:
+//       "$ref": "#/definitions/FaucetGrantDTO"
+//   404:
+//     description: No faucet grant found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *faucetEndpoint) Status(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	g, err := endpoint.faucet.Status(identity.FromAddress(params.ByName("id")))
+	switch err {
+	case nil:
+		utils.WriteAsJSON(contract.NewFaucetGrantDTO(g), resp)
+	case faucet.ErrGrantNotFound:
+		utils.SendErrorMessage(resp, "No faucet grant found", http.StatusNotFound)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+// AddRoutesForFaucet attaches test network faucet endpoints to router
+func AddRoutesForFaucet(router *httprouter.Router, granter faucetGranter) {
+	endpoint := &faucetEndpoint{faucet: granter}
+	router.POST("/identities/:id/faucet", endpoint.Request)
+	router.GET("/identities/:id/faucet", endpoint.Status)
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/faucet"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	faucetIdentity = "0x0000000000000000000000000000000000000001"
+	faucetChannel  = "0x0000000000000000000000000000000000000002"
+)
+
+type mockFaucet struct {
+	err    error
+	grants map[identity.Identity]faucet.Grant
+}
+
+func (m *mockFaucet) Request(id identity.Identity) (faucet.Grant, error) {
+	if m.err != nil {
+		return faucet.Grant{}, m.err
+	}
+	requested := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	g := faucet.Grant{
+		Identity:    id,
+		Channel:     common.HexToAddress(faucetChannel),
+		Status:      faucet.StatusPending,
+		RequestedAt: requested,
+		UpdatedAt:   requested,
+	}
+	m.grants[id] = g
+	return g, nil
+}
+
+func (m *mockFaucet) Status(id identity.Identity) (faucet.Grant, error) {
+	g, ok := m.grants[id]
+	if !ok {
+		return faucet.Grant{}, faucet.ErrGrantNotFound
+	}
+	return g, nil
+}
+
+func TestFaucetEndpoint_Request(t *testing.T) {
+	granter := &mockFaucet{grants: make(map[identity.Identity]faucet.Grant)}
+	router := httprouter.New()
+	AddRoutesForFaucet(router, granter)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/identities/"+faucetIdentity+"/faucet", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/identities/"+faucetIdentity+"/faucet", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{
+		"identity": "`+faucetIdentity+`",
+		"channel": "`+faucetChannel+`",
+		"status": "pending",
+		"requested_at": "2020-09-01T10:00:00Z",
+		"updated_at": "2020-09-01T10:00:00Z"
+	}`, resp.Body.String())
+
+	g := granter.grants[identity.FromAddress(faucetIdentity)]
+	g.Status = faucet.StatusGranted
+	g.TxHash = "0x1"
+	g.MYST = big.NewInt(10)
+	granter.grants[g.Identity] = g
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/identities/"+faucetIdentity+"/faucet", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"status":"granted"`)
+	assert.Contains(t, resp.Body.String(), `"myst":"10"`)
+
+	granter.err = faucet.ErrAlreadyGranted
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/identities/"+faucetIdentity+"/faucet", nil))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/gateway"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type lanGateway interface {
+	Status() gateway.Status
+	Configure(config gateway.Config) error
+}
+
+type gatewayAPI struct {
+	gateway lanGateway
+}
+
+// Status returns gateway mode configuration
+// swagger:operation GET /gateway Gateway gatewayStatus
+// ---
+// summary: Returns gateway mode configuration
+// description: Returns LAN network forwarded through the active session and whether it is being forwarded right now
+// responses:
+//   200:
+//     description: Gateway mode configuration
+//     schema:
+//       "$ref": "#/definitions/GatewayDTO"
+func (api *gatewayAPI) Status(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(toGatewayResponse(api.gateway.Status()), resp)
+}
+
+// Configure configures gateway mode
+// swagger:operation PUT /gateway Gateway gatewayConfigure
+// ---
+// summary: Configures gateway mode
+// description: Enables or disables forwarding of LAN traffic through the active session and persists it to user config. LAN traffic is dropped while gateway is enabled and there is no session
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/GatewayRequestDTO"
+// responses:
+//   200:
+//     description: Gateway mode configured
+//     schema:
+//       "$ref": "#/definitions/GatewayDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   501:
+//     description: Gateway mode is not supported on this OS
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *gatewayAPI) Configure(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var request contract.GatewayRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errors := request.Validate(); errors.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errors)
+		return
+	}
+
+	err := api.gateway.Configure(gateway.Config{
+		Enabled:   request.Enabled,
+		Interface: request.Interface,
+		Subnet:    request.Subnet,
+	})
+	if err == gateway.ErrNotSupported {
+		utils.SendError(resp, err, http.StatusNotImplemented)
+		return
+	} else if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(toGatewayResponse(api.gateway.Status()), resp)
+}
+
+func toGatewayResponse(status gateway.Status) contract.GatewayDTO {
+	return contract.GatewayDTO{
+		Enabled:    status.Enabled,
+		Interface:  status.Interface,
+		Subnet:     status.Subnet,
+		Forwarding: status.Forwarding,
+	}
+}
+
+// AddRoutesForGateway adds gateway mode routes to given router
+func AddRoutesForGateway(router *httprouter.Router, gateway lanGateway) {
+	api := &gatewayAPI{gateway: gateway}
+
+	router.GET("/gateway", api.Status)
+	router.PUT("/gateway", api.Configure)
+}
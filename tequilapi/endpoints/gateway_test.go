@@ -0,0 +1,101 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/gateway"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockLANGateway struct {
+	status      gateway.Status
+	onConfigure error
+}
+
+func (m *mockLANGateway) Status() gateway.Status {
+	return m.status
+}
+
+func (m *mockLANGateway) Configure(config gateway.Config) error {
+	if m.onConfigure != nil {
+		return m.onConfigure
+	}
+	m.status = gateway.Status{Config: config}
+	return nil
+}
+
+func newGatewayRouter(gw lanGateway) *httprouter.Router {
+	router := httprouter.New()
+	AddRoutesForGateway(router, gw)
+	return router
+}
+
+func Test_GatewayStatus(t *testing.T) {
+	gw := &mockLANGateway{status: gateway.Status{
+		Config:     gateway.Config{Enabled: true, Interface: "eth0", Subnet: "192.168.8.0/24"},
+		Forwarding: true,
+	}}
+	req := httptest.NewRequest(http.MethodGet, "/gateway", nil)
+	resp := httptest.NewRecorder()
+
+	newGatewayRouter(gw).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"enabled": true, "interface": "eth0", "subnet": "192.168.8.0/24", "forwarding": true}`, resp.Body.String())
+}
+
+func Test_GatewayConfigure(t *testing.T) {
+	gw := &mockLANGateway{}
+	req := httptest.NewRequest(http.MethodPut, "/gateway", strings.NewReader(`{"enabled": true, "interface": "eth0", "subnet": "192.168.8.0/24"}`))
+	resp := httptest.NewRecorder()
+
+	newGatewayRouter(gw).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, gateway.Config{Enabled: true, Interface: "eth0", Subnet: "192.168.8.0/24"}, gw.status.Config)
+}
+
+func Test_GatewayConfigureValidatesRequest(t *testing.T) {
+	gw := &mockLANGateway{}
+	req := httptest.NewRequest(http.MethodPut, "/gateway", strings.NewReader(`{"enabled": true, "subnet": "192.168.8.1"}`))
+	resp := httptest.NewRecorder()
+
+	newGatewayRouter(gw).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"interface"`)
+	assert.Contains(t, resp.Body.String(), `"subnet"`)
+	assert.Equal(t, gateway.Config{}, gw.status.Config)
+}
+
+func Test_GatewayConfigureReturnsNotImplementedOnUnsupportedOS(t *testing.T) {
+	gw := &mockLANGateway{onConfigure: gateway.ErrNotSupported}
+	req := httptest.NewRequest(http.MethodPut, "/gateway", strings.NewReader(`{"enabled": true, "interface": "eth0", "subnet": "192.168.8.0/24"}`))
+	resp := httptest.NewRecorder()
+
+	newGatewayRouter(gw).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotImplemented, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"gateway_not_supported"`)
+}
@@ -22,6 +22,7 @@ import (
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/blockchain"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
@@ -58,9 +59,13 @@ func HealthCheckEndpointFactory(currentTimeFunc func() time.Time, procID func()
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (hce *healthCheckEndpoint) HealthCheck(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	status := contract.HealthCheckDTO{
+	utils.WriteAsJSON(hce.status(), writer)
+}
+
+func (hce *healthCheckEndpoint) status() contract.HealthCheckDTO {
+	return contract.HealthCheckDTO{
 		Uptime:  hce.currentTimeFunc().Sub(hce.startTime).String(),
 		Process: hce.processNumber,
 		Version: metadata.VersionAsString(),
@@ -70,5 +75,43 @@ func (hce *healthCheckEndpoint) HealthCheck(writer http.ResponseWriter, request
 			BuildNumber: metadata.BuildNumber,
 		},
 	}
+}
+
+type rpcHealthProvider interface {
+	Status() []blockchain.EndpointStatus
+}
+
+type detailedHealthCheckEndpoint struct {
+	*healthCheckEndpoint
+	rpc rpcHealthProvider
+}
+
+// swagger:operation GET /healthcheck/detailed Client detailedHealthCheck
+// ---
+// summary: Returns detailed information about client
+// description: Returns health check information about client along with health of ethereum RPC endpoints
+// responses:
+//   200:
+//     description: Detailed health check information
+//     schema:
+//       "$ref": "#/definitions/HealthCheckDetailedDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (dhce *detailedHealthCheckEndpoint) HealthCheck(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	status := contract.HealthCheckDetailedDTO{
+		HealthCheckDTO: dhce.status(),
+		RPC:            contract.NewRPCEndpointsDTO(dhce.rpc.Status()),
+	}
 	utils.WriteAsJSON(status, writer)
 }
+
+// AddRoutesForDetailedHealthCheck attaches detailed healthcheck endpoint to router
+func AddRoutesForDetailedHealthCheck(router *httprouter.Router, currentTimeFunc func() time.Time, procID func() int, rpc rpcHealthProvider) {
+	endpoint := &detailedHealthCheckEndpoint{
+		healthCheckEndpoint: HealthCheckEndpointFactory(currentTimeFunc, procID),
+		rpc:                 rpc,
+	}
+	router.GET("/healthcheck/detailed", endpoint.HealthCheck)
+}
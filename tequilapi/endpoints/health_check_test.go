@@ -23,6 +23,7 @@ import (
 	"time"
 
 	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/blockchain"
 	"github.com/mysteriumnetwork/node/metadata"
 	"github.com/stretchr/testify/assert"
 )
@@ -60,6 +61,55 @@ func TestHealthCheckReturnsExpectedJSONObject(t *testing.T) {
 		resp.Body.String())
 }
 
+type mockRPCHealthProvider struct {
+	status []blockchain.EndpointStatus
+}
+
+func (mrhp *mockRPCHealthProvider) Status() []blockchain.EndpointStatus {
+	return mrhp.status
+}
+
+func TestDetailedHealthCheckReturnsRPCEndpoints(t *testing.T) {
+	tick1 := time.Unix(0, 0)
+	tick2 := tick1.Add(time.Minute)
+
+	router := httprouter.New()
+	AddRoutesForDetailedHealthCheck(
+		router,
+		newMockTimer([]time.Time{tick1, tick2}).Now,
+		func() int { return 1 },
+		&mockRPCHealthProvider{
+			status: []blockchain.EndpointStatus{
+				{URL: "http://first", Active: true, Healthy: true, Latency: 120 * time.Millisecond, CheckedAt: tick2},
+				{URL: "http://second", Error: "timeout"},
+			},
+		},
+	)
+
+	req := httptest.NewRequest("GET", "/healthcheck/detailed", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, 200, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"uptime" : "1m0s",
+			"process" : 1,
+			"version": "`+metadata.VersionAsString()+`",
+			"build_info" : {
+				"branch": "`+metadata.BuildBranch+`",
+				"commit": "`+metadata.BuildCommit+`",
+				"build_number": "`+metadata.BuildNumber+`"
+			},
+			"rpc": [
+				{"url": "http://first", "active": true, "healthy": true, "latency_ms": 120, "checked_at": "1970-01-01T00:01:00Z"},
+				{"url": "http://second", "active": false, "healthy": false, "latency_ms": 0, "error": "timeout"}
+			]
+		}`,
+		resp.Body.String())
+}
+
 type mockTimer struct {
 	values  []time.Time
 	current int
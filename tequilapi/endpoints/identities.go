@@ -28,6 +28,7 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	identity_selector "github.com/mysteriumnetwork/node/identity/selector"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/session/pingpong"
 	pingpong_event "github.com/mysteriumnetwork/node/session/pingpong/event"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
@@ -37,7 +38,7 @@ import (
 )
 
 type balanceProvider interface {
-	ForceBalanceUpdate(id identity.Identity) uint64
+	ForceBalanceUpdate(id identity.Identity) money.Tokens
 }
 
 type earningsProvider interface {
@@ -70,7 +71,7 @@ type identitiesAPI struct {
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (endpoint *identitiesAPI) List(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	ids := endpoint.idm.GetIdentities()
 	idsDTO := contract.NewIdentityListResponse(ids)
@@ -95,15 +96,15 @@ func (endpoint *identitiesAPI) List(resp http.ResponseWriter, _ *http.Request, _
 //   400:
 //     description: Bad Request
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   422:
 //     description: Parameters validation error
 //     schema:
-//       "$ref": "#/definitions/ValidationErrorDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (endpoint *identitiesAPI) Current(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	var req contract.IdentityCurrentRequest
 	err := json.NewDecoder(request.Body).Decode(&req)
@@ -150,15 +151,15 @@ func (endpoint *identitiesAPI) Current(resp http.ResponseWriter, request *http.R
 //   400:
 //     description: Bad Request
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   422:
 //     description: Parameters validation error
 //     schema:
-//       "$ref": "#/definitions/ValidationErrorDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (endpoint *identitiesAPI) Create(resp http.ResponseWriter, httpReq *http.Request, _ httprouter.Params) {
 	var req contract.IdentityCreateRequest
 	err := json.NewDecoder(httpReq.Body).Decode(&req)
@@ -203,15 +204,15 @@ func (endpoint *identitiesAPI) Create(resp http.ResponseWriter, httpReq *http.Re
 //   400:
 //     description: Body parsing error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   403:
 //     description: Forbidden
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (endpoint *identitiesAPI) Unlock(resp http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
 	address := params.ByName("id")
 	id, err := endpoint.idm.GetIdentity(address)
@@ -258,7 +259,7 @@ func (endpoint *identitiesAPI) Unlock(resp http.ResponseWriter, httpReq *http.Re
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (endpoint *identitiesAPI) Get(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
 	address := params.ByName("id")
 	id, err := endpoint.idm.GetIdentity(address)
@@ -285,9 +286,9 @@ func (endpoint *identitiesAPI) Get(resp http.ResponseWriter, _ *http.Request, pa
 		Address:            address,
 		RegistrationStatus: regStatus.String(),
 		ChannelAddress:     channelAddress.Hex(),
-		Balance:            balance,
-		Earnings:           settlement.UnsettledBalance,
-		EarningsTotal:      settlement.LifetimeBalance,
+		Balance:            contract.NewTokensDTO(balance),
+		Earnings:           contract.NewTokensDTO(settlement.UnsettledBalance),
+		EarningsTotal:      contract.NewTokensDTO(settlement.LifetimeBalance),
 	}
 	utils.WriteAsJSON(status, resp)
 }
@@ -310,7 +311,7 @@ func (endpoint *identitiesAPI) Get(resp http.ResponseWriter, _ *http.Request, pa
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (endpoint *identitiesAPI) RegistrationStatus(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
 	address := params.ByName("id")
 	id, err := endpoint.idm.GetIdentity(address)
@@ -350,7 +351,7 @@ func (endpoint *identitiesAPI) RegistrationStatus(resp http.ResponseWriter, _ *h
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (endpoint *identitiesAPI) Beneficiary(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
 	address := params.ByName("id")
 	data, err := endpoint.bc.GetProviderChannel(common.HexToAddress(config.GetString(config.FlagAccountantID)), common.HexToAddress(address), false)
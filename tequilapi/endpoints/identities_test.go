@@ -131,8 +131,9 @@ func TestUnlockIdentityWithNoPassphrase(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code":"validation_failed",
 			"message": "validation_error",
-			"errors" : {
+			"fields" : {
 				"passphrase": [ {"code" : "required" , "message" : "Field is required" } ]
 			}
 		}`,
@@ -195,8 +196,9 @@ func TestCreateNewIdentityNoPassphrase(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code":"validation_failed",
 			"message": "validation_error",
-			"errors" : {
+			"fields" : {
 				"passphrase": [ {"code" : "required" , "message" : "Field is required" } ]
 			}
 		}`,
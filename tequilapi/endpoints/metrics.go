@@ -0,0 +1,94 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type requestMetrics interface {
+	Snapshot() contract.RequestMetricsDTO
+}
+
+// MetricsEndpoint struct represents endpoints about tequilapi requests and event delivery
+type MetricsEndpoint struct {
+	metrics requestMetrics
+	events  eventbus.MetricsProvider
+}
+
+// NewMetricsEndpoint creates and returns metrics endpoint
+func NewMetricsEndpoint(metrics requestMetrics, events eventbus.MetricsProvider) *MetricsEndpoint {
+	return &MetricsEndpoint{
+		metrics: metrics,
+		events:  events,
+	}
+}
+
+// Metrics provides request metrics of every tequilapi endpoint
+// swagger:operation GET /metrics Metrics RequestMetricsDTO
+// ---
+// summary: Shows tequilapi request metrics
+// description: Returns request count, errors and latency histogram of every endpoint since node start
+// responses:
+//   200:
+//     description: Request metrics grouped by endpoint
+//     schema:
+//       "$ref": "#/definitions/RequestMetricsDTO"
+func (me *MetricsEndpoint) Metrics(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(me.metrics.Snapshot(), resp)
+}
+
+// EventMetrics provides delivery metrics of every event bus subscriber
+// swagger:operation GET /metrics/events Metrics EventMetricsDTO
+// ---
+// summary: Shows event delivery metrics
+// description: Returns delivered, dropped, coalesced and queued event counts of every event bus subscriber since node start
+// responses:
+//   200:
+//     description: Event delivery metrics grouped by subscriber
+//     schema:
+//       "$ref": "#/definitions/EventMetricsDTO"
+func (me *MetricsEndpoint) EventMetrics(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	dto := contract.EventMetricsDTO{Subscribers: []contract.EventSubscriberMetricsDTO{}}
+	for _, m := range me.events.Metrics() {
+		dto.Subscribers = append(dto.Subscribers, contract.EventSubscriberMetricsDTO{
+			Topic:     m.Topic,
+			Handler:   m.Handler,
+			Delivered: m.Delivered,
+			Dropped:   m.Dropped,
+			Coalesced: m.Coalesced,
+			Queued:    m.Queued,
+		})
+	}
+	utils.WriteAsJSON(dto, resp)
+}
+
+// AddRoutesForMetrics adds metrics routes to given router
+func AddRoutesForMetrics(router *httprouter.Router, metrics requestMetrics, events eventbus.MetricsProvider) {
+	metricsEndpoint := NewMetricsEndpoint(metrics, events)
+
+	router.GET("/metrics", metricsEndpoint.Metrics)
+	if events != nil {
+		router.GET("/metrics/events", metricsEndpoint.EventMetrics)
+	}
+}
@@ -0,0 +1,74 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/eventbus"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsEndpoint_EventMetrics(t *testing.T) {
+	bus := eventbus.New()
+	assert.NoError(t, bus.Subscribe("test topic", func(data string) {}))
+	bus.Publish("test topic", "test data")
+	bus.Publish("test topic", 1)
+
+	router := httprouter.New()
+	AddRoutesForMetrics(router, &mockRequestMetrics{}, bus.(eventbus.MetricsProvider))
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics/events", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"subscribers": [
+			{
+				"topic": "test topic",
+				"handler": "github.com/mysteriumnetwork/node/tequilapi/endpoints.TestMetricsEndpoint_EventMetrics.func1",
+				"delivered": 1,
+				"dropped": 1,
+				"coalesced": 0,
+				"queued": 0
+			}
+		]
+	}`, resp.Body.String())
+}
+
+func TestMetricsEndpoint_EventMetricsNotRoutedWithoutProvider(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForMetrics(router, &mockRequestMetrics{}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/metrics/events", nil)
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+type mockRequestMetrics struct{}
+
+func (m *mockRequestMetrics) Snapshot() contract.RequestMetricsDTO {
+	return contract.RequestMetricsDTO{}
+}
@@ -60,7 +60,7 @@ func newMMNAPI(config mmnProvider, client *mmn.MMN) *mmnAPI {
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (api *mmnAPI) GetNodeReport(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
 	report, err := api.mmn.GetReport()
 	if err != nil {
@@ -85,7 +85,7 @@ func (api *mmnAPI) GetNodeReport(writer http.ResponseWriter, httpReq *http.Reque
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (api *mmnAPI) GetApiKey(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
 	res := contract.MMNApiKeyRequest{ApiKey: api.config.GetString("mmn.api-key")}
 	utils.WriteAsJSON(res, writer)
@@ -108,11 +108,11 @@ func (api *mmnAPI) GetApiKey(writer http.ResponseWriter, httpReq *http.Request,
 //   422:
 //     description: Parameters validation error
 //     schema:
-//       "$ref": "#/definitions/ValidationErrorDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 
 func (api *mmnAPI) SetApiKey(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
 	var req contract.MMNApiKeyRequest
@@ -171,7 +171,7 @@ func (api *mmnAPI) SetApiKey(writer http.ResponseWriter, httpReq *http.Request,
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (api *mmnAPI) ClearApiKey(writer http.ResponseWriter, httpReq *http.Request, params httprouter.Params) {
 	api.config.RemoveUser("mmn")
 	if err := api.config.SaveUserConfig(); err != nil {
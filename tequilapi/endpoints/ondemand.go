@@ -0,0 +1,123 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/ondemand"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type onDemandConnector interface {
+	Status() ondemand.Status
+	Configure(config ondemand.Config) error
+}
+
+type onDemandAPI struct {
+	onDemand onDemandConnector
+}
+
+// Status returns on-demand mode configuration
+// swagger:operation GET /connection/on-demand Connection connectionOnDemand
+// ---
+// summary: Returns on-demand mode configuration
+// description: Returns traffic the main connection is connected on demand for, and the state of connection made for it
+// responses:
+//   200:
+//     description: On-demand mode configuration
+//     schema:
+//       "$ref": "#/definitions/OnDemandDTO"
+func (api *onDemandAPI) Status(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewOnDemandDTO(api.onDemand.Status()), resp)
+}
+
+// Configure configures on-demand mode
+// swagger:operation PUT /connection/on-demand Connection connectionOnDemandConfigure
+// ---
+// summary: Configures on-demand mode
+// description: Replaces traffic the main connection is connected on demand for and persists it to user config. Connection made on demand is disconnected once the watched traffic stays idle
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/OnDemandRequestDTO"
+// responses:
+//   200:
+//     description: On-demand mode configured
+//     schema:
+//       "$ref": "#/definitions/OnDemandDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   501:
+//     description: On-demand mode is not supported on this OS
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *onDemandAPI) Configure(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var request contract.OnDemandRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errors := request.Validate(); errors.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errors)
+		return
+	}
+
+	err := api.onDemand.Configure(ondemand.Config{
+		Enabled:      request.Enabled,
+		ConsumerID:   request.ConsumerID,
+		Country:      strings.ToUpper(request.Country),
+		ServiceType:  request.ServiceType,
+		Destinations: request.Destinations,
+		Interface:    request.Interface,
+		IdleTimeout:  time.Duration(request.IdleTimeout) * time.Second,
+	})
+	if err == ondemand.ErrNotSupported {
+		utils.SendError(resp, err, http.StatusNotImplemented)
+		return
+	} else if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewOnDemandDTO(api.onDemand.Status()), resp)
+}
+
+// AddRoutesForOnDemand adds on-demand mode routes to given router
+func AddRoutesForOnDemand(router *httprouter.Router, onDemand onDemandConnector) {
+	api := &onDemandAPI{onDemand: onDemand}
+
+	router.GET("/connection/on-demand", api.Status)
+	router.PUT("/connection/on-demand", api.Configure)
+}
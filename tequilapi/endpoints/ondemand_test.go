@@ -0,0 +1,128 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/ondemand"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockOnDemandConnector struct {
+	status ondemand.Status
+	err    error
+}
+
+func (m *mockOnDemandConnector) Status() ondemand.Status {
+	return m.status
+}
+
+func (m *mockOnDemandConnector) Configure(config ondemand.Config) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.status = ondemand.Status{Config: config}
+	return nil
+}
+
+func newOnDemandRouter(onDemand onDemandConnector) *httprouter.Router {
+	router := httprouter.New()
+	AddRoutesForOnDemand(router, onDemand)
+	return router
+}
+
+func Test_OnDemandStatus(t *testing.T) {
+	onDemand := &mockOnDemandConnector{status: ondemand.Status{
+		Config: ondemand.Config{
+			Enabled: true, ConsumerID: "0x1", Country: "DE", ServiceType: "wireguard",
+			Destinations: []string{"10.8.0.0/16"}, IdleTimeout: 10 * time.Minute,
+		},
+		Connected:    true,
+		LastActivity: time.Date(2020, 6, 1, 12, 0, 0, 0, time.UTC),
+	}}
+	req := httptest.NewRequest(http.MethodGet, "/connection/on-demand", nil)
+	resp := httptest.NewRecorder()
+
+	newOnDemandRouter(onDemand).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"enabled": true,
+		"consumer_id": "0x1",
+		"country": "DE",
+		"service_type": "wireguard",
+		"destinations": ["10.8.0.0/16"],
+		"idle_timeout": 600,
+		"connected": true,
+		"last_activity": "2020-06-01T12:00:00Z"
+	}`, resp.Body.String())
+}
+
+func Test_OnDemandConfigure(t *testing.T) {
+	onDemand := &mockOnDemandConnector{}
+	req := httptest.NewRequest(http.MethodPut, "/connection/on-demand", strings.NewReader(`{"enabled": true, "consumer_id": "0x1", "country": "de", "interface": "virbr0", "idle_timeout": 300}`))
+	resp := httptest.NewRecorder()
+
+	newOnDemandRouter(onDemand).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, ondemand.Config{Enabled: true, ConsumerID: "0x1", Country: "DE", Interface: "virbr0", IdleTimeout: 5 * time.Minute}, onDemand.status.Config)
+	assert.JSONEq(t, `{
+		"enabled": true,
+		"consumer_id": "0x1",
+		"country": "DE",
+		"destinations": [],
+		"interface": "virbr0",
+		"idle_timeout": 300,
+		"connected": false
+	}`, resp.Body.String())
+}
+
+func Test_OnDemandConfigureValidatesRequest(t *testing.T) {
+	for _, body := range []string{
+		`{"enabled": true, "country": "DE", "interface": "virbr0"}`,
+		`{"enabled": true, "consumer_id": "0x1", "interface": "virbr0"}`,
+		`{"enabled": true, "consumer_id": "0x1", "country": "DE"}`,
+		`{"enabled": false, "destinations": ["example.com"]}`,
+	} {
+		onDemand := &mockOnDemandConnector{}
+		req := httptest.NewRequest(http.MethodPut, "/connection/on-demand", strings.NewReader(body))
+		resp := httptest.NewRecorder()
+
+		newOnDemandRouter(onDemand).ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, body)
+		assert.Equal(t, ondemand.Status{}, onDemand.status)
+	}
+}
+
+func Test_OnDemandConfigureNotSupported(t *testing.T) {
+	onDemand := &mockOnDemandConnector{err: ondemand.ErrNotSupported}
+	req := httptest.NewRequest(http.MethodPut, "/connection/on-demand", strings.NewReader(`{"enabled": true, "consumer_id": "0x1", "country": "DE", "interface": "virbr0"}`))
+	resp := httptest.NewRecorder()
+
+	newOnDemandRouter(onDemand).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusNotImplemented, resp.Code)
+}
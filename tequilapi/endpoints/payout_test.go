@@ -87,8 +87,9 @@ func TestUpdatePayoutInfoWithoutAddress(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code":"validation_failed",
 			"message": "validation_error",
-			"errors" : {
+			"fields" : {
 				"eth_address": [ {"code" : "required" , "message" : "Field is required" } ]
 			}
 		}`,
@@ -182,5 +183,5 @@ func TestGetPayoutInfo_ReturnsError_WhenPayoutInfoFindingFails(t *testing.T) {
 	handlerFunc(resp, req, params)
 
 	assert.Equal(t, http.StatusNotFound, resp.Code)
-	assert.JSONEq(t, `{"message": "payout info for identity is not mocked"}`, resp.Body.String())
+	assert.JSONEq(t, `{"code":"not_found","message": "payout info for identity is not mocked"}`, resp.Body.String())
 }
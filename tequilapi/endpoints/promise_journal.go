@@ -0,0 +1,96 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type promiseJournal interface {
+	Check() (pingpong.PromiseJournalReport, error)
+	Repair() (pingpong.PromiseJournalReport, error)
+}
+
+type promiseJournalEndpoint struct {
+	journal promiseJournal
+}
+
+// NewPromiseJournalEndpoint creates and returns promise journal endpoint
+func NewPromiseJournalEndpoint(journal promiseJournal) *promiseJournalEndpoint {
+	return &promiseJournalEndpoint{
+		journal: journal,
+	}
+}
+
+// swagger:operation GET /promises/journal Promises checkPromiseJournal
+// ---
+// summary: Checks promise journal
+// description: Inspects the journal of promises accepted from consumers and reports corrupted entries
+// responses:
+//   200:
+//     description: Promise journal state
+//     schema:
+//       "$ref": "#/definitions/PromiseJournalDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *promiseJournalEndpoint) Check(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	report, err := endpoint.journal.Check()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewPromiseJournalDTO(report), resp)
+}
+
+// swagger:operation POST /promises/journal/repair Promises repairPromiseJournal
+// ---
+// summary: Repairs promise journal
+// description: Removes corrupted entries of the promise journal and reconciles the rest against the accountants
+// responses:
+//   200:
+//     description: Promise journal state after the repair
+//     schema:
+//       "$ref": "#/definitions/PromiseJournalDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *promiseJournalEndpoint) Repair(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	report, err := endpoint.journal.Repair()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewPromiseJournalDTO(report), resp)
+}
+
+// AddRoutesForPromiseJournal attaches promise journal endpoints to router
+func AddRoutesForPromiseJournal(router *httprouter.Router, journal promiseJournal) {
+	promiseJournalEndpoint := NewPromiseJournalEndpoint(journal)
+	router.GET("/promises/journal", promiseJournalEndpoint.Check)
+	router.POST("/promises/journal/repair", promiseJournalEndpoint.Repair)
+}
@@ -0,0 +1,86 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/stretchr/testify/assert"
+)
+
+type promiseJournalMock struct {
+	report      pingpong.PromiseJournalReport
+	repaired    bool
+	errToReturn error
+}
+
+func (m *promiseJournalMock) Check() (pingpong.PromiseJournalReport, error) {
+	return m.report, m.errToReturn
+}
+
+func (m *promiseJournalMock) Repair() (pingpong.PromiseJournalReport, error) {
+	m.repaired = true
+	m.report.Corrupted = nil
+	return m.report, m.errToReturn
+}
+
+func Test_PromiseJournalEndpoint_Check(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/promises/journal", nil)
+	resp := httptest.NewRecorder()
+
+	router := httprouter.New()
+	AddRoutesForPromiseJournal(router, &promiseJournalMock{
+		report: pingpong.PromiseJournalReport{Entries: 3, Pending: 1, Corrupted: []string{"abc"}},
+	})
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"entries":3,"pending":1,"abandoned":0,"corrupted":["abc"]}`, resp.Body.String())
+}
+
+func Test_PromiseJournalEndpoint_Repair(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/promises/journal/repair", nil)
+	resp := httptest.NewRecorder()
+
+	journal := &promiseJournalMock{
+		report: pingpong.PromiseJournalReport{Entries: 3, Corrupted: []string{"abc"}},
+	}
+	router := httprouter.New()
+	AddRoutesForPromiseJournal(router, journal)
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.True(t, journal.repaired)
+	assert.JSONEq(t, `{"entries":3,"pending":0,"abandoned":0,"corrupted":[]}`, resp.Body.String())
+}
+
+func Test_PromiseJournalEndpoint_CheckFails(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/promises/journal", nil)
+	resp := httptest.NewRecorder()
+
+	router := httprouter.New()
+	AddRoutesForPromiseJournal(router, &promiseJournalMock{errToReturn: errors.New("boom")})
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+}
@@ -18,14 +18,38 @@
 package endpoints
 
 import (
+	"encoding/json"
+	"fmt"
+	"math"
 	"net/http"
+	"sort"
 	"strconv"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/datasize"
+	"github.com/mysteriumnetwork/node/market"
+	"github.com/mysteriumnetwork/node/session/pingpong"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/utils/stringutil"
+	"github.com/rs/zerolog/log"
+)
+
+// Supported proposal sort orders.
+const (
+	proposalsSortByPriceGiB    = "price_gb"
+	proposalsSortByPriceMinute = "price_minute"
+	proposalsSortByQuality     = "quality"
+	proposalsSortByCountry     = "country"
+)
+
+// Typical usage proposal costs are projected for.
+const (
+	typicalUsageGB    = 1
+	typicalUsageHours = 1
 )
 
 // QualityFinder allows to fetch proposal quality data
@@ -33,16 +57,23 @@ type QualityFinder interface {
 	ProposalsMetrics() []quality.ConnectMetric
 }
 
+// CostEstimator projects cost of using a proposal
+type CostEstimator interface {
+	Estimate(method market.PaymentMethod, duration time.Duration, data datasize.BitSize) (pingpong.CostEstimate, error)
+}
+
 type proposalsEndpoint struct {
 	proposalRepository proposal.Repository
 	qualityProvider    QualityFinder
+	costEstimator      CostEstimator
 }
 
 // NewProposalsEndpoint creates and returns proposal creation endpoint
-func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvider QualityFinder) *proposalsEndpoint {
+func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvider QualityFinder, costEstimator CostEstimator) *proposalsEndpoint {
 	return &proposalsEndpoint{
 		proposalRepository: proposalRepository,
 		qualityProvider:    qualityProvider,
+		costEstimator:      costEstimator,
 	}
 }
 
@@ -71,15 +102,63 @@ func NewProposalsEndpoint(proposalRepository proposal.Repository, qualityProvide
 //     name: fetch_metrics
 //     description: if set to true, fetches the connection success metrics for nodes. False by default.
 //     type: boolean
+//   - in: query
+//     name: upper_time_price_bound
+//     description: maximum price per minute. May be given without the lower bound.
+//     type: integer
+//   - in: query
+//     name: upper_gb_price_bound
+//     description: maximum price per GiB. May be given without the lower bound.
+//     type: integer
+//   - in: query
+//     name: country
+//     description: comma separated list of provider countries to include
+//     type: string
+//   - in: query
+//     name: exclude_country
+//     description: comma separated list of provider countries to exclude
+//     type: string
+//   - in: query
+//     name: isp
+//     description: comma separated list of provider ISPs to include
+//     type: string
+//   - in: query
+//     name: exclude_isp
+//     description: comma separated list of provider ISPs to exclude
+//     type: string
+//   - in: query
+//     name: nat_compatibility
+//     description: the local NAT type. Only providers reachable from it are returned. Possible values are "none", "fullcone", "rcone", "prcone" and "symmetric"
+//     type: string
+//   - in: query
+//     name: content_filter
+//     description: comma separated list of content categories the provider must block in DNS queries, e.g. "malware,adult" for family-safe exits
+//     type: string
+//   - in: query
+//     name: tags
+//     description: comma separated list of tags the provider must have declared in its self-description, e.g. "streaming-friendly"
+//     type: string
+//   - in: query
+//     name: quality_min
+//     description: minimum connect success rate of the provider, in range [0, 1]. Implies fetch_metrics.
+//     type: number
+//   - in: query
+//     name: sort_by
+//     description: the order of proposals. Possible values are "price_gb", "price_minute", "quality" and "country"
+//     type: string
 // responses:
 //   200:
 //     description: List of proposals
 //     schema:
 //       "$ref": "#/definitions/ListProposalsResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
 	upperTimePriceBound, err := parsePriceBound(req, "upper_time_price_bound")
 	if err != nil {
@@ -103,6 +182,17 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 		return
 	}
 
+	qualityMin, err := parseQualityMin(req)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	sortBy := req.URL.Query().Get("sort_by")
+	if err := validateProposalsSort(sortBy); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
 	proposals, err := pe.proposalRepository.Proposals(&proposal.Filter{
 		ProviderID:          req.URL.Query().Get("provider_id"),
 		ServiceType:         req.URL.Query().Get("service_type"),
@@ -112,6 +202,14 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 		UpperGBPriceBound:   upperGBPriceBound,
 		LowerTimePriceBound: lowerTimePriceBound,
 		UpperTimePriceBound: upperTimePriceBound,
+		IncludeCountries:    stringutil.Split(req.URL.Query().Get("country"), ','),
+		ExcludeCountries:    stringutil.Split(req.URL.Query().Get("exclude_country"), ','),
+		IncludeISPs:         stringutil.Split(req.URL.Query().Get("isp"), ','),
+		ExcludeISPs:         stringutil.Split(req.URL.Query().Get("exclude_isp"), ','),
+		NATCompatibility:    req.URL.Query().Get("nat_compatibility"),
+		ContentFilter:       stringutil.Split(req.URL.Query().Get("content_filter"), ','),
+		Tags:                stringutil.Split(req.URL.Query().Get("tags"), ','),
+		QualityMin:          qualityMin,
 		ExcludeUnsupported:  true,
 		IncludeFailed:       req.URL.Query().Get("monitoring_failed") == "true",
 	})
@@ -125,16 +223,82 @@ func (pe *proposalsEndpoint) List(resp http.ResponseWriter, req *http.Request, p
 	for _, p := range proposals {
 		proposalsRes.Proposals = append(proposalsRes.Proposals, contract.NewProposalDTO(p))
 	}
+	pe.addTypicalUsageCosts(proposalsRes.Proposals, proposals)
 
 	fetchConnectCounts := req.URL.Query().Get("fetch_metrics")
-	if fetchConnectCounts == "true" {
+	if fetchConnectCounts == "true" || qualityMin != nil || sortBy == proposalsSortByQuality {
 		metrics := pe.qualityProvider.ProposalsMetrics()
 		addProposalMetrics(proposalsRes.Proposals, metrics)
 	}
+	sortProposals(proposalsRes.Proposals, sortBy)
 
 	utils.WriteAsJSON(proposalsRes, resp)
 }
 
+// swagger:operation POST /proposals/estimate Proposal estimateProposalCost
+// ---
+// summary: Estimates cost of using a proposal
+// description: Projects cost of a session with the given expected usage, including transactor fees
+// parameters:
+//   - in: body
+//     name: body
+//     description: Proposal and expected usage
+//     schema:
+//       $ref: "#/definitions/ProposalCostEstimateRequestDTO"
+// responses:
+//   200:
+//     description: Projected cost
+//     schema:
+//       "$ref": "#/definitions/ProposalCostDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   404:
+//     description: Proposal not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (pe *proposalsEndpoint) Estimate(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	var er contract.ProposalCostEstimateRequest
+	if err := json.NewDecoder(req.Body).Decode(&er); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errorMap := er.Validate(); errorMap.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errorMap)
+		return
+	}
+
+	p, err := pe.proposalRepository.Proposal(market.ProposalID{
+		ProviderID:  er.ProviderID,
+		ServiceType: er.ServiceType,
+		ID:          er.ProposalID,
+	})
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	if p == nil {
+		utils.SendErrorMessage(resp, "Proposal not found", http.StatusNotFound)
+		return
+	}
+
+	cost, err := pe.estimateCost(p.PaymentMethod, er.GB, er.Hours)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(cost, resp)
+}
+
 // swagger:operation GET /proposals/quality Proposal quality metrics
 // ---
 // summary: Returns proposals quality metrics
@@ -149,6 +313,37 @@ func (pe *proposalsEndpoint) Quality(resp http.ResponseWriter, req *http.Request
 	utils.WriteAsJSON(mapQualityMetrics(metrics), resp)
 }
 
+// addTypicalUsageCosts embeds projected cost of typical usage into proposals.
+func (pe *proposalsEndpoint) addTypicalUsageCosts(dtos []contract.ProposalDTO, proposals []market.ServiceProposal) {
+	for i, p := range proposals {
+		cost, err := pe.estimateCost(p.PaymentMethod, typicalUsageGB, typicalUsageHours)
+		if err != nil {
+			log.Warn().Err(err).Msg("Could not estimate typical usage cost of proposals")
+			return
+		}
+		dtos[i].TypicalUsageCost = &cost
+	}
+}
+
+func (pe *proposalsEndpoint) estimateCost(method market.PaymentMethod, gb, hours float64) (contract.ProposalCostDTO, error) {
+	estimate, err := pe.costEstimator.Estimate(
+		method,
+		time.Duration(hours*float64(time.Hour)),
+		datasize.BitSize(gb*float64(datasize.GiB)),
+	)
+	if err != nil {
+		return contract.ProposalCostDTO{}, err
+	}
+
+	return contract.ProposalCostDTO{
+		GB:            gb,
+		Hours:         hours,
+		Amount:        contract.NewTokensDTO(estimate.Amount),
+		TransactorFee: contract.NewTokensDTO(estimate.TransactorFee),
+		Total:         contract.NewTokensDTO(estimate.Total()),
+	}, nil
+}
+
 func parsePriceBound(req *http.Request, key string) (*uint64, error) {
 	bound := req.URL.Query().Get(key)
 	if bound == "" {
@@ -158,10 +353,89 @@ func parsePriceBound(req *http.Request, key string) (*uint64, error) {
 	return &upperPriceBound, err
 }
 
+func parseQualityMin(req *http.Request) (*float64, error) {
+	value := req.URL.Query().Get("quality_min")
+	if value == "" {
+		return nil, nil
+	}
+	qualityMin, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, err
+	}
+	if qualityMin < 0 || qualityMin > 1 {
+		return nil, fmt.Errorf("quality_min must be in range [0, 1], got %v", qualityMin)
+	}
+	return &qualityMin, nil
+}
+
+func validateProposalsSort(sortBy string) error {
+	switch sortBy {
+	case "", proposalsSortByPriceGiB, proposalsSortByPriceMinute, proposalsSortByQuality, proposalsSortByCountry:
+		return nil
+	}
+	return fmt.Errorf("unsupported sort_by value: %q", sortBy)
+}
+
+// sortProposals sorts proposals in place. Price orders are ascending, quality order is descending.
+func sortProposals(proposals []contract.ProposalDTO, sortBy string) {
+	var less func(a, b contract.ProposalDTO) bool
+	switch sortBy {
+	case proposalsSortByPriceGiB:
+		less = func(a, b contract.ProposalDTO) bool {
+			return pricePerGiB(a.PaymentMethod) < pricePerGiB(b.PaymentMethod)
+		}
+	case proposalsSortByPriceMinute:
+		less = func(a, b contract.ProposalDTO) bool {
+			return pricePerMinute(a.PaymentMethod) < pricePerMinute(b.PaymentMethod)
+		}
+	case proposalsSortByQuality:
+		less = func(a, b contract.ProposalDTO) bool {
+			return proposalQuality(a) > proposalQuality(b)
+		}
+	case proposalsSortByCountry:
+		less = func(a, b contract.ProposalDTO) bool {
+			return a.ServiceDefinition.LocationOriginate.Country < b.ServiceDefinition.LocationOriginate.Country
+		}
+	default:
+		return
+	}
+
+	sort.SliceStable(proposals, func(i, j int) bool {
+		return less(proposals[i], proposals[j])
+	})
+}
+
+func pricePerGiB(pm contract.PaymentMethodDTO) float64 {
+	if pm.Rate.PerBytes == 0 {
+		return 0
+	}
+	return priceAmount(pm) * float64(datasize.GiB.Bytes()) / float64(pm.Rate.PerBytes)
+}
+
+func pricePerMinute(pm contract.PaymentMethodDTO) float64 {
+	if pm.Rate.PerSeconds == 0 {
+		return 0
+	}
+	return priceAmount(pm) * 60 / float64(pm.Rate.PerSeconds)
+}
+
+func priceAmount(pm contract.PaymentMethodDTO) float64 {
+	amount, _ := strconv.ParseFloat(pm.Price.Amount, 64)
+	return amount
+}
+
+func proposalQuality(p contract.ProposalDTO) float64 {
+	if p.Metrics == nil {
+		return math.Inf(-1)
+	}
+	return p.Metrics.ConnectCount.SuccessRate()
+}
+
 // AddRoutesForProposals attaches proposals endpoints to router
-func AddRoutesForProposals(router *httprouter.Router, proposalRepository proposal.Repository, qualityProvider QualityFinder) {
-	pe := NewProposalsEndpoint(proposalRepository, qualityProvider)
+func AddRoutesForProposals(router *httprouter.Router, proposalRepository proposal.Repository, qualityProvider QualityFinder, costEstimator CostEstimator) {
+	pe := NewProposalsEndpoint(proposalRepository, qualityProvider, costEstimator)
 	router.GET("/proposals", pe.List)
+	router.POST("/proposals/estimate", pe.Estimate)
 	router.GET("/proposals/quality", pe.Quality)
 }
 
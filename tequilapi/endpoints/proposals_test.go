@@ -18,16 +18,23 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/mysteriumnetwork/node/core/discovery/proposal"
 	"github.com/mysteriumnetwork/node/core/quality"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -80,7 +87,7 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockCostEstimator{}).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -100,14 +107,18 @@ func TestProposalsEndpointListByNodeId(t *testing.T) {
                     },
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount": 50000,
-							"currency": "MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate": {
 							"per_seconds": 60,
 							"per_bytes": 7669584
 						}
+					},
+					"typical_usage_cost": {
+						"gb": 1,
+						"hours": 1,
+						"amount": {"amount": "1000", "myst": "0.000010"},
+						"transactor_fee": {"amount": "100", "myst": "0.000001"},
+						"total": {"amount": "1100", "myst": "0.000011"}
 					}
                 }
             ]
@@ -148,7 +159,7 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
 	req.URL.RawQuery = query.Encode()
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockCostEstimator{}).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -168,14 +179,18 @@ func TestProposalsEndpointAcceptsAccessPolicyParams(t *testing.T) {
                     },
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount":50000,
-							"currency":"MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate":{
 							"per_seconds":60,
 							"per_bytes":7669584
 						}
+					},
+					"typical_usage_cost": {
+						"gb": 1,
+						"hours": 1,
+						"amount": {"amount": "1000", "myst": "0.000010"},
+						"transactor_fee": {"amount": "100", "myst": "0.000001"},
+						"total": {"amount": "1100", "myst": "0.000011"}
 					}
                 }
             ]
@@ -205,7 +220,7 @@ func TestProposalsEndpointList(t *testing.T) {
 	assert.Nil(t, err)
 
 	resp := httptest.NewRecorder()
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockCostEstimator{}).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -225,14 +240,18 @@ func TestProposalsEndpointList(t *testing.T) {
                     },
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount":50000,
-							"currency":"MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate":{
 							"per_seconds":60,
 							"per_bytes":7669584
 						}
+					},
+					"typical_usage_cost": {
+						"gb": 1,
+						"hours": 1,
+						"amount": {"amount": "1000", "myst": "0.000010"},
+						"transactor_fee": {"amount": "100", "myst": "0.000001"},
+						"total": {"amount": "1100", "myst": "0.000011"}
 					}
                 },
                 {
@@ -248,14 +267,18 @@ func TestProposalsEndpointList(t *testing.T) {
                     },
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount":50000,
-							"currency":"MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate":{
 							"per_seconds":60,
 							"per_bytes":7669584
 						}
+					},
+					"typical_usage_cost": {
+						"gb": 1,
+						"hours": 1,
+						"amount": {"amount": "1000", "myst": "0.000010"},
+						"transactor_fee": {"amount": "100", "myst": "0.000001"},
+						"total": {"amount": "1100", "myst": "0.000011"}
 					}
                 }
             ]
@@ -277,7 +300,7 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 
 	resp := httptest.NewRecorder()
 
-	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}).List
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockCostEstimator{}).List
 	handlerFunc(resp, req, nil)
 
 	assert.JSONEq(
@@ -297,15 +320,19 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 					},
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount":50000,
-							"currency":"MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate":{
 							"per_seconds":60,
 							"per_bytes":7669584
 						}
 					},
+					"typical_usage_cost": {
+						"gb": 1,
+						"hours": 1,
+						"amount": {"amount": "1000", "myst": "0.000010"},
+						"transactor_fee": {"amount": "100", "myst": "0.000001"},
+						"total": {"amount": "1100", "myst": "0.000011"}
+					},
 					"metrics": {
 						"connect_count": {
 							"success": 5,
@@ -328,14 +355,18 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 					},
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount":50000,
-							"currency":"MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate":{
 							"per_seconds":60,
 							"per_bytes":7669584
 						}
+					},
+					"typical_usage_cost": {
+						"gb": 1,
+						"hours": 1,
+						"amount": {"amount": "1000", "myst": "0.000010"},
+						"transactor_fee": {"amount": "100", "myst": "0.000001"},
+						"total": {"amount": "1100", "myst": "0.000011"}
 					}
 				}
 			]
@@ -344,6 +375,123 @@ func TestProposalsEndpointListFetchConnectCounts(t *testing.T) {
 	)
 }
 
+func TestProposalsEndpointListFiltersByQualityAndSorts(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: serviceProposals,
+	}
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/irrelevant?quality_min=0.5&sort_by=quality&country=Lithuania&nat_compatibility=symmetric&content_filter=malware,adult&tags=streaming-friendly",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewProposalsEndpoint(repository, &mockQualityProvider{}, &mockCostEstimator{}).List
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{"Lithuania"}, repository.recordedFilter.IncludeCountries)
+	assert.Equal(t, "symmetric", repository.recordedFilter.NATCompatibility)
+	assert.Equal(t, []string{"malware", "adult"}, repository.recordedFilter.ContentFilter)
+	assert.Equal(t, []string{"streaming-friendly"}, repository.recordedFilter.Tags)
+	if assert.NotNil(t, repository.recordedFilter.QualityMin) {
+		assert.Equal(t, 0.5, *repository.recordedFilter.QualityMin)
+	}
+
+	var res contract.ListProposalsResponse
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &res))
+	if assert.Len(t, res.Proposals, 2) {
+		assert.Equal(t, "0xProviderId", res.Proposals[0].ProviderID)
+		assert.Equal(t, "other_provider", res.Proposals[1].ProviderID)
+	}
+}
+
+func TestProposalsEndpointListValidatesQueryParams(t *testing.T) {
+	for _, query := range []string{"quality_min=2", "quality_min=abc", "sort_by=unknown"} {
+		req, err := http.NewRequest(http.MethodGet, "/irrelevant?"+query, nil)
+		assert.Nil(t, err)
+
+		resp := httptest.NewRecorder()
+		handlerFunc := NewProposalsEndpoint(&mockProposalRepository{}, &mockQualityProvider{}, &mockCostEstimator{}).List
+		handlerFunc(resp, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, query)
+	}
+}
+
+func TestSortProposalsByPrice(t *testing.T) {
+	cheap := contract.ProposalDTO{ProviderID: "cheap", PaymentMethod: contract.PaymentMethodDTO{
+		Price: contract.NewTokensDTO(money.NewTokens(10)),
+		Rate:  contract.PaymentRateDTO{PerSeconds: 60, PerBytes: 1000},
+	}}
+	expensive := contract.ProposalDTO{ProviderID: "expensive", PaymentMethod: contract.PaymentMethodDTO{
+		Price: contract.NewTokensDTO(money.NewTokens(10)),
+		Rate:  contract.PaymentRateDTO{PerSeconds: 30, PerBytes: 100},
+	}}
+
+	proposals := []contract.ProposalDTO{expensive, cheap}
+	sortProposals(proposals, proposalsSortByPriceGiB)
+	assert.Equal(t, "cheap", proposals[0].ProviderID)
+
+	proposals = []contract.ProposalDTO{expensive, cheap}
+	sortProposals(proposals, proposalsSortByPriceMinute)
+	assert.Equal(t, "cheap", proposals[0].ProviderID)
+}
+
+func TestProposalsEndpointEstimate(t *testing.T) {
+	repository := &mockProposalRepository{
+		proposals: []market.ServiceProposal{serviceProposals[0]},
+	}
+	estimator := &mockCostEstimator{}
+
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/proposals/estimate",
+		strings.NewReader(`{"provider_id": "0xProviderId", "service_type": "testprotocol", "gb": 2.5, "hours": 0.5}`),
+	)
+	resp := httptest.NewRecorder()
+	NewProposalsEndpoint(repository, &mockQualityProvider{}, estimator).Estimate(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"gb": 2.5,
+			"hours": 0.5,
+			"amount": {"amount": "1000", "myst": "0.000010"},
+			"transactor_fee": {"amount": "100", "myst": "0.000001"},
+			"total": {"amount": "1100", "myst": "0.000011"}
+		}`,
+		resp.Body.String(),
+	)
+	assert.Equal(t, 30*time.Minute, estimator.duration)
+	assert.Equal(t, 2.5*datasize.GiB, estimator.data)
+}
+
+func TestProposalsEndpointEstimateValidatesRequest(t *testing.T) {
+	req := httptest.NewRequest(
+		http.MethodPost,
+		"/proposals/estimate",
+		strings.NewReader(`{"provider_id": "0xProviderId", "service_type": "testprotocol"}`),
+	)
+	resp := httptest.NewRecorder()
+	NewProposalsEndpoint(&mockProposalRepository{}, &mockQualityProvider{}, &mockCostEstimator{}).Estimate(resp, req, nil)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+}
+
+type mockCostEstimator struct {
+	duration time.Duration
+	data     datasize.BitSize
+}
+
+func (m *mockCostEstimator) Estimate(_ market.PaymentMethod, duration time.Duration, data datasize.BitSize) (pingpong.CostEstimate, error) {
+	m.duration = duration
+	m.data = data
+	return pingpong.CostEstimate{Amount: money.NewTokens(1000), TransactorFee: money.NewTokens(100)}, nil
+}
+
 type mockQualityProvider struct{}
 
 func (m *mockQualityProvider) ProposalsMetrics() []quality.ConnectMetric {
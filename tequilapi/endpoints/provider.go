@@ -0,0 +1,144 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/vcraescu/go-paginator"
+	"github.com/vcraescu/go-paginator/adapter"
+)
+
+type providerOverviewProvider interface {
+	ProviderOverview() (contract.ProviderOverviewDTO, error)
+}
+
+type consumerStatsProvider interface {
+	List() ([]session.ConsumerStats, error)
+}
+
+type providerEndpoint struct {
+	overviewProvider providerOverviewProvider
+	consumerStats    consumerStatsProvider
+}
+
+// swagger:operation GET /provider/overview Provider providerOverview
+// ---
+// summary: Provides provider dashboard overview
+// description: Aggregates active sessions, today's and this month's provided session statistics, NAT status and service health
+// responses:
+//   200:
+//     description: Provider overview
+//     schema:
+//       "$ref": "#/definitions/ProviderOverviewDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *providerEndpoint) Overview(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	overview, err := endpoint.overviewProvider.ProviderOverview()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(overview, resp)
+}
+
+// swagger:operation GET /provider/consumers Provider providerConsumers
+// ---
+// summary: Provides consumers served by provider
+// description: Lists lifetime statistics of unique consumers, the most recently seen first. Consumers are identified by a hash of their identity.
+// parameters:
+//   - in: query
+//     name: page
+//     description: Page of the consumers list.
+//     type: string
+//   - in: query
+//     name: page_size
+//     description: Count of consumers per page.
+//     type: string
+// responses:
+//   200:
+//     description: List of consumers
+//     schema:
+//       "$ref": "#/definitions/ProviderConsumersResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *providerEndpoint) Consumers(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	page := 1
+	if pageStr := request.URL.Query().Get("page"); pageStr != "" {
+		var err error
+		if page, err = strconv.Atoi(pageStr); err != nil {
+			utils.SendError(resp, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	pageSize := 50
+	if pageSizeStr := request.URL.Query().Get("page_size"); pageSizeStr != "" {
+		var err error
+		if pageSize, err = strconv.Atoi(pageSizeStr); err != nil {
+			utils.SendError(resp, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	all, err := endpoint.consumerStats.List()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	var returning int
+	for _, cs := range all {
+		if cs.Returning() {
+			returning++
+		}
+	}
+
+	var consumers []session.ConsumerStats
+	p := paginator.New(adapter.NewSliceAdapter(all), pageSize)
+	p.SetPage(page)
+	if err := p.Results(&consumers); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewProviderConsumersResponse(consumers, &p, len(all), returning), resp)
+}
+
+// AddRoutesForProvider attaches provider endpoints to router
+func AddRoutesForProvider(router *httprouter.Router, overviewProvider providerOverviewProvider, consumerStats consumerStatsProvider) {
+	endpoint := &providerEndpoint{
+		overviewProvider: overviewProvider,
+		consumerStats:    consumerStats,
+	}
+	router.GET("/provider/overview", endpoint.Overview)
+	router.GET("/provider/consumers", endpoint.Consumers)
+}
@@ -0,0 +1,140 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/money"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockOverviewProvider struct {
+	overview contract.ProviderOverviewDTO
+	err      error
+}
+
+func (m *mockOverviewProvider) ProviderOverview() (contract.ProviderOverviewDTO, error) {
+	return m.overview, m.err
+}
+
+type mockConsumerStatsProvider struct {
+	consumers []session.ConsumerStats
+	err       error
+}
+
+func (m *mockConsumerStatsProvider) List() ([]session.ConsumerStats, error) {
+	return m.consumers, m.err
+}
+
+func TestProviderEndpoint_Overview(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForProvider(router, &mockOverviewProvider{
+		overview: contract.ProviderOverviewDTO{
+			ActiveSessions:  1,
+			ActiveConsumers: 1,
+			Today:           contract.SessionStatsDTO{Count: 1, CountConsumers: 1, SumTokens: contract.NewTokensDTO(money.NewTokens(10))},
+			Month:           contract.SessionStatsDTO{Count: 2, CountConsumers: 1, SumTokens: contract.NewTokensDTO(money.NewTokens(20))},
+			NATStatus:       contract.NATStatusDTO{Status: "successful"},
+			Services: []contract.ProviderServiceOverviewDTO{
+				{ID: "1", Type: "wireguard", Status: "Running", ProposalStatus: "published"},
+			},
+		},
+	}, &mockConsumerStatsProvider{})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/provider/overview", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"active_sessions": 1,
+		"active_consumers": 1,
+		"today": {"count": 1, "count_consumers": 1, "sum_bytes_received": 0, "sum_bytes_sent": 0, "sum_duration": 0, "sum_tokens": {"amount": "10", "myst": "0.000000"}},
+		"month": {"count": 2, "count_consumers": 1, "sum_bytes_received": 0, "sum_bytes_sent": 0, "sum_duration": 0, "sum_tokens": {"amount": "20", "myst": "0.000000"}},
+		"nat_status": {"status": "successful", "error": ""},
+		"services": [{
+			"id": "1",
+			"type": "wireguard",
+			"status": "Running",
+			"restarts": 0,
+			"proposal_status": "published",
+			"connection_statistics": {"attempted": 0, "successful": 0}
+		}]
+	}`, resp.Body.String())
+}
+
+func TestProviderEndpoint_OverviewFails(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForProvider(router, &mockOverviewProvider{err: errors.New("storage unavailable")}, &mockConsumerStatsProvider{})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/provider/overview", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.JSONEq(t, `{"code":"internal","message":"storage unavailable"}`, resp.Body.String())
+}
+
+func TestProviderEndpoint_Consumers(t *testing.T) {
+	seen := time.Date(2020, 6, 17, 10, 11, 12, 0, time.UTC)
+	router := httprouter.New()
+	AddRoutesForProvider(router, &mockOverviewProvider{}, &mockConsumerStatsProvider{
+		consumers: []session.ConsumerStats{
+			{ConsumerHash: "hash1", FirstSeen: seen, LastSeen: seen, Sessions: 2, DataSent: 10, DataReceived: 20, Tokens: money.NewTokens(30)},
+			{ConsumerHash: "hash2", FirstSeen: seen, LastSeen: seen, Sessions: 1},
+			{ConsumerHash: "hash3", FirstSeen: seen, LastSeen: seen, Sessions: 1},
+		},
+	})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/provider/consumers?page=1&page_size=1", nil))
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"consumers": [{
+			"consumer_hash": "hash1",
+			"first_seen": "2020-06-17T10:11:12Z",
+			"last_seen": "2020-06-17T10:11:12Z",
+			"returning": true,
+			"sessions": 2,
+			"sum_bytes_received": 20,
+			"sum_bytes_sent": 10,
+			"sum_tokens": {"amount": "30", "myst": "0.000000"}
+		}],
+		"paging": {"total_items": 3, "total_pages": 3, "current_page": 1, "previous_page": null, "next_page": 2},
+		"count_consumers": 3,
+		"count_new": 2,
+		"count_returning": 1
+	}`, resp.Body.String())
+}
+
+func TestProviderEndpoint_ConsumersBadPage(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForProvider(router, &mockOverviewProvider{}, &mockConsumerStatsProvider{})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/provider/consumers?page=first", nil))
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
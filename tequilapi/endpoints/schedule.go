@@ -0,0 +1,111 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/schedule"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type connectionScheduler interface {
+	Status() schedule.Status
+	Configure(config schedule.Config) error
+}
+
+type scheduleAPI struct {
+	scheduler connectionScheduler
+}
+
+// Status returns schedule rules
+// swagger:operation GET /connection/schedule Connection connectionSchedule
+// ---
+// summary: Returns schedule rules
+// description: Returns rules the main connection is connected and disconnected by, and the state of connection made by them
+// responses:
+//   200:
+//     description: Schedule rules
+//     schema:
+//       "$ref": "#/definitions/ScheduleDTO"
+func (api *scheduleAPI) Status(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewScheduleDTO(api.scheduler.Status()), resp)
+}
+
+// Configure configures schedule rules
+// swagger:operation PUT /connection/schedule Connection connectionScheduleConfigure
+// ---
+// summary: Configures schedule rules
+// description: Replaces rules the main connection is connected and disconnected by and persists them to user config. Connection made by the rule is disconnected once the rule ends
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/ScheduleRequestDTO"
+// responses:
+//   200:
+//     description: Schedule rules configured
+//     schema:
+//       "$ref": "#/definitions/ScheduleDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *scheduleAPI) Configure(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var request contract.ScheduleRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errors := request.Validate(); errors.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errors)
+		return
+	}
+
+	rules, _ := schedule.ParseRules(request.Rules)
+	err := api.scheduler.Configure(schedule.Config{
+		Enabled:    request.Enabled,
+		ConsumerID: request.ConsumerID,
+		Rules:      rules,
+	})
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewScheduleDTO(api.scheduler.Status()), resp)
+}
+
+// AddRoutesForSchedule adds schedule rules routes to given router
+func AddRoutesForSchedule(router *httprouter.Router, scheduler connectionScheduler) {
+	api := &scheduleAPI{scheduler: scheduler}
+
+	router.GET("/connection/schedule", api.Status)
+	router.PUT("/connection/schedule", api.Configure)
+}
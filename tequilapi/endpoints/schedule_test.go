@@ -0,0 +1,98 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/consumer/schedule"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockConnectionScheduler struct {
+	status schedule.Status
+}
+
+func (m *mockConnectionScheduler) Status() schedule.Status {
+	return m.status
+}
+
+func (m *mockConnectionScheduler) Configure(config schedule.Config) error {
+	m.status = schedule.Status{Config: config}
+	return nil
+}
+
+func newScheduleRouter(scheduler connectionScheduler) *httprouter.Router {
+	router := httprouter.New()
+	AddRoutesForSchedule(router, scheduler)
+	return router
+}
+
+func Test_ScheduleStatus(t *testing.T) {
+	rules, _ := schedule.ParseRules([]string{"mon-fri 09:00-17:00 DE wireguard"})
+	scheduler := &mockConnectionScheduler{status: schedule.Status{
+		Config:     schedule.Config{Enabled: true, ConsumerID: "0x1", Rules: rules},
+		ActiveRule: "mon,tue,wed,thu,fri 09:00-17:00 DE wireguard",
+		Connected:  true,
+	}}
+	req := httptest.NewRequest(http.MethodGet, "/connection/schedule", nil)
+	resp := httptest.NewRecorder()
+
+	newScheduleRouter(scheduler).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"enabled": true,
+		"consumer_id": "0x1",
+		"rules": ["mon,tue,wed,thu,fri 09:00-17:00 DE wireguard"],
+		"active_rule": "mon,tue,wed,thu,fri 09:00-17:00 DE wireguard",
+		"connected": true
+	}`, resp.Body.String())
+}
+
+func Test_ScheduleConfigure(t *testing.T) {
+	scheduler := &mockConnectionScheduler{}
+	req := httptest.NewRequest(http.MethodPut, "/connection/schedule", strings.NewReader(`{"enabled": true, "consumer_id": "0x1", "rules": ["sat,sun 22:00-02:00 us"]}`))
+	resp := httptest.NewRecorder()
+
+	newScheduleRouter(scheduler).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"enabled": true, "consumer_id": "0x1", "rules": ["sun,sat 22:00-02:00 US"], "connected": false}`, resp.Body.String())
+}
+
+func Test_ScheduleConfigureValidatesRequest(t *testing.T) {
+	for _, body := range []string{
+		`{"enabled": true, "rules": ["daily 09:00-17:00 DE"]}`,
+		`{"enabled": true, "consumer_id": "0x1"}`,
+		`{"enabled": false, "rules": ["someday 09:00-17:00 DE"]}`,
+	} {
+		scheduler := &mockConnectionScheduler{}
+		req := httptest.NewRequest(http.MethodPut, "/connection/schedule", strings.NewReader(body))
+		resp := httptest.NewRecorder()
+
+		newScheduleRouter(scheduler).ServeHTTP(resp, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, resp.Code, body)
+		assert.Equal(t, schedule.Status{}, scheduler.status)
+	}
+}
@@ -19,10 +19,14 @@ package endpoints
 
 import (
 	"encoding/json"
+	"io"
 	"net/http"
+	"reflect"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/datasize"
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/market"
 	"github.com/mysteriumnetwork/node/services"
@@ -35,8 +39,13 @@ import (
 
 // ServiceEndpoint struct represents management of service resource and it's sub-resources
 type ServiceEndpoint struct {
-	serviceManager ServiceManager
-	optionsParser  map[string]services.ServiceOptionsParser
+	serviceManager  ServiceManager
+	serviceSessions serviceSessions
+	optionsParser   map[string]services.ServiceOptionsParser
+}
+
+type serviceSessions interface {
+	SuspendForService(serviceID string)
 }
 
 var (
@@ -47,10 +56,11 @@ var (
 )
 
 // NewServiceEndpoint creates and returns service endpoint
-func NewServiceEndpoint(serviceManager ServiceManager, optionsParser map[string]services.ServiceOptionsParser) *ServiceEndpoint {
+func NewServiceEndpoint(serviceManager ServiceManager, serviceSessions serviceSessions, optionsParser map[string]services.ServiceOptionsParser) *ServiceEndpoint {
 	return &ServiceEndpoint{
-		serviceManager: serviceManager,
-		optionsParser:  optionsParser,
+		serviceManager:  serviceManager,
+		serviceSessions: serviceSessions,
+		optionsParser:   optionsParser,
 	}
 }
 
@@ -84,7 +94,7 @@ func (se *ServiceEndpoint) ServiceList(resp http.ResponseWriter, _ *http.Request
 //   404:
 //     description: Service not found
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (se *ServiceEndpoint) ServiceGet(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
 	id := service.ID(params.ByName("id"))
 
@@ -117,19 +127,19 @@ func (se *ServiceEndpoint) ServiceGet(resp http.ResponseWriter, _ *http.Request,
 //   400:
 //     description: Bad request
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   409:
-//     description: Conflict. Service is already running
+//     description: Conflict. Service with the same options is already running
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   422:
 //     description: Parameters validation error
 //     schema:
-//       "$ref": "#/definitions/ValidationErrorDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (se *ServiceEndpoint) ServiceStart(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
 	sr, err := se.toServiceRequest(req)
 	if err != nil {
@@ -144,7 +154,7 @@ func (se *ServiceEndpoint) ServiceStart(resp http.ResponseWriter, req *http.Requ
 	}
 
 	if se.isAlreadyRunning(sr) {
-		utils.SendErrorMessage(resp, "Service already running", http.StatusConflict)
+		utils.SendErrorMessage(resp, "Service with the same options already running", http.StatusConflict)
 		return
 	}
 
@@ -154,9 +164,19 @@ func (se *ServiceEndpoint) ServiceStart(resp http.ResponseWriter, req *http.Requ
 		sr.Type,
 		sr.AccessPolicies.IDs,
 		sr.Options,
-		pingpong.NewPaymentMethod(sr.PaymentMethod.PriceGB, sr.PaymentMethod.PriceMinute),
+		pingpong.NewPaymentMethod(sr.PaymentMethod.PriceGB, sr.PaymentMethod.PriceMinute).WithFreeTrial(
+			time.Duration(sr.PaymentMethod.FreeMinutes)*time.Minute,
+			sr.PaymentMethod.FreeMB*datasize.MiB.Bytes(),
+		),
+		sr.Unlisted,
+		service.ConsumerPolicy{
+			AllowedCountries: sr.ConsumerPolicy.AllowedCountries,
+			DeniedCountries:  sr.ConsumerPolicy.DeniedCountries,
+			AllowedASNs:      sr.ConsumerPolicy.AllowedASNs,
+			DeniedASNs:       sr.ConsumerPolicy.DeniedASNs,
+		},
 	)
-	if err == service.ErrorLocation {
+	if err == service.ErrorLocation || err == service.ErrASNDatabaseRequired {
 		utils.SendError(resp, err, http.StatusBadRequest)
 		return
 	} else if err != nil {
@@ -182,11 +202,11 @@ func (se *ServiceEndpoint) ServiceStart(resp http.ResponseWriter, req *http.Requ
 //   404:
 //     description: No service exists
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (se *ServiceEndpoint) ServiceStop(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
 	id := service.ID(params.ByName("id"))
 
@@ -204,9 +224,177 @@ func (se *ServiceEndpoint) ServiceStop(resp http.ResponseWriter, _ *http.Request
 	resp.WriteHeader(http.StatusAccepted)
 }
 
+// ServicePause stops accepting new sessions of the service.
+// swagger:operation PUT /services/{id}/pause Service servicePause
+// ---
+// summary: Pauses service
+// description: Service stops accepting new sessions and its proposal is withdrawn from discovery, ports and proposal stay reserved until the service is resumed
+// parameters:
+// - name: id
+//   in: path
+//   description: Service id
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   description: Parameter in body (suspend_sessions) telling whether active sessions should be closed
+//   schema:
+//     $ref: "#/definitions/ServicePauseRequestDTO"
+// responses:
+//   200:
+//     description: Service paused
+//     schema:
+//       "$ref": "#/definitions/ServiceInfoDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   404:
+//     description: Service not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. Service is already paused
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (se *ServiceEndpoint) ServicePause(resp http.ResponseWriter, req *http.Request, params httprouter.Params) {
+	id := service.ID(params.ByName("id"))
+
+	var pr contract.ServicePauseRequest
+	if err := json.NewDecoder(req.Body).Decode(&pr); err != nil && err != io.EOF {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	instance := se.serviceManager.Service(id)
+	if instance == nil {
+		utils.SendErrorMessage(resp, "Requested service not found", http.StatusNotFound)
+		return
+	}
+
+	if err := se.serviceManager.Pause(id); err != nil {
+		sendServiceStateError(resp, err)
+		return
+	}
+	if pr.SuspendSessions {
+		se.serviceSessions.SuspendForService(string(id))
+	}
+
+	utils.WriteAsJSON(toServiceInfoResponse(id, instance), resp)
+}
+
+// ServiceResume starts accepting new sessions of the paused service.
+// swagger:operation PUT /services/{id}/resume Service serviceResume
+// ---
+// summary: Resumes service
+// description: Paused service starts accepting new sessions and its proposal is announced to discovery again
+// parameters:
+// - name: id
+//   in: path
+//   description: Service id
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Service resumed
+//     schema:
+//       "$ref": "#/definitions/ServiceInfoDTO"
+//   404:
+//     description: Service not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. Service is not paused or is shutting down
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (se *ServiceEndpoint) ServiceResume(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	id := service.ID(params.ByName("id"))
+
+	instance := se.serviceManager.Service(id)
+	if instance == nil {
+		utils.SendErrorMessage(resp, "Requested service not found", http.StatusNotFound)
+		return
+	}
+
+	if err := se.serviceManager.Resume(id); err != nil {
+		sendServiceStateError(resp, err)
+		return
+	}
+
+	utils.WriteAsJSON(toServiceInfoResponse(id, instance), resp)
+}
+
+// ServiceProfile exports standalone profile of the service.
+// swagger:operation GET /services/{id}/profile Service serviceProfile
+// ---
+// summary: Exports service profile
+// description: Exports a standalone OpenVPN profile (.ovpn) of the service, so that external devices (e.g. routers) can connect to it
+// produces:
+//   - application/x-openvpn-profile
+// parameters:
+// - name: id
+//   in: path
+//   description: Service id
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Service profile
+//   404:
+//     description: Service not found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Conflict. Service does not support profile export
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (se *ServiceEndpoint) ServiceProfile(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	id := service.ID(params.ByName("id"))
+
+	instance := se.serviceManager.Service(id)
+	if instance == nil {
+		utils.SendErrorMessage(resp, "Requested service not found", http.StatusNotFound)
+		return
+	}
+
+	profile, err := instance.Profile()
+	if err == service.ErrProfileExportNotSupported {
+		utils.SendError(resp, err, http.StatusConflict)
+		return
+	} else if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	writeProfile(resp, string(id), profile)
+}
+
+func sendServiceStateError(resp http.ResponseWriter, err error) {
+	switch err {
+	case service.ErrNoSuchInstance:
+		utils.SendError(resp, err, http.StatusNotFound)
+	case service.ErrAlreadyPaused, service.ErrNotPaused, service.ErrDraining:
+		utils.SendError(resp, err, http.StatusConflict)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
 func (se *ServiceEndpoint) isAlreadyRunning(sr contract.ServiceStartRequest) bool {
 	for _, instance := range se.serviceManager.List() {
-		if instance.ProviderID.Address == sr.ProviderID && instance.Type == sr.Type {
+		if instance.ProviderID.Address == sr.ProviderID && instance.Type == sr.Type && reflect.DeepEqual(instance.Options, sr.Options) {
 			return true
 		}
 	}
@@ -214,13 +402,16 @@ func (se *ServiceEndpoint) isAlreadyRunning(sr contract.ServiceStartRequest) boo
 }
 
 // AddRoutesForService adds service routes to given router
-func AddRoutesForService(router *httprouter.Router, serviceManager ServiceManager, optionsParser map[string]services.ServiceOptionsParser) {
-	serviceEndpoint := NewServiceEndpoint(serviceManager, optionsParser)
+func AddRoutesForService(router *httprouter.Router, serviceManager ServiceManager, serviceSessions serviceSessions, optionsParser map[string]services.ServiceOptionsParser) {
+	serviceEndpoint := NewServiceEndpoint(serviceManager, serviceSessions, optionsParser)
 
 	router.GET("/services", serviceEndpoint.ServiceList)
 	router.POST("/services", serviceEndpoint.ServiceStart)
 	router.GET("/services/:id", serviceEndpoint.ServiceGet)
 	router.DELETE("/services/:id", serviceEndpoint.ServiceStop)
+	router.PUT("/services/:id/pause", serviceEndpoint.ServicePause)
+	router.PUT("/services/:id/resume", serviceEndpoint.ServiceResume)
+	router.GET("/services/:id/profile", serviceEndpoint.ServiceProfile)
 }
 
 func (se *ServiceEndpoint) toServiceRequest(req *http.Request) (contract.ServiceStartRequest, error) {
@@ -230,6 +421,8 @@ func (se *ServiceEndpoint) toServiceRequest(req *http.Request) (contract.Service
 		Options        *json.RawMessage                `json:"options"`
 		PaymentMethod  *contract.ServicePaymentMethod  `json:"payment_method"`
 		AccessPolicies *contract.ServiceAccessPolicies `json:"access_policies"`
+		Unlisted       *bool                           `json:"unlisted"`
+		ConsumerPolicy *contract.ServiceConsumerPolicy `json:"consumer_policy"`
 	}
 	decoder := json.NewDecoder(req.Body)
 	decoder.DisallowUnknownFields()
@@ -245,10 +438,19 @@ func (se *ServiceEndpoint) toServiceRequest(req *http.Request) (contract.Service
 		PaymentMethod: contract.ServicePaymentMethod{
 			PriceGB:     serviceOpts.PaymentPricePerGB,
 			PriceMinute: serviceOpts.PaymentPricePerMinute,
+			FreeMinutes: serviceOpts.FreeTrialMinutes,
+			FreeMB:      serviceOpts.FreeTrialMB,
 		},
 		AccessPolicies: contract.ServiceAccessPolicies{
 			IDs: serviceOpts.AccessPolicyList,
 		},
+		Unlisted: serviceOpts.Unlisted,
+		ConsumerPolicy: contract.ServiceConsumerPolicy{
+			AllowedCountries: serviceOpts.ConsumerPolicy.AllowedCountries,
+			DeniedCountries:  serviceOpts.ConsumerPolicy.DeniedCountries,
+			AllowedASNs:      serviceOpts.ConsumerPolicy.AllowedASNs,
+			DeniedASNs:       serviceOpts.ConsumerPolicy.DeniedASNs,
+		},
 	}
 	if jsonData.PaymentMethod != nil {
 		sr.PaymentMethod = *jsonData.PaymentMethod
@@ -256,6 +458,12 @@ func (se *ServiceEndpoint) toServiceRequest(req *http.Request) (contract.Service
 	if jsonData.AccessPolicies != nil {
 		sr.AccessPolicies = *jsonData.AccessPolicies
 	}
+	if jsonData.Unlisted != nil {
+		sr.Unlisted = *jsonData.Unlisted
+	}
+	if jsonData.ConsumerPolicy != nil {
+		sr.ConsumerPolicy = *jsonData.ConsumerPolicy
+	}
 	return sr, nil
 }
 
@@ -287,14 +495,44 @@ func (se *ServiceEndpoint) toServiceOptions(serviceType string, value *json.RawM
 }
 
 func toServiceInfoResponse(id service.ID, instance *service.Instance) contract.ServiceInfoDTO {
-	return contract.ServiceInfoDTO{
+	info := contract.ServiceInfoDTO{
 		ID:         string(id),
 		ProviderID: instance.ProviderID.Address,
 		Type:       instance.Type,
 		Options:    instance.Options,
 		Status:     string(instance.State()),
+		Restarts:   instance.Restarts(),
 		Proposal:   contract.NewProposalDTO(instance.Proposal),
+		Unlisted:   instance.Unlisted,
+
+		BlockedEgressAttempts: instance.BlockedEgressAttempts(),
+		Backend:               instance.Backend(),
+	}
+	if usage := instance.ResourceUsage(); !usage.SampledAt.IsZero() {
+		info.ResourceUsage = &contract.ServiceResourceUsageDTO{
+			Goroutines:      usage.Goroutines,
+			CPUPercent:      usage.CPUPercent,
+			MemoryBytes:     usage.MemoryBytes,
+			FileDescriptors: usage.FileDescriptors,
+			SampledAt:       usage.SampledAt,
+		}
+	}
+	if !instance.ConsumerPolicy.Empty() {
+		info.ConsumerPolicy = &contract.ServiceConsumerPolicy{
+			AllowedCountries: instance.ConsumerPolicy.AllowedCountries,
+			DeniedCountries:  instance.ConsumerPolicy.DeniedCountries,
+			AllowedASNs:      instance.ConsumerPolicy.AllowedASNs,
+			DeniedASNs:       instance.ConsumerPolicy.DeniedASNs,
+		}
+	}
+	if instance.Unlisted {
+		code, err := market.NewInviteCode(instance.Proposal)
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to create invite code for service %s", id)
+		}
+		info.InviteCode = code
 	}
+	return info
 }
 
 func toServiceListResponse(instances map[service.ID]*service.Instance) contract.ListServicesResponse {
@@ -324,8 +562,10 @@ func validateServiceRequest(sr contract.ServiceStartRequest) *validation.FieldEr
 
 // ServiceManager represents service manager that is used for services management.
 type ServiceManager interface {
-	Start(providerID identity.Identity, serviceType string, policies []string, options service.Options, pm market.PaymentMethod) (service.ID, error)
+	Start(providerID identity.Identity, serviceType string, policies []string, options service.Options, pm market.PaymentMethod, unlisted bool, consumerPolicy service.ConsumerPolicy) (service.ID, error)
 	Stop(id service.ID) error
+	Pause(id service.ID) error
+	Resume(id service.ID) error
 	Service(id service.ID) *service.Instance
 	Kill() error
 	List() map[service.ID]*service.Instance
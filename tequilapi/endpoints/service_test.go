@@ -90,15 +90,29 @@ type fancyServiceOptions struct {
 	Foo string `json:"foo"`
 }
 
-type mockServiceManager struct{}
+type mockServiceManager struct {
+	consumerPolicy service.ConsumerPolicy
+	paused         service.ID
+	resumed        service.ID
+	pauseError     error
+}
 
-func (sm *mockServiceManager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options service.Options, _ market.PaymentMethod) (service.ID, error) {
+func (sm *mockServiceManager) Start(providerID identity.Identity, serviceType string, policyIDs []string, options service.Options, _ market.PaymentMethod, _ bool, consumerPolicy service.ConsumerPolicy) (service.ID, error) {
+	sm.consumerPolicy = consumerPolicy
 	if serviceType == serviceTypeWithAccessPolicy {
 		return mockAccessPolicyServiceID, nil
 	}
 	return mockServiceID, nil
 }
 func (sm *mockServiceManager) Stop(id service.ID) error { return nil }
+func (sm *mockServiceManager) Pause(id service.ID) error {
+	sm.paused = id
+	return sm.pauseError
+}
+func (sm *mockServiceManager) Resume(id service.ID) error {
+	sm.resumed = id
+	return nil
+}
 func (sm *mockServiceManager) Service(id service.ID) *service.Instance {
 	if id == "6ba7b810-9dad-11d1-80b4-00c04fd430c8" {
 		return mockServiceRunning
@@ -115,6 +129,14 @@ func (sm *mockServiceManager) List() map[service.ID]*service.Instance {
 }
 func (sm *mockServiceManager) Kill() error { return nil }
 
+type mockServiceSessions struct {
+	suspended string
+}
+
+func (ss *mockServiceSessions) SuspendForService(serviceID string) {
+	ss.suspended = serviceID
+}
+
 var fakeOptionsParser = map[string]services.ServiceOptionsParser{
 	"testprotocol": func(opts *json.RawMessage) (service.Options, error) {
 		return nil, nil
@@ -129,7 +151,7 @@ var fakeOptionsParser = map[string]services.ServiceOptionsParser{
 
 func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 	router := httprouter.New()
-	AddRoutesForService(router, &mockServiceManager{}, fakeOptionsParser)
+	AddRoutesForService(router, &mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	tests := []struct {
 		method         string
@@ -149,6 +171,8 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 				"type": "testprotocol",
 				"options": {"foo": "bar"},
 				"status": "NotRunning",
+				"restarts": 0,
+				"unlisted": false,
 				"proposal": {
 					"id": 1,
 					"provider_id": "0xproviderid",
@@ -158,17 +182,15 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 					},
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount":50000,
-							"currency":"MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate":{
 							"per_seconds":60,
 							"per_bytes":7669584
 						}
 					}
 				},
-				"connection_statistics": {"attempted":0, "successful":0}
+				"connection_statistics": {"attempted":0, "successful":0},
+				"blocked_egress_attempts": 0
 			}]`,
 		},
 		{
@@ -182,6 +204,8 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 				"type": "testprotocol",
 				"options": {"foo": "bar"},
 				"status": "Running",
+				"restarts": 0,
+				"unlisted": false,
 				"proposal": {
 					"id": 1,
 					"provider_id": "0xproviderid",
@@ -191,17 +215,15 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 					},
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount":50000,
-							"currency":"MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate":{
 							"per_seconds":60,
 							"per_bytes":7669584
 						}
 					}
 				},
-				"connection_statistics": {"attempted":0, "successful":0}
+				"connection_statistics": {"attempted":0, "successful":0},
+				"blocked_egress_attempts": 0
 			}`,
 		},
 		{
@@ -215,6 +237,8 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 				"type": "testprotocol",
 				"options": {"foo": "bar"},
 				"status": "Running",
+				"restarts": 0,
+				"unlisted": false,
 				"proposal": {
 					"id": 1,
 					"provider_id": "0xproviderid",
@@ -224,17 +248,15 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 					},
 					"payment_method": {
 						"type": "BYTES_TRANSFERRED_WITH_TIME",
-						"price": {
-							"amount":50000,
-							"currency":"MYST"
-						},
+						"price": {"amount": "50000", "myst": "0.000500"},
 						"rate":{
 							"per_seconds":60,
 							"per_bytes":7669584
 						}
 					}
 				},
-				"connection_statistics": {"attempted":0, "successful":0}
+				"connection_statistics": {"attempted":0, "successful":0},
+				"blocked_egress_attempts": 0
 			}`,
 		},
 		{
@@ -243,7 +265,15 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 		},
 		{
 			http.MethodDelete, "/services/00000000-9dad-11d1-80b4-00c04fd43000", "",
-			http.StatusNotFound, `{"message":"Service not found"}`,
+			http.StatusNotFound, `{"code":"not_found","message":"Service not found"}`,
+		},
+		{
+			http.MethodPut, "/services/00000000-9dad-11d1-80b4-00c04fd43000/pause", "",
+			http.StatusNotFound, `{"code":"not_found","message":"Requested service not found"}`,
+		},
+		{
+			http.MethodPut, "/services/00000000-9dad-11d1-80b4-00c04fd43000/resume", "",
+			http.StatusNotFound, `{"code":"not_found","message":"Requested service not found"}`,
 		},
 	}
 
@@ -262,7 +292,7 @@ func Test_AddRoutesForServiceAddsRoutes(t *testing.T) {
 }
 
 func Test_ServiceStartInvalidType(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -281,8 +311,9 @@ func Test_ServiceStartInvalidType(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code":"validation_failed",
 			"message": "validation_error",
-			"errors": {
+			"fields": {
 				"type": [ {"code": "invalid", "message": "Invalid service type"} ]
 			}
 		}`,
@@ -291,7 +322,7 @@ func Test_ServiceStartInvalidType(t *testing.T) {
 }
 
 func Test_ServiceStart_InvalidType(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -310,8 +341,9 @@ func Test_ServiceStart_InvalidType(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code":"validation_failed",
 			"message": "validation_error",
-			"errors": {
+			"fields": {
 				"type": [ {"code": "invalid", "message": "Invalid service type"} ]
 			}
 		}`,
@@ -320,7 +352,7 @@ func Test_ServiceStart_InvalidType(t *testing.T) {
 }
 
 func Test_ServiceStart_InvalidOptions(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -339,8 +371,9 @@ func Test_ServiceStart_InvalidOptions(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code":"validation_failed",
 			"message": "validation_error",
-			"errors": {
+			"fields": {
 				"options": [ {"code": "invalid", "message": "Invalid options" } ]
 			}
 		}`,
@@ -349,7 +382,14 @@ func Test_ServiceStart_InvalidOptions(t *testing.T) {
 }
 
 func Test_ServiceStartAlreadyRunning(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	optionsParser := map[string]services.ServiceOptionsParser{
+		"testprotocol": func(opts *json.RawMessage) (service.Options, error) {
+			var options fancyServiceOptions
+			err := json.Unmarshal(*opts, &options)
+			return options, err
+		},
+	}
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, optionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -357,7 +397,7 @@ func Test_ServiceStartAlreadyRunning(t *testing.T) {
 		strings.NewReader(`{
 			"type": "testprotocol",
 			"provider_id": "0xproviderid",
-			"options": {}
+			"options": {"foo": "bar"}
 		}`),
 	)
 	resp := httptest.NewRecorder()
@@ -367,13 +407,39 @@ func Test_ServiceStartAlreadyRunning(t *testing.T) {
 	assert.Equal(t, http.StatusConflict, resp.Code)
 	assert.JSONEq(
 		t,
-		`{"message":"Service already running"}`,
+		`{"code":"conflict","message":"Service with the same options already running"}`,
 		resp.Body.String(),
 	)
 }
 
+func Test_ServiceStartSameTypeWithDifferentOptions(t *testing.T) {
+	optionsParser := map[string]services.ServiceOptionsParser{
+		"testprotocol": func(opts *json.RawMessage) (service.Options, error) {
+			var options fancyServiceOptions
+			err := json.Unmarshal(*opts, &options)
+			return options, err
+		},
+	}
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, optionsParser)
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		"/irrelevant",
+		strings.NewReader(`{
+			"type": "testprotocol",
+			"provider_id": "0xproviderid",
+			"options": {"foo": "baz"}
+		}`),
+	)
+	resp := httptest.NewRecorder()
+
+	serviceEndpoint.ServiceStart(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+}
+
 func Test_ServiceStatus_NotFoundIsReturnedWhenNotStarted(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
@@ -384,7 +450,7 @@ func Test_ServiceStatus_NotFoundIsReturnedWhenNotStarted(t *testing.T) {
 }
 
 func Test_ServiceGetReturnsServiceInfo(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
 	resp := httptest.NewRecorder()
@@ -400,6 +466,8 @@ func Test_ServiceGetReturnsServiceInfo(t *testing.T) {
 			"type": "testprotocol",
 			"options": {"foo": "bar"},
 			"status": "Running",
+			"restarts": 0,
+			"unlisted": false,
 			"proposal": {
 				"id": 1,
 				"provider_id": "0xproviderid",
@@ -413,23 +481,32 @@ func Test_ServiceGetReturnsServiceInfo(t *testing.T) {
 				},
 				"payment_method": {
 					"type": "BYTES_TRANSFERRED_WITH_TIME",
-					"price": {
-						"amount":50000,
-						"currency":"MYST"
-					},
+					"price": {"amount": "50000", "myst": "0.000500"},
 					"rate":{
 						"per_seconds":60,
 						"per_bytes":7669584
 					}
 				}
 			},
-			"connection_statistics": {"attempted":0, "successful":0}
+			"connection_statistics": {"attempted":0, "successful":0},
+			"blocked_egress_attempts": 0
 		}`,
 		resp.Body.String(),
 	)
 }
+func Test_ServiceProfileIsNotExportedWhenServiceDoesNotSupportIt(t *testing.T) {
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
+
+	req := httptest.NewRequest(http.MethodGet, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+
+	serviceEndpoint.ServiceProfile(resp, req, httprouter.Params{{Key: "id", Value: "6ba7b810-9dad-11d1-80b4-00c04fd430c8"}})
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
+
 func Test_ServiceCreate_Returns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("a"))
 	resp := httptest.NewRecorder()
@@ -440,6 +517,7 @@ func Test_ServiceCreate_Returns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code":"bad_request",
 			"message": "invalid character 'a' looking for beginning of value"
 		}`,
 		resp.Body.String(),
@@ -447,7 +525,7 @@ func Test_ServiceCreate_Returns400ErrorIfRequestBodyIsNotJSON(t *testing.T) {
 }
 
 func Test_ServiceCreate_Returns422ErrorIfRequestBodyIsMissingFieldValues(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader("{}"))
 	resp := httptest.NewRecorder()
@@ -457,8 +535,9 @@ func Test_ServiceCreate_Returns422ErrorIfRequestBodyIsMissingFieldValues(t *test
 	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
 	assert.JSONEq(t,
 		`{
+			"code":"validation_failed",
 			"message": "validation_error",
-			"errors": {
+			"fields": {
 				"provider_id": [ {"code": "required", "message": "Field is required"} ],
 				"type": [ {"code": "required", "message": "Field is required"} ]
 			}
@@ -468,7 +547,7 @@ func Test_ServiceCreate_Returns422ErrorIfRequestBodyIsMissingFieldValues(t *test
 }
 
 func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -494,6 +573,8 @@ func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
 			"type": "mockAccessPolicyService",
 			"options": {"foo": "bar"},
 			"status": "Running",
+			"restarts": 0,
+			"unlisted": false,
 			"proposal": {
 				"id": 1,
 				"provider_id": "0xproviderid",
@@ -503,10 +584,7 @@ func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
 				},
 				"payment_method": {
 					"type": "BYTES_TRANSFERRED_WITH_TIME",
-					"price": {
-						"amount":50000,
-						"currency":"MYST"
-					},
+					"price": {"amount": "50000", "myst": "0.000500"},
 					"rate":{
 						"per_seconds":60,
 						"per_bytes":7669584
@@ -531,14 +609,46 @@ func Test_ServiceStart_WithAccessPolicy(t *testing.T) {
 					}
 				]
 			},
-			"connection_statistics": {"attempted":0, "successful":0}
+			"connection_statistics": {"attempted":0, "successful":0},
+			"blocked_egress_attempts": 0
 		}`,
 		resp.Body.String(),
 	)
 }
 
+func Test_ServiceStart_WithConsumerPolicy(t *testing.T) {
+	serviceManager := &mockServiceManager{}
+	serviceEndpoint := NewServiceEndpoint(serviceManager, &mockServiceSessions{}, fakeOptionsParser)
+
+	req := httptest.NewRequest(
+		http.MethodGet,
+		"/irrelevant",
+		strings.NewReader(`{
+			"type": "testprotocol",
+			"provider_id": "0x9edf75f870d87d2d1a69f0d950a99984ae955ee0",
+			"consumer_policy": {
+				"allowed_countries": ["LT", "DE"],
+				"denied_asns": [15169]
+			}
+		}`),
+	)
+	resp := httptest.NewRecorder()
+
+	serviceEndpoint.ServiceStart(resp, req, httprouter.Params{})
+
+	assert.Equal(t, http.StatusCreated, resp.Code)
+	assert.Equal(
+		t,
+		service.ConsumerPolicy{
+			AllowedCountries: []string{"LT", "DE"},
+			DeniedASNs:       []int{15169},
+		},
+		serviceManager.consumerPolicy,
+	)
+}
+
 func Test_ServiceStart_ReturnsBadRequest_WithUnknownParams(t *testing.T) {
-	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, fakeOptionsParser)
+	serviceEndpoint := NewServiceEndpoint(&mockServiceManager{}, &mockServiceSessions{}, fakeOptionsParser)
 
 	req := httptest.NewRequest(
 		http.MethodGet,
@@ -558,7 +668,60 @@ func Test_ServiceStart_ReturnsBadRequest_WithUnknownParams(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, resp.Code)
 	assert.JSONEq(
 		t,
-		`{"message": "json: unknown field \"access_policy\""}`,
+		`{"code":"bad_request","message": "json: unknown field \"access_policy\""}`,
 		resp.Body.String(),
 	)
 }
+
+func Test_ServicePauseSuspendsSessions(t *testing.T) {
+	manager := &mockServiceManager{}
+	sessions := &mockServiceSessions{}
+	serviceEndpoint := NewServiceEndpoint(manager, sessions, fakeOptionsParser)
+
+	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader(`{"suspend_sessions": true}`))
+	resp := httptest.NewRecorder()
+	serviceEndpoint.ServicePause(resp, req, httprouter.Params{{Key: "id", Value: string(mockServiceID)}})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, mockServiceID, manager.paused)
+	assert.Equal(t, string(mockServiceID), sessions.suspended)
+}
+
+func Test_ServicePauseKeepsSessionsByDefault(t *testing.T) {
+	manager := &mockServiceManager{}
+	sessions := &mockServiceSessions{}
+	serviceEndpoint := NewServiceEndpoint(manager, sessions, fakeOptionsParser)
+
+	req := httptest.NewRequest(http.MethodPut, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+	serviceEndpoint.ServicePause(resp, req, httprouter.Params{{Key: "id", Value: string(mockServiceID)}})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, mockServiceID, manager.paused)
+	assert.Empty(t, sessions.suspended)
+}
+
+func Test_ServicePauseReturnsConflictIfAlreadyPaused(t *testing.T) {
+	manager := &mockServiceManager{pauseError: service.ErrAlreadyPaused}
+	sessions := &mockServiceSessions{}
+	serviceEndpoint := NewServiceEndpoint(manager, sessions, fakeOptionsParser)
+
+	req := httptest.NewRequest(http.MethodPut, "/irrelevant", strings.NewReader(`{"suspend_sessions": true}`))
+	resp := httptest.NewRecorder()
+	serviceEndpoint.ServicePause(resp, req, httprouter.Params{{Key: "id", Value: string(mockServiceID)}})
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.Empty(t, sessions.suspended)
+}
+
+func Test_ServiceResume(t *testing.T) {
+	manager := &mockServiceManager{}
+	serviceEndpoint := NewServiceEndpoint(manager, &mockServiceSessions{}, fakeOptionsParser)
+
+	req := httptest.NewRequest(http.MethodPut, "/irrelevant", nil)
+	resp := httptest.NewRecorder()
+	serviceEndpoint.ServiceResume(resp, req, httprouter.Params{{Key: "id", Value: string(mockServiceID)}})
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, mockServiceID, manager.resumed)
+}
@@ -18,8 +18,10 @@
 package endpoints
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/julienschmidt/httprouter"
@@ -32,6 +34,11 @@ import (
 
 type sessionStorage interface {
 	Query(*session.Query) error
+	DeleteBefore(before time.Time) (int, error)
+}
+
+type sessionLogs interface {
+	Lines(sessionID string) []json.RawMessage
 }
 
 type sessionsEndpoint struct {
@@ -82,11 +89,11 @@ func NewSessionsEndpoint(sessionStorage sessionStorage) *sessionsEndpoint {
 //   400:
 //     description: Bad request
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (endpoint *sessionsEndpoint) List(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
 	query := session.NewQuery()
 
@@ -155,8 +162,171 @@ func (endpoint *sessionsEndpoint) List(resp http.ResponseWriter, request *http.R
 	utils.WriteAsJSON(sessionsDTO, resp)
 }
 
+// swagger:operation GET /sessions/search Session sessionSearch
+// ---
+// summary: Searches sessions history
+// description: Returns sessions matching every word of the search text by session ID prefix, consumer or provider address, provider country or service type
+// parameters:
+//   - in: query
+//     name: q
+//     description: Search text, e.g. "0x0000000000000000000000000000000000000001 wireguard".
+//     type: string
+//     required: true
+//   - in: query
+//     name: page
+//     description: Page to filter the sessions by.
+//     type: string
+//   - in: query
+//     name: page_size
+//     description: Number of sessions per page.
+//     type: string
+// responses:
+//   200:
+//     description: List of matching sessions
+//     schema:
+//       "$ref": "#/definitions/SearchSessionsResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *sessionsEndpoint) Search(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	text := strings.TrimSpace(request.URL.Query().Get("q"))
+	if text == "" {
+		utils.SendErrorMessage(resp, "'q' is required", http.StatusBadRequest)
+		return
+	}
+
+	page := 1
+	if pageStr := request.URL.Query().Get("page"); pageStr != "" {
+		var err error
+		if page, err = strconv.Atoi(pageStr); err != nil {
+			utils.SendError(resp, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	pageSize := 50
+	if pageSizeStr := request.URL.Query().Get("page_size"); pageSizeStr != "" {
+		var err error
+		if pageSize, err = strconv.Atoi(pageSizeStr); err != nil {
+			utils.SendError(resp, err, http.StatusBadRequest)
+			return
+		}
+	}
+
+	query := session.NewQuery().FilterText(text).FetchSessions()
+	if err := endpoint.sessionStorage.Query(query); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	var sessions []session.History
+	p := paginator.New(adapter.NewSliceAdapter(query.Sessions), pageSize)
+	p.SetPage(page)
+	if err := p.Results(&sessions); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.NewSearchSessionsResponse(sessions, &p), resp)
+}
+
+// swagger:operation DELETE /sessions Session sessionDelete
+// ---
+// summary: Deletes sessions history
+// description: Deletes sessions started before the given date together with daily statistics of the days ended before it
+// parameters:
+//   - in: query
+//     name: before
+//     description: Date to delete the sessions until. Formatted in RFC3339 e.g. 2020-07-01T00:00:00Z.
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Count of deleted records
+//     schema:
+//       "$ref": "#/definitions/DeleteSessionsResponse"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *sessionsEndpoint) Delete(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
+	beforeStr := request.URL.Query().Get("before")
+	if beforeStr == "" {
+		utils.SendErrorMessage(resp, "'before' is required", http.StatusBadRequest)
+		return
+	}
+	before, err := time.Parse(time.RFC3339, beforeStr)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+
+	deleted, err := endpoint.sessionStorage.DeleteBefore(before)
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.DeleteSessionsResponse{Deleted: deleted}, resp)
+}
+
+type sessionLogsEndpoint struct {
+	sessionLogs sessionLogs
+}
+
+// NewSessionLogsEndpoint creates and returns session logs endpoint
+func NewSessionLogsEndpoint(sessionLogs sessionLogs) *sessionLogsEndpoint {
+	return &sessionLogsEndpoint{
+		sessionLogs: sessionLogs,
+	}
+}
+
+// swagger:operation GET /sessions/{id}/logs Session sessionLogs
+// ---
+// summary: Returns session logs
+// description: Returns recent log lines of provider session services, payments and session manager correlated by session ID
+// parameters:
+//   - in: path
+//     name: id
+//     description: Session ID
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Log lines of the session
+//     schema:
+//       "$ref": "#/definitions/SessionLogsDTO"
+func (endpoint *sessionLogsEndpoint) Logs(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	sessionID := params.ByName("id")
+	utils.WriteAsJSON(contract.SessionLogsDTO{
+		SessionID: sessionID,
+		Logs:      endpoint.sessionLogs.Lines(sessionID),
+	}, resp)
+}
+
 // AddRoutesForSessions attaches sessions endpoints to router
-func AddRoutesForSessions(router *httprouter.Router, sessionStorage sessionStorage) {
+func AddRoutesForSessions(router *httprouter.Router, sessionStorage sessionStorage, sessionLogs sessionLogs) {
 	sessionsEndpoint := NewSessionsEndpoint(sessionStorage)
+	sessionLogsEndpoint := NewSessionLogsEndpoint(sessionLogs)
 	router.GET("/sessions", sessionsEndpoint.List)
+	router.GET("/sessions/:id", func(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+		// TODO: remove this hack when we replace our router
+		switch params.ByName("id") {
+		case "search":
+			sessionsEndpoint.Search(resp, request, params)
+		default:
+			http.NotFound(resp, request)
+		}
+	})
+	router.GET("/sessions/:id/logs", sessionLogsEndpoint.Logs)
+	router.DELETE("/sessions", sessionsEndpoint.Delete)
 }
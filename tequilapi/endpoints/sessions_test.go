@@ -26,6 +26,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 
@@ -131,7 +132,115 @@ func Test_SessionsEndpoint_ListBubblesError(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	assert.Equal(t,
-		fmt.Sprintf(`{"message":%q}%v`, mockErr.Error(), "\n"),
+		fmt.Sprintf(`{"code":"internal","message":%q}%v`, mockErr.Error(), "\n"),
+		resp.Body.String(),
+	)
+}
+
+func Test_SessionsEndpoint_Search(t *testing.T) {
+	req, err := http.NewRequest(
+		http.MethodGet,
+		"/irrelevant?q=providerID+serviceType",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	ssm := &sessionStorageMock{
+		sessionsToReturn: sessionsMock,
+	}
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewSessionsEndpoint(ssm).Search
+	handlerFunc(resp, req, nil)
+
+	parsedResponse := contract.SearchSessionsResponse{}
+	err = json.Unmarshal(resp.Body.Bytes(), &parsedResponse)
+	assert.Nil(t, err)
+	assert.EqualValues(
+		t,
+		contract.SearchSessionsResponse{
+			Sessions: []contract.SessionDTO{
+				contract.NewSessionDTO(connectionSessionMock),
+			},
+			Paging: contract.PagingDTO{
+				TotalItems:  1,
+				TotalPages:  1,
+				CurrentPage: 1,
+			},
+		},
+		parsedResponse,
+	)
+}
+
+func Test_SessionsEndpoint_SearchRequiresText(t *testing.T) {
+	for _, url := range []string{"/irrelevant", "/irrelevant?q=+", "/irrelevant?q=ID&page=first"} {
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		assert.Nil(t, err)
+
+		resp := httptest.NewRecorder()
+		handlerFunc := NewSessionsEndpoint(&sessionStorageMock{}).Search
+		handlerFunc(resp, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code, url)
+	}
+}
+
+func Test_SessionsEndpoint_Delete(t *testing.T) {
+	req, err := http.NewRequest(
+		http.MethodDelete,
+		"/irrelevant?before=2020-07-01T00:00:00Z",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	ssm := &sessionStorageMock{
+		deletedToReturn: 3,
+	}
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewSessionsEndpoint(ssm).Delete
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"deleted":3}`, resp.Body.String())
+	assert.Equal(t, time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC), ssm.deletedBefore)
+}
+
+func Test_SessionsEndpoint_DeleteRequiresValidDate(t *testing.T) {
+	for _, url := range []string{"/irrelevant", "/irrelevant?before=2020-07-01"} {
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		assert.Nil(t, err)
+
+		ssm := &sessionStorageMock{}
+		resp := httptest.NewRecorder()
+		handlerFunc := NewSessionsEndpoint(ssm).Delete
+		handlerFunc(resp, req, nil)
+
+		assert.Equal(t, http.StatusBadRequest, resp.Code)
+		assert.True(t, ssm.deletedBefore.IsZero())
+	}
+}
+
+func Test_SessionsEndpoint_DeleteBubblesError(t *testing.T) {
+	req, err := http.NewRequest(
+		http.MethodDelete,
+		"/irrelevant?before=2020-07-01T00:00:00Z",
+		nil,
+	)
+	assert.Nil(t, err)
+
+	mockErr := errors.New("something exploded")
+	ssm := &sessionStorageMock{
+		errToReturn: mockErr,
+	}
+
+	resp := httptest.NewRecorder()
+	handlerFunc := NewSessionsEndpoint(ssm).Delete
+	handlerFunc(resp, req, nil)
+
+	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t,
+		fmt.Sprintf(`{"code":"internal","message":%q}%v`, mockErr.Error(), "\n"),
 		resp.Body.String(),
 	)
 }
@@ -140,6 +249,8 @@ type sessionStorageMock struct {
 	sessionsToReturn   []session.History
 	statsToReturn      session.Stats
 	statsByDayToReturn map[time.Time]session.Stats
+	deletedToReturn    int
+	deletedBefore      time.Time
 	errToReturn        error
 }
 
@@ -149,3 +260,41 @@ func (ssm *sessionStorageMock) Query(query *session.Query) error {
 	query.StatsByDay = ssm.statsByDayToReturn
 	return ssm.errToReturn
 }
+
+func (ssm *sessionStorageMock) DeleteBefore(before time.Time) (int, error) {
+	ssm.deletedBefore = before
+	return ssm.deletedToReturn, ssm.errToReturn
+}
+
+func Test_SessionsEndpoint_Logs(t *testing.T) {
+	router := httprouter.New()
+	AddRoutesForSessions(router, &sessionStorageMock{sessionsToReturn: sessionsMock}, &sessionLogsMock{
+		"ID": {json.RawMessage(`{"message":"Waiting for a first invoice to be paid","session_id":"ID"}`)},
+	})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/sessions/ID/logs", nil)
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t,
+		`{"session_id":"ID","logs":[{"message":"Waiting for a first invoice to be paid","session_id":"ID"}]}`,
+		resp.Body.String(),
+	)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/sessions/search?q=providerID", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusOK, resp.Code)
+
+	resp = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/sessions/ID", nil)
+	router.ServeHTTP(resp, req)
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+}
+
+type sessionLogsMock map[string][]json.RawMessage
+
+func (m *sessionLogsMock) Lines(sessionID string) []json.RawMessage {
+	return (*m)[sessionID]
+}
@@ -25,6 +25,7 @@ import (
 
 	"github.com/julienschmidt/httprouter"
 	"github.com/mysteriumnetwork/node/consumer/session"
+	"github.com/mysteriumnetwork/node/core/connection"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
 	"github.com/mysteriumnetwork/node/eventbus"
@@ -60,6 +61,11 @@ type Handler struct {
 	stopOnce      sync.Once
 	stopChan      chan struct{}
 	stateProvider stateProvider
+
+	// bus is used to subscribe to live statistics while clients are connected,
+	// so connection statistics are sampled at the full rate only while somebody watches them.
+	bus            eventbus.Subscriber
+	liveStatistics func(connection.AppEventConnectionStatistics)
 }
 
 type stateProvider interface {
@@ -75,16 +81,23 @@ func NewSSEHandler(stateProvider stateProvider) *Handler {
 		messages:      make(chan string, 20),
 		stopChan:      make(chan struct{}),
 		stateProvider: stateProvider,
+		// Clients get statistics through the state, the subscription only marks them as watching.
+		liveStatistics: func(connection.AppEventConnectionStatistics) {},
 	}
 }
 
 // Subscribe subscribes to the event bus.
 func (h *Handler) Subscribe(bus eventbus.Subscriber) error {
+	h.bus = bus
 	err := bus.Subscribe(nodeEvent.AppTopicNode, h.ConsumeNodeEvent)
 	if err != nil {
 		return err
 	}
-	err = bus.Subscribe(stateEvent.AppTopicState, h.ConsumeStateEvent)
+	// Clients need the latest state only, so a slow client never delays state announcements.
+	err = bus.SubscribeBuffered(stateEvent.AppTopicState, h.ConsumeStateEvent, eventbus.BufferOptions{
+		Size:     1,
+		Overflow: eventbus.OverflowCoalesce,
+	})
 	return err
 }
 
@@ -158,6 +171,9 @@ func (h *Handler) sendInitialState(messageChan chan string) error {
 
 func (h *Handler) serve() {
 	defer func() {
+		if len(h.clients) > 0 {
+			h.unwatchStatistics()
+		}
 		for k := range h.clients {
 			close(k)
 		}
@@ -168,10 +184,16 @@ func (h *Handler) serve() {
 		case <-h.stopChan:
 			return
 		case s := <-h.newClients:
+			if len(h.clients) == 0 {
+				h.watchStatistics()
+			}
 			h.clients[s] = struct{}{}
 		case s := <-h.deadClients:
 			delete(h.clients, s)
 			close(s)
+			if len(h.clients) == 0 {
+				h.unwatchStatistics()
+			}
 		case msg := <-h.messages:
 			for s := range h.clients {
 				s <- msg
@@ -180,6 +202,24 @@ func (h *Handler) serve() {
 	}
 }
 
+func (h *Handler) watchStatistics() {
+	if h.bus == nil {
+		return
+	}
+	if err := h.bus.Subscribe(connection.AppTopicConnectionStatisticsLive, h.liveStatistics); err != nil {
+		log.Error().Err(err).Msg("Could not subscribe to live statistics")
+	}
+}
+
+func (h *Handler) unwatchStatistics() {
+	if h.bus == nil {
+		return
+	}
+	if err := h.bus.Unsubscribe(connection.AppTopicConnectionStatisticsLive, h.liveStatistics); err != nil {
+		log.Error().Err(err).Msg("Could not unsubscribe from live statistics")
+	}
+}
+
 func (h *Handler) stop() {
 	h.stopOnce.Do(func() { close(h.stopChan) })
 }
@@ -207,6 +247,7 @@ func (h *Handler) ConsumeNodeEvent(e nodeEvent.Payload) {
 
 type stateRes struct {
 	NATStatus     contract.NATStatusDTO     `json:"nat_status"`
+	BrokerStatus  contract.BrokerStatusDTO  `json:"broker_status"`
 	Services      []contract.ServiceInfoDTO `json:"service_info"`
 	Sessions      []contract.SessionDTO     `json:"sessions"`
 	SessionsStats contract.SessionStatsDTO  `json:"sessions_stats"`
@@ -215,7 +256,9 @@ type stateRes struct {
 }
 
 type consumerStateRes struct {
-	Connection contract.ConnectionDTO `json:"connection"`
+	Connection  contract.ConnectionDTO            `json:"connection"`
+	Connections map[string]contract.ConnectionDTO `json:"connections,omitempty"`
+	Schedule    *contract.ScheduleDTO             `json:"schedule,omitempty"`
 }
 
 func mapState(event stateEvent.State) stateRes {
@@ -225,9 +268,9 @@ func mapState(event stateEvent.State) stateRes {
 			Address:            identity.Address,
 			RegistrationStatus: identity.RegistrationStatus.String(),
 			ChannelAddress:     identity.ChannelAddress.Hex(),
-			Balance:            identity.Balance,
-			Earnings:           identity.Earnings,
-			EarningsTotal:      identity.EarningsTotal,
+			Balance:            contract.NewTokensDTO(identity.Balance),
+			Earnings:           contract.NewTokensDTO(identity.Earnings),
+			EarningsTotal:      contract.NewTokensDTO(identity.EarningsTotal),
 		}
 	}
 
@@ -238,13 +281,30 @@ func mapState(event stateEvent.State) stateRes {
 		sessionsStats.Add(se)
 	}
 
+	var connectionsRes map[string]contract.ConnectionDTO
+	if len(event.Connections) > 0 {
+		connectionsRes = make(map[string]contract.ConnectionDTO, len(event.Connections))
+		for id, c := range event.Connections {
+			connectionsRes[id] = contract.NewConnectionDTO(c.Session, c.Statistics, c.Throughput, c.Invoice)
+		}
+	}
+
+	var scheduleRes *contract.ScheduleDTO
+	if event.Schedule.Enabled {
+		dto := contract.NewScheduleDTO(event.Schedule)
+		scheduleRes = &dto
+	}
+
 	res := stateRes{
 		NATStatus:     event.NATStatus,
+		BrokerStatus:  event.Broker,
 		Services:      event.Services,
 		Sessions:      sessionsRes,
 		SessionsStats: contract.NewSessionStatsDTO(sessionsStats),
 		Consumer: consumerStateRes{
-			Connection: contract.NewConnectionDTO(event.Connection.Session, event.Connection.Statistics, event.Connection.Throughput, event.Connection.Invoice),
+			Connection:  contract.NewConnectionDTO(event.Connection.Session, event.Connection.Statistics, event.Connection.Throughput, event.Connection.Invoice),
+			Connections: connectionsRes,
+			Schedule:    scheduleRes,
 		},
 		Identities: identitiesRes,
 	}
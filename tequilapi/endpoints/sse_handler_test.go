@@ -32,19 +32,26 @@ import (
 	"github.com/mysteriumnetwork/node/core/connection"
 	nodeEvent "github.com/mysteriumnetwork/node/core/node/event"
 	stateEvent "github.com/mysteriumnetwork/node/core/state/event"
+	"github.com/mysteriumnetwork/node/eventbus"
 	"github.com/mysteriumnetwork/node/identity/registry"
+	"github.com/mysteriumnetwork/node/money"
 	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/stretchr/testify/assert"
 )
 
 type mockStateProvider struct {
-	stateToReturn stateEvent.State
+	stateToReturn             stateEvent.State
+	statisticsHistoryToReturn []connection.Statistics
 }
 
 func (msp *mockStateProvider) GetState() stateEvent.State {
 	return msp.stateToReturn
 }
 
+func (msp *mockStateProvider) ConnectionStatisticsHistory() []connection.Statistics {
+	return msp.statisticsHistoryToReturn
+}
+
 func TestHandler_Stops(t *testing.T) {
 	h := NewSSEHandler(&mockStateProvider{})
 
@@ -135,6 +142,9 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
       "status": "",
       "error": ""
     },
+    "broker_status": {
+      "status": ""
+    },
     "service_info": null,
     "sessions": [],
     "sessions_stats": {
@@ -143,7 +153,7 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
       "sum_bytes_received": 0,
       "sum_bytes_sent": 0,
       "sum_duration": 0,
-      "sum_tokens": 0
+      "sum_tokens": {"amount": "0", "myst": "0.000000"}
 	},
     "consumer": {
       "connection": {
@@ -173,6 +183,9 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
       "status": "mass panic",
       "error": "cookie prices rise drastically"
     },
+    "broker_status": {
+      "status": ""
+    },
     "service_info": null,
     "sessions": [],
     "sessions_stats": {
@@ -181,7 +194,7 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
       "sum_bytes_received": 0,
       "sum_bytes_sent": 0,
       "sum_duration": 0,
-      "sum_tokens": 0
+      "sum_tokens": {"amount": "0", "myst": "0.000000"}
 	},
     "consumer": {
       "connection": {
@@ -201,9 +214,9 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 			Address:            "0xd535eba31e9bd2d7a4e34852e6292b359e5c77f7",
 			RegistrationStatus: registry.RegisteredConsumer,
 			ChannelAddress:     common.HexToAddress("0x000000000000000000000000000000000000000a"),
-			Balance:            50,
-			Earnings:           1,
-			EarningsTotal:      100,
+			Balance:            money.NewTokens(50),
+			Earnings:           money.NewTokens(1),
+			EarningsTotal:      money.NewTokens(100),
 		},
 	}
 	h.ConsumeStateEvent(changedState)
@@ -218,6 +231,9 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
       "status": "",
       "error": ""
     },
+    "broker_status": {
+      "status": ""
+    },
     "service_info": null,
     "sessions": [],
     "sessions_stats": {
@@ -226,7 +242,7 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
       "sum_bytes_received": 0,
       "sum_bytes_sent": 0,
       "sum_duration": 0,
-      "sum_tokens": 0
+      "sum_tokens": {"amount": "0", "myst": "0.000000"}
 	},
     "consumer": {
       "connection": {
@@ -238,9 +254,9 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
         "id": "0xd535eba31e9bd2d7a4e34852e6292b359e5c77f7",
         "registration_status": "RegisteredConsumer",
         "channel_address": "0x000000000000000000000000000000000000000A",
-        "balance": 50,
-        "earnings": 1,
-        "earnings_total": 100
+        "balance": {"amount": "50", "myst": "0.000001"},
+        "earnings": {"amount": "1", "myst": "0.000000"},
+        "earnings_total": {"amount": "100", "myst": "0.000001"}
       }
     ]
   },
@@ -253,3 +269,25 @@ func TestHandler_SendsInitialAndFollowingStates(t *testing.T) {
 
 	<-serveExit
 }
+
+func TestHandler_WatchesLiveStatisticsWhileClientsAreConnected(t *testing.T) {
+	bus := eventbus.New()
+	counter := bus.(eventbus.SubscriptionCounter)
+	h := NewSSEHandler(&mockStateProvider{})
+	assert.NoError(t, h.Subscribe(bus))
+	go h.serve()
+	defer h.stop()
+
+	first, second := make(chan string), make(chan string)
+	h.newClients <- first
+	h.newClients <- second
+	assert.Eventually(t, func() bool {
+		return counter.Subscribers(connection.AppTopicConnectionStatisticsLive) == 1
+	}, time.Second, time.Millisecond)
+
+	h.deadClients <- first
+	h.deadClients <- second
+	assert.Eventually(t, func() bool {
+		return counter.Subscribers(connection.AppTopicConnectionStatisticsLive) == 0
+	}, time.Second, time.Millisecond)
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type telemetryPreviewer interface {
+	Enabled() bool
+	Preview() ([]byte, error)
+}
+
+type telemetryAPI struct {
+	telemetry telemetryPreviewer
+}
+
+// Preview shows telemetry which would be sent
+// swagger:operation GET /telemetry/preview Telemetry telemetryPreview
+// ---
+// summary: Shows telemetry which would be sent
+// description: Returns the exact anonymized payload which is sent on the next flush if user consented to telemetry
+// responses:
+//   200:
+//     description: Telemetry preview
+//     schema:
+//       "$ref": "#/definitions/TelemetryPreviewDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *telemetryAPI) Preview(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	payload, err := api.telemetry.Preview()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+
+	utils.WriteAsJSON(contract.TelemetryPreviewDTO{
+		Enabled: api.telemetry.Enabled(),
+		Payload: payload,
+	}, resp)
+}
+
+// AddRoutesForTelemetry adds telemetry routes to given router
+func AddRoutesForTelemetry(router *httprouter.Router, telemetry telemetryPreviewer) {
+	api := &telemetryAPI{telemetry: telemetry}
+
+	router.GET("/telemetry/preview", api.Preview)
+}
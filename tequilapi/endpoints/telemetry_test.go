@@ -0,0 +1,54 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockTelemetryPreviewer struct {
+	enabled bool
+	payload []byte
+}
+
+func (m *mockTelemetryPreviewer) Enabled() bool {
+	return m.enabled
+}
+
+func (m *mockTelemetryPreviewer) Preview() ([]byte, error) {
+	return m.payload, nil
+}
+
+func Test_TelemetryPreview_ReturnsExactPayload(t *testing.T) {
+	previewer := &mockTelemetryPreviewer{payload: []byte(`{"reports":[{"identity":"abc","country":"LT"}]}`)}
+	req, err := http.NewRequest(http.MethodGet, "/telemetry/preview", nil)
+	assert.NoError(t, err)
+	resp := httptest.NewRecorder()
+	router := httprouter.New()
+	AddRoutesForTelemetry(router, previewer)
+
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"enabled": false, "payload": {"reports": [{"identity": "abc", "country": "LT"}]}}`, resp.Body.String())
+}
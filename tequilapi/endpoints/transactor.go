@@ -19,7 +19,6 @@ package endpoints
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -30,7 +29,6 @@ import (
 	"github.com/mysteriumnetwork/node/identity"
 	"github.com/mysteriumnetwork/node/identity/registry"
 	"github.com/mysteriumnetwork/node/session/pingpong"
-	"github.com/mysteriumnetwork/node/tequilapi/client"
 	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
@@ -45,7 +43,6 @@ type Transactor interface {
 // promiseSettler settles the given promises
 type promiseSettler interface {
 	ForceSettle(providerID identity.Identity, accountantID common.Address) error
-	SettleWithBeneficiary(id identity.Identity, beneficiary, accountantID common.Address) error
 	GetAccountantFee() (uint16, error)
 }
 
@@ -88,7 +85,7 @@ type Fees struct {
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (te *transactorEndpoint) TransactorFees(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	registrationFees, err := te.transactor.FetchRegistrationFees()
 	if err != nil {
@@ -138,7 +135,7 @@ type SettleRequest struct {
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (te *transactorEndpoint) SettleSync(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
 	err := te.settle(request, te.promiseSettler.ForceSettle)
 	if err != nil {
@@ -165,7 +162,7 @@ func (te *transactorEndpoint) SettleSync(resp http.ResponseWriter, request *http
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (te *transactorEndpoint) SettleAsync(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
 	err := te.settle(request, func(provider identity.Identity, accountant common.Address) error {
 		go func() {
@@ -211,11 +208,11 @@ func (te *transactorEndpoint) settle(request *http.Request, settler func(identit
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   400:
 //     description: Bad request
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (te *transactorEndpoint) TopUp(resp http.ResponseWriter, request *http.Request, _ httprouter.Params) {
 	topUpDTO := registry.TopUpRequest{}
 
@@ -255,11 +252,11 @@ func (te *transactorEndpoint) TopUp(resp http.ResponseWriter, request *http.Requ
 //   400:
 //     description: Bad request
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (te *transactorEndpoint) RegisterIdentity(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	identity := params.ByName("id")
 
@@ -281,26 +278,6 @@ func (te *transactorEndpoint) RegisterIdentity(resp http.ResponseWriter, request
 	resp.WriteHeader(http.StatusAccepted)
 }
 
-func (te *transactorEndpoint) SetBeneficiary(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	id := params.ByName("id")
-
-	req := &client.SettleWithBeneficiaryRequest{}
-	err := json.NewDecoder(request.Body).Decode(&req)
-	if err != nil {
-		utils.SendError(resp, fmt.Errorf("failed to parse set beneficiary request: %w", err), http.StatusBadRequest)
-		return
-	}
-
-	err = te.promiseSettler.SettleWithBeneficiary(identity.FromAddress(id), common.HexToAddress(req.Beneficiary), common.HexToAddress(req.AccountantID))
-	if err != nil {
-		log.Err(err).Msgf("Failed set beneficiary request for ID: %s, %+v", id, req)
-		utils.SendError(resp, fmt.Errorf("failed set beneficiary request: %w", err), http.StatusInternalServerError)
-		return
-	}
-
-	resp.WriteHeader(http.StatusAccepted)
-}
-
 // swagger:operation GET /settle/history SettlementHistory
 // ---
 // summary: Returns settlement history
@@ -311,7 +288,7 @@ func (te *transactorEndpoint) SetBeneficiary(resp http.ResponseWriter, request *
 //   500:
 //     description: Internal server error
 //     schema:
-//       "$ref": "#/definitions/ErrorMessageDTO"
+//       "$ref": "#/definitions/ErrorDTO"
 func (te *transactorEndpoint) SettlementHistory(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	providerID := req.URL.Query().Get("providerID")
 	if providerID == "" {
@@ -337,7 +314,6 @@ func (te *transactorEndpoint) SettlementHistory(resp http.ResponseWriter, req *h
 func AddRoutesForTransactor(router *httprouter.Router, transactor Transactor, promiseSettler promiseSettler, settlementHistoryProvider settlementHistoryProvider) {
 	te := NewTransactorEndpoint(transactor, promiseSettler, settlementHistoryProvider)
 	router.POST("/identities/:id/register", te.RegisterIdentity)
-	router.POST("/identities/:id/beneficiary", te.SetBeneficiary)
 	router.GET("/transactor/fees", te.TransactorFees)
 	router.POST("/transactor/topup", te.TopUp)
 	router.POST("/transactor/settle/sync", te.SettleSync)
@@ -139,7 +139,7 @@ func Test_TopUp_BubblesErrors(t *testing.T) {
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
 	assert.JSONEq(
 		t,
-		fmt.Sprintf(`{"message":"server response invalid: %v %v (%v/topup)"}`, mockStatus, http.StatusText(mockStatus), server.URL),
+		fmt.Sprintf(`{"code":"internal","message":"server response invalid: %v %v (%v/topup)"}`, mockStatus, http.StatusText(mockStatus), server.URL),
 		resp.Body.String(),
 	)
 }
@@ -189,7 +189,7 @@ func Test_SettleAsync_ReturnsError(t *testing.T) {
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
-	assert.JSONEq(t, `{"message":"failed to unmarshal settle request: invalid character 'a' looking for beginning of value"}`, resp.Body.String())
+	assert.JSONEq(t, `{"code":"internal","message":"failed to unmarshal settle request: invalid character 'a' looking for beginning of value"}`, resp.Body.String())
 }
 
 func Test_SettleSync_OK(t *testing.T) {
@@ -237,7 +237,7 @@ func Test_SettleSync_ReturnsError(t *testing.T) {
 	router.ServeHTTP(resp, req)
 
 	assert.Equal(t, http.StatusInternalServerError, resp.Code)
-	assert.JSONEq(t, `{"message":"settling failed: explosions everywhere"}`, resp.Body.String())
+	assert.JSONEq(t, `{"code":"internal","message":"settling failed: explosions everywhere"}`, resp.Body.String())
 }
 
 func Test_SettleHistory(t *testing.T) {
@@ -261,7 +261,7 @@ func Test_SettleHistory(t *testing.T) {
 		router.ServeHTTP(resp, req)
 
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
-		assert.JSONEq(t, `{"message":"providerID is required"}`, resp.Body.String())
+		assert.JSONEq(t, `{"code":"bad_request","message":"providerID is required"}`, resp.Body.String())
 	})
 	t.Run("returns error on missing accountantID", func(t *testing.T) {
 		mockResponse := ""
@@ -283,7 +283,7 @@ func Test_SettleHistory(t *testing.T) {
 		router.ServeHTTP(resp, req)
 
 		assert.Equal(t, http.StatusBadRequest, resp.Code)
-		assert.JSONEq(t, `{"message":"accountantID is required"}`, resp.Body.String())
+		assert.JSONEq(t, `{"code":"bad_request","message":"accountantID is required"}`, resp.Body.String())
 	})
 	t.Run("returns error on failed history retrieval", func(t *testing.T) {
 		mockResponse := ""
@@ -305,7 +305,7 @@ func Test_SettleHistory(t *testing.T) {
 		router.ServeHTTP(resp, req)
 
 		assert.Equal(t, http.StatusInternalServerError, resp.Code)
-		assert.JSONEq(t, `{"message":"explosions everywhere"}`, resp.Body.String())
+		assert.JSONEq(t, `{"code":"internal","message":"explosions everywhere"}`, resp.Body.String())
 	})
 	t.Run("returns settlement history", func(t *testing.T) {
 		expectedJSON := `[{"time":"2020-05-26T09:12:32.475904Z","tx_hash":"0x88af51047ff2da1e3626722fe239f70c3ddd668f067b2ac8d67b280d2eff39f7","promise":{"ChannelID":"+6pGXXkM8mIuwjZ72JNukxcqE1UkhbC47Ijg4UqurJY=","Amount":30245,"Fee":0,"Hashlock":"6RauIa1dz9pXOca788BIJigdgIzWVbn9k3VXZLvp2gM=","R":"qhLbkT/xKQFxvf7bE66yA/mr4LY5WEnqP/280KnNHi8=","Signature":"pqHSWofpfM7cUZ2KfhKmzd+iyxs6xsbWqXOkO0noCRwEfHHZtAP2S9E+sE72m2bFQmTtPB8mzQ6X0aNrn9h39Rw="},"beneficiary":"0x0000000000000000000000000000000000000000","amount":30091,"total_settled":30245},{"time":"2020-05-26T08:15:58.386698Z","tx_hash":"0x9eea5c4da8a67929d5dd5d8b6dedb3bd44e7bd3ec299f8972f3212db8afb938a","promise":{"ChannelID":"+6pGXXkM8mIuwjZ72JNukxcqE1UkhbC47Ijg4UqurJY=","Amount":154,"Fee":0,"Hashlock":"wIAKURZIMqlrlyjXNOX+Y8xIDyPSBZuMFU37bNJrRNQ=","R":"PqnMB6sYiwgM+pPdnk5Q8TOw93E78M7aFz1G2DkBKJE=","Signature":"elsD3ennGajAjUg7Ky4M4+8Olde2V2vNwm2v1c5pqQs/6V0mY7ECPLUzsU8dGKKI5EceFUVGqTnKrcLIUwRY6xs="},"beneficiary":"0x0000000000000000000000000000000000000000","amount":154,"total_settled":154}]`
@@ -0,0 +1,200 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+)
+
+type uiOriginPolicy interface {
+	Trusted(origin string) bool
+	Handshake(origin string) contract.UIOriginDTO
+	Origins() contract.UIOriginsDTO
+	Allow(origin string, allowance string) error
+	Revoke(origin string) error
+}
+
+type uiOriginsAPI struct {
+	policy uiOriginPolicy
+}
+
+// Handshake announces UI origin to the node
+// swagger:operation GET /ui/handshake UI uiHandshake
+// ---
+// summary: Announces UI origin to the node
+// description: Can be called from any origin. Returns whether the origin is allowed to talk to the node, unknown origin is kept pending until user allows it
+// responses:
+//   200:
+//     description: Origin status
+//     schema:
+//       "$ref": "#/definitions/UIOriginDTO"
+//   400:
+//     description: Origin header is missing
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *uiOriginsAPI) Handshake(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		utils.SendErrorMessage(resp, "Origin header is required", http.StatusBadRequest)
+		return
+	}
+
+	// handshake response must be readable by the origin which is not allowed yet
+	resp.Header().Set("Access-Control-Allow-Origin", origin)
+	utils.WriteAsJSON(api.policy.Handshake(origin), resp)
+}
+
+// Origins lists UI origins
+// swagger:operation GET /ui/origins UI uiOrigins
+// ---
+// summary: Lists UI origins
+// description: Returns origins allowed by user and the ones waiting for approval after handshake
+// responses:
+//   200:
+//     description: UI origins
+//     schema:
+//       "$ref": "#/definitions/UIOriginsDTO"
+//   403:
+//     description: Origin is not allowed to manage UI origins
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *uiOriginsAPI) Origins(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if !api.trusted(resp, req) {
+		return
+	}
+
+	utils.WriteAsJSON(api.policy.Origins(), resp)
+}
+
+// Allow allows UI origin
+// swagger:operation POST /ui/origins UI uiAllowOrigin
+// ---
+// summary: Allows UI origin
+// description: Allows origin to talk to the node and persists it to user config
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/UIOriginRequestDTO"
+// responses:
+//   200:
+//     description: Origin allowed
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   403:
+//     description: Origin is not allowed to manage UI origins
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *uiOriginsAPI) Allow(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if !api.trusted(resp, req) {
+		return
+	}
+
+	var request contract.UIOriginRequest
+	if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errors := request.Validate(); errors.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errors)
+		return
+	}
+
+	if err := api.policy.Allow(request.Origin, request.Allowance); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+}
+
+// Revoke revokes UI origin
+// swagger:operation DELETE /ui/origins UI uiRevokeOrigin
+// ---
+// summary: Revokes UI origin
+// description: Forgets allowed or pending origin and persists allowed origins to user config
+// parameters:
+//   - in: query
+//     name: origin
+//     type: string
+//     required: true
+// responses:
+//   200:
+//     description: Origin revoked
+//   400:
+//     description: Origin is missing
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   403:
+//     description: Origin is not allowed to manage UI origins
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (api *uiOriginsAPI) Revoke(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if !api.trusted(resp, req) {
+		return
+	}
+
+	origin := req.URL.Query().Get("origin")
+	if origin == "" {
+		utils.SendErrorMessage(resp, "origin is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.policy.Revoke(origin); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	resp.WriteHeader(http.StatusOK)
+}
+
+// trusted makes sure origins allowed by user cannot allow other origins themselves
+func (api *uiOriginsAPI) trusted(resp http.ResponseWriter, req *http.Request) bool {
+	if api.policy.Trusted(req.Header.Get("Origin")) {
+		return true
+	}
+	utils.SendErrorMessage(resp, "Origin is not allowed to manage UI origins", http.StatusForbidden)
+	return false
+}
+
+// AddRoutesForUIOrigins adds UI origin handshake and management routes to given router
+func AddRoutesForUIOrigins(router *httprouter.Router, policy uiOriginPolicy) {
+	api := &uiOriginsAPI{policy: policy}
+
+	router.GET("/ui/handshake", api.Handshake)
+	router.GET("/ui/origins", api.Origins)
+	router.POST("/ui/origins", api.Allow)
+	router.DELETE("/ui/origins", api.Revoke)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockUIOriginPolicy struct {
+	trusted   string
+	allowed   map[string]string
+	revoked   []string
+	handshake []string
+}
+
+func (m *mockUIOriginPolicy) Trusted(origin string) bool {
+	return origin == "" || origin == m.trusted
+}
+
+func (m *mockUIOriginPolicy) Handshake(origin string) contract.UIOriginDTO {
+	m.handshake = append(m.handshake, origin)
+	return contract.UIOriginDTO{Origin: origin, Status: "pending"}
+}
+
+func (m *mockUIOriginPolicy) Origins() contract.UIOriginsDTO {
+	return contract.UIOriginsDTO{Allowed: []contract.UIOriginDTO{}, Pending: []contract.UIOriginDTO{}}
+}
+
+func (m *mockUIOriginPolicy) Allow(origin string, allowance string) error {
+	m.allowed[origin] = allowance
+	return nil
+}
+
+func (m *mockUIOriginPolicy) Revoke(origin string) error {
+	m.revoked = append(m.revoked, origin)
+	return nil
+}
+
+func newUIOriginsRouter(policy uiOriginPolicy) *httprouter.Router {
+	router := httprouter.New()
+	AddRoutesForUIOrigins(router, policy)
+	return router
+}
+
+func Test_UIHandshake_IsReadableByAnyOrigin(t *testing.T) {
+	policy := &mockUIOriginPolicy{}
+	req := httptest.NewRequest(http.MethodGet, "/ui/handshake", nil)
+	req.Header.Set("Origin", "http://dashboard.lan")
+	resp := httptest.NewRecorder()
+
+	newUIOriginsRouter(policy).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "http://dashboard.lan", resp.Header().Get("Access-Control-Allow-Origin"))
+	assert.JSONEq(t, `{"origin": "http://dashboard.lan", "status": "pending"}`, resp.Body.String())
+	assert.Equal(t, []string{"http://dashboard.lan"}, policy.handshake)
+}
+
+func Test_UIHandshake_RequiresOrigin(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/ui/handshake", nil)
+	resp := httptest.NewRecorder()
+
+	newUIOriginsRouter(&mockUIOriginPolicy{}).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusBadRequest, resp.Code)
+}
+
+func Test_UIOrigins_AllowedOnlyFromTrustedOrigin(t *testing.T) {
+	policy := &mockUIOriginPolicy{trusted: "http://localhost:3000", allowed: map[string]string{}}
+	router := newUIOriginsRouter(policy)
+
+	for origin, expectedCode := range map[string]int{
+		"http://localhost:3000": http.StatusOK,
+		"":                      http.StatusOK,
+		"http://dashboard.lan":  http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/ui/origins", strings.NewReader(`{"origin": "http://192.168.1.10:3000", "allowance": "read"}`))
+		if origin != "" {
+			req.Header.Set("Origin", origin)
+		}
+		resp := httptest.NewRecorder()
+
+		router.ServeHTTP(resp, req)
+
+		assert.Equal(t, expectedCode, resp.Code, origin)
+	}
+	assert.Equal(t, map[string]string{"http://192.168.1.10:3000": "read"}, policy.allowed)
+}
+
+func Test_UIOrigins_AllowValidatesRequest(t *testing.T) {
+	policy := &mockUIOriginPolicy{allowed: map[string]string{}}
+	req := httptest.NewRequest(http.MethodPost, "/ui/origins", strings.NewReader(`{"origin": "http://192.168.1.10:3000/path", "allowance": "admin"}`))
+	resp := httptest.NewRecorder()
+
+	newUIOriginsRouter(policy).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Empty(t, policy.allowed)
+}
+
+func Test_UIOrigins_Revoke(t *testing.T) {
+	policy := &mockUIOriginPolicy{}
+	req := httptest.NewRequest(http.MethodDelete, "/ui/origins?origin=http%3A%2F%2Fdashboard.lan", nil)
+	resp := httptest.NewRecorder()
+
+	newUIOriginsRouter(policy).ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, []string{"http://dashboard.lan"}, policy.revoked)
+}
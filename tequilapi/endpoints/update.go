@@ -0,0 +1,172 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/updater"
+)
+
+type nodeUpdater interface {
+	Status() updater.Status
+	Check() (updater.Status, error)
+	Install() error
+	SetChannel(channel string) error
+}
+
+type updateConfig interface {
+	SetUser(key string, value interface{})
+	SaveUserConfig() error
+}
+
+type updateEndpoint struct {
+	updater nodeUpdater
+	config  updateConfig
+}
+
+// swagger:operation GET /update Update updateStatus
+// ---
+// summary: Returns self-update status
+// description: Returns running node version, followed release channel and available update
+// responses:
+//   200:
+//     description: Update status
+//     schema:
+//       "$ref": "#/definitions/UpdateStatusDTO"
+func (endpoint *updateEndpoint) Status(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	utils.WriteAsJSON(contract.NewUpdateStatusDTO(endpoint.updater.Status()), resp)
+}
+
+// swagger:operation POST /update/check Update updateCheck
+// ---
+// summary: Checks for update
+// description: Checks release channel for a newer node version
+// responses:
+//   200:
+//     description: Update status
+//     schema:
+//       "$ref": "#/definitions/UpdateStatusDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *updateEndpoint) Check(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	status, err := endpoint.updater.Check()
+	if err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(contract.NewUpdateStatusDTO(status), resp)
+}
+
+// swagger:operation POST /update/install Update updateInstall
+// ---
+// summary: Installs update
+// description: Downloads and verifies available release, replaces node binary and restarts node once active provider sessions finish
+// responses:
+//   202:
+//     description: Update accepted, node will restart
+//   404:
+//     description: No update available
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Update already in progress
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *updateEndpoint) Install(resp http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	err := endpoint.updater.Install()
+	switch err {
+	case nil:
+		resp.WriteHeader(http.StatusAccepted)
+	case updater.ErrNoUpdate:
+		utils.SendError(resp, err, http.StatusNotFound)
+	case updater.ErrUpdateInProgress:
+		utils.SendError(resp, err, http.StatusConflict)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+// swagger:operation PUT /update/channel Update updateChannel
+// ---
+// summary: Switches release channel
+// description: Switches release channel followed by the node and saves it to user configuration
+// parameters:
+//   - in: body
+//     name: body
+//     schema:
+//       $ref: "#/definitions/UpdateChannelRequest"
+// responses:
+//   200:
+//     description: Update status
+//     schema:
+//       "$ref": "#/definitions/UpdateStatusDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *updateEndpoint) SetChannel(resp http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var channelReq contract.UpdateChannelRequest
+	if err := json.NewDecoder(req.Body).Decode(&channelReq); err != nil {
+		utils.SendError(resp, err, http.StatusBadRequest)
+		return
+	}
+	if errs := channelReq.Validate(); errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
+	}
+
+	if err := endpoint.updater.SetChannel(channelReq.Channel); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	endpoint.config.SetUser(config.FlagUpdateChannel.Name, channelReq.Channel)
+	if err := endpoint.config.SaveUserConfig(); err != nil {
+		utils.SendError(resp, err, http.StatusInternalServerError)
+		return
+	}
+	utils.WriteAsJSON(contract.NewUpdateStatusDTO(endpoint.updater.Status()), resp)
+}
+
+// AddRoutesForUpdate attaches node self-update endpoints to router
+func AddRoutesForUpdate(router *httprouter.Router, nodeUpdater nodeUpdater) {
+	addRoutesForUpdate(router, nodeUpdater, config.Current)
+}
+
+func addRoutesForUpdate(router *httprouter.Router, nodeUpdater nodeUpdater, cfg updateConfig) {
+	endpoint := &updateEndpoint{updater: nodeUpdater, config: cfg}
+	router.GET("/update", endpoint.Status)
+	router.POST("/update/check", endpoint.Check)
+	router.POST("/update/install", endpoint.Install)
+	router.PUT("/update/channel", endpoint.SetChannel)
+}
@@ -0,0 +1,129 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/updater"
+	"github.com/stretchr/testify/assert"
+)
+
+type mockUpdater struct {
+	status     updater.Status
+	installErr error
+}
+
+func (m *mockUpdater) Status() updater.Status {
+	return m.status
+}
+
+func (m *mockUpdater) Check() (updater.Status, error) {
+	m.status.Available = &updater.Release{Version: "0.40.0"}
+	m.status.LastCheck = time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	return m.status, nil
+}
+
+func (m *mockUpdater) Install() error {
+	return m.installErr
+}
+
+func (m *mockUpdater) SetChannel(channel string) error {
+	m.status.Channel = channel
+	return nil
+}
+
+type mockUpdateConfig struct {
+	values map[string]interface{}
+	saved  bool
+}
+
+func (m *mockUpdateConfig) SetUser(key string, value interface{}) {
+	m.values[key] = value
+}
+
+func (m *mockUpdateConfig) SaveUserConfig() error {
+	m.saved = true
+	return nil
+}
+
+func newUpdateRouter(u nodeUpdater, cfg updateConfig) *httprouter.Router {
+	router := httprouter.New()
+	addRoutesForUpdate(router, u, cfg)
+	return router
+}
+
+func Test_UpdateCheck(t *testing.T) {
+	u := &mockUpdater{status: updater.Status{CurrentVersion: "0.39.0", Channel: "stable", State: updater.StateIdle}}
+	router := newUpdateRouter(u, &mockUpdateConfig{})
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/update", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{"current_version": "0.39.0", "channel": "stable", "state": "Idle"}`, resp.Body.String())
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/update/check", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"current_version": "0.39.0",
+		"channel": "stable",
+		"state": "Idle",
+		"available_version": "0.40.0",
+		"last_check": "2020-09-01T10:00:00Z"
+	}`, resp.Body.String())
+}
+
+func Test_UpdateInstall(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{nil, http.StatusAccepted},
+		{updater.ErrNoUpdate, http.StatusNotFound},
+		{updater.ErrUpdateInProgress, http.StatusConflict},
+	} {
+		router := newUpdateRouter(&mockUpdater{installErr: tc.err}, &mockUpdateConfig{})
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/update/install", nil))
+		assert.Equal(t, tc.code, resp.Code)
+	}
+}
+
+func Test_UpdateSetChannel(t *testing.T) {
+	u := &mockUpdater{status: updater.Status{Channel: "stable"}}
+	cfg := &mockUpdateConfig{values: map[string]interface{}{}}
+	router := newUpdateRouter(u, cfg)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/update/channel", strings.NewReader(`{"channel": "nightly"}`)))
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Equal(t, "stable", u.status.Channel)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPut, "/update/channel", strings.NewReader(`{"channel": "beta"}`)))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Equal(t, "beta", u.status.Channel)
+	assert.Equal(t, "beta", cfg.values["update.channel"])
+	assert.True(t, cfg.saved)
+}
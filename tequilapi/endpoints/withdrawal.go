@@ -0,0 +1,203 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/config"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/mysteriumnetwork/node/withdrawal"
+	"github.com/pkg/errors"
+)
+
+type withdrawer interface {
+	SetBeneficiary(id identity.Identity, beneficiary, accountantID common.Address) (withdrawal.Withdrawal, error)
+	Withdraw(id identity.Identity, accountantID common.Address) (withdrawal.Withdrawal, error)
+	Status(id identity.Identity) (withdrawal.Withdrawal, bool)
+}
+
+type withdrawalEndpoint struct {
+	withdrawer   withdrawer
+	accountantID string
+}
+
+// swagger:operation POST /identities/{id}/beneficiary Identity setBeneficiary
+// ---
+// summary: Changes beneficiary
+// description: Changes beneficiary of identity, earnings are settled to the new beneficiary. Progress is reported by withdrawal status.
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity address
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   schema:
+//     $ref: "#/definitions/SetBeneficiaryRequest"
+// responses:
+//   202:
+//     description: Beneficiary change started
+//     schema:
+//       "$ref": "#/definitions/WithdrawalDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Withdrawal already in progress
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *withdrawalEndpoint) SetBeneficiary(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	var req contract.SetBeneficiaryRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil {
+		utils.SendError(resp, errors.Wrap(err, "failed to parse set beneficiary request"), http.StatusBadRequest)
+		return
+	}
+	if errs := req.Validate(); errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
+	}
+
+	w, err := endpoint.withdrawer.SetBeneficiary(identity.FromAddress(params.ByName("id")), common.HexToAddress(req.Beneficiary), endpoint.accountant(req.AccountantID))
+	endpoint.sendStarted(resp, w, err)
+}
+
+// swagger:operation POST /identities/{id}/withdraw Identity withdraw
+// ---
+// summary: Withdraws earnings
+// description: Settles earnings of identity to its current beneficiary. Progress is reported by withdrawal status.
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity address
+//   type: string
+//   required: true
+// - in: body
+//   name: body
+//   schema:
+//     $ref: "#/definitions/WithdrawRequest"
+// responses:
+//   202:
+//     description: Withdrawal started
+//     schema:
+//       "$ref": "#/definitions/WithdrawalDTO"
+//   400:
+//     description: Bad request
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   409:
+//     description: Withdrawal already in progress
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   422:
+//     description: Parameters validation error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+//   500:
+//     description: Internal server error
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *withdrawalEndpoint) Withdraw(resp http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	var req contract.WithdrawRequest
+	if err := json.NewDecoder(request.Body).Decode(&req); err != nil && err != io.EOF {
+		utils.SendError(resp, errors.Wrap(err, "failed to parse withdraw request"), http.StatusBadRequest)
+		return
+	}
+	if errs := req.Validate(); errs.HasErrors() {
+		utils.SendValidationErrorMessage(resp, errs)
+		return
+	}
+
+	w, err := endpoint.withdrawer.Withdraw(identity.FromAddress(params.ByName("id")), endpoint.accountant(req.AccountantID))
+	endpoint.sendStarted(resp, w, err)
+}
+
+// swagger:operation GET /identities/{id}/withdraw Identity withdrawalStatus
+// ---
+// summary: Returns withdrawal status
+// description: Returns status of the last withdrawal or beneficiary change of identity
+// parameters:
+// - name: id
+//   in: path
+//   description: Identity address
+//   type: string
+//   required: true
+// responses:
+//   200:
+//     description: Withdrawal status
+//     schema:
+//       "$ref": "#/definitions/WithdrawalDTO"
+//   404:
+//     description: No withdrawal found
+//     schema:
+//       "$ref": "#/definitions/ErrorDTO"
+func (endpoint *withdrawalEndpoint) Status(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+	w, ok := endpoint.withdrawer.Status(identity.FromAddress(params.ByName("id")))
+	if !ok {
+		utils.SendErrorMessage(resp, "No withdrawal found", http.StatusNotFound)
+		return
+	}
+	utils.WriteAsJSON(contract.NewWithdrawalDTO(w), resp)
+}
+
+func (endpoint *withdrawalEndpoint) accountant(accountantID string) common.Address {
+	if accountantID == "" {
+		return common.HexToAddress(endpoint.accountantID)
+	}
+	return common.HexToAddress(accountantID)
+}
+
+func (endpoint *withdrawalEndpoint) sendStarted(resp http.ResponseWriter, w withdrawal.Withdrawal, err error) {
+	switch err {
+	case nil:
+		resp.WriteHeader(http.StatusAccepted)
+		utils.WriteAsJSON(contract.NewWithdrawalDTO(w), resp)
+	case withdrawal.ErrWithdrawalInProgress:
+		utils.SendError(resp, err, http.StatusConflict)
+	default:
+		utils.SendError(resp, err, http.StatusInternalServerError)
+	}
+}
+
+// AddRoutesForWithdrawal attaches beneficiary change and withdrawal endpoints to router
+func AddRoutesForWithdrawal(router *httprouter.Router, withdrawer withdrawer) {
+	addRoutesForWithdrawal(router, withdrawer, config.GetString(config.FlagAccountantID))
+}
+
+func addRoutesForWithdrawal(router *httprouter.Router, withdrawer withdrawer, accountantID string) {
+	endpoint := &withdrawalEndpoint{withdrawer: withdrawer, accountantID: accountantID}
+	router.POST("/identities/:id/beneficiary", endpoint.SetBeneficiary)
+	router.POST("/identities/:id/withdraw", endpoint.Withdraw)
+	router.GET("/identities/:id/withdraw", endpoint.Status)
+}
@@ -0,0 +1,132 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package endpoints
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/withdrawal"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	withdrawalIdentity   = "0x0000000000000000000000000000000000000001"
+	withdrawalAccountant = "0x0000000000000000000000000000000000000002"
+	withdrawalWallet     = "0x0000000000000000000000000000000000000003"
+)
+
+type mockWithdrawer struct {
+	err         error
+	withdrawals map[identity.Identity]withdrawal.Withdrawal
+}
+
+func (m *mockWithdrawer) SetBeneficiary(id identity.Identity, beneficiary, accountantID common.Address) (withdrawal.Withdrawal, error) {
+	return m.start(id, beneficiary, accountantID)
+}
+
+func (m *mockWithdrawer) Withdraw(id identity.Identity, accountantID common.Address) (withdrawal.Withdrawal, error) {
+	return m.start(id, common.HexToAddress(withdrawalWallet), accountantID)
+}
+
+func (m *mockWithdrawer) start(id identity.Identity, beneficiary, accountantID common.Address) (withdrawal.Withdrawal, error) {
+	if m.err != nil {
+		return withdrawal.Withdrawal{}, m.err
+	}
+	started := time.Date(2020, 9, 1, 10, 0, 0, 0, time.UTC)
+	w := withdrawal.Withdrawal{
+		Identity:    id,
+		Accountant:  accountantID,
+		Beneficiary: beneficiary,
+		Status:      withdrawal.StatusSettling,
+		StartedAt:   started,
+		UpdatedAt:   started,
+	}
+	m.withdrawals[id] = w
+	return w, nil
+}
+
+func (m *mockWithdrawer) Status(id identity.Identity) (withdrawal.Withdrawal, bool) {
+	w, ok := m.withdrawals[id]
+	return w, ok
+}
+
+func newWithdrawalRouter(w withdrawer) *httprouter.Router {
+	router := httprouter.New()
+	addRoutesForWithdrawal(router, w, withdrawalAccountant)
+	return router
+}
+
+func TestWithdrawalEndpoint_SetBeneficiary(t *testing.T) {
+	router := newWithdrawalRouter(&mockWithdrawer{withdrawals: make(map[identity.Identity]withdrawal.Withdrawal)})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/identities/"+withdrawalIdentity+"/beneficiary", strings.NewReader(`{"beneficiary": "`+withdrawalWallet+`"}`))
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+	assert.JSONEq(t, `{
+		"identity": "`+withdrawalIdentity+`",
+		"accountant_id": "`+withdrawalAccountant+`",
+		"beneficiary": "`+withdrawalWallet+`",
+		"status": "settling",
+		"confirmations": 0,
+		"started_at": "2020-09-01T10:00:00Z",
+		"updated_at": "2020-09-01T10:00:00Z"
+	}`, resp.Body.String())
+}
+
+func TestWithdrawalEndpoint_SetBeneficiaryValidates(t *testing.T) {
+	router := newWithdrawalRouter(&mockWithdrawer{withdrawals: make(map[identity.Identity]withdrawal.Withdrawal)})
+
+	resp := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/identities/"+withdrawalIdentity+"/beneficiary", strings.NewReader(`{"beneficiary": "my-wallet"}`))
+	router.ServeHTTP(resp, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, resp.Code)
+	assert.Contains(t, resp.Body.String(), "Beneficiary must be an ethereum address")
+}
+
+func TestWithdrawalEndpoint_Withdraw(t *testing.T) {
+	withdrawer := &mockWithdrawer{withdrawals: make(map[identity.Identity]withdrawal.Withdrawal)}
+	router := newWithdrawalRouter(withdrawer)
+
+	resp := httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/identities/"+withdrawalIdentity+"/withdraw", nil))
+	assert.Equal(t, http.StatusNotFound, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/identities/"+withdrawalIdentity+"/withdraw", nil))
+	assert.Equal(t, http.StatusAccepted, resp.Code)
+
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/identities/"+withdrawalIdentity+"/withdraw", nil))
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.Contains(t, resp.Body.String(), `"status":"settling"`)
+
+	withdrawer.err = withdrawal.ErrWithdrawalInProgress
+	resp = httptest.NewRecorder()
+	router.ServeHTTP(resp, httptest.NewRequest(http.MethodPost, "/identities/"+withdrawalIdentity+"/withdraw", nil))
+	assert.Equal(t, http.StatusConflict, resp.Code)
+}
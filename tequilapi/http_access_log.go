@@ -0,0 +1,224 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/core/auth"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/rs/zerolog/log"
+)
+
+// latencyBuckets are the upper bounds of request latency histogram.
+var latencyBuckets = []time.Duration{
+	10 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	5 * time.Second,
+}
+
+const (
+	latencyBucketInf = "+Inf"
+	routeNotFound    = "not_found"
+)
+
+type endpointKey struct {
+	method, route string
+}
+
+type endpointStats struct {
+	count        uint64
+	clientErrors uint64
+	serverErrors uint64
+	latencyTotal time.Duration
+	latencyMax   time.Duration
+	buckets      []uint64
+}
+
+// RequestMetrics collects request count, errors and latency histogram of every tequilapi endpoint.
+type RequestMetrics struct {
+	lock      sync.Mutex
+	endpoints map[endpointKey]*endpointStats
+}
+
+// NewRequestMetrics returns empty request metrics.
+func NewRequestMetrics() *RequestMetrics {
+	return &RequestMetrics{
+		endpoints: make(map[endpointKey]*endpointStats),
+	}
+}
+
+// Record adds the request to metrics of its endpoint.
+func (m *RequestMetrics) Record(method, route string, status int, latency time.Duration) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := endpointKey{method: method, route: route}
+	stats, ok := m.endpoints[key]
+	if !ok {
+		stats = &endpointStats{buckets: make([]uint64, len(latencyBuckets)+1)}
+		m.endpoints[key] = stats
+	}
+
+	stats.count++
+	switch {
+	case status >= 500:
+		stats.serverErrors++
+	case status >= 400:
+		stats.clientErrors++
+	}
+	stats.latencyTotal += latency
+	if latency > stats.latencyMax {
+		stats.latencyMax = latency
+	}
+	stats.buckets[sort.Search(len(latencyBuckets), func(i int) bool { return latency <= latencyBuckets[i] })]++
+}
+
+// Snapshot returns metrics of all endpoints ordered by route.
+func (m *RequestMetrics) Snapshot() contract.RequestMetricsDTO {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	dto := contract.RequestMetricsDTO{
+		Endpoints: make([]contract.EndpointMetricsDTO, 0, len(m.endpoints)),
+	}
+	for key, stats := range m.endpoints {
+		latency := make(map[string]uint64, len(stats.buckets))
+		for i, count := range stats.buckets {
+			bound := latencyBucketInf
+			if i < len(latencyBuckets) {
+				bound = latencyBuckets[i].String()
+			}
+			latency[bound] = count
+		}
+
+		dto.Endpoints = append(dto.Endpoints, contract.EndpointMetricsDTO{
+			Method:       key.method,
+			Route:        key.route,
+			Count:        stats.count,
+			ClientErrors: stats.clientErrors,
+			ServerErrors: stats.serverErrors,
+			LatencyAvg:   uint64(stats.latencyTotal / time.Duration(stats.count) / time.Millisecond),
+			LatencyMax:   uint64(stats.latencyMax / time.Millisecond),
+			Latency:      latency,
+		})
+	}
+	sort.Slice(dto.Endpoints, func(i, j int) bool {
+		if dto.Endpoints[i].Route == dto.Endpoints[j].Route {
+			return dto.Endpoints[i].Method < dto.Endpoints[j].Method
+		}
+		return dto.Endpoints[i].Route < dto.Endpoints[j].Route
+	})
+	return dto
+}
+
+type accessLog struct {
+	originalHandler http.Handler
+	router          *httprouter.Router
+	metrics         *RequestMetrics
+}
+
+// ApplyAccessLog wraps original handler by logging every request and recording it to metrics of its endpoint
+func ApplyAccessLog(original http.Handler, router *httprouter.Router, metrics *RequestMetrics) http.Handler {
+	return &accessLog{originalHandler: original, router: router, metrics: metrics}
+}
+
+func (al *accessLog) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	recorder := &statusRecorder{ResponseWriter: resp, status: http.StatusOK}
+	al.originalHandler.ServeHTTP(recorder, req)
+	latency := time.Since(start)
+
+	route := routeOf(al.router, req.Method, req.URL.Path)
+	al.metrics.Record(req.Method, route, recorder.status, latency)
+
+	event := log.Debug()
+	if recorder.status >= 500 {
+		event = log.Warn()
+	}
+	event.
+		Str("method", req.Method).
+		Str("path", req.URL.Path).
+		Str("route", route).
+		Int("status", recorder.status).
+		Dur("latency", latency).
+		Str("token", tokenID(req)).
+		Msg("Tequilapi request")
+}
+
+// routeOf returns the route matching the given path, with path parameter values replaced by their names
+// so that metrics of e.g. different identities are collected under the same endpoint.
+func routeOf(router *httprouter.Router, method, path string) string {
+	handle, params, _ := router.Lookup(method, path)
+	if handle == nil {
+		return routeNotFound
+	}
+
+	segments := strings.Split(path, "/")
+	for _, param := range params {
+		for i, segment := range segments {
+			if segment == param.Value {
+				segments[i] = ":" + param.Key
+				break
+			}
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// tokenID identifies the caller by a short hash of its token, the token itself is never logged.
+func tokenID(req *http.Request) string {
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	if cookie, err := req.Cookie(auth.JWTCookieName); err == nil {
+		token = cookie.Value
+	}
+	if token == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:4])
+}
+
+// statusRecorder remembers the status code written to response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Flush lets streaming endpoints, e.g. server sent events, flush through the recorder.
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
@@ -0,0 +1,109 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/mysteriumnetwork/node/tequilapi/endpoints"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessLogRecordsRequestsByRoute(t *testing.T) {
+	// given
+	router := httprouter.New()
+	router.GET("/identities/:id/status", func(resp http.ResponseWriter, _ *http.Request, params httprouter.Params) {
+		if params.ByName("id") == "0xbad" {
+			resp.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+	metrics := NewRequestMetrics()
+	endpoints.AddRoutesForMetrics(router, metrics, nil)
+	handler := ApplyAccessLog(router, router, metrics)
+
+	// when
+	for _, path := range []string{"/identities/0x1/status", "/identities/0x2/status", "/identities/0xbad/status", "/missing"} {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+	resp := httptest.NewRecorder()
+	handler.ServeHTTP(resp, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	// then
+	assert.Equal(t, http.StatusOK, resp.Code)
+	assert.JSONEq(t, `{
+		"endpoints": [
+			{
+				"method": "GET",
+				"route": "/identities/:id/status",
+				"count": 3,
+				"client_errors": 0,
+				"server_errors": 1,
+				"latency_avg": 0,
+				"latency_max": 0,
+				"latency": {"10ms": 3, "50ms": 0, "100ms": 0, "500ms": 0, "1s": 0, "5s": 0, "+Inf": 0}
+			},
+			{
+				"method": "GET",
+				"route": "not_found",
+				"count": 1,
+				"client_errors": 1,
+				"server_errors": 0,
+				"latency_avg": 0,
+				"latency_max": 0,
+				"latency": {"10ms": 1, "50ms": 0, "100ms": 0, "500ms": 0, "1s": 0, "5s": 0, "+Inf": 0}
+			}
+		]
+	}`, resp.Body.String())
+}
+
+func TestRequestMetricsLatencyHistogram(t *testing.T) {
+	metrics := NewRequestMetrics()
+	metrics.Record(http.MethodPut, "/connection", http.StatusCreated, 40*time.Millisecond)
+	metrics.Record(http.MethodPut, "/connection", http.StatusConflict, 100*time.Millisecond)
+	metrics.Record(http.MethodPut, "/connection", http.StatusCreated, 10*time.Second)
+
+	assert.Equal(t, []contract.EndpointMetricsDTO{
+		{
+			Method:       http.MethodPut,
+			Route:        "/connection",
+			Count:        3,
+			ClientErrors: 1,
+			LatencyAvg:   3380,
+			LatencyMax:   10000,
+			Latency:      map[string]uint64{"10ms": 0, "50ms": 1, "100ms": 1, "500ms": 0, "1s": 0, "5s": 0, "+Inf": 1},
+		},
+	}, metrics.Snapshot().Endpoints)
+}
+
+func TestTokenIDDoesNotRevealToken(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/connection", nil)
+	assert.Equal(t, "", tokenID(req))
+
+	req.Header.Set("Authorization", "Bearer secret")
+	id := tokenID(req)
+	assert.Len(t, id, 8)
+	assert.NotContains(t, id, "secret")
+
+	req.AddCookie(&http.Cookie{Name: "token", Value: "other"})
+	assert.NotEqual(t, id, tokenID(req))
+}
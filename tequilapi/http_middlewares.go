@@ -20,6 +20,8 @@ package tequilapi
 import (
 	"net/http"
 	"strings"
+
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
 )
 
 type corsHandler struct {
@@ -30,8 +32,11 @@ type corsHandler struct {
 // CorsPolicy resolves allowed origin
 type CorsPolicy interface {
 	AllowedOrigin(requestOrigin string) string
+	AllowedMethods(requestOrigin string) []string
 }
 
+var corsAllowedMethods = []string{http.MethodPost, http.MethodGet, http.MethodOptions, http.MethodPut, http.MethodDelete}
+
 func (wrapper corsHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
 	if isPreflightCorsRequest(req) {
 		generatePreflightResponse(req, resp, wrapper.corsPolicy)
@@ -39,6 +44,10 @@ func (wrapper corsHandler) ServeHTTP(resp http.ResponseWriter, req *http.Request
 	}
 
 	allowCorsActions(resp, req, wrapper.corsPolicy)
+	if !isMethodAllowed(req, wrapper.corsPolicy) {
+		utils.SendErrorMessage(resp, "method is not allowed for origin", http.StatusForbidden)
+		return
+	}
 	wrapper.originalHandler.ServeHTTP(resp, req)
 }
 
@@ -52,7 +61,21 @@ func allowCorsActions(resp http.ResponseWriter, req *http.Request, corsPolicy Co
 	allowedOrigin := corsPolicy.AllowedOrigin(requestOrigin)
 
 	resp.Header().Set("Access-Control-Allow-Origin", allowedOrigin)
-	resp.Header().Set("Access-Control-Allow-Methods", "POST, GET, OPTIONS, PUT, DELETE")
+	resp.Header().Set("Access-Control-Allow-Methods", strings.Join(corsPolicy.AllowedMethods(requestOrigin), ", "))
+}
+
+func isMethodAllowed(req *http.Request, corsPolicy CorsPolicy) bool {
+	requestOrigin := req.Header.Get("Origin")
+	if requestOrigin == "" {
+		return true
+	}
+
+	for _, method := range corsPolicy.AllowedMethods(requestOrigin) {
+		if req.Method == method {
+			return true
+		}
+	}
+	return false
 }
 
 func isPreflightCorsRequest(req *http.Request) bool {
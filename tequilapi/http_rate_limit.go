@@ -0,0 +1,183 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/tequilapi/utils"
+	"github.com/rs/zerolog/log"
+)
+
+// RateLimit is the rate of token bucket refill and its capacity, zero Rate disables the limit.
+type RateLimit struct {
+	// Rate is the number of requests per second
+	Rate  float64
+	Burst int
+}
+
+// RateLimitConfig holds limits of expensive operations, i.e. connect and identity registration, and of all other requests.
+// Every caller is limited by its token and by its IP separately.
+type RateLimitConfig struct {
+	Reads  RateLimit
+	Writes RateLimit
+}
+
+// bucketsCleanupInterval is how often buckets of idle callers are dropped.
+const bucketsCleanupInterval = time.Minute
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// limiter keeps a token bucket per caller.
+type limiter struct {
+	limit RateLimit
+	now   func() time.Time
+
+	lock        sync.Mutex
+	buckets     map[string]*tokenBucket
+	lastCleanup time.Time
+}
+
+func newLimiter(limit RateLimit, now func() time.Time) *limiter {
+	return &limiter{
+		limit:       limit,
+		now:         now,
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: now(),
+	}
+}
+
+// allow takes a token from the buckets of every caller key, returns how long to wait for the next one if any bucket is empty.
+// Nothing is taken unless all buckets have a token, so a rejected request does not drain the buckets of other keys.
+func (l *limiter) allow(keys ...string) (bool, time.Duration) {
+	if l.limit.Rate <= 0 {
+		return true, 0
+	}
+
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastCleanup) > bucketsCleanupInterval {
+		l.cleanup(now)
+	}
+
+	buckets := make([]*tokenBucket, len(keys))
+	var retryAfter time.Duration
+	for i, key := range keys {
+		bucket, ok := l.buckets[key]
+		if !ok {
+			bucket = &tokenBucket{tokens: float64(l.limit.Burst), last: now}
+			l.buckets[key] = bucket
+		}
+		bucket.tokens = math.Min(float64(l.limit.Burst), bucket.tokens+now.Sub(bucket.last).Seconds()*l.limit.Rate)
+		bucket.last = now
+		buckets[i] = bucket
+
+		if bucket.tokens < 1 {
+			wait := time.Duration((1 - bucket.tokens) / l.limit.Rate * float64(time.Second))
+			if wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+	if retryAfter > 0 {
+		return false, retryAfter
+	}
+
+	for _, bucket := range buckets {
+		bucket.tokens--
+	}
+	return true, 0
+}
+
+// cleanup drops buckets which are full again, they are recreated full on the next request anyway.
+func (l *limiter) cleanup(now time.Time) {
+	for key, bucket := range l.buckets {
+		if bucket.tokens+now.Sub(bucket.last).Seconds()*l.limit.Rate >= float64(l.limit.Burst) {
+			delete(l.buckets, key)
+		}
+	}
+	l.lastCleanup = now
+}
+
+type rateLimiter struct {
+	originalHandler http.Handler
+	reads           *limiter
+	writes          *limiter
+}
+
+// ApplyRateLimit wraps original handler by rejecting requests of callers exceeding the rate limits with 429 status
+func ApplyRateLimit(original http.Handler, config RateLimitConfig) http.Handler {
+	return &rateLimiter{
+		originalHandler: original,
+		reads:           newLimiter(config.Reads, time.Now),
+		writes:          newLimiter(config.Writes, time.Now),
+	}
+}
+
+func (rl *rateLimiter) ServeHTTP(resp http.ResponseWriter, req *http.Request) {
+	limiter := rl.reads
+	if isExpensiveRequest(req) {
+		limiter = rl.writes
+	}
+
+	keys := []string{"ip:" + remoteIP(req)}
+	if token := tokenID(req); token != "" {
+		keys = append(keys, "token:"+token)
+	}
+	if allowed, retryAfter := limiter.allow(keys...); !allowed {
+		log.Warn().Msgf("Tequilapi rate limit exceeded by %s: %s %s", strings.Join(keys, ", "), req.Method, req.URL.Path)
+		resp.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+		utils.SendErrorMessage(resp, "rate limit exceeded", http.StatusTooManyRequests)
+		return
+	}
+
+	rl.originalHandler.ServeHTTP(resp, req)
+}
+
+// isExpensiveRequest tells if request establishes a connection or registers an identity.
+func isExpensiveRequest(req *http.Request) bool {
+	path := strings.TrimSuffix(req.URL.Path, "/")
+	switch req.Method {
+	case http.MethodPut:
+		return path == "/connection" || path == "/connection/probe" ||
+			strings.HasPrefix(path, "/connections/")
+	case http.MethodPost:
+		return path == "/connection/invite" || path == "/connection/country" ||
+			strings.HasPrefix(path, "/identities/") && strings.HasSuffix(path, "/register")
+	}
+	return false
+}
+
+func remoteIP(req *http.Request) string {
+	host, _, err := net.SplitHostPort(req.RemoteAddr)
+	if err != nil {
+		return req.RemoteAddr
+	}
+	return host
+}
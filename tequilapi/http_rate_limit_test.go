@@ -0,0 +1,137 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiterRefillsBucket(t *testing.T) {
+	now := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(RateLimit{Rate: 2, Burst: 3}, func() time.Time { return now })
+
+	for i := 0; i < 3; i++ {
+		allowed, _ := l.allow("caller")
+		assert.True(t, allowed)
+	}
+	allowed, retryAfter := l.allow("caller")
+	assert.False(t, allowed)
+	assert.Equal(t, 500*time.Millisecond, retryAfter)
+
+	allowed, _ = l.allow("other-caller")
+	assert.True(t, allowed, "callers have separate buckets")
+
+	now = now.Add(500 * time.Millisecond)
+	allowed, _ = l.allow("caller")
+	assert.True(t, allowed)
+	allowed, _ = l.allow("caller")
+	assert.False(t, allowed)
+}
+
+func TestLimiterDropsFullBuckets(t *testing.T) {
+	now := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(RateLimit{Rate: 1, Burst: 1}, func() time.Time { return now })
+
+	l.allow("caller")
+	now = now.Add(30 * time.Second)
+	l.allow("busy-caller")
+	assert.Len(t, l.buckets, 2)
+
+	now = now.Add(bucketsCleanupInterval)
+	l.allow("busy-caller")
+	assert.Len(t, l.buckets, 1)
+}
+
+func TestLimiterWithoutRateAllowsEverything(t *testing.T) {
+	l := newLimiter(RateLimit{}, time.Now)
+	for i := 0; i < 100; i++ {
+		allowed, _ := l.allow("caller")
+		assert.True(t, allowed)
+	}
+}
+
+func TestRateLimitSeparatesReadsFromWrites(t *testing.T) {
+	// given
+	mock := &mockedHTTPHandler{}
+	handler := ApplyRateLimit(mock, RateLimitConfig{
+		Reads:  RateLimit{Rate: 1, Burst: 2},
+		Writes: RateLimit{Rate: 0.1, Burst: 1},
+	})
+	serve := func(method, remoteAddr, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/connection", nil)
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		resp := httptest.NewRecorder()
+		handler.ServeHTTP(resp, req)
+		return resp
+	}
+
+	// then
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "10.0.0.1:1000", "").Code)
+	resp := serve(http.MethodPut, "10.0.0.1:1001", "")
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
+	assert.Equal(t, "10", resp.Header().Get("Retry-After"))
+	assert.JSONEq(t, `{"code":"rate_limited","message": "rate limit exceeded"}`, resp.Body.String())
+
+	assert.Equal(t, http.StatusOK, serve(http.MethodGet, "10.0.0.1:1002", "").Code)
+	assert.Equal(t, http.StatusOK, serve(http.MethodPut, "10.0.0.2:1000", "token").Code)
+	assert.Equal(t, http.StatusTooManyRequests, serve(http.MethodPut, "10.0.0.3:1000", "token").Code, "token is limited regardless of IP")
+}
+
+func TestLimiterDoesNotTakeTokensOfRejectedRequest(t *testing.T) {
+	now := time.Date(2020, 7, 1, 12, 0, 0, 0, time.UTC)
+	l := newLimiter(RateLimit{Rate: 1, Burst: 1}, func() time.Time { return now })
+
+	allowed, _ := l.allow("ip:10.0.0.1", "token:abc")
+	assert.True(t, allowed)
+
+	allowed, _ = l.allow("ip:10.0.0.2", "token:abc")
+	assert.False(t, allowed)
+	allowed, _ = l.allow("ip:10.0.0.2")
+	assert.True(t, allowed, "rejected request must not drain the IP bucket")
+}
+
+func TestRateLimitClassifiesExpensiveRequests(t *testing.T) {
+	tests := []struct {
+		method    string
+		path      string
+		expensive bool
+	}{
+		{http.MethodPut, "/connection", true},
+		{http.MethodPut, "/connection/probe", true},
+		{http.MethodPut, "/connections/my-connection", true},
+		{http.MethodPost, "/connection/invite", true},
+		{http.MethodPost, "/connection/country", true},
+		{http.MethodPost, "/identities/0x1/register", true},
+		{http.MethodGet, "/connection", false},
+		{http.MethodDelete, "/connection", false},
+		{http.MethodPost, "/identities", false},
+		{http.MethodPut, "/identities/0x1/unlock", false},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		assert.Equal(t, tt.expensive, isExpensiveRequest(req), "%s %s", tt.method, tt.path)
+	}
+}
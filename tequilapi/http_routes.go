@@ -1,3 +1,5 @@
+// +build !mobile_slim
+
 /*
  * Copyright (C) 2017 The "MysteriumNetwork/node" Authors.
  *
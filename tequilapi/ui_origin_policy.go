@@ -0,0 +1,230 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+)
+
+// OriginAllowance defines which requests an allowed UI origin is permitted to make.
+type OriginAllowance string
+
+const (
+	// OriginAllowanceFull allows origin to make any request.
+	OriginAllowanceFull = OriginAllowance("full")
+	// OriginAllowanceRead allows origin to make read-only requests.
+	OriginAllowanceRead = OriginAllowance("read")
+)
+
+const (
+	originStatusTrusted = "trusted"
+	originStatusAllowed = "allowed"
+	originStatusPending = "pending"
+
+	maxPendingOrigins = 20
+	pendingOriginTTL  = time.Hour
+)
+
+var corsReadMethods = []string{http.MethodGet, http.MethodHead, http.MethodOptions}
+
+// ParseAllowedOrigin parses origin entry given in "<origin>[=<allowance>]" format.
+func ParseAllowedOrigin(entry string) (string, OriginAllowance, error) {
+	parts := strings.SplitN(entry, "=", 2)
+	origin := strings.TrimSpace(parts[0])
+	if origin == "" {
+		return "", "", fmt.Errorf("empty origin in entry %q", entry)
+	}
+	if len(parts) == 1 {
+		return origin, OriginAllowanceFull, nil
+	}
+
+	allowance := OriginAllowance(strings.TrimSpace(parts[1]))
+	switch allowance {
+	case OriginAllowanceFull, OriginAllowanceRead:
+		return origin, allowance, nil
+	default:
+		return "", "", fmt.Errorf("unknown allowance %q for origin %s", allowance, origin)
+	}
+}
+
+// UIOriginPolicy is a CORS policy which extends the trusted policy with UI origins allowed by user.
+// Unknown origins announce themselves with a handshake and wait until user approves them.
+type UIOriginPolicy struct {
+	trusted CorsPolicy
+	save    func(entries []string) error
+	now     func() time.Time
+
+	mu      sync.RWMutex
+	allowed map[string]OriginAllowance
+	pending map[string]time.Time
+}
+
+// NewUIOriginPolicy creates UI origin policy from the allowed origin entries, approved origins are passed to save.
+func NewUIOriginPolicy(trusted CorsPolicy, entries []string, save func(entries []string) error) (*UIOriginPolicy, error) {
+	policy := &UIOriginPolicy{
+		trusted: trusted,
+		save:    save,
+		now:     time.Now,
+		allowed: make(map[string]OriginAllowance),
+		pending: make(map[string]time.Time),
+	}
+	for _, entry := range entries {
+		origin, allowance, err := ParseAllowedOrigin(entry)
+		if err != nil {
+			return nil, err
+		}
+		policy.allowed[origin] = allowance
+	}
+	return policy, nil
+}
+
+// AllowedOrigin returns the request origin if it is trusted or allowed by user, otherwise resolves it by trusted policy
+func (p *UIOriginPolicy) AllowedOrigin(requestOrigin string) string {
+	if _, ok := p.allowance(requestOrigin); ok {
+		return requestOrigin
+	}
+	return p.trusted.AllowedOrigin(requestOrigin)
+}
+
+// AllowedMethods restricts origins allowed to read only to safe methods
+func (p *UIOriginPolicy) AllowedMethods(requestOrigin string) []string {
+	if allowance, ok := p.allowance(requestOrigin); ok && allowance == OriginAllowanceRead {
+		return corsReadMethods
+	}
+	return p.trusted.AllowedMethods(requestOrigin)
+}
+
+// Trusted checks if request comes from the built-in trusted origin or from outside of browser.
+func (p *UIOriginPolicy) Trusted(requestOrigin string) bool {
+	return requestOrigin == "" || p.trusted.AllowedOrigin(requestOrigin) == requestOrigin
+}
+
+// Handshake returns status of the UI origin, unknown origin is remembered as pending user approval.
+func (p *UIOriginPolicy) Handshake(origin string) contract.UIOriginDTO {
+	if p.Trusted(origin) {
+		return contract.UIOriginDTO{Origin: origin, Status: originStatusTrusted, Allowance: string(OriginAllowanceFull)}
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if allowance, ok := p.allowed[origin]; ok {
+		return contract.UIOriginDTO{Origin: origin, Status: originStatusAllowed, Allowance: string(allowance)}
+	}
+
+	p.expirePending()
+	if _, ok := p.pending[origin]; !ok && len(p.pending) >= maxPendingOrigins {
+		p.dropOldestPending()
+	}
+	p.pending[origin] = p.now()
+	return contract.UIOriginDTO{Origin: origin, Status: originStatusPending}
+}
+
+// Origins lists origins allowed by user and the ones waiting for approval.
+func (p *UIOriginPolicy) Origins() contract.UIOriginsDTO {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.expirePending()
+	result := contract.UIOriginsDTO{
+		Allowed: make([]contract.UIOriginDTO, 0, len(p.allowed)),
+		Pending: make([]contract.UIOriginDTO, 0, len(p.pending)),
+	}
+	for origin, allowance := range p.allowed {
+		result.Allowed = append(result.Allowed, contract.UIOriginDTO{Origin: origin, Status: originStatusAllowed, Allowance: string(allowance)})
+	}
+	for origin := range p.pending {
+		result.Pending = append(result.Pending, contract.UIOriginDTO{Origin: origin, Status: originStatusPending})
+	}
+	sort.Slice(result.Allowed, func(i, j int) bool { return result.Allowed[i].Origin < result.Allowed[j].Origin })
+	sort.Slice(result.Pending, func(i, j int) bool { return result.Pending[i].Origin < result.Pending[j].Origin })
+	return result
+}
+
+// Allow allows origin to make requests of given allowance and saves allowed origins, empty allowance means full.
+func (p *UIOriginPolicy) Allow(origin string, allowance string) error {
+	if allowance == "" {
+		allowance = string(OriginAllowanceFull)
+	}
+	origin, parsed, err := ParseAllowedOrigin(origin + "=" + allowance)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.allowed[origin] = parsed
+	delete(p.pending, origin)
+	return p.save(p.entries())
+}
+
+// Revoke forgets allowed or pending origin and saves allowed origins.
+func (p *UIOriginPolicy) Revoke(origin string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.pending, origin)
+	if _, ok := p.allowed[origin]; !ok {
+		return nil
+	}
+	delete(p.allowed, origin)
+	return p.save(p.entries())
+}
+
+func (p *UIOriginPolicy) allowance(origin string) (OriginAllowance, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	allowance, ok := p.allowed[origin]
+	return allowance, ok
+}
+
+func (p *UIOriginPolicy) entries() []string {
+	entries := make([]string, 0, len(p.allowed))
+	for origin, allowance := range p.allowed {
+		entries = append(entries, origin+"="+string(allowance))
+	}
+	sort.Strings(entries)
+	return entries
+}
+
+func (p *UIOriginPolicy) expirePending() {
+	for origin, seenAt := range p.pending {
+		if p.now().Sub(seenAt) > pendingOriginTTL {
+			delete(p.pending, origin)
+		}
+	}
+}
+
+func (p *UIOriginPolicy) dropOldestPending() {
+	var oldest string
+	for origin, seenAt := range p.pending {
+		if oldest == "" || seenAt.Before(p.pending[oldest]) {
+			oldest = origin
+		}
+	}
+	delete(p.pending, oldest)
+}
@@ -0,0 +1,163 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package tequilapi
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAllowedOrigin(t *testing.T) {
+	tests := []struct {
+		entry     string
+		origin    string
+		allowance OriginAllowance
+		wantErr   bool
+	}{
+		{entry: "http://192.168.1.10:3000", origin: "http://192.168.1.10:3000", allowance: OriginAllowanceFull},
+		{entry: "http://192.168.1.10:3000=read", origin: "http://192.168.1.10:3000", allowance: OriginAllowanceRead},
+		{entry: " http://dashboard.lan = full ", origin: "http://dashboard.lan", allowance: OriginAllowanceFull},
+		{entry: "http://dashboard.lan=write", wantErr: true},
+		{entry: "=read", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.entry, func(t *testing.T) {
+			origin, allowance, err := ParseAllowedOrigin(tt.entry)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.origin, origin)
+			assert.Equal(t, tt.allowance, allowance)
+		})
+	}
+}
+
+func TestUIOriginPolicy_AllowsConfiguredOrigins(t *testing.T) {
+	policy, err := NewUIOriginPolicy(testCorsPolicy, []string{"http://192.168.1.10:3000", "http://dashboard.lan=read"}, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "http://192.168.1.10:3000", policy.AllowedOrigin("http://192.168.1.10:3000"))
+	assert.Equal(t, corsAllowedMethods, policy.AllowedMethods("http://192.168.1.10:3000"))
+	assert.Equal(t, "http://dashboard.lan", policy.AllowedOrigin("http://dashboard.lan"))
+	assert.Equal(t, corsReadMethods, policy.AllowedMethods("http://dashboard.lan"))
+	assert.Equal(t, "http://localhost:3000", policy.AllowedOrigin("http://localhost:3000"))
+	assert.Equal(t, "https://mysterium.network", policy.AllowedOrigin("http://evil.com"))
+}
+
+func TestUIOriginPolicy_RejectsInvalidEntries(t *testing.T) {
+	_, err := NewUIOriginPolicy(testCorsPolicy, []string{"http://dashboard.lan=admin"}, nil)
+	assert.Error(t, err)
+}
+
+func TestUIOriginPolicy_HandshakeWaitsForApproval(t *testing.T) {
+	// given
+	var saved []string
+	policy, err := NewUIOriginPolicy(testCorsPolicy, nil, func(entries []string) error {
+		saved = entries
+		return nil
+	})
+	assert.NoError(t, err)
+
+	// when
+	status := policy.Handshake("http://dashboard.lan")
+
+	// then
+	assert.Equal(t, contract.UIOriginDTO{Origin: "http://dashboard.lan", Status: "pending"}, status)
+	assert.Equal(t, "https://mysterium.network", policy.AllowedOrigin("http://dashboard.lan"))
+	assert.Equal(t, []contract.UIOriginDTO{{Origin: "http://dashboard.lan", Status: "pending"}}, policy.Origins().Pending)
+
+	// when
+	err = policy.Allow("http://dashboard.lan", "read")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://dashboard.lan=read"}, saved)
+	assert.Equal(t, contract.UIOriginDTO{Origin: "http://dashboard.lan", Status: "allowed", Allowance: "read"}, policy.Handshake("http://dashboard.lan"))
+	assert.Empty(t, policy.Origins().Pending)
+
+	// when
+	err = policy.Revoke("http://dashboard.lan")
+
+	// then
+	assert.NoError(t, err)
+	assert.Equal(t, []string{}, saved)
+	assert.Equal(t, "https://mysterium.network", policy.AllowedOrigin("http://dashboard.lan"))
+}
+
+func TestUIOriginPolicy_HandshakeOfTrustedOrigin(t *testing.T) {
+	policy, err := NewUIOriginPolicy(testCorsPolicy, nil, nil)
+	assert.NoError(t, err)
+
+	assert.Equal(t, contract.UIOriginDTO{Origin: "http://localhost:3000", Status: "trusted", Allowance: "full"}, policy.Handshake("http://localhost:3000"))
+	assert.Empty(t, policy.Origins().Pending)
+}
+
+func TestUIOriginPolicy_LimitsPendingOrigins(t *testing.T) {
+	policy, err := NewUIOriginPolicy(testCorsPolicy, nil, nil)
+	assert.NoError(t, err)
+	now := time.Now()
+	policy.now = func() time.Time { return now }
+
+	policy.Handshake("http://oldest.lan")
+	for i := 0; i < maxPendingOrigins; i++ {
+		now = now.Add(time.Second)
+		policy.Handshake("http://dashboard" + string(rune('a'+i)) + ".lan")
+	}
+	pending := policy.Origins().Pending
+	assert.Len(t, pending, maxPendingOrigins)
+	assert.NotContains(t, pending, contract.UIOriginDTO{Origin: "http://oldest.lan", Status: "pending"})
+
+	now = now.Add(pendingOriginTTL + time.Second)
+	assert.Empty(t, policy.Origins().Pending)
+}
+
+func TestUIOriginPolicy_AllowFailsWhenNotSaved(t *testing.T) {
+	policy, err := NewUIOriginPolicy(testCorsPolicy, nil, func(entries []string) error {
+		return errors.New("disk full")
+	})
+	assert.NoError(t, err)
+
+	assert.EqualError(t, policy.Allow("http://dashboard.lan", ""), "disk full")
+	assert.Error(t, policy.Allow("http://dashboard.lan", "admin"))
+}
+
+func TestCorsRejectsWritesOfReadOnlyOrigin(t *testing.T) {
+	policy, err := NewUIOriginPolicy(testCorsPolicy, []string{"http://dashboard.lan=read"}, nil)
+	assert.NoError(t, err)
+
+	for method, expectedCode := range map[string]int{http.MethodGet: http.StatusOK, http.MethodPut: http.StatusForbidden} {
+		req := httptest.NewRequest(method, "/connection", nil)
+		req.Header.Add("Origin", "http://dashboard.lan")
+		respRecorder := httptest.NewRecorder()
+		mock := &mockedHTTPHandler{}
+
+		ApplyCors(mock, policy).ServeHTTP(respRecorder, req)
+
+		assert.Equal(t, expectedCode, respRecorder.Code, method)
+		assert.Equal(t, "GET, HEAD, OPTIONS", respRecorder.Header().Get("Access-Control-Allow-Methods"))
+		assert.Equal(t, expectedCode == http.StatusOK, mock.wasCalled, method)
+	}
+}
@@ -19,9 +19,9 @@ package utils
 
 import (
 	"encoding/json"
-	"fmt"
 	"net/http"
 
+	"github.com/mysteriumnetwork/node/tequilapi/contract"
 	"github.com/mysteriumnetwork/node/tequilapi/validation"
 )
 
@@ -39,20 +39,14 @@ func WriteAsJSON(v interface{}, writer http.ResponseWriter) {
 	}
 }
 
-// swagger:model ErrorMessageDTO
-type errorMessage struct {
-	// example: error message
-	Message string `json:"message"`
-}
-
-// SendError generates error response for error
+// SendError generates error response for error, code of the error is taken from the error catalog
 func SendError(writer http.ResponseWriter, err error, httpCode int) {
-	SendErrorMessage(writer, fmt.Sprint(err), httpCode)
+	SendErrorBody(writer, contract.NewErrorDTO(err, httpCode), httpCode)
 }
 
 // SendErrorMessage generates error response with custom json message
 func SendErrorMessage(writer http.ResponseWriter, message string, httpCode int) {
-	SendErrorBody(writer, &errorMessage{message}, httpCode)
+	SendErrorBody(writer, contract.NewErrorMessageDTO(message, httpCode), httpCode)
 }
 
 // SendErrorBody generates error response with custom body
@@ -61,15 +55,7 @@ func SendErrorBody(writer http.ResponseWriter, message interface{}, httpCode int
 	WriteAsJSON(message, writer)
 }
 
-// swagger:model ValidationErrorDTO
-type validationErrorMessage struct {
-	errorMessage
-	ValidationErrors *validation.FieldErrorMap `json:"errors"`
-}
-
 // SendValidationErrorMessage generates error response for validation errors
 func SendValidationErrorMessage(resp http.ResponseWriter, errorMap *validation.FieldErrorMap) {
-	errorResponse := errorMessage{Message: "validation_error"}
-
-	SendErrorBody(resp, &validationErrorMessage{errorResponse, errorMap}, http.StatusUnprocessableEntity)
+	SendErrorBody(resp, contract.NewValidationErrorDTO(errorMap), http.StatusUnprocessableEntity)
 }
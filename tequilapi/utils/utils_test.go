@@ -19,10 +19,12 @@ package utils
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
 
+	"github.com/mysteriumnetwork/node/core/connection"
 	"github.com/mysteriumnetwork/node/tequilapi/validation"
 	"github.com/stretchr/testify/assert"
 )
@@ -59,20 +61,38 @@ func TestSendErrorRendersErrorMessage(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "internal",
 			"message" : "custom_error"
 		}`,
 		resp.Body.String())
 }
 
+func TestSendErrorRendersCodeOfKnownError(t *testing.T) {
+	resp := httptest.NewRecorder()
+
+	SendError(resp, fmt.Errorf("could not connect: %w", connection.ErrAlreadyExists), http.StatusConflict)
+
+	assert.Equal(t, http.StatusConflict, resp.Code)
+	assert.JSONEq(
+		t,
+		`{
+			"code" : "connection_exists",
+			"message" : "connection already exists",
+			"detail" : "could not connect: connection already exists"
+		}`,
+		resp.Body.String())
+}
+
 func TestSendErrorMessageRendersErrorMessage(t *testing.T) {
 	resp := httptest.NewRecorder()
 
-	SendErrorBody(resp, errorMessage{"error_message"}, http.StatusInternalServerError)
+	SendErrorMessage(resp, "error_message", http.StatusTooManyRequests)
 
-	assert.Equal(t, http.StatusInternalServerError, resp.Code)
+	assert.Equal(t, http.StatusTooManyRequests, resp.Code)
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "rate_limited",
 			"message" : "error_message"
 		}`,
 		resp.Body.String())
@@ -91,8 +111,9 @@ func TestSendValidationErrorMessageRendersErrorMessage(t *testing.T) {
 	assert.JSONEq(
 		t,
 		`{
+			"code" : "validation_failed",
 			"message" : "validation_error" ,
-			"errors" : {
+			"fields" : {
 				"email" : [
 					{ "code" : "required" , "message" : "field required"}
 				]
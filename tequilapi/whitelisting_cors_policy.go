@@ -38,6 +38,11 @@ func (policy RegexpCorsPolicy) AllowedOrigin(requestOrigin string) string {
 	return policy.DefaultTrustedOrigin
 }
 
+// AllowedMethods returns all methods supported by API, whitelisting does not restrict them
+func (policy RegexpCorsPolicy) AllowedMethods(_ string) []string {
+	return corsAllowedMethods
+}
+
 func (policy RegexpCorsPolicy) isOriginAllowed(origin string) bool {
 	for _, allowedSuffix := range policy.AllowedOriginSuffixes {
 		match, err := regexp.MatchString(allowedSuffix, origin)
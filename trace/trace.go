@@ -29,7 +29,7 @@ import (
 
 const (
 	// AppTopicTraceEvent represents event topic for Trace events
-	AppTopicTraceEvent = "Trace"
+	AppTopicTraceEvent = "trace"
 )
 
 // NewTracer returns new tracer instance.
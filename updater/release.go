@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/pkg/errors"
+)
+
+// ErrInvalidSignature indicates that release is not signed by the release signer.
+var ErrInvalidSignature = errors.New("release signature is invalid")
+
+// Release describes node binary published to the release channel.
+type Release struct {
+	Version string `json:"version"`
+	Channel string `json:"channel"`
+	OS      string `json:"os"`
+	Arch    string `json:"arch"`
+	URL     string `json:"url"`
+	// SHA256 is hex encoded checksum of the binary.
+	SHA256 string `json:"sha256"`
+	// Signature is base64 encoded signature of the release payload made by the release signer.
+	Signature string `json:"signature"`
+}
+
+// payload is the signed part of the release, so that neither the binary nor its version, channel
+// or platform can be swapped without invalidating the signature.
+func (r Release) payload() []byte {
+	return []byte(fmt.Sprintf("%s\n%s\n%s/%s\n%s", r.Version, r.Channel, r.OS, r.Arch, strings.ToLower(r.SHA256)))
+}
+
+func fetchRelease(httpClient *requests.HTTPClient, releaseURL, channel string) (*Release, error) {
+	req, err := requests.NewGetRequest(releaseURL, fmt.Sprintf("%s/%s-%s.json", channel, runtime.GOOS, runtime.GOARCH), nil)
+	if err != nil {
+		return nil, err
+	}
+	var release Release
+	if err := httpClient.DoRequestAndParseResponse(req, &release); err != nil {
+		return nil, errors.Wrap(err, "could not fetch release")
+	}
+	return &release, nil
+}
+
+// verify checks that release is signed by the release signer and is published for the given channel and this platform.
+func (r Release) verify(verifier identity.Verifier, channel string) error {
+	if !verifier.Verify(r.payload(), identity.SignatureBase64(r.Signature)) {
+		return ErrInvalidSignature
+	}
+	if r.Channel != channel || r.OS != runtime.GOOS || r.Arch != runtime.GOARCH {
+		return errors.Errorf("release is published for %s channel and %s/%s, expected %s channel and %s/%s",
+			r.Channel, r.OS, r.Arch, channel, runtime.GOOS, runtime.GOARCH)
+	}
+	return nil
+}
+
+// download saves release binary to the given path and verifies its checksum.
+func (r Release) download(httpClient *requests.HTTPClient, path string) error {
+	req, err := http.NewRequest(http.MethodGet, r.URL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "could not download release")
+	}
+	defer resp.Body.Close()
+	if err := requests.ParseResponseError(resp); err != nil {
+		return errors.Wrap(err, "could not download release")
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0755)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(f, hash), resp.Body); err != nil {
+		return errors.Wrap(err, "could not download release")
+	}
+	if checksum := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(checksum, r.SHA256) {
+		return errors.Errorf("release checksum mismatch: expected %s, got %s", r.SHA256, checksum)
+	}
+	return f.Close()
+}
@@ -0,0 +1,337 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package updater
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	// ChannelStable is a release channel of stable node versions.
+	ChannelStable = "stable"
+	// ChannelBeta is a release channel of pre-release node versions.
+	ChannelBeta = "beta"
+)
+
+// State is a state of the update process.
+type State string
+
+const (
+	// StateIdle means that no update is in progress.
+	StateIdle = State("Idle")
+	// StateDownloading means that release binary is being downloaded and verified.
+	StateDownloading = State("Downloading")
+	// StateDraining means that node waits for active provider sessions to finish.
+	StateDraining = State("Draining")
+	// StateRestarting means that binary was replaced and node is restarting.
+	StateRestarting = State("Restarting")
+)
+
+var (
+	// ErrUnknownChannel indicates that release channel is not supported.
+	ErrUnknownChannel = errors.New("unknown release channel")
+	// ErrNoUpdate indicates that there is no newer release to install.
+	ErrNoUpdate = errors.New("no update available")
+	// ErrUpdateInProgress indicates that update is already being installed.
+	ErrUpdateInProgress = errors.New("update is already in progress")
+)
+
+// Options describes updater options.
+type Options struct {
+	// URL is an address of release channels.
+	URL string
+	// Channel is a release channel to follow.
+	Channel string
+	// Signer is an identity signing released binaries.
+	Signer identity.Identity
+	// Auto enables installing updates as soon as they are found.
+	Auto bool
+	// CheckInterval is how often release channel is checked.
+	CheckInterval time.Duration
+	// DrainTimeout limits how long node waits for active provider sessions to finish before restarting.
+	DrainTimeout time.Duration
+}
+
+// Status describes state of the updater.
+type Status struct {
+	CurrentVersion string
+	Channel        string
+	State          State
+	Available      *Release
+	LastCheck      time.Time
+	Error          string
+}
+
+type sessionLister interface {
+	GetAll() []*service.Session
+}
+
+type sessionDrainer interface {
+	StopAccepting()
+}
+
+// Updater checks release channel for new node versions and installs them.
+type Updater struct {
+	httpClient *requests.HTTPClient
+	verifier   identity.Verifier
+	sessions   sessionLister
+	drainer    sessionDrainer
+	shutdown   func() error
+	options    Options
+	version    string
+	executable func() (string, error)
+
+	lock           sync.Mutex
+	status         Status
+	restartPending string
+	stop           chan struct{}
+	stopOnce       sync.Once
+}
+
+// NewUpdater creates node updater. Shutdown is called to stop the node once new binary is in place,
+// sessions and drainer may be nil on nodes not providing services.
+func NewUpdater(httpClient *requests.HTTPClient, sessions sessionLister, drainer sessionDrainer, shutdown func() error, currentVersion string, options Options) *Updater {
+	return &Updater{
+		httpClient: httpClient,
+		verifier:   identity.NewVerifierIdentity(options.Signer),
+		sessions:   sessions,
+		drainer:    drainer,
+		shutdown:   shutdown,
+		options:    options,
+		version:    currentVersion,
+		executable: executablePath,
+		status: Status{
+			CurrentVersion: currentVersion,
+			Channel:        options.Channel,
+			State:          StateIdle,
+		},
+		stop: make(chan struct{}),
+	}
+}
+
+// Start periodically checks release channel, installing found updates if auto update is enabled.
+func (u *Updater) Start() {
+	for {
+		if _, err := u.Check(); err != nil {
+			log.Warn().Err(err).Msg("Could not check for node updates")
+		} else if u.options.Auto {
+			if err := u.Install(); err != nil && err != ErrNoUpdate && err != ErrUpdateInProgress {
+				log.Error().Err(err).Msg("Could not install node update")
+			}
+		}
+
+		select {
+		case <-time.After(u.options.CheckInterval):
+		case <-u.stop:
+			return
+		}
+	}
+}
+
+// Stop stops periodic checks.
+func (u *Updater) Stop() {
+	u.stopOnce.Do(func() {
+		close(u.stop)
+	})
+}
+
+// Status returns current updater status.
+func (u *Updater) Status() Status {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	return u.status
+}
+
+// SetChannel switches release channel followed by the updater.
+func (u *Updater) SetChannel(channel string) error {
+	if channel != ChannelStable && channel != ChannelBeta {
+		return ErrUnknownChannel
+	}
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	if u.status.Channel != channel {
+		u.status.Channel = channel
+		u.status.Available = nil
+	}
+	return nil
+}
+
+// Check fetches the latest release of the channel and remembers it if it is newer than the running node.
+func (u *Updater) Check() (Status, error) {
+	channel := u.Status().Channel
+	release, err := fetchRelease(u.httpClient, u.options.URL, channel)
+
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.status.LastCheck = time.Now()
+	if err != nil {
+		u.status.Error = err.Error()
+		return u.status, err
+	}
+	if u.status.Channel != channel {
+		return u.status, nil
+	}
+
+	u.status.Error = ""
+	u.status.Available = nil
+	if err := release.verify(u.verifier, channel); err != nil {
+		u.status.Error = err.Error()
+		return u.status, err
+	}
+	if isNewer(release.Version, u.version) {
+		log.Info().Msgf("Node update %s is available in %s channel", release.Version, channel)
+		u.status.Available = release
+	}
+	return u.status, nil
+}
+
+// Install downloads available release, replaces node binary and restarts the node in background.
+func (u *Updater) Install() error {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+
+	if u.status.State != StateIdle {
+		return ErrUpdateInProgress
+	}
+	if u.status.Available == nil {
+		return ErrNoUpdate
+	}
+	u.status.State = StateDownloading
+	u.status.Error = ""
+
+	go u.install(*u.status.Available)
+	return nil
+}
+
+func (u *Updater) install(release Release) {
+	if err := u.replaceBinary(release); err != nil {
+		log.Error().Err(err).Msgf("Could not install node update %s", release.Version)
+		u.lock.Lock()
+		u.status.State = StateIdle
+		u.status.Error = err.Error()
+		u.lock.Unlock()
+		return
+	}
+
+	u.setState(StateDraining)
+	u.drain()
+
+	u.setState(StateRestarting)
+	log.Info().Msgf("Node updated to %s, restarting", release.Version)
+	if err := u.shutdown(); err != nil {
+		log.Error().Err(err).Msg("Could not stop node for restart")
+	}
+}
+
+// replaceBinary downloads and verifies release next to the running binary and swaps them.
+func (u *Updater) replaceBinary(release Release) error {
+	if err := release.verify(u.verifier, release.Channel); err != nil {
+		return err
+	}
+
+	path, err := u.executable()
+	if err != nil {
+		return errors.Wrap(err, "could not locate node binary")
+	}
+	newPath, oldPath := path+".new", path+".old"
+	if err := release.download(u.httpClient, newPath); err != nil {
+		os.Remove(newPath)
+		return err
+	}
+
+	os.Remove(oldPath)
+	if err := os.Rename(path, oldPath); err != nil {
+		os.Remove(newPath)
+		return errors.Wrap(err, "could not back up node binary")
+	}
+	if err := os.Rename(newPath, path); err != nil {
+		if restoreErr := os.Rename(oldPath, path); restoreErr != nil {
+			log.Error().Err(restoreErr).Msg("Could not restore node binary")
+		}
+		return errors.Wrap(err, "could not replace node binary")
+	}
+
+	u.lock.Lock()
+	u.restartPending = path
+	u.lock.Unlock()
+	return nil
+}
+
+// drain stops accepting new provider sessions and waits for active ones to finish, but no longer than drain timeout.
+func (u *Updater) drain() {
+	if u.drainer != nil {
+		u.drainer.StopAccepting()
+	}
+	if u.sessions == nil {
+		return
+	}
+
+	deadline := time.After(u.options.DrainTimeout)
+	for {
+		active := len(u.sessions.GetAll())
+		if active == 0 {
+			return
+		}
+		log.Info().Msgf("Waiting for %d active sessions to finish before restart", active)
+
+		select {
+		case <-deadline:
+			log.Warn().Msgf("Drain timeout reached, restarting with %d active sessions", active)
+			return
+		case <-time.After(10 * time.Second):
+		}
+	}
+}
+
+func (u *Updater) setState(state State) {
+	u.lock.Lock()
+	defer u.lock.Unlock()
+	u.status.State = state
+}
+
+// RestartIfUpdated starts the new node binary once node is shut down after the update.
+// It does nothing if update was not installed.
+func (u *Updater) RestartIfUpdated() error {
+	u.lock.Lock()
+	path := u.restartPending
+	u.restartPending = ""
+	u.lock.Unlock()
+
+	if path == "" {
+		return nil
+	}
+	return restart(path)
+}
+
+func executablePath() (string, error) {
+	path, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return filepath.EvalSymlinks(path)
+}
@@ -0,0 +1,199 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package updater
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/mysteriumnetwork/node/core/service"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/requests"
+	"github.com/stretchr/testify/assert"
+)
+
+var newBinary = []byte("new node binary")
+
+type releaseServer struct {
+	*httptest.Server
+	release Release
+}
+
+func signRelease(release *Release) {
+	signature, _ := (&identity.SignerFake{}).Sign(release.payload())
+	release.Signature = signature.Base64()
+}
+
+func newReleaseServer(version string) *releaseServer {
+	checksum := sha256.Sum256(newBinary)
+
+	rs := &releaseServer{}
+	rs.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case fmt.Sprintf("/stable/%s-%s.json", runtime.GOOS, runtime.GOARCH):
+			json.NewEncoder(w).Encode(rs.release)
+		case "/myst":
+			w.Write(newBinary)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	rs.release = Release{
+		Version: version,
+		Channel: ChannelStable,
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		URL:     rs.URL + "/myst",
+		SHA256:  hex.EncodeToString(checksum[:]),
+	}
+	signRelease(&rs.release)
+	return rs
+}
+
+type mockSessions struct {
+	sessions      []*service.Session
+	stopAccepting int
+}
+
+func (m *mockSessions) GetAll() []*service.Session {
+	return m.sessions
+}
+
+func (m *mockSessions) StopAccepting() {
+	m.stopAccepting++
+}
+
+func newTestUpdater(t *testing.T, url string) (*Updater, string, chan struct{}, *mockSessions) {
+	dir, err := ioutil.TempDir("", "updater")
+	assert.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	binary := filepath.Join(dir, "myst")
+	assert.NoError(t, ioutil.WriteFile(binary, []byte("old node binary"), 0755))
+
+	shutdown := make(chan struct{}, 1)
+	sessions := &mockSessions{}
+	u := NewUpdater(requests.NewHTTPClient("0.0.0.0", time.Second), sessions, sessions, func() error {
+		shutdown <- struct{}{}
+		return nil
+	}, "0.39.0", Options{URL: url, Channel: ChannelStable, DrainTimeout: time.Second})
+	u.verifier = &identity.VerifierFake{}
+	u.executable = func() (string, error) { return binary, nil }
+	return u, binary, shutdown, sessions
+}
+
+func TestUpdater_Check(t *testing.T) {
+	server := newReleaseServer("0.40.0")
+	defer server.Close()
+	u, _, _, _ := newTestUpdater(t, server.URL)
+
+	status, err := u.Check()
+	assert.NoError(t, err)
+	assert.Equal(t, &server.release, status.Available)
+	assert.False(t, status.LastCheck.IsZero())
+
+	server.release.Version = "0.39.0"
+	signRelease(&server.release)
+	status, err = u.Check()
+	assert.NoError(t, err)
+	assert.Nil(t, status.Available)
+	assert.Equal(t, ErrNoUpdate, u.Install())
+
+	assert.Equal(t, ErrUnknownChannel, u.SetChannel("nightly"))
+	assert.NoError(t, u.SetChannel(ChannelBeta))
+	_, err = u.Check()
+	assert.Error(t, err)
+	assert.NotEmpty(t, u.Status().Error)
+}
+
+func TestUpdater_InstallReplacesBinaryAndRestarts(t *testing.T) {
+	server := newReleaseServer("0.40.0")
+	defer server.Close()
+	u, binary, shutdown, sessions := newTestUpdater(t, server.URL)
+
+	_, err := u.Check()
+	assert.NoError(t, err)
+	assert.NoError(t, u.Install())
+
+	select {
+	case <-shutdown:
+	case <-time.After(2 * time.Second):
+		t.Fatal("node was not shut down after update")
+	}
+	assert.Equal(t, StateRestarting, u.Status().State)
+	assert.Equal(t, 1, sessions.stopAccepting)
+
+	content, err := ioutil.ReadFile(binary)
+	assert.NoError(t, err)
+	assert.Equal(t, newBinary, content)
+	backup, err := ioutil.ReadFile(binary + ".old")
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("old node binary"), backup)
+	assert.Equal(t, binary, u.restartPending)
+}
+
+func TestUpdater_InstallRejectsTamperedRelease(t *testing.T) {
+	server := newReleaseServer("0.40.0")
+	defer server.Close()
+	u, binary, _, _ := newTestUpdater(t, server.URL)
+
+	_, err := u.Check()
+	assert.NoError(t, err)
+	u.status.Available.Signature = "Zm9yZ2Vk"
+	assert.Equal(t, ErrInvalidSignature, u.replaceBinary(*u.status.Available))
+
+	release := server.release
+	release.SHA256 = "00"
+	signRelease(&release)
+	assert.Error(t, u.replaceBinary(release))
+
+	content, err := ioutil.ReadFile(binary)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("old node binary"), content)
+	_, err = os.Stat(binary + ".new")
+	assert.True(t, os.IsNotExist(err))
+	assert.Empty(t, u.restartPending)
+}
+
+func TestUpdater_CheckRejectsUnsignedReleaseMetadata(t *testing.T) {
+	server := newReleaseServer("0.38.0")
+	defer server.Close()
+	u, _, _, _ := newTestUpdater(t, server.URL)
+
+	// older signed binary served as a newer version
+	server.release.Version = "0.40.0"
+	status, err := u.Check()
+	assert.Equal(t, ErrInvalidSignature, err)
+	assert.Nil(t, status.Available)
+
+	// release signed for another channel
+	server.release.Channel = ChannelBeta
+	signRelease(&server.release)
+	status, err = u.Check()
+	assert.Error(t, err)
+	assert.Nil(t, status.Available)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package updater
+
+import (
+	"strconv"
+	"strings"
+)
+
+// isNewer tells whether candidate version is newer than the current one.
+// Versions which are not in major.minor.patch form, like development builds, are never considered for update.
+func isNewer(candidate, current string) bool {
+	c, ok := parseVersion(candidate)
+	if !ok {
+		return false
+	}
+	cur, ok := parseVersion(current)
+	if !ok {
+		return false
+	}
+	for i := range c {
+		if c[i] != cur[i] {
+			return c[i] > cur[i]
+		}
+	}
+	return false
+}
+
+func parseVersion(version string) ([3]int, bool) {
+	var parsed [3]int
+	parts := strings.SplitN(strings.TrimPrefix(version, "v"), ".", 4)
+	if len(parts) < 3 {
+		return parsed, false
+	}
+	for i := 0; i < 3; i++ {
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return parsed, false
+		}
+		parsed[i] = n
+	}
+	return parsed, true
+}
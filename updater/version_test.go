@@ -0,0 +1,41 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package updater
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isNewer(t *testing.T) {
+	for _, tc := range []struct {
+		candidate, current string
+		newer              bool
+	}{
+		{"0.40.0", "0.39.2", true},
+		{"1.0.0", "0.99.99", true},
+		{"0.39.10", "0.39.9", true},
+		{"0.39.2", "0.39.2", false},
+		{"0.38.5", "0.39.0", false},
+		{"0.40.0", "source.dev-build", false},
+		{"beta", "0.39.0", false},
+	} {
+		assert.Equal(t, tc.newer, isNewer(tc.candidate, tc.current), "%s over %s", tc.candidate, tc.current)
+	}
+}
@@ -0,0 +1,69 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netutil
+
+import (
+	"net"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// InterfaceDialer opens connections through the given network interface regardless of the routing table of the host,
+// e.g. to keep traffic inside of a tunnel even if its default route is missing or replaced.
+type InterfaceDialer struct {
+	// Iface is name of the interface to send traffic through.
+	Iface string
+	// LocalIP is address of the interface connections originate from.
+	LocalIP net.IP
+	// Timeout is the maximum time to wait for connection to establish.
+	Timeout time.Duration
+}
+
+// Dial connects to the address on the named network through the interface.
+func (d *InterfaceDialer) Dial(network, address string) (net.Conn, error) {
+	iface, err := net.InterfaceByName(d.Iface)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find interface %s", d.Iface)
+	}
+
+	dialer := &net.Dialer{
+		Timeout: d.Timeout,
+		Control: func(network, address string, conn syscall.RawConn) error {
+			var err error
+			if controlErr := conn.Control(func(fd uintptr) {
+				err = bindToInterface(fd, iface)
+			}); controlErr != nil {
+				return controlErr
+			}
+			return err
+		},
+	}
+	switch {
+	case strings.HasPrefix(network, "tcp"):
+		dialer.LocalAddr = &net.TCPAddr{IP: d.LocalIP}
+	case strings.HasPrefix(network, "udp"):
+		dialer.LocalAddr = &net.UDPAddr{IP: d.LocalIP}
+	default:
+		return nil, errors.Errorf("unsupported network %s", network)
+	}
+	// Interface is bound with IPv4 socket options, tunnels of consumer are IPv4 only.
+	return dialer.Dial(network[:3]+"4", address)
+}
@@ -0,0 +1,34 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netutil
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+)
+
+// ipUnicastIf is IP_UNICAST_IF socket option, it is missing in syscall package.
+const ipUnicastIf = 31
+
+func bindToInterface(fd uintptr, iface *net.Interface) error {
+	// Interface index is expected in network byte order.
+	index := make([]byte, 4)
+	binary.BigEndian.PutUint32(index, uint32(iface.Index))
+	return syscall.SetsockoptInt(syscall.Handle(fd), syscall.IPPROTO_IP, ipUnicastIf, int(binary.LittleEndian.Uint32(index)))
+}
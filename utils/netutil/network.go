@@ -18,12 +18,14 @@
 package netutil
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strings"
+	"sync"
 
 	"github.com/jackpal/gateway"
-	"github.com/mysteriumnetwork/node/core/storage/boltdb"
+	"github.com/mysteriumnetwork/node/core/storage"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
@@ -32,6 +34,9 @@ var (
 	defaultRouteManager *routeManager = nil
 	// LogNetworkStats logs network information to the Trace log level.
 	LogNetworkStats = defaultLogNetworkStats
+
+	excludedRoutes     = make(map[string]net.IP)
+	excludedRoutesLock sync.Mutex
 )
 
 const (
@@ -44,12 +49,12 @@ type route struct {
 }
 
 type routeManager struct {
-	db          *boltdb.Bolt
+	db          storage.Storage
 	deleteRoute func(ip, wg string) error
 }
 
 // SetRouteManagerStorage initiate defaultRouteManager with a provided storage.
-func SetRouteManagerStorage(db *boltdb.Bolt) {
+func SetRouteManagerStorage(db storage.Storage) {
 	defaultRouteManager = &routeManager{
 		db:          db,
 		deleteRoute: deleteRoute,
@@ -87,6 +92,10 @@ func ClearStaleRoutes() {
 			log.Error().Err(err).Msgf("Failed to delete %s record", r.Record)
 		}
 	}
+
+	excludedRoutesLock.Lock()
+	excludedRoutes = make(map[string]net.IP)
+	excludedRoutesLock.Unlock()
 }
 
 // ExcludeRoute excludes given IP from VPN tunnel.
@@ -96,16 +105,81 @@ func ExcludeRoute(ip net.IP) error {
 		return fmt.Errorf("failed to get default gateway: %w", err)
 	}
 
+	excludedRoutesLock.Lock()
+	defer excludedRoutesLock.Unlock()
+
+	return addExcludedRoute(ip, gw)
+}
+
+// RefreshExcludedRoute moves the route of IP excluded from VPN tunnel to the current default gateway,
+// e.g. after the host switched from WiFi to LTE and the previous gateway is no longer reachable.
+func RefreshExcludedRoute(ip net.IP) error {
+	gw, err := gateway.DiscoverGateway()
+	if err != nil {
+		return fmt.Errorf("failed to get default gateway: %w", err)
+	}
+
+	excludedRoutesLock.Lock()
+	defer excludedRoutesLock.Unlock()
+
+	previous, ok := excludedRoutes[ip.String()]
+	if ok && previous.Equal(gw) {
+		return nil
+	}
+	if ok {
+		log.Info().Msgf("Moving excluded route of %s from gateway %s to %s", ip, previous, gw)
+		if err := deleteRoute(ip.String(), previous.String()); err != nil {
+			// Route is usually gone already together with the interface of the previous gateway.
+			log.Debug().Err(err).Msgf("Failed to delete route: %s %s", ip, previous)
+		}
+		if defaultRouteManager != nil {
+			err := defaultRouteManager.db.Delete(routeRecordBucket, &route{Record: routeRecord(ip, previous)})
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to delete %s record", routeRecordBucket)
+			}
+		}
+	}
+
+	return addExcludedRoute(ip, gw)
+}
+
+// DeleteExcludedRoute removes the route of IP excluded from VPN tunnel by ExcludeRoute.
+func DeleteExcludedRoute(ip net.IP) error {
+	excludedRoutesLock.Lock()
+	defer excludedRoutesLock.Unlock()
+
+	gw, ok := excludedRoutes[ip.String()]
+	if !ok {
+		return nil
+	}
+	delete(excludedRoutes, ip.String())
+
+	if defaultRouteManager != nil {
+		err := defaultRouteManager.db.Delete(routeRecordBucket, &route{Record: routeRecord(ip, gw)})
+		if err != nil {
+			log.Error().Err(err).Msgf("Failed to delete %s record", routeRecordBucket)
+		}
+	}
+	return deleteRoute(ip.String(), gw.String())
+}
+
+func addExcludedRoute(ip, gw net.IP) error {
 	if defaultRouteManager != nil {
-		err := defaultRouteManager.db.Store(routeRecordBucket, &route{
-			Record: strings.Join([]string{ip.String(), gw.String()}, routeRecordDelimeter),
-		})
+		err := defaultRouteManager.db.Store(routeRecordBucket, &route{Record: routeRecord(ip, gw)})
 		if err != nil {
 			log.Error().Err(err).Msgf("Failed to save %s record", routeRecordBucket)
 		}
 	}
 
-	return excludeRoute(ip, gw)
+	if err := excludeRoute(ip, gw); err != nil {
+		return err
+	}
+	excludedRoutes[ip.String()] = gw
+	return nil
+}
+
+func routeRecord(ip, gw net.IP) string {
+	return strings.Join([]string{ip.String(), gw.String()}, routeRecordDelimeter)
 }
 
 // AddDefaultRoute adds default VPN tunnel route.
@@ -113,11 +187,30 @@ func AddDefaultRoute(iface string) error {
 	return addDefaultRoute(iface)
 }
 
+// ErrPolicyRoutingNotSupported indicates that routing tables selected by firewall mark are not available on this OS.
+var ErrPolicyRoutingNotSupported = errors.New("policy routing is supported on Linux only")
+
+// AddPolicyRoute routes packets marked with the table number as firewall mark via given interface,
+// using a separate routing table so that the main one is left untouched.
+func AddPolicyRoute(iface string, table int) error {
+	return addPolicyRoute(iface, table)
+}
+
+// DeletePolicyRoute removes policy route added by AddPolicyRoute.
+func DeletePolicyRoute(table int) error {
+	return deletePolicyRoute(table)
+}
+
 // AssignIP assigns subnet to given interface.
 func AssignIP(iface string, subnet net.IPNet) error {
 	return assignIP(iface, subnet)
 }
 
+// SetMTU sets MTU of the given interface.
+func SetMTU(iface string, mtu int) error {
+	return setMTU(iface, mtu)
+}
+
 func defaultLogNetworkStats() {
 	if log.Logger.GetLevel() != zerolog.TraceLevel {
 		return
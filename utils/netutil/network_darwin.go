@@ -20,6 +20,7 @@ package netutil
 import (
 	"net"
 	"os/exec"
+	"strconv"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
 )
@@ -28,6 +29,18 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return cmdutil.SudoExec("ifconfig", iface, subnet.String(), peerIP(subnet).String())
 }
 
+func setMTU(iface string, mtu int) error {
+	return cmdutil.SudoExec("ifconfig", iface, "mtu", strconv.Itoa(mtu))
+}
+
+func addPolicyRoute(iface string, table int) error {
+	return ErrPolicyRoutingNotSupported
+}
+
+func deletePolicyRoute(table int) error {
+	return ErrPolicyRoutingNotSupported
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	return cmdutil.SudoExec("route", "add", "-host", ip.String(), gw.String())
 }
@@ -20,6 +20,7 @@ package netutil
 import (
 	"net"
 	"os/exec"
+	"strconv"
 
 	"github.com/mysteriumnetwork/node/utils/cmdutil"
 )
@@ -31,6 +32,10 @@ func assignIP(iface string, subnet net.IPNet) error {
 	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "up")
 }
 
+func setMTU(iface string, mtu int) error {
+	return cmdutil.SudoExec("ip", "link", "set", "dev", iface, "mtu", strconv.Itoa(mtu))
+}
+
 func excludeRoute(ip, gw net.IP) error {
 	return cmdutil.SudoExec("ip", "route", "add", ip.String(), "via", gw.String())
 }
@@ -47,6 +52,19 @@ func addDefaultRoute(iface string) error {
 	return cmdutil.SudoExec("ip", "route", "add", "128.0.0.0/1", "dev", iface)
 }
 
+func addPolicyRoute(iface string, table int) error {
+	id := strconv.Itoa(table)
+	if err := cmdutil.SudoExec("ip", "route", "replace", "default", "dev", iface, "table", id); err != nil {
+		return err
+	}
+	return cmdutil.SudoExec("ip", "rule", "add", "fwmark", id, "table", id)
+}
+
+func deletePolicyRoute(table int) error {
+	id := strconv.Itoa(table)
+	return cmdutil.SudoExec("ip", "rule", "delete", "fwmark", id, "table", id)
+}
+
 func logNetworkStats() {
 	for _, args := range [][]string{{"iptables", "-L", "-n"}, {"iptables", "-L", "-n", "-t", "nat"}, {"ip", "route", "list"}, {"ip", "address", "list"}} {
 		out, err := exec.Command("sudo", args...).CombinedOutput()
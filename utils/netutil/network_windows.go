@@ -27,18 +27,42 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+var (
+	// powershell executes given command and returns its combined output, it is replaced in tests.
+	powershell = defaultPowershell
+	// lookupInterface returns index and gateway of the named interface, it is replaced in tests.
+	lookupInterface = interfaceInfo
+)
+
+func defaultPowershell(cmd string) ([]byte, error) {
+	return exec.Command("powershell", "-Command", cmd).CombinedOutput()
+}
+
 func assignIP(iface string, subnet net.IPNet) error {
-	out, err := exec.Command("powershell", "-Command", "netsh interface ip set address name=\""+iface+"\" source=static "+subnet.String()).CombinedOutput()
+	out, err := powershell("netsh interface ip set address name=\"" + iface + "\" source=static " + subnet.String())
+	return errors.Wrap(err, string(out))
+}
+
+func setMTU(iface string, mtu int) error {
+	out, err := powershell("netsh interface ipv4 set subinterface \"" + iface + "\" mtu=" + strconv.Itoa(mtu) + " store=active")
 	return errors.Wrap(err, string(out))
 }
 
+func addPolicyRoute(iface string, table int) error {
+	return ErrPolicyRoutingNotSupported
+}
+
+func deletePolicyRoute(table int) error {
+	return ErrPolicyRoutingNotSupported
+}
+
 func excludeRoute(ip, gw net.IP) error {
-	out, err := exec.Command("powershell", "-Command", "route add "+ip.String()+"/32 "+gw.String()).CombinedOutput()
+	out, err := powershell("route add " + ip.String() + "/32 " + gw.String())
 	return errors.Wrap(err, string(out))
 }
 
 func deleteRoute(ip, gw string) error {
-	out, err := exec.Command("powershell", "-Command", "route delete "+ip+"/32").CombinedOutput()
+	out, err := powershell("route delete " + ip + "/32")
 	if err != nil {
 		return fmt.Errorf("failed to delete route: %w, %s", err, string(out))
 	}
@@ -47,16 +71,16 @@ func deleteRoute(ip, gw string) error {
 }
 
 func addDefaultRoute(name string) error {
-	id, gw, err := interfaceInfo(name)
+	id, gw, err := lookupInterface(name)
 	if err != nil {
 		return errors.Wrap(err, "failed to get info of interface: "+name)
 	}
 
-	if out, err := exec.Command("powershell", "-Command", "route add 0.0.0.0/1 "+gw+" if "+id).CombinedOutput(); err != nil {
+	if out, err := powershell("route add 0.0.0.0/1 " + gw + " if " + id); err != nil {
 		return errors.Wrap(err, string(out))
 	}
 
-	out, err := exec.Command("powershell", "-Command", "route add 128.0.0.0/1 "+gw+" if "+id).CombinedOutput()
+	out, err := powershell("route add 128.0.0.0/1 " + gw + " if " + id)
 	return errors.Wrap(err, string(out))
 }
 
@@ -76,26 +100,30 @@ func interfaceInfo(name string) (id, gw string, err error) {
 		ip, _, err := net.ParseCIDR(addr.String())
 		if err != nil {
 			log.Error().Err(err).Msg("Failed to parse an interface IP address")
+			continue
 		}
 
 		if ip.To4() == nil {
 			continue
 		}
 
-		if ipv4.Equal(net.IPv4zero) {
+		if ipv4 != nil {
 			return "", "", errors.New("failed to get interface info: exactly 1 IPv4 expected")
 		}
 
 		ipv4 = ip.To4()
 		ipv4[net.IPv4len-1] = byte(1)
 	}
+	if ipv4 == nil {
+		return "", "", errors.New("failed to get interface info: no IPv4 assigned")
+	}
 
 	return strconv.Itoa(iface.Index), ipv4.String(), nil
 }
 
 func logNetworkStats() {
 	for _, args := range []string{"ipconfig /all", "netstat -r"} {
-		out, err := exec.Command("powershell", "-Command", args).CombinedOutput()
+		out, err := powershell(args)
 		logOutputToTrace(out, err, args)
 	}
 }
@@ -0,0 +1,85 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package netutil
+
+import (
+	"errors"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type powershellMock struct {
+	commands []string
+	err      error
+}
+
+func (m *powershellMock) exec(cmd string) ([]byte, error) {
+	m.commands = append(m.commands, cmd)
+	return nil, m.err
+}
+
+func mockPowershell(err error) *powershellMock {
+	mock := &powershellMock{err: err}
+	powershell = mock.exec
+	return mock
+}
+
+func TestExcludeRoute_Windows(t *testing.T) {
+	mock := mockPowershell(nil)
+	defer func() { powershell = defaultPowershell }()
+
+	err := excludeRoute(net.ParseIP("1.2.3.4"), net.ParseIP("192.168.1.1"))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"route add 1.2.3.4/32 192.168.1.1"}, mock.commands)
+
+	err = deleteRoute("1.2.3.4", "192.168.1.1")
+	assert.NoError(t, err)
+	assert.Equal(t, "route delete 1.2.3.4/32", mock.commands[1])
+}
+
+func TestAddDefaultRoute_Windows(t *testing.T) {
+	mock := mockPowershell(nil)
+	defer func() { powershell = defaultPowershell }()
+	lookupInterface = func(name string) (string, string, error) {
+		assert.Equal(t, "myst0", name)
+		return "12", "10.182.0.1", nil
+	}
+	defer func() { lookupInterface = interfaceInfo }()
+
+	err := addDefaultRoute("myst0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"route add 0.0.0.0/1 10.182.0.1 if 12",
+		"route add 128.0.0.0/1 10.182.0.1 if 12",
+	}, mock.commands)
+}
+
+func TestAddDefaultRoute_WindowsStopsOnError(t *testing.T) {
+	mock := mockPowershell(errors.New("access denied"))
+	defer func() { powershell = defaultPowershell }()
+	lookupInterface = func(name string) (string, string, error) {
+		return "12", "10.182.0.1", nil
+	}
+	defer func() { lookupInterface = interfaceInfo }()
+
+	err := addDefaultRoute("myst0")
+	assert.Error(t, err)
+	assert.Len(t, mock.commands, 1)
+}
@@ -0,0 +1,58 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package withdrawal
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// chainReader reads withdrawal transactions from blockchain.
+type chainReader interface {
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+type ethClientGetter interface {
+	Client() *ethclient.Client
+}
+
+// EthChain reads blockchain through the reconnectable client,
+// the underlying client is resolved on every call to pick up reconnects.
+type EthChain struct {
+	client ethClientGetter
+}
+
+// NewChainReader returns blockchain reader backed by the given ethereum client.
+func NewChainReader(client ethClientGetter) *EthChain {
+	return &EthChain{client: client}
+}
+
+// TransactionReceipt returns the receipt of a mined transaction.
+func (c *EthChain) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return c.client.Client().TransactionReceipt(ctx, txHash)
+}
+
+// HeaderByNumber returns a block header, the latest one if number is nil.
+func (c *EthChain) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return c.client.Client().HeaderByNumber(ctx, number)
+}
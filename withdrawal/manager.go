@@ -0,0 +1,290 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package withdrawal
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog/log"
+)
+
+// Status represents withdrawal progress.
+type Status string
+
+const (
+	// StatusSettling means that promises are being settled by the transactor.
+	StatusSettling Status = "settling"
+	// StatusConfirming means that settlement transaction is mined and waits for confirmations.
+	StatusConfirming Status = "confirming"
+	// StatusConfirmed means that funds reached the beneficiary.
+	StatusConfirmed Status = "confirmed"
+	// StatusFailed means that withdrawal did not succeed.
+	StatusFailed Status = "failed"
+)
+
+var (
+	// ErrWithdrawalInProgress indicates that identity already has a withdrawal in progress.
+	ErrWithdrawalInProgress = errors.New("withdrawal already in progress")
+	// ErrTransactionNotFound indicates that settlement finished without a recorded transaction.
+	ErrTransactionNotFound = errors.New("settlement transaction not found")
+	// ErrTransactionReverted indicates that settlement transaction was reverted.
+	ErrTransactionReverted = errors.New("settlement transaction reverted")
+)
+
+// Withdrawal describes withdrawal of identity earnings.
+type Withdrawal struct {
+	Identity      identity.Identity
+	Accountant    common.Address
+	Beneficiary   common.Address
+	Status        Status
+	TxHash        common.Hash
+	Confirmations uint64
+	Error         string
+	StartedAt     time.Time
+	UpdatedAt     time.Time
+}
+
+// Done tells whether withdrawal is finished.
+func (w Withdrawal) Done() bool {
+	return w.Status == StatusConfirmed || w.Status == StatusFailed
+}
+
+type settler interface {
+	ForceSettle(providerID identity.Identity, accountantID common.Address) error
+	SettleWithBeneficiary(providerID identity.Identity, beneficiary, accountantID common.Address) error
+}
+
+type settlementHistory interface {
+	Get(provider identity.Identity, accountant common.Address) ([]pingpong.SettlementHistoryEntry, error)
+}
+
+type channelProvider interface {
+	GetProviderChannel(accountantAddress common.Address, addressToCheck common.Address, pending bool) (paymentClient.ProviderChannel, error)
+}
+
+type publisher interface {
+	Publish(topic string, data interface{})
+}
+
+// Options describes how withdrawal transactions are tracked.
+type Options struct {
+	// Confirmations is the number of blocks required on top of settlement transaction.
+	Confirmations uint64
+	// PollInterval is how often transaction status is checked.
+	PollInterval time.Duration
+	// Timeout limits waiting for confirmations.
+	Timeout time.Duration
+}
+
+// DefaultOptions returns default withdrawal tracking options.
+func DefaultOptions() Options {
+	return Options{
+		Confirmations: 6,
+		PollInterval:  15 * time.Second,
+		Timeout:       30 * time.Minute,
+	}
+}
+
+// Manager changes beneficiaries and withdraws earnings, tracking settlement transactions until they are confirmed.
+type Manager struct {
+	settler   settler
+	history   settlementHistory
+	channels  channelProvider
+	chain     chainReader
+	publisher publisher
+	options   Options
+
+	lock        sync.Mutex
+	withdrawals map[identity.Identity]Withdrawal
+}
+
+// NewManager creates withdrawal manager.
+func NewManager(settler settler, history settlementHistory, channels channelProvider, chain chainReader, publisher publisher, options Options) *Manager {
+	return &Manager{
+		settler:     settler,
+		history:     history,
+		channels:    channels,
+		chain:       chain,
+		publisher:   publisher,
+		options:     options,
+		withdrawals: make(map[identity.Identity]Withdrawal),
+	}
+}
+
+// Status returns the last withdrawal of identity.
+func (m *Manager) Status(id identity.Identity) (Withdrawal, bool) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	w, ok := m.withdrawals[id]
+	return w, ok
+}
+
+// SetBeneficiary changes beneficiary of identity, earnings are settled to the new beneficiary along the way.
+func (m *Manager) SetBeneficiary(id identity.Identity, beneficiary, accountantID common.Address) (Withdrawal, error) {
+	return m.start(id, beneficiary, accountantID, func() error {
+		return m.settler.SettleWithBeneficiary(id, beneficiary, accountantID)
+	})
+}
+
+// Withdraw settles earnings of identity to its current beneficiary.
+func (m *Manager) Withdraw(id identity.Identity, accountantID common.Address) (Withdrawal, error) {
+	channel, err := m.channels.GetProviderChannel(accountantID, id.ToCommonAddress(), false)
+	if err != nil {
+		return Withdrawal{}, errors.Wrap(err, "could not get beneficiary")
+	}
+
+	return m.start(id, channel.Beneficiary, accountantID, func() error {
+		return m.settler.ForceSettle(id, accountantID)
+	})
+}
+
+func (m *Manager) start(id identity.Identity, beneficiary, accountantID common.Address, settle func() error) (Withdrawal, error) {
+	m.lock.Lock()
+	if w, ok := m.withdrawals[id]; ok && !w.Done() {
+		m.lock.Unlock()
+		return Withdrawal{}, ErrWithdrawalInProgress
+	}
+	now := time.Now().UTC()
+	w := Withdrawal{
+		Identity:    id,
+		Accountant:  accountantID,
+		Beneficiary: beneficiary,
+		Status:      StatusSettling,
+		StartedAt:   now,
+		UpdatedAt:   now,
+	}
+	m.withdrawals[id] = w
+	m.lock.Unlock()
+
+	m.publisher.Publish(AppTopicWithdrawal, AppEventWithdrawal{Withdrawal: w})
+	go m.run(w, settle)
+	return w, nil
+}
+
+func (m *Manager) run(w Withdrawal, settle func() error) {
+	log.Info().Msgf("Withdrawing earnings of %s to %s", w.Identity.Address, w.Beneficiary.Hex())
+	if err := settle(); err != nil {
+		m.fail(w, errors.Wrap(err, "could not settle"))
+		return
+	}
+
+	txHash, err := m.settlementTx(w)
+	if err != nil {
+		m.fail(w, err)
+		return
+	}
+	w.TxHash = txHash
+	m.update(w, StatusConfirming)
+
+	if err := m.waitConfirmations(&w); err != nil {
+		m.fail(w, err)
+		return
+	}
+	log.Info().Msgf("Withdrawal of %s confirmed in tx %s", w.Identity.Address, w.TxHash.Hex())
+	m.update(w, StatusConfirmed)
+}
+
+// settlementTx looks up settlement transaction recorded after withdrawal has started.
+func (m *Manager) settlementTx(w Withdrawal) (common.Hash, error) {
+	entries, err := m.history.Get(w.Identity, w.Accountant)
+	if err != nil {
+		return common.Hash{}, errors.Wrap(err, "could not get settlement history")
+	}
+	for _, entry := range entries {
+		if !entry.Time.Before(w.StartedAt) && entry.TxHash != (common.Hash{}) {
+			return entry.TxHash, nil
+		}
+	}
+	return common.Hash{}, ErrTransactionNotFound
+}
+
+func (m *Manager) waitConfirmations(w *Withdrawal) error {
+	ctx, cancel := context.WithTimeout(context.Background(), m.options.Timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(m.options.PollInterval)
+	defer ticker.Stop()
+	for {
+		confirmations, err := m.confirmations(ctx, w.TxHash)
+		if err != nil {
+			log.Warn().Err(err).Msgf("Could not check withdrawal tx %s", w.TxHash.Hex())
+		}
+		if errors.Cause(err) == ErrTransactionReverted {
+			return err
+		}
+		if confirmations != w.Confirmations {
+			w.Confirmations = confirmations
+			m.update(*w, StatusConfirming)
+		}
+		if confirmations >= m.options.Confirmations {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.Errorf("transaction was not confirmed in %s", m.options.Timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *Manager) confirmations(ctx context.Context, txHash common.Hash) (uint64, error) {
+	receipt, err := m.chain.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get transaction receipt")
+	}
+	if receipt.Status == types.ReceiptStatusFailed {
+		return 0, ErrTransactionReverted
+	}
+
+	head, err := m.chain.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get latest block")
+	}
+	if head.Number.Cmp(receipt.BlockNumber) < 0 {
+		return 0, nil
+	}
+	return new(big.Int).Sub(head.Number, receipt.BlockNumber).Uint64() + 1, nil
+}
+
+func (m *Manager) fail(w Withdrawal, err error) {
+	log.Error().Err(err).Msgf("Withdrawal of %s failed", w.Identity.Address)
+	w.Error = err.Error()
+	m.update(w, StatusFailed)
+}
+
+func (m *Manager) update(w Withdrawal, status Status) {
+	w.Status = status
+	w.UpdatedAt = time.Now().UTC()
+
+	m.lock.Lock()
+	m.withdrawals[w.Identity] = w
+	m.lock.Unlock()
+
+	m.publisher.Publish(AppTopicWithdrawal, AppEventWithdrawal{Withdrawal: w})
+}
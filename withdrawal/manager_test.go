@@ -0,0 +1,218 @@
+/*
+ * Copyright (C) 2020 The "MysteriumNetwork/node" Authors.
+ *
+ * This program is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * This program is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU General Public License for more details.
+ *
+ * You should have received a copy of the GNU General Public License
+ * along with this program.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package withdrawal
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/mysteriumnetwork/node/identity"
+	"github.com/mysteriumnetwork/node/mocks"
+	"github.com/mysteriumnetwork/node/session/pingpong"
+	paymentClient "github.com/mysteriumnetwork/payments/client"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	providerID    = identity.FromAddress("0x0000000000000000000000000000000000000001")
+	accountantID  = common.HexToAddress("0x0000000000000000000000000000000000000002")
+	beneficiary   = common.HexToAddress("0x0000000000000000000000000000000000000003")
+	newWallet     = common.HexToAddress("0x0000000000000000000000000000000000000004")
+	settlementTx  = common.HexToHash("0x88af51047ff2da1e3626722fe239f70c3ddd668f067b2ac8d67b280d2eff39f7")
+	testOptions   = Options{Confirmations: 3, PollInterval: time.Millisecond, Timeout: time.Second}
+	errSettleTest = errors.New("settle failed")
+)
+
+type mockSettler struct {
+	history *mockHistory
+	err     error
+
+	lock        sync.Mutex
+	beneficiary common.Address
+}
+
+func (ms *mockSettler) ForceSettle(_ identity.Identity, _ common.Address) error {
+	return ms.settle(common.Address{})
+}
+
+func (ms *mockSettler) SettleWithBeneficiary(_ identity.Identity, beneficiary, _ common.Address) error {
+	return ms.settle(beneficiary)
+}
+
+func (ms *mockSettler) settle(beneficiary common.Address) error {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	ms.beneficiary = beneficiary
+	if ms.err != nil {
+		return ms.err
+	}
+	ms.history.add(pingpong.SettlementHistoryEntry{Time: time.Now().UTC(), TxHash: settlementTx, Beneficiary: beneficiary})
+	return nil
+}
+
+type mockHistory struct {
+	lock    sync.Mutex
+	entries []pingpong.SettlementHistoryEntry
+}
+
+func (mh *mockHistory) add(entry pingpong.SettlementHistoryEntry) {
+	mh.lock.Lock()
+	defer mh.lock.Unlock()
+	mh.entries = append([]pingpong.SettlementHistoryEntry{entry}, mh.entries...)
+}
+
+func (mh *mockHistory) Get(_ identity.Identity, _ common.Address) ([]pingpong.SettlementHistoryEntry, error) {
+	mh.lock.Lock()
+	defer mh.lock.Unlock()
+	return mh.entries, nil
+}
+
+type mockChannels struct{}
+
+func (mc *mockChannels) GetProviderChannel(_ common.Address, _ common.Address, _ bool) (paymentClient.ProviderChannel, error) {
+	return paymentClient.ProviderChannel{Beneficiary: beneficiary}, nil
+}
+
+// mockChain mines a new block on every head request.
+type mockChain struct {
+	status uint64
+
+	lock sync.Mutex
+	head int64
+}
+
+func (mc *mockChain) TransactionReceipt(_ context.Context, _ common.Hash) (*types.Receipt, error) {
+	return &types.Receipt{Status: mc.status, BlockNumber: big.NewInt(100)}, nil
+}
+
+func (mc *mockChain) HeaderByNumber(_ context.Context, _ *big.Int) (*types.Header, error) {
+	mc.lock.Lock()
+	defer mc.lock.Unlock()
+	if mc.head == 0 {
+		mc.head = 100
+	} else {
+		mc.head++
+	}
+	return &types.Header{Number: big.NewInt(mc.head)}, nil
+}
+
+func waitDone(t *testing.T, m *Manager) Withdrawal {
+	var w Withdrawal
+	assert.Eventually(t, func() bool {
+		w, _ = m.Status(providerID)
+		return w.Done()
+	}, time.Second, time.Millisecond)
+	return w
+}
+
+func TestManager_Withdraw(t *testing.T) {
+	history := &mockHistory{}
+	bus := mocks.NewEventBus()
+	m := NewManager(&mockSettler{history: history}, history, &mockChannels{}, &mockChain{status: types.ReceiptStatusSuccessful}, bus, testOptions)
+
+	w, err := m.Withdraw(providerID, accountantID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusSettling, w.Status)
+	assert.Equal(t, beneficiary, w.Beneficiary)
+
+	w = waitDone(t, m)
+	assert.Equal(t, StatusConfirmed, w.Status)
+	assert.Equal(t, settlementTx, w.TxHash)
+	assert.Equal(t, uint64(3), w.Confirmations)
+	assert.Empty(t, w.Error)
+
+	var statuses []Status
+	for _, e := range bus.GetEventHistory() {
+		assert.Equal(t, AppTopicWithdrawal, e.Topic)
+		statuses = append(statuses, e.Event.(AppEventWithdrawal).Withdrawal.Status)
+	}
+	assert.Equal(t, []Status{StatusSettling, StatusConfirming, StatusConfirming, StatusConfirming, StatusConfirming, StatusConfirmed}, statuses)
+}
+
+func TestManager_SetBeneficiary(t *testing.T) {
+	history := &mockHistory{}
+	settler := &mockSettler{history: history}
+	m := NewManager(settler, history, &mockChannels{}, &mockChain{status: types.ReceiptStatusSuccessful}, mocks.NewEventBus(), testOptions)
+
+	w, err := m.SetBeneficiary(providerID, newWallet, accountantID)
+	assert.NoError(t, err)
+	assert.Equal(t, newWallet, w.Beneficiary)
+
+	w = waitDone(t, m)
+	assert.Equal(t, StatusConfirmed, w.Status)
+	assert.Equal(t, newWallet, settler.beneficiary)
+}
+
+func TestManager_WithdrawFails(t *testing.T) {
+	tests := map[string]struct {
+		settler       *mockSettler
+		chain         *mockChain
+		expectedError error
+	}{
+		"settlement fails": {
+			settler:       &mockSettler{err: errSettleTest},
+			chain:         &mockChain{status: types.ReceiptStatusSuccessful},
+			expectedError: errSettleTest,
+		},
+		"transaction reverted": {
+			settler:       &mockSettler{},
+			chain:         &mockChain{status: types.ReceiptStatusFailed},
+			expectedError: ErrTransactionReverted,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			history := &mockHistory{}
+			tc.settler.history = history
+			m := NewManager(tc.settler, history, &mockChannels{}, tc.chain, mocks.NewEventBus(), testOptions)
+
+			_, err := m.Withdraw(providerID, accountantID)
+			assert.NoError(t, err)
+
+			w := waitDone(t, m)
+			assert.Equal(t, StatusFailed, w.Status)
+			assert.Contains(t, w.Error, tc.expectedError.Error())
+		})
+	}
+}
+
+func TestManager_RejectsConcurrentWithdrawal(t *testing.T) {
+	history := &mockHistory{}
+	blocked := make(chan struct{})
+	m := NewManager(&mockSettler{history: history}, history, &mockChannels{}, &mockChain{status: types.ReceiptStatusSuccessful}, mocks.NewEventBus(), testOptions)
+
+	_, err := m.start(providerID, beneficiary, accountantID, func() error {
+		<-blocked
+		return errSettleTest
+	})
+	assert.NoError(t, err)
+
+	_, err = m.Withdraw(providerID, accountantID)
+	assert.Equal(t, ErrWithdrawalInProgress, err)
+
+	close(blocked)
+	waitDone(t, m)
+	_, err = m.Withdraw(providerID, accountantID)
+	assert.NoError(t, err)
+}